@@ -5,19 +5,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
 	"z-novel-ai-api/internal/application/quota"
-	"z-novel-ai-api/internal/config"
 	einocallback "z-novel-ai-api/internal/infrastructure/eino/callback"
+	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
 	"z-novel-ai-api/internal/wire"
+	"z-novel-ai-api/migrations"
 	"z-novel-ai-api/pkg/logger"
-	"z-novel-ai-api/pkg/tracer"
-
-	"github.com/joho/godotenv"
+	"z-novel-ai-api/pkg/runtime"
 )
 
 // Version 版本信息，构建时注入
@@ -26,102 +21,92 @@ var (
 	BuildTime = "unknown"
 )
 
+// @title Z-Novel AI API
+// @version 1.0
+// @description 对话驱动的小说创作后端：项目/设定/章节 CRUD、设定集与章节生成（异步 Job 与 SSE 流式）、检索与计费。
+// @description SSE 接口每个事件的 data 为 StreamEvent 信封，响应头 X-Stream-Schema-Version 声明事件协议版本。
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description 格式：Bearer <access_token>
 func main() {
-	// 加载 .env 文件（如果存在）
-	_ = godotenv.Load()
-
-	// 加载配置
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
-	}
-
-	// 初始化日志
-	logger.Init(
-		cfg.Observability.Logging.Level,
-		cfg.Observability.Logging.Format,
-	)
+	runtime.Run(context.Background(), runtime.Options{
+		ServiceName: "api-gateway",
+		Version:     Version,
+		BuildTime:   BuildTime,
+		CheckConfig: true,
+		Init:        initGateway,
+	})
+}
 
-	ctx := context.Background()
+// initGateway 初始化网关依赖并启动 HTTP 服务器
+func initGateway(ctx context.Context, app *runtime.App) error {
+	cfg := app.Config
 	log := logger.FromContext(ctx)
-	log.Info("starting api-gateway",
-		"version", Version,
-		"build_time", BuildTime,
-		"env", cfg.App.Env,
-	)
 
-	// 初始化追踪
-	shutdown, err := tracer.Init(ctx, tracer.Config{
-		ServiceName: cfg.App.Name,
-		Endpoint:    cfg.Observability.Tracing.Endpoint,
-		SampleRate:  cfg.Observability.Tracing.SampleRate,
-		Enabled:     cfg.Observability.Tracing.Enabled,
-	})
-	if err != nil {
-		log.Error("failed to init tracer", "error", err)
-		os.Exit(1)
-	}
-	defer func() {
-		if err := shutdown(ctx); err != nil {
-			log.Error("failed to shutdown tracer", "error", err)
+	// 开发环境自动迁移
+	if cfg.Database.Postgres.AutoMigrate {
+		applied, err := postgres.AutoMigrate(ctx, &cfg.Database.Postgres, migrations.Postgres())
+		if err != nil {
+			return fmt.Errorf("failed to auto migrate: %w", err)
 		}
-	}()
+		log.Info("database migrated", "applied", len(applied))
+	}
 
 	// 初始化应用（使用 Wire 注入）
-	app, cleanupApp, err := wire.InitializeApp(ctx, cfg)
+	router, cleanupApp, err := wire.InitializeApp(ctx, cfg)
 	if err != nil {
-		logger.Fatal(ctx, "failed to initialize app", err)
+		return fmt.Errorf("failed to initialize app: %w", err)
 	}
-	defer cleanupApp()
+	app.Defer(cleanupApp)
 
 	// 初始化 Eino 全局 callbacks（指标/追踪/日志/自动化扣费）
 	// 注意：这里需要注入 Repo 以实现自动扣费
-	usageRecorder := quota.NewLLMUsageRecorder(app.Handlers.TenantRepo, app.Handlers.LLMUsageRepo)
+	usageRecorder := quota.NewLLMUsageRecorder(router.Handlers.TenantRepo, router.Handlers.LLMUsageRepo, router.Handlers.QuotaReservationRepo)
 	var tenantGetter einocallback.TenantIDGetter
-	if g, ok := app.Handlers.TenantContext.(interface {
+	if g, ok := router.Handlers.TenantContext.(interface {
 		GetCurrentTenant(ctx context.Context) (string, error)
 	}); ok {
 		tenantGetter = g
 	}
 	einocallback.Init(usageRecorder, tenantGetter)
 
-	// 获取引擎
-	r := app.Engine()
+	// 启动预热（后台执行，不阻塞监听）：编译工作流图、加载向量集合、建立模型与嵌入服务连接
+	if cfg.Warmup.Enabled {
+		go func() {
+			router.Handlers.Warmer.Run(ctx).Log(ctx)
+		}()
+	}
 
 	// 创建 HTTP 服务器
 	addr := fmt.Sprintf("%s:%d", cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
 	srv := &http.Server{
 		Addr:         addr,
-		Handler:      r,
+		Handler:      router.Engine(),
 		ReadTimeout:  cfg.Server.HTTP.ReadTimeout,
 		WriteTimeout: cfg.Server.HTTP.WriteTimeout,
 		IdleTimeout:  cfg.Server.HTTP.IdleTimeout,
 	}
 
-	// 启动服务器
-	go func() {
-		log.Info("http server starting", "addr", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Error("http server error", "error", err)
-			os.Exit(1)
+	// 启动服务器，收到退出信号后优雅关闭
+	app.Go(func(ctx context.Context) error {
+		errCh := make(chan error, 1)
+		go func() {
+			log.Info("http server starting", "addr", addr)
+			errCh <- srv.ListenAndServe()
+		}()
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("http server error: %w", err)
+		case <-ctx.Done():
 		}
-	}()
-
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Info("shutting down server...")
-
-	// 优雅关闭
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Error("server forced to shutdown", "error", err)
-	}
-
-	log.Info("server exited")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), runtime.DefaultShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Error("server forced to shutdown", "error", err)
+		}
+		return nil
+	})
+	return nil
 }
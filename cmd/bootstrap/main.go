@@ -3,41 +3,37 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 
-	"github.com/joho/godotenv"
-
-	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/wire"
+	"z-novel-ai-api/pkg/runtime"
 )
 
 func main() {
-	_ = godotenv.Load()
+	// 一次性任务：不启动服务组件，Init 完成即退出
+	runtime.Run(context.Background(), runtime.Options{
+		ServiceName: "bootstrap",
+		Init:        bootstrap,
+	})
+}
 
+// bootstrap 创建默认租户与首个管理员（已存在时跳过）
+func bootstrap(ctx context.Context, app *runtime.App) error {
 	fmt.Println("Starting system bootstrap...")
 
-	// 1. 加载配置
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
-	}
-
-	ctx := context.Background()
-
-	// 2. 初始化数据层（仅 PostgreSQL）
-	dataLayer, cleanup, err := wire.InitializePostgresOnly(ctx, cfg)
+	// 1. 初始化数据层（仅 PostgreSQL）
+	dataLayer, cleanup, err := wire.InitializePostgresOnly(ctx, app.Config)
 	if err != nil {
-		log.Fatalf("failed to initialize data layer: %v", err)
+		return fmt.Errorf("failed to initialize data layer: %w", err)
 	}
-	defer cleanup()
+	app.Defer(cleanup)
 
-	// 3. 创建默认租户
+	// 2. 创建默认租户
 	defaultTenantSlug := "default-tenant"
 	exists, err := dataLayer.TenantRepo.ExistsBySlug(ctx, defaultTenantSlug)
 	if err != nil {
-		log.Fatalf("failed to check tenant existence: %v", err)
+		return fmt.Errorf("failed to check tenant existence: %w", err)
 	}
 
 	var tenantID string
@@ -45,20 +41,20 @@ func main() {
 		fmt.Printf("Creating default tenant: %s...\n", defaultTenantSlug)
 		tenant := entity.NewTenant("Default Tenant", defaultTenantSlug)
 		if err := dataLayer.TenantRepo.Create(ctx, tenant); err != nil {
-			log.Fatalf("failed to create default tenant: %v", err)
+			return fmt.Errorf("failed to create default tenant: %w", err)
 		}
 		tenantID = tenant.ID
 		fmt.Printf("Default tenant created with ID: %s\n", tenantID)
 	} else {
 		tenant, err := dataLayer.TenantRepo.GetBySlug(ctx, defaultTenantSlug)
 		if err != nil {
-			log.Fatalf("failed to get existing tenant: %v", err)
+			return fmt.Errorf("failed to get existing tenant: %w", err)
 		}
 		tenantID = tenant.ID
 		fmt.Printf("Default tenant already exists with ID: %s\n", tenantID)
 	}
 
-	// 4. 创建首个管理员
+	// 3. 创建首个管理员
 	adminEmail := os.Getenv("BOOTSTRAP_ADMIN_EMAIL")
 	if adminEmail == "" {
 		adminEmail = "admin@nsxzhou.fun"
@@ -70,7 +66,7 @@ func main() {
 
 	userExists, err := dataLayer.UserRepo.ExistsByEmail(ctx, tenantID, adminEmail)
 	if err != nil {
-		log.Fatalf("failed to check admin existence: %v", err)
+		return fmt.Errorf("failed to check admin existence: %w", err)
 	}
 
 	if !userExists {
@@ -78,11 +74,11 @@ func main() {
 		admin := entity.NewUser(tenantID, adminEmail, "System Admin")
 		admin.Role = entity.UserRoleAdmin
 		if err := admin.SetPassword(adminPassword); err != nil {
-			log.Fatalf("failed to hash admin password: %v", err)
+			return fmt.Errorf("failed to hash admin password: %w", err)
 		}
 
 		if err := dataLayer.UserRepo.Create(ctx, admin); err != nil {
-			log.Fatalf("failed to create admin user: %v", err)
+			return fmt.Errorf("failed to create admin user: %w", err)
 		}
 		fmt.Printf("Admin user created successfully.\n")
 	} else {
@@ -90,4 +86,5 @@ func main() {
 	}
 
 	fmt.Println("Bootstrap completed successfully.")
+	return nil
 }
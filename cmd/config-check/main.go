@@ -0,0 +1,74 @@
+// Package main 配置校验命令：静态校验配置并检查外部依赖连通性，输出报告；存在错误时以非零状态退出
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/preflight"
+)
+
+func main() {
+	offline := flag.Bool("offline", false, "only validate config, skip connectivity checks")
+	skipLLM := flag.Bool("skip-llm", false, "skip LLM provider connectivity checks")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout for each connectivity check")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	failed := false
+
+	// 1. 严格加载：未知键（通常是拼写错误）直接报错
+	fmt.Println("== Config ==")
+	cfg, err := config.LoadStrict()
+	if err != nil {
+		fmt.Printf("[ERROR] %v\n", err)
+		// 回退普通加载，继续输出其余问题
+		cfg, err = config.Load()
+		if err != nil {
+			os.Exit(1)
+		}
+		failed = true
+	}
+
+	// 2. 静态校验
+	report := cfg.Validate()
+	for _, issue := range report.Issues {
+		fmt.Println(issue.String())
+	}
+	if report.HasErrors() {
+		failed = true
+	}
+	if len(report.Issues) == 0 {
+		fmt.Println("[OK] no issues found")
+	}
+
+	// 3. 连通性与一致性检查
+	if !*offline {
+		fmt.Println()
+		fmt.Println("== Connectivity ==")
+		checks := preflight.Run(context.Background(), cfg, preflight.Options{
+			Timeout: *timeout,
+			SkipLLM: *skipLLM,
+		})
+		for _, check := range checks {
+			fmt.Println(check.String())
+		}
+		if preflight.Failed(checks) {
+			failed = true
+		}
+	}
+
+	fmt.Println()
+	if failed {
+		fmt.Println("config check FAILED")
+		os.Exit(1)
+	}
+	fmt.Println("config check passed")
+}
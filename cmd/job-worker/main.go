@@ -7,132 +7,141 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
-	"github.com/joho/godotenv"
-
+	"z-novel-ai-api/internal/application/maintenance"
 	"z-novel-ai-api/internal/application/quota"
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
 	storychapter "z-novel-ai-api/internal/application/story/chapter"
 	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	storyspoiler "z-novel-ai-api/internal/application/story/spoiler"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
-	infraembedding "z-novel-ai-api/internal/infrastructure/embedding"
-	"z-novel-ai-api/internal/infrastructure/llm"
 	"z-novel-ai-api/internal/infrastructure/messaging"
-	"z-novel-ai-api/internal/infrastructure/persistence/milvus"
+	"z-novel-ai-api/internal/infrastructure/objectstore"
 	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
-	"z-novel-ai-api/internal/infrastructure/persistence/redis"
+	"z-novel-ai-api/internal/wire"
 	wfmodel "z-novel-ai-api/internal/workflow/model"
+	"z-novel-ai-api/migrations"
 	"z-novel-ai-api/pkg/logger"
-	"z-novel-ai-api/pkg/tracer"
-
-	einocallback "z-novel-ai-api/internal/infrastructure/eino/callback"
+	"z-novel-ai-api/pkg/runtime"
 )
 
-func main() {
-	// 加载 .env 文件（如果存在）
-	_ = godotenv.Load()
-
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
-	}
+// 章节生成任务进度：开始生成记为 chapterProgressStart，流式输出按字数线性推进至 chapterProgressEnd，
+// 收尾落库后置为 100；进度写库按 chapterProgressStep 节流。
+const (
+	chapterProgressStart = 5
+	chapterProgressEnd   = 95
+	chapterProgressStep  = 5
+)
 
-	logger.Init(cfg.Observability.Logging.Level, cfg.Observability.Logging.Format)
-	ctx := context.Background()
+// 月度配额重置：定时扫描配额周期到期的租户，每轮最多处理 quotaResetBatchSize 个
+const (
+	quotaResetInterval  = 10 * time.Minute
+	quotaResetBatchSize = 200
+)
 
-	shutdown, err := tracer.Init(ctx, tracer.Config{
-		ServiceName: "job-worker",
-		Endpoint:    cfg.Observability.Tracing.Endpoint,
-		SampleRate:  cfg.Observability.Tracing.SampleRate,
-		Enabled:     cfg.Observability.Tracing.Enabled,
+func main() {
+	runtime.Run(context.Background(), runtime.Options{
+		ServiceName:   "job-worker",
+		CheckConfig:   true,
+		MetricsServer: true, // Prometheus 指标（消费积压、认领、消息年龄、处理耗时与停滞告警）
+		Init:          initWorker,
 	})
-	if err != nil {
-		logger.Fatal(ctx, "failed to init tracer", err)
-	}
-	defer func() { _ = shutdown(ctx) }()
+}
 
-	// 1. 初始化基础基础设施
-	pgClient, err := postgres.NewClient(&cfg.Database.Postgres)
-	if err != nil {
-		logger.Fatal(ctx, "failed to init postgres", err)
-	}
-	defer func() { _ = pgClient.Close() }()
+// initWorker 初始化 Worker 依赖，注册任务处理器并启动消费者与后台循环
+func initWorker(runCtx context.Context, app *runtime.App) error {
+	cfg := app.Config
+	// 任务处理使用不随退出信号取消的上下文：消费者停止时等待进行中的任务收尾
+	ctx := context.WithoutCancel(runCtx)
 
-	redisClient, err := redis.NewClient(&cfg.Cache.Redis)
+	// 1. 初始化依赖（使用 Wire 注入；Milvus/Embedding 不可用时自动降级，向量索引与 RAG 召回不可用）
+	worker, cleanupWorker, err := wire.InitializeWorker(ctx, cfg)
 	if err != nil {
-		logger.Fatal(ctx, "failed to init redis", err)
-	}
-	defer func() { _ = redisClient.Close() }()
-
-	// 额外依赖：Milvus + Embedding（用于同步写索引；不可用时自动降级）
-	var vectorRepo *milvus.Repository
-	var indexer *appretrieval.Indexer
-	var retrievalEngine *appretrieval.Engine
-	var milvusClient *milvus.Client
-	if client, err := milvus.NewClient(ctx, &cfg.Vector.Milvus); err != nil {
-		logger.Warn(ctx, "milvus not available, vector indexing disabled", "error", err.Error())
-	} else {
-		milvusClient = client
-		defer func() { _ = milvusClient.Close() }()
-		vectorRepo = milvus.NewRepository(milvusClient)
+		return fmt.Errorf("failed to initialize worker: %w", err)
 	}
+	app.Defer(cleanupWorker)
 
-	embedder, err := infraembedding.NewEinoEmbedder(ctx, &cfg.Embedding)
-	if err != nil {
-		logger.Warn(ctx, "embedding not available, vector indexing disabled", "error", err.Error())
-	} else if vectorRepo != nil {
-		vectorPort := milvus.NewRetrievalVectorRepository(vectorRepo)
-		indexer = appretrieval.NewIndexer(embedder, vectorPort, cfg.Embedding.BatchSize)
-		retrievalEngine = appretrieval.NewEngine(embedder, vectorPort, nil, cfg.Embedding.BatchSize)
-	}
-
-	// 2. 初始化 Repositories
-	txMgr := postgres.NewTxManager(pgClient)
-	tenantCtx := postgres.NewTenantContext(pgClient)
-	jobRepo := postgres.NewJobRepository(pgClient)
-	tenantRepo := postgres.NewTenantRepository(pgClient)
-	chapterRepo := postgres.NewChapterRepository(pgClient)
-	projectRepo := postgres.NewProjectRepository(pgClient)
-	llmUsageRepo := postgres.NewLLMUsageEventRepository(pgClient)
-
-	// 3. 初始化 Eino 全局 callbacks（搬移到这里以确保 Repo 变量已定义）
-	einocallback.Init(quota.NewLLMUsageRecorder(tenantRepo, llmUsageRepo), tenantCtx)
-
-	// 4. 初始化应用逻辑
-	llmFactory := llm.NewEinoFactory(cfg)
-	foundationGenerator := storyfoundation.NewFoundationGenerator(llmFactory)
-	chapterGenerator := storychapter.NewChapterGenerator(llmFactory)
-	tokenQuotaChecker := quota.NewTokenQuotaChecker(tenantRepo)
+	if cfg.Database.Postgres.AutoMigrate {
+		migrator, err := postgres.NewMigrator(worker.PgClient, migrations.Postgres())
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+		applied, err := migrator.Up(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("failed to auto migrate: %w", err)
+		}
+		logger.Info(ctx, "database migrated", "applied", len(applied))
+	}
+
+	// 1.1 启动预热：开始消费任务前编译工作流、加载向量集合并建立模型/嵌入服务连接，首个任务不再承担冷启动
+	if cfg.Warmup.Enabled {
+		worker.Warmer.Run(runCtx).Log(runCtx)
+	}
+
+	// 2. 处理器使用的依赖
+	redisClient := worker.RedisClient
+	txMgr := worker.TxManager
+	tenantCtx := worker.TenantContext
+	jobRepo := worker.JobRepo
+	tenantRepo := worker.TenantRepo
+	chapterRepo := worker.ChapterRepo
+	projectRepo := worker.ProjectRepo
+	projectLocker := worker.ProjectLocker
+	retrievalEngine := worker.RetrievalEngine
+	foundationGenerator := worker.FoundationGenerator
+	foundationPlanLimits := worker.FoundationPlanLimits
+	chapterGenerator := worker.ChapterGenerator
+	tokenQuotaChecker := worker.TokenQuotaChecker
+	planService := worker.PlanService
+	seriesService := worker.SeriesService
+	jobTimeline := worker.JobTimeline
+	contextPins := worker.ContextPins
+	canonContext := worker.CanonContext
+	spoilerGuards := worker.SpoilerGuards
+	jobBudget := worker.JobBudget
+	titleSuggestions := worker.TitleSuggestions
+	finalizer := worker.Finalizer
+	maintenanceRunner := worker.MaintenanceRunner
+	opsSwitches := worker.OpsSwitches
+	jobProgress := worker.JobProgress
+	jobCancel := worker.JobCancel
+	consumerName := hostnameConsumerName()
 
 	// 5. 初始化消息消费者
 	consumer := messaging.NewConsumer(redisClient.Redis(), messaging.ConsumerConfig{
-		Stream:        messaging.StreamStoryGen,
+		Streams:       messaging.StoryGenStreams, // 按 high → normal → low 顺序优先领取
 		Group:         messaging.ConsumerGroupGenWorker,
-		ConsumerName:  hostnameConsumerName(),
+		ConsumerName:  consumerName,
 		BlockTimeout:  cfg.Messaging.RedisStream.BlockTimeout,
 		ClaimInterval: cfg.Messaging.RedisStream.ClaimInterval,
 		RetryLimit:    cfg.Messaging.RedisStream.RetryLimit,
+		// 停滞检测：同一消息反复被认领却无法推进时告警
+		StalledClaimThreshold: cfg.Messaging.RedisStream.StalledClaimThreshold,
 		Backoff: messaging.BackoffConfig{
 			Initial:    cfg.Messaging.RedisStream.RetryBackoff.Initial,
 			Max:        cfg.Messaging.RedisStream.RetryBackoff.Max,
 			Multiplier: cfg.Messaging.RedisStream.RetryBackoff.Multiplier,
 		},
+		PauseGate: opsSwitches,
 	})
 
 	// 注册 chapter_gen 处理器
 	consumer.RegisterHandler("chapter_gen", func(_ context.Context, msg *messaging.Message) error {
-		var payload messaging.GenerationJobMessage
-		if err := msg.UnmarshalPayload(&payload); err != nil {
+		payload, params, err := messaging.DecodeChapterGenJob(msg)
+		invalidPayload, err := screenJobPayload(ctx, payload, err)
+		if err != nil {
 			return err
 		}
 
-		var chapterForIndex *entity.Chapter
+		// 1. 准备事务：校验任务、召回上下文并标记开始（genJob 为空表示无需生成）
+		var genJob *entity.GenerationJob
+		var genInput *wfmodel.ChapterGenerateInput
+		var genSpoilers *storyspoiler.Constraints
 		txErr := txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
 			if err := tenantCtx.SetTenant(txCtx, payload.TenantID); err != nil {
 				return err
@@ -145,175 +154,268 @@ func main() {
 			if job == nil {
 				return fmt.Errorf("job not found: %s", payload.JobID)
 			}
-			if job.Status == entity.JobStatusCancelled {
+			// 已取消或因成本上限终止的任务不再执行（重复投递的消息直接确认）
+			if job.Status == entity.JobStatusCancelled || job.ErrorCode == entity.JobErrorCostCeiling {
 				return nil
 			}
 			if job.Status == entity.JobStatusCompleted {
 				return nil
 			}
-
-			// 余额检查（不足时不重试，直接标记失败）
-			if _, err := tokenQuotaChecker.CheckBalance(txCtx, payload.TenantID, 1000); err != nil {
-				var exceeded quota.TokenBalanceExceededError
-				if errors.As(err, &exceeded) {
-					job.Fail(err.Error())
-					_ = jobRepo.Update(txCtx, job)
-					if payload.ChapterID != nil {
-						_ = markChapterDraft(txCtx, chapterRepo, *payload.ChapterID)
-					}
-					return nil
+			// 载荷校验失败：直接标记失败并确认消息（重试无法修复生产者与 Worker 的结构不一致）
+			if invalidPayload != nil {
+				chapterID := ""
+				if payload.ChapterID != nil {
+					chapterID = *payload.ChapterID
 				}
-				return err
+				return finalizer.FailChapter(txCtx, job, chapterID, invalidPayload)
 			}
 
-			if payload.ChapterID == nil {
-				job.Fail("chapter_id is required for chapter_gen job")
-				_ = jobRepo.Update(txCtx, job)
-				return fmt.Errorf("chapter_id is required")
+			// 共享锁：生成期间阻止 Foundation 落库/重排修改项目结构，写回章节时卷/序号保持一致
+			if err := projectLocker.LockProjectShared(txCtx, payload.ProjectID); err != nil {
+				return err
 			}
+			jobTimeline.Record(txCtx, job, entity.JobEventClaimed, "claimed by worker", map[string]any{"worker": consumerName})
 
 			chapter, err := chapterRepo.GetByID(txCtx, *payload.ChapterID)
 			if err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+				_ = finalizer.FailChapter(txCtx, job, "", err)
 				return err
 			}
 			if chapter == nil {
 				err := fmt.Errorf("chapter not found: %s", *payload.ChapterID)
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+				_ = finalizer.FailChapter(txCtx, job, "", err)
 				return err
 			}
 
 			project, err := projectRepo.GetByID(txCtx, payload.ProjectID)
 			if err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+				_ = finalizer.FailChapter(txCtx, job, chapter.ID, err)
 				return err
 			}
 			if project == nil {
 				err := fmt.Errorf("project not found: %s", payload.ProjectID)
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+				_ = finalizer.FailChapter(txCtx, job, chapter.ID, err)
 				return err
 			}
 
-			in, err := buildChapterInput(cfg, project, chapter, payload.Params)
+			// 配额预留：入队时已预留则直接通过；旧消息或预留已过期/释放时按解析后的目标字数补预留（不足时不重试，直接标记失败）
+			candidates := chapterCandidateCount(params)
+			targetWordCount := resolveTargetWordCount(project, params.TargetWordCount)
+			if err := tokenQuotaChecker.EnsureReserved(txCtx, payload.TenantID, job.ID, quota.ChapterReserveTokens(targetWordCount)*int64(candidates)); err != nil {
+				var exceeded quota.TokenBalanceExceededError
+				if errors.As(err, &exceeded) {
+					_ = finalizer.FailChapter(txCtx, job, chapter.ID, err)
+					return nil
+				}
+				return err
+			}
+
+			in, err := buildChapterInput(cfg, project, chapter, params)
 			if err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
-				_ = markChapterDraft(txCtx, chapterRepo, chapter.ID)
+				_ = finalizer.FailChapter(txCtx, job, chapter.ID, err)
 				return nil
 			}
 
+			// 先标记开始：此后的召回/设定加载均为软失败，以任务警告告知用户（重试时 Start 会清空上一轮警告）
+			job.Start()
+
+			// 剧透保护：加载失败不阻断生成
+			spoilers, serr := spoilerGuards.ForChapter(txCtx, chapter)
+			if serr != nil {
+				logger.Warn(txCtx, "failed to load spoiler guards", "error", serr.Error(), "chapter_id", chapter.ID)
+				job.AddWarnings(appstory.SpoilerGuardWarning())
+			}
+
 			// RAG：在生成前召回上下文，注入 Prompt（失败不影响主流程）
-			if retrievalEngine != nil {
-				ro, rerr := retrievalEngine.Search(txCtx, appretrieval.SearchInput{
-					TenantID:         payload.TenantID,
-					ProjectID:        payload.ProjectID,
-					Query:            in.ChapterOutline,
-					CurrentStoryTime: chapter.StoryTimeStart,
-					TopK:             12,
-					IncludeEntities:  false,
-				})
-				if rerr == nil && ro != nil && len(ro.Segments) > 0 {
-					in.RetrievedContext = appretrieval.BuildPromptContext(ro.Segments, 10, 360)
+			if retrievalEngine.Enabled() {
+				narrativePos, perr := chapterRepo.GetNarrativePosition(txCtx, chapter.ID)
+				if perr != nil {
+					logger.Warn(txCtx, "failed to get chapter narrative position", "error", perr.Error(), "chapter_id", chapter.ID)
+				}
+				seriesProjectIDs, serr := seriesService.RetrievalProjectIDs(txCtx, project)
+				if serr != nil {
+					logger.Warn(txCtx, "failed to resolve series retrieval scope", "error", serr.Error(), "project_id", project.ID)
+				}
+				focusIDs, ferr := retrievalEngine.DetectFocusEntities(txCtx, project.ID, chapter.Title+"\n"+in.ChapterOutline)
+				if ferr != nil {
+					logger.Warn(txCtx, "failed to detect outline entities", "error", ferr.Error(), "chapter_id", chapter.ID)
+				}
+				searchIn := appretrieval.ChapterSearchInput(payload.TenantID, project.Settings, chapter, in.ChapterOutline, narrativePos, seriesProjectIDs)
+				searchIn.FocusEntityIDs = focusIDs
+				ro, rerr := retrievalEngine.Search(txCtx, searchIn)
+				if rerr == nil && ro != nil {
+					segments := spoilers.FilterSegments(ro.Segments)
+					if len(segments) > 0 {
+						in.RetrievedContext = appretrieval.BuildPromptContext(segments, appretrieval.PromptMaxSegments, appretrieval.PromptMaxRunesPerSegment)
+						job.RecordTruncation(appstory.RetrievalTruncations(segments)...)
+					}
+					jobTimeline.Record(txCtx, job, entity.JobEventRAGRetrieved, "context retrieved", map[string]any{"segments": len(segments), "spoiler_excluded": len(ro.Segments) - len(segments)})
+				}
+				if rerr != nil {
+					logger.Warn(txCtx, "failed to retrieve chapter context", "error", rerr.Error(), "chapter_id", chapter.ID)
+					job.AddWarnings(appstory.RetrievalFailedWarning())
 				}
 			}
 
-			if chapter.Status != entity.ChapterStatusGenerating {
-				chapter.Status = entity.ChapterStatusGenerating
+			// 作者固定的上下文不受召回结果影响，始终注入（置于召回上下文之前）
+			in.RetrievedContext = appstory.JoinPromptContext(contextPins.PromptContext(txCtx, chapter), in.RetrievedContext)
+			in.RetrievedContext = appstory.JoinPromptContext(in.RetrievedContext, spoilers.PromptBlock())
+
+			// 激活构件的设定摘录（本书没有时继承系列前作）：按大纲筛选相关角色，作为独立模板变量注入
+			canon := canonContext.ForChapter(txCtx, project, chapter.Title, in.ChapterOutline)
+			in.ActiveWorldview, in.ActiveCharacters = canon.Worldview, canon.Characters
+
+			if want := appstory.ChapterStatusForJob(job.Status, ""); chapter.Status != want {
+				chapter.Status = want
 				_ = chapterRepo.Update(txCtx, chapter)
 			}
 
-			job.Start()
-			job.UpdateProgress(5)
+			job.UpdateProgress(chapterProgressStart)
+			// 记录实际送入模型的输入，供管理员回放调试
+			job.InputSnapshot = storychapter.MarshalInputSnapshot(in)
 			if err := jobRepo.Update(txCtx, job); err != nil {
 				return err
 			}
+			jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "chapter generation started", map[string]any{"candidates": candidates})
 
-			out, err := chapterGenerator.Generate(txCtx, in)
-			if err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+			genJob, genInput, genSpoilers = job, in, spoilers
+			return nil
+		})
+		if txErr != nil {
+			return txErr
+		}
+		if genJob == nil {
+			return nil
+		}
+
+		// 2. 事务外流式生成：按已生成字数折算进度，节流写库（每 chapterProgressStep%），避免长事务持有连接。
+		// 多候选时并行生成，进度按全部候选的累计字数折算（目标字数 × 候选数）；
+		// 正文分片与进度同时发布到 Redis 频道，供网关 GET /v1/jobs/:jid/stream（SSE）与 /v1/ws（WebSocket）推送给前端；
+		// 生成期间监听取消信号，用户取消任务时中止全部候选的 LLM 调用。
+		candidates := chapterCandidateCount(params)
+		live := appstory.NewJobProgressStream(jobProgress, genJob.TenantID, genJob.ProjectID, genJob.ID)
+		progress := appstory.NewStreamProgress(chapterProgressStart, chapterProgressEnd, genInput.TargetWordCount*candidates, chapterProgressStep)
+		var progressMu sync.Mutex
+		generatedBy := make([]int, candidates)
+		genCtx, finishGen := appstory.WithGenerationTimeout(ctx, appstory.StageChapterGen, cfg.Story.GenerationTimeouts.ChapterGen)
+		genCtx, finishSpend := jobBudget.Track(genCtx, genJob)
+		genCtx, finishCancel := appstory.WithJobCancellation(genCtx, jobCancel, genJob.ID)
+		outs, failedCandidates, genErr := appstory.RunCandidates(genCtx, candidates, func(candCtx context.Context, i int) (*wfmodel.ChapterGenerateOutput, error) {
+			return chapterGenerator.GenerateStreaming(candCtx, genInput, func(chunk string, generated int) {
+				live.Content(ctx, i, chunk)
+				progressMu.Lock()
+				generatedBy[i] = generated
+				total := 0
+				for _, n := range generatedBy {
+					total += n
+				}
+				p, ok := progress.Advance(total)
+				progressMu.Unlock()
+				if !ok {
+					return
+				}
+				live.Progress(ctx, p)
+				if err := txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+					if err := tenantCtx.SetTenant(txCtx, payload.TenantID); err != nil {
+						return err
+					}
+					return jobRepo.UpdateProgress(txCtx, genJob.ID, p)
+				}); err != nil {
+					logger.Warn(ctx, "failed to update job progress", "error", err.Error(), "job_id", genJob.ID)
+				}
+			})
+		})
+		genErr = finishSpend(finishGen(finishCancel(genErr)))
+
+		// 3. 收尾事务：重新持有共享锁并重新加载章节，基于最新结构写回结果
+		var chapterForIndex *appstory.ChapterIndexSnapshot
+		txErr = txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+			if err := tenantCtx.SetTenant(txCtx, payload.TenantID); err != nil {
 				return err
 			}
-
-			job.UpdateProgress(80)
-			if err := jobRepo.Update(txCtx, job); err != nil {
+			if err := projectLocker.LockProjectShared(txCtx, payload.ProjectID); err != nil {
 				return err
 			}
 
-			chapter.Outline = in.ChapterOutline
-			chapter.SetContent(out.Content)
-			chapter.Status = entity.ChapterStatusCompleted
-			chapter.GenerationMetadata = &entity.GenerationMetadata{
-				Model:            out.Meta.Model,
-				Provider:         out.Meta.Provider,
-				PromptTokens:     out.Meta.PromptTokens,
-				CompletionTokens: out.Meta.CompletionTokens,
-				Temperature:      out.Meta.Temperature,
-				GeneratedAt:      out.Meta.GeneratedAt.Format(time.RFC3339),
+			if appstory.IsJobCancelled(genErr) {
+				// 用户已取消：保持 cancelled 并确认消息，不再重试
+				return finalizer.AbortChapter(txCtx, genJob, *payload.ChapterID)
+			}
+			if genErr != nil {
+				// 失败状态随事务提交；返回错误交由消费者按退避策略重试（重试时 Start 会累计 RetryCount，
+				// 累计消耗达到成本上限时 error_code 记为 cost_ceiling_exceeded 且不再重试）
+				return finalizer.FailChapter(txCtx, genJob, *payload.ChapterID, genErr)
 			}
 
-			if err := chapterRepo.Update(txCtx, chapter); err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
+			chapter, err := chapterRepo.GetByID(txCtx, *payload.ChapterID)
+			if err != nil {
 				return err
 			}
-
-			if stats, err := projectRepo.GetStats(txCtx, project.ID); err == nil && stats != nil {
-				_ = projectRepo.UpdateWordCount(txCtx, project.ID, int(stats.TotalWordCount))
+			if chapter == nil {
+				err := fmt.Errorf("chapter not found: %s", *payload.ChapterID)
+				_ = finalizer.FailChapter(txCtx, genJob, "", err)
+				return nil
 			}
 
-			result, _ := json.Marshal(map[string]interface{}{
-				"chapter_id": chapter.ID,
-				"word_count": len([]rune(out.Content)),
-			})
-			job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
-			job.Complete(result)
-			if err := jobRepo.Update(txCtx, job); err != nil {
-				return err
+			// 剧透扫描仅标记，不阻断落库（由作者决定是否重生成）
+			for _, out := range outs {
+				if violations := genSpoilers.Scan(out.Content); len(violations) > 0 {
+					jobTimeline.Record(txCtx, genJob, entity.JobEventSpoilerFlagged, storyspoiler.Summary(violations), map[string]any{"violations": violations})
+				}
 			}
 
+			chapter.Outline = genInput.ChapterOutline
 			// 事务内仅准备索引输入；索引写入放到事务提交之后执行，避免持有 DB 连接。
-			chapterForIndex = &entity.Chapter{
-				ID:             chapter.ID,
-				ProjectID:      chapter.ProjectID,
-				Title:          chapter.Title,
-				ContentText:    chapter.ContentText,
-				StoryTimeStart: chapter.StoryTimeStart,
-				StoryTimeEnd:   chapter.StoryTimeEnd,
+			if candidates <= 1 {
+				chapterForIndex, err = finalizer.CompleteChapter(txCtx, genJob, chapter, outs[0])
+				return err
 			}
-			return nil
+			if failedCandidates > 0 {
+				genJob.AddWarnings(appstory.CandidatesFailedWarning(failedCandidates, candidates))
+			}
+			selection, _ := appstory.NormalizeCandidateSelection(params.Selection)
+			chapterForIndex, err = finalizer.CompleteChapterCandidates(txCtx, genJob, chapter, outs, selection, genInput.TargetWordCount)
+			return err
 		})
-
 		if txErr != nil {
 			return txErr
 		}
+		live.Finish(ctx, genJob)
+		if genErr != nil {
+			if appstory.IsCostCeilingExceeded(genErr) || appstory.IsJobCancelled(genErr) {
+				return nil
+			}
+			return genErr
+		}
 
-		// 同步写索引：章节生成成功后写入向量索引（失败不影响消费 ACK）
-		if indexer != nil && chapterForIndex != nil {
-			indexCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-			if err := indexer.IndexChapter(indexCtx, payload.TenantID, payload.ProjectID, chapterForIndex); err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) {
-				logger.Warn(ctx, "failed to index chapter after job completion",
-					"error", err.Error(),
-					"chapter_id", chapterForIndex.ID,
-				)
+		// 同步写索引：章节生成成功后写入向量索引（失败不影响消费 ACK，仅记录任务警告）
+		if err := finalizer.IndexChapter(ctx, payload.TenantID, chapterForIndex); err != nil {
+			if werr := txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+				if err := tenantCtx.SetTenant(txCtx, payload.TenantID); err != nil {
+					return err
+				}
+				return jobRepo.AppendWarnings(txCtx, genJob.ID, appstory.IndexFailedWarning())
+			}); werr != nil {
+				logger.Warn(ctx, "failed to record job warning", "error", werr.Error(), "job_id", genJob.ID)
 			}
 		}
+		// 占位标题的章节自动提出标题建议（失败仅记录日志，不影响消费 ACK）
+		if err := titleSuggestions.SuggestAfterGeneration(ctx, payload.TenantID, chapterForIndex, outs[0].Meta.Provider, outs[0].Meta.Model); err != nil {
+			logger.Warn(ctx, "failed to suggest chapter titles", "error", err.Error(), "chapter_id", *payload.ChapterID)
+		}
 		return nil
 	})
 
 	// 注册 foundation_gen 处理器
 	consumer.RegisterHandler("foundation_gen", func(handlerCtx context.Context, msg *messaging.Message) error {
-		var payload messaging.GenerationJobMessage
-		if err := msg.UnmarshalPayload(&payload); err != nil {
+		payload, params, err := messaging.DecodeFoundationGenJob(msg)
+		invalidPayload, err := screenJobPayload(handlerCtx, payload, err)
+		if err != nil {
 			return err
 		}
 
-		return txMgr.WithTransaction(handlerCtx, func(txCtx context.Context) error {
+		var retryErr error
+		if err := txMgr.WithTransaction(handlerCtx, func(txCtx context.Context) error {
 			if err := tenantCtx.SetTenant(txCtx, payload.TenantID); err != nil {
 				return err
 			}
@@ -325,9 +427,19 @@ func main() {
 			if job == nil {
 				return fmt.Errorf("job not found: %s", payload.JobID)
 			}
-			if job.Status == entity.JobStatusCancelled {
+			// 已取消或因成本上限终止的任务不再执行（重复投递的消息直接确认）
+			if job.Status == entity.JobStatusCancelled || job.ErrorCode == entity.JobErrorCostCeiling {
 				return nil
 			}
+			if invalidPayload != nil {
+				job.FailWithCode(appstory.JobErrorCode(invalidPayload), invalidPayload.Error())
+				if err := jobRepo.Update(txCtx, job); err != nil {
+					return err
+				}
+				jobTimeline.Record(txCtx, job, entity.JobEventFailed, invalidPayload.Error(), nil)
+				return tokenQuotaChecker.Release(txCtx, job.ID)
+			}
+			jobTimeline.Record(txCtx, job, entity.JobEventClaimed, "claimed by worker", map[string]any{"worker": consumerName})
 
 			tenant, err := tenantRepo.GetByID(txCtx, payload.TenantID)
 			if err != nil {
@@ -337,11 +449,11 @@ func main() {
 				return fmt.Errorf("tenant not found: %s", payload.TenantID)
 			}
 
-			// 余额检查
-			if _, err := tokenQuotaChecker.CheckBalance(txCtx, payload.TenantID, 1000); err != nil {
+			// 配额预留（同 chapter_gen：缺失时补预留，不足时直接标记失败）
+			if err := tokenQuotaChecker.EnsureReserved(txCtx, payload.TenantID, job.ID, quota.FoundationReserveTokens(params.MaxTokens)); err != nil {
 				var exceeded quota.TokenBalanceExceededError
 				if errors.As(err, &exceeded) {
-					job.Fail(err.Error())
+					job.FailWithCode(entity.JobErrorQuotaExceeded, err.Error())
 					_ = jobRepo.Update(txCtx, job)
 					return nil
 				}
@@ -356,49 +468,167 @@ func main() {
 				return fmt.Errorf("project not found: %s", payload.ProjectID)
 			}
 
-			in, err := buildFoundationInput(project, payload.Params)
+			in, err := buildFoundationInput(project, params)
 			if err != nil {
 				job.Fail(err.Error())
+				if err := tokenQuotaChecker.Release(txCtx, job.ID); err != nil {
+					return err
+				}
 				return jobRepo.Update(txCtx, job)
 			}
 
 			job.Start()
+			// 超长附件在 Prompt 中会被截断，提前告知用户
+			_, truncated := wfmodel.LimitAttachments(in.Attachments)
+			job.AddWarnings(appstory.AttachmentWarnings(truncated)...)
+			job.RecordTruncation(appstory.AttachmentTruncations(in.Attachments)...)
 			if err := jobRepo.Update(txCtx, job); err != nil {
 				return err
 			}
-
-			out, err := foundationGenerator.Generate(txCtx, in)
+			jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "foundation generation started", nil)
+
+			genCtx, finishGen := appstory.WithGenerationTimeout(txCtx, appstory.StageFoundationGen, cfg.Story.GenerationTimeouts.FoundationGen)
+			genCtx, finishSpend := jobBudget.Track(genCtx, job)
+			genCtx, finishCancel := appstory.WithJobCancellation(genCtx, jobCancel, job.ID)
+			out, err := foundationGenerator.Generate(genCtx, in)
+			if err = finishSpend(finishGen(finishCancel(err))); appstory.IsJobCancelled(err) {
+				// 用户已取消：记录本次消耗并释放预留，不再重试
+				now := time.Now()
+				job.Status = entity.JobStatusCancelled
+				job.CompletedAt = &now
+				if err := jobRepo.Update(txCtx, job); err != nil {
+					return err
+				}
+				jobTimeline.Record(txCtx, job, entity.JobEventCancelled, "generation aborted by cancel request", map[string]any{"spent_tokens": job.SpentTokens})
+				return tokenQuotaChecker.Release(txCtx, job.ID)
+			}
 			if err != nil {
-				job.Fail(err.Error())
-				_ = jobRepo.Update(txCtx, job)
-				return err
+				job.FailWithCode(appstory.JobErrorCode(err), err.Error())
+				if err := jobRepo.Update(txCtx, job); err != nil {
+					return err
+				}
+				jobTimeline.Record(txCtx, job, entity.JobEventFailed, err.Error(), nil)
+				if appstory.IsCostCeilingExceeded(err) {
+					// 累计消耗达到成本上限：失败即终态，释放预留且不再重试
+					return tokenQuotaChecker.Release(txCtx, job.ID)
+				}
+				// 失败状态与本次消耗随事务提交；返回错误交由消费者按退避策略重试
+				retryErr = err
+				return nil
 			}
-			if err := storyfoundation.ValidateFoundationPlan(out.Plan); err != nil {
+			if err := storyfoundation.ValidateFoundationPlan(out.Plan, foundationPlanLimits.For(tenant)); err != nil {
 				job.Fail(err.Error())
 				_ = jobRepo.Update(txCtx, job)
+				_ = tokenQuotaChecker.Release(txCtx, job.ID)
+				var ve storyfoundation.FoundationPlanValidationError
+				var tooLarge storyfoundation.FoundationPlanTooLargeError
+				if errors.As(err, &ve) {
+					jobTimeline.Record(txCtx, job, entity.JobEventValidateFailed, "foundation plan validation failed", map[string]any{"issues": ve.Issues})
+				} else if errors.As(err, &tooLarge) {
+					jobTimeline.Record(txCtx, job, entity.JobEventValidateFailed, "foundation plan too large", map[string]any{"issues": tooLarge.Issues})
+				}
+				jobTimeline.Record(txCtx, job, entity.JobEventFailed, err.Error(), nil)
 				return nil
 			}
 
 			resultBytes, _ := json.Marshal(out.Plan)
 			job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
 			job.Complete(resultBytes)
-			return jobRepo.Update(txCtx, job)
-		})
+			if err := jobRepo.Update(txCtx, job); err != nil {
+				return err
+			}
+			if err := tokenQuotaChecker.Settle(txCtx, job.ID, int64(out.Meta.PromptTokens+out.Meta.CompletionTokens)); err != nil {
+				return err
+			}
+			jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "foundation plan generated", map[string]any{
+				"prompt_tokens":     out.Meta.PromptTokens,
+				"completion_tokens": out.Meta.CompletionTokens,
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+		return retryErr
 	})
 
+	// 注册运维任务处理器（导出/重建索引/清理向量）：消息类型即任务类型
+	for _, jobType := range maintenance.JobTypes() {
+		consumer.RegisterHandler(string(jobType), func(handlerCtx context.Context, msg *messaging.Message) error {
+			// 运维任务的输入从任务记录读取，消息仅需可定位任务
+			payload, err := messaging.DecodeJobEnvelope(msg)
+			if _, err := screenJobPayload(handlerCtx, payload, err); err != nil {
+				return err
+			}
+			return maintenanceRunner.Run(handlerCtx, consumerName, payload.TenantID, payload.JobID)
+		})
+	}
+
 	if err := consumer.Start(ctx); err != nil {
-		logger.Fatal(ctx, "failed to start consumer", err)
+		return fmt.Errorf("failed to start consumer: %w", err)
+	}
+	app.Go(func(runCtx context.Context) error {
+		<-runCtx.Done()
+		consumer.Stop()
+		return nil
+	})
+	// 上报队列深度与任务耗时（网关据此返回排队估算并按阈值背压）
+	go consumer.PublishStats(runCtx, cfg.Messaging.Backpressure.StatsInterval)
+
+	go runQuotaResetLoop(runCtx, txMgr, planService)
+
+	// 对象存储过期清理（删除幂等，多实例并发执行无副作用）
+	if worker.ObjectStore != nil {
+		lifecycle := objectstore.NewLifecycle(worker.ObjectStore, objectstore.LifecycleRulesFromConfig(cfg.Storage.Lifecycle))
+		go lifecycle.Run(runCtx, cfg.Storage.Lifecycle.Interval)
+	}
+
+	// 编辑后自动重建索引（到期请求由 Redis 原子领取，多实例互不重复）
+	if cfg.Story.Reindex.Enabled {
+		go worker.Reindexer.Run(runCtx, cfg.Story.Reindex.PollInterval)
 	}
 
-	log := logger.FromContext(ctx)
-	log.Info("job-worker started")
+	// 任务/章节状态巡检（修复幂等，多实例并发执行无副作用）
+	if cfg.Story.GenerationConsistency.Enabled {
+		go worker.Consistency.Run(runCtx, cfg.Story.GenerationConsistency.Interval)
+	}
+	return nil
+}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// runQuotaResetLoop 定时重置配额周期到期的租户余额（多实例并发执行时由租户行锁保证幂等）
+func runQuotaResetLoop(ctx context.Context, txMgr *postgres.TxManager, planService *quota.PlanService) {
+	ticker := time.NewTicker(quotaResetInterval)
+	defer ticker.Stop()
+	for {
+		resetDueQuotas(ctx, txMgr, planService)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
 
-	log.Info("job-worker shutting down")
-	consumer.Stop()
+func resetDueQuotas(ctx context.Context, txMgr *postgres.TxManager, planService *quota.PlanService) {
+	now := time.Now()
+	tenantIDs, err := planService.ListQuotaResetDue(ctx, now, quotaResetBatchSize)
+	if err != nil {
+		logger.Warn(ctx, "failed to list tenants due for quota reset", "error", err.Error())
+		return
+	}
+	for _, tenantID := range tenantIDs {
+		var reset bool
+		if err := txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+			var resetErr error
+			reset, resetErr = planService.ResetQuota(txCtx, tenantID, now)
+			return resetErr
+		}); err != nil {
+			logger.Warn(ctx, "failed to reset tenant quota", "error", err.Error(), "tenant_id", tenantID)
+			continue
+		}
+		if reset {
+			logger.Info(ctx, "tenant quota reset", "tenant_id", tenantID)
+		}
+	}
 }
 
 func hostnameConsumerName() string {
@@ -409,49 +639,31 @@ func hostnameConsumerName() string {
 	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
-func buildFoundationInput(project *entity.Project, params map[string]interface{}) (*wfmodel.FoundationGenerateInput, error) {
+func buildFoundationInput(project *entity.Project, params *messaging.FoundationGenParams) (*wfmodel.FoundationGenerateInput, error) {
 	if project == nil {
 		return nil, fmt.Errorf("project is nil")
 	}
 
-	prompt, _ := params["prompt"].(string)
-	prompt = strings.TrimSpace(prompt)
+	prompt := strings.TrimSpace(params.Prompt)
 	if prompt == "" {
 		return nil, fmt.Errorf("missing prompt")
 	}
 
-	provider, _ := params["provider"].(string)
-	modelName, _ := params["model"].(string)
-
 	var temperature *float32
-	if v, ok := params["temperature"].(float64); ok {
-		f := float32(v)
+	if params.Temperature != nil {
+		f := float32(*params.Temperature)
 		temperature = &f
 	}
 
-	var maxTokens *int
-	if v, ok := params["max_tokens"].(float64); ok {
-		i := int(v)
-		maxTokens = &i
-	}
-
-	attachments := make([]wfmodel.TextAttachment, 0)
-	if raw, ok := params["attachments"].([]interface{}); ok {
-		for _, item := range raw {
-			m, ok := item.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			name, _ := m["name"].(string)
-			content, _ := m["content"].(string)
-			if strings.TrimSpace(content) == "" {
-				continue
-			}
-			attachments = append(attachments, wfmodel.TextAttachment{
-				Name:    name,
-				Content: content,
-			})
+	attachments := make([]wfmodel.TextAttachment, 0, len(params.Attachments))
+	for _, a := range params.Attachments {
+		if strings.TrimSpace(a.Content) == "" {
+			continue
 		}
+		attachments = append(attachments, wfmodel.TextAttachment{
+			Name:    a.Name,
+			Content: a.Content,
+		})
 	}
 
 	return &wfmodel.FoundationGenerateInput{
@@ -459,14 +671,25 @@ func buildFoundationInput(project *entity.Project, params map[string]interface{}
 		ProjectDescription: project.Description,
 		Prompt:             prompt,
 		Attachments:        attachments,
-		Provider:           strings.TrimSpace(provider),
-		Model:              strings.TrimSpace(modelName),
+		Provider:           strings.TrimSpace(params.Provider),
+		Model:              strings.TrimSpace(params.Model),
 		Temperature:        temperature,
-		MaxTokens:          maxTokens,
+		MaxTokens:          params.MaxTokens,
 	}, nil
 }
 
-func buildChapterInput(cfg *config.Config, project *entity.Project, chapter *entity.Chapter, params map[string]interface{}) (*wfmodel.ChapterGenerateInput, error) {
+// resolveTargetWordCount 解析目标字数：载荷未携带时依次回退到项目默认章节长度与 2000（与入队时的解析一致）
+func resolveTargetWordCount(project *entity.Project, requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if project.Settings != nil && project.Settings.DefaultChapterLength > 0 {
+		return project.Settings.DefaultChapterLength
+	}
+	return 2000
+}
+
+func buildChapterInput(cfg *config.Config, project *entity.Project, chapter *entity.Chapter, params *messaging.ChapterGenParams) (*wfmodel.ChapterGenerateInput, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is nil")
 	}
@@ -477,8 +700,7 @@ func buildChapterInput(cfg *config.Config, project *entity.Project, chapter *ent
 		return nil, fmt.Errorf("chapter is nil")
 	}
 
-	outline, _ := params["outline"].(string)
-	outline = strings.TrimSpace(outline)
+	outline := strings.TrimSpace(params.Outline)
 	if outline == "" {
 		outline = strings.TrimSpace(chapter.Outline)
 	}
@@ -486,40 +708,24 @@ func buildChapterInput(cfg *config.Config, project *entity.Project, chapter *ent
 		return nil, fmt.Errorf("missing outline")
 	}
 
-	targetWordCount := 0
-	if v, ok := params["target_word_count"].(float64); ok {
-		targetWordCount = int(v)
-	}
-	if targetWordCount <= 0 {
-		if project.Settings != nil && project.Settings.DefaultChapterLength > 0 {
-			targetWordCount = project.Settings.DefaultChapterLength
-		} else {
-			targetWordCount = 2000
-		}
-	}
+	targetWordCount := resolveTargetWordCount(project, params.TargetWordCount)
 
-	provider, _ := params["provider"].(string)
-	modelName, _ := params["model"].(string)
-	provider, modelName, err := resolveProviderModelForWorker(cfg, provider, modelName)
+	provider, modelName, err := resolveProviderModelForWorker(cfg, params.Provider, params.Model)
 	if err != nil {
 		return nil, err
 	}
 
 	var temperature *float32
-	if v, ok := params["temperature"].(float64); ok && v != 0 {
-		t := float32(v)
+	if params.Temperature != nil && *params.Temperature != 0 {
+		t := float32(*params.Temperature)
 		temperature = &t
 	} else if project.Settings != nil && project.Settings.Temperature != 0 {
 		t := float32(project.Settings.Temperature)
 		temperature = &t
 	}
 
-	writingStyle := ""
-	pov := ""
-	if project.Settings != nil {
-		writingStyle = strings.TrimSpace(project.Settings.WritingStyle)
-		pov = strings.TrimSpace(project.Settings.POV)
-	}
+	// 多 POV：章节指定 POV 角色时优先使用该角色的专属风格
+	writingStyle, pov := project.Settings.ResolveStyle(chapter.POVEntity())
 
 	return &wfmodel.ChapterGenerateInput{
 		ProjectTitle:       project.Title,
@@ -535,6 +741,46 @@ func buildChapterInput(cfg *config.Config, project *entity.Project, chapter *ent
 	}, nil
 }
 
+// chapterCandidateCount 读取任务的候选数（入队时已按 story.best_of_n 上限与预算折算；旧消息缺省为 1）
+func chapterCandidateCount(params *messaging.ChapterGenParams) int {
+	n := 1
+	if params.Candidates > 1 {
+		n = params.Candidates
+	}
+	if n > appstory.MaxCandidatesPerRequest {
+		n = appstory.MaxCandidatesPerRequest
+	}
+	return n
+}
+
+// screenJobPayload 在处理器入口处理载荷解析结果：
+// 信封无法解析（无法定位任务）或结构版本高于当前 Worker 时返回 retryErr，交由消费者重试（最终进入死信队列）；
+// 参数校验失败或版本已不再兼容时返回 invalid，由调用方将任务标记为失败且不再重试；未知字段仅记录告警。
+func screenJobPayload(ctx context.Context, payload *messaging.DecodedJob, err error) (invalid error, retryErr error) {
+	retryable := err != nil && !messaging.IsPayloadError(err)
+	if ve, ok := messaging.AsUnsupportedVersion(err); ok && !ve.Newer() {
+		retryable = false
+	}
+	if payload == nil || retryable {
+		if err != nil {
+			logger.Warn(ctx, "rejected job payload", "error", err.Error())
+		}
+		return nil, err
+	}
+	if len(payload.UnknownFields) > 0 {
+		logger.Warn(ctx, "job payload has unknown fields",
+			"job_id", payload.JobID,
+			"job_type", payload.JobType,
+			"schema_version", payload.SchemaVersion,
+			"fields", strings.Join(payload.UnknownFields, ","))
+	}
+	if err != nil {
+		logger.Warn(ctx, "invalid job payload", "error", err.Error(), "job_id", payload.JobID)
+		return &appstory.InvalidJobPayloadError{Err: err}, nil
+	}
+	return nil, nil
+}
+
 func resolveProviderModelForWorker(cfg *config.Config, provider, modelName string) (string, string, error) {
 	if cfg == nil {
 		return "", "", fmt.Errorf("config is nil")
@@ -566,18 +812,3 @@ func resolveProviderModelForWorker(cfg *config.Config, provider, modelName strin
 
 	return p, m, nil
 }
-
-func markChapterDraft(ctx context.Context, chapterRepo *postgres.ChapterRepository, chapterID string) error {
-	if strings.TrimSpace(chapterID) == "" {
-		return nil
-	}
-	chapter, err := chapterRepo.GetByID(ctx, chapterID)
-	if err != nil || chapter == nil {
-		return err
-	}
-	if chapter.Status == entity.ChapterStatusGenerating {
-		chapter.Status = entity.ChapterStatusDraft
-		return chapterRepo.Update(ctx, chapter)
-	}
-	return nil
-}
@@ -4,54 +4,31 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"google.golang.org/grpc"
 
 	memoryv1 "z-novel-ai-api/api/proto/gen/go/memory"
-	"z-novel-ai-api/internal/config"
-	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
 	grpcserver "z-novel-ai-api/internal/interfaces/grpc/server"
-	"z-novel-ai-api/pkg/logger"
-	"z-novel-ai-api/pkg/tracer"
+	"z-novel-ai-api/internal/wire"
+	"z-novel-ai-api/pkg/runtime"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
-	}
-
-	logger.Init(cfg.Observability.Logging.Level, cfg.Observability.Logging.Format)
-	ctx := context.Background()
-
-	shutdown, err := tracer.Init(ctx, tracer.Config{
+	runtime.Run(context.Background(), runtime.Options{
 		ServiceName: "memory-svc",
-		Endpoint:    cfg.Observability.Tracing.Endpoint,
-		SampleRate:  cfg.Observability.Tracing.SampleRate,
-		Enabled:     cfg.Observability.Tracing.Enabled,
+		Init: func(ctx context.Context, app *runtime.App) error {
+			svc, cleanup, err := wire.InitializeMemoryService(ctx, app.Config)
+			if err != nil {
+				return fmt.Errorf("failed to initialize memory service: %w", err)
+			}
+			app.Defer(cleanup)
+
+			app.Go(func(ctx context.Context) error {
+				return grpcserver.Run(ctx, app.Config, func(s *grpc.Server) {
+					memoryv1.RegisterMemoryServiceServer(s, svc)
+				})
+			})
+			return nil
+		},
 	})
-	if err != nil {
-		logger.Fatal(ctx, "failed to init tracer", err)
-	}
-	defer func() {
-		_ = shutdown(ctx)
-	}()
-
-	pgClient, err := postgres.NewClient(&cfg.Database.Postgres)
-	if err != nil {
-		logger.Fatal(ctx, "failed to init postgres", err)
-	}
-	defer func() { _ = pgClient.Close() }()
-
-	txMgr := postgres.NewTxManager(pgClient)
-	tenantCtx := postgres.NewTenantContext(pgClient)
-	entityRepo := postgres.NewEntityRepository(pgClient)
-
-	if err := grpcserver.Run(ctx, cfg, func(s *grpc.Server) {
-		memoryv1.RegisterMemoryServiceServer(s, grpcserver.NewMemoryService(txMgr, tenantCtx, entityRepo))
-	}); err != nil {
-		logger.Fatal(ctx, "grpc server exited", err)
-	}
 }
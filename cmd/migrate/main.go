@@ -0,0 +1,188 @@
+// Package main 数据库迁移命令（迁移 SQL 已嵌入二进制）
+//
+// 用法：
+//
+//	migrate up [N]          执行全部（或最多 N 个）待执行迁移
+//	migrate down [N]        回滚最近 N 个迁移（默认 1）
+//	migrate status [-check] 输出迁移状态；-check 时存在待执行迁移或 dirty 状态则以非零状态退出（用于 CI）
+//	migrate force V         强制设置版本并清除 dirty 标记（人工修复失败迁移后使用）
+//	migrate create NAME     在 migrations/postgres 下创建下一序号的 up/down 文件
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
+	"z-novel-ai-api/migrations"
+)
+
+const migrationsDir = "migrations/postgres"
+
+func main() {
+	_ = godotenv.Load()
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	if cmd == "create" {
+		if len(args) != 1 {
+			fail("usage: migrate create NAME")
+		}
+		if err := create(args[0]); err != nil {
+			fail(err.Error())
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail(fmt.Sprintf("failed to load config: %v", err))
+	}
+	client, err := postgres.NewClient(&cfg.Database.Postgres)
+	if err != nil {
+		fail(err.Error())
+	}
+	defer client.Close()
+
+	migrator, err := postgres.NewMigrator(client, migrations.Postgres())
+	if err != nil {
+		fail(err.Error())
+	}
+
+	ctx := context.Background()
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx, optionalCount(args, 0))
+		for _, m := range applied {
+			fmt.Printf("applied  %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fail(err.Error())
+		}
+		if len(applied) == 0 {
+			fmt.Println("no change")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, optionalCount(args, 1))
+		for _, m := range reverted {
+			fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fail(err.Error())
+		}
+	case "status":
+		fs := flag.NewFlagSet("status", flag.ExitOnError)
+		check := fs.Bool("check", false, "exit non-zero when migrations are pending or dirty")
+		_ = fs.Parse(args)
+		os.Exit(status(ctx, migrator, *check))
+	case "force":
+		if len(args) != 1 {
+			fail("usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			fail("invalid version: " + args[0])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			fail(err.Error())
+		}
+		fmt.Printf("forced version %d\n", version)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// status 逐行输出迁移状态（applied / pending），便于 CI 日志与 grep
+func status(ctx context.Context, migrator *postgres.Migrator, check bool) int {
+	st, err := migrator.Status(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, m := range st.Applied {
+		state := "applied"
+		if st.Dirty && m.Version == st.Version {
+			state = "dirty"
+		}
+		fmt.Printf("%-8s %06d_%s\n", state, m.Version, m.Name)
+	}
+	for _, m := range st.Pending {
+		fmt.Printf("%-8s %06d_%s\n", "pending", m.Version, m.Name)
+	}
+	fmt.Printf("version=%d dirty=%t pending=%d\n", st.Version, st.Dirty, len(st.Pending))
+
+	if check && (st.Dirty || len(st.Pending) > 0) {
+		return 1
+	}
+	return 0
+}
+
+var createNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// create 按当前最大序号 +1 创建空迁移文件
+func create(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !createNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	var next uint64 = 1
+	entries, err := os.ReadDir(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", migrationsDir, err)
+	}
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && v >= next {
+			next = v + 1
+		}
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		file := fmt.Sprintf("%06d_%s.%s.sql", next, name, direction)
+		header := fmt.Sprintf("-- %s\n", file)
+		if err := os.WriteFile(filepath.Join(migrationsDir, file), []byte(header), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", filepath.Join(migrationsDir, file))
+	}
+	return nil
+}
+
+func optionalCount(args []string, def int) int {
+	if len(args) == 0 {
+		return def
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		fail("invalid count: " + args[0])
+	}
+	return n
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate <up [N] | down [N] | status [-check] | force VERSION | create NAME>")
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
@@ -0,0 +1,369 @@
+// Command seed-demo 创建演示租户与一个完整的示例项目（设定构件、角色、大纲、已生成章节与向量索引），
+// 无需任何 LLM 密钥即可体验前端与检索链路。向量由本地确定性 Embedder 生成，
+// 若要在 API 网关中检索这些向量，需同样配置 embedding.provider=fake。
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/application/story/foundation"
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	infraembedding "z-novel-ai-api/internal/infrastructure/embedding"
+	"z-novel-ai-api/internal/infrastructure/persistence/milvus"
+	"z-novel-ai-api/internal/wire"
+)
+
+//go:embed demo_project.json
+var demoProjectJSON []byte
+
+// demoProject 演示项目数据
+type demoProject struct {
+	Title       string                    `json:"title"`
+	Description string                    `json:"description"`
+	Plan        storymodel.FoundationPlan `json:"plan"`
+	Chapters    []demoChapter             `json:"chapters"`
+}
+
+// demoChapter 预置的“已生成”章节正文
+type demoChapter struct {
+	Key     string `json:"key"`
+	POVKey  string `json:"pov_key"`
+	Summary string `json:"summary"`
+	Content string `json:"content"`
+}
+
+// seededArtifact 已写入的构件版本（事务提交后用于写索引）
+type seededArtifact struct {
+	artifactType entity.ArtifactType
+	artifactID   string
+	content      json.RawMessage
+}
+
+func main() {
+	tenantSlug := flag.String("tenant", "demo", "demo tenant slug")
+	email := flag.String("email", "demo@example.com", "demo user email")
+	skipVectors := flag.Bool("skip-vectors", false, "do not write vector index to Milvus")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	var demo demoProject
+	if err := json.Unmarshal(demoProjectJSON, &demo); err != nil {
+		log.Fatalf("failed to parse demo project: %v", err)
+	}
+
+	ctx := context.Background()
+
+	dataLayer, cleanup, err := wire.InitializePostgresOnly(ctx, cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize data layer: %v", err)
+	}
+	defer cleanup()
+
+	// 1. 演示租户与用户
+	tenant, err := ensureTenant(ctx, dataLayer, *tenantSlug)
+	if err != nil {
+		log.Fatalf("failed to ensure demo tenant: %v", err)
+	}
+	password := os.Getenv("SEED_DEMO_PASSWORD")
+	if password == "" {
+		password = "demo123" // 仅用于本地演示
+	}
+	user, err := ensureUser(ctx, dataLayer, tenant.ID, *email, password)
+	if err != nil {
+		log.Fatalf("failed to ensure demo user: %v", err)
+	}
+
+	// 2. 项目、设定构件与章节（同一事务内写入）
+	var (
+		project   *entity.Project
+		artifacts []seededArtifact
+		chapters  []*chapterIndex
+	)
+	err = dataLayer.TxManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := dataLayer.TenantContext.SetTenant(txCtx, tenant.ID); err != nil {
+			return err
+		}
+
+		existing, err := findProject(txCtx, dataLayer.ProjectRepo, user.ID, demo.Title)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			project = existing
+			return errDemoExists
+		}
+
+		project, err = createProject(txCtx, dataLayer, tenant.ID, user.ID, &demo)
+		if err != nil {
+			return err
+		}
+		artifacts, err = createArtifacts(txCtx, dataLayer.ArtifactRepo, tenant.ID, project.ID, user.ID, &demo)
+		if err != nil {
+			return err
+		}
+		chapters, err = fillChapters(txCtx, dataLayer, project.ID, &demo)
+		return err
+	})
+	if errors.Is(err, errDemoExists) {
+		fmt.Printf("Demo project already exists (tenant=%s project=%s); delete it to reseed.\n", tenant.ID, project.ID)
+		return
+	}
+	if err != nil {
+		log.Fatalf("failed to seed demo project: %v", err)
+	}
+	fmt.Printf("Demo project created: %s (%s)\n", project.Title, project.ID)
+
+	// 3. 向量索引（事务提交后写入，Milvus 不可用时跳过）
+	if *skipVectors {
+		fmt.Println("Skipping vector index.")
+	} else if err := indexDemo(ctx, cfg, tenant.ID, project.ID, artifacts, chapters); err != nil {
+		fmt.Printf("Vector index skipped: %v\n", err)
+	} else {
+		fmt.Println("Vector index written with the fake embedder; set embedding.provider=fake on the API gateway to query it.")
+	}
+
+	fmt.Printf("Seed completed. Login: tenant=%s email=%s\n", tenant.Slug, *email)
+}
+
+var errDemoExists = errors.New("demo project already exists")
+
+// chapterIndex 写索引所需的章节信息
+type chapterIndex struct {
+	chapter          *entity.Chapter
+	narrativePos     int64
+	involvedEntities []string
+}
+
+func ensureTenant(ctx context.Context, dl *wire.PostgresOnlyDataLayer, slug string) (*entity.Tenant, error) {
+	tenant, err := dl.TenantRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != nil {
+		fmt.Printf("Demo tenant already exists with ID: %s\n", tenant.ID)
+		return tenant, nil
+	}
+	tenant = entity.NewTenant("Demo Tenant", slug)
+	if err := dl.TenantRepo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Demo tenant created with ID: %s\n", tenant.ID)
+	return tenant, nil
+}
+
+func ensureUser(ctx context.Context, dl *wire.PostgresOnlyDataLayer, tenantID, email, password string) (*entity.User, error) {
+	user, err := dl.UserRepo.GetByEmail(ctx, tenantID, email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+	user = entity.NewUser(tenantID, email, "Demo User")
+	user.Role = entity.UserRoleAdmin
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+	if err := dl.UserRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Demo user created: %s\n", email)
+	return user, nil
+}
+
+func findProject(ctx context.Context, repo repository.ProjectRepository, ownerID, title string) (*entity.Project, error) {
+	result, err := repo.ListByOwner(ctx, ownerID, repository.NewPagination(1, 100))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range result.Items {
+		if p != nil && p.Title == title {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// createProject 创建项目并通过 FoundationApplier 落库实体、关系、分卷与章节大纲
+func createProject(ctx context.Context, dl *wire.PostgresOnlyDataLayer, tenantID, ownerID string, demo *demoProject) (*entity.Project, error) {
+	project := entity.NewProject(tenantID, ownerID, demo.Title)
+	project.Description = demo.Description
+	project.Status = entity.ProjectStatusWriting
+	if err := dl.ProjectRepo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+
+	applier := foundation.NewFoundationApplier(dl.ProjectRepo, dl.EntityRepo, dl.RelationRepo, dl.VolumeRepo, dl.ChapterRepo, nil, nil, nil)
+	result, err := applier.Apply(ctx, project.ID, &demo.Plan)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Foundation applied: %d entities, %d relations, %d volumes, %d chapters\n",
+		result.EntitiesCreated, result.RelationsCreated, result.VolumesCreated, result.ChaptersCreated)
+
+	return dl.ProjectRepo.GetByID(ctx, project.ID)
+}
+
+// createArtifacts 写入与 FoundationPlan 一致的设定构件并激活
+func createArtifacts(ctx context.Context, repo repository.ArtifactRepository, tenantID, projectID, userID string, demo *demoProject) ([]seededArtifact, error) {
+	plan := demo.Plan
+	nf := &artifact.NovelFoundationArtifact{Title: demo.Title, Description: demo.Description, Genre: plan.Project.Genre}
+	wv := &artifact.WorldviewArtifact{
+		Genre:           plan.Project.Genre,
+		TargetWordCount: plan.Project.TargetWordCount,
+		WritingStyle:    plan.Project.WritingStyle,
+		POV:             plan.Project.POV,
+		Temperature:     plan.Project.Temperature,
+		WorldSettings:   plan.Project.WorldSettings,
+		WorldBible:      plan.Project.WorldBible,
+	}
+	ch := &artifact.CharactersArtifact{Entities: plan.Entities, Relations: plan.Relations}
+	ol := &artifact.OutlineArtifact{Volumes: plan.Volumes}
+	if err := errors.Join(
+		artifact.ValidateNovelFoundationArtifact(nf),
+		artifact.ValidateWorldviewArtifact(wv),
+		artifact.ValidateCharactersArtifact(ch),
+		artifact.ValidateOutlineArtifact(ol),
+	); err != nil {
+		return nil, err
+	}
+
+	contents := []struct {
+		artifactType entity.ArtifactType
+		value        any
+	}{
+		{entity.ArtifactTypeNovelFoundation, nf},
+		{entity.ArtifactTypeWorldview, wv},
+		{entity.ArtifactTypeCharacters, ch},
+		{entity.ArtifactTypeOutline, ol},
+	}
+
+	out := make([]seededArtifact, 0, len(contents))
+	for _, c := range contents {
+		raw, err := json.Marshal(c.value)
+		if err != nil {
+			return nil, err
+		}
+		art, err := repo.EnsureArtifact(ctx, tenantID, projectID, c.artifactType)
+		if err != nil {
+			return nil, err
+		}
+		createdBy := userID
+		version := &entity.ArtifactVersion{
+			ID:         uuid.NewString(),
+			ArtifactID: art.ID,
+			VersionNo:  1,
+			BranchKey:  "main",
+			Content:    raw,
+			CreatedBy:  &createdBy,
+		}
+		if err := repo.CreateVersion(ctx, version); err != nil {
+			return nil, err
+		}
+		if err := repo.SetActiveVersion(ctx, art.ID, version.ID); err != nil {
+			return nil, err
+		}
+		out = append(out, seededArtifact{artifactType: c.artifactType, artifactID: art.ID, content: raw})
+	}
+	return out, nil
+}
+
+// fillChapters 为预置章节写入正文，模拟已完成的生成结果
+func fillChapters(ctx context.Context, dl *wire.PostgresOnlyDataLayer, projectID string, demo *demoProject) ([]*chapterIndex, error) {
+	out := make([]*chapterIndex, 0, len(demo.Chapters))
+	totalWords := 0
+	for _, dc := range demo.Chapters {
+		chapter, err := dl.ChapterRepo.GetByAIKey(ctx, projectID, dc.Key)
+		if err != nil {
+			return nil, err
+		}
+		if chapter == nil {
+			return nil, fmt.Errorf("demo chapter not found: %s", dc.Key)
+		}
+
+		var involved []string
+		if key := strings.TrimSpace(dc.POVKey); key != "" {
+			pov, err := dl.EntityRepo.GetByAIKey(ctx, projectID, key)
+			if err != nil {
+				return nil, err
+			}
+			if pov != nil {
+				chapter.POVEntityID = &pov.ID
+				involved = append(involved, pov.ID)
+			}
+		}
+
+		chapter.SetContent(dc.Content)
+		chapter.Summary = dc.Summary
+		chapter.StoryTimeEnd = chapter.StoryTimeStart
+		chapter.Status = entity.ChapterStatusCompleted
+		chapter.GenerationMetadata = &entity.GenerationMetadata{
+			Provider:         "demo",
+			Model:            "seed-demo",
+			CompletionTokens: chapter.WordCount,
+			GeneratedAt:      time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := dl.ChapterRepo.Update(ctx, chapter); err != nil {
+			return nil, err
+		}
+		totalWords += chapter.WordCount
+
+		pos, err := dl.ChapterRepo.GetNarrativePosition(ctx, chapter.ID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &chapterIndex{chapter: chapter, narrativePos: pos, involvedEntities: involved})
+	}
+	if err := dl.ProjectRepo.UpdateWordCount(ctx, projectID, totalWords); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// indexDemo 使用本地确定性 Embedder 写入构件与章节的向量索引
+func indexDemo(ctx context.Context, cfg *config.Config, tenantID, projectID string, artifacts []seededArtifact, chapters []*chapterIndex) error {
+	client, err := milvus.NewClient(ctx, &cfg.Vector.Milvus)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	vectorRepo := milvus.NewRetrievalVectorRepository(milvus.NewRepository(client))
+	indexer := retrieval.NewIndexer(infraembedding.NewFakeEmbedder(cfg.Embedding.Dimension), vectorRepo, cfg.Embedding.BatchSize)
+
+	for _, a := range artifacts {
+		if err := indexer.IndexArtifactJSON(ctx, tenantID, projectID, a.artifactType, a.artifactID, a.content); err != nil {
+			return fmt.Errorf("index artifact %s: %w", a.artifactType, err)
+		}
+	}
+	for _, c := range chapters {
+		opts := retrieval.ChapterIndexOptions{NarrativePos: c.narrativePos, InvolvedEntities: c.involvedEntities}
+		if err := indexer.IndexChapter(ctx, tenantID, projectID, c.chapter, opts); err != nil {
+			return fmt.Errorf("index chapter %s: %w", c.chapter.ID, err)
+		}
+	}
+	return nil
+}
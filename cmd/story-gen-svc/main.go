@@ -4,43 +4,32 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"google.golang.org/grpc"
 
 	storyv1 "z-novel-ai-api/api/proto/gen/go/story"
-	"z-novel-ai-api/internal/config"
 	grpcserver "z-novel-ai-api/internal/interfaces/grpc/server"
-	"z-novel-ai-api/pkg/logger"
-	"z-novel-ai-api/pkg/tracer"
+	"z-novel-ai-api/internal/wire"
+	"z-novel-ai-api/pkg/runtime"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		os.Exit(1)
-	}
-
-	logger.Init(cfg.Observability.Logging.Level, cfg.Observability.Logging.Format)
-	ctx := context.Background()
-
-	shutdown, err := tracer.Init(ctx, tracer.Config{
+	runtime.Run(context.Background(), runtime.Options{
 		ServiceName: "story-gen-svc",
-		Endpoint:    cfg.Observability.Tracing.Endpoint,
-		SampleRate:  cfg.Observability.Tracing.SampleRate,
-		Enabled:     cfg.Observability.Tracing.Enabled,
-	})
-	if err != nil {
-		logger.Fatal(ctx, "failed to init tracer", err)
-	}
-	defer func() {
-		_ = shutdown(ctx)
-	}()
+		Init: func(ctx context.Context, app *runtime.App) error {
+			// 仅指标/追踪/日志：生成服务不访问数据库，Token 扣费由网关按返回的用量结算
+			svc, cleanup, err := wire.InitializeStoryGenService(ctx, app.Config)
+			if err != nil {
+				return fmt.Errorf("failed to initialize story gen service: %w", err)
+			}
+			app.Defer(cleanup)
 
-	if err := grpcserver.Run(ctx, cfg, func(s *grpc.Server) {
-		storyv1.RegisterStoryGenServiceServer(s, &grpcserver.StoryGenService{})
-	}); err != nil {
-		logger.Fatal(ctx, "grpc server exited", err)
-	}
+			app.Go(func(ctx context.Context) error {
+				return grpcserver.Run(ctx, app.Config, func(s *grpc.Server) {
+					storyv1.RegisterStoryGenServiceServer(s, svc)
+				})
+			})
+			return nil
+		},
+	})
 }
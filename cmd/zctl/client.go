@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+// defaultTimeout 单次请求默认超时
+const defaultTimeout = 30 * time.Second
+
+// globalOptions 全局连接与输出参数
+type globalOptions struct {
+	apiURL  string
+	token   string
+	timeout time.Duration
+	json    bool
+}
+
+// apiClient 管理 API 客户端（统一解析 dto.Response / dto.ErrorResponse 信封）
+type apiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	json    bool
+}
+
+func (o *globalOptions) client() (*apiClient, error) {
+	if strings.TrimSpace(o.token) == "" {
+		return nil, errors.New("access token is required (--token or ZCTL_TOKEN)")
+	}
+	return &apiClient{
+		baseURL: strings.TrimRight(o.apiURL, "/"),
+		token:   o.token,
+		http:    &http.Client{Timeout: o.timeout},
+		json:    o.json,
+	}, nil
+}
+
+// envelope 成功响应信封（data 延迟解析）
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// request 发起请求并将 data 解析到 out（out 为 nil 时忽略响应体）；headers 为可选的额外请求头
+func (c *apiClient) request(ctx context.Context, method, path string, body, out any, headers map[string]string) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var e dto.ErrorResponse
+		if json.Unmarshal(raw, &e) == nil && e.Message != "" {
+			msg := fmt.Sprintf("%s %s: %d %s", method, path, resp.StatusCode, e.Message)
+			if e.Error != nil && e.Error.Details != "" {
+				msg += " (" + e.Error.Details + ")"
+			}
+			return errors.New(msg)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if raw, ok := out.(*json.RawMessage); ok {
+		*raw = env.Data
+		return nil
+	}
+	return json.Unmarshal(env.Data, out)
+}
+
+func (c *apiClient) get(ctx context.Context, path string, out any) error {
+	return c.request(ctx, http.MethodGet, path, nil, out, nil)
+}
+
+func (c *apiClient) post(ctx context.Context, path string, body, out any) error {
+	return c.request(ctx, http.MethodPost, path, body, out, nil)
+}
+
+// printJSON 以缩进 JSON 输出
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
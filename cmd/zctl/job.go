@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+func newJobCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Generation and maintenance jobs",
+	}
+	cmd.AddCommand(newJobCancelCmd(opts), newJobTranscriptCmd(opts))
+	return cmd
+}
+
+func newJobCancelCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel JOB_ID",
+		Short: "Cancel a pending or running job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var resp dto.CancelJobResponse
+			if err := client.request(cmd.Context(), http.MethodDelete, "/v1/jobs/"+url.PathEscape(args[0]), nil, &resp, nil); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(resp)
+			}
+			fmt.Printf("job %s cancelled\n", resp.ID)
+			return nil
+		},
+	}
+}
+
+// jobTranscript 任务详情 + 时间线 + 候选结果
+type jobTranscript struct {
+	Job        *dto.JobResponse         `json:"job"`
+	Events     []*dto.JobEventResponse  `json:"events"`
+	Candidates []*dto.CandidateResponse `json:"candidates,omitempty"`
+}
+
+func newJobTranscriptCmd(opts *globalOptions) *cobra.Command {
+	var withContent bool
+	cmd := &cobra.Command{
+		Use:   "transcript JOB_ID",
+		Short: "Dump a job's details, timeline and candidates",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			base := "/v1/jobs/" + url.PathEscape(args[0])
+
+			var t jobTranscript
+			if err := client.get(ctx, base, &t.Job); err != nil {
+				return err
+			}
+			var events dto.JobEventListResponse
+			if err := client.get(ctx, base+"/events", &events); err != nil {
+				return err
+			}
+			t.Events = events.Events
+			var candidates dto.CandidateListResponse
+			if err := client.get(ctx, base+"/candidates", &candidates); err != nil {
+				return err
+			}
+			t.Candidates = candidates.Candidates
+
+			if client.json {
+				return printJSON(t)
+			}
+			printTranscript(&t, withContent)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&withContent, "content", false, "include full candidate content")
+	return cmd
+}
+
+func printTranscript(t *jobTranscript, withContent bool) {
+	j := t.Job
+	fmt.Printf("Job       %s\n", j.ID)
+	fmt.Printf("Type      %s (%s)\n", j.JobType, j.Category)
+	status := j.Status
+	if j.ErrorCode != "" {
+		status += " / " + j.ErrorCode
+	}
+	fmt.Printf("Status    %s  progress %d%%  retries %d\n", status, j.Progress, j.RetryCount)
+	fmt.Printf("Project   %s\n", j.ProjectID)
+	if j.ChapterID != nil {
+		fmt.Printf("Chapter   %s\n", *j.ChapterID)
+	}
+	if j.LLMProvider != "" || j.LLMModel != "" {
+		fmt.Printf("Model     %s/%s  tokens %d+%d  %dms\n", j.LLMProvider, j.LLMModel, j.TokensPrompt, j.TokensCompletion, j.DurationMs)
+	}
+	fmt.Printf("Created   %s  started %s  completed %s\n", formatTime(j.CreatedAt), formatTime(j.StartedAt), formatTime(j.CompletedAt))
+	if j.ErrorMsg != "" {
+		fmt.Printf("Error     %s\n", j.ErrorMsg)
+	}
+	for _, w := range j.Warnings {
+		fmt.Printf("Warning   [%s] %s\n", w.Code, w.Message)
+	}
+
+	fmt.Println("\nTimeline")
+	for _, ev := range t.Events {
+		line := fmt.Sprintf("  %s  %-18s %s", formatTime(ev.CreatedAt), ev.Type, ev.Message)
+		if len(ev.Data) > 0 {
+			data, _ := json.Marshal(ev.Data)
+			line += "  " + string(data)
+		}
+		fmt.Println(line)
+	}
+
+	if len(t.Candidates) > 0 {
+		fmt.Println("\nCandidates")
+		for _, c := range t.Candidates {
+			fmt.Printf("  #%d  %-9s score %.3f  %s/%s  tokens %d+%d\n",
+				c.CandidateNo, c.Status, c.Score, c.Provider, c.Model, c.PromptTokens, c.CompletionTokens)
+			if withContent {
+				content := c.Content
+				if content == "" && len(c.ArtifactContent) > 0 {
+					content = string(c.ArtifactContent)
+				}
+				fmt.Println(indent(content, "      "))
+			}
+		}
+	}
+
+	if len(j.Result) > 0 {
+		result, _ := json.MarshalIndent(j.Result, "", "  ")
+		fmt.Println("\nResult")
+		fmt.Println(indent(string(result), "  "))
+	}
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prefix)
+}
@@ -0,0 +1,67 @@
+// Package main zctl 运维命令行：封装管理类 HTTP API，覆盖常见运维操作。
+//
+// 用法：
+//
+//	zctl queue list                          查看各消息队列积压与死信数量
+//	zctl queue dlq QUEUE [--limit N]         列出死信消息
+//	zctl queue requeue QUEUE (ID... | --all) 死信消息重新入队
+//	zctl project reindex PROJECT_ID          提交向量索引重建任务
+//	zctl project recount PROJECT_ID          提交项目统计重算任务（字数/卷汇总/实体出场/向量用量）
+//	zctl tenant balance TENANT_ID --delta N --reason TEXT  调整租户 Token 余额
+//	zctl job cancel JOB_ID                   取消任务
+//	zctl job transcript JOB_ID               输出任务详情、时间线与候选结果
+//
+// 连接参数：--api-url（或 ZCTL_API_URL，默认 http://localhost:8080）与 --token（或 ZCTL_TOKEN，admin 用户的访问令牌）。
+// 任务、项目类操作作用于令牌所属租户；--json 输出接口原始数据。
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Version 版本信息，构建时注入
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	opts := &globalOptions{}
+	root := &cobra.Command{
+		Use:           "zctl",
+		Short:         "z-novel-ai operations CLI",
+		Version:       Version + " (" + BuildTime + ")",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.apiURL, "api-url", envOr("ZCTL_API_URL", "http://localhost:8080"), "API gateway base URL (env ZCTL_API_URL)")
+	flags.StringVar(&opts.token, "token", os.Getenv("ZCTL_TOKEN"), "admin access token (env ZCTL_TOKEN)")
+	flags.DurationVar(&opts.timeout, "timeout", defaultTimeout, "request timeout")
+	flags.BoolVar(&opts.json, "json", false, "print raw JSON response data")
+
+	root.AddCommand(
+		newQueueCmd(opts),
+		newProjectCmd(opts),
+		newTenantCmd(opts),
+		newJobCmd(opts),
+	)
+	return root
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
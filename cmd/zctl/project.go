@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+func newProjectCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Project maintenance",
+	}
+	cmd.AddCommand(newProjectReindexCmd(opts), newProjectRecountCmd(opts), newProjectVectorsCmd(opts))
+	return cmd
+}
+
+func newProjectReindexCmd(opts *globalOptions) *cobra.Command {
+	var idempotencyKey string
+	cmd := &cobra.Command{
+		Use:   "reindex PROJECT_ID",
+		Short: "Queue a vector index rebuild for a project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var headers map[string]string
+			if idempotencyKey != "" {
+				headers = map[string]string{"Idempotency-Key": idempotencyKey}
+			}
+			path := fmt.Sprintf("/v1/projects/%s/jobs", url.PathEscape(args[0]))
+			var job dto.JobResponse
+			body := &dto.CreateJobRequest{JobType: string(entity.JobTypeIndexRebuild)}
+			if err := client.request(cmd.Context(), http.MethodPost, path, body, &job, headers); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(job)
+			}
+			fmt.Printf("index rebuild queued: job %s (%s)\n", job.ID, job.Status)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "deduplicate repeated submissions")
+	return cmd
+}
+
+func newProjectRecountCmd(opts *globalOptions) *cobra.Command {
+	var idempotencyKey string
+	cmd := &cobra.Command{
+		Use:   "recount PROJECT_ID",
+		Short: "Queue a rebuild of a project's word counts, volume rollups, entity appearances and vector usage",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var headers map[string]string
+			if idempotencyKey != "" {
+				headers = map[string]string{"Idempotency-Key": idempotencyKey}
+			}
+			path := fmt.Sprintf("/v1/admin/projects/%s/recount", url.PathEscape(args[0]))
+			var job dto.JobResponse
+			if err := client.request(cmd.Context(), http.MethodPost, path, nil, &job, headers); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(job)
+			}
+			fmt.Printf("project recount queued: job %s (%s); see `zctl job transcript %s` for the before/after report\n", job.ID, job.Status, job.ID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&idempotencyKey, "idempotency-key", "", "deduplicate repeated submissions")
+	return cmd
+}
+
+func newProjectVectorsCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "vectors PROJECT_ID",
+		Short: "Count a project's indexed vector segments by segment type",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/v1/projects/%s/vectors/stats", url.PathEscape(args[0]))
+			var stats dto.VectorSegmentStatsResponse
+			if err := client.get(cmd.Context(), path, &stats); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(stats)
+			}
+
+			types := make([]string, 0, len(stats.ByType))
+			for t := range stats.ByType {
+				types = append(types, t)
+			}
+			sort.Strings(types)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SEGMENT_TYPE\tCOUNT")
+			for _, t := range types {
+				fmt.Fprintf(w, "%s\t%d\n", t, stats.ByType[t])
+			}
+			fmt.Fprintf(w, "total\t%d\n", stats.Total)
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+func newQueueCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspect message queues and dead-letter queues",
+	}
+	cmd.AddCommand(newQueueListCmd(opts), newQueueDLQCmd(opts), newQueueRequeueCmd(opts), newQueuePurgeCmd(opts), newQueuePendingCmd(opts))
+	return cmd
+}
+
+func newQueueListCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show backlog, pending and DLQ size of each queue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			var queues []*dto.OpsQueueResponse
+			if err := client.get(cmd.Context(), "/v1/ops/queues", &queues); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(queues)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "QUEUE\tLENGTH\tLAG\tPENDING\tCONSUMERS\tWORKERS\tAVG_JOB\tDLQ")
+			for _, q := range queues {
+				workers, avg := "-", "-"
+				if q.Workers != nil {
+					workers = strconv.Itoa(*q.Workers)
+				}
+				if q.AvgJobMillis != nil && *q.AvgJobMillis > 0 {
+					avg = fmt.Sprintf("%.1fs", float64(*q.AvgJobMillis)/1000)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%s\t%d\n",
+					q.Name, q.Length, q.Lag, q.Pending, q.Consumers, workers, avg, q.DLQLength)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newQueueDLQCmd(opts *globalOptions) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "dlq QUEUE [DLQ_ID]",
+		Short: "List dead-letter messages of a queue (story-gen / memory-update / audit-log), or show one with its payload",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			if len(args) == 2 {
+				path := fmt.Sprintf("/v1/ops/queues/%s/dlq/%s", url.PathEscape(args[0]), url.PathEscape(args[1]))
+				var entry dto.OpsDLQMessageDetailResponse
+				if err := client.get(cmd.Context(), path, &entry); err != nil {
+					return err
+				}
+				return printJSON(entry)
+			}
+			path := fmt.Sprintf("/v1/ops/queues/%s/dlq?limit=%d", url.PathEscape(args[0]), limit)
+			var entries []*dto.OpsDLQMessageResponse
+			if err := client.get(cmd.Context(), path, &entries); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(entries)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DLQ_ID\tFAILED_AT\tTYPE\tMESSAGE_ID\tTENANT\tRETRIES\tERROR")
+			for _, e := range entries {
+				failedAt := "-"
+				if e.FailedAt != nil {
+					failedAt = formatTime(*e.FailedAt)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", e.ID, failedAt, e.Type, e.MessageID, e.TenantID, e.RetryCount, e.Error)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 100, "maximum number of messages (max 1000)")
+	return cmd
+}
+
+func newQueueRequeueCmd(opts *globalOptions) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "requeue QUEUE [DLQ_ID...]",
+		Short: "Publish dead-letter messages back to their original queue",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := args[1:]
+			if len(ids) == 0 && !all {
+				return errors.New("specify DLQ message IDs or --all")
+			}
+			if len(ids) > 0 && all {
+				return errors.New("--all cannot be combined with message IDs")
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/v1/ops/queues/%s/dlq/requeue", url.PathEscape(args[0]))
+			var resp dto.RequeueDLQResponse
+			if err := client.post(cmd.Context(), path, &dto.RequeueDLQRequest{IDs: ids}, &resp); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(resp)
+			}
+			fmt.Printf("requeued %d message(s)\n", len(resp.Requeued))
+			for _, id := range resp.Requeued {
+				fmt.Println(" ", id)
+			}
+			if len(ids) > len(resp.Requeued) {
+				fmt.Fprintf(os.Stderr, "%d message(s) not found or not parseable\n", len(ids)-len(resp.Requeued))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "requeue all messages at the head of the DLQ (up to 1000)")
+	return cmd
+}
+
+func newQueuePurgeCmd(opts *globalOptions) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "purge QUEUE [DLQ_ID...]",
+		Short: "Delete dead-letter messages permanently",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := args[1:]
+			if len(ids) == 0 && !all {
+				return errors.New("specify DLQ message IDs or --all")
+			}
+			if len(ids) > 0 && all {
+				return errors.New("--all cannot be combined with message IDs")
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/v1/ops/queues/%s/dlq/purge", url.PathEscape(args[0]))
+			var resp dto.PurgeDLQResponse
+			if err := client.post(cmd.Context(), path, &dto.PurgeDLQRequest{IDs: ids}, &resp); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(resp)
+			}
+			fmt.Printf("purged %d message(s)\n", resp.Purged)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "delete every message in the DLQ")
+	return cmd
+}
+
+func newQueuePendingCmd(opts *globalOptions) *cobra.Command {
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "pending QUEUE",
+		Short: "List claimed but unacknowledged messages and their delivery counts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/v1/ops/queues/%s/pending?limit=%d", url.PathEscape(args[0]), limit)
+			var entries []*dto.OpsPendingMessageResponse
+			if err := client.get(cmd.Context(), path, &entries); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(entries)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "STREAM_ID\tCONSUMER\tIDLE\tRETRIES\tTYPE\tMESSAGE_ID\tTENANT")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%.1fs\t%d/%d\t%s\t%s\t%s\n",
+					e.ID, e.Consumer, float64(e.IdleMs)/1000, e.RetryCount, e.RetryLimit, e.Type, e.MessageID, e.TenantID)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 100, "maximum number of messages (max 1000)")
+	return cmd
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+func newTenantCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "Tenant administration",
+	}
+	cmd.AddCommand(newTenantBalanceCmd(opts))
+	return cmd
+}
+
+func newTenantBalanceCmd(opts *globalOptions) *cobra.Command {
+	var (
+		delta  int64
+		reason string
+	)
+	cmd := &cobra.Command{
+		Use:   "balance TENANT_ID --delta N --reason TEXT",
+		Short: "Credit (positive delta) or deduct (negative delta) a tenant's token balance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if delta == 0 {
+				return errors.New("--delta must be non-zero")
+			}
+			if strings.TrimSpace(reason) == "" {
+				return errors.New("--reason is required")
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			path := fmt.Sprintf("/v1/ops/tenants/%s/balance", url.PathEscape(args[0]))
+			var resp dto.AdjustBalanceResponse
+			if err := client.post(cmd.Context(), path, &dto.AdjustBalanceRequest{Delta: delta, Reason: reason}, &resp); err != nil {
+				return err
+			}
+			if client.json {
+				return printJSON(resp)
+			}
+			fmt.Printf("tenant %s balance adjusted by %+d, now %d\n", resp.TenantID, resp.Delta, resp.TokenBalance)
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&delta, "delta", 0, "token amount to add (negative to deduct)")
+	cmd.Flags().StringVar(&reason, "reason", "", "reason recorded with the adjustment")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package billing
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidSignature Webhook 签名校验失败（含时间戳超出容忍范围）
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrInvalidPayload Webhook 请求体无法解析或缺少必要字段
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// PaymentProvider 定义应用层对“支付提供商”的最小依赖（port）。
+// 由基础设施层提供具体实现（例如 Stripe、自建网关），自托管部署可按此接口接入自己的支付处理方。
+type PaymentProvider interface {
+	// Name 提供商标识（写入账单 provider 字段，与 external_id 组成幂等键）
+	Name() string
+	// ParseWebhook 校验签名并将回调解析为支付事件；与充值无关的事件返回 Succeeded=false 的事件
+	ParseWebhook(header http.Header, payload []byte) (*PaymentEvent, error)
+}
+
+// PaymentEvent 支付提供商回调事件（已完成验签）
+type PaymentEvent struct {
+	// EventID 提供商侧事件 ID
+	EventID string
+	// Type 提供商原始事件类型
+	Type string
+	// Succeeded 是否为已确认到账的充值事件；为 false 时事件被忽略
+	Succeeded bool
+
+	TenantID string
+	// ExternalID 提供商侧支付单号（同一笔支付的重复回调保持一致）
+	ExternalID string
+	// Amount 支付金额（最小货币单位，如分）
+	Amount   int64
+	Currency string
+	// Tokens 购买的 Token 数（0 表示按金额折算）
+	Tokens int64
+	PaidAt time.Time
+}
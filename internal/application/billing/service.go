@@ -0,0 +1,124 @@
+// Package billing 提供 Token 充值计费服务：处理支付回调入账并查询购买记录
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ErrTenantNotFound 支付事件关联的租户不存在
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// PaymentResult 支付事件处理结果
+type PaymentResult struct {
+	Invoice *entity.Invoice
+	// Duplicate 该笔支付此前已入账（重复回调）
+	Duplicate bool
+}
+
+// Service Token 充值计费服务
+type Service struct {
+	provider    PaymentProvider
+	tenantRepo  repository.TenantRepository
+	invoiceRepo repository.InvoiceRepository
+
+	// tokensPerMinorUnit 事件未携带 tokens 时按金额折算的比例（0 表示不折算）
+	tokensPerMinorUnit int64
+}
+
+// NewService 创建计费服务；provider 为 nil 时表示未启用计费，Webhook 不可用
+func NewService(provider PaymentProvider, tenantRepo repository.TenantRepository, invoiceRepo repository.InvoiceRepository, tokensPerMinorUnit int64) *Service {
+	return &Service{
+		provider:           provider,
+		tenantRepo:         tenantRepo,
+		invoiceRepo:        invoiceRepo,
+		tokensPerMinorUnit: tokensPerMinorUnit,
+	}
+}
+
+// Enabled 是否已配置支付提供商
+func (s *Service) Enabled() bool {
+	return s != nil && s.provider != nil
+}
+
+// ParseWebhook 校验并解析支付回调
+func (s *Service) ParseWebhook(header http.Header, payload []byte) (*PaymentEvent, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("billing not configured")
+	}
+	return s.provider.ParseWebhook(header, payload)
+}
+
+// HandlePayment 为已确认到账的支付事件记录账单并为租户入账（需在租户事务内调用，幂等）。
+// 同一笔支付（provider + external_id）的重复回调只入账一次。
+func (s *Service) HandlePayment(ctx context.Context, event *PaymentEvent) (*PaymentResult, error) {
+	if event == nil || !event.Succeeded {
+		return nil, fmt.Errorf("%w: not a succeeded payment event", ErrInvalidPayload)
+	}
+	tokens := s.resolveTokens(event)
+	if tokens <= 0 {
+		return nil, fmt.Errorf("%w: token amount unresolved", ErrInvalidPayload)
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, event.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, event.TenantID)
+	}
+
+	paidAt := event.PaidAt
+	if paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+	invoice := &entity.Invoice{
+		TenantID:   event.TenantID,
+		Provider:   s.provider.Name(),
+		ExternalID: event.ExternalID,
+		EventID:    event.EventID,
+		Amount:     event.Amount,
+		Currency:   strings.ToLower(strings.TrimSpace(event.Currency)),
+		Tokens:     tokens,
+		Status:     entity.InvoiceStatusPaid,
+		PaidAt:     paidAt,
+	}
+	created, err := s.invoiceRepo.Create(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return &PaymentResult{Invoice: invoice, Duplicate: true}, nil
+	}
+	if err := s.tenantRepo.CreditBalance(ctx, event.TenantID, tokens); err != nil {
+		return nil, err
+	}
+	return &PaymentResult{Invoice: invoice}, nil
+}
+
+// ListInvoices 获取租户购买记录
+func (s *Service) ListInvoices(ctx context.Context, tenantID string, pagination repository.Pagination) (*repository.PagedResult[*entity.Invoice], error) {
+	return s.invoiceRepo.ListByTenant(ctx, tenantID, pagination)
+}
+
+// GetInvoice 获取账单详情
+func (s *Service) GetInvoice(ctx context.Context, id string) (*entity.Invoice, error) {
+	return s.invoiceRepo.GetByID(ctx, id)
+}
+
+func (s *Service) resolveTokens(event *PaymentEvent) int64 {
+	if event.Tokens > 0 {
+		return event.Tokens
+	}
+	if s.tokensPerMinorUnit > 0 && event.Amount > 0 {
+		return event.Amount * s.tokensPerMinorUnit
+	}
+	return 0
+}
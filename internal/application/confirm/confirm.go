@@ -0,0 +1,144 @@
+// Package confirm 提供危险操作的二次确认令牌：先签发描述影响范围的短时令牌，执行操作时出示令牌并一次性核销。
+// 令牌存于 Redis（跨 API 实例共享），与租户、用户、操作与目标资源绑定，任一不符即视为无效。
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// TokenTTL 令牌有效期（足够用户阅读影响范围后确认，又不至于长期悬挂）
+	TokenTTL = 5 * time.Minute
+
+	// keyPrefix Redis Key 前缀；Key 使用令牌的 SHA-256，避免明文令牌落入存储
+	keyPrefix = "confirm:token:"
+)
+
+// 需要二次确认的操作
+const (
+	ActionProjectDelete = "project.delete"
+	ActionUserDelete    = "user.delete"
+	// ActionArtifactBranchDelete 删除构件分支（资源 ID 见 BranchResourceID）
+	ActionArtifactBranchDelete = "artifact_branch.delete"
+)
+
+// BranchResourceID 分支删除的资源 ID：构件 ID 与分支名组合，令牌只对该分支有效
+func BranchResourceID(artifactID, branchKey string) string {
+	return artifactID + "@" + branchKey
+}
+
+// ErrInvalidToken 令牌不存在、已过期、已使用或与当前请求不符
+var ErrInvalidToken = errors.New("confirmation token is invalid, expired or already used")
+
+// Impact 操作影响范围（对象类型 → 将被删除或受影响的数量）
+type Impact map[string]int64
+
+// Token 签发给客户端的确认令牌
+type Token struct {
+	Token      string    `json:"token"`
+	Action     string    `json:"action"`
+	ResourceID string    `json:"resource_id"`
+	Impact     Impact    `json:"impact"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// record 令牌在存储中的内容
+type record struct {
+	TenantID   string    `json:"tenant_id"`
+	UserID     string    `json:"user_id"`
+	Action     string    `json:"action"`
+	ResourceID string    `json:"resource_id"`
+	Impact     Impact    `json:"impact"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Store 令牌存储接口（由 Redis 缓存实现）
+type Store interface {
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Service 二次确认令牌服务
+type Service struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewService 创建二次确认令牌服务
+func NewService(store Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Issue 为指定用户对指定资源的操作签发确认令牌
+func (s *Service) Issue(ctx context.Context, tenantID, userID, action, resourceID string, impact Impact) (*Token, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	if impact == nil {
+		impact = Impact{}
+	}
+
+	rec := record{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Action:     action,
+		ResourceID: resourceID,
+		Impact:     impact,
+		ExpiresAt:  s.now().Add(TokenTTL),
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal confirmation token: %w", err)
+	}
+	if err := s.store.Set(ctx, storeKey(token), data, TokenTTL); err != nil {
+		return nil, fmt.Errorf("failed to save confirmation token: %w", err)
+	}
+
+	return &Token{Token: token, Action: action, ResourceID: resourceID, Impact: impact, ExpiresAt: rec.ExpiresAt}, nil
+}
+
+// Consume 校验并核销令牌；令牌与租户、用户、操作或资源不符时返回 ErrInvalidToken 且不核销
+func (s *Service) Consume(ctx context.Context, token, tenantID, userID, action, resourceID string) error {
+	if token == "" {
+		return ErrInvalidToken
+	}
+	key := storeKey(token)
+	values, err := s.store.MGet(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to load confirmation token: %w", err)
+	}
+	if len(values) == 0 || values[0] == nil {
+		return ErrInvalidToken
+	}
+
+	var rec record
+	if err := json.Unmarshal(values[0], &rec); err != nil {
+		return ErrInvalidToken
+	}
+	if rec.TenantID != tenantID || rec.UserID != userID || rec.Action != action || rec.ResourceID != resourceID {
+		return ErrInvalidToken
+	}
+	if !s.now().Before(rec.ExpiresAt) {
+		return ErrInvalidToken
+	}
+
+	if err := s.store.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to consume confirmation token: %w", err)
+	}
+	return nil
+}
+
+func storeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return keyPrefix + hex.EncodeToString(sum[:])
+}
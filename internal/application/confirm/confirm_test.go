@@ -0,0 +1,78 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type memStore map[string][]byte
+
+func (m memStore) MGet(_ context.Context, keys ...string) ([][]byte, error) {
+	out := make([][]byte, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out, nil
+}
+
+func (m memStore) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	m[key] = value.([]byte)
+	return nil
+}
+
+func (m memStore) Delete(_ context.Context, keys ...string) error {
+	for _, k := range keys {
+		delete(m, k)
+	}
+	return nil
+}
+
+func TestConsumeIsSingleUseAndScoped(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(memStore{})
+
+	tok, err := svc.Issue(ctx, "t1", "u1", ActionProjectDelete, "p1", Impact{"chapters": 12})
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if tok.Impact["chapters"] != 12 {
+		t.Fatalf("impact should be echoed, got %+v", tok.Impact)
+	}
+
+	mismatches := [][4]string{
+		{"t2", "u1", ActionProjectDelete, "p1"},
+		{"t1", "u2", ActionProjectDelete, "p1"},
+		{"t1", "u1", ActionUserDelete, "p1"},
+		{"t1", "u1", ActionProjectDelete, "p2"},
+	}
+	for _, m := range mismatches {
+		if err := svc.Consume(ctx, tok.Token, m[0], m[1], m[2], m[3]); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("scope %v should be rejected, got %v", m, err)
+		}
+	}
+
+	if err := svc.Consume(ctx, tok.Token, "t1", "u1", ActionProjectDelete, "p1"); err != nil {
+		t.Fatalf("mismatched attempts must not burn the token: %v", err)
+	}
+	if err := svc.Consume(ctx, tok.Token, "t1", "u1", ActionProjectDelete, "p1"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token must be single-use, got %v", err)
+	}
+}
+
+func TestConsumeRejectsExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewService(memStore{})
+	svc.now = func() time.Time { return now }
+
+	tok, err := svc.Issue(ctx, "t1", "u1", ActionUserDelete, "u9", nil)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	now = now.Add(TokenTTL)
+	if err := svc.Consume(ctx, tok.Token, "t1", "u1", ActionUserDelete, "u9"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token should be rejected, got %v", err)
+	}
+}
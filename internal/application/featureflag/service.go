@@ -0,0 +1,307 @@
+// Package featureflag 提供功能开关服务：全局定义 + 租户/项目级覆盖 + 按租户哈希的灰度比例，
+// 定义与覆盖经 Redis 短时缓存，供处理器与生成器统一判定高风险能力是否开启。
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// cacheTTL 开关定义与覆盖的缓存时间（全局定义改动最长在该时间后生效）
+	cacheTTL = time.Minute
+
+	flagsCacheKey = "ff:flags"
+)
+
+// 判定来源
+const (
+	SourceProjectOverride = "project_override"
+	SourceTenantOverride  = "tenant_override"
+	SourceRollout         = "rollout"
+	SourceDefault         = "default"
+)
+
+// ErrFlagNotFound 开关未定义
+var ErrFlagNotFound = errors.New("feature flag not found")
+
+// defaults 开关定义缺失或加载失败时的回退值（与上线前的行为保持一致）
+var defaults = map[string]bool{
+	entity.FeatureFlagArtifactJSONPatch:    true,
+	entity.FeatureFlagArtifactConflictScan: true,
+	entity.FeatureFlagChapterPipelineV2:    false,
+}
+
+// Client 功能开关判定接口（处理器与生成器依赖此接口；nil 实现按回退值判定）
+type Client interface {
+	Enabled(ctx context.Context, key, tenantID, projectID string) bool
+}
+
+// Cache 开关缓存接口（由 Redis 缓存实现）
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Evaluation 单个开关的判定结果
+type Evaluation struct {
+	Flag    *entity.FeatureFlag
+	Key     string
+	Enabled bool
+	Source  string
+}
+
+// Service 功能开关服务
+type Service struct {
+	repo      repository.FeatureFlagRepository
+	cache     Cache
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+}
+
+// NewService 创建功能开关服务
+func NewService(repo repository.FeatureFlagRepository, cache Cache, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *Service {
+	return &Service{
+		repo:      repo,
+		cache:     cache,
+		txMgr:     txMgr,
+		tenantCtx: tenantCtx,
+	}
+}
+
+// Default 返回开关的回退值（未登记的开关视为关闭）
+func Default(key string) bool {
+	return defaults[key]
+}
+
+// Enabled 判定开关对租户/项目是否开启；加载失败时记录日志并按回退值判定。
+func (s *Service) Enabled(ctx context.Context, key, tenantID, projectID string) bool {
+	if s == nil || s.repo == nil {
+		return Default(key)
+	}
+	ev, err := s.Evaluate(ctx, key, tenantID, projectID)
+	if err != nil {
+		logger.Warn(ctx, "failed to evaluate feature flag, using default", "error", err.Error(), "flag", key, "tenant_id", tenantID)
+		return Default(key)
+	}
+	return ev.Enabled
+}
+
+// Evaluate 判定单个开关并返回来源
+func (s *Service) Evaluate(ctx context.Context, key, tenantID, projectID string) (*Evaluation, error) {
+	flags, err := s.loadFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.loadOverrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate(key, findFlag(flags, key), overrides, tenantID, projectID), nil
+}
+
+// EvaluateAll 判定全部已定义开关（按 Key 排序）
+func (s *Service) EvaluateAll(ctx context.Context, tenantID, projectID string) ([]*Evaluation, error) {
+	flags, err := s.loadFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.loadOverrides(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Evaluation, 0, len(flags))
+	for _, f := range flags {
+		out = append(out, evaluate(f.Key, f, overrides, tenantID, projectID))
+	}
+	return out, nil
+}
+
+// ListOverrides 获取租户的全部覆盖
+func (s *Service) ListOverrides(ctx context.Context, tenantID string) ([]*entity.FeatureFlagOverride, error) {
+	return s.loadOverrides(ctx, tenantID)
+}
+
+// SetOverride 设置租户/项目级覆盖（projectID 为空表示租户级），并使租户缓存失效。
+func (s *Service) SetOverride(ctx context.Context, tenantID, key string, projectID *string, enabled bool) (*entity.FeatureFlagOverride, error) {
+	key = strings.TrimSpace(key)
+	flag, err := s.repo.GetFlag(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, ErrFlagNotFound
+	}
+
+	override := &entity.FeatureFlagOverride{
+		TenantID:  tenantID,
+		ProjectID: normalizeProjectID(projectID),
+		FlagKey:   key,
+		Enabled:   enabled,
+	}
+	if err := s.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		return s.repo.UpsertOverride(txCtx, override)
+	}); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, tenantID)
+	return override, nil
+}
+
+// DeleteOverride 删除租户/项目级覆盖，并使租户缓存失效。
+func (s *Service) DeleteOverride(ctx context.Context, tenantID, key string, projectID *string) error {
+	if err := s.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		return s.repo.DeleteOverride(txCtx, tenantID, strings.TrimSpace(key), normalizeProjectID(projectID))
+	}); err != nil {
+		return err
+	}
+	s.invalidate(ctx, tenantID)
+	return nil
+}
+
+// evaluate 判定优先级：项目级覆盖 > 租户级覆盖 > 全局开关与灰度比例 > 回退值
+func evaluate(key string, flag *entity.FeatureFlag, overrides []*entity.FeatureFlagOverride, tenantID, projectID string) *Evaluation {
+	ev := &Evaluation{Flag: flag, Key: key}
+	if flag == nil {
+		ev.Enabled = Default(key)
+		ev.Source = SourceDefault
+		return ev
+	}
+
+	var tenantOverride *entity.FeatureFlagOverride
+	for _, o := range overrides {
+		if o == nil || o.FlagKey != key {
+			continue
+		}
+		if o.IsProjectScoped() {
+			if projectID != "" && *o.ProjectID == projectID {
+				ev.Enabled = o.Enabled
+				ev.Source = SourceProjectOverride
+				return ev
+			}
+			continue
+		}
+		tenantOverride = o
+	}
+	if tenantOverride != nil {
+		ev.Enabled = tenantOverride.Enabled
+		ev.Source = SourceTenantOverride
+		return ev
+	}
+
+	ev.Enabled = flag.InRollout(tenantID)
+	ev.Source = SourceRollout
+	return ev
+}
+
+func findFlag(flags []*entity.FeatureFlag, key string) *entity.FeatureFlag {
+	for _, f := range flags {
+		if f != nil && f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Service) loadFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	var flags []*entity.FeatureFlag
+	if s.readCache(ctx, flagsCacheKey, &flags) {
+		return flags, nil
+	}
+	flags, err := s.repo.ListFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.writeCache(ctx, flagsCacheKey, flags)
+	return flags, nil
+}
+
+func (s *Service) loadOverrides(ctx context.Context, tenantID string) ([]*entity.FeatureFlagOverride, error) {
+	if strings.TrimSpace(tenantID) == "" {
+		return nil, nil
+	}
+	key := overridesCacheKey(tenantID)
+	var overrides []*entity.FeatureFlagOverride
+	if s.readCache(ctx, key, &overrides) {
+		return overrides, nil
+	}
+	if err := s.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		var loadErr error
+		overrides, loadErr = s.repo.ListOverrides(txCtx, tenantID)
+		return loadErr
+	}); err != nil {
+		return nil, err
+	}
+	s.writeCache(ctx, key, overrides)
+	return overrides, nil
+}
+
+// readCache 缓存未命中或读取失败均回源数据库
+func (s *Service) readCache(ctx context.Context, key string, out interface{}) bool {
+	if s.cache == nil {
+		return false
+	}
+	b, err := s.cache.Get(ctx, key)
+	if err != nil || len(b) == 0 {
+		return false
+	}
+	return json.Unmarshal(b, out) == nil
+}
+
+// writeCache 缓存写入失败不影响判定结果
+func (s *Service) writeCache(ctx context.Context, key string, value interface{}) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Set(ctx, key, value, cacheTTL); err != nil {
+		logger.Warn(ctx, "failed to cache feature flags", "error", err.Error(), "key", key)
+	}
+}
+
+func (s *Service) invalidate(ctx context.Context, tenantID string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Delete(ctx, overridesCacheKey(tenantID)); err != nil {
+		logger.Warn(ctx, "failed to invalidate feature flag cache", "error", err.Error(), "tenant_id", tenantID)
+	}
+}
+
+// inTenant 覆盖表受 RLS 约束：在租户上下文中访问（已处于事务中时复用外层事务）
+func (s *Service) inTenant(ctx context.Context, tenantID string, fn func(txCtx context.Context) error) error {
+	if s.txMgr == nil || s.tenantCtx == nil {
+		return fn(ctx)
+	}
+	return s.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.tenantCtx.SetTenant(txCtx, tenantID); err != nil {
+			return err
+		}
+		return fn(txCtx)
+	})
+}
+
+func overridesCacheKey(tenantID string) string {
+	return fmt.Sprintf("ff:ovr:%s", tenantID)
+}
+
+func normalizeProjectID(projectID *string) *string {
+	if projectID == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*projectID)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+var _ Client = (*Service)(nil)
@@ -0,0 +1,286 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+const (
+	// defaultArtifactKeepLast 每个分支默认保留的最新版本数
+	defaultArtifactKeepLast = 10
+	maxArtifactKeepLast     = 1000
+	// maxAbandonedBranchDays 废弃分支判定天数上限（0 表示不按废弃分支清理）
+	maxAbandonedBranchDays = 3650
+	// mainBranchKey 主分支永不视为废弃
+	mainBranchKey = "main"
+)
+
+// artifactRetention 构件版本保留策略：激活版本与带标签的版本始终保留；
+// 每个分支保留最新 KeepLast 个版本；非主分支且不含激活版本、最新版本早于 AbandonedAfter 的分支整体清理
+type artifactRetention struct {
+	KeepLast       int
+	AbandonedAfter time.Duration
+}
+
+// artifactGCReport 单个构件的清理报告（dry-run 时 Deleted/ReclaimedBytes 为 0）
+type artifactGCReport struct {
+	ArtifactID        string   `json:"artifact_id"`
+	Type              string   `json:"type"`
+	Versions          int      `json:"versions"`
+	KeptActive        int      `json:"kept_active"`
+	KeptTagged        int      `json:"kept_tagged"`
+	KeptRecent        int      `json:"kept_recent"`
+	Candidates        int      `json:"candidates"`
+	CandidateBytes    int64    `json:"candidate_bytes"`
+	Deleted           int      `json:"deleted"`
+	ReclaimedBytes    int64    `json:"reclaimed_bytes"`
+	AbandonedBranches []string `json:"abandoned_branches,omitempty"`
+}
+
+// normalizeArtifactGCParams 校验清理参数：keep_last（默认 10）、abandoned_branch_days（默认 0 不启用）、
+// dry_run（默认 true，只生成报告不删除）
+func normalizeArtifactGCParams(params map[string]any) (map[string]any, error) {
+	keepLast, err := intParam(params, "keep_last", defaultArtifactKeepLast, 1, maxArtifactKeepLast)
+	if err != nil {
+		return nil, err
+	}
+	abandonedDays, err := intParam(params, "abandoned_branch_days", 0, 0, maxAbandonedBranchDays)
+	if err != nil {
+		return nil, err
+	}
+	dryRun := true
+	if v, ok := params["dry_run"]; ok && v != nil {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: dry_run must be a boolean", ErrInvalidParams)
+		}
+		dryRun = b
+	}
+	return map[string]any{
+		"keep_last":             keepLast,
+		"abandoned_branch_days": abandonedDays,
+		"dry_run":               dryRun,
+	}, nil
+}
+
+func intParam(params map[string]any, key string, def, min, max int) (int, error) {
+	v, ok := params[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+	var n float64
+	switch x := v.(type) {
+	case float64:
+		n = x
+	case int:
+		n = float64(x)
+	default:
+		return 0, fmt.Errorf("%w: %s must be an integer", ErrInvalidParams, key)
+	}
+	if n != math.Trunc(n) || n < float64(min) || n > float64(max) {
+		return 0, fmt.Errorf("%w: %s must be an integer between %d and %d", ErrInvalidParams, key, min, max)
+	}
+	return int(n), nil
+}
+
+// collectArtifactGarbage 按保留策略清理项目下各构件的历史版本；dry_run 时只输出报告。
+// 每个构件在独立事务内评估并删除，删除语句再次排除激活与带标签的版本。
+func (r *Runner) collectArtifactGarbage(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	var params struct {
+		KeepLast            int   `json:"keep_last"`
+		AbandonedBranchDays int   `json:"abandoned_branch_days"`
+		DryRun              *bool `json:"dry_run"`
+	}
+	if len(job.InputParams) > 0 {
+		if err := json.Unmarshal(job.InputParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid artifact gc params: %w", err)
+		}
+	}
+	if params.KeepLast < 1 {
+		params.KeepLast = defaultArtifactKeepLast
+	}
+	dryRun := params.DryRun == nil || *params.DryRun
+	policy := artifactRetention{
+		KeepLast:       params.KeepLast,
+		AbandonedAfter: time.Duration(params.AbandonedBranchDays) * 24 * time.Hour,
+	}
+
+	var artifacts []*entity.ProjectArtifact
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		project, err := r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("%w: %s", errProjectNotFound, job.ProjectID)
+		}
+		artifacts, err = r.artifactRepo.ListArtifactsByProject(txCtx, job.ProjectID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	reports := make([]artifactGCReport, 0, len(artifacts))
+	var scanned, candidates, deleted int
+	var candidateBytes, reclaimed int64
+	for i, art := range artifacts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if art == nil {
+			continue
+		}
+		report, err := r.collectArtifact(ctx, tenantID, art.ID, policy, dryRun, now)
+		if err != nil {
+			return nil, fmt.Errorf("artifact %s: %w", art.ID, err)
+		}
+		if report == nil {
+			continue
+		}
+		reports = append(reports, *report)
+		scanned += report.Versions
+		candidates += report.Candidates
+		candidateBytes += report.CandidateBytes
+		deleted += report.Deleted
+		reclaimed += report.ReclaimedBytes
+
+		if p := (i + 1) * 100 / len(artifacts); p < 100 {
+			r.updateProgress(ctx, tenantID, job.ID, p)
+		}
+	}
+
+	if deleted > 0 {
+		metrics.ArtifactGCVersionsDeleted.Add(float64(deleted))
+		metrics.ArtifactGCReclaimedBytes.Add(float64(reclaimed))
+	}
+
+	return jobResult{
+		"dry_run":               dryRun,
+		"keep_last":             policy.KeepLast,
+		"abandoned_branch_days": params.AbandonedBranchDays,
+		"artifacts":             reports,
+		"versions_scanned":      scanned,
+		"candidates":            candidates,
+		"candidate_bytes":       candidateBytes,
+		"deleted":               deleted,
+		"reclaimed_bytes":       reclaimed,
+	}, nil
+}
+
+// collectArtifact 评估并清理单个构件；构件已删除时返回 nil
+func (r *Runner) collectArtifact(ctx context.Context, tenantID, artifactID string, policy artifactRetention, dryRun bool, now time.Time) (*artifactGCReport, error) {
+	var report *artifactGCReport
+	err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		art, err := r.artifactRepo.GetArtifactByID(txCtx, artifactID)
+		if err != nil || art == nil {
+			return err
+		}
+		versions, err := r.artifactRepo.ListVersionSummaries(txCtx, artifactID)
+		if err != nil {
+			return err
+		}
+		tags, err := r.artifactRepo.ListTags(txCtx, artifactID)
+		if err != nil {
+			return err
+		}
+		tagged := make(map[string]bool, len(tags))
+		for _, t := range tags {
+			if t != nil {
+				tagged[t.VersionID] = true
+			}
+		}
+		activeID := ""
+		if art.ActiveVersionID != nil {
+			activeID = *art.ActiveVersionID
+		}
+
+		rep, drop := planArtifactGC(versions, activeID, tagged, policy, now)
+		rep.ArtifactID = art.ID
+		rep.Type = string(art.Type)
+		report = rep
+		if dryRun || len(drop) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(drop))
+		for id := range drop {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		removed, err := r.artifactRepo.DeleteVersions(txCtx, artifactID, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range removed {
+			report.Deleted++
+			report.ReclaimedBytes += drop[id]
+		}
+		return nil
+	})
+	return report, err
+}
+
+// planArtifactGC 按保留策略评估版本，返回报告与待删除版本（ID -> 存储字节数）
+func planArtifactGC(versions []*entity.ArtifactVersionSummary, activeID string, tagged map[string]bool, policy artifactRetention, now time.Time) (*artifactGCReport, map[string]int64) {
+	report := &artifactGCReport{}
+	drop := make(map[string]int64)
+
+	branches := make(map[string][]*entity.ArtifactVersionSummary)
+	keys := make([]string, 0)
+	for _, v := range versions {
+		if v == nil {
+			continue
+		}
+		if _, ok := branches[v.BranchKey]; !ok {
+			keys = append(keys, v.BranchKey)
+		}
+		branches[v.BranchKey] = append(branches[v.BranchKey], v)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		list := branches[key]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].VersionNo > list[j].VersionNo })
+
+		hasActive := false
+		for _, v := range list {
+			if v.ID == activeID {
+				hasActive = true
+				break
+			}
+		}
+		abandoned := policy.AbandonedAfter > 0 && key != mainBranchKey && !hasActive &&
+			list[0].CreatedAt.Before(now.Add(-policy.AbandonedAfter))
+
+		removedAll := true
+		for i, v := range list {
+			report.Versions++
+			switch {
+			case v.ID == activeID:
+				report.KeptActive++
+			case tagged[v.ID]:
+				report.KeptTagged++
+			case !abandoned && i < policy.KeepLast:
+				report.KeptRecent++
+			default:
+				report.Candidates++
+				report.CandidateBytes += v.ContentBytes
+				drop[v.ID] = v.ContentBytes
+				continue
+			}
+			removedAll = false
+		}
+		if abandoned && removedAll {
+			report.AbandonedBranches = append(report.AbandonedBranches, key)
+		}
+	}
+	return report, drop
+}
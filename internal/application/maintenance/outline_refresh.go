@@ -0,0 +1,130 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// maxOutlineRefreshChapters 单个大纲刷新任务可指定的章节数上限
+const maxOutlineRefreshChapters = 1000
+
+// normalizeOutlineRefreshParams 校验大纲刷新参数：chapter_ids（可选，为空表示项目内全部大纲过期章节）
+func normalizeOutlineRefreshParams(params map[string]any) (map[string]any, error) {
+	ids := []string{}
+	if v, ok := params["chapter_ids"]; ok && v != nil {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: chapter_ids must be an array of strings", ErrInvalidParams)
+		}
+		if len(list) > maxOutlineRefreshChapters {
+			return nil, fmt.Errorf("%w: chapter_ids exceeds %d items", ErrInvalidParams, maxOutlineRefreshChapters)
+		}
+		for _, item := range list {
+			id, ok := item.(string)
+			if !ok || strings.TrimSpace(id) == "" {
+				return nil, fmt.Errorf("%w: chapter_ids must be an array of strings", ErrInvalidParams)
+			}
+			ids = append(ids, strings.TrimSpace(id))
+		}
+	}
+	return map[string]any{"chapter_ids": ids}, nil
+}
+
+// refreshOutline 将大纲过期章节的大纲文本同步为当前激活大纲中对应条目的内容；
+// 尚无正文的章节同时清除过期标记，已有正文的章节保留标记，直至按新大纲重新生成或作者确认
+func (r *Runner) refreshOutline(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	var params struct {
+		ChapterIDs []string `json:"chapter_ids"`
+	}
+	if len(job.InputParams) > 0 {
+		if err := json.Unmarshal(job.InputParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid outline refresh params: %w", err)
+		}
+	}
+	wanted := make(map[string]bool, len(params.ChapterIDs))
+	for _, id := range params.ChapterIDs {
+		wanted[id] = true
+	}
+
+	var stale, refreshed, cleared, missing int
+	var outlineVersionID string
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		project, err := r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("%w: %s", errProjectNotFound, job.ProjectID)
+		}
+
+		versionID, volumes, err := storyoutline.Active(txCtx, r.artifactRepo, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		outlineVersionID = versionID
+		entries := make(map[string]storymodel.ChapterPlan)
+		for _, vol := range volumes {
+			for _, ch := range vol.Chapters {
+				entries[ch.Key] = ch
+			}
+		}
+
+		links, err := r.chapterRepo.ListOutlineLinks(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if !link.OutlineStale || (len(wanted) > 0 && !wanted[link.ID]) {
+				continue
+			}
+			stale++
+			plan, ok := entries[link.OutlineKey]
+			if !ok {
+				// 条目已从大纲删除：无可同步内容，由覆盖度的孤立章节呈现
+				missing++
+				continue
+			}
+			ch, err := r.chapterRepo.GetByIDForUpdate(txCtx, link.ID)
+			if err != nil {
+				return err
+			}
+			if ch == nil {
+				continue
+			}
+			changed := false
+			if text := strings.TrimSpace(plan.Outline); text != "" && ch.Outline != text {
+				ch.Outline = text
+				refreshed++
+				changed = true
+			}
+			if strings.TrimSpace(ch.ContentText) == "" {
+				ch.ClearOutlineStale()
+				cleared++
+				changed = true
+			}
+			if !changed {
+				continue
+			}
+			if err := r.chapterRepo.Update(txCtx, ch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return jobResult{
+		"outline_version_id": outlineVersionID,
+		"stale":              stale,
+		"refreshed":          refreshed,
+		"cleared":            cleared,
+		"missing":            missing,
+	}, nil
+}
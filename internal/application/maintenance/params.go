@@ -0,0 +1,61 @@
+// Package maintenance 提供导出、重建索引、清理向量、清理构件版本、大纲刷新、重新统计等运维任务的执行逻辑。
+// 运维任务与生成任务共用 generation_jobs（category = maintenance），
+// 由 HTTP 入队、job-worker 执行，进度与时间线通过统一的任务接口查看。
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+
+	"z-novel-ai-api/internal/application/story/manuscript"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// ErrInvalidParams 任务类型不支持或参数不合法
+var ErrInvalidParams = errors.New("invalid maintenance job params")
+
+// JobTypes 由本包执行的运维任务类型（job-worker 据此注册处理器）
+func JobTypes() []entity.JobType {
+	return []entity.JobType{
+		entity.JobTypeProjectExport,
+		entity.JobTypeIndexRebuild,
+		entity.JobTypeVectorPurge,
+		entity.JobTypeArtifactGC,
+		entity.JobTypeOutlineRefresh,
+		entity.JobTypeProjectRecount,
+	}
+}
+
+// Supports 判断任务类型是否为可提交的运维任务
+func Supports(jobType entity.JobType) bool {
+	for _, t := range JobTypes() {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeParams 校验提交参数并返回写入任务的规范化参数
+func NormalizeParams(jobType entity.JobType, params map[string]any) (map[string]any, error) {
+	if !Supports(jobType) {
+		return nil, fmt.Errorf("%w: unsupported job_type %q", ErrInvalidParams, jobType)
+	}
+
+	switch jobType {
+	case entity.JobTypeProjectExport:
+		raw, _ := params["format"].(string)
+		format, ok := manuscript.ParseFormat(raw)
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidParams, raw)
+		}
+		return map[string]any{"format": string(format)}, nil
+	case entity.JobTypeArtifactGC:
+		return normalizeArtifactGCParams(params)
+	case entity.JobTypeOutlineRefresh:
+		return normalizeOutlineRefreshParams(params)
+	default:
+		// 重建索引 / 清理向量 / 重新统计不接受参数，范围固定为整个项目
+		return map[string]any{}, nil
+	}
+}
@@ -0,0 +1,305 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// recountPageSize 重新统计时分页读取章节/实体的每页条数
+const recountPageSize = 100
+
+// projectTally 项目统计快照（重新统计前后各取一次，写入任务结果供对比）
+type projectTally struct {
+	ProjectWordCount   int   `json:"project_word_count"`
+	ChapterWordCount   int   `json:"chapter_word_count"` // 章节 word_count 之和
+	Chapters           int   `json:"chapters"`
+	VolumeWordCount    int   `json:"volume_word_count"`    // 卷汇总 word_count 之和
+	VolumeChapterCount int   `json:"volume_chapter_count"` // 卷汇总 chapter_count 之和
+	EntityAppearances  int   `json:"entity_appearances"`   // 实体 appear_count 之和
+	VectorSegments     int64 `json:"vector_segments,omitempty"`
+}
+
+// recountFixes 各类被修正的记录数
+type recountFixes struct {
+	ChapterWordCounts int  `json:"chapter_word_counts"`
+	ChapterStats      int  `json:"chapter_stats"`
+	Volumes           int  `json:"volumes"`
+	Entities          int  `json:"entities"`
+	ProjectWordCount  bool `json:"project_word_count"`
+	VectorUsage       bool `json:"vector_usage"`
+}
+
+// appearance 实体出场统计（按叙事顺序累计）
+type appearance struct {
+	first, last string
+	count       int
+}
+
+// recountProject 从源数据重新计算项目统计：章节字数与文本统计 → 卷汇总 → 项目字数 → 实体出场统计，
+// 均在同一事务内完成；向量用量计数在事务外按向量存储中的实际片段重算（未开启计数时跳过）。
+// 重复执行结果一致，结果包含重算前后的统计与各类修正数。
+func (r *Runner) recountProject(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	var before, after projectTally
+	var fixes recountFixes
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		project, err := r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("%w: %s", errProjectNotFound, job.ProjectID)
+		}
+
+		if before, err = r.tally(txCtx, project); err != nil {
+			return err
+		}
+		povByChapter, err := r.recountChapters(txCtx, job.ProjectID, &fixes)
+		if err != nil {
+			return err
+		}
+
+		volumesBefore, err := r.volumeRepo.ListByProject(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if err := r.volumeRepo.RefreshRollups(txCtx, job.ProjectID); err != nil {
+			return err
+		}
+		volumesAfter, err := r.volumeRepo.ListByProject(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		fixes.Volumes = changedVolumes(volumesBefore, volumesAfter)
+
+		stats, err := r.projectRepo.GetStats(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if wc := int(stats.TotalWordCount); wc != project.CurrentWordCount {
+			if err := r.projectRepo.UpdateWordCount(txCtx, job.ProjectID, wc); err != nil {
+				return err
+			}
+			fixes.ProjectWordCount = true
+		}
+
+		if err := r.recountAppearances(txCtx, job.ProjectID, povByChapter, &fixes); err != nil {
+			return err
+		}
+
+		project, err = r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		after, err = r.tally(txCtx, project)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	res := jobResult{}
+	vectorBefore, vectorAfter, err := r.indexer.RecountUsage(ctx, tenantID, job.ProjectID)
+	switch {
+	case err == nil:
+		before.VectorSegments, after.VectorSegments = vectorBefore.Segments, vectorAfter.Segments
+		fixes.VectorUsage = vectorBefore != vectorAfter
+	case errors.Is(err, appretrieval.ErrVectorDisabled), errors.Is(err, appretrieval.ErrSegmentScanUnsupported):
+		res["vector_usage_skipped"] = err.Error()
+	default:
+		// 数据库统计已提交，向量用量重算失败不影响任务结果，重复执行即可
+		logger.Warn(ctx, "failed to recount vector usage", "error", err.Error(), "job_id", job.ID)
+		res["vector_usage_error"] = err.Error()
+	}
+
+	res["before"] = before
+	res["after"] = after
+	res["fixed"] = fixes
+	return res, nil
+}
+
+// recountChapters 按正文重算章节字数与文本统计（逐页加载含正文的章节），返回各章节的 POV 角色
+func (r *Runner) recountChapters(ctx context.Context, projectID string, fixes *recountFixes) (map[string]string, error) {
+	stored, err := r.chapterRepo.ListStats(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	statsByChapter := make(map[string]*entity.ChapterStats, len(stored))
+	for _, s := range stored {
+		statsByChapter[s.ChapterID] = s
+	}
+
+	pov := make(map[string]string)
+	filter := &repository.ChapterFilter{IncludeContent: true}
+	for page := 1; ; page++ {
+		res, err := r.chapterRepo.ListByProject(ctx, projectID, filter, repository.Pagination{Page: page, PageSize: recountPageSize})
+		if err != nil {
+			return nil, err
+		}
+		if res == nil || len(res.Items) == 0 {
+			return pov, nil
+		}
+		for _, ch := range res.Items {
+			if ch == nil {
+				continue
+			}
+			if id := ch.POVEntity(); id != "" {
+				pov[ch.ID] = id
+			}
+			if wc := len([]rune(ch.ContentText)); wc != ch.WordCount {
+				if err := r.chapterRepo.UpdateWordCount(ctx, ch.ID, wc); err != nil {
+					return nil, err
+				}
+				fixes.ChapterWordCounts++
+			}
+			fresh := entity.NewChapterStats(ch)
+			if !sameChapterStats(statsByChapter[ch.ID], fresh) {
+				if err := r.chapterRepo.SaveStats(ctx, fresh); err != nil {
+					return nil, err
+				}
+				fixes.ChapterStats++
+			}
+		}
+		if len(res.Items) < recountPageSize {
+			return pov, nil
+		}
+	}
+}
+
+// recountAppearances 按叙事顺序从章节 POV 与章节事件参与者重算实体出场次数及首次/最近出场章节
+func (r *Runner) recountAppearances(ctx context.Context, projectID string, povByChapter map[string]string, fixes *recountFixes) error {
+	chapters, err := r.chapterRepo.ListOutlineLinks(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	counted := make(map[string]*appearance)
+	for _, ch := range chapters {
+		involved := map[string]struct{}{}
+		if pov := povByChapter[ch.ID]; pov != "" {
+			involved[pov] = struct{}{}
+		}
+		events, err := r.eventRepo.ListByChapter(ctx, ch.ID)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			if ev == nil {
+				continue
+			}
+			for _, id := range ev.InvolvedEntities {
+				if id != "" {
+					involved[id] = struct{}{}
+				}
+			}
+		}
+		for id := range involved {
+			a := counted[id]
+			if a == nil {
+				a = &appearance{first: ch.ID}
+				counted[id] = a
+			}
+			a.last = ch.ID
+			a.count++
+		}
+	}
+
+	for page := 1; ; page++ {
+		res, err := r.entityRepo.ListByProject(ctx, projectID, nil, repository.Pagination{Page: page, PageSize: recountPageSize})
+		if err != nil {
+			return err
+		}
+		if res == nil || len(res.Items) == 0 {
+			return nil
+		}
+		for _, e := range res.Items {
+			if e == nil {
+				continue
+			}
+			want := appearance{}
+			if a := counted[e.ID]; a != nil {
+				want = *a
+			}
+			if e.AppearCount == want.count && e.FirstAppearChapterID == want.first && e.LastAppearChapterID == want.last {
+				continue
+			}
+			if err := r.entityRepo.SetAppearances(ctx, e.ID, want.first, want.last, want.count); err != nil {
+				return err
+			}
+			fixes.Entities++
+		}
+		if len(res.Items) < recountPageSize {
+			return nil
+		}
+	}
+}
+
+// tally 读取项目当前的统计值
+func (r *Runner) tally(ctx context.Context, project *entity.Project) (projectTally, error) {
+	t := projectTally{ProjectWordCount: project.CurrentWordCount}
+	chapters, err := r.chapterRepo.ListOutlineLinks(ctx, project.ID)
+	if err != nil {
+		return t, err
+	}
+	for _, ch := range chapters {
+		t.Chapters++
+		t.ChapterWordCount += ch.WordCount
+	}
+	volumes, err := r.volumeRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return t, err
+	}
+	for _, v := range volumes {
+		t.VolumeWordCount += v.WordCount
+		t.VolumeChapterCount += v.ChapterCount
+	}
+	for page := 1; ; page++ {
+		res, err := r.entityRepo.ListByProject(ctx, project.ID, nil, repository.Pagination{Page: page, PageSize: recountPageSize})
+		if err != nil {
+			return t, err
+		}
+		if res == nil {
+			break
+		}
+		for _, e := range res.Items {
+			if e != nil {
+				t.EntityAppearances += e.AppearCount
+			}
+		}
+		if len(res.Items) < recountPageSize {
+			break
+		}
+	}
+	return t, nil
+}
+
+// sameChapterStats 已存储的文本统计是否与重新计算的一致（不比较更新时间）
+func sameChapterStats(stored, fresh *entity.ChapterStats) bool {
+	return stored != nil &&
+		stored.WordCount == fresh.WordCount &&
+		stored.DialogueChars == fresh.DialogueChars &&
+		stored.NarrationChars == fresh.NarrationChars &&
+		stored.ParagraphCount == fresh.ParagraphCount &&
+		stored.SceneCount == fresh.SceneCount
+}
+
+// changedVolumes 汇总值发生变化的卷数
+func changedVolumes(before, after []*entity.Volume) int {
+	prev := make(map[string]*entity.Volume, len(before))
+	for _, v := range before {
+		prev[v.ID] = v
+	}
+	changed := 0
+	for _, v := range after {
+		p := prev[v.ID]
+		if p == nil || p.WordCount != v.WordCount || p.ChapterCount != v.ChapterCount ||
+			p.DraftChapters != v.DraftChapters || p.GeneratingChapters != v.GeneratingChapters ||
+			p.ReviewChapters != v.ReviewChapters || p.CompletedChapters != v.CompletedChapters {
+			changed++
+		}
+	}
+	return changed
+}
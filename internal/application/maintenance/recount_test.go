@@ -0,0 +1,95 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestRecountProjectFixesDriftIdempotently(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	projects := memrepo.NewProjectRepository(store)
+	volumes := memrepo.NewVolumeRepository(store)
+	chapters := memrepo.NewChapterRepository(store)
+	entities := memrepo.NewEntityRepository(store)
+	events := memrepo.NewEventRepository(store)
+	jobs := memrepo.NewJobRepository(store)
+	runner := NewRunner(memrepo.NewTxManager(store), memrepo.NewTenantContext(store), jobs, projects, chapters, volumes, entities, events,
+		memrepo.NewArtifactRepository(store), memrepo.NewProjectNoteRepository(store), nil, nil, nil, appstory.NewJobTimeline(memrepo.NewJobEventRepository(store)))
+
+	mustNoErr := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := entity.NewProject("tenant-1", "owner-1", "测试项目")
+	mustNoErr(projects.Create(ctx, project))
+	vol := entity.NewVolume(project.ID, 1, "第一卷")
+	mustNoErr(volumes.Create(ctx, vol))
+
+	hero := entity.NewStoryEntity(project.ID, "主角", entity.EntityTypeCharacter, entity.ImportanceProtagonist)
+	mustNoErr(entities.Create(ctx, hero))
+	ally := entity.NewStoryEntity(project.ID, "同伴", entity.EntityTypeCharacter, entity.ImportanceSecondary)
+	mustNoErr(entities.Create(ctx, ally))
+
+	var chapterIDs []string
+	for i, content := range []string{"第一章正文。", "“你好。”他说。"} {
+		ch := entity.NewChapter(project.ID, vol.ID, i+1)
+		ch.SetContent(content)
+		ch.POVEntityID = &hero.ID
+		mustNoErr(chapters.Create(ctx, ch))
+		chapterIDs = append(chapterIDs, ch.ID)
+	}
+	ev := entity.NewEvent(project.ID, 1, "相遇")
+	ev.ChapterID = chapterIDs[1]
+	ev.AddInvolvedEntity(ally.ID)
+	mustNoErr(events.Create(ctx, ev))
+
+	// 制造漂移：章节/项目字数错误，实体出场计数残留
+	mustNoErr(chapters.UpdateWordCount(ctx, chapterIDs[0], 999))
+	mustNoErr(projects.UpdateWordCount(ctx, project.ID, 12345))
+	mustNoErr(entities.SetAppearances(ctx, ally.ID, "", "", 7))
+
+	job := entity.NewGenerationJob("tenant-1", project.ID, entity.JobTypeProjectRecount, json.RawMessage(`{}`))
+	res, err := runner.recountProject(ctx, "tenant-1", job)
+	mustNoErr(err)
+	fixes := res["fixed"].(recountFixes)
+	if fixes.ChapterWordCounts != 1 || !fixes.ProjectWordCount || fixes.Entities != 2 {
+		t.Fatalf("unexpected fixes %+v", fixes)
+	}
+	after := res["after"].(projectTally)
+	want := len([]rune("第一章正文。")) + len([]rune("“你好。”他说。"))
+	if after.ProjectWordCount != want || after.ChapterWordCount != want || after.VolumeWordCount != want || after.VolumeChapterCount != 2 {
+		t.Fatalf("unexpected tally %+v", after)
+	}
+	if res["before"].(projectTally).ProjectWordCount != 12345 {
+		t.Fatal("before tally should report drifted values")
+	}
+
+	got, err := entities.GetByID(ctx, hero.ID)
+	mustNoErr(err)
+	if got.AppearCount != 2 || got.FirstAppearChapterID != chapterIDs[0] || got.LastAppearChapterID != chapterIDs[1] {
+		t.Fatalf("unexpected hero appearances %+v", got)
+	}
+	got, err = entities.GetByID(ctx, ally.ID)
+	mustNoErr(err)
+	if got.AppearCount != 1 || got.FirstAppearChapterID != chapterIDs[1] {
+		t.Fatalf("unexpected ally appearances %+v", got)
+	}
+	if _, ok := res["vector_usage_skipped"]; !ok {
+		t.Fatal("vector usage recount should be skipped without indexer")
+	}
+
+	// 再次执行无需修正
+	res, err = runner.recountProject(ctx, "tenant-1", job)
+	mustNoErr(err)
+	if fixes := res["fixed"].(recountFixes); fixes != (recountFixes{}) {
+		t.Fatalf("second run should be a no-op, got %+v", fixes)
+	}
+}
@@ -0,0 +1,444 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"z-novel-ai-api/internal/application/provenance"
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/application/story/manuscript"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// documentIndexTimeout 重建索引时单个文档（章节/设定）的写入超时
+	documentIndexTimeout = 30 * time.Second
+	// progressStep 进度写库节流步长（百分比）
+	progressStep = 5
+	// maxReportedFailures 结果中保留的失败明细条数
+	maxReportedFailures = 10
+)
+
+// errProjectNotFound 项目已删除：任务直接失败，无需重试
+var errProjectNotFound = errors.New("project not found")
+
+// Runner 运维任务执行器。
+//
+// 约定：
+// - 领取、落结果各用一个短事务（自行 SetTenant）；导出渲染、Embedding、向量写入在事务外执行。
+// - 执行期间任务被取消时不覆盖 cancelled 状态。
+type Runner struct {
+	txMgr        repository.Transactor
+	tenantCtx    repository.TenantContextManager
+	jobRepo      repository.JobRepository
+	projectRepo  repository.ProjectRepository
+	chapterRepo  repository.ChapterRepository
+	volumeRepo   repository.VolumeRepository
+	entityRepo   repository.EntityRepository
+	eventRepo    repository.EventRepository
+	artifactRepo repository.ArtifactRepository
+	noteRepo     repository.ProjectNoteRepository
+	finalizer    *appstory.GenerationFinalizer
+	indexer      *appretrieval.Indexer
+	watermarker  *provenance.Watermarker
+	timeline     *appstory.JobTimeline
+}
+
+// NewRunner 创建运维任务执行器；indexer 为 nil 时重建索引/清理向量任务直接失败，watermarker 为 nil 时导出不嵌入水印
+func NewRunner(
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	jobRepo repository.JobRepository,
+	projectRepo repository.ProjectRepository,
+	chapterRepo repository.ChapterRepository,
+	volumeRepo repository.VolumeRepository,
+	entityRepo repository.EntityRepository,
+	eventRepo repository.EventRepository,
+	artifactRepo repository.ArtifactRepository,
+	noteRepo repository.ProjectNoteRepository,
+	finalizer *appstory.GenerationFinalizer,
+	indexer *appretrieval.Indexer,
+	watermarker *provenance.Watermarker,
+	timeline *appstory.JobTimeline,
+) *Runner {
+	return &Runner{
+		txMgr:        txMgr,
+		tenantCtx:    tenantCtx,
+		jobRepo:      jobRepo,
+		projectRepo:  projectRepo,
+		chapterRepo:  chapterRepo,
+		volumeRepo:   volumeRepo,
+		entityRepo:   entityRepo,
+		eventRepo:    eventRepo,
+		artifactRepo: artifactRepo,
+		noteRepo:     noteRepo,
+		finalizer:    finalizer,
+		indexer:      indexer,
+		watermarker:  watermarker,
+		timeline:     timeline,
+	}
+}
+
+// Run 领取并执行运维任务。返回错误时由消费者按退避策略重试（重试时 Start 会累计 RetryCount）；
+// 不可恢复的失败（项目不存在、向量能力未启用）只标记任务失败，不返回错误。
+func (r *Runner) Run(ctx context.Context, worker, tenantID, jobID string) error {
+	var job *entity.GenerationJob
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		j, err := r.jobRepo.GetByID(txCtx, jobID)
+		if err != nil {
+			return err
+		}
+		if j == nil {
+			return fmt.Errorf("job not found: %s", jobID)
+		}
+		if j.Status == entity.JobStatusCancelled || j.Status == entity.JobStatusCompleted {
+			return nil
+		}
+		r.timeline.Record(txCtx, j, entity.JobEventClaimed, "claimed by worker", map[string]any{"worker": worker})
+
+		j.Start()
+		if err := r.jobRepo.Update(txCtx, j); err != nil {
+			return err
+		}
+		r.timeline.Record(txCtx, j, entity.JobEventStarted, string(j.JobType)+" started", nil)
+		job = j
+		return nil
+	}); err != nil {
+		return err
+	}
+	if job == nil {
+		return nil
+	}
+
+	result, runErr := r.execute(ctx, tenantID, job)
+
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		current, err := r.jobRepo.GetByID(txCtx, job.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil || current.Status == entity.JobStatusCancelled {
+			return nil
+		}
+
+		if runErr != nil {
+			job.Fail(runErr.Error())
+			if err := r.jobRepo.Update(txCtx, job); err != nil {
+				return err
+			}
+			r.timeline.Record(txCtx, job, entity.JobEventFailed, runErr.Error(), nil)
+			return nil
+		}
+
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode job result: %w", err)
+		}
+		job.Complete(raw)
+		if err := r.jobRepo.Update(txCtx, job); err != nil {
+			return err
+		}
+		r.timeline.Record(txCtx, job, entity.JobEventCompleted, string(job.JobType)+" completed", result.summary())
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if errors.Is(runErr, errProjectNotFound) || errors.Is(runErr, appretrieval.ErrVectorDisabled) {
+		return nil
+	}
+	return runErr
+}
+
+// jobResult 任务结果（写入 output_result）
+type jobResult map[string]any
+
+// summary 时间线只记录摘要，不重复写入导出正文
+func (res jobResult) summary() map[string]any {
+	out := make(map[string]any, len(res))
+	for k, v := range res {
+		if k == "content" {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func (r *Runner) execute(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	switch job.JobType {
+	case entity.JobTypeProjectExport:
+		return r.exportProject(ctx, tenantID, job)
+	case entity.JobTypeIndexRebuild:
+		return r.rebuildIndex(ctx, tenantID, job)
+	case entity.JobTypeVectorPurge:
+		return r.purgeVectors(ctx, tenantID, job)
+	case entity.JobTypeArtifactGC:
+		return r.collectArtifactGarbage(ctx, tenantID, job)
+	case entity.JobTypeOutlineRefresh:
+		return r.refreshOutline(ctx, tenantID, job)
+	case entity.JobTypeProjectRecount:
+		return r.recountProject(ctx, tenantID, job)
+	default:
+		return nil, fmt.Errorf("unsupported maintenance job type: %s", job.JobType)
+	}
+}
+
+// exportProject 渲染成稿并写入任务结果（与同步导出接口一致：按叙事顺序、启用时嵌入来源水印）
+func (r *Runner) exportProject(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	var params struct {
+		Format string `json:"format"`
+	}
+	if len(job.InputParams) > 0 {
+		if err := json.Unmarshal(job.InputParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid export params: %w", err)
+		}
+	}
+	format, ok := manuscript.ParseFormat(params.Format)
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", params.Format)
+	}
+
+	var project *entity.Project
+	var chapters []*entity.Chapter
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		var err error
+		project, err = r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("%w: %s", errProjectNotFound, job.ProjectID)
+		}
+		chapters, err = r.chapterRepo.ListManuscript(txCtx, job.ProjectID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	body := manuscript.Render(project, chapters, format)
+	watermarked := r.watermarker.Enabled()
+	if watermarked {
+		body = r.watermarker.Embed(body, provenance.Marker{TenantID: tenantID, ProjectID: project.ID})
+	}
+	sum := sha256.Sum256([]byte(body))
+
+	return jobResult{
+		"format":       string(format),
+		"filename":     fmt.Sprintf("%s.%s", strings.TrimSpace(project.Title), format.Extension()),
+		"content_type": format.ContentType(),
+		"content":      body,
+		"chars":        utf8.RuneCountInString(body),
+		"chapters":     len(chapters),
+		"sha256":       hex.EncodeToString(sum[:]),
+		"watermarked":  watermarked,
+	}, nil
+}
+
+// indexDocument 重建索引的单个文档
+type indexDocument struct {
+	chapter *appstory.ChapterIndexSnapshot
+
+	artifactID   string
+	artifactType entity.ArtifactType
+	content      json.RawMessage
+	// branchKey 非主线分支头写入分支记忆；空值为主线（激活版本）
+	branchKey string
+
+	note *entity.ProjectNote
+}
+
+// rebuildIndex 清空项目向量后按当前数据重建：已完成章节 + 各设定的激活版本与非主线分支头 + 导入的作者笔记。
+// 先清空再写入，避免已删除章节/设定的旧片段残留。
+func (r *Runner) rebuildIndex(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	if !r.indexer.Enabled() {
+		return nil, appretrieval.ErrVectorDisabled
+	}
+
+	var docs []indexDocument
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		project, err := r.projectRepo.GetByID(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return fmt.Errorf("%w: %s", errProjectNotFound, job.ProjectID)
+		}
+
+		chapters, err := r.chapterRepo.ListManuscript(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, ch := range chapters {
+			docs = append(docs, indexDocument{chapter: r.finalizer.IndexSnapshot(txCtx, ch)})
+		}
+
+		artifacts, err := r.artifactRepo.ListArtifactsByProject(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, art := range artifacts {
+			if art == nil || art.ActiveVersionID == nil || appretrieval.ArtifactSegmentType(art.Type) == "" {
+				continue
+			}
+			version, err := r.artifactRepo.GetVersionByID(txCtx, *art.ActiveVersionID)
+			if err != nil {
+				return err
+			}
+			if version == nil {
+				continue
+			}
+			docs = append(docs, indexDocument{artifactID: art.ID, artifactType: art.Type, content: version.Content})
+
+			heads, err := r.artifactRepo.ListBranchHeads(txCtx, art.ID)
+			if err != nil {
+				return err
+			}
+			for _, head := range heads {
+				if head == nil || appretrieval.IsMainBranch(head.BranchKey) {
+					continue
+				}
+				// 分支头不含内容，按分支读取最新版本
+				latest, err := r.artifactRepo.GetLatestVersionByBranch(txCtx, art.ID, head.BranchKey)
+				if err != nil {
+					return err
+				}
+				if latest == nil {
+					continue
+				}
+				docs = append(docs, indexDocument{artifactID: art.ID, artifactType: art.Type, content: latest.Content, branchKey: head.BranchKey})
+			}
+		}
+
+		notes, err := r.noteRepo.ListByProject(txCtx, job.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, note := range notes {
+			docs = append(docs, indexDocument{note: note})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := r.indexer.PurgeProject(ctx, tenantID, job.ProjectID); err != nil {
+		return nil, fmt.Errorf("failed to purge project vectors: %w", err)
+	}
+
+	var failures []string
+	failed := 0
+	lastProgress := 0
+	for i, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := r.indexOne(ctx, tenantID, job.ProjectID, doc); err != nil {
+			logger.Warn(ctx, "failed to rebuild document index", "error", err.Error(), "job_id", job.ID)
+			failed++
+			if len(failures) < maxReportedFailures {
+				failures = append(failures, err.Error())
+			}
+		}
+		if p := (i + 1) * 100 / len(docs); p < 100 && p-lastProgress >= progressStep {
+			lastProgress = p
+			r.updateProgress(ctx, tenantID, job.ID, p)
+		}
+	}
+
+	if failed > 0 && failed == len(docs) {
+		return nil, fmt.Errorf("failed to rebuild index: %s", failures[0])
+	}
+
+	res := jobResult{
+		"documents": len(docs),
+		"failed":    failed,
+		"errors":    failures,
+	}
+	if counts := r.segmentCounts(ctx, tenantID, job.ProjectID); counts != nil {
+		res["segments"] = counts
+	}
+	return res, nil
+}
+
+func (r *Runner) indexOne(ctx context.Context, tenantID, projectID string, doc indexDocument) error {
+	indexCtx, cancel := context.WithTimeout(ctx, documentIndexTimeout)
+	defer cancel()
+
+	if doc.chapter != nil {
+		opts := appretrieval.ChapterIndexOptions{
+			NarrativePos:     doc.chapter.NarrativePos,
+			InvolvedEntities: doc.chapter.InvolvedEntities,
+		}
+		if err := r.indexer.IndexChapter(indexCtx, tenantID, projectID, doc.chapter.Chapter, opts); err != nil {
+			return fmt.Errorf("chapter %s: %w", doc.chapter.Chapter.ID, err)
+		}
+		return nil
+	}
+	if doc.note != nil {
+		if err := r.indexer.IndexNotes(indexCtx, tenantID, projectID, doc.note); err != nil {
+			return fmt.Errorf("note %s: %w", doc.note.ID, err)
+		}
+		return nil
+	}
+	if err := r.indexer.IndexArtifactBranch(indexCtx, tenantID, projectID, doc.artifactType, doc.artifactID, doc.branchKey, doc.content); err != nil {
+		if errors.Is(err, appretrieval.ErrBranchIndexUnsupported) {
+			return nil
+		}
+		return fmt.Errorf("artifact %s: %w", doc.artifactID, err)
+	}
+	return nil
+}
+
+// purgeVectors 清空项目的全部向量片段（检索将退化为无上下文，直至重建索引）
+func (r *Runner) purgeVectors(ctx context.Context, tenantID string, job *entity.GenerationJob) (jobResult, error) {
+	counts := r.segmentCounts(ctx, tenantID, job.ProjectID)
+	if err := r.indexer.PurgeProject(ctx, tenantID, job.ProjectID); err != nil {
+		return nil, err
+	}
+	res := jobResult{"purged": true}
+	if counts != nil {
+		res["purged_segments"] = counts
+	}
+	return res, nil
+}
+
+// segmentCounts 统计项目各类型片段数写入结果；向量存储不支持遍历或统计失败时返回 nil（不影响任务结果）
+func (r *Runner) segmentCounts(ctx context.Context, tenantID, projectID string) map[string]int64 {
+	counts, err := r.indexer.CountSegments(ctx, tenantID, projectID)
+	if err != nil {
+		if !errors.Is(err, appretrieval.ErrSegmentScanUnsupported) && !errors.Is(err, appretrieval.ErrVectorDisabled) {
+			logger.Warn(ctx, "failed to count project segments", "error", err.Error(), "project_id", projectID)
+		}
+		return nil
+	}
+	return counts
+}
+
+// updateProgress 进度写库失败只打日志
+func (r *Runner) updateProgress(ctx context.Context, tenantID, jobID string, progress int) {
+	if err := r.inTenant(ctx, tenantID, func(txCtx context.Context) error {
+		return r.jobRepo.UpdateProgress(txCtx, jobID, progress)
+	}); err != nil {
+		logger.Warn(ctx, "failed to update job progress", "error", err.Error(), "job_id", jobID)
+	}
+}
+
+func (r *Runner) inTenant(ctx context.Context, tenantID string, fn func(txCtx context.Context) error) error {
+	return r.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := r.tenantCtx.SetTenant(txCtx, tenantID); err != nil {
+			return err
+		}
+		return fn(txCtx)
+	})
+}
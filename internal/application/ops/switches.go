@@ -0,0 +1,196 @@
+// Package ops 提供运维开关：全局与租户级的只读模式、暂停生成与维护公告，
+// 存于 Redis（跨 API 与 Worker 进程共享），由 HTTP 中间件与消息消费者执行。
+package ops
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// localCacheTTL 进程内缓存时间（中间件每个请求都会查询；开关变更最长在该时间后全量生效）
+	localCacheTTL = 3 * time.Second
+
+	globalKey       = "ops:switches:global"
+	tenantKeyPrefix = "ops:switches:tenant:"
+
+	// MaxMessageRunes 维护公告最大长度
+	MaxMessageRunes = 500
+)
+
+// Switches 单个作用域（全局或租户）的运维开关
+type Switches struct {
+	// ReadOnly 只读模式：拒绝所有写请求（含生成类接口）
+	ReadOnly bool `json:"read_only"`
+	// GenerationPaused 暂停生成：拒绝生成类接口，Worker 停止认领任务
+	GenerationPaused bool `json:"generation_paused"`
+	// Message 维护公告（通过响应头下发给客户端）
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// IsZero 是否所有开关均未设置
+func (s *Switches) IsZero() bool {
+	return s == nil || (!s.ReadOnly && !s.GenerationPaused && strings.TrimSpace(s.Message) == "")
+}
+
+// Effective 合并后的生效开关（任一作用域开启即生效；公告租户级优先）
+type Effective struct {
+	ReadOnly         bool
+	GenerationPaused bool
+	Message          string
+	Global           *Switches
+	Tenant           *Switches
+}
+
+// Store 开关存储接口（由 Redis 缓存实现）
+type Store interface {
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Service 运维开关服务
+type Service struct {
+	store Store
+
+	mu    sync.Mutex
+	cache map[string]cachedEffective
+}
+
+type cachedEffective struct {
+	effective *Effective
+	expiresAt time.Time
+}
+
+// NewService 创建运维开关服务
+func NewService(store Store) *Service {
+	return &Service{
+		store: store,
+		cache: make(map[string]cachedEffective),
+	}
+}
+
+// Effective 返回租户的生效开关（tenantID 为空时仅看全局）；读取失败时放行（全部关闭），避免 Redis 故障阻断业务。
+func (s *Service) Effective(ctx context.Context, tenantID string) *Effective {
+	if s == nil || s.store == nil {
+		return &Effective{}
+	}
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.cache[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.effective
+	}
+
+	global, tenant, err := s.Load(ctx, tenantID)
+	if err != nil {
+		logger.Warn(ctx, "failed to load ops switches", "error", err.Error(), "tenant_id", tenantID)
+		return &Effective{}
+	}
+	eff := Merge(global, tenant)
+	s.mu.Lock()
+	s.cache[tenantID] = cachedEffective{effective: eff, expiresAt: now.Add(localCacheTTL)}
+	s.mu.Unlock()
+	return eff
+}
+
+// Resolve 供 HTTP 中间件查询生效开关
+func (s *Service) Resolve(ctx context.Context, tenantID string) (readOnly, generationPaused bool, message string) {
+	eff := s.Effective(ctx, tenantID)
+	return eff.ReadOnly, eff.GenerationPaused, eff.Message
+}
+
+// GenerationPaused 供消息消费者判断是否暂停认领（tenantID 为空表示仅看全局）
+func (s *Service) GenerationPaused(ctx context.Context, tenantID string) bool {
+	return s.Effective(ctx, tenantID).GenerationPaused
+}
+
+// Load 读取全局与租户级原始开关（未设置时为 nil）
+func (s *Service) Load(ctx context.Context, tenantID string) (global, tenant *Switches, err error) {
+	keys := []string{globalKey}
+	if tenantID != "" {
+		keys = append(keys, tenantKey(tenantID))
+	}
+	vals, err := s.store.MGet(ctx, keys...)
+	if err != nil {
+		return nil, nil, err
+	}
+	global = decode(vals, 0)
+	tenant = decode(vals, 1)
+	return global, tenant, nil
+}
+
+// Set 设置开关（tenantID 为空表示全局）；全部关闭且无公告时等同于清除。
+func (s *Service) Set(ctx context.Context, tenantID string, sw *Switches) error {
+	if sw.IsZero() {
+		return s.Clear(ctx, tenantID)
+	}
+	sw.Message = strings.TrimSpace(sw.Message)
+	if err := s.store.Set(ctx, scopeKey(tenantID), sw, 0); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Clear 清除开关（tenantID 为空表示全局）
+func (s *Service) Clear(ctx context.Context, tenantID string) error {
+	if err := s.store.Delete(ctx, scopeKey(tenantID)); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// invalidate 清空本进程缓存（其他进程按 localCacheTTL 过期）
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedEffective)
+	s.mu.Unlock()
+}
+
+// Merge 合并全局与租户级开关
+func Merge(global, tenant *Switches) *Effective {
+	eff := &Effective{Global: global, Tenant: tenant}
+	for _, sw := range []*Switches{global, tenant} {
+		if sw == nil {
+			continue
+		}
+		eff.ReadOnly = eff.ReadOnly || sw.ReadOnly
+		eff.GenerationPaused = eff.GenerationPaused || sw.GenerationPaused
+		if strings.TrimSpace(sw.Message) != "" {
+			eff.Message = sw.Message
+		}
+	}
+	return eff
+}
+
+func decode(vals [][]byte, i int) *Switches {
+	if i >= len(vals) || len(vals[i]) == 0 {
+		return nil
+	}
+	var sw Switches
+	if err := json.Unmarshal(vals[i], &sw); err != nil {
+		return nil
+	}
+	return &sw
+}
+
+func scopeKey(tenantID string) string {
+	if tenantID == "" {
+		return globalKey
+	}
+	return tenantKey(tenantID)
+}
+
+func tenantKey(tenantID string) string {
+	return tenantKeyPrefix + tenantID
+}
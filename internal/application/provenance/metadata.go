@@ -0,0 +1,75 @@
+package provenance
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const metadataPrefix = "ai-provenance:"
+
+// metadataBlockPattern 匹配文末元数据块 <!-- ai-provenance: {...} -->
+var metadataBlockPattern = regexp.MustCompile(`(?s)\n*<!--\s*ai-provenance:\s*(\{.*?\})\s*-->\n*`)
+
+// metadataBlock 元数据块内容（明文可读，便于出版方人工核对 AI 参与声明）
+type metadataBlock struct {
+	Version       int    `json:"v"`
+	Generator     string `json:"generator"`
+	Disclosure    string `json:"disclosure"`
+	TenantID      string `json:"tenant_id"`
+	ProjectID     string `json:"project_id"`
+	IssuedAt      int64  `json:"issued_at"`
+	ContentSHA256 string `json:"content_sha256"`
+	Signature     string `json:"sig"`
+}
+
+// appendMetadata 在文末追加签名元数据块；签名覆盖正文摘要，正文被修改后 ContentIntact 为 false
+func (w *Watermarker) appendMetadata(text string, marker Marker) string {
+	digest := contentDigest(text)
+	block := metadataBlock{
+		Version:       1,
+		Generator:     Generator,
+		Disclosure:    "AI-assisted",
+		TenantID:      marker.TenantID,
+		ProjectID:     marker.ProjectID,
+		IssuedAt:      marker.IssuedAt.Unix(),
+		ContentSHA256: digest,
+		Signature:     hex.EncodeToString(w.sign(marker, digest)),
+	}
+	raw, _ := json.Marshal(block)
+	return strings.TrimRight(text, "\n") + "\n\n<!-- " + metadataPrefix + " " + string(raw) + " -->\n"
+}
+
+// verifyMetadata 校验元数据块签名，返回来源信息与记录的正文摘要
+func (w *Watermarker) verifyMetadata(raw string) (*Marker, string, bool) {
+	var block metadataBlock
+	if err := json.Unmarshal([]byte(raw), &block); err != nil || block.Version != 1 {
+		return nil, "", false
+	}
+	marker := Marker{
+		TenantID:  block.TenantID,
+		ProjectID: block.ProjectID,
+		IssuedAt:  time.Unix(block.IssuedAt, 0).UTC(),
+	}
+	sig, err := hex.DecodeString(block.Signature)
+	if err != nil || !hmac.Equal(sig, w.sign(marker, block.ContentSHA256)) {
+		return nil, "", false
+	}
+	return &marker, block.ContentSHA256, true
+}
+
+// findMetadataBlock 查找最后一个元数据块
+func findMetadataBlock(text string) (string, bool) {
+	matches := metadataBlockPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[len(matches)-1][1], true
+}
+
+func removeMetadataBlocks(text string) string {
+	return metadataBlockPattern.ReplaceAllString(text, "\n")
+}
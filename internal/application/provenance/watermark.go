@@ -0,0 +1,161 @@
+// Package provenance 提供导出成稿的 AI 来源标记（水印）嵌入与校验
+package provenance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mode 水印嵌入方式
+type Mode string
+
+const (
+	// ModeMetadata 在文末追加签名元数据块（HTML 注释，Markdown 渲染后不可见）
+	ModeMetadata Mode = "metadata"
+	// ModeZeroWidth 在段落末尾嵌入零宽字符编码的签名标记（对读者不可见，片段摘录仍可校验）
+	ModeZeroWidth Mode = "zero_width"
+	// ModeBoth 同时嵌入元数据块与零宽标记
+	ModeBoth Mode = "both"
+)
+
+// Generator 写入元数据块的生成系统标识
+const Generator = "z-novel-ai-api"
+
+// ParseMode 解析配置中的水印方式（未知值回退为 metadata）
+func ParseMode(s string) Mode {
+	switch Mode(strings.ToLower(strings.TrimSpace(s))) {
+	case ModeZeroWidth:
+		return ModeZeroWidth
+	case ModeBoth:
+		return ModeBoth
+	default:
+		return ModeMetadata
+	}
+}
+
+// Marker 嵌入水印的来源信息
+type Marker struct {
+	TenantID  string
+	ProjectID string
+	IssuedAt  time.Time
+}
+
+// Method 校验命中的水印方式
+type Method string
+
+const (
+	MethodMetadata  Method = "metadata"
+	MethodZeroWidth Method = "zero_width"
+)
+
+// Verification 水印校验结果
+type Verification struct {
+	// Watermarked 文本中存在本系统格式的水印（签名未必有效）
+	Watermarked bool
+	// Valid 至少一个水印签名校验通过，即文本由本系统导出
+	Valid   bool
+	Methods []Method
+	Marker  *Marker
+	// ContentIntact 元数据块记录的正文摘要与当前文本一致（无元数据块时为 nil）
+	ContentIntact *bool
+	// ZeroWidthMarks 签名有效的零宽标记数量
+	ZeroWidthMarks int
+}
+
+// Watermarker 水印嵌入与校验器；签名使用 HMAC-SHA256，校验无需查询数据库
+type Watermarker struct {
+	secret []byte
+	mode   Mode
+	now    func() time.Time
+}
+
+// NewWatermarker 创建水印器；secret 为空时返回 nil（表示未启用）
+func NewWatermarker(secret string, mode Mode) *Watermarker {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil
+	}
+	return &Watermarker{secret: []byte(secret), mode: mode, now: time.Now}
+}
+
+// Enabled 是否已启用
+func (w *Watermarker) Enabled() bool {
+	return w != nil
+}
+
+// Embed 按配置的方式为成稿嵌入水印；未启用时原样返回
+func (w *Watermarker) Embed(text string, marker Marker) string {
+	if !w.Enabled() {
+		return text
+	}
+	if marker.IssuedAt.IsZero() {
+		marker.IssuedAt = w.now()
+	}
+	marker.IssuedAt = marker.IssuedAt.UTC().Truncate(time.Second)
+
+	out := text
+	if w.mode == ModeZeroWidth || w.mode == ModeBoth {
+		out = w.embedZeroWidth(out, marker)
+	}
+	if w.mode == ModeMetadata || w.mode == ModeBoth {
+		out = w.appendMetadata(out, marker)
+	}
+	return out
+}
+
+// Verify 校验文本中的水印；多个水印时以元数据块中的来源信息为准
+func (w *Watermarker) Verify(text string) *Verification {
+	result := &Verification{}
+	if !w.Enabled() {
+		return result
+	}
+
+	if block, ok := findMetadataBlock(text); ok {
+		result.Watermarked = true
+		if marker, digest, valid := w.verifyMetadata(block); valid {
+			result.Valid = true
+			result.Methods = append(result.Methods, MethodMetadata)
+			result.Marker = marker
+			intact := hmac.Equal([]byte(digest), []byte(contentDigest(text)))
+			result.ContentIntact = &intact
+		}
+	}
+
+	marks, found := w.extractZeroWidth(text)
+	if found {
+		result.Watermarked = true
+	}
+	if len(marks) > 0 {
+		result.Valid = true
+		result.Methods = append(result.Methods, MethodZeroWidth)
+		result.ZeroWidthMarks = len(marks)
+		if result.Marker == nil {
+			result.Marker = marks[0]
+		}
+	}
+	return result
+}
+
+// sign 计算来源信息签名（contentSHA256 为空时仅覆盖来源字段）
+func (w *Watermarker) sign(marker Marker, contentSHA256 string) []byte {
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write([]byte(strings.Join([]string{
+		"v1",
+		marker.TenantID,
+		marker.ProjectID,
+		strconv.FormatInt(marker.IssuedAt.Unix(), 10),
+		contentSHA256,
+	}, "|")))
+	return mac.Sum(nil)
+}
+
+// contentDigest 计算去除水印后的正文摘要（首尾空白不计入）
+func contentDigest(text string) string {
+	stripped := strings.TrimSpace(stripZeroWidth(removeMetadataBlocks(text)))
+	sum := sha256.Sum256([]byte(stripped))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package provenance
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// zeroWidthBoundary 标记起止符（U+FEFF 零宽不换行空格）
+	zeroWidthBoundary = '\uFEFF'
+	// zeroWidthMarkEvery 每隔多少个段落嵌入一次标记（首段必嵌入）
+	zeroWidthMarkEvery = 10
+	// zeroWidthMACSize 标记内截断的签名字节数
+	zeroWidthMACSize = 10
+	// zeroWidthPayloadSize 版本(1) + 租户 UUID(16) + 项目 UUID(16) + 时间戳(8) + 签名
+	zeroWidthPayloadSize = 1 + 16 + 16 + 8 + zeroWidthMACSize
+)
+
+// zeroWidthDigits 每个零宽字符编码 2 bit（ZWSP / ZWNJ / ZWJ / WORD JOINER）
+var zeroWidthDigits = [4]rune{'\u200B', '\u200C', '\u200D', '\u2060'}
+
+// embedZeroWidth 在首段及此后每隔 zeroWidthMarkEvery 段的段尾嵌入零宽标记
+func (w *Watermarker) embedZeroWidth(text string, marker Marker) string {
+	mark, ok := w.encodeZeroWidth(marker)
+	if !ok {
+		return text
+	}
+	paragraphs := strings.Split(text, "\n\n")
+	for i := range paragraphs {
+		if i%zeroWidthMarkEvery != 0 || strings.TrimSpace(paragraphs[i]) == "" {
+			continue
+		}
+		paragraphs[i] = strings.TrimRight(paragraphs[i], "\n") + mark + trailingNewlines(paragraphs[i])
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// encodeZeroWidth 将来源信息编码为零宽字符串；ID 非 UUID 时无法紧凑编码，跳过嵌入
+func (w *Watermarker) encodeZeroWidth(marker Marker) (string, bool) {
+	tenantID, err := uuid.Parse(marker.TenantID)
+	if err != nil {
+		return "", false
+	}
+	projectID, err := uuid.Parse(marker.ProjectID)
+	if err != nil {
+		return "", false
+	}
+
+	payload := make([]byte, 0, zeroWidthPayloadSize)
+	payload = append(payload, 1)
+	payload = append(payload, tenantID[:]...)
+	payload = append(payload, projectID[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(marker.IssuedAt.Unix()))
+	payload = append(payload, w.sign(marker, "")[:zeroWidthMACSize]...)
+
+	var b strings.Builder
+	b.WriteRune(zeroWidthBoundary)
+	for _, by := range payload {
+		for shift := 6; shift >= 0; shift -= 2 {
+			b.WriteRune(zeroWidthDigits[(by>>shift)&0x3])
+		}
+	}
+	b.WriteRune(zeroWidthBoundary)
+	return b.String(), true
+}
+
+// extractZeroWidth 提取并校验文本中的零宽标记；found 表示存在格式正确的标记（签名未必有效）
+func (w *Watermarker) extractZeroWidth(text string) (marks []*Marker, found bool) {
+	parts := strings.Split(text, string(zeroWidthBoundary))
+	// 起止符成对出现，奇数下标为标记内容
+	for i := 1; i < len(parts)-1; i += 2 {
+		payload, ok := decodeZeroWidth(parts[i])
+		if !ok {
+			continue
+		}
+		found = true
+		if marker, valid := w.verifyZeroWidth(payload); valid {
+			marks = append(marks, marker)
+		}
+	}
+	return marks, found
+}
+
+func decodeZeroWidth(s string) ([]byte, bool) {
+	runes := []rune(s)
+	if len(runes) != zeroWidthPayloadSize*4 {
+		return nil, false
+	}
+	payload := make([]byte, zeroWidthPayloadSize)
+	for i, r := range runes {
+		digit := -1
+		for d, zw := range zeroWidthDigits {
+			if r == zw {
+				digit = d
+				break
+			}
+		}
+		if digit < 0 {
+			return nil, false
+		}
+		payload[i/4] |= byte(digit) << (6 - 2*(i%4))
+	}
+	return payload, true
+}
+
+func (w *Watermarker) verifyZeroWidth(payload []byte) (*Marker, bool) {
+	if payload[0] != 1 {
+		return nil, false
+	}
+	tenantID, _ := uuid.FromBytes(payload[1:17])
+	projectID, _ := uuid.FromBytes(payload[17:33])
+	marker := Marker{
+		TenantID:  tenantID.String(),
+		ProjectID: projectID.String(),
+		IssuedAt:  time.Unix(int64(binary.BigEndian.Uint64(payload[33:41])), 0).UTC(),
+	}
+	if !hmac.Equal(payload[41:], w.sign(marker, "")[:zeroWidthMACSize]) {
+		return nil, false
+	}
+	return &marker, true
+}
+
+// stripZeroWidth 移除水印使用的零宽字符
+func stripZeroWidth(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == zeroWidthBoundary {
+			return -1
+		}
+		for _, zw := range zeroWidthDigits {
+			if r == zw {
+				return -1
+			}
+		}
+		return r
+	}, text)
+}
+
+func trailingNewlines(s string) string {
+	return s[len(strings.TrimRight(s, "\n")):]
+}
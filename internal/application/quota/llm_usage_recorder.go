@@ -10,15 +10,19 @@ import (
 	"z-novel-ai-api/internal/domain/service"
 )
 
+// LLMUsageRecorder 按调用扣减租户余额并记录用量流水。
+// 调用归属于某个任务时同步收缩该任务的配额预留：已扣减的部分不再计入占用，避免可用余额被重复计算。
 type LLMUsageRecorder struct {
-	tenantRepo repository.TenantRepository
-	usageRepo  repository.LLMUsageEventRepository
+	tenantRepo      repository.TenantRepository
+	usageRepo       repository.LLMUsageEventRepository
+	reservationRepo repository.QuotaReservationRepository
 }
 
-func NewLLMUsageRecorder(tenantRepo repository.TenantRepository, usageRepo repository.LLMUsageEventRepository) *LLMUsageRecorder {
+func NewLLMUsageRecorder(tenantRepo repository.TenantRepository, usageRepo repository.LLMUsageEventRepository, reservationRepo repository.QuotaReservationRepository) *LLMUsageRecorder {
 	return &LLMUsageRecorder{
-		tenantRepo: tenantRepo,
-		usageRepo:  usageRepo,
+		tenantRepo:      tenantRepo,
+		usageRepo:       usageRepo,
+		reservationRepo: reservationRepo,
 	}
 }
 
@@ -38,6 +42,9 @@ func (r *LLMUsageRecorder) Record(ctx context.Context, in service.LLMUsageInput)
 	totalTokens := int64(in.PromptTokens + in.CompletionTokens)
 	if totalTokens > 0 {
 		_ = r.tenantRepo.DeductBalance(ctx, tenantID, totalTokens)
+		if jobID := strings.TrimSpace(in.JobID); jobID != "" && r.reservationRepo != nil {
+			_ = r.reservationRepo.Consume(ctx, jobID, totalTokens)
+		}
 	}
 
 	evt := &entity.LLMUsageEvent{
@@ -45,6 +52,9 @@ func (r *LLMUsageRecorder) Record(ctx context.Context, in service.LLMUsageInput)
 		Provider:         strings.TrimSpace(in.Provider),
 		Model:            strings.TrimSpace(in.Model),
 		Workflow:         strings.TrimSpace(in.Workflow),
+		ProjectID:        optionalID(in.ProjectID),
+		JobID:            optionalID(in.JobID),
+		JobType:          strings.TrimSpace(in.JobType),
 		TokensPrompt:     in.PromptTokens,
 		TokensCompletion: in.CompletionTokens,
 		DurationMs:       in.DurationMs,
@@ -52,3 +62,12 @@ func (r *LLMUsageRecorder) Record(ctx context.Context, in service.LLMUsageInput)
 	_ = r.usageRepo.Create(ctx, evt)
 	return nil
 }
+
+// optionalID 空 ID 记为 NULL（uuid 列不接受空字符串）
+func optionalID(id string) *string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil
+	}
+	return &id
+}
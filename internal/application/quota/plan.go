@@ -0,0 +1,238 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+const (
+	// DefaultPlanCode 新建租户默认关联的套餐
+	DefaultPlanCode = "free"
+
+	// planLimitCacheTTL 限流参数缓存时间（限流中间件每个请求都会查询）
+	planLimitCacheTTL = time.Minute
+)
+
+// ErrPlanNotFound 套餐不存在或已下架
+var ErrPlanNotFound = errors.New("plan not found")
+
+// PlanChange 套餐变更结果
+type PlanChange struct {
+	Tenant       *entity.Tenant
+	PreviousPlan *entity.Plan
+	Plan         *entity.Plan
+	// RemainingRatio 变更时当前配额周期的剩余比例（0~1）
+	RemainingRatio float64
+	// BalanceAdjustment 按剩余比例折算的余额调整量（升级为正、降级为负）
+	BalanceAdjustment int64
+}
+
+// PlanService 订阅套餐服务：套餐查询、变更（按周期剩余比例折算余额）与月度配额重置
+type PlanService struct {
+	tenantRepo repository.TenantRepository
+	planRepo   repository.PlanRepository
+
+	mu         sync.Mutex
+	limitCache map[string]cachedPlanLimit
+}
+
+type cachedPlanLimit struct {
+	requestsPerSecond int
+	expiresAt         time.Time
+}
+
+// NewPlanService 创建订阅套餐服务
+func NewPlanService(tenantRepo repository.TenantRepository, planRepo repository.PlanRepository) *PlanService {
+	return &PlanService{
+		tenantRepo: tenantRepo,
+		planRepo:   planRepo,
+		limitCache: make(map[string]cachedPlanLimit),
+	}
+}
+
+// ListPlans 获取可订阅套餐
+func (s *PlanService) ListPlans(ctx context.Context) ([]*entity.Plan, error) {
+	return s.planRepo.List(ctx, true)
+}
+
+// TenantPlan 获取租户及其当前套餐（未关联套餐时 plan 为 nil）
+func (s *PlanService) TenantPlan(ctx context.Context, tenantID string) (*entity.Tenant, *entity.Plan, error) {
+	tenant, err := s.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tenant == nil {
+		return nil, nil, fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	if tenant.PlanID == nil {
+		return tenant, nil, nil
+	}
+	plan, err := s.planRepo.GetByID(ctx, *tenant.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tenant, plan, nil
+}
+
+// AssignDefaultPlan 为新建租户关联默认套餐（默认套餐不存在时保持未关联）
+func (s *PlanService) AssignDefaultPlan(ctx context.Context, tenant *entity.Tenant, now time.Time) error {
+	plan, err := s.planRepo.GetByCode(ctx, DefaultPlanCode)
+	if err != nil {
+		return err
+	}
+	if plan == nil || !plan.IsActive {
+		return nil
+	}
+	tenant.AssignPlan(plan, now)
+	return nil
+}
+
+// ChangePlan 变更租户套餐（需在事务内调用）。
+// 配额周期保持不变；余额按当前周期剩余比例折算两档套餐的月度额度差（降级时余额最低扣至 0）。
+// 租户此前未关联套餐或没有配额周期时，从 now 开启新周期并按新套餐额度补足。
+func (s *PlanService) ChangePlan(ctx context.Context, tenantID, planCode string, now time.Time) (*PlanChange, error) {
+	plan, err := s.planRepo.GetByCode(ctx, strings.TrimSpace(planCode))
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil || !plan.IsActive {
+		return nil, ErrPlanNotFound
+	}
+
+	tenant, err := s.tenantRepo.GetByIDForUpdate(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, fmt.Errorf("tenant not found: %s", tenantID)
+	}
+
+	var previous *entity.Plan
+	if tenant.PlanID != nil {
+		previous, err = s.planRepo.GetByID(ctx, *tenant.PlanID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	change := &PlanChange{Tenant: tenant, PreviousPlan: previous, Plan: plan}
+	if previous != nil && previous.ID == plan.ID {
+		return change, nil
+	}
+
+	if tenant.QuotaPeriodStart == nil || tenant.QuotaPeriodEnd == nil || !now.Before(*tenant.QuotaPeriodEnd) {
+		tenant.StartQuotaPeriod(now)
+	}
+	change.RemainingRatio = remainingRatio(*tenant.QuotaPeriodStart, *tenant.QuotaPeriodEnd, now)
+
+	var previousAllowance int64
+	if previous != nil {
+		previousAllowance = previous.MonthlyTokens
+	}
+	change.BalanceAdjustment = int64(math.Round(float64(plan.MonthlyTokens-previousAllowance) * change.RemainingRatio))
+
+	tenant.PlanID = &plan.ID
+	tenant.TokenBalance += change.BalanceAdjustment
+	if tenant.TokenBalance < 0 {
+		tenant.TokenBalance = 0
+	}
+	tenant.UpdatedAt = now
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.invalidate(tenantID)
+	return change, nil
+}
+
+// ListQuotaResetDue 获取配额周期已到期的租户
+func (s *PlanService) ListQuotaResetDue(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	return s.tenantRepo.ListQuotaResetDue(ctx, now, limit)
+}
+
+// ResetQuota 租户配额周期到期时重置余额为套餐月度额度并滚动周期（需在事务内调用，幂等）。
+// 余额不结转；停机导致错过多个周期时直接滚动到包含 now 的周期。
+func (s *PlanService) ResetQuota(ctx context.Context, tenantID string, now time.Time) (bool, error) {
+	tenant, err := s.tenantRepo.GetByIDForUpdate(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+	if tenant == nil || !tenant.QuotaPeriodDue(now) {
+		return false, nil
+	}
+	plan, err := s.planRepo.GetByID(ctx, *tenant.PlanID)
+	if err != nil {
+		return false, err
+	}
+	if plan == nil {
+		return false, nil
+	}
+
+	start := *tenant.QuotaPeriodEnd
+	for !now.Before(entity.NextQuotaPeriodEnd(start)) {
+		start = entity.NextQuotaPeriodEnd(start)
+	}
+	tenant.StartQuotaPeriod(start)
+	tenant.TokenBalance = plan.MonthlyTokens
+	tenant.UpdatedAt = now
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RequestsPerSecond 返回租户套餐的限流参数（0 表示使用全局配置）；查询失败时回退全局配置。
+func (s *PlanService) RequestsPerSecond(ctx context.Context, tenantID string) int {
+	if s == nil || tenantID == "" {
+		return 0
+	}
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.limitCache[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.requestsPerSecond
+	}
+
+	_, plan, err := s.TenantPlan(ctx, tenantID)
+	if err != nil {
+		return 0
+	}
+	rps := 0
+	if plan != nil {
+		rps = plan.RequestsPerSecond
+	}
+	s.mu.Lock()
+	s.limitCache[tenantID] = cachedPlanLimit{requestsPerSecond: rps, expiresAt: now.Add(planLimitCacheTTL)}
+	s.mu.Unlock()
+	return rps
+}
+
+func (s *PlanService) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.limitCache, tenantID)
+	s.mu.Unlock()
+}
+
+// remainingRatio 计算周期剩余比例
+func remainingRatio(start, end, now time.Time) float64 {
+	total := end.Sub(start)
+	if total <= 0 {
+		return 0
+	}
+	remaining := end.Sub(now)
+	switch {
+	case remaining <= 0:
+		return 0
+	case remaining >= total:
+		return 1
+	}
+	return float64(remaining) / float64(total)
+}
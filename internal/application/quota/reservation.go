@@ -0,0 +1,164 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+const (
+	// reservationTTL 预留有效期：超过后不再计入占用，避免 Worker 异常退出导致额度永久冻结
+	reservationTTL = 2 * time.Hour
+
+	// chapterPromptReserveTokens 章节生成 Prompt（大纲/风格/RAG 上下文）的预留量
+	chapterPromptReserveTokens = 4000
+	// foundationReserveTokens 设定集生成未指定 max_tokens 时的预留量
+	foundationReserveTokens = 16000
+	// foundationPromptReserveTokens 设定集生成 Prompt（含附件）的预留量
+	foundationPromptReserveTokens = 8000
+	// artifactReserveTokens 单个构件生成未指定 max_tokens 时的预估量
+	artifactReserveTokens = 8000
+	// artifactPromptReserveTokens 单个构件生成 Prompt（当前设定 + 会话上下文）的预估量
+	artifactPromptReserveTokens = 6000
+)
+
+// ChapterReserveTokens 按目标字数估算章节生成需预留的 Token（正文约 1 字 1 Token，另留 10% 余量）
+func ChapterReserveTokens(targetWordCount int) int64 {
+	if targetWordCount < 0 {
+		targetWordCount = 0
+	}
+	return int64(chapterPromptReserveTokens + targetWordCount + targetWordCount/10)
+}
+
+// FoundationReserveTokens 估算设定集生成需预留的 Token
+func FoundationReserveTokens(maxTokens *int) int64 {
+	if maxTokens == nil || *maxTokens <= 0 {
+		return foundationReserveTokens
+	}
+	return int64(foundationPromptReserveTokens + *maxTokens)
+}
+
+// ArtifactReserveTokens 估算单个构件生成的 Token（用于多候选生成的预算折算）
+func ArtifactReserveTokens(maxTokens *int) int64 {
+	if maxTokens == nil || *maxTokens <= 0 {
+		return artifactReserveTokens
+	}
+	return int64(artifactPromptReserveTokens + *maxTokens)
+}
+
+// Reserve 为任务预留 Token 额度（需在事务内调用）。
+// 通过锁定租户行串行化同一租户的并发预留：可用余额 = 余额 - 其他任务的有效预留。
+// 余额不足时返回 TokenBalanceExceededError，进行中任务数达到套餐上限时返回 ConcurrencyLimitExceededError；
+// 同一任务重复调用会以新额度覆盖原预留。
+func (c *TokenQuotaChecker) Reserve(ctx context.Context, tenantID, jobID string, amount int64) error {
+	if c == nil {
+		return nil
+	}
+	if strings.TrimSpace(jobID) == "" {
+		return fmt.Errorf("job id is required for quota reservation")
+	}
+	if c.reservationRepo == nil {
+		_, err := c.CheckBalance(ctx, tenantID, amount)
+		return err
+	}
+
+	tenant, err := c.tenantRepo.GetByIDForUpdate(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to lock tenant for quota reservation: %w", err)
+	}
+	if tenant == nil {
+		return fmt.Errorf("tenant not found: %s", tenantID)
+	}
+
+	if err := c.checkConcurrency(ctx, tenant, jobID); err != nil {
+		return err
+	}
+
+	available, err := c.availableBalance(ctx, tenant, jobID)
+	if err != nil {
+		return err
+	}
+	if available < amount {
+		return TokenBalanceExceededError{
+			TenantID: tenantID,
+			Balance:  available,
+			Required: amount,
+		}
+	}
+
+	return c.reservationRepo.Upsert(ctx, entity.NewQuotaReservation(tenantID, jobID, amount, reservationTTL))
+}
+
+// EnsureReserved 确认任务持有有效预留；没有时（如旧消息、预留已过期或重试前已释放）按 amount 重新预留。
+func (c *TokenQuotaChecker) EnsureReserved(ctx context.Context, tenantID, jobID string, amount int64) error {
+	if c == nil {
+		return nil
+	}
+	if c.reservationRepo != nil {
+		existing, err := c.reservationRepo.GetByJobID(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.IsActive(time.Now()) {
+			return nil
+		}
+	}
+	return c.Reserve(ctx, tenantID, jobID, amount)
+}
+
+// Settle 任务完成时结算预留。
+// 实际用量已由 LLMUsageRecorder 按调用逐次扣减余额（同时收缩预留），这里仅记录实际用量并解除剩余占用。
+func (c *TokenQuotaChecker) Settle(ctx context.Context, jobID string, actualTokens int64) error {
+	if c == nil || c.reservationRepo == nil || strings.TrimSpace(jobID) == "" {
+		return nil
+	}
+	_, err := c.reservationRepo.Finish(ctx, jobID, entity.QuotaReservationSettled, actualTokens)
+	return err
+}
+
+// Release 任务失败或取消时释放预留（幂等）。
+func (c *TokenQuotaChecker) Release(ctx context.Context, jobID string) error {
+	if c == nil || c.reservationRepo == nil || strings.TrimSpace(jobID) == "" {
+		return nil
+	}
+	_, err := c.reservationRepo.Finish(ctx, jobID, entity.QuotaReservationReleased, 0)
+	return err
+}
+
+// checkConcurrency 按套餐并发上限检查进行中任务数（有效预留数即进行中任务数）
+func (c *TokenQuotaChecker) checkConcurrency(ctx context.Context, tenant *entity.Tenant, jobID string) error {
+	plan, err := c.tenantPlan(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if plan == nil || plan.MaxConcurrentJobs <= 0 {
+		return nil
+	}
+	active, err := c.reservationRepo.CountActive(ctx, tenant.ID, jobID)
+	if err != nil {
+		return err
+	}
+	if active >= int64(plan.MaxConcurrentJobs) {
+		return ConcurrencyLimitExceededError{
+			TenantID: tenant.ID,
+			Limit:    plan.MaxConcurrentJobs,
+			Active:   active,
+		}
+	}
+	return nil
+}
+
+// availableBalance 余额扣除有效预留后的可用额度
+func (c *TokenQuotaChecker) availableBalance(ctx context.Context, tenant *entity.Tenant, excludeJobID string) (int64, error) {
+	if c.reservationRepo == nil {
+		return tenant.TokenBalance, nil
+	}
+	reserved, err := c.reservationRepo.SumActive(ctx, tenant.ID, excludeJobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum quota reservations: %w", err)
+	}
+	return tenant.TokenBalance - reserved, nil
+}
@@ -0,0 +1,178 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/service"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+type reservationFixture struct {
+	store        *memrepo.Store
+	tx           *memrepo.TxManager
+	tenants      *memrepo.TenantRepository
+	reservations *memrepo.QuotaReservationRepository
+	checker      *TokenQuotaChecker
+	recorder     *LLMUsageRecorder
+	tenant       *entity.Tenant
+}
+
+func newReservationFixture(t *testing.T, balance int64) *reservationFixture {
+	t.Helper()
+	store := memrepo.NewStore()
+	f := &reservationFixture{
+		store:        store,
+		tx:           memrepo.NewTxManager(store),
+		tenants:      memrepo.NewTenantRepository(store),
+		reservations: memrepo.NewQuotaReservationRepository(store),
+	}
+	f.checker = NewTokenQuotaChecker(f.tenants, f.reservations, memrepo.NewPlanRepository(store))
+	f.recorder = NewLLMUsageRecorder(f.tenants, memrepo.NewLLMUsageEventRepository(store), f.reservations)
+	f.tenant = entity.NewTenant("t", "t")
+	f.tenant.TokenBalance = balance
+	if err := f.tenants.Create(context.Background(), f.tenant); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *reservationFixture) available(t *testing.T) int64 {
+	t.Helper()
+	available, err := f.checker.CheckBalance(context.Background(), f.tenant.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return available
+}
+
+func TestReserveSerializesConcurrentJobsOnSameTenant(t *testing.T) {
+	f := newReservationFixture(t, 10000)
+	// 放大“汇总有效预留”与“写入预留”之间的窗口：没有租户行锁时并发预留会同时通过余额检查
+	f.store.Now = func() time.Time {
+		time.Sleep(time.Millisecond)
+		return time.Now()
+	}
+
+	const jobs = 8
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+		rejected int
+	)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var reserveErr error
+			// 预留失败不回滚事务：内存事务的回滚会恢复整个数据集，与并发事务互相覆盖
+			err := f.tx.WithTransaction(context.Background(), func(ctx context.Context) error {
+				reserveErr = f.checker.Reserve(ctx, f.tenant.ID, fmt.Sprintf("job-%d", i), 3000)
+				return nil
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			var exceeded TokenBalanceExceededError
+			switch {
+			case err != nil:
+				t.Error(err)
+			case reserveErr == nil:
+				reserved++
+			case errors.As(reserveErr, &exceeded):
+				rejected++
+			default:
+				t.Error(reserveErr)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if reserved != 3 || rejected != jobs-3 {
+		t.Fatalf("reserved=%d rejected=%d, want 3 and %d", reserved, rejected, jobs-3)
+	}
+	if got := f.available(t); got != 1000 {
+		t.Fatalf("available = %d, want 1000", got)
+	}
+}
+
+func TestReservationShrinksWithUsageAndSettles(t *testing.T) {
+	ctx := context.Background()
+	f := newReservationFixture(t, 10000)
+	if err := f.checker.Reserve(ctx, f.tenant.ID, "job-1", 5000); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.available(t); got != 5000 {
+		t.Fatalf("available after reserve = %d, want 5000", got)
+	}
+
+	// 逐次调用扣减余额的同时收缩预留：可用额度不因同一笔用量被扣两次而下降
+	record := func(jobID string, prompt, completion int) {
+		t.Helper()
+		if err := f.recorder.Record(ctx, service.LLMUsageInput{TenantID: f.tenant.ID, JobID: jobID, PromptTokens: prompt, CompletionTokens: completion}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("job-1", 800, 400)
+	record("job-1", 700, 1100)
+	if got := f.available(t); got != 5000 {
+		t.Fatalf("available after usage = %d, want 5000 (balance 7000 - remaining reservation 2000)", got)
+	}
+
+	// 超出预留的用量将预留收缩到 0，而非负数
+	record("job-1", 0, 2500)
+	reservation, _ := f.reservations.GetByJobID(ctx, "job-1")
+	if reservation.Amount != 0 {
+		t.Fatalf("reservation amount = %d, want 0", reservation.Amount)
+	}
+	if got := f.available(t); got != 4500 {
+		t.Fatalf("available after overrun = %d, want 4500", got)
+	}
+
+	// 结算记录实际用量并解除占用；重复结算与结算后的释放不改变终态
+	if err := f.checker.Settle(ctx, "job-1", 5500); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.checker.Settle(ctx, "job-1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.checker.Release(ctx, "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	reservation, _ = f.reservations.GetByJobID(ctx, "job-1")
+	if reservation.Status != entity.QuotaReservationSettled || reservation.SettledTokens != 5500 {
+		t.Fatalf("reservation = %s/%d, want settled with 5500 tokens", reservation.Status, reservation.SettledTokens)
+	}
+	if got := f.available(t); got != 4500 {
+		t.Fatalf("available after settle = %d, want 4500", got)
+	}
+
+	// 不属于任何任务的调用只扣余额
+	record("", 500, 0)
+	if got := f.available(t); got != 4000 {
+		t.Fatalf("available after unattributed usage = %d, want 4000", got)
+	}
+}
+
+func TestReleaseFreesReservation(t *testing.T) {
+	ctx := context.Background()
+	f := newReservationFixture(t, 6000)
+	if err := f.checker.Reserve(ctx, f.tenant.ID, "job-1", 4000); err != nil {
+		t.Fatal(err)
+	}
+	var exceeded TokenBalanceExceededError
+	if err := f.checker.Reserve(ctx, f.tenant.ID, "job-2", 4000); !errors.As(err, &exceeded) {
+		t.Fatalf("second reservation err = %v, want balance exceeded", err)
+	}
+	if err := f.checker.Release(ctx, "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.checker.Reserve(ctx, f.tenant.ID, "job-2", 4000); err != nil {
+		t.Fatalf("reservation after release: %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 )
 
@@ -19,18 +20,43 @@ func (e TokenBalanceExceededError) Error() string {
 	return fmt.Sprintf("token balance insufficient: tenant=%s balance=%d required=%d", e.TenantID, e.Balance, e.Required)
 }
 
-// TokenQuotaChecker 用于检查租户 Token 余额
+// ConcurrencyLimitExceededError 表示租户进行中的生成任务数已达套餐上限
+type ConcurrencyLimitExceededError struct {
+	TenantID string
+	Limit    int
+	Active   int64
+}
+
+func (e ConcurrencyLimitExceededError) Error() string {
+	return fmt.Sprintf("concurrent job limit reached: tenant=%s active=%d limit=%d", e.TenantID, e.Active, e.Limit)
+}
+
+// PlanFeatureUnavailableError 表示租户套餐未开通该功能
+type PlanFeatureUnavailableError struct {
+	TenantID string
+	Feature  entity.PlanFeature
+}
+
+func (e PlanFeatureUnavailableError) Error() string {
+	return fmt.Sprintf("feature not available in current plan: tenant=%s feature=%s", e.TenantID, e.Feature)
+}
+
+// TokenQuotaChecker 用于检查租户 Token 余额，并管理任务级配额预留（见 reservation.go）与套餐限制
 type TokenQuotaChecker struct {
-	tenantRepo repository.TenantRepository
+	tenantRepo      repository.TenantRepository
+	reservationRepo repository.QuotaReservationRepository
+	planRepo        repository.PlanRepository
 }
 
-func NewTokenQuotaChecker(tenantRepo repository.TenantRepository) *TokenQuotaChecker {
+func NewTokenQuotaChecker(tenantRepo repository.TenantRepository, reservationRepo repository.QuotaReservationRepository, planRepo repository.PlanRepository) *TokenQuotaChecker {
 	return &TokenQuotaChecker{
-		tenantRepo: tenantRepo,
+		tenantRepo:      tenantRepo,
+		reservationRepo: reservationRepo,
+		planRepo:        planRepo,
 	}
 }
 
-// CheckBalance 检查租户余额是否充足
+// CheckBalance 检查租户可用余额（余额扣除有效预留后）是否充足
 func (c *TokenQuotaChecker) CheckBalance(ctx context.Context, tenantID string, required int64) (balance int64, err error) {
 	tenant, err := c.tenantRepo.GetByID(ctx, tenantID)
 	if err != nil {
@@ -40,13 +66,51 @@ func (c *TokenQuotaChecker) CheckBalance(ctx context.Context, tenantID string, r
 		return 0, fmt.Errorf("tenant not found: %s", tenantID)
 	}
 
-	if !tenant.HasSufficientBalance(required) {
-		return tenant.TokenBalance, TokenBalanceExceededError{
+	available, err := c.availableBalance(ctx, tenant, "")
+	if err != nil {
+		return 0, err
+	}
+	if available < required {
+		return available, TokenBalanceExceededError{
 			TenantID: tenantID,
-			Balance:  tenant.TokenBalance,
+			Balance:  available,
 			Required: required,
 		}
 	}
 
-	return tenant.TokenBalance, nil
+	return available, nil
+}
+
+// CheckFeature 检查租户套餐是否开通指定功能（未关联套餐的租户不受限制）
+func (c *TokenQuotaChecker) CheckFeature(ctx context.Context, tenantID string, feature entity.PlanFeature) error {
+	if c == nil {
+		return nil
+	}
+	tenant, err := c.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant for plan check: %w", err)
+	}
+	if tenant == nil {
+		return fmt.Errorf("tenant not found: %s", tenantID)
+	}
+	plan, err := c.tenantPlan(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if !plan.HasFeature(feature) {
+		return PlanFeatureUnavailableError{TenantID: tenantID, Feature: feature}
+	}
+	return nil
+}
+
+// tenantPlan 获取租户当前套餐（未关联时返回 nil）
+func (c *TokenQuotaChecker) tenantPlan(ctx context.Context, tenant *entity.Tenant) (*entity.Plan, error) {
+	if c.planRepo == nil || tenant.PlanID == nil {
+		return nil, nil
+	}
+	plan, err := c.planRepo.GetByID(ctx, *tenant.PlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant plan: %w", err)
+	}
+	return plan, nil
 }
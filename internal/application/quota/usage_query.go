@@ -0,0 +1,105 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+const (
+	// UsageExportMaxRows 单次导出的最大行数，超出时要求缩小时间范围或增加筛选条件
+	UsageExportMaxRows = 100000
+
+	// usageExportBatch 导出时每批读取的行数
+	usageExportBatch = 1000
+)
+
+// ErrUsageExportTooLarge 导出行数超过上限
+var ErrUsageExportTooLarge = errors.New("usage export too large")
+
+// UsageQuery LLM 用量流水查询：按租户在事务内绑定 RLS 租户后查询，供财务对账与容量规划
+type UsageQuery struct {
+	usageRepo repository.LLMUsageEventRepository
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+}
+
+// NewUsageQuery 创建用量流水查询服务
+func NewUsageQuery(usageRepo repository.LLMUsageEventRepository, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *UsageQuery {
+	return &UsageQuery{
+		usageRepo: usageRepo,
+		txMgr:     txMgr,
+		tenantCtx: tenantCtx,
+	}
+}
+
+// List 分页查询租户用量流水，并汇总全部符合条件的记录
+func (q *UsageQuery) List(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.LLMUsageEvent], *repository.LLMUsageSummary, error) {
+	var (
+		result  *repository.PagedResult[*entity.LLMUsageEvent]
+		summary *repository.LLMUsageSummary
+	)
+	err := q.withTenant(ctx, tenantID, func(txCtx context.Context) error {
+		var err error
+		if result, err = q.usageRepo.List(txCtx, tenantID, filter, pagination); err != nil {
+			return err
+		}
+		summary, err = q.usageRepo.Summarize(txCtx, tenantID, filter)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, summary, nil
+}
+
+// Export 按时间升序逐条回调符合条件的用量流水，返回导出行数；
+// 超过 UsageExportMaxRows 时在回调任何记录前返回 ErrUsageExportTooLarge
+func (q *UsageQuery) Export(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, fn func(*entity.LLMUsageEvent) error) (int, error) {
+	exported := 0
+	err := q.withTenant(ctx, tenantID, func(txCtx context.Context) error {
+		summary, err := q.usageRepo.Summarize(txCtx, tenantID, filter)
+		if err != nil {
+			return err
+		}
+		if summary.Events > UsageExportMaxRows {
+			return ErrUsageExportTooLarge
+		}
+
+		var (
+			afterCreatedAt time.Time
+			afterID        string
+		)
+		for exported < UsageExportMaxRows {
+			batch, err := q.usageRepo.ListAfter(txCtx, tenantID, filter, afterCreatedAt, afterID, usageExportBatch)
+			if err != nil {
+				return err
+			}
+			for _, e := range batch {
+				if err := fn(e); err != nil {
+					return err
+				}
+				exported++
+			}
+			if len(batch) < usageExportBatch {
+				return nil
+			}
+			last := batch[len(batch)-1]
+			afterCreatedAt, afterID = last.CreatedAt, last.ID
+		}
+		return nil
+	})
+	return exported, err
+}
+
+func (q *UsageQuery) withTenant(ctx context.Context, tenantID string, fn func(txCtx context.Context) error) error {
+	return q.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := q.tenantCtx.SetTenant(txCtx, tenantID); err != nil {
+			return err
+		}
+		return fn(txCtx)
+	})
+}
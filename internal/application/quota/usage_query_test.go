@@ -0,0 +1,128 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/domain/service"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestUsageQueryFiltersAndExports(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	tenants := memrepo.NewTenantRepository(store)
+	usage := memrepo.NewLLMUsageEventRepository(store)
+
+	tenant := entity.NewTenant("t", "t")
+	if err := tenants.Create(ctx, tenant); err != nil {
+		t.Fatal(err)
+	}
+	other := entity.NewTenant("o", "o")
+	if err := tenants.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	// 经用量回调记录：归属随 context 传入
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	recorder := NewLLMUsageRecorder(tenants, usage, nil)
+	record := func(at time.Time, tenantID, provider string, attr service.UsageAttribution) {
+		store.Now = func() time.Time { return at }
+		err := recorder.Record(ctx, service.LLMUsageInput{
+			TenantID:         tenantID,
+			Workflow:         "chapter_generate",
+			Provider:         provider,
+			Model:            "m1",
+			ProjectID:        attr.ProjectID,
+			JobID:            attr.JobID,
+			JobType:          attr.JobType,
+			PromptTokens:     10,
+			CompletionTokens: 5,
+			DurationMs:       100,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	projectID := "11111111-1111-1111-1111-111111111111"
+	chapterJob := service.UsageAttribution{ProjectID: projectID, JobID: "22222222-2222-2222-2222-222222222222", JobType: string(entity.JobTypeChapterGen)}
+	record(base, tenant.ID, "openai", chapterJob)
+	record(base.Add(time.Hour), tenant.ID, "openai", service.UsageAttribution{})
+	record(base.Add(2*time.Hour), tenant.ID, "anthropic", chapterJob)
+	record(base.Add(24*time.Hour), tenant.ID, "openai", chapterJob)
+	record(base.Add(time.Hour), other.ID, "openai", chapterJob)
+
+	q := NewUsageQuery(usage, memrepo.NewTxManager(store), memrepo.NewTenantContext(store))
+
+	filter := &repository.LLMUsageEventFilter{
+		Start:     base,
+		End:       base.Add(24 * time.Hour),
+		ProjectID: projectID,
+		JobType:   string(entity.JobTypeChapterGen),
+	}
+	result, summary, err := q.List(ctx, tenant.ID, filter, repository.NewPagination(1, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || summary.Events != 2 || summary.TokensPrompt != 20 || summary.TokensCompletion != 10 {
+		t.Fatalf("unexpected result: total=%d summary=%+v", result.Total, summary)
+	}
+	// 按时间倒序
+	if result.Items[0].Provider != "anthropic" || result.Items[1].JobID == nil || *result.Items[1].JobID != chapterJob.JobID {
+		t.Fatalf("unexpected items: %+v %+v", result.Items[0], result.Items[1])
+	}
+
+	filter = &repository.LLMUsageEventFilter{Provider: "openai"}
+	var exported []*entity.LLMUsageEvent
+	n, err := q.Export(ctx, tenant.ID, filter, func(e *entity.LLMUsageEvent) error {
+		exported = append(exported, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按时间升序，不含其他租户；未归属的调用项目为空
+	if n != 3 || len(exported) != 3 || !exported[0].CreatedAt.Equal(base) || exported[1].ProjectID != nil {
+		t.Fatalf("unexpected export: n=%d %+v", n, exported)
+	}
+}
+
+func TestUsageQueryExportPagesThroughBatches(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	usage := memrepo.NewLLMUsageEventRepository(store)
+
+	tenantID := "33333333-3333-3333-3333-333333333333"
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	total := usageExportBatch + 5
+	for i := 0; i < total; i++ {
+		// 同一时刻的记录按 ID 排序，游标不能跳过或重复
+		if err := usage.Create(ctx, &entity.LLMUsageEvent{
+			TenantID:  tenantID,
+			Provider:  "openai",
+			Model:     "m1",
+			CreatedAt: base.Add(time.Duration(i/10) * time.Second),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := NewUsageQuery(usage, memrepo.NewTxManager(store), memrepo.NewTenantContext(store))
+	seen := make(map[string]bool)
+	n, err := q.Export(ctx, tenantID, nil, func(e *entity.LLMUsageEvent) error {
+		if seen[e.ID] {
+			t.Fatalf("duplicate row %s", e.ID)
+		}
+		seen[e.ID] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != total || len(seen) != total {
+		t.Fatalf("expected %d rows, got %d", total, n)
+	}
+}
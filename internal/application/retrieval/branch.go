@@ -0,0 +1,93 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// MainBranchKey 主线分支：章节、笔记与激活构件的片段均属于主线（canon）
+const MainBranchKey = "main"
+
+// ErrBranchIndexUnsupported 表示向量存储不支持分支隔离（未实现 BranchScopedStore 或集合缺少 branch_key 字段）。
+// 此时分支内容不写入索引，避免污染主线记忆。
+var ErrBranchIndexUnsupported = errors.New("vector store does not support branch-scoped segments")
+
+// BranchScopedStore 可选：支持按分支隔离片段的向量存储实现该接口。
+// 实现须满足：InsertSegments 按 VectorStorySegment.BranchKey 标记片段（空值视为主线）；
+// DeleteSegmentsByDocAndType 仅删除主线片段；SearchSegments 按 VectorSearchParams.BranchKey 过滤——
+// 主线只读主线片段，其他分支读取本分支片段，并对本分支没有片段的文档回退到主线片段。
+type BranchScopedStore interface {
+	// BranchesSupported 集合是否支持分支隔离（须在 EnsureStorySegmentsCollection 之后调用）
+	BranchesSupported() bool
+	// DeleteBranchSegments 删除分支片段；docID / segmentType 为空表示不按该维度过滤
+	DeleteBranchSegments(ctx context.Context, tenantID, projectID, docID, segmentType, branchKey string) error
+}
+
+// NormalizeBranchKey 规范化分支名：空值为主线
+func NormalizeBranchKey(branchKey string) string {
+	if bk := strings.TrimSpace(branchKey); bk != "" {
+		return bk
+	}
+	return MainBranchKey
+}
+
+// IsMainBranch 是否为主线分支
+func IsMainBranch(branchKey string) bool {
+	return NormalizeBranchKey(branchKey) == MainBranchKey
+}
+
+// IndexArtifactBranch 以分支片段重建构件在 branchKey 分支上的索引（主线等同 IndexArtifactJSON）。
+// 分支片段只在检索该分支时可见，不影响主线检索；向量存储不支持分支隔离时返回 ErrBranchIndexUnsupported。
+func (i *Indexer) IndexArtifactBranch(ctx context.Context, tenantID, projectID string, artifactType entity.ArtifactType, artifactID, branchKey string, content json.RawMessage) error {
+	return i.indexArtifact(ctx, tenantID, projectID, artifactType, artifactID, NormalizeBranchKey(branchKey), content)
+}
+
+// PurgeArtifactBranch 删除构件在分支上的全部片段（分支删除时调用；仅依赖向量存储）
+func (i *Indexer) PurgeArtifactBranch(ctx context.Context, tenantID, projectID string, artifactType entity.ArtifactType, artifactID, branchKey string) error {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" || strings.TrimSpace(artifactID) == "" {
+		return fmt.Errorf("tenant_id, project_id and artifact_id are required")
+	}
+	branchKey = NormalizeBranchKey(branchKey)
+	if branchKey == MainBranchKey {
+		return fmt.Errorf("main branch segments cannot be purged")
+	}
+	if i == nil || i.vector == nil {
+		return ErrVectorDisabled
+	}
+	if err := i.ensureReady(ctx); err != nil {
+		return err
+	}
+	store, err := i.branchStore()
+	if err != nil {
+		return err
+	}
+	segmentType := ArtifactSegmentType(artifactType)
+	if err := store.DeleteBranchSegments(ctx, tenantID, projectID, artifactID, segmentType, branchKey); err != nil {
+		return err
+	}
+	i.restore(ctx, tenantID, projectID, usageBranchDocKey(segmentType, artifactID, branchKey), VectorUsage{})
+	return nil
+}
+
+// branchStore 返回支持分支隔离的向量存储
+func (i *Indexer) branchStore() (BranchScopedStore, error) {
+	store, ok := i.vector.(BranchScopedStore)
+	if !ok || !store.BranchesSupported() {
+		return nil, ErrBranchIndexUnsupported
+	}
+	return store, nil
+}
+
+// usageBranchDocKey 分支片段的用量键（主线沿用 usageDocKey，与历史计数兼容）
+func usageBranchDocKey(segmentType, docID, branchKey string) string {
+	key := usageDocKey(segmentType, docID)
+	if branchKey = NormalizeBranchKey(branchKey); branchKey != MainBranchKey {
+		key += "@" + branchKey
+	}
+	return key
+}
@@ -0,0 +1,155 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// branchVectorRepo 按 BranchScopedStore 约定实现分支隔离的内存向量存储
+type branchVectorRepo struct {
+	segments []*VectorStorySegment
+}
+
+func (r *branchVectorRepo) EnsureStorySegmentsCollection(context.Context) error { return nil }
+
+func (r *branchVectorRepo) SearchSegments(_ context.Context, p *VectorSearchParams) ([]*VectorSearchResult, error) {
+	branchKey := NormalizeBranchKey(p.BranchKey)
+	overridden := map[string]bool{}
+	for _, s := range r.segments {
+		if branchKey != MainBranchKey && NormalizeBranchKey(s.BranchKey) == branchKey {
+			overridden[s.DocID] = true
+		}
+	}
+	var out []*VectorSearchResult
+	for _, s := range r.segments {
+		bk := NormalizeBranchKey(s.BranchKey)
+		if bk != branchKey && (bk != MainBranchKey || overridden[s.DocID]) {
+			continue
+		}
+		out = append(out, &VectorSearchResult{ID: s.ID, TextContent: s.TextContent, ChapterID: s.DocID, BranchKey: bk})
+	}
+	return out, nil
+}
+
+func (r *branchVectorRepo) DeleteSegmentsByDocAndType(_ context.Context, _, _, docID, segmentType string) error {
+	r.remove(func(s *VectorStorySegment) bool {
+		return s.DocID == docID && s.SegmentType == segmentType && IsMainBranch(s.BranchKey)
+	})
+	return nil
+}
+
+func (r *branchVectorRepo) DeleteProjectSegments(context.Context, string, string) error {
+	r.segments = nil
+	return nil
+}
+
+func (r *branchVectorRepo) InsertSegments(_ context.Context, _, _ string, segments []*VectorStorySegment) error {
+	r.segments = append(r.segments, segments...)
+	return nil
+}
+
+func (r *branchVectorRepo) BranchesSupported() bool { return true }
+
+func (r *branchVectorRepo) DeleteBranchSegments(_ context.Context, _, _, docID, segmentType, branchKey string) error {
+	r.remove(func(s *VectorStorySegment) bool {
+		return s.BranchKey == branchKey && (docID == "" || s.DocID == docID) && (segmentType == "" || s.SegmentType == segmentType)
+	})
+	return nil
+}
+
+func (r *branchVectorRepo) remove(match func(s *VectorStorySegment) bool) {
+	kept := r.segments[:0]
+	for _, s := range r.segments {
+		if !match(s) {
+			kept = append(kept, s)
+		}
+	}
+	r.segments = kept
+}
+
+func (r *branchVectorRepo) count(docID, branchKey string) int {
+	n := 0
+	for _, s := range r.segments {
+		if s.DocID == docID && NormalizeBranchKey(s.BranchKey) == branchKey {
+			n++
+		}
+	}
+	return n
+}
+
+func TestBranchScopedMemory(t *testing.T) {
+	ctx := context.Background()
+	vec := &branchVectorRepo{}
+	counter := &memUsageCounter{docs: map[string]VectorUsage{}}
+	idx := NewIndexer(stubEmbedder{}, vec, 0)
+	idx.EnableUsageQuota(counter, QuotaLimits{}, 0)
+
+	canon := json.RawMessage(`{"name":"林远","fate":"活到结局"}`)
+	whatIf := json.RawMessage(`{"name":"林远","fate":"第三卷战死"}`)
+	if err := idx.IndexArtifactJSON(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", canon); err != nil {
+		t.Fatal(err)
+	}
+	note := &entity.ProjectNote{ID: "n1", Content: "主线笔记"}
+	if err := idx.IndexNotes(ctx, "t1", "p1", note); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.IndexArtifactBranch(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", "what-if", whatIf); err != nil {
+		t.Fatal(err)
+	}
+	// 主线重建不触碰分支片段
+	if err := idx.IndexArtifactJSON(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", canon); err != nil {
+		t.Fatal(err)
+	}
+	if vec.count("a1", MainBranchKey) != 2 || vec.count("a1", "what-if") != 2 {
+		t.Fatalf("unexpected segments %d main / %d branch", vec.count("a1", MainBranchKey), vec.count("a1", "what-if"))
+	}
+
+	engine := NewEngine(stubEmbedder{}, vec, nil, 0)
+	branchOf := func(branchKey string) map[string]string {
+		out, err := engine.Search(ctx, SearchInput{TenantID: "t1", ProjectID: "p1", Query: "林远", TopK: 10, BranchKey: branchKey})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]string{}
+		for _, seg := range out.Segments {
+			got[seg.ArtifactID+seg.NoteID] = seg.BranchKey
+		}
+		return got
+	}
+	if got := branchOf(""); got["a1"] != MainBranchKey || got["n1"] != MainBranchKey {
+		t.Fatalf("main search should only see canon segments, got %v", got)
+	}
+	// 分支检索：构件取分支版本，分支未写入的笔记回退到主线
+	if got := branchOf("what-if"); got["a1"] != "what-if" || got["n1"] != MainBranchKey {
+		t.Fatalf("branch search should overlay branch segments on canon, got %v", got)
+	}
+
+	if counter.docs[usageBranchDocKey(ArtifactSegmentType(entity.ArtifactTypeCharacters), "a1", "what-if")].Segments != 2 {
+		t.Fatalf("branch usage not tracked: %v", counter.docs)
+	}
+	if err := idx.PurgeArtifactBranch(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", "what-if"); err != nil {
+		t.Fatal(err)
+	}
+	if vec.count("a1", "what-if") != 0 || vec.count("a1", MainBranchKey) != 2 {
+		t.Fatal("purge should remove only the branch segments")
+	}
+	if counter.total().Segments != 3 {
+		t.Fatalf("purge should release branch usage, got %+v", counter.total())
+	}
+	if got := branchOf("what-if"); got["a1"] != MainBranchKey {
+		t.Fatalf("deleted branch should fall back to canon, got %v", got)
+	}
+	if err := idx.PurgeArtifactBranch(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", MainBranchKey); err == nil {
+		t.Fatal("main branch must not be purged")
+	}
+
+	// 不支持分支隔离的存储：拒绝写入分支片段，避免污染主线
+	plain := NewIndexer(stubEmbedder{}, &stubVectorRepo{segments: map[string]int{}}, 0)
+	if err := plain.IndexArtifactBranch(ctx, "t1", "p1", entity.ArtifactTypeCharacters, "a1", "what-if", whatIf); !errors.Is(err, ErrBranchIndexUnsupported) {
+		t.Fatalf("expected ErrBranchIndexUnsupported, got %v", err)
+	}
+}
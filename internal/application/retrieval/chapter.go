@@ -0,0 +1,40 @@
+package retrieval
+
+import "z-novel-ai-api/internal/domain/entity"
+
+const (
+	// ChapterSearchTopK 章节生成前召回的片段数
+	ChapterSearchTopK = 12
+	// MaxChapterSearchTopK 项目设置可调的召回片段数上限
+	MaxChapterSearchTopK = 50
+)
+
+// ChapterTopK 按项目设置解析章节召回片段数：配置了命名空间配额时取配额总和，否则取 retrieval_top_k；
+// 未设置使用默认值，超出上限截断
+func ChapterTopK(settings *entity.ProjectSettings) int {
+	topK := settings.ChapterRetrievalTopK()
+	if quotas := NormalizeNamespaceQuotas(settings.RetrievalNamespaceQuotas()); len(quotas) > 0 {
+		topK = quotaTotal(quotas)
+	}
+	if topK <= 0 {
+		return ChapterSearchTopK
+	}
+	return min(topK, MaxChapterSearchTopK)
+}
+
+// ChapterSearchInput 章节生成前的召回参数：以章节大纲为查询，按章节叙事位置/故事时间与 POV 过滤。
+// Worker、SSE 与调试接口共用，保证调试结果与实际生成时的召回一致。
+func ChapterSearchInput(tenantID string, settings *entity.ProjectSettings, chapter *entity.Chapter, query string, narrativePos int64, seriesProjectIDs []string) SearchInput {
+	return SearchInput{
+		TenantID:            tenantID,
+		ProjectID:           chapter.ProjectID,
+		Query:               query,
+		CurrentStoryTime:    chapter.StoryTimeStart,
+		CurrentNarrativePos: narrativePos,
+		POVEntityID:         chapter.POVEntity(),
+		SeriesProjectIDs:    seriesProjectIDs,
+		TopK:                ChapterTopK(settings),
+		NamespaceQuotas:     NormalizeNamespaceQuotas(settings.RetrievalNamespaceQuotas()),
+		IncludeEntities:     false,
+	}
+}
@@ -3,6 +3,7 @@ package retrieval
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,10 +12,21 @@ import (
 	"z-novel-ai-api/internal/domain/repository"
 )
 
+// povOverFetchFactor POV 隔离/实体聚焦检索时的候选放大倍数
+const povOverFetchFactor = 3
+
 type Engine struct {
 	embedder embedding.Embedder
 	vector   VectorRepository
 	entity   repository.EntityRepository
+	sparse   SparseEncoder
+
+	// reranker 召回后的精排（可选，见 EnableRerank）
+	reranker      Reranker
+	rerankTimeout time.Duration
+
+	// relations 聚焦实体关联实体的关系加权（可选，见 EnableRelationWeighting）
+	relations RelatedEntityScorer
 
 	embeddingBatchSize int
 }
@@ -28,6 +40,7 @@ func NewEngine(embedder embedding.Embedder, vectorRepo VectorRepository, entityR
 		embedder:           embedder,
 		vector:             vectorRepo,
 		entity:             entityRepo,
+		sparse:             NewBM25Encoder(),
 		embeddingBatchSize: bs,
 	}
 }
@@ -52,6 +65,14 @@ func (e *Engine) DebugSearch(ctx context.Context, in SearchInput) (*SearchOutput
 }
 
 func (e *Engine) search(ctx context.Context, in SearchInput, forceDebug bool) (*SearchOutput, error) {
+	// 指定 segment_type 时按调用方过滤，不再按命名空间分配配额
+	in.NamespaceQuotas = NormalizeNamespaceQuotas(in.NamespaceQuotas)
+	if len(in.SegmentTypes) > 0 {
+		in.NamespaceQuotas = nil
+	}
+	if len(in.NamespaceQuotas) > 0 {
+		in.TopK = quotaTotal(in.NamespaceQuotas)
+	}
 	if in.TopK <= 0 {
 		in.TopK = 10
 	}
@@ -59,8 +80,13 @@ func (e *Engine) search(ctx context.Context, in SearchInput, forceDebug bool) (*
 		in.TopK = 50
 	}
 	in.Query = strings.TrimSpace(in.Query)
+	in.POVEntityID = strings.TrimSpace(in.POVEntityID)
+	in.FocusEntityIDs = normalizeIDs(in.FocusEntityIDs)
 	in.TenantID = strings.TrimSpace(in.TenantID)
 	in.ProjectID = strings.TrimSpace(in.ProjectID)
+	if in.VectorWeight < 0 || in.VectorWeight > 1 || in.KeywordWeight < 0 || in.KeywordWeight > 1 {
+		return nil, fmt.Errorf("vector_weight and keyword_weight must be within [0, 1]")
+	}
 	if in.TenantID == "" || in.ProjectID == "" {
 		return nil, fmt.Errorf("tenant_id and project_id are required")
 	}
@@ -92,56 +118,33 @@ func (e *Engine) search(ctx context.Context, in SearchInput, forceDebug bool) (*
 					out.QueryEmbedding = emb
 				}
 
-				results, err := e.vector.SearchSegments(ctx, &VectorSearchParams{
-					TenantID:         in.TenantID,
-					ProjectID:        in.ProjectID,
-					QueryVector:      emb,
-					CurrentStoryTime: in.CurrentStoryTime,
-					TopK:             in.TopK,
-					SegmentTypes:     in.SegmentTypes,
-				})
+				var res *recallResult
+				if len(in.NamespaceQuotas) > 0 {
+					res, err = e.recallNamespaces(ctx, in, emb)
+				} else {
+					res, err = e.recall(ctx, in, emb, in.SegmentTypes, in.TopK)
+				}
 				if err != nil {
 					out.DisabledReason = err.Error()
 				} else {
-					out.Segments = make([]Segment, 0, len(results))
-					for _, r := range results {
-						if r == nil {
-							continue
-						}
-						meta, text := decodeSegmentText(r.TextContent)
-						seg := Segment{
-							ID:     strings.TrimSpace(r.ID),
-							Text:   strings.TrimSpace(text),
-							Score:  1 - float64(r.Score), // 将“距离”转换为更直观的相似度（COSINE: distance=1-cos）
-							Source: "vector",
-
-							DocType:      strings.TrimSpace(meta.DocType),
-							ChapterID:    strings.TrimSpace(meta.ChapterID),
-							ChapterTitle: strings.TrimSpace(meta.ChapterTitle),
-							StoryTime:    r.StoryTime,
-							ArtifactID:   strings.TrimSpace(meta.ArtifactID),
-							ArtifactType: strings.TrimSpace(meta.ArtifactType),
-							RefPath:      strings.TrimSpace(meta.RefPath),
-						}
-
-						// 兼容：历史数据可能没有 meta，回退使用 Milvus 字段
-						if seg.DocType == "" && strings.TrimSpace(r.ChapterID) != "" {
-							seg.DocType = "chapter"
-						}
-						if seg.DocType == "chapter" && seg.ChapterID == "" {
-							seg.ChapterID = strings.TrimSpace(r.ChapterID)
-						}
-						if seg.DocType == "artifact" && seg.ArtifactID == "" {
-							seg.ArtifactID = strings.TrimSpace(r.ChapterID)
-						}
-
-						out.Segments = append(out.Segments, seg)
-					}
+					out.Segments = res.segments
 					if dbg != nil {
 						dbg.VectorSearchTimeMs = time.Since(start).Milliseconds()
-						dbg.TotalCandidates = len(out.Segments)
+						dbg.TotalCandidates = res.total
 						dbg.FilteredCandidates = len(out.Segments)
+						dbg.TimeFilter = resolveTimeFilter(in)
+						dbg.VectorTopK = res.vectorTopK
+						dbg.POVFiltered = res.povFiltered
+						dbg.FocusEntityIDs = in.FocusEntityIDs
+						dbg.FocusBoosted = res.focusBoosted
+						dbg.RelationBoosted = res.relationBoosted
+						dbg.Partitions = e.partitions(in)
+						dbg.NamespaceQuotas = in.NamespaceQuotas
+						dbg.NamespaceHits = res.namespaceHits
+						dbg.BranchKey = NormalizeBranchKey(in.BranchKey)
+						dbg.KeywordTerms = len(e.sparse.EncodeQuery(in.Query).Indices)
 					}
+					e.applyRerank(ctx, in.Query, out, dbg)
 				}
 			}
 		}
@@ -177,6 +180,230 @@ func (e *Engine) search(ctx context.Context, in SearchInput, forceDebug bool) (*
 	return out, nil
 }
 
+// recallResult 一次向量召回（单个命名空间或不分命名空间）的结果与统计
+type recallResult struct {
+	segments     []Segment
+	total        int
+	vectorTopK   int
+	povFiltered  int
+	focusBoosted int
+	// relationBoosted 因涉及聚焦实体的关联实体被加权的片段数
+	relationBoosted int
+
+	// namespaceHits 各命名空间最终入选的片段数（仅配额检索时填充）
+	namespaceHits map[string]int
+}
+
+// recall 在指定 segment_type 范围内召回 topK 个片段：当前项目经 POV 过滤与实体聚焦加权后，与同系列前作片段合并排序。
+func (e *Engine) recall(ctx context.Context, in SearchInput, emb []float32, segmentTypes []string, topK int) (*recallResult, error) {
+	// POV 过滤与实体聚焦在召回后进行（involved_entities 存于片段 meta），需多召回一些候选
+	vectorTopK := topK
+	if in.POVEntityID != "" || len(in.FocusEntityIDs) > 0 {
+		vectorTopK = topK * povOverFetchFactor
+	}
+
+	results, err := e.vector.SearchSegments(ctx, &VectorSearchParams{
+		TenantID:            in.TenantID,
+		ProjectID:           in.ProjectID,
+		QueryVector:         emb,
+		QuerySparse:         e.sparse.EncodeQuery(in.Query),
+		DenseWeight:         in.VectorWeight,
+		SparseWeight:        in.KeywordWeight,
+		CurrentStoryTime:    in.CurrentStoryTime,
+		CurrentNarrativePos: in.CurrentNarrativePos,
+		TimeFilter:          resolveTimeFilter(in),
+		TopK:                vectorTopK,
+		SegmentTypes:        segmentTypes,
+		BranchKey:           NormalizeBranchKey(in.BranchKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]Segment, 0, len(results))
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		segments = append(segments, toSegment(r, in.ProjectID))
+	}
+	res := &recallResult{total: len(segments), vectorTopK: vectorTopK}
+	if in.POVEntityID != "" {
+		segments = filterSegmentsByPOV(segments, in.POVEntityID)
+	}
+	res.povFiltered = res.total - len(segments)
+	res.focusBoosted = boostFocusSegments(segments, in.FocusEntityIDs)
+	res.relationBoosted = e.boostRelatedSegments(ctx, in.ProjectID, segments, in.FocusEntityIDs)
+	if res.focusBoosted > 0 || res.relationBoosted > 0 {
+		sortSegmentsByScore(segments)
+	}
+	// 前作片段的实体 ID 属于各自项目，不参与 POV 过滤
+	if len(in.SeriesProjectIDs) > 0 {
+		seriesSegments := e.searchSeriesProjects(ctx, in, emb, segmentTypes, topK)
+		res.total += len(seriesSegments)
+		segments = append(segments, seriesSegments...)
+		sortSegmentsByScore(segments)
+	}
+	if len(segments) > topK {
+		segments = segments[:topK]
+	}
+	res.segments = segments
+	return res, nil
+}
+
+// recallNamespaces 按命名空间配额分别召回后合并排序：摘要、正文、设定卡等各自竞争配额内的名额，互不挤占。
+// 配额总和超过 TopK 上限时按得分截断。
+func (e *Engine) recallNamespaces(ctx context.Context, in SearchInput, emb []float32) (*recallResult, error) {
+	out := &recallResult{namespaceHits: make(map[string]int, len(in.NamespaceQuotas))}
+	for _, ns := range Namespaces() {
+		quota := in.NamespaceQuotas[ns]
+		if quota <= 0 {
+			continue
+		}
+		res, err := e.recall(ctx, in, emb, NamespaceSegmentTypes(ns), quota)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", ns, err)
+		}
+		out.segments = append(out.segments, res.segments...)
+		out.total += res.total
+		out.vectorTopK += res.vectorTopK
+		out.povFiltered += res.povFiltered
+		out.focusBoosted += res.focusBoosted
+		out.relationBoosted += res.relationBoosted
+		out.namespaceHits[ns] = len(res.segments)
+	}
+	sortSegmentsByScore(out.segments)
+	if len(out.segments) > in.TopK {
+		out.segments = out.segments[:in.TopK]
+	}
+	return out, nil
+}
+
+// searchSeriesProjects 只读检索同系列前作的分区：前作已完结，不做时间/叙事位置过滤；单个前作失败时跳过。
+func (e *Engine) searchSeriesProjects(ctx context.Context, in SearchInput, emb []float32, segmentTypes []string, topK int) []Segment {
+	var segments []Segment
+	for _, pid := range in.SeriesProjectIDs {
+		pid = strings.TrimSpace(pid)
+		if pid == "" || pid == in.ProjectID {
+			continue
+		}
+		results, err := e.vector.SearchSegments(ctx, &VectorSearchParams{
+			TenantID:     in.TenantID,
+			ProjectID:    pid,
+			QueryVector:  emb,
+			QuerySparse:  e.sparse.EncodeQuery(in.Query),
+			DenseWeight:  in.VectorWeight,
+			SparseWeight: in.KeywordWeight,
+			TopK:         topK,
+			SegmentTypes: segmentTypes,
+		})
+		if err != nil {
+			continue
+		}
+		for _, r := range results {
+			if r == nil {
+				continue
+			}
+			segments = append(segments, toSegment(r, pid))
+		}
+	}
+	return segments
+}
+
+// toSegment 将向量检索结果转换为片段
+func toSegment(r *VectorSearchResult, projectID string) Segment {
+	meta, text := decodeSegmentText(r.TextContent)
+	seg := Segment{
+		ID:        strings.TrimSpace(r.ID),
+		Text:      strings.TrimSpace(text),
+		Score:     1 - float64(r.Score), // 将“距离”转换为更直观的相似度（COSINE: distance=1-cos）
+		Source:    "vector",
+		ProjectID: projectID,
+
+		DocType:      strings.TrimSpace(meta.DocType),
+		ChapterID:    strings.TrimSpace(meta.ChapterID),
+		ChapterTitle: strings.TrimSpace(meta.ChapterTitle),
+		StoryTime:    r.StoryTime,
+		NarrativePos: r.NarrativePos,
+		ArtifactID:   strings.TrimSpace(meta.ArtifactID),
+		ArtifactType: strings.TrimSpace(meta.ArtifactType),
+		RefPath:      strings.TrimSpace(meta.RefPath),
+		NoteID:       strings.TrimSpace(meta.NoteID),
+		NoteTitle:    strings.TrimSpace(meta.NoteTitle),
+		BranchKey:    NormalizeBranchKey(r.BranchKey),
+
+		InvolvedEntities: meta.InvolvedEntities,
+	}
+
+	// 兼容：历史数据可能没有 meta，回退使用 Milvus 字段
+	if seg.DocType == "" && strings.TrimSpace(r.ChapterID) != "" {
+		seg.DocType = "chapter"
+	}
+	if isChapterDoc(seg.DocType) && seg.ChapterID == "" {
+		seg.ChapterID = strings.TrimSpace(r.ChapterID)
+	}
+	if seg.DocType == "artifact" && seg.ArtifactID == "" {
+		seg.ArtifactID = strings.TrimSpace(r.ChapterID)
+	}
+	return seg
+}
+
+// sortSegmentsByScore 按相似度降序排列（合并多个分区结果时使用）
+func sortSegmentsByScore(segments []Segment) {
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].Score > segments[j].Score
+	})
+}
+
+// filterSegmentsByPOV 仅保留 POV 角色“可能知道”的片段：
+// - 非章节片段（设定/大纲等）视为公共知识，保留（章节摘要按章节处理）；
+// - 章节片段无 involved_entities（历史数据）时无法判断，保留；
+// - 否则仅保留 involved_entities 包含 POV 角色的片段。
+func filterSegmentsByPOV(segments []Segment, povEntityID string) []Segment {
+	out := segments[:0]
+	for _, seg := range segments {
+		if !isChapterDoc(seg.DocType) || len(seg.InvolvedEntities) == 0 {
+			out = append(out, seg)
+			continue
+		}
+		for _, id := range seg.InvolvedEntities {
+			if id == povEntityID {
+				out = append(out, seg)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// partitions 检索涉及的向量分区名；向量存储未实现 PartitionNamer 时返回空
+func (e *Engine) partitions(in SearchInput) []string {
+	namer, ok := e.vector.(PartitionNamer)
+	if !ok {
+		return nil
+	}
+	out := []string{namer.PartitionName(in.TenantID, in.ProjectID)}
+	for _, pid := range in.SeriesProjectIDs {
+		pid = strings.TrimSpace(pid)
+		if pid == "" || pid == in.ProjectID {
+			continue
+		}
+		out = append(out, namer.PartitionName(in.TenantID, pid))
+	}
+	return out
+}
+
+// resolveTimeFilter 确定时间过滤维度：显式指定优先；否则有叙事位置时按叙事位置过滤，再回退为故事时间。
+func resolveTimeFilter(in SearchInput) TimeFilter {
+	if in.TimeFilter != "" {
+		return in.TimeFilter
+	}
+	if in.CurrentNarrativePos > 0 {
+		return TimeFilterNarrative
+	}
+	return TimeFilterStoryTime
+}
+
 func (e *Engine) embedQuery(ctx context.Context, query string) ([]float32, error) {
 	if e == nil || e.embedder == nil {
 		return nil, ErrVectorDisabled
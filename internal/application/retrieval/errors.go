@@ -5,4 +5,8 @@ import "errors"
 var (
 	// ErrVectorDisabled 表示向量检索/索引能力未配置（Milvus 或 Embedder 不可用）。
 	ErrVectorDisabled = errors.New("vector retrieval is disabled")
+	// ErrSegmentScanUnsupported 表示向量存储不支持按项目遍历片段（未实现 SegmentScanner）。
+	ErrSegmentScanUnsupported = errors.New("vector store does not support segment scanning")
+	// ErrInvalidSegmentCursor 表示分页游标非法（游标应为上一页最后一条片段 ID）。
+	ErrInvalidSegmentCursor = errors.New("invalid segment cursor")
 )
@@ -0,0 +1,223 @@
+package retrieval
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+const (
+	// FocusMaxEntities 单章聚焦实体上限
+	FocusMaxEntities = 8
+	// focusEntityScanLimit 参与识别的项目实体上限（按重要性、出场次数排序后截取）
+	focusEntityScanLimit = 500
+	// focusEmbedCandidates 名称未命中时参与向量相似度比较的实体上限
+	focusEmbedCandidates = 64
+	// focusSimilarityThreshold 实体简介与大纲的最低余弦相似度
+	focusSimilarityThreshold = 0.6
+	// focusBoostPerEntity / focusMaxBoost 章节片段每命中一个聚焦实体的加分及加分上限
+	focusBoostPerEntity = 0.05
+	focusMaxBoost       = 0.15
+)
+
+// DetectFocusEntities 识别大纲涉及的实体 ID：先按名称/别名匹配，再以实体简介与大纲的向量相似度补充未点名的实体。
+// 向量不可用或嵌入失败时仅返回名称匹配结果；需在已 SetTenant 的上下文中调用。
+func (e *Engine) DetectFocusEntities(ctx context.Context, projectID, text string) ([]string, error) {
+	text = strings.TrimSpace(text)
+	if e == nil || e.entity == nil || strings.TrimSpace(projectID) == "" || text == "" {
+		return nil, nil
+	}
+
+	res, err := e.entity.ListByProject(ctx, projectID, nil, repository.Pagination{Page: 1, PageSize: focusEntityScanLimit})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || len(res.Items) == 0 {
+		return nil, nil
+	}
+	entities := make([]*entity.StoryEntity, 0, len(res.Items))
+	for _, ent := range res.Items {
+		if ent != nil && strings.TrimSpace(ent.Name) != "" {
+			entities = append(entities, ent)
+		}
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		ri, rj := focusImportanceRank(entities[i].Importance), focusImportanceRank(entities[j].Importance)
+		if ri != rj {
+			return ri < rj
+		}
+		return entities[i].AppearCount > entities[j].AppearCount
+	})
+
+	out := make([]string, 0, FocusMaxEntities)
+	rest := make([]*entity.StoryEntity, 0, len(entities))
+	for _, ent := range entities {
+		if len(out) < FocusMaxEntities && textMentionsEntity(text, ent) {
+			out = append(out, ent.ID)
+			continue
+		}
+		rest = append(rest, ent)
+	}
+	if len(out) >= FocusMaxEntities || !e.Enabled() || len(rest) == 0 {
+		return out, nil
+	}
+
+	if len(rest) > focusEmbedCandidates {
+		rest = rest[:focusEmbedCandidates]
+	}
+	similar := e.similarEntities(ctx, text, rest)
+	for _, id := range similar {
+		if len(out) >= FocusMaxEntities {
+			break
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// similarEntities 返回简介与大纲相似度达到阈值的实体 ID（按相似度降序）；嵌入失败时返回空
+func (e *Engine) similarEntities(ctx context.Context, text string, entities []*entity.StoryEntity) []string {
+	inputs := make([]string, 0, len(entities)+1)
+	inputs = append(inputs, text)
+	for _, ent := range entities {
+		inputs = append(inputs, entityProfileText(ent))
+	}
+
+	vectors := make([][]float64, 0, len(inputs))
+	for start := 0; start < len(inputs); start += e.embeddingBatchSize {
+		end := start + e.embeddingBatchSize
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		batch, err := e.embedder.EmbedStrings(ctx, inputs[start:end])
+		if err != nil || len(batch) != end-start {
+			return nil
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	type scored struct {
+		id    string
+		score float64
+	}
+	hits := make([]scored, 0, len(entities))
+	for i, ent := range entities {
+		if s := cosineSimilarity(vectors[0], vectors[i+1]); s >= focusSimilarityThreshold {
+			hits = append(hits, scored{id: ent.ID, score: s})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	out := make([]string, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.id)
+	}
+	return out
+}
+
+// boostFocusSegments 提升涉及聚焦实体的章节片段得分（不剔除其他片段），返回被加权的片段数
+func boostFocusSegments(segments []Segment, focusEntityIDs []string) int {
+	if len(focusEntityIDs) == 0 {
+		return 0
+	}
+	focus := make(map[string]struct{}, len(focusEntityIDs))
+	for _, id := range focusEntityIDs {
+		focus[id] = struct{}{}
+	}
+
+	boosted := 0
+	for i := range segments {
+		if !isChapterDoc(segments[i].DocType) {
+			continue
+		}
+		hits := 0
+		for _, id := range segments[i].InvolvedEntities {
+			if _, ok := focus[id]; ok {
+				hits++
+			}
+		}
+		if hits == 0 {
+			continue
+		}
+		segments[i].Score += math.Min(float64(hits)*focusBoostPerEntity, focusMaxBoost)
+		boosted++
+	}
+	return boosted
+}
+
+// textMentionsEntity 文本是否提及实体名称或别名（至少 2 个字，避免单字误命中）
+func textMentionsEntity(text string, ent *entity.StoryEntity) bool {
+	names := append([]string{ent.Name}, ent.Aliases...)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len([]rune(name)) >= 2 && strings.Contains(text, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func entityProfileText(ent *entity.StoryEntity) string {
+	parts := []string{strings.TrimSpace(ent.Name)}
+	if desc := strings.TrimSpace(ent.Description); desc != "" {
+		parts = append(parts, desc)
+	}
+	if state := strings.TrimSpace(ent.CurrentState); state != "" {
+		parts = append(parts, state)
+	}
+	return strings.Join(parts, "：")
+}
+
+func focusImportanceRank(imp entity.EntityImportance) int {
+	switch imp {
+	case entity.ImportanceProtagonist:
+		return 0
+	case entity.ImportanceMajor:
+		return 1
+	case entity.ImportanceSecondary:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// normalizeIDs 去除空白与重复 ID，保持原有顺序
+func normalizeIDs(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -23,10 +23,16 @@ const (
 type Indexer struct {
 	embedder embedding.Embedder
 	vector   VectorRepository
+	sparse   SparseEncoder
 
 	embeddingBatchSize int
 	chunkSizeRunes     int
 	chunkOverlapRunes  int
+
+	// usage 向量用量计数（nil 表示不计数、不限额），见 EnableUsageQuota
+	usage     UsageCounter
+	limits    QuotaLimits
+	dimension int
 }
 
 func NewIndexer(embedder embedding.Embedder, vectorRepo VectorRepository, embeddingBatchSize int) *Indexer {
@@ -37,6 +43,7 @@ func NewIndexer(embedder embedding.Embedder, vectorRepo VectorRepository, embedd
 	return &Indexer{
 		embedder:           embedder,
 		vector:             vectorRepo,
+		sparse:             NewBM25Encoder(),
 		embeddingBatchSize: bs,
 		chunkSizeRunes:     defaultChunkSizeRunes,
 		chunkOverlapRunes:  defaultChunkOverlapRunes,
@@ -54,7 +61,16 @@ func (i *Indexer) ensureReady(ctx context.Context) error {
 	return i.vector.EnsureStorySegmentsCollection(ctx)
 }
 
-func (i *Indexer) IndexChapter(ctx context.Context, tenantID, projectID string, chapter *entity.Chapter) error {
+// ChapterIndexOptions 章节索引附加信息
+type ChapterIndexOptions struct {
+	// NarrativePos 章节叙事位置（见 entity.NarrativePosition），用于按阅读顺序过滤
+	NarrativePos int64
+	// InvolvedEntities 章节涉及的实体 ID，用于 POV 隔离检索
+	InvolvedEntities []string
+}
+
+// IndexChapter 重建章节正文索引，并以独立的 segment_type（chapter_summary）重建章节摘要索引。
+func (i *Indexer) IndexChapter(ctx context.Context, tenantID, projectID string, chapter *entity.Chapter, opts ChapterIndexOptions) error {
 	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
 		return fmt.Errorf("tenant_id and project_id are required")
 	}
@@ -72,20 +88,10 @@ func (i *Indexer) IndexChapter(ctx context.Context, tenantID, projectID string,
 	}
 
 	segmentType := "chapter"
-	if err := i.vector.DeleteSegmentsByDocAndType(ctx, tenantID, projectID, chapter.ID, segmentType); err != nil {
-		return err
-	}
 
+	// 空正文不写索引；但仍会执行删除以避免“旧分片残留”。
 	content := strings.TrimSpace(chapter.ContentText)
-	if content == "" {
-		// 空正文不写索引；但会先执行删除以避免“旧分片残留”。
-		return nil
-	}
-
 	chunks := splitByRunes(content, i.chunkSizeRunes, i.chunkOverlapRunes)
-	if len(chunks) == 0 {
-		return nil
-	}
 
 	embedInputs := make([]string, 0, len(chunks))
 	segments := make([]*VectorStorySegment, 0, len(chunks))
@@ -101,6 +107,8 @@ func (i *Indexer) IndexChapter(ctx context.Context, tenantID, projectID string,
 			ChapterID:    chapter.ID,
 			ChapterTitle: strings.TrimSpace(chapter.Title),
 			RefPath:      "/content_text",
+
+			InvolvedEntities: opts.InvolvedEntities,
 		}
 		textContent := encodeSegmentText(meta, strings.TrimSpace(chunk))
 
@@ -111,27 +119,101 @@ func (i *Indexer) IndexChapter(ctx context.Context, tenantID, projectID string,
 
 		embedInputs = append(embedInputs, embedText)
 		segments = append(segments, &VectorStorySegment{
-			ID:          uuid.NewString(),
-			TenantID:    tenantID,
-			ProjectID:   projectID,
-			DocID:       chapter.ID,
-			StoryTime:   storyTime,
-			SegmentType: segmentType,
-			TextContent: textContent,
+			ID:           uuid.NewString(),
+			TenantID:     tenantID,
+			ProjectID:    projectID,
+			DocID:        chapter.ID,
+			StoryTime:    storyTime,
+			NarrativePos: opts.NarrativePos,
+			SegmentType:  segmentType,
+			TextContent:  textContent,
 		})
 	}
+	if err := i.replaceDoc(ctx, tenantID, projectID, chapter.ID, segmentType, MainBranchKey, segments, embedInputs); err != nil {
+		return err
+	}
+	return i.indexChapterSummary(ctx, tenantID, projectID, chapter, storyTime, opts)
+}
 
-	vectors, err := i.embedBatch(ctx, embedInputs)
-	if err != nil {
+// indexChapterSummary 重建章节摘要索引（空摘要仅删除旧片段），与正文分属不同命名空间，检索时按各自配额召回。
+func (i *Indexer) indexChapterSummary(ctx context.Context, tenantID, projectID string, chapter *entity.Chapter, storyTime int64, opts ChapterIndexOptions) error {
+	chunks := splitByRunes(strings.TrimSpace(chapter.Summary), i.chunkSizeRunes, i.chunkOverlapRunes)
+	title := strings.TrimSpace(chapter.Title)
+	embedInputs := make([]string, 0, len(chunks))
+	segments := make([]*VectorStorySegment, 0, len(chunks))
+	for _, chunk := range chunks {
+		meta := SegmentMeta{
+			DocType:      SummarySegmentType,
+			ChapterID:    chapter.ID,
+			ChapterTitle: title,
+			RefPath:      "/summary",
+
+			InvolvedEntities: opts.InvolvedEntities,
+		}
+		textContent := encodeSegmentText(meta, strings.TrimSpace(chunk))
+
+		embedText := "章节摘要：" + strings.TrimSpace(chunk)
+		if title != "" {
+			embedText = "章节摘要《" + title + "》：" + strings.TrimSpace(chunk)
+		}
+
+		embedInputs = append(embedInputs, embedText)
+		segments = append(segments, &VectorStorySegment{
+			ID:           uuid.NewString(),
+			TenantID:     tenantID,
+			ProjectID:    projectID,
+			DocID:        chapter.ID,
+			StoryTime:    storyTime,
+			NarrativePos: opts.NarrativePos,
+			SegmentType:  SummarySegmentType,
+			TextContent:  textContent,
+		})
+	}
+	return i.replaceDoc(ctx, tenantID, projectID, chapter.ID, SummarySegmentType, MainBranchKey, segments, embedInputs)
+}
+
+// PurgeProject 清空项目的全部向量片段（仅依赖向量存储，Embedding 不可用时同样可执行）。
+func (i *Indexer) PurgeProject(ctx context.Context, tenantID, projectID string) error {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
+		return fmt.Errorf("tenant_id and project_id are required")
+	}
+	if i == nil || i.vector == nil {
+		return ErrVectorDisabled
+	}
+	if err := i.vector.DeleteProjectSegments(ctx, tenantID, projectID); err != nil {
 		return err
 	}
-	for idx := range segments {
-		segments[idx].Vector = vectors[idx]
+	if i.UsageTracked() {
+		return i.usage.PurgeProject(ctx, tenantID, projectID)
+	}
+	return nil
+}
+
+// UpdateNarrativePositions 章节重排/重编号后回写章节片段的叙事位置（positions: 章节 ID → 叙事位置）。
+// 仅依赖向量存储，不重新向量化；向量存储未实现 NarrativePositionUpdater 时返回 ErrVectorDisabled。
+func (i *Indexer) UpdateNarrativePositions(ctx context.Context, tenantID, projectID string, positions map[string]int64) error {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
+		return fmt.Errorf("tenant_id and project_id are required")
+	}
+	if i == nil || i.vector == nil {
+		return ErrVectorDisabled
+	}
+	updater, ok := i.vector.(NarrativePositionUpdater)
+	if !ok {
+		return ErrVectorDisabled
 	}
-	return i.vector.InsertSegments(ctx, tenantID, projectID, segments)
+	if len(positions) == 0 {
+		return nil
+	}
+	return updater.UpdateNarrativePositions(ctx, tenantID, projectID, positions)
 }
 
 func (i *Indexer) IndexArtifactJSON(ctx context.Context, tenantID, projectID string, artifactType entity.ArtifactType, artifactID string, content json.RawMessage) error {
+	return i.indexArtifact(ctx, tenantID, projectID, artifactType, artifactID, MainBranchKey, content)
+}
+
+// indexArtifact 以 branchKey 分支片段重建构件索引（非主线要求向量存储支持分支隔离）
+func (i *Indexer) indexArtifact(ctx context.Context, tenantID, projectID string, artifactType entity.ArtifactType, artifactID, branchKey string, content json.RawMessage) error {
 	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
 		return fmt.Errorf("tenant_id and project_id are required")
 	}
@@ -147,28 +229,24 @@ func (i *Indexer) IndexArtifactJSON(ctx context.Context, tenantID, projectID str
 	if err := i.ensureReady(ctx); err != nil {
 		return err
 	}
+	if branchKey != MainBranchKey {
+		if _, err := i.branchStore(); err != nil {
+			return err
+		}
+	}
 
 	segmentType := ArtifactSegmentType(artifactType)
 	if segmentType == "" {
 		return fmt.Errorf("unsupported artifact_type: %s", artifactType)
 	}
 
-	if err := i.vector.DeleteSegmentsByDocAndType(ctx, tenantID, projectID, artifactID, segmentType); err != nil {
-		return err
-	}
-	if len(content) == 0 || strings.TrimSpace(string(content)) == "" {
-		return nil
-	}
-
-	var obj any
-	if err := json.Unmarshal(content, &obj); err != nil {
-		return fmt.Errorf("invalid artifact json: %w", err)
-	}
-
 	leaves := make([]jsonLeaf, 0, 128)
-	collectJSONLeaves(obj, "", &leaves, defaultMaxJSONLeaves)
-	if len(leaves) == 0 {
-		return nil
+	if len(content) > 0 && strings.TrimSpace(string(content)) != "" {
+		var obj any
+		if err := json.Unmarshal(content, &obj); err != nil {
+			return fmt.Errorf("invalid artifact json: %w", err)
+		}
+		collectJSONLeaves(obj, "", &leaves, defaultMaxJSONLeaves)
 	}
 
 	embedInputs := make([]string, 0, len(leaves))
@@ -200,23 +278,105 @@ func (i *Indexer) IndexArtifactJSON(ctx context.Context, tenantID, projectID str
 				DocID:       artifactID,
 				StoryTime:   0,
 				SegmentType: segmentType,
+				BranchKey:   branchKey,
 				TextContent: textContent,
 			})
 		}
 	}
+	return i.replaceDoc(ctx, tenantID, projectID, artifactID, segmentType, branchKey, segments, embedInputs)
+}
+
+// NotesSegmentType 作者导入笔记的 segment_type
+const NotesSegmentType = "notes"
 
+// IndexNotes 重建单篇项目笔记的索引（按笔记 ID 覆盖写入）。
+func (i *Indexer) IndexNotes(ctx context.Context, tenantID, projectID string, note *entity.ProjectNote) error {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
+		return fmt.Errorf("tenant_id and project_id are required")
+	}
+	if note == nil || strings.TrimSpace(note.ID) == "" {
+		return fmt.Errorf("note.id is required")
+	}
+	if !i.Enabled() {
+		return ErrVectorDisabled
+	}
+	if err := i.ensureReady(ctx); err != nil {
+		return err
+	}
+
+	chunks := splitByRunes(strings.TrimSpace(note.Content), i.chunkSizeRunes, i.chunkOverlapRunes)
+	title := strings.TrimSpace(note.Title)
+	embedInputs := make([]string, 0, len(chunks))
+	segments := make([]*VectorStorySegment, 0, len(chunks))
+	for _, chunk := range chunks {
+		meta := SegmentMeta{
+			DocType:   NotesSegmentType,
+			NoteID:    note.ID,
+			NoteTitle: title,
+		}
+		textContent := encodeSegmentText(meta, strings.TrimSpace(chunk))
+
+		embedText := "作者笔记：" + strings.TrimSpace(chunk)
+		if title != "" {
+			embedText = "作者笔记《" + title + "》：" + strings.TrimSpace(chunk)
+		}
+
+		embedInputs = append(embedInputs, embedText)
+		segments = append(segments, &VectorStorySegment{
+			ID:          uuid.NewString(),
+			TenantID:    tenantID,
+			ProjectID:   projectID,
+			DocID:       note.ID,
+			StoryTime:   0,
+			SegmentType: NotesSegmentType,
+			TextContent: textContent,
+		})
+	}
+	return i.replaceDoc(ctx, tenantID, projectID, note.ID, NotesSegmentType, MainBranchKey, segments, embedInputs)
+}
+
+// replaceDoc 用 segments 覆盖文档（docID + segmentType）在 branchKey 分支上的全部片段：先登记用量并检查配额，
+// 再删除旧片段、向量化并写入新片段；segments 为空时仅删除。配额不足时不触碰已有片段。
+func (i *Indexer) replaceDoc(ctx context.Context, tenantID, projectID, docID, segmentType, branchKey string, segments []*VectorStorySegment, embedInputs []string) error {
+	docKey := usageBranchDocKey(segmentType, docID, branchKey)
+	prev, err := i.reserve(ctx, tenantID, projectID, docKey, segments)
+	if err != nil {
+		return err
+	}
+	if err := i.deleteDoc(ctx, tenantID, projectID, docID, segmentType, branchKey); err != nil {
+		i.restore(ctx, tenantID, projectID, docKey, prev)
+		return err
+	}
 	if len(segments) == 0 {
 		return nil
 	}
 
 	vectors, err := i.embedBatch(ctx, embedInputs)
 	if err != nil {
+		i.restore(ctx, tenantID, projectID, docKey, VectorUsage{})
 		return err
 	}
 	for idx := range segments {
 		segments[idx].Vector = vectors[idx]
+		segments[idx].Sparse = i.sparse.EncodeDocument(embedInputs[idx])
+	}
+	if err := i.vector.InsertSegments(ctx, tenantID, projectID, segments); err != nil {
+		i.restore(ctx, tenantID, projectID, docKey, VectorUsage{})
+		return err
+	}
+	return nil
+}
+
+// deleteDoc 删除文档在 branchKey 分支上的片段（主线不触碰分支片段）
+func (i *Indexer) deleteDoc(ctx context.Context, tenantID, projectID, docID, segmentType, branchKey string) error {
+	if branchKey == MainBranchKey {
+		return i.vector.DeleteSegmentsByDocAndType(ctx, tenantID, projectID, docID, segmentType)
+	}
+	store, err := i.branchStore()
+	if err != nil {
+		return err
 	}
-	return i.vector.InsertSegments(ctx, tenantID, projectID, segments)
+	return store.DeleteBranchSegments(ctx, tenantID, projectID, docID, segmentType, branchKey)
 }
 
 // ArtifactSegmentType 将 ArtifactType 映射为 Milvus segment_type（用于过滤/删除/检索）。
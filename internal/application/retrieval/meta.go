@@ -10,14 +10,20 @@ const segmentMetaPrefix = "@@meta:"
 // SegmentMeta 是写入到 Milvus text_content 的结构化元信息（用于“结构化定位”）。
 // 约定：仅用于读写自家写入的段落；不存在时应安全降级。
 type SegmentMeta struct {
-	DocType string `json:"doc_type,omitempty"` // chapter | artifact
+	DocType string `json:"doc_type,omitempty"` // chapter | artifact | notes
 
 	ChapterID    string `json:"chapter_id,omitempty"`
 	ChapterTitle string `json:"chapter_title,omitempty"`
 
+	// InvolvedEntities 片段涉及/在场的实体 ID（章节 POV 角色 + 章节事件参与者），用于 POV 隔离检索
+	InvolvedEntities []string `json:"involved_entities,omitempty"`
+
 	ArtifactID   string `json:"artifact_id,omitempty"`
 	ArtifactType string `json:"artifact_type,omitempty"` // worldview/characters/outline/novel_foundation
 
+	NoteID    string `json:"note_id,omitempty"`
+	NoteTitle string `json:"note_title,omitempty"`
+
 	RefPath string `json:"ref_path,omitempty"` // JSON Pointer（RFC6901）或近似路径
 }
 
@@ -0,0 +1,87 @@
+package retrieval
+
+import (
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// SummarySegmentType 章节摘要的 segment_type（与正文分片同属一个章节文档，独立覆盖写入）
+const SummarySegmentType = "chapter_summary"
+
+// 检索命名空间：将 segment_type 分组，使摘要、正文、设定卡等在检索时按配额各取 TopK，互不挤占
+const (
+	// NamespaceProse 章节正文
+	NamespaceProse = "prose"
+	// NamespaceSummaries 章节摘要
+	NamespaceSummaries = "summaries"
+	// NamespaceEntityCards 角色设定卡（characters 构件）
+	NamespaceEntityCards = "entity_cards"
+	// NamespaceSettings 其余设定构件（基础设定/世界观/大纲）
+	NamespaceSettings = "settings"
+	// NamespaceNotes 作者导入笔记
+	NamespaceNotes = "notes"
+)
+
+// Namespaces 返回全部检索命名空间（固定顺序，用于配额检索与调试输出）
+func Namespaces() []string {
+	return []string{NamespaceSummaries, NamespaceProse, NamespaceEntityCards, NamespaceSettings, NamespaceNotes}
+}
+
+// NamespaceSegmentTypes 命名空间包含的 segment_type；未知命名空间返回 nil
+func NamespaceSegmentTypes(ns string) []string {
+	switch ns {
+	case NamespaceProse:
+		return []string{"chapter"}
+	case NamespaceSummaries:
+		return []string{SummarySegmentType}
+	case NamespaceEntityCards:
+		return []string{ArtifactSegmentType(entity.ArtifactTypeCharacters)}
+	case NamespaceSettings:
+		return []string{
+			ArtifactSegmentType(entity.ArtifactTypeNovelFoundation),
+			ArtifactSegmentType(entity.ArtifactTypeWorldview),
+			ArtifactSegmentType(entity.ArtifactTypeOutline),
+		}
+	case NamespaceNotes:
+		return []string{NotesSegmentType}
+	default:
+		return nil
+	}
+}
+
+// IsNamespace 是否为已知检索命名空间
+func IsNamespace(ns string) bool {
+	return NamespaceSegmentTypes(ns) != nil
+}
+
+// NormalizeNamespaceQuotas 规范化命名空间配额：忽略未知命名空间与非正配额，单个配额截断到 MaxChapterSearchTopK；
+// 结果为空时返回 nil（表示不启用配额，所有片段共享同一 TopK）
+func NormalizeNamespaceQuotas(quotas map[string]int) map[string]int {
+	var out map[string]int
+	for ns, n := range quotas {
+		ns = strings.ToLower(strings.TrimSpace(ns))
+		if !IsNamespace(ns) || n <= 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]int, len(quotas))
+		}
+		out[ns] = min(n, MaxChapterSearchTopK)
+	}
+	return out
+}
+
+// quotaTotal 配额总和
+func quotaTotal(quotas map[string]int) int {
+	total := 0
+	for _, n := range quotas {
+		total += n
+	}
+	return total
+}
+
+// isChapterDoc 片段是否来自章节（正文或摘要）：参与 POV 隔离与实体聚焦
+func isChapterDoc(docType string) bool {
+	return docType == "chapter" || docType == SummarySegmentType
+}
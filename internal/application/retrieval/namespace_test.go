@@ -0,0 +1,122 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// typedVectorRepo 按 segment_type 返回预置结果（距离递增）
+type typedVectorRepo struct {
+	stubVectorRepo
+	byType map[string][]*VectorSearchResult
+	calls  int
+}
+
+func (r *typedVectorRepo) SearchSegments(_ context.Context, p *VectorSearchParams) ([]*VectorSearchResult, error) {
+	r.calls++
+	var out []*VectorSearchResult
+	for st, results := range r.byType {
+		if len(p.SegmentTypes) > 0 && !contains(p.SegmentTypes, st) {
+			continue
+		}
+		out = append(out, results...)
+	}
+	sortResultsByDistance(out)
+	if len(out) > p.TopK {
+		out = out[:p.TopK]
+	}
+	return out, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortResultsByDistance(results []*VectorSearchResult) {
+	for i := 1; i < len(results); i++ {
+		for j := i; j > 0 && results[j].Score < results[j-1].Score; j-- {
+			results[j], results[j-1] = results[j-1], results[j]
+		}
+	}
+}
+
+func typedResults(docType string, n int, baseDistance float32) []*VectorSearchResult {
+	out := make([]*VectorSearchResult, 0, n)
+	for i := 0; i < n; i++ {
+		meta := SegmentMeta{DocType: docType, ChapterID: fmt.Sprintf("%s-%d", docType, i)}
+		out = append(out, &VectorSearchResult{
+			ID:          fmt.Sprintf("%s-%d", docType, i),
+			Score:       baseDistance + float32(i)*0.01,
+			TextContent: encodeSegmentText(meta, "text"),
+		})
+	}
+	return out
+}
+
+func TestSearchNamespaceQuotas(t *testing.T) {
+	ctx := context.Background()
+	vec := &typedVectorRepo{
+		stubVectorRepo: stubVectorRepo{segments: map[string]int{}},
+		byType: map[string][]*VectorSearchResult{
+			// 摘要与查询更相近，不分配额时会挤占全部名额
+			SummarySegmentType:    typedResults(SummarySegmentType, 10, 0.1),
+			"chapter":             typedResults("chapter", 10, 0.3),
+			"artifact_characters": typedResults("artifact", 10, 0.5),
+		},
+	}
+	engine := NewEngine(stubEmbedder{}, vec, nil, 0)
+
+	out, err := engine.Search(ctx, SearchInput{TenantID: "t1", ProjectID: "p1", Query: "q", TopK: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range out.Segments {
+		if seg.DocType != SummarySegmentType {
+			t.Fatalf("expected summaries to dominate shared TopK, got %s", seg.DocType)
+		}
+	}
+
+	out, err = engine.DebugSearch(ctx, SearchInput{
+		TenantID: "t1", ProjectID: "p1", Query: "q",
+		NamespaceQuotas: map[string]int{NamespaceSummaries: 2, NamespaceProse: 3, NamespaceEntityCards: 1, "unknown": 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Segments) != 6 {
+		t.Fatalf("expected TopK = quota total 6, got %d", len(out.Segments))
+	}
+	counts := map[string]int{}
+	for i, seg := range out.Segments {
+		counts[seg.DocType]++
+		if i > 0 && seg.Score > out.Segments[i-1].Score {
+			t.Fatal("merged segments must be sorted by score")
+		}
+	}
+	if counts[SummarySegmentType] != 2 || counts["chapter"] != 3 || counts["artifact"] != 1 {
+		t.Fatalf("unexpected namespace split %v", counts)
+	}
+	if out.Debug.NamespaceHits[NamespaceProse] != 3 || len(out.Debug.NamespaceQuotas) != 3 {
+		t.Fatalf("unexpected debug info %+v", out.Debug)
+	}
+
+	// 显式指定 segment_type 时不按命名空间检索
+	vec.calls = 0
+	out, err = engine.Search(ctx, SearchInput{
+		TenantID: "t1", ProjectID: "p1", Query: "q", TopK: 4,
+		SegmentTypes:    []string{"chapter"},
+		NamespaceQuotas: map[string]int{NamespaceSummaries: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vec.calls != 1 || len(out.Segments) != 4 || out.Segments[0].DocType != "chapter" {
+		t.Fatalf("segment types should override quotas: calls=%d segments=%d", vec.calls, len(out.Segments))
+	}
+}
@@ -5,6 +5,44 @@ import (
 	"strings"
 )
 
+// 章节生成的 Prompt 召回预算（Worker / SSE / 调试接口共用）
+const (
+	PromptMaxSegments        = 10
+	PromptMaxRunesPerSegment = 360
+)
+
+// PromptSlot 单个召回片段在 Prompt 预算中的位置
+type PromptSlot struct {
+	// Included 是否写入 Prompt（超出条数上限或正文为空的片段不写入）
+	Included bool
+	// Truncated 写入时是否被截断
+	Truncated bool
+	// Runes 片段（压缩为单行后）的字数；KeptRunes 实际写入 Prompt 的字数
+	Runes     int
+	KeptRunes int
+}
+
+// PromptPlacement 按 BuildPromptContext 的规则计算每个片段是否落入 Prompt 预算（与 segments 一一对应）。
+func PromptPlacement(segments []Segment, maxSegments int, maxRunesPerSegment int) []PromptSlot {
+	if maxSegments <= 0 {
+		maxSegments = 10
+	}
+	if maxRunesPerSegment <= 0 {
+		maxRunesPerSegment = 400
+	}
+	slots := make([]PromptSlot, len(segments))
+	for i := range segments {
+		txt := compactOneLine(segments[i].Text)
+		runes := len([]rune(txt))
+		if i >= maxSegments || strings.TrimSpace(txt) == "" {
+			slots[i] = PromptSlot{Runes: runes}
+			continue
+		}
+		slots[i] = PromptSlot{Included: true, Truncated: runes > maxRunesPerSegment, Runes: runes, KeptRunes: min(runes, maxRunesPerSegment)}
+	}
+	return slots
+}
+
 // BuildPromptContext 将召回结果格式化为可直接注入 Prompt 的块。
 // 约束：尽量短，避免把 score 等调试信息塞进 Prompt。
 func BuildPromptContext(segments []Segment, maxSegments int, maxRunesPerSegment int) string {
@@ -37,6 +75,17 @@ func BuildPromptContext(segments []Segment, maxSegments int, maxRunesPerSegment
 				title = strings.TrimSpace(s.ChapterID)
 			}
 			ref = fmt.Sprintf("Chapter:%s", title)
+		case SummarySegmentType:
+			title := strings.TrimSpace(s.ChapterTitle)
+			if title == "" {
+				title = strings.TrimSpace(s.ChapterID)
+			}
+			ref = fmt.Sprintf("Summary:%s", title)
+		case "notes":
+			ref = "Notes"
+			if title := strings.TrimSpace(s.NoteTitle); title != "" {
+				ref = "Notes:" + title
+			}
 		default:
 			ref = "Context"
 		}
@@ -0,0 +1,58 @@
+package retrieval
+
+import (
+	"context"
+	"math"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+// relationBoostMax 章节片段涉及聚焦实体的关联实体时的最大加分（按衰减后的关系强度折算）
+const relationBoostMax = 0.05
+
+// RelatedEntityScorer 关系时效加权：返回与给定实体存在关系的其他实体及其衰减后的有效强度（0~1）。
+// 长期未被强化的关系强度已按半衰期衰减，早已失效的关系几乎不再影响排序。
+type RelatedEntityScorer interface {
+	RelatedStrengths(ctx context.Context, projectID string, entityIDs []string) (map[string]float64, error)
+}
+
+// EnableRelationWeighting 启用关系加权：指定聚焦实体时，涉及其关联实体的章节片段按关系有效强度加分
+func (e *Engine) EnableRelationWeighting(s RelatedEntityScorer) {
+	if e == nil {
+		return
+	}
+	e.relations = s
+}
+
+// boostRelatedSegments 提升涉及聚焦实体关联实体的章节片段得分（聚焦实体本身已由 boostFocusSegments 加权），
+// 返回被加权的片段数；查询关系失败时不加权
+func (e *Engine) boostRelatedSegments(ctx context.Context, projectID string, segments []Segment, focusEntityIDs []string) int {
+	if e.relations == nil || len(focusEntityIDs) == 0 || len(segments) == 0 {
+		return 0
+	}
+	strengths, err := e.relations.RelatedStrengths(ctx, projectID, focusEntityIDs)
+	if err != nil {
+		logger.Warn(ctx, "failed to load related entity strengths, skipping relation weighting", "error", err, "project_id", projectID)
+		return 0
+	}
+	if len(strengths) == 0 {
+		return 0
+	}
+
+	boosted := 0
+	for i := range segments {
+		if !isChapterDoc(segments[i].DocType) {
+			continue
+		}
+		best := 0.0
+		for _, id := range segments[i].InvolvedEntities {
+			best = math.Max(best, strengths[id])
+		}
+		if best <= 0 {
+			continue
+		}
+		segments[i].Score += math.Min(best, 1) * relationBoostMax
+		boosted++
+	}
+	return boosted
+}
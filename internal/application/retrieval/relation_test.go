@@ -0,0 +1,62 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+)
+
+// staticRelationScorer 返回预置的关联实体强度，并记录收到的聚焦实体
+type staticRelationScorer struct {
+	strengths map[string]float64
+	focus     []string
+}
+
+func (s *staticRelationScorer) RelatedStrengths(_ context.Context, _ string, entityIDs []string) (map[string]float64, error) {
+	s.focus = entityIDs
+	return s.strengths, nil
+}
+
+func TestSearchRelationWeightingUsesDecayedStrength(t *testing.T) {
+	ctx := context.Background()
+	results := typedResults("chapter", 3, 0.1)
+	involve := func(i int, ids ...string) {
+		meta := SegmentMeta{DocType: "chapter", ChapterID: results[i].ID, InvolvedEntities: ids}
+		results[i].TextContent = encodeSegmentText(meta, "text")
+	}
+	involve(1, "old-ally")
+	involve(2, "ally")
+	vec := &typedVectorRepo{
+		stubVectorRepo: stubVectorRepo{segments: map[string]int{}},
+		byType:         map[string][]*VectorSearchResult{"chapter": results},
+	}
+	engine := NewEngine(stubEmbedder{}, vec, nil, 0)
+	// 近期强化的盟友保持高强度，久未强化的旧盟友已衰减
+	scorer := &staticRelationScorer{strengths: map[string]float64{"ally": 0.9, "old-ally": 0.05}}
+	engine.EnableRelationWeighting(scorer)
+
+	out, err := engine.DebugSearch(ctx, SearchInput{TenantID: "t1", ProjectID: "p1", Query: "林舟夜探旧宅", TopK: 3, FocusEntityIDs: []string{"hero"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scorer.focus) != 1 || scorer.focus[0] != "hero" {
+		t.Fatalf("scorer should receive focus entities, got %v", scorer.focus)
+	}
+	want := []string{"chapter-2", "chapter-0", "chapter-1"}
+	for i, seg := range out.Segments {
+		if seg.ID != want[i] {
+			t.Fatalf("segment %d = %s, want %s", i, seg.ID, want[i])
+		}
+	}
+	if out.Debug.RelationBoosted != 2 {
+		t.Fatalf("relation boosted = %d, want 2", out.Debug.RelationBoosted)
+	}
+
+	// 未指定聚焦实体时不加权
+	out, err = engine.DebugSearch(ctx, SearchInput{TenantID: "t1", ProjectID: "p1", Query: "林舟夜探旧宅", TopK: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Segments[0].ID != "chapter-0" || out.Debug.RelationBoosted != 0 {
+		t.Fatalf("relation weighting should require focus entities, got %s / %d", out.Segments[0].ID, out.Debug.RelationBoosted)
+	}
+}
@@ -0,0 +1,84 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// DefaultRerankTimeout 未配置时单次精排的时限；超时后保持向量召回顺序
+	DefaultRerankTimeout = 5 * time.Second
+	// rerankMaxDocRunes 送入精排的单个片段最大字符数
+	rerankMaxDocRunes = 1000
+)
+
+// Reranker 召回后的精排：按与查询（章节生成时为章节大纲）的相关性为候选片段打分。
+// 返回与 docs 一一对应的分数，分数越高越相关；实现可以是外部 rerank API 或 LLM 打分。
+type Reranker interface {
+	Rerank(ctx context.Context, query string, docs []string) ([]float64, error)
+}
+
+// EnableRerank 启用精排阶段：向量召回（含 POV 过滤、实体聚焦与命名空间配额）确定入选片段后，
+// 按精排分数重新排序。timeout <= 0 时使用 DefaultRerankTimeout；精排失败时保持原顺序。
+func (e *Engine) EnableRerank(r Reranker, timeout time.Duration) {
+	if e == nil {
+		return
+	}
+	if timeout <= 0 {
+		timeout = DefaultRerankTimeout
+	}
+	e.reranker = r
+	e.rerankTimeout = timeout
+}
+
+// rerank 按精排分数重排片段并以精排分数替换 Score；失败时返回原片段与错误
+func (e *Engine) rerank(ctx context.Context, query string, segments []Segment) ([]Segment, error) {
+	docs := make([]string, len(segments))
+	for i, seg := range segments {
+		docs[i] = truncateRunes(seg.Text, rerankMaxDocRunes)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.rerankTimeout)
+	defer cancel()
+	scores, err := e.reranker.Rerank(ctx, query, docs)
+	if err != nil {
+		return segments, err
+	}
+	if len(scores) != len(segments) {
+		return segments, fmt.Errorf("reranker returned %d scores for %d segments", len(scores), len(segments))
+	}
+
+	out := make([]Segment, len(segments))
+	copy(out, segments)
+	for i := range out {
+		out[i].Score = scores[i]
+	}
+	sortSegmentsByScore(out)
+	return out, nil
+}
+
+// applyRerank 对召回结果执行精排（未启用或片段不足两条时跳过），失败时降级为向量召回顺序
+func (e *Engine) applyRerank(ctx context.Context, query string, out *SearchOutput, dbg *DebugInfo) {
+	if e.reranker == nil || len(out.Segments) < 2 {
+		return
+	}
+	start := time.Now()
+	segments, err := e.rerank(ctx, query, out.Segments)
+	if dbg != nil {
+		dbg.RerankTimeMs = time.Since(start).Milliseconds()
+	}
+	if err != nil {
+		logger.Warn(ctx, "retrieval rerank failed, keeping vector order", "error", err, "segments", len(out.Segments))
+		if dbg != nil {
+			dbg.RerankError = err.Error()
+		}
+		return
+	}
+	out.Segments = segments
+	if dbg != nil {
+		dbg.Reranked = true
+	}
+}
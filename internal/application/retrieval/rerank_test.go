@@ -0,0 +1,76 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// scriptedReranker 按片段位置返回预置分数（或错误），并记录收到的查询
+type scriptedReranker struct {
+	scores []float64
+	err    error
+	query  string
+	docs   int
+}
+
+func (r *scriptedReranker) Rerank(_ context.Context, query string, docs []string) ([]float64, error) {
+	r.query, r.docs = query, len(docs)
+	return r.scores, r.err
+}
+
+func TestSearchRerankReordersSegments(t *testing.T) {
+	ctx := context.Background()
+	vec := &typedVectorRepo{
+		stubVectorRepo: stubVectorRepo{segments: map[string]int{}},
+		byType:         map[string][]*VectorSearchResult{"chapter": typedResults("chapter", 3, 0.1)},
+	}
+	engine := NewEngine(stubEmbedder{}, vec, nil, 0)
+	in := SearchInput{TenantID: "t1", ProjectID: "p1", Query: "林舟夜探旧宅", TopK: 3}
+
+	base, err := engine.Search(ctx, in)
+	if err != nil || len(base.Segments) != 3 {
+		t.Fatalf("unexpected baseline %+v, err %v", base, err)
+	}
+
+	rr := &scriptedReranker{scores: []float64{0.1, 0.2, 0.9}}
+	engine.EnableRerank(rr, time.Second)
+	out, err := engine.DebugSearch(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.query != in.Query || rr.docs != 3 {
+		t.Fatalf("reranker should receive the query and all segments, got %q / %d", rr.query, rr.docs)
+	}
+	want := []string{base.Segments[2].ID, base.Segments[1].ID, base.Segments[0].ID}
+	for i, seg := range out.Segments {
+		if seg.ID != want[i] {
+			t.Fatalf("segment %d = %s, want %s", i, seg.ID, want[i])
+		}
+	}
+	if out.Segments[0].Score != 0.9 || !out.Debug.Reranked {
+		t.Fatalf("rerank score and debug flag should be set, got %+v / %+v", out.Segments[0], out.Debug)
+	}
+
+	// 精排失败时保持向量召回顺序
+	engine.EnableRerank(&scriptedReranker{err: errors.New("rerank unavailable")}, time.Second)
+	out, err = engine.DebugSearch(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, seg := range out.Segments {
+		if seg.ID != base.Segments[i].ID {
+			t.Fatalf("failed rerank should keep vector order, got %s at %d", seg.ID, i)
+		}
+	}
+	if out.Debug.Reranked || out.Debug.RerankError == "" {
+		t.Fatalf("debug should record the rerank error, got %+v", out.Debug)
+	}
+
+	// 分数个数不符视为失败
+	engine.EnableRerank(&scriptedReranker{scores: []float64{1}}, time.Second)
+	if out, _ = engine.DebugSearch(ctx, in); out.Debug.Reranked {
+		t.Fatal("mismatched score count should not be applied")
+	}
+}
@@ -0,0 +1,59 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultSegmentPageSize 列出项目片段的默认每页条数
+	DefaultSegmentPageSize = 100
+	// MaxSegmentPageSize 列出项目片段的每页条数上限
+	MaxSegmentPageSize = 1000
+)
+
+// ListSegments 按片段 ID 顺序分页列出项目已索引的片段（不含向量）。
+// 不依赖 Embedder：向量存储可用即可浏览。
+func (i *Indexer) ListSegments(ctx context.Context, params SegmentListParams) (*SegmentPage, error) {
+	if strings.TrimSpace(params.TenantID) == "" || strings.TrimSpace(params.ProjectID) == "" {
+		return nil, fmt.Errorf("tenant_id and project_id are required")
+	}
+	if strings.ContainsAny(params.Cursor, "\"\\ ") {
+		return nil, ErrInvalidSegmentCursor
+	}
+	scanner, err := i.scanner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if params.Limit <= 0 {
+		params.Limit = DefaultSegmentPageSize
+	}
+	if params.Limit > MaxSegmentPageSize {
+		params.Limit = MaxSegmentPageSize
+	}
+	return scanner.ListProjectSegments(ctx, &params)
+}
+
+// CountSegments 统计项目已索引片段数（按 segment_type 分组）
+func (i *Indexer) CountSegments(ctx context.Context, tenantID, projectID string) (map[string]int64, error) {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
+		return nil, fmt.Errorf("tenant_id and project_id are required")
+	}
+	scanner, err := i.scanner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return scanner.CountProjectSegments(ctx, tenantID, projectID)
+}
+
+func (i *Indexer) scanner(ctx context.Context) (SegmentScanner, error) {
+	if err := i.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	scanner, ok := i.vector.(SegmentScanner)
+	if !ok {
+		return nil, ErrSegmentScanUnsupported
+	}
+	return scanner, nil
+}
@@ -0,0 +1,138 @@
+package retrieval
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SparseEncoder 将文本编码为稀疏向量，作为向量库原生混合检索的关键词通道（BM25 / SPLADE 等）
+type SparseEncoder interface {
+	EncodeDocument(text string) SparseVector
+	EncodeQuery(text string) SparseVector
+}
+
+const (
+	// sparseIndexMask 词项哈希映射到 [0, 2^31) 的稀疏维度
+	sparseIndexMask = 0x7fffffff
+
+	defaultBM25K1        = 1.2
+	defaultBM25B         = 0.75
+	defaultBM25AvgDocLen = 600 // 约等于一个默认分块（800 字）的词项数
+)
+
+// BM25Encoder 本地 BM25 词频编码器：
+// - 分词：汉字/假名/谚文按相邻二元组（单字成段时取单字），其他字母数字按词（忽略单个 ASCII 字符）；
+// - 词项经 FNV-1a 哈希映射到稀疏维度，无需维护词表；
+// - 文档侧按 BM25 词频饱和与长度归一化计算权重，查询侧每个词项权重为 1。
+//
+// Milvus 2.4 无内置 BM25，这里不维护语料 IDF；稀疏通道主要补足专名/术语的字面匹配，
+// 最终得分由 WeightedRanker 与稠密通道加权融合。
+type BM25Encoder struct {
+	k1        float64
+	b         float64
+	avgDocLen float64
+}
+
+// NewBM25Encoder 创建默认参数的 BM25 编码器
+func NewBM25Encoder() *BM25Encoder {
+	return &BM25Encoder{k1: defaultBM25K1, b: defaultBM25B, avgDocLen: defaultBM25AvgDocLen}
+}
+
+// EncodeDocument 文档侧编码：w = tf·(k1+1) / (tf + k1·(1 - b + b·|d|/avgdl))
+func (e *BM25Encoder) EncodeDocument(text string) SparseVector {
+	terms := sparseTerms(text)
+	if len(terms) == 0 {
+		return SparseVector{}
+	}
+	tf := make(map[uint32]float64, len(terms))
+	for _, t := range terms {
+		tf[sparseIndex(t)]++
+	}
+	norm := e.k1 * (1 - e.b + e.b*float64(len(terms))/e.avgDocLen)
+	weights := make(map[uint32]float32, len(tf))
+	for idx, f := range tf {
+		weights[idx] = float32(f * (e.k1 + 1) / (f + norm))
+	}
+	return toSparseVector(weights)
+}
+
+// EncodeQuery 查询侧编码：去重后每个词项权重为 1
+func (e *BM25Encoder) EncodeQuery(text string) SparseVector {
+	terms := sparseTerms(text)
+	if len(terms) == 0 {
+		return SparseVector{}
+	}
+	weights := make(map[uint32]float32, len(terms))
+	for _, t := range terms {
+		weights[sparseIndex(t)] = 1
+	}
+	return toSparseVector(weights)
+}
+
+// sparseTerms 切分词项（小写）
+func sparseTerms(text string) []string {
+	var (
+		terms []string
+		word  []rune
+		cjk   []rune
+	)
+	flushWord := func() {
+		if len(word) > 1 || (len(word) == 1 && word[0] > unicode.MaxASCII) {
+			terms = append(terms, string(word))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func sparseIndex(term string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(term))
+	return h.Sum32() & sparseIndexMask
+}
+
+func toSparseVector(weights map[uint32]float32) SparseVector {
+	v := SparseVector{
+		Indices: make([]uint32, 0, len(weights)),
+		Values:  make([]float32, 0, len(weights)),
+	}
+	for idx := range weights {
+		v.Indices = append(v.Indices, idx)
+	}
+	sort.Slice(v.Indices, func(i, j int) bool { return v.Indices[i] < v.Indices[j] })
+	for _, idx := range v.Indices {
+		v.Values = append(v.Values, weights[idx])
+	}
+	return v
+}
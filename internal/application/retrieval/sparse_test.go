@@ -0,0 +1,93 @@
+package retrieval
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSparseTerms(t *testing.T) {
+	got := sparseTerms("林默拔剑。Sword of 7 Seas! 雪")
+	want := []string{"林默", "默拔", "拔剑", "sword", "of", "seas", "雪"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sparseTerms = %v, want %v", got, want)
+	}
+}
+
+func TestBM25EncoderQueryMatchesDocument(t *testing.T) {
+	enc := NewBM25Encoder()
+	doc := enc.EncodeDocument("林默在青云宗后山练剑，林默的剑意已成。")
+	query := enc.EncodeQuery("林默 剑意")
+
+	if doc.Empty() || query.Empty() {
+		t.Fatal("expected non-empty sparse vectors")
+	}
+	for i := 1; i < len(doc.Indices); i++ {
+		if doc.Indices[i-1] >= doc.Indices[i] {
+			t.Fatal("document indices must be strictly ascending")
+		}
+	}
+
+	weights := make(map[uint32]float32, len(doc.Indices))
+	for i, idx := range doc.Indices {
+		weights[idx] = doc.Values[i]
+	}
+	var score float32
+	for i, idx := range query.Indices {
+		score += query.Values[i] * weights[idx]
+	}
+	if score <= 0 {
+		t.Fatalf("expected positive inner product, got %f", score)
+	}
+
+	// 重复出现的词项权重更高，但受 k1 饱和（不超过 k1+1）
+	repeated := weights[sparseIndex("林默")]
+	single := weights[sparseIndex("青云")]
+	if repeated <= single || repeated >= defaultBM25K1+1 {
+		t.Fatalf("unexpected tf saturation: repeated=%f single=%f", repeated, single)
+	}
+
+	if v := enc.EncodeQuery("，。！"); !v.Empty() {
+		t.Fatalf("punctuation-only query should be empty, got %v", v)
+	}
+}
+
+// weightRecordingRepo 记录检索参数中的稀疏查询与融合权重
+type weightRecordingRepo struct {
+	stubVectorRepo
+	params []*VectorSearchParams
+}
+
+func (r *weightRecordingRepo) SearchSegments(_ context.Context, p *VectorSearchParams) ([]*VectorSearchResult, error) {
+	r.params = append(r.params, p)
+	return nil, nil
+}
+
+func TestSearchPassesKeywordLegAndFusionWeights(t *testing.T) {
+	ctx := context.Background()
+	vec := &weightRecordingRepo{stubVectorRepo: stubVectorRepo{segments: map[string]int{}}}
+	engine := NewEngine(stubEmbedder{}, vec, nil, 0)
+
+	out, err := engine.DebugSearch(ctx, SearchInput{
+		TenantID: "t1", ProjectID: "p1", Query: "青云宗 掌门", SeriesProjectIDs: []string{"p0"},
+		VectorWeight: 0.4, KeywordWeight: 0.6,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vec.params) != 2 {
+		t.Fatalf("expected current and series project searches, got %d", len(vec.params))
+	}
+	for _, p := range vec.params {
+		if len(p.QuerySparse.Indices) == 0 || p.DenseWeight != 0.4 || p.SparseWeight != 0.6 {
+			t.Fatalf("keyword leg and weights should reach the vector store, got %+v", p)
+		}
+	}
+	if out.Debug.KeywordTerms != len(vec.params[0].QuerySparse.Indices) {
+		t.Fatalf("debug keyword terms %d, want %d", out.Debug.KeywordTerms, len(vec.params[0].QuerySparse.Indices))
+	}
+
+	if _, err := engine.Search(ctx, SearchInput{TenantID: "t1", ProjectID: "p1", Query: "q", KeywordWeight: 1.5}); err == nil {
+		t.Fatal("out-of-range keyword weight should be rejected")
+	}
+}
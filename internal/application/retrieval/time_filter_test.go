@@ -0,0 +1,39 @@
+package retrieval
+
+import "testing"
+
+func TestParseTimeFilter(t *testing.T) {
+	cases := map[string]TimeFilter{
+		"narrative":    TimeFilterNarrative,
+		" Story_Time ": TimeFilterStoryTime,
+		"STORY_TIME":   TimeFilterStoryTime,
+		"":             "",
+		"chapter":      "",
+	}
+	for in, want := range cases {
+		if got := ParseTimeFilter(in); got != want {
+			t.Errorf("ParseTimeFilter(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResolveTimeFilter(t *testing.T) {
+	cases := []struct {
+		name string
+		in   SearchInput
+		want TimeFilter
+	}{
+		{name: "narrative position given", in: SearchInput{CurrentNarrativePos: 2000001, CurrentStoryTime: 10}, want: TimeFilterNarrative},
+		{name: "story time only", in: SearchInput{CurrentStoryTime: 10}, want: TimeFilterStoryTime},
+		{name: "nothing given", in: SearchInput{}, want: TimeFilterStoryTime},
+		{name: "explicit story time wins", in: SearchInput{CurrentNarrativePos: 2000001, TimeFilter: TimeFilterStoryTime}, want: TimeFilterStoryTime},
+		{name: "explicit narrative without position", in: SearchInput{TimeFilter: TimeFilterNarrative}, want: TimeFilterNarrative},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveTimeFilter(tc.in); got != tc.want {
+				t.Fatalf("resolveTimeFilter = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,29 @@
 package retrieval
 
+import "strings"
+
+// TimeFilter 检索时间过滤维度
+type TimeFilter string
+
+const (
+	// TimeFilterNarrative 按叙事位置过滤：仅召回读者在当前位置之前已读到的内容（回忆/倒叙章节不受影响）。
+	TimeFilterNarrative TimeFilter = "narrative"
+	// TimeFilterStoryTime 按故事内时间过滤：仅召回故事时间不晚于当前时间的内容。
+	TimeFilterStoryTime TimeFilter = "story_time"
+)
+
+// ParseTimeFilter 解析时间过滤维度；空值或未知值返回空（由引擎按输入自动选择）。
+func ParseTimeFilter(s string) TimeFilter {
+	switch TimeFilter(strings.ToLower(strings.TrimSpace(s))) {
+	case TimeFilterNarrative:
+		return TimeFilterNarrative
+	case TimeFilterStoryTime:
+		return TimeFilterStoryTime
+	default:
+		return ""
+	}
+}
+
 // SearchInput 本地检索输入。
 type SearchInput struct {
 	TenantID         string
@@ -8,9 +32,36 @@ type SearchInput struct {
 	CurrentStoryTime int64
 	TopK             int
 
+	// CurrentNarrativePos 当前叙事位置（见 entity.NarrativePosition），仅召回严格早于该位置的章节片段。
+	CurrentNarrativePos int64
+	// TimeFilter 时间过滤维度；为空时优先按叙事位置，未提供叙事位置则回退为故事时间。
+	TimeFilter TimeFilter
+
+	// POVEntityID 非空时启用 POV 隔离：章节片段仅保留涉及该角色的内容（设定类片段不受影响）。
+	POVEntityID string
+
+	// FocusEntityIDs 聚焦实体（通常由 DetectFocusEntities 从章节大纲识别）：涉及这些实体的章节片段优先排序，其他片段不剔除。
+	FocusEntityIDs []string
+
+	// SeriesProjectIDs 同系列前作项目 ID（只读召回，不受时间/叙事位置过滤）；为空表示仅检索当前项目。
+	SeriesProjectIDs []string
+
 	// SegmentTypes 为空表示不过滤；非空则仅检索指定 segment_type。
 	SegmentTypes []string
 
+	// NamespaceQuotas 各检索命名空间（见 Namespaces）的召回配额，如 summaries:4 / prose:6 / entity_cards:2；
+	// 非空时按命名空间分别召回后合并，TopK 取配额总和。指定 SegmentTypes 时忽略。
+	NamespaceQuotas map[string]int
+
+	// BranchKey 检索的分支（what-if 时间线），为空表示主线；非主线时本分支片段覆盖同一文档的主线片段，
+	// 本分支未写入的文档回退到主线。仅作用于当前项目，系列前作只读主线。
+	BranchKey string
+
+	// VectorWeight / KeywordWeight 混合检索中稠密向量与 BM25 关键词两路的融合权重（[0, 1]）；
+	// 均为 0 时使用配置默认值，融合方式为 rrf 或向量存储不支持混合检索时忽略
+	VectorWeight  float64
+	KeywordWeight float64
+
 	IncludeEntities  bool
 	IncludeEmbedding bool
 }
@@ -21,15 +72,27 @@ type Segment struct {
 	Score  float64
 	Source string
 
+	// ProjectID 片段所属项目（跨系列召回时可能为前作）
+	ProjectID string
+
 	DocType string
 
 	ChapterID    string
 	ChapterTitle string
 	StoryTime    int64
+	NarrativePos int64
+
+	InvolvedEntities []string
 
 	ArtifactID   string
 	ArtifactType string
 	RefPath      string
+
+	NoteID    string
+	NoteTitle string
+
+	// BranchKey 片段所属分支（main 为主线；分支检索时本分支片段覆盖主线）
+	BranchKey string
 }
 
 type EntityRef struct {
@@ -43,6 +106,31 @@ type DebugInfo struct {
 	EntitySearchTimeMs int64
 	TotalCandidates    int
 	FilteredCandidates int
+
+	// TimeFilter 实际生效的时间过滤维度
+	TimeFilter TimeFilter
+	// VectorTopK 向量召回的候选数（POV 隔离时放大）
+	VectorTopK int
+	// POVFiltered 因 POV 隔离被剔除的片段数
+	POVFiltered int
+	// FocusEntityIDs 生效的聚焦实体；FocusBoosted 因涉及聚焦实体被提升排序的片段数
+	FocusEntityIDs []string
+	FocusBoosted   int
+	// RelationBoosted 因涉及聚焦实体的关联实体（按衰减后的关系强度）被加权的片段数
+	RelationBoosted int
+	// Partitions 实际检索的向量分区（当前项目在前，其余为同系列前作）
+	Partitions []string
+	// NamespaceQuotas 生效的命名空间配额；NamespaceHits 各命名空间最终入选的片段数（未启用配额时为空）
+	NamespaceQuotas map[string]int
+	NamespaceHits   map[string]int
+	// BranchKey 实际检索的分支（仅作用于当前项目）
+	BranchKey string
+	// KeywordTerms 查询经 BM25 分词后的词项数（混合检索关键词一路的查询维度；为 0 时该路无召回）
+	KeywordTerms int
+	// Reranked 是否经精排重新排序；RerankError 精排失败（保持向量召回顺序）时的错误
+	Reranked     bool
+	RerankTimeMs int64
+	RerankError  string
 }
 
 type SearchOutput struct {
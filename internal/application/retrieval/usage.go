@@ -0,0 +1,203 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrVectorQuotaExceeded 写入后租户或项目的向量片段数将超过配额
+var ErrVectorQuotaExceeded = errors.New("vector quota exceeded")
+
+// 配额范围
+const (
+	QuotaScopeTenant  = "tenant"
+	QuotaScopeProject = "project"
+)
+
+// QuotaExceededError 向量配额超限详情（errors.Is 匹配 ErrVectorQuotaExceeded）
+type QuotaExceededError struct {
+	Scope     string // tenant / project
+	Limit     int64
+	Used      int64
+	Requested int64 // 本次写入新增的片段数
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("vector quota exceeded: %s has %d/%d segments, indexing needs %d more", e.Scope, e.Used, e.Limit, e.Requested)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrVectorQuotaExceeded
+}
+
+// QuotaLimits 向量片段数上限（0 表示不限）
+type QuotaLimits struct {
+	MaxSegmentsPerTenant  int64 `json:"max_segments_per_tenant"`
+	MaxSegmentsPerProject int64 `json:"max_segments_per_project"`
+}
+
+// VectorUsage 文档 / 项目 / 租户的向量用量
+type VectorUsage struct {
+	Segments  int64
+	TextBytes int64 // 片段正文（含元数据头）字节数
+}
+
+// UsageCounter 向量用量计数（port）：按文档（doc_id + segment_type）记录片段数，汇总到项目与租户。
+// 由基础设施层提供原子实现（例如 Redis Lua）。
+type UsageCounter interface {
+	// ReplaceDoc 将文档用量替换为 usage，返回替换前的用量；新增片段使租户或项目超过 limits 时不写入并返回 *QuotaExceededError
+	ReplaceDoc(ctx context.Context, tenantID, projectID, docKey string, usage VectorUsage, limits QuotaLimits) (VectorUsage, error)
+	// PurgeProject 清空项目用量
+	PurgeProject(ctx context.Context, tenantID, projectID string) error
+	// TenantUsage 租户下各项目的用量（项目 ID → 用量）
+	TenantUsage(ctx context.Context, tenantID string) (map[string]VectorUsage, error)
+}
+
+// UsageSummary 对外展示的用量（ApproxBytes = 正文字节 + 片段数 × 稠密向量字节，不含索引与稀疏向量开销）
+type UsageSummary struct {
+	Segments    int64 `json:"segments"`
+	ApproxBytes int64 `json:"approx_bytes"`
+}
+
+// ProjectUsage 项目用量
+type ProjectUsage struct {
+	ProjectID string `json:"project_id"`
+	UsageSummary
+}
+
+// UsageReport 租户向量用量报告
+type UsageReport struct {
+	Tenant   UsageSummary   `json:"tenant"`
+	Projects []ProjectUsage `json:"projects"`
+	Limits   QuotaLimits    `json:"limits"`
+}
+
+// EnableUsageQuota 开启向量用量计数与配额；dimension 为稠密向量维度，用于估算存储量
+func (i *Indexer) EnableUsageQuota(counter UsageCounter, limits QuotaLimits, dimension int) {
+	if i == nil {
+		return
+	}
+	i.usage = counter
+	i.limits = limits
+	i.dimension = dimension
+}
+
+// UsageTracked 是否开启了向量用量计数
+func (i *Indexer) UsageTracked() bool {
+	return i != nil && i.usage != nil
+}
+
+// Usage 租户向量用量报告（项目按片段数降序）
+func (i *Indexer) Usage(ctx context.Context, tenantID string) (*UsageReport, error) {
+	if strings.TrimSpace(tenantID) == "" {
+		return nil, fmt.Errorf("tenant_id is required")
+	}
+	if !i.UsageTracked() {
+		return nil, ErrVectorDisabled
+	}
+	byProject, err := i.usage.TenantUsage(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{Projects: make([]ProjectUsage, 0, len(byProject)), Limits: i.limits}
+	for projectID, u := range byProject {
+		if u.Segments <= 0 && u.TextBytes <= 0 {
+			continue
+		}
+		s := i.summarize(u)
+		report.Tenant.Segments += s.Segments
+		report.Tenant.ApproxBytes += s.ApproxBytes
+		report.Projects = append(report.Projects, ProjectUsage{ProjectID: projectID, UsageSummary: s})
+	}
+	sort.Slice(report.Projects, func(a, b int) bool {
+		if report.Projects[a].Segments != report.Projects[b].Segments {
+			return report.Projects[a].Segments > report.Projects[b].Segments
+		}
+		return report.Projects[a].ProjectID < report.Projects[b].ProjectID
+	})
+	return report, nil
+}
+
+func (i *Indexer) summarize(u VectorUsage) UsageSummary {
+	return UsageSummary{Segments: u.Segments, ApproxBytes: u.TextBytes + u.Segments*int64(i.dimension)*4}
+}
+
+// reserve 写入前按新片段数替换文档用量并检查配额；未开启计数时直接放行
+func (i *Indexer) reserve(ctx context.Context, tenantID, projectID, docKey string, segments []*VectorStorySegment) (VectorUsage, error) {
+	if !i.UsageTracked() {
+		return VectorUsage{}, nil
+	}
+	usage := VectorUsage{Segments: int64(len(segments))}
+	for _, s := range segments {
+		usage.TextBytes += int64(len(s.TextContent))
+	}
+	return i.usage.ReplaceDoc(ctx, tenantID, projectID, docKey, usage, i.limits)
+}
+
+// restore 写入失败时回写文档用量（尽力而为，计数本身为近似值）
+func (i *Indexer) restore(ctx context.Context, tenantID, projectID, docKey string, usage VectorUsage) {
+	if !i.UsageTracked() {
+		return
+	}
+	_, _ = i.usage.ReplaceDoc(ctx, tenantID, projectID, docKey, usage, QuotaLimits{})
+}
+
+func usageDocKey(segmentType, docID string) string {
+	return segmentType + "/" + docID
+}
+
+// RecountUsage 按向量存储中实际的片段重新统计项目用量（修正写入失败、批量删除等造成的计数漂移），返回重算前后的项目用量。
+// 需开启用量计数且向量存储支持按项目遍历片段。
+func (i *Indexer) RecountUsage(ctx context.Context, tenantID, projectID string) (before, after VectorUsage, err error) {
+	if strings.TrimSpace(tenantID) == "" || strings.TrimSpace(projectID) == "" {
+		return before, after, fmt.Errorf("tenant_id and project_id are required")
+	}
+	if !i.UsageTracked() {
+		return before, after, ErrVectorDisabled
+	}
+	scanner, err := i.scanner(ctx)
+	if err != nil {
+		return before, after, err
+	}
+	byProject, err := i.usage.TenantUsage(ctx, tenantID)
+	if err != nil {
+		return before, after, err
+	}
+	before = byProject[projectID]
+
+	docs := make(map[string]VectorUsage)
+	params := &SegmentListParams{TenantID: tenantID, ProjectID: projectID, Limit: MaxSegmentPageSize}
+	for {
+		page, err := scanner.ListProjectSegments(ctx, params)
+		if err != nil {
+			return before, after, err
+		}
+		for _, s := range page.Segments {
+			key := usageBranchDocKey(s.SegmentType, s.DocID, s.BranchKey)
+			u := docs[key]
+			u.Segments++
+			u.TextBytes += int64(len(s.TextContent))
+			docs[key] = u
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+
+	if err := i.usage.PurgeProject(ctx, tenantID, projectID); err != nil {
+		return before, after, err
+	}
+	for key, u := range docs {
+		if _, err := i.usage.ReplaceDoc(ctx, tenantID, projectID, key, u, QuotaLimits{}); err != nil {
+			return before, after, err
+		}
+		after.Segments += u.Segments
+		after.TextBytes += u.TextBytes
+	}
+	return before, after, nil
+}
@@ -0,0 +1,128 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+type stubEmbedder struct{}
+
+func (stubEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i := range texts {
+		out[i] = []float64{1, 0}
+	}
+	return out, nil
+}
+
+type stubVectorRepo struct {
+	segments map[string]int // docID → 片段数
+}
+
+func (r *stubVectorRepo) EnsureStorySegmentsCollection(context.Context) error { return nil }
+func (r *stubVectorRepo) SearchSegments(context.Context, *VectorSearchParams) ([]*VectorSearchResult, error) {
+	return nil, nil
+}
+func (r *stubVectorRepo) DeleteSegmentsByDocAndType(_ context.Context, _, _, docID, _ string) error {
+	delete(r.segments, docID)
+	return nil
+}
+func (r *stubVectorRepo) DeleteProjectSegments(context.Context, string, string) error {
+	r.segments = map[string]int{}
+	return nil
+}
+func (r *stubVectorRepo) InsertSegments(_ context.Context, _, _ string, segments []*VectorStorySegment) error {
+	for _, s := range segments {
+		r.segments[s.DocID]++
+	}
+	return nil
+}
+
+// memUsageCounter 单项目内存计数（仅检查项目上限）
+type memUsageCounter struct {
+	docs map[string]VectorUsage
+}
+
+func (m *memUsageCounter) total() (t VectorUsage) {
+	for _, u := range m.docs {
+		t.Segments += u.Segments
+		t.TextBytes += u.TextBytes
+	}
+	return t
+}
+
+func (m *memUsageCounter) ReplaceDoc(_ context.Context, _, _, docKey string, usage VectorUsage, limits QuotaLimits) (VectorUsage, error) {
+	prev := m.docs[docKey]
+	delta := usage.Segments - prev.Segments
+	if used := m.total().Segments; delta > 0 && limits.MaxSegmentsPerProject > 0 && used+delta > limits.MaxSegmentsPerProject {
+		return prev, &QuotaExceededError{Scope: QuotaScopeProject, Limit: limits.MaxSegmentsPerProject, Used: used, Requested: delta}
+	}
+	m.docs[docKey] = usage
+	return prev, nil
+}
+
+func (m *memUsageCounter) PurgeProject(context.Context, string, string) error {
+	m.docs = map[string]VectorUsage{}
+	return nil
+}
+
+func (m *memUsageCounter) TenantUsage(context.Context, string) (map[string]VectorUsage, error) {
+	return map[string]VectorUsage{"p1": m.total()}, nil
+}
+
+func TestIndexerEnforcesVectorQuota(t *testing.T) {
+	ctx := context.Background()
+	vec := &stubVectorRepo{segments: map[string]int{}}
+	counter := &memUsageCounter{docs: map[string]VectorUsage{}}
+	idx := NewIndexer(stubEmbedder{}, vec, 0)
+	idx.chunkSizeRunes, idx.chunkOverlapRunes = 10, 0
+	idx.EnableUsageQuota(counter, QuotaLimits{MaxSegmentsPerProject: 3}, 2)
+
+	note := &entity.ProjectNote{ID: "n1", Content: strings.Repeat("甲", 20)}
+	if err := idx.IndexNotes(ctx, "t1", "p1", note); err != nil {
+		t.Fatalf("index within quota: %v", err)
+	}
+	if vec.segments["n1"] != 2 {
+		t.Fatalf("expected 2 segments, got %d", vec.segments["n1"])
+	}
+
+	big := &entity.ProjectNote{ID: "n2", Content: strings.Repeat("乙", 20)}
+	err := idx.IndexNotes(ctx, "t1", "p1", big)
+	var qe *QuotaExceededError
+	if !errors.Is(err, ErrVectorQuotaExceeded) || !errors.As(err, &qe) || qe.Scope != QuotaScopeProject || qe.Used != 2 {
+		t.Fatalf("expected project quota error, got %v", err)
+	}
+	if vec.segments["n2"] != 0 {
+		t.Fatal("rejected document must not be written")
+	}
+
+	// 缩减已有文档总是放行，腾出的额度可被其他文档使用
+	note.Content = strings.Repeat("甲", 5)
+	if err := idx.IndexNotes(ctx, "t1", "p1", note); err != nil {
+		t.Fatalf("shrink: %v", err)
+	}
+	if err := idx.IndexNotes(ctx, "t1", "p1", big); err != nil {
+		t.Fatalf("index after shrink: %v", err)
+	}
+
+	report, err := idx.Usage(ctx, "t1")
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	if report.Tenant.Segments != 3 || report.Tenant.ApproxBytes < 3*2*4 {
+		t.Fatalf("unexpected usage %+v", report.Tenant)
+	}
+
+	if err := idx.PurgeProject(ctx, "t1", "p1"); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if counter.total().Segments != 0 {
+		t.Fatal("purge should reset usage")
+	}
+}
@@ -8,9 +8,55 @@ type VectorRepository interface {
 	EnsureStorySegmentsCollection(ctx context.Context) error
 	SearchSegments(ctx context.Context, params *VectorSearchParams) ([]*VectorSearchResult, error)
 	DeleteSegmentsByDocAndType(ctx context.Context, tenantID, projectID, docID, segmentType string) error
+	DeleteProjectSegments(ctx context.Context, tenantID, projectID string) error
 	InsertSegments(ctx context.Context, tenantID, projectID string, segments []*VectorStorySegment) error
 }
 
+// PartitionNamer 可选：按租户/项目分区的向量存储实现该接口，用于调试输出实际检索的分区。
+type PartitionNamer interface {
+	PartitionName(tenantID, projectID string) string
+}
+
+// SegmentScanner 可选：支持按项目遍历片段的向量存储实现该接口，用于导出、去重、清理与管理端浏览。
+type SegmentScanner interface {
+	ListProjectSegments(ctx context.Context, params *SegmentListParams) (*SegmentPage, error)
+	CountProjectSegments(ctx context.Context, tenantID, projectID string) (map[string]int64, error)
+}
+
+// NarrativePositionUpdater 可选：支持原地回写片段叙事位置的向量存储实现该接口，
+// 章节重排/重编号后无需重新向量化即可让叙事位置过滤保持正确。
+type NarrativePositionUpdater interface {
+	// UpdateNarrativePositions 按 chapter_id → narrative_pos 回写该项目章节片段的叙事位置
+	UpdateNarrativePositions(ctx context.Context, tenantID, projectID string, positions map[string]int64) error
+}
+
+// SegmentListParams 按项目分页列出片段的参数（游标为上一页最后一条片段 ID）
+type SegmentListParams struct {
+	TenantID     string
+	ProjectID    string
+	SegmentTypes []string
+	Cursor       string
+	Limit        int
+}
+
+// SegmentPage 一页已存储片段；NextCursor 为空表示已遍历完毕
+type SegmentPage struct {
+	Segments   []*StoredSegment
+	NextCursor string
+}
+
+// StoredSegment 已存储片段（不含向量）
+type StoredSegment struct {
+	ID           string
+	DocID        string
+	SegmentType  string
+	TextContent  string
+	StoryTime    int64
+	NarrativePos int64
+	// BranchKey 片段所属分支（空值为主线）
+	BranchKey string
+}
+
 type VectorSearchParams struct {
 	TenantID         string
 	ProjectID        string
@@ -18,23 +64,55 @@ type VectorSearchParams struct {
 	CurrentStoryTime int64
 	TopK             int
 	SegmentTypes     []string
+
+	// CurrentNarrativePos / TimeFilter 见 SearchInput；TimeFilter 已由引擎解析为确定值。
+	CurrentNarrativePos int64
+	TimeFilter          TimeFilter
+
+	// QuerySparse 查询的稀疏向量；向量存储支持混合检索时与 QueryVector 一同召回，否则忽略
+	QuerySparse SparseVector
+	// DenseWeight / SparseWeight 两路融合权重，均为 0 时使用向量存储的默认配置
+	DenseWeight  float64
+	SparseWeight float64
+
+	// BranchKey 检索的分支（空值为主线）；仅 BranchScopedStore 按分支过滤，见该接口说明
+	BranchKey string
 }
 
 type VectorSearchResult struct {
-	ID          string
-	Score       float32
-	TextContent string
-	ChapterID   string
-	StoryTime   int64
+	ID           string
+	Score        float32
+	TextContent  string
+	ChapterID    string
+	StoryTime    int64
+	NarrativePos int64
+	// BranchKey 片段所属分支（空值为主线）
+	BranchKey string
 }
 
 type VectorStorySegment struct {
-	ID          string
-	TenantID    string
-	ProjectID   string
-	DocID       string
-	StoryTime   int64
-	SegmentType string
-	TextContent string
-	Vector      []float32
+	ID           string
+	TenantID     string
+	ProjectID    string
+	DocID        string
+	StoryTime    int64
+	NarrativePos int64
+	SegmentType  string
+	TextContent  string
+	Vector       []float32
+	// Sparse 稀疏向量（关键词通道）；集合不含稀疏字段时忽略
+	Sparse SparseVector
+	// BranchKey 片段所属分支（空值为主线）；集合不支持分支隔离时忽略
+	BranchKey string
+}
+
+// SparseVector 稀疏向量（维度下标 → 权重），Indices 与 Values 一一对应
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+// Empty 是否不含任何维度
+func (v SparseVector) Empty() bool {
+	return len(v.Indices) == 0
 }
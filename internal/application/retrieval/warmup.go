@@ -0,0 +1,17 @@
+package retrieval
+
+import "context"
+
+// warmupQuery 嵌入预热使用的短文本
+const warmupQuery = "warmup"
+
+// WarmupCollection 确保 story_segments 集合存在并已加载到内存，首个检索请求不再承担加载耗时
+func (e *Engine) WarmupCollection(ctx context.Context) error {
+	return e.ensureReady(ctx)
+}
+
+// WarmupEmbedder 以一条短文本调用嵌入服务，提前建立连接
+func (e *Engine) WarmupEmbedder(ctx context.Context) error {
+	_, err := e.embedQuery(ctx, warmupQuery)
+	return err
+}
@@ -0,0 +1,144 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// 激活校验 Webhook 调用超时的默认值与上限
+const (
+	DefaultActivationWebhookTimeout = 5 * time.Second
+	MaxActivationWebhookTimeout     = 30 * time.Second
+)
+
+// 触发激活校验的操作
+const (
+	ActivationTriggerRollback     = "rollback"
+	ActivationTriggerCandidate    = "candidate_select"
+	ActivationTriggerConversation = "conversation"
+	ActivationTriggerDraft        = "draft_materialize"
+)
+
+// ErrActivationRejected 租户校验 Webhook 判定构件版本不通过
+var ErrActivationRejected = errors.New("artifact activation rejected by validation webhook")
+
+// ErrActivationValidationUnavailable 校验 Webhook 调用失败且未配置 fail_open
+var ErrActivationValidationUnavailable = errors.New("artifact validation webhook unavailable")
+
+// ActivationValidationRequest 发给校验 Webhook 的请求体
+type ActivationValidationRequest struct {
+	TenantID  string `json:"tenant_id"`
+	ProjectID string `json:"project_id"`
+	// ArtifactID 首次生成时构件尚未创建，可能为空
+	ArtifactID   string              `json:"artifact_id,omitempty"`
+	ArtifactType entity.ArtifactType `json:"artifact_type"`
+	// VersionID 待激活的已有版本（回滚、采用候选）；生成后直接激活的新版本为空
+	VersionID string `json:"version_id,omitempty"`
+	VersionNo int    `json:"version_no,omitempty"`
+	Trigger   string `json:"trigger"`
+	// Content 候选内容（构件 JSON）
+	Content json.RawMessage `json:"content"`
+}
+
+// ActivationValidationMessage 校验消息
+type ActivationValidationMessage struct {
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// ActivationValidationResult 校验 Webhook 的响应体
+type ActivationValidationResult struct {
+	Passed   bool                          `json:"passed"`
+	Messages []ActivationValidationMessage `json:"messages,omitempty"`
+}
+
+// ActivationRejectedError 校验不通过详情（errors.Is 匹配 ErrActivationRejected）
+type ActivationRejectedError struct {
+	Messages []ActivationValidationMessage
+}
+
+func (e *ActivationRejectedError) Error() string {
+	if len(e.Messages) == 0 {
+		return ErrActivationRejected.Error()
+	}
+	parts := make([]string, 0, len(e.Messages))
+	for _, m := range e.Messages {
+		if m.Path != "" {
+			parts = append(parts, m.Path+": "+m.Message)
+		} else {
+			parts = append(parts, m.Message)
+		}
+	}
+	return ErrActivationRejected.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ActivationRejectedError) Is(target error) bool {
+	return target == ErrActivationRejected
+}
+
+// ActivationWebhookCaller 定义应用层对“校验 Webhook 调用”的最小依赖（port），由基础设施层提供 HTTP 实现。
+// 非 2xx 响应、超时或响应无法解析时返回 error
+type ActivationWebhookCaller interface {
+	Call(ctx context.Context, hook *entity.ArtifactValidationWebhook, req *ActivationValidationRequest, timeout time.Duration) (*ActivationValidationResult, error)
+}
+
+// ActivationValidator 构件版本激活前的租户自定义校验（命名规范、违禁内容、内部设定核对等）
+type ActivationValidator struct {
+	tenantRepo repository.TenantRepository
+	caller     ActivationWebhookCaller
+}
+
+// NewActivationValidator 创建激活校验器（caller 为 nil 时不校验）
+func NewActivationValidator(tenantRepo repository.TenantRepository, caller ActivationWebhookCaller) *ActivationValidator {
+	return &ActivationValidator{tenantRepo: tenantRepo, caller: caller}
+}
+
+// Check 按租户配置调用校验 Webhook：未配置或构件类型不在范围内时放行；
+// 不通过返回 *ActivationRejectedError，调用失败返回 ErrActivationValidationUnavailable（fail_open 时放行）
+func (v *ActivationValidator) Check(ctx context.Context, req *ActivationValidationRequest) error {
+	if v == nil || v.caller == nil || req == nil {
+		return nil
+	}
+	tenant, err := v.tenantRepo.GetByID(ctx, req.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant: %w", err)
+	}
+	hook := tenant.ArtifactValidation()
+	if !hook.AppliesTo(req.ArtifactType) {
+		return nil
+	}
+
+	res, err := v.caller.Call(ctx, hook, req, ActivationWebhookTimeout(hook))
+	if err != nil {
+		if hook.FailOpen {
+			logger.Warn(ctx, "artifact validation webhook failed, allowing activation",
+				"error", err.Error(),
+				"artifact_id", req.ArtifactID,
+			)
+			return nil
+		}
+		return fmt.Errorf("%w: %v", ErrActivationValidationUnavailable, err)
+	}
+	if !res.Passed {
+		return &ActivationRejectedError{Messages: res.Messages}
+	}
+	return nil
+}
+
+// ActivationWebhookTimeout 单次调用超时（未配置取默认值，不超过上限）
+func ActivationWebhookTimeout(hook *entity.ArtifactValidationWebhook) time.Duration {
+	if hook == nil || hook.TimeoutMs <= 0 {
+		return DefaultActivationWebhookTimeout
+	}
+	return min(time.Duration(hook.TimeoutMs)*time.Millisecond, MaxActivationWebhookTimeout)
+}
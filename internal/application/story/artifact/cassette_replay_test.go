@@ -0,0 +1,113 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// artifactCassetteDir 构件生成图的录制夹具；修改 Prompt 模板或图结构后以 LLM_CASSETTE_MODE=record 重新录制
+const artifactCassetteDir = "testdata/llm_cassettes"
+
+// scriptedArtifactModel 录制时使用的脚本化提供商：按调用顺序返回预置响应。
+// 提供商不支持 response_format，每轮先报错再由图降级为纯 Prompt 调用；
+// 首轮查询项目简介，随后输出缺少标题的 JSON（触发修复），修复后输出合法构件
+type scriptedArtifactModel struct {
+	steps []func() (*schema.Message, error)
+	calls int
+}
+
+func newScriptedArtifactModel() *scriptedArtifactModel {
+	unsupported := func() (*schema.Message, error) {
+		return nil, errors.New("invalid request: response_format json_schema is not supported by this model")
+	}
+	reply := func(content string, toolCalls []schema.ToolCall, prompt, completion int) func() (*schema.Message, error) {
+		return func() (*schema.Message, error) {
+			msg := schema.AssistantMessage(content, toolCalls)
+			msg.ResponseMeta = &schema.ResponseMeta{
+				FinishReason: "stop",
+				Usage:        &schema.TokenUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion},
+			}
+			return msg, nil
+		}
+	}
+	return &scriptedArtifactModel{steps: []func() (*schema.Message, error){
+		unsupported,
+		reply("", []schema.ToolCall{{ID: "call_brief", Type: "function", Function: schema.FunctionCall{Name: "project_get_brief", Arguments: "{}"}}}, 320, 12),
+		unsupported,
+		reply(`{"title":"","description":"港城雾季，失踪的灯塔看守人留下一本航海日志。"}`, nil, 380, 40),
+		unsupported,
+		reply(`{"title":"雾港纪事","description":"港城雾季，失踪的灯塔看守人留下一本航海日志。","genre":"悬疑"}`, nil, 460, 52),
+	}}
+}
+
+func (m *scriptedArtifactModel) Generate(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if m.calls >= len(m.steps) {
+		return nil, errors.New("unexpected model call")
+	}
+	step := m.steps[m.calls]
+	m.calls++
+	return step()
+}
+
+func (m *scriptedArtifactModel) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("stream not scripted")
+}
+
+func (m *scriptedArtifactModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+type cassetteFactory struct {
+	model model.BaseChatModel
+}
+
+func (f cassetteFactory) Get(context.Context, string) (model.BaseChatModel, error) {
+	return f.model, nil
+}
+
+func TestArtifactGraphReplaysToolAndRepairPath(t *testing.T) {
+	cassette := config.LLMCassetteConfig{Mode: config.CassetteModeReplay, Dir: artifactCassetteDir}
+	var inner model.BaseChatModel
+	if os.Getenv("LLM_CASSETTE_MODE") == config.CassetteModeRecord {
+		cassette.Mode = config.CassetteModeRecord
+		inner = newScriptedArtifactModel()
+	}
+	chatModel := llm.NewCassetteChatModel(inner, "scripted", config.ProviderConfig{Model: "scripted-1", MaxTokens: 4096}, cassette)
+	gen := NewArtifactGenerator(cassetteFactory{model: chatModel}, nil, nil)
+
+	out, err := gen.Generate(context.Background(), &wfmodel.ArtifactGenerateInput{
+		TenantID:           "tenant-1",
+		ProjectID:          "project-1",
+		ProjectTitle:       "雾港",
+		ProjectDescription: "港城悬疑故事",
+		Type:               entity.ArtifactTypeNovelFoundation,
+		Prompt:             "根据项目简介生成小说基础设定",
+		Provider:           "scripted",
+	})
+	if err != nil {
+		t.Fatalf("replay failed (re-record with LLM_CASSETTE_MODE=record after changing prompts): %v", err)
+	}
+
+	if want := "init>model>tools>model>validate>repair>model>validate>finalize"; out.GraphPath != want {
+		t.Fatalf("graph path = %s, want %s", out.GraphPath, want)
+	}
+	if out.RepairRounds != 1 || out.FallbackUsed {
+		t.Fatalf("repair rounds = %d fallback = %v, want 1 repair without fallback", out.RepairRounds, out.FallbackUsed)
+	}
+	if want := `{"title":"雾港纪事","description":"港城雾季，失踪的灯塔看守人留下一本航海日志。","genre":"悬疑"}`; string(out.Content) != want {
+		t.Fatalf("content = %s, want %s", out.Content, want)
+	}
+	if out.Meta.PromptTokens != 460 || out.Meta.CompletionTokens != 52 {
+		t.Fatalf("usage = %d/%d, want usage of the final call", out.Meta.PromptTokens, out.Meta.CompletionTokens)
+	}
+}
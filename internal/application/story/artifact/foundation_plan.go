@@ -0,0 +1,79 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// FoundationPlanVersion 由构件转换出的 FoundationPlan 版本号
+const FoundationPlanVersion = 1
+
+// FoundationPlanArtifactTypes 可转换为 FoundationPlan 的构件类型
+var FoundationPlanArtifactTypes = []entity.ArtifactType{
+	entity.ArtifactTypeWorldview,
+	entity.ArtifactTypeCharacters,
+	entity.ArtifactTypeOutline,
+}
+
+// IsFoundationPlanArtifactType 判断构件类型能否转换为 FoundationPlan
+func IsFoundationPlanArtifactType(t entity.ArtifactType) bool {
+	for _, v := range FoundationPlanArtifactTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildFoundationPlan 将 worldview/characters/outline 构件内容（任意子集）转换为增量 FoundationPlan：
+// 未提供的构件对应部分留空，Apply 时不会改动项目中的已有数据
+func BuildFoundationPlan(contents map[entity.ArtifactType]json.RawMessage) (*storymodel.FoundationPlan, error) {
+	plan := &storymodel.FoundationPlan{Version: FoundationPlanVersion}
+
+	if raw, ok := contents[entity.ArtifactTypeWorldview]; ok {
+		var a WorldviewArtifact
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("failed to parse worldview json: %w", err)
+		}
+		if err := ValidateWorldviewArtifact(&a); err != nil {
+			return nil, err
+		}
+		plan.Project = storymodel.ProjectPlan{
+			Genre:           a.Genre,
+			TargetWordCount: a.TargetWordCount,
+			WritingStyle:    a.WritingStyle,
+			POV:             a.POV,
+			Temperature:     a.Temperature,
+			WorldSettings:   a.WorldSettings,
+			WorldBible:      a.WorldBible,
+		}
+	}
+
+	if raw, ok := contents[entity.ArtifactTypeCharacters]; ok {
+		var a CharactersArtifact
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("failed to parse characters json: %w", err)
+		}
+		if err := ValidateCharactersArtifact(&a); err != nil {
+			return nil, err
+		}
+		plan.Entities = a.Entities
+		plan.Relations = a.Relations
+	}
+
+	if raw, ok := contents[entity.ArtifactTypeOutline]; ok {
+		var a OutlineArtifact
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("failed to parse outline json: %w", err)
+		}
+		if err := ValidateOutlineArtifact(&a); err != nil {
+			return nil, err
+		}
+		plan.Volumes = a.Volumes
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,37 @@
+package artifact
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+func TestBuildFoundationPlan(t *testing.T) {
+	plan, err := BuildFoundationPlan(map[entity.ArtifactType]json.RawMessage{
+		entity.ArtifactTypeCharacters: json.RawMessage(`{"entities":[{"key":"hero","name":"林远","type":"character"},{"key":"mentor","name":"玄清","type":"character"}],"relations":[{"source_key":"hero","target_key":"mentor","relation_type":"mentor"}]}`),
+		entity.ArtifactTypeOutline:    json.RawMessage(`{"volumes":[{"key":"v1","title":"第一卷","chapters":[{"key":"c1","title":"觉醒","outline":"少年觉醒"}]}]}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Version != FoundationPlanVersion {
+		t.Fatalf("unexpected version %d", plan.Version)
+	}
+	if len(plan.Entities) != 2 || len(plan.Relations) != 1 || len(plan.Volumes) != 1 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	// 未提供 worldview 时项目部分留空，不覆盖已有设定
+	if plan.Project.Genre != "" || plan.Project.WorldBible != "" {
+		t.Fatalf("project plan should be empty, got %+v", plan.Project)
+	}
+
+	_, err = BuildFoundationPlan(map[entity.ArtifactType]json.RawMessage{
+		entity.ArtifactTypeOutline: json.RawMessage(`{"volumes":[{"key":"","title":"第一卷","chapters":[]}]}`),
+	})
+	var ve ArtifactValidationError
+	if !errors.As(err, &ve) || ve.Type != entity.ArtifactTypeOutline {
+		t.Fatalf("expected outline validation error, got %v", err)
+	}
+}
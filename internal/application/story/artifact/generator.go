@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"z-novel-ai-api/internal/application/featureflag"
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
 	wfmodel "z-novel-ai-api/internal/workflow/model"
 	workflowpipeline "z-novel-ai-api/internal/workflow/pipeline"
@@ -14,12 +15,20 @@ type ArtifactGenerator struct {
 	pipeline *workflowpipeline.ArtifactPipeline
 }
 
-func NewArtifactGenerator(factory wfport.ChatModelFactory, retrievalEngine *appretrieval.Engine) *ArtifactGenerator {
+func NewArtifactGenerator(factory wfport.ChatModelFactory, retrievalEngine *appretrieval.Engine, flags featureflag.Client) *ArtifactGenerator {
 	return &ArtifactGenerator{
-		pipeline: workflowpipeline.NewArtifactPipeline(factory, retrievalEngine, artifactValidator{}, artifactJSONPatcher{}),
+		pipeline: workflowpipeline.NewArtifactPipeline(factory, retrievalEngine, artifactValidator{}, artifactJSONPatcher{flags: flags}),
 	}
 }
 
+// Warmup 预热构件生成工作流（编译生成图与工具节点）
+func (g *ArtifactGenerator) Warmup(ctx context.Context) error {
+	if g == nil || g.pipeline == nil {
+		return fmt.Errorf("artifact workflow not configured")
+	}
+	return g.pipeline.Warmup(ctx)
+}
+
 func (g *ArtifactGenerator) Generate(ctx context.Context, in *wfmodel.ArtifactGenerateInput) (*wfmodel.ArtifactGenerateOutput, error) {
 	if g == nil || g.pipeline == nil {
 		return nil, fmt.Errorf("artifact workflow not configured")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"z-novel-ai-api/internal/application/featureflag"
 	"z-novel-ai-api/internal/domain/entity"
 	wfmodel "z-novel-ai-api/internal/workflow/model"
 	wfnode "z-novel-ai-api/internal/workflow/node"
@@ -15,10 +16,19 @@ func (artifactValidator) NormalizeAndValidate(t entity.ArtifactType, rawJSON str
 	return normalizeAndValidateArtifact(t, rawJSON)
 }
 
-type artifactJSONPatcher struct{}
+// artifactJSONPatcher 增量模式在满足前提后再由功能开关按租户/项目灰度放量
+type artifactJSONPatcher struct {
+	flags featureflag.Client
+}
 
-func (artifactJSONPatcher) IsEnabled(in *wfmodel.ArtifactGenerateInput) bool {
-	return isArtifactJSONPatchEnabled(in)
+func (p artifactJSONPatcher) IsEnabled(ctx context.Context, in *wfmodel.ArtifactGenerateInput) bool {
+	if !isArtifactJSONPatchApplicable(in) {
+		return false
+	}
+	if p.flags == nil {
+		return featureflag.Default(entity.FeatureFlagArtifactJSONPatch)
+	}
+	return p.flags.Enabled(ctx, entity.FeatureFlagArtifactJSONPatch, in.TenantID, in.ProjectID)
 }
 
 func (artifactJSONPatcher) AllowedOps() []string {
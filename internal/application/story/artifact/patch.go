@@ -12,7 +12,8 @@ import (
 	wfmodel "z-novel-ai-api/internal/workflow/model"
 )
 
-func isArtifactJSONPatchEnabled(in *wfmodel.ArtifactGenerateInput) bool {
+// isArtifactJSONPatchApplicable 增量模式的前提：已知构件类型且存在可打补丁的当前版本
+func isArtifactJSONPatchApplicable(in *wfmodel.ArtifactGenerateInput) bool {
 	if in == nil {
 		return false
 	}
@@ -0,0 +1,224 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// MaxCandidatesPerRequest 请求可声明的候选数上限（实际再受 story.best_of_n.max_candidates 约束）
+const MaxCandidatesPerRequest = 8
+
+// CandidateCount 按配置上限与 Token 预算折算实际候选数（至少 1）：
+// 先以 maxCandidates 封顶，再在 maxTotalTokens > 0 时保证 n * perCandidateTokens 不超过预算。
+func CandidateCount(requested, maxCandidates int, maxTotalTokens, perCandidateTokens int64) int {
+	n := requested
+	if n > maxCandidates {
+		n = maxCandidates
+	}
+	if maxTotalTokens > 0 && perCandidateTokens > 0 {
+		if budget := int(maxTotalTokens / perCandidateTokens); n > budget {
+			n = budget
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// NormalizeCandidateSelection 解析选择方式，空值默认 auto
+func NormalizeCandidateSelection(s string) (entity.CandidateSelection, error) {
+	sel := entity.CandidateSelection(strings.ToLower(strings.TrimSpace(s)))
+	if sel == "" {
+		return entity.CandidateSelectionAuto, nil
+	}
+	if !sel.IsValid() {
+		return "", fmt.Errorf("invalid selection: %s (expected auto or manual)", s)
+	}
+	return sel, nil
+}
+
+// RunCandidates 并行生成 n 个候选，返回成功的结果（按候选顺序）与失败的个数。
+// 全部失败时返回第一个候选的错误；部分失败仅计数，由调用方记录任务警告。
+func RunCandidates[T any](ctx context.Context, n int, gen func(ctx context.Context, i int) (T, error)) ([]T, int, error) {
+	if n < 1 {
+		n = 1
+	}
+	outs := make([]T, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outs[i], errs[i] = gen(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+
+	results := make([]T, 0, n)
+	failed := 0
+	for i := range outs {
+		if errs[i] != nil {
+			failed++
+			continue
+		}
+		results = append(results, outs[i])
+	}
+	if len(results) == 0 {
+		return nil, failed, errs[0]
+	}
+	return results, failed, nil
+}
+
+// CandidatesFailedWarning 部分候选生成失败（仅在成功的候选中择优）
+func CandidatesFailedWarning(failed, total int) entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningCandidateFailed,
+		fmt.Sprintf("%d of %d candidates failed to generate; the result was chosen from the remaining candidates", failed, total))
+}
+
+// BestCandidate 返回评分最高的候选下标（同分取靠前者）
+func BestCandidate(scores []float64) int {
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// 章节质量评分各项权重（合计 100）
+const (
+	chapterScoreLength     = 40.0 // 字数贴近目标
+	chapterScoreParagraphs = 20.0 // 分段合理
+	chapterScoreRepetition = 25.0 // 无重复段落/句子
+	chapterScoreEnding     = 15.0 // 结尾完整（未被截断）
+
+	// chapterRunesPerParagraph 期望的平均段落长度（字）
+	chapterRunesPerParagraph = 400
+)
+
+// ScoreChapter 章节候选的启发式质量评分（0-100）：综合字数贴合度、分段、重复度与结尾完整性。
+// 仅用于同一请求内候选之间的相对排序，不作为绝对质量判断。
+func ScoreChapter(content string, targetWordCount int) float64 {
+	content = strings.TrimSpace(content)
+	runes := utf8.RuneCountInString(content)
+	if runes == 0 {
+		return 0
+	}
+	if targetWordCount <= 0 {
+		targetWordCount = runes
+	}
+
+	score := chapterScoreLength * (1 - math.Min(math.Abs(1-float64(runes)/float64(targetWordCount)), 1))
+
+	var paragraphs []string
+	for _, p := range strings.Split(content, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	expected := math.Max(1, float64(runes)/chapterRunesPerParagraph)
+	score += chapterScoreParagraphs * math.Min(float64(len(paragraphs))/expected, 1)
+
+	score += chapterScoreRepetition * (1 - math.Min(duplicateRatio(content)*2, 1))
+
+	last, _ := utf8.DecodeLastRuneInString(content)
+	if strings.ContainsRune("。！？…”」』.!?\"", last) {
+		score += chapterScoreEnding
+	}
+	return roundScore(score)
+}
+
+// duplicateRatio 重复句子占全部句子的比例（按句末标点切分，忽略过短的句子）
+func duplicateRatio(content string) float64 {
+	sentences := strings.FieldsFunc(content, func(r rune) bool {
+		return r == '\n' || strings.ContainsRune("。！？!?", r)
+	})
+	seen := make(map[string]struct{}, len(sentences))
+	total, dup := 0, 0
+	for _, s := range sentences {
+		s = strings.TrimSpace(s)
+		if utf8.RuneCountInString(s) < 8 {
+			continue
+		}
+		total++
+		if _, ok := seen[s]; ok {
+			dup++
+			continue
+		}
+		seen[s] = struct{}{}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(dup) / float64(total)
+}
+
+// 构件质量评分各项权重（合计 100）
+const (
+	artifactScoreCompleteness = 60.0 // 字段填写完整
+	artifactScoreRichness     = 40.0 // 内容充实度
+
+	// artifactRichRunes 文本总量达到该字数视为充实
+	artifactRichRunes = 2000
+)
+
+// ScoreArtifact 构件候选的启发式质量评分（0-100）：非空字段占比与文本充实度；非法 JSON 记 0 分。
+func ScoreArtifact(content json.RawMessage) float64 {
+	var v any
+	if err := json.Unmarshal(content, &v); err != nil {
+		return 0
+	}
+	var leaves, filled, textRunes int
+	walkJSONLeaves(v, func(leaf any) {
+		leaves++
+		switch x := leaf.(type) {
+		case string:
+			if s := strings.TrimFunc(x, unicode.IsSpace); s != "" {
+				filled++
+				textRunes += utf8.RuneCountInString(s)
+			}
+		case nil:
+		default:
+			filled++
+		}
+	})
+	if leaves == 0 {
+		return 0
+	}
+	score := artifactScoreCompleteness * float64(filled) / float64(leaves)
+	score += artifactScoreRichness * math.Min(float64(textRunes)/artifactRichRunes, 1)
+	return roundScore(score)
+}
+
+func walkJSONLeaves(v any, fn func(leaf any)) {
+	switch x := v.(type) {
+	case map[string]any:
+		for _, child := range x {
+			walkJSONLeaves(child, fn)
+		}
+	case []any:
+		if len(x) == 0 {
+			fn(nil)
+		}
+		for _, child := range x {
+			walkJSONLeaves(child, fn)
+		}
+	default:
+		fn(x)
+	}
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*10) / 10
+}
@@ -0,0 +1,304 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// canonWorldviewRunes / canonCharactersRunes 世界观与角色摘录注入 Prompt 的字数预算
+	canonWorldviewRunes  = 1500
+	canonCharactersRunes = 2000
+	// canonRunesPerEntity 单个角色条目的最大字数
+	canonRunesPerEntity = 240
+	// canonMaxLocations 世界观摘录中列出的地点上限
+	canonMaxLocations = 20
+)
+
+// CanonExcerpts 当前激活的世界观/角色构件摘录（已按预算裁剪，可能为空）
+type CanonExcerpts struct {
+	Worldview  string
+	Characters string
+}
+
+// CanonContextService 设定摘录：读取项目生效的世界观/角色构件版本，按章节大纲筛选相关角色并裁剪到预算，
+// 作为章节生成 Prompt 的模板变量注入，使正文遵守作者维护的设定。
+// 项目加入系列且本书没有激活版本时，沿用系列前作的激活版本（与会话生成的设定继承一致，见 SeriesService.ResolveCanon）。
+//
+// 约定：ForChapter 需在已 SetTenant 的上下文中调用。
+type CanonContextService struct {
+	artifactRepo repository.ArtifactRepository
+	series       *storyseries.SeriesService
+}
+
+// NewCanonContextService 创建设定摘录服务（series 为 nil 时只读取本书的激活版本）
+func NewCanonContextService(artifactRepo repository.ArtifactRepository, series *storyseries.SeriesService) *CanonContextService {
+	return &CanonContextService{artifactRepo: artifactRepo, series: series}
+}
+
+// ForChapter 加载章节生成所需的设定摘录；加载或解析失败不阻断生成，仅记录日志并返回空摘录
+func (s *CanonContextService) ForChapter(ctx context.Context, project *entity.Project, chapterTitle, outline string) CanonExcerpts {
+	var out CanonExcerpts
+	if s == nil || s.artifactRepo == nil || project == nil || strings.TrimSpace(project.ID) == "" {
+		return out
+	}
+
+	var wv storyartifact.WorldviewArtifact
+	if s.loadCanon(ctx, project, entity.ArtifactTypeWorldview, &wv) {
+		out.Worldview = formatCanonWorldview(&wv)
+	}
+	var ch storyartifact.CharactersArtifact
+	if s.loadCanon(ctx, project, entity.ArtifactTypeCharacters, &ch) {
+		out.Characters = formatCanonCharacters(&ch, chapterTitle+"\n"+outline)
+	}
+	return out
+}
+
+// loadCanon 解析项目生效（可能继承自前作）的指定类型设定内容到 dst；不存在或失败时返回 false
+func (s *CanonContextService) loadCanon(ctx context.Context, project *entity.Project, t entity.ArtifactType, dst any) bool {
+	v, err := s.canonVersion(ctx, project, t)
+	if err != nil {
+		logger.Warn(ctx, "failed to load canon artifact version", "error", err.Error(), "project_id", project.ID, "type", string(t))
+		return false
+	}
+	if v == nil || len(v.Content) == 0 {
+		return false
+	}
+	if err := json.Unmarshal(v.Content, dst); err != nil {
+		logger.Warn(ctx, "failed to parse canon artifact version", "error", err.Error(), "artifact_id", v.ArtifactID, "type", string(t))
+		return false
+	}
+	return true
+}
+
+// canonVersion 返回生效的设定版本：配置了系列服务时按系列继承解析，否则只取本书的激活版本
+func (s *CanonContextService) canonVersion(ctx context.Context, project *entity.Project, t entity.ArtifactType) (*entity.ArtifactVersion, error) {
+	if s.series != nil {
+		canon, err := s.series.ResolveCanon(ctx, project, t)
+		if err != nil || canon == nil {
+			return nil, err
+		}
+		return canon.Version, nil
+	}
+
+	artifacts, err := s.artifactRepo.ListArtifactsByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range artifacts {
+		if a == nil || a.Type != t || a.ActiveVersionID == nil || strings.TrimSpace(*a.ActiveVersionID) == "" {
+			continue
+		}
+		v, err := s.artifactRepo.GetVersionByID(ctx, *a.ActiveVersionID)
+		if err != nil || v == nil || v.ArtifactID != a.ID {
+			return nil, err
+		}
+		return v, nil
+	}
+	return nil, nil
+}
+
+func formatCanonWorldview(wv *storyartifact.WorldviewArtifact) string {
+	lines := make([]string, 0, 5)
+	if v := compactCanonText(wv.Genre); v != "" {
+		lines = append(lines, "题材："+v)
+	}
+	if v := compactCanonText(wv.WorldSettings.TimeSystem); v != "" {
+		lines = append(lines, "时间体系："+v)
+	}
+	if v := compactCanonText(wv.WorldSettings.Calendar); v != "" {
+		lines = append(lines, "历法："+v)
+	}
+	if locs := compactCanonList(wv.WorldSettings.Locations, canonMaxLocations); locs != "" {
+		lines = append(lines, "主要地点："+locs)
+	}
+	// 世界观正文最长且最不结构化，放在最后，超出预算时优先截断
+	if v := strings.TrimSpace(wv.WorldBible); v != "" {
+		lines = append(lines, "世界观设定：\n"+v)
+	}
+	return truncateRunes(strings.Join(lines, "\n"), canonWorldviewRunes)
+}
+
+// formatCanonCharacters 渲染角色摘录：大纲/标题中提及的角色优先，其次主角与主要角色；
+// 仅保留已选角色之间的关系，整体裁剪到预算（按条目整行截断，不输出半条）。
+func formatCanonCharacters(ch *storyartifact.CharactersArtifact, mentionText string) string {
+	type candidate struct {
+		plan      *storymodel.EntityPlan
+		mentioned bool
+		order     int
+	}
+
+	candidates := make([]candidate, 0, len(ch.Entities))
+	for i := range ch.Entities {
+		e := &ch.Entities[i]
+		if strings.TrimSpace(e.Name) == "" {
+			continue
+		}
+		mentioned := mentionsEntity(mentionText, e)
+		if !mentioned && !isCoreImportance(e.Importance) {
+			continue
+		}
+		candidates = append(candidates, candidate{plan: e, mentioned: mentioned, order: i})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].mentioned != candidates[j].mentioned {
+			return candidates[i].mentioned
+		}
+		return importanceRank(candidates[i].plan.Importance) < importanceRank(candidates[j].plan.Importance)
+	})
+
+	var b strings.Builder
+	remaining := canonCharactersRunes
+	selected := make(map[string]string, len(candidates))
+	for _, c := range candidates {
+		line := formatCanonEntity(c.plan)
+		n := len([]rune(line)) + 1
+		if n > remaining {
+			break
+		}
+		remaining -= n
+		b.WriteString(line)
+		b.WriteByte('\n')
+		if key := strings.TrimSpace(c.plan.Key); key != "" {
+			selected[key] = strings.TrimSpace(c.plan.Name)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+
+	relHeader := true
+	for _, r := range ch.Relations {
+		src, okSrc := selected[strings.TrimSpace(r.SourceKey)]
+		dst, okDst := selected[strings.TrimSpace(r.TargetKey)]
+		if !okSrc || !okDst {
+			continue
+		}
+		line := fmt.Sprintf("- %s → %s：%s", src, dst, r.RelationType)
+		if desc := compactCanonText(r.Description); desc != "" {
+			line += "（" + desc + "）"
+		}
+		line = truncateRunes(line, canonRunesPerEntity)
+		n := len([]rune(line)) + 1
+		if relHeader {
+			n += len([]rune("人物关系：")) + 1
+		}
+		if n > remaining {
+			break
+		}
+		remaining -= n
+		if relHeader {
+			b.WriteString("人物关系：\n")
+			relHeader = false
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func formatCanonEntity(e *storymodel.EntityPlan) string {
+	head := "- " + strings.TrimSpace(e.Name)
+	meta := make([]string, 0, 2)
+	if e.Importance != "" {
+		meta = append(meta, string(e.Importance))
+	}
+	if aliases := compactCanonList(e.Aliases, 5); aliases != "" {
+		meta = append(meta, "别名："+aliases)
+	}
+	if len(meta) > 0 {
+		head += "（" + strings.Join(meta, "；") + "）"
+	}
+
+	parts := make([]string, 0, 5)
+	if v := compactCanonText(e.Description); v != "" {
+		parts = append(parts, v)
+	}
+	if e.Attributes != nil {
+		if v := compactCanonText(e.Attributes.Personality); v != "" {
+			parts = append(parts, "性格："+v)
+		}
+		if v := compactCanonList(e.Attributes.Abilities, 5); v != "" {
+			parts = append(parts, "能力："+v)
+		}
+	}
+	if v := compactCanonText(e.CurrentState); v != "" {
+		parts = append(parts, "当前状态："+v)
+	}
+	if len(parts) == 0 {
+		return truncateRunes(head, canonRunesPerEntity)
+	}
+	return truncateRunes(head+"："+strings.Join(parts, "；"), canonRunesPerEntity)
+}
+
+// mentionsEntity 大纲或标题是否提及该角色（名称或别名，至少 2 个字以避免单字误命中）
+func mentionsEntity(text string, e *storymodel.EntityPlan) bool {
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+	names := append([]string{e.Name}, e.Aliases...)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len([]rune(name)) < 2 {
+			continue
+		}
+		if strings.Contains(text, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCoreImportance(imp entity.EntityImportance) bool {
+	return imp == entity.ImportanceProtagonist || imp == entity.ImportanceMajor
+}
+
+func importanceRank(imp entity.EntityImportance) int {
+	switch imp {
+	case entity.ImportanceProtagonist:
+		return 0
+	case entity.ImportanceMajor:
+		return 1
+	case entity.ImportanceSecondary:
+		return 2
+	case entity.ImportanceMinor:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func compactCanonText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func compactCanonList(items []string, limit int) string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = compactCanonText(item); item != "" {
+			out = append(out, item)
+		}
+		if len(out) >= limit {
+			break
+		}
+	}
+	return strings.Join(out, "、")
+}
+
+func truncateRunes(s string, limit int) string {
+	r := []rune(s)
+	if len(r) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(r[:limit])) + "…"
+}
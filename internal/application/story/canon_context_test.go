@@ -0,0 +1,60 @@
+package story
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestCanonContextInheritsSeriesCanon(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	projects := memrepo.NewProjectRepository(store)
+	artifacts := memrepo.NewArtifactRepository(store)
+	seriesRepo := memrepo.NewSeriesRepository(store)
+
+	series := entity.NewSeries(testTenantID, "owner-1", "三部曲")
+	mustNoErr(t, seriesRepo.Create(ctx, series))
+	newBook := func(title string, order int) *entity.Project {
+		p := entity.NewProject(testTenantID, "owner-1", title)
+		p.SeriesID, p.SeriesOrder = &series.ID, order
+		mustNoErr(t, projects.Create(ctx, p))
+		return p
+	}
+	first, second := newBook("第一部", 1), newBook("第二部", 2)
+
+	activate := func(p *entity.Project, t2 entity.ArtifactType, content string) {
+		a, err := artifacts.EnsureArtifact(ctx, testTenantID, p.ID, t2)
+		mustNoErr(t, err)
+		v := &entity.ArtifactVersion{ID: p.ID + "-" + string(t2), ArtifactID: a.ID, VersionNo: 1, BranchKey: "main", Content: []byte(content)}
+		mustNoErr(t, artifacts.CreateVersion(ctx, v))
+		mustNoErr(t, artifacts.SetActiveVersion(ctx, a.ID, v.ID))
+	}
+	activate(first, entity.ArtifactTypeWorldview, `{"genre":"仙侠","world_bible":"九州灵脉枯竭。"}`)
+	activate(first, entity.ArtifactTypeCharacters, `{"entities":[{"key":"hero","name":"林默","importance":"protagonist"}]}`)
+
+	svc := NewCanonContextService(artifacts, storyseries.NewSeriesService(seriesRepo, projects, artifacts))
+
+	// 续作没有自己的设定：沿用前作的世界观与角色
+	got := svc.ForChapter(ctx, second, "第一章", "林默下山")
+	if !strings.Contains(got.Worldview, "九州灵脉枯竭") || !strings.Contains(got.Characters, "林默") {
+		t.Fatalf("series canon not inherited: %+v", got)
+	}
+
+	// 续作激活自己的世界观后覆盖前作，角色仍继承
+	activate(second, entity.ArtifactTypeWorldview, `{"genre":"仙侠","world_bible":"灵脉复苏百年之后。"}`)
+	got = svc.ForChapter(ctx, second, "第一章", "林默下山")
+	if !strings.Contains(got.Worldview, "灵脉复苏百年之后") || strings.Contains(got.Worldview, "九州灵脉枯竭") || !strings.Contains(got.Characters, "林默") {
+		t.Fatalf("own canon should override inherited: %+v", got)
+	}
+
+	// 未配置系列服务时只读本书
+	local := NewCanonContextService(artifacts, nil).ForChapter(ctx, second, "第一章", "林默下山")
+	if local.Characters != "" || !strings.Contains(local.Worldview, "灵脉复苏百年之后") {
+		t.Fatalf("local canon = %+v", local)
+	}
+}
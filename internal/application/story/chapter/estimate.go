@@ -0,0 +1,101 @@
+package chapter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"z-novel-ai-api/internal/application/story/storyutil"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+const (
+	// messageTokenOverhead 每条消息的角色/分隔符开销（OpenAI 兼容格式的经验值）
+	messageTokenOverhead = 4
+	// completionOvershootRatio 模型实际输出通常略超目标字数，按 10% 余量估算
+	completionOvershootRatio = 1.1
+)
+
+// ProviderPricing 单个提供商的计费参数（由接口层从配置映射而来）
+type ProviderPricing struct {
+	Provider    string
+	Model       string
+	MaxTokens   int
+	InputPer1K  float64
+	OutputPer1K float64
+	Currency    string
+}
+
+// CostEstimate 单个提供商的 Token 与成本预估
+type CostEstimate struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	// CompletionCapped 目标字数超过提供商 max_tokens，输出会被截断
+	CompletionCapped bool
+	Priced           bool
+	Currency         string
+	PromptCost       float64
+	CompletionCost   float64
+	TotalCost        float64
+}
+
+// EstimatePromptTokens 渲染与实际生成一致的 Prompt 并估算其 Token 数（不调用模型）。
+func (g *ChapterGenerator) EstimatePromptTokens(ctx context.Context, in *wfmodel.ChapterGenerateInput) (int, error) {
+	if g == nil || g.chain == nil {
+		return 0, fmt.Errorf("chapter workflow not configured")
+	}
+	msgs, err := g.chain.Messages(ctx, in)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+		total += messageTokenOverhead + storyutil.EstimateTokens(msg.Content)
+	}
+	return total, nil
+}
+
+// EstimateCompletionTokens 按目标字数估算输出 Token 数（中文正文约 1 字 1 Token）。
+func EstimateCompletionTokens(targetWordCount int) int {
+	if targetWordCount <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(targetWordCount) * completionOvershootRatio))
+}
+
+// EstimateCosts 按提供商计费参数计算成本；输出 Token 受各提供商 max_tokens 限制。
+// 各提供商共用同一 Token 估算（不区分分词器），成本差异仅来自单价与 max_tokens 截断。
+func EstimateCosts(promptTokens, completionTokens int, pricing []ProviderPricing) []CostEstimate {
+	out := make([]CostEstimate, 0, len(pricing))
+	for _, p := range pricing {
+		est := CostEstimate{
+			Provider:         p.Provider,
+			Model:            p.Model,
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			Currency:         strings.TrimSpace(p.Currency),
+		}
+		if p.MaxTokens > 0 && est.CompletionTokens > p.MaxTokens {
+			est.CompletionTokens = p.MaxTokens
+			est.CompletionCapped = true
+		}
+		if p.InputPer1K > 0 || p.OutputPer1K > 0 {
+			est.Priced = true
+			est.PromptCost = roundCost(float64(est.PromptTokens) / 1000 * p.InputPer1K)
+			est.CompletionCost = roundCost(float64(est.CompletionTokens) / 1000 * p.OutputPer1K)
+			est.TotalCost = roundCost(est.PromptCost + est.CompletionCost)
+		}
+		out = append(out, est)
+	}
+	return out
+}
+
+func roundCost(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -2,9 +2,12 @@ package chapter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cloudwego/eino/schema"
 
@@ -23,6 +26,14 @@ func NewChapterGenerator(factory workflowport.ChatModelFactory) *ChapterGenerato
 	}
 }
 
+// Warmup 预热章节生成工作流（提示词模板）
+func (g *ChapterGenerator) Warmup(ctx context.Context) error {
+	if g == nil || g.chain == nil {
+		return fmt.Errorf("chapter workflow not configured")
+	}
+	return g.chain.Warmup(ctx)
+}
+
 func (g *ChapterGenerator) Generate(ctx context.Context, in *wfmodel.ChapterGenerateInput) (*wfmodel.ChapterGenerateOutput, error) {
 	if g == nil || g.chain == nil {
 		return nil, fmt.Errorf("chapter workflow not configured")
@@ -74,3 +85,63 @@ func (g *ChapterGenerator) Stream(ctx context.Context, in *wfmodel.ChapterGenera
 	}
 	return g.chain.Stream(ctx, in)
 }
+
+// GenerateStreaming 以流式方式调用模型并聚合为完整输出；每收到一段正文回调 onChunk（参数为本段正文与累计已生成字数）。
+// 适用于需要按生成量上报进度、但最终只关心完整结果的场景（如 Worker 异步任务）。
+func (g *ChapterGenerator) GenerateStreaming(ctx context.Context, in *wfmodel.ChapterGenerateInput, onChunk func(chunk string, generated int)) (*wfmodel.ChapterGenerateOutput, error) {
+	reader, err := g.Stream(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	meta := wfmodel.LLMUsageMeta{
+		Provider: strings.TrimSpace(in.Provider),
+		Model:    strings.TrimSpace(in.Model),
+	}
+	if in.Temperature != nil {
+		meta.Temperature = float64(*in.Temperature)
+	}
+
+	var raw strings.Builder
+	generated := 0
+	for {
+		msg, recvErr := reader.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		if recvErr != nil {
+			return nil, recvErr
+		}
+		// 任务已取消或超时：即使读取端仍有已缓冲的分片也立即中止
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			continue
+		}
+
+		if msg.Content != "" {
+			raw.WriteString(msg.Content)
+			generated += utf8.RuneCountInString(msg.Content)
+			if onChunk != nil {
+				onChunk(msg.Content, generated)
+			}
+		}
+		if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+			meta.PromptTokens = msg.ResponseMeta.Usage.PromptTokens
+			meta.CompletionTokens = msg.ResponseMeta.Usage.CompletionTokens
+		}
+	}
+	meta.GeneratedAt = time.Now().UTC()
+
+	content := strings.TrimSpace(raw.String())
+	if content == "" {
+		return nil, fmt.Errorf("empty chapter content")
+	}
+
+	return &wfmodel.ChapterGenerateOutput{
+		Content: content,
+		Meta:    meta,
+	}, nil
+}
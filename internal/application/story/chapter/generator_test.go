@@ -0,0 +1,88 @@
+package chapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// newMockGenerator 以离线 mock 提供商组装章节生成器（走真实的 EinoFactory 与章节链）
+func newMockGenerator(maxTokens int) *ChapterGenerator {
+	cfg := &config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "mock",
+		Providers: map[string]config.ProviderConfig{
+			"mock": {Type: config.ProviderTypeMock, Model: "mock-v1", MaxTokens: maxTokens},
+		},
+	}}
+	return NewChapterGenerator(llm.NewEinoFactory(cfg))
+}
+
+func mockChapterInput(target int) *wfmodel.ChapterGenerateInput {
+	return &wfmodel.ChapterGenerateInput{
+		ProjectTitle:    "雾港纪事",
+		ChapterTitle:    "灯塔",
+		ChapterOutline:  "林默在雾夜登上废弃灯塔，发现看守人留下的航海日志。",
+		TargetWordCount: target,
+		Provider:        "mock",
+		Model:           "mock-v1",
+	}
+}
+
+func TestChapterGeneratorWithMockProvider(t *testing.T) {
+	ctx := context.Background()
+	g := newMockGenerator(8192)
+
+	out, err := g.Generate(ctx, mockChapterInput(800))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if n := utf8.RuneCountInString(out.Content); n < 780 || n > 800 {
+		t.Fatalf("content length = %d, want about the 800-rune target", n)
+	}
+	if !strings.HasPrefix(out.Content, "林默在雾夜登上废弃灯塔") {
+		t.Fatalf("content should open with the outline, got %q", string([]rune(out.Content)[:20]))
+	}
+	if out.Meta.Provider != "mock" || out.Meta.PromptTokens <= 0 || out.Meta.CompletionTokens != 800 {
+		t.Fatalf("usage meta = %+v", out.Meta)
+	}
+
+	// 同一输入输出确定
+	again, err := g.Generate(ctx, mockChapterInput(800))
+	if err != nil {
+		t.Fatalf("generate again: %v", err)
+	}
+	if again.Content != out.Content {
+		t.Fatalf("mock output is not deterministic")
+	}
+}
+
+func TestChapterGeneratorStreamingWithMockProvider(t *testing.T) {
+	// max_tokens 低于目标字数时输出被截断
+	g := newMockGenerator(300)
+
+	chunks, last := 0, 0
+	out, err := g.GenerateStreaming(context.Background(), mockChapterInput(2000), func(chunk string, generated int) {
+		chunks++
+		if generated <= last {
+			t.Errorf("generated count not increasing: %d after %d", generated, last)
+		}
+		last = generated
+	})
+	if err != nil {
+		t.Fatalf("generate streaming: %v", err)
+	}
+	if chunks < 2 {
+		t.Fatalf("chunks = %d, want simulated streaming", chunks)
+	}
+	if last != 300 || out.Meta.CompletionTokens != 300 {
+		t.Fatalf("generated = %d completion_tokens = %d, want capped at 300", last, out.Meta.CompletionTokens)
+	}
+	if out.Meta.PromptTokens <= 0 {
+		t.Fatalf("prompt usage not reported: %+v", out.Meta)
+	}
+}
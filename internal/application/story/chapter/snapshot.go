@@ -0,0 +1,134 @@
+package chapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+
+	workflowchain "z-novel-ai-api/internal/workflow/chain"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
+)
+
+// InputSnapshot 章节生成输入快照：任务执行时记录实际送入模型的全部输入（模板版本、召回上下文、参数），
+// 用于回放沙箱复现与对比。
+type InputSnapshot struct {
+	PromptID     string `json:"prompt_id"`
+	PromptDigest string `json:"prompt_digest"`
+	// PromptSystem / PromptUser 仅在使用了模板覆盖时记录（内置模板可由 PromptID + PromptDigest 定位）
+	PromptSystem string `json:"prompt_system,omitempty"`
+	PromptUser   string `json:"prompt_user,omitempty"`
+
+	ProjectTitle       string `json:"project_title"`
+	ProjectDescription string `json:"project_description"`
+	ChapterTitle       string `json:"chapter_title"`
+	ChapterOutline     string `json:"chapter_outline"`
+	RetrievedContext   string `json:"retrieved_context"`
+	ActiveWorldview    string `json:"active_worldview"`
+	ActiveCharacters   string `json:"active_characters"`
+	TargetWordCount    int    `json:"target_word_count"`
+	WritingStyle       string `json:"writing_style"`
+	POV                string `json:"pov"`
+	// ContinueFrom 续写被中断的章节时已有的部分正文
+	ContinueFrom string `json:"continue_from,omitempty"`
+
+	Provider    string   `json:"provider"`
+	Model       string   `json:"model"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// NewInputSnapshot 由生成输入构建快照
+func NewInputSnapshot(in *wfmodel.ChapterGenerateInput) (*InputSnapshot, error) {
+	if in == nil {
+		return nil, fmt.Errorf("input is nil")
+	}
+	system, user, err := workflowchain.ChapterPromptText(in.PromptOverride)
+	if err != nil {
+		return nil, err
+	}
+	s := &InputSnapshot{
+		PromptID:           string(workflowprompt.PromptChapterGenV1),
+		PromptDigest:       workflowprompt.Digest(system, user),
+		ProjectTitle:       in.ProjectTitle,
+		ProjectDescription: in.ProjectDescription,
+		ChapterTitle:       in.ChapterTitle,
+		ChapterOutline:     in.ChapterOutline,
+		RetrievedContext:   in.RetrievedContext,
+		ActiveWorldview:    in.ActiveWorldview,
+		ActiveCharacters:   in.ActiveCharacters,
+		TargetWordCount:    in.TargetWordCount,
+		WritingStyle:       in.WritingStyle,
+		POV:                in.POV,
+		ContinueFrom:       in.ContinueFrom,
+		Provider:           in.Provider,
+		Model:              in.Model,
+		Temperature:        in.Temperature,
+		MaxTokens:          in.MaxTokens,
+	}
+	if in.PromptOverride != nil {
+		s.PromptSystem, s.PromptUser = system, user
+	}
+	return s, nil
+}
+
+// MarshalInputSnapshot 序列化生成输入快照；失败时返回 nil（快照仅用于调试，不阻断生成）
+func MarshalInputSnapshot(in *wfmodel.ChapterGenerateInput) json.RawMessage {
+	s, err := NewInputSnapshot(in)
+	if err != nil {
+		return nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// ParseInputSnapshot 解析任务记录的输入快照；未记录时返回 nil, nil
+func ParseInputSnapshot(raw json.RawMessage) (*InputSnapshot, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var s InputSnapshot
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse input snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// Input 还原为生成输入（快照记录了覆盖模板时一并还原）
+func (s *InputSnapshot) Input() *wfmodel.ChapterGenerateInput {
+	in := &wfmodel.ChapterGenerateInput{
+		ProjectTitle:       s.ProjectTitle,
+		ProjectDescription: s.ProjectDescription,
+		ChapterTitle:       s.ChapterTitle,
+		ChapterOutline:     s.ChapterOutline,
+		RetrievedContext:   s.RetrievedContext,
+		ActiveWorldview:    s.ActiveWorldview,
+		ActiveCharacters:   s.ActiveCharacters,
+		TargetWordCount:    s.TargetWordCount,
+		WritingStyle:       s.WritingStyle,
+		POV:                s.POV,
+		ContinueFrom:       s.ContinueFrom,
+		Provider:           s.Provider,
+		Model:              s.Model,
+		Temperature:        s.Temperature,
+		MaxTokens:          s.MaxTokens,
+	}
+	if strings.TrimSpace(s.PromptSystem) != "" || strings.TrimSpace(s.PromptUser) != "" {
+		in.PromptOverride = &wfmodel.PromptTemplateOverride{System: s.PromptSystem, User: s.PromptUser}
+	}
+	return in
+}
+
+// RenderPrompt 渲染与实际生成一致的 Prompt 消息（不调用模型）
+func (g *ChapterGenerator) RenderPrompt(ctx context.Context, in *wfmodel.ChapterGenerateInput) ([]*schema.Message, error) {
+	if g == nil || g.chain == nil {
+		return nil, fmt.Errorf("chapter workflow not configured")
+	}
+	return g.chain.Messages(ctx, in)
+}
@@ -0,0 +1,62 @@
+package chapter
+
+import (
+	"context"
+	"fmt"
+
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+const (
+	// DefaultTitleSuggestions 默认标题建议数量
+	DefaultTitleSuggestions = 5
+	// MinTitleSuggestions / MaxTitleSuggestions 标题建议数量范围
+	MinTitleSuggestions = 3
+	MaxTitleSuggestions = 5
+
+	// titleExcerptHeadRunes / titleExcerptTailRunes 标题建议只看正文开头与结尾，控制 Prompt 成本
+	titleExcerptHeadRunes = 2000
+	titleExcerptTailRunes = 800
+	// titleSuggestMaxTokens 标题建议输出上限（仅 JSON 短数组）
+	titleSuggestMaxTokens = 200
+)
+
+// titleSuggestTemperature 标题建议温度：略高以获得风格差异
+var titleSuggestTemperature float32 = 0.9
+
+// SuggestTitles 根据章节正文提出候选标题；正文按 TitleExcerpt 截取，数量约束到 [3, 5]
+func (g *ChapterGenerator) SuggestTitles(ctx context.Context, in *wfmodel.ChapterTitleSuggestInput) (*wfmodel.ChapterTitleSuggestOutput, error) {
+	if g == nil || g.chain == nil {
+		return nil, fmt.Errorf("chapter workflow not configured")
+	}
+	if in == nil {
+		return nil, fmt.Errorf("input is nil")
+	}
+	req := *in
+	req.ChapterContent = TitleExcerpt(in.ChapterContent)
+	switch {
+	case req.Count <= 0:
+		req.Count = DefaultTitleSuggestions
+	case req.Count < MinTitleSuggestions:
+		req.Count = MinTitleSuggestions
+	case req.Count > MaxTitleSuggestions:
+		req.Count = MaxTitleSuggestions
+	}
+	if req.Temperature == nil {
+		req.Temperature = &titleSuggestTemperature
+	}
+	if req.MaxTokens == nil {
+		maxTokens := titleSuggestMaxTokens
+		req.MaxTokens = &maxTokens
+	}
+	return g.chain.SuggestTitles(ctx, &req)
+}
+
+// TitleExcerpt 截取正文开头与结尾用于标题建议（中间以省略号连接）
+func TitleExcerpt(content string) string {
+	runes := []rune(content)
+	if len(runes) <= titleExcerptHeadRunes+titleExcerptTailRunes {
+		return content
+	}
+	return string(runes[:titleExcerptHeadRunes]) + "\n……\n" + string(runes[len(runes)-titleExcerptTailRunes:])
+}
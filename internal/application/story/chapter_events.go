@@ -0,0 +1,137 @@
+package story
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ChapterEventReplacer 章节事件替换：章节重新生成后用新抽取的事件替换该章节的旧事件。
+//
+// 旧事件不删除，而是标记为已替代（superseded_at）；与新事件摘要相同的旧事件记录 superseded_by 链接，
+// 便于审计同一事件在多次生成间的演变。新事件写入后按其参与者强化同章出场实体之间的关系，
+// 并重新计算章节索引的涉及实体（involved_entities）。
+//
+// 生成收尾（GenerationFinalizer）在章节正文被重新生成或切换候选时以空事件调用 Replace，
+// 此时旧事件全部被替代且 superseded_by 为空（新正文尚未抽取事件，无可链接的后继）。
+//
+// 约定：Replace 由调用方负责事务边界（需已 SetTenant）；IndexChapter 必须在事务提交之后调用。
+type ChapterEventReplacer struct {
+	eventRepo   repository.EventRepository
+	chapterRepo repository.ChapterRepository
+	indexer     *appretrieval.Indexer
+	relations   *RelationWeigher
+}
+
+// NewChapterEventReplacer 创建章节事件替换服务
+func NewChapterEventReplacer(
+	eventRepo repository.EventRepository,
+	chapterRepo repository.ChapterRepository,
+	indexer *appretrieval.Indexer,
+	relations *RelationWeigher,
+) *ChapterEventReplacer {
+	return &ChapterEventReplacer{
+		eventRepo:   eventRepo,
+		chapterRepo: chapterRepo,
+		indexer:     indexer,
+		relations:   relations,
+	}
+}
+
+// SupersededEvent 被替代的旧事件
+type SupersededEvent struct {
+	EventID      string
+	SupersededBy *string
+}
+
+// ChapterEventReplacement 章节事件替换结果
+type ChapterEventReplacement struct {
+	Created    []*entity.Event
+	Superseded []SupersededEvent
+	// Duplicates 本批次内因摘要重复被合并的事件数
+	Duplicates int
+	// Index 按新事件重新计算涉及实体后的章节索引快照，事务提交后交给 IndexChapter
+	Index *ChapterIndexSnapshot
+}
+
+// Replace 以 events 替换章节当前有效的事件：批次内按摘要去重（合并涉及实体与标签），写入新事件后替代旧事件，
+// 以 POV 角色与新事件参与者强化关系，并据此准备章节索引快照（涉及实体不再包含被替代事件的参与者）。
+func (r *ChapterEventReplacer) Replace(ctx context.Context, chapter *entity.Chapter, events []*entity.Event) (*ChapterEventReplacement, error) {
+	if r == nil || r.eventRepo == nil {
+		return nil, fmt.Errorf("chapter event replacer not configured")
+	}
+	if chapter == nil {
+		return nil, fmt.Errorf("chapter is nil")
+	}
+
+	previous, err := r.eventRepo.ListByChapter(ctx, chapter.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChapterEventReplacement{}
+	byKey := make(map[string]*entity.Event, len(events))
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		key := ev.DedupKey()
+		if key == "" {
+			continue
+		}
+		if kept, ok := byKey[key]; ok {
+			for _, id := range ev.InvolvedEntities {
+				kept.AddInvolvedEntity(id)
+			}
+			for _, tag := range ev.Tags {
+				kept.AddTag(tag)
+			}
+			result.Duplicates++
+			continue
+		}
+		ev.ProjectID = chapter.ProjectID
+		ev.ChapterID = chapter.ID
+		ev.SupersededAt = nil
+		ev.SupersededBy = nil
+		byKey[key] = ev
+		result.Created = append(result.Created, ev)
+	}
+
+	for _, ev := range result.Created {
+		if err := r.eventRepo.Create(ctx, ev); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for _, old := range previous {
+		var successor *string
+		if ev, ok := byKey[old.DedupKey()]; ok {
+			id := ev.ID
+			successor = &id
+		}
+		if err := r.eventRepo.Supersede(ctx, old.ID, successor, now); err != nil {
+			return nil, err
+		}
+		result.Superseded = append(result.Superseded, SupersededEvent{EventID: old.ID, SupersededBy: successor})
+	}
+
+	involved := involvedEntities(chapter, result.Created)
+	// 同章出场的实体之间的已有关系视为被强化，刷新其时效
+	r.relations.Reinforce(ctx, chapter, involved)
+	result.Index = newChapterIndexSnapshot(ctx, r.chapterRepo, chapter, involved)
+	return result, nil
+}
+
+// IndexChapter 以新的涉及实体重建章节索引（失败仅记录日志并返回错误，不影响已提交的事件替换）。
+// 向量能力未启用时返回 nil。
+func (r *ChapterEventReplacer) IndexChapter(ctx context.Context, tenantID string, snapshot *ChapterIndexSnapshot) error {
+	if r == nil {
+		return nil
+	}
+	return indexChapterSnapshot(ctx, r.indexer, tenantID, snapshot)
+}
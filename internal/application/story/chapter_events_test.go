@@ -0,0 +1,127 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+type constEmbedder struct{}
+
+func (constEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i := range texts {
+		out[i] = []float64{1, 0}
+	}
+	return out, nil
+}
+
+// capturingVectorRepo 记录写入的片段（按 segment_type 覆盖）
+type capturingVectorRepo struct {
+	inserted map[string][]*appretrieval.VectorStorySegment
+}
+
+func (r *capturingVectorRepo) EnsureStorySegmentsCollection(context.Context) error { return nil }
+func (r *capturingVectorRepo) SearchSegments(context.Context, *appretrieval.VectorSearchParams) ([]*appretrieval.VectorSearchResult, error) {
+	return nil, nil
+}
+func (r *capturingVectorRepo) DeleteSegmentsByDocAndType(_ context.Context, _, _, _, segmentType string) error {
+	delete(r.inserted, segmentType)
+	return nil
+}
+func (r *capturingVectorRepo) DeleteProjectSegments(context.Context, string, string) error {
+	return nil
+}
+func (r *capturingVectorRepo) InsertSegments(_ context.Context, _, _ string, segments []*appretrieval.VectorStorySegment) error {
+	for _, seg := range segments {
+		r.inserted[seg.SegmentType] = append(r.inserted[seg.SegmentType], seg)
+	}
+	return nil
+}
+
+// involvedEntitiesOf 解析片段文本头部的元数据中的 involved_entities
+func involvedEntitiesOf(t *testing.T, seg *appretrieval.VectorStorySegment) []string {
+	t.Helper()
+	line, _, _ := strings.Cut(seg.TextContent, "\n")
+	var meta struct {
+		InvolvedEntities []string `json:"involved_entities"`
+	}
+	if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &meta); err != nil {
+		t.Fatalf("segment meta not decodable: %q", line)
+	}
+	return meta.InvolvedEntities
+}
+
+func TestChapterEventReplacerReinforcesFromNewEvents(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("new events involve both related entities", func(t *testing.T) {
+		f := newFinalizerFixture(t)
+		replacer := NewChapterEventReplacer(f.events, f.chapters, nil, NewRelationWeigher(f.chapters, f.relations, 10))
+
+		ev := entity.NewEvent(f.project.ID, 100, "二人结盟")
+		ev.AddInvolvedEntity(f.relation.TargetEntityID)
+		result, err := replacer.Replace(ctx, f.chapter, []*entity.Event{ev})
+		mustNoErr(t, err)
+		if len(result.Created) != 1 || len(result.Superseded) != 1 || result.Superseded[0].EventID != f.event.ID {
+			t.Fatalf("unexpected replacement: %+v", result)
+		}
+		rel, _ := f.relations.GetByID(ctx, f.relation.ID)
+		if rel.LastReinforcedChapterID == nil || *rel.LastReinforcedChapterID != f.chapter.ID {
+			t.Fatalf("relation should be reinforced by the chapter's new events")
+		}
+	})
+
+	t.Run("old participant no longer appears", func(t *testing.T) {
+		f := newFinalizerFixture(t)
+		replacer := NewChapterEventReplacer(f.events, f.chapters, nil, NewRelationWeigher(f.chapters, f.relations, 10))
+
+		// 旧事件的参与者不在新事件中，不应据旧事件强化
+		_, err := replacer.Replace(ctx, f.chapter, []*entity.Event{entity.NewEvent(f.project.ID, 100, "独自下山")})
+		mustNoErr(t, err)
+		rel, _ := f.relations.GetByID(ctx, f.relation.ID)
+		if rel.LastReinforcedChapterID != nil {
+			t.Fatalf("relation should not be reinforced from superseded events")
+		}
+	})
+}
+
+func TestChapterEventReplacerReindexesInvolvedEntities(t *testing.T) {
+	ctx := context.Background()
+	f := newFinalizerFixture(t)
+	f.chapter.SetContent("林默与沈川在渡口相遇，决定同行。")
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+	vectors := &capturingVectorRepo{inserted: map[string][]*appretrieval.VectorStorySegment{}}
+	indexer := appretrieval.NewIndexer(constEmbedder{}, vectors, 0)
+	replacer := NewChapterEventReplacer(f.events, f.chapters, indexer, NewRelationWeigher(f.chapters, f.relations, 10))
+
+	newcomer := entity.NewStoryEntity(f.project.ID, "沈川", entity.EntityTypeCharacter, entity.ImportanceSecondary)
+	newcomer.ID = "entity-shenchuan"
+	ev := entity.NewEvent(f.project.ID, 100, "渡口相遇")
+	ev.AddInvolvedEntity(newcomer.ID)
+	result, err := replacer.Replace(ctx, f.chapter, []*entity.Event{ev})
+	mustNoErr(t, err)
+
+	// 旧事件的参与者（苏晴）已被替代，不应再出现在涉及实体中
+	want := f.relation.SourceEntityID + "," + newcomer.ID
+	if result.Index == nil || strings.Join(result.Index.InvolvedEntities, ",") != want {
+		t.Fatalf("snapshot involved entities = %v, want POV then new event participants", result.Index)
+	}
+	mustNoErr(t, replacer.IndexChapter(ctx, testTenantID, result.Index))
+	chunks := vectors.inserted["chapter"]
+	if len(chunks) == 0 {
+		t.Fatalf("chapter was not re-indexed")
+	}
+	for _, seg := range chunks {
+		if got := strings.Join(involvedEntitiesOf(t, seg), ","); got != want {
+			t.Fatalf("indexed involved entities = %s, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package story
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// ChapterTitleService 章节标题建议：为正文提出 3-5 个候选标题，写入 generation_metadata.title_suggestions 供一键采用。
+//
+// 约定：Suggest 调用模型，须在事务外执行；SaveSuggestions 由调用方负责事务边界（需已 SetTenant）。
+type ChapterTitleService struct {
+	generator   *storychapter.ChapterGenerator
+	chapterRepo repository.ChapterRepository
+	projectRepo repository.ProjectRepository
+	txMgr       repository.Transactor
+	tenantCtx   repository.TenantContextManager
+
+	// auto 章节生成后是否为占位标题自动提出建议
+	auto    bool
+	timeout time.Duration
+}
+
+// NewChapterTitleService 创建章节标题建议服务
+func NewChapterTitleService(
+	generator *storychapter.ChapterGenerator,
+	chapterRepo repository.ChapterRepository,
+	projectRepo repository.ProjectRepository,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	auto bool,
+	timeout time.Duration,
+) *ChapterTitleService {
+	return &ChapterTitleService{
+		generator:   generator,
+		chapterRepo: chapterRepo,
+		projectRepo: projectRepo,
+		txMgr:       txMgr,
+		tenantCtx:   tenantCtx,
+		auto:        auto,
+		timeout:     timeout,
+	}
+}
+
+// Suggest 根据章节正文提出候选标题（count <= 0 时取默认数量）
+func (s *ChapterTitleService) Suggest(ctx context.Context, project *entity.Project, chapter *entity.Chapter, provider, model string, count int) ([]string, error) {
+	if s == nil || s.generator == nil {
+		return nil, fmt.Errorf("title suggestion not configured")
+	}
+	if strings.TrimSpace(chapter.ContentText) == "" {
+		return nil, fmt.Errorf("chapter has no content")
+	}
+
+	in := &wfmodel.ChapterTitleSuggestInput{
+		SeqNum:         chapter.SeqNum,
+		CurrentTitle:   chapter.Title,
+		ChapterOutline: chapter.Outline,
+		ChapterContent: chapter.ContentText,
+		Count:          count,
+		Provider:       provider,
+		Model:          model,
+	}
+	if project != nil {
+		in.ProjectTitle = project.Title
+		in.ProjectGenre = project.Genre
+	}
+
+	genCtx, finish := WithGenerationTimeout(ctx, StageTitleSuggest, s.timeout)
+	out, err := s.generator.SuggestTitles(genCtx, in)
+	if err = finish(err); err != nil {
+		return nil, err
+	}
+	return out.Titles, nil
+}
+
+// SaveSuggestions 重新加载章节并仅写入标题建议（不覆盖并发的正文编辑）；章节不存在时返回 nil
+func (s *ChapterTitleService) SaveSuggestions(ctx context.Context, chapterID string, titles []string) (*entity.Chapter, error) {
+	chapter, err := s.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil || chapter == nil {
+		return nil, err
+	}
+	chapter.SetTitleSuggestions(titles)
+	if err := s.chapterRepo.UpdateGenerationMetadata(ctx, chapter.ID, chapter.GenerationMetadata); err != nil {
+		return nil, err
+	}
+	return chapter, nil
+}
+
+// SuggestAfterGeneration 章节生成落库后的收尾步骤：标题为空或占位时自动提出建议。
+// 须在收尾事务提交之后调用；失败仅返回错误，由调用方记录日志，不影响任务结果。
+func (s *ChapterTitleService) SuggestAfterGeneration(ctx context.Context, tenantID string, snapshot *ChapterIndexSnapshot, provider, model string) error {
+	if s == nil || !s.auto || snapshot == nil || snapshot.Chapter == nil || !snapshot.Chapter.HasPlaceholderTitle() {
+		return nil
+	}
+
+	var (
+		chapter *entity.Chapter
+		project *entity.Project
+	)
+	if err := s.withTenant(ctx, tenantID, func(txCtx context.Context) error {
+		var err error
+		if chapter, err = s.chapterRepo.GetByID(txCtx, snapshot.Chapter.ID); err != nil || chapter == nil {
+			return err
+		}
+		project, err = s.projectRepo.GetByID(txCtx, chapter.ProjectID)
+		return err
+	}); err != nil {
+		return err
+	}
+	if chapter == nil || !chapter.HasPlaceholderTitle() {
+		return nil
+	}
+
+	titles, err := s.Suggest(ctx, project, chapter, provider, model, 0)
+	if err != nil {
+		return err
+	}
+
+	return s.withTenant(ctx, tenantID, func(txCtx context.Context) error {
+		// 模型调用期间作者可能已手动改名，此时不再写入建议
+		current, err := s.chapterRepo.GetByID(txCtx, chapter.ID)
+		if err != nil || current == nil || !current.HasPlaceholderTitle() {
+			return err
+		}
+		_, err = s.SaveSuggestions(txCtx, chapter.ID, titles)
+		return err
+	})
+}
+
+func (s *ChapterTitleService) withTenant(ctx context.Context, tenantID string, fn func(txCtx context.Context) error) error {
+	return s.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.tenantCtx.SetTenant(txCtx, tenantID); err != nil {
+			return err
+		}
+		return fn(txCtx)
+	})
+}
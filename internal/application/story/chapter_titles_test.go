@@ -0,0 +1,54 @@
+package story
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestChapterTitleServiceSaveAndAccept(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	chapters := memrepo.NewChapterRepository(store)
+	svc := NewChapterTitleService(nil, chapters, memrepo.NewProjectRepository(store),
+		memrepo.NewTxManager(store), memrepo.NewTenantContext(store), true, time.Minute)
+
+	chapter := entity.NewChapter("project-1", "volume-1", 3)
+	chapter.Title = "第三章"
+	chapter.ContentText = "林默推开门。"
+	mustNoErr(t, chapters.Create(ctx, chapter))
+	if !chapter.HasPlaceholderTitle() {
+		t.Fatalf("%q should be a placeholder title", chapter.Title)
+	}
+
+	// 并发的正文编辑不应被标题建议覆盖
+	mustNoErr(t, chapters.UpdateContent(ctx, chapter.ID, "林默推开门，夜色涌入。", ""))
+	saved, err := svc.SaveSuggestions(ctx, chapter.ID, []string{"夜色", "推门"})
+	mustNoErr(t, err)
+	if saved.ContentText != "林默推开门，夜色涌入。" {
+		t.Fatalf("content overwritten: %q", saved.ContentText)
+	}
+
+	got, err := chapters.GetByID(ctx, chapter.ID)
+	mustNoErr(t, err)
+	if len(got.TitleSuggestions()) != 2 {
+		t.Fatalf("suggestions not persisted: %+v", got.GenerationMetadata)
+	}
+	if got.AcceptTitleSuggestion("别的标题") {
+		t.Fatalf("title outside suggestions should be rejected")
+	}
+	if !got.AcceptTitleSuggestion(" 夜色 ") || got.Title != "夜色" || got.TitleSuggestions() != nil {
+		t.Fatalf("accept failed: title=%q suggestions=%v", got.Title, got.TitleSuggestions())
+	}
+	if got.HasPlaceholderTitle() {
+		t.Fatalf("accepted title should not be a placeholder")
+	}
+
+	// 非占位标题不触发自动建议（未配置生成器也不会报错）
+	if err := svc.SuggestAfterGeneration(ctx, testTenantID, &ChapterIndexSnapshot{Chapter: got}, "openai", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -29,9 +29,11 @@ func NewRollingContextManager(cache KVCache) *RollingContextManager {
 	}
 }
 
-func (m *RollingContextManager) SnapshotAndAppendUserPrompt(ctx context.Context, tenantID, projectID, sessionID string, task entity.ConversationTask, userPrompt string) (summary string, recentUserTurns string, updateErr error) {
+// SnapshotAndAppendUserPrompt 返回本轮 Prompt 使用的滚动上下文快照并记入本轮指令；
+// truncated 为记入时被截断的内容（本轮指令过长或滚动摘要超出上限）
+func (m *RollingContextManager) SnapshotAndAppendUserPrompt(ctx context.Context, tenantID, projectID, sessionID string, task entity.ConversationTask, userPrompt string) (summary string, recentUserTurns string, truncated []entity.PromptTruncation, updateErr error) {
 	if m == nil || m.cache == nil || strings.TrimSpace(string(task)) == "" {
-		return "", "", nil
+		return "", "", nil, nil
 	}
 
 	key := rollingContextKey(tenantID, projectID, sessionID, task)
@@ -42,9 +44,9 @@ func (m *RollingContextManager) SnapshotAndAppendUserPrompt(ctx context.Context,
 	}
 
 	summary, recentUserTurns = rolling.SnapshotForPrompt()
-	rolling.AppendUserPrompt(strings.TrimSpace(userPrompt))
+	truncated = rolling.AppendUserPrompt(strings.TrimSpace(userPrompt))
 	updateErr = m.cache.Set(ctx, key, &rolling, m.ttl)
-	return summary, recentUserTurns, updateErr
+	return summary, recentUserTurns, truncated, updateErr
 }
 
 func rollingContextKey(tenantID, projectID, sessionID string, task entity.ConversationTask) string {
@@ -3,7 +3,9 @@ package context
 import (
 	"fmt"
 	"strings"
+
 	"z-novel-ai-api/internal/application/story/storyutil"
+	"z-novel-ai-api/internal/domain/entity"
 )
 
 const (
@@ -32,24 +34,33 @@ func (c *RollingConversationContext) SnapshotForPrompt() (summary string, recent
 	return summary, recentUserTurns
 }
 
-func (c *RollingConversationContext) AppendUserPrompt(prompt string) {
+// AppendUserPrompt 记入本轮用户指令并按需滚动压缩，返回记入时被截断的内容（供截断报告使用）
+func (c *RollingConversationContext) AppendUserPrompt(prompt string) []entity.PromptTruncation {
 	if c == nil {
-		return
+		return nil
 	}
 	p := strings.TrimSpace(prompt)
 	if p == "" {
-		return
+		return nil
+	}
+	var truncated []entity.PromptTruncation
+	if kept := storyutil.TruncateByRunes(p, rollingContextTurnMaxRunes); kept != p {
+		truncated = append(truncated, storyutil.NewPromptTruncation(entity.TruncationSourceRollingContext, "prompt", p, rollingContextTurnMaxRunes))
+		p = kept
 	}
-	p = storyutil.TruncateByRunes(p, rollingContextTurnMaxRunes)
 
 	c.UserTurnCount++
 	c.RecentUserTurns = append(c.RecentUserTurns, p)
-	c.compact()
+	if t := c.compact(); t != nil {
+		truncated = append(truncated, *t)
+	}
+	return truncated
 }
 
-func (c *RollingConversationContext) compact() {
+// compact 滚动压缩；摘要超出上限被截断时返回截断明细
+func (c *RollingConversationContext) compact() *entity.PromptTruncation {
 	if c == nil {
-		return
+		return nil
 	}
 
 	// 未超过阈值：保留更多“最近指令”，但限制上界，避免无限增长。
@@ -57,19 +68,24 @@ func (c *RollingConversationContext) compact() {
 		if len(c.RecentUserTurns) > rollingContextTriggerTurns {
 			c.RecentUserTurns = c.RecentUserTurns[len(c.RecentUserTurns)-rollingContextTriggerTurns:]
 		}
-		return
+		return nil
 	}
 
 	// 超过阈值：滚动压缩，把较早的 recent turns 合并进 summary，只保留最后 N 条。
 	if len(c.RecentUserTurns) <= rollingContextRecentKeep {
-		return
+		return nil
 	}
 
 	older := c.RecentUserTurns[:len(c.RecentUserTurns)-rollingContextRecentKeep]
 	c.RecentUserTurns = c.RecentUserTurns[len(c.RecentUserTurns)-rollingContextRecentKeep:]
 
-	c.Summary = appendToSummary(c.Summary, older)
-	c.Summary = storyutil.TruncateByRunes(c.Summary, rollingContextSummaryMaxRunes)
+	summary := appendToSummary(c.Summary, older)
+	c.Summary = storyutil.TruncateByRunes(summary, rollingContextSummaryMaxRunes)
+	if c.Summary == summary {
+		return nil
+	}
+	t := storyutil.NewPromptTruncation(entity.TruncationSourceRollingContext, "summary", summary, rollingContextSummaryMaxRunes)
+	return &t
 }
 
 func formatRecentUserTurns(turns []string) string {
@@ -0,0 +1,193 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+const (
+	// MaxContextPins 单章固定上下文条数上限
+	MaxContextPins = 20
+	// maxPinNoteRunes / maxPinTextRunes 备注与片段文本的长度上限
+	maxPinNoteRunes = 200
+	maxPinTextRunes = 2000
+	// pinnedRunesPerItem 每条固定上下文注入 Prompt 的最大字数
+	pinnedRunesPerItem = 600
+)
+
+// ErrInvalidContextPin 固定上下文不合法（类型未知、引用不存在或跨项目等）
+var ErrInvalidContextPin = errors.New("invalid context pin")
+
+// ContextPinService 章节固定上下文：校验作者指定的章节/实体/片段，并在生成前渲染为 Prompt 块。
+// 固定上下文记录在章节上，重生成沿用；与 RAG 召回互补，不受相似度阈值影响。
+//
+// 约定：Normalize / PromptContext 均需在已 SetTenant 的上下文中调用。
+type ContextPinService struct {
+	chapterRepo repository.ChapterRepository
+	entityRepo  repository.EntityRepository
+}
+
+// NewContextPinService 创建固定上下文服务
+func NewContextPinService(chapterRepo repository.ChapterRepository, entityRepo repository.EntityRepository) *ContextPinService {
+	return &ContextPinService{
+		chapterRepo: chapterRepo,
+		entityRepo:  entityRepo,
+	}
+}
+
+// Normalize 校验并规范化固定上下文（去空白、去重）；引用的章节/实体必须属于同一项目，且不能引用章节自身
+func (s *ContextPinService) Normalize(ctx context.Context, chapter *entity.Chapter, pins []entity.ContextPin) ([]entity.ContextPin, error) {
+	if len(pins) > MaxContextPins {
+		return nil, fmt.Errorf("%w: at most %d pins per chapter", ErrInvalidContextPin, MaxContextPins)
+	}
+
+	seen := make(map[string]struct{}, len(pins))
+	out := make([]entity.ContextPin, 0, len(pins))
+	for i, pin := range pins {
+		pin.RefID = strings.TrimSpace(pin.RefID)
+		pin.Note = strings.TrimSpace(pin.Note)
+		pin.Text = strings.TrimSpace(pin.Text)
+		if pin.RefID == "" {
+			return nil, fmt.Errorf("%w: pins[%d].ref_id is required", ErrInvalidContextPin, i)
+		}
+		if len([]rune(pin.Note)) > maxPinNoteRunes {
+			return nil, fmt.Errorf("%w: pins[%d].note too long", ErrInvalidContextPin, i)
+		}
+
+		switch pin.Type {
+		case entity.ContextPinChapter:
+			if pin.RefID == chapter.ID {
+				return nil, fmt.Errorf("%w: pins[%d] references the chapter itself", ErrInvalidContextPin, i)
+			}
+			ref, err := s.chapterRepo.GetByID(ctx, pin.RefID)
+			if err != nil {
+				return nil, err
+			}
+			if ref == nil || ref.ProjectID != chapter.ProjectID {
+				return nil, fmt.Errorf("%w: pins[%d] chapter not found", ErrInvalidContextPin, i)
+			}
+			pin.Text = ""
+		case entity.ContextPinEntity:
+			ref, err := s.entityRepo.GetByID(ctx, pin.RefID)
+			if err != nil {
+				return nil, err
+			}
+			if ref == nil || ref.ProjectID != chapter.ProjectID {
+				return nil, fmt.Errorf("%w: pins[%d] entity not found", ErrInvalidContextPin, i)
+			}
+			pin.Text = ""
+		case entity.ContextPinSegment:
+			if pin.Text == "" {
+				return nil, fmt.Errorf("%w: pins[%d].text is required for segment pins", ErrInvalidContextPin, i)
+			}
+			if len([]rune(pin.Text)) > maxPinTextRunes {
+				return nil, fmt.Errorf("%w: pins[%d].text too long", ErrInvalidContextPin, i)
+			}
+		default:
+			return nil, fmt.Errorf("%w: pins[%d].type %q is not supported", ErrInvalidContextPin, i, pin.Type)
+		}
+
+		key := string(pin.Type) + ":" + pin.RefID
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, pin)
+	}
+	return out, nil
+}
+
+// PromptContext 将章节的固定上下文渲染为 Prompt 块；引用已被删除的条目跳过（仅记录日志）
+func (s *ContextPinService) PromptContext(ctx context.Context, chapter *entity.Chapter) string {
+	if s == nil || chapter == nil || len(chapter.ContextPins) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(chapter.ContextPins)+1)
+	lines = append(lines, "【作者指定上下文（必须参考）】")
+	for _, pin := range chapter.ContextPins {
+		ref, text := s.resolve(ctx, chapter, pin)
+		text = compactPinText(text)
+		if text == "" {
+			continue
+		}
+		if pin.Note != "" {
+			text = text + "（备注：" + pin.Note + "）"
+		}
+		lines = append(lines, fmt.Sprintf("[P%d] (%s) %s", len(lines), ref, text))
+	}
+	if len(lines) == 1 {
+		return ""
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *ContextPinService) resolve(ctx context.Context, chapter *entity.Chapter, pin entity.ContextPin) (ref string, text string) {
+	switch pin.Type {
+	case entity.ContextPinChapter:
+		ch, err := s.chapterRepo.GetByID(ctx, pin.RefID)
+		if err != nil {
+			logger.Warn(ctx, "failed to load pinned chapter", "error", err.Error(), "chapter_id", chapter.ID, "ref_id", pin.RefID)
+			return "", ""
+		}
+		if ch == nil || ch.ProjectID != chapter.ProjectID {
+			return "", ""
+		}
+		title := strings.TrimSpace(ch.Title)
+		if title == "" {
+			title = fmt.Sprintf("第%d章", ch.DisplayNumber())
+		}
+		body := strings.TrimSpace(ch.Summary)
+		if body == "" {
+			body = ch.ContentText
+		}
+		return "Chapter:" + title, body
+	case entity.ContextPinEntity:
+		e, err := s.entityRepo.GetByID(ctx, pin.RefID)
+		if err != nil {
+			logger.Warn(ctx, "failed to load pinned entity", "error", err.Error(), "chapter_id", chapter.ID, "ref_id", pin.RefID)
+			return "", ""
+		}
+		if e == nil || e.ProjectID != chapter.ProjectID {
+			return "", ""
+		}
+		parts := []string{strings.TrimSpace(e.Description)}
+		if state := strings.TrimSpace(e.CurrentState); state != "" {
+			parts = append(parts, "当前状态："+state)
+		}
+		return "Entity:" + strings.TrimSpace(e.Name), strings.Join(parts, " ")
+	case entity.ContextPinSegment:
+		return "Segment", pin.Text
+	default:
+		return "", ""
+	}
+}
+
+// JoinPromptContext 拼接固定上下文与召回上下文（固定上下文在前）
+func JoinPromptContext(pinned, retrieved string) string {
+	pinned = strings.TrimSpace(pinned)
+	retrieved = strings.TrimSpace(retrieved)
+	switch {
+	case pinned == "":
+		return retrieved
+	case retrieved == "":
+		return pinned
+	default:
+		return pinned + "\n\n" + retrieved
+	}
+}
+
+func compactPinText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= pinnedRunesPerItem {
+		return s
+	}
+	return strings.TrimSpace(string(r[:pinnedRunesPerItem])) + "…"
+}
@@ -0,0 +1,116 @@
+// Package duplicate 基于章节内容指纹（SimHash）发现同项目内近乎重复的章节，
+// 用于提示重生成异常导致的不同章节槽位写入了几乎相同的正文。
+package duplicate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// maxMatches 单次检测返回的最多相似章节数
+const maxMatches = 3
+
+// Match 与被检测章节内容高度相似的已有章节
+type Match struct {
+	ChapterID  string  `json:"chapter_id"`
+	SeqNum     int     `json:"seq_num"`
+	Title      string  `json:"title,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+func (m Match) String() string {
+	return fmt.Sprintf("chapter content is %.0f%% similar to chapter %d %s (%s); check whether it was generated into the wrong slot",
+		m.Similarity*100, m.SeqNum, m.Title, m.ChapterID)
+}
+
+// Detector 章节重复内容检测器
+type Detector struct {
+	chapterRepo repository.ChapterRepository
+	threshold   float64
+}
+
+// NewDetector 创建重复内容检测器；threshold <= 0 时关闭检测
+func NewDetector(chapterRepo repository.ChapterRepository, threshold float64) *Detector {
+	return &Detector{
+		chapterRepo: chapterRepo,
+		threshold:   threshold,
+	}
+}
+
+// Enabled 是否启用检测
+func (d *Detector) Enabled() bool {
+	return d != nil && d.threshold > 0
+}
+
+// CheckChapter 将章节（需已落库，调用方负责事务边界）与同项目其他章节比较，
+// 返回相似度达到阈值的章节（按相似度降序）。缺少指纹的历史章节按正文补算并回写。
+func (d *Detector) CheckChapter(ctx context.Context, chapter *entity.Chapter) ([]Match, error) {
+	if !d.Enabled() || chapter == nil {
+		return nil, nil
+	}
+	fp := entity.NewChapterFingerprint(chapter)
+	if !fp.Comparable() {
+		return nil, nil
+	}
+
+	chapters, err := d.chapterRepo.ListTimeline(ctx, chapter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.chapterRepo.ListFingerprints(ctx, chapter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	fps := make(map[string]*entity.ChapterFingerprint, len(rows))
+	for _, row := range rows {
+		fps[row.ChapterID] = row
+	}
+
+	var matches []Match
+	for _, other := range chapters {
+		if other.ID == chapter.ID {
+			continue
+		}
+		otherFP := fps[other.ID]
+		if otherFP == nil {
+			if otherFP, err = d.backfill(ctx, other.ID); err != nil {
+				return nil, err
+			}
+		}
+		if !otherFP.Comparable() {
+			continue
+		}
+		if sim := fp.Similarity(otherFP); sim >= d.threshold {
+			matches = append(matches, Match{
+				ChapterID:  other.ID,
+				SeqNum:     other.SeqNum,
+				Title:      other.Title,
+				Similarity: sim,
+			})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	if len(matches) > maxMatches {
+		matches = matches[:maxMatches]
+	}
+	return matches, nil
+}
+
+// backfill 为指纹表上线前保存的章节补算指纹（回写失败仅记录日志）
+func (d *Detector) backfill(ctx context.Context, chapterID string) (*entity.ChapterFingerprint, error) {
+	full, err := d.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil || full == nil {
+		return nil, err
+	}
+	fp := entity.NewChapterFingerprint(full)
+	if err := d.chapterRepo.SaveFingerprint(ctx, fp); err != nil {
+		logger.Warn(ctx, "failed to backfill chapter fingerprint", "error", err.Error(), "chapter_id", chapterID)
+	}
+	return fp, nil
+}
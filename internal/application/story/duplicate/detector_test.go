@@ -0,0 +1,55 @@
+package duplicate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+const (
+	chapterA = "林默推开客栈的木门，寒风裹着雪粒扑面而来。掌柜抬头看了他一眼，又低下头拨弄算盘。角落里坐着一个披着斗篷的老人，桌上放着一柄没有剑鞘的长剑。"
+	chapterB = "山谷深处的湖泊终年不冻，传说湖底沉着一座古城。苏晴沿着湖岸慢慢走着，脚下的碎石发出细微的声响，远处的钟声若有若无，像是从水下传来的回音。"
+)
+
+func TestDetectorFlagsNearDuplicateChapter(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	chapters := memrepo.NewChapterRepository(store)
+	detector := NewDetector(chapters, 0.9)
+
+	original := strings.Repeat(chapterA, 6)
+	mustCreate := func(seq int, content string) *entity.Chapter {
+		t.Helper()
+		c := entity.NewChapter("project-1", "volume-1", seq)
+		c.ContentText = content
+		if err := chapters.Create(ctx, c); err != nil {
+			t.Fatalf("create chapter: %v", err)
+		}
+		return c
+	}
+	first := mustCreate(1, original)
+	mustCreate(2, strings.Repeat(chapterB, 6))
+	mustCreate(3, "第三章提纲")
+
+	// 重生成写错槽位：正文与第 1 章几乎相同，仅改动个别字句
+	dup := mustCreate(4, strings.Replace(original, "掌柜抬头看了他一眼", "掌柜抬眼瞥了他一下", 1))
+	matches, err := detector.CheckChapter(ctx, dup)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ChapterID != first.ID || matches[0].Similarity < 0.9 {
+		t.Fatalf("expected chapter 1 as the only match, got %+v", matches)
+	}
+
+	distinct := mustCreate(5, strings.Repeat("夜色里城门缓缓关闭，守卫举着火把巡视城墙，更夫的梆子声从街巷深处传来。", 8))
+	if matches, err := detector.CheckChapter(ctx, distinct); err != nil || len(matches) != 0 {
+		t.Fatalf("unrelated chapter should not match, got %+v (err=%v)", matches, err)
+	}
+
+	if matches, _ := NewDetector(chapters, 0).CheckChapter(ctx, dup); matches != nil {
+		t.Fatalf("threshold 0 should disable the check")
+	}
+}
@@ -0,0 +1,633 @@
+// Package story 提供章节生成等跨入口（HTTP/SSE/Worker）共用的应用服务。
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/application/story/storyutil"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// chapterIndexTimeout 章节落库后同步写索引的超时时间
+const chapterIndexTimeout = 15 * time.Second
+
+// GenerationFinalizer 章节生成收尾服务：统一 Worker 与 SSE 两条路径的落库/计量/索引逻辑。
+//
+// 约定：
+// - CompleteChapter / FailChapter 由调用方负责事务边界（需已 SetTenant），并同时结算/释放任务的配额预留。
+// - IndexChapter 必须在事务提交之后调用，避免持有 DB 连接执行 Embedding。
+type GenerationFinalizer struct {
+	chapterRepo repository.ChapterRepository
+	projectRepo repository.ProjectRepository
+	jobRepo     repository.JobRepository
+	eventRepo   repository.EventRepository
+	indexer     *appretrieval.Indexer
+	timeline    *JobTimeline
+	quota       *quota.TokenQuotaChecker
+	candidates  repository.GenerationCandidateRepository
+	duplicates  *duplicate.Detector
+	events      *ChapterEventReplacer
+}
+
+// NewGenerationFinalizer 创建章节生成收尾服务
+func NewGenerationFinalizer(
+	chapterRepo repository.ChapterRepository,
+	projectRepo repository.ProjectRepository,
+	jobRepo repository.JobRepository,
+	eventRepo repository.EventRepository,
+	indexer *appretrieval.Indexer,
+	timeline *JobTimeline,
+	quotaChecker *quota.TokenQuotaChecker,
+	candidateRepo repository.GenerationCandidateRepository,
+	duplicates *duplicate.Detector,
+	events *ChapterEventReplacer,
+) *GenerationFinalizer {
+	return &GenerationFinalizer{
+		chapterRepo: chapterRepo,
+		projectRepo: projectRepo,
+		jobRepo:     jobRepo,
+		eventRepo:   eventRepo,
+		indexer:     indexer,
+		timeline:    timeline,
+		quota:       quotaChecker,
+		candidates:  candidateRepo,
+		duplicates:  duplicates,
+		events:      events,
+	}
+}
+
+// ChapterIndexSnapshot 事务提交后写索引所需的章节快照
+type ChapterIndexSnapshot struct {
+	Chapter          *entity.Chapter
+	NarrativePos     int64
+	InvolvedEntities []string
+}
+
+// CompleteChapter 将生成结果写入章节，刷新项目字数，并将任务标记为完成。
+// 返回值为用于事务提交后写索引的章节快照。生成期间章节被作者修改时不覆盖正文，
+// 结果保存为待选候选（见 CompleteChapterCandidates），返回 nil。
+func (f *GenerationFinalizer) CompleteChapter(ctx context.Context, job *entity.GenerationJob, chapter *entity.Chapter, out *wfmodel.ChapterGenerateOutput) (*ChapterIndexSnapshot, error) {
+	if f == nil {
+		return nil, fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job is nil")
+	}
+	if chapter == nil {
+		return nil, fmt.Errorf("chapter is nil")
+	}
+	if out == nil {
+		return nil, fmt.Errorf("chapter output is nil")
+	}
+	if EditedDuringGeneration(job, chapter) {
+		return f.CompleteChapterCandidates(ctx, job, chapter, []*wfmodel.ChapterGenerateOutput{out}, entity.CandidateSelectionManual, 0)
+	}
+
+	if err := f.applyChapterOutput(ctx, chapter, out); err != nil {
+		return nil, err
+	}
+	job.AddWarnings(f.duplicateWarnings(ctx, chapter)...)
+
+	result, _ := json.Marshal(map[string]any{
+		"chapter_id": chapter.ID,
+		"word_count": chapter.WordCount,
+	})
+	job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
+	job.Complete(result)
+	if err := f.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := f.quota.Settle(ctx, job.ID, int64(out.Meta.PromptTokens+out.Meta.CompletionTokens)); err != nil {
+		return nil, err
+	}
+	f.timeline.Record(ctx, job, entity.JobEventCompleted, "chapter generated", map[string]any{
+		"word_count":        chapter.WordCount,
+		"prompt_tokens":     out.Meta.PromptTokens,
+		"completion_tokens": out.Meta.CompletionTokens,
+	})
+
+	return f.IndexSnapshot(ctx, chapter), nil
+}
+
+// CompleteChapterCandidates 多候选生成（best-of-N）收尾：保存全部候选并按质量评分排序。
+// selection=auto 时采用最高分候选写入章节（其余记为 discarded），返回索引快照；
+// selection=manual 时候选均待选、章节置为 review，返回 nil，由 SelectChapterCandidate 完成写入。
+// 任务的 Token 计量与配额结算按全部候选合计。生成期间章节被作者修改时按 manual 处理，不覆盖作者的修改。
+func (f *GenerationFinalizer) CompleteChapterCandidates(ctx context.Context, job *entity.GenerationJob, chapter *entity.Chapter, outs []*wfmodel.ChapterGenerateOutput, selection entity.CandidateSelection, targetWordCount int) (*ChapterIndexSnapshot, error) {
+	if f == nil {
+		return nil, fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil || chapter == nil {
+		return nil, fmt.Errorf("job and chapter are required")
+	}
+	if len(outs) == 0 {
+		return nil, fmt.Errorf("no chapter candidates")
+	}
+	if EditedDuringGeneration(job, chapter) {
+		selection = entity.CandidateSelectionManual
+		job.AddWarnings(ChapterEditedWarning())
+	}
+
+	scores := make([]float64, len(outs))
+	for i, out := range outs {
+		scores[i] = ScoreChapter(out.Content, targetWordCount)
+	}
+	best := BestCandidate(scores)
+
+	var promptTokens, completionTokens int
+	candidates := make([]*entity.GenerationCandidate, 0, len(outs))
+	for i, out := range outs {
+		status := entity.CandidateStatusPending
+		if selection == entity.CandidateSelectionAuto {
+			status = entity.CandidateStatusDiscarded
+			if i == best {
+				status = entity.CandidateStatusSelected
+			}
+		}
+		c := &entity.GenerationCandidate{
+			TenantID:         job.TenantID,
+			JobID:            job.ID,
+			ProjectID:        chapter.ProjectID,
+			TargetType:       entity.CandidateTargetChapter,
+			TargetID:         chapter.ID,
+			CandidateNo:      i + 1,
+			Status:           status,
+			Score:            scores[i],
+			Content:          out.Content,
+			Provider:         out.Meta.Provider,
+			Model:            out.Meta.Model,
+			PromptTokens:     out.Meta.PromptTokens,
+			CompletionTokens: out.Meta.CompletionTokens,
+			Temperature:      out.Meta.Temperature,
+		}
+		if status == entity.CandidateStatusSelected {
+			now := time.Now()
+			c.SelectedAt = &now
+		}
+		candidates = append(candidates, c)
+		promptTokens += out.Meta.PromptTokens
+		completionTokens += out.Meta.CompletionTokens
+	}
+	if err := f.candidates.CreateBatch(ctx, candidates); err != nil {
+		return nil, err
+	}
+
+	if selection == entity.CandidateSelectionAuto {
+		if err := f.applyChapterOutput(ctx, chapter, outs[best]); err != nil {
+			return nil, err
+		}
+		job.AddWarnings(f.duplicateWarnings(ctx, chapter)...)
+	} else {
+		chapter.Status = ChapterStatusForJob(entity.JobStatusCompleted, selection)
+		if err := f.chapterRepo.Update(ctx, chapter); err != nil {
+			return nil, err
+		}
+	}
+
+	summaries := make([]map[string]any, 0, len(candidates))
+	for _, c := range candidates {
+		summaries = append(summaries, map[string]any{
+			"candidate_id": c.ID,
+			"candidate_no": c.CandidateNo,
+			"score":        c.Score,
+			"status":       c.Status,
+		})
+	}
+	resultObj := map[string]any{
+		"chapter_id": chapter.ID,
+		"selection":  selection,
+		"candidates": summaries,
+	}
+	if selection == entity.CandidateSelectionAuto {
+		resultObj["word_count"] = chapter.WordCount
+		resultObj["selected_candidate_id"] = candidates[best].ID
+	}
+	result, _ := json.Marshal(resultObj)
+	job.SetLLMMetrics(outs[best].Meta.Provider, outs[best].Meta.Model, promptTokens, completionTokens)
+	job.Complete(result)
+	if err := f.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := f.quota.Settle(ctx, job.ID, int64(promptTokens+completionTokens)); err != nil {
+		return nil, err
+	}
+	f.timeline.Record(ctx, job, entity.JobEventCompleted, "chapter candidates generated", map[string]any{
+		"candidates":        len(candidates),
+		"selection":         selection,
+		"prompt_tokens":     promptTokens,
+		"completion_tokens": completionTokens,
+	})
+	if selection != entity.CandidateSelectionAuto {
+		return nil, nil
+	}
+	f.timeline.Record(ctx, job, entity.JobEventSelected, "highest scoring candidate applied", map[string]any{
+		"candidate_id": candidates[best].ID,
+		"score":        candidates[best].Score,
+	})
+
+	return f.IndexSnapshot(ctx, chapter), nil
+}
+
+// SelectChapterCandidate 将章节候选写入章节正文并标记为已采用（同任务其余候选记为 discarded）。
+// 可对已采用过其他候选的任务再次调用以切换结果；返回值为用于事务提交后写索引的章节快照。
+func (f *GenerationFinalizer) SelectChapterCandidate(ctx context.Context, job *entity.GenerationJob, chapter *entity.Chapter, candidate *entity.GenerationCandidate) (*ChapterIndexSnapshot, error) {
+	if f == nil {
+		return nil, fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil || chapter == nil || candidate == nil {
+		return nil, fmt.Errorf("job, chapter and candidate are required")
+	}
+
+	out := &wfmodel.ChapterGenerateOutput{
+		Content: candidate.Content,
+		Meta: wfmodel.LLMUsageMeta{
+			Provider:         candidate.Provider,
+			Model:            candidate.Model,
+			PromptTokens:     candidate.PromptTokens,
+			CompletionTokens: candidate.CompletionTokens,
+			Temperature:      candidate.Temperature,
+			GeneratedAt:      candidate.CreatedAt,
+		},
+	}
+	if err := f.applyChapterOutput(ctx, chapter, out); err != nil {
+		return nil, err
+	}
+	// 原样采用中断保存的部分内容时章节尚未写完，保持 draft 供作者继续编辑
+	if candidate.Partial {
+		chapter.Status = entity.ChapterStatusDraft
+		if err := f.chapterRepo.Update(ctx, chapter); err != nil {
+			return nil, err
+		}
+	}
+	if err := f.candidates.MarkSelected(ctx, job.ID, candidate.ID, nil); err != nil {
+		return nil, err
+	}
+	if warnings := f.duplicateWarnings(ctx, chapter); len(warnings) > 0 {
+		if err := f.jobRepo.AppendWarnings(ctx, job.ID, warnings...); err != nil {
+			return nil, err
+		}
+	}
+	f.timeline.Record(ctx, job, entity.JobEventSelected, "candidate selected", map[string]any{
+		"candidate_id": candidate.ID,
+		"score":        candidate.Score,
+		"word_count":   chapter.WordCount,
+	})
+
+	return f.IndexSnapshot(ctx, chapter), nil
+}
+
+// EditedDuringGeneration 生成期间章节是否被作者修改：版本号不同于任务创建时记录的 base_version（全文保存），
+// 或任务创建后有未被生成覆盖的自动保存（合并窗口内的自动保存不递增版本，以 draft_dirty 与最后编辑时间判断）。
+func EditedDuringGeneration(job *entity.GenerationJob, chapter *entity.Chapter) bool {
+	if job == nil || chapter == nil {
+		return false
+	}
+	if base, ok := JobBaseChapterVersion(job); ok && chapter.Version != base {
+		return true
+	}
+	return chapter.DraftDirty && chapter.LastEditedAt != nil && chapter.LastEditedAt.After(job.CreatedAt)
+}
+
+// applyChapterOutput 将生成结果写入章节、替代旧正文抽取的事件并刷新项目字数
+func (f *GenerationFinalizer) applyChapterOutput(ctx context.Context, chapter *entity.Chapter, out *wfmodel.ChapterGenerateOutput) error {
+	chapter.ReplaceContent(out.Content)
+	chapter.Status = entity.ChapterStatusCompleted
+	chapter.DraftDirty = false
+	// 按当前大纲重新生成后不再视为偏离
+	chapter.ClearOutlineStale()
+	chapter.GenerationMetadata = &entity.GenerationMetadata{
+		Model:            out.Meta.Model,
+		Provider:         out.Meta.Provider,
+		PromptTokens:     out.Meta.PromptTokens,
+		CompletionTokens: out.Meta.CompletionTokens,
+		Temperature:      out.Meta.Temperature,
+		GeneratedAt:      out.Meta.GeneratedAt.Format(time.RFC3339),
+	}
+	if err := f.chapterRepo.Update(ctx, chapter); err != nil {
+		return err
+	}
+	// 正文已被替换，旧正文抽取的事件不再有效；新事件由后续抽取经 ChapterEventReplacer 写入
+	if f.events != nil {
+		if _, err := f.events.Replace(ctx, chapter, nil); err != nil {
+			return err
+		}
+	}
+	f.refreshProjectWordCount(ctx, chapter.ProjectID)
+	return nil
+}
+
+// duplicateWarnings 检测写入后的章节是否与同项目其他章节高度相似（检测失败仅记录日志，不阻断收尾）
+func (f *GenerationFinalizer) duplicateWarnings(ctx context.Context, chapter *entity.Chapter) []entity.JobWarning {
+	matches, err := f.duplicates.CheckChapter(ctx, chapter)
+	if err != nil {
+		logger.Warn(ctx, "failed to check duplicate chapter content", "error", err.Error(), "chapter_id", chapter.ID)
+		return nil
+	}
+	return DuplicateContentWarnings(matches)
+}
+
+// IndexSnapshot 在事务内准备章节索引快照（叙事位置 + 涉及实体），供提交后写索引。
+func (f *GenerationFinalizer) IndexSnapshot(ctx context.Context, chapter *entity.Chapter) *ChapterIndexSnapshot {
+	return newChapterIndexSnapshot(ctx, f.chapterRepo, chapter, f.chapterInvolvedEntities(ctx, chapter))
+}
+
+// newChapterIndexSnapshot 查询叙事位置并组装索引快照
+func newChapterIndexSnapshot(ctx context.Context, chapterRepo repository.ChapterRepository, chapter *entity.Chapter, involved []string) *ChapterIndexSnapshot {
+	narrativePos, err := chapterRepo.GetNarrativePosition(ctx, chapter.ID)
+	if err != nil {
+		// 叙事位置仅影响检索过滤精度，不阻断收尾
+		logger.Warn(ctx, "failed to get chapter narrative position", "error", err.Error(), "chapter_id", chapter.ID)
+	}
+
+	return &ChapterIndexSnapshot{
+		Chapter:          chapterIndexSnapshot(chapter),
+		NarrativePos:     narrativePos,
+		InvolvedEntities: involved,
+	}
+}
+
+// FailChapter 将任务标记为失败，并把仍处于 generating 的章节回退为 draft（不清空旧正文）。
+func (f *GenerationFinalizer) FailChapter(ctx context.Context, job *entity.GenerationJob, chapterID string, cause error) error {
+	if f == nil {
+		return fmt.Errorf("generation finalizer not configured")
+	}
+	if job != nil {
+		msg := "generation failed"
+		if cause != nil {
+			msg = cause.Error()
+		}
+		job.FailWithCode(JobErrorCode(cause), msg)
+		if err := f.jobRepo.Update(ctx, job); err != nil {
+			return err
+		}
+		if err := f.quota.Release(ctx, job.ID); err != nil {
+			return err
+		}
+		f.timeline.Record(ctx, job, entity.JobEventFailed, msg, nil)
+	}
+
+	f.resetGeneratingChapter(ctx, chapterID)
+	return nil
+}
+
+// CancelChapter 流式生成被客户端中断时结束任务，并把仍处于 generating 的章节回退为 draft（不覆盖旧正文）。
+// partial 含已生成的内容时保存为 partial 候选（待作者续写、原样采用或丢弃），任务记为 cancelled_partial，
+// 按已产生的 Token 结算配额（流中断时模型通常未返回用量，按输出长度估算）；无内容时任务记为 cancelled 并释放预留。
+func (f *GenerationFinalizer) CancelChapter(ctx context.Context, job *entity.GenerationJob, chapterID string, partial *wfmodel.ChapterGenerateOutput) (*entity.GenerationCandidate, error) {
+	if f == nil {
+		return nil, fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job is nil")
+	}
+	defer f.resetGeneratingChapter(ctx, chapterID)
+
+	if partial == nil || strings.TrimSpace(partial.Content) == "" {
+		now := time.Now()
+		job.Status = entity.JobStatusCancelled
+		job.CompletedAt = &now
+		if err := f.jobRepo.Update(ctx, job); err != nil {
+			return nil, err
+		}
+		if err := f.quota.Release(ctx, job.ID); err != nil {
+			return nil, err
+		}
+		f.timeline.Record(ctx, job, entity.JobEventCancelled, "stream interrupted before any content", nil)
+		return nil, nil
+	}
+
+	content := strings.TrimSpace(partial.Content)
+	completionTokens := partial.Meta.CompletionTokens
+	if completionTokens <= 0 {
+		completionTokens = storyutil.EstimateTokens(content)
+	}
+	candidate := &entity.GenerationCandidate{
+		TenantID:         job.TenantID,
+		JobID:            job.ID,
+		ProjectID:        job.ProjectID,
+		TargetType:       entity.CandidateTargetChapter,
+		TargetID:         chapterID,
+		CandidateNo:      1,
+		Status:           entity.CandidateStatusPending,
+		Score:            ScoreChapter(content, 0),
+		Content:          content,
+		Partial:          true,
+		Provider:         partial.Meta.Provider,
+		Model:            partial.Meta.Model,
+		PromptTokens:     partial.Meta.PromptTokens,
+		CompletionTokens: completionTokens,
+		Temperature:      partial.Meta.Temperature,
+	}
+	if err := f.candidates.CreateBatch(ctx, []*entity.GenerationCandidate{candidate}); err != nil {
+		return nil, err
+	}
+
+	wordCount := len([]rune(content))
+	result, _ := json.Marshal(map[string]any{
+		"chapter_id":   chapterID,
+		"candidate_id": candidate.ID,
+		"word_count":   wordCount,
+		"partial":      true,
+	})
+	job.SetLLMMetrics(partial.Meta.Provider, partial.Meta.Model, partial.Meta.PromptTokens, completionTokens)
+	job.CancelPartial(result)
+	if err := f.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := f.quota.Settle(ctx, job.ID, int64(partial.Meta.PromptTokens+completionTokens)); err != nil {
+		return nil, err
+	}
+	f.timeline.Record(ctx, job, entity.JobEventCancelled, "stream interrupted, partial content saved", map[string]any{
+		"candidate_id":      candidate.ID,
+		"word_count":        wordCount,
+		"completion_tokens": completionTokens,
+	})
+	return candidate, nil
+}
+
+// AbortChapter 任务被用户取消后 Worker 中止生成时结束任务：保持 cancelled 状态并记录本次尝试的消耗，
+// 释放配额预留（取消接口已释放时幂等），并把仍处于 generating 的章节回退为 draft（不覆盖旧正文）。
+func (f *GenerationFinalizer) AbortChapter(ctx context.Context, job *entity.GenerationJob, chapterID string) error {
+	if f == nil {
+		return fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	defer f.resetGeneratingChapter(ctx, chapterID)
+
+	if job.Status != entity.JobStatusCancelled {
+		now := time.Now()
+		job.Status = entity.JobStatusCancelled
+		job.CompletedAt = &now
+	}
+	if err := f.jobRepo.Update(ctx, job); err != nil {
+		return err
+	}
+	if err := f.quota.Release(ctx, job.ID); err != nil {
+		return err
+	}
+	f.timeline.Record(ctx, job, entity.JobEventCancelled, "generation aborted by cancel request", map[string]any{
+		"spent_tokens": job.SpentTokens,
+	})
+	return nil
+}
+
+// PartialCandidate 返回 cancelled_partial 任务保存的部分内容候选（已采用或丢弃时返回 nil）
+func (f *GenerationFinalizer) PartialCandidate(ctx context.Context, job *entity.GenerationJob) (*entity.GenerationCandidate, error) {
+	if f == nil || job == nil || job.Status != entity.JobStatusCancelledPartial {
+		return nil, nil
+	}
+	candidates, err := f.candidates.ListByJob(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		if c.Partial && c.Status == entity.CandidateStatusPending {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// DiscardPartial 丢弃中断时保存的部分内容（作者丢弃，或续写完成后由完整结果替代）
+func (f *GenerationFinalizer) DiscardPartial(ctx context.Context, job *entity.GenerationJob, candidate *entity.GenerationCandidate, reason string) error {
+	if f == nil {
+		return fmt.Errorf("generation finalizer not configured")
+	}
+	if job == nil || candidate == nil {
+		return fmt.Errorf("job and candidate are required")
+	}
+	if err := f.candidates.MarkDiscarded(ctx, candidate.ID); err != nil {
+		return err
+	}
+	f.timeline.Record(ctx, job, entity.JobEventDiscarded, reason, map[string]any{
+		"candidate_id": candidate.ID,
+	})
+	return nil
+}
+
+// resetGeneratingChapter 将仍处于 generating 的章节回退为 draft（失败仅记录日志）
+func (f *GenerationFinalizer) resetGeneratingChapter(ctx context.Context, chapterID string) {
+	if strings.TrimSpace(chapterID) == "" {
+		return
+	}
+	ch, err := f.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Warn(ctx, "failed to load chapter for generation failure", "error", err.Error(), "chapter_id", chapterID)
+		return
+	}
+	if ch == nil || ch.Status != entity.ChapterStatusGenerating {
+		return
+	}
+	ch.Status = ChapterStatusForJob(entity.JobStatusFailed, "")
+	if err := f.chapterRepo.Update(ctx, ch); err != nil {
+		logger.Warn(ctx, "failed to reset chapter status after generation failure", "error", err.Error(), "chapter_id", chapterID)
+	}
+}
+
+// IndexChapter 章节落库后同步写入向量索引（失败仅记录日志并返回错误，由调用方记录任务警告，不影响主流程）。
+// 向量能力未启用时返回 nil。
+func (f *GenerationFinalizer) IndexChapter(ctx context.Context, tenantID string, snapshot *ChapterIndexSnapshot) error {
+	if f == nil {
+		return nil
+	}
+	return indexChapterSnapshot(ctx, f.indexer, tenantID, snapshot)
+}
+
+// indexChapterSnapshot 按快照写入章节向量索引（不受调用方取消影响，带独立超时）
+func indexChapterSnapshot(ctx context.Context, indexer *appretrieval.Indexer, tenantID string, snapshot *ChapterIndexSnapshot) error {
+	if indexer == nil || snapshot == nil || snapshot.Chapter == nil {
+		return nil
+	}
+	chapter := snapshot.Chapter
+	indexCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chapterIndexTimeout)
+	defer cancel()
+	opts := appretrieval.ChapterIndexOptions{
+		NarrativePos:     snapshot.NarrativePos,
+		InvolvedEntities: snapshot.InvolvedEntities,
+	}
+	if err := indexer.IndexChapter(indexCtx, tenantID, chapter.ProjectID, chapter, opts); err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) {
+		logger.Warn(ctx, "failed to index chapter",
+			"error", err.Error(),
+			"chapter_id", chapter.ID,
+		)
+		return err
+	}
+	return nil
+}
+
+// chapterInvolvedEntities 汇总章节涉及的实体：POV 角色 + 章节当前有效事件的参与者（去重，保持顺序）。
+func (f *GenerationFinalizer) chapterInvolvedEntities(ctx context.Context, chapter *entity.Chapter) []string {
+	var events []*entity.Event
+	if f.eventRepo != nil {
+		var err error
+		events, err = f.eventRepo.ListByChapter(ctx, chapter.ID)
+		if err != nil {
+			logger.Warn(ctx, "failed to list chapter events for involved entities", "error", err.Error(), "chapter_id", chapter.ID)
+		}
+	}
+	return involvedEntities(chapter, events)
+}
+
+// involvedEntities POV 角色在前，其后为事件参与者（去重，保持顺序）
+func involvedEntities(chapter *entity.Chapter, events []*entity.Event) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	add := func(id string) {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return
+		}
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+
+	add(chapter.POVEntity())
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		for _, id := range ev.InvolvedEntities {
+			add(id)
+		}
+	}
+	return out
+}
+
+func (f *GenerationFinalizer) refreshProjectWordCount(ctx context.Context, projectID string) {
+	stats, err := f.projectRepo.GetStats(ctx, projectID)
+	if err != nil || stats == nil {
+		logger.Warn(ctx, "failed to refresh project word count after chapter generation", "error", err)
+		return
+	}
+	if err := f.projectRepo.UpdateWordCount(ctx, projectID, int(stats.TotalWordCount)); err != nil {
+		logger.Warn(ctx, "failed to update project word count after chapter generation", "error", err.Error())
+	}
+}
+
+// chapterIndexSnapshot 仅复制索引所需字段，避免事务外继续持有被修改的实体。
+func chapterIndexSnapshot(ch *entity.Chapter) *entity.Chapter {
+	return &entity.Chapter{
+		ID:             ch.ID,
+		ProjectID:      ch.ProjectID,
+		Title:          ch.Title,
+		ContentText:    ch.ContentText,
+		StoryTimeStart: ch.StoryTimeStart,
+		StoryTimeEnd:   ch.StoryTimeEnd,
+	}
+}
@@ -0,0 +1,552 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+const testTenantID = "tenant-1"
+
+type finalizerFixture struct {
+	chapters     *memrepo.ChapterRepository
+	projects     *memrepo.ProjectRepository
+	jobs         *memrepo.JobRepository
+	jobEvents    *memrepo.JobEventRepository
+	reservations *memrepo.QuotaReservationRepository
+	relations    *memrepo.RelationRepository
+	candidates   *memrepo.GenerationCandidateRepository
+	events       *memrepo.EventRepository
+	finalizer    *GenerationFinalizer
+
+	project  *entity.Project
+	chapter  *entity.Chapter
+	job      *entity.GenerationJob
+	relation *entity.Relation
+	event    *entity.Event
+}
+
+// newFinalizerFixture 准备一个 generating 状态的章节、运行中的任务及其配额预留，
+// 章节 POV 角色与事件参与者之间已有一条关系。
+func newFinalizerFixture(t *testing.T) *finalizerFixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	f := &finalizerFixture{
+		chapters:     memrepo.NewChapterRepository(store),
+		projects:     memrepo.NewProjectRepository(store),
+		jobs:         memrepo.NewJobRepository(store),
+		jobEvents:    memrepo.NewJobEventRepository(store),
+		reservations: memrepo.NewQuotaReservationRepository(store),
+		relations:    memrepo.NewRelationRepository(store),
+		candidates:   memrepo.NewGenerationCandidateRepository(store),
+		events:       memrepo.NewEventRepository(store),
+	}
+	tenants := memrepo.NewTenantRepository(store)
+	volumes := memrepo.NewVolumeRepository(store)
+	entities := memrepo.NewEntityRepository(store)
+
+	f.finalizer = NewGenerationFinalizer(
+		f.chapters, f.projects, f.jobs, f.events, nil,
+		NewJobTimeline(f.jobEvents),
+		quota.NewTokenQuotaChecker(tenants, f.reservations, memrepo.NewPlanRepository(store)),
+		f.candidates,
+		duplicate.NewDetector(f.chapters, 0.9),
+		NewChapterEventReplacer(f.events, f.chapters, nil, nil),
+	)
+
+	f.project = entity.NewProject(testTenantID, "owner-1", "测试项目")
+	mustNoErr(t, f.projects.Create(ctx, f.project))
+	volume := entity.NewVolume(f.project.ID, 1, "第一卷")
+	mustNoErr(t, volumes.Create(ctx, volume))
+
+	hero := entity.NewStoryEntity(f.project.ID, "林默", entity.EntityTypeCharacter, entity.ImportanceProtagonist)
+	friend := entity.NewStoryEntity(f.project.ID, "苏晴", entity.EntityTypeCharacter, entity.ImportanceSecondary)
+	mustNoErr(t, entities.Create(ctx, hero))
+	mustNoErr(t, entities.Create(ctx, friend))
+	f.relation = entity.NewRelation(f.project.ID, hero.ID, friend.ID, entity.RelationTypeFriend)
+	mustNoErr(t, f.relations.Create(ctx, f.relation))
+
+	f.chapter = entity.NewChapter(f.project.ID, volume.ID, 1)
+	f.chapter.Title = "觉醒"
+	f.chapter.POVEntityID = &hero.ID
+	f.chapter.Status = entity.ChapterStatusGenerating
+	mustNoErr(t, f.chapters.Create(ctx, f.chapter))
+
+	event := entity.NewEvent(f.project.ID, 100, "二人初遇")
+	event.ChapterID = f.chapter.ID
+	event.AddInvolvedEntity(friend.ID)
+	mustNoErr(t, f.events.Create(ctx, event))
+	f.event = event
+
+	f.job = entity.NewGenerationJob(testTenantID, f.project.ID, entity.JobTypeChapterGen, nil)
+	f.job.ChapterID = &f.chapter.ID
+	f.job.Start()
+	mustNoErr(t, f.jobs.Create(ctx, f.job))
+	mustNoErr(t, f.reservations.Upsert(ctx, entity.NewQuotaReservation(testTenantID, f.job.ID, 5000, time.Hour)))
+	return f
+}
+
+func (f *finalizerFixture) reservationStatus(t *testing.T) *entity.QuotaReservation {
+	t.Helper()
+	r, err := f.reservations.GetByJobID(context.Background(), f.job.ID)
+	mustNoErr(t, err)
+	if r == nil {
+		t.Fatalf("reservation not found")
+	}
+	return r
+}
+
+func (f *finalizerFixture) jobEventTypes(t *testing.T) []entity.JobEventType {
+	t.Helper()
+	events, err := f.jobEvents.ListByJob(context.Background(), f.job.ID)
+	mustNoErr(t, err)
+	types := make([]entity.JobEventType, 0, len(events))
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	return types
+}
+
+func chapterOutput(content string, promptTokens, completionTokens int) *wfmodel.ChapterGenerateOutput {
+	return &wfmodel.ChapterGenerateOutput{
+		Content: content,
+		Meta: wfmodel.LLMUsageMeta{
+			Provider:         "openai",
+			Model:            "gpt-4o",
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			Temperature:      0.7,
+			GeneratedAt:      time.Now().UTC(),
+		},
+	}
+}
+
+func TestGenerationFinalizerCompleteChapter(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	content := "晨光落在山门前，林默第一次握紧了剑。"
+
+	snapshot, err := f.finalizer.CompleteChapter(ctx, f.job, f.chapter, chapterOutput(content, 120, 80))
+	mustNoErr(t, err)
+
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.ContentText != content || chapter.Status != entity.ChapterStatusCompleted {
+		t.Fatalf("chapter not completed: status=%s content=%q", chapter.Status, chapter.ContentText)
+	}
+	wantWords := len([]rune(content))
+	if chapter.WordCount != wantWords {
+		t.Fatalf("word count = %d, want %d", chapter.WordCount, wantWords)
+	}
+	if chapter.GenerationMetadata == nil || chapter.GenerationMetadata.Model != "gpt-4o" {
+		t.Fatalf("generation metadata not recorded: %+v", chapter.GenerationMetadata)
+	}
+	project, _ := f.projects.GetByID(ctx, f.project.ID)
+	if project.CurrentWordCount != wantWords {
+		t.Fatalf("project word count = %d, want %d", project.CurrentWordCount, wantWords)
+	}
+
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.Status != entity.JobStatusCompleted || job.TokensPrompt != 120 || job.TokensComplete != 80 {
+		t.Fatalf("job not completed with metrics: %+v", job)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationSettled || r.SettledTokens != 200 {
+		t.Fatalf("reservation = %s/%d, want settled/200", r.Status, r.SettledTokens)
+	}
+	if types := f.jobEventTypes(t); len(types) != 1 || types[0] != entity.JobEventCompleted {
+		t.Fatalf("job events = %v, want [completed]", types)
+	}
+
+	if snapshot == nil || snapshot.Chapter.ContentText != content {
+		t.Fatalf("snapshot missing chapter content")
+	}
+	// 旧正文抽取的事件随正文替换被替代，涉及实体只剩 POV，待抽取方提交新事件
+	if got := strings.Join(snapshot.InvolvedEntities, ","); got != f.relation.SourceEntityID {
+		t.Fatalf("involved entities = %v, want POV only after stale events are superseded", snapshot.InvolvedEntities)
+	}
+	stale, _ := f.events.GetByID(ctx, f.event.ID)
+	if stale.SupersededAt == nil || stale.SupersededBy != nil {
+		t.Fatalf("stale event superseded_at=%v superseded_by=%v, want superseded without successor", stale.SupersededAt, stale.SupersededBy)
+	}
+	if current, _ := f.events.ListByChapter(ctx, f.chapter.ID); len(current) != 0 {
+		t.Fatalf("chapter still has %d current events after regeneration", len(current))
+	}
+	// 关系强化以事件替换时写入的新事件为准，收尾时旧事件可能已过期
+	rel, _ := f.relations.GetByID(ctx, f.relation.ID)
+	if rel.LastReinforcedChapterID != nil {
+		t.Fatalf("relation should not be reinforced before chapter events are replaced")
+	}
+}
+
+func TestGenerationFinalizerCompleteChapterKeepsConcurrentEdits(t *testing.T) {
+	ctx := context.Background()
+	generated := "生成的新正文。"
+
+	cases := []struct {
+		name string
+		edit func(f *finalizerFixture)
+	}{
+		{
+			// 合并窗口内的自动保存不递增版本，以 draft_dirty 与编辑时间识别
+			name: "autosave after job created",
+			edit: func(f *finalizerFixture) {
+				f.chapter.ApplyAutosave("作者的修改。", "user-1", f.job.CreatedAt.Add(time.Second), time.Hour)
+			},
+		},
+		{
+			// 全文保存递增版本号，与任务记录的 base_version 不一致
+			name: "full save bumps version",
+			edit: func(f *finalizerFixture) {
+				f.job.InputParams = []byte(`{"base_version":1}`)
+				f.chapter.ReplaceContent("作者的修改。")
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFinalizerFixture(t)
+			f.chapter.SetContent("旧正文")
+			tc.edit(f)
+			mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+			snapshot, err := f.finalizer.CompleteChapter(ctx, f.job, f.chapter, chapterOutput(generated, 100, 50))
+			mustNoErr(t, err)
+			if snapshot != nil {
+				t.Fatalf("conflicting result should not be indexed")
+			}
+			chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+			if chapter.ContentText != "作者的修改。" || chapter.Status != entity.ChapterStatusReview {
+				t.Fatalf("chapter = %s/%q, want author's edit kept in review", chapter.Status, chapter.ContentText)
+			}
+			candidates, _ := f.candidates.ListByJob(ctx, f.job.ID)
+			if len(candidates) != 1 || candidates[0].Content != generated || candidates[0].Status != entity.CandidateStatusPending {
+				t.Fatalf("generated content should be saved as a pending candidate: %+v", candidates)
+			}
+			job, _ := f.jobs.GetByID(ctx, f.job.ID)
+			if job.Status != entity.JobStatusCompleted || len(job.Warnings) != 1 || job.Warnings[0].Code != entity.JobWarningChapterEdited {
+				t.Fatalf("job = %s with warnings %+v, want completed with chapter_edited", job.Status, job.Warnings)
+			}
+			if r := f.reservationStatus(t); r.Status != entity.QuotaReservationSettled || r.SettledTokens != 150 {
+				t.Fatalf("reservation = %s/%d, want settled/150", r.Status, r.SettledTokens)
+			}
+		})
+	}
+
+	// 任务创建前的自动保存（作者保存后主动重新生成）照常覆盖
+	f := newFinalizerFixture(t)
+	f.chapter.ApplyAutosave("作者的修改。", "user-1", f.job.CreatedAt.Add(-time.Minute), time.Hour)
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+	f.job.InputParams = []byte(fmt.Sprintf(`{"base_version":%d}`, f.chapter.Version))
+	snapshot, err := f.finalizer.CompleteChapter(ctx, f.job, f.chapter, chapterOutput(generated, 100, 50))
+	mustNoErr(t, err)
+	if snapshot == nil || snapshot.Chapter.ContentText != generated {
+		t.Fatalf("edits made before the job was created should be overwritten")
+	}
+}
+
+func TestGenerationFinalizerFailChapter(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	f.chapter.SetContent("旧正文")
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+	cause := &GenerationTimeoutError{Stage: StageChapterGen, Timeout: time.Minute, Err: context.DeadlineExceeded}
+	mustNoErr(t, f.finalizer.FailChapter(ctx, f.job, f.chapter.ID, cause))
+
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.Status != entity.JobStatusFailed || job.ErrorCode != entity.JobErrorTimeout {
+		t.Fatalf("job = %s/%s, want failed/timeout", job.Status, job.ErrorCode)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationReleased {
+		t.Fatalf("reservation status = %s, want released", r.Status)
+	}
+	if types := f.jobEventTypes(t); len(types) != 1 || types[0] != entity.JobEventFailed {
+		t.Fatalf("job events = %v, want [failed]", types)
+	}
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusDraft || chapter.ContentText != "旧正文" {
+		t.Fatalf("chapter = %s/%q, want draft with previous content", chapter.Status, chapter.ContentText)
+	}
+
+	// 释放是幂等的，已结算/释放的预留不会被再次改写
+	mustNoErr(t, f.finalizer.FailChapter(ctx, f.job, f.chapter.ID, errors.New("boom")))
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationReleased {
+		t.Fatalf("reservation status = %s after second failure", r.Status)
+	}
+}
+
+func TestGenerationFinalizerFailChapterKeepsNonGeneratingChapter(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	f.chapter.Status = entity.ChapterStatusCompleted
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+	mustNoErr(t, f.finalizer.FailChapter(ctx, f.job, f.chapter.ID, errors.New("boom")))
+
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.ErrorCode != entity.JobErrorFailed {
+		t.Fatalf("error code = %s, want failed", job.ErrorCode)
+	}
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusCompleted {
+		t.Fatalf("completed chapter was reset to %s", chapter.Status)
+	}
+}
+
+func TestGenerationFinalizerCompleteChapterCandidatesAuto(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	short := "太短。"
+	long := strings.Repeat("山风吹过竹林，少年抬头望向远方。", 20)
+
+	snapshot, err := f.finalizer.CompleteChapterCandidates(ctx, f.job, f.chapter,
+		[]*wfmodel.ChapterGenerateOutput{chapterOutput(short, 100, 10), chapterOutput(long, 100, 300)},
+		entity.CandidateSelectionAuto, len([]rune(long)))
+	mustNoErr(t, err)
+	if snapshot == nil {
+		t.Fatalf("auto selection should return an index snapshot")
+	}
+
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.ContentText != long || chapter.Status != entity.ChapterStatusCompleted {
+		t.Fatalf("best candidate not applied: status=%s", chapter.Status)
+	}
+	candidates, _ := f.candidates.ListByJob(ctx, f.job.ID)
+	if len(candidates) != 2 || candidates[0].Status != entity.CandidateStatusDiscarded || candidates[1].Status != entity.CandidateStatusSelected {
+		t.Fatalf("unexpected candidate statuses: %+v", candidates)
+	}
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.TokensPrompt != 200 || job.TokensComplete != 310 {
+		t.Fatalf("job tokens = %d/%d, want summed across candidates", job.TokensPrompt, job.TokensComplete)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationSettled || r.SettledTokens != 510 {
+		t.Fatalf("reservation = %s/%d, want settled/510", r.Status, r.SettledTokens)
+	}
+	types := f.jobEventTypes(t)
+	if len(types) != 2 || types[0] != entity.JobEventCompleted || types[1] != entity.JobEventSelected {
+		t.Fatalf("job events = %v, want [completed selected]", types)
+	}
+}
+
+func TestGenerationFinalizerManualCandidatesThenSelect(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+
+	snapshot, err := f.finalizer.CompleteChapterCandidates(ctx, f.job, f.chapter,
+		[]*wfmodel.ChapterGenerateOutput{chapterOutput("候选一的正文。", 50, 20), chapterOutput("候选二的正文。", 50, 30)},
+		entity.CandidateSelectionManual, 100)
+	mustNoErr(t, err)
+	if snapshot != nil {
+		t.Fatalf("manual selection should not return an index snapshot")
+	}
+
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusReview || chapter.ContentText != "" {
+		t.Fatalf("chapter = %s/%q, want review without content", chapter.Status, chapter.ContentText)
+	}
+	candidates, _ := f.candidates.ListByJob(ctx, f.job.ID)
+	for _, c := range candidates {
+		if c.Status != entity.CandidateStatusPending {
+			t.Fatalf("candidate %d status = %s, want pending", c.CandidateNo, c.Status)
+		}
+	}
+
+	snapshot, err = f.finalizer.SelectChapterCandidate(ctx, f.job, chapter, candidates[1])
+	mustNoErr(t, err)
+	if snapshot == nil || snapshot.Chapter.ContentText != "候选二的正文。" {
+		t.Fatalf("selected candidate not reflected in snapshot")
+	}
+	chapter, _ = f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusCompleted || chapter.ContentText != "候选二的正文。" {
+		t.Fatalf("chapter = %s/%q after selection", chapter.Status, chapter.ContentText)
+	}
+	candidates, _ = f.candidates.ListByJob(ctx, f.job.ID)
+	if candidates[0].Status != entity.CandidateStatusDiscarded || candidates[1].Status != entity.CandidateStatusSelected {
+		t.Fatalf("unexpected candidate statuses after selection: %s, %s", candidates[0].Status, candidates[1].Status)
+	}
+}
+
+func TestGenerationFinalizerCancelChapterSavesPartial(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	f.chapter.SetContent("旧正文")
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+	// 流中断时模型尚未返回用量，按输出长度估算
+	partial := chapterOutput("林默推开门，风雪扑面而来。", 0, 0)
+	candidate, err := f.finalizer.CancelChapter(ctx, f.job, f.chapter.ID, partial)
+	mustNoErr(t, err)
+	if candidate == nil || !candidate.Partial || candidate.Status != entity.CandidateStatusPending || candidate.CompletionTokens == 0 {
+		t.Fatalf("unexpected partial candidate: %+v", candidate)
+	}
+
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.Status != entity.JobStatusCancelledPartial || !job.IsFinished() {
+		t.Fatalf("job status = %s, want cancelled_partial", job.Status)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationSettled || r.SettledTokens != int64(candidate.CompletionTokens) {
+		t.Fatalf("reservation = %s/%d, want settled/%d", r.Status, r.SettledTokens, candidate.CompletionTokens)
+	}
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusDraft || chapter.ContentText != "旧正文" {
+		t.Fatalf("chapter = %s/%q, want draft with previous content", chapter.Status, chapter.ContentText)
+	}
+
+	got, err := f.finalizer.PartialCandidate(ctx, job)
+	mustNoErr(t, err)
+	if got == nil || got.ID != candidate.ID {
+		t.Fatalf("partial candidate not found for resume")
+	}
+
+	// 原样采用：写入正文但章节保持 draft
+	_, err = f.finalizer.SelectChapterCandidate(ctx, job, chapter, got)
+	mustNoErr(t, err)
+	chapter, _ = f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusDraft || chapter.ContentText != partial.Content {
+		t.Fatalf("chapter = %s/%q after accepting partial content", chapter.Status, chapter.ContentText)
+	}
+	if got, _ := f.finalizer.PartialCandidate(ctx, job); got != nil {
+		t.Fatalf("accepted partial content should no longer be resumable")
+	}
+}
+
+func TestGenerationFinalizerCancelChapterWithoutContent(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+
+	candidate, err := f.finalizer.CancelChapter(ctx, f.job, f.chapter.ID, chapterOutput("  ", 0, 0))
+	mustNoErr(t, err)
+	if candidate != nil {
+		t.Fatalf("empty stream should not save a candidate")
+	}
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.Status != entity.JobStatusCancelled {
+		t.Fatalf("job status = %s, want cancelled", job.Status)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationReleased {
+		t.Fatalf("reservation status = %s, want released", r.Status)
+	}
+	if types := f.jobEventTypes(t); len(types) != 1 || types[0] != entity.JobEventCancelled {
+		t.Fatalf("job events = %v, want [cancelled]", types)
+	}
+}
+
+func TestGenerationFinalizerAbortChapter(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	f.chapter.SetContent("旧正文")
+	mustNoErr(t, f.chapters.Update(ctx, f.chapter))
+
+	// Worker 内存中的任务仍为 running，已消耗的 Token 需随取消一并记录
+	f.job.RecordAttempt(320)
+	mustNoErr(t, f.finalizer.AbortChapter(ctx, f.job, f.chapter.ID))
+
+	job, _ := f.jobs.GetByID(ctx, f.job.ID)
+	if job.Status != entity.JobStatusCancelled || job.SpentTokens != 320 || job.CompletedAt == nil {
+		t.Fatalf("job = %s/%d, want cancelled with spent tokens", job.Status, job.SpentTokens)
+	}
+	if r := f.reservationStatus(t); r.Status != entity.QuotaReservationReleased {
+		t.Fatalf("reservation status = %s, want released", r.Status)
+	}
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.Status != entity.ChapterStatusDraft || chapter.ContentText != "旧正文" {
+		t.Fatalf("chapter = %s/%q, want draft with previous content", chapter.Status, chapter.ContentText)
+	}
+	if types := f.jobEventTypes(t); len(types) != 1 || types[0] != entity.JobEventCancelled {
+		t.Fatalf("job events = %v, want [cancelled]", types)
+	}
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestGenerationFinalizerIndexInvariants 收尾返回的索引快照与实体解耦，提交后按快照写索引
+func TestGenerationFinalizerIndexInvariants(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	vectors := &capturingVectorRepo{inserted: map[string][]*appretrieval.VectorStorySegment{}}
+	finalizer := NewGenerationFinalizer(
+		f.chapters, f.projects, f.jobs, f.events, appretrieval.NewIndexer(constEmbedder{}, vectors, 0),
+		NewJobTimeline(f.jobEvents), nil, f.candidates, duplicate.NewDetector(f.chapters, 0.9), nil,
+	)
+	content := "夜雨敲窗，林默推开了藏经阁的门。"
+
+	snapshot, err := finalizer.CompleteChapter(ctx, f.job, f.chapter, chapterOutput(content, 100, 60))
+	mustNoErr(t, err)
+
+	// 调用方在提交前后继续修改实体（如 SSE 路径复用同一对象）不影响快照
+	f.chapter.ContentText = "被后续逻辑改写的正文"
+	f.chapter.Title = "改名"
+	if snapshot.Chapter == f.chapter || snapshot.Chapter.ContentText != content || snapshot.Chapter.Title != "觉醒" {
+		t.Fatalf("snapshot shares state with the chapter entity: %+v", snapshot.Chapter)
+	}
+
+	// 收尾本身不写索引（事务内不做 Embedding），提交后由调用方按快照写入
+	if len(vectors.inserted) != 0 {
+		t.Fatalf("chapter indexed before commit: %v", vectors.inserted)
+	}
+	mustNoErr(t, finalizer.IndexChapter(ctx, testTenantID, snapshot))
+	chunks := vectors.inserted["chapter"]
+	if len(chunks) == 0 {
+		t.Fatalf("chapter was not indexed")
+	}
+	for _, seg := range chunks {
+		if strings.Contains(seg.TextContent, "被后续逻辑改写") || !strings.Contains(seg.TextContent, "藏经阁") {
+			t.Fatalf("indexed text does not come from the snapshot: %q", seg.TextContent)
+		}
+	}
+
+	// 空快照与未启用向量能力时为空操作
+	mustNoErr(t, finalizer.IndexChapter(ctx, testTenantID, nil))
+	mustNoErr(t, f.finalizer.IndexChapter(ctx, testTenantID, snapshot))
+	var nilFinalizer *GenerationFinalizer
+	mustNoErr(t, nilFinalizer.IndexChapter(ctx, testTenantID, snapshot))
+}
+
+// TestGenerationFinalizerProjectWordCountCoversAllChapters 项目字数按全部章节重新汇总，而非累加本次生成
+func TestGenerationFinalizerProjectWordCountCoversAllChapters(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	other := entity.NewChapter(f.project.ID, f.chapter.VolumeID, 2)
+	other.SetContent("第二章已有的正文。")
+	mustNoErr(t, f.chapters.Create(ctx, other))
+
+	content := "第一章的新正文"
+	_, err := f.finalizer.CompleteChapter(ctx, f.job, f.chapter, chapterOutput(content, 10, 10))
+	mustNoErr(t, err)
+	want := len([]rune(content)) + other.WordCount
+	project, _ := f.projects.GetByID(ctx, f.project.ID)
+	if project.CurrentWordCount != want {
+		t.Fatalf("project word count = %d, want %d", project.CurrentWordCount, want)
+	}
+
+	// 重新生成替换正文时按新正文重算，不重复计入旧正文
+	regen := entity.NewGenerationJob(testTenantID, f.project.ID, entity.JobTypeChapterGen, nil)
+	regen.ChapterID = &f.chapter.ID
+	regen.Start()
+	mustNoErr(t, f.jobs.Create(ctx, regen))
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	chapter.Status = entity.ChapterStatusGenerating
+	mustNoErr(t, f.chapters.Update(ctx, chapter))
+	content = "重写"
+	_, err = f.finalizer.CompleteChapter(ctx, regen, chapter, chapterOutput(content, 10, 10))
+	mustNoErr(t, err)
+	project, _ = f.projects.GetByID(ctx, f.project.ID)
+	if want := len([]rune(content)) + other.WordCount; project.CurrentWordCount != want {
+		t.Fatalf("project word count after regeneration = %d, want %d", project.CurrentWordCount, want)
+	}
+}
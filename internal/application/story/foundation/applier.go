@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/timeline"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 )
@@ -21,6 +23,13 @@ type FoundationApplyResult struct {
 	VolumesUpdated   int `json:"volumes_updated"`
 	ChaptersCreated  int `json:"chapters_created"`
 	ChaptersUpdated  int `json:"chapters_updated"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// NarrativePositionSyncer 卷/章重排后回写向量索引中的章节叙事位置（由 story.ChapterNumbering 实现）
+type NarrativePositionSyncer interface {
+	SyncNarrativePositions(ctx context.Context, tenantID, projectID string)
 }
 
 type FoundationApplier struct {
@@ -29,6 +38,10 @@ type FoundationApplier struct {
 	relationRepo repository.RelationRepository
 	volumeRepo   repository.VolumeRepository
 	chapterRepo  repository.ChapterRepository
+	locker       repository.ProjectLocker
+
+	storyTimeChecker *timeline.StoryTimeValidator
+	narrative        NarrativePositionSyncer
 }
 
 func NewFoundationApplier(
@@ -37,13 +50,19 @@ func NewFoundationApplier(
 	relationRepo repository.RelationRepository,
 	volumeRepo repository.VolumeRepository,
 	chapterRepo repository.ChapterRepository,
+	storyTimeChecker *timeline.StoryTimeValidator,
+	locker repository.ProjectLocker,
+	narrative NarrativePositionSyncer,
 ) *FoundationApplier {
 	return &FoundationApplier{
-		projectRepo:  projectRepo,
-		entityRepo:   entityRepo,
-		relationRepo: relationRepo,
-		volumeRepo:   volumeRepo,
-		chapterRepo:  chapterRepo,
+		projectRepo:      projectRepo,
+		entityRepo:       entityRepo,
+		relationRepo:     relationRepo,
+		volumeRepo:       volumeRepo,
+		chapterRepo:      chapterRepo,
+		storyTimeChecker: storyTimeChecker,
+		locker:           locker,
+		narrative:        narrative,
 	}
 }
 
@@ -52,6 +71,7 @@ func NewFoundationApplier(
 // 约定：
 // - 调用方负责事务边界（HTTP: DBTransaction 中间件；Worker: txMgr.WithTransaction + tenantCtx.SetTenant）。
 // - 默认“追加/幂等”，不做破坏性删除。
+// - 落库前持有项目排他锁，与章节生成任务（共享锁）及重排操作串行。
 func (a *FoundationApplier) Apply(ctx context.Context, projectID string, plan *storymodel.FoundationPlan) (*FoundationApplyResult, error) {
 	if a == nil {
 		return nil, fmt.Errorf("foundation applier not configured")
@@ -63,6 +83,12 @@ func (a *FoundationApplier) Apply(ctx context.Context, projectID string, plan *s
 		return nil, fmt.Errorf("project_id is required")
 	}
 
+	if a.locker != nil {
+		if err := a.locker.LockProject(ctx, projectID); err != nil {
+			return nil, err
+		}
+	}
+
 	project, err := a.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
 		return nil, err
@@ -127,6 +153,16 @@ func (a *FoundationApplier) Apply(ctx context.Context, projectID string, plan *s
 	if err != nil {
 		return nil, err
 	}
+	// 大纲关联：落库前的章节快照，用于检测条目 key 冲突
+	existingChapters, err := a.chapterRepo.ListOutlineLinks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	linked := outline.LinkedChapters(existingChapters)
+	before := make(map[string]*entity.Chapter, len(existingChapters))
+	for _, ch := range existingChapters {
+		before[ch.ID] = ch
+	}
 	volumeIDsInOrder := make([]string, 0, len(plan.Volumes))
 	for i := range plan.Volumes {
 		vp := plan.Volumes[i]
@@ -166,6 +202,7 @@ func (a *FoundationApplier) Apply(ctx context.Context, projectID string, plan *s
 			}
 			if ch != nil {
 				chapterIDsInOrder = append(chapterIDsInOrder, ch.ID)
+				result.Warnings = append(result.Warnings, outlineKeyWarnings(&cp, ch, created, before, linked)...)
 			}
 		}
 
@@ -177,6 +214,26 @@ func (a *FoundationApplier) Apply(ctx context.Context, projectID string, plan *s
 	if err := a.volumeRepo.ReorderVolumes(ctx, projectID, volumeIDsInOrder); err != nil {
 		return nil, err
 	}
+	// 展示序号：卷/章重排后按叙事顺序重新连续编号（已持有项目锁）
+	if err := a.chapterRepo.RefreshDisplayNumbers(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	// 故事时间单调性：重排完成后按叙事顺序整体校验（reject 模式下返回错误，由调用方回滚事务）
+	if a.storyTimeChecker != nil {
+		regressions, err := a.storyTimeChecker.CheckProject(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range regressions {
+			result.Warnings = append(result.Warnings, regressions[i].String())
+		}
+	}
+
+	// 卷/章重排改变了叙事位置：回写已有章节片段的 narrative_pos（失败仅记录日志）
+	if a.narrative != nil {
+		a.narrative.SyncNarrativePositions(ctx, project.TenantID, projectID)
+	}
 
 	return result, nil
 }
@@ -458,9 +515,11 @@ func (a *FoundationApplier) upsertChapter(ctx context.Context, projectID, volume
 		}
 		ch := entity.NewChapter(projectID, volumeID, seqNum)
 		ch.AIKey = key
+		ch.OutlineKey = key
 		ch.Title = strings.TrimSpace(p.Title)
 		ch.Outline = strings.TrimSpace(p.Outline)
 		ch.StoryTimeStart = p.StoryTimeStart
+		ch.IsFlashback = p.IsFlashback
 		ch.Notes = upsertTargetWordCountLine(ch.Notes, p.TargetWordCount)
 		if err := a.chapterRepo.Create(ctx, ch); err != nil {
 			return nil, false, false, err
@@ -473,6 +532,11 @@ func (a *FoundationApplier) upsertChapter(ctx context.Context, projectID, volume
 		existing.AIKey = key
 		updated = true
 	}
+	// 作者手动调整过的关联保持不变，仅为未关联的章节补齐
+	if existing.OutlineKey == "" {
+		existing.OutlineKey = key
+		updated = true
+	}
 	if strings.TrimSpace(volumeID) != "" && existing.VolumeID != volumeID {
 		existing.VolumeID = volumeID
 		if nextSeq != nil {
@@ -493,6 +557,10 @@ func (a *FoundationApplier) upsertChapter(ctx context.Context, projectID, volume
 		existing.StoryTimeStart = p.StoryTimeStart
 		updated = true
 	}
+	if p.IsFlashback && !existing.IsFlashback {
+		existing.IsFlashback = true
+		updated = true
+	}
 
 	if p.TargetWordCount > 0 {
 		nextNotes := upsertTargetWordCountLine(existing.Notes, p.TargetWordCount)
@@ -510,6 +578,22 @@ func (a *FoundationApplier) upsertChapter(ctx context.Context, projectID, volume
 	return existing, false, updated, nil
 }
 
+// outlineKeyWarnings 大纲条目 key 冲突告警：key 同时关联了其他章节，或被复用到标题不同且已有正文的章节
+func outlineKeyWarnings(p *storymodel.ChapterPlan, ch *entity.Chapter, created bool, before map[string]*entity.Chapter, linked map[string][]*entity.Chapter) []string {
+	key := strings.TrimSpace(p.Key)
+	var warnings []string
+	if w := outline.CollisionWarning(key, ch, linked); w != "" {
+		warnings = append(warnings, w)
+	}
+	if prev := before[ch.ID]; !created && prev != nil && prev.WordCount > 0 {
+		if title := strings.TrimSpace(p.Title); title != "" && title != prev.Title {
+			warnings = append(warnings, fmt.Sprintf("outline key %q matched chapter %s (%q, %d words) and retitled it to %q; check that the key was not reused for a different chapter",
+				key, ch.ID, prev.Title, prev.WordCount, title))
+		}
+	}
+	return warnings
+}
+
 func upsertTargetWordCountLine(notes string, target int) string {
 	if target <= 0 {
 		return notes
@@ -0,0 +1,379 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/application/story/timeline"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+const testTenantID = "tenant-1"
+
+type applierFixture struct {
+	store     *memrepo.Store
+	txMgr     *memrepo.TxManager
+	tenantCtx *memrepo.TenantContext
+	projects  *memrepo.ProjectRepository
+	entities  *memrepo.EntityRepository
+	relations *memrepo.RelationRepository
+	volumes   *memrepo.VolumeRepository
+	chapters  *memrepo.ChapterRepository
+	project   *entity.Project
+}
+
+func newApplierFixture(t *testing.T) *applierFixture {
+	t.Helper()
+	store := memrepo.NewStore()
+	f := &applierFixture{
+		store:     store,
+		txMgr:     memrepo.NewTxManager(store),
+		tenantCtx: memrepo.NewTenantContext(store),
+		projects:  memrepo.NewProjectRepository(store),
+		entities:  memrepo.NewEntityRepository(store),
+		relations: memrepo.NewRelationRepository(store),
+		volumes:   memrepo.NewVolumeRepository(store),
+		chapters:  memrepo.NewChapterRepository(store),
+	}
+	f.project = entity.NewProject(testTenantID, "owner-1", "测试项目")
+	if err := f.projects.Create(context.Background(), f.project); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	return f
+}
+
+func (f *applierFixture) applier(mode timeline.CheckMode) *FoundationApplier {
+	return NewFoundationApplier(
+		f.projects, f.entities, f.relations, f.volumes, f.chapters,
+		timeline.NewStoryTimeValidator(f.chapters, mode),
+		memrepo.NewProjectLocker(f.store),
+		nil,
+	)
+}
+
+// apply 按 Worker 路径的约定在事务内设置租户后落库
+func (f *applierFixture) apply(t *testing.T, a *FoundationApplier, tenantID string, plan *storymodel.FoundationPlan) (*FoundationApplyResult, error) {
+	t.Helper()
+	var result *FoundationApplyResult
+	err := f.txMgr.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := f.tenantCtx.SetTenant(ctx, tenantID); err != nil {
+			return err
+		}
+		var err error
+		result, err = a.Apply(ctx, f.project.ID, plan)
+		return err
+	})
+	return result, err
+}
+
+func (f *applierFixture) chapterKeysInOrder(t *testing.T, volumeID string) []string {
+	t.Helper()
+	chapters, err := f.chapters.ListByVolume(context.Background(), volumeID)
+	if err != nil {
+		t.Fatalf("list chapters: %v", err)
+	}
+	keys := make([]string, 0, len(chapters))
+	for _, ch := range chapters {
+		keys = append(keys, ch.AIKey)
+	}
+	return keys
+}
+
+func samplePlan() *storymodel.FoundationPlan {
+	return &storymodel.FoundationPlan{
+		Version: 1,
+		Project: storymodel.ProjectPlan{
+			Genre:           "玄幻",
+			TargetWordCount: 300000,
+			WritingStyle:    "简洁",
+			POV:             "third",
+			WorldBible:      "灵气复苏的近未来。",
+		},
+		Entities: []storymodel.EntityPlan{
+			{Key: "hero", Name: "林默", Type: entity.EntityTypeCharacter, Importance: entity.ImportanceProtagonist, Aliases: []string{"阿默", "阿默"}},
+			{Key: "mentor", Name: "老周", Type: entity.EntityTypeCharacter},
+		},
+		Relations: []storymodel.RelationPlan{
+			{SourceKey: "hero", TargetKey: "mentor", RelationType: entity.RelationTypeMentor, Strength: 0.8},
+		},
+		Volumes: []storymodel.VolumePlan{
+			{Key: "v1", Title: "第一卷", Chapters: []storymodel.ChapterPlan{
+				{Key: "c1", Title: "觉醒", Outline: "主角觉醒", TargetWordCount: 3000, StoryTimeStart: 100},
+				{Key: "c2", Title: "拜师", Outline: "拜入门下", StoryTimeStart: 200},
+			}},
+			{Key: "v2", Title: "第二卷", Chapters: []storymodel.ChapterPlan{
+				{Key: "c3", Title: "出山", Outline: "下山历练", StoryTimeStart: 300},
+			}},
+		},
+	}
+}
+
+func TestFoundationApplierApplyCreatesPlan(t *testing.T) {
+	f := newApplierFixture(t)
+	ctx := context.Background()
+
+	result, err := f.apply(t, f.applier(timeline.CheckModeWarn), testTenantID, samplePlan())
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !result.ProjectUpdated || result.EntitiesCreated != 2 || result.RelationsCreated != 1 ||
+		result.VolumesCreated != 2 || result.ChaptersCreated != 3 || len(result.Warnings) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	project, _ := f.projects.GetByID(ctx, f.project.ID)
+	if project.Genre != "玄幻" || project.TargetWordCount != 300000 || project.Settings.WritingStyle != "简洁" {
+		t.Fatalf("project plan not applied: %+v", project)
+	}
+	if !strings.Contains(project.Description, "灵气复苏的近未来。") {
+		t.Fatalf("world bible not merged into description: %q", project.Description)
+	}
+
+	hero, _ := f.entities.GetByAIKey(ctx, f.project.ID, "hero")
+	mentor, _ := f.entities.GetByAIKey(ctx, f.project.ID, "mentor")
+	if hero == nil || mentor == nil {
+		t.Fatalf("entities not created")
+	}
+	if len(hero.Aliases) != 1 || hero.Importance != entity.ImportanceProtagonist {
+		t.Fatalf("hero not mapped: aliases=%v importance=%s", hero.Aliases, hero.Importance)
+	}
+	if mentor.Importance != entity.ImportanceSecondary {
+		t.Fatalf("default importance = %s, want secondary", mentor.Importance)
+	}
+	rel, _ := f.relations.GetByEntitiesAndType(ctx, f.project.ID, hero.ID, mentor.ID, entity.RelationTypeMentor)
+	if rel == nil || rel.Strength != 0.8 {
+		t.Fatalf("relation not created: %+v", rel)
+	}
+
+	volumes, _ := f.volumes.ListByProject(ctx, f.project.ID)
+	if len(volumes) != 2 || volumes[0].AIKey != "v1" || volumes[0].SeqNum != 1 || volumes[1].SeqNum != 2 {
+		t.Fatalf("unexpected volumes: %+v", volumes)
+	}
+	if got := f.chapterKeysInOrder(t, volumes[0].ID); strings.Join(got, ",") != "c1,c2" {
+		t.Fatalf("volume 1 chapters = %v", got)
+	}
+	c1, _ := f.chapters.GetByAIKey(ctx, f.project.ID, "c1")
+	if c1.Notes != "target_word_count: 3000" || c1.StoryTimeStart != 100 {
+		t.Fatalf("chapter plan not applied: notes=%q story_time=%d", c1.Notes, c1.StoryTimeStart)
+	}
+}
+
+func TestFoundationApplierApplyIsIdempotent(t *testing.T) {
+	f := newApplierFixture(t)
+	a := f.applier(timeline.CheckModeWarn)
+
+	if _, err := f.apply(t, a, testTenantID, samplePlan()); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+	result, err := f.apply(t, a, testTenantID, samplePlan())
+	if err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+	want := FoundationApplyResult{}
+	if result.ProjectUpdated != want.ProjectUpdated ||
+		result.EntitiesCreated+result.EntitiesUpdated+result.RelationsCreated+result.RelationsUpdated != 0 ||
+		result.VolumesCreated+result.VolumesUpdated+result.ChaptersCreated+result.ChaptersUpdated != 0 {
+		t.Fatalf("re-applying the same plan changed data: %+v", result)
+	}
+
+	stats, _ := f.projects.GetStats(context.Background(), f.project.ID)
+	if stats.TotalEntities != 2 || stats.TotalVolumes != 2 || stats.TotalChapters != 3 {
+		t.Fatalf("duplicate rows after re-apply: %+v", stats)
+	}
+}
+
+func TestFoundationApplierApplyReordersByPlan(t *testing.T) {
+	f := newApplierFixture(t)
+	a := f.applier(timeline.CheckModeOff)
+	ctx := context.Background()
+
+	if _, err := f.apply(t, a, testTenantID, samplePlan()); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+
+	// 调换卷顺序，并把 c3 移到第一卷开头
+	plan := samplePlan()
+	v1, v2 := plan.Volumes[0], plan.Volumes[1]
+	v1.Chapters = append([]storymodel.ChapterPlan{v2.Chapters[0]}, v1.Chapters...)
+	v2.Chapters = nil
+	plan.Volumes = []storymodel.VolumePlan{v2, v1}
+
+	result, err := f.apply(t, a, testTenantID, plan)
+	if err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+	if result.ChaptersUpdated != 1 {
+		t.Fatalf("chapters updated = %d, want 1", result.ChaptersUpdated)
+	}
+
+	volumes, _ := f.volumes.ListByProject(ctx, f.project.ID)
+	if volumes[0].AIKey != "v2" || volumes[1].AIKey != "v1" {
+		t.Fatalf("volumes not reordered: %s, %s", volumes[0].AIKey, volumes[1].AIKey)
+	}
+	if got := f.chapterKeysInOrder(t, volumes[1].ID); strings.Join(got, ",") != "c3,c1,c2" {
+		t.Fatalf("chapters not reordered: %v", got)
+	}
+	for i, ch := range mustListByVolume(t, f.chapters, volumes[1].ID) {
+		if ch.SeqNum != i+1 {
+			t.Fatalf("chapter %s seq = %d, want %d", ch.AIKey, ch.SeqNum, i+1)
+		}
+	}
+}
+
+func TestFoundationApplierRejectModeRollsBack(t *testing.T) {
+	f := newApplierFixture(t)
+	plan := samplePlan()
+	plan.Volumes[0].Chapters[1].StoryTimeStart = 50 // 早于上一章，形成倒退
+
+	_, err := f.apply(t, f.applier(timeline.CheckModeReject), testTenantID, plan)
+	var regression timeline.RegressionError
+	if !errors.As(err, &regression) {
+		t.Fatalf("err = %v, want RegressionError", err)
+	}
+
+	stats, _ := f.projects.GetStats(context.Background(), f.project.ID)
+	if stats.TotalEntities != 0 || stats.TotalVolumes != 0 || stats.TotalChapters != 0 {
+		t.Fatalf("rejected apply was not rolled back: %+v", stats)
+	}
+	project, _ := f.projects.GetByID(context.Background(), f.project.ID)
+	if project.Genre != "" {
+		t.Fatalf("project update was not rolled back: genre=%q", project.Genre)
+	}
+}
+
+// recordingNarrativeSyncer 记录叙事位置回写请求
+type recordingNarrativeSyncer struct {
+	calls []string
+}
+
+func (s *recordingNarrativeSyncer) SyncNarrativePositions(_ context.Context, tenantID, projectID string) {
+	s.calls = append(s.calls, tenantID+"/"+projectID)
+}
+
+func TestFoundationApplierSyncsNarrativePositionsAfterReorder(t *testing.T) {
+	f := newApplierFixture(t)
+	syncer := &recordingNarrativeSyncer{}
+	a := f.applier(timeline.CheckModeReject)
+	a.narrative = syncer
+
+	if _, err := f.apply(t, a, testTenantID, samplePlan()); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if want := testTenantID + "/" + f.project.ID; len(syncer.calls) != 1 || syncer.calls[0] != want {
+		t.Fatalf("sync calls = %v, want [%s]", syncer.calls, want)
+	}
+
+	// 校验失败回滚时不回写
+	plan := samplePlan()
+	plan.Volumes[0].Chapters[1].StoryTimeStart = 50
+	if _, err := f.apply(t, a, testTenantID, plan); err == nil {
+		t.Fatal("expected regression error")
+	}
+	if len(syncer.calls) != 1 {
+		t.Fatalf("sync called on rejected apply: %v", syncer.calls)
+	}
+}
+
+func TestFoundationApplierWarnModeReportsRegressions(t *testing.T) {
+	f := newApplierFixture(t)
+	plan := samplePlan()
+	plan.Volumes[0].Chapters[1].StoryTimeStart = 50
+
+	result, err := f.apply(t, f.applier(timeline.CheckModeWarn), testTenantID, plan)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("warnings = %v, want one regression", result.Warnings)
+	}
+}
+
+func TestFoundationApplierRejectsInvalidPlans(t *testing.T) {
+	f := newApplierFixture(t)
+	a := f.applier(timeline.CheckModeOff)
+
+	plan := samplePlan()
+	plan.Relations[0].TargetKey = "missing"
+	if _, err := f.apply(t, a, testTenantID, plan); err == nil || !strings.Contains(err.Error(), "target_key not found") {
+		t.Fatalf("err = %v, want unknown target_key", err)
+	}
+
+	plan = samplePlan()
+	if _, err := f.apply(t, a, testTenantID, plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	plan.Entities[1].Type = entity.EntityTypeLocation
+	if _, err := f.apply(t, a, testTenantID, plan); err == nil || !strings.Contains(err.Error(), "type mismatch") {
+		t.Fatalf("err = %v, want entity type mismatch", err)
+	}
+}
+
+func TestFoundationApplierScopedToTenant(t *testing.T) {
+	f := newApplierFixture(t)
+
+	_, err := f.apply(t, f.applier(timeline.CheckModeOff), "tenant-2", samplePlan())
+	if err == nil || !strings.Contains(err.Error(), "project not found") {
+		t.Fatalf("err = %v, want project not found for another tenant", err)
+	}
+}
+
+func TestFoundationApplierRequiresTransactionForLock(t *testing.T) {
+	f := newApplierFixture(t)
+
+	if _, err := f.applier(timeline.CheckModeOff).Apply(context.Background(), f.project.ID, samplePlan()); err == nil {
+		t.Fatalf("expected lock error outside transaction")
+	}
+}
+
+func mustListByVolume(t *testing.T, repo repository.ChapterRepository, volumeID string) []*entity.Chapter {
+	t.Helper()
+	chapters, err := repo.ListByVolume(context.Background(), volumeID)
+	if err != nil {
+		t.Fatalf("list chapters: %v", err)
+	}
+	return chapters
+}
+
+func TestFoundationApplierWarnsOnOutlineKeyCollisions(t *testing.T) {
+	f := newApplierFixture(t)
+	a := f.applier(timeline.CheckModeOff)
+	ctx := context.Background()
+
+	if _, err := f.apply(t, a, testTenantID, samplePlan()); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+	c1, _ := f.chapters.GetByAIKey(ctx, f.project.ID, "c1")
+	c2, _ := f.chapters.GetByAIKey(ctx, f.project.ID, "c2")
+	if c1.OutlineKey != "c1" || c2.OutlineKey != "c2" {
+		t.Fatalf("outline keys not stored: %q, %q", c1.OutlineKey, c2.OutlineKey)
+	}
+	c1.SetContent("林默睁开眼。")
+	if err := f.chapters.Update(ctx, c1); err != nil {
+		t.Fatalf("update c1: %v", err)
+	}
+	// 作者手动把 c2 关联到 c3 条目：落库不覆盖手动关联，但 c3 条目出现两个章节
+	c2.OutlineKey = "c3"
+	if err := f.chapters.Update(ctx, c2); err != nil {
+		t.Fatalf("update c2: %v", err)
+	}
+
+	plan := samplePlan()
+	plan.Volumes[0].Chapters[0].Title = "重生"
+	result, err := f.apply(t, a, testTenantID, plan)
+	if err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+	if len(result.Warnings) != 2 ||
+		!strings.Contains(result.Warnings[0], `outline key "c1" matched chapter`) ||
+		!strings.Contains(result.Warnings[1], `outline key "c3" is also linked to chapter(s) `+c2.ID) {
+		t.Fatalf("unexpected warnings: %v", result.Warnings)
+	}
+	if c2, _ = f.chapters.GetByAIKey(ctx, f.project.ID, "c2"); c2.OutlineKey != "c3" {
+		t.Fatalf("manual outline link overwritten: %q", c2.OutlineKey)
+	}
+}
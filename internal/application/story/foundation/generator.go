@@ -30,6 +30,14 @@ func NewFoundationGenerator(factory workflowport.ChatModelFactory) *FoundationGe
 	}
 }
 
+// Warmup 预热设定集生成工作流（编译生成链）
+func (g *FoundationGenerator) Warmup(ctx context.Context) error {
+	if g == nil || g.chain == nil {
+		return fmt.Errorf("foundation workflow not configured")
+	}
+	return g.chain.Warmup(ctx)
+}
+
 func (g *FoundationGenerator) Generate(ctx context.Context, in *wfmodel.FoundationGenerateInput) (*FoundationGenerateOutput, error) {
 	if g == nil || g.chain == nil {
 		return nil, fmt.Errorf("foundation workflow not configured")
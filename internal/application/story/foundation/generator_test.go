@@ -0,0 +1,40 @@
+package foundation
+
+import (
+	"context"
+	"testing"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// TestFoundationGeneratorWithMockProvider 离线 mock 提供商产出可通过校验的设定集
+func TestFoundationGeneratorWithMockProvider(t *testing.T) {
+	cfg := &config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "mock",
+		Providers: map[string]config.ProviderConfig{
+			"mock": {Type: config.ProviderTypeMock, Model: "mock-v1", MaxTokens: 8192},
+		},
+	}}
+	g := NewFoundationGenerator(llm.NewEinoFactory(cfg))
+
+	out, err := g.Generate(context.Background(), &wfmodel.FoundationGenerateInput{
+		ProjectTitle: "雾港纪事",
+		Prompt:       "港城雾季，失踪的灯塔看守人留下一本航海日志。",
+		Provider:     "mock",
+	})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if out.Plan == nil || len(out.Plan.Entities) == 0 || len(out.Plan.Volumes) == 0 {
+		t.Fatalf("plan missing entities or volumes: %+v", out.Plan)
+	}
+	if err := ValidateFoundationPlan(out.Plan, entity.FoundationPlanLimits{}); err != nil {
+		t.Fatalf("mock plan should validate: %v", err)
+	}
+	if out.Meta.PromptTokens <= 0 || out.Meta.CompletionTokens <= 0 {
+		t.Fatalf("usage meta = %+v", out.Meta)
+	}
+}
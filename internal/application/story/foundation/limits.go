@@ -0,0 +1,46 @@
+package foundation
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// PlanLimits 设定集规模上限：全局配置叠加租户覆盖（TenantSettings.FoundationPlanLimits）
+type PlanLimits struct {
+	defaults   entity.FoundationPlanLimits
+	tenantRepo repository.TenantRepository
+}
+
+// NewPlanLimits 创建设定集规模上限解析器
+func NewPlanLimits(defaults entity.FoundationPlanLimits, tenantRepo repository.TenantRepository) *PlanLimits {
+	return &PlanLimits{defaults: defaults, tenantRepo: tenantRepo}
+}
+
+// Defaults 全局配置的上限（nil 时不限制）
+func (p *PlanLimits) Defaults() entity.FoundationPlanLimits {
+	if p == nil {
+		return entity.FoundationPlanLimits{}
+	}
+	return p.defaults
+}
+
+// For 已加载租户生效的上限
+func (p *PlanLimits) For(tenant *entity.Tenant) entity.FoundationPlanLimits {
+	return p.Defaults().Override(tenant.FoundationPlanLimits())
+}
+
+// ForTenant 按租户 ID 读取生效的上限；读取租户失败时回退为全局配置
+func (p *PlanLimits) ForTenant(ctx context.Context, tenantID string) entity.FoundationPlanLimits {
+	if p == nil || p.tenantRepo == nil || tenantID == "" {
+		return p.Defaults()
+	}
+	tenant, err := p.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Warn(ctx, "failed to load tenant foundation plan limits", "error", err.Error(), "tenant_id", tenantID)
+		return p.defaults
+	}
+	return p.For(tenant)
+}
@@ -0,0 +1,77 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func sizedPlan(volumes, chaptersPerVolume int, outline string) *storymodel.FoundationPlan {
+	plan := &storymodel.FoundationPlan{Version: 1}
+	for v := 0; v < volumes; v++ {
+		vol := storymodel.VolumePlan{Key: fmt.Sprintf("v%d", v), Title: fmt.Sprintf("第%d卷", v+1)}
+		for c := 0; c < chaptersPerVolume; c++ {
+			vol.Chapters = append(vol.Chapters, storymodel.ChapterPlan{
+				Key:     fmt.Sprintf("v%d-c%d", v, c),
+				Title:   fmt.Sprintf("第%d章", c+1),
+				Outline: outline,
+			})
+		}
+		plan.Volumes = append(plan.Volumes, vol)
+	}
+	return plan
+}
+
+func TestValidateFoundationPlanSizeLimits(t *testing.T) {
+	limits := entity.FoundationPlanLimits{MaxVolumes: 3, MaxChapters: 10, MaxOutlineChars: 100}
+
+	if err := ValidateFoundationPlan(sizedPlan(2, 5, "大纲"), limits); err != nil {
+		t.Fatalf("plan within limits rejected: %v", err)
+	}
+
+	// 规模超限时直接返回，不展开逐项校验
+	err := ValidateFoundationPlan(sizedPlan(5, 3, strings.Repeat("字", 10)), limits)
+	var tooLarge FoundationPlanTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected too large error, got %v", err)
+	}
+	want := []string{"volumes: 5 exceeds limit 3", "chapters: 15 exceeds limit 10", "outline_chars: 150 exceeds limit 100"}
+	if strings.Join(tooLarge.Issues, "; ") != strings.Join(want, "; ") {
+		t.Fatalf("unexpected issues: %v", tooLarge.Issues)
+	}
+
+	// 0 表示不限制
+	if err := ValidateFoundationPlan(sizedPlan(5, 3, "大纲"), entity.FoundationPlanLimits{}); err != nil {
+		t.Fatalf("unlimited plan rejected: %v", err)
+	}
+}
+
+func TestPlanLimitsTenantOverride(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	tenants := memrepo.NewTenantRepository(store)
+
+	tenant := entity.NewTenant("epic", "epic")
+	tenant.Settings = &entity.TenantSettings{FoundationPlanLimits: &entity.FoundationPlanLimits{MaxChapters: 2000}}
+	if err := tenants.Create(ctx, tenant); err != nil {
+		t.Fatal(err)
+	}
+	plain := entity.NewTenant("plain", "plain")
+	if err := tenants.Create(ctx, plain); err != nil {
+		t.Fatal(err)
+	}
+
+	limits := NewPlanLimits(entity.FoundationPlanLimits{MaxVolumes: 30, MaxChapters: 600}, tenants)
+	if got := limits.ForTenant(ctx, tenant.ID); got.MaxChapters != 2000 || got.MaxVolumes != 30 {
+		t.Fatalf("unexpected override: %+v", got)
+	}
+	if got := limits.ForTenant(ctx, plain.ID); got.MaxChapters != 600 {
+		t.Fatalf("unexpected defaults: %+v", got)
+	}
+}
@@ -20,12 +20,25 @@ func (e FoundationPlanValidationError) Error() string {
 	return "foundation plan validation failed: " + strings.Join(e.Issues, "; ")
 }
 
+// FoundationPlanTooLargeError 设定集规模超出上限（先于逐项校验返回，不再展开海量条目的问题列表）
+type FoundationPlanTooLargeError struct {
+	Issues []string
+}
+
+func (e FoundationPlanTooLargeError) Error() string {
+	return "foundation plan too large: " + strings.Join(e.Issues, "; ")
+}
+
 // ValidateFoundationPlan 对 FoundationPlan 做强约束校验，避免脏数据落库。
-func ValidateFoundationPlan(plan *storymodel.FoundationPlan) error {
+// 规模超出 limits 任一维度时返回 FoundationPlanTooLargeError，其余问题返回 FoundationPlanValidationError。
+func ValidateFoundationPlan(plan *storymodel.FoundationPlan, limits entity.FoundationPlanLimits) error {
 	var issues []string
 	if plan == nil {
 		return FoundationPlanValidationError{Issues: []string{"plan is nil"}}
 	}
+	if sizeIssues := checkPlanSize(plan, limits); len(sizeIssues) > 0 {
+		return FoundationPlanTooLargeError{Issues: sizeIssues}
+	}
 
 	if plan.Version <= 0 {
 		issues = append(issues, "version must be positive")
@@ -192,6 +205,30 @@ func ValidateFoundationPlan(plan *storymodel.FoundationPlan) error {
 	return nil
 }
 
+// checkPlanSize 按上限检查规划规模（0 表示该维度不限制）
+func checkPlanSize(plan *storymodel.FoundationPlan, limits entity.FoundationPlanLimits) []string {
+	chapters, outlineChars := 0, 0
+	for i := range plan.Volumes {
+		chapters += len(plan.Volumes[i].Chapters)
+		for j := range plan.Volumes[i].Chapters {
+			outlineChars += utf8.RuneCountInString(plan.Volumes[i].Chapters[j].Outline)
+		}
+	}
+
+	var issues []string
+	check := func(name string, n, limit int) {
+		if limit > 0 && n > limit {
+			issues = append(issues, fmt.Sprintf("%s: %d exceeds limit %d", name, n, limit))
+		}
+	}
+	check("entities", len(plan.Entities), limits.MaxEntities)
+	check("relations", len(plan.Relations), limits.MaxRelations)
+	check("volumes", len(plan.Volumes), limits.MaxVolumes)
+	check("chapters", chapters, limits.MaxChapters)
+	check("outline_chars", outlineChars, limits.MaxOutlineChars)
+	return issues
+}
+
 func isValidEntityType(t entity.StoryEntityType) bool {
 	switch t {
 	case entity.EntityTypeCharacter,
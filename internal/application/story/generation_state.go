@@ -0,0 +1,296 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// generationConsistencyBatch 每个租户每轮最多检查的章节 / 活跃任务数
+const generationConsistencyBatch = 200
+
+// ChapterStatusForJob 章节生成任务状态到章节状态的状态机（两者唯一的对应关系，收尾与巡检共用）：
+//
+//	pending / running                        -> generating
+//	completed（selection=auto 或单候选）       -> completed
+//	completed（selection=manual）             -> review（候选待选）
+//	failed / cancelled / cancelled_partial   -> draft（部分内容保存为候选，不写入正文）
+//
+// 任务完成后章节可被用户继续编辑或改选候选，因此只有 generating 是必须与活跃任务一一对应的状态。
+func ChapterStatusForJob(status entity.JobStatus, selection entity.CandidateSelection) entity.ChapterStatus {
+	switch status {
+	case entity.JobStatusPending, entity.JobStatusRunning:
+		return entity.ChapterStatusGenerating
+	case entity.JobStatusCompleted:
+		if selection == entity.CandidateSelectionManual {
+			return entity.ChapterStatusReview
+		}
+		return entity.ChapterStatusCompleted
+	default:
+		return entity.ChapterStatusDraft
+	}
+}
+
+// JobCandidateSelection 读取章节生成任务结果中的候选选择方式（未记录时视为 auto）
+func JobCandidateSelection(job *entity.GenerationJob) entity.CandidateSelection {
+	if job == nil || len(job.OutputResult) == 0 {
+		return entity.CandidateSelectionAuto
+	}
+	var out struct {
+		Selection entity.CandidateSelection `json:"selection"`
+	}
+	if err := json.Unmarshal(job.OutputResult, &out); err != nil || !out.Selection.IsValid() {
+		return entity.CandidateSelectionAuto
+	}
+	return out.Selection
+}
+
+// JobBaseChapterVersion 读取章节生成任务创建时记录的章节版本（input_params.base_version，未记录时 ok=false）
+func JobBaseChapterVersion(job *entity.GenerationJob) (version int, ok bool) {
+	if job == nil || len(job.InputParams) == 0 {
+		return 0, false
+	}
+	var in struct {
+		BaseVersion *int `json:"base_version"`
+	}
+	if err := json.Unmarshal(job.InputParams, &in); err != nil || in.BaseVersion == nil {
+		return 0, false
+	}
+	return *in.BaseVersion, true
+}
+
+// GenerationIssueKind 任务与章节状态不一致的类型
+type GenerationIssueKind string
+
+const (
+	// GenerationIssueStaleGenerating 章节处于 generating，但没有排队或执行中的生成任务（Worker 崩溃、收尾事务失败等）；
+	// 修复为最近一次任务按状态机对应的状态
+	GenerationIssueStaleGenerating GenerationIssueKind = "stale_generating"
+	// GenerationIssueActiveJobNotGenerating 存在排队或执行中的生成任务，但章节不处于 generating（或已删除）；
+	// 仅报告，任务收尾时会按状态机写入章节状态
+	GenerationIssueActiveJobNotGenerating GenerationIssueKind = "active_job_not_generating"
+)
+
+// GenerationIssue 一条不一致记录
+type GenerationIssue struct {
+	Kind           GenerationIssueKind
+	ProjectID      string
+	ChapterID      string
+	JobID          string
+	JobStatus      entity.JobStatus
+	ChapterStatus  entity.ChapterStatus
+	ExpectedStatus entity.ChapterStatus
+	Repaired       bool
+}
+
+// GenerationConsistencyReport 单个租户的巡检结果
+type GenerationConsistencyReport struct {
+	TenantID  string
+	CheckedAt time.Time
+	Issues    []GenerationIssue
+}
+
+// Repaired 已修复的不一致数
+func (r *GenerationConsistencyReport) Repaired() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Repaired {
+			n++
+		}
+	}
+	return n
+}
+
+// GenerationConsistency 任务记录与章节状态的不变量巡检：按 ChapterStatusForJob 对账，
+// 修复卡在 generating 的章节并报告其余不一致。只检查最近更新早于 grace 的记录，避开正在进行中的状态切换。
+type GenerationConsistency struct {
+	chapterRepo repository.ChapterRepository
+	jobRepo     repository.JobRepository
+	tenantRepo  repository.TenantRepository
+	txMgr       repository.Transactor
+	tenantCtx   repository.TenantContextManager
+
+	grace time.Duration
+	now   func() time.Time
+}
+
+// NewGenerationConsistency 创建任务/章节状态巡检服务
+func NewGenerationConsistency(
+	chapterRepo repository.ChapterRepository,
+	jobRepo repository.JobRepository,
+	tenantRepo repository.TenantRepository,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	grace time.Duration,
+) *GenerationConsistency {
+	return &GenerationConsistency{
+		chapterRepo: chapterRepo,
+		jobRepo:     jobRepo,
+		tenantRepo:  tenantRepo,
+		txMgr:       txMgr,
+		tenantCtx:   tenantCtx,
+		grace:       grace,
+		now:         time.Now,
+	}
+}
+
+// Run 周期性巡检全部租户并修复，直至 ctx 取消；发现的不一致记录日志
+func (s *GenerationConsistency) Run(ctx context.Context, interval time.Duration) {
+	if s == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sweep(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(ctx, "generation consistency sweep failed", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *GenerationConsistency) sweep(ctx context.Context) error {
+	pagination := repository.NewPagination(1, 100)
+	for {
+		result, err := s.tenantRepo.List(ctx, pagination)
+		if err != nil {
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+		for _, tenant := range result.Items {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			report, err := s.Reconcile(ctx, tenant.ID, true)
+			if err != nil {
+				logger.Warn(ctx, "generation consistency check failed", "error", err.Error(), "tenant_id", tenant.ID)
+				continue
+			}
+			for _, issue := range report.Issues {
+				logger.Warn(ctx, "generation state inconsistency",
+					"tenant_id", tenant.ID,
+					"kind", string(issue.Kind),
+					"chapter_id", issue.ChapterID,
+					"job_id", issue.JobID,
+					"chapter_status", string(issue.ChapterStatus),
+					"expected_status", string(issue.ExpectedStatus),
+					"repaired", issue.Repaired,
+				)
+			}
+		}
+		if pagination.Page >= result.TotalPages {
+			return nil
+		}
+		pagination = repository.NewPagination(pagination.Page+1, pagination.PageSize)
+	}
+}
+
+// Reconcile 在租户事务内检查任务与章节状态是否符合状态机；repair=true 时修复卡在 generating 的章节
+func (s *GenerationConsistency) Reconcile(ctx context.Context, tenantID string, repair bool) (*GenerationConsistencyReport, error) {
+	now := s.now()
+	report := &GenerationConsistencyReport{TenantID: tenantID, CheckedAt: now}
+	cutoff := now.Add(-s.grace)
+
+	err := s.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.tenantCtx.SetTenant(txCtx, tenantID); err != nil {
+			return err
+		}
+		if err := s.checkStaleGenerating(txCtx, report, cutoff, repair); err != nil {
+			return err
+		}
+		return s.checkActiveJobs(txCtx, report, cutoff)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *GenerationConsistency) checkStaleGenerating(ctx context.Context, report *GenerationConsistencyReport, cutoff time.Time, repair bool) error {
+	chapters, err := s.chapterRepo.ListByStatus(ctx, entity.ChapterStatusGenerating, cutoff, generationConsistencyBatch)
+	if err != nil {
+		return err
+	}
+	for _, ch := range chapters {
+		active, err := s.jobRepo.GetActiveByChapter(ctx, ch.ID)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			continue
+		}
+		issue := GenerationIssue{
+			Kind:           GenerationIssueStaleGenerating,
+			ProjectID:      ch.ProjectID,
+			ChapterID:      ch.ID,
+			ChapterStatus:  ch.Status,
+			ExpectedStatus: entity.ChapterStatusDraft,
+		}
+		latest, err := s.jobRepo.GetLatestByChapter(ctx, ch.ID)
+		if err != nil {
+			return err
+		}
+		if latest != nil {
+			issue.JobID = latest.ID
+			issue.JobStatus = latest.Status
+			issue.ExpectedStatus = ChapterStatusForJob(latest.Status, JobCandidateSelection(latest))
+		}
+		// 任务已完成但正文未写入（收尾中断）：没有可展示的结果，回退为 draft
+		if issue.ExpectedStatus == entity.ChapterStatusCompleted && ch.WordCount == 0 {
+			issue.ExpectedStatus = entity.ChapterStatusDraft
+		}
+		if repair {
+			if err := s.chapterRepo.UpdateStatus(ctx, ch.ID, issue.ExpectedStatus); err != nil {
+				return err
+			}
+			issue.Repaired = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return nil
+}
+
+func (s *GenerationConsistency) checkActiveJobs(ctx context.Context, report *GenerationConsistencyReport, cutoff time.Time) error {
+	running, err := s.jobRepo.GetRunningJobs(ctx)
+	if err != nil {
+		return err
+	}
+	pending, err := s.jobRepo.GetPendingJobs(ctx, generationConsistencyBatch)
+	if err != nil {
+		return err
+	}
+	for _, job := range append(running, pending...) {
+		if job.JobType != entity.JobTypeChapterGen || job.ChapterID == nil || !job.CreatedAt.Before(cutoff) {
+			continue
+		}
+		ch, err := s.chapterRepo.GetByID(ctx, *job.ChapterID)
+		if err != nil {
+			return err
+		}
+		if ch != nil && ch.Status == entity.ChapterStatusGenerating {
+			continue
+		}
+		issue := GenerationIssue{
+			Kind:           GenerationIssueActiveJobNotGenerating,
+			ProjectID:      job.ProjectID,
+			ChapterID:      *job.ChapterID,
+			JobID:          job.ID,
+			JobStatus:      job.Status,
+			ExpectedStatus: ChapterStatusForJob(job.Status, ""),
+		}
+		if ch != nil {
+			issue.ChapterStatus = ch.Status
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package story
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestGenerationConsistencyRepairsStaleGenerating(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	chapters := memrepo.NewChapterRepository(store)
+	jobs := memrepo.NewJobRepository(store)
+	tenants := memrepo.NewTenantRepository(store)
+	projects := memrepo.NewProjectRepository(store)
+
+	now := time.Now()
+	stale := now.Add(-time.Hour)
+	store.Now = func() time.Time { return stale }
+
+	tenant := entity.NewTenant("t", "t")
+	mustNoErr(t, tenants.Create(ctx, tenant))
+	project := entity.NewProject(tenant.ID, "owner-1", "测试项目")
+	mustNoErr(t, projects.Create(ctx, project))
+
+	newChapter := func(seq int, status entity.ChapterStatus, content string) *entity.Chapter {
+		ch := entity.NewChapter(project.ID, "", seq)
+		ch.Status = status
+		if content != "" {
+			ch.ReplaceContent(content)
+		}
+		ch.UpdatedAt = store.Now()
+		mustNoErr(t, chapters.Create(ctx, ch))
+		return ch
+	}
+	newJob := func(ch *entity.Chapter, createdAt time.Time, apply func(*entity.GenerationJob)) *entity.GenerationJob {
+		job := entity.NewGenerationJob(tenant.ID, project.ID, entity.JobTypeChapterGen, nil)
+		job.ChapterID = &ch.ID
+		job.CreatedAt = createdAt
+		if apply != nil {
+			apply(job)
+		}
+		mustNoErr(t, jobs.Create(ctx, job))
+		return job
+	}
+
+	orphan := newChapter(1, entity.ChapterStatusGenerating, "")
+	manual := newChapter(2, entity.ChapterStatusGenerating, "")
+	newJob(manual, stale.Add(-time.Minute), func(j *entity.GenerationJob) { j.Fail("boom") })
+	newJob(manual, stale, func(j *entity.GenerationJob) { j.Complete([]byte(`{"selection":"manual"}`)) })
+	written := newChapter(3, entity.ChapterStatusGenerating, "正文已经写入")
+	newJob(written, stale, func(j *entity.GenerationJob) { j.Complete([]byte(`{"word_count":6}`)) })
+	running := newChapter(4, entity.ChapterStatusGenerating, "")
+	newJob(running, stale, func(j *entity.GenerationJob) { j.Start() })
+	drifted := newChapter(5, entity.ChapterStatusDraft, "")
+	pending := newJob(drifted, stale, nil)
+
+	// 宽限期内刚进入 generating 的章节不检查
+	store.Now = func() time.Time { return now }
+	fresh := newChapter(6, entity.ChapterStatusGenerating, "")
+
+	gc := NewGenerationConsistency(chapters, jobs, tenants, memrepo.NewTxManager(store), memrepo.NewTenantContext(store), 10*time.Minute)
+	gc.now = func() time.Time { return now }
+
+	want := map[string]entity.ChapterStatus{
+		orphan.ID:  entity.ChapterStatusDraft,
+		manual.ID:  entity.ChapterStatusReview,
+		written.ID: entity.ChapterStatusCompleted,
+	}
+
+	// 仅报告：不修改章节
+	report, err := gc.Reconcile(ctx, tenant.ID, false)
+	mustNoErr(t, err)
+	if len(report.Issues) != 4 || report.Repaired() != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	for _, issue := range report.Issues {
+		switch issue.Kind {
+		case GenerationIssueStaleGenerating:
+			if want[issue.ChapterID] != issue.ExpectedStatus {
+				t.Fatalf("chapter %s: expected %s, got %s", issue.ChapterID, want[issue.ChapterID], issue.ExpectedStatus)
+			}
+		case GenerationIssueActiveJobNotGenerating:
+			if issue.JobID != pending.ID || issue.ChapterStatus != entity.ChapterStatusDraft {
+				t.Fatalf("unexpected active job issue: %+v", issue)
+			}
+		}
+	}
+
+	report, err = gc.Reconcile(ctx, tenant.ID, true)
+	mustNoErr(t, err)
+	if report.Repaired() != 3 {
+		t.Fatalf("expected 3 repairs, got %+v", report)
+	}
+	for id, status := range want {
+		ch, err := chapters.GetByID(ctx, id)
+		mustNoErr(t, err)
+		if ch.Status != status {
+			t.Fatalf("chapter %s: expected %s, got %s", id, status, ch.Status)
+		}
+	}
+	for _, id := range []string{running.ID, fresh.ID} {
+		ch, err := chapters.GetByID(ctx, id)
+		mustNoErr(t, err)
+		if ch.Status != entity.ChapterStatusGenerating {
+			t.Fatalf("chapter %s should stay generating, got %s", id, ch.Status)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// 生成超时阶段（与 story.generation_timeouts 的键一致）
+const (
+	StageChapterGen    = "chapter_gen"
+	StageFoundationGen = "foundation_gen"
+	StageArtifactGen   = "artifact_gen"
+	StageConflictScan  = "conflict_scan"
+	StageTitleSuggest  = "title_suggest"
+)
+
+// GenerationTimeoutError 生成调用超过配置的时限
+type GenerationTimeoutError struct {
+	Stage   string
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *GenerationTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Stage, e.Timeout)
+}
+
+func (e *GenerationTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// WithGenerationTimeout 以截止时间包住一次生成调用（timeout <= 0 时不限制）。
+// 返回的 finish 须在调用结束（流式读取完毕）后调用：释放计时器，并在截止时间触发导致失败时
+// 把错误转换为 GenerationTimeoutError；上层上下文自身取消或到期的不视为本阶段超时。finish 可重复调用。
+func WithGenerationTimeout(ctx context.Context, stage string, timeout time.Duration) (context.Context, func(err error) error) {
+	if timeout <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	genCtx, cancel := context.WithTimeout(ctx, timeout)
+	return genCtx, func(err error) error {
+		timedOut := errors.Is(genCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+		if err == nil || !timedOut {
+			return err
+		}
+		var te *GenerationTimeoutError
+		if errors.As(err, &te) {
+			return err
+		}
+		return &GenerationTimeoutError{Stage: stage, Timeout: timeout, Err: err}
+	}
+}
+
+// JobErrorCode 按失败原因归类任务错误码
+func JobErrorCode(err error) entity.JobErrorCode {
+	// 成本上限包裹了最后一次失败的原因，优先判定
+	if IsCostCeilingExceeded(err) {
+		return entity.JobErrorCostCeiling
+	}
+	var te *GenerationTimeoutError
+	if errors.As(err, &te) {
+		return entity.JobErrorTimeout
+	}
+	var ip *InvalidJobPayloadError
+	if errors.As(err, &ip) {
+		return entity.JobErrorInvalidPayload
+	}
+	var exceeded quota.TokenBalanceExceededError
+	if errors.As(err, &exceeded) {
+		return entity.JobErrorQuotaExceeded
+	}
+	return entity.JobErrorFailed
+}
+
+// InvalidJobPayloadError Worker 收到的任务消息载荷校验失败：任务直接失败，不再重试
+type InvalidJobPayloadError struct {
+	Err error
+}
+
+func (e *InvalidJobPayloadError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidJobPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// IsGenerationTimeout 是否为生成超时
+func IsGenerationTimeout(err error) bool {
+	var te *GenerationTimeoutError
+	return errors.As(err, &te)
+}
@@ -0,0 +1,374 @@
+// Package health 汇总项目健康度：把分散在大纲、时间轴、构件、任务与配额中的待处理问题按严重程度集中呈现，
+// 让作者一眼看到“需要处理什么”，而不是逐章发现问题。
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/timeline"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// Severity 严重程度
+type Severity string
+
+const (
+	SeverityOK       Severity = "ok"
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Max 取两者中更严重的一个
+func (s Severity) Max(other Severity) Severity {
+	if other.rank() > s.rank() {
+		return other
+	}
+	if s == "" {
+		return SeverityOK
+	}
+	return s
+}
+
+// 检查项
+const (
+	// CheckOutlineDrift 章节与激活大纲的偏离（关联了大纲中不存在的条目、未关联或重复关联）
+	CheckOutlineDrift = "outline_drift"
+	// CheckOpenOutlineItems 未落地的大纲条目：已写到更靠后的条目，但之前的条目仍无章节（遗留剧情线）
+	CheckOpenOutlineItems = "open_outline_items"
+	// CheckOutlineStale 关联的大纲条目在新激活的大纲中已变化（outline_stale），正文可能已偏离新大纲
+	CheckOutlineStale = "outline_stale"
+	// CheckTimeline 故事时间倒退
+	CheckTimeline = "timeline"
+	// CheckConflictWarnings 激活构件版本上仍未处理的设定冲突警告
+	CheckConflictWarnings = "conflict_warnings"
+	// CheckStaleArtifacts 上游构件在下游构件激活版本之后又有更新
+	CheckStaleArtifacts = "stale_artifacts"
+	// CheckFailedJobs 近期失败且未重试的生成任务
+	CheckFailedJobs = "failed_jobs"
+	// CheckQuota 租户 Token 余额
+	CheckQuota = "quota"
+)
+
+// MaxIssuesPerCheck 每个检查项返回的问题明细上限（Count 仍为总数）
+const MaxIssuesPerCheck = 20
+
+// FailedJobWindow 失败任务的统计窗口
+const FailedJobWindow = 7 * 24 * time.Hour
+
+// QuotaWarningRatio 可用余额低于套餐月度额度的该比例时告警
+const QuotaWarningRatio = 0.1
+
+// Issue 单个问题
+type Issue struct {
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	ChapterID  string   `json:"chapter_id,omitempty"`
+	OutlineKey string   `json:"outline_key,omitempty"`
+	ArtifactID string   `json:"artifact_id,omitempty"`
+	VersionID  string   `json:"version_id,omitempty"`
+	JobID      string   `json:"job_id,omitempty"`
+}
+
+// Check 单个检查项结果
+type Check struct {
+	Key      string   `json:"key"`
+	Severity Severity `json:"severity"`
+	Summary  string   `json:"summary"`
+	Count    int      `json:"count"`
+	// Score 检查项的量化指标（outline_drift：偏离大纲的章节占比；quota：可用余额占月度额度比例）
+	Score  *float64 `json:"score,omitempty"`
+	Issues []Issue  `json:"issues"`
+	// Unavailable 数据源读取失败，本项未评估（不参与整体状态）
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// Report 项目健康度报告
+type Report struct {
+	ProjectID string `json:"project_id"`
+	// Status 所有检查项中最严重的级别
+	Status      Severity  `json:"status"`
+	Checks      []Check   `json:"checks"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// NewReport 汇总检查项；整体状态取最严重的级别
+func NewReport(projectID string, checks []Check, now time.Time) *Report {
+	status := SeverityOK
+	for _, c := range checks {
+		if !c.Unavailable {
+			status = status.Max(c.Severity)
+		}
+	}
+	return &Report{ProjectID: projectID, Status: status, Checks: checks, GeneratedAt: now}
+}
+
+// newCheck 按问题明细生成检查项：级别取最严重的问题，明细按严重程度降序截断
+func newCheck(key, summary string, issues []Issue) Check {
+	severity := SeverityOK
+	for _, is := range issues {
+		severity = severity.Max(is.Severity)
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Severity.rank() > issues[j].Severity.rank() })
+	count := len(issues)
+	if len(issues) > MaxIssuesPerCheck {
+		issues = issues[:MaxIssuesPerCheck]
+	}
+	if issues == nil {
+		issues = []Issue{}
+	}
+	return Check{Key: key, Severity: severity, Summary: summary, Count: count, Issues: issues}
+}
+
+// Unavailable 数据源读取失败时的占位检查项
+func Unavailable(key string) Check {
+	return Check{Key: key, Severity: SeverityOK, Summary: "check could not be evaluated", Issues: []Issue{}, Unavailable: true}
+}
+
+// OutlineDrift 章节与激活大纲的偏离：关联了大纲中不存在的条目（warning）、同一条目关联多章（warning）、
+// 未关联任何条目（info）；chapterCount 为项目章节总数
+func OutlineDrift(cov *outline.Coverage, chapterCount int) Check {
+	if cov == nil || cov.OutlineVersionID == "" {
+		return newCheck(CheckOutlineDrift, "project has no active outline", nil)
+	}
+	var issues []Issue
+	drifted := make(map[string]struct{})
+	for _, o := range cov.Orphans {
+		drifted[o.ChapterID] = struct{}{}
+		if o.OutlineKey == "" {
+			issues = append(issues, Issue{Severity: SeverityInfo, ChapterID: o.ChapterID,
+				Message: fmt.Sprintf("chapter %q is not linked to any outline item", o.Title)})
+			continue
+		}
+		issues = append(issues, Issue{Severity: SeverityWarning, ChapterID: o.ChapterID, OutlineKey: o.OutlineKey,
+			Message: fmt.Sprintf("chapter %q is linked to outline item %q which is not in the active outline", o.Title, o.OutlineKey)})
+	}
+	for _, it := range cov.Items {
+		for _, dup := range it.DuplicateChapterIDs {
+			drifted[dup] = struct{}{}
+			issues = append(issues, Issue{Severity: SeverityWarning, ChapterID: dup, OutlineKey: it.Key,
+				Message: fmt.Sprintf("outline item %q is linked to more than one chapter", it.Key)})
+		}
+	}
+
+	check := newCheck(CheckOutlineDrift, fmt.Sprintf("%d of %d chapters drift from the active outline", len(drifted), chapterCount), issues)
+	score := 0.0
+	if chapterCount > 0 {
+		score = round2(float64(len(drifted)) / float64(chapterCount))
+	}
+	check.Score = &score
+	return check
+}
+
+// OpenOutlineItems 已写到更靠后的大纲条目、但之前仍无章节的条目（跳过的剧情线）；
+// 最后一个已落地条目之后的条目属于尚未写到的进度，不计入
+func OpenOutlineItems(cov *outline.Coverage) Check {
+	if cov == nil {
+		return newCheck(CheckOpenOutlineItems, "no open outline items", nil)
+	}
+	last := -1
+	for i, it := range cov.Items {
+		if it.Status != outline.ItemStatusMissing {
+			last = i
+		}
+	}
+	var issues []Issue
+	for _, it := range cov.Items[:last+1] {
+		if it.Status == outline.ItemStatusMissing {
+			issues = append(issues, Issue{Severity: SeverityWarning, OutlineKey: it.Key,
+				Message: fmt.Sprintf("outline item %q (%s) was skipped: later items already have chapters", it.Key, it.Title)})
+		}
+	}
+	return newCheck(CheckOpenOutlineItems, fmt.Sprintf("%d outline items left open behind the writing position", len(issues)), issues)
+}
+
+// OutlineStale 大纲过期的章节：已有正文的为 warning（需按新大纲重新生成或确认），尚无正文的为 info
+func OutlineStale(chapters []*entity.Chapter) Check {
+	var issues []Issue
+	for _, ch := range chapters {
+		if ch == nil || !ch.OutlineStale {
+			continue
+		}
+		severity := SeverityWarning
+		if ch.WordCount == 0 {
+			severity = SeverityInfo
+		}
+		msg := fmt.Sprintf("outline item %q of chapter %q changed in the active outline", ch.OutlineKey, ch.Title)
+		if ch.OutlineStaleSince != nil {
+			msg += " on " + ch.OutlineStaleSince.UTC().Format(time.RFC3339)
+		}
+		issues = append(issues, Issue{Severity: severity, ChapterID: ch.ID, OutlineKey: ch.OutlineKey, Message: msg})
+	}
+	return newCheck(CheckOutlineStale, fmt.Sprintf("%d chapters are stale against the active outline", len(issues)), issues)
+}
+
+// Timeline 故事时间倒退（未标记为回忆的章节早于前文）
+func Timeline(regressions []timeline.Regression) Check {
+	issues := make([]Issue, 0, len(regressions))
+	for _, r := range regressions {
+		issues = append(issues, Issue{Severity: SeverityWarning, ChapterID: r.ChapterID, Message: r.String()})
+	}
+	return newCheck(CheckTimeline, fmt.Sprintf("%d story time regressions", len(issues)), issues)
+}
+
+// conflictTurnMeta 助手轮次 metadata 中的冲突警告（与 ConversationHandler 写入的结构一致）
+type conflictTurnMeta struct {
+	ArtifactID       string `json:"artifact_id"`
+	VersionID        string `json:"version_id"`
+	ConflictWarnings []struct {
+		Severity   string `json:"severity"`
+		Message    string `json:"message"`
+		Suggestion string `json:"suggestion"`
+	} `json:"conflict_warnings"`
+}
+
+// conflictSeverity 冲突检查的 high/medium/low 映射为健康度级别
+func conflictSeverity(s string) Severity {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "high":
+		return SeverityCritical
+	case "medium":
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// ConflictWarnings 激活版本上记录的设定冲突警告：版本仍处于激活状态即视为未处理（生成并激活新版本后自动消除）
+func ConflictWarnings(turns []*entity.ConversationTurn) Check {
+	var issues []Issue
+	seen := make(map[string]struct{})
+	for _, t := range turns {
+		if t == nil {
+			continue
+		}
+		var meta conflictTurnMeta
+		if err := json.Unmarshal(t.Metadata, &meta); err != nil {
+			continue
+		}
+		for _, w := range meta.ConflictWarnings {
+			msg := strings.TrimSpace(w.Message)
+			if msg == "" {
+				continue
+			}
+			key := meta.VersionID + "\x00" + msg
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			if s := strings.TrimSpace(w.Suggestion); s != "" {
+				msg += " (suggestion: " + s + ")"
+			}
+			issues = append(issues, Issue{Severity: conflictSeverity(w.Severity), ArtifactID: meta.ArtifactID, VersionID: meta.VersionID, Message: msg})
+		}
+	}
+	return newCheck(CheckConflictWarnings, fmt.Sprintf("%d unresolved setting conflicts on active artifact versions", len(issues)), issues)
+}
+
+// artifactUpstream 构件依赖：下游构件生成时以上游构件为输入
+var artifactUpstream = map[entity.ArtifactType][]entity.ArtifactType{
+	entity.ArtifactTypeWorldview:  {entity.ArtifactTypeNovelFoundation},
+	entity.ArtifactTypeCharacters: {entity.ArtifactTypeNovelFoundation, entity.ArtifactTypeWorldview},
+	entity.ArtifactTypeOutline:    {entity.ArtifactTypeNovelFoundation, entity.ArtifactTypeWorldview, entity.ArtifactTypeCharacters},
+}
+
+// ActiveArtifact 构件及其激活版本
+type ActiveArtifact struct {
+	Artifact *entity.ProjectArtifact
+	Version  *entity.ArtifactVersion
+}
+
+// StaleArtifacts 上游构件的激活版本晚于下游构件激活版本时，下游构件可能已与设定不一致
+func StaleArtifacts(active map[entity.ArtifactType]ActiveArtifact) Check {
+	types := make([]entity.ArtifactType, 0, len(artifactUpstream))
+	for t := range artifactUpstream {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	var issues []Issue
+	for _, t := range types {
+		down, ok := active[t]
+		if !ok || down.Version == nil {
+			continue
+		}
+		var newer []string
+		for _, up := range artifactUpstream[t] {
+			if u, ok := active[up]; ok && u.Version != nil && u.Version.CreatedAt.After(down.Version.CreatedAt) {
+				newer = append(newer, string(up))
+			}
+		}
+		if len(newer) == 0 {
+			continue
+		}
+		issues = append(issues, Issue{Severity: SeverityWarning, ArtifactID: down.Artifact.ID, VersionID: down.Version.ID,
+			Message: fmt.Sprintf("%s was last activated before %s changed; regenerate or review it", t, strings.Join(newer, ", "))})
+	}
+	return newCheck(CheckStaleArtifacts, fmt.Sprintf("%d artifacts are older than their upstream settings", len(issues)), issues)
+}
+
+// FailedJobs since 之后失败且尚未重试的任务
+func FailedJobs(jobs []*entity.GenerationJob, since time.Time) Check {
+	var issues []Issue
+	for _, j := range jobs {
+		if j == nil || j.Status != entity.JobStatusFailed || j.UpdatedAt.Before(since) {
+			continue
+		}
+		msg := fmt.Sprintf("%s job failed", j.JobType)
+		if e := strings.TrimSpace(j.ErrorMessage); e != "" {
+			msg += ": " + e
+		}
+		is := Issue{Severity: SeverityWarning, JobID: j.ID, Message: msg}
+		if j.ChapterID != nil {
+			is.ChapterID = *j.ChapterID
+		}
+		issues = append(issues, is)
+	}
+	return newCheck(CheckFailedJobs, fmt.Sprintf("%d jobs failed in the last %d days", len(issues), int(FailedJobWindow.Hours()/24)), issues)
+}
+
+// Quota 租户 Token 余额：耗尽为 critical，低于月度额度的 QuotaWarningRatio 为 warning（未关联套餐时只检查是否耗尽）
+func Quota(tenant *entity.Tenant, plan *entity.Plan) Check {
+	if tenant == nil {
+		return Unavailable(CheckQuota)
+	}
+	var issues []Issue
+	var score *float64
+	if plan != nil && plan.MonthlyTokens > 0 {
+		ratio := round2(float64(tenant.TokenBalance) / float64(plan.MonthlyTokens))
+		score = &ratio
+	}
+	switch {
+	case tenant.TokenBalance <= 0:
+		issues = append(issues, Issue{Severity: SeverityCritical, Message: "token balance is exhausted; generation is blocked until the quota resets or the plan changes"})
+	case score != nil && *score < QuotaWarningRatio:
+		issues = append(issues, Issue{Severity: SeverityWarning,
+			Message: fmt.Sprintf("token balance is low: %d of %d monthly tokens left", tenant.TokenBalance, plan.MonthlyTokens)})
+	}
+	check := newCheck(CheckQuota, fmt.Sprintf("%d tokens available", tenant.TokenBalance), issues)
+	check.Score = score
+	return check
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+func TestOutlineChecks(t *testing.T) {
+	volumes := []storymodel.VolumePlan{{
+		Key: "v1",
+		Chapters: []storymodel.ChapterPlan{
+			{Key: "c1", Title: "启程"},
+			{Key: "c2", Title: "渡河"},
+			{Key: "c3", Title: "入城"},
+			{Key: "c4", Title: "终章"},
+		},
+	}}
+	chapters := []*entity.Chapter{
+		{ID: "ch1", OutlineKey: "c1", WordCount: 3000},
+		{ID: "ch3", OutlineKey: "c3"},
+		{ID: "ch3b", OutlineKey: "c3"},
+		{ID: "chx", OutlineKey: "deleted", Title: "旧支线"},
+	}
+	cov := outline.Build(volumes, chapters)
+	cov.OutlineVersionID = "ov1"
+
+	open := OpenOutlineItems(cov)
+	if open.Count != 1 || open.Issues[0].OutlineKey != "c2" || open.Severity != SeverityWarning {
+		t.Fatalf("only c2 is skipped behind the writing position, got %+v", open)
+	}
+
+	drift := OutlineDrift(cov, len(chapters))
+	if drift.Count != 2 || drift.Score == nil || *drift.Score != 0.5 {
+		t.Fatalf("orphan and duplicate link should drift, got %+v", drift)
+	}
+
+	if none := OutlineDrift(&outline.Coverage{}, 3); none.Severity != SeverityOK || none.Score != nil {
+		t.Fatalf("projects without an outline have no drift, got %+v", none)
+	}
+}
+
+func TestOutlineStale(t *testing.T) {
+	since := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	chapters := []*entity.Chapter{
+		{ID: "ch1", OutlineKey: "c1", WordCount: 3000, OutlineStale: true, OutlineStaleSince: &since},
+		{ID: "ch2", OutlineKey: "c2", OutlineStale: true},
+		{ID: "ch3", OutlineKey: "c3", WordCount: 2000},
+	}
+	check := OutlineStale(chapters)
+	if check.Count != 2 || check.Severity != SeverityWarning {
+		t.Fatalf("two stale chapters expected, got %+v", check)
+	}
+	// 已有正文的章节排在前面（warning），尚无正文的为 info
+	if check.Issues[0].ChapterID != "ch1" || check.Issues[1].Severity != SeverityInfo {
+		t.Fatalf("unexpected issues: %+v", check.Issues)
+	}
+
+	if none := OutlineStale(chapters[2:]); none.Severity != SeverityOK || none.Count != 0 {
+		t.Fatalf("no stale chapters expected, got %+v", none)
+	}
+}
+
+func TestArtifactChecks(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	active := map[entity.ArtifactType]ActiveArtifact{
+		entity.ArtifactTypeNovelFoundation: {Artifact: &entity.ProjectArtifact{ID: "a0"}, Version: &entity.ArtifactVersion{ID: "v0", CreatedAt: t0}},
+		entity.ArtifactTypeWorldview:       {Artifact: &entity.ProjectArtifact{ID: "a1"}, Version: &entity.ArtifactVersion{ID: "v1", CreatedAt: t0.Add(2 * time.Hour)}},
+		entity.ArtifactTypeOutline:         {Artifact: &entity.ProjectArtifact{ID: "a3"}, Version: &entity.ArtifactVersion{ID: "v3", CreatedAt: t0.Add(time.Hour)}},
+	}
+	stale := StaleArtifacts(active)
+	if stale.Count != 1 || stale.Issues[0].ArtifactID != "a3" {
+		t.Fatalf("outline predates the worldview change, got %+v", stale)
+	}
+
+	meta, _ := json.Marshal(map[string]any{
+		"artifact_id": "a1",
+		"version_id":  "v1",
+		"conflict_warnings": []map[string]string{
+			{"severity": "high", "message": "主角年龄与设定矛盾"},
+			{"severity": "low", "message": "地名写法不一致"},
+		},
+	})
+	turn := &entity.ConversationTurn{Role: entity.RoleAssistant, Metadata: meta}
+	conflicts := ConflictWarnings([]*entity.ConversationTurn{turn, turn})
+	if conflicts.Count != 2 || conflicts.Severity != SeverityCritical || conflicts.Issues[0].VersionID != "v1" {
+		t.Fatalf("high conflicts should be critical and deduplicated, got %+v", conflicts)
+	}
+}
+
+func TestReportStatus(t *testing.T) {
+	now := time.Now()
+	chapterID := "ch1"
+	jobs := []*entity.GenerationJob{
+		{ID: "j1", JobType: entity.JobTypeChapterGen, Status: entity.JobStatusFailed, ChapterID: &chapterID, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "j0", JobType: entity.JobTypeChapterGen, Status: entity.JobStatusFailed, UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+	}
+	failed := FailedJobs(jobs, now.Add(-FailedJobWindow))
+	if failed.Count != 1 || failed.Issues[0].ChapterID != chapterID {
+		t.Fatalf("only recent failures count, got %+v", failed)
+	}
+
+	low := Quota(&entity.Tenant{TokenBalance: 50}, &entity.Plan{MonthlyTokens: 1000})
+	if low.Severity != SeverityWarning || *low.Score != 0.05 {
+		t.Fatalf("balance under 10%% should warn, got %+v", low)
+	}
+	if empty := Quota(&entity.Tenant{TokenBalance: 0}, nil); empty.Severity != SeverityCritical {
+		t.Fatalf("exhausted balance should be critical, got %+v", empty)
+	}
+
+	report := NewReport("p1", []Check{failed, low, {Key: CheckTimeline, Severity: SeverityCritical, Unavailable: true}}, now)
+	if report.Status != SeverityWarning {
+		t.Fatalf("unavailable checks must not affect status, got %s", report.Status)
+	}
+}
@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/timeline"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// failedJobScanLimit 失败任务最多扫描的条数（按创建时间倒序）
+const failedJobScanLimit = 100
+
+// Service 项目健康度服务
+type Service struct {
+	chapterRepo  repository.ChapterRepository
+	artifactRepo repository.ArtifactRepository
+	turnRepo     repository.ConversationTurnRepository
+	jobRepo      repository.JobRepository
+	planService  *quota.PlanService
+}
+
+// NewService 创建项目健康度服务
+func NewService(
+	chapterRepo repository.ChapterRepository,
+	artifactRepo repository.ArtifactRepository,
+	turnRepo repository.ConversationTurnRepository,
+	jobRepo repository.JobRepository,
+	planService *quota.PlanService,
+) *Service {
+	return &Service{
+		chapterRepo:  chapterRepo,
+		artifactRepo: artifactRepo,
+		turnRepo:     turnRepo,
+		jobRepo:      jobRepo,
+		planService:  planService,
+	}
+}
+
+// Check 评估项目健康度。单个数据源读取失败只将对应检查项标记为 unavailable，不影响其余检查项
+func (s *Service) Check(ctx context.Context, tenantID, projectID string, now time.Time) *Report {
+	checks := make([]Check, 0, 8)
+
+	chapters, err := s.chapterRepo.ListOutlineLinks(ctx, projectID)
+	if err != nil {
+		logger.Warn(ctx, "health: failed to list chapters", "error", err.Error(), "project_id", projectID)
+	}
+	versionID, volumes, outlineErr := outline.Active(ctx, s.artifactRepo, projectID)
+	if outlineErr != nil {
+		logger.Warn(ctx, "health: failed to load active outline", "error", outlineErr.Error(), "project_id", projectID)
+	}
+	if err != nil || outlineErr != nil {
+		checks = append(checks, Unavailable(CheckOutlineDrift), Unavailable(CheckOpenOutlineItems))
+	} else {
+		cov := outline.Build(volumes, chapters)
+		cov.OutlineVersionID = versionID
+		checks = append(checks, OutlineDrift(cov, len(chapters)), OpenOutlineItems(cov))
+	}
+	if err != nil {
+		checks = append(checks, Unavailable(CheckOutlineStale))
+	} else {
+		checks = append(checks, OutlineStale(chapters))
+	}
+
+	if ordered, err := s.chapterRepo.ListTimeline(ctx, projectID); err != nil {
+		logger.Warn(ctx, "health: failed to list chapter timeline", "error", err.Error(), "project_id", projectID)
+		checks = append(checks, Unavailable(CheckTimeline))
+	} else {
+		checks = append(checks, Timeline(timeline.FindRegressions(ordered)))
+	}
+
+	checks = append(checks, s.artifactChecks(ctx, projectID)...)
+	checks = append(checks, s.failedJobs(ctx, projectID, now))
+	checks = append(checks, s.quota(ctx, tenantID))
+	return NewReport(projectID, checks, now)
+}
+
+// artifactChecks 基于各构件激活版本的冲突警告与过期检查
+func (s *Service) artifactChecks(ctx context.Context, projectID string) []Check {
+	artifacts, err := s.artifactRepo.ListArtifactsByProject(ctx, projectID)
+	if err != nil {
+		logger.Warn(ctx, "health: failed to list artifacts", "error", err.Error(), "project_id", projectID)
+		return []Check{Unavailable(CheckConflictWarnings), Unavailable(CheckStaleArtifacts)}
+	}
+
+	active := make(map[entity.ArtifactType]ActiveArtifact, len(artifacts))
+	versionIDs := make([]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		if a == nil || a.ActiveVersionID == nil {
+			continue
+		}
+		version, err := s.artifactRepo.GetVersionByID(ctx, *a.ActiveVersionID)
+		if err != nil {
+			logger.Warn(ctx, "health: failed to get active artifact version", "error", err.Error(), "artifact_id", a.ID)
+			return []Check{Unavailable(CheckConflictWarnings), Unavailable(CheckStaleArtifacts)}
+		}
+		if version == nil {
+			continue
+		}
+		active[a.Type] = ActiveArtifact{Artifact: a, Version: version}
+		versionIDs = append(versionIDs, version.ID)
+	}
+
+	conflicts := Unavailable(CheckConflictWarnings)
+	if turns, err := s.turnRepo.ListConflictWarnings(ctx, versionIDs); err != nil {
+		logger.Warn(ctx, "health: failed to list conflict warnings", "error", err.Error(), "project_id", projectID)
+	} else {
+		conflicts = ConflictWarnings(turns)
+	}
+	return []Check{conflicts, StaleArtifacts(active)}
+}
+
+func (s *Service) failedJobs(ctx context.Context, projectID string, now time.Time) Check {
+	result, err := s.jobRepo.ListByProject(ctx, projectID, &repository.JobFilter{Status: entity.JobStatusFailed}, repository.NewPagination(1, failedJobScanLimit))
+	if err != nil {
+		logger.Warn(ctx, "health: failed to list failed jobs", "error", err.Error(), "project_id", projectID)
+		return Unavailable(CheckFailedJobs)
+	}
+	return FailedJobs(result.Items, now.Add(-FailedJobWindow))
+}
+
+func (s *Service) quota(ctx context.Context, tenantID string) Check {
+	tenant, plan, err := s.planService.TenantPlan(ctx, tenantID)
+	if err != nil {
+		logger.Warn(ctx, "health: failed to get tenant plan", "error", err.Error(), "tenant_id", tenantID)
+		return Unavailable(CheckQuota)
+	}
+	return Quota(tenant, plan)
+}
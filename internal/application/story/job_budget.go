@@ -0,0 +1,72 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/service"
+)
+
+// spendSettleTimeout 尝试结束后等待流式回调完成计量的最长时间
+const spendSettleTimeout = 2 * time.Second
+
+// CostCeilingExceededError 任务累计 Token 消耗达到单任务上限：本次失败即终态，不再重试
+type CostCeilingExceededError struct {
+	Ceiling     int64
+	SpentTokens int64
+	Attempts    int
+	Err         error
+}
+
+func (e *CostCeilingExceededError) Error() string {
+	return fmt.Sprintf("job cost ceiling reached: %d tokens spent over %d attempts (ceiling %d); last error: %v",
+		e.SpentTokens, e.Attempts, e.Ceiling, e.Err)
+}
+
+func (e *CostCeilingExceededError) Unwrap() error {
+	return e.Err
+}
+
+// IsCostCeilingExceeded 是否因累计消耗达到上限而终止
+func IsCostCeilingExceeded(err error) bool {
+	var ce *CostCeilingExceededError
+	return errors.As(err, &ce)
+}
+
+// JobBudget 单任务成本上限：跨重试累计每次尝试的 Token 消耗（含修复轮次与提供商切换），
+// 达到上限后的下一次失败转为终态（error_code=cost_ceiling_exceeded）。
+type JobBudget struct {
+	ceiling int64
+}
+
+// NewJobBudget 创建单任务成本上限；ceiling <= 0 表示不限制（仍累计消耗）
+func NewJobBudget(ceiling int64) *JobBudget {
+	return &JobBudget{ceiling: ceiling}
+}
+
+// Track 为一次执行尝试挂载 Token 计量器与用量归属。返回的 finish 须在尝试结束（流式读取完毕）后调用一次：
+// 将本次尝试计入 job.Attempts / job.SpentTokens，并在尝试失败且累计消耗达到上限时把错误转换为 CostCeilingExceededError。
+func (b *JobBudget) Track(ctx context.Context, job *entity.GenerationJob) (context.Context, func(err error) error) {
+	meter := service.NewSpendMeter()
+	ctx = service.WithSpendMeter(ctx, meter)
+	if job != nil {
+		// 本次尝试内的 LLM 调用归属到任务，便于按项目 / 任务类型查询用量流水
+		ctx = service.WithUsageAttribution(ctx, service.UsageAttribution{ProjectID: job.ProjectID, JobID: job.ID, JobType: string(job.JobType)})
+	}
+	return ctx, func(err error) error {
+		meter.Wait(spendSettleTimeout)
+		job.RecordAttempt(meter.Total())
+		if err == nil || job == nil || b == nil || b.ceiling <= 0 || job.SpentTokens < b.ceiling {
+			return err
+		}
+		return &CostCeilingExceededError{
+			Ceiling:     b.ceiling,
+			SpentTokens: job.SpentTokens,
+			Attempts:    job.Attempts,
+			Err:         err,
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/service"
+)
+
+func TestJobBudgetAccumulatesAcrossAttempts(t *testing.T) {
+	budget := NewJobBudget(1000)
+	job := entity.NewGenerationJob(testTenantID, "project-1", entity.JobTypeChapterGen, nil)
+	errUpstream := errors.New("upstream 502")
+
+	// 第一次尝试：一次生成 + 一轮修复，失败但未达上限，保留原错误以便重试
+	ctx, finish := budget.Track(context.Background(), job)
+	service.SpendMeterFromContext(ctx).Add(300, 100)
+	service.SpendMeterFromContext(ctx).Add(150, 50)
+	if err := finish(errUpstream); !errors.Is(err, errUpstream) || IsCostCeilingExceeded(err) {
+		t.Fatalf("first failure should stay retryable, got %v", err)
+	}
+	if job.Attempts != 1 || job.SpentTokens != 600 {
+		t.Fatalf("attempts=%d spent=%d, want 1 and 600", job.Attempts, job.SpentTokens)
+	}
+
+	// 第二次尝试：流式调用结束后计量，累计达到上限，失败转为终态
+	ctx, finish = budget.Track(context.Background(), job)
+	done := service.SpendMeterFromContext(ctx).BeginStream()
+	go done(200, 300)
+	err := finish(errUpstream)
+	if !IsCostCeilingExceeded(err) || !errors.Is(err, errUpstream) {
+		t.Fatalf("expected cost ceiling error wrapping the cause, got %v", err)
+	}
+	if JobErrorCode(err) != entity.JobErrorCostCeiling {
+		t.Errorf("error code = %s, want %s", JobErrorCode(err), entity.JobErrorCostCeiling)
+	}
+	if job.Attempts != 2 || job.SpentTokens != 1100 {
+		t.Fatalf("attempts=%d spent=%d, want 2 and 1100", job.Attempts, job.SpentTokens)
+	}
+
+	// 成功的尝试即使超过上限也照常返回
+	_, finish = budget.Track(context.Background(), job)
+	if err := finish(nil); err != nil {
+		t.Fatalf("successful attempt should not fail: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+// ErrJobCancelled 任务在生成期间被用户取消：本次生成中止，不计为失败、不重试
+var ErrJobCancelled = errors.New("job cancelled by user")
+
+// IsJobCancelled 生成是否因任务被取消而中止
+func IsJobCancelled(err error) bool {
+	return errors.Is(err, ErrJobCancelled)
+}
+
+// JobCancelSignal 运行中任务的取消信号（port）：网关取消任务时发出，执行生成的进程（Worker 或同步接口）
+// 监听后中止 LLM 调用。信号独立于数据库状态，订阅前已发出的请求也能被感知。
+type JobCancelSignal interface {
+	// Request 记录任务的取消请求并通知正在监听的执行方
+	Request(ctx context.Context, jobID string) error
+	// Watch 监听任务取消；返回的 channel 在收到取消请求（含订阅前已记录的请求）时关闭，调用 stop 结束监听
+	Watch(ctx context.Context, jobID string) (<-chan struct{}, func(), error)
+}
+
+// WithJobCancellation 让一次生成调用随任务取消而中止（signal 为 nil 时不监听；监听失败仅记录日志，不阻断生成）。
+// 返回的 finish 须在调用结束（流式读取完毕）后调用：停止监听，收到取消请求时无论调用结果如何
+// 都返回包装了 ErrJobCancelled 的错误（任务已取消，结果不再落库）。
+func WithJobCancellation(ctx context.Context, signal JobCancelSignal, jobID string) (context.Context, func(err error) error) {
+	if signal == nil || jobID == "" {
+		return ctx, func(err error) error { return err }
+	}
+	cancelled, stop, err := signal.Watch(ctx, jobID)
+	if err != nil {
+		logger.Warn(ctx, "failed to watch job cancellation", "error", err.Error(), "job_id", jobID)
+		return ctx, func(err error) error { return err }
+	}
+
+	genCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-cancelled:
+			cancel(ErrJobCancelled)
+		case <-genCtx.Done():
+		}
+	}()
+	return genCtx, func(err error) error {
+		aborted := errors.Is(context.Cause(genCtx), ErrJobCancelled)
+		cancel(nil)
+		stop()
+		switch {
+		case !aborted:
+			return err
+		case err == nil:
+			return ErrJobCancelled
+		case IsJobCancelled(err):
+			return err
+		default:
+			return fmt.Errorf("%w: %v", ErrJobCancelled, err)
+		}
+	}
+}
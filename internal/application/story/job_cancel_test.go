@@ -0,0 +1,99 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryCancelSignal 进程内的取消信号：已请求的任务在 Watch 时立即返回已关闭的 channel
+type memoryCancelSignal struct {
+	mu        sync.Mutex
+	requested map[string]bool
+	watchers  map[string][]chan struct{}
+}
+
+func newMemoryCancelSignal() *memoryCancelSignal {
+	return &memoryCancelSignal{requested: map[string]bool{}, watchers: map[string][]chan struct{}{}}
+}
+
+func (s *memoryCancelSignal) Request(_ context.Context, jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.requested[jobID] {
+		return nil
+	}
+	s.requested[jobID] = true
+	for _, ch := range s.watchers[jobID] {
+		close(ch)
+	}
+	delete(s.watchers, jobID)
+	return nil
+}
+
+func (s *memoryCancelSignal) Watch(_ context.Context, jobID string) (<-chan struct{}, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := make(chan struct{})
+	if s.requested[jobID] {
+		close(ch)
+	} else {
+		s.watchers[jobID] = append(s.watchers[jobID], ch)
+	}
+	return ch, func() {}, nil
+}
+
+func TestWithJobCancellationAbortsGeneration(t *testing.T) {
+	signal := newMemoryCancelSignal()
+	genCtx, finish := WithJobCancellation(context.Background(), signal, "job-1")
+
+	if err := signal.Request(context.Background(), "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-genCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("generation context should be cancelled after the cancel request")
+	}
+	err := finish(genCtx.Err())
+	if !IsJobCancelled(err) || !errors.Is(context.Cause(genCtx), ErrJobCancelled) {
+		t.Fatalf("expected job cancelled error, got %v", err)
+	}
+
+	// 调用在取消前已成功返回：任务已取消，结果同样不再落库
+	genCtx, finish = WithJobCancellation(context.Background(), signal, "job-1")
+	<-genCtx.Done()
+	if err := finish(nil); !IsJobCancelled(err) {
+		t.Fatalf("successful call after cancel request should report cancellation, got %v", err)
+	}
+}
+
+func TestWithJobCancellationPassThrough(t *testing.T) {
+	signal := newMemoryCancelSignal()
+	errUpstream := errors.New("upstream 502")
+
+	genCtx, finish := WithJobCancellation(context.Background(), signal, "job-2")
+	if err := finish(errUpstream); !errors.Is(err, errUpstream) || IsJobCancelled(err) {
+		t.Fatalf("failure without cancel request should pass through, got %v", err)
+	}
+	if genCtx.Err() == nil {
+		t.Fatal("finish should release the generation context")
+	}
+
+	// 其他任务的取消请求不影响本任务
+	_ = signal.Request(context.Background(), "job-3")
+	genCtx, finish = WithJobCancellation(context.Background(), signal, "job-2")
+	if genCtx.Err() != nil {
+		t.Fatal("generation context cancelled by another job's request")
+	}
+	if err := finish(nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx := context.Background()
+	if got, finish := WithJobCancellation(ctx, nil, "job-2"); got != ctx || finish(errUpstream) != errUpstream {
+		t.Fatal("nil signal should be a no-op")
+	}
+}
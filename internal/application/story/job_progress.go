@@ -0,0 +1,186 @@
+package story
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// 异步任务实时进度事件类型（与 SSE 事件类型同名，网关订阅后直接转发）
+const (
+	JobProgressContent  = "content"
+	JobProgressProgress = "progress"
+	JobProgressDone     = "done"
+	JobProgressError    = "error"
+	// JobProgressConflicts 生成结果的设定冲突提醒（SSE 任务流不转发，由项目订阅方消费）
+	JobProgressConflicts = "conflict_warnings"
+)
+
+// 正文分片合并发布的阈值：单个候选累计达到 jobProgressFlushRunes 字或距上次发布超过 jobProgressFlushInterval
+const (
+	jobProgressFlushRunes    = 200
+	jobProgressFlushInterval = 500 * time.Millisecond
+)
+
+// JobProgressEvent 异步任务实时进度事件
+type JobProgressEvent struct {
+	JobID string `json:"job_id"`
+	Type  string `json:"type"`
+	// TenantID / ProjectID 事件归属；ProjectID 非空时同时发布到项目频道
+	TenantID  string `json:"tenant_id,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	// Candidate 候选序号（多候选并行生成时区分正文归属）
+	Candidate int    `json:"candidate,omitempty"`
+	Chunk     string `json:"chunk,omitempty"`
+	Progress  int    `json:"progress,omitempty"`
+	Status    string `json:"status,omitempty"`
+	ChapterID string `json:"chapter_id,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Conflicts 设定冲突提醒列表（conflict_warnings 事件）
+	Conflicts json.RawMessage `json:"conflicts,omitempty"`
+}
+
+// JobProgressBus 任务实时进度广播（port）：Worker 发布，网关按任务或项目订阅后以 SSE / WebSocket 推送给前端。
+// 广播不持久化，订阅前发布的事件不可见；任务最终状态以数据库为准。
+type JobProgressBus interface {
+	Publish(ctx context.Context, ev *JobProgressEvent) error
+	// Subscribe 订阅任务事件；返回的 channel 在 cancel 或 ctx 结束后关闭
+	Subscribe(ctx context.Context, jobID string) (<-chan *JobProgressEvent, func(), error)
+	// SubscribeProject 订阅项目下全部任务的事件（含冲突提醒）；返回的 channel 在 cancel 或 ctx 结束后关闭
+	SubscribeProject(ctx context.Context, tenantID, projectID string) (<-chan *JobProgressEvent, func(), error)
+}
+
+// JobProgressStream 单个任务的进度发布器：正文按候选合并后发布，避免逐 token 写 Redis；
+// 发布失败只打日志，不影响生成。bus 为 nil 时所有方法为空操作。
+type JobProgressStream struct {
+	bus       JobProgressBus
+	tenantID  string
+	projectID string
+	jobID     string
+	now       func() time.Time
+
+	mu      sync.Mutex
+	pending map[int]*pendingChunk
+}
+
+// pendingChunk 候选尚未发布的正文
+type pendingChunk struct {
+	text      strings.Builder
+	runes     int
+	lastFlush time.Time
+}
+
+// NewJobProgressStream 创建任务进度发布器（事件同时发布到任务所属项目的频道）
+func NewJobProgressStream(bus JobProgressBus, tenantID, projectID, jobID string) *JobProgressStream {
+	if bus == nil {
+		return nil
+	}
+	return &JobProgressStream{bus: bus, tenantID: tenantID, projectID: projectID, jobID: jobID, now: time.Now, pending: map[int]*pendingChunk{}}
+}
+
+// Content 追加候选的正文分片；达到合并阈值时发布
+func (s *JobProgressStream) Content(ctx context.Context, candidate int, chunk string) {
+	if s == nil || chunk == "" {
+		return
+	}
+	s.mu.Lock()
+	p := s.pending[candidate]
+	if p == nil {
+		p = &pendingChunk{lastFlush: s.now()}
+		s.pending[candidate] = p
+	}
+	p.text.WriteString(chunk)
+	p.runes += utf8.RuneCountInString(chunk)
+	var ev *JobProgressEvent
+	if p.runes >= jobProgressFlushRunes || s.now().Sub(p.lastFlush) >= jobProgressFlushInterval {
+		ev = s.take(candidate, p)
+	}
+	s.mu.Unlock()
+	s.publish(ctx, ev)
+}
+
+// Progress 发布任务进度（先发布已缓冲的正文，保证前端看到的进度不超前于内容）
+func (s *JobProgressStream) Progress(ctx context.Context, progress int) {
+	if s == nil {
+		return
+	}
+	s.flush(ctx)
+	s.publish(ctx, &JobProgressEvent{JobID: s.jobID, Type: JobProgressProgress, Progress: progress})
+}
+
+// Finish 发布剩余正文与任务结束事件（完成为 done，其余结束状态为 error）
+func (s *JobProgressStream) Finish(ctx context.Context, job *entity.GenerationJob) {
+	if s == nil || job == nil {
+		return
+	}
+	s.flush(ctx)
+	ev := &JobProgressEvent{JobID: s.jobID, Type: JobProgressDone, Progress: job.Progress, Status: string(job.Status)}
+	if job.ChapterID != nil {
+		ev.ChapterID = *job.ChapterID
+	}
+	if job.Status != entity.JobStatusCompleted {
+		ev.Type = JobProgressError
+		ev.ErrorCode = string(job.ErrorCode)
+		ev.Error = job.ErrorMessage
+	}
+	s.publish(ctx, ev)
+}
+
+// flush 发布全部候选的缓冲正文
+func (s *JobProgressStream) flush(ctx context.Context) {
+	s.mu.Lock()
+	events := make([]*JobProgressEvent, 0, len(s.pending))
+	for candidate, p := range s.pending {
+		if ev := s.take(candidate, p); ev != nil {
+			events = append(events, ev)
+		}
+	}
+	s.mu.Unlock()
+	for _, ev := range events {
+		s.publish(ctx, ev)
+	}
+}
+
+// take 取出候选的缓冲正文（调用方持有锁）
+func (s *JobProgressStream) take(candidate int, p *pendingChunk) *JobProgressEvent {
+	if p.runes == 0 {
+		return nil
+	}
+	ev := &JobProgressEvent{JobID: s.jobID, Type: JobProgressContent, Candidate: candidate, Chunk: p.text.String()}
+	p.text.Reset()
+	p.runes = 0
+	p.lastFlush = s.now()
+	return ev
+}
+
+func (s *JobProgressStream) publish(ctx context.Context, ev *JobProgressEvent) {
+	if ev == nil {
+		return
+	}
+	ev.TenantID, ev.ProjectID = s.tenantID, s.projectID
+	if err := s.bus.Publish(ctx, ev); err != nil {
+		logger.Warn(ctx, "failed to publish job progress", "error", err.Error(), "job_id", s.jobID, "type", ev.Type)
+	}
+}
+
+// PublishConflictWarnings 发布任务结果的设定冲突提醒到项目频道；bus 为 nil 或没有冲突时为空操作
+func PublishConflictWarnings(ctx context.Context, bus JobProgressBus, tenantID, projectID, jobID string, warnings any) {
+	if bus == nil || projectID == "" {
+		return
+	}
+	data, err := json.Marshal(warnings)
+	if err != nil || string(data) == "null" || string(data) == "[]" {
+		return
+	}
+	ev := &JobProgressEvent{JobID: jobID, Type: JobProgressConflicts, TenantID: tenantID, ProjectID: projectID, Conflicts: data}
+	if err := bus.Publish(ctx, ev); err != nil {
+		logger.Warn(ctx, "failed to publish conflict warnings", "error", err.Error(), "job_id", jobID)
+	}
+}
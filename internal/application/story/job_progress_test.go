@@ -0,0 +1,98 @@
+package story
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+type recordingProgressBus struct {
+	mu     sync.Mutex
+	events []*JobProgressEvent
+}
+
+func (b *recordingProgressBus) Publish(_ context.Context, ev *JobProgressEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.events = append(b.events, ev)
+	return nil
+}
+
+func (b *recordingProgressBus) Subscribe(context.Context, string) (<-chan *JobProgressEvent, func(), error) {
+	return nil, func() {}, nil
+}
+
+func (b *recordingProgressBus) SubscribeProject(context.Context, string, string) (<-chan *JobProgressEvent, func(), error) {
+	return nil, func() {}, nil
+}
+
+func TestJobProgressStreamBatchesContent(t *testing.T) {
+	ctx := context.Background()
+	bus := &recordingProgressBus{}
+	now := time.Unix(0, 0)
+	s := NewJobProgressStream(bus, "t1", "p1", "job-1")
+	s.now = func() time.Time { return now }
+
+	s.Content(ctx, 0, "你好")
+	s.Content(ctx, 1, "世界")
+	if len(bus.events) != 0 {
+		t.Fatalf("small chunks should be buffered, got %d events", len(bus.events))
+	}
+
+	s.Content(ctx, 0, strings.Repeat("字", jobProgressFlushRunes))
+	if len(bus.events) != 1 || bus.events[0].Candidate != 0 || !strings.HasPrefix(bus.events[0].Chunk, "你好字") {
+		t.Fatalf("expected candidate 0 flushed at rune threshold, got %+v", bus.events)
+	}
+
+	now = now.Add(jobProgressFlushInterval)
+	s.Content(ctx, 1, "！")
+	if len(bus.events) != 2 || bus.events[1].Chunk != "世界！" || bus.events[1].Candidate != 1 {
+		t.Fatalf("expected candidate 1 flushed after interval, got %+v", bus.events[1:])
+	}
+
+	// 进度事件之前先发布缓冲的正文
+	s.Content(ctx, 0, "尾")
+	s.Progress(ctx, 40)
+	if len(bus.events) != 4 || bus.events[2].Chunk != "尾" || bus.events[3].Type != JobProgressProgress || bus.events[3].Progress != 40 {
+		t.Fatalf("unexpected events %+v", bus.events[2:])
+	}
+
+	job := &entity.GenerationJob{ID: "job-1", Status: entity.JobStatusFailed, ErrorCode: entity.JobErrorFailed, ErrorMessage: "boom"}
+	s.Finish(ctx, job)
+	last := bus.events[len(bus.events)-1]
+	if last.Type != JobProgressError || last.ErrorCode != string(entity.JobErrorFailed) || last.Error != "boom" {
+		t.Fatalf("unexpected finish event %+v", last)
+	}
+	for _, ev := range bus.events {
+		if ev.TenantID != "t1" || ev.ProjectID != "p1" {
+			t.Fatalf("event should carry its project for project subscribers, got %+v", ev)
+		}
+	}
+
+	var nilStream *JobProgressStream
+	nilStream.Content(ctx, 0, "x")
+	if NewJobProgressStream(nil, "t1", "p1", "job-2") != nil {
+		t.Fatal("stream without bus should be nil")
+	}
+}
+
+func TestPublishConflictWarnings(t *testing.T) {
+	ctx := context.Background()
+	bus := &recordingProgressBus{}
+
+	PublishConflictWarnings(ctx, bus, "t1", "p1", "job-1", []map[string]string{})
+	PublishConflictWarnings(ctx, bus, "t1", "", "job-1", []map[string]string{{"message": "x"}})
+	if len(bus.events) != 0 {
+		t.Fatalf("empty warnings or missing project should not publish, got %+v", bus.events)
+	}
+
+	PublishConflictWarnings(ctx, bus, "t1", "p1", "job-1", []map[string]string{{"message": "林远已在第三卷战死"}})
+	if len(bus.events) != 1 || bus.events[0].Type != JobProgressConflicts || !strings.Contains(string(bus.events[0].Conflicts), "林远") {
+		t.Fatalf("unexpected conflict event %+v", bus.events)
+	}
+	PublishConflictWarnings(ctx, nil, "t1", "p1", "job-1", []map[string]string{{"message": "x"}})
+}
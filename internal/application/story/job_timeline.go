@@ -0,0 +1,37 @@
+package story
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// JobTimeline 任务时间线记录器：HTTP/SSE/Worker 在任务关键节点追加事件，供 UI 展示进度。
+//
+// 约定：
+// - 事件与任务状态写在同一事务中（调用方负责事务边界与 SetTenant）。
+// - 记录失败只打日志，不影响主流程：仓储在调用方事务内以保存点写入，插入失败只回滚该条事件。
+type JobTimeline struct {
+	repo repository.JobEventRepository
+}
+
+// NewJobTimeline 创建任务时间线记录器
+func NewJobTimeline(repo repository.JobEventRepository) *JobTimeline {
+	return &JobTimeline{repo: repo}
+}
+
+// Record 追加一条任务事件
+func (t *JobTimeline) Record(ctx context.Context, job *entity.GenerationJob, eventType entity.JobEventType, message string, data map[string]any) {
+	if t == nil || t.repo == nil || job == nil || job.ID == "" {
+		return
+	}
+	if err := t.repo.Create(ctx, entity.NewJobEvent(job, eventType, message, data)); err != nil {
+		logger.Warn(ctx, "failed to record job event",
+			"error", err.Error(),
+			"job_id", job.ID,
+			"event_type", string(eventType),
+		)
+	}
+}
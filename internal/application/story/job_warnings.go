@@ -0,0 +1,90 @@
+package story
+
+import (
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/domain/entity"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// 任务警告文案：Worker、SSE 与同步接口共用，保证同类问题对用户的提示一致
+
+// RetrievalFailedWarning 上下文召回失败（生成未参考历史章节与设定）
+func RetrievalFailedWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningRetrievalUnavailable,
+		"context retrieval failed; the chapter was generated without retrieved context from earlier chapters")
+}
+
+// SpoilerGuardWarning 剧透保护加载失败（生成未受剧透约束，结果也未做剧透扫描）
+func SpoilerGuardWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningSpoilerGuardSkipped,
+		"spoiler guards could not be loaded; the chapter was generated and saved without spoiler checks")
+}
+
+// IndexFailedWarning 结果已保存但写入检索索引失败（后续生成可能召回不到该内容，可通过重建索引修复）
+func IndexFailedWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningIndexFailed,
+		"result was saved but could not be indexed for retrieval; later generations may not recall it until the index is rebuilt")
+}
+
+// ChapterEditedWarning 生成期间章节被作者修改，结果保存为待选候选而未覆盖正文
+func ChapterEditedWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningChapterEdited,
+		"the chapter was edited while it was being generated; the result was saved as a candidate instead of overwriting your changes")
+}
+
+// ActivationBlockedWarning 新版本已保存，但未通过租户校验 Webhook（或校验不可用），未被激活
+func ActivationBlockedWarning(reason string) entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningActivationBlocked,
+		"the new version was saved but not activated: "+reason)
+}
+
+// ConflictScanWarning 设定冲突检查失败（结果未经过冲突检查）
+func ConflictScanWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningConflictScanSkipped,
+		"setting conflict scan failed; the new version was saved without conflict warnings")
+}
+
+// ConflictScanTimeoutWarning 设定冲突检查超时（结果未经过冲突检查）
+func ConflictScanTimeoutWarning() entity.JobWarning {
+	return entity.NewJobWarning(entity.JobWarningConflictScanSkipped,
+		"setting conflict scan timed out; the new version was saved without conflict warnings")
+}
+
+// AttachmentWarnings 将附件截断信息转换为任务警告（每个被截断的附件一条）
+func AttachmentWarnings(truncated []wfmodel.AttachmentTruncation) []entity.JobWarning {
+	if len(truncated) == 0 {
+		return nil
+	}
+	out := make([]entity.JobWarning, 0, len(truncated))
+	for _, t := range truncated {
+		name := strings.TrimSpace(t.Name)
+		if name == "" {
+			name = "unnamed attachment"
+		}
+		var msg string
+		if t.KeptRunes == 0 {
+			msg = fmt.Sprintf("attachment %q (%d characters) was dropped: the total attachment limit of %d characters was reached",
+				name, t.Runes, wfmodel.AttachmentsMaxRunes)
+		} else {
+			msg = fmt.Sprintf("attachment %q was truncated from %d to %d characters (limit %d per attachment, %d in total); the model did not see the rest",
+				name, t.Runes, t.KeptRunes, wfmodel.AttachmentMaxRunes, wfmodel.AttachmentsMaxRunes)
+		}
+		out = append(out, entity.NewJobWarning(entity.JobWarningAttachmentTruncated, msg))
+	}
+	return out
+}
+
+// DuplicateContentWarnings 生成结果与同项目已有章节高度相似（疑似重生成写错章节），每个相似章节一条
+func DuplicateContentWarnings(matches []duplicate.Match) []entity.JobWarning {
+	if len(matches) == 0 {
+		return nil
+	}
+	out := make([]entity.JobWarning, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, entity.NewJobWarning(entity.JobWarningDuplicateContent, m.String()))
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+// Package manuscript 提供项目成稿（已完成章节合集）的渲染
+package manuscript
+
+import (
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// Format 成稿导出格式
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "txt"
+)
+
+// ParseFormat 解析导出格式（空值默认 markdown）
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "markdown", "md":
+		return FormatMarkdown, true
+	case "txt", "text":
+		return FormatText, true
+	default:
+		return "", false
+	}
+}
+
+// ContentType 响应 Content-Type
+func (f Format) ContentType() string {
+	if f == FormatText {
+		return "text/plain; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension 导出文件扩展名
+func (f Format) Extension() string {
+	if f == FormatText {
+		return "txt"
+	}
+	return "md"
+}
+
+// Render 按叙事顺序将章节渲染为成稿；章节无标题时按顺序编号
+func Render(project *entity.Project, chapters []*entity.Chapter, format Format) string {
+	var b strings.Builder
+	title := strings.TrimSpace(project.Title)
+	if format == FormatMarkdown {
+		b.WriteString("# " + title + "\n")
+	} else {
+		b.WriteString(title + "\n")
+	}
+
+	for i, ch := range chapters {
+		heading := strings.TrimSpace(ch.Title)
+		if heading == "" {
+			heading = fmt.Sprintf("第%d章", i+1)
+		}
+		if format == FormatMarkdown {
+			b.WriteString("\n## " + heading + "\n\n")
+		} else {
+			b.WriteString("\n\n" + heading + "\n\n")
+		}
+		b.WriteString(strings.TrimSpace(ch.ContentText))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
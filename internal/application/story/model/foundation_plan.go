@@ -57,4 +57,5 @@ type ChapterPlan struct {
 	Outline         string `json:"outline"`
 	TargetWordCount int    `json:"target_word_count,omitempty"`
 	StoryTimeStart  int64  `json:"story_time_start,omitempty"`
+	IsFlashback     bool   `json:"is_flashback,omitempty"`
 }
@@ -0,0 +1,224 @@
+// Package notes 提供作者笔记冷启动导入：将自由格式的笔记分段，
+// 依次多轮抽取世界观、角色、大纲草稿（后一轮以前一轮草稿为上下文），供作为分支版本落库。
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/domain/entity"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+const (
+	// MaxNotesRunes 单次导入的笔记字数上限（约 50 页）
+	MaxNotesRunes = 200000
+	// DefaultBranchKey 草稿版本默认写入的分支
+	DefaultBranchKey = "notes-import"
+	// chunkRunes 每段笔记的字数上限（按段落切分，超长段落硬切；与单个附件上限一致，避免注入时被截断）
+	chunkRunes = wfmodel.AttachmentMaxRunes
+)
+
+// ErrEmptyNotes 笔记为空
+var ErrEmptyNotes = errors.New("notes are empty")
+
+// ErrNotesTooLong 笔记超过单次导入上限
+var ErrNotesTooLong = fmt.Errorf("notes exceed %d characters", MaxNotesRunes)
+
+// PassOrder 抽取顺序：角色依赖世界观，大纲依赖世界观与角色
+var PassOrder = []entity.ArtifactType{
+	entity.ArtifactTypeWorldview,
+	entity.ArtifactTypeCharacters,
+	entity.ArtifactTypeOutline,
+}
+
+// Ingestor 笔记抽取服务
+type Ingestor struct {
+	generator *storyartifact.ArtifactGenerator
+}
+
+// NewIngestor 创建笔记抽取服务
+func NewIngestor(generator *storyartifact.ArtifactGenerator) *Ingestor {
+	return &Ingestor{generator: generator}
+}
+
+// ExtractInput 抽取输入
+type ExtractInput struct {
+	TenantID           string
+	ProjectID          string
+	ProjectTitle       string
+	ProjectDescription string
+
+	Notes string
+
+	// Base 各类型设定的基线内容（通常为当前激活版本），抽取在其基础上增补
+	Base map[entity.ArtifactType]json.RawMessage
+
+	Provider    string
+	Model       string
+	Temperature *float32
+	MaxTokens   *int
+}
+
+// Draft 单类设定的抽取草稿
+type Draft struct {
+	Type    entity.ArtifactType
+	Content json.RawMessage
+	// Passes 实际执行的抽取轮数（每段笔记一轮）
+	Passes int
+}
+
+// ExtractOutput 抽取结果
+type ExtractOutput struct {
+	Chunks int
+	Drafts []Draft
+	Usage  wfmodel.LLMUsageMeta
+}
+
+// Validate 校验笔记长度并返回去除首尾空白后的内容
+func Validate(notes string) (string, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return "", ErrEmptyNotes
+	}
+	if utf8.RuneCountInString(notes) > MaxNotesRunes {
+		return "", ErrNotesTooLong
+	}
+	return notes, nil
+}
+
+// Extract 逐类型、逐段调用设定生成工作流：每段笔记在上一段的抽取结果上增补，
+// 后续类型以已抽取的草稿作为上下文。任一轮失败即返回错误。
+func (i *Ingestor) Extract(ctx context.Context, in *ExtractInput) (*ExtractOutput, error) {
+	if i == nil || i.generator == nil {
+		return nil, fmt.Errorf("notes ingestor not configured")
+	}
+	if in == nil {
+		return nil, fmt.Errorf("input is nil")
+	}
+	notes, err := Validate(in.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := Split(notes, chunkRunes)
+	out := &ExtractOutput{Chunks: len(chunks)}
+	drafts := make(map[entity.ArtifactType]json.RawMessage, len(PassOrder))
+	current := func(t entity.ArtifactType) json.RawMessage {
+		if c, ok := drafts[t]; ok {
+			return c
+		}
+		return in.Base[t]
+	}
+
+	for _, t := range PassOrder {
+		passes := 0
+		for idx, chunk := range chunks {
+			gen, err := i.generator.Generate(ctx, &wfmodel.ArtifactGenerateInput{
+				TenantID:           in.TenantID,
+				ProjectID:          in.ProjectID,
+				ProjectTitle:       in.ProjectTitle,
+				ProjectDescription: in.ProjectDescription,
+				Type:               t,
+				Prompt:             passPrompt(t, idx, len(chunks)),
+				Attachments: []wfmodel.TextAttachment{{
+					Name:    fmt.Sprintf("作者笔记（第 %d/%d 段）", idx+1, len(chunks)),
+					Content: chunk,
+				}},
+				CurrentWorldview:   current(entity.ArtifactTypeWorldview),
+				CurrentCharacters:  current(entity.ArtifactTypeCharacters),
+				CurrentOutline:     current(entity.ArtifactTypeOutline),
+				CurrentArtifactRaw: current(t),
+				Provider:           in.Provider,
+				Model:              in.Model,
+				Temperature:        in.Temperature,
+				MaxTokens:          in.MaxTokens,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s pass %d/%d: %w", t, idx+1, len(chunks), err)
+			}
+			drafts[t] = gen.Content
+			passes++
+
+			out.Usage.Provider = gen.Meta.Provider
+			out.Usage.Model = gen.Meta.Model
+			out.Usage.Temperature = gen.Meta.Temperature
+			out.Usage.GeneratedAt = gen.Meta.GeneratedAt
+			out.Usage.PromptTokens += gen.Meta.PromptTokens
+			out.Usage.CompletionTokens += gen.Meta.CompletionTokens
+		}
+		out.Drafts = append(out.Drafts, Draft{Type: t, Content: drafts[t], Passes: passes})
+	}
+	return out, nil
+}
+
+// passPrompt 单轮抽取指令
+func passPrompt(t entity.ArtifactType, idx, total int) string {
+	subject := map[entity.ArtifactType]string{
+		entity.ArtifactTypeWorldview:  "世界观设定（规则、地理、势力、历史、术语等）",
+		entity.ArtifactTypeCharacters: "角色设定（姓名、身份、性格、动机、人物关系等）",
+		entity.ArtifactTypeOutline:    "故事大纲（主线、卷/章节走向、关键情节节点）",
+	}[t]
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "以下附加材料是作者自由书写的原始笔记的第 %d/%d 段。请仅从中抽取%s，", idx+1, total, subject)
+	sb.WriteString("整理为结构化构件。")
+	if idx > 0 {
+		sb.WriteString("当前构件已包含前面各段的抽取结果：保留已有内容，只补充或修正本段提供的新信息。")
+	}
+	sb.WriteString("笔记未提及的内容不要臆造；前后矛盾时以笔记中靠后的表述为准。")
+	return sb.String()
+}
+
+// Split 按段落将笔记切分为不超过 size 字的片段；单个段落超长时按字数硬切。
+func Split(text string, size int) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return nil
+	}
+	if size <= 0 || utf8.RuneCountInString(text) <= size {
+		return []string{text}
+	}
+
+	var (
+		chunks []string
+		buf    strings.Builder
+		bufLen int
+	)
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		buf.Reset()
+		bufLen = 0
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		runes := []rune(para)
+		for len(runes) > size {
+			flush()
+			chunks = append(chunks, string(runes[:size]))
+			runes = runes[size:]
+		}
+		if bufLen > 0 && bufLen+2+len(runes) > size {
+			flush()
+		}
+		if bufLen > 0 {
+			buf.WriteString("\n\n")
+			bufLen += 2
+		}
+		buf.WriteString(string(runes))
+		bufLen += len(runes)
+	}
+	flush()
+	return chunks
+}
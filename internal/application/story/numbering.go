@@ -0,0 +1,222 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// ErrNumberingVolumeNotFound 重编号或移动的目标卷不存在，或不属于该项目
+var ErrNumberingVolumeNotFound = errors.New("volume not found in project")
+
+// ChapterNumbering 章节编号维护：seq_num 是卷内排序键（新建取 MAX+1，删除后留下空洞），
+// display_no 是项目内按叙事顺序连续的展示序号（第 N 章）。删除、移动、重排后压缩 seq_num 并刷新 display_no。
+// 所有方法都在调用方事务内执行（与 Reorder 调用相同），并先锁定项目，避免与并发的新建、应用设定集交错。
+//
+// 卷序号或卷内序号变化会改变章节的叙事位置（见 entity.NarrativePosition），
+// 调用方在重排/重编号成功后调用 SyncNarrativePositions 回写向量索引中的 narrative_pos。
+type ChapterNumbering struct {
+	chapterRepo repository.ChapterRepository
+	volumeRepo  repository.VolumeRepository
+	locker      repository.ProjectLocker
+	indexer     *appretrieval.Indexer
+}
+
+// NewChapterNumbering 创建章节编号服务（indexer 为 nil 时不回写叙事位置）
+func NewChapterNumbering(
+	chapterRepo repository.ChapterRepository,
+	volumeRepo repository.VolumeRepository,
+	locker repository.ProjectLocker,
+	indexer *appretrieval.Indexer,
+) *ChapterNumbering {
+	return &ChapterNumbering{chapterRepo: chapterRepo, volumeRepo: volumeRepo, locker: locker, indexer: indexer}
+}
+
+// AssignDisplayNumber 新建章节后刷新项目展示序号，并回填到 chapter.DisplayNo
+func (n *ChapterNumbering) AssignDisplayNumber(ctx context.Context, chapter *entity.Chapter) error {
+	if err := n.RefreshDisplayNumbers(ctx, chapter.ProjectID); err != nil {
+		return err
+	}
+	stored, err := n.chapterRepo.GetByID(ctx, chapter.ID)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		chapter.DisplayNo = stored.DisplayNo
+	}
+	return nil
+}
+
+// RefreshDisplayNumbers 只刷新展示序号（卷重排等不改变卷内序号的场景）
+func (n *ChapterNumbering) RefreshDisplayNumbers(ctx context.Context, projectID string) error {
+	if err := n.lock(ctx, projectID); err != nil {
+		return err
+	}
+	return n.chapterRepo.RefreshDisplayNumbers(ctx, projectID)
+}
+
+// RenumberVolume 将卷内 seq_num 压缩为 1..n（保持相对顺序）并刷新展示序号；volumeID 为空时只刷新展示序号
+func (n *ChapterNumbering) RenumberVolume(ctx context.Context, projectID, volumeID string) error {
+	if err := n.lock(ctx, projectID); err != nil {
+		return err
+	}
+	if volumeID != "" {
+		if err := n.checkVolume(ctx, projectID, volumeID); err != nil {
+			return err
+		}
+		if err := n.chapterRepo.ReorderChapters(ctx, projectID, volumeID, nil); err != nil {
+			return err
+		}
+	}
+	return n.chapterRepo.RefreshDisplayNumbers(ctx, projectID)
+}
+
+// RenumberProject 压缩卷序号与各卷章节序号，并刷新展示序号
+func (n *ChapterNumbering) RenumberProject(ctx context.Context, projectID string) error {
+	if err := n.lock(ctx, projectID); err != nil {
+		return err
+	}
+	if err := n.volumeRepo.ReorderVolumes(ctx, projectID, nil); err != nil {
+		return err
+	}
+	volumes, err := n.volumeRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		if err := n.chapterRepo.ReorderChapters(ctx, projectID, v.ID, nil); err != nil {
+			return err
+		}
+	}
+	return n.chapterRepo.RefreshDisplayNumbers(ctx, projectID)
+}
+
+// MoveChapter 将章节移动到目标卷的第 position 位（从 1 开始，<= 0 或越界时放到末尾），
+// 压缩来源卷与目标卷的序号并刷新展示序号；完成后 chapter 的卷、序号与展示序号为最新值
+func (n *ChapterNumbering) MoveChapter(ctx context.Context, chapter *entity.Chapter, volumeID string, position int) error {
+	projectID := chapter.ProjectID
+	if err := n.lock(ctx, projectID); err != nil {
+		return err
+	}
+	if err := n.checkVolume(ctx, projectID, volumeID); err != nil {
+		return err
+	}
+
+	sourceVolumeID := chapter.VolumeID
+	if sourceVolumeID != volumeID {
+		// 先以目标卷末尾序号落位，避免与目标卷已有序号冲突
+		next, err := n.chapterRepo.GetNextSeqNum(ctx, projectID, volumeID)
+		if err != nil {
+			return err
+		}
+		chapter.VolumeID = volumeID
+		chapter.SeqNum = next
+		if err := n.chapterRepo.Update(ctx, chapter); err != nil {
+			return err
+		}
+	}
+
+	siblings, err := n.chapterRepo.ListByVolume(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	order := make([]string, 0, len(siblings))
+	for _, c := range siblings {
+		if c.ID != chapter.ID {
+			order = append(order, c.ID)
+		}
+	}
+	idx := len(order)
+	if position > 0 && position <= len(order) {
+		idx = position - 1
+	}
+	order = append(order[:idx], append([]string{chapter.ID}, order[idx:]...)...)
+	if err := n.chapterRepo.ReorderChapters(ctx, projectID, volumeID, order); err != nil {
+		return err
+	}
+	if sourceVolumeID != "" && sourceVolumeID != volumeID {
+		if err := n.chapterRepo.ReorderChapters(ctx, projectID, sourceVolumeID, nil); err != nil {
+			return err
+		}
+	}
+	if err := n.chapterRepo.RefreshDisplayNumbers(ctx, projectID); err != nil {
+		return err
+	}
+
+	stored, err := n.chapterRepo.GetByID(ctx, chapter.ID)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		chapter.SeqNum = stored.SeqNum
+		chapter.DisplayNo = stored.DisplayNo
+	}
+	return nil
+}
+
+// SyncNarrativePositions 按当前卷序号与卷内序号重新计算项目各章节的叙事位置，并回写向量索引中
+// 已有章节片段的 narrative_pos（不重新向量化）。失败仅记录日志：索引中的旧位置只影响检索过滤精度，
+// 下次重建章节索引时也会被覆盖。向量能力未启用时不做处理。
+func (n *ChapterNumbering) SyncNarrativePositions(ctx context.Context, tenantID, projectID string) {
+	if n == nil || n.indexer == nil {
+		return
+	}
+	positions, err := n.narrativePositions(ctx, projectID)
+	if err != nil {
+		logger.Warn(ctx, "failed to compute chapter narrative positions", "error", err.Error(), "project_id", projectID)
+		return
+	}
+	syncCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chapterIndexTimeout)
+	defer cancel()
+	if err := n.indexer.UpdateNarrativePositions(syncCtx, tenantID, projectID, positions); err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) {
+		logger.Warn(ctx, "failed to update chapter narrative positions", "error", err.Error(), "project_id", projectID)
+	}
+}
+
+// narrativePositions 计算项目各章节的叙事位置（章节 ID → 位置），与 ChapterRepository.GetNarrativePosition 一致
+func (n *ChapterNumbering) narrativePositions(ctx context.Context, projectID string) (map[string]int64, error) {
+	volumes, err := n.volumeRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	volumeSeq := make(map[string]int, len(volumes))
+	for _, v := range volumes {
+		volumeSeq[v.ID] = v.SeqNum
+	}
+	chapters, err := n.chapterRepo.ListTimeline(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int64, len(chapters))
+	for _, c := range chapters {
+		// 未归属卷（或卷已删除）的章节按卷序号 0 处理
+		positions[c.ID] = entity.NarrativePosition(volumeSeq[c.VolumeID], c.SeqNum)
+	}
+	return positions, nil
+}
+
+func (n *ChapterNumbering) lock(ctx context.Context, projectID string) error {
+	if n.locker == nil {
+		return nil
+	}
+	if err := n.locker.LockProject(ctx, projectID); err != nil {
+		return fmt.Errorf("failed to lock project for renumbering: %w", err)
+	}
+	return nil
+}
+
+func (n *ChapterNumbering) checkVolume(ctx context.Context, projectID, volumeID string) error {
+	vol, err := n.volumeRepo.GetByID(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	if vol == nil || vol.ProjectID != projectID {
+		return ErrNumberingVolumeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestChapterNumberingCompactsAfterDeleteAndMove(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	chapters := memrepo.NewChapterRepository(store)
+	volumes := memrepo.NewVolumeRepository(store)
+	projects := memrepo.NewProjectRepository(store)
+	txMgr := memrepo.NewTxManager(store)
+	n := NewChapterNumbering(chapters, volumes, memrepo.NewProjectLocker(store), nil)
+
+	project := entity.NewProject(testTenantID, "owner-1", "测试项目")
+	mustNoErr(t, projects.Create(ctx, project))
+	v1 := entity.NewVolume(project.ID, 1, "第一卷")
+	v2 := entity.NewVolume(project.ID, 2, "第二卷")
+	mustNoErr(t, volumes.Create(ctx, v1))
+	mustNoErr(t, volumes.Create(ctx, v2))
+
+	newChapter := func(volumeID string, seq int, title string) *entity.Chapter {
+		ch := entity.NewChapter(project.ID, volumeID, seq)
+		ch.Title = title
+		mustNoErr(t, chapters.Create(ctx, ch))
+		return ch
+	}
+	a := newChapter(v1.ID, 1, "a")
+	b := newChapter(v1.ID, 2, "b")
+	c := newChapter(v1.ID, 3, "c")
+	d := newChapter(v2.ID, 1, "d")
+	e := newChapter(v2.ID, 2, "e")
+
+	assertNumbers := func(want map[string][2]int) {
+		t.Helper()
+		for id, w := range want {
+			got, err := chapters.GetByID(ctx, id)
+			mustNoErr(t, err)
+			if got.SeqNum != w[0] || got.DisplayNo != w[1] {
+				t.Fatalf("chapter %s: got seq=%d display=%d, want seq=%d display=%d", got.Title, got.SeqNum, got.DisplayNo, w[0], w[1])
+			}
+		}
+	}
+
+	// 删除卷中间的章节后压缩序号，展示序号跨卷连续
+	mustNoErr(t, txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := chapters.Delete(txCtx, b.ID); err != nil {
+			return err
+		}
+		return n.RenumberVolume(txCtx, project.ID, v1.ID)
+	}))
+	assertNumbers(map[string][2]int{a.ID: {1, 1}, c.ID: {2, 2}, d.ID: {1, 3}, e.ID: {2, 4}})
+
+	// 跨卷移动到第一位：来源卷与目标卷都重新压缩
+	moved, err := chapters.GetByID(ctx, e.ID)
+	mustNoErr(t, err)
+	mustNoErr(t, txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		return n.MoveChapter(txCtx, moved, v1.ID, 1)
+	}))
+	if moved.VolumeID != v1.ID || moved.SeqNum != 1 || moved.DisplayNo != 1 {
+		t.Fatalf("moved chapter not refreshed: %+v", moved)
+	}
+	assertNumbers(map[string][2]int{e.ID: {1, 1}, a.ID: {2, 2}, c.ID: {3, 3}, d.ID: {1, 4}})
+
+	// 目标卷不属于项目
+	other := entity.NewVolume("other-project", 1, "他卷")
+	mustNoErr(t, volumes.Create(ctx, other))
+	err = txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		return n.MoveChapter(txCtx, moved, other.ID, 0)
+	})
+	if !errors.Is(err, ErrNumberingVolumeNotFound) {
+		t.Fatalf("expected volume not found, got %v", err)
+	}
+}
+
+// positionVectorRepo 在 capturingVectorRepo 基础上记录叙事位置回写
+type positionVectorRepo struct {
+	capturingVectorRepo
+	tenantID, projectID string
+	positions           map[string]int64
+}
+
+func (r *positionVectorRepo) UpdateNarrativePositions(_ context.Context, tenantID, projectID string, positions map[string]int64) error {
+	r.tenantID, r.projectID, r.positions = tenantID, projectID, positions
+	return nil
+}
+
+func TestChapterNumberingSyncsNarrativePositions(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	chapters := memrepo.NewChapterRepository(store)
+	volumes := memrepo.NewVolumeRepository(store)
+	projects := memrepo.NewProjectRepository(store)
+	txMgr := memrepo.NewTxManager(store)
+	vectors := &positionVectorRepo{capturingVectorRepo: capturingVectorRepo{inserted: map[string][]*appretrieval.VectorStorySegment{}}}
+	n := NewChapterNumbering(chapters, volumes, memrepo.NewProjectLocker(store), appretrieval.NewIndexer(constEmbedder{}, vectors, 0))
+
+	project := entity.NewProject(testTenantID, "owner-1", "测试项目")
+	mustNoErr(t, projects.Create(ctx, project))
+	v1 := entity.NewVolume(project.ID, 1, "第一卷")
+	v2 := entity.NewVolume(project.ID, 2, "第二卷")
+	mustNoErr(t, volumes.Create(ctx, v1))
+	mustNoErr(t, volumes.Create(ctx, v2))
+	var ids []string
+	for i, volumeID := range []string{v1.ID, v1.ID, v2.ID, ""} {
+		ch := entity.NewChapter(project.ID, volumeID, i+1)
+		mustNoErr(t, chapters.Create(ctx, ch))
+		ids = append(ids, ch.ID)
+	}
+
+	// 第二卷的章节移到第一卷卷首：第一卷原有章节的叙事位置全部后移
+	moved, err := chapters.GetByID(ctx, ids[2])
+	mustNoErr(t, err)
+	mustNoErr(t, txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := n.MoveChapter(txCtx, moved, v1.ID, 1); err != nil {
+			return err
+		}
+		n.SyncNarrativePositions(txCtx, testTenantID, project.ID)
+		return nil
+	}))
+
+	if vectors.tenantID != testTenantID || vectors.projectID != project.ID || len(vectors.positions) != len(ids) {
+		t.Fatalf("unexpected sync: tenant=%s project=%s positions=%v", vectors.tenantID, vectors.projectID, vectors.positions)
+	}
+	for _, id := range ids {
+		want, err := chapters.GetNarrativePosition(ctx, id)
+		mustNoErr(t, err)
+		if got := vectors.positions[id]; got != want {
+			t.Fatalf("chapter %s: synced narrative_pos %d, want %d", id, got, want)
+		}
+	}
+	if vectors.positions[ids[2]] != entity.NarrativePosition(1, 1) || vectors.positions[ids[1]] != entity.NarrativePosition(1, 3) {
+		t.Fatalf("positions not recomputed after move: %v", vectors.positions)
+	}
+
+	// 向量存储不支持原地回写时静默跳过
+	plain := NewChapterNumbering(chapters, volumes, nil, appretrieval.NewIndexer(constEmbedder{}, &capturingVectorRepo{}, 0))
+	plain.SyncNarrativePositions(ctx, testTenantID, project.ID)
+}
@@ -0,0 +1,39 @@
+package outline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// document outline 构件内容中覆盖度关心的部分
+type document struct {
+	Volumes []storymodel.VolumePlan `json:"volumes"`
+}
+
+// Active 读取项目 outline 构件的激活版本，返回版本 ID 与卷/章条目；项目尚无大纲时均为空
+func Active(ctx context.Context, artifactRepo repository.ArtifactRepository, projectID string) (string, []storymodel.VolumePlan, error) {
+	artifacts, err := artifactRepo.ListArtifactsByProject(ctx, projectID)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, a := range artifacts {
+		if a == nil || a.Type != entity.ArtifactTypeOutline || a.ActiveVersionID == nil {
+			continue
+		}
+		version, err := artifactRepo.GetVersionByID(ctx, *a.ActiveVersionID)
+		if err != nil || version == nil {
+			return "", nil, err
+		}
+		var doc document
+		if err := json.Unmarshal(version.Content, &doc); err != nil {
+			return "", nil, fmt.Errorf("invalid outline artifact content: %w", err)
+		}
+		return version.ID, doc.Volumes, nil
+	}
+	return "", nil, nil
+}
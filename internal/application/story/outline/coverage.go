@@ -0,0 +1,156 @@
+// Package outline 维护大纲条目与章节的显式关联：按章节的 outline_key 汇总每个大纲章节条目的落地状态。
+package outline
+
+import (
+	"fmt"
+	"strings"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// ItemStatus 大纲章节条目的落地状态
+type ItemStatus string
+
+const (
+	// ItemStatusMissing 尚无关联章节
+	ItemStatusMissing ItemStatus = "missing"
+	// ItemStatusDraft 已有章节但尚无正文
+	ItemStatusDraft ItemStatus = "draft"
+	// ItemStatusGenerated 已有正文，待作者审阅（draft/review 状态）
+	ItemStatusGenerated ItemStatus = "generated"
+	// ItemStatusApproved 章节已完成（completed，计入成稿与公开 API）
+	ItemStatusApproved ItemStatus = "approved"
+)
+
+// Item 单个大纲章节条目及其关联章节
+type Item struct {
+	VolumeKey   string     `json:"volume_key"`
+	VolumeTitle string     `json:"volume_title,omitempty"`
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Status      ItemStatus `json:"status"`
+	ChapterID   string     `json:"chapter_id,omitempty"`
+	WordCount   int        `json:"word_count"`
+	// DuplicateChapterIDs 同一条目关联了多个章节时，除 ChapterID 外的其余章节（按叙事顺序）
+	DuplicateChapterIDs []string `json:"duplicate_chapter_ids,omitempty"`
+}
+
+// Orphan 关联了当前大纲中不存在的条目，或未关联任何条目的章节
+type Orphan struct {
+	ChapterID  string `json:"chapter_id"`
+	Title      string `json:"title,omitempty"`
+	OutlineKey string `json:"outline_key,omitempty"`
+}
+
+// Coverage 大纲覆盖度
+type Coverage struct {
+	// OutlineVersionID 计算所依据的大纲构件激活版本；项目尚无大纲时为空
+	OutlineVersionID string             `json:"outline_version_id,omitempty"`
+	Items            []Item             `json:"items"`
+	Counts           map[ItemStatus]int `json:"counts"`
+	Orphans          []Orphan           `json:"orphans"`
+}
+
+// ChapterStatus 章节对应的条目状态
+func ChapterStatus(ch *entity.Chapter) ItemStatus {
+	switch {
+	case ch == nil:
+		return ItemStatusMissing
+	case ch.Status == entity.ChapterStatusCompleted:
+		return ItemStatusApproved
+	case ch.WordCount > 0:
+		return ItemStatusGenerated
+	default:
+		return ItemStatusDraft
+	}
+}
+
+// Build 按大纲条目顺序汇总覆盖度（volumes 为 outline 构件的卷/章条目）；
+// chapters 须按叙事顺序排列，同一条目关联多章时取最靠前的一章
+func Build(volumes []storymodel.VolumePlan, chapters []*entity.Chapter) *Coverage {
+	out := &Coverage{
+		Items: []Item{},
+		Counts: map[ItemStatus]int{
+			ItemStatusMissing:   0,
+			ItemStatusDraft:     0,
+			ItemStatusGenerated: 0,
+			ItemStatusApproved:  0,
+		},
+		Orphans: []Orphan{},
+	}
+
+	linked := LinkedChapters(chapters)
+	keys := make(map[string]struct{})
+	for _, v := range volumes {
+		for _, cp := range v.Chapters {
+			key := strings.TrimSpace(cp.Key)
+			if key == "" {
+				continue
+			}
+			keys[key] = struct{}{}
+			item := Item{
+				VolumeKey:   strings.TrimSpace(v.Key),
+				VolumeTitle: strings.TrimSpace(v.Title),
+				Key:         key,
+				Title:       strings.TrimSpace(cp.Title),
+				Status:      ItemStatusMissing,
+			}
+			if chs := linked[key]; len(chs) > 0 {
+				item.ChapterID = chs[0].ID
+				item.WordCount = chs[0].WordCount
+				item.Status = ChapterStatus(chs[0])
+				for _, dup := range chs[1:] {
+					item.DuplicateChapterIDs = append(item.DuplicateChapterIDs, dup.ID)
+				}
+			}
+			out.Counts[item.Status]++
+			out.Items = append(out.Items, item)
+		}
+	}
+
+	for _, ch := range chapters {
+		if ch == nil {
+			continue
+		}
+		key := strings.TrimSpace(ch.OutlineKey)
+		if _, ok := keys[key]; ok {
+			continue
+		}
+		out.Orphans = append(out.Orphans, Orphan{ChapterID: ch.ID, Title: ch.Title, OutlineKey: key})
+	}
+	return out
+}
+
+// LinkedChapters 按 outline_key 分组章节（保持输入顺序，忽略未关联的章节）
+func LinkedChapters(chapters []*entity.Chapter) map[string][]*entity.Chapter {
+	linked := make(map[string][]*entity.Chapter)
+	for _, ch := range chapters {
+		if ch == nil {
+			continue
+		}
+		if key := strings.TrimSpace(ch.OutlineKey); key != "" {
+			linked[key] = append(linked[key], ch)
+		}
+	}
+	return linked
+}
+
+// CollisionWarning 大纲条目 key 已关联到 applied 以外的章节时返回告警文本，否则返回空串
+func CollisionWarning(key string, applied *entity.Chapter, linked map[string][]*entity.Chapter) string {
+	var others []string
+	for _, ch := range linked[key] {
+		if applied == nil || ch.ID != applied.ID {
+			others = append(others, ch.ID)
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+	appliedID := ""
+	if applied != nil {
+		appliedID = applied.ID
+	}
+	return fmt.Sprintf("outline key %q is also linked to chapter(s) %s; applied to chapter %s, relink or clear outline_key on the others",
+		key, strings.Join(others, ", "), appliedID)
+}
@@ -0,0 +1,48 @@
+package outline
+
+import (
+	"reflect"
+	"testing"
+
+	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+func TestBuildCoverage(t *testing.T) {
+	volumes := []storymodel.VolumePlan{
+		{Key: "v1", Title: "第一卷", Chapters: []storymodel.ChapterPlan{
+			{Key: "c1", Title: "觉醒"},
+			{Key: "c2", Title: "拜师"},
+			{Key: "c3", Title: "出山"},
+			{Key: "c4", Title: "归来"},
+		}},
+	}
+	chapters := []*entity.Chapter{
+		{ID: "ch-1", OutlineKey: "c1", Status: entity.ChapterStatusCompleted, WordCount: 3000},
+		{ID: "ch-2", OutlineKey: "c2", Status: entity.ChapterStatusReview, WordCount: 2800},
+		{ID: "ch-3", OutlineKey: "c3", Status: entity.ChapterStatusDraft},
+		{ID: "ch-3b", OutlineKey: "c3", Status: entity.ChapterStatusDraft, WordCount: 100},
+		{ID: "ch-x", OutlineKey: "removed", Title: "旧章节"},
+		{ID: "ch-y", Title: "番外"},
+	}
+
+	got := Build(volumes, chapters)
+
+	statuses := make([]ItemStatus, 0, len(got.Items))
+	for _, it := range got.Items {
+		statuses = append(statuses, it.Status)
+	}
+	want := []ItemStatus{ItemStatusApproved, ItemStatusGenerated, ItemStatusDraft, ItemStatusMissing}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	if got.Items[2].ChapterID != "ch-3" || !reflect.DeepEqual(got.Items[2].DuplicateChapterIDs, []string{"ch-3b"}) {
+		t.Fatalf("duplicate links not reported: %+v", got.Items[2])
+	}
+	if got.Counts[ItemStatusMissing] != 1 || got.Counts[ItemStatusApproved] != 1 {
+		t.Fatalf("unexpected counts: %v", got.Counts)
+	}
+	if len(got.Orphans) != 2 || got.Orphans[0].OutlineKey != "removed" || got.Orphans[1].ChapterID != "ch-y" {
+		t.Fatalf("unexpected orphans: %+v", got.Orphans)
+	}
+}
@@ -0,0 +1,127 @@
+package outline
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// StaleKeys 返回两个 outline 版本间发生实质变化的章节条目 key（条目内容修改或跨卷移动，按 key 排序）。
+// 新增条目尚无关联章节；删除的条目由覆盖度的孤立章节呈现，均不计入
+func StaleKeys(from, to json.RawMessage) ([]string, error) {
+	diff, err := storyartifact.CompareArtifactContent(entity.ArtifactTypeOutline, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil || diff.Outline == nil {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(k string) {
+		if k == "" || seen[k] {
+			return
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	for _, k := range diff.Outline.ChaptersUpdated {
+		add(k)
+	}
+	for _, m := range diff.Outline.ChaptersMoved {
+		add(m.Key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// StaleMark 一次大纲激活的过期标记结果
+type StaleMark struct {
+	// ChapterIDs 被标记为大纲过期的章节
+	ChapterIDs []string
+	// RefreshJob 项目开启 outline_refresh 时创建的大纲刷新任务（已写库、待调用方在提交后发布），否则为 nil
+	RefreshJob *entity.GenerationJob
+	// RefreshParams 刷新任务参数（发布消息时使用）
+	RefreshParams map[string]any
+}
+
+// StaleTracker 大纲激活后标记偏离新大纲的章节（outline_stale），由项目健康检查呈现、重新生成或大纲刷新任务清除
+type StaleTracker struct {
+	artifactRepo repository.ArtifactRepository
+	chapterRepo  repository.ChapterRepository
+	projectRepo  repository.ProjectRepository
+	jobRepo      repository.JobRepository
+	timeline     *appstory.JobTimeline
+	now          func() time.Time
+}
+
+// NewStaleTracker 创建大纲过期标记服务
+func NewStaleTracker(
+	artifactRepo repository.ArtifactRepository,
+	chapterRepo repository.ChapterRepository,
+	projectRepo repository.ProjectRepository,
+	jobRepo repository.JobRepository,
+	timeline *appstory.JobTimeline,
+) *StaleTracker {
+	return &StaleTracker{
+		artifactRepo: artifactRepo,
+		chapterRepo:  chapterRepo,
+		projectRepo:  projectRepo,
+		jobRepo:      jobRepo,
+		timeline:     timeline,
+		now:          time.Now,
+	}
+}
+
+// MarkActivated 在 outline 构件切换激活版本后调用（与切换处于同一事务）：与先前激活的版本比较，
+// 将关联到实质变化条目的章节标记为大纲过期；项目开启 outline_refresh 时同时创建大纲刷新任务。
+// 非 outline 构件、首次激活、重复激活同一版本或无章节受影响时返回 nil
+func (t *StaleTracker) MarkActivated(ctx context.Context, art *entity.ProjectArtifact, previousVersionID *string, activated *entity.ArtifactVersion) (*StaleMark, error) {
+	if t == nil || art == nil || art.Type != entity.ArtifactTypeOutline || activated == nil ||
+		previousVersionID == nil || *previousVersionID == "" || *previousVersionID == activated.ID {
+		return nil, nil
+	}
+	previous, err := t.artifactRepo.GetVersionByID(ctx, *previousVersionID)
+	if err != nil || previous == nil {
+		return nil, err
+	}
+	keys, err := StaleKeys(previous.Content, activated.Content)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	ids, err := t.chapterRepo.MarkOutlineStale(ctx, art.ProjectID, keys, t.now())
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	mark := &StaleMark{ChapterIDs: ids}
+
+	project, err := t.projectRepo.GetByID(ctx, art.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil || !project.Settings.OutlineRefreshEnabled() {
+		return mark, nil
+	}
+	params := map[string]any{"chapter_ids": ids}
+	raw, _ := json.Marshal(params)
+	job := entity.NewGenerationJob(art.TenantID, art.ProjectID, entity.JobTypeOutlineRefresh, raw)
+	job.ID = uuid.NewString()
+	if err := t.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	t.timeline.Record(ctx, job, entity.JobEventQueued, string(job.JobType)+" queued", map[string]any{
+		"outline_version_id": activated.ID,
+		"chapters":           len(ids),
+	})
+	mark.RefreshJob = job
+	mark.RefreshParams = params
+	return mark, nil
+}
@@ -0,0 +1,122 @@
+package outline
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func outlineContent(t *testing.T, volumes ...map[string]any) json.RawMessage {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"volumes": volumes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func outlineVolume(key string, chapters ...map[string]any) map[string]any {
+	return map[string]any{"key": key, "title": key, "chapters": chapters}
+}
+
+func outlineChapter(key, outline string) map[string]any {
+	return map[string]any{"key": key, "title": key, "outline": outline}
+}
+
+func TestStaleKeys(t *testing.T) {
+	from := outlineContent(t,
+		outlineVolume("v1", outlineChapter("c1", "觉醒"), outlineChapter("c2", "拜师"), outlineChapter("c3", "出山")),
+		outlineVolume("v2", outlineChapter("c4", "归来")),
+	)
+	// c1 未变；c2 内容修改；c3 移到第二卷；c4 删除；c5 新增
+	to := outlineContent(t,
+		outlineVolume("v1", outlineChapter("c1", "觉醒"), outlineChapter("c2", "拜师失败，流落江湖")),
+		outlineVolume("v2", outlineChapter("c3", "出山"), outlineChapter("c5", "终局")),
+	)
+	keys, err := StaleKeys(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"c2", "c3"}) {
+		t.Fatalf("stale keys = %v, want [c2 c3]", keys)
+	}
+}
+
+func TestStaleTrackerMarkActivated(t *testing.T) {
+	ctx := context.Background()
+	store := memrepo.NewStore()
+	projects := memrepo.NewProjectRepository(store)
+	chapters := memrepo.NewChapterRepository(store)
+	artifacts := memrepo.NewArtifactRepository(store)
+	jobs := memrepo.NewJobRepository(store)
+	tracker := NewStaleTracker(artifacts, chapters, projects, jobs, appstory.NewJobTimeline(memrepo.NewJobEventRepository(store)))
+
+	project := entity.NewProject("tenant-1", "owner-1", "测试项目")
+	if err := projects.Create(ctx, project); err != nil {
+		t.Fatal(err)
+	}
+	art, err := artifacts.EnsureArtifact(ctx, project.TenantID, project.ID, entity.ArtifactTypeOutline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := &entity.ArtifactVersion{ID: "ov1", ArtifactID: art.ID, VersionNo: 1, BranchKey: "main",
+		Content: outlineContent(t, outlineVolume("v1", outlineChapter("c1", "觉醒"), outlineChapter("c2", "拜师")))}
+	v2 := &entity.ArtifactVersion{ID: "ov2", ArtifactID: art.ID, VersionNo: 2, BranchKey: "main",
+		Content: outlineContent(t, outlineVolume("v1", outlineChapter("c1", "觉醒"), outlineChapter("c2", "拜师失败")))}
+	for _, v := range []*entity.ArtifactVersion{v1, v2} {
+		if err := artifacts.CreateVersion(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	linked := entity.NewChapter(project.ID, "", 1)
+	linked.OutlineKey = "c2"
+	other := entity.NewChapter(project.ID, "", 2)
+	other.OutlineKey = "c1"
+	for _, ch := range []*entity.Chapter{linked, other} {
+		if err := chapters.Create(ctx, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 首次激活没有可比较的旧版本
+	if mark, err := tracker.MarkActivated(ctx, art, nil, v1); err != nil || mark != nil {
+		t.Fatalf("first activation should not mark, got %+v, %v", mark, err)
+	}
+
+	mark, err := tracker.MarkActivated(ctx, art, &v1.ID, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mark == nil || !reflect.DeepEqual(mark.ChapterIDs, []string{linked.ID}) || mark.RefreshJob != nil {
+		t.Fatalf("only the chapter linked to c2 should be marked without refresh, got %+v", mark)
+	}
+	got, _ := chapters.GetByID(ctx, linked.ID)
+	if !got.OutlineStale || got.OutlineStaleSince == nil {
+		t.Fatalf("chapter not marked stale: %+v", got)
+	}
+	if untouched, _ := chapters.GetByID(ctx, other.ID); untouched.OutlineStale {
+		t.Fatal("unchanged outline item should not be marked")
+	}
+
+	// 开启 outline_refresh 后回滚到旧版本：再次标记并创建刷新任务
+	project.Settings = &entity.ProjectSettings{OutlineRefresh: true}
+	if err := projects.Update(ctx, project); err != nil {
+		t.Fatal(err)
+	}
+	mark, err = tracker.MarkActivated(ctx, art, &v2.ID, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mark == nil || mark.RefreshJob == nil || mark.RefreshJob.JobType != entity.JobTypeOutlineRefresh {
+		t.Fatalf("refresh job expected, got %+v", mark)
+	}
+	if stored, _ := jobs.GetByID(ctx, mark.RefreshJob.ID); stored == nil {
+		t.Fatal("refresh job not persisted")
+	}
+}
@@ -0,0 +1,206 @@
+// Package pacing 基于章节文本统计计算节奏曲线，帮助作者发现拖沓的中段。
+package pacing
+
+import (
+	"math"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+const (
+	// DefaultWindow 滚动平均默认窗口（章节数）
+	DefaultWindow = 3
+	// MaxWindow 滚动平均最大窗口
+	MaxWindow = 10
+
+	// minChaptersForSagging 判定拖沓所需的最少已分析章节数（过少时均值与标准差无意义）
+	minChaptersForSagging = 4
+
+	// 强度分项权重：对白占比、场景切换密度、段落短促度
+	dialogueWeight  = 0.45
+	sceneWeight     = 0.30
+	paragraphWeight = 0.25
+
+	// dialogueSaturation 对白占比达到该值即视为满分
+	dialogueSaturation = 0.6
+	// sceneSaturation 每千字场景数达到该值即视为满分
+	sceneSaturation = 2.0
+	// 平均段落字数在 [shortParagraph, longParagraph] 间线性从满分降到 0
+	shortParagraph = 40.0
+	longParagraph  = 240.0
+)
+
+// ChapterPacing 单章节奏数据
+type ChapterPacing struct {
+	ChapterID      string  `json:"chapter_id"`
+	VolumeID       string  `json:"volume_id,omitempty"`
+	SeqNum         int     `json:"seq_num"`
+	Title          string  `json:"title,omitempty"`
+	Position       int     `json:"position"`
+	WordCount      int     `json:"word_count"`
+	DialogueRatio  float64 `json:"dialogue_ratio"`
+	SceneCount     int     `json:"scene_count"`
+	ParagraphCount int     `json:"paragraph_count"`
+	// Intensity 单章节奏强度（0-100，越高越紧凑）
+	Intensity float64 `json:"intensity"`
+	// RollingScore 截至本章的滚动平均强度（窗口内仅计已分析章节）
+	RollingScore float64 `json:"rolling_score"`
+	// Sagging 滚动分低于全书均值一个标准差
+	Sagging bool `json:"sagging"`
+	// Analyzed 是否有可分析的正文（空章节不参与滚动平均与拖沓判定）
+	Analyzed bool `json:"analyzed"`
+}
+
+// SaggingRange 连续拖沓区间（按叙事位置，闭区间）
+type SaggingRange struct {
+	StartChapterID string `json:"start_chapter_id"`
+	EndChapterID   string `json:"end_chapter_id"`
+	StartPosition  int    `json:"start_position"`
+	EndPosition    int    `json:"end_position"`
+}
+
+// Report 项目节奏报告
+type Report struct {
+	Window        int             `json:"window"`
+	TotalWords    int             `json:"total_words"`
+	AverageScore  float64         `json:"average_score"`
+	StdDev        float64         `json:"std_dev"`
+	SagThreshold  float64         `json:"sag_threshold"`
+	Chapters      []ChapterPacing `json:"chapters"`
+	SaggingRanges []SaggingRange  `json:"sagging_ranges"`
+	AnalyzedCount int             `json:"analyzed_count"`
+	ChapterCount  int             `json:"chapter_count"`
+}
+
+// NormalizeWindow 将窗口约束到 [1, MaxWindow]，非正数回退为默认值
+func NormalizeWindow(window int) int {
+	switch {
+	case window <= 0:
+		return DefaultWindow
+	case window > MaxWindow:
+		return MaxWindow
+	default:
+		return window
+	}
+}
+
+// Intensity 单章节奏强度：对白越多、场景切换越频繁、段落越短，节奏越紧凑
+func Intensity(s *entity.ChapterStats) float64 {
+	if s == nil || s.WordCount == 0 {
+		return 0
+	}
+	dialogue := clamp01(s.DialogueRatio / dialogueSaturation)
+	scenes := clamp01(float64(s.SceneCount) * 1000 / float64(s.WordCount) / sceneSaturation)
+	paragraphs := 0.0
+	if s.ParagraphCount > 0 {
+		paragraphs = clamp01((longParagraph - s.AvgParagraphChars()) / (longParagraph - shortParagraph))
+	}
+	return round2(100 * (dialogueWeight*dialogue + sceneWeight*scenes + paragraphWeight*paragraphs))
+}
+
+// Analyze 按叙事顺序的章节（不含正文）与其统计生成节奏报告；缺少统计的章节视为未分析
+func Analyze(chapters []*entity.Chapter, stats map[string]*entity.ChapterStats, window int) *Report {
+	window = NormalizeWindow(window)
+	report := &Report{
+		Window:        window,
+		Chapters:      make([]ChapterPacing, 0, len(chapters)),
+		SaggingRanges: []SaggingRange{},
+		ChapterCount:  len(chapters),
+	}
+
+	var recent []float64
+	var rolling []float64
+	for i, c := range chapters {
+		cp := ChapterPacing{
+			ChapterID: c.ID,
+			VolumeID:  c.VolumeID,
+			SeqNum:    c.SeqNum,
+			Title:     c.Title,
+			Position:  i + 1,
+		}
+		if s := stats[c.ID]; s != nil && s.WordCount > 0 {
+			cp.WordCount = s.WordCount
+			cp.DialogueRatio = round2(s.DialogueRatio)
+			cp.SceneCount = s.SceneCount
+			cp.ParagraphCount = s.ParagraphCount
+			cp.Intensity = Intensity(s)
+			cp.Analyzed = true
+
+			recent = append(recent, cp.Intensity)
+			if len(recent) > window {
+				recent = recent[1:]
+			}
+			cp.RollingScore = round2(mean(recent))
+			rolling = append(rolling, cp.RollingScore)
+			report.TotalWords += s.WordCount
+			report.AnalyzedCount++
+		}
+		report.Chapters = append(report.Chapters, cp)
+	}
+
+	if len(rolling) == 0 {
+		return report
+	}
+	avg := mean(rolling)
+	sd := stddev(rolling, avg)
+	report.AverageScore = round2(avg)
+	report.StdDev = round2(sd)
+	report.SagThreshold = round2(avg - sd)
+	if len(rolling) < minChaptersForSagging || sd == 0 {
+		return report
+	}
+
+	var current *SaggingRange
+	for i := range report.Chapters {
+		cp := &report.Chapters[i]
+		if !cp.Analyzed {
+			continue
+		}
+		cp.Sagging = cp.RollingScore < avg-sd
+		switch {
+		case cp.Sagging && current == nil:
+			current = &SaggingRange{StartChapterID: cp.ChapterID, StartPosition: cp.Position}
+			fallthrough
+		case cp.Sagging:
+			current.EndChapterID = cp.ChapterID
+			current.EndPosition = cp.Position
+		case current != nil:
+			report.SaggingRanges = append(report.SaggingRanges, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		report.SaggingRanges = append(report.SaggingRanges, *current)
+	}
+	return report
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stddev(values []float64, avg float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
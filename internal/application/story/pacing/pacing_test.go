@@ -0,0 +1,58 @@
+package pacing
+
+import (
+	"strings"
+	"testing"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+func TestAnalyzeChapterText(t *testing.T) {
+	content := "林默推开门。\n\n“你来了。”她说，“坐吧。”\n\n* * *\n\n夜色渐深，「走吧」他低声道。\n"
+	s := entity.AnalyzeChapterText(content)
+	if s.Paragraphs != 3 || s.Scenes != 2 {
+		t.Fatalf("paragraphs=%d scenes=%d, want 3 and 2", s.Paragraphs, s.Scenes)
+	}
+	// 对白：你来了。 坐吧。 走吧
+	if s.DialogueChars != 9 {
+		t.Errorf("dialogue chars = %d, want 9", s.DialogueChars)
+	}
+	if s.NarrationChars != 6+3+10 {
+		t.Errorf("narration chars = %d, want 19", s.NarrationChars)
+	}
+	if empty := entity.AnalyzeChapterText("  \n"); empty.Scenes != 0 || empty.DialogueRatio() != 0 {
+		t.Errorf("empty text should yield zero stats, got %+v", empty)
+	}
+}
+
+func TestAnalyzeFlagsSaggingMiddle(t *testing.T) {
+	brisk := strings.Repeat("“快走！”他喊。\n", 20) + "***\n" + strings.Repeat("“追！”\n", 20)
+	slow := strings.Repeat(strings.Repeat("山风吹过荒原，草木低伏，远处的城墙在暮色里模糊成一条灰线。", 6)+"\n", 8)
+
+	contents := []string{brisk, brisk, brisk, slow, slow, slow, brisk, brisk, "", brisk}
+	chapters := make([]*entity.Chapter, len(contents))
+	stats := map[string]*entity.ChapterStats{}
+	for i, text := range contents {
+		c := &entity.Chapter{ID: string(rune('a' + i)), ProjectID: "p", SeqNum: i + 1, ContentText: text}
+		chapters[i] = c
+		stats[c.ID] = entity.NewChapterStats(c)
+	}
+
+	report := Analyze(chapters, stats, 2)
+	if report.AnalyzedCount != 9 || report.Chapters[8].Analyzed {
+		t.Fatalf("empty chapter should be skipped, analyzed=%d", report.AnalyzedCount)
+	}
+	if report.Chapters[0].Intensity <= report.Chapters[3].Intensity {
+		t.Fatalf("brisk chapter intensity %.2f should exceed slow %.2f", report.Chapters[0].Intensity, report.Chapters[3].Intensity)
+	}
+	if len(report.SaggingRanges) != 1 {
+		t.Fatalf("expected one sagging range, got %+v", report.SaggingRanges)
+	}
+	r := report.SaggingRanges[0]
+	if r.StartPosition < 4 || r.EndPosition > 7 {
+		t.Errorf("sagging range %d-%d should sit in the slow middle", r.StartPosition, r.EndPosition)
+	}
+	if report.Chapters[0].Sagging || report.Chapters[9].Sagging {
+		t.Errorf("brisk chapters should not be flagged")
+	}
+}
@@ -0,0 +1,39 @@
+package story
+
+// StreamProgress 按已生成字数 / 目标字数将流式生成折算为任务进度，并按步长节流上报。
+//
+// 进度在 [from, to] 区间内线性增长（超出目标字数时封顶 to），100 留给收尾落库。
+type StreamProgress struct {
+	from   int
+	to     int
+	step   int
+	target int
+	last   int
+}
+
+// NewStreamProgress 创建流式进度折算器；step 为两次上报之间的最小进度增量
+func NewStreamProgress(from, to, target, step int) *StreamProgress {
+	if target <= 0 {
+		target = 1
+	}
+	if step <= 0 {
+		step = 1
+	}
+	return &StreamProgress{from: from, to: to, step: step, target: target, last: from}
+}
+
+// Advance 根据累计已生成字数计算进度；仅当较上次上报增长达到步长（或首次到达上限）时返回 ok=true
+func (p *StreamProgress) Advance(generated int) (progress int, ok bool) {
+	if p == nil {
+		return 0, false
+	}
+	progress = p.from + generated*(p.to-p.from)/p.target
+	if progress > p.to {
+		progress = p.to
+	}
+	if progress-p.last < p.step && !(progress == p.to && p.last < p.to) {
+		return p.last, false
+	}
+	p.last = progress
+	return progress, true
+}
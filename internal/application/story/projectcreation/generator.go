@@ -34,6 +34,14 @@ func NewProjectCreationGenerator(factory workflowport.ChatModelFactory) *Project
 	}
 }
 
+// Warmup 预热项目创建工作流（编译对话链）
+func (g *ProjectCreationGenerator) Warmup(ctx context.Context) error {
+	if g == nil || g.chain == nil {
+		return fmt.Errorf("project creation workflow not configured")
+	}
+	return g.chain.Warmup(ctx)
+}
+
 // Generate 执行生成流程：Prompt 渲染 -> LLM 调用 (Structured Output) -> 结果解析
 func (g *ProjectCreationGenerator) Generate(ctx context.Context, in *wfmodel.ProjectCreationGenerateInput) (*wfmodel.ProjectCreationGenerateOutput, error) {
 	if g == nil || g.chain == nil {
@@ -0,0 +1,141 @@
+package story
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// reindexClaimBatch 每轮最多领取的到期重建请求数
+const reindexClaimBatch = 20
+
+// ReindexRequest 章节重建索引请求
+type ReindexRequest struct {
+	TenantID  string
+	ProjectID string
+	ChapterID string
+}
+
+// ReindexQueue 章节重建索引请求队列（port）：同一章节重复入队只保留一条，到期时间推迟到 now+debounce，
+// 但不晚于首次入队后 maxDelay（maxDelay <= 0 表示不封顶）。由基础设施层提供原子实现（例如 Redis ZSET）。
+type ReindexQueue interface {
+	Schedule(ctx context.Context, req ReindexRequest, now time.Time, debounce, maxDelay time.Duration) error
+	// ClaimDue 领取（并移出队列）到期的请求
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]ReindexRequest, error)
+}
+
+// ChapterReindexer 编辑后自动重建章节索引：API 在正文变化的保存后排队（debounce 合并连续保存），
+// Worker 周期性领取到期请求，读取最新正文写入向量索引。
+type ChapterReindexer struct {
+	queue       ReindexQueue
+	chapterRepo repository.ChapterRepository
+	finalizer   *GenerationFinalizer
+	txMgr       repository.Transactor
+	tenantCtx   repository.TenantContextManager
+
+	debounce time.Duration
+	maxDelay time.Duration
+	now      func() time.Time
+}
+
+// NewChapterReindexer 创建章节自动重建索引服务（queue 为 nil 时不排队）
+func NewChapterReindexer(
+	queue ReindexQueue,
+	chapterRepo repository.ChapterRepository,
+	finalizer *GenerationFinalizer,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	debounce, maxDelay time.Duration,
+) *ChapterReindexer {
+	return &ChapterReindexer{
+		queue:       queue,
+		chapterRepo: chapterRepo,
+		finalizer:   finalizer,
+		txMgr:       txMgr,
+		tenantCtx:   tenantCtx,
+		debounce:    debounce,
+		maxDelay:    maxDelay,
+		now:         time.Now,
+	}
+}
+
+// RequestIfChanged 章节保存（ChapterRepository.Update）改变了正文时排队重建索引；排队失败仅记录日志
+func (r *ChapterReindexer) RequestIfChanged(ctx context.Context, tenantID string, chapter *entity.Chapter) {
+	if r == nil || r.queue == nil || chapter == nil || !chapter.ContentChanged {
+		return
+	}
+	req := ReindexRequest{TenantID: tenantID, ProjectID: chapter.ProjectID, ChapterID: chapter.ID}
+	if err := r.queue.Schedule(ctx, req, r.now(), r.debounce, r.maxDelay); err != nil {
+		logger.Warn(ctx, "failed to schedule chapter reindex", "error", err.Error(), "chapter_id", chapter.ID)
+	}
+}
+
+// Run 周期性处理到期的重建请求，直至 ctx 取消
+func (r *ChapterReindexer) Run(ctx context.Context, interval time.Duration) {
+	if r == nil || r.queue == nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(ctx, "chapter reindex sweep failed", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue 领取并处理一批到期请求，返回成功重建的章节数。单个章节失败只记录日志（不重新排队，下次保存会再次触发）
+func (r *ChapterReindexer) ProcessDue(ctx context.Context) (int, error) {
+	reqs, err := r.queue.ClaimDue(ctx, r.now(), reindexClaimBatch)
+	if err != nil {
+		return 0, err
+	}
+	indexed := 0
+	for _, req := range reqs {
+		if ctx.Err() != nil {
+			return indexed, ctx.Err()
+		}
+		if err := r.reindex(ctx, req); err != nil {
+			logger.Warn(ctx, "failed to reindex edited chapter", "error", err.Error(), "chapter_id", req.ChapterID)
+			continue
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+// reindex 在租户事务内读取最新正文并准备索引快照，提交后写入向量索引。
+// 章节已删除或正在生成（生成收尾会自行写索引）时跳过。
+func (r *ChapterReindexer) reindex(ctx context.Context, req ReindexRequest) error {
+	var snapshot *ChapterIndexSnapshot
+	if err := r.txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := r.tenantCtx.SetTenant(txCtx, req.TenantID); err != nil {
+			return err
+		}
+		chapter, err := r.chapterRepo.GetByID(txCtx, req.ChapterID)
+		if err != nil {
+			return err
+		}
+		if chapter == nil || chapter.Status == entity.ChapterStatusGenerating {
+			return nil
+		}
+		snapshot = r.finalizer.IndexSnapshot(txCtx, chapter)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to load chapter: %w", err)
+	}
+	if snapshot == nil {
+		return nil
+	}
+	return r.finalizer.IndexChapter(ctx, req.TenantID, snapshot)
+}
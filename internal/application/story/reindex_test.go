@@ -0,0 +1,55 @@
+package story
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeReindexQueue struct {
+	scheduled []ReindexRequest
+	due       []ReindexRequest
+}
+
+func (q *fakeReindexQueue) Schedule(_ context.Context, req ReindexRequest, _ time.Time, _, _ time.Duration) error {
+	q.scheduled = append(q.scheduled, req)
+	return nil
+}
+
+func (q *fakeReindexQueue) ClaimDue(context.Context, time.Time, int) ([]ReindexRequest, error) {
+	due := q.due
+	q.due = nil
+	return due, nil
+}
+
+func TestChapterReindexerSchedulesOnlyContentChanges(t *testing.T) {
+	f := newFinalizerFixture(t)
+	ctx := context.Background()
+	queue := &fakeReindexQueue{}
+	store := f.chapters
+	r := NewChapterReindexer(queue, store, f.finalizer, nil, nil, time.Second, time.Minute)
+
+	chapter, err := store.GetByID(ctx, f.chapter.ID)
+	mustNoErr(t, err)
+	chapter.ContentText = "林默推开门。"
+	mustNoErr(t, store.Update(ctx, chapter))
+	r.RequestIfChanged(ctx, testTenantID, chapter)
+
+	// 仅修改标题不排队
+	chapter.Title = "新标题"
+	mustNoErr(t, store.Update(ctx, chapter))
+	r.RequestIfChanged(ctx, testTenantID, chapter)
+
+	if len(queue.scheduled) != 1 {
+		t.Fatalf("expected 1 scheduled reindex, got %d", len(queue.scheduled))
+	}
+	got := queue.scheduled[0]
+	if got.TenantID != testTenantID || got.ProjectID != f.project.ID || got.ChapterID != chapter.ID {
+		t.Fatalf("unexpected request %+v", got)
+	}
+
+	// 未配置队列时不排队也不报错
+	var disabled *ChapterReindexer
+	disabled.RequestIfChanged(ctx, testTenantID, chapter)
+	NewChapterReindexer(nil, store, f.finalizer, nil, nil, 0, 0).RequestIfChanged(ctx, testTenantID, chapter)
+}
@@ -0,0 +1,178 @@
+package story
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// ErrChapterNotInProject 参照章节不属于关系所在项目
+var ErrChapterNotInProject = errors.New("chapter not found in project")
+
+// WeightedRelation 按时效加权后的关系
+type WeightedRelation struct {
+	Relation *entity.Relation
+	// EffectiveStrength 衰减后的有效强度
+	EffectiveStrength float64
+	// ChaptersSince 参照章节距最近强化章节的章数（无强化记录时为 0）
+	ChaptersSince int
+}
+
+// RelationWeigher 关系时效加权：按距最近强化章节的章数做半衰期衰减，
+// 并在章节完成后强化同场出现的实体之间的关系，避免早已失效的关系长期占据高位。
+type RelationWeigher struct {
+	chapterRepo      repository.ChapterRepository
+	relationRepo     repository.RelationRepository
+	halfLifeChapters int
+}
+
+// NewRelationWeigher 创建关系时效加权器；halfLifeChapters <= 0 时不衰减
+func NewRelationWeigher(chapterRepo repository.ChapterRepository, relationRepo repository.RelationRepository, halfLifeChapters int) *RelationWeigher {
+	return &RelationWeigher{
+		chapterRepo:      chapterRepo,
+		relationRepo:     relationRepo,
+		halfLifeChapters: halfLifeChapters,
+	}
+}
+
+// Weigh 以 atChapterID 为当前位置（为空时取项目叙事顺序最后一章）计算关系的有效强度，保持输入顺序。
+func (w *RelationWeigher) Weigh(ctx context.Context, projectID, atChapterID string, relations []*entity.Relation) ([]WeightedRelation, error) {
+	ordinals, err := w.chapterOrdinals(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := len(ordinals) - 1
+	if atChapterID != "" {
+		pos, ok := ordinals[atChapterID]
+		if !ok {
+			return nil, ErrChapterNotInProject
+		}
+		current = pos
+	}
+
+	out := make([]WeightedRelation, 0, len(relations))
+	for _, rel := range relations {
+		if rel == nil {
+			continue
+		}
+		since := 0
+		if anchor, ok := ordinals[rel.RecencyAnchor()]; ok && current > anchor {
+			since = current - anchor
+		}
+		out = append(out, WeightedRelation{
+			Relation:          rel,
+			EffectiveStrength: entity.DecayedStrength(rel.Strength, since, w.halfLifeChapters),
+			ChaptersSince:     since,
+		})
+	}
+	return out, nil
+}
+
+// RelatedStrengths 返回与 entityIDs 存在关系的其他实体及其以项目最新章节为参照的有效强度（多条关系取最大值），
+// 满足 retrieval.RelatedEntityScorer，供检索按关系时效加权。
+func (w *RelationWeigher) RelatedStrengths(ctx context.Context, projectID string, entityIDs []string) (map[string]float64, error) {
+	if w == nil || w.relationRepo == nil || len(entityIDs) == 0 {
+		return nil, nil
+	}
+	relations, err := w.relationRepo.GetRelationGraph(ctx, projectID, entityIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return nil, nil
+	}
+	weighted, err := w.Weigh(ctx, projectID, "", relations)
+	if err != nil {
+		return nil, err
+	}
+
+	focus := make(map[string]struct{}, len(entityIDs))
+	for _, id := range entityIDs {
+		focus[id] = struct{}{}
+	}
+	out := make(map[string]float64)
+	for _, wr := range weighted {
+		for _, id := range []string{wr.Relation.SourceEntityID, wr.Relation.TargetEntityID} {
+			if _, ok := focus[id]; ok {
+				continue
+			}
+			if wr.EffectiveStrength > out[id] {
+				out[id] = wr.EffectiveStrength
+			}
+		}
+	}
+	return out, nil
+}
+
+// SortByEffectiveStrength 按有效强度降序排列（稳定排序）
+func SortByEffectiveStrength(weighted []WeightedRelation) {
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].EffectiveStrength > weighted[j].EffectiveStrength
+	})
+}
+
+// Reinforce 章节完成后强化 entityIDs 两两之间已有的关系（失败仅记录日志）。
+// 已被叙事上更晚的章节强化过的关系不回退；同一章节重复生成不重复强化。
+func (w *RelationWeigher) Reinforce(ctx context.Context, chapter *entity.Chapter, entityIDs []string) {
+	if w == nil || w.relationRepo == nil || chapter == nil || len(entityIDs) < 2 {
+		return
+	}
+
+	relations, err := w.relationRepo.GetRelationGraph(ctx, chapter.ProjectID, entityIDs)
+	if err != nil {
+		logger.Warn(ctx, "failed to load relations for reinforcement", "error", err.Error(), "chapter_id", chapter.ID)
+		return
+	}
+	if len(relations) == 0 {
+		return
+	}
+	ordinals, err := w.chapterOrdinals(ctx, chapter.ProjectID)
+	if err != nil {
+		logger.Warn(ctx, "failed to load chapter order for reinforcement", "error", err.Error(), "chapter_id", chapter.ID)
+		return
+	}
+	current, ok := ordinals[chapter.ID]
+	if !ok {
+		return
+	}
+
+	involved := make(map[string]struct{}, len(entityIDs))
+	for _, id := range entityIDs {
+		involved[id] = struct{}{}
+	}
+	for _, rel := range relations {
+		if rel == nil {
+			continue
+		}
+		_, src := involved[rel.SourceEntityID]
+		_, dst := involved[rel.TargetEntityID]
+		if !src || !dst {
+			continue
+		}
+		if anchor, ok := ordinals[rel.RecencyAnchor()]; ok && anchor >= current {
+			continue
+		}
+		rel.Reinforce(chapter.ID)
+		if err := w.relationRepo.UpdateReinforcement(ctx, rel); err != nil {
+			logger.Warn(ctx, "failed to reinforce relation", "error", err.Error(), "relation_id", rel.ID, "chapter_id", chapter.ID)
+		}
+	}
+}
+
+// chapterOrdinals 章节 ID -> 叙事顺序序号（从 0 开始）
+func (w *RelationWeigher) chapterOrdinals(ctx context.Context, projectID string) (map[string]int, error) {
+	chapters, err := w.chapterRepo.ListTimeline(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	ordinals := make(map[string]int, len(chapters))
+	for i, ch := range chapters {
+		ordinals[ch.ID] = i
+	}
+	return ordinals, nil
+}
@@ -0,0 +1,177 @@
+// Package series 提供系列（多部曲）相关的应用服务：跨书检索范围、设定继承与系列统计。
+package series
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// CanonArtifact 项目生效的设定构件（可能继承自前作）
+type CanonArtifact struct {
+	Type entity.ArtifactType
+	// SourceProjectID 构件实际所属项目；Inherited 为 true 时为前作项目
+	SourceProjectID string
+	Inherited       bool
+	Version         *entity.ArtifactVersion
+}
+
+// Stats 系列统计信息
+type Stats struct {
+	SeriesID       string
+	TotalProjects  int
+	TotalChapters  int
+	TotalEntities  int
+	TotalWordCount int64
+	Projects       []*repository.SeriesProjectStats
+}
+
+// SeriesService 系列服务
+type SeriesService struct {
+	seriesRepo   repository.SeriesRepository
+	projectRepo  repository.ProjectRepository
+	artifactRepo repository.ArtifactRepository
+}
+
+// NewSeriesService 创建系列服务
+func NewSeriesService(
+	seriesRepo repository.SeriesRepository,
+	projectRepo repository.ProjectRepository,
+	artifactRepo repository.ArtifactRepository,
+) *SeriesService {
+	return &SeriesService{
+		seriesRepo:   seriesRepo,
+		projectRepo:  projectRepo,
+		artifactRepo: artifactRepo,
+	}
+}
+
+// EarlierProjects 返回同系列中排在 project 之前的项目（由近到远）；未加入系列时返回 nil。
+func (s *SeriesService) EarlierProjects(ctx context.Context, project *entity.Project) ([]*entity.Project, error) {
+	if s == nil || project == nil || project.SeriesID == nil || strings.TrimSpace(*project.SeriesID) == "" {
+		return nil, nil
+	}
+	projects, err := s.seriesRepo.ListProjects(ctx, *project.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	var earlier []*entity.Project
+	for i := len(projects) - 1; i >= 0; i-- {
+		p := projects[i]
+		if p == nil || p.ID == project.ID || p.SeriesOrder >= project.SeriesOrder {
+			continue
+		}
+		earlier = append(earlier, p)
+	}
+	return earlier, nil
+}
+
+// RetrievalProjectIDs 返回可供 project 只读召回的前作项目 ID；项目未开启 series_retrieval 时返回 nil。
+func (s *SeriesService) RetrievalProjectIDs(ctx context.Context, project *entity.Project) ([]string, error) {
+	if project == nil || project.Settings == nil || !project.Settings.SeriesRetrieval {
+		return nil, nil
+	}
+	return s.earlierProjectIDs(ctx, project)
+}
+
+// EarlierProjectIDs 按项目 ID 返回同系列前作项目 ID（不检查 series_retrieval，供显式请求跨书检索时使用）。
+func (s *SeriesService) EarlierProjectIDs(ctx context.Context, projectID string) ([]string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, nil
+	}
+	return s.earlierProjectIDs(ctx, project)
+}
+
+func (s *SeriesService) earlierProjectIDs(ctx context.Context, project *entity.Project) ([]string, error) {
+	earlier, err := s.EarlierProjects(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(earlier))
+	for _, p := range earlier {
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+// ResolveCanon 解析项目生效的设定构件：本书已有激活版本时优先（单书覆盖），
+// 否则沿系列顺序向前查找最近一部有激活版本的前作。均不存在时返回 nil。
+func (s *SeriesService) ResolveCanon(ctx context.Context, project *entity.Project, artifactType entity.ArtifactType) (*CanonArtifact, error) {
+	if project == nil {
+		return nil, nil
+	}
+	if !entity.IsSeriesInheritable(artifactType) {
+		return nil, fmt.Errorf("artifact type %s is not inheritable", artifactType)
+	}
+
+	v, err := s.activeVersion(ctx, project.ID, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		return &CanonArtifact{Type: artifactType, SourceProjectID: project.ID, Version: v}, nil
+	}
+
+	earlier, err := s.EarlierProjects(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range earlier {
+		v, err := s.activeVersion(ctx, p.ID, artifactType)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			return &CanonArtifact{Type: artifactType, SourceProjectID: p.ID, Inherited: true, Version: v}, nil
+		}
+	}
+	return nil, nil
+}
+
+// activeVersion 获取项目指定类型构件的激活版本
+func (s *SeriesService) activeVersion(ctx context.Context, projectID string, artifactType entity.ArtifactType) (*entity.ArtifactVersion, error) {
+	arts, err := s.artifactRepo.ListArtifactsByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range arts {
+		if a == nil || a.Type != artifactType {
+			continue
+		}
+		if a.ActiveVersionID == nil || strings.TrimSpace(*a.ActiveVersionID) == "" {
+			return nil, nil
+		}
+		return s.artifactRepo.GetVersionByID(ctx, *a.ActiveVersionID)
+	}
+	return nil, nil
+}
+
+// GetStats 汇总系列统计信息
+func (s *SeriesService) GetStats(ctx context.Context, seriesID string) (*Stats, error) {
+	projects, err := s.seriesRepo.GetProjectStats(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{
+		SeriesID:      seriesID,
+		TotalProjects: len(projects),
+		Projects:      projects,
+	}
+	for _, p := range projects {
+		stats.TotalChapters += p.TotalChapters
+		stats.TotalEntities += p.TotalEntities
+		stats.TotalWordCount += p.TotalWordCount
+	}
+	return stats, nil
+}
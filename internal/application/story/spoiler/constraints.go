@@ -0,0 +1,166 @@
+package spoiler
+
+import (
+	"fmt"
+	"strings"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// excerptRunes 违规片段前后各保留的字数
+const excerptRunes = 20
+
+// ActiveGuard 对当前章节生效的剧透保护及其扫描词
+type ActiveGuard struct {
+	Guard *entity.SpoilerGuard
+	Terms []string
+}
+
+// Constraints 章节生效的剧透约束；零值/nil 表示无约束
+type Constraints struct {
+	Guards []ActiveGuard
+}
+
+// Violation 生成内容中出现受保护对象
+type Violation struct {
+	GuardID string                  `json:"guard_id"`
+	Kind    entity.SpoilerGuardKind `json:"kind"`
+	Label   string                  `json:"label"`
+	Term    string                  `json:"term"`
+	Offset  int                     `json:"offset"`
+	Excerpt string                  `json:"excerpt"`
+}
+
+// Empty 是否没有生效约束
+func (c *Constraints) Empty() bool {
+	return c == nil || len(c.Guards) == 0
+}
+
+// PromptBlock 渲染否定约束 Prompt 块
+func (c *Constraints) PromptBlock() string {
+	if c.Empty() {
+		return ""
+	}
+	lines := make([]string, 0, len(c.Guards)+2)
+	lines = append(lines, "【禁止提前揭示（本章不得出现，尚未到揭晓时机）】")
+	for i, ag := range c.Guards {
+		g := ag.Guard
+		var line string
+		switch g.Kind {
+		case entity.SpoilerGuardEntity:
+			line = fmt.Sprintf("[N%d] 角色/设定「%s」：不得出场、不得被提及或暗示", i+1, g.Label)
+			if aliases := otherTerms(ag.Terms, g.Label); len(aliases) > 0 {
+				line += "（含：" + strings.Join(aliases, "、") + "）"
+			}
+		case entity.SpoilerGuardEvent:
+			line = fmt.Sprintf("[N%d] 事件「%s」：不得提前发生、被预告或回顾", i+1, g.Label)
+		default:
+			line = fmt.Sprintf("[N%d] 事实「%s」：不得点明或暗示", i+1, g.Label)
+		}
+		if g.Note != "" {
+			line += "（备注：" + g.Note + "）"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "以上内容即使出现在参考上下文中，也不得写入本章正文。")
+	return strings.Join(lines, "\n")
+}
+
+// FilterSegments 剔除涉及受保护实体或包含受保护词的召回片段
+func (c *Constraints) FilterSegments(segments []appretrieval.Segment) []appretrieval.Segment {
+	if c.Empty() || len(segments) == 0 {
+		return segments
+	}
+	out := make([]appretrieval.Segment, 0, len(segments))
+	for _, seg := range segments {
+		if c.Excludes(seg) {
+			continue
+		}
+		out = append(out, seg)
+	}
+	return out
+}
+
+// Excludes 片段是否因剧透保护被剔除
+func (c *Constraints) Excludes(seg appretrieval.Segment) bool {
+	if c.Empty() {
+		return false
+	}
+	for _, ag := range c.Guards {
+		if ag.Guard.Kind == entity.SpoilerGuardEntity && ag.Guard.RefID != nil {
+			for _, id := range seg.InvolvedEntities {
+				if id == *ag.Guard.RefID {
+					return true
+				}
+			}
+		}
+		for _, term := range ag.Terms {
+			if strings.Contains(seg.Text, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Scan 扫描生成内容，每条保护最多报告一次（取最早出现的位置）
+func (c *Constraints) Scan(text string) []Violation {
+	if c.Empty() || text == "" {
+		return nil
+	}
+	var out []Violation
+	for _, ag := range c.Guards {
+		best, bestTerm := -1, ""
+		for _, term := range ag.Terms {
+			if idx := strings.Index(text, term); idx >= 0 && (best < 0 || idx < best) {
+				best, bestTerm = idx, term
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		offset := len([]rune(text[:best]))
+		out = append(out, Violation{
+			GuardID: ag.Guard.ID,
+			Kind:    ag.Guard.Kind,
+			Label:   ag.Guard.Label,
+			Term:    bestTerm,
+			Offset:  offset,
+			Excerpt: excerpt(text, offset, len([]rune(bestTerm))),
+		})
+	}
+	return out
+}
+
+// Summary 违规概要（用于时间线与告警消息）
+func Summary(violations []Violation) string {
+	labels := make([]string, 0, len(violations))
+	for _, v := range violations {
+		labels = append(labels, v.Label)
+	}
+	return "spoiler guard violated: " + strings.Join(labels, ", ")
+}
+
+func excerpt(text string, offset, length int) string {
+	r := []rune(text)
+	start := offset - excerptRunes
+	if start < 0 {
+		start = 0
+	}
+	end := offset + length + excerptRunes
+	if end > len(r) {
+		end = len(r)
+	}
+	return strings.Join(strings.Fields(string(r[start:end])), " ")
+}
+
+func otherTerms(terms []string, label string) []string {
+	out := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t != label {
+			out = append(out, t)
+		}
+	}
+	return out
+}
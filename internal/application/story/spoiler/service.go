@@ -0,0 +1,267 @@
+// Package spoiler 提供剧透保护（"暂不提及"约束）：在揭晓点之前的章节中，
+// 从召回结果剔除受保护对象的片段、向 Prompt 注入否定约束，并在生成后扫描违规。
+package spoiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+const (
+	// MaxKeywords 单条剧透保护的关键词上限
+	MaxKeywords = 20
+	// minTermRunes 扫描词最短字数（过短的词误报率过高，不参与扫描与过滤）
+	minTermRunes = 2
+	// maxLabelRunes / maxKeywordRunes / maxNoteRunes 文本长度上限
+	maxLabelRunes   = 200
+	maxKeywordRunes = 50
+	maxNoteRunes    = 500
+)
+
+// ErrInvalidGuard 剧透保护不合法（类型未知、引用不存在、揭晓点缺失或跨项目等）
+var ErrInvalidGuard = errors.New("invalid spoiler guard")
+
+// Service 剧透保护服务
+//
+// 约定：所有方法均需在已 SetTenant 的上下文中调用。
+type Service struct {
+	guardRepo   repository.SpoilerGuardRepository
+	chapterRepo repository.ChapterRepository
+	volumeRepo  repository.VolumeRepository
+	entityRepo  repository.EntityRepository
+	eventRepo   repository.EventRepository
+}
+
+// NewService 创建剧透保护服务
+func NewService(
+	guardRepo repository.SpoilerGuardRepository,
+	chapterRepo repository.ChapterRepository,
+	volumeRepo repository.VolumeRepository,
+	entityRepo repository.EntityRepository,
+	eventRepo repository.EventRepository,
+) *Service {
+	return &Service{
+		guardRepo:   guardRepo,
+		chapterRepo: chapterRepo,
+		volumeRepo:  volumeRepo,
+		entityRepo:  entityRepo,
+		eventRepo:   eventRepo,
+	}
+}
+
+// Normalize 校验并规范化剧透保护：引用与揭晓点须属于同一项目，揭晓点二选一；
+// 未填写 Label 时取实体名称/事件摘要，事实类型必须提供 Label 与关键词。
+func (s *Service) Normalize(ctx context.Context, guard *entity.SpoilerGuard) error {
+	guard.Label = strings.TrimSpace(guard.Label)
+	guard.Note = strings.TrimSpace(guard.Note)
+	guard.RefID = trimOptional(guard.RefID)
+	guard.RevealChapterID = trimOptional(guard.RevealChapterID)
+	guard.RevealVolumeID = trimOptional(guard.RevealVolumeID)
+
+	keywords, err := normalizeKeywords(guard.Keywords)
+	if err != nil {
+		return err
+	}
+	guard.Keywords = keywords
+
+	if len([]rune(guard.Note)) > maxNoteRunes {
+		return fmt.Errorf("%w: note too long", ErrInvalidGuard)
+	}
+
+	switch guard.Kind {
+	case entity.SpoilerGuardEntity:
+		if guard.RefID == nil {
+			return fmt.Errorf("%w: ref_id is required for entity guards", ErrInvalidGuard)
+		}
+		ref, err := s.entityRepo.GetByID(ctx, *guard.RefID)
+		if err != nil {
+			return err
+		}
+		if ref == nil || ref.ProjectID != guard.ProjectID {
+			return fmt.Errorf("%w: entity not found", ErrInvalidGuard)
+		}
+		if guard.Label == "" {
+			guard.Label = strings.TrimSpace(ref.Name)
+		}
+	case entity.SpoilerGuardEvent:
+		if guard.RefID != nil {
+			ref, err := s.eventRepo.GetByID(ctx, *guard.RefID)
+			if err != nil {
+				return err
+			}
+			if ref == nil || ref.ProjectID != guard.ProjectID {
+				return fmt.Errorf("%w: event not found", ErrInvalidGuard)
+			}
+			if guard.Label == "" {
+				guard.Label = strings.TrimSpace(ref.Summary)
+			}
+		}
+		if len(guard.Keywords) == 0 {
+			return fmt.Errorf("%w: keywords are required for event guards", ErrInvalidGuard)
+		}
+	case entity.SpoilerGuardFact:
+		guard.RefID = nil
+		if len(guard.Keywords) == 0 {
+			return fmt.Errorf("%w: keywords are required for fact guards", ErrInvalidGuard)
+		}
+	default:
+		return fmt.Errorf("%w: kind %q is not supported", ErrInvalidGuard, guard.Kind)
+	}
+
+	if guard.Label == "" {
+		return fmt.Errorf("%w: label is required", ErrInvalidGuard)
+	}
+	if len([]rune(guard.Label)) > maxLabelRunes {
+		return fmt.Errorf("%w: label too long", ErrInvalidGuard)
+	}
+
+	switch {
+	case guard.RevealChapterID != nil && guard.RevealVolumeID != nil:
+		return fmt.Errorf("%w: only one of reveal_chapter_id and reveal_volume_id may be set", ErrInvalidGuard)
+	case guard.RevealChapterID != nil:
+		ch, err := s.chapterRepo.GetByID(ctx, *guard.RevealChapterID)
+		if err != nil {
+			return err
+		}
+		if ch == nil || ch.ProjectID != guard.ProjectID {
+			return fmt.Errorf("%w: reveal chapter not found", ErrInvalidGuard)
+		}
+	case guard.RevealVolumeID != nil:
+		vol, err := s.volumeRepo.GetByID(ctx, *guard.RevealVolumeID)
+		if err != nil {
+			return err
+		}
+		if vol == nil || vol.ProjectID != guard.ProjectID {
+			return fmt.Errorf("%w: reveal volume not found", ErrInvalidGuard)
+		}
+	default:
+		return fmt.Errorf("%w: reveal_chapter_id or reveal_volume_id is required", ErrInvalidGuard)
+	}
+	return nil
+}
+
+// ForChapter 计算章节生效的剧透约束：叙事位置早于揭晓点的保护生效。
+// 揭晓点为卷时，以该卷第一章之前为界。没有生效约束时返回空约束（可安全调用其方法）。
+func (s *Service) ForChapter(ctx context.Context, chapter *entity.Chapter) (*Constraints, error) {
+	if s == nil || chapter == nil {
+		return &Constraints{}, nil
+	}
+	guards, err := s.guardRepo.ListByProject(ctx, chapter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(guards) == 0 {
+		return &Constraints{}, nil
+	}
+
+	pos, err := s.chapterRepo.GetNarrativePosition(ctx, chapter.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Constraints{}
+	for _, g := range guards {
+		if g.RevealChapterID != nil && *g.RevealChapterID == chapter.ID {
+			continue
+		}
+		revealPos, err := s.revealPosition(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+		if revealPos <= 0 || pos >= revealPos {
+			continue
+		}
+		terms, err := s.terms(ctx, g)
+		if err != nil {
+			return nil, err
+		}
+		out.Guards = append(out.Guards, ActiveGuard{Guard: g, Terms: terms})
+	}
+	return out, nil
+}
+
+func (s *Service) revealPosition(ctx context.Context, g *entity.SpoilerGuard) (int64, error) {
+	switch {
+	case g.RevealChapterID != nil:
+		return s.chapterRepo.GetNarrativePosition(ctx, *g.RevealChapterID)
+	case g.RevealVolumeID != nil:
+		vol, err := s.volumeRepo.GetByID(ctx, *g.RevealVolumeID)
+		if err != nil || vol == nil {
+			return 0, err
+		}
+		return entity.NarrativePosition(vol.SeqNum, 0), nil
+	default:
+		return 0, nil
+	}
+}
+
+// terms 扫描词：实体类型为名称 + 别名 + 关键词，其余类型仅关键词
+func (s *Service) terms(ctx context.Context, g *entity.SpoilerGuard) ([]string, error) {
+	candidates := make([]string, 0, len(g.Keywords)+4)
+	if g.Kind == entity.SpoilerGuardEntity && g.RefID != nil {
+		e, err := s.entityRepo.GetByID(ctx, *g.RefID)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			candidates = append(candidates, e.Name)
+			candidates = append(candidates, e.Aliases...)
+		}
+	}
+	candidates = append(candidates, g.Keywords...)
+	return dedupeTerms(candidates), nil
+}
+
+func normalizeKeywords(in entity.StringSlice) (entity.StringSlice, error) {
+	if len(in) > MaxKeywords {
+		return nil, fmt.Errorf("%w: at most %d keywords", ErrInvalidGuard, MaxKeywords)
+	}
+	out := make(entity.StringSlice, 0, len(in))
+	seen := make(map[string]struct{}, len(in))
+	for i, kw := range in {
+		kw = strings.TrimSpace(kw)
+		n := len([]rune(kw))
+		if n < minTermRunes || n > maxKeywordRunes {
+			return nil, fmt.Errorf("%w: keywords[%d] must be %d-%d characters", ErrInvalidGuard, i, minTermRunes, maxKeywordRunes)
+		}
+		if _, ok := seen[kw]; ok {
+			continue
+		}
+		seen[kw] = struct{}{}
+		out = append(out, kw)
+	}
+	return out, nil
+}
+
+func dedupeTerms(in []string) []string {
+	out := make([]string, 0, len(in))
+	seen := make(map[string]struct{}, len(in))
+	for _, t := range in {
+		t = strings.TrimSpace(t)
+		if len([]rune(t)) < minTermRunes {
+			continue
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	return out
+}
+
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
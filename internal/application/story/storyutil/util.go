@@ -6,7 +6,10 @@ import (
 	"errors"
 	"io"
 	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"z-novel-ai-api/internal/domain/entity"
 )
 
 // ExtractJSONObject 尝试从一段可能包含"前后缀噪音"的文本中提取顶层 JSON（对象或数组）。
@@ -72,3 +75,35 @@ func TruncateByRunes(s string, maxRunes int) string {
 	}
 	return s
 }
+
+// EstimateTokens 以启发式规则估算文本 Token 数（不依赖具体模型的分词器）：
+// CJK 字符按 1 字 1 Token 计，其余非空白字符按约 4 字符 1 Token 计。
+func EstimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			cjk++
+		case unicode.IsSpace(r):
+		default:
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// NewPromptTruncation 构造截断明细：original 为原文，keptRunes 为实际写入 Prompt 的字数（0 表示整体丢弃），
+// 被截去部分的 Token 数按 EstimateTokens 估算
+func NewPromptTruncation(source entity.TruncationSource, name, original string, keptRunes int) entity.PromptTruncation {
+	runes := []rune(original)
+	keptRunes = max(0, min(keptRunes, len(runes)))
+	return entity.PromptTruncation{
+		Source:        source,
+		Name:          name,
+		OriginalChars: len(runes),
+		KeptChars:     keptRunes,
+		CutChars:      len(runes) - keptRunes,
+		CutTokens:     EstimateTokens(string(runes[keptRunes:])),
+		Dropped:       keptRunes == 0,
+	}
+}
@@ -0,0 +1,298 @@
+// Package textdiff 提供面向正文的语义化 diff：先按段落对齐，再对改写过的段落做词级对比
+// （中日韩文字按字、拉丁文字按词、标点单独成词），比按行 diff 更适合阅读小说正文的改动。
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Op 差异操作
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+	// OpModify 段落被改写（仅用于段落级，段内由 Segments 给出词级差异）
+	OpModify Op = "modify"
+)
+
+// ModifySimilarity 删除段与新增段的相似度（共同字符占比）不低于该值时视为同一段落被改写
+const ModifySimilarity = 0.5
+
+// maxLCSCells 单次 LCS 的最大计算规模（行 × 列）；超过时整体视为替换，避免超长输入耗尽内存
+const maxLCSCells = 4_000_000
+
+// pairLookahead 为删除段寻找对应改写段时，最多向后查看的新增段数
+const pairLookahead = 3
+
+// Segment 段内的一段连续文本
+type Segment struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Paragraph 段落级差异
+type Paragraph struct {
+	Op Op `json:"op"`
+	// FromIndex / ToIndex 段落在旧 / 新正文中的序号（从 0 开始，不存在时为 -1）
+	FromIndex int       `json:"from_index"`
+	ToIndex   int       `json:"to_index"`
+	Segments  []Segment `json:"segments"`
+}
+
+// Stats 差异统计（字符数按 rune 计）
+type Stats struct {
+	ParagraphsAdded     int `json:"paragraphs_added"`
+	ParagraphsRemoved   int `json:"paragraphs_removed"`
+	ParagraphsModified  int `json:"paragraphs_modified"`
+	ParagraphsUnchanged int `json:"paragraphs_unchanged"`
+	CharsInserted       int `json:"chars_inserted"`
+	CharsDeleted        int `json:"chars_deleted"`
+}
+
+// Result 对比结果
+type Result struct {
+	Paragraphs []Paragraph `json:"paragraphs"`
+	Stats      Stats       `json:"stats"`
+}
+
+// Compare 对比两段正文
+func Compare(from, to string) *Result {
+	a, b := SplitParagraphs(from), SplitParagraphs(to)
+	out := &Result{Paragraphs: []Paragraph{}}
+
+	var dels, inss []int
+	flush := func() {
+		out.appendRun(a, b, dels, inss)
+		dels, inss = dels[:0], inss[:0]
+	}
+	for _, e := range lcs(a, b) {
+		switch e.op {
+		case OpDelete:
+			dels = append(dels, e.a)
+		case OpInsert:
+			inss = append(inss, e.b)
+		default:
+			flush()
+			out.add(Paragraph{Op: OpEqual, FromIndex: e.a, ToIndex: e.b, Segments: []Segment{{Op: OpEqual, Text: a[e.a]}}})
+		}
+	}
+	flush()
+	return out
+}
+
+// SplitParagraphs 按换行切分段落，去掉首尾空白（含全角缩进）并丢弃空段
+func SplitParagraphs(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// appendRun 处理两个相同段落之间的删除段与新增段：相似的删除段与新增段配对为改写，其余保持删除 / 新增
+func (r *Result) appendRun(a, b []string, dels, inss []int) {
+	j := 0
+	for _, di := range dels {
+		match := -1
+		var segs []Segment
+		for k := j; k < len(inss) && k < j+pairLookahead; k++ {
+			if s, sim := compareWords(a[di], b[inss[k]]); sim >= ModifySimilarity {
+				match, segs = k, s
+				break
+			}
+		}
+		if match < 0 {
+			r.add(Paragraph{Op: OpDelete, FromIndex: di, ToIndex: -1, Segments: []Segment{{Op: OpDelete, Text: a[di]}}})
+			continue
+		}
+		for ; j < match; j++ {
+			r.add(Paragraph{Op: OpInsert, FromIndex: -1, ToIndex: inss[j], Segments: []Segment{{Op: OpInsert, Text: b[inss[j]]}}})
+		}
+		r.add(Paragraph{Op: OpModify, FromIndex: di, ToIndex: inss[match], Segments: segs})
+		j = match + 1
+	}
+	for ; j < len(inss); j++ {
+		r.add(Paragraph{Op: OpInsert, FromIndex: -1, ToIndex: inss[j], Segments: []Segment{{Op: OpInsert, Text: b[inss[j]]}}})
+	}
+}
+
+// add 追加段落并累计统计
+func (r *Result) add(p Paragraph) {
+	r.Paragraphs = append(r.Paragraphs, p)
+	switch p.Op {
+	case OpEqual:
+		r.Stats.ParagraphsUnchanged++
+	case OpInsert:
+		r.Stats.ParagraphsAdded++
+	case OpDelete:
+		r.Stats.ParagraphsRemoved++
+	case OpModify:
+		r.Stats.ParagraphsModified++
+	}
+	for _, s := range p.Segments {
+		switch s.Op {
+		case OpInsert:
+			r.Stats.CharsInserted += utf8.RuneCountInString(s.Text)
+		case OpDelete:
+			r.Stats.CharsDeleted += utf8.RuneCountInString(s.Text)
+		}
+	}
+}
+
+// compareWords 段内词级对比，返回合并后的片段与相似度（共同字符数 × 2 / 两段字符总数）
+func compareWords(from, to string) ([]Segment, float64) {
+	a, b := Tokenize(from), Tokenize(to)
+	var segs []Segment
+	common := 0
+	for _, e := range lcs(a, b) {
+		var text string
+		switch e.op {
+		case OpDelete:
+			text = a[e.a]
+		default:
+			text = b[e.b]
+		}
+		if e.op == OpEqual {
+			common += utf8.RuneCountInString(text)
+		}
+		if n := len(segs); n > 0 && segs[n-1].Op == e.op {
+			segs[n-1].Text += text
+			continue
+		}
+		segs = append(segs, Segment{Op: e.op, Text: text})
+	}
+	total := utf8.RuneCountInString(from) + utf8.RuneCountInString(to)
+	if total == 0 {
+		return segs, 1
+	}
+	return segs, float64(2*common) / float64(total)
+}
+
+// Tokenize 切分词级对比单元：中日韩文字每字一个单元，字母 / 数字连续成词，空白连续成一个单元，其余字符（标点）各自成单元
+func Tokenize(s string) []string {
+	var out []string
+	start := -1
+	kind := 0 // 1 = 字母数字词，2 = 空白
+	flush := func(end int) {
+		if start >= 0 {
+			out = append(out, s[start:end])
+			start, kind = -1, 0
+		}
+	}
+	for i, r := range s {
+		switch {
+		case isCJK(r):
+			flush(i)
+			out = append(out, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'':
+			if kind != 1 {
+				flush(i)
+				start, kind = i, 1
+			}
+		case unicode.IsSpace(r):
+			if kind != 2 {
+				flush(i)
+				start, kind = i, 2
+			}
+		default:
+			flush(i)
+			out = append(out, string(r))
+		}
+	}
+	flush(len(s))
+	return out
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// edit LCS 编辑脚本中的一步（a / b 为两侧序号，不适用时为 -1）
+type edit struct {
+	op   Op
+	a, b int
+}
+
+// lcs 计算最长公共子序列编辑脚本；同一位置优先输出删除，使改写片段按“先删后增”排列。
+// 先剥离公共前后缀，剩余规模超过 maxLCSCells 时中间部分整体视为删除 + 新增
+func lcs(a, b []string) []edit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	out := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < pre; i++ {
+		out = append(out, edit{op: OpEqual, a: i, b: i})
+	}
+	out = append(out, lcsMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf], pre)...)
+	for k := suf; k > 0; k-- {
+		out = append(out, edit{op: OpEqual, a: len(a) - k, b: len(b) - k})
+	}
+	return out
+}
+
+// lcsMiddle 对剥离前后缀后的部分做 DP；offset 为剥离的前缀长度，用于还原原始序号
+func lcsMiddle(a, b []string, offset int) []edit {
+	n, m := len(a), len(b)
+	var out []edit
+	if n == 0 || m == 0 || n*m > maxLCSCells {
+		for i := range a {
+			out = append(out, edit{op: OpDelete, a: offset + i, b: -1})
+		}
+		for j := range b {
+			out = append(out, edit{op: OpInsert, a: -1, b: offset + j})
+		}
+		return out
+	}
+
+	// dp[i][j] = a[i:] 与 b[j:] 的 LCS 长度；规模受 maxLCSCells 限制，min(n, m) 不会超过 uint16
+	w := m + 1
+	dp := make([]uint16, (n+1)*w)
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i*w+j] = dp[(i+1)*w+j+1] + 1
+			case dp[(i+1)*w+j] >= dp[i*w+j+1]:
+				dp[i*w+j] = dp[(i+1)*w+j]
+			default:
+				dp[i*w+j] = dp[i*w+j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, edit{op: OpEqual, a: offset + i, b: offset + j})
+			i++
+			j++
+		case dp[(i+1)*w+j] >= dp[i*w+j+1]:
+			out = append(out, edit{op: OpDelete, a: offset + i, b: -1})
+			i++
+		default:
+			out = append(out, edit{op: OpInsert, a: -1, b: offset + j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, edit{op: OpDelete, a: offset + i, b: -1})
+	}
+	for ; j < m; j++ {
+		out = append(out, edit{op: OpInsert, a: -1, b: offset + j})
+	}
+	return out
+}
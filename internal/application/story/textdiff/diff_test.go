@@ -0,0 +1,47 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("他说：“Hello, world 42！”")
+	want := []string{"他", "说", "：", "“", "Hello", ",", " ", "world", " ", "42", "！", "”"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tokens = %q, want %q", got, want)
+	}
+}
+
+func TestComparePairsRewrittenParagraphs(t *testing.T) {
+	from := "　　清晨，林风推开木门。\n\n　　院子里积了一夜的雪。\n　　他想起了师父。"
+	to := "　　清晨，林风轻轻推开木门。\n\n　　院子里积了一夜的雪。\n　　远处传来钟声。\n　　他忽然想起了师父的话。"
+	r := Compare(from, to)
+
+	ops := make([]Op, 0, len(r.Paragraphs))
+	for _, p := range r.Paragraphs {
+		ops = append(ops, p.Op)
+	}
+	if want := []Op{OpModify, OpEqual, OpInsert, OpModify}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("paragraph ops = %v, want %v", ops, want)
+	}
+	first := r.Paragraphs[0].Segments
+	if want := []Segment{{OpEqual, "清晨，林风"}, {OpInsert, "轻轻"}, {OpEqual, "推开木门。"}}; !reflect.DeepEqual(first, want) {
+		t.Fatalf("word-level segments = %+v", first)
+	}
+	if r.Stats.ParagraphsModified != 2 || r.Stats.ParagraphsAdded != 1 || r.Stats.CharsInserted != 2+7+4 || r.Stats.CharsDeleted != 0 {
+		t.Fatalf("unexpected stats %+v", r.Stats)
+	}
+
+	if r := Compare("第一段。", "完全不同的一句话"); r.Stats.ParagraphsRemoved != 1 || r.Stats.ParagraphsAdded != 1 {
+		t.Fatalf("dissimilar paragraphs should not pair as a rewrite, got %+v", r.Stats)
+	}
+}
+
+func TestRenderHTMLEscapes(t *testing.T) {
+	out := RenderHTML(Compare("a <b> c", "a <b> d"))
+	if !strings.Contains(out, `<p class="diff-modify">a &lt;b&gt; <del>c</del><ins>d</ins></p>`) {
+		t.Fatalf("unexpected html: %s", out)
+	}
+}
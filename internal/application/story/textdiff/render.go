@@ -0,0 +1,31 @@
+package textdiff
+
+import (
+	"html"
+	"strings"
+)
+
+// RenderHTML 渲染为 HTML 片段：每段一个 <p class="diff-{op}">，段内删除 / 新增分别用 <del> / <ins> 标记（文本已转义）
+func RenderHTML(r *Result) string {
+	var b strings.Builder
+	b.WriteString(`<div class="chapter-diff">`)
+	for _, p := range r.Paragraphs {
+		b.WriteString(`<p class="diff-`)
+		b.WriteString(string(p.Op))
+		b.WriteString(`">`)
+		for _, s := range p.Segments {
+			text := html.EscapeString(s.Text)
+			switch s.Op {
+			case OpInsert:
+				b.WriteString("<ins>" + text + "</ins>")
+			case OpDelete:
+				b.WriteString("<del>" + text + "</del>")
+			default:
+				b.WriteString(text)
+			}
+		}
+		b.WriteString("</p>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
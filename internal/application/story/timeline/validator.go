@@ -0,0 +1,164 @@
+// Package timeline 提供章节故事时间轴相关的校验能力。
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// CheckMode 故事时间单调性校验模式
+type CheckMode string
+
+const (
+	CheckModeOff    CheckMode = "off"
+	CheckModeWarn   CheckMode = "warn"
+	CheckModeReject CheckMode = "reject"
+)
+
+// ParseCheckMode 解析配置值；未知值回退为 warn。
+func ParseCheckMode(s string) CheckMode {
+	switch CheckMode(strings.ToLower(strings.TrimSpace(s))) {
+	case CheckModeOff:
+		return CheckModeOff
+	case CheckModeReject:
+		return CheckModeReject
+	default:
+		return CheckModeWarn
+	}
+}
+
+// Regression 表示一次故事时间倒退：当前章节的开始时间早于叙事上前一章节的结束时间。
+type Regression struct {
+	ChapterID          string `json:"chapter_id"`
+	ChapterTitle       string `json:"chapter_title,omitempty"`
+	StoryTimeStart     int64  `json:"story_time_start"`
+	PrevChapterID      string `json:"prev_chapter_id"`
+	PrevChapterTitle   string `json:"prev_chapter_title,omitempty"`
+	PrevStoryTimeUpper int64  `json:"prev_story_time_upper"`
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("chapter %s story_time_start=%d is earlier than previous chapter %s (%d); mark it as flashback if intentional",
+		r.ChapterID, r.StoryTimeStart, r.PrevChapterID, r.PrevStoryTimeUpper)
+}
+
+// RegressionError 在 reject 模式下返回，携带全部倒退明细。
+type RegressionError struct {
+	Regressions []Regression
+}
+
+func (e RegressionError) Error() string {
+	if len(e.Regressions) == 0 {
+		return "story time regression detected"
+	}
+	msgs := make([]string, 0, len(e.Regressions))
+	for i := range e.Regressions {
+		msgs = append(msgs, e.Regressions[i].String())
+	}
+	return "story time regression detected: " + strings.Join(msgs, "; ")
+}
+
+// FindRegressions 在按叙事顺序排列的章节中查找故事时间倒退。
+//
+// 约定：
+// - story_time_start 为 0 视为“未设置”，不参与比较。
+// - 标记为 flashback 的章节既不被校验，也不作为后续章节的比较基准。
+func FindRegressions(ordered []*entity.Chapter) []Regression {
+	var out []Regression
+	var prev *entity.Chapter
+	for _, ch := range ordered {
+		if ch == nil || ch.IsFlashback || ch.StoryTimeStart <= 0 {
+			continue
+		}
+		if prev != nil && ch.StoryTimeStart < prev.StoryTimeUpperBound() {
+			out = append(out, Regression{
+				ChapterID:          ch.ID,
+				ChapterTitle:       ch.Title,
+				StoryTimeStart:     ch.StoryTimeStart,
+				PrevChapterID:      prev.ID,
+				PrevChapterTitle:   prev.Title,
+				PrevStoryTimeUpper: prev.StoryTimeUpperBound(),
+			})
+		}
+		if prev == nil || ch.StoryTimeUpperBound() >= prev.StoryTimeUpperBound() {
+			prev = ch
+		}
+	}
+	return out
+}
+
+// StoryTimeValidator 校验章节故事时间是否随叙事顺序单调不减。
+type StoryTimeValidator struct {
+	chapterRepo repository.ChapterRepository
+	mode        CheckMode
+}
+
+// NewStoryTimeValidator 创建故事时间校验器
+func NewStoryTimeValidator(chapterRepo repository.ChapterRepository, mode CheckMode) *StoryTimeValidator {
+	return &StoryTimeValidator{
+		chapterRepo: chapterRepo,
+		mode:        mode,
+	}
+}
+
+// Mode 返回当前校验模式
+func (v *StoryTimeValidator) Mode() CheckMode {
+	if v == nil {
+		return CheckModeOff
+	}
+	return v.mode
+}
+
+// CheckChapter 校验指定章节与其叙事前后章节的时间关系（章节需已落库，调用方负责事务边界）。
+// warn 模式返回倒退明细；reject 模式在存在倒退时返回 RegressionError。
+func (v *StoryTimeValidator) CheckChapter(ctx context.Context, chapter *entity.Chapter) ([]Regression, error) {
+	if v.Mode() == CheckModeOff || chapter == nil {
+		return nil, nil
+	}
+	all, err := v.checkProject(ctx, chapter.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var related []Regression
+	for i := range all {
+		if all[i].ChapterID == chapter.ID || all[i].PrevChapterID == chapter.ID {
+			related = append(related, all[i])
+		}
+	}
+	return related, v.verdict(related)
+}
+
+// CheckProject 校验整个项目的章节时间轴（用于 Foundation Apply 等批量写入场景）。
+func (v *StoryTimeValidator) CheckProject(ctx context.Context, projectID string) ([]Regression, error) {
+	if v.Mode() == CheckModeOff {
+		return nil, nil
+	}
+	all, err := v.checkProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return all, v.verdict(all)
+}
+
+func (v *StoryTimeValidator) checkProject(ctx context.Context, projectID string) ([]Regression, error) {
+	if v.chapterRepo == nil {
+		return nil, fmt.Errorf("chapter repository not configured")
+	}
+	ordered, err := v.chapterRepo.ListTimeline(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return FindRegressions(ordered), nil
+}
+
+func (v *StoryTimeValidator) verdict(regressions []Regression) error {
+	if len(regressions) > 0 && v.mode == CheckModeReject {
+		return RegressionError{Regressions: regressions}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// markdownRenderer 渲染可读的 Markdown 记录：每个轮次一节，构件快照以 JSON 代码块附在助手轮次之后
+type markdownRenderer struct {
+	w io.Writer
+}
+
+func (r *markdownRenderer) begin(h Header) error {
+	var b strings.Builder
+	title := h.ProjectTitle
+	if title == "" {
+		title = h.ProjectID
+	}
+	b.WriteString("# 会话记录：" + title + "\n\n")
+	b.WriteString("- 会话 ID：" + h.SessionID + "\n")
+	b.WriteString("- 当前任务：" + h.CurrentTask + "\n")
+	b.WriteString("- 创建时间：" + formatTime(h.CreatedAt) + "\n")
+	b.WriteString("- 导出时间：" + formatTime(h.ExportedAt) + "\n")
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+func (r *markdownRenderer) turn(t *Turn) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n---\n\n## %d. %s · %s · %s\n\n", t.Index, roleLabel(t.Role), t.Task, formatTime(t.CreatedAt))
+	if content := strings.TrimSpace(t.Content); content != "" {
+		b.WriteString(content + "\n")
+	}
+	if len(t.Attachments) > 0 {
+		b.WriteString("\n附件：" + strings.Join(t.Attachments, "、") + "\n")
+	}
+
+	if a := t.Artifact; a != nil {
+		label := a.Type
+		if label == "" {
+			label = "构件"
+		}
+		fmt.Fprintf(&b, "\n**构件快照**：%s v%d", label, a.VersionNo)
+		if a.BranchKey != "" {
+			fmt.Fprintf(&b, "（分支 %s）", a.BranchKey)
+		}
+		markers := make([]string, 0, 2)
+		if a.Activated {
+			markers = append(markers, "生成时已激活")
+		}
+		if a.ActiveNow {
+			markers = append(markers, "当前激活版本")
+		}
+		if len(markers) > 0 {
+			b.WriteString(" · " + strings.Join(markers, " · "))
+		}
+		b.WriteString("\n")
+		if a.Missing {
+			b.WriteString("\n> 该版本已不可读取\n")
+		} else if len(a.Content) > 0 {
+			b.WriteString("\n```json\n" + indentJSON(a.Content) + "\n```\n")
+		}
+	}
+
+	if len(t.ConflictWarnings) > 0 {
+		b.WriteString("\n**设定冲突提示**：\n\n")
+		for _, w := range t.ConflictWarnings {
+			line := "- [" + w.Severity + "] " + strings.TrimSpace(w.Message)
+			if s := strings.TrimSpace(w.Suggestion); s != "" {
+				line += "（建议：" + s + "）"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+func (r *markdownRenderer) end(total int) error {
+	if total == 0 {
+		_, err := io.WriteString(r.w, "\n（会话暂无内容）\n")
+		return err
+	}
+	return nil
+}
+
+// jsonRenderer 渲染 JSON 记录：{"session": Header, "turns": [Turn...], "total_turns": N}，轮次逐个写出
+type jsonRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (r *jsonRenderer) begin(h Header) error {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(r.w, `{"session":`+string(raw)+`,"turns":[`)
+	return err
+}
+
+func (r *jsonRenderer) turn(t *Turn) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if r.count > 0 {
+		if _, err := io.WriteString(r.w, ","); err != nil {
+			return err
+		}
+	}
+	r.count++
+	_, err = r.w.Write(raw)
+	return err
+}
+
+func (r *jsonRenderer) end(total int) error {
+	_, err := fmt.Fprintf(r.w, `],"total_turns":%d}`+"\n", total)
+	return err
+}
+
+func roleLabel(role string) string {
+	switch role {
+	case "user":
+		return "用户"
+	case "assistant":
+		return "助手"
+	case "system":
+		return "系统"
+	default:
+		return role
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func indentJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,253 @@
+// Package transcript 提供长期会话的归档导出（Markdown / JSON 记录），用于留存设定的设计过程
+package transcript
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// Format 会话导出格式
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+)
+
+// exportPageSize 导出时每批读取的轮次数（逐批写出，长会话不整体驻留内存）
+const exportPageSize = 100
+
+// ParseFormat 解析导出格式（空值默认 markdown）
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "markdown", "md":
+		return FormatMarkdown, true
+	case "json":
+		return FormatJSON, true
+	default:
+		return "", false
+	}
+}
+
+// ContentType 响应 Content-Type
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension 导出文件扩展名
+func (f Format) Extension() string {
+	if f == FormatJSON {
+		return "json"
+	}
+	return "md"
+}
+
+// Header 导出记录头部
+type Header struct {
+	SessionID    string    `json:"session_id"`
+	ProjectID    string    `json:"project_id"`
+	ProjectTitle string    `json:"project_title"`
+	CurrentTask  string    `json:"current_task"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExportedAt   time.Time `json:"exported_at"`
+}
+
+// Turn 单个轮次的导出记录
+type Turn struct {
+	Index            int               `json:"index"`
+	ID               string            `json:"id"`
+	Role             string            `json:"role"`
+	Task             string            `json:"task"`
+	Content          string            `json:"content"`
+	Attachments      []string          `json:"attachments,omitempty"`
+	Artifact         *ArtifactStep     `json:"artifact,omitempty"`
+	ConflictWarnings []ConflictWarning `json:"conflict_warnings,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+}
+
+// ArtifactStep assistant 轮次产出的构件版本快照
+type ArtifactStep struct {
+	ArtifactID string `json:"artifact_id"`
+	Type       string `json:"type,omitempty"`
+	VersionID  string `json:"version_id"`
+	VersionNo  int    `json:"version_no"`
+	BranchKey  string `json:"branch_key,omitempty"`
+	// Activated 生成时是否设为激活版本；ActiveNow 导出时是否仍为激活版本
+	Activated bool            `json:"activated"`
+	ActiveNow bool            `json:"active_now"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	// Missing 版本已被删除或不可读取
+	Missing bool `json:"missing,omitempty"`
+}
+
+// ConflictWarning 生成时的设定冲突提示
+type ConflictWarning struct {
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// turnMeta 会话轮次 metadata 中导出关心的字段（与 ConversationHandler 写入的结构一致）
+type turnMeta struct {
+	Attachments []struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	} `json:"attachments"`
+	ArtifactID       string            `json:"artifact_id"`
+	VersionID        string            `json:"version_id"`
+	VersionNo        int               `json:"version_no"`
+	BranchKey        string            `json:"branch_key"`
+	Activated        bool              `json:"activated"`
+	ConflictWarnings []ConflictWarning `json:"conflict_warnings"`
+}
+
+// Exporter 会话导出服务：按创建顺序分批读取轮次，附上每一步产出的构件快照，边读边写
+type Exporter struct {
+	turnRepo     repository.ConversationTurnRepository
+	artifactRepo repository.ArtifactRepository
+}
+
+// NewExporter 创建会话导出服务
+func NewExporter(turnRepo repository.ConversationTurnRepository, artifactRepo repository.ArtifactRepository) *Exporter {
+	return &Exporter{turnRepo: turnRepo, artifactRepo: artifactRepo}
+}
+
+// Export 将会话完整写出到 w；写出过程中出错时已写出的内容无法撤回，由调用方记录日志
+func (e *Exporter) Export(ctx context.Context, w io.Writer, project *entity.Project, session *entity.ConversationSession, format Format) error {
+	if project == nil || session == nil {
+		return fmt.Errorf("project and session are required")
+	}
+
+	artifacts, err := e.artifactRepo.ListArtifactsByProject(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*entity.ProjectArtifact, len(artifacts))
+	for _, a := range artifacts {
+		if a != nil {
+			byID[a.ID] = a
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	var r renderer
+	if format == FormatJSON {
+		r = &jsonRenderer{w: bw}
+	} else {
+		r = &markdownRenderer{w: bw}
+	}
+
+	header := Header{
+		SessionID:    session.ID,
+		ProjectID:    project.ID,
+		ProjectTitle: strings.TrimSpace(project.Title),
+		CurrentTask:  string(session.CurrentTask),
+		CreatedAt:    session.CreatedAt,
+		ExportedAt:   time.Now().UTC(),
+	}
+	if err := r.begin(header); err != nil {
+		return err
+	}
+
+	index := 0
+	for page := 1; ; page++ {
+		res, err := e.turnRepo.ListBySession(ctx, session.ID, repository.Pagination{Page: page, PageSize: exportPageSize})
+		if err != nil {
+			return err
+		}
+		if res == nil || len(res.Items) == 0 {
+			break
+		}
+		for _, t := range res.Items {
+			if t == nil {
+				continue
+			}
+			index++
+			if err := r.turn(e.buildTurn(ctx, index, t, byID)); err != nil {
+				return err
+			}
+		}
+		// 每批刷新一次，让客户端尽早收到数据
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		if len(res.Items) < exportPageSize {
+			break
+		}
+	}
+
+	if err := r.end(index); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (e *Exporter) buildTurn(ctx context.Context, index int, t *entity.ConversationTurn, artifacts map[string]*entity.ProjectArtifact) *Turn {
+	out := &Turn{
+		Index:     index,
+		ID:        t.ID,
+		Role:      string(t.Role),
+		Task:      string(t.Task),
+		Content:   t.Content,
+		CreatedAt: t.CreatedAt,
+	}
+
+	var meta turnMeta
+	if len(t.Metadata) > 0 {
+		_ = json.Unmarshal(t.Metadata, &meta)
+	}
+	for _, a := range meta.Attachments {
+		if strings.TrimSpace(a.Content) == "" {
+			continue
+		}
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			name = "附件"
+		}
+		out.Attachments = append(out.Attachments, fmt.Sprintf("%s（%d 字）", name, len([]rune(a.Content))))
+	}
+	out.ConflictWarnings = meta.ConflictWarnings
+
+	if t.Role != entity.RoleAssistant || strings.TrimSpace(meta.VersionID) == "" {
+		return out
+	}
+	step := &ArtifactStep{
+		ArtifactID: meta.ArtifactID,
+		VersionID:  meta.VersionID,
+		VersionNo:  meta.VersionNo,
+		BranchKey:  meta.BranchKey,
+		Activated:  meta.Activated,
+	}
+	if a := artifacts[meta.ArtifactID]; a != nil {
+		step.Type = string(a.Type)
+		step.ActiveNow = a.ActiveVersionID != nil && *a.ActiveVersionID == meta.VersionID
+	}
+	v, err := e.artifactRepo.GetVersionByID(ctx, meta.VersionID)
+	if err != nil || v == nil || v.ArtifactID != meta.ArtifactID {
+		step.Missing = true
+	} else {
+		step.Content = v.Content
+	}
+	out.Artifact = step
+	return out
+}
+
+type renderer interface {
+	begin(h Header) error
+	turn(t *Turn) error
+	end(total int) error
+}
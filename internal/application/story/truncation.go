@@ -0,0 +1,73 @@
+package story
+
+import (
+	"strings"
+
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/application/story/storyutil"
+	"z-novel-ai-api/internal/domain/entity"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// 截断报告明细：Worker、SSE 与同步接口共用，与任务警告互补（警告面向阅读，报告供客户端按字段展示）
+
+// AttachmentTruncations 按 LimitAttachments 的规则计算附件截断明细（每个被截断或丢弃的附件一条）
+func AttachmentTruncations(attachments []wfmodel.TextAttachment) []entity.PromptTruncation {
+	_, truncated := wfmodel.LimitAttachments(attachments)
+	if len(truncated) == 0 {
+		return nil
+	}
+	out := make([]entity.PromptTruncation, 0, len(truncated))
+	next := 0
+	for _, a := range attachments {
+		if next >= len(truncated) {
+			break
+		}
+		content := strings.TrimSpace(a.Content)
+		t := truncated[next]
+		if content == "" || a.Name != t.Name || len([]rune(content)) != t.Runes {
+			continue
+		}
+		out = append(out, storyutil.NewPromptTruncation(entity.TruncationSourceAttachment, a.Name, content, t.KeptRunes))
+		next++
+	}
+	return out
+}
+
+// RetrievalTruncations 按章节生成的召回预算（PromptMaxSegments / PromptMaxRunesPerSegment）计算召回片段截断明细：
+// 超出条数上限的片段记为丢弃，超出单片段字数的记为截断
+func RetrievalTruncations(segments []retrieval.Segment) []entity.PromptTruncation {
+	var out []entity.PromptTruncation
+	for i, slot := range retrieval.PromptPlacement(segments, retrieval.PromptMaxSegments, retrieval.PromptMaxRunesPerSegment) {
+		if slot.Runes == 0 || (slot.Included && !slot.Truncated) {
+			continue
+		}
+		text := strings.Join(strings.Fields(segments[i].Text), " ")
+		out = append(out, storyutil.NewPromptTruncation(entity.TruncationSourceRetrieval, segmentRef(segments[i]), text, slot.KeptRunes))
+	}
+	return out
+}
+
+// segmentRef 召回片段的可读引用（如 chapter:雨夜、artifact:characters /1）
+func segmentRef(s retrieval.Segment) string {
+	docType := strings.TrimSpace(s.DocType)
+	var ref string
+	switch docType {
+	case "chapter", retrieval.SummarySegmentType:
+		ref = strings.TrimSpace(s.ChapterTitle)
+		if ref == "" {
+			ref = strings.TrimSpace(s.ChapterID)
+		}
+	case "artifact":
+		ref = strings.TrimSpace(strings.TrimSpace(s.ArtifactType) + " " + strings.TrimSpace(s.RefPath))
+	case "notes":
+		ref = strings.TrimSpace(s.NoteTitle)
+	}
+	if ref == "" {
+		ref = s.ID
+	}
+	if docType == "" {
+		return ref
+	}
+	return docType + ":" + ref
+}
@@ -0,0 +1,61 @@
+package story
+
+import (
+	"strings"
+	"testing"
+
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+func TestAttachmentTruncations(t *testing.T) {
+	items := AttachmentTruncations([]wfmodel.TextAttachment{
+		{Name: "empty", Content: "  "},
+		{Name: "short.txt", Content: "短附件"},
+		{Name: "long.txt", Content: strings.Repeat("字", wfmodel.AttachmentMaxRunes+100)},
+		{Name: "rest.txt", Content: strings.Repeat("a", wfmodel.AttachmentMaxRunes)},
+		{Name: "dropped.txt", Content: strings.Repeat("b", 40)},
+	})
+	if len(items) != 3 {
+		t.Fatalf("expected long, rest and dropped attachments, got %+v", items)
+	}
+	long := items[0]
+	if long.Name != "long.txt" || long.CutChars != 100 || long.CutTokens != 100 || long.Dropped {
+		t.Fatalf("unexpected long attachment truncation %+v", long)
+	}
+	rest := items[1]
+	if rest.KeptChars != wfmodel.AttachmentsMaxRunes-wfmodel.AttachmentMaxRunes-3 || rest.CutChars != wfmodel.AttachmentMaxRunes-rest.KeptChars {
+		t.Fatalf("unexpected total-limit truncation %+v", rest)
+	}
+	if d := items[2]; !d.Dropped || d.KeptChars != 0 || d.CutChars != 40 || d.CutTokens != 10 {
+		t.Fatalf("attachment beyond the total limit should be dropped, got %+v", d)
+	}
+
+	report := entity.NewTruncationReport(items...)
+	if report.CutChars != long.CutChars+rest.CutChars+40 || report.CutTokens != long.CutTokens+rest.CutTokens+10 {
+		t.Fatalf("report totals do not add up: %+v", report)
+	}
+	if entity.NewTruncationReport() != nil || AttachmentTruncations([]wfmodel.TextAttachment{{Name: "a", Content: "ok"}}) != nil {
+		t.Fatal("no truncation should yield no report")
+	}
+}
+
+func TestRetrievalTruncations(t *testing.T) {
+	segments := make([]retrieval.Segment, retrieval.PromptMaxSegments+1)
+	for i := range segments {
+		segments[i] = retrieval.Segment{ID: "s", DocType: "chapter", ChapterTitle: "雨夜", Text: "短片段"}
+	}
+	segments[0].Text = strings.Repeat("长", retrieval.PromptMaxRunesPerSegment+20)
+
+	items := RetrievalTruncations(segments)
+	if len(items) != 2 {
+		t.Fatalf("expected one truncated and one dropped segment, got %+v", items)
+	}
+	if it := items[0]; it.Source != entity.TruncationSourceRetrieval || it.Name != "chapter:雨夜" || it.CutChars != 20 || it.Dropped {
+		t.Fatalf("unexpected truncated segment %+v", it)
+	}
+	if it := items[1]; !it.Dropped || it.CutChars != 3 {
+		t.Fatalf("segment beyond the prompt budget should be dropped, got %+v", it)
+	}
+}
@@ -0,0 +1,175 @@
+// Package warmup 提供进程启动与运维触发的预热：编译工作流图、加载向量集合、建立模型与嵌入服务连接，
+// 避免部署后的首个用户请求承担数秒的冷启动开销。
+package warmup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+// DefaultTimeout 未配置时单轮预热的总时限
+const DefaultTimeout = 60 * time.Second
+
+// ErrSkipped 步骤因依赖未配置而跳过（如向量检索未启用），不计为失败
+var ErrSkipped = errors.New("warmup step skipped")
+
+// Step 预热步骤；各步骤并行执行，互不依赖
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// StepResult 单个步骤的执行结果
+type StepResult struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report 一轮预热的结果
+type Report struct {
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMs int64        `json:"duration_ms"`
+	OK         bool         `json:"ok"`
+	Steps      []StepResult `json:"steps"`
+}
+
+// Warmer 预热执行器：同一时刻只执行一轮，并发触发的调用等待并共享该轮结果
+type Warmer struct {
+	steps   []Step
+	timeout time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	running chan struct{}
+	last    *Report
+}
+
+// NewWarmer 创建预热执行器；timeout <= 0 时使用 DefaultTimeout
+func NewWarmer(timeout time.Duration, steps ...Step) *Warmer {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Warmer{steps: steps, timeout: timeout, now: time.Now}
+}
+
+// Steps 返回步骤名称（按注册顺序）
+func (w *Warmer) Steps() []string {
+	if w == nil {
+		return nil
+	}
+	names := make([]string, 0, len(w.steps))
+	for _, s := range w.steps {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// Last 返回最近一轮预热的结果（尚未执行时为 nil）
+func (w *Warmer) Last() *Report {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+// Run 执行一轮预热并返回结果；已有一轮在执行时等待其完成并返回该轮结果
+func (w *Warmer) Run(ctx context.Context) *Report {
+	if w == nil {
+		return &Report{OK: true, Steps: []StepResult{}}
+	}
+	w.mu.Lock()
+	if running := w.running; running != nil {
+		w.mu.Unlock()
+		select {
+		case <-running:
+			return w.Last()
+		case <-ctx.Done():
+			return &Report{StartedAt: w.now(), Steps: []StepResult{{Name: "wait", Error: ctx.Err().Error()}}}
+		}
+	}
+	done := make(chan struct{})
+	w.running = done
+	w.mu.Unlock()
+
+	report := w.run(ctx)
+
+	w.mu.Lock()
+	w.last = report
+	w.running = nil
+	w.mu.Unlock()
+	close(done)
+	return report
+}
+
+func (w *Warmer) run(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	started := w.now()
+	results := make([]StepResult, len(w.steps))
+	var wg sync.WaitGroup
+	for i, step := range w.steps {
+		wg.Add(1)
+		go func(i int, step Step) {
+			defer wg.Done()
+			results[i] = w.runStep(ctx, step)
+		}(i, step)
+	}
+	wg.Wait()
+
+	report := &Report{StartedAt: started, DurationMs: w.now().Sub(started).Milliseconds(), OK: true, Steps: results}
+	for _, r := range results {
+		if r.Error != "" {
+			report.OK = false
+		}
+	}
+	return report
+}
+
+// runStep 执行单个步骤；panic 记为失败，不影响其他步骤
+func (w *Warmer) runStep(ctx context.Context, step Step) (result StepResult) {
+	start := w.now()
+	result.Name = step.Name
+	defer func() {
+		if p := recover(); p != nil {
+			result.Error = "panic during warmup"
+			logger.Warn(ctx, "warmup step panicked", "step", step.Name, "panic", p)
+		}
+		result.DurationMs = w.now().Sub(start).Milliseconds()
+	}()
+
+	err := step.Run(ctx)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrSkipped):
+		result.Skipped = true
+	default:
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Log 按结果输出日志：失败的步骤逐条告警，其余汇总为一条信息日志
+func (r *Report) Log(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	var skipped []string
+	for _, s := range r.Steps {
+		if s.Error != "" {
+			logger.Warn(ctx, "warmup step failed", "step", s.Name, "duration_ms", s.DurationMs, "error", s.Error)
+		}
+		if s.Skipped {
+			skipped = append(skipped, s.Name)
+		}
+	}
+	logger.Info(ctx, "warmup finished", "ok", r.OK, "duration_ms", r.DurationMs, "steps", len(r.Steps), "skipped", skipped)
+}
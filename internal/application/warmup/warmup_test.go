@@ -0,0 +1,84 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmerRunsStepsAndReports(t *testing.T) {
+	var graphs atomic.Int32
+	w := NewWarmer(time.Second,
+		Step{Name: "graphs", Run: func(context.Context) error { graphs.Add(1); return nil }},
+		Step{Name: "vector_collection", Run: func(context.Context) error { return ErrSkipped }},
+		Step{Name: "providers", Run: func(context.Context) error { return errors.New("provider openai: dial tcp: timeout") }},
+		Step{Name: "embedder", Run: func(context.Context) error { panic("boom") }},
+	)
+	if w.Last() != nil {
+		t.Fatal("no report before the first run")
+	}
+
+	report := w.Run(context.Background())
+	if report.OK || len(report.Steps) != 4 || graphs.Load() != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	byName := map[string]StepResult{}
+	for _, s := range report.Steps {
+		byName[s.Name] = s
+	}
+	if byName["graphs"].Error != "" || byName["graphs"].Skipped {
+		t.Fatalf("graphs should succeed, got %+v", byName["graphs"])
+	}
+	if !byName["vector_collection"].Skipped || byName["vector_collection"].Error != "" {
+		t.Fatalf("skipped step should not count as failure, got %+v", byName["vector_collection"])
+	}
+	if byName["providers"].Error == "" || byName["embedder"].Error == "" {
+		t.Fatalf("failing and panicking steps should report errors, got %+v", report.Steps)
+	}
+	if w.Last() != report {
+		t.Fatal("last report should be recorded")
+	}
+}
+
+func TestWarmerTimeoutAndSingleFlight(t *testing.T) {
+	release := make(chan struct{})
+	var runs atomic.Int32
+	w := NewWarmer(50*time.Millisecond, Step{Name: "slow", Run: func(ctx context.Context) error {
+		runs.Add(1)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
+
+	// 并发触发共享同一轮结果
+	var wg sync.WaitGroup
+	reports := make([]*Report, 3)
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reports[i] = w.Run(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	close(release)
+
+	if runs.Load() < 1 || runs.Load() > 3 {
+		t.Fatalf("unexpected run count %d", runs.Load())
+	}
+	for _, r := range reports {
+		if r == nil || r.OK || r.Steps[0].Error == "" {
+			t.Fatalf("step exceeding the timeout should fail, got %+v", r)
+		}
+	}
+
+	if report := w.Run(context.Background()); !report.OK {
+		t.Fatalf("step should succeed once released, got %+v", report)
+	}
+}
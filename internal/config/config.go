@@ -2,7 +2,11 @@
 package config
 
 import (
+	"math"
+	"strings"
 	"time"
+
+	"z-novel-ai-api/pkg/logger"
 )
 
 // Config 应用配置根结构
@@ -19,15 +23,175 @@ type Config struct {
 	Messaging     MessagingConfig     `yaml:"messaging" mapstructure:"messaging"`
 	Observability ObservabilityConfig `yaml:"observability" mapstructure:"observability"`
 	Security      SecurityConfig      `yaml:"security" mapstructure:"security"`
+	Story         StoryConfig         `yaml:"story" mapstructure:"story"`
+	PublicAPI     PublicAPIConfig     `yaml:"public_api" mapstructure:"public_api"`
+	Billing       BillingConfig       `yaml:"billing" mapstructure:"billing"`
+	Provenance    ProvenanceConfig    `yaml:"provenance" mapstructure:"provenance"`
+	Warmup        WarmupConfig        `yaml:"warmup" mapstructure:"warmup"`
 
 	// 注意：历史上的 features.* 功能开关为“占位配置”，容易造成“开关可用/已生效”的误解，已移除。
 }
 
+// StoryConfig 创作业务规则配置
+type StoryConfig struct {
+	// StoryTimeCheck 章节故事时间单调性校验模式：off / warn / reject
+	StoryTimeCheck string `yaml:"story_time_check" mapstructure:"story_time_check"`
+	// AutosaveVersionInterval 自动保存合并窗口：同一编辑者在窗口内的连续保存不递增章节版本
+	AutosaveVersionInterval time.Duration `yaml:"autosave_version_interval" mapstructure:"autosave_version_interval"`
+	// RelationHalfLifeChapters 关系强度半衰期（章）：距上次强化每隔该章数，有效强度减半；0 表示不衰减
+	RelationHalfLifeChapters int `yaml:"relation_half_life_chapters" mapstructure:"relation_half_life_chapters"`
+	// SyncGeneration 同步生成接口（发送会话消息、设定集预览）在客户端断开时的处理策略
+	SyncGeneration SyncGenerationConfig `yaml:"sync_generation" mapstructure:"sync_generation"`
+	// GenerationTimeouts 各类生成调用的超时（Worker 与同步/流式接口共用）
+	GenerationTimeouts GenerationTimeoutsConfig `yaml:"generation_timeouts" mapstructure:"generation_timeouts"`
+	// BestOfN 多候选生成（章节/构件并行生成 N 个候选并择优）
+	BestOfN BestOfNConfig `yaml:"best_of_n" mapstructure:"best_of_n"`
+	// AutoTitleSuggestions 章节生成后若标题为空或占位（如“第3章”），自动提出标题建议供一键采用
+	AutoTitleSuggestions bool `yaml:"auto_title_suggestions" mapstructure:"auto_title_suggestions"`
+	// DuplicateSimilarityThreshold 章节保存时与同项目其他章节的 SimHash 相似度达到该值即提示疑似重复（0 表示关闭）
+	DuplicateSimilarityThreshold float64 `yaml:"duplicate_similarity_threshold" mapstructure:"duplicate_similarity_threshold"`
+	// JobCostCeilingTokens 单任务累计 Token 上限（跨重试、修复轮次与提供商切换）：达到后的下一次失败不再重试；0 表示不限制
+	JobCostCeilingTokens int64 `yaml:"job_cost_ceiling_tokens" mapstructure:"job_cost_ceiling_tokens"`
+	// Reindex 手动编辑章节正文后自动重建向量索引
+	Reindex ReindexConfig `yaml:"reindex" mapstructure:"reindex"`
+	// GenerationConsistency 生成任务与章节状态的不变量巡检
+	GenerationConsistency GenerationConsistencyConfig `yaml:"generation_consistency" mapstructure:"generation_consistency"`
+	// FoundationPlanLimits 设定集规模上限（生成与应用前校验；租户可由运维接口单独放宽）
+	FoundationPlanLimits FoundationPlanLimitsConfig `yaml:"foundation_plan_limits" mapstructure:"foundation_plan_limits"`
+}
+
+// FoundationPlanLimitsConfig 设定集规模上限：实体数、关系数、卷数、章节总数、章节大纲总字符数；0 表示不限制
+type FoundationPlanLimitsConfig struct {
+	MaxEntities     int `yaml:"max_entities" mapstructure:"max_entities"`
+	MaxRelations    int `yaml:"max_relations" mapstructure:"max_relations"`
+	MaxVolumes      int `yaml:"max_volumes" mapstructure:"max_volumes"`
+	MaxChapters     int `yaml:"max_chapters" mapstructure:"max_chapters"`
+	MaxOutlineChars int `yaml:"max_outline_chars" mapstructure:"max_outline_chars"`
+}
+
+// GenerationConsistencyConfig 任务/章节状态巡检：Worker 每 Interval 检查全部租户，
+// 将无活跃任务且超过 Grace 未更新的 generating 章节按状态机修复，并报告其余不一致
+type GenerationConsistencyConfig struct {
+	Enabled  bool          `yaml:"enabled" mapstructure:"enabled"`
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+	Grace    time.Duration `yaml:"grace" mapstructure:"grace"`
+}
+
+// ReindexConfig 编辑后自动重建索引：同一章节在 Debounce 内的连续保存合并为一次重建，
+// 持续编辑时最迟在首次保存后 MaxDelay 内重建；Worker 每 PollInterval 领取到期请求
+type ReindexConfig struct {
+	Enabled      bool          `yaml:"enabled" mapstructure:"enabled"`
+	Debounce     time.Duration `yaml:"debounce" mapstructure:"debounce"`
+	MaxDelay     time.Duration `yaml:"max_delay" mapstructure:"max_delay"`
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+}
+
+// BestOfNConfig 多候选生成配置：请求的候选数先按 MaxCandidates 封顶，再按 MaxTotalTokens 预算缩减
+type BestOfNConfig struct {
+	// MaxCandidates 单次请求的候选数上限（1 表示关闭多候选）
+	MaxCandidates int `yaml:"max_candidates" mapstructure:"max_candidates"`
+	// MaxTotalTokens 全部候选预估 Token 之和的上限；0 表示不限制
+	MaxTotalTokens int64 `yaml:"max_total_tokens" mapstructure:"max_total_tokens"`
+}
+
+// GenerationTimeoutsConfig 按任务类型的生成超时：以 context 截止时间包住模型调用，超时的任务记为 timeout 错误码；0 表示不限制
+type GenerationTimeoutsConfig struct {
+	ChapterGen    time.Duration `yaml:"chapter_gen" mapstructure:"chapter_gen"`
+	FoundationGen time.Duration `yaml:"foundation_gen" mapstructure:"foundation_gen"`
+	ArtifactGen   time.Duration `yaml:"artifact_gen" mapstructure:"artifact_gen"`
+	// ConflictScan 构件生成后的设定冲突检查（超时仅跳过检查并记警告）
+	ConflictScan time.Duration `yaml:"conflict_scan" mapstructure:"conflict_scan"`
+	// TitleSuggest 章节标题建议（超时仅跳过建议）
+	TitleSuggest time.Duration `yaml:"title_suggest" mapstructure:"title_suggest"`
+}
+
+// 同步生成的客户端断开策略
+const (
+	// DisconnectDetach 生成与落库脱离请求上下文，客户端断开后继续完成并标记任务完成
+	DisconnectDetach = "detach"
+	// DisconnectCancel 随请求取消中止生成，任务标记失败
+	DisconnectCancel = "cancel"
+)
+
+// 可按接口配置断开策略的同步生成接口
+const (
+	SyncEndpointSendMessage       = "send_message"
+	SyncEndpointPreviewFoundation = "preview_foundation"
+)
+
+// SyncGenerationConfig 同步生成断开处理配置
+type SyncGenerationConfig struct {
+	// OnDisconnect 默认策略：detach / cancel
+	OnDisconnect string `yaml:"on_disconnect" mapstructure:"on_disconnect"`
+	// Endpoints 按接口覆盖策略，键为 send_message / preview_foundation
+	Endpoints map[string]string `yaml:"endpoints" mapstructure:"endpoints"`
+	// DetachTimeout 脱离请求后生成与落库的最长耗时
+	DetachTimeout time.Duration `yaml:"detach_timeout" mapstructure:"detach_timeout"`
+}
+
+// Policy 返回接口的断开策略（未配置时为 detach）
+func (c SyncGenerationConfig) Policy(endpoint string) string {
+	if p := strings.ToLower(strings.TrimSpace(c.Endpoints[endpoint])); p != "" {
+		return p
+	}
+	if p := strings.ToLower(strings.TrimSpace(c.OnDisconnect)); p != "" {
+		return p
+	}
+	return DisconnectDetach
+}
+
+// PublicAPIConfig 公开只读 API 配置（免认证，面向静态站点/阅读器）
+type PublicAPIConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// MaxAge 浏览器缓存时间（Cache-Control: max-age）
+	MaxAge time.Duration `yaml:"max_age" mapstructure:"max_age"`
+	// SharedMaxAge CDN/共享缓存时间（Cache-Control: s-maxage）
+	SharedMaxAge time.Duration `yaml:"shared_max_age" mapstructure:"shared_max_age"`
+	// StaleWhileRevalidate 过期后允许 CDN 先返回旧内容并后台刷新的时间
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate" mapstructure:"stale_while_revalidate"`
+	// RateLimit 按客户端 IP 限流
+	RateLimit RateLimitConfig `yaml:"rate_limit" mapstructure:"rate_limit"`
+}
+
+// BillingConfig 计费（Token 充值）配置
+type BillingConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Provider 支付提供商：stripe / generic（自建支付网关按通用签名协议回调）
+	Provider string `yaml:"provider" mapstructure:"provider"`
+	// WebhookSecret Webhook 签名密钥
+	WebhookSecret string `yaml:"webhook_secret" mapstructure:"webhook_secret"`
+	// SignatureTolerance 签名时间戳允许的最大偏差（防重放）
+	SignatureTolerance time.Duration `yaml:"signature_tolerance" mapstructure:"signature_tolerance"`
+	// TokensPerMinorUnit 支付事件未携带 tokens 时，按支付金额（最小货币单位，如分）折算的 Token 数
+	TokensPerMinorUnit int64 `yaml:"tokens_per_minor_unit" mapstructure:"tokens_per_minor_unit"`
+}
+
+// ProvenanceConfig 导出成稿 AI 来源标记（水印）配置
+type ProvenanceConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Mode 嵌入方式：metadata（文末签名元数据块）/ zero_width（段落零宽字符标记）/ both
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	// Secret 水印签名密钥（校验依赖同一密钥，轮换后旧导出稿将无法校验）
+	Secret string `yaml:"secret" mapstructure:"secret"`
+}
+
+// WarmupConfig 启动预热：网关与 job-worker 启动时编译工作流图、加载 story_segments 集合、
+// 创建模型客户端并调用一次嵌入服务；运维可经 POST /v1/ops/warmup 手动触发
+type WarmupConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Timeout 单轮预热的总时限
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	// PingProviders 向每个提供商发送一次 1 Token 的请求以建立连接（产生少量模型调用；关闭时只创建客户端）
+	PingProviders bool `yaml:"ping_providers" mapstructure:"ping_providers"`
+}
+
 // AppConfig 应用基础配置
 type AppConfig struct {
 	Name    string `yaml:"name" mapstructure:"name"`
 	Version string `yaml:"version" mapstructure:"version"`
 	Env     string `yaml:"env" mapstructure:"env"`
+	// StrictConfig 严格启动模式：配置存在未知键或校验错误时拒绝启动（否则仅记录告警）
+	StrictConfig bool `yaml:"strict_config" mapstructure:"strict_config"`
 }
 
 // ServerConfig 服务器配置
@@ -54,6 +218,9 @@ type GRPCClientsConfig struct {
 	MemoryServiceAddr string `yaml:"memory_service_addr" mapstructure:"memory_service_addr"`
 	// ValidatorServiceAddr 校验服务地址 (host:port)
 	ValidatorServiceAddr string `yaml:"validator_service_addr" mapstructure:"validator_service_addr"`
+
+	// ProxyChapterStream 为 true 时 SSE 章节流经 StoryGen gRPC 服务生成，网关进程内不调用模型
+	ProxyChapterStream bool `yaml:"proxy_chapter_stream" mapstructure:"proxy_chapter_stream"`
 }
 
 // HTTPServerConfig HTTP 服务器配置
@@ -63,6 +230,54 @@ type HTTPServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout" mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout" mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout" mapstructure:"idle_timeout"`
+
+	// Compression 响应压缩（gzip/deflate）
+	Compression CompressionConfig `yaml:"compression" mapstructure:"compression"`
+	// BodyLimit 请求体大小限制
+	BodyLimit BodyLimitConfig `yaml:"body_limit" mapstructure:"body_limit"`
+	// RequestTimeout 请求级截止时间：注入 context 截止时间，数据库/缓存/向量库/模型调用均按其超时
+	RequestTimeout RequestTimeoutConfig `yaml:"request_timeout" mapstructure:"request_timeout"`
+	// DefaultLocale 错误消息默认语言（en / zh-CN）：请求未携带或无法匹配 Accept-Language 时使用
+	DefaultLocale string `yaml:"default_locale" mapstructure:"default_locale"`
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Level 压缩级别（1-9）
+	Level int `yaml:"level" mapstructure:"level"`
+	// MinSize 小于该字节数的响应不压缩
+	MinSize int `yaml:"min_size" mapstructure:"min_size"`
+	// ContentTypes 需要压缩的 Content-Type 前缀（为空时使用内置列表）
+	ContentTypes []string `yaml:"content_types" mapstructure:"content_types"`
+}
+
+// BodyLimitConfig 请求体大小限制配置
+type BodyLimitConfig struct {
+	// DefaultMaxBytes 默认上限（0 表示不限制）
+	DefaultMaxBytes int64 `yaml:"default_max_bytes" mapstructure:"default_max_bytes"`
+	// Routes 路由级上限（按路由模板前缀匹配，最长前缀优先）
+	Routes []RouteBodyLimit `yaml:"routes" mapstructure:"routes"`
+}
+
+// RouteBodyLimit 路由级请求体上限
+type RouteBodyLimit struct {
+	Prefix   string `yaml:"prefix" mapstructure:"prefix"`
+	MaxBytes int64  `yaml:"max_bytes" mapstructure:"max_bytes"`
+}
+
+// RequestTimeoutConfig 请求级截止时间配置（SSE/流式等长连接接口不受约束，由各自的生成超时控制）
+type RequestTimeoutConfig struct {
+	// Default 默认截止时间（0 表示不限制），应小于 write_timeout
+	Default time.Duration `yaml:"default" mapstructure:"default"`
+	// Routes 路由级截止时间（按路由模板前缀匹配，最长前缀优先；0 表示不限制）
+	Routes []RouteRequestTimeout `yaml:"routes" mapstructure:"routes"`
+}
+
+// RouteRequestTimeout 路由级截止时间
+type RouteRequestTimeout struct {
+	Prefix  string        `yaml:"prefix" mapstructure:"prefix"`
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
 }
 
 // GRPCServerConfig gRPC 服务器配置
@@ -91,6 +306,10 @@ type PostgresConfig struct {
 	MaxIdleConns    int           `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time"`
+	// AutoMigrate 启动时自动执行待执行迁移（仅建议开发环境开启）
+	AutoMigrate bool `yaml:"auto_migrate" mapstructure:"auto_migrate"`
+	// SlowQueryThreshold 慢查询阈值，超过即记录告警日志（0 表示不记录）
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" mapstructure:"slow_query_threshold"`
 }
 
 // CacheConfig 缓存配置
@@ -113,7 +332,29 @@ type RedisConfig struct {
 
 // VectorConfig 向量数据库配置
 type VectorConfig struct {
-	Milvus MilvusConfig `yaml:"milvus" mapstructure:"milvus"`
+	Milvus MilvusConfig      `yaml:"milvus" mapstructure:"milvus"`
+	Quota  VectorQuotaConfig `yaml:"quota" mapstructure:"quota"`
+	Rerank RerankConfig      `yaml:"rerank" mapstructure:"rerank"`
+}
+
+// RerankConfig 召回后精排：按与查询（章节生成时为章节大纲）的相关性对候选片段重新排序。
+// provider 为 api 时调用外部 rerank 接口（POST {endpoint}/rerank，Cohere / Jina / BGE 兼容格式），
+// 为 llm 时由 llm_provider 指定的模型逐批打分；精排失败或超时时保持向量召回顺序
+type RerankConfig struct {
+	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`
+	Provider string `yaml:"provider" mapstructure:"provider"`
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+	APIKey   string `yaml:"api_key" mapstructure:"api_key"`
+	Model    string `yaml:"model" mapstructure:"model"`
+	// LLMProvider provider 为 llm 时使用的提供商（留空使用 llm.default_provider）
+	LLMProvider string        `yaml:"llm_provider" mapstructure:"llm_provider"`
+	Timeout     time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// VectorQuotaConfig 向量配额：按租户 / 项目限制已索引片段数（0 表示不限），超限时索引写入失败
+type VectorQuotaConfig struct {
+	MaxSegmentsPerTenant  int64 `yaml:"max_segments_per_tenant" mapstructure:"max_segments_per_tenant"`
+	MaxSegmentsPerProject int64 `yaml:"max_segments_per_project" mapstructure:"max_segments_per_project"`
 }
 
 // MilvusConfig Milvus 配置
@@ -127,11 +368,67 @@ type MilvusConfig struct {
 	MetricType         string `yaml:"metric_type" mapstructure:"metric_type"`
 	HNSWM              int    `yaml:"hnsw_m" mapstructure:"hnsw_m"`
 	HNSWEfConstruction int    `yaml:"hnsw_ef_construction" mapstructure:"hnsw_ef_construction"`
+
+	// HybridSearch 集合含稀疏向量字段时启用原生混合检索（稠密 + BM25 稀疏两路召回后融合）；
+	// 升级前创建的集合不含该字段，自动退回单向量检索。
+	HybridSearch bool `yaml:"hybrid_search" mapstructure:"hybrid_search"`
+	// HybridRanker 两路结果的融合方式：weighted（按权重加权归一化得分，默认）/ rrf（按名次倒数融合，忽略权重）
+	HybridRanker string `yaml:"hybrid_ranker" mapstructure:"hybrid_ranker"`
+	// RRFK RRF 平滑常数 k（得分为 Σ 1/(k+rank)），默认 60
+	RRFK float64 `yaml:"rrf_k" mapstructure:"rrf_k"`
+	// DenseWeight / SparseWeight weighted 融合中稠密/稀疏通道的默认权重（请求可经 vector_weight / keyword_weight 覆盖）
+	DenseWeight  float64 `yaml:"dense_weight" mapstructure:"dense_weight"`
+	SparseWeight float64 `yaml:"sparse_weight" mapstructure:"sparse_weight"`
 }
 
 // StorageConfig 对象存储配置
 type StorageConfig struct {
-	R2 R2Config `yaml:"r2" mapstructure:"r2"`
+	// Driver 存储驱动：local / s3 / minio / r2
+	Driver string `yaml:"driver" mapstructure:"driver"`
+	// SignedURLTTL 签名 URL 默认有效期
+	SignedURLTTL time.Duration `yaml:"signed_url_ttl" mapstructure:"signed_url_ttl"`
+
+	Local LocalStorageConfig `yaml:"local" mapstructure:"local"`
+	S3    S3Config           `yaml:"s3" mapstructure:"s3"`
+	R2    R2Config           `yaml:"r2" mapstructure:"r2"`
+
+	// Lifecycle 过期对象清理（由 Worker 周期执行）
+	Lifecycle StorageLifecycleConfig `yaml:"lifecycle" mapstructure:"lifecycle"`
+}
+
+// LocalStorageConfig 本地文件系统存储配置
+type LocalStorageConfig struct {
+	// Root 存储根目录
+	Root string `yaml:"root" mapstructure:"root"`
+	// BaseURL 签名 URL 前缀（由 API 服务挂载，如 http://localhost:8080/objects）
+	BaseURL string `yaml:"base_url" mapstructure:"base_url"`
+	// SigningKey 签名密钥（为空时不支持签名 URL）
+	SigningKey string `yaml:"signing_key" mapstructure:"signing_key"`
+}
+
+// S3Config S3 兼容存储配置（AWS S3 / MinIO）
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint" mapstructure:"endpoint"`
+	Region          string `yaml:"region" mapstructure:"region"`
+	Bucket          string `yaml:"bucket" mapstructure:"bucket"`
+	AccessKeyID     string `yaml:"access_key_id" mapstructure:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" mapstructure:"secret_access_key"`
+	// UsePathStyle 使用路径寻址（minio 驱动始终开启）
+	UsePathStyle bool `yaml:"use_path_style" mapstructure:"use_path_style"`
+}
+
+// StorageLifecycleConfig 对象生命周期配置
+type StorageLifecycleConfig struct {
+	// Interval 清理间隔（0 表示不清理）
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+	// Rules 按前缀的保留时长
+	Rules []StorageLifecycleRule `yaml:"rules" mapstructure:"rules"`
+}
+
+// StorageLifecycleRule 前缀保留规则
+type StorageLifecycleRule struct {
+	Prefix string        `yaml:"prefix" mapstructure:"prefix"`
+	MaxAge time.Duration `yaml:"max_age" mapstructure:"max_age"`
 }
 
 // R2Config Cloudflare R2 配置
@@ -147,16 +444,82 @@ type R2Config struct {
 type LLMConfig struct {
 	DefaultProvider string                    `yaml:"default_provider" mapstructure:"default_provider"`
 	Providers       map[string]ProviderConfig `yaml:"providers" mapstructure:"providers"`
+
+	// Cassette LLM 调用录制/回放（集成测试用）
+	Cassette LLMCassetteConfig `yaml:"cassette" mapstructure:"cassette"`
+
+	// CircuitBreaker 按提供商 + 模型熔断
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
 }
 
+// CircuitBreakerConfig LLM 熔断配置：最近 Window 次调用中失败率达到 FailureRate（且不少于 MinRequests 次）时熔断，
+// 熔断期间直接切换到提供商的 Fallbacks；OpenDuration 后进入半开状态，放行 HalfOpenProbes 次探测，全部成功则恢复
+type CircuitBreakerConfig struct {
+	Enabled        bool          `yaml:"enabled" mapstructure:"enabled"`
+	Window         int           `yaml:"window" mapstructure:"window"`
+	MinRequests    int           `yaml:"min_requests" mapstructure:"min_requests"`
+	FailureRate    float64       `yaml:"failure_rate" mapstructure:"failure_rate"`
+	OpenDuration   time.Duration `yaml:"open_duration" mapstructure:"open_duration"`
+	HalfOpenProbes int           `yaml:"half_open_probes" mapstructure:"half_open_probes"`
+}
+
+// LLM 调用录制/回放模式
+const (
+	CassetteModeOff    = "off"
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+	// CassetteModeAuto 命中回放、未命中则调用真实提供商并录制
+	CassetteModeAuto = "auto"
+)
+
+// LLMCassetteConfig LLM 调用录制/回放配置：record 将真实响应（含流式分片与工具调用）写入 Dir，
+// replay 仅从 Dir 回放，未命中时报错，保证 CI 中的结果确定且不访问外部服务。
+type LLMCassetteConfig struct {
+	Mode string `yaml:"mode" mapstructure:"mode"`
+	Dir  string `yaml:"dir" mapstructure:"dir"`
+}
+
+// LLM 提供商类型
+const (
+	// ProviderTypeOpenAI OpenAI 兼容接口（默认）
+	ProviderTypeOpenAI = "openai"
+	// ProviderTypeMock 本地确定性输出（不访问外部服务），用于离线开发与集成测试
+	ProviderTypeMock = "mock"
+)
+
 // ProviderConfig LLM 提供商配置 (兼容 OpenAI 格式)
 type ProviderConfig struct {
+	// Type 提供商类型：openai（默认）/ mock
+	Type        string        `yaml:"type" mapstructure:"type"`
 	APIKey      string        `yaml:"api_key" mapstructure:"api_key"`
 	BaseURL     string        `yaml:"base_url" mapstructure:"base_url"`
 	Model       string        `yaml:"model" mapstructure:"model"`
 	MaxTokens   int           `yaml:"max_tokens" mapstructure:"max_tokens"`
 	Temperature float64       `yaml:"temperature" mapstructure:"temperature"`
 	Timeout     time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	// Fallbacks 熔断时按顺序改用的提供商（使用其自身的默认模型）
+	Fallbacks []string `yaml:"fallbacks" mapstructure:"fallbacks"`
+
+	Pricing ProviderPricing `yaml:"pricing" mapstructure:"pricing"`
+}
+
+// ProviderPricing 提供商计费单价（按每 1K Token 计；未配置时成本预估仅返回 Token 数）
+type ProviderPricing struct {
+	InputPer1K  float64 `yaml:"input_per_1k" mapstructure:"input_per_1k"`
+	OutputPer1K float64 `yaml:"output_per_1k" mapstructure:"output_per_1k"`
+	Currency    string  `yaml:"currency" mapstructure:"currency"`
+}
+
+// UnknownCurrency 已配置单价但未配置币种时，成本汇总使用的币种标识
+const UnknownCurrency = "unknown"
+
+// Cost 按单价折算实际用量成本（保留 6 位小数）；未配置单价时返回 false
+func (p ProviderPricing) Cost(promptTokens, completionTokens int) (float64, bool) {
+	if p.InputPer1K <= 0 && p.OutputPer1K <= 0 {
+		return 0, false
+	}
+	cost := float64(promptTokens)/1000*p.InputPer1K + float64(completionTokens)/1000*p.OutputPer1K
+	return math.Round(cost*1e6) / 1e6, true
 }
 
 // EmbeddingConfig Embedding 配置
@@ -171,7 +534,18 @@ type EmbeddingConfig struct {
 
 // MessagingConfig 消息队列配置
 type MessagingConfig struct {
-	RedisStream RedisStreamConfig `yaml:"redis_stream" mapstructure:"redis_stream"`
+	RedisStream  RedisStreamConfig  `yaml:"redis_stream" mapstructure:"redis_stream"`
+	Backpressure BackpressureConfig `yaml:"backpressure" mapstructure:"backpressure"`
+}
+
+// BackpressureConfig 生成队列背压配置（Worker 上报队列统计，网关估算排队并按阈值拒绝）
+type BackpressureConfig struct {
+	// StatsInterval Worker 上报队列深度与任务耗时的间隔（统计超过 3 个间隔未更新视为不可用）
+	StatsInterval time.Duration `yaml:"stats_interval" mapstructure:"stats_interval"`
+	// DefaultJobDuration 尚无耗时样本时用于估算的单任务时长
+	DefaultJobDuration time.Duration `yaml:"default_job_duration" mapstructure:"default_job_duration"`
+	// RejectQueueDepth 队列深度达到该值时拒绝新的生成任务（503）；0 表示不拒绝
+	RejectQueueDepth int `yaml:"reject_queue_depth" mapstructure:"reject_queue_depth"`
 }
 
 // RedisStreamConfig Redis Stream 配置
@@ -182,6 +556,8 @@ type RedisStreamConfig struct {
 	ClaimInterval       time.Duration `yaml:"claim_interval" mapstructure:"claim_interval"`
 	RetryLimit          int           `yaml:"retry_limit" mapstructure:"retry_limit"`
 	RetryBackoff        BackoffConfig `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+	// StalledClaimThreshold 同一消息投递次数超过该值时视为消费停滞并告警；0 表示取 2 × retry_limit
+	StalledClaimThreshold int `yaml:"stalled_claim_threshold" mapstructure:"stalled_claim_threshold"`
 }
 
 // BackoffConfig 退避配置
@@ -193,9 +569,14 @@ type BackoffConfig struct {
 
 // ObservabilityConfig 可观测性配置
 type ObservabilityConfig struct {
-	Logging LoggingConfig `yaml:"logging" mapstructure:"logging"`
-	Tracing TracingConfig `yaml:"tracing" mapstructure:"tracing"`
-	Metrics MetricsConfig `yaml:"metrics" mapstructure:"metrics"`
+	Logging   LoggingConfig   `yaml:"logging" mapstructure:"logging"`
+	AccessLog AccessLogConfig `yaml:"access_log" mapstructure:"access_log"`
+	Tracing   TracingConfig   `yaml:"tracing" mapstructure:"tracing"`
+	Metrics   MetricsConfig   `yaml:"metrics" mapstructure:"metrics"`
+	// Diagnostics 运行时诊断（pprof/expvar/goroutine 与 heap 快照）
+	Diagnostics DiagnosticsConfig `yaml:"diagnostics" mapstructure:"diagnostics"`
+	// Redaction 日志与链路导出的统一脱敏（Prompt/正文/凭据替换，邮箱/IP 等标识哈希）
+	Redaction logger.RedactionConfig `yaml:"redaction" mapstructure:"redaction"`
 }
 
 // LoggingConfig 日志配置
@@ -205,6 +586,28 @@ type LoggingConfig struct {
 	Output string `yaml:"output" mapstructure:"output"`
 }
 
+// AccessLogConfig HTTP 访问日志配置：错误响应（>= 400）与慢请求始终记录，成功请求按采样率记录
+type AccessLogConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// SampleRate 成功请求的默认采样率 [0, 1]
+	SampleRate float64 `yaml:"sample_rate" mapstructure:"sample_rate"`
+	// SlowThreshold 耗时超过该值的请求始终记录；0 表示不按耗时记录
+	SlowThreshold time.Duration `yaml:"slow_threshold" mapstructure:"slow_threshold"`
+	// SkipPaths 不记录的请求路径（健康检查、指标端点等）
+	SkipPaths []string `yaml:"skip_paths" mapstructure:"skip_paths"`
+	// Routes 按路由覆盖采样率
+	Routes []AccessLogRouteConfig `yaml:"routes" mapstructure:"routes"`
+}
+
+// AccessLogRouteConfig 单个路由的采样率覆盖
+type AccessLogRouteConfig struct {
+	// Method 为空表示匹配全部方法
+	Method string `yaml:"method" mapstructure:"method"`
+	// Route Gin 路由模板，如 /v1/projects/:pid/sessions/:sid/messages
+	Route      string  `yaml:"route" mapstructure:"route"`
+	SampleRate float64 `yaml:"sample_rate" mapstructure:"sample_rate"`
+}
+
 // TracingConfig 追踪配置
 type TracingConfig struct {
 	Enabled    bool    `yaml:"enabled" mapstructure:"enabled"`
@@ -220,6 +623,19 @@ type MetricsConfig struct {
 	Path    string `yaml:"path" mapstructure:"path"`
 }
 
+// DiagnosticsConfig 运行时诊断配置
+type DiagnosticsConfig struct {
+	// Enabled 启用诊断端点：网关挂载在 /v1/ops/diagnostics（仅 admin）；配置 Port 时各进程另在独立端口暴露
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Host / Port 独立诊断端口（Port 为 0 时不监听）；该端口不做认证，应仅监听本机或内网地址
+	Host string `yaml:"host" mapstructure:"host"`
+	Port int    `yaml:"port" mapstructure:"port"`
+	// SnapshotDir goroutine/heap 快照目录（为空时使用系统临时目录）
+	SnapshotDir string `yaml:"snapshot_dir" mapstructure:"snapshot_dir"`
+	// MaxSnapshots 快照保留数量，超出时删除最旧的
+	MaxSnapshots int `yaml:"max_snapshots" mapstructure:"max_snapshots"`
+}
+
 // SecurityConfig 安全配置
 type SecurityConfig struct {
 	JWT       JWTConfig       `yaml:"jwt" mapstructure:"jwt"`
@@ -8,11 +8,22 @@ import (
 	"strings"
 
 	"github.com/spf13/viper"
+
+	"z-novel-ai-api/pkg/logger"
 )
 
 // Load 加载配置文件
 // 按优先级加载：默认配置 -> 环境配置 -> 环境变量
 func Load() (*Config, error) {
+	return load(false)
+}
+
+// LoadStrict 严格加载配置：配置文件中存在无法映射到配置结构的键（通常是拼写错误）时返回错误
+func LoadStrict() (*Config, error) {
+	return load(true)
+}
+
+func load(strict bool) (*Config, error) {
 	v := viper.New()
 	v.SetConfigType("yaml")
 
@@ -40,7 +51,11 @@ func Load() (*Config, error) {
 
 	// 解析配置
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
+	unmarshal := v.Unmarshal
+	if strict {
+		unmarshal = v.UnmarshalExact
+	}
+	if err := unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -114,6 +129,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("app.name", "z-novel-ai-api")
 	v.SetDefault("app.version", "v0.0.0")
 	v.SetDefault("app.env", "development")
+	v.SetDefault("app.strict_config", false)
 
 	// HTTP 服务器默认值
 	v.SetDefault("server.http.host", "0.0.0.0")
@@ -121,6 +137,23 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.http.read_timeout", "30s")
 	v.SetDefault("server.http.write_timeout", "60s")
 	v.SetDefault("server.http.idle_timeout", "120s")
+	v.SetDefault("server.http.default_locale", "en")
+	v.SetDefault("server.http.compression.enabled", true)
+	v.SetDefault("server.http.compression.level", 5)
+	v.SetDefault("server.http.compression.min_size", 1024)
+	v.SetDefault("server.http.body_limit.default_max_bytes", 2<<20)
+	v.SetDefault("server.http.body_limit.routes", []map[string]any{
+		{"prefix": "/v1/auth/", "max_bytes": 16 << 10},
+		{"prefix": "/v1/projects/:pid/ingest-notes", "max_bytes": 16 << 20},
+		{"prefix": "/objects/", "max_bytes": 64 << 20},
+	})
+	v.SetDefault("server.http.request_timeout.default", "30s")
+	v.SetDefault("server.http.request_timeout.routes", []map[string]any{
+		{"prefix": "/v1/retrieval/", "timeout": "10s"},
+		{"prefix": "/v1/projects/:pid/retrieval/", "timeout": "10s"},
+		{"prefix": "/v1/chapters/:cid/suggest-titles", "timeout": "55s"},
+		{"prefix": "/v1/ops/warmup", "timeout": "90s"},
+	})
 
 	// gRPC 服务器默认值
 	v.SetDefault("server.grpc.host", "0.0.0.0")
@@ -134,6 +167,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("clients.grpc.story_gen_service_addr", "localhost:50053")
 	v.SetDefault("clients.grpc.memory_service_addr", "localhost:50054")
 	v.SetDefault("clients.grpc.validator_service_addr", "localhost:50055")
+	v.SetDefault("clients.grpc.proxy_chapter_stream", false)
 
 	// 数据库默认值
 	v.SetDefault("database.postgres.host", "localhost")
@@ -145,6 +179,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.postgres.max_idle_conns", 10)
 	v.SetDefault("database.postgres.conn_max_lifetime", "30m")
 	v.SetDefault("database.postgres.conn_max_idle_time", "5m")
+	v.SetDefault("database.postgres.auto_migrate", false)
+	v.SetDefault("database.postgres.slow_query_threshold", "200ms")
 
 	// Redis 默认值
 	v.SetDefault("cache.redis.host", "localhost")
@@ -156,6 +192,11 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("cache.redis.read_timeout", "3s")
 	v.SetDefault("cache.redis.write_timeout", "3s")
 
+	// 生成队列背压默认值
+	v.SetDefault("messaging.backpressure.stats_interval", "10s")
+	v.SetDefault("messaging.backpressure.default_job_duration", "90s")
+	v.SetDefault("messaging.backpressure.reject_queue_depth", 0)
+
 	// Milvus 默认值
 	v.SetDefault("vector.milvus.host", "localhost")
 	v.SetDefault("vector.milvus.port", 19530)
@@ -164,11 +205,28 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("vector.milvus.metric_type", "COSINE")
 	v.SetDefault("vector.milvus.hnsw_m", 16)
 	v.SetDefault("vector.milvus.hnsw_ef_construction", 200)
+	v.SetDefault("vector.milvus.hybrid_search", true)
+	v.SetDefault("vector.milvus.hybrid_ranker", "weighted")
+	v.SetDefault("vector.milvus.rrf_k", 60)
+	v.SetDefault("vector.milvus.dense_weight", 0.7)
+	v.SetDefault("vector.milvus.sparse_weight", 0.3)
+	v.SetDefault("vector.rerank.enabled", false)
+	v.SetDefault("vector.rerank.provider", "api")
+	v.SetDefault("vector.rerank.timeout", "5s")
 
 	// 可观测性默认值
 	v.SetDefault("observability.logging.level", "info")
 	v.SetDefault("observability.logging.format", "json")
 	v.SetDefault("observability.logging.output", "stdout")
+	v.SetDefault("observability.access_log.enabled", true)
+	v.SetDefault("observability.access_log.sample_rate", 1.0)
+	v.SetDefault("observability.access_log.slow_threshold", "2s")
+	v.SetDefault("observability.access_log.skip_paths", []string{"/health", "/ready", "/live", "/metrics"})
+	v.SetDefault("observability.redaction.enabled", true)
+	v.SetDefault("observability.redaction.max_value_length", logger.DefaultMaxValueLength)
+	v.SetDefault("observability.diagnostics.enabled", false)
+	v.SetDefault("observability.diagnostics.host", "127.0.0.1")
+	v.SetDefault("observability.diagnostics.max_snapshots", 20)
 	v.SetDefault("observability.tracing.enabled", true)
 	v.SetDefault("observability.tracing.exporter", "otlp")
 	v.SetDefault("observability.tracing.endpoint", "localhost:4317")
@@ -184,4 +242,76 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("security.rate_limit.enabled", true)
 	v.SetDefault("security.rate_limit.requests_per_second", 100)
 	v.SetDefault("security.rate_limit.burst", 200)
+
+	// 创作业务规则默认值
+	v.SetDefault("story.story_time_check", "warn")
+	v.SetDefault("story.autosave_version_interval", "2m")
+	v.SetDefault("story.relation_half_life_chapters", 50)
+	v.SetDefault("story.sync_generation.on_disconnect", "detach")
+	v.SetDefault("story.sync_generation.detach_timeout", "10m")
+	v.SetDefault("story.generation_timeouts.chapter_gen", "10m")
+	v.SetDefault("story.generation_timeouts.foundation_gen", "5m")
+	v.SetDefault("story.generation_timeouts.artifact_gen", "5m")
+	v.SetDefault("story.generation_timeouts.conflict_scan", "90s")
+	v.SetDefault("story.generation_timeouts.title_suggest", "60s")
+	v.SetDefault("story.auto_title_suggestions", true)
+	v.SetDefault("story.duplicate_similarity_threshold", 0.9)
+	v.SetDefault("story.job_cost_ceiling_tokens", 200000)
+	v.SetDefault("story.best_of_n.max_candidates", 3)
+	v.SetDefault("story.best_of_n.max_total_tokens", 60000)
+	v.SetDefault("story.reindex.enabled", true)
+	v.SetDefault("story.reindex.debounce", "30s")
+	v.SetDefault("story.reindex.max_delay", "5m")
+	v.SetDefault("story.reindex.poll_interval", "5s")
+	v.SetDefault("story.generation_consistency.enabled", true)
+	v.SetDefault("story.generation_consistency.interval", "5m")
+	v.SetDefault("story.generation_consistency.grace", "15m")
+	v.SetDefault("story.foundation_plan_limits.max_entities", 300)
+	v.SetDefault("story.foundation_plan_limits.max_relations", 1000)
+	v.SetDefault("story.foundation_plan_limits.max_volumes", 30)
+	v.SetDefault("story.foundation_plan_limits.max_chapters", 600)
+	v.SetDefault("story.foundation_plan_limits.max_outline_chars", 600000)
+
+	// 公开只读 API 默认值
+	v.SetDefault("public_api.enabled", true)
+	v.SetDefault("public_api.max_age", "60s")
+	v.SetDefault("public_api.shared_max_age", "10m")
+	v.SetDefault("public_api.stale_while_revalidate", "24h")
+	v.SetDefault("public_api.rate_limit.enabled", true)
+	v.SetDefault("public_api.rate_limit.requests_per_second", 20)
+	v.SetDefault("public_api.rate_limit.burst", 40)
+
+	// LLM 录制/回放默认值
+	v.SetDefault("llm.cassette.mode", "off")
+	v.SetDefault("llm.cassette.dir", "testdata/llm_cassettes")
+
+	// LLM 熔断默认值
+	v.SetDefault("llm.circuit_breaker.enabled", true)
+	v.SetDefault("llm.circuit_breaker.window", 20)
+	v.SetDefault("llm.circuit_breaker.min_requests", 10)
+	v.SetDefault("llm.circuit_breaker.failure_rate", 0.5)
+	v.SetDefault("llm.circuit_breaker.open_duration", "30s")
+	v.SetDefault("llm.circuit_breaker.half_open_probes", 1)
+
+	// 计费默认值
+	v.SetDefault("billing.enabled", false)
+	v.SetDefault("billing.provider", "stripe")
+	v.SetDefault("billing.signature_tolerance", "5m")
+	v.SetDefault("billing.tokens_per_minor_unit", 0)
+
+	// 来源标记默认值
+	v.SetDefault("provenance.enabled", false)
+	v.SetDefault("provenance.mode", "metadata")
+
+	// 启动预热默认值
+	v.SetDefault("warmup.enabled", false)
+	v.SetDefault("warmup.timeout", "60s")
+	v.SetDefault("warmup.ping_providers", false)
+
+	// 对象存储默认值
+	v.SetDefault("storage.driver", "local")
+	v.SetDefault("storage.signed_url_ttl", "15m")
+	v.SetDefault("storage.local.root", "data/objects")
+	v.SetDefault("storage.s3.region", "us-east-1")
+	v.SetDefault("storage.lifecycle.interval", "1h")
 }
@@ -0,0 +1,527 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Severity 校验问题级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 配置不可用，严格模式下阻止启动
+	SeverityWarning Severity = "warning" // 可运行但可能不符合预期
+)
+
+// Issue 配置校验问题
+type Issue struct {
+	Severity Severity
+	// Field 配置路径（与 YAML 键一致，如 llm.providers.openai.base_url）
+	Field   string
+	Message string
+}
+
+// String 格式化为单行报告
+func (i Issue) String() string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(i.Severity)), i.Field, i.Message)
+}
+
+// ValidationReport 配置校验结果
+type ValidationReport struct {
+	Issues []Issue
+}
+
+// HasErrors 是否存在错误级问题
+func (r *ValidationReport) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors 返回错误级问题
+func (r *ValidationReport) Errors() []Issue {
+	var out []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+func (r *ValidationReport) errorf(field, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Severity: SeverityError, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) warnf(field, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Severity: SeverityWarning, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// CheckStartup 服务启动时校验配置。
+// 严格模式（app.strict_config）下重新严格加载以检查未知键，且存在错误级问题时返回 error；
+// 非严格模式仅返回校验结果，由调用方记录告警。
+func CheckStartup(cfg *Config) (*ValidationReport, error) {
+	report := cfg.Validate()
+	if !cfg.App.StrictConfig {
+		return report, nil
+	}
+	if _, err := LoadStrict(); err != nil {
+		return report, err
+	}
+	if report.HasErrors() {
+		return report, fmt.Errorf("invalid config: %d error(s)", len(report.Errors()))
+	}
+	return report, nil
+}
+
+// unresolvedEnvPattern 未被替换的 ${VAR} 占位符（环境变量缺失且无默认值）
+var unresolvedEnvPattern = regexp.MustCompile(`\$\{\w+\}`)
+
+// Validate 对配置做静态校验（不访问外部依赖）：取值范围、枚举值、必填项与跨字段一致性
+func (c *Config) Validate() *ValidationReport {
+	r := &ValidationReport{}
+
+	validatePort(r, "server.http.port", c.Server.HTTP.Port)
+	if comp := c.Server.HTTP.Compression; comp.Enabled && (comp.Level < 1 || comp.Level > 9) {
+		r.errorf("server.http.compression.level", "must be between 1 and 9")
+	}
+	if c.Server.HTTP.BodyLimit.DefaultMaxBytes < 0 {
+		r.errorf("server.http.body_limit.default_max_bytes", "must not be negative")
+	}
+	for i, rule := range c.Server.HTTP.BodyLimit.Routes {
+		field := fmt.Sprintf("server.http.body_limit.routes[%d]", i)
+		if rule.Prefix == "" {
+			r.errorf(field+".prefix", "must not be empty")
+		}
+		if rule.MaxBytes <= 0 {
+			r.errorf(field+".max_bytes", "must be positive")
+		}
+	}
+	if rt := c.Server.HTTP.RequestTimeout; rt.Default < 0 {
+		r.errorf("server.http.request_timeout.default", "must not be negative")
+	} else if wt := c.Server.HTTP.WriteTimeout; wt > 0 && rt.Default >= wt {
+		r.warnf("server.http.request_timeout.default", "should be shorter than server.http.write_timeout (%s) so timeouts are reported before the connection is cut", wt)
+	}
+	for i, rule := range c.Server.HTTP.RequestTimeout.Routes {
+		field := fmt.Sprintf("server.http.request_timeout.routes[%d]", i)
+		if !strings.HasPrefix(rule.Prefix, "/") {
+			r.errorf(field+".prefix", "must start with /")
+		}
+		if rule.Timeout < 0 {
+			r.errorf(field+".timeout", "must not be negative")
+		}
+	}
+	validatePort(r, "server.grpc.port", c.Server.GRPC.Port)
+
+	pg := c.Database.Postgres
+	requireString(r, "database.postgres.host", pg.Host)
+	validatePort(r, "database.postgres.port", pg.Port)
+	requireString(r, "database.postgres.user", pg.User)
+	requireString(r, "database.postgres.database", pg.Database)
+	checkSecret(r, "database.postgres.password", pg.Password, false)
+	validateEnum(r, "database.postgres.ssl_mode", pg.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	if pg.MaxOpenConns > 0 && pg.MaxIdleConns > pg.MaxOpenConns {
+		r.warnf("database.postgres.max_idle_conns", "greater than max_open_conns (%d > %d)", pg.MaxIdleConns, pg.MaxOpenConns)
+	}
+	if pg.SlowQueryThreshold < 0 {
+		r.errorf("database.postgres.slow_query_threshold", "must not be negative")
+	}
+
+	requireString(r, "cache.redis.host", c.Cache.Redis.Host)
+	validatePort(r, "cache.redis.port", c.Cache.Redis.Port)
+	checkSecret(r, "cache.redis.password", c.Cache.Redis.Password, false)
+
+	mv := c.Vector.Milvus
+	requireString(r, "vector.milvus.host", mv.Host)
+	validatePort(r, "vector.milvus.port", mv.Port)
+	checkSecret(r, "vector.milvus.password", mv.Password, false)
+	validateEnum(r, "vector.milvus.metric_type", mv.MetricType, "L2", "IP", "COSINE")
+	validateEnum(r, "vector.milvus.index_type", mv.IndexType, "HNSW", "IVF_FLAT", "IVF_SQ8", "IVF_PQ", "FLAT", "AUTOINDEX")
+	if mv.HybridSearch {
+		if mv.HybridRanker != "" {
+			validateEnum(r, "vector.milvus.hybrid_ranker", mv.HybridRanker, "weighted", "rrf")
+		}
+		if mv.RRFK < 0 {
+			r.errorf("vector.milvus.rrf_k", "must not be negative")
+		}
+		if mv.DenseWeight < 0 || mv.DenseWeight > 1 || mv.SparseWeight < 0 || mv.SparseWeight > 1 {
+			r.errorf("vector.milvus.dense_weight", "dense_weight and sparse_weight must be within [0, 1]")
+		} else if mv.DenseWeight+mv.SparseWeight == 0 {
+			r.errorf("vector.milvus.dense_weight", "dense_weight and sparse_weight must not both be zero")
+		}
+	}
+	if rr := c.Vector.Rerank; rr.Enabled {
+		validateEnum(r, "vector.rerank.provider", rr.Provider, "api", "llm")
+		switch rr.Provider {
+		case "api":
+			validateURL(r, "vector.rerank.endpoint", rr.Endpoint)
+			checkSecret(r, "vector.rerank.api_key", rr.APIKey, false)
+		case "llm":
+			if rr.LLMProvider != "" {
+				if _, ok := c.LLM.Providers[rr.LLMProvider]; !ok {
+					r.errorf("vector.rerank.llm_provider", "%q is not one of the configured providers (%s)", rr.LLMProvider, strings.Join(c.ProviderNames(), ", "))
+				}
+			}
+		}
+		if rr.Timeout < 0 {
+			r.errorf("vector.rerank.timeout", "must not be negative")
+		}
+	}
+	if q := c.Vector.Quota; q.MaxSegmentsPerTenant < 0 || q.MaxSegmentsPerProject < 0 {
+		r.errorf("vector.quota", "segment limits must not be negative")
+	}
+
+	c.validateLLM(r)
+
+	emb := c.Embedding
+	if emb.Dimension <= 0 {
+		r.errorf("embedding.dimension", "must be positive")
+	}
+	if emb.BatchSize < 0 {
+		r.errorf("embedding.batch_size", "must not be negative")
+	}
+	switch {
+	case emb.Provider == "fake":
+		r.warnf("embedding.provider", "fake embedder in use, retrieval results are not semantic")
+	case emb.Endpoint == "":
+		r.warnf("embedding.endpoint", "not set, vector features will be disabled")
+	default:
+		validateURL(r, "embedding.endpoint", emb.Endpoint)
+		requireString(r, "embedding.model", emb.Model)
+	}
+	checkSecret(r, "embedding.api_key", emb.APIKey, false)
+
+	rs := c.Messaging.RedisStream
+	if rs.RetryLimit < 0 {
+		r.errorf("messaging.redis_stream.retry_limit", "must not be negative")
+	}
+	if rs.StalledClaimThreshold < 0 {
+		r.errorf("messaging.redis_stream.stalled_claim_threshold", "must not be negative")
+	} else if rs.StalledClaimThreshold > 0 && rs.StalledClaimThreshold < rs.RetryLimit {
+		r.warnf("messaging.redis_stream.stalled_claim_threshold", "below retry_limit, normal retries will be reported as stalled")
+	}
+	if rs.RetryBackoff.Multiplier != 0 && rs.RetryBackoff.Multiplier < 1 {
+		r.errorf("messaging.redis_stream.retry_backoff.multiplier", "must be >= 1")
+	}
+	if rs.RetryBackoff.Max > 0 && rs.RetryBackoff.Initial > rs.RetryBackoff.Max {
+		r.errorf("messaging.redis_stream.retry_backoff.initial", "greater than retry_backoff.max")
+	}
+	if c.Messaging.Backpressure.RejectQueueDepth < 0 {
+		r.errorf("messaging.backpressure.reject_queue_depth", "must not be negative")
+	}
+
+	obs := c.Observability
+	validateEnum(r, "observability.logging.level", strings.ToLower(obs.Logging.Level), "debug", "info", "warn", "error")
+	validateEnum(r, "observability.logging.format", strings.ToLower(obs.Logging.Format), "json", "text")
+	if obs.AccessLog.SampleRate < 0 || obs.AccessLog.SampleRate > 1 {
+		r.errorf("observability.access_log.sample_rate", "must be within [0, 1]")
+	}
+	if obs.AccessLog.SlowThreshold < 0 {
+		r.errorf("observability.access_log.slow_threshold", "must not be negative")
+	}
+	for i, route := range obs.AccessLog.Routes {
+		field := fmt.Sprintf("observability.access_log.routes[%d]", i)
+		if !strings.HasPrefix(route.Route, "/") {
+			r.errorf(field+".route", "must start with /")
+		}
+		if route.SampleRate < 0 || route.SampleRate > 1 {
+			r.errorf(field+".sample_rate", "must be within [0, 1]")
+		}
+	}
+	if obs.Tracing.SampleRate < 0 || obs.Tracing.SampleRate > 1 {
+		r.errorf("observability.tracing.sample_rate", "must be within [0, 1]")
+	}
+	if obs.Tracing.Enabled && obs.Tracing.Endpoint == "" {
+		r.errorf("observability.tracing.endpoint", "required when tracing is enabled")
+	}
+	if obs.Metrics.Enabled && !strings.HasPrefix(obs.Metrics.Path, "/") {
+		r.errorf("observability.metrics.path", "must start with /")
+	}
+	if d := obs.Diagnostics; d.Port < 0 || d.Port > 65535 {
+		r.errorf("observability.diagnostics.port", "must be between 0 and 65535")
+	}
+	if obs.Diagnostics.MaxSnapshots < 0 {
+		r.errorf("observability.diagnostics.max_snapshots", "must not be negative")
+	}
+	if obs.Redaction.MaxValueLength < 0 {
+		r.errorf("observability.redaction.max_value_length", "must not be negative")
+	}
+	if !obs.Redaction.Enabled && c.App.Env == "production" {
+		r.warnf("observability.redaction.enabled", "disabled in production: prompts, chapter text and emails may leak into logs and traces")
+	}
+
+	jwt := c.Security.JWT
+	checkSecret(r, "security.jwt.secret", jwt.Secret, true)
+	if jwt.Secret != "" && !unresolvedEnvPattern.MatchString(jwt.Secret) && len(jwt.Secret) < 32 {
+		r.warnf("security.jwt.secret", "shorter than 32 bytes")
+	}
+	if jwt.Expiration <= 0 {
+		r.errorf("security.jwt.expiration", "must be positive")
+	}
+	if jwt.RefreshExpiration > 0 && jwt.RefreshExpiration < jwt.Expiration {
+		r.warnf("security.jwt.refresh_expiration", "shorter than access token expiration")
+	}
+	validateRateLimit(r, "security.rate_limit", c.Security.RateLimit)
+	validateRateLimit(r, "public_api.rate_limit", c.PublicAPI.RateLimit)
+
+	validateEnum(r, "story.story_time_check", strings.ToLower(c.Story.StoryTimeCheck), "off", "warn", "reject")
+	if c.Story.RelationHalfLifeChapters < 0 {
+		r.errorf("story.relation_half_life_chapters", "must not be negative")
+	}
+	sg := c.Story.SyncGeneration
+	validateEnum(r, "story.sync_generation.on_disconnect", sg.Policy(""), DisconnectDetach, DisconnectCancel)
+	for endpoint, policy := range sg.Endpoints {
+		if endpoint != SyncEndpointSendMessage && endpoint != SyncEndpointPreviewFoundation {
+			r.warnf("story.sync_generation.endpoints."+endpoint, "unknown endpoint; expected %s or %s", SyncEndpointSendMessage, SyncEndpointPreviewFoundation)
+			continue
+		}
+		validateEnum(r, "story.sync_generation.endpoints."+endpoint, strings.ToLower(strings.TrimSpace(policy)), DisconnectDetach, DisconnectCancel)
+	}
+	if sg.DetachTimeout < 0 {
+		r.errorf("story.sync_generation.detach_timeout", "must not be negative")
+	}
+	gt := c.Story.GenerationTimeouts
+	timeouts := []struct {
+		field    string
+		value    time.Duration
+		detached bool // 同步接口脱离请求后受 detach_timeout 约束
+	}{
+		{"chapter_gen", gt.ChapterGen, false},
+		{"foundation_gen", gt.FoundationGen, true},
+		{"artifact_gen", gt.ArtifactGen, true},
+		{"conflict_scan", gt.ConflictScan, false},
+		{"title_suggest", gt.TitleSuggest, false},
+	}
+	for _, t := range timeouts {
+		if t.value < 0 {
+			r.errorf("story.generation_timeouts."+t.field, "must not be negative")
+		} else if t.detached && sg.DetachTimeout > 0 && t.value > sg.DetachTimeout {
+			r.warnf("story.generation_timeouts."+t.field, "exceeds story.sync_generation.detach_timeout (%s); detached sync generation is cut off first", sg.DetachTimeout)
+		}
+	}
+	if t := c.Story.DuplicateSimilarityThreshold; t < 0 || t > 1 {
+		r.errorf("story.duplicate_similarity_threshold", "must be between 0 and 1 (0 disables the check)")
+	}
+	if ceiling := c.Story.JobCostCeilingTokens; ceiling < 0 {
+		r.errorf("story.job_cost_ceiling_tokens", "must not be negative (0 disables the ceiling)")
+	} else if ceiling > 0 && c.Story.BestOfN.MaxTotalTokens > ceiling {
+		r.warnf("story.job_cost_ceiling_tokens", "lower than story.best_of_n.max_total_tokens (%d); a single failed multi-candidate attempt may end the job", c.Story.BestOfN.MaxTotalTokens)
+	}
+	if c.Story.BestOfN.MaxCandidates < 1 {
+		r.errorf("story.best_of_n.max_candidates", "must be at least 1 (1 disables best-of-N)")
+	}
+	if c.Story.BestOfN.MaxTotalTokens < 0 {
+		r.errorf("story.best_of_n.max_total_tokens", "must not be negative")
+	}
+
+	if c.Billing.Enabled {
+		validateEnum(r, "billing.provider", strings.ToLower(c.Billing.Provider), "stripe", "generic")
+		checkSecret(r, "billing.webhook_secret", c.Billing.WebhookSecret, true)
+	}
+	if c.Billing.TokensPerMinorUnit < 0 {
+		r.errorf("billing.tokens_per_minor_unit", "must not be negative")
+	}
+	if c.Provenance.Enabled {
+		validateEnum(r, "provenance.mode", strings.ToLower(c.Provenance.Mode), "metadata", "zero_width", "both")
+		checkSecret(r, "provenance.secret", c.Provenance.Secret, true)
+	}
+	if c.Warmup.Timeout < 0 {
+		r.errorf("warmup.timeout", "must not be negative")
+	}
+	c.validateStorage(r)
+
+	return r
+}
+
+func (c *Config) validateStorage(r *ValidationReport) {
+	st := c.Storage
+	driver := strings.ToLower(strings.TrimSpace(st.Driver))
+	validateEnum(r, "storage.driver", driver, "local", "s3", "minio", "r2")
+	switch driver {
+	case "local":
+		requireString(r, "storage.local.root", st.Local.Root)
+		checkSecret(r, "storage.local.signing_key", st.Local.SigningKey, false)
+		if st.Local.SigningKey == "" || st.Local.BaseURL == "" {
+			r.warnf("storage.local", "signing_key or base_url not set, signed urls are disabled")
+		}
+	case "s3", "minio":
+		requireString(r, "storage.s3.bucket", st.S3.Bucket)
+		checkSecret(r, "storage.s3.access_key_id", st.S3.AccessKeyID, true)
+		checkSecret(r, "storage.s3.secret_access_key", st.S3.SecretAccessKey, true)
+		if driver == "minio" {
+			validateURL(r, "storage.s3.endpoint", st.S3.Endpoint)
+		}
+	case "r2":
+		requireString(r, "storage.r2.account_id", st.R2.AccountID)
+		requireString(r, "storage.r2.bucket", st.R2.Bucket)
+		checkSecret(r, "storage.r2.access_key_id", st.R2.AccessKeyID, true)
+		checkSecret(r, "storage.r2.secret_access_key", st.R2.SecretAccessKey, true)
+	}
+	if st.SignedURLTTL < 0 {
+		r.errorf("storage.signed_url_ttl", "must not be negative")
+	}
+	for i, rule := range st.Lifecycle.Rules {
+		field := fmt.Sprintf("storage.lifecycle.rules[%d]", i)
+		if strings.TrimSpace(rule.Prefix) == "" {
+			r.errorf(field+".prefix", "must not be empty")
+		}
+		if rule.MaxAge <= 0 {
+			r.errorf(field+".max_age", "must be positive")
+		}
+	}
+}
+
+func (c *Config) validateLLM(r *ValidationReport) {
+	if len(c.LLM.Providers) == 0 {
+		r.errorf("llm.providers", "no provider configured")
+		return
+	}
+	if _, ok := c.LLM.Providers[c.LLM.DefaultProvider]; !ok {
+		r.errorf("llm.default_provider", "%q is not one of the configured providers (%s)", c.LLM.DefaultProvider, strings.Join(c.ProviderNames(), ", "))
+	}
+
+	cassette := c.LLM.Cassette
+	if cassette.Mode != "" {
+		validateEnum(r, "llm.cassette.mode", cassette.Mode, CassetteModeOff, CassetteModeRecord, CassetteModeReplay, CassetteModeAuto)
+	}
+	if cassette.Mode != "" && cassette.Mode != CassetteModeOff {
+		requireString(r, "llm.cassette.dir", cassette.Dir)
+		if c.App.Env == "production" {
+			r.warnf("llm.cassette.mode", "%q is intended for tests, not production", cassette.Mode)
+		}
+	}
+
+	if cb := c.LLM.CircuitBreaker; cb.Enabled {
+		if cb.Window <= 0 {
+			r.errorf("llm.circuit_breaker.window", "must be positive")
+		}
+		if cb.MinRequests <= 0 || (cb.Window > 0 && cb.MinRequests > cb.Window) {
+			r.errorf("llm.circuit_breaker.min_requests", "must be within [1, window]")
+		}
+		if cb.FailureRate <= 0 || cb.FailureRate > 1 {
+			r.errorf("llm.circuit_breaker.failure_rate", "must be within (0, 1]")
+		}
+		if cb.OpenDuration <= 0 {
+			r.errorf("llm.circuit_breaker.open_duration", "must be positive")
+		}
+		if cb.HalfOpenProbes <= 0 {
+			r.errorf("llm.circuit_breaker.half_open_probes", "must be positive")
+		}
+	}
+
+	for _, name := range c.ProviderNames() {
+		p := c.LLM.Providers[name]
+		prefix := "llm.providers." + name
+		switch p.Type {
+		case "", ProviderTypeOpenAI:
+			validateURL(r, prefix+".base_url", p.BaseURL)
+			requireString(r, prefix+".model", p.Model)
+			checkSecret(r, prefix+".api_key", p.APIKey, true)
+		case ProviderTypeMock:
+			if name == c.LLM.DefaultProvider {
+				r.warnf(prefix+".type", "mock provider is the default, generation returns canned output")
+			}
+		default:
+			r.errorf(prefix+".type", "must be one of: %s, %s", ProviderTypeOpenAI, ProviderTypeMock)
+		}
+		if p.MaxTokens < 0 {
+			r.errorf(prefix+".max_tokens", "must not be negative")
+		}
+		if p.Temperature < 0 || p.Temperature > 2 {
+			r.errorf(prefix+".temperature", "must be within [0, 2]")
+		}
+		if p.Timeout < 0 {
+			r.errorf(prefix+".timeout", "must not be negative")
+		}
+		for i, fb := range p.Fallbacks {
+			field := fmt.Sprintf("%s.fallbacks[%d]", prefix, i)
+			if fb == name {
+				r.errorf(field, "must not reference the provider itself")
+			} else if _, ok := c.LLM.Providers[fb]; !ok {
+				r.errorf(field, "%q is not one of the configured providers", fb)
+			}
+		}
+		if p.Pricing.InputPer1K < 0 || p.Pricing.OutputPer1K < 0 {
+			r.errorf(prefix+".pricing", "prices must not be negative")
+		}
+		if (p.Pricing.InputPer1K > 0 || p.Pricing.OutputPer1K > 0) && strings.TrimSpace(p.Pricing.Currency) == "" {
+			r.warnf(prefix+".pricing.currency", "is empty; usage costs will be reported under currency %q", UnknownCurrency)
+		}
+	}
+}
+
+// ProviderNames 返回已配置的 LLM 提供商名称（按字母序）
+func (c *Config) ProviderNames() []string {
+	names := make([]string, 0, len(c.LLM.Providers))
+	for name := range c.LLM.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func requireString(r *ValidationReport, field, value string) {
+	if strings.TrimSpace(value) == "" {
+		r.errorf(field, "is required")
+		return
+	}
+	if unresolvedEnvPattern.MatchString(value) {
+		r.errorf(field, "contains unresolved environment placeholder %s", unresolvedEnvPattern.FindString(value))
+	}
+}
+
+// checkSecret 校验密钥类字段：占位符未替换时报错；required 为 true 时不允许为空
+func checkSecret(r *ValidationReport, field, value string, required bool) {
+	if unresolvedEnvPattern.MatchString(value) {
+		r.errorf(field, "environment variable %s is not set", strings.Trim(unresolvedEnvPattern.FindString(value), "${}"))
+		return
+	}
+	if required && strings.TrimSpace(value) == "" {
+		r.errorf(field, "is required")
+	}
+}
+
+func validatePort(r *ValidationReport, field string, port int) {
+	if port <= 0 || port > 65535 {
+		r.errorf(field, "must be within [1, 65535], got %d", port)
+	}
+}
+
+func validateURL(r *ValidationReport, field, raw string) {
+	if strings.TrimSpace(raw) == "" {
+		r.errorf(field, "is required")
+		return
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		r.errorf(field, "must be an absolute http(s) URL, got %q", raw)
+	}
+}
+
+func validateEnum(r *ValidationReport, field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	r.errorf(field, "%q is not one of: %s", value, strings.Join(allowed, ", "))
+}
+
+func validateRateLimit(r *ValidationReport, field string, cfg RateLimitConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.RequestsPerSecond <= 0 {
+		r.errorf(field+".requests_per_second", "must be positive when rate limiting is enabled")
+	}
+	if cfg.Burst < cfg.RequestsPerSecond {
+		r.warnf(field+".burst", "smaller than requests_per_second")
+	}
+}
@@ -40,12 +40,59 @@ type ArtifactVersion struct {
 	CreatedBy       *string         `json:"created_by,omitempty" gorm:"type:uuid"`
 	SourceJobID     *string         `json:"source_job_id,omitempty" gorm:"type:uuid"`
 	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+
+	// Metadata 版本生成时的附加信息（激活策略与决策原因）；早期版本为空
+	Metadata *ArtifactVersionMetadata `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+// ArtifactVersionMetadata 版本附加信息
+type ArtifactVersionMetadata struct {
+	// ActivationPolicy 写入时生效的项目自动激活策略
+	ActivationPolicy ArtifactActivationPolicy `json:"activation_policy,omitempty"`
+	// Activated 写入时是否被激活
+	Activated bool `json:"activated"`
+	// ActivationReason 激活决策原因（见 ActivationReason* 常量）
+	ActivationReason string `json:"activation_reason,omitempty"`
 }
 
 func (ArtifactVersion) TableName() string {
 	return "artifact_versions"
 }
 
+// ArtifactVersionSummary 版本摘要（不含内容），用于版本清理评估
+type ArtifactVersionSummary struct {
+	ID        string
+	VersionNo int
+	BranchKey string
+	// ContentBytes 内容在库中的存储字节数（pg_column_size，压缩后）
+	ContentBytes int64
+	CreatedAt    time.Time
+}
+
+// ArtifactTagMaxRunes 版本标签最大长度
+const ArtifactTagMaxRunes = 64
+
+// ArtifactVersionTag 构件版本标签（命名发布，如 "beta-1 canon"）：同一构件内标签唯一，
+// 被标记的版本受保护，不得被版本清理删除（数据库外键约束兜底）
+type ArtifactVersionTag struct {
+	ID         string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID   string    `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	ArtifactID string    `json:"artifact_id" gorm:"type:uuid;index;not null"`
+	VersionID  string    `json:"version_id" gorm:"type:uuid;index;not null"`
+	Tag        string    `json:"tag" gorm:"type:varchar(64);not null"`
+	Note       string    `json:"note,omitempty" gorm:"type:text"`
+	CreatedBy  *string   `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// VersionNo / BranchKey 查询时联表带出的版本信息（只读）
+	VersionNo int    `json:"version_no" gorm:"->;-:migration"`
+	BranchKey string `json:"branch_key" gorm:"->;-:migration"`
+}
+
+func (ArtifactVersionTag) TableName() string {
+	return "artifact_version_tags"
+}
+
 func TaskToArtifactType(task ConversationTask) (ArtifactType, error) {
 	switch task {
 	case ConversationTaskNovelFoundation:
@@ -60,3 +107,101 @@ func TaskToArtifactType(task ConversationTask) (ArtifactType, error) {
 		return "", fmt.Errorf("invalid task: %s", task)
 	}
 }
+
+// ArtifactActivationPolicy 构件新版本的自动激活策略（项目设置）；请求显式指定 activate 时总是以请求为准
+type ArtifactActivationPolicy string
+
+const (
+	// ArtifactActivationManual 从不自动激活，需显式 activate=true、采用候选或回滚
+	ArtifactActivationManual ArtifactActivationPolicy = "manual"
+	// ArtifactActivationAutoMain 写入 main 分支或构件首个版本时自动激活（默认）
+	ArtifactActivationAutoMain ArtifactActivationPolicy = "auto_main"
+	// ArtifactActivationAutoOnCleanScan 在 auto_main 的基础上，还要求设定冲突检查已执行且未发现冲突
+	ArtifactActivationAutoOnCleanScan ArtifactActivationPolicy = "auto_on_clean_scan"
+)
+
+// IsValid 是否为已知策略
+func (p ArtifactActivationPolicy) IsValid() bool {
+	switch p {
+	case ArtifactActivationManual, ArtifactActivationAutoMain, ArtifactActivationAutoOnCleanScan:
+		return true
+	}
+	return false
+}
+
+// 激活决策原因（写入 ArtifactVersionMetadata.ActivationReason）
+const (
+	ActivationReasonRequested         = "requested"
+	ActivationReasonRequestedOff      = "requested_off"
+	ActivationReasonManualPolicy      = "manual_policy"
+	ActivationReasonMainBranch        = "main_branch"
+	ActivationReasonFirstVersion      = "first_version"
+	ActivationReasonNonMainBranch     = "non_main_branch"
+	ActivationReasonScanClean         = "scan_clean"
+	ActivationReasonScanConflicts     = "scan_conflicts"
+	ActivationReasonScanSkipped       = "scan_skipped"
+	ActivationReasonValidationBlocked = "validation_blocked"
+	// ActivationReasonCandidateApply 采用候选时沿用生成时决定的激活设置
+	ActivationReasonCandidateApply = "candidate_apply"
+)
+
+// ConflictScanOutcome 设定冲突检查结果（供 auto_on_clean_scan 判断）
+type ConflictScanOutcome int
+
+const (
+	// ConflictScanSkipped 未执行、执行失败或超时
+	ConflictScanSkipped ConflictScanOutcome = iota
+	ConflictScanClean
+	ConflictScanConflicts
+)
+
+// ArtifactActivationInput 激活决策输入
+type ArtifactActivationInput struct {
+	Policy    ArtifactActivationPolicy
+	BranchKey string
+	// Requested 请求显式指定的 activate（nil 表示按策略）
+	Requested *bool
+	// FirstVersion 构件尚无基线版本
+	FirstVersion bool
+	Scan         ConflictScanOutcome
+}
+
+// DecideArtifactActivation 按项目策略决定新版本是否激活，返回决策与原因。
+// 会话生成与异步构件生成共用，保证同一项目的激活行为一致
+func DecideArtifactActivation(in ArtifactActivationInput) (bool, string) {
+	if in.Requested != nil {
+		if *in.Requested {
+			return true, ActivationReasonRequested
+		}
+		return false, ActivationReasonRequestedOff
+	}
+	policy := in.Policy
+	if !policy.IsValid() {
+		policy = ArtifactActivationAutoMain
+	}
+	if policy == ArtifactActivationManual {
+		return false, ActivationReasonManualPolicy
+	}
+
+	reason := ActivationReasonMainBranch
+	switch {
+	case in.BranchKey == "" || in.BranchKey == "main":
+	case in.FirstVersion:
+		// 首次生成默认激活，避免构件长期无 active_version
+		reason = ActivationReasonFirstVersion
+	default:
+		return false, ActivationReasonNonMainBranch
+	}
+
+	if policy == ArtifactActivationAutoOnCleanScan {
+		switch in.Scan {
+		case ConflictScanClean:
+			return true, ActivationReasonScanClean
+		case ConflictScanConflicts:
+			return false, ActivationReasonScanConflicts
+		default:
+			return false, ActivationReasonScanSkipped
+		}
+	}
+	return true, reason
+}
@@ -2,6 +2,10 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -23,6 +27,27 @@ type GenerationMetadata struct {
 	CompletionTokens int     `json:"completion_tokens,omitempty"`
 	Temperature      float64 `json:"temperature,omitempty"`
 	GeneratedAt      string  `json:"generated_at,omitempty"`
+	// TitleSuggestions 待采用的标题建议（生成后自动提出或手动请求，采用后清空）
+	TitleSuggestions []string `json:"title_suggestions,omitempty"`
+}
+
+// ContextPinType 固定上下文类型
+type ContextPinType string
+
+const (
+	ContextPinChapter ContextPinType = "chapter" // 引用其他章节（注入摘要，缺省时截取正文开头）
+	ContextPinEntity  ContextPinType = "entity"  // 引用实体（注入名称、描述与当前状态）
+	ContextPinSegment ContextPinType = "segment" // 引用检索片段（注入固定时的片段文本）
+)
+
+// ContextPin 章节固定上下文：生成时无论相似度高低都会注入 Prompt，重生成沿用
+type ContextPin struct {
+	Type  ContextPinType `json:"type"`
+	RefID string         `json:"ref_id"`
+	// Text 片段文本（仅 segment 类型，固定时快照，避免索引重建后失效）
+	Text string `json:"text,omitempty"`
+	// Note 作者备注（如“呼应第三章的誓言”），随上下文一并注入
+	Note string `json:"note,omitempty"`
 }
 
 // Chapter 章节实体
@@ -30,8 +55,10 @@ type Chapter struct {
 	ID                 string              `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	ProjectID          string              `json:"project_id" gorm:"type:uuid;index;not null"`
 	AIKey              string              `json:"ai_key,omitempty" gorm:"column:ai_key;type:varchar(128);index"`
+	OutlineKey         string              `json:"outline_key,omitempty" gorm:"column:outline_key;type:varchar(128);index"` // 关联的大纲章节条目（outline 构件 volumes[].chapters[].key）
 	VolumeID           string              `json:"volume_id,omitempty" gorm:"type:uuid;index"`
 	SeqNum             int                 `json:"seq_num" gorm:"not null"`
+	DisplayNo          int                 `json:"display_no" gorm:"not null;default:0"` // 项目内按叙事顺序连续的展示序号（第 N 章），由章节编号服务维护
 	Title              string              `json:"title,omitempty" gorm:"type:varchar(255)"`
 	Outline            string              `json:"outline,omitempty" gorm:"type:text"`
 	ContentText        string              `json:"content_text,omitempty" gorm:"type:text"`
@@ -39,12 +66,24 @@ type Chapter struct {
 	Notes              string              `json:"notes,omitempty" gorm:"type:text"`
 	StoryTimeStart     int64               `json:"story_time_start,omitempty"`
 	StoryTimeEnd       int64               `json:"story_time_end,omitempty"`
+	IsFlashback        bool                `json:"is_flashback,omitempty" gorm:"default:false"`
+	POVEntityID        *string             `json:"pov_entity_id,omitempty" gorm:"column:pov_entity_id;type:uuid"`
 	WordCount          int                 `json:"word_count" gorm:"default:0"`
 	Status             ChapterStatus       `json:"status" gorm:"type:varchar(50);default:'draft'"`
 	GenerationMetadata *GenerationMetadata `json:"generation_metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	ContextPins        []ContextPin        `json:"context_pins,omitempty" gorm:"type:jsonb;serializer:json"`
 	Version            int                 `json:"version" gorm:"default:1"`
+	DraftDirty         bool                `json:"draft_dirty,omitempty" gorm:"default:false"`
+	OutlineStale       bool                `json:"outline_stale,omitempty" gorm:"default:false"` // 关联的大纲条目在激活新版本时发生实质变化，正文/摘要可能已偏离新大纲
+	OutlineStaleSince  *time.Time          `json:"outline_stale_since,omitempty"`
+	LastEditedBy       *string             `json:"last_edited_by,omitempty" gorm:"type:uuid"`
+	LastEditedAt       *time.Time          `json:"last_edited_at,omitempty"`
+	ContentHash        string              `json:"-" gorm:"type:varchar(64);not null;default:''"` // 正文 SHA-256（由仓储保存时计算）
 	CreatedAt          time.Time           `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt          time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// ContentChanged 由 ChapterRepository.Create / Update 设置：本次保存是否改变了正文（与已存储的 content_hash 比较；新建时为是否有正文）
+	ContentChanged bool `json:"-" gorm:"-"`
 }
 
 // TableName 指定表名
@@ -52,6 +91,23 @@ func (Chapter) TableName() string {
 	return "chapters"
 }
 
+// DisplayNumber 展示序号（第 N 章）；尚未编号时退回卷内序号
+func (c *Chapter) DisplayNumber() int {
+	if c.DisplayNo > 0 {
+		return c.DisplayNo
+	}
+	return c.SeqNum
+}
+
+// ChapterContentHash 正文的 SHA-256（十六进制）；空正文返回空串，与迁移回填一致
+func ChapterContentHash(content string) string {
+	if content == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 // NewChapter 创建新章节
 func NewChapter(projectID, volumeID string, seqNum int) *Chapter {
 	now := time.Now()
@@ -74,11 +130,112 @@ func (c *Chapter) SetContent(content string) {
 	c.UpdatedAt = time.Now()
 }
 
+// ReplaceContent 整体替换正文（重新生成、手动全文保存）：已有正文且内容变化时递增版本号，
+// 使旧正文留在上一版本的快照中。返回是否产生了新版本
+func (c *Chapter) ReplaceContent(content string) bool {
+	bump := c.ContentText != "" && c.ContentText != content
+	if bump {
+		c.Version++
+	}
+	c.SetContent(content)
+	return bump
+}
+
+// ClearOutlineStale 清除大纲过期标记（按新大纲重新生成或作者确认后调用）
+func (c *Chapter) ClearOutlineStale() {
+	c.OutlineStale = false
+	c.OutlineStaleSince = nil
+}
+
+// StoryTimeUpperBound 返回章节在故事时间轴上的上界（优先 end，缺省回退 start）
+func (c *Chapter) StoryTimeUpperBound() int64 {
+	if c.StoryTimeEnd > 0 {
+		return c.StoryTimeEnd
+	}
+	return c.StoryTimeStart
+}
+
+// NarrativeVolumeStride 叙事位置中“卷”的步长（单卷章节数上限）
+const NarrativeVolumeStride int64 = 100000
+
+// NarrativePosition 计算章节在叙事顺序（读者阅读顺序）上的位置：卷序号 * 步长 + 章节序号。
+// 未归属卷的章节按卷序号 0 处理。
+func NarrativePosition(volumeSeq, chapterSeq int) int64 {
+	return int64(volumeSeq)*NarrativeVolumeStride + int64(chapterSeq)
+}
+
+// POVEntity 返回章节 POV 角色实体 ID（未设置返回空串）
+func (c *Chapter) POVEntity() string {
+	if c == nil || c.POVEntityID == nil {
+		return ""
+	}
+	return *c.POVEntityID
+}
+
+// placeholderTitlePattern 占位标题：第N章 / 第十二章 / Chapter N / 新章节 / 未命名（允许前后空白与标点）
+var placeholderTitlePattern = regexp.MustCompile(`(?i)^\s*(第\s*[0-9零一二三四五六七八九十百千万]+\s*章|chapter\s*\d+|新章节|未命名(章节)?|untitled)\s*[:：.。]?\s*$`)
+
+// HasPlaceholderTitle 标题是否为空或仅为占位（如“第3章”），此类章节生成后会自动提出标题建议
+func (c *Chapter) HasPlaceholderTitle() bool {
+	return strings.TrimSpace(c.Title) == "" || placeholderTitlePattern.MatchString(c.Title)
+}
+
+// TitleSuggestions 返回待采用的标题建议
+func (c *Chapter) TitleSuggestions() []string {
+	if c == nil || c.GenerationMetadata == nil {
+		return nil
+	}
+	return c.GenerationMetadata.TitleSuggestions
+}
+
+// SetTitleSuggestions 写入标题建议（无生成元数据时新建）
+func (c *Chapter) SetTitleSuggestions(titles []string) {
+	if c.GenerationMetadata == nil {
+		c.GenerationMetadata = &GenerationMetadata{}
+	}
+	c.GenerationMetadata.TitleSuggestions = titles
+}
+
+// AcceptTitleSuggestion 采用一条标题建议并清空建议列表；标题不在建议中时返回 false
+func (c *Chapter) AcceptTitleSuggestion(title string) bool {
+	title = strings.TrimSpace(title)
+	for _, t := range c.TitleSuggestions() {
+		if t == title {
+			c.Title = title
+			c.GenerationMetadata.TitleSuggestions = nil
+			return true
+		}
+	}
+	return false
+}
+
 // IsEditable 检查章节是否可编辑
 func (c *Chapter) IsEditable() bool {
 	return c.Status == ChapterStatusDraft || c.Status == ChapterStatusReview
 }
 
+// ApplyAutosave 应用一次自动保存：同一编辑者在 debounce 窗口内的连续保存合并到当前版本，
+// 窗口外或编辑者变化时递增版本号（editorID 为空视为匿名编辑者，同样参与合并）。返回是否产生了新版本。
+func (c *Chapter) ApplyAutosave(content, editorID string, now time.Time, debounce time.Duration) bool {
+	lastEditor := ""
+	if c.LastEditedBy != nil {
+		lastEditor = *c.LastEditedBy
+	}
+	bump := c.LastEditedAt == nil || lastEditor != editorID || now.Sub(*c.LastEditedAt) >= debounce
+
+	c.SetContent(content)
+	c.DraftDirty = true
+	c.LastEditedBy = nil
+	if editorID != "" {
+		c.LastEditedBy = &editorID
+	}
+	c.LastEditedAt = &now
+	if bump {
+		c.Version++
+	}
+	return bump
+}
+
 // IncrementVersion 增加版本号
 func (c *Chapter) IncrementVersion() {
 	c.Version++
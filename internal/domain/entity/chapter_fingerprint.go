@@ -0,0 +1,91 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"time"
+	"unicode"
+)
+
+const (
+	// fingerprintShingleRunes SimHash 的字符 n-gram 长度（中文按字切分，4 字足以区分常见搭配）
+	fingerprintShingleRunes = 4
+	// MinFingerprintShingles 参与重复检测的最少 n-gram 数：过短的章节（占位、提纲）相似度没有意义
+	MinFingerprintShingles = 200
+)
+
+// ChapterFingerprint 章节内容指纹（章节正文保存时重新计算，用于发现近乎重复的章节）
+type ChapterFingerprint struct {
+	ChapterID    string    `json:"chapter_id" gorm:"type:uuid;primaryKey"`
+	ProjectID    string    `json:"project_id" gorm:"type:uuid;index;not null"`
+	SimHash      int64     `json:"sim_hash" gorm:"not null;default:0"`
+	ShingleCount int       `json:"shingle_count" gorm:"not null;default:0"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ChapterFingerprint) TableName() string {
+	return "chapter_fingerprints"
+}
+
+// NewChapterFingerprint 计算章节正文的 SimHash 指纹
+func NewChapterFingerprint(chapter *Chapter) *ChapterFingerprint {
+	hash, shingles := ContentSimHash(chapter.ContentText)
+	return &ChapterFingerprint{
+		ChapterID:    chapter.ID,
+		ProjectID:    chapter.ProjectID,
+		SimHash:      int64(hash),
+		ShingleCount: shingles,
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// Comparable 正文是否足够长、可参与重复检测
+func (f *ChapterFingerprint) Comparable() bool {
+	return f != nil && f.ShingleCount >= MinFingerprintShingles
+}
+
+// Similarity 两个指纹的相似度（1 - 汉明距离/64），取值 [0, 1]
+func (f *ChapterFingerprint) Similarity(other *ChapterFingerprint) float64 {
+	distance := bits.OnesCount64(uint64(f.SimHash) ^ uint64(other.SimHash))
+	return 1 - float64(distance)/64
+}
+
+// ContentSimHash 计算正文的 64 位 SimHash：忽略空白与标点，按字符 n-gram 取 FNV 哈希逐位投票。
+// 返回哈希与参与计算的 n-gram 数；正文短于一个 n-gram 时返回 0, 0。
+func ContentSimHash(content string) (uint64, int) {
+	runes := make([]rune, 0, len(content))
+	for _, r := range content {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, unicode.ToLower(r))
+		}
+	}
+	if len(runes) < fingerprintShingleRunes {
+		return 0, 0
+	}
+
+	var weights [64]int
+	h := fnv.New64a()
+	shingles := len(runes) - fingerprintShingleRunes + 1
+	for i := 0; i < shingles; i++ {
+		h.Reset()
+		_, _ = h.Write([]byte(string(runes[i : i+fingerprintShingleRunes])))
+		sum := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var hash uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			hash |= 1 << b
+		}
+	}
+	return hash, shingles
+}
@@ -0,0 +1,126 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ChapterStats 章节文本统计（章节正文保存时重新计算，供节奏分析使用）
+type ChapterStats struct {
+	ChapterID      string    `json:"chapter_id" gorm:"type:uuid;primaryKey"`
+	ProjectID      string    `json:"project_id" gorm:"type:uuid;index;not null"`
+	WordCount      int       `json:"word_count" gorm:"not null;default:0"`
+	DialogueChars  int       `json:"dialogue_chars" gorm:"not null;default:0"`
+	NarrationChars int       `json:"narration_chars" gorm:"not null;default:0"`
+	DialogueRatio  float64   `json:"dialogue_ratio" gorm:"not null;default:0"`
+	ParagraphCount int       `json:"paragraph_count" gorm:"not null;default:0"`
+	SceneCount     int       `json:"scene_count" gorm:"not null;default:0"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ChapterStats) TableName() string {
+	return "chapter_stats"
+}
+
+// NewChapterStats 分析章节正文生成统计
+func NewChapterStats(chapter *Chapter) *ChapterStats {
+	t := AnalyzeChapterText(chapter.ContentText)
+	return &ChapterStats{
+		ChapterID:      chapter.ID,
+		ProjectID:      chapter.ProjectID,
+		WordCount:      len([]rune(chapter.ContentText)),
+		DialogueChars:  t.DialogueChars,
+		NarrationChars: t.NarrationChars,
+		DialogueRatio:  t.DialogueRatio(),
+		ParagraphCount: t.Paragraphs,
+		SceneCount:     t.Scenes,
+		UpdatedAt:      time.Now(),
+	}
+}
+
+// AvgParagraphChars 平均段落字数（不含空白）
+func (s *ChapterStats) AvgParagraphChars() float64 {
+	if s == nil || s.ParagraphCount == 0 {
+		return 0
+	}
+	return float64(s.DialogueChars+s.NarrationChars) / float64(s.ParagraphCount)
+}
+
+// ChapterTextStats 章节正文分析结果
+type ChapterTextStats struct {
+	// DialogueChars 引号内（“”「」『』""）的非空白字符数
+	DialogueChars int
+	// NarrationChars 引号外的非空白字符数（不含引号与场景分隔行）
+	NarrationChars int
+	// Paragraphs 非空段落数（不含场景分隔行）
+	Paragraphs int
+	// Scenes 场景数：有正文时为场景分隔行（***、---、§ 等）数 + 1
+	Scenes int
+}
+
+// DialogueRatio 对白占正文（对白 + 叙述）的比例
+func (s ChapterTextStats) DialogueRatio() float64 {
+	total := s.DialogueChars + s.NarrationChars
+	if total == 0 {
+		return 0
+	}
+	return float64(s.DialogueChars) / float64(total)
+}
+
+// dialogueQuotes 对白引号：开引号 -> 闭引号
+var dialogueQuotes = map[rune]rune{'“': '”', '「': '」', '『': '』', '"': '"'}
+
+// AnalyzeChapterText 统计对白/叙述字数、段落数与场景数。
+// 引号未闭合时在段落末尾结束对白（中文小说常见的跨段对白每段重新开引号）。
+func AnalyzeChapterText(content string) ChapterTextStats {
+	var s ChapterTextStats
+	breaks := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if isSceneBreak(line) {
+			breaks++
+			continue
+		}
+		s.Paragraphs++
+
+		var closing rune
+		for _, r := range line {
+			switch {
+			case closing != 0 && r == closing:
+				closing = 0
+			case closing == 0 && dialogueQuotes[r] != 0:
+				closing = dialogueQuotes[r]
+			case unicode.IsSpace(r):
+			case closing != 0:
+				s.DialogueChars++
+			default:
+				s.NarrationChars++
+			}
+		}
+	}
+	if s.Paragraphs > 0 {
+		s.Scenes = breaks + 1
+	}
+	return s
+}
+
+// isSceneBreak 是否为场景分隔行：仅由分隔符号组成（如 ***、* * *、---、———、§、◇◇◇）
+func isSceneBreak(line string) bool {
+	n := 0
+	for _, r := range line {
+		switch {
+		case unicode.IsSpace(r):
+		case strings.ContainsRune("*＊-—=~～#§◆◇○●·•", r):
+			n++
+		default:
+			return false
+		}
+	}
+	return n > 0
+}
@@ -0,0 +1,43 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+// ChapterVersion 章节正文版本快照：章节保存时按当前版本号写入，同一版本号内的多次保存
+// （自动保存 debounce 窗口内的连续编辑）覆盖同一快照，供版本对比使用
+type ChapterVersion struct {
+	ChapterID   string    `json:"chapter_id" gorm:"type:uuid;primaryKey"`
+	Version     int       `json:"version" gorm:"primaryKey"`
+	ProjectID   string    `json:"project_id" gorm:"type:uuid;index;not null"`
+	ContentText string    `json:"content_text" gorm:"type:text;not null"`
+	WordCount   int       `json:"word_count" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (ChapterVersion) TableName() string {
+	return "chapter_versions"
+}
+
+// NewChapterVersion 以章节当前正文生成当前版本号的快照
+func NewChapterVersion(chapter *Chapter) *ChapterVersion {
+	now := time.Now()
+	return &ChapterVersion{
+		ChapterID:   chapter.ID,
+		Version:     chapter.Version,
+		ProjectID:   chapter.ProjectID,
+		ContentText: chapter.ContentText,
+		WordCount:   len([]rune(chapter.ContentText)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Key 快照主键（chapter_id + version），用于内存仓储
+func (v *ChapterVersion) Key() string {
+	return v.ChapterID + "#" + strconv.Itoa(v.Version)
+}
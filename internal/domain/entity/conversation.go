@@ -20,8 +20,25 @@ type ConversationSession struct {
 	TenantID    string           `json:"tenant_id" gorm:"type:uuid;index;not null"`
 	ProjectID   string           `json:"project_id" gorm:"type:uuid;index;not null"`
 	CurrentTask ConversationTask `json:"current_task" gorm:"type:varchar(32);not null;default:'novel_foundation'"`
-	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// GenerationDefaults 会话级默认生成参数（发送消息时未显式指定的参数以此补全）
+	GenerationDefaults *SessionGenerationDefaults `json:"generation_defaults,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// SessionGenerationDefaults 会话级默认生成参数
+type SessionGenerationDefaults struct {
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// IsEmpty 是否未设置任何默认参数
+func (d *SessionGenerationDefaults) IsEmpty() bool {
+	return d == nil || (d.Provider == "" && d.Model == "" && d.Temperature == nil && d.MaxTokens == nil)
 }
 
 func (ConversationSession) TableName() string {
@@ -49,13 +66,37 @@ type ConversationTurn struct {
 	Task      ConversationTask `json:"task" gorm:"type:varchar(32);not null"`
 	Content   string           `json:"content" gorm:"type:text;not null"`
 	Metadata  json.RawMessage  `json:"metadata,omitempty" gorm:"type:jsonb"`
-	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime"`
+
+	// 用量归因（仅 assistant 轮次记录；成本按提供商配置单价折算，未配置单价时为 0）
+	PromptTokens     int     `json:"prompt_tokens,omitempty" gorm:"not null;default:0"`
+	CompletionTokens int     `json:"completion_tokens,omitempty" gorm:"not null;default:0"`
+	Cost             float64 `json:"cost,omitempty" gorm:"type:numeric(18,6);not null;default:0"`
+	CostCurrency     string  `json:"cost_currency,omitempty" gorm:"type:varchar(8)"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 func (ConversationTurn) TableName() string {
 	return "conversation_turns"
 }
 
+// SetUsage 记录本轮 LLM 用量与成本
+func (t *ConversationTurn) SetUsage(promptTokens, completionTokens int, cost float64, currency string) {
+	t.PromptTokens = promptTokens
+	t.CompletionTokens = completionTokens
+	t.Cost = cost
+	t.CostCurrency = currency
+}
+
+// ConversationUsage 会话用量汇总
+type ConversationUsage struct {
+	Turns            int64
+	PromptTokens     int64
+	CompletionTokens int64
+	// Costs 按币种汇总的成本（未配置单价的轮次不计入）
+	Costs map[string]float64
+}
+
 func NewConversationTurn(sessionID string, role Role, task ConversationTask, content string, metadata json.RawMessage) *ConversationTurn {
 	return &ConversationTurn{
 		SessionID: sessionID,
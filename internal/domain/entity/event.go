@@ -2,6 +2,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
@@ -40,7 +41,11 @@ type Event struct {
 	Importance       EventImportance `json:"importance" gorm:"type:varchar(50);default:'normal'"`
 	Tags             StringSlice     `json:"tags,omitempty" gorm:"type:jsonb"`
 	VectorID         string          `json:"vector_id,omitempty" gorm:"type:varchar(255)"`
-	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	// SupersededAt 被替代时间（章节重新抽取事件后旧事件保留用于审计，不再参与查询）
+	SupersededAt *time.Time `json:"superseded_at,omitempty"`
+	// SupersededBy 替代该事件的新事件（重新抽取出同一事件时记录）
+	SupersededBy *string   `json:"superseded_by,omitempty" gorm:"type:uuid"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
 // TableName 指定表名
@@ -86,3 +91,13 @@ func (e *Event) SetTimeRange(start, end int64) {
 	e.StoryTimeStart = start
 	e.StoryTimeEnd = end
 }
+
+// IsSuperseded 是否已被替代
+func (e *Event) IsSuperseded() bool {
+	return e.SupersededAt != nil
+}
+
+// DedupKey 事件去重键：按摘要归一化（忽略大小写与空白差异）
+func (e *Event) DedupKey() string {
+	return strings.ToLower(strings.Join(strings.Fields(e.Summary), " "))
+}
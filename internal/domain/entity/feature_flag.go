@@ -0,0 +1,75 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// 已知功能开关（用于灰度发布高风险能力）
+const (
+	FeatureFlagArtifactJSONPatch    = "artifact_json_patch"    // 构件生成优先输出 JSON Patch 增量
+	FeatureFlagArtifactConflictScan = "artifact_conflict_scan" // 构件生成后的跨构件冲突扫描
+	FeatureFlagChapterPipelineV2    = "chapter_pipeline_v2"    // 新版章节生成流水线（预留）
+)
+
+// FeatureFlag 全局功能开关定义（平台级，不受租户 RLS 约束）
+type FeatureFlag struct {
+	Key         string `json:"key" gorm:"type:varchar(100);primaryKey"`
+	Description string `json:"description,omitempty" gorm:"type:text"`
+	// Enabled 全局总开关；关闭时仅显式覆盖为开启的租户/项目可用
+	Enabled bool `json:"enabled" gorm:"not null;default:false"`
+	// RolloutPercent 全局开启时按租户哈希分桶的灰度比例（0~100）
+	RolloutPercent int       `json:"rollout_percent" gorm:"not null;default:0"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// InRollout 判断租户是否落在全局灰度范围内（同一租户在同一开关上的分桶结果稳定）
+func (f *FeatureFlag) InRollout(tenantID string) bool {
+	if f == nil || !f.Enabled {
+		return false
+	}
+	switch {
+	case f.RolloutPercent <= 0:
+		return false
+	case f.RolloutPercent >= 100:
+		return true
+	}
+	return RolloutBucket(f.Key, tenantID) < f.RolloutPercent
+}
+
+// RolloutBucket 计算租户在指定开关上的灰度分桶（0~99）
+func RolloutBucket(key, tenantID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(tenantID))
+	return int(h.Sum32() % 100)
+}
+
+// FeatureFlagOverride 租户/项目级开关覆盖（ProjectID 为空表示租户级覆盖）
+type FeatureFlagOverride struct {
+	ID        string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID  string    `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	ProjectID *string   `json:"project_id,omitempty" gorm:"type:uuid"`
+	FlagKey   string    `json:"flag_key" gorm:"type:varchar(100);not null"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (FeatureFlagOverride) TableName() string {
+	return "feature_flag_overrides"
+}
+
+// IsProjectScoped 是否为项目级覆盖
+func (o *FeatureFlagOverride) IsProjectScoped() bool {
+	return o != nil && o.ProjectID != nil && *o.ProjectID != ""
+}
@@ -0,0 +1,89 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CandidateTargetType 候选结果对应的生成目标
+type CandidateTargetType string
+
+const (
+	CandidateTargetChapter  CandidateTargetType = "chapter"
+	CandidateTargetArtifact CandidateTargetType = "artifact"
+)
+
+// CandidateStatus 候选结果状态
+type CandidateStatus string
+
+const (
+	// CandidateStatusPending 等待用户选择（selection=manual）
+	CandidateStatusPending CandidateStatus = "pending"
+	// CandidateStatusSelected 已采用（写入章节正文 / 构件版本）
+	CandidateStatusSelected CandidateStatus = "selected"
+	// CandidateStatusDiscarded 未采用，保留供参考（可再次选择）
+	CandidateStatusDiscarded CandidateStatus = "discarded"
+)
+
+// CandidateSelection 多候选的选择方式
+type CandidateSelection string
+
+const (
+	// CandidateSelectionAuto 按质量评分自动采用最高分候选
+	CandidateSelectionAuto CandidateSelection = "auto"
+	// CandidateSelectionManual 全部候选待选，由用户通过选择接口采用
+	CandidateSelectionManual CandidateSelection = "manual"
+)
+
+// IsValid 检查选择方式是否合法
+func (s CandidateSelection) IsValid() bool {
+	return s == CandidateSelectionAuto || s == CandidateSelectionManual
+}
+
+// CandidateApplyOptions 构件候选被采用时写入版本所需的分支信息（生成时确定，选择时沿用）
+type CandidateApplyOptions struct {
+	BranchKey       string  `json:"branch_key,omitempty"`
+	ParentVersionID *string `json:"parent_version_id,omitempty"`
+	Activate        bool    `json:"activate,omitempty"`
+}
+
+// GenerationCandidate 多候选生成（best-of-N）中的单个候选结果
+type GenerationCandidate struct {
+	ID          string              `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID    string              `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	JobID       string              `json:"job_id" gorm:"type:uuid;index;not null"`
+	ProjectID   string              `json:"project_id" gorm:"type:uuid;not null"`
+	TargetType  CandidateTargetType `json:"target_type" gorm:"type:varchar(32);not null"`
+	TargetID    string              `json:"target_id" gorm:"type:uuid;not null"` // 章节 ID 或构件 ID
+	CandidateNo int                 `json:"candidate_no" gorm:"not null"`
+	Status      CandidateStatus     `json:"status" gorm:"type:varchar(32);not null;default:'pending'"`
+	// Score 质量评分（0-100，越高越好）
+	Score float64 `json:"score"`
+	// Content 章节正文，或构件 JSON 文本
+	Content string `json:"content" gorm:"type:text;not null"`
+	// Raw 构件生成的模型原始回复（作为会话助手消息）
+	Raw              string                 `json:"raw,omitempty" gorm:"type:text"`
+	Apply            *CandidateApplyOptions `json:"apply,omitempty" gorm:"type:jsonb;serializer:json"`
+	Provider         string                 `json:"provider,omitempty" gorm:"type:varchar(100)"`
+	Model            string                 `json:"model,omitempty" gorm:"type:varchar(100)"`
+	PromptTokens     int                    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                    `json:"completion_tokens,omitempty"`
+	Temperature      float64                `json:"temperature,omitempty"`
+	// Partial 流式生成被中断时保存的部分正文（可续写、原样采用或丢弃）
+	Partial bool `json:"partial,omitempty" gorm:"not null;default:false"`
+	// VersionID 构件候选被采用时生成的版本 ID
+	VersionID  *string    `json:"version_id,omitempty" gorm:"type:uuid"`
+	SelectedAt *time.Time `json:"selected_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (GenerationCandidate) TableName() string {
+	return "generation_candidates"
+}
+
+// ArtifactContent 返回构件候选的 JSON 内容
+func (c *GenerationCandidate) ArtifactContent() json.RawMessage {
+	return json.RawMessage(c.Content)
+}
@@ -10,15 +10,90 @@ import (
 type JobType string
 
 const (
-	JobTypeChapterGen    JobType = "chapter_gen"
-	JobTypeFoundationGen JobType = "foundation_gen"
-	JobTypeArtifactGen   JobType = "artifact_gen"
-	JobTypeSummary       JobType = "summary"
-	JobTypeEntityExtract JobType = "entity_extract"
-	JobTypeEmbeddingGen  JobType = "embedding_gen"
-	JobTypeIndexRebuild  JobType = "index_rebuild"
+	JobTypeChapterGen     JobType = "chapter_gen"
+	JobTypeFoundationGen  JobType = "foundation_gen"
+	JobTypeArtifactGen    JobType = "artifact_gen"
+	JobTypeSummary        JobType = "summary"
+	JobTypeEntityExtract  JobType = "entity_extract"
+	JobTypeEmbeddingGen   JobType = "embedding_gen"
+	JobTypeIndexRebuild   JobType = "index_rebuild"
+	JobTypeProjectExport  JobType = "project_export"
+	JobTypeVectorPurge    JobType = "vector_purge"
+	JobTypeNotesIngest    JobType = "notes_ingest"
+	JobTypeArtifactGC     JobType = "artifact_gc"
+	JobTypeOutlineRefresh JobType = "outline_refresh"
+	JobTypeProjectRecount JobType = "project_recount"
 )
 
+// JobCategory 任务类别：生成类任务消耗 LLM，运维类任务（导出、重建索引、清理等）仅操作已有数据
+type JobCategory string
+
+const (
+	JobCategoryGeneration  JobCategory = "generation"
+	JobCategoryMaintenance JobCategory = "maintenance"
+)
+
+// CategoryOf 返回任务类型所属类别；未登记的类型一律视为运维任务，便于新增类型而无需迁移
+func CategoryOf(jobType JobType) JobCategory {
+	switch jobType {
+	case JobTypeChapterGen, JobTypeFoundationGen, JobTypeArtifactGen, JobTypeSummary, JobTypeEntityExtract, JobTypeNotesIngest:
+		return JobCategoryGeneration
+	default:
+		return JobCategoryMaintenance
+	}
+}
+
+// IsValid 检查类别是否合法
+func (c JobCategory) IsValid() bool {
+	return c == JobCategoryGeneration || c == JobCategoryMaintenance
+}
+
+// 任务优先级（1-10，越大越先执行）。Worker 按 high / normal / low 三个优先级流依次消费，
+// 不低于 JobPriorityHigh 的任务进入 high 流，不高于 JobPriorityLow 的进入 low 流
+const (
+	JobPriorityMin    = 1
+	JobPriorityLow    = 2
+	JobPriorityNormal = 5
+	JobPriorityHigh   = 8
+	JobPriorityMax    = 10
+)
+
+// JobTrigger 任务触发方式（决定默认优先级）
+type JobTrigger string
+
+const (
+	// JobTriggerInteractive 用户在界面上等待结果（如重新生成章节）
+	JobTriggerInteractive JobTrigger = "interactive"
+	// JobTriggerStandard 普通异步提交
+	JobTriggerStandard JobTrigger = "standard"
+	// JobTriggerBulk 批量起草等无人等待的后台生成
+	JobTriggerBulk JobTrigger = "bulk"
+)
+
+// DefaultPriority 任务默认优先级：交互式请求为 high，批量起草与运维任务为 low，其余为 normal
+func DefaultPriority(jobType JobType, trigger JobTrigger) int {
+	switch {
+	case trigger == JobTriggerInteractive:
+		return JobPriorityHigh
+	case trigger == JobTriggerBulk, CategoryOf(jobType) == JobCategoryMaintenance:
+		return JobPriorityLow
+	default:
+		return JobPriorityNormal
+	}
+}
+
+// PriorityClass 优先级档位（high / normal / low），与 Worker 消费的优先级流一一对应
+func PriorityClass(priority int) string {
+	switch {
+	case priority >= JobPriorityHigh:
+		return "high"
+	case priority <= JobPriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
 // JobStatus 任务状态
 type JobStatus string
 
@@ -28,32 +103,90 @@ const (
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
 	JobStatusCancelled JobStatus = "cancelled"
+	// JobStatusCancelledPartial 流式生成被客户端中断，已生成的部分内容保存为 partial 候选，待作者续写或丢弃
+	JobStatusCancelledPartial JobStatus = "cancelled_partial"
 )
 
+// JobErrorCode 任务失败类型（供客户端区分是否值得重试、如何提示）
+type JobErrorCode string
+
+const (
+	// JobErrorFailed 未细分的失败
+	JobErrorFailed JobErrorCode = "failed"
+	// JobErrorTimeout 生成超过配置的时限（story.generation_timeouts）
+	JobErrorTimeout JobErrorCode = "timeout"
+	// JobErrorQuotaExceeded Token 余额不足
+	JobErrorQuotaExceeded JobErrorCode = "quota_exceeded"
+	// JobErrorCostCeiling 累计 Token 消耗达到单任务上限（story.job_cost_ceiling_tokens），失败后不再重试
+	JobErrorCostCeiling JobErrorCode = "cost_ceiling_exceeded"
+	// JobErrorInvalidPayload 任务消息载荷不符合约定（生产者与 Worker 版本不一致），失败后不再重试
+	JobErrorInvalidPayload JobErrorCode = "invalid_payload"
+)
+
+// JobWarningCode 任务警告类型
+type JobWarningCode string
+
+const (
+	JobWarningIndexFailed          JobWarningCode = "index_failed"
+	JobWarningConflictScanSkipped  JobWarningCode = "conflict_scan_skipped"
+	JobWarningAttachmentTruncated  JobWarningCode = "attachment_truncated"
+	JobWarningRetrievalUnavailable JobWarningCode = "retrieval_unavailable"
+	JobWarningSpoilerGuardSkipped  JobWarningCode = "spoiler_guard_skipped"
+	JobWarningCandidateFailed      JobWarningCode = "candidate_failed"
+	JobWarningDuplicateContent     JobWarningCode = "duplicate_content"
+	JobWarningActivationBlocked    JobWarningCode = "activation_blocked"
+	JobWarningChapterEdited        JobWarningCode = "chapter_edited"
+)
+
+// MaxJobWarnings 单个任务保留的警告上限（超出后丢弃新警告，避免异常循环撑大记录）
+const MaxJobWarnings = 20
+
+// JobWarning 任务执行过程中的非致命问题（任务仍可成功），随任务详情返回给用户
+type JobWarning struct {
+	Code      JobWarningCode `json:"code"`
+	Message   string         `json:"message"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// JobWarnings 任务警告列表
+type JobWarnings []JobWarning
+
+// NewJobWarning 创建任务警告
+func NewJobWarning(code JobWarningCode, message string) JobWarning {
+	return JobWarning{Code: code, Message: message, CreatedAt: time.Now()}
+}
+
 // GenerationJob 生成任务
 type GenerationJob struct {
-	ID             string          `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	TenantID       string          `json:"tenant_id" gorm:"type:uuid;index;not null"`
-	ProjectID      string          `json:"project_id" gorm:"type:uuid;index;not null"`
-	ChapterID      *string         `json:"chapter_id,omitempty" gorm:"type:uuid;index"`
-	JobType        JobType         `json:"job_type" gorm:"type:varchar(50);not null;"`
-	Status         JobStatus       `json:"status" gorm:"type:varchar(50);default:'pending';index"`
-	Priority       int             `json:"priority" gorm:"default:5"`
-	InputParams    json.RawMessage `json:"input_params" gorm:"type:jsonb"`
-	OutputResult   json.RawMessage `json:"output_result,omitempty" gorm:"type:jsonb"`
-	ErrorMessage   string          `json:"error_message,omitempty" gorm:"type:text"`
-	LLMProvider    string          `json:"llm_provider,omitempty" gorm:"type:varchar(100)"`
-	LLMModel       string          `json:"llm_model,omitempty" gorm:"type:varchar(100)"`
-	TokensPrompt   int             `json:"tokens_prompt,omitempty"`
-	TokensComplete int             `json:"tokens_completion,omitempty" gorm:"column:tokens_completion"`
-	DurationMs     int             `json:"duration_ms,omitempty"`
-	RetryCount     int             `json:"retry_count" gorm:"default:0"`
-	Progress       int             `json:"progress" gorm:"default:0"`
-	IdempotencyKey *string         `json:"idempotency_key,omitempty" gorm:"type:varchar(255);uniqueIndex"`
-	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
-	StartedAt      *time.Time      `json:"started_at,omitempty"`
-	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	ID             string            `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID       string            `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	ProjectID      string            `json:"project_id" gorm:"type:uuid;index;not null"`
+	ChapterID      *string           `json:"chapter_id,omitempty" gorm:"type:uuid;index"`
+	JobType        JobType           `json:"job_type" gorm:"type:varchar(50);not null;"`
+	Category       JobCategory       `json:"category" gorm:"type:varchar(32);not null;default:'generation';index"`
+	Status         JobStatus         `json:"status" gorm:"type:varchar(50);default:'pending';index"`
+	Priority       int               `json:"priority" gorm:"default:5"`
+	InputParams    json.RawMessage   `json:"input_params" gorm:"type:jsonb"`
+	OutputResult   json.RawMessage   `json:"output_result,omitempty" gorm:"type:jsonb"`
+	InputSnapshot  json.RawMessage   `json:"-" gorm:"type:jsonb"` // 执行时送入模型的输入快照（供回放调试，不随任务详情返回）
+	Warnings       JobWarnings       `json:"warnings,omitempty" gorm:"type:jsonb;serializer:json"`
+	Truncation     *TruncationReport `json:"truncation,omitempty" gorm:"type:jsonb;serializer:json"` // 组装 Prompt 时的截断报告
+	ErrorMessage   string            `json:"error_message,omitempty" gorm:"type:text"`
+	ErrorCode      JobErrorCode      `json:"error_code,omitempty" gorm:"type:varchar(32)"`
+	LLMProvider    string            `json:"llm_provider,omitempty" gorm:"type:varchar(100)"`
+	LLMModel       string            `json:"llm_model,omitempty" gorm:"type:varchar(100)"`
+	TokensPrompt   int               `json:"tokens_prompt,omitempty"`
+	TokensComplete int               `json:"tokens_completion,omitempty" gorm:"column:tokens_completion"`
+	DurationMs     int               `json:"duration_ms,omitempty"`
+	RetryCount     int               `json:"retry_count" gorm:"default:0"`
+	Attempts       int               `json:"attempts" gorm:"not null;default:0"`     // 累计执行尝试次数（含失败尝试）
+	SpentTokens    int64             `json:"spent_tokens" gorm:"not null;default:0"` // 累计 Token 消耗（含失败尝试、修复轮次与提供商切换）
+	Progress       int               `json:"progress" gorm:"default:0"`
+	IdempotencyKey *string           `json:"idempotency_key,omitempty" gorm:"type:varchar(255);uniqueIndex"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+	StartedAt      *time.Time        `json:"started_at,omitempty"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
 }
 
 // TableName 指定表名
@@ -67,8 +200,9 @@ func NewGenerationJob(tenantID, projectID string, jobType JobType, inputParams j
 		TenantID:    tenantID,
 		ProjectID:   projectID,
 		JobType:     jobType,
+		Category:    CategoryOf(jobType),
 		Status:      JobStatusPending,
-		Priority:    5,
+		Priority:    DefaultPriority(jobType, JobTriggerStandard),
 		InputParams: inputParams,
 		RetryCount:  0,
 		CreatedAt:   time.Now(),
@@ -90,8 +224,11 @@ func (j *GenerationJob) Start() {
 	j.CompletedAt = nil
 	j.DurationMs = 0
 	j.ErrorMessage = ""
+	j.ErrorCode = ""
 	if isRetry {
 		j.OutputResult = nil
+		j.Warnings = nil
+		j.Truncation = nil
 	}
 	j.Progress = 0
 }
@@ -105,6 +242,7 @@ func (j *GenerationJob) Complete(result json.RawMessage) {
 	j.Status = JobStatusCompleted
 	j.OutputResult = result
 	j.ErrorMessage = ""
+	j.ErrorCode = ""
 	j.CompletedAt = &now
 	if j.StartedAt != nil {
 		j.DurationMs = int(now.Sub(*j.StartedAt).Milliseconds())
@@ -112,14 +250,48 @@ func (j *GenerationJob) Complete(result json.RawMessage) {
 	j.UpdateProgress(100)
 }
 
+// CancelPartial 生成中途被中断、已保存部分内容时结束任务（result 记录部分内容的位置）
+func (j *GenerationJob) CancelPartial(result json.RawMessage) {
+	if j == nil {
+		return
+	}
+	now := time.Now()
+	j.Status = JobStatusCancelledPartial
+	j.OutputResult = result
+	j.ErrorMessage = ""
+	j.ErrorCode = ""
+	j.CompletedAt = &now
+	if j.StartedAt != nil {
+		j.DurationMs = int(now.Sub(*j.StartedAt).Milliseconds())
+	}
+}
+
+// IsFinished 任务是否已结束（完成、失败、取消或部分取消）
+func (j *GenerationJob) IsFinished() bool {
+	switch j.Status {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled, JobStatusCancelledPartial:
+		return true
+	}
+	return false
+}
+
 // Fail 任务失败
 func (j *GenerationJob) Fail(errMsg string) {
+	j.FailWithCode(JobErrorFailed, errMsg)
+}
+
+// FailWithCode 任务失败并记录失败类型
+func (j *GenerationJob) FailWithCode(code JobErrorCode, errMsg string) {
 	if j == nil {
 		return
 	}
+	if code == "" {
+		code = JobErrorFailed
+	}
 	now := time.Now()
 	j.Status = JobStatusFailed
 	j.ErrorMessage = errMsg
+	j.ErrorCode = code
 	j.OutputResult = nil
 	j.CompletedAt = &now
 	if j.StartedAt != nil {
@@ -138,11 +310,42 @@ func (j *GenerationJob) Retry() {
 	j.StartedAt = nil
 	j.CompletedAt = nil
 	j.ErrorMessage = ""
+	j.ErrorCode = ""
 	j.OutputResult = nil
+	j.Warnings = nil
+	j.Truncation = nil
 	j.DurationMs = 0
 	j.Progress = 0
 }
 
+// AddWarnings 记录任务警告（同类型同内容去重，超出上限时忽略）
+func (j *GenerationJob) AddWarnings(warnings ...JobWarning) {
+	if j == nil {
+		return
+	}
+	for _, w := range warnings {
+		if len(j.Warnings) >= MaxJobWarnings {
+			return
+		}
+		if j.hasWarning(w) {
+			continue
+		}
+		if w.CreatedAt.IsZero() {
+			w.CreatedAt = time.Now()
+		}
+		j.Warnings = append(j.Warnings, w)
+	}
+}
+
+func (j *GenerationJob) hasWarning(w JobWarning) bool {
+	for _, existing := range j.Warnings {
+		if existing.Code == w.Code && existing.Message == w.Message {
+			return true
+		}
+	}
+	return false
+}
+
 // CanRetry 检查是否可以重试
 func (j *GenerationJob) CanRetry(maxRetries int) bool {
 	return j.RetryCount < maxRetries && j.Status == JobStatusFailed
@@ -156,6 +359,17 @@ func (j *GenerationJob) SetLLMMetrics(provider, model string, promptTokens, comp
 	j.TokensComplete = completionTokens
 }
 
+// RecordAttempt 累计一次执行尝试及其 Token 消耗（重试不清零）
+func (j *GenerationJob) RecordAttempt(tokens int64) {
+	if j == nil {
+		return
+	}
+	j.Attempts++
+	if tokens > 0 {
+		j.SpentTokens += tokens
+	}
+}
+
 // UpdateProgress 更新任务进度
 func (j *GenerationJob) UpdateProgress(progress int) {
 	if progress < 0 {
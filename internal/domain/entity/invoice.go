@@ -0,0 +1,33 @@
+// Package entity 定义领域实体
+package entity
+
+import "time"
+
+// InvoiceStatus 账单状态
+type InvoiceStatus string
+
+const (
+	InvoiceStatusPaid InvoiceStatus = "paid" // 支付成功，Token 已入账
+)
+
+// Invoice Token 充值账单（由支付提供商回调确认后生成）
+type Invoice struct {
+	ID       string `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID string `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	Provider string `json:"provider" gorm:"type:varchar(32);not null"`
+	// ExternalID 支付提供商侧的支付单号（同一提供商内唯一，用于回调幂等）
+	ExternalID string `json:"external_id" gorm:"type:varchar(255);not null"`
+	EventID    string `json:"event_id,omitempty" gorm:"type:varchar(255)"`
+	// Amount 支付金额（最小货币单位，如分）
+	Amount    int64         `json:"amount" gorm:"not null;default:0"`
+	Currency  string        `json:"currency" gorm:"type:varchar(8);not null;default:''"`
+	Tokens    int64         `json:"tokens" gorm:"not null"`
+	Status    InvoiceStatus `json:"status" gorm:"type:varchar(16);not null;default:'paid'"`
+	PaidAt    time.Time     `json:"paid_at" gorm:"not null"`
+	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (Invoice) TableName() string {
+	return "invoices"
+}
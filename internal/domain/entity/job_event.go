@@ -0,0 +1,54 @@
+// Package entity 定义领域实体
+package entity
+
+import "time"
+
+// JobEventType 任务时间线事件类型
+type JobEventType string
+
+const (
+	JobEventQueued         JobEventType = "queued"          // 任务已创建并入队
+	JobEventClaimed        JobEventType = "claimed"         // 被 Worker 领取
+	JobEventReprioritized  JobEventType = "reprioritized"   // 排队期间被管理员调整优先级
+	JobEventStarted        JobEventType = "started"         // 运维任务开始执行
+	JobEventRAGRetrieved   JobEventType = "rag_retrieved"   // 完成上下文召回
+	JobEventLLMStarted     JobEventType = "llm_started"     // 开始调用模型
+	JobEventTokensStreamed JobEventType = "tokens_streamed" // 流式输出完成（记录输出量）
+	JobEventValidateFailed JobEventType = "validate_failed" // 输出校验失败
+	JobEventRepaired       JobEventType = "repaired"        // 校验失败后经修复通过
+	JobEventSpoilerFlagged JobEventType = "spoiler_flagged" // 生成内容触发剧透保护
+	JobEventDetached       JobEventType = "detached"        // 同步生成期间客户端断开，结果在后台完成落库
+	JobEventSelected       JobEventType = "selected"        // 多候选生成中采用了某个候选（自动或手动）
+	JobEventCompleted      JobEventType = "completed"
+	JobEventFailed         JobEventType = "failed"
+	JobEventCancelled      JobEventType = "cancelled"
+	JobEventDiscarded      JobEventType = "discarded" // 中断时保存的部分内容被丢弃（作者丢弃或续写完成后替代）
+)
+
+// JobEvent 任务时间线事件（只追加）
+type JobEvent struct {
+	ID        string         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID  string         `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	JobID     string         `json:"job_id" gorm:"type:uuid;index;not null"`
+	Type      JobEventType   `json:"type" gorm:"type:varchar(32);not null"`
+	Message   string         `json:"message,omitempty" gorm:"type:text"`
+	Data      map[string]any `json:"data,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (JobEvent) TableName() string {
+	return "job_events"
+}
+
+// NewJobEvent 创建任务时间线事件
+func NewJobEvent(job *GenerationJob, eventType JobEventType, message string, data map[string]any) *JobEvent {
+	return &JobEvent{
+		TenantID:  job.TenantID,
+		JobID:     job.ID,
+		Type:      eventType,
+		Message:   message,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+}
@@ -9,6 +9,9 @@ type LLMUsageEvent struct {
 	Provider         string    `json:"provider" gorm:"type:varchar(32);not null"`
 	Model            string    `json:"model" gorm:"type:varchar(64);not null"`
 	Workflow         string    `json:"workflow" gorm:"type:varchar(64)"`
+	ProjectID        *string   `json:"project_id,omitempty" gorm:"type:uuid"`
+	JobID            *string   `json:"job_id,omitempty" gorm:"type:uuid"`
+	JobType          string    `json:"job_type" gorm:"type:varchar(32);not null;default:''"`
 	TokensPrompt     int       `json:"tokens_prompt" gorm:"not null;default:0"`
 	TokensCompletion int       `json:"tokens_completion" gorm:"not null;default:0"`
 	DurationMs       int       `json:"duration_ms" gorm:"not null;default:0"`
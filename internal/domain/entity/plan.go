@@ -0,0 +1,47 @@
+// Package entity 定义领域实体
+package entity
+
+import "time"
+
+// PlanFeature 套餐功能开关
+type PlanFeature string
+
+const (
+	PlanFeatureChapterStream      PlanFeature = "chapter_stream"      // SSE 流式生成章节
+	PlanFeatureFoundationGenerate PlanFeature = "foundation_generate" // 设定集生成（预览/流式/异步）
+)
+
+// Plan 订阅套餐
+type Plan struct {
+	ID   string `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Code string `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name string `json:"name" gorm:"type:varchar(100);not null"`
+	// MonthlyTokens 每个配额周期重置后的 Token 余额
+	MonthlyTokens int64 `json:"monthly_tokens" gorm:"not null;default:0"`
+	// MaxConcurrentJobs 同时进行中的生成任务上限（0 表示不限制）
+	MaxConcurrentJobs int `json:"max_concurrent_jobs" gorm:"not null;default:0"`
+	// RequestsPerSecond 租户级 API 限流（0 表示使用全局配置）
+	RequestsPerSecond int             `json:"requests_per_second" gorm:"not null;default:0"`
+	Features          map[string]bool `json:"features,omitempty" gorm:"type:jsonb;serializer:json"`
+	IsActive          bool            `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (Plan) TableName() string {
+	return "plans"
+}
+
+// HasFeature 检查套餐是否开通指定功能（未声明的功能视为未开通）
+func (p *Plan) HasFeature(feature PlanFeature) bool {
+	if p == nil {
+		return true
+	}
+	return p.Features[string(feature)]
+}
+
+// NextQuotaPeriodEnd 计算配额周期结束时间（按月滚动）
+func NextQuotaPeriodEnd(start time.Time) time.Time {
+	return start.AddDate(0, 1, 0)
+}
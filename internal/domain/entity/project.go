@@ -2,6 +2,7 @@
 package entity
 
 import (
+	"strings"
 	"time"
 )
 
@@ -29,6 +30,147 @@ type ProjectSettings struct {
 	WritingStyle         string  `json:"writing_style,omitempty"`
 	POV                  string  `json:"pov,omitempty"`
 	Temperature          float64 `json:"temperature,omitempty"`
+
+	// POVStyles 多视角小说中各 POV 角色的专属风格（key 为角色实体 ID）
+	POVStyles map[string]*POVStyle `json:"pov_styles,omitempty"`
+
+	// SeriesRetrieval 是否在生成/检索时只读召回同系列前作的内容（需项目归属系列）
+	SeriesRetrieval bool `json:"series_retrieval,omitempty"`
+
+	// PublicRead 是否通过公开只读 API（/public/v1）对外发布已完成章节
+	PublicRead bool `json:"public_read,omitempty"`
+
+	// Provider / Model 项目默认 LLM（请求未指定 provider 时使用，为空回退全局默认）
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	// RetrievalTopK 章节生成前召回的片段数（0 表示使用系统默认）
+	RetrievalTopK int `json:"retrieval_top_k,omitempty"`
+	// RetrievalNamespaces 章节召回的命名空间配额（如 summaries:4 / prose:6 / entity_cards:2）；为空表示所有片段共享 RetrievalTopK
+	RetrievalNamespaces map[string]int `json:"retrieval_namespaces,omitempty"`
+
+	// ArtifactActivation 构件新版本的自动激活策略（为空表示 auto_main）
+	ArtifactActivation ArtifactActivationPolicy `json:"artifact_activation,omitempty"`
+
+	// OutlineRefresh 激活的大纲条目发生实质变化时，是否自动提交大纲刷新任务（outline_refresh）同步章节大纲文本
+	OutlineRefresh bool `json:"outline_refresh,omitempty"`
+}
+
+// NewProjectSettingsFromTemplate 以租户默认设置模板创建项目设置（模板为空时返回空设置）
+func NewProjectSettingsFromTemplate(tpl *ProjectSettings) *ProjectSettings {
+	s := &ProjectSettings{}
+	s.ResetTo(tpl)
+	return s
+}
+
+// ResetTo 将可模板化的设置重置为租户默认值；POV 角色风格、公开发布状态与构件激活策略属于项目自身，保持不变
+func (s *ProjectSettings) ResetTo(tpl *ProjectSettings) {
+	povStyles, publicRead, activation := s.POVStyles, s.PublicRead, s.ArtifactActivation
+	*s = ProjectSettings{}
+	if tpl != nil {
+		*s = *tpl.Template()
+	}
+	s.POVStyles, s.PublicRead, s.ArtifactActivation = povStyles, publicRead, activation
+}
+
+// Template 返回可作为租户默认模板的设置副本（去除 POV 角色风格与公开发布等项目专属字段）
+func (s *ProjectSettings) Template() *ProjectSettings {
+	if s == nil {
+		return &ProjectSettings{}
+	}
+	return &ProjectSettings{
+		DefaultChapterLength: s.DefaultChapterLength,
+		WritingStyle:         s.WritingStyle,
+		POV:                  s.POV,
+		Temperature:          s.Temperature,
+		SeriesRetrieval:      s.SeriesRetrieval,
+		Provider:             s.Provider,
+		Model:                s.Model,
+		RetrievalTopK:        s.RetrievalTopK,
+		RetrievalNamespaces:  copyQuotas(s.RetrievalNamespaces),
+		OutlineRefresh:       s.OutlineRefresh,
+	}
+}
+
+// copyQuotas 复制配额表，避免模板与项目设置共享同一 map
+func copyQuotas(m map[string]int) map[string]int {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// ChapterRetrievalTopK 章节召回片段数设置（未设置返回 0）
+func (s *ProjectSettings) ChapterRetrievalTopK() int {
+	if s == nil {
+		return 0
+	}
+	return s.RetrievalTopK
+}
+
+// RetrievalNamespaceQuotas 章节召回的命名空间配额设置（未设置返回 nil）
+func (s *ProjectSettings) RetrievalNamespaceQuotas() map[string]int {
+	if s == nil {
+		return nil
+	}
+	return s.RetrievalNamespaces
+}
+
+// ArtifactActivationPolicy 构件自动激活策略（未设置返回 auto_main）
+func (s *ProjectSettings) ArtifactActivationPolicy() ArtifactActivationPolicy {
+	if s == nil || !s.ArtifactActivation.IsValid() {
+		return ArtifactActivationAutoMain
+	}
+	return s.ArtifactActivation
+}
+
+// OutlineRefreshEnabled 大纲变化后是否自动提交大纲刷新任务
+func (s *ProjectSettings) OutlineRefreshEnabled() bool {
+	return s != nil && s.OutlineRefresh
+}
+
+// DefaultProviderModel 项目默认 LLM Provider/Model
+func (s *ProjectSettings) DefaultProviderModel() (provider, model string) {
+	if s == nil {
+		return "", ""
+	}
+	return strings.TrimSpace(s.Provider), strings.TrimSpace(s.Model)
+}
+
+// IsPublicReadable 项目是否开启公开只读访问
+func (p *Project) IsPublicReadable() bool {
+	return p != nil && p.Settings != nil && p.Settings.PublicRead
+}
+
+// POVStyle POV 角色专属写作设置（为空的字段回退到项目级设置）
+type POVStyle struct {
+	WritingStyle string `json:"writing_style,omitempty"`
+	POV          string `json:"pov,omitempty"`
+}
+
+// ResolveStyle 解析指定 POV 角色的写作风格与叙事视角：优先 POV 专属设置，缺省回退项目级设置。
+func (s *ProjectSettings) ResolveStyle(povEntityID string) (writingStyle, pov string) {
+	if s == nil {
+		return "", ""
+	}
+	writingStyle = strings.TrimSpace(s.WritingStyle)
+	pov = strings.TrimSpace(s.POV)
+	if povEntityID == "" {
+		return writingStyle, pov
+	}
+	if ps := s.POVStyles[povEntityID]; ps != nil {
+		if v := strings.TrimSpace(ps.WritingStyle); v != "" {
+			writingStyle = v
+		}
+		if v := strings.TrimSpace(ps.POV); v != "" {
+			pov = v
+		}
+	}
+	return writingStyle, pov
 }
 
 // Project 小说项目实体
@@ -44,6 +186,8 @@ type Project struct {
 	Settings         *ProjectSettings `json:"settings,omitempty" gorm:"type:jsonb;serializer:json"`
 	WorldSettings    *WorldSettings   `json:"world_settings,omitempty" gorm:"type:jsonb;serializer:json"`
 	Status           ProjectStatus    `json:"status" gorm:"type:varchar(50);default:'draft'"`
+	SeriesID         *string          `json:"series_id,omitempty" gorm:"type:uuid;index"`
+	SeriesOrder      int              `json:"series_order,omitempty" gorm:"default:0"`
 	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt        time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,37 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// ProjectNote 作者导入的原始笔记：冷启动时用于抽取设定草稿，并作为独立片段类型写入向量索引供后续召回
+type ProjectNote struct {
+	ID        string `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID  string `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	ProjectID string `json:"project_id" gorm:"type:uuid;index;not null"`
+	Title     string `json:"title,omitempty" gorm:"type:varchar(255)"`
+	Content   string `json:"content" gorm:"type:text;not null"`
+	CharCount int    `json:"char_count" gorm:"not null;default:0"`
+	// SourceJobID 导入该笔记的任务
+	SourceJobID *string   `json:"source_job_id,omitempty" gorm:"type:uuid"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (ProjectNote) TableName() string {
+	return "project_notes"
+}
+
+// NewProjectNote 创建项目笔记
+func NewProjectNote(tenantID, projectID, title, content string) *ProjectNote {
+	return &ProjectNote{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Title:     title,
+		Content:   content,
+		CharCount: utf8.RuneCountInString(content),
+		CreatedAt: time.Now(),
+	}
+}
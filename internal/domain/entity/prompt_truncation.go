@@ -0,0 +1,69 @@
+package entity
+
+// TruncationSource Prompt 截断来源
+type TruncationSource string
+
+const (
+	// TruncationSourceAttachment 附件超出单个/总字数上限
+	TruncationSourceAttachment TruncationSource = "attachment"
+	// TruncationSourceRollingContext 本轮指令记入会话滚动上下文时被截断，或滚动摘要压缩时丢弃了较早内容
+	TruncationSourceRollingContext TruncationSource = "rolling_context"
+	// TruncationSourceRetrieval 召回片段超出 Prompt 条数或单片段字数预算
+	TruncationSourceRetrieval TruncationSource = "retrieval"
+)
+
+// MaxTruncationItems 单份截断报告保留的明细上限（汇总字段仍计入全部截断）
+const MaxTruncationItems = 50
+
+// PromptTruncation 组装 Prompt 时被截断（或整体丢弃）的一项输入
+type PromptTruncation struct {
+	Source TruncationSource `json:"source"`
+	// Name 附件名、召回片段引用或滚动上下文的部分（prompt / summary）
+	Name          string `json:"name,omitempty"`
+	OriginalChars int    `json:"original_chars"`
+	KeptChars     int    `json:"kept_chars"`
+	CutChars      int    `json:"cut_chars"`
+	// CutTokens 被截去部分的估算 Token 数（启发式估算，不依赖具体模型的分词器）
+	CutTokens int  `json:"cut_tokens"`
+	Dropped   bool `json:"dropped,omitempty"`
+}
+
+// TruncationReport 一次生成的 Prompt 截断报告，随任务详情与同步接口的 usage 返回，
+// 用于向用户解释模型为何“忽略”了部分输入
+type TruncationReport struct {
+	Items     []PromptTruncation `json:"items"`
+	CutChars  int                `json:"cut_chars"`
+	CutTokens int                `json:"cut_tokens"`
+}
+
+// NewTruncationReport 汇总截断明细；没有截断时返回 nil
+func NewTruncationReport(items ...PromptTruncation) *TruncationReport {
+	var r *TruncationReport
+	return r.Add(items...)
+}
+
+// Add 追加截断明细并返回报告（接收者为 nil 时按需创建）
+func (r *TruncationReport) Add(items ...PromptTruncation) *TruncationReport {
+	if len(items) == 0 {
+		return r
+	}
+	if r == nil {
+		r = &TruncationReport{}
+	}
+	for _, it := range items {
+		r.CutChars += it.CutChars
+		r.CutTokens += it.CutTokens
+		if len(r.Items) < MaxTruncationItems {
+			r.Items = append(r.Items, it)
+		}
+	}
+	return r
+}
+
+// RecordTruncation 记录组装 Prompt 时的截断明细
+func (j *GenerationJob) RecordTruncation(items ...PromptTruncation) {
+	if j == nil {
+		return
+	}
+	j.Truncation = j.Truncation.Add(items...)
+}
@@ -0,0 +1,50 @@
+// Package entity 定义领域实体
+package entity
+
+import "time"
+
+// QuotaReservationStatus 配额预留状态
+type QuotaReservationStatus string
+
+const (
+	QuotaReservationReserved QuotaReservationStatus = "reserved" // 已预留（计入占用）
+	QuotaReservationSettled  QuotaReservationStatus = "settled"  // 任务完成，已按实际用量结算
+	QuotaReservationReleased QuotaReservationStatus = "released" // 任务失败/取消，预留已释放
+)
+
+// QuotaReservation 任务级 Token 配额预留（每个任务至多一条）
+type QuotaReservation struct {
+	ID            string                 `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID      string                 `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	JobID         string                 `json:"job_id" gorm:"type:uuid;uniqueIndex;not null"`
+	Amount        int64                  `json:"amount" gorm:"not null"`
+	SettledTokens int64                  `json:"settled_tokens" gorm:"not null;default:0"`
+	Status        QuotaReservationStatus `json:"status" gorm:"type:varchar(16);not null;default:'reserved'"`
+	ExpiresAt     time.Time              `json:"expires_at" gorm:"not null"`
+	CreatedAt     time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (QuotaReservation) TableName() string {
+	return "quota_reservations"
+}
+
+// NewQuotaReservation 创建配额预留
+func NewQuotaReservation(tenantID, jobID string, amount int64, ttl time.Duration) *QuotaReservation {
+	now := time.Now()
+	return &QuotaReservation{
+		TenantID:  tenantID,
+		JobID:     jobID,
+		Amount:    amount,
+		Status:    QuotaReservationReserved,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsActive 是否仍计入占用
+func (r *QuotaReservation) IsActive(now time.Time) bool {
+	return r.Status == QuotaReservationReserved && now.Before(r.ExpiresAt)
+}
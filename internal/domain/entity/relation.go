@@ -2,9 +2,13 @@
 package entity
 
 import (
+	"math"
 	"time"
 )
 
+// relationReinforceRate 每次强化时强度向 1 靠近的比例
+const relationReinforceRate = 0.1
+
 // RelationType 关系类型
 type RelationType string
 
@@ -38,8 +42,10 @@ type Relation struct {
 	Attributes     *RelationAttributes `json:"attributes,omitempty" gorm:"type:jsonb;serializer:json"`
 	FirstChapterID string              `json:"first_chapter_id,omitempty" gorm:"type:uuid"`
 	LastChapterID  string              `json:"last_chapter_id,omitempty" gorm:"type:uuid"`
-	CreatedAt      time.Time           `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time           `json:"updated_at" gorm:"autoUpdateTime"`
+	// LastReinforcedChapterID 最近一次被章节内容强化（双方同时出场）的章节；为空表示从未强化（手工/设定创建）
+	LastReinforcedChapterID *string   `json:"last_reinforced_chapter_id,omitempty" gorm:"type:uuid"`
+	CreatedAt               time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt               time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName 指定表名
@@ -81,3 +87,27 @@ func (r *Relation) RecordAppearance(chapterID string) {
 	r.LastChapterID = chapterID
 	r.UpdatedAt = time.Now()
 }
+
+// Reinforce 章节中双方同时出场：记录强化章节并使强度向 1 靠近
+func (r *Relation) Reinforce(chapterID string) {
+	r.RecordAppearance(chapterID)
+	r.LastReinforcedChapterID = &chapterID
+	r.UpdateStrength(r.Strength + (1-r.Strength)*relationReinforceRate)
+}
+
+// RecencyAnchor 衰减计算的参照章节：最近强化章节，其次为最近出现章节
+func (r *Relation) RecencyAnchor() string {
+	if r.LastReinforcedChapterID != nil && *r.LastReinforcedChapterID != "" {
+		return *r.LastReinforcedChapterID
+	}
+	return r.LastChapterID
+}
+
+// DecayedStrength 按半衰期计算有效强度：每经过 halfLifeChapters 章未被强化，强度减半。
+// chaptersSince <= 0 或 halfLifeChapters <= 0 时不衰减。
+func DecayedStrength(strength float64, chaptersSince, halfLifeChapters int) float64 {
+	if chaptersSince <= 0 || halfLifeChapters <= 0 {
+		return strength
+	}
+	return strength * math.Pow(0.5, float64(chaptersSince)/float64(halfLifeChapters))
+}
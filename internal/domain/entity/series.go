@@ -0,0 +1,50 @@
+// Package entity 定义领域实体
+package entity
+
+import (
+	"time"
+)
+
+// Series 系列（多部曲）：将多个项目按顺序组织，共享设定与检索范围
+type Series struct {
+	ID          string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID    string    `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	OwnerID     string    `json:"owner_id,omitempty" gorm:"type:uuid;index"`
+	Title       string    `json:"title" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (Series) TableName() string {
+	return "series"
+}
+
+// NewSeries 创建新系列
+func NewSeries(tenantID, ownerID, title string) *Series {
+	now := time.Now()
+	return &Series{
+		TenantID:  tenantID,
+		OwnerID:   ownerID,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// SeriesInheritableArtifactTypes 可在系列内跨书继承的构件类型（后续书籍未创建同类构件时沿用前作）
+var SeriesInheritableArtifactTypes = []ArtifactType{
+	ArtifactTypeWorldview,
+	ArtifactTypeCharacters,
+}
+
+// IsSeriesInheritable 判断构件类型是否支持系列继承
+func IsSeriesInheritable(t ArtifactType) bool {
+	for _, it := range SeriesInheritableArtifactTypes {
+		if it == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// Package entity 定义领域实体
+package entity
+
+import "time"
+
+// SpoilerGuardKind 剧透保护对象类型
+type SpoilerGuardKind string
+
+const (
+	SpoilerGuardEntity SpoilerGuardKind = "entity" // 实体（人物/地点/物品等）在揭晓前不得出场或被提及
+	SpoilerGuardFact   SpoilerGuardKind = "fact"   // 事实/真相在揭晓前不得点明
+	SpoilerGuardEvent  SpoilerGuardKind = "event"  // 事件在揭晓前不得提前发生或被预告
+)
+
+// SpoilerGuard 剧透保护：在揭晓点（章节或卷）之前的章节中，受保护对象不得出现。
+// 生效范围按叙事顺序判断：叙事位置早于揭晓点的章节受约束。
+type SpoilerGuard struct {
+	ID        string           `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID  string           `json:"tenant_id" gorm:"type:uuid;index;not null"`
+	ProjectID string           `json:"project_id" gorm:"type:uuid;index;not null"`
+	Kind      SpoilerGuardKind `json:"kind" gorm:"type:varchar(16);not null"`
+	// RefID 受保护的实体/事件 ID（fact 类型为空）
+	RefID *string `json:"ref_id,omitempty" gorm:"type:uuid"`
+	// Label 注入 Prompt 的描述（实体名、事实陈述或事件摘要）
+	Label string `json:"label" gorm:"type:text;not null"`
+	// Keywords 生成后扫描用的关键词（实体类型额外使用名称与别名）
+	Keywords StringSlice `json:"keywords,omitempty" gorm:"type:jsonb"`
+	// RevealChapterID / RevealVolumeID 揭晓点（二选一）：该章节/卷起不再受约束
+	RevealChapterID *string   `json:"reveal_chapter_id,omitempty" gorm:"type:uuid"`
+	RevealVolumeID  *string   `json:"reveal_volume_id,omitempty" gorm:"type:uuid"`
+	Note            string    `json:"note,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (SpoilerGuard) TableName() string {
+	return "spoiler_guards"
+}
@@ -26,6 +26,82 @@ type TenantSettings struct {
 	DefaultModel            string `json:"default_model,omitempty"`
 	DefaultLanguage         string `json:"default_language,omitempty"`
 	AllowPublicRegistration bool   `json:"allow_public_registration,omitempty"`
+
+	// ProjectDefaults 新建项目的默认设置模板（章节长度、风格、视角、召回与 LLM 默认值）
+	ProjectDefaults *ProjectSettings `json:"project_defaults,omitempty"`
+
+	// ArtifactValidation 构件版本激活前调用的外部校验 Webhook（未设置时不校验）
+	ArtifactValidation *ArtifactValidationWebhook `json:"artifact_validation,omitempty"`
+
+	// FoundationPlanLimits 设定集规模上限的租户覆盖（超长篇等合理的大规划；未设置的维度使用全局配置）
+	FoundationPlanLimits *FoundationPlanLimits `json:"foundation_plan_limits,omitempty"`
+}
+
+// FoundationPlanLimits 设定集（FoundationPlan）规模上限，应用前校验，避免异常的 LLM 输出一次性写入海量数据；
+// 各维度 0 表示不限制（作为租户覆盖时表示沿用全局配置）
+type FoundationPlanLimits struct {
+	MaxEntities  int `json:"max_entities,omitempty"`
+	MaxRelations int `json:"max_relations,omitempty"`
+	MaxVolumes   int `json:"max_volumes,omitempty"`
+	// MaxChapters 全部卷的章节总数
+	MaxChapters int `json:"max_chapters,omitempty"`
+	// MaxOutlineChars 全部章节大纲的总字符数
+	MaxOutlineChars int `json:"max_outline_chars,omitempty"`
+}
+
+// Override 以 o 中大于 0 的维度覆盖当前上限
+func (l FoundationPlanLimits) Override(o *FoundationPlanLimits) FoundationPlanLimits {
+	if o == nil {
+		return l
+	}
+	pick := func(base, v int) int {
+		if v > 0 {
+			return v
+		}
+		return base
+	}
+	return FoundationPlanLimits{
+		MaxEntities:     pick(l.MaxEntities, o.MaxEntities),
+		MaxRelations:    pick(l.MaxRelations, o.MaxRelations),
+		MaxVolumes:      pick(l.MaxVolumes, o.MaxVolumes),
+		MaxChapters:     pick(l.MaxChapters, o.MaxChapters),
+		MaxOutlineChars: pick(l.MaxOutlineChars, o.MaxOutlineChars),
+	}
+}
+
+// IsZero 是否未设置任何维度
+func (l FoundationPlanLimits) IsZero() bool {
+	return l == FoundationPlanLimits{}
+}
+
+// ArtifactValidationWebhook 构件激活校验 Webhook：激活前将候选内容 POST 到 URL，
+// 外部服务返回通过 / 不通过及消息，不通过时阻止激活
+type ArtifactValidationWebhook struct {
+	URL string `json:"url"`
+	// Secret 请求签名密钥（HMAC-SHA256，见 X-Signature 头）；不在租户资料中返回
+	Secret string `json:"secret,omitempty"`
+	// ArtifactTypes 需要校验的构件类型（为空表示全部）
+	ArtifactTypes []ArtifactType `json:"artifact_types,omitempty"`
+	// TimeoutMs 单次调用超时（毫秒，0 使用默认值）
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// FailOpen 调用失败（超时、非 2xx、响应无法解析）时放行；默认阻止激活
+	FailOpen bool `json:"fail_open,omitempty"`
+}
+
+// AppliesTo 是否需要校验该类型的构件
+func (w *ArtifactValidationWebhook) AppliesTo(t ArtifactType) bool {
+	if w == nil || w.URL == "" {
+		return false
+	}
+	if len(w.ArtifactTypes) == 0 {
+		return true
+	}
+	for _, at := range w.ArtifactTypes {
+		if at == t {
+			return true
+		}
+	}
+	return false
 }
 
 // Tenant 租户实体
@@ -39,6 +115,11 @@ type Tenant struct {
 	Status       TenantStatus    `json:"status" gorm:"type:varchar(50);default:'active'"`
 	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// 订阅套餐与当前配额周期（未关联套餐时不做月度重置与套餐限制）
+	PlanID           *string    `json:"plan_id,omitempty" gorm:"type:uuid"`
+	QuotaPeriodStart *time.Time `json:"quota_period_start,omitempty"`
+	QuotaPeriodEnd   *time.Time `json:"quota_period_end,omitempty"`
 }
 
 // TableName 指定表名
@@ -50,8 +131,8 @@ func (Tenant) TableName() string {
 func NewTenant(name, slug string) *Tenant {
 	now := time.Now()
 	return &Tenant{
-		Name: name,
-		Slug: slug,
+		Name:   name,
+		Slug:   slug,
 		Status: TenantStatusActive,
 		Quota: &TenantQuota{
 			MaxProjects:           100,
@@ -65,11 +146,54 @@ func NewTenant(name, slug string) *Tenant {
 	}
 }
 
+// ProjectDefaults 租户默认项目设置模板（未设置返回 nil）
+func (t *Tenant) ProjectDefaults() *ProjectSettings {
+	if t == nil || t.Settings == nil {
+		return nil
+	}
+	return t.Settings.ProjectDefaults
+}
+
+// ArtifactValidation 租户构件激活校验 Webhook（未设置返回 nil）
+func (t *Tenant) ArtifactValidation() *ArtifactValidationWebhook {
+	if t == nil || t.Settings == nil {
+		return nil
+	}
+	return t.Settings.ArtifactValidation
+}
+
+// FoundationPlanLimits 租户的设定集规模上限覆盖（未设置返回 nil）
+func (t *Tenant) FoundationPlanLimits() *FoundationPlanLimits {
+	if t == nil || t.Settings == nil {
+		return nil
+	}
+	return t.Settings.FoundationPlanLimits
+}
+
 // HasSufficientBalance 检查余额是否充足
 func (t *Tenant) HasSufficientBalance(required int64) bool {
 	return t.TokenBalance >= required
 }
 
+// AssignPlan 关联套餐并开启新的配额周期（余额重置为套餐月度额度）
+func (t *Tenant) AssignPlan(plan *Plan, now time.Time) {
+	t.PlanID = &plan.ID
+	t.TokenBalance = plan.MonthlyTokens
+	t.StartQuotaPeriod(now)
+}
+
+// StartQuotaPeriod 从 start 开始新的配额周期
+func (t *Tenant) StartQuotaPeriod(start time.Time) {
+	end := NextQuotaPeriodEnd(start)
+	t.QuotaPeriodStart = &start
+	t.QuotaPeriodEnd = &end
+}
+
+// QuotaPeriodDue 当前配额周期是否已到期
+func (t *Tenant) QuotaPeriodDue(now time.Time) bool {
+	return t.PlanID != nil && t.QuotaPeriodEnd != nil && !now.Before(*t.QuotaPeriodEnd)
+}
+
 // IsActive 检查租户是否活跃
 func (t *Tenant) IsActive() bool {
 	return t.Status == TenantStatusActive
@@ -25,10 +25,21 @@ type Volume struct {
 	Summary     string       `json:"summary,omitempty" gorm:"type:text"`
 	WordCount   int          `json:"word_count" gorm:"default:0"`
 	Status      VolumeStatus `json:"status" gorm:"type:varchar(50);default:'draft'"`
-	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// 章节汇总（由 chapters 表触发器维护，应用层只读）
+	ChapterCount       int `json:"chapter_count" gorm:"->"`
+	DraftChapters      int `json:"draft_chapters" gorm:"->"`
+	GeneratingChapters int `json:"generating_chapters" gorm:"->"`
+	ReviewChapters     int `json:"review_chapters" gorm:"->"`
+	CompletedChapters  int `json:"completed_chapters" gorm:"->"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
+// ReadingCharsPerMinute 预计阅读时长按每分钟阅读字数估算
+const ReadingCharsPerMinute = 400
+
 // TableName 指定表名
 func (Volume) TableName() string {
 	return "volumes"
@@ -53,3 +64,31 @@ func (v *Volume) UpdateWordCount(delta int) {
 	v.WordCount += delta
 	v.UpdatedAt = time.Now()
 }
+
+// ApplyChapterRollup 按卷内章节重新计算字数与章节状态汇总（与迁移 000041 的触发器口径一致）
+func (v *Volume) ApplyChapterRollup(chapters []*Chapter) {
+	v.WordCount = 0
+	v.ChapterCount = len(chapters)
+	v.DraftChapters, v.GeneratingChapters, v.ReviewChapters, v.CompletedChapters = 0, 0, 0, 0
+	for _, ch := range chapters {
+		v.WordCount += ch.WordCount
+		switch ch.Status {
+		case ChapterStatusDraft:
+			v.DraftChapters++
+		case ChapterStatusGenerating:
+			v.GeneratingChapters++
+		case ChapterStatusReview:
+			v.ReviewChapters++
+		case ChapterStatusCompleted:
+			v.CompletedChapters++
+		}
+	}
+}
+
+// ReadingMinutes 预计阅读时长（分钟，向上取整；无正文时为 0）
+func (v *Volume) ReadingMinutes() int {
+	if v.WordCount <= 0 {
+		return 0
+	}
+	return (v.WordCount + ReadingCharsPerMinute - 1) / ReadingCharsPerMinute
+}
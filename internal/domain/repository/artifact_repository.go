@@ -17,13 +17,26 @@ type ArtifactRepository interface {
 	CreateVersion(ctx context.Context, version *entity.ArtifactVersion) error
 	GetLatestVersionNo(ctx context.Context, artifactID string) (int, error)
 	GetVersionByID(ctx context.Context, id string) (*entity.ArtifactVersion, error)
-	// ListVersions 列出版本；branchKey 为空表示不过滤
-	ListVersions(ctx context.Context, artifactID string, branchKey string, pagination Pagination) (*PagedResult[*entity.ArtifactVersion], error)
+	// ListVersions 列出版本；branchKey / tag 为空表示不过滤
+	ListVersions(ctx context.Context, artifactID string, branchKey string, tag string, pagination Pagination) (*PagedResult[*entity.ArtifactVersion], error)
 	// GetLatestVersionByBranch 获取指定分支的最新版本；不存在返回 nil
 	GetLatestVersionByBranch(ctx context.Context, artifactID string, branchKey string) (*entity.ArtifactVersion, error)
 	// ListBranchHeads 返回每个分支的最新版本（按 branch_key 聚合）
 	ListBranchHeads(ctx context.Context, artifactID string) ([]*entity.ArtifactVersion, error)
+	// ListVersionSummaries 列出全部版本摘要（按 branch_key、version_no 降序）
+	ListVersionSummaries(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionSummary, error)
+	// DeleteVersions 删除指定版本，激活版本与带标签的版本始终跳过；返回实际删除的版本 ID
+	DeleteVersions(ctx context.Context, artifactID string, versionIDs []string) ([]string, error)
 
 	// SetActiveVersion 设置激活版本
 	SetActiveVersion(ctx context.Context, artifactID, versionID string) error
+
+	// CreateTag 为版本打标签（同一构件内标签唯一）
+	CreateTag(ctx context.Context, tag *entity.ArtifactVersionTag) error
+	// GetTag 按标签名获取；不存在返回 nil
+	GetTag(ctx context.Context, artifactID, tag string) (*entity.ArtifactVersionTag, error)
+	// ListTags 列出构件的全部标签（含版本号）
+	ListTags(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionTag, error)
+	// DeleteTag 删除标签（版本本身保留）；返回是否存在
+	DeleteTag(ctx context.Context, artifactID, tag string) (bool, error)
 }
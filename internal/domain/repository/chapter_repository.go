@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
 )
@@ -11,6 +12,8 @@ import (
 type ChapterFilter struct {
 	VolumeID string
 	Status   entity.ChapterStatus
+	// IncludeContent 是否加载正文（默认仅加载摘要等轻量字段，避免列表查询传输整章正文）
+	IncludeContent bool
 }
 
 // ChapterRepository 章节仓储接口
@@ -21,13 +24,16 @@ type ChapterRepository interface {
 	// GetByID 根据 ID 获取章节
 	GetByID(ctx context.Context, id string) (*entity.Chapter, error)
 
+	// GetByIDForUpdate 根据 ID 获取章节并加行锁（需在事务内调用）
+	GetByIDForUpdate(ctx context.Context, id string) (*entity.Chapter, error)
+
 	// Update 更新章节
 	Update(ctx context.Context, chapter *entity.Chapter) error
 
 	// Delete 删除章节
 	Delete(ctx context.Context, id string) error
 
-	// ListByProject 获取项目章节列表
+	// ListByProject 获取项目章节列表（默认不含正文，见 ChapterFilter.IncludeContent）
 	ListByProject(ctx context.Context, projectID string, filter *ChapterFilter, pagination Pagination) (*PagedResult[*entity.Chapter], error)
 
 	// ListByVolume 获取卷章节列表（按序号排序）
@@ -42,18 +48,69 @@ type ChapterRepository interface {
 	// UpdateContent 更新章节内容
 	UpdateContent(ctx context.Context, id, content, summary string) error
 
+	// UpdateWordCount 更新章节字数（仅写该列，用于按正文重新统计）
+	UpdateWordCount(ctx context.Context, id string, wordCount int) error
+
 	// UpdateStatus 更新章节状态
 	UpdateStatus(ctx context.Context, id string, status entity.ChapterStatus) error
 
+	// UpdateContextPins 更新章节固定上下文（仅写该列，不影响并发的正文编辑）
+	UpdateContextPins(ctx context.Context, id string, pins []entity.ContextPin) error
+
+	// UpdateGenerationMetadata 更新章节生成元数据（仅写该列，如标题建议，不影响并发的正文编辑）
+	UpdateGenerationMetadata(ctx context.Context, id string, meta *entity.GenerationMetadata) error
+
+	// MarkOutlineStale 将项目内关联到给定大纲条目（outline_key）的章节标记为大纲过期（已过期的保留最早的标记时间），返回命中的章节 ID
+	MarkOutlineStale(ctx context.Context, projectID string, outlineKeys []string, at time.Time) ([]string, error)
+
+	// ClearOutlineStale 清除章节的大纲过期标记（仅写该列，不影响并发的正文编辑）
+	ClearOutlineStale(ctx context.Context, id string) error
+
 	// ReorderChapters 重新排序某一卷下的章节（按给定 ID 顺序；未包含的章节会追加到末尾）
 	ReorderChapters(ctx context.Context, projectID, volumeID string, chapterIDs []string) error
 
 	// GetNextSeqNum 获取下一个序号
 	GetNextSeqNum(ctx context.Context, projectID, volumeID string) (int, error)
 
+	// RefreshDisplayNumbers 按叙事顺序（卷序号 -> 章节序号）为项目全部章节重新分配连续的展示序号 display_no（从 1 开始）
+	RefreshDisplayNumbers(ctx context.Context, projectID string) error
+
 	// GetByStoryTimeRange 根据故事时间范围获取章节
 	GetByStoryTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Chapter, error)
 
-	// GetRecent 获取最近章节
+	// ListTimeline 按叙事顺序（卷序号 -> 章节序号）获取项目章节的时间轴字段（不含正文）
+	ListTimeline(ctx context.Context, projectID string) ([]*entity.Chapter, error)
+
+	// ListOutlineLinks 按叙事顺序获取项目全部章节的大纲关联字段（ai_key / outline_key / 标题 / 字数 / 状态 / 大纲过期标记，不含正文）
+	ListOutlineLinks(ctx context.Context, projectID string) ([]*entity.Chapter, error)
+
+	// ListPublished 按叙事顺序获取项目已完成章节（不含正文，用于公开只读 API）
+	ListPublished(ctx context.Context, projectID string) ([]*entity.Chapter, error)
+
+	// ListManuscript 按叙事顺序获取项目已完成章节（含正文，用于导出成稿）
+	ListManuscript(ctx context.Context, projectID string) ([]*entity.Chapter, error)
+
+	// GetNarrativePosition 获取章节的叙事位置（见 entity.NarrativePosition）
+	GetNarrativePosition(ctx context.Context, chapterID string) (int64, error)
+
+	// ListByStatus 获取指定状态且 updated_at 早于 updatedBefore 的章节（不含正文，按 updated_at 升序，最多 limit 条）
+	ListByStatus(ctx context.Context, status entity.ChapterStatus, updatedBefore time.Time, limit int) ([]*entity.Chapter, error)
+
+	// GetRecent 获取最近章节（不含正文）
 	GetRecent(ctx context.Context, projectID string, limit int) ([]*entity.Chapter, error)
+
+	// ListStats 获取项目全部章节的文本统计（Create / Update / UpdateContent 时维护）
+	ListStats(ctx context.Context, projectID string) ([]*entity.ChapterStats, error)
+
+	// SaveStats 写入（覆盖）章节文本统计，用于补算历史章节
+	SaveStats(ctx context.Context, stats *entity.ChapterStats) error
+
+	// ListFingerprints 获取项目全部章节的内容指纹（Create / Update / UpdateContent 时维护）
+	ListFingerprints(ctx context.Context, projectID string) ([]*entity.ChapterFingerprint, error)
+
+	// SaveFingerprint 写入（覆盖）章节内容指纹，用于补算历史章节
+	SaveFingerprint(ctx context.Context, fp *entity.ChapterFingerprint) error
+
+	// GetVersion 获取章节指定版本的正文快照（Create / Update / UpdateContent 时按当前版本号维护；不存在时返回 nil）
+	GetVersion(ctx context.Context, chapterID string, version int) (*entity.ChapterVersion, error)
 }
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 
 	"z-novel-ai-api/internal/domain/entity"
 )
@@ -17,5 +18,13 @@ type ConversationSessionRepository interface {
 
 type ConversationTurnRepository interface {
 	Create(ctx context.Context, turn *entity.ConversationTurn) error
+	// GetByIDForUpdate 获取轮次并加行锁（草稿轮次转为构件版本时防止重复转换）
+	GetByIDForUpdate(ctx context.Context, id string) (*entity.ConversationTurn, error)
+	// UpdateMetadata 覆盖写入轮次 metadata
+	UpdateMetadata(ctx context.Context, id string, metadata json.RawMessage) error
 	ListBySession(ctx context.Context, sessionID string, pagination Pagination) (*PagedResult[*entity.ConversationTurn], error)
+	// SumUsageBySession 汇总会话内各轮次记录的 Token 用量与成本
+	SumUsageBySession(ctx context.Context, sessionID string) (*entity.ConversationUsage, error)
+	// ListConflictWarnings 获取为指定构件版本记录了设定冲突警告的助手轮次（metadata.version_id 命中且含 conflict_warnings）
+	ListConflictWarnings(ctx context.Context, versionIDs []string) ([]*entity.ConversationTurn, error)
 }
@@ -46,6 +46,9 @@ type EntityRepository interface {
 	// RecordAppearance 记录出场
 	RecordAppearance(ctx context.Context, id, chapterID string) error
 
+	// SetAppearances 覆盖实体出场统计（首次/最近出场章节为空表示清除），用于按章节数据重新统计
+	SetAppearances(ctx context.Context, id, firstChapterID, lastChapterID string, count int) error
+
 	// GetByType 根据类型获取实体列表
 	GetByType(ctx context.Context, projectID string, entityType entity.StoryEntityType) ([]*entity.StoryEntity, error)
 
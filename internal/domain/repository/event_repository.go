@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
 )
@@ -16,6 +17,8 @@ type EventFilter struct {
 	Tags       []string
 	TimeStart  int64
 	TimeEnd    int64
+	// IncludeSuperseded 是否包含已被替代的事件（默认仅返回当前有效事件）
+	IncludeSuperseded bool
 }
 
 // EventRepository 事件仓储接口
@@ -35,9 +38,12 @@ type EventRepository interface {
 	// ListByProject 获取项目事件列表
 	ListByProject(ctx context.Context, projectID string, filter *EventFilter, pagination Pagination) (*PagedResult[*entity.Event], error)
 
-	// ListByChapter 获取章节事件列表
+	// ListByChapter 获取章节当前有效的事件列表
 	ListByChapter(ctx context.Context, chapterID string) ([]*entity.Event, error)
 
+	// Supersede 将事件标记为已被替代（supersededBy 为空表示无对应新事件）
+	Supersede(ctx context.Context, id string, supersededBy *string, at time.Time) error
+
 	// GetByTimeRange 根据时间范围获取事件
 	GetByTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Event, error)
 
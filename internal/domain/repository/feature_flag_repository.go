@@ -0,0 +1,22 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// FeatureFlagRepository 功能开关仓储接口
+type FeatureFlagRepository interface {
+	// ListFlags 获取全部全局开关定义
+	ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error)
+	// GetFlag 根据 Key 获取开关定义
+	GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error)
+	// ListOverrides 获取租户的全部覆盖（含项目级）
+	ListOverrides(ctx context.Context, tenantID string) ([]*entity.FeatureFlagOverride, error)
+	// UpsertOverride 创建或更新覆盖（按租户 + 开关 + 项目唯一）
+	UpsertOverride(ctx context.Context, override *entity.FeatureFlagOverride) error
+	// DeleteOverride 删除覆盖（projectID 为空表示租户级覆盖）
+	DeleteOverride(ctx context.Context, tenantID, flagKey string, projectID *string) error
+}
@@ -0,0 +1,22 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// GenerationCandidateRepository 多候选生成结果仓储接口
+type GenerationCandidateRepository interface {
+	// CreateBatch 批量保存同一任务的候选结果
+	CreateBatch(ctx context.Context, candidates []*entity.GenerationCandidate) error
+	// GetByID 根据 ID 获取候选结果
+	GetByID(ctx context.Context, id string) (*entity.GenerationCandidate, error)
+	// ListByJob 按候选序号获取任务的全部候选结果
+	ListByJob(ctx context.Context, jobID string) ([]*entity.GenerationCandidate, error)
+	// MarkSelected 将候选标记为已采用，同任务其余候选标记为未采用
+	MarkSelected(ctx context.Context, jobID, candidateID string, versionID *string) error
+	// MarkDiscarded 将单个候选标记为未采用
+	MarkDiscarded(ctx context.Context, candidateID string) error
+}
@@ -0,0 +1,18 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// InvoiceRepository 账单仓储接口
+type InvoiceRepository interface {
+	// Create 创建账单；同一提供商的 external_id 已存在时不写入并返回 false（用于回调幂等）
+	Create(ctx context.Context, invoice *entity.Invoice) (bool, error)
+	// GetByID 根据 ID 获取账单
+	GetByID(ctx context.Context, id string) (*entity.Invoice, error)
+	// ListByTenant 获取租户账单（按支付时间倒序）
+	ListByTenant(ctx context.Context, tenantID string, pagination Pagination) (*PagedResult[*entity.Invoice], error)
+}
@@ -0,0 +1,16 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// JobEventRepository 任务时间线事件仓储接口
+type JobEventRepository interface {
+	// Create 追加事件
+	Create(ctx context.Context, event *entity.JobEvent) error
+	// ListByJob 按时间顺序获取任务的全部事件
+	ListByJob(ctx context.Context, jobID string) ([]*entity.JobEvent, error)
+}
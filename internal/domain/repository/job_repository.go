@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
@@ -11,8 +12,12 @@ import (
 // JobFilter 任务过滤条件
 type JobFilter struct {
 	JobType   entity.JobType
+	Category  entity.JobCategory
 	Status    entity.JobStatus
 	ChapterID *string
+	// MinPriority / MaxPriority 优先级范围（0 表示不限）
+	MinPriority int
+	MaxPriority int
 }
 
 // JobRepository 生成任务仓储接口
@@ -32,18 +37,34 @@ type JobRepository interface {
 	// ListByProject 获取项目任务列表
 	ListByProject(ctx context.Context, projectID string, filter *JobFilter, pagination Pagination) (*PagedResult[*entity.GenerationJob], error)
 
+	// ListStatusByIDs 批量获取任务状态摘要（单次 IN 查询，仅加载状态相关列，不含输入/输出参数）；
+	// 不存在或不可见的 ID 直接忽略，结果顺序不保证与入参一致
+	ListStatusByIDs(ctx context.Context, ids []string) ([]*entity.GenerationJob, error)
+
 	// GetByIdempotencyKey 根据幂等键获取任务
 	GetByIdempotencyKey(ctx context.Context, key string) (*entity.GenerationJob, error)
 
 	// UpdateStatus 更新任务状态
 	UpdateStatus(ctx context.Context, id string, status entity.JobStatus) error
 
+	// UpdateInputSnapshot 记录任务执行时的输入快照
+	UpdateInputSnapshot(ctx context.Context, id string, snapshot json.RawMessage) error
+
+	// AppendWarnings 追加任务警告（用于任务已落库后的收尾步骤，如写索引）；超出上限的部分丢弃
+	AppendWarnings(ctx context.Context, id string, warnings ...entity.JobWarning) error
+
 	// UpdateProgress 更新任务进度（0-100）
 	UpdateProgress(ctx context.Context, id string, progress int) error
 
 	// GetPendingJobs 获取待处理任务
 	GetPendingJobs(ctx context.Context, limit int) ([]*entity.GenerationJob, error)
 
+	// GetActiveByChapter 获取章节上正在排队或执行中的生成任务（不存在返回 nil）
+	GetActiveByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error)
+
+	// GetLatestByChapter 获取章节最近创建的章节生成任务（不限状态，不存在返回 nil）
+	GetLatestByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error)
+
 	// GetRunningJobs 获取运行中任务
 	GetRunningJobs(ctx context.Context) ([]*entity.GenerationJob, error)
 
@@ -8,8 +8,36 @@ import (
 	"z-novel-ai-api/internal/domain/entity"
 )
 
+// LLMUsageEventFilter 用量流水查询条件：时间范围为 [Start, End)，零值与空字符串表示不限
+type LLMUsageEventFilter struct {
+	Start     time.Time
+	End       time.Time
+	Provider  string
+	Model     string
+	Workflow  string
+	JobType   string
+	ProjectID string
+}
+
+// LLMUsageSummary 用量流水汇总
+type LLMUsageSummary struct {
+	Events           int64
+	TokensPrompt     int64
+	TokensCompletion int64
+	DurationMs       int64
+}
+
 type LLMUsageEventRepository interface {
 	Create(ctx context.Context, event *entity.LLMUsageEvent) error
 	GetTokenUsage(ctx context.Context, tenantID string, startInclusive, endExclusive time.Time) (int64, error)
-}
 
+	// List 按条件分页查询租户用量流水（按时间倒序）
+	List(ctx context.Context, tenantID string, filter *LLMUsageEventFilter, pagination Pagination) (*PagedResult[*entity.LLMUsageEvent], error)
+
+	// ListAfter 按 (created_at, id) 升序游标读取用量流水（导出用）：返回排在 (afterCreatedAt, afterID) 之后的至多 limit 条，
+	// afterID 为空时从头读取
+	ListAfter(ctx context.Context, tenantID string, filter *LLMUsageEventFilter, afterCreatedAt time.Time, afterID string, limit int) ([]*entity.LLMUsageEvent, error)
+
+	// Summarize 汇总符合条件的用量流水
+	Summarize(ctx context.Context, tenantID string, filter *LLMUsageEventFilter) (*LLMUsageSummary, error)
+}
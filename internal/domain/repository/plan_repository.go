@@ -0,0 +1,18 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// PlanRepository 订阅套餐仓储接口
+type PlanRepository interface {
+	// GetByID 根据 ID 获取套餐
+	GetByID(ctx context.Context, id string) (*entity.Plan, error)
+	// GetByCode 根据编码获取套餐
+	GetByCode(ctx context.Context, code string) (*entity.Plan, error)
+	// List 获取套餐列表（activeOnly 为 true 时仅返回可订阅套餐）
+	List(ctx context.Context, activeOnly bool) ([]*entity.Plan, error)
+}
@@ -0,0 +1,15 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import "context"
+
+// ProjectLocker 项目级结构锁接口（事务级，事务结束自动释放）
+//
+// 结构性变更（Foundation 落库、卷/章节重排等）持有排他锁；章节生成任务在写回章节前持有共享锁，
+// 保证两者串行执行，避免生成任务以过期的卷/序号覆盖重排结果。
+type ProjectLocker interface {
+	// LockProject 获取项目排他锁（阻塞直至获取）
+	LockProject(ctx context.Context, projectID string) error
+	// LockProjectShared 获取项目共享锁（阻塞直至获取）
+	LockProjectShared(ctx context.Context, projectID string) error
+}
@@ -0,0 +1,16 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// ProjectNoteRepository 项目笔记仓储接口
+type ProjectNoteRepository interface {
+	// Create 创建项目笔记
+	Create(ctx context.Context, note *entity.ProjectNote) error
+	// ListByProject 按导入顺序获取项目的全部笔记
+	ListByProject(ctx context.Context, projectID string) ([]*entity.ProjectNote, error)
+}
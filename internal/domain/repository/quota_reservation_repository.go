@@ -0,0 +1,24 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// QuotaReservationRepository Token 配额预留仓储接口
+type QuotaReservationRepository interface {
+	// Upsert 创建预留；同一任务已存在记录时重置为新的预留（用于重试后重新预留）
+	Upsert(ctx context.Context, reservation *entity.QuotaReservation) error
+	// GetByJobID 获取任务的预留记录
+	GetByJobID(ctx context.Context, jobID string) (*entity.QuotaReservation, error)
+	// SumActive 汇总租户有效预留（status = reserved 且未过期），excludeJobID 非空时排除该任务
+	SumActive(ctx context.Context, tenantID, excludeJobID string) (int64, error)
+	// CountActive 统计租户有效预留数量（即进行中的任务数），excludeJobID 非空时排除该任务
+	CountActive(ctx context.Context, tenantID, excludeJobID string) (int64, error)
+	// Consume 按已记账的实际用量收缩任务的有效预留（不低于 0），避免余额扣减与预留占用重复计算
+	Consume(ctx context.Context, jobID string, tokens int64) error
+	// Finish 将仍处于 reserved 的预留置为终态；返回是否有记录被更新
+	Finish(ctx context.Context, jobID string, status entity.QuotaReservationStatus, settledTokens int64) (bool, error)
+}
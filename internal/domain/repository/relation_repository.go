@@ -48,6 +48,9 @@ type RelationRepository interface {
 	// UpdateStrength 更新关系强度
 	UpdateStrength(ctx context.Context, id string, strength float64) error
 
+	// UpdateReinforcement 写回章节强化结果（仅强度与出现/强化章节列）
+	UpdateReinforcement(ctx context.Context, relation *entity.Relation) error
+
 	// DeleteByEntity 删除实体相关的所有关系
 	DeleteByEntity(ctx context.Context, entityID string) error
 
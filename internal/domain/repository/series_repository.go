@@ -0,0 +1,43 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// SeriesRepository 系列仓储接口
+type SeriesRepository interface {
+	// Create 创建系列
+	Create(ctx context.Context, series *entity.Series) error
+
+	// GetByID 根据 ID 获取系列
+	GetByID(ctx context.Context, id string) (*entity.Series, error)
+
+	// Update 更新系列
+	Update(ctx context.Context, series *entity.Series) error
+
+	// Delete 删除系列（项目保留，仅解除归属）
+	Delete(ctx context.Context, id string) error
+
+	// List 获取系列列表
+	List(ctx context.Context, pagination Pagination) (*PagedResult[*entity.Series], error)
+
+	// ListProjects 获取系列下的项目（按 series_order 升序）
+	ListProjects(ctx context.Context, seriesID string) ([]*entity.Project, error)
+
+	// GetProjectStats 获取系列下各项目的统计信息（按 series_order 升序）
+	GetProjectStats(ctx context.Context, seriesID string) ([]*SeriesProjectStats, error)
+}
+
+// SeriesProjectStats 系列内单个项目的统计信息
+type SeriesProjectStats struct {
+	ProjectID      string `json:"project_id"`
+	Title          string `json:"title"`
+	SeriesOrder    int    `json:"series_order"`
+	Status         string `json:"status"`
+	TotalChapters  int    `json:"total_chapters"`
+	TotalEntities  int    `json:"total_entities"`
+	TotalWordCount int64  `json:"total_word_count"`
+}
@@ -0,0 +1,20 @@
+// Package repository 定义数据访问层接口
+package repository
+
+import (
+	"context"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// SpoilerGuardRepository 剧透保护仓储接口
+type SpoilerGuardRepository interface {
+	// Create 创建剧透保护
+	Create(ctx context.Context, guard *entity.SpoilerGuard) error
+	// GetByID 根据 ID 获取剧透保护
+	GetByID(ctx context.Context, id string) (*entity.SpoilerGuard, error)
+	// Delete 删除剧透保护
+	Delete(ctx context.Context, id string) error
+	// ListByProject 按创建顺序获取项目的全部剧透保护
+	ListByProject(ctx context.Context, projectID string) ([]*entity.SpoilerGuard, error)
+}
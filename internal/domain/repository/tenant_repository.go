@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
 )
@@ -15,6 +16,9 @@ type TenantRepository interface {
 	// GetByID 根据 ID 获取租户
 	GetByID(ctx context.Context, id string) (*entity.Tenant, error)
 
+	// GetByIDForUpdate 根据 ID 获取租户并加行锁（需在事务内调用，用于串行化配额预留）
+	GetByIDForUpdate(ctx context.Context, id string) (*entity.Tenant, error)
+
 	// GetBySlug 根据 Slug 获取租户
 	GetBySlug(ctx context.Context, slug string) (*entity.Tenant, error)
 
@@ -33,6 +37,12 @@ type TenantRepository interface {
 	// ExistsBySlug 检查 Slug 是否存在
 	ExistsBySlug(ctx context.Context, slug string) (bool, error)
 
+	// ListQuotaResetDue 获取配额周期已到期（quota_period_end <= before）且已关联套餐的租户 ID
+	ListQuotaResetDue(ctx context.Context, before time.Time, limit int) ([]string, error)
+
 	// DeductBalance 原子扣除租户余额
 	DeductBalance(ctx context.Context, id string, amount int64) error
+
+	// CreditBalance 原子增加租户余额（充值入账）
+	CreditBalance(ctx context.Context, id string, amount int64) error
 }
@@ -33,6 +33,9 @@ type VolumeRepository interface {
 	// UpdateWordCount 更新字数统计
 	UpdateWordCount(ctx context.Context, id string, wordCount int) error
 
+	// RefreshRollups 按章节重新汇总项目全部卷的字数与章节状态计数（与 chapters 表触发器口径一致）
+	RefreshRollups(ctx context.Context, projectID string) error
+
 	// ReorderVolumes 重新排序卷
 	ReorderVolumes(ctx context.Context, projectID string, volumeIDs []string) error
 
@@ -61,3 +61,39 @@ func ProviderFromContext(ctx context.Context) string {
 	}
 	return strings.TrimSpace(s)
 }
+
+type usageAttributionCtxKey struct{}
+
+// UsageAttribution LLM 调用的归属（项目 / 生成任务），随 context 传递到用量回调写入用量流水
+type UsageAttribution struct {
+	ProjectID string
+	JobID     string
+	JobType   string
+}
+
+// WithUsageAttribution 写入用量归属；与已有归属合并，非空字段覆盖
+func WithUsageAttribution(ctx context.Context, attr UsageAttribution) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	merged := UsageAttributionFromContext(ctx)
+	if v := strings.TrimSpace(attr.ProjectID); v != "" {
+		merged.ProjectID = v
+	}
+	if v := strings.TrimSpace(attr.JobID); v != "" {
+		merged.JobID = v
+	}
+	if v := strings.TrimSpace(attr.JobType); v != "" {
+		merged.JobType = v
+	}
+	return context.WithValue(ctx, usageAttributionCtxKey{}, merged)
+}
+
+// UsageAttributionFromContext 读取用量归属（未设置时为空）
+func UsageAttributionFromContext(ctx context.Context) UsageAttribution {
+	if ctx == nil {
+		return UsageAttribution{}
+	}
+	attr, _ := ctx.Value(usageAttributionCtxKey{}).(UsageAttribution)
+	return attr
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type spendMeterKey struct{}
+
+// SpendMeter 累计一次任务执行中全部模型调用（含修复轮次、提供商切换）的 Token 消耗。
+// 由 Eino 回调写入：非流式调用在 OnEnd 累加，流式调用在回调流读完后累加。
+type SpendMeter struct {
+	prompt     atomic.Int64
+	completion atomic.Int64
+	calls      atomic.Int64
+	pending    sync.WaitGroup
+}
+
+// NewSpendMeter 创建 Token 计量器
+func NewSpendMeter() *SpendMeter {
+	return &SpendMeter{}
+}
+
+// Add 累加一次调用的 Token 消耗
+func (m *SpendMeter) Add(promptTokens, completionTokens int) {
+	if m == nil {
+		return
+	}
+	m.prompt.Add(int64(promptTokens))
+	m.completion.Add(int64(completionTokens))
+	m.calls.Add(1)
+}
+
+// BeginStream 登记一次尚未结束的流式调用，返回的函数在流读完后调用以累加消耗（只能调用一次）
+func (m *SpendMeter) BeginStream() func(promptTokens, completionTokens int) {
+	if m == nil {
+		return func(int, int) {}
+	}
+	m.pending.Add(1)
+	return func(promptTokens, completionTokens int) {
+		m.Add(promptTokens, completionTokens)
+		m.pending.Done()
+	}
+}
+
+// Wait 等待已登记的流式调用完成计量，最多等待 timeout；返回是否全部完成
+func (m *SpendMeter) Wait(timeout time.Duration) bool {
+	if m == nil {
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		m.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Total 已累计的 Token 总量
+func (m *SpendMeter) Total() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.prompt.Load() + m.completion.Load()
+}
+
+// Calls 已计量的模型调用次数
+func (m *SpendMeter) Calls() int {
+	if m == nil {
+		return 0
+	}
+	return int(m.calls.Load())
+}
+
+// WithSpendMeter 在上下文中挂载 Token 计量器
+func WithSpendMeter(ctx context.Context, m *SpendMeter) context.Context {
+	if ctx == nil || m == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spendMeterKey{}, m)
+}
+
+// SpendMeterFromContext 获取上下文中的 Token 计量器（未挂载时返回 nil）
+func SpendMeterFromContext(ctx context.Context) *SpendMeter {
+	if ctx == nil {
+		return nil
+	}
+	m, _ := ctx.Value(spendMeterKey{}).(*SpendMeter)
+	return m
+}
@@ -11,6 +11,11 @@ type LLMUsageInput struct {
 	Provider string
 	Model    string
 
+	// 归属（可选）：见 UsageAttribution
+	ProjectID string
+	JobID     string
+	JobType   string
+
 	PromptTokens     int
 	CompletionTokens int
 	DurationMs       int
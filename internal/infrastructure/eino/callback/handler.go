@@ -2,18 +2,24 @@ package callback
 
 import (
 	"context"
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	einocb "github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
 	cbtemplate "github.com/cloudwego/eino/utils/callbacks"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"z-novel-ai-api/internal/domain/service"
+	"z-novel-ai-api/pkg/deadline"
+	"z-novel-ai-api/pkg/logger"
 	"z-novel-ai-api/pkg/metrics"
 )
 
@@ -32,11 +38,18 @@ func newChatModelCallbackHandler(usageRecorder service.LLMUsageRecorder, tenantI
 			provider := service.ProviderFromContext(ctx)
 			modelName := modelNameFromInput(input)
 
+			// 仅记录 Prompt 规模，不写入消息内容（错误信息经 logger.Redaction 脱敏）
 			attrs := []attribute.KeyValue{
 				attribute.String("eino.workflow", workflow),
 				attribute.String("llm.provider", provider),
 				attribute.String("llm.model", modelName),
 			}
+			if input != nil {
+				attrs = append(attrs,
+					attribute.Int("llm.input_messages", len(input.Messages)),
+					attribute.Int("llm.input_chars", inputChars(input)),
+				)
+			}
 			if info != nil {
 				attrs = append(attrs,
 					attribute.String("eino.node_name", info.Name),
@@ -65,14 +78,21 @@ func newChatModelCallbackHandler(usageRecorder service.LLMUsageRecorder, tenantI
 				metrics.LLMTokensUsed.WithLabelValues(workflow, provider, modelName, "prompt").Add(float64(promptTokens))
 				metrics.LLMTokensUsed.WithLabelValues(workflow, provider, modelName, "completion").Add(float64(completionTokens))
 
+				// 单任务累计消耗（含修复轮次与提供商切换），供任务成本上限判定
+				service.SpendMeterFromContext(ctx).Add(promptTokens, completionTokens)
+
 				// 扣费/流水：从 callbacks 中解耦到应用层（quota），这里仅做 best-effort 调用。
 				if usageRecorder != nil && tenantIDGetter != nil {
 					tenantID, _ := tenantIDGetter.GetCurrentTenant(ctx)
+					attr := service.UsageAttributionFromContext(ctx)
 					_ = usageRecorder.Record(ctx, service.LLMUsageInput{
 						TenantID:         tenantID,
 						Workflow:         workflow,
 						Provider:         provider,
 						Model:            modelName,
+						ProjectID:        attr.ProjectID,
+						JobID:            attr.JobID,
+						JobType:          attr.JobType,
 						PromptTokens:     promptTokens,
 						CompletionTokens: completionTokens,
 						DurationMs:       int(elapsedSeconds(ctx) * 1000),
@@ -93,6 +113,32 @@ func newChatModelCallbackHandler(usageRecorder service.LLMUsageRecorder, tenantI
 			return ctx
 		},
 
+		// 流式调用仅用于单任务消耗计量：读完回调流后取各分片中最大的用量（提供商通常只在末尾分片上报累计用量）
+		OnEndWithStreamOutput: func(ctx context.Context, _ *einocb.RunInfo, output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+			meter := service.SpendMeterFromContext(ctx)
+			if meter == nil {
+				output.Close()
+				return ctx
+			}
+			done := meter.BeginStream()
+			go func() {
+				defer output.Close()
+				var promptTokens, completionTokens int
+				for {
+					chunk, err := output.Recv()
+					if err != nil {
+						break
+					}
+					if chunk != nil && chunk.TokenUsage != nil {
+						promptTokens = max(promptTokens, chunk.TokenUsage.PromptTokens)
+						completionTokens = max(completionTokens, chunk.TokenUsage.CompletionTokens)
+					}
+				}
+				done(promptTokens, completionTokens)
+			}()
+			return ctx
+		},
+
 		OnError: func(ctx context.Context, info *einocb.RunInfo, err error) context.Context {
 			workflow := service.WorkflowFromContext(ctx)
 			provider := service.ProviderFromContext(ctx)
@@ -102,14 +148,14 @@ func newChatModelCallbackHandler(usageRecorder service.LLMUsageRecorder, tenantI
 			}
 
 			metrics.LLMCallTotal.WithLabelValues(workflow, provider, modelName, "error").Inc()
+			deadline.Observe(ctx, deadline.LLM, err)
 			if d := elapsedSeconds(ctx); d > 0 {
 				metrics.LLMCallDuration.WithLabelValues(workflow, provider, modelName).Observe(d)
 			}
 
 			span := trace.SpanFromContext(ctx)
 			if span != nil {
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
+				recordError(span, err)
 				span.End()
 			}
 			return ctx
@@ -117,6 +163,16 @@ func newChatModelCallbackHandler(usageRecorder service.LLMUsageRecorder, tenantI
 	}
 }
 
+// newEmbeddingCallbackHandler 向量化调用因请求截止时间超时时上报 embedding 依赖
+func newEmbeddingCallbackHandler() *cbtemplate.EmbeddingCallbackHandler {
+	return &cbtemplate.EmbeddingCallbackHandler{
+		OnError: func(ctx context.Context, _ *einocb.RunInfo, err error) context.Context {
+			deadline.Observe(ctx, deadline.Embedding, err)
+			return ctx
+		},
+	}
+}
+
 func newToolCallbackHandler() *cbtemplate.ToolCallbackHandler {
 	return &cbtemplate.ToolCallbackHandler{
 		OnStart: func(ctx context.Context, info *einocb.RunInfo, _ *tool.CallbackInput) context.Context {
@@ -170,8 +226,7 @@ func newToolCallbackHandler() *cbtemplate.ToolCallbackHandler {
 
 			span := trace.SpanFromContext(ctx)
 			if span != nil {
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
+				recordError(span, err)
 				span.End()
 			}
 			return ctx
@@ -179,6 +234,29 @@ func newToolCallbackHandler() *cbtemplate.ToolCallbackHandler {
 	}
 }
 
+// recordError 以脱敏后的错误信息记录 exception 事件与 Span 状态（提供商错误可能回显 Prompt 片段）
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	msg := logger.Redaction().Text(err.Error())
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+		semconv.ExceptionType(fmt.Sprintf("%T", err)),
+		semconv.ExceptionMessage(msg),
+	))
+	span.SetStatus(codes.Error, msg)
+}
+
+func inputChars(in *model.CallbackInput) int {
+	n := 0
+	for _, m := range in.Messages {
+		if m != nil {
+			n += utf8.RuneCountInString(m.Content)
+		}
+	}
+	return n
+}
+
 func elapsedSeconds(ctx context.Context) float64 {
 	v := ctx.Value(startTimeKey{})
 	start, ok := v.(time.Time)
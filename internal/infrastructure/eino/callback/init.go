@@ -17,6 +17,7 @@ func Init(usageRecorder service.LLMUsageRecorder, tenantIDGetter TenantIDGetter)
 		handler := cbtemplate.NewHandlerHelper().
 			ChatModel(newChatModelCallbackHandler(usageRecorder, tenantIDGetter)).
 			Tool(newToolCallbackHandler()).
+			Embedding(newEmbeddingCallbackHandler()).
 			Handler()
 		einocallbacks.AppendGlobalHandlers(handler)
 	})
@@ -11,7 +11,11 @@ import (
 )
 
 // NewEinoEmbedder 创建基于 Eino 的 Embedder
+// provider 为 fake 时返回本地确定性 Embedder（见 FakeEmbedder）。
 func NewEinoEmbedder(ctx context.Context, cfg *config.EmbeddingConfig) (embedding.Embedder, error) {
+	if cfg.Provider == ProviderFake {
+		return NewFakeEmbedder(cfg.Dimension), nil
+	}
 	if cfg.Endpoint == "" {
 		return nil, fmt.Errorf("embedding endpoint is required")
 	}
@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"unicode"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// ProviderFake 本地确定性 Embedder（不调用外部服务），用于演示数据与无密钥的本地开发
+const ProviderFake = "fake"
+
+// FakeEmbedder 基于字符 unigram/bigram 特征哈希的确定性 Embedder。
+// 相同文本得到相同向量，字面相近的文本向量也相近，足以演示检索链路，但不具备语义能力。
+type FakeEmbedder struct {
+	dimension int
+}
+
+// NewFakeEmbedder 创建确定性 Embedder
+func NewFakeEmbedder(dimension int) *FakeEmbedder {
+	if dimension <= 0 {
+		dimension = 1024
+	}
+	return &FakeEmbedder{dimension: dimension}
+}
+
+// EmbedStrings 实现 embedding.Embedder
+func (e *FakeEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = e.embed(text)
+	}
+	return out, nil
+}
+
+func (e *FakeEmbedder) embed(text string) []float64 {
+	vec := make([]float64, e.dimension)
+	var prev rune
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			prev = 0
+			continue
+		}
+		r = unicode.ToLower(r)
+		e.add(vec, string(r), 1)
+		if prev != 0 {
+			e.add(vec, string([]rune{prev, r}), 2)
+		}
+		prev = r
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		vec[0] = 1
+		return vec
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
+
+// add 将特征哈希到某一维；哈希高位决定符号以降低碰撞偏差
+func (e *FakeEmbedder) add(vec []float64, feature string, weight float64) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(feature))
+	sum := h.Sum32()
+	if sum&0x80000000 != 0 {
+		weight = -weight
+	}
+	vec[int(sum&0x7fffffff)%e.dimension] += weight
+}
@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+// CircuitState 熔断器状态
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitHalfOpen CircuitState = "half_open"
+	CircuitOpen     CircuitState = "open"
+)
+
+// ErrCircuitOpen 提供商熔断且没有可用的备用提供商
+var ErrCircuitOpen = errors.New("llm circuit breaker is open")
+
+// gaugeValue 熔断状态的指标值
+func (s CircuitState) gaugeValue() float64 {
+	switch s {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// BreakerStatus 熔断器状态快照（用于运维接口）
+type BreakerStatus struct {
+	Provider    string       `json:"provider"`
+	Model       string       `json:"model"`
+	State       CircuitState `json:"state"`
+	Requests    int          `json:"requests"`
+	Failures    int          `json:"failures"`
+	FailureRate float64      `json:"failure_rate"`
+	OpenedAt    *time.Time   `json:"opened_at,omitempty"`
+	RetryAt     *time.Time   `json:"retry_at,omitempty"`
+	LastError   string       `json:"last_error,omitempty"`
+}
+
+// circuitBreaker 单个提供商 + 模型的熔断器：滑动窗口记录最近调用结果，失败率超过阈值即熔断；
+// 熔断期满后进入半开状态，放行有限的探测请求，全部成功才恢复，任一失败重新熔断。
+type circuitBreaker struct {
+	cfg      config.CircuitBreakerConfig
+	provider string
+	model    string
+
+	mu       sync.Mutex
+	state    CircuitState
+	window   []bool // true 表示失败
+	next     int
+	size     int
+	failures int
+	openedAt time.Time
+	lastErr  string
+
+	// 半开状态：已放行的探测数、成功数及放行时间（探测结果丢失时按 OpenDuration 过期重放）
+	probes       int
+	probeOK      int
+	probeStarted time.Time
+}
+
+func newCircuitBreaker(cfg config.CircuitBreakerConfig, provider, model string) *circuitBreaker {
+	b := &circuitBreaker{
+		cfg:      cfg,
+		provider: provider,
+		model:    model,
+		state:    CircuitClosed,
+		window:   make([]bool, cfg.Window),
+	}
+	metrics.LLMCircuitState.WithLabelValues(provider, model).Set(CircuitClosed.gaugeValue())
+	return b
+}
+
+// allow 判断是否放行本次调用；放行时返回的 done 必须以调用结果调用一次
+func (b *circuitBreaker) allow() (done func(ctx context.Context, err error), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.cfg.OpenDuration {
+			return nil, false
+		}
+		b.setState(CircuitHalfOpen)
+		b.probes, b.probeOK = 0, 0
+	case CircuitHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			if now.Sub(b.probeStarted) < b.cfg.OpenDuration {
+				return nil, false
+			}
+			b.probes, b.probeOK = 0, 0
+		}
+	default:
+		return b.doneFunc(false), true
+	}
+
+	b.probes++
+	b.probeStarted = now
+	return b.doneFunc(true), true
+}
+
+func (b *circuitBreaker) doneFunc(probe bool) func(ctx context.Context, err error) {
+	var once sync.Once
+	return func(ctx context.Context, err error) {
+		once.Do(func() { b.record(probe, classifyOutcome(ctx, err), err) })
+	}
+}
+
+// record 记录调用结果；outcomeIgnored 不计入窗口
+func (b *circuitBreaker) record(probe bool, outcome callOutcome, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if outcome == outcomeFailure && err != nil {
+		b.lastErr = err.Error()
+	}
+
+	if probe {
+		if b.state != CircuitHalfOpen {
+			return
+		}
+		switch outcome {
+		case outcomeIgnored:
+			// 探测未得出结论：释放名额，允许下一次探测
+			b.probes--
+		case outcomeFailure:
+			b.trip()
+		default:
+			b.probeOK++
+			if b.probeOK >= b.cfg.HalfOpenProbes {
+				b.reset()
+			}
+		}
+		return
+	}
+
+	if outcome == outcomeIgnored || b.state != CircuitClosed {
+		return
+	}
+	failed := outcome == outcomeFailure
+	if b.size == len(b.window) && b.window[b.next] {
+		b.failures--
+	}
+	b.window[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.window)
+	if b.size < len(b.window) {
+		b.size++
+	}
+	if b.size >= b.cfg.MinRequests && float64(b.failures)/float64(b.size) >= b.cfg.FailureRate {
+		b.trip()
+	}
+}
+
+func (b *circuitBreaker) trip() {
+	b.openedAt = time.Now()
+	b.probes, b.probeOK = 0, 0
+	b.setState(CircuitOpen)
+}
+
+func (b *circuitBreaker) reset() {
+	for i := range b.window {
+		b.window[i] = false
+	}
+	b.next, b.size, b.failures = 0, 0, 0
+	b.probes, b.probeOK = 0, 0
+	b.lastErr = ""
+	b.setState(CircuitClosed)
+}
+
+func (b *circuitBreaker) setState(s CircuitState) {
+	b.state = s
+	metrics.LLMCircuitState.WithLabelValues(b.provider, b.model).Set(s.gaugeValue())
+}
+
+func (b *circuitBreaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := BreakerStatus{
+		Provider:  b.provider,
+		Model:     b.model,
+		State:     b.state,
+		Requests:  b.size,
+		Failures:  b.failures,
+		LastError: b.lastErr,
+	}
+	if b.size > 0 {
+		st.FailureRate = float64(b.failures) / float64(b.size)
+	}
+	if b.state == CircuitOpen {
+		opened := b.openedAt.UTC()
+		retry := opened.Add(b.cfg.OpenDuration)
+		st.OpenedAt, st.RetryAt = &opened, &retry
+	}
+	return st
+}
+
+// statusCodePattern 匹配 OpenAI 兼容客户端错误信息中的 HTTP 状态码
+var statusCodePattern = regexp.MustCompile(`status code: (\d{3})`)
+
+// callOutcome 单次调用对提供商健康度的结论
+type callOutcome int
+
+const (
+	outcomeSuccess callOutcome = iota
+	outcomeFailure
+	// outcomeIgnored 结果不反映提供商健康度（调用方取消、请求本身有误）
+	outcomeIgnored
+)
+
+// classifyOutcome 判断调用结果：调用方取消或超出调用方截止时间、4xx 请求错误（408/429 除外）不计入；
+// 超时、网络错误、5xx、限流等计为失败。
+func classifyOutcome(ctx context.Context, err error) callOutcome {
+	if err == nil {
+		return outcomeSuccess
+	}
+	if ctx != nil && ctx.Err() != nil {
+		return outcomeIgnored
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return outcomeFailure
+	}
+	if m := statusCodePattern.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		if code >= 400 && code < 500 && code != 408 && code != 429 {
+			return outcomeIgnored
+		}
+	}
+	return outcomeFailure
+}
@@ -0,0 +1,387 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+	"unsafe"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"z-novel-ai-api/internal/config"
+	llmctx "z-novel-ai-api/internal/domain/service"
+)
+
+const cassetteModelType = "Cassette"
+
+// ErrCassetteMiss 回放模式下未找到与请求匹配的录制
+var ErrCassetteMiss = errors.New("llm cassette miss")
+
+// CassetteChatModel 录制/回放包装器（VCR 风格）：
+// record 模式调用真实模型并把响应（完整消息或流式分片，含工具调用与错误）写入夹具文件；
+// replay 模式仅按请求指纹读取夹具，回放时同样触发 Eino 回调，保证计量链路一致。
+// 录制的错误会原样回放，因此 response_format / 工具绑定失败后的降级与修复路径也能被覆盖。
+type CassetteChatModel struct {
+	inner     model.BaseChatModel
+	provider  string
+	model     string
+	maxTokens int
+	mode      string
+	dir       string
+	tools     []string
+}
+
+// cassetteRequest 参与指纹计算的请求要素（不含 ToolCall ID 等每次调用都会变化的字段）
+type cassetteRequest struct {
+	Workflow    string            `json:"workflow"`
+	Provider    string            `json:"provider"`
+	Model       string            `json:"model"`
+	Temperature float32           `json:"temperature"`
+	MaxTokens   int               `json:"max_tokens"`
+	Stream      bool              `json:"stream"`
+	Tools       []string          `json:"tools,omitempty"`
+	Options     map[string]any    `json:"options,omitempty"`
+	Messages    []cassetteMessage `json:"messages"`
+}
+
+type cassetteMessage struct {
+	Role      schema.RoleType    `json:"role"`
+	Content   string             `json:"content"`
+	ToolName  string             `json:"tool_name,omitempty"`
+	ToolCalls []cassetteToolCall `json:"tool_calls,omitempty"`
+}
+
+type cassetteToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// cassetteFixture 夹具文件内容
+type cassetteFixture struct {
+	Key        string            `json:"key"`
+	Request    cassetteRequest   `json:"request"`
+	Response   *schema.Message   `json:"response,omitempty"`
+	Chunks     []*schema.Message `json:"chunks,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+}
+
+// NewCassetteChatModel 创建录制/回放包装器
+func NewCassetteChatModel(inner model.BaseChatModel, provider string, providerCfg config.ProviderConfig, cfg config.LLMCassetteConfig) *CassetteChatModel {
+	return &CassetteChatModel{
+		inner:     inner,
+		provider:  provider,
+		model:     providerCfg.Model,
+		maxTokens: providerCfg.MaxTokens,
+		mode:      cfg.Mode,
+		dir:       cfg.Dir,
+	}
+}
+
+// Generate 实现 model.BaseChatModel
+func (m *CassetteChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req, cfg := m.request(ctx, in, false, opts)
+	key := cassetteKey(req)
+
+	fixture, err := m.load(req.Workflow, key)
+	if err != nil {
+		return nil, err
+	}
+	if fixture != nil {
+		if fixture.Error != "" {
+			return nil, errors.New(fixture.Error)
+		}
+		return generateWithCallbacks(ctx, m.GetType(), in, cfg, fixture.Response), nil
+	}
+	if m.mode == config.CassetteModeReplay {
+		return nil, fmt.Errorf("%w: workflow=%s key=%s", ErrCassetteMiss, req.Workflow, key)
+	}
+
+	out, genErr := m.inner.Generate(ctx, in, opts...)
+	fixture = &cassetteFixture{Key: key, Request: req, Response: out, RecordedAt: time.Now().UTC()}
+	if genErr != nil {
+		fixture.Response = nil
+		fixture.Error = genErr.Error()
+	}
+	if err := m.save(fixture); err != nil {
+		return nil, err
+	}
+	return out, genErr
+}
+
+// Stream 实现 model.BaseChatModel：录制时旁路收集分片，流读取完毕后落盘
+func (m *CassetteChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	req, cfg := m.request(ctx, in, true, opts)
+	key := cassetteKey(req)
+
+	fixture, err := m.load(req.Workflow, key)
+	if err != nil {
+		return nil, err
+	}
+	if fixture != nil {
+		if fixture.Error != "" {
+			return nil, errors.New(fixture.Error)
+		}
+		return streamWithCallbacks(ctx, m.GetType(), in, cfg, fixture.Chunks, 0), nil
+	}
+	if m.mode == config.CassetteModeReplay {
+		return nil, fmt.Errorf("%w: workflow=%s key=%s", ErrCassetteMiss, req.Workflow, key)
+	}
+
+	fixture = &cassetteFixture{Key: key, Request: req, RecordedAt: time.Now().UTC()}
+	sr, streamErr := m.inner.Stream(ctx, in, opts...)
+	if streamErr != nil {
+		fixture.Error = streamErr.Error()
+		if err := m.save(fixture); err != nil {
+			return nil, err
+		}
+		return nil, streamErr
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sr.Close()
+		defer sw.Close()
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				// 仅在完整读取后落盘，避免中断的流产生残缺夹具
+				_ = m.save(fixture)
+				return
+			}
+			if err != nil {
+				sw.Send(nil, err)
+				return
+			}
+			fixture.Chunks = append(fixture.Chunks, chunk)
+			if closed := sw.Send(chunk, nil); closed {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// WithTools 实现 model.ToolCallingChatModel：绑定工具后的模型同样被包装，工具名参与指纹
+func (m *CassetteChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		if t != nil {
+			names = append(names, t.Name)
+		}
+	}
+	sort.Strings(names)
+
+	next := *m
+	next.tools = names
+	if tcm, ok := m.inner.(model.ToolCallingChatModel); ok {
+		inner, err := tcm.WithTools(tools)
+		if err != nil {
+			return nil, err
+		}
+		next.inner = inner
+	}
+	return &next, nil
+}
+
+// GetType 返回组件类型（用于回调 RunInfo）
+func (m *CassetteChatModel) GetType() string {
+	return cassetteModelType
+}
+
+// IsCallbacksEnabled 回放时由包装器触发回调；录制时由内部模型触发
+func (m *CassetteChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+func (m *CassetteChatModel) request(ctx context.Context, in []*schema.Message, stream bool, opts []model.Option) (cassetteRequest, *model.Config) {
+	cfg := modelConfig(m.model, m.maxTokens, opts)
+	req := cassetteRequest{
+		Workflow:    llmctx.WorkflowFromContext(ctx),
+		Provider:    m.provider,
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		MaxTokens:   cfg.MaxTokens,
+		Stream:      stream,
+		Tools:       m.tools,
+		Options:     resolvedOptions(opts),
+		Messages:    make([]cassetteMessage, 0, len(in)),
+	}
+	if req.Workflow == "" {
+		req.Workflow = "default"
+	}
+	for _, msg := range in {
+		if msg == nil {
+			continue
+		}
+		cm := cassetteMessage{Role: msg.Role, Content: msg.Content, ToolName: msg.ToolName}
+		for _, tc := range msg.ToolCalls {
+			cm.ToolCalls = append(cm.ToolCalls, cassetteToolCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+		}
+		req.Messages = append(req.Messages, cm)
+	}
+	return req, cfg
+}
+
+// resolvedOptions 解析调用选项的实际取值参与指纹（Model/Temperature/MaxTokens 已单独记录）：
+// 通用选项直接读取，提供商特定选项（如 openai.WithExtraFields 携带的 response_format）按选项类型聚合后取其导出字段，
+// 因此结构化输出与降级调用、不同 JSON Schema 的调用会落到不同夹具
+func resolvedOptions(opts []model.Option) map[string]any {
+	out := make(map[string]any)
+	common := model.GetCommonOptions(nil, opts...)
+	if common.TopP != nil {
+		out["top_p"] = *common.TopP
+	}
+	if len(common.Stop) > 0 {
+		out["stop"] = common.Stop
+	}
+	if common.ToolChoice != nil {
+		out["tool_choice"] = *common.ToolChoice
+	}
+	if len(common.AllowedToolNames) > 0 {
+		out["allowed_tools"] = common.AllowedToolNames
+	}
+	if len(common.Tools) > 0 {
+		names := make([]string, 0, len(common.Tools))
+		for _, t := range common.Tools {
+			if t != nil {
+				names = append(names, t.Name)
+			}
+		}
+		sort.Strings(names)
+		out["tools"] = names
+	}
+	for name, values := range implSpecificOptions(opts) {
+		out[name] = values
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// implSpecificOptions 回放提供商特定选项：Eino 仅支持按具体类型读取（GetImplSpecificOptions[T]），
+// 而提供商的选项类型未导出，这里经反射取出选项函数，作用于同类型的零值后导出其字段（函数/通道类字段不参与指纹）
+func implSpecificOptions(opts []model.Option) map[string]any {
+	bases := make(map[reflect.Type]reflect.Value)
+	for i := range opts {
+		field := reflect.ValueOf(&opts[i]).Elem().FieldByName("implSpecificOptFn")
+		if !field.IsValid() || field.Kind() != reflect.Interface || field.IsNil() {
+			continue
+		}
+		fn := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Elem()
+		if fn.Kind() != reflect.Func || fn.IsNil() || fn.Type().NumIn() != 1 || fn.Type().NumOut() != 0 || fn.Type().In(0).Kind() != reflect.Pointer {
+			continue
+		}
+		typ := fn.Type().In(0).Elem()
+		base, ok := bases[typ]
+		if !ok {
+			base = reflect.New(typ)
+			bases[typ] = base
+		}
+		fn.Call([]reflect.Value{base})
+	}
+
+	out := make(map[string]any, len(bases))
+	for typ, base := range bases {
+		v := base.Elem()
+		if typ.Kind() != reflect.Struct {
+			if fingerprintable(v) {
+				out[typ.String()] = v.Interface()
+			}
+			continue
+		}
+		fields := make(map[string]any)
+		for i := 0; i < typ.NumField(); i++ {
+			if f := typ.Field(i); f.IsExported() && fingerprintable(v.Field(i)) {
+				fields[f.Name] = v.Field(i).Interface()
+			}
+		}
+		if len(fields) > 0 {
+			out[typ.String()] = fields
+		}
+	}
+	return out
+}
+
+func fingerprintable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	}
+	return !v.IsZero()
+}
+
+func cassetteKey(req cassetteRequest) string {
+	raw, _ := json.Marshal(req)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:16])
+}
+
+func (m *CassetteChatModel) path(workflow, key string) string {
+	return filepath.Join(m.dir, workflow, key+".json")
+}
+
+// load 读取夹具；record 模式总是重新录制，不存在时返回 nil
+func (m *CassetteChatModel) load(workflow, key string) (*cassetteFixture, error) {
+	if m.mode == config.CassetteModeRecord {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(m.path(workflow, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read llm cassette: %w", err)
+	}
+	var fixture cassetteFixture
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode llm cassette %s: %w", key, err)
+	}
+	return &fixture, nil
+}
+
+// save 先写临时文件再重命名，避免并发录制时读到半截夹具
+func (m *CassetteChatModel) save(fixture *cassetteFixture) error {
+	path := m.path(fixture.Request.Workflow, fixture.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create llm cassette dir: %w", err)
+	}
+	raw, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode llm cassette: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return fmt.Errorf("failed to write llm cassette: %w", err)
+	}
+	// 夹具随代码提交，使用常规文件权限（CreateTemp 默认 0600）
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write llm cassette: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write llm cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write llm cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write llm cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	openaiopts "github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"z-novel-ai-api/internal/config"
+	llmctx "z-novel-ai-api/internal/domain/service"
+)
+
+// countingModel 记录调用次数的内部模型：按调用顺序返回 replies（以 "error:" 开头的条目作为错误返回）
+type countingModel struct {
+	replies []string
+	calls   int
+}
+
+func (m *countingModel) next() (*schema.Message, error) {
+	reply := m.replies[m.calls%len(m.replies)]
+	m.calls++
+	if msg, ok := strings.CutPrefix(reply, "error:"); ok {
+		return nil, errors.New(msg)
+	}
+	return schema.AssistantMessage(reply, nil), nil
+}
+
+func (m *countingModel) Generate(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	return m.next()
+}
+
+func (m *countingModel) Stream(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.next()
+	if err != nil {
+		return nil, err
+	}
+	half := len(msg.Content) / 2
+	return schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage(msg.Content[:half], nil),
+		schema.AssistantMessage(msg.Content[half:], nil),
+	}), nil
+}
+
+func responseFormat(name string) model.Option {
+	return openaiopts.WithExtraFields(map[string]any{
+		"response_format": map[string]any{
+			"type":        "json_schema",
+			"json_schema": map[string]any{"name": name, "schema": map[string]any{"type": "object"}},
+		},
+	})
+}
+
+func TestCassetteKeyUsesResolvedOptionValues(t *testing.T) {
+	m := &CassetteChatModel{provider: "openai", model: "gpt"}
+	ctx := llmctx.WithWorkflowProvider(context.Background(), "artifact_generate", "openai")
+	in := []*schema.Message{schema.UserMessage("生成构件")}
+	key := func(opts ...model.Option) string {
+		req, _ := m.request(ctx, in, false, opts)
+		return cassetteKey(req)
+	}
+
+	if key(responseFormat("a")) != key(responseFormat("a")) {
+		t.Fatal("equal option values should produce the same key")
+	}
+	// 选项个数相同但取值不同：不同的 JSON Schema、结构化输出与其他提供商选项都应区分
+	distinct := map[string]string{
+		"no options":        key(),
+		"schema a":          key(responseFormat("a")),
+		"schema b":          key(responseFormat("b")),
+		"reasoning effort":  key(openaiopts.WithReasoningEffort(openaiopts.ReasoningEffortLevelHigh)),
+		"top p":             key(model.WithTopP(0.5)),
+		"stop words":        key(model.WithStop([]string{"END"})),
+		"schema a + effort": key(responseFormat("a"), openaiopts.WithReasoningEffort(openaiopts.ReasoningEffortLevelHigh)),
+	}
+	seen := make(map[string]string, len(distinct))
+	for name, k := range distinct {
+		if other, dup := seen[k]; dup {
+			t.Fatalf("%q and %q produced the same key", name, other)
+		}
+		seen[k] = name
+	}
+	// 后出现的同类选项覆盖前者，与提供商实际生效的取值一致
+	if key(responseFormat("a"), responseFormat("b")) != key(responseFormat("b")) {
+		t.Fatal("later option should override earlier one of the same type")
+	}
+	// Model/Temperature/MaxTokens 已单独记录，显式传入默认值不改变指纹
+	if key(model.WithModel("gpt")) != key() {
+		t.Fatal("explicit default model should not change the key")
+	}
+}
+
+func TestCassetteRecordsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	ctx := llmctx.WithWorkflowProvider(context.Background(), "chapter_gen", "openai")
+	providerCfg := config.ProviderConfig{Model: "gpt", MaxTokens: 100}
+	in := []*schema.Message{schema.UserMessage("写一章")}
+
+	inner := &countingModel{replies: []string{"雨夜，码头的灯一盏盏熄灭。", "error:response_format json_schema is not supported"}}
+	recorder := NewCassetteChatModel(inner, "openai", providerCfg, config.LLMCassetteConfig{Mode: config.CassetteModeRecord, Dir: dir})
+	want, err := recorder.Generate(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.Generate(ctx, in, responseFormat("chapter")); err == nil {
+		t.Fatal("expected the recorded provider error")
+	}
+	inner.replies = []string{"第二章：潮声"}
+	sr, err := recorder.Stream(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := sr.Recv(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sr.Close()
+
+	// 回放不访问内部模型
+	replayer := NewCassetteChatModel(nil, "openai", providerCfg, config.LLMCassetteConfig{Mode: config.CassetteModeReplay, Dir: dir})
+	got, err := replayer.Generate(ctx, in)
+	if err != nil || got.Content != want.Content {
+		t.Fatalf("replayed Generate = %v, %v; want %q", got, err, want.Content)
+	}
+	if _, err := replayer.Generate(ctx, in, responseFormat("chapter")); err == nil || !strings.Contains(err.Error(), "response_format") {
+		t.Fatalf("replayed error = %v, want recorded response_format error", err)
+	}
+	if _, err := replayer.Generate(ctx, in, responseFormat("other")); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("different schema err = %v, want ErrCassetteMiss", err)
+	}
+
+	sr, err = replayer.Stream(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []string
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk.Content)
+	}
+	if len(chunks) != 2 || strings.Join(chunks, "") != "第二章：潮声" {
+		t.Fatalf("replayed stream chunks = %q", chunks)
+	}
+	if inner.calls != 3 {
+		t.Fatalf("inner model calls = %d, want 3 (record only)", inner.calls)
+	}
+}
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"z-novel-ai-api/internal/config"
@@ -16,22 +17,109 @@ type EinoFactory struct {
 	config *config.LLMConfig
 	models map[string]model.BaseChatModel
 	mu     sync.RWMutex
+
+	// breakers 按 提供商 + 模型 惰性创建的熔断器
+	breakers  map[breakerKey]*circuitBreaker
+	breakerMu sync.Mutex
+}
+
+type breakerKey struct {
+	provider string
+	model    string
+}
+
+// ProviderStatus 提供商配置与熔断状态（不含密钥）
+type ProviderStatus struct {
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Model     string          `json:"model"`
+	Default   bool            `json:"default"`
+	Fallbacks []string        `json:"fallbacks"`
+	Breakers  []BreakerStatus `json:"breakers"`
 }
 
 // NewEinoFactory 创建 Eino LLM 工厂
 func NewEinoFactory(cfg *config.Config) *EinoFactory {
 	return &EinoFactory{
-		config: &cfg.LLM,
-		models: make(map[string]model.BaseChatModel),
+		config:   &cfg.LLM,
+		models:   make(map[string]model.BaseChatModel),
+		breakers: make(map[breakerKey]*circuitBreaker),
 	}
 }
 
-// Get 获取指定名称的 ChatModel，如果未指定则返回默认客户端
+// Get 获取指定名称的 ChatModel，如果未指定则返回默认客户端；启用熔断时返回熔断包装器
 func (f *EinoFactory) Get(ctx context.Context, name string) (model.BaseChatModel, error) {
 	if name == "" {
 		name = f.config.DefaultProvider
 	}
+	m, err := f.base(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.config.CircuitBreaker.Enabled {
+		return m, nil
+	}
+	return &FailoverChatModel{factory: f, provider: name, inner: m}, nil
+}
+
+// CircuitBreakerEnabled 是否启用熔断
+func (f *EinoFactory) CircuitBreakerEnabled() bool {
+	return f.config.CircuitBreaker.Enabled
+}
+
+// Providers 返回所有提供商的配置摘要与熔断状态（按名称排序）
+func (f *EinoFactory) Providers() []ProviderStatus {
+	names := make([]string, 0, len(f.config.Providers))
+	for name := range f.config.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f.breakerMu.Lock()
+	byProvider := make(map[string][]*circuitBreaker, len(f.breakers))
+	for key, b := range f.breakers {
+		byProvider[key.provider] = append(byProvider[key.provider], b)
+	}
+	f.breakerMu.Unlock()
 
+	out := make([]ProviderStatus, 0, len(names))
+	for _, name := range names {
+		p := f.config.Providers[name]
+		st := ProviderStatus{
+			Name:      name,
+			Type:      p.Type,
+			Model:     p.Model,
+			Default:   name == f.config.DefaultProvider,
+			Fallbacks: append([]string{}, p.Fallbacks...),
+			Breakers:  make([]BreakerStatus, 0, len(byProvider[name])),
+		}
+		if st.Type == "" {
+			st.Type = config.ProviderTypeOpenAI
+		}
+		for _, b := range byProvider[name] {
+			st.Breakers = append(st.Breakers, b.status())
+		}
+		sort.Slice(st.Breakers, func(i, j int) bool { return st.Breakers[i].Model < st.Breakers[j].Model })
+		out = append(out, st)
+	}
+	return out
+}
+
+// breaker 获取（必要时创建）提供商 + 模型的熔断器
+func (f *EinoFactory) breaker(provider, modelName string) *circuitBreaker {
+	key := breakerKey{provider: provider, model: modelName}
+	f.breakerMu.Lock()
+	defer f.breakerMu.Unlock()
+	b, ok := f.breakers[key]
+	if !ok {
+		b = newCircuitBreaker(f.config.CircuitBreaker, provider, modelName)
+		f.breakers[key] = b
+	}
+	return b
+}
+
+// base 获取未经熔断包装的 ChatModel（惰性创建并缓存）
+func (f *EinoFactory) base(ctx context.Context, name string) (model.BaseChatModel, error) {
 	f.mu.RLock()
 	m, ok := f.models[name]
 	f.mu.RUnlock()
@@ -53,17 +141,28 @@ func (f *EinoFactory) Get(ctx context.Context, name string) (model.BaseChatModel
 		return nil, fmt.Errorf("provider %s not found in LLM config", name)
 	}
 
-	// 使用 Eino 的 OpenAI 适配器
-	chatModel, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		APIKey:      providerCfg.APIKey,
-		BaseURL:     providerCfg.BaseURL,
-		Model:       providerCfg.Model,
-		MaxTokens:   &providerCfg.MaxTokens,
-		Temperature: ptrFloat32(float32(providerCfg.Temperature)),
-		Timeout:     providerCfg.Timeout,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create eino chat model for %s: %w", name, err)
+	var chatModel model.BaseChatModel
+	if providerCfg.Type == config.ProviderTypeMock {
+		chatModel = NewMockChatModel(providerCfg)
+	} else {
+		// 使用 Eino 的 OpenAI 适配器
+		openaiModel, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
+			APIKey:      providerCfg.APIKey,
+			BaseURL:     providerCfg.BaseURL,
+			Model:       providerCfg.Model,
+			MaxTokens:   &providerCfg.MaxTokens,
+			Temperature: ptrFloat32(float32(providerCfg.Temperature)),
+			Timeout:     providerCfg.Timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create eino chat model for %s: %w", name, err)
+		}
+		chatModel = openaiModel
+	}
+
+	// 录制/回放：包装真实或模拟模型，使 CI 中的调用结果确定
+	if mode := f.config.Cassette.Mode; mode != "" && mode != config.CassetteModeOff {
+		chatModel = NewCassetteChatModel(chatModel, name, providerCfg, f.config.Cassette)
 	}
 
 	f.models[name] = chatModel
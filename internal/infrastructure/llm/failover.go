@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	llmctx "z-novel-ai-api/internal/domain/service"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+const failoverModelType = "Failover"
+
+// FailoverChatModel 熔断包装器：调用前检查 提供商 + 实际模型 的熔断器，
+// 熔断期间不再等待超时，直接改用提供商配置的 Fallbacks 中第一个未熔断的提供商（使用其默认模型）；
+// 备用提供商的调用以其名称写入 LLM 上下文，计量与计费按实际提供商归属。
+type FailoverChatModel struct {
+	factory  *EinoFactory
+	provider string
+	inner    model.BaseChatModel
+	// tools 已绑定的工具，切换到备用提供商时重新绑定
+	tools []*schema.ToolInfo
+}
+
+// Generate 实现 model.BaseChatModel
+func (m *FailoverChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	ctx, target, opts, done, err := m.route(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	out, err := target.Generate(ctx, in, opts...)
+	done(ctx, err)
+	return out, err
+}
+
+// Stream 实现 model.BaseChatModel：流中途出错同样计入熔断器
+func (m *FailoverChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	ctx, target, opts, done, err := m.route(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := target.Stream(ctx, in, opts...)
+	if err != nil {
+		done(ctx, err)
+		return nil, err
+	}
+
+	out, sw := schema.Pipe[*schema.Message](1)
+	go func() {
+		defer sr.Close()
+		defer sw.Close()
+		for {
+			chunk, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				done(ctx, nil)
+				return
+			}
+			if err != nil {
+				done(ctx, err)
+				sw.Send(nil, err)
+				return
+			}
+			if closed := sw.Send(chunk, nil); closed {
+				// 调用方提前关闭：提供商已正常响应
+				done(ctx, nil)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// WithTools 实现 model.ToolCallingChatModel
+func (m *FailoverChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	tcm, ok := m.inner.(model.ToolCallingChatModel)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support tool calling", m.provider)
+	}
+	inner, err := tcm.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	next := *m
+	next.inner = inner
+	next.tools = tools
+	return &next, nil
+}
+
+// GetType 返回组件类型
+func (m *FailoverChatModel) GetType() string {
+	return failoverModelType
+}
+
+// IsCallbacksEnabled 回调由实际调用的内部模型触发
+func (m *FailoverChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// route 选择本次调用的目标模型；返回的 done 须以调用结果调用一次
+func (m *FailoverChatModel) route(ctx context.Context, opts []model.Option) (context.Context, model.BaseChatModel, []model.Option, func(context.Context, error), error) {
+	cfg := m.factory.config.Providers[m.provider]
+	modelName := effectiveModel(cfg.Model, opts)
+	if done, ok := m.factory.breaker(m.provider, modelName).allow(); ok {
+		return ctx, m.inner, opts, done, nil
+	}
+
+	for _, name := range cfg.Fallbacks {
+		fbCfg, ok := m.factory.config.Providers[name]
+		if !ok {
+			continue
+		}
+		target, err := m.factory.base(ctx, name)
+		if err != nil {
+			continue
+		}
+		if m.tools != nil {
+			tcm, ok := target.(model.ToolCallingChatModel)
+			if !ok {
+				continue
+			}
+			if target, err = tcm.WithTools(m.tools); err != nil {
+				continue
+			}
+		}
+		done, ok := m.factory.breaker(name, fbCfg.Model).allow()
+		if !ok {
+			continue
+		}
+		metrics.LLMCircuitShortCircuits.WithLabelValues(m.provider, modelName, "fallback").Inc()
+		// 调用方指定的模型属于原提供商，备用提供商使用其默认模型
+		fbOpts := append(append(make([]model.Option, 0, len(opts)+1), opts...), model.WithModel(fbCfg.Model))
+		return llmctx.WithProvider(ctx, name), target, fbOpts, done, nil
+	}
+
+	metrics.LLMCircuitShortCircuits.WithLabelValues(m.provider, modelName, "rejected").Inc()
+	return ctx, nil, nil, nil, fmt.Errorf("%w: provider=%s model=%s", ErrCircuitOpen, m.provider, modelName)
+}
+
+// effectiveModel 解析本次调用实际使用的模型（调用选项优先于提供商默认模型）
+func effectiveModel(defaultModel string, opts []model.Option) string {
+	o := model.GetCommonOptions(&model.Options{Model: &defaultModel}, opts...)
+	if o.Model == nil || *o.Model == "" {
+		return defaultModel
+	}
+	return *o.Model
+}
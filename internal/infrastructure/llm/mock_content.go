@@ -0,0 +1,280 @@
+package llm
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+//go:embed mock_foundation_plan.json
+var mockFoundationPlanJSON []byte
+
+// mockLoremSentences 章节正文的填充句（按大纲哈希确定起点，保证同一输入输出一致）
+var mockLoremSentences = []string{
+	"风从街角卷过，带起一阵细碎的尘土。",
+	"他停下脚步，侧耳听着远处若有若无的声响。",
+	"灯火在雾气里晕开，像一枚被水浸湿的铜币。",
+	"她没有立刻回答，只是把手里的东西握得更紧了些。",
+	"时间仿佛在这一刻慢了下来，连呼吸都变得清晰可闻。",
+	"墙上的影子随着火光晃动，拉得很长。",
+	"有些事情一旦开始，就再也无法回头。",
+	"脚下的石板还带着白日残留的余温。",
+	"他想起很久以前听过的那句话，至今仍不明白其中的意思。",
+	"远处传来钟声，一下，又一下，敲在每个人的心上。",
+	"空气里混着潮湿的泥土味和淡淡的铁锈气息。",
+	"沉默持续了很久，直到有人先移开了目光。",
+}
+
+// mockResponse 按工作流生成确定性输出
+func mockResponse(workflow string, in []*schema.Message, maxTokens int) string {
+	switch workflow {
+	case "chapter_generate", "chapter_stream":
+		return mockChapter(in, maxTokens)
+	case "foundation_generate", "foundation_stream":
+		return string(mockFoundationPlan())
+	case "artifact_generate":
+		return mockArtifact(in)
+	case "artifact_conflict_scan":
+		return `{"conflicts":[]}`
+	case "project_creation_generate":
+		return mockProjectCreation(in)
+	case "chapter_title_suggest":
+		return mockChapterTitles(in)
+	case "retrieval_rerank":
+		// 空分数：精排后保持向量召回顺序
+		return `{"scores":[]}`
+	default:
+		return "（离线模拟输出）" + firstLine(lastUserContent(in))
+	}
+}
+
+// mockChapter 生成目标字数的填充正文（受 max_tokens 限制）
+func mockChapter(in []*schema.Message, maxTokens int) string {
+	prompt := userContent(in)
+	target, _ := strconv.Atoi(promptField(prompt, "目标字数："))
+	if target <= 0 {
+		target = 1000
+	}
+	if maxTokens > 0 && target > maxTokens {
+		target = maxTokens
+	}
+
+	outline := promptBlock(prompt, "章节大纲：")
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(outline))
+	idx := int(h.Sum32() % uint32(len(mockLoremSentences)))
+
+	var b strings.Builder
+	if outline != "" {
+		b.WriteString(firstLine(outline))
+		b.WriteString("\n\n")
+	}
+	count := utf8.RuneCountInString(b.String())
+	inParagraph := 0
+	for count < target {
+		s := mockLoremSentences[idx%len(mockLoremSentences)]
+		idx++
+		b.WriteString(s)
+		count += utf8.RuneCountInString(s)
+		inParagraph++
+		if inParagraph == 4 && count < target {
+			b.WriteString("\n\n")
+			inParagraph = 0
+		}
+	}
+	return string([]rune(b.String())[:target])
+}
+
+// mockChapterTitles 取正文节选中前几句的开头作为候选标题
+func mockChapterTitles(in []*schema.Message) string {
+	prompt := userContent(in)
+	count := 0
+	if f := strings.Fields(promptField(prompt, "请给出 ")); len(f) > 0 {
+		count, _ = strconv.Atoi(f[0])
+	}
+	if count <= 0 {
+		count = 3
+	}
+	content := promptBlock(prompt, "章节正文（节选）：")
+	titles := make([]string, 0, count)
+	for _, s := range strings.FieldsFunc(content, func(r rune) bool { return strings.ContainsRune("。！？\n", r) }) {
+		if t := truncateRunes(strings.Trim(strings.TrimSpace(s), "“”，"), 8); t != "" {
+			titles = append(titles, t)
+		}
+		if len(titles) == count {
+			break
+		}
+	}
+	for i := len(titles); i < count; i++ {
+		titles = append(titles, fmt.Sprintf("离线模拟标题%d", i+1))
+	}
+	out, _ := json.Marshal(map[string]any{"titles": titles})
+	return string(out)
+}
+
+// mockFoundationPlan 返回预置的设定集（FoundationPlan）
+func mockFoundationPlan() json.RawMessage {
+	return mockFoundationPlanJSON
+}
+
+// mockArtifact 按构件类型返回完整 JSON；patch 模式下返回以 add 覆盖各顶层字段的 JSON Patch
+func mockArtifact(in []*schema.Message) string {
+	prompt := userContent(in)
+	artifactType := promptField(prompt, "任务：")
+
+	var plan map[string]any
+	_ = json.Unmarshal(mockFoundationPlanJSON, &plan)
+
+	var content map[string]any
+	switch artifactType {
+	case "novel_foundation":
+		project, _ := plan["project"].(map[string]any)
+		title := promptField(prompt, "项目标题：")
+		if title == "" {
+			title = "离线模拟项目"
+		}
+		description := firstLine(promptBlock(prompt, "项目简介："))
+		if description == "" {
+			description = "由离线模拟模型生成的项目简介。"
+		}
+		content = map[string]any{"title": title, "description": description, "genre": project["genre"]}
+	case "worldview":
+		content, _ = plan["project"].(map[string]any)
+	case "characters":
+		content = map[string]any{"entities": plan["entities"], "relations": plan["relations"]}
+	case "outline":
+		content = map[string]any{"volumes": plan["volumes"]}
+	default:
+		content = map[string]any{}
+	}
+
+	if !strings.Contains(prompt, "允许的 patch 约束") {
+		out, _ := json.Marshal(content)
+		return string(out)
+	}
+
+	ops := make([]map[string]any, 0, len(content))
+	for _, path := range sortedKeys(content) {
+		ops = append(ops, map[string]any{"op": "add", "path": "/" + path, "value": content[path]})
+	}
+	out, _ := json.Marshal(ops)
+	return string(out)
+}
+
+// mockProjectCreation 按 discover → narrow → draft → confirm 推进阶段；
+// 确认阶段返回 create_project（是否真正创建仍由接口层的确认门控决定）。
+func mockProjectCreation(in []*schema.Message) string {
+	prompt := userContent(in)
+	stage := promptField(prompt, "当前阶段：")
+
+	draft := map[string]string{}
+	_ = json.Unmarshal([]byte(promptBlock(prompt, "当前草稿（draft JSON）：")), &draft)
+	userInput := firstLine(promptBlock(prompt, "用户输入："))
+	if draft["title"] == "" {
+		draft["title"] = "离线模拟项目"
+	}
+	if draft["description"] == "" {
+		draft["description"] = truncateRunes(userInput, 200)
+	}
+	if draft["genre"] == "" {
+		draft["genre"] = "奇幻冒险"
+	}
+
+	next, action, confirm := "narrow", "none", false
+	switch stage {
+	case "narrow":
+		next = "draft"
+	case "draft":
+		next, action, confirm = "confirm", "propose_creation", true
+	case "confirm":
+		next, action, confirm = "confirm", "create_project", true
+	}
+
+	env := map[string]any{
+		"assistant_message":     fmt.Sprintf("（离线模拟）已记录你的想法，当前阶段：%s。", next),
+		"stage":                 next,
+		"draft":                 draft,
+		"action":                action,
+		"requires_confirmation": confirm,
+	}
+	if action != "none" {
+		env["project"] = draft
+	}
+	out, _ := json.Marshal(env)
+	return string(out)
+}
+
+// userContent 拼接全部用户消息（模板渲染后的 Prompt 与修复指令）
+func userContent(in []*schema.Message) string {
+	var parts []string
+	for _, msg := range in {
+		if msg != nil && msg.Role == schema.User {
+			parts = append(parts, msg.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func lastUserContent(in []*schema.Message) string {
+	for i := len(in) - 1; i >= 0; i-- {
+		if in[i] != nil && in[i].Role == schema.User {
+			return in[i].Content
+		}
+	}
+	return ""
+}
+
+// promptField 读取模板中“标签：值”同一行的值
+func promptField(prompt, label string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), label); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// promptBlock 读取模板中“标签：”之后直到空行的多行内容
+func promptBlock(prompt, label string) string {
+	_, rest, ok := strings.Cut(prompt, label)
+	if !ok {
+		return ""
+	}
+	rest = strings.TrimLeft(rest, " \n")
+	if end := strings.Index(rest, "\n\n"); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+
+	"z-novel-ai-api/internal/config"
+	llmctx "z-novel-ai-api/internal/domain/service"
+)
+
+const (
+	mockModelType = "Mock"
+
+	// mockStreamChunkRunes 模拟流式输出时每个分片的字数
+	mockStreamChunkRunes = 24
+	// mockStreamInterval 模拟流式输出的分片间隔
+	mockStreamInterval = 20 * time.Millisecond
+)
+
+// MockChatModel 离线 ChatModel：按工作流返回确定性的预置输出（合法的 FoundationPlan / 构件 JSON、
+// 指定字数的章节正文等），模拟流式分片与 Token 用量，并像真实模型一样触发 Eino 回调（计量/计费链路可用）。
+type MockChatModel struct {
+	model     string
+	maxTokens int
+}
+
+// NewMockChatModel 创建离线 ChatModel
+func NewMockChatModel(cfg config.ProviderConfig) *MockChatModel {
+	name := strings.TrimSpace(cfg.Model)
+	if name == "" {
+		name = "mock"
+	}
+	return &MockChatModel{model: name, maxTokens: cfg.MaxTokens}
+}
+
+// Generate 实现 model.BaseChatModel
+func (m *MockChatModel) Generate(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	cfg := modelConfig(m.model, m.maxTokens, opts)
+	content := mockResponse(llmctx.WorkflowFromContext(ctx), in, cfg.MaxTokens)
+	out := &schema.Message{
+		Role:    schema.Assistant,
+		Content: content,
+		ResponseMeta: &schema.ResponseMeta{
+			FinishReason: "stop",
+			Usage:        mockUsage(in, content),
+		},
+	}
+	return generateWithCallbacks(ctx, m.GetType(), in, cfg, out), nil
+}
+
+// Stream 实现 model.BaseChatModel：按固定字数分片输出，最后一条消息仅携带 Usage
+func (m *MockChatModel) Stream(ctx context.Context, in []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	cfg := modelConfig(m.model, m.maxTokens, opts)
+	content := mockResponse(llmctx.WorkflowFromContext(ctx), in, cfg.MaxTokens)
+
+	parts := splitRunes(content, mockStreamChunkRunes)
+	chunks := make([]*schema.Message, 0, len(parts)+1)
+	for _, part := range parts {
+		chunks = append(chunks, &schema.Message{Role: schema.Assistant, Content: part})
+	}
+	chunks = append(chunks, &schema.Message{
+		Role:         schema.Assistant,
+		ResponseMeta: &schema.ResponseMeta{FinishReason: "stop", Usage: mockUsage(in, content)},
+	})
+	return streamWithCallbacks(ctx, m.GetType(), in, cfg, chunks, mockStreamInterval), nil
+}
+
+// WithTools 实现 model.ToolCallingChatModel：离线模型不发起工具调用，直接返回自身
+func (m *MockChatModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// GetType 返回组件类型（用于回调 RunInfo）
+func (m *MockChatModel) GetType() string {
+	return mockModelType
+}
+
+// IsCallbacksEnabled 由组件自身触发回调
+func (m *MockChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// mockUsage 按字数近似 Token 用量（中文约 1 字 1 Token）
+func mockUsage(in []*schema.Message, content string) *schema.TokenUsage {
+	prompt := 0
+	for _, msg := range in {
+		if msg != nil {
+			prompt += utf8.RuneCountInString(msg.Content)
+		}
+	}
+	completion := utf8.RuneCountInString(content)
+	return &schema.TokenUsage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
+
+func splitRunes(s string, size int) []string {
+	runes := []rune(s)
+	out := make([]string, 0, len(runes)/size+1)
+	for start := 0; start < len(runes); start += size {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		out = append(out, string(runes[start:end]))
+	}
+	return out
+}
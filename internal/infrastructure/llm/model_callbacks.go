@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// 本文件为不经过真实提供商的 ChatModel（mock / 回放）提供与 OpenAI 适配器一致的回调语义，
+// 保证指标、Token 计量与扣费链路在离线场景下同样生效。
+
+// modelConfig 从调用选项中提取回调所需的模型配置
+func modelConfig(defaultModel string, defaultMaxTokens int, opts []model.Option) *model.Config {
+	o := model.GetCommonOptions(&model.Options{Model: &defaultModel, MaxTokens: &defaultMaxTokens}, opts...)
+	cfg := &model.Config{}
+	if o.Model != nil {
+		cfg.Model = *o.Model
+	}
+	if o.MaxTokens != nil {
+		cfg.MaxTokens = *o.MaxTokens
+	}
+	if o.Temperature != nil {
+		cfg.Temperature = *o.Temperature
+	}
+	return cfg
+}
+
+// generateWithCallbacks 触发 OnStart/OnEnd 并返回完整消息
+func generateWithCallbacks(ctx context.Context, typ string, in []*schema.Message, cfg *model.Config, out *schema.Message) *schema.Message {
+	ctx = callbacks.EnsureRunInfo(ctx, typ, components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: in, Config: cfg})
+	callbacks.OnEnd(ctx, &model.CallbackOutput{
+		Message:    out,
+		Config:     cfg,
+		TokenUsage: callbackUsage(out),
+	})
+	return out
+}
+
+// streamWithCallbacks 以 interval 为间隔逐条输出 chunks，并以 OnEndWithStreamOutput 包装回调流
+func streamWithCallbacks(ctx context.Context, typ string, in []*schema.Message, cfg *model.Config, chunks []*schema.Message, interval time.Duration) *schema.StreamReader[*schema.Message] {
+	ctx = callbacks.EnsureRunInfo(ctx, typ, components.ComponentOfChatModel)
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: in, Config: cfg})
+
+	sr, sw := schema.Pipe[*model.CallbackOutput](1)
+	go func() {
+		defer sw.Close()
+		for _, chunk := range chunks {
+			if interval > 0 {
+				select {
+				case <-ctx.Done():
+					sw.Send(nil, ctx.Err())
+					return
+				case <-time.After(interval):
+				}
+			}
+			if closed := sw.Send(&model.CallbackOutput{Message: chunk, Config: cfg, TokenUsage: callbackUsage(chunk)}, nil); closed {
+				return
+			}
+		}
+	}()
+
+	_, nsr := callbacks.OnEndWithStreamOutput(ctx, schema.StreamReaderWithConvert(sr,
+		func(src *model.CallbackOutput) (callbacks.CallbackOutput, error) {
+			return src, nil
+		}))
+	return schema.StreamReaderWithConvert(nsr, func(src callbacks.CallbackOutput) (*schema.Message, error) {
+		out := src.(*model.CallbackOutput)
+		if out.Message == nil {
+			return nil, schema.ErrNoValue
+		}
+		return out.Message, nil
+	})
+}
+
+func callbackUsage(msg *schema.Message) *model.TokenUsage {
+	if msg == nil || msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return nil
+	}
+	u := msg.ResponseMeta.Usage
+	return &model.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"z-novel-ai-api/internal/config"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Warmup 为全部已配置的提供商创建 ChatModel 客户端；ping 为 true 时再发送一次 1 Token 的最小请求，
+// 提前完成 DNS/TLS 握手与连接池建立（不经熔断器，失败不计入熔断统计；录制/回放模式下不发送）
+func (f *EinoFactory) Warmup(ctx context.Context, ping bool) error {
+	names := make([]string, 0, len(f.config.Providers))
+	for name := range f.config.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if mode := f.config.Cassette.Mode; mode != "" && mode != config.CassetteModeOff {
+		ping = false
+	}
+
+	var errs []error
+	for _, name := range names {
+		m, err := f.base(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ping || f.config.Providers[name].Type == config.ProviderTypeMock {
+			continue
+		}
+		if _, err := m.Generate(ctx, []*schema.Message{schema.UserMessage("ping")}, model.WithMaxTokens(1)); err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
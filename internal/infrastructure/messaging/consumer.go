@@ -18,17 +18,28 @@ import (
 // MessageHandler 消息处理函数
 type MessageHandler func(ctx context.Context, msg *Message) error
 
+// PauseGate 运维暂停开关（tenantID 为空表示仅查询全局开关）
+type PauseGate interface {
+	GenerationPaused(ctx context.Context, tenantID string) bool
+}
+
 // Consumer 消息消费者
 type Consumer struct {
 	client        *redis.Client
-	stream        Stream
+	streams       []Stream
 	group         ConsumerGroup
 	consumerName  string
 	blockTimeout  time.Duration
 	claimInterval time.Duration
 	reclaimIdle   time.Duration
 	retryLimit    int
+	stalledClaims int
 	backoff       BackoffConfig
+	pauseGate     PauseGate
+
+	statsMu      sync.Mutex
+	avgJobMillis float64
+	stalled      map[string]int64
 
 	handlers map[string]MessageHandler
 	mu       sync.RWMutex
@@ -38,13 +49,19 @@ type Consumer struct {
 
 // ConsumerConfig 消费者配置
 type ConsumerConfig struct {
-	Stream        Stream
+	Stream Stream
+	// Streams 同时消费的多个流，按优先级从高到低排列（有积压时总是先消费靠前的流）；为空时仅消费 Stream
+	Streams       []Stream
 	Group         ConsumerGroup
 	ConsumerName  string
 	BlockTimeout  time.Duration
 	ClaimInterval time.Duration
 	RetryLimit    int
-	Backoff       BackoffConfig
+	// StalledClaimThreshold 同一消息投递次数超过该值时视为停滞并告警；<= 0 时取 2 × RetryLimit
+	StalledClaimThreshold int
+	Backoff               BackoffConfig
+	// PauseGate 全局暂停时停止认领消息；租户暂停时该租户的消息重新入队延后处理
+	PauseGate PauseGate
 }
 
 // NewConsumer 创建消息消费者
@@ -58,20 +75,30 @@ func NewConsumer(client *redis.Client, cfg ConsumerConfig) *Consumer {
 	if cfg.RetryLimit <= 0 {
 		cfg.RetryLimit = 3
 	}
+	if cfg.StalledClaimThreshold <= 0 {
+		cfg.StalledClaimThreshold = 2 * cfg.RetryLimit
+	}
 	if cfg.Backoff.Initial <= 0 {
 		cfg.Backoff = DefaultBackoffConfig()
 	}
+	streams := cfg.Streams
+	if len(streams) == 0 {
+		streams = []Stream{cfg.Stream}
+	}
 
 	return &Consumer{
 		client:        client,
-		stream:        cfg.Stream,
+		streams:       streams,
 		group:         cfg.Group,
 		consumerName:  cfg.ConsumerName,
 		blockTimeout:  cfg.BlockTimeout,
 		claimInterval: cfg.ClaimInterval,
 		reclaimIdle:   maxDuration(5*time.Minute, cfg.Backoff.Max*2),
 		retryLimit:    cfg.RetryLimit,
+		stalledClaims: cfg.StalledClaimThreshold,
 		backoff:       cfg.Backoff,
+		pauseGate:     cfg.PauseGate,
+		stalled:       make(map[string]int64),
 		handlers:      make(map[string]MessageHandler),
 		stopCh:        make(chan struct{}),
 	}
@@ -95,9 +122,11 @@ func (c *Consumer) Start(ctx context.Context) error {
 	c.mu.Unlock()
 
 	// 确保消费者组存在
-	err := c.client.XGroupCreateMkStream(ctx, string(c.stream), string(c.group), "0").Err()
-	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
-		return fmt.Errorf("failed to create consumer group: %w", err)
+	for _, stream := range c.streams {
+		err := c.client.XGroupCreateMkStream(ctx, string(stream), string(c.group), "0").Err()
+		if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+			return fmt.Errorf("failed to create consumer group: %w", err)
+		}
 	}
 
 	go c.run(ctx)
@@ -118,12 +147,13 @@ func (c *Consumer) Stop() {
 func (c *Consumer) run(ctx context.Context) {
 	log := logger.FromContext(ctx)
 	log.Info("consumer started",
-		"stream", c.stream,
+		"streams", c.streams,
 		"group", c.group,
 		"consumer", c.consumerName,
 	)
 
 	lastClaim := time.Now().Add(-c.claimInterval)
+	paused := false
 
 	for {
 		select {
@@ -136,6 +166,20 @@ func (c *Consumer) run(ctx context.Context) {
 		default:
 		}
 
+		// 全局暂停：不读取新消息，也不认领待重试消息
+		if c.pauseGate != nil && c.pauseGate.GenerationPaused(ctx, "") {
+			if !paused {
+				log.Warn("consumer paused by ops switch", "streams", c.streams)
+				paused = true
+			}
+			c.wait(ctx, c.blockTimeout)
+			continue
+		}
+		if paused {
+			log.Info("consumer resumed", "streams", c.streams)
+			paused = false
+		}
+
 		c.processDuePending(ctx)
 		if time.Since(lastClaim) >= c.claimInterval {
 			c.reclaimStale(ctx)
@@ -143,14 +187,7 @@ func (c *Consumer) run(ctx context.Context) {
 		}
 
 		// 读取消息
-		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    string(c.group),
-			Consumer: c.consumerName,
-			Streams:  []string{string(c.stream), ">"},
-			Count:    10,
-			Block:    c.blockTimeout,
-		}).Result()
-
+		streams, err := c.read(ctx)
 		if err != nil {
 			if err == redis.Nil {
 				continue
@@ -160,19 +197,83 @@ func (c *Consumer) run(ctx context.Context) {
 			continue
 		}
 
+		total, deferred := 0, 0
 		for _, stream := range streams {
 			for _, xmsg := range stream.Messages {
-				c.processMessage(ctx, xmsg)
+				total++
+				if c.processMessage(ctx, Stream(stream.Stream), xmsg) {
+					deferred++
+				}
 			}
 		}
+		// 整批均因租户暂停被延后时稍作等待，避免空转
+		if total > 0 && deferred == total {
+			c.wait(ctx, c.blockTimeout)
+		}
+	}
+}
+
+// read 读取新消息。单个流时批量阻塞读取；多个流时先按优先级依次非阻塞读取单条，首个有积压的流即返回，
+// 均为空时再阻塞等待任一流（此时每个流至多返回一条，按优先级顺序处理）
+func (c *Consumer) read(ctx context.Context) ([]redis.XStream, error) {
+	if len(c.streams) == 1 {
+		return c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    string(c.group),
+			Consumer: c.consumerName,
+			Streams:  []string{string(c.streams[0]), ">"},
+			Count:    10,
+			Block:    c.blockTimeout,
+		}).Result()
+	}
+
+	for _, stream := range c.streams {
+		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    string(c.group),
+			Consumer: c.consumerName,
+			Streams:  []string{string(stream), ">"},
+			Count:    1,
+			Block:    -1,
+		}).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if len(res) > 0 && len(res[0].Messages) > 0 {
+			return res, nil
+		}
+	}
+
+	args := make([]string, 0, 2*len(c.streams))
+	for _, stream := range c.streams {
+		args = append(args, string(stream))
+	}
+	for range c.streams {
+		args = append(args, ">")
+	}
+	return c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    string(c.group),
+		Consumer: c.consumerName,
+		Streams:  args,
+		Count:    1,
+		Block:    c.blockTimeout,
+	}).Result()
+}
+
+// wait 可被停止信号打断的等待
+func (c *Consumer) wait(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-c.stopCh:
+	case <-timer.C:
 	}
 }
 
-// processMessage 处理单条消息
-func (c *Consumer) processMessage(ctx context.Context, xmsg redis.XMessage) {
+// processMessage 处理单条消息；返回 true 表示因租户暂停已重新入队
+func (c *Consumer) processMessage(ctx context.Context, stream Stream, xmsg redis.XMessage) bool {
 	ctx, span := tracer.Start(ctx, "consumer.processMessage",
 		trace.WithAttributes(
-			attribute.String("stream", string(c.stream)),
+			attribute.String("stream", string(stream)),
 			attribute.String("stream.message_id", xmsg.ID),
 		))
 	defer span.End()
@@ -182,14 +283,14 @@ func (c *Consumer) processMessage(ctx context.Context, xmsg redis.XMessage) {
 	dataStr, ok := xmsg.Values["data"].(string)
 	if !ok {
 		logger.FromContext(ctx).Error("invalid message format", "message_id", xmsg.ID)
-		c.ack(ctx, xmsg.ID)
-		return
+		c.ack(ctx, stream, xmsg.ID)
+		return false
 	}
 
 	if err := json.Unmarshal([]byte(dataStr), &msg); err != nil {
 		logger.FromContext(ctx).Error("failed to unmarshal message", "error", err, "message_id", xmsg.ID)
-		c.ack(ctx, xmsg.ID)
-		return
+		c.ack(ctx, stream, xmsg.ID)
+		return false
 	}
 
 	// 注入日志上下文（便于观测：tenant_id/project_id/request_id）
@@ -211,10 +312,25 @@ func (c *Consumer) processMessage(ctx context.Context, xmsg redis.XMessage) {
 	span.SetAttributes(
 		attribute.String("message.id", msg.ID),
 		attribute.String("message.type", msg.Type),
+		attribute.Int("message.version", msg.Version),
 		attribute.String("tenant_id", msg.TenantID),
 		attribute.String("project_id", msg.ProjectID),
 	)
 
+	// 版本协商：更新的生产者写入的消息留待重试（由已升级的实例处理），过旧的消息直接进入死信队列
+	if err := msg.CheckVersion(); err != nil {
+		span.RecordError(err)
+		if ve, _ := AsUnsupportedVersion(err); ve != nil && ve.Newer() {
+			log.Warn("message version newer than consumer", "error", err, "message_id", msg.ID)
+			c.handleFailure(ctx, stream, xmsg, &msg, err)
+			return false
+		}
+		log.Error("message version no longer supported", "error", err, "message_id", msg.ID)
+		c.recordProcessed(stream, &msg, "dead_letter", 0, 0)
+		c.deadLetter(ctx, stream, xmsg.ID, &msg, err, c.getRetryCount(ctx, stream, xmsg.ID))
+		return false
+	}
+
 	// 查找处理器
 	c.mu.RLock()
 	handler, exists := c.handlers[msg.Type]
@@ -222,34 +338,63 @@ func (c *Consumer) processMessage(ctx context.Context, xmsg redis.XMessage) {
 
 	if !exists {
 		log.Warn("no handler for message type", "type", msg.Type)
-		c.ack(ctx, xmsg.ID)
-		return
+		c.recordProcessed(stream, &msg, "unhandled", 0, 0)
+		c.ack(ctx, stream, xmsg.ID)
+		return false
+	}
+
+	if c.pauseGate != nil && msg.TenantID != "" && c.pauseGate.GenerationPaused(ctx, msg.TenantID) {
+		c.recordProcessed(stream, &msg, "deferred", 0, 0)
+		c.requeue(ctx, stream, xmsg)
+		return true
 	}
 
 	// 执行处理器
-	if err := handler(ctx, &msg); err != nil {
+	startedAt := time.Now()
+	age := messageAge(&msg, startedAt)
+	err := handler(ctx, &msg)
+	duration := time.Since(startedAt)
+	c.recordJobDuration(duration)
+	if err != nil {
 		span.RecordError(err)
-		log.Error("handler failed", "error", err, "message_id", msg.ID)
-		c.handleFailure(ctx, xmsg, &msg, err)
-		return
+		c.recordProcessed(stream, &msg, "failed", age, duration)
+		log.Error("handler failed", "error", err, "message_id", msg.ID, "type", msg.Type, "age", age.String(), "duration", duration.String())
+		c.handleFailure(ctx, stream, xmsg, &msg, err)
+		return false
 	}
 
-	c.ack(ctx, xmsg.ID)
+	c.recordProcessed(stream, &msg, "success", age, duration)
+	c.ack(ctx, stream, xmsg.ID)
+	return false
+}
+
+// requeue 租户暂停期间将消息追加回流尾部并确认原消息（不计入重试次数）
+func (c *Consumer) requeue(ctx context.Context, stream Stream, xmsg redis.XMessage) {
+	if err := c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: string(stream),
+		Values: xmsg.Values,
+	}).Err(); err != nil {
+		logger.FromContext(ctx).Error("failed to requeue paused message", "error", err, "message_id", xmsg.ID)
+		return
+	}
+	c.ack(ctx, stream, xmsg.ID)
 }
 
 // ack 确认消息
-func (c *Consumer) ack(ctx context.Context, id string) {
-	if err := c.client.XAck(ctx, string(c.stream), string(c.group), id).Err(); err != nil {
+func (c *Consumer) ack(ctx context.Context, stream Stream, id string) {
+	if err := c.client.XAck(ctx, string(stream), string(c.group), id).Err(); err != nil {
 		logger.FromContext(ctx).Error("failed to ack message", "error", err, "message_id", id)
+		return
 	}
+	c.forgetStalled(id)
 }
 
 // handleFailure 处理失败
-func (c *Consumer) handleFailure(ctx context.Context, xmsg redis.XMessage, msg *Message, err error) {
+func (c *Consumer) handleFailure(ctx context.Context, stream Stream, xmsg redis.XMessage, msg *Message, err error) {
 	log := logger.FromContext(ctx)
 
 	// 获取重试次数
-	retryCount := c.getRetryCount(ctx, xmsg.ID)
+	retryCount := c.getRetryCount(ctx, stream, xmsg.ID)
 
 	if retryCount >= c.retryLimit {
 		// 移入死信队列
@@ -257,8 +402,7 @@ func (c *Consumer) handleFailure(ctx context.Context, xmsg redis.XMessage, msg *
 			"message_id", msg.ID,
 			"retry_count", retryCount,
 		)
-		c.moveToDLQ(ctx, msg, err)
-		c.ack(ctx, xmsg.ID)
+		c.deadLetter(ctx, stream, xmsg.ID, msg, err, retryCount)
 		return
 	}
 	log.Info("message left pending for retry",
@@ -268,10 +412,10 @@ func (c *Consumer) handleFailure(ctx context.Context, xmsg redis.XMessage, msg *
 }
 
 // getRetryCount 获取重试次数
-func (c *Consumer) getRetryCount(ctx context.Context, messageID string) int {
+func (c *Consumer) getRetryCount(ctx context.Context, stream Stream, messageID string) int {
 	// 通过 XPENDING 获取消息的投递次数
 	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
-		Stream: string(c.stream),
+		Stream: string(stream),
 		Group:  string(c.group),
 		Start:  messageID,
 		End:    messageID,
@@ -285,27 +429,43 @@ func (c *Consumer) getRetryCount(ctx context.Context, messageID string) int {
 	return int(pending[0].RetryCount)
 }
 
-// moveToDLQ 移入死信队列
-func (c *Consumer) moveToDLQ(ctx context.Context, msg *Message, err error) {
-	dlqStream := c.stream.DLQStream()
+// deadLetter 移入死信队列并确认原消息；死信写入失败时保留在待处理列表，下次认领时重试（持续失败会触发停滞告警）
+func (c *Consumer) deadLetter(ctx context.Context, stream Stream, id string, msg *Message, cause error, retryCount int) {
+	if err := c.moveToDLQ(ctx, stream, msg, cause, retryCount); err != nil {
+		logger.FromContext(ctx).Error("failed to move message to DLQ", "error", err, "message_id", id)
+		return
+	}
+	c.ack(ctx, stream, id)
+}
 
-	dlqMsg := map[string]interface{}{
-		"original_stream": string(c.stream),
-		"data":            msg,
-		"error":           err.Error(),
-		"failed_at":       time.Now().Unix(),
+// moveToDLQ 移入死信队列
+func (c *Consumer) moveToDLQ(ctx context.Context, stream Stream, msg *Message, err error, retryCount int) error {
+	dlqStream := stream.DLQStream()
+
+	dlqMsg := dlqRecord{
+		OriginalStream: string(stream),
+		Data:           msg,
+		Error:          err.Error(),
+		FailedAt:       time.Now().Unix(),
+		RetryCount:     retryCount,
 	}
 
 	data, _ := json.Marshal(dlqMsg)
-	c.client.XAdd(ctx, &redis.XAddArgs{
+	return c.client.XAdd(ctx, &redis.XAddArgs{
 		Stream: dlqStream,
 		Values: map[string]interface{}{"data": string(data)},
-	})
+	}).Err()
 }
 
 func (c *Consumer) processDuePending(ctx context.Context) {
+	for _, stream := range c.streams {
+		c.processDuePendingStream(ctx, stream)
+	}
+}
+
+func (c *Consumer) processDuePendingStream(ctx context.Context, stream Stream) {
 	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
-		Stream:   string(c.stream),
+		Stream:   string(stream),
 		Group:    string(c.group),
 		Start:    "-",
 		End:      "+",
@@ -322,15 +482,17 @@ func (c *Consumer) processDuePending(ctx context.Context) {
 
 	for i := range pending {
 		p := pending[i]
+		c.detectStalled(ctx, stream, p)
 		retryCount := int(p.RetryCount)
 		if retryCount >= c.retryLimit {
 			claimed, claimErr := c.client.XClaim(ctx, &redis.XClaimArgs{
-				Stream:   string(c.stream),
+				Stream:   string(stream),
 				Group:    string(c.group),
 				Consumer: c.consumerName,
 				MinIdle:  0,
 				Messages: []string{p.ID},
 			}).Result()
+			c.recordClaim(stream, claimReasonDLQ, claimErr)
 			if claimErr != nil {
 				logger.FromContext(ctx).Error("failed to claim pending message for DLQ", "error", claimErr, "message_id", p.ID)
 				continue
@@ -339,18 +501,18 @@ func (c *Consumer) processDuePending(ctx context.Context) {
 			for _, xmsg := range claimed {
 				raw, ok := xmsg.Values["data"].(string)
 				if !ok {
-					c.ack(ctx, xmsg.ID)
+					c.ack(ctx, stream, xmsg.ID)
 					continue
 				}
 
 				var msg Message
 				if unmarshalErr := json.Unmarshal([]byte(raw), &msg); unmarshalErr != nil {
-					c.ack(ctx, xmsg.ID)
+					c.ack(ctx, stream, xmsg.ID)
 					continue
 				}
 
-				c.moveToDLQ(ctx, &msg, fmt.Errorf("message exceeded max retries"))
-				c.ack(ctx, xmsg.ID)
+				c.recordProcessed(stream, &msg, "dead_letter", 0, 0)
+				c.deadLetter(ctx, stream, xmsg.ID, &msg, fmt.Errorf("message exceeded max retries"), int(p.RetryCount))
 			}
 			continue
 		}
@@ -361,19 +523,20 @@ func (c *Consumer) processDuePending(ctx context.Context) {
 		}
 
 		claimed, claimErr := c.client.XClaim(ctx, &redis.XClaimArgs{
-			Stream:   string(c.stream),
+			Stream:   string(stream),
 			Group:    string(c.group),
 			Consumer: c.consumerName,
 			MinIdle:  backoff,
 			Messages: []string{p.ID},
 		}).Result()
+		c.recordClaim(stream, claimReasonRetry, claimErr)
 		if claimErr != nil {
 			logger.FromContext(ctx).Error("failed to claim pending message", "error", claimErr, "message_id", p.ID)
 			continue
 		}
 
 		for _, xmsg := range claimed {
-			c.processMessage(ctx, xmsg)
+			c.processMessage(ctx, stream, xmsg)
 		}
 	}
 }
@@ -382,9 +545,14 @@ func (c *Consumer) reclaimStale(ctx context.Context) {
 	if c.reclaimIdle <= 0 {
 		return
 	}
+	for _, stream := range c.streams {
+		c.reclaimStaleStream(ctx, stream)
+	}
+}
 
+func (c *Consumer) reclaimStaleStream(ctx context.Context, stream Stream) {
 	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
-		Stream: string(c.stream),
+		Stream: string(stream),
 		Group:  string(c.group),
 		Start:  "-",
 		End:    "+",
@@ -403,17 +571,19 @@ func (c *Consumer) reclaimStale(ctx context.Context) {
 		if p.Consumer == c.consumerName {
 			continue
 		}
+		c.detectStalled(ctx, stream, p)
 		if p.Idle < c.reclaimIdle {
 			continue
 		}
 		if int(p.RetryCount) >= c.retryLimit {
 			claimed, claimErr := c.client.XClaim(ctx, &redis.XClaimArgs{
-				Stream:   string(c.stream),
+				Stream:   string(stream),
 				Group:    string(c.group),
 				Consumer: c.consumerName,
 				MinIdle:  c.reclaimIdle,
 				Messages: []string{p.ID},
 			}).Result()
+			c.recordClaim(stream, claimReasonDLQ, claimErr)
 			if claimErr != nil {
 				logger.FromContext(ctx).Error("failed to claim stale message for DLQ", "error", claimErr, "message_id", p.ID)
 				continue
@@ -421,35 +591,36 @@ func (c *Consumer) reclaimStale(ctx context.Context) {
 			for _, xmsg := range claimed {
 				raw, ok := xmsg.Values["data"].(string)
 				if !ok {
-					c.ack(ctx, xmsg.ID)
+					c.ack(ctx, stream, xmsg.ID)
 					continue
 				}
 
 				var msg Message
 				if unmarshalErr := json.Unmarshal([]byte(raw), &msg); unmarshalErr != nil {
-					c.ack(ctx, xmsg.ID)
+					c.ack(ctx, stream, xmsg.ID)
 					continue
 				}
-				c.moveToDLQ(ctx, &msg, fmt.Errorf("message exceeded max retries"))
-				c.ack(ctx, xmsg.ID)
+				c.recordProcessed(stream, &msg, "dead_letter", 0, 0)
+				c.deadLetter(ctx, stream, xmsg.ID, &msg, fmt.Errorf("message exceeded max retries"), int(p.RetryCount))
 			}
 			continue
 		}
 
 		claimed, claimErr := c.client.XClaim(ctx, &redis.XClaimArgs{
-			Stream:   string(c.stream),
+			Stream:   string(stream),
 			Group:    string(c.group),
 			Consumer: c.consumerName,
 			MinIdle:  c.reclaimIdle,
 			Messages: []string{p.ID},
 		}).Result()
+		c.recordClaim(stream, claimReasonReclaim, claimErr)
 		if claimErr != nil {
 			logger.FromContext(ctx).Error("failed to reclaim pending message", "error", claimErr, "message_id", p.ID)
 			continue
 		}
 
 		for _, xmsg := range claimed {
-			c.processMessage(ctx, xmsg)
+			c.processMessage(ctx, stream, xmsg)
 		}
 	}
 }
@@ -474,17 +645,19 @@ func (c *Consumer) MonitorDLQ(ctx context.Context, alertThreshold int64) {
 		case <-c.stopCh:
 			return
 		case <-ticker.C:
-			dlqStream := c.stream.DLQStream()
-			info, err := c.client.XInfoStream(ctx, dlqStream).Result()
-			if err != nil {
-				continue
-			}
+			for _, stream := range c.streams {
+				dlqStream := stream.DLQStream()
+				info, err := c.client.XInfoStream(ctx, dlqStream).Result()
+				if err != nil {
+					continue
+				}
 
-			if info.Length > alertThreshold {
-				log.Warn("DLQ has pending messages",
-					"stream", dlqStream,
-					"count", info.Length,
-				)
+				if info.Length > alertThreshold {
+					log.Warn("DLQ has pending messages",
+						"stream", dlqStream,
+						"count", info.Length,
+					)
+				}
 			}
 		}
 	}
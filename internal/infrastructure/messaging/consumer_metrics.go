@@ -0,0 +1,84 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"z-novel-ai-api/pkg/logger"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+// 认领原因（stream_claim_attempts_total 的 reason 标签）
+const (
+	claimReasonRetry   = "retry"
+	claimReasonReclaim = "reclaim"
+	claimReasonDLQ     = "dlq"
+)
+
+// recordClaim 记录一次 XCLAIM 尝试
+func (c *Consumer) recordClaim(stream Stream, reason string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	metrics.RedisStreamClaimAttempts.WithLabelValues(string(stream), reason, status).Inc()
+}
+
+// recordProcessed 记录一条消息的处理结果、处理耗时与开始处理时的消息年龄
+func (c *Consumer) recordProcessed(stream Stream, msg *Message, status string, age, duration time.Duration) {
+	name := string(stream)
+	metrics.RedisStreamProcessed.WithLabelValues(name, status).Inc()
+	if msg == nil {
+		return
+	}
+	if age > 0 {
+		metrics.RedisStreamMessageAge.WithLabelValues(name, msg.Type).Observe(age.Seconds())
+	}
+	if duration > 0 {
+		metrics.RedisStreamHandlerDuration.WithLabelValues(name, msg.Type, status).Observe(duration.Seconds())
+	}
+}
+
+// messageAge 消息自生产以来的时长（生产时间缺失时返回 0）
+func messageAge(msg *Message, now time.Time) time.Duration {
+	if msg == nil || msg.CreatedAt.IsZero() {
+		return 0
+	}
+	return now.Sub(msg.CreatedAt)
+}
+
+// detectStalled 停滞检测：同一消息的投递（认领）次数超过阈值时记录错误日志与告警指标。
+// 正常情况下消息在 retry_limit 次后进入死信队列，超过阈值意味着认领循环无法推进（如死信写入持续失败）。
+// 同一消息仅在投递次数变化时重复告警，确认后清除记录。
+func (c *Consumer) detectStalled(ctx context.Context, stream Stream, p redis.XPendingExt) {
+	if c.stalledClaims <= 0 || p.RetryCount <= int64(c.stalledClaims) {
+		return
+	}
+	c.statsMu.Lock()
+	if c.stalled[p.ID] == p.RetryCount {
+		c.statsMu.Unlock()
+		return
+	}
+	c.stalled[p.ID] = p.RetryCount
+	c.statsMu.Unlock()
+
+	metrics.RedisStreamStalledMessages.WithLabelValues(string(stream), string(c.group)).Inc()
+	logger.FromContext(ctx).Error("stalled stream message claimed repeatedly",
+		"stream", stream,
+		"group", c.group,
+		"message_id", p.ID,
+		"owner", p.Consumer,
+		"delivery_count", p.RetryCount,
+		"idle", p.Idle.String(),
+		"threshold", c.stalledClaims,
+	)
+}
+
+// forgetStalled 消息确认后清除停滞记录
+func (c *Consumer) forgetStalled(id string) {
+	c.statsMu.Lock()
+	delete(c.stalled, id)
+	c.statsMu.Unlock()
+}
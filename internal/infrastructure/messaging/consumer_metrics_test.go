@@ -0,0 +1,39 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+
+	"z-novel-ai-api/pkg/metrics"
+)
+
+func TestDetectStalledReportsOncePerDeliveryCount(t *testing.T) {
+	ctx := context.Background()
+	c := NewConsumer(nil, ConsumerConfig{Stream: "stream:test:stalled", Group: "cg-test", RetryLimit: 3})
+	if c.stalledClaims != 6 {
+		t.Fatalf("default stall threshold should be 2 x retry limit, got %d", c.stalledClaims)
+	}
+	stalled := metrics.RedisStreamStalledMessages.WithLabelValues("stream:test:stalled", "cg-test")
+
+	c.detectStalled(ctx, "stream:test:stalled", redis.XPendingExt{ID: "1-0", RetryCount: 6, Idle: time.Minute})
+	if got := testutil.ToFloat64(stalled); got != 0 {
+		t.Fatalf("delivery count at the threshold is not stalled, got %v", got)
+	}
+
+	c.detectStalled(ctx, "stream:test:stalled", redis.XPendingExt{ID: "1-0", RetryCount: 7, Idle: time.Minute})
+	c.detectStalled(ctx, "stream:test:stalled", redis.XPendingExt{ID: "1-0", RetryCount: 7, Idle: 2 * time.Minute})
+	if got := testutil.ToFloat64(stalled); got != 1 {
+		t.Fatalf("same delivery count should be reported once, got %v", got)
+	}
+
+	c.detectStalled(ctx, "stream:test:stalled", redis.XPendingExt{ID: "1-0", RetryCount: 8, Idle: time.Minute})
+	c.forgetStalled("1-0")
+	c.detectStalled(ctx, "stream:test:stalled", redis.XPendingExt{ID: "1-0", RetryCount: 8, Idle: time.Minute})
+	if got := testutil.ToFloat64(stalled); got != 3 {
+		t.Fatalf("new claims and forgotten messages should be reported again, got %v", got)
+	}
+}
@@ -0,0 +1,272 @@
+// Package messaging 提供消息队列实现
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxDLQBatch 单次查看/重新入队/清除的死信消息数上限
+const MaxDLQBatch = 1000
+
+// ErrDLQMessageNotFound 死信消息不存在（已重新入队、已清除或 ID 错误）
+var ErrDLQMessageNotFound = errors.New("dlq message not found")
+
+// dlqRecord 死信消息内容（moveToDLQ 写入 data 字段的 JSON）
+type dlqRecord struct {
+	OriginalStream string   `json:"original_stream"`
+	Data           *Message `json:"data"`
+	Error          string   `json:"error"`
+	FailedAt       int64    `json:"failed_at"`
+	// RetryCount 移入死信队列时的投递次数（旧版本写入的记录为 0）
+	RetryCount int `json:"retry_count,omitempty"`
+}
+
+// DLQEntry 死信队列中的一条消息
+type DLQEntry struct {
+	// ID 死信流中的消息 ID
+	ID             string
+	OriginalStream Stream
+	Message        *Message
+	Error          string
+	FailedAt       time.Time
+	RetryCount     int
+}
+
+// PendingEntry 已认领未确认的消息（处理中或等待退避重试）
+type PendingEntry struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	RetryCount int
+	// Message 原消息；已被裁剪或无法解析时为 nil
+	Message *Message
+}
+
+// QueueInfo 队列实时状态（运维查看，直接读取 Redis Stream 信息）
+type QueueInfo struct {
+	Stream Stream
+	Group  ConsumerGroup
+	// Length 流中保留的消息数（含已确认消息，受 MAXLEN 近似裁剪）
+	Length int64
+	// Lag 尚未被任何 Worker 认领的消息数
+	Lag int64
+	// Pending 已认领未确认的消息数
+	Pending int64
+	// Consumers 消费者组内的消费者数
+	Consumers int64
+	// DLQLength 死信队列中的消息数
+	DLQLength int64
+	// Stats Worker 上报的队列统计（未上报或已过期时为 nil）
+	Stats *QueueStats
+}
+
+// InspectQueue 读取流、消费者组与死信队列的当前状态；流或消费者组尚未创建时对应字段为 0
+func (p *Producer) InspectQueue(ctx context.Context, stream Stream, statsMaxAge time.Duration) (*QueueInfo, error) {
+	info := &QueueInfo{Stream: stream, Group: stream.ConsumerGroup()}
+
+	length, err := p.client.XLen(ctx, string(stream)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream length: %w", err)
+	}
+	info.Length = length
+
+	if length > 0 && info.Group != "" {
+		groups, err := p.client.XInfoGroups(ctx, string(stream)).Result()
+		if err != nil && !isNoGroupErr(err) {
+			return nil, fmt.Errorf("failed to get consumer group info: %w", err)
+		}
+		for _, g := range groups {
+			if g.Name != string(info.Group) {
+				continue
+			}
+			info.Pending = g.Pending
+			info.Consumers = g.Consumers
+			if g.Lag > 0 {
+				info.Lag = g.Lag
+			}
+		}
+	}
+
+	dlqLength, err := p.client.XLen(ctx, stream.DLQStream()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DLQ length: %w", err)
+	}
+	info.DLQLength = dlqLength
+
+	stats, err := p.QueueStats(ctx, stream, statsMaxAge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+	info.Stats = stats
+	return info, nil
+}
+
+// ListDLQ 按进入顺序列出死信消息（最多 limit 条）
+func (p *Producer) ListDLQ(ctx context.Context, stream Stream, limit int64) ([]*DLQEntry, error) {
+	if limit <= 0 || limit > MaxDLQBatch {
+		limit = MaxDLQBatch
+	}
+	msgs, err := p.client.XRangeN(ctx, stream.DLQStream(), "-", "+", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DLQ: %w", err)
+	}
+	entries := make([]*DLQEntry, 0, len(msgs))
+	for _, m := range msgs {
+		entries = append(entries, parseDLQEntry(stream, m))
+	}
+	return entries, nil
+}
+
+// RequeueDLQ 将死信消息重新发布到原始流并从死信队列删除，返回已重新入队的死信消息 ID。
+// ids 为空时处理队首的 MaxDLQBatch 条；不存在的 ID 忽略，无法解析的消息保留在死信队列中。
+func (p *Producer) RequeueDLQ(ctx context.Context, stream Stream, ids []string) ([]string, error) {
+	var msgs []redis.XMessage
+	if len(ids) == 0 {
+		var err error
+		msgs, err = p.client.XRangeN(ctx, stream.DLQStream(), "-", "+", MaxDLQBatch).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DLQ: %w", err)
+		}
+	} else {
+		for _, id := range ids {
+			found, err := p.client.XRangeN(ctx, stream.DLQStream(), id, id, 1).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read DLQ message %s: %w", id, err)
+			}
+			msgs = append(msgs, found...)
+		}
+	}
+
+	requeued := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		entry := parseDLQEntry(stream, m)
+		if entry.Message == nil {
+			continue
+		}
+		entry.Message.SetMetadata("requeued_from_dlq", entry.ID)
+		if _, err := p.Publish(ctx, entry.OriginalStream, entry.Message); err != nil {
+			return requeued, err
+		}
+		if err := p.client.XDel(ctx, stream.DLQStream(), entry.ID).Err(); err != nil {
+			return requeued, fmt.Errorf("failed to delete DLQ message %s: %w", entry.ID, err)
+		}
+		requeued = append(requeued, entry.ID)
+	}
+	return requeued, nil
+}
+
+// GetDLQ 读取单条死信消息（含载荷）
+func (p *Producer) GetDLQ(ctx context.Context, stream Stream, id string) (*DLQEntry, error) {
+	msgs, err := p.client.XRangeN(ctx, stream.DLQStream(), id, id, 1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DLQ message %s: %w", id, err)
+	}
+	if len(msgs) == 0 {
+		return nil, ErrDLQMessageNotFound
+	}
+	return parseDLQEntry(stream, msgs[0]), nil
+}
+
+// PurgeDLQ 从死信队列删除消息，返回删除条数；ids 为空时清空整个死信队列
+func (p *Producer) PurgeDLQ(ctx context.Context, stream Stream, ids []string) (int64, error) {
+	if len(ids) > 0 {
+		n, err := p.client.XDel(ctx, stream.DLQStream(), ids...).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete DLQ messages: %w", err)
+		}
+		return n, nil
+	}
+
+	var length *redis.IntCmd
+	_, err := p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		length = pipe.XLen(ctx, stream.DLQStream())
+		pipe.Del(ctx, stream.DLQStream())
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge DLQ: %w", err)
+	}
+	return length.Val(), nil
+}
+
+// ListPending 列出消费者组中已认领未确认的消息及其投递次数（最多 limit 条），用于查看重试中的任务；
+// 投递次数达到 retry_limit 后由 Worker 移入死信队列。流或消费者组尚未创建时返回空列表
+func (p *Producer) ListPending(ctx context.Context, stream Stream, limit int64) ([]*PendingEntry, error) {
+	group := stream.ConsumerGroup()
+	if group == "" {
+		return []*PendingEntry{}, nil
+	}
+	if limit <= 0 || limit > MaxDLQBatch {
+		limit = MaxDLQBatch
+	}
+	pending, err := p.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: string(stream),
+		Group:  string(group),
+		Start:  "-",
+		End:    "+",
+		Count:  limit,
+	}).Result()
+	if err != nil && err != redis.Nil && !isNoGroupErr(err) {
+		return nil, fmt.Errorf("failed to query pending messages: %w", err)
+	}
+	if len(pending) == 0 {
+		return []*PendingEntry{}, nil
+	}
+
+	// 批量读取原消息，展示类型、租户与项目
+	pipe := p.client.Pipeline()
+	cmds := make([]*redis.XMessageSliceCmd, len(pending))
+	for i, pe := range pending {
+		cmds[i] = pipe.XRangeN(ctx, string(stream), pe.ID, pe.ID, 1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read pending messages: %w", err)
+	}
+
+	entries := make([]*PendingEntry, 0, len(pending))
+	for i, pe := range pending {
+		entry := &PendingEntry{ID: pe.ID, Consumer: pe.Consumer, Idle: pe.Idle, RetryCount: int(pe.RetryCount)}
+		if msgs := cmds[i].Val(); len(msgs) > 0 {
+			if raw, ok := msgs[0].Values["data"].(string); ok {
+				var msg Message
+				if json.Unmarshal([]byte(raw), &msg) == nil {
+					entry.Message = &msg
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseDLQEntry(stream Stream, m redis.XMessage) *DLQEntry {
+	entry := &DLQEntry{ID: m.ID, OriginalStream: stream}
+	raw, _ := m.Values["data"].(string)
+	var rec dlqRecord
+	if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+		entry.Error = "unparseable DLQ message: " + err.Error()
+		return entry
+	}
+	if rec.OriginalStream != "" {
+		entry.OriginalStream = Stream(rec.OriginalStream)
+	}
+	entry.Message = rec.Data
+	entry.Error = rec.Error
+	entry.RetryCount = rec.RetryCount
+	if rec.FailedAt > 0 {
+		entry.FailedAt = time.Unix(rec.FailedAt, 0)
+	}
+	return entry
+}
+
+// isNoGroupErr 流或消费者组不存在（XINFO 返回 "ERR no such key"，XPENDING 返回 "NOGROUP ..."）
+func isNoGroupErr(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "no such key") || strings.HasPrefix(err.Error(), "NOGROUP"))
+}
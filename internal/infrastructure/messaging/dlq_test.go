@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseDLQEntryKeepsRetryCountAndPayload(t *testing.T) {
+	msg, err := NewMessage("job-1", "chapter_gen", "tenant-1", "project-1", map[string]string{"chapter_id": "c1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(dlqRecord{OriginalStream: string(StreamStoryGenHigh), Data: msg, Error: "boom", FailedAt: 1700000000, RetryCount: 3})
+
+	entry := parseDLQEntry(StreamStoryGen, redis.XMessage{ID: "1-0", Values: map[string]any{"data": string(data)}})
+	if entry.OriginalStream != StreamStoryGenHigh || entry.RetryCount != 3 || entry.Error != "boom" || entry.FailedAt.Unix() != 1700000000 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if entry.Message == nil || entry.Message.ID != "job-1" || string(entry.Message.Payload) != `{"chapter_id":"c1"}` {
+		t.Fatalf("payload should be preserved, got %+v", entry.Message)
+	}
+
+	broken := parseDLQEntry(StreamStoryGen, redis.XMessage{ID: "2-0", Values: map[string]any{"data": "{"}})
+	if broken.Message != nil || broken.OriginalStream != StreamStoryGen || broken.Error == "" {
+		t.Fatalf("unparseable record should keep the DLQ stream and report the error, got %+v", broken)
+	}
+}
+
+func TestIsNoGroupErr(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("ERR no such key"), true},
+		{errors.New("NOGROUP No such key 'stream:story:gen' or consumer group 'cg-gen-worker' in XPENDING command"), true},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+		{nil, false},
+	} {
+		if got := isNoGroupErr(tc.err); got != tc.want {
+			t.Fatalf("isNoGroupErr(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
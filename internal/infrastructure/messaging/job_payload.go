@@ -0,0 +1,211 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GenerationJobSchemaVersion 生成任务参数的当前结构版本，与消息版本遵循相同的 N / N-1 约定（见 MessageVersion）。
+// 新增可选字段属于兼容变更，不提升版本（旧 Worker 仅记录未知字段）；缺省（0）为版本字段上线前入队的消息。
+const GenerationJobSchemaVersion = 1
+
+// PayloadError 消息载荷不符合约定（字段类型错误、缺少必填字段、取值越界），重试无意义
+type PayloadError struct {
+	JobType string
+	Field   string
+	Reason  string
+}
+
+func (e *PayloadError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid %s payload: %s", e.JobType, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s payload: %s: %s", e.JobType, e.Field, e.Reason)
+}
+
+// IsPayloadError 是否为载荷校验失败
+func IsPayloadError(err error) bool {
+	var pe *PayloadError
+	return errors.As(err, &pe)
+}
+
+// ChapterGenParams chapter_gen 任务参数
+type ChapterGenParams struct {
+	// Outline 本次生成使用的大纲（为空时 Worker 回退到章节自身的大纲）
+	Outline         string   `json:"outline"`
+	TargetWordCount int      `json:"target_word_count"`
+	Provider        string   `json:"provider"`
+	Model           string   `json:"model"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	// Candidates 并行生成的候选数（<= 1 表示单候选），Selection 为候选择优策略
+	Candidates int    `json:"candidates,omitempty"`
+	Selection  string `json:"selection,omitempty"`
+}
+
+func (p *ChapterGenParams) validate() *PayloadError {
+	if p.TargetWordCount < 0 {
+		return &PayloadError{Field: "target_word_count", Reason: "must not be negative"}
+	}
+	if p.Candidates < 0 {
+		return &PayloadError{Field: "candidates", Reason: "must not be negative"}
+	}
+	return validateTemperature(p.Temperature)
+}
+
+// FoundationAttachment foundation_gen 任务的文本附件
+type FoundationAttachment struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// FoundationGenParams foundation_gen 任务参数
+type FoundationGenParams struct {
+	Prompt      string                 `json:"prompt"`
+	Attachments []FoundationAttachment `json:"attachments,omitempty"`
+	Provider    string                 `json:"provider"`
+	Model       string                 `json:"model"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	MaxTokens   *int                   `json:"max_tokens,omitempty"`
+}
+
+func (p *FoundationGenParams) validate() *PayloadError {
+	if strings.TrimSpace(p.Prompt) == "" {
+		return &PayloadError{Field: "prompt", Reason: "is required"}
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		return &PayloadError{Field: "max_tokens", Reason: "must be positive"}
+	}
+	return validateTemperature(p.Temperature)
+}
+
+func validateTemperature(t *float64) *PayloadError {
+	if t != nil && *t < 0 {
+		return &PayloadError{Field: "temperature", Reason: "must not be negative"}
+	}
+	return nil
+}
+
+// EncodeJobParams 将类型化任务参数编码为消息的 Params（生产者使用，保证与 Worker 解析的结构一致）
+func EncodeJobParams(params any) map[string]interface{} {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	out := make(map[string]interface{})
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+// DecodedJob 解析并校验后的任务消息
+type DecodedJob struct {
+	*GenerationJobMessage
+	// UnknownFields 当前版本未定义的字段（信封字段为 name，参数字段为 params.name），
+	// 通常意味着生产者先于 Worker 升级，由调用方记录日志
+	UnknownFields []string
+}
+
+// DecodeChapterGenJob 解析 chapter_gen 消息并校验参数。
+// 信封可解析但参数非法时，返回值中的信封仍可用（调用方据此将任务标记为失败）。
+func DecodeChapterGenJob(msg *Message) (*DecodedJob, *ChapterGenParams, error) {
+	var params ChapterGenParams
+	job, err := decodeJob(msg, "chapter_gen", &params, params.validate)
+	if err != nil {
+		return job, nil, err
+	}
+	if job.ChapterID == nil || strings.TrimSpace(*job.ChapterID) == "" {
+		return job, nil, &PayloadError{JobType: "chapter_gen", Field: "chapter_id", Reason: "is required"}
+	}
+	return job, &params, nil
+}
+
+// DecodeFoundationGenJob 解析 foundation_gen 消息并校验参数
+func DecodeFoundationGenJob(msg *Message) (*DecodedJob, *FoundationGenParams, error) {
+	var params FoundationGenParams
+	job, err := decodeJob(msg, "foundation_gen", &params, params.validate)
+	if err != nil {
+		return job, nil, err
+	}
+	return job, &params, nil
+}
+
+// DecodeJobEnvelope 解析任务消息信封（不解析参数，供运维任务等从数据库读取输入的处理器使用）
+func DecodeJobEnvelope(msg *Message) (*DecodedJob, error) {
+	return decodeJob(msg, msg.Type, nil, nil)
+}
+
+// decodeJob 解析信封、检查结构版本与未知字段，并将 Params 解析到 params（nil 表示跳过）。
+// 信封无法解析（缺少 job_id 等）时返回 nil 信封。
+func decodeJob(msg *Message, jobType string, params any, validate func() *PayloadError) (*DecodedJob, error) {
+	if msg == nil {
+		return nil, &PayloadError{JobType: jobType, Reason: "message is nil"}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Payload, &fields); err != nil {
+		return nil, &PayloadError{JobType: jobType, Reason: err.Error()}
+	}
+	var envelope GenerationJobMessage
+	if err := json.Unmarshal(msg.Payload, &envelope); err != nil {
+		return nil, &PayloadError{JobType: jobType, Reason: err.Error()}
+	}
+	if strings.TrimSpace(envelope.JobID) == "" || strings.TrimSpace(envelope.TenantID) == "" {
+		return nil, &PayloadError{JobType: jobType, Field: "job_id", Reason: "job_id and tenant_id are required"}
+	}
+
+	job := &DecodedJob{GenerationJobMessage: &envelope}
+	if err := CheckVersion(jobType+" payload", envelope.SchemaVersion, GenerationJobSchemaVersion); err != nil {
+		return job, err
+	}
+	job.UnknownFields = unknownKeys(fields, reflect.TypeOf(envelope), "")
+	if params == nil {
+		return job, nil
+	}
+
+	raw := fields["params"]
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = []byte("{}")
+	}
+	var paramFields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &paramFields); err != nil {
+		return job, &PayloadError{JobType: jobType, Field: "params", Reason: "must be an object"}
+	}
+	job.UnknownFields = append(job.UnknownFields, unknownKeys(paramFields, reflect.TypeOf(params).Elem(), "params.")...)
+
+	if err := json.Unmarshal(raw, params); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return job, &PayloadError{JobType: jobType, Field: "params." + typeErr.Field, Reason: "expected " + typeErr.Type.String() + ", got " + typeErr.Value}
+		}
+		return job, &PayloadError{JobType: jobType, Field: "params", Reason: err.Error()}
+	}
+	if validate != nil {
+		if perr := validate(); perr != nil {
+			perr.JobType = jobType
+			perr.Field = "params." + perr.Field
+			return job, perr
+		}
+	}
+	return job, nil
+}
+
+// unknownKeys 返回 fields 中不属于结构体 JSON 字段的键（排序后加前缀）
+func unknownKeys(fields map[string]json.RawMessage, t reflect.Type, prefix string) []string {
+	known := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = struct{}{}
+		}
+	}
+	var unknown []string
+	for key := range fields {
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, prefix+key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,80 @@
+package messaging
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeChapterGenJob(t *testing.T) {
+	chapterID := "chapter-1"
+	temp := 0.7
+	job := &GenerationJobMessage{
+		JobID:         "job-1",
+		TenantID:      "tenant-1",
+		ProjectID:     "project-1",
+		ChapterID:     &chapterID,
+		JobType:       "chapter_gen",
+		SchemaVersion: GenerationJobSchemaVersion,
+		Params: EncodeJobParams(ChapterGenParams{
+			Outline:         "主角离开小镇",
+			TargetWordCount: 3000,
+			Temperature:     &temp,
+			Candidates:      2,
+			Selection:       "longest",
+		}),
+	}
+	job.Params["tone"] = "dark"
+	msg, err := NewMessage(job.JobID, "chapter_gen", job.TenantID, job.ProjectID, job)
+	if err != nil {
+		t.Fatalf("new message: %v", err)
+	}
+
+	decoded, params, err := DecodeChapterGenJob(msg)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if params.TargetWordCount != 3000 || params.Candidates != 2 || params.Selection != "longest" || *params.Temperature != temp {
+		t.Fatalf("unexpected params: %+v", params)
+	}
+	if !reflect.DeepEqual(decoded.UnknownFields, []string{"params.tone"}) {
+		t.Fatalf("expected params.tone to be reported as unknown, got %v", decoded.UnknownFields)
+	}
+}
+
+func TestDecodeChapterGenJobLegacyAndInvalidPayloads(t *testing.T) {
+	decode := func(payload string) (*DecodedJob, *ChapterGenParams, error) {
+		return DecodeChapterGenJob(&Message{Type: "chapter_gen", Payload: []byte(payload)})
+	}
+
+	// 版本字段上线前入队的消息按版本 1 解析
+	if _, params, err := decode(`{"job_id":"j","tenant_id":"t","chapter_id":"c","params":{"outline":"o","target_word_count":2000}}`); err != nil || params.TargetWordCount != 2000 {
+		t.Fatalf("legacy payload should decode, got %+v (err=%v)", params, err)
+	}
+
+	job, _, err := decode(`{"job_id":"j","tenant_id":"t","chapter_id":"c","params":{"target_word_count":"long"}}`)
+	var pe *PayloadError
+	if !errors.As(err, &pe) || pe.Field != "params.target_word_count" || job == nil || job.JobID != "j" {
+		t.Fatalf("expected a field-level payload error with the envelope, got job=%+v err=%v", job, err)
+	}
+
+	if _, _, err := decode(`{"job_id":"j","tenant_id":"t","params":{}}`); !IsPayloadError(err) {
+		t.Fatalf("missing chapter_id should be a payload error, got %v", err)
+	}
+
+	_, _, err = decode(`{"job_id":"j","tenant_id":"t","chapter_id":"c","schema_version":2,"params":{"outline":{"text":"o"}}}`)
+	if ve, ok := AsUnsupportedVersion(err); !ok || !ve.Newer() || IsPayloadError(err) {
+		t.Fatalf("newer schema version should be rejected as retryable, got %v", err)
+	}
+
+	if job, _, err := decode(`{"params":{}}`); job != nil || !IsPayloadError(err) {
+		t.Fatalf("payload without job_id cannot be located, got job=%+v err=%v", job, err)
+	}
+}
+
+func TestDecodeFoundationGenJobRequiresPrompt(t *testing.T) {
+	msg := &Message{Type: "foundation_gen", Payload: []byte(`{"job_id":"j","tenant_id":"t","params":{"prompt":"  ","temperature":null,"max_tokens":null}}`)}
+	if _, _, err := DecodeFoundationGenJob(msg); !IsPayloadError(err) {
+		t.Fatalf("blank prompt should be rejected, got %v", err)
+	}
+}
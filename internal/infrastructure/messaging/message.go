@@ -3,6 +3,7 @@ package messaging
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -10,6 +11,7 @@ import (
 type Message struct {
 	ID        string            `json:"id"`
 	Type      string            `json:"type"`
+	Version   int               `json:"version"` // 消息版本（见 MessageVersion 的兼容约定）
 	TenantID  string            `json:"tenant_id"`
 	ProjectID string            `json:"project_id"`
 	Payload   json.RawMessage   `json:"payload"`
@@ -27,6 +29,7 @@ func NewMessage(id, msgType, tenantID, projectID string, payload interface{}) (*
 	return &Message{
 		ID:        id,
 		Type:      msgType,
+		Version:   MessageVersion,
 		TenantID:  tenantID,
 		ProjectID: projectID,
 		Payload:   payloadBytes,
@@ -51,6 +54,11 @@ func (m *Message) GetMetadata(key string) string {
 	return m.Metadata[key]
 }
 
+// CheckVersion 检查消息版本是否在当前消费者的兼容范围内
+func (m *Message) CheckVersion() error {
+	return CheckVersion("message", m.Version, MessageVersion)
+}
+
 // UnmarshalPayload 解析消息载荷
 func (m *Message) UnmarshalPayload(v interface{}) error {
 	return json.Unmarshal(m.Payload, v)
@@ -60,16 +68,75 @@ func (m *Message) UnmarshalPayload(v interface{}) error {
 type Stream string
 
 const (
-	StreamStoryGen     Stream = "stream:story:gen"
+	// StreamStoryGen 生成/运维任务流（normal 优先级）
+	StreamStoryGen Stream = "stream:story:gen"
+	// StreamStoryGenHigh 高优先级任务流（交互式请求）
+	StreamStoryGenHigh Stream = "stream:story:gen:high"
+	// StreamStoryGenLow 低优先级任务流（批量起草、运维任务）
+	StreamStoryGenLow  Stream = "stream:story:gen:low"
 	StreamMemoryUpdate Stream = "stream:memory:update"
 	StreamAuditLog     Stream = "stream:audit:log"
 )
 
+// 优先级流阈值（与 entity.JobPriorityHigh / entity.JobPriorityLow 一致）
+const (
+	priorityHighThreshold = 8
+	priorityLowThreshold  = 2
+)
+
+// StoryGenStreams 任务流按优先级从高到低排列（Worker 按此顺序消费）
+var StoryGenStreams = []Stream{StreamStoryGenHigh, StreamStoryGen, StreamStoryGenLow}
+
+// KnownStreams 已定义的业务流（运维查看队列时遍历）
+var KnownStreams = []Stream{StreamStoryGenHigh, StreamStoryGen, StreamStoryGenLow, StreamMemoryUpdate, StreamAuditLog}
+
+// StoryGenStream 按任务优先级选择任务流
+func StoryGenStream(priority int) Stream {
+	switch {
+	case priority >= priorityHighThreshold:
+		return StreamStoryGenHigh
+	case priority <= priorityLowThreshold:
+		return StreamStoryGenLow
+	default:
+		return StreamStoryGen
+	}
+}
+
 // DLQStream 获取对应的死信队列流名称
 func (s Stream) DLQStream() string {
 	return "dlq:" + string(s)
 }
 
+// Name 流的简写名称（去掉 stream: 前缀、冒号换成连字符，如 story-gen），用于 URL 与命令行
+func (s Stream) Name() string {
+	return strings.ReplaceAll(strings.TrimPrefix(string(s), "stream:"), ":", "-")
+}
+
+// ConsumerGroup 消费该流的消费者组
+func (s Stream) ConsumerGroup() ConsumerGroup {
+	switch s {
+	case StreamStoryGen, StreamStoryGenHigh, StreamStoryGenLow:
+		return ConsumerGroupGenWorker
+	case StreamMemoryUpdate:
+		return ConsumerGroupMemWriter
+	case StreamAuditLog:
+		return ConsumerGroupArchiver
+	default:
+		return ""
+	}
+}
+
+// ParseStream 按完整流名或简写名称查找已定义的流
+func ParseStream(name string) (Stream, bool) {
+	name = strings.TrimSpace(name)
+	for _, s := range KnownStreams {
+		if name == string(s) || name == s.Name() {
+			return s, true
+		}
+	}
+	return "", false
+}
+
 // ConsumerGroup 消费者组定义
 type ConsumerGroup string
 
@@ -0,0 +1,116 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reprioritizeScanBatch 查找待调整消息时每次 XRANGE 读取的条数
+const reprioritizeScanBatch = 200
+
+// ErrMessageNotQueued 任务消息不在流中等待消费（已被 Worker 认领、已处理或已被删除）
+var ErrMessageNotQueued = errors.New("job message is not waiting in queue")
+
+// moveQueuedScript 原子地将尚未投递的消息从一个流移到另一个流：消息已在消费者组的待处理列表中（已被认领）时放弃。
+// 脚本执行期间 Worker 无法读取该消息，避免同一任务被两个流各投递一次。
+// KEYS[1] 原流 KEYS[2] 目标流；ARGV[1] 消费者组 ARGV[2] 原消息 ID ARGV[3] 新消息内容 ARGV[4] 目标流 MAXLEN
+var moveQueuedScript = redis.NewScript(`
+local pending = redis.call('XPENDING', KEYS[1], ARGV[1], ARGV[2], ARGV[2], 1)
+if #pending > 0 then
+	return 0
+end
+if redis.call('XDEL', KEYS[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[4], '*', 'data', ARGV[3])
+return 1
+`)
+
+// Reprioritize 调整排队中任务的优先级：在 from 流尚未投递的消息中按任务 ID 查找，改写载荷与元数据中的优先级后移入 to 流。
+// 消息已被认领或找不到时返回 ErrMessageNotQueued。两个流需位于同一 Redis 节点（脚本跨键操作）。
+func (p *Producer) Reprioritize(ctx context.Context, jobID string, priority int, from, to Stream) error {
+	ctx, span := tracer.Start(ctx, "producer.Reprioritize")
+	defer span.End()
+
+	entryID, msg, err := p.findUndelivered(ctx, from, jobID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if msg == nil {
+		return ErrMessageNotQueued
+	}
+
+	if err := setPayloadPriority(msg, priority); err != nil {
+		return err
+	}
+	msg.SetMetadata("priority", strconv.Itoa(priority))
+	msg.SetMetadata("reprioritized_from", string(from))
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	moved, err := moveQueuedScript.Run(ctx, p.client, []string{string(from), string(to)},
+		string(from.ConsumerGroup()), entryID, string(data), p.maxLen).Int()
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to move job message: %w", err)
+	}
+	if moved == 0 {
+		return ErrMessageNotQueued
+	}
+	return nil
+}
+
+// findUndelivered 在流中消费者组尚未投递的区间内按任务 ID 查找消息
+func (p *Producer) findUndelivered(ctx context.Context, stream Stream, jobID string) (string, *Message, error) {
+	start := "-"
+	groups, err := p.client.XInfoGroups(ctx, string(stream)).Result()
+	if err != nil && !isNoGroupErr(err) {
+		return "", nil, fmt.Errorf("failed to query consumer groups: %w", err)
+	}
+	for _, g := range groups {
+		if g.Name == string(stream.ConsumerGroup()) && g.LastDeliveredID != "" && g.LastDeliveredID != "0-0" {
+			start = "(" + g.LastDeliveredID
+		}
+	}
+
+	for {
+		entries, err := p.client.XRangeN(ctx, string(stream), start, "+", reprioritizeScanBatch).Result()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to scan stream: %w", err)
+		}
+		for _, e := range entries {
+			raw, _ := e.Values["data"].(string)
+			var msg Message
+			if json.Unmarshal([]byte(raw), &msg) == nil && msg.ID == jobID {
+				return e.ID, &msg, nil
+			}
+		}
+		if len(entries) < reprioritizeScanBatch {
+			return "", nil, nil
+		}
+		start = "(" + entries[len(entries)-1].ID
+	}
+}
+
+// setPayloadPriority 改写任务载荷中的 priority 字段，保留其余字段原样
+func setPayloadPriority(msg *Message, priority int) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Payload, &fields); err != nil {
+		return fmt.Errorf("failed to decode job payload: %w", err)
+	}
+	fields["priority"] = json.RawMessage(strconv.Itoa(priority))
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	msg.Payload = payload
+	return nil
+}
@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStoryGenStreamByPriority(t *testing.T) {
+	for priority, want := range map[int]Stream{
+		10: StreamStoryGenHigh,
+		8:  StreamStoryGenHigh,
+		7:  StreamStoryGen,
+		5:  StreamStoryGen,
+		3:  StreamStoryGen,
+		2:  StreamStoryGenLow,
+		1:  StreamStoryGenLow,
+	} {
+		if got := StoryGenStream(priority); got != want {
+			t.Fatalf("priority %d: got %s, want %s", priority, got, want)
+		}
+	}
+	for _, s := range StoryGenStreams {
+		if s.ConsumerGroup() != ConsumerGroupGenWorker {
+			t.Fatalf("stream %s should be consumed by gen worker", s)
+		}
+	}
+}
+
+func TestSetPayloadPriorityKeepsOtherFields(t *testing.T) {
+	msg, err := NewMessage("job-1", "gen", "tenant-1", "project-1", &GenerationJobMessage{JobID: "job-1", Priority: 5})
+	if err != nil {
+		t.Fatalf("new message: %v", err)
+	}
+	if err := setPayloadPriority(msg, 9); err != nil {
+		t.Fatalf("set priority: %v", err)
+	}
+	var job GenerationJobMessage
+	if err := json.Unmarshal(msg.Payload, &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if job.Priority != 9 || job.JobID != "job-1" {
+		t.Fatalf("unexpected payload %+v", job)
+	}
+}
@@ -71,6 +71,7 @@ func (p *Producer) Publish(ctx context.Context, stream Stream, msg *Message) (st
 
 // PublishGenJob 发布生成任务
 func (p *Producer) PublishGenJob(ctx context.Context, job *GenerationJobMessage) (string, error) {
+	job.SchemaVersion = GenerationJobSchemaVersion
 	msg, err := NewMessage(job.JobID, "chapter_gen", job.TenantID, job.ProjectID, job)
 	if err != nil {
 		return "", err
@@ -81,11 +82,12 @@ func (p *Producer) PublishGenJob(ctx context.Context, job *GenerationJobMessage)
 		msg.SetMetadata("idempotency_key", *job.IdempotencyKey)
 	}
 
-	return p.Publish(ctx, StreamStoryGen, msg)
+	return p.Publish(ctx, StoryGenStream(job.Priority), msg)
 }
 
 // PublishFoundationJob 发布设定集生成任务
 func (p *Producer) PublishFoundationJob(ctx context.Context, job *GenerationJobMessage) (string, error) {
+	job.SchemaVersion = GenerationJobSchemaVersion
 	msg, err := NewMessage(job.JobID, "foundation_gen", job.TenantID, job.ProjectID, job)
 	if err != nil {
 		return "", err
@@ -96,7 +98,23 @@ func (p *Producer) PublishFoundationJob(ctx context.Context, job *GenerationJobM
 		msg.SetMetadata("idempotency_key", *job.IdempotencyKey)
 	}
 
-	return p.Publish(ctx, StreamStoryGen, msg)
+	return p.Publish(ctx, StoryGenStream(job.Priority), msg)
+}
+
+// PublishMaintenanceJob 发布运维任务（导出/重建索引/清理向量等），消息类型即任务类型
+func (p *Producer) PublishMaintenanceJob(ctx context.Context, job *GenerationJobMessage) (string, error) {
+	job.SchemaVersion = GenerationJobSchemaVersion
+	msg, err := NewMessage(job.JobID, job.JobType, job.TenantID, job.ProjectID, job)
+	if err != nil {
+		return "", err
+	}
+
+	msg.SetMetadata("priority", fmt.Sprintf("%d", job.Priority))
+	if job.IdempotencyKey != nil {
+		msg.SetMetadata("idempotency_key", *job.IdempotencyKey)
+	}
+
+	return p.Publish(ctx, StoryGenStream(job.Priority), msg)
 }
 
 // PublishMemoryUpdate 发布记忆更新任务
@@ -129,6 +147,7 @@ type GenerationJobMessage struct {
 	JobType        string                 `json:"job_type"`
 	Priority       int                    `json:"priority"`
 	IdempotencyKey *string                `json:"idempotency_key,omitempty"`
+	SchemaVersion  int                    `json:"schema_version,omitempty"` // 载荷结构版本（发布时填充为 GenerationJobSchemaVersion）
 	Params         map[string]interface{} `json:"params"`
 }
 
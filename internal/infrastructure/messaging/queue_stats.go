@@ -0,0 +1,234 @@
+// Package messaging 提供消息队列实现
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"z-novel-ai-api/pkg/logger"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+// jobDurationSmoothing 任务耗时指数移动平均的平滑系数
+const jobDurationSmoothing = 0.2
+
+// QueueStats 队列积压统计（Worker 定期写入 Redis，网关据此估算排队位置与开始时间）
+type QueueStats struct {
+	Stream Stream `json:"stream"`
+	// Lag 尚未被任何 Worker 认领的消息数
+	Lag int64 `json:"lag"`
+	// Pending 已认领未确认的消息数（处理中或等待重试）
+	Pending int64 `json:"pending"`
+	// Workers 最近仍在上报心跳的 Worker 数
+	Workers int `json:"workers"`
+	// AvgJobMillis 各 Worker 任务耗时移动平均的均值（0 表示尚无样本）
+	AvgJobMillis int64     `json:"avg_job_ms"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Depth 队列深度（未认领 + 处理中）
+func (s *QueueStats) Depth() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.Lag + s.Pending
+}
+
+// QueueEstimate 新任务的排队估算
+type QueueEstimate struct {
+	// Position 新任务的排队位置（1 表示队首）
+	Position int64
+	// Wait 预计等待时长
+	Wait time.Duration
+	// StartAt 预计开始时间
+	StartAt time.Time
+}
+
+// Estimate 估算此刻入队任务的排队位置与开始时间（按 Worker 数并行消化，逐个串行处理）
+func (s *QueueStats) Estimate(now time.Time, defaultJob time.Duration) *QueueEstimate {
+	depth := s.Depth()
+	perJob := time.Duration(s.AvgJobMillis) * time.Millisecond
+	if perJob <= 0 {
+		perJob = defaultJob
+	}
+	workers := int64(s.Workers)
+	if workers <= 0 {
+		workers = 1
+	}
+	rounds := int64(math.Ceil(float64(depth) / float64(workers)))
+	wait := time.Duration(rounds) * perJob
+	return &QueueEstimate{
+		Position: depth + 1,
+		Wait:     wait,
+		StartAt:  now.Add(wait),
+	}
+}
+
+type workerHeartbeat struct {
+	AvgJobMillis int64     `json:"avg_job_ms"`
+	HeartbeatAt  time.Time `json:"heartbeat_at"`
+}
+
+func queueStatsKey(stream Stream) string {
+	return string(stream) + ":stats"
+}
+
+func queueWorkersKey(stream Stream) string {
+	return string(stream) + ":workers"
+}
+
+// QueueStats 读取 Worker 上报的队列统计；统计不存在或超过 maxAge 未更新时返回 nil（无 Worker 运行或未上报）
+func (p *Producer) QueueStats(ctx context.Context, stream Stream, maxAge time.Duration) (*QueueStats, error) {
+	raw, err := p.client.Get(ctx, queueStatsKey(stream)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stats QueueStats
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && time.Since(stats.UpdatedAt) > maxAge {
+		return nil, nil
+	}
+	return &stats, nil
+}
+
+// QueueStatsAhead 汇总优先级不低于 priority 的各任务流统计（新任务排在这些流的积压之后）；均未上报时返回 nil。
+// 同一批 Worker 消费全部任务流，Worker 数与任务耗时取各流上报值的最大值
+func (p *Producer) QueueStatsAhead(ctx context.Context, priority int, maxAge time.Duration) (*QueueStats, error) {
+	target := StoryGenStream(priority)
+	var merged *QueueStats
+	for _, stream := range StoryGenStreams {
+		stats, err := p.QueueStats(ctx, stream, maxAge)
+		if err != nil {
+			return nil, err
+		}
+		if stats != nil {
+			if merged == nil {
+				merged = &QueueStats{Stream: target, UpdatedAt: stats.UpdatedAt}
+			}
+			merged.Lag += stats.Lag
+			merged.Pending += stats.Pending
+			merged.Workers = max(merged.Workers, stats.Workers)
+			merged.AvgJobMillis = max(merged.AvgJobMillis, stats.AvgJobMillis)
+			if stats.UpdatedAt.Before(merged.UpdatedAt) {
+				merged.UpdatedAt = stats.UpdatedAt
+			}
+		}
+		if stream == target {
+			break
+		}
+	}
+	return merged, nil
+}
+
+// recordJobDuration 记录单个任务耗时（指数移动平均）
+func (c *Consumer) recordJobDuration(d time.Duration) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	ms := float64(d.Milliseconds())
+	if c.avgJobMillis <= 0 {
+		c.avgJobMillis = ms
+		return
+	}
+	c.avgJobMillis = c.avgJobMillis*(1-jobDurationSmoothing) + ms*jobDurationSmoothing
+}
+
+func (c *Consumer) jobDurationMillis() int64 {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return int64(c.avgJobMillis)
+}
+
+// PublishStats 定期上报本 Worker 心跳与任务耗时，并汇总写入队列统计（供网关背压估算）
+func (c *Consumer) PublishStats(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.publishStats(ctx, interval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Consumer) publishStats(ctx context.Context, interval time.Duration) {
+	for _, stream := range c.streams {
+		c.publishStreamStats(ctx, stream, interval)
+	}
+}
+
+func (c *Consumer) publishStreamStats(ctx context.Context, stream Stream, interval time.Duration) {
+	log := logger.FromContext(ctx)
+	now := time.Now()
+	staleAfter := 3 * interval
+	workersKey := queueWorkersKey(stream)
+
+	hb, _ := json.Marshal(workerHeartbeat{AvgJobMillis: c.jobDurationMillis(), HeartbeatAt: now})
+	if err := c.client.HSet(ctx, workersKey, c.consumerName, string(hb)).Err(); err != nil {
+		log.Warn("failed to publish worker heartbeat", "error", err)
+		return
+	}
+
+	entries, err := c.client.HGetAll(ctx, workersKey).Result()
+	if err != nil {
+		log.Warn("failed to load worker heartbeats", "error", err)
+		return
+	}
+	workers := 0
+	var sumMillis, samples int64
+	for name, raw := range entries {
+		var w workerHeartbeat
+		if json.Unmarshal([]byte(raw), &w) != nil || now.Sub(w.HeartbeatAt) > staleAfter {
+			c.client.HDel(ctx, workersKey, name)
+			continue
+		}
+		workers++
+		if w.AvgJobMillis > 0 {
+			sumMillis += w.AvgJobMillis
+			samples++
+		}
+	}
+
+	stats := QueueStats{Stream: stream, Workers: workers, UpdatedAt: now}
+	if samples > 0 {
+		stats.AvgJobMillis = sumMillis / samples
+	}
+	groups, err := c.client.XInfoGroups(ctx, string(stream)).Result()
+	if err != nil {
+		log.Warn("failed to query consumer group info", "error", err)
+		return
+	}
+	for _, g := range groups {
+		if g.Name != string(c.group) {
+			continue
+		}
+		stats.Pending = g.Pending
+		if g.Lag > 0 {
+			stats.Lag = g.Lag
+		}
+	}
+
+	metrics.RedisStreamLag.WithLabelValues(string(stream), string(c.group)).Set(float64(stats.Lag))
+	metrics.RedisStreamPending.WithLabelValues(string(stream), string(c.group)).Set(float64(stats.Pending))
+
+	data, _ := json.Marshal(stats)
+	if err := c.client.Set(ctx, queueStatsKey(stream), string(data), staleAfter).Err(); err != nil {
+		log.Warn("failed to publish queue stats", "error", err)
+	}
+}
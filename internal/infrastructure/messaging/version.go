@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 消息版本约定（api-gateway 与 job-worker 独立滚动发布）：
+// 生产者写入当前版本 N，消费者必须同时处理 N 与 N-1；未带版本的历史消息视为版本 0。
+// 不兼容变更（删除/重命名字段、改变语义）才提升版本，并分两步发布：
+//  1. 先发布可处理 N+1（且仍可处理 N）的消费者；
+//  2. 再发布写入 N+1 的生产者。回滚时顺序相反。
+//
+// 消费者收到高于 N 的消息（生产者先于消费者升级）时不猜测字段含义，留待重试由已升级的实例处理，
+// 超过重试次数进入死信队列（可重放）；低于 N-1 的消息已不再兼容，直接进入死信队列。
+const MessageVersion = 1
+
+// UnsupportedVersionError 消息（或载荷）版本超出当前消费者的兼容范围 [Current-1, Current]
+type UnsupportedVersionError struct {
+	Kind    string
+	Version int
+	Current int
+}
+
+func (e *UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("%s version %d is not supported (supported: %d..%d)", e.Kind, e.Version, e.Current-1, e.Current)
+}
+
+// Newer 是否由更新的生产者写入（升级完成后可被处理，值得重试）
+func (e *UnsupportedVersionError) Newer() bool {
+	return e.Version > e.Current
+}
+
+// AsUnsupportedVersion 提取版本不兼容错误
+func AsUnsupportedVersion(err error) (*UnsupportedVersionError, bool) {
+	var ve *UnsupportedVersionError
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	return nil, false
+}
+
+// CheckVersion 按 N / N-1 约定检查版本：version 须在 [current-1, current] 内
+func CheckVersion(kind string, version, current int) error {
+	if version > current || version < current-1 {
+		return &UnsupportedVersionError{Kind: kind, Version: version, Current: current}
+	}
+	return nil
+}
+
+// DecodePayload 校验消息版本后解析载荷
+func DecodePayload[T any](msg *Message) (*T, error) {
+	if err := msg.CheckVersion(); err != nil {
+		return nil, err
+	}
+	var payload T
+	if err := msg.UnmarshalPayload(&payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
@@ -0,0 +1,56 @@
+package messaging
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCheckVersionAcceptsCurrentAndPrevious(t *testing.T) {
+	for _, tc := range []struct {
+		version int
+		ok      bool
+		newer   bool
+	}{
+		{version: 3, ok: true},
+		{version: 2, ok: true},
+		{version: 4, newer: true},
+		{version: 1},
+	} {
+		err := CheckVersion("message", tc.version, 3)
+		if (err == nil) != tc.ok {
+			t.Fatalf("version %d: unexpected result %v", tc.version, err)
+		}
+		if ve, ok := AsUnsupportedVersion(err); ok && ve.Newer() != tc.newer {
+			t.Fatalf("version %d: newer=%v, want %v", tc.version, ve.Newer(), tc.newer)
+		}
+	}
+}
+
+func TestDecodePayloadChecksMessageVersion(t *testing.T) {
+	update := &MemoryUpdateMessage{TenantID: "tenant-1", ChapterID: "chapter-1", ChapterVersion: 2}
+	msg, err := NewMessage("m1", "memory_update", update.TenantID, "", update)
+	if err != nil {
+		t.Fatalf("new message: %v", err)
+	}
+	if msg.Version != MessageVersion {
+		t.Fatalf("new messages should carry version %d, got %d", MessageVersion, msg.Version)
+	}
+	got, err := DecodePayload[MemoryUpdateMessage](msg)
+	if err != nil || got.ChapterID != "chapter-1" || got.ChapterVersion != 2 {
+		t.Fatalf("decode: %+v (err=%v)", got, err)
+	}
+
+	// 版本字段上线前写入的消息（无 version）仍在 N-1 兼容范围内
+	var legacy Message
+	if err := json.Unmarshal([]byte(`{"id":"m0","type":"memory_update","payload":{"chapter_id":"c"}}`), &legacy); err != nil {
+		t.Fatalf("unmarshal legacy: %v", err)
+	}
+	if _, err := DecodePayload[MemoryUpdateMessage](&legacy); err != nil {
+		t.Fatalf("legacy message should decode: %v", err)
+	}
+
+	msg.Version = MessageVersion + 1
+	if _, err := DecodePayload[MemoryUpdateMessage](msg); err == nil {
+		t.Fatalf("message from a newer producer should be rejected")
+	}
+}
@@ -0,0 +1,167 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// ErrKeepObject 清理钩子返回该错误时保留对象（如仍被引用）
+var ErrKeepObject = errors.New("keep object")
+
+// LifecycleRule 过期清理规则：前缀下最后修改时间早于 MaxAge 的对象将被删除
+type LifecycleRule struct {
+	Prefix string
+	MaxAge time.Duration
+}
+
+// LifecycleRulesFromConfig 将配置转换为清理规则
+func LifecycleRulesFromConfig(cfg config.StorageLifecycleConfig) []LifecycleRule {
+	rules := make([]LifecycleRule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, LifecycleRule{Prefix: r.Prefix, MaxAge: r.MaxAge})
+	}
+	return rules
+}
+
+// CleanupHook 删除前回调：返回 ErrKeepObject 保留对象，返回其他错误则跳过本轮并记录日志
+type CleanupHook func(ctx context.Context, obj ObjectInfo) error
+
+// SweepResult 一轮清理结果
+type SweepResult struct {
+	Scanned int
+	Deleted int
+	Kept    int
+	Failed  int
+}
+
+// Lifecycle 对象生命周期清理器。各子系统通过 OnExpire 注册前缀钩子，
+// 用于删除前同步清理数据库引用或保留仍在使用的对象。
+type Lifecycle struct {
+	store Store
+	rules []LifecycleRule
+	now   func() time.Time
+
+	mu    sync.RWMutex
+	hooks map[string][]CleanupHook
+}
+
+// NewLifecycle 创建生命周期清理器（忽略前缀为空或 MaxAge 非正的规则）
+func NewLifecycle(store Store, rules []LifecycleRule) *Lifecycle {
+	valid := make([]LifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		if strings.TrimSpace(r.Prefix) == "" || r.MaxAge <= 0 {
+			continue
+		}
+		valid = append(valid, r)
+	}
+	return &Lifecycle{
+		store: store,
+		rules: valid,
+		now:   time.Now,
+		hooks: make(map[string][]CleanupHook),
+	}
+}
+
+// OnExpire 注册前缀清理钩子（匹配对象键前缀，可注册多个，按注册顺序执行）
+func (l *Lifecycle) OnExpire(prefix string, hook CleanupHook) {
+	if l == nil || hook == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks[prefix] = append(l.hooks[prefix], hook)
+}
+
+// Sweep 执行一轮过期清理
+func (l *Lifecycle) Sweep(ctx context.Context) (SweepResult, error) {
+	var res SweepResult
+	if l == nil || l.store == nil {
+		return res, nil
+	}
+
+	now := l.now()
+	for _, rule := range l.rules {
+		objects, err := l.store.List(ctx, rule.Prefix)
+		if err != nil {
+			return res, err
+		}
+		cutoff := now.Add(-rule.MaxAge)
+		for _, obj := range objects {
+			res.Scanned++
+			if !obj.LastModified.Before(cutoff) {
+				continue
+			}
+			if err := l.runHooks(ctx, obj); err != nil {
+				if errors.Is(err, ErrKeepObject) {
+					res.Kept++
+					continue
+				}
+				res.Failed++
+				logger.Warn(ctx, "object cleanup hook failed", "key", obj.Key, "error", err.Error())
+				continue
+			}
+			if err := l.store.Delete(ctx, obj.Key); err != nil {
+				res.Failed++
+				logger.Warn(ctx, "failed to delete expired object", "key", obj.Key, "error", err.Error())
+				continue
+			}
+			res.Deleted++
+		}
+	}
+	return res, nil
+}
+
+// Run 按间隔循环清理，直到 ctx 取消
+func (l *Lifecycle) Run(ctx context.Context, interval time.Duration) {
+	if l == nil || len(l.rules) == 0 || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		res, err := l.Sweep(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(ctx, "object lifecycle sweep failed", err)
+		} else if res.Deleted > 0 || res.Failed > 0 {
+			logger.Info(ctx, "object lifecycle sweep finished",
+				"scanned", res.Scanned, "deleted", res.Deleted, "kept", res.Kept, "failed", res.Failed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Lifecycle) runHooks(ctx context.Context, obj ObjectInfo) error {
+	l.mu.RLock()
+	prefixes := make([]string, 0, len(l.hooks))
+	for prefix := range l.hooks {
+		if strings.HasPrefix(obj.Key, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	var hooks []CleanupHook
+	for _, prefix := range prefixes {
+		hooks = append(hooks, l.hooks[prefix]...)
+	}
+	l.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
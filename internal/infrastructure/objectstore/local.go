@@ -0,0 +1,326 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	localTempPrefix = ".tmp-"
+
+	localQueryMethod    = "X-Method"
+	localQueryExpires   = "X-Expires"
+	localQuerySignature = "X-Signature"
+)
+
+// LocalStore 本地文件系统对象存储（开发/单机部署）。
+// 签名 URL 指向 BaseURL，由 Handler 校验 HMAC 签名后读写文件。
+type LocalStore struct {
+	root       string
+	baseURL    *url.URL
+	signingKey []byte
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewLocalStore 创建本地对象存储（signingKey 为空时不支持签名 URL）
+func NewLocalStore(root, baseURL, signingKey string, ttl time.Duration) (*LocalStore, error) {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return nil, fmt.Errorf("local storage root is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage root: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	s := &LocalStore{root: abs, ttl: ttl, now: time.Now}
+	if strings.TrimSpace(baseURL) != "" {
+		u, err := url.Parse(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage base url: %w", err)
+		}
+		s.baseURL = u
+	}
+	if key := strings.TrimSpace(signingKey); key != "" {
+		s.signingKey = []byte(key)
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultSignedURLTTL
+	}
+	return s, nil
+}
+
+// Put 写入对象（先写临时文件再原子替换）
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	_, p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), localTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp object: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	written, err := io.Copy(tmp, readerWithContext(ctx, body))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("failed to write object: short body (%d of %d bytes)", written, size)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to commit object: %w", err)
+	}
+	return nil
+}
+
+// Get 读取对象
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, p, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open object: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return f, s.info(key, st), nil
+}
+
+// Stat 获取对象元信息
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	if st.IsDir() {
+		return nil, ErrNotFound
+	}
+	return s.info(key, st), nil
+}
+
+// Delete 删除对象
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	_, p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// List 列出前缀下的全部对象
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	prefix = strings.TrimLeft(prefix, "/")
+	var out []ObjectInfo
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), localTempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		st, err := d.Info()
+		if err != nil {
+			return err
+		}
+		out = append(out, *s.info(key, st))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return out, nil
+}
+
+// SignedURL 生成指向 BaseURL 的 HMAC 签名 URL
+func (s *LocalStore) SignedURL(ctx context.Context, key, method string, ttl time.Duration) (string, error) {
+	if s.baseURL == nil || len(s.signingKey) == 0 {
+		return "", ErrSigningDisabled
+	}
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	method, err = normalizeSignMethod(method)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+
+	u := *s.baseURL
+	u.Path = u.Path + "/" + key
+	q := url.Values{}
+	q.Set(localQueryMethod, method)
+	q.Set(localQueryExpires, expires)
+	q.Set(localQuerySignature, s.sign(method, key, expires))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// MountPath 签名 URL 的路由前缀（BaseURL 的路径部分）
+func (s *LocalStore) MountPath() string {
+	if s.baseURL == nil {
+		return ""
+	}
+	return s.baseURL.Path
+}
+
+// Handler 处理签名 URL 请求：GET/HEAD 读取，PUT 上传
+func (s *LocalStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := CleanKey(strings.TrimPrefix(r.URL.Path, s.MountPath()))
+		if err != nil {
+			http.Error(w, "invalid object key", http.StatusBadRequest)
+			return
+		}
+
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		if !s.verify(method, key, r.URL.Query()) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			rc, info, err := s.Get(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					http.NotFound(w, r)
+					return
+				}
+				http.Error(w, "failed to read object", http.StatusInternalServerError)
+				return
+			}
+			defer rc.Close()
+			w.Header().Set("Content-Type", info.ContentType)
+			http.ServeContent(w, r, path.Base(key), info.LastModified, rc.(io.ReadSeeker))
+		case http.MethodPut:
+			opts := PutOptions{ContentType: r.Header.Get("Content-Type")}
+			if err := s.Put(r.Context(), key, r.Body, r.ContentLength, opts); err != nil {
+				http.Error(w, "failed to write object", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (s *LocalStore) verify(method, key string, q url.Values) bool {
+	if len(s.signingKey) == 0 || !strings.EqualFold(q.Get(localQueryMethod), method) {
+		return false
+	}
+	expires := q.Get(localQueryExpires)
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(s.sign(method, key, expires)), []byte(q.Get(localQuerySignature)))
+}
+
+func (s *LocalStore) sign(method, key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(method + "\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path 返回规范化后的对象键与文件路径
+func (s *LocalStore) path(key string) (string, string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	return key, filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStore) info(key string, st fs.FileInfo) *ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         st.Size(),
+		ContentType:  contentType,
+		ETag:         fmt.Sprintf("%x-%x", st.ModTime().UnixNano(), st.Size()),
+		LastModified: st.ModTime(),
+	}
+}
+
+// readerWithContext 在每次读取前检查 context，便于取消大文件写入
+func readerWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return r.Read(p)
+	})
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
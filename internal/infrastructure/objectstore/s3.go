@@ -0,0 +1,328 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const s3ErrorBodyLimit = 4 << 10
+
+// S3Options S3 兼容存储参数（AWS S3 / MinIO / Cloudflare R2）
+type S3Options struct {
+	// Endpoint 服务地址（如 https://s3.us-east-1.amazonaws.com、http://minio:9000）；为空时按 Region 推导 AWS 地址
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// UsePathStyle 使用 <endpoint>/<bucket>/<key> 寻址（MinIO/R2 需要）；否则使用虚拟主机寻址
+	UsePathStyle bool
+	SignedURLTTL time.Duration
+	HTTPClient   *http.Client
+}
+
+// S3Store S3 兼容对象存储（基于 REST API + SigV4 签名，不依赖 SDK）
+type S3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    *sigV4Signer
+	ttl       time.Duration
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Store 创建 S3 兼容对象存储
+func NewS3Store(opts S3Options) (*S3Store, error) {
+	bucket := strings.TrimSpace(opts.Bucket)
+	if bucket == "" {
+		return nil, fmt.Errorf("storage bucket is required")
+	}
+	if strings.TrimSpace(opts.AccessKeyID) == "" || strings.TrimSpace(opts.SecretAccessKey) == "" {
+		return nil, fmt.Errorf("storage access key id and secret access key are required")
+	}
+	region := strings.TrimSpace(opts.Region)
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := strings.TrimSpace(opts.Endpoint)
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid storage endpoint: %s", endpoint)
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	ttl := opts.SignedURLTTL
+	if ttl <= 0 {
+		ttl = defaultSignedURLTTL
+	}
+
+	return &S3Store{
+		endpoint:  u,
+		bucket:    bucket,
+		pathStyle: opts.UsePathStyle,
+		signer: &sigV4Signer{
+			accessKeyID:     strings.TrimSpace(opts.AccessKeyID),
+			secretAccessKey: strings.TrimSpace(opts.SecretAccessKey),
+			region:          region,
+		},
+		ttl:    ttl,
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Put 写入对象；长度未知时先读入内存以便设置 Content-Length
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		buf, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read object body: %w", err)
+		}
+		body, size = bytes.NewReader(buf), int64(len(buf))
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	if opts.ContentDisposition != "" {
+		req.Header.Set("Content-Disposition", opts.ContentDisposition)
+	}
+	if opts.CacheControl != "" {
+		req.Header.Set("Cache-Control", opts.CacheControl)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Get 读取对象
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, infoFromHeader(key, resp), nil
+}
+
+// Stat 获取对象元信息
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return infoFromHeader(key, resp), nil
+}
+
+// Delete 删除对象（S3 对不存在的对象同样返回 204）
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List 列出前缀下的全部对象（ListObjectsV2，自动翻页）
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	token := ""
+	for {
+		q := url.Values{}
+		q.Set("list-type", "2")
+		if prefix = strings.TrimLeft(prefix, "/"); prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", q, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var page listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode list objects response: %w", err)
+		}
+
+		for _, c := range page.Contents {
+			out = append(out, ObjectInfo{
+				Key:          c.Key,
+				Size:         c.Size,
+				ETag:         strings.Trim(c.ETag, `"`),
+				LastModified: c.LastModified,
+			})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// SignedURL 生成 SigV4 预签名 URL
+func (s *S3Store) SignedURL(ctx context.Context, key, method string, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	method, err = normalizeSignMethod(method)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+	return s.signer.presign(method, s.objectURL(key, nil), s.now(), ttl), nil
+}
+
+// objectURL 构造对象地址（key 为空时指向桶）
+func (s *S3Store) objectURL(key string, q url.Values) *url.URL {
+	u := *s.endpoint
+	p := strings.TrimRight(u.Path, "/")
+	if s.pathStyle {
+		p += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	p += "/" + key
+	u.Path = p
+	u.RawPath = canonicalURI(&u)
+	if q != nil {
+		u.RawQuery = canonicalQuery(q)
+	}
+	return &u
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, q url.Values, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage request: %w", err)
+	}
+	req.URL = s.objectURL(key, q)
+	req.Host = req.URL.Host
+	return req, nil
+}
+
+// do 签名并发送请求；404 返回 ErrNotFound，其他非 2xx 解析 S3 错误体
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	s.signer.signRequest(req, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLimit))
+	_ = xml.Unmarshal(raw, &apiErr)
+	if apiErr.Code == "" {
+		apiErr.Code = resp.Status
+	}
+	return nil, fmt.Errorf("storage %s %s: %s: %s", req.Method, req.URL.Path, apiErr.Code, apiErr.Message)
+}
+
+func infoFromHeader(key string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if info.Size < 0 {
+		info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	return info
+}
@@ -0,0 +1,299 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 路径寻址的最小 S3 实现：按收到的原始请求重新计算 SigV4 签名并校验，
+// 覆盖签名路径与实际发送路径（RawPath）、签名头与实际请求头的一致性
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+	signer *sigV4Signer
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	// pageSize ListObjectsV2 每页条数（用于覆盖翻页）
+	pageSize int
+}
+
+type fakeObject struct {
+	body        string
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		t:        t,
+		bucket:   "novel-assets",
+		signer:   &sigV4Signer{accessKeyID: "AKID", secretAccessKey: "SECRET", region: "auto"},
+		objects:  map[string]fakeObject{},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.verify(r); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>%s</Message></Error>", err)
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, r.URL.Query())
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if r.ContentLength != int64(len(body)) {
+			f.t.Errorf("content length = %d, body = %d bytes", r.ContentLength, len(body))
+		}
+		f.objects[key] = fakeObject{body: string(body), contentType: r.Header.Get("Content-Type")}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(obj.body)))
+		w.Header().Set("ETag", `"etag-`+key+`"`)
+		w.Header().Set("Last-Modified", "Wed, 01 Oct 2025 08:00:00 GMT")
+		if r.Method == http.MethodGet {
+			_, _ = io.WriteString(w, obj.body)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) verify(r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	sigAt := strings.LastIndex(auth, "Signature=")
+	if sigAt < 0 {
+		return errors.New("missing signature")
+	}
+	amzDate, err := time.Parse(sigV4DateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return err
+	}
+	// 以服务端视角重建请求：路径取线上实际发送的编码形式
+	wire := r.Clone(r.Context())
+	wire.URL = &url.URL{Host: r.Host, Path: r.URL.Path, RawPath: r.URL.EscapedPath(), RawQuery: r.URL.RawQuery}
+	wire.Header.Del("Authorization")
+	for name := range wire.Header {
+		if l := strings.ToLower(name); l == "content-length" {
+			wire.Header.Del(name)
+		}
+	}
+	_, canonical := canonicalRequest(wire, r.Header.Get("X-Amz-Content-Sha256"))
+	if canonicalURI(wire.URL) != wire.URL.EscapedPath() {
+		return fmt.Errorf("sent path %s differs from signed path %s", wire.URL.EscapedPath(), canonicalURI(wire.URL))
+	}
+	want := f.signer.signature(amzDate, stringToSign(r.Header.Get("X-Amz-Date"), f.signer.scope(amzDate), canonical))
+	if got := auth[sigAt+len("Signature="):]; got != want {
+		return fmt.Errorf("signature mismatch for %s %s", r.Method, r.URL.EscapedPath())
+	}
+	return nil
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, q url.Values) {
+	if q.Get("list-type") != "2" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, q.Get("prefix")) && k > q.Get("continuation-token") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+		ETag string `xml:"ETag"`
+	}
+	out := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}{}
+	if len(keys) > f.pageSize {
+		keys = keys[:f.pageSize]
+		out.IsTruncated = true
+		out.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, k := range keys {
+		out.Contents = append(out.Contents, content{Key: k, Size: int64(len(f.objects[k].body)), ETag: `"etag-` + k + `"`})
+	}
+	_ = xml.NewEncoder(w).Encode(out)
+}
+
+func newTestS3Store(t *testing.T, endpoint string, pathStyle bool) *S3Store {
+	t.Helper()
+	store, err := NewS3Store(S3Options{
+		Endpoint:        endpoint,
+		Region:          "auto",
+		Bucket:          "novel-assets",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+		UsePathStyle:    pathStyle,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3StoreObjectLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake, srv := newFakeS3(t)
+	store := newTestS3Store(t, srv.URL, true)
+
+	keys := []string{"exports/p1/第 1 章.txt", "exports/p1/a+b=c.txt", "exports/p1/notes.md", "exports/p2/x.txt"}
+	for i, key := range keys {
+		size := int64(-1) // 长度未知时先读入内存
+		if i%2 == 0 {
+			size = int64(len(key))
+		}
+		if err := store.Put(ctx, "/"+key, strings.NewReader(key), size, PutOptions{ContentType: "text/plain; charset=utf-8"}); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+	if err := store.Put(ctx, "exports/empty.txt", strings.NewReader(""), 0, PutOptions{}); err != nil {
+		t.Fatalf("put empty object: %v", err)
+	}
+	if obj := fake.objects["exports/empty.txt"]; obj.contentType != "application/octet-stream" {
+		t.Fatalf("default content type = %q", obj.contentType)
+	}
+
+	body, info, err := store.Get(ctx, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := io.ReadAll(body)
+	_ = body.Close()
+	if string(raw) != keys[0] || info.Size != int64(len(keys[0])) || info.ContentType != "text/plain; charset=utf-8" || info.ETag != "etag-"+keys[0] {
+		t.Fatalf("get = %q %+v", raw, info)
+	}
+	if info.LastModified.IsZero() {
+		t.Fatal("last modified not parsed")
+	}
+	info, err = store.Stat(ctx, keys[1])
+	if err != nil || info.Size != int64(len(keys[1])) {
+		t.Fatalf("stat = %+v, %v", info, err)
+	}
+
+	// 翻页列出前缀下的全部对象
+	list, err := store.List(ctx, "exports/p1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, o := range list {
+		listed = append(listed, o.Key)
+	}
+	if want := []string{keys[2], keys[1], keys[0]}; strings.Join(listed, "|") != strings.Join(sortedCopy(want), "|") {
+		t.Fatalf("list = %v", listed)
+	}
+
+	if err := store.Delete(ctx, keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Get(ctx, keys[0]); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get deleted object err = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Stat(ctx, keys[0]); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("stat deleted object err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "exports/missing.txt"); err != nil {
+		t.Fatalf("delete missing object: %v", err)
+	}
+	if err := store.Put(ctx, "../escape", strings.NewReader("x"), 1, PutOptions{}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("path traversal err = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestS3StoreSurfacesServiceErrors(t *testing.T) {
+	_, srv := newFakeS3(t)
+	store, err := NewS3Store(S3Options{Endpoint: srv.URL, Bucket: "novel-assets", AccessKeyID: "AKID", SecretAccessKey: "WRONG", Region: "auto", UsePathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(context.Background(), "a.txt", strings.NewReader("x"), 1, PutOptions{})
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("err = %v, want SignatureDoesNotMatch from the service", err)
+	}
+}
+
+func TestS3StoreSignedURL(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3Store(t, "https://s3.example.com", false)
+	store.now = func() time.Time { return time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC) }
+
+	raw, err := store.SignedURL(ctx, "exports/第 1 章.txt", "put", 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 虚拟主机寻址：桶名进入 Host，路径逐段编码
+	if u.Host != "novel-assets.s3.example.com" || u.EscapedPath() != "/exports/%E7%AC%AC%201%20%E7%AB%A0.txt" {
+		t.Fatalf("signed url = %s", raw)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Expires") != "600" || q.Get("X-Amz-Credential") != "AKID/20261001/auto/s3/aws4_request" || q.Get("X-Amz-Signature") == "" {
+		t.Fatalf("signed url query = %v", q)
+	}
+	// 签名可由服务端按同一规范请求复算
+	sig := q.Get("X-Amz-Signature")
+	q.Del("X-Amz-Signature")
+	canonical := presignedCanonicalRequest(http.MethodPut, u, q)
+	if want := store.signer.signature(store.now(), stringToSign("20261001T080000Z", store.signer.scope(store.now()), canonical)); sig != want {
+		t.Fatalf("signature = %s, want %s", sig, want)
+	}
+
+	if _, err := store.SignedURL(ctx, "a.txt", "DELETE", 0); err == nil {
+		t.Fatal("expected unsupported method error")
+	}
+	raw, _ = store.SignedURL(ctx, "a.txt", "", 0)
+	if u, _ := url.Parse(raw); u.Query().Get("X-Amz-Expires") != fmt.Sprint(int(defaultSignedURLTTL/time.Second)) {
+		t.Fatalf("default ttl not applied: %s", raw)
+	}
+}
+
+func sortedCopy(in []string) []string {
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,198 @@
+package objectstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4（S3 兼容服务通用）
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4Service     = "s3"
+	sigV4DateFormat  = "20060102T150405Z"
+	sigV4ShortFormat = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+type sigV4Signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+}
+
+// signRequest 以请求头方式签名。
+// 调用方未设置 x-amz-content-sha256 时使用 UNSIGNED-PAYLOAD（支持流式上传），否则按已设置的负载哈希签名
+func (s *sigV4Signer) signRequest(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		payloadHash = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonical := canonicalRequest(req, payloadHash)
+	scope := s.scope(now)
+	signature := s.signature(now, stringToSign(amzDate, scope, canonical))
+	req.Header.Set("Authorization", sigV4Algorithm+
+		" Credential="+s.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+}
+
+// presign 生成查询串签名 URL（仅签 host 头）
+func (s *sigV4Signer) presign(method string, u *url.URL, now time.Time, ttl time.Duration) string {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	scope := s.scope(now)
+
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", sigV4Algorithm)
+	q.Set("X-Amz-Credential", s.accessKeyID+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", expiresSeconds(ttl))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := presignedCanonicalRequest(method, u, q)
+	q.Set("X-Amz-Signature", s.signature(now, stringToSign(amzDate, scope, canonical)))
+	out := *u
+	out.RawQuery = canonicalQuery(q)
+	return out.String()
+}
+
+// canonicalRequest 请求头签名的规范请求，返回签名头列表与规范请求
+func canonicalRequest(req *http.Request, payloadHash string) (string, string) {
+	signedHeaders, headers := canonicalHeaders(req)
+	return signedHeaders, strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+// presignedCanonicalRequest 查询串签名的规范请求（q 为不含 X-Amz-Signature 的查询参数）
+func presignedCanonicalRequest(method string, u *url.URL, q url.Values) string {
+	return strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+}
+
+func (s *sigV4Signer) scope(now time.Time) string {
+	return now.UTC().Format(sigV4ShortFormat) + "/" + s.region + "/" + sigV4Service + "/aws4_request"
+}
+
+func (s *sigV4Signer) signature(now time.Time, toSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.UTC().Format(sigV4ShortFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, sigV4Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func stringToSign(amzDate, scope, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+}
+
+// canonicalHeaders 签名 host 与全部 x-amz-* / content-* 头
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "content-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(headers[name])
+		b.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalURI 逐段编码路径（请求发送时 RawPath 取同一编码，保证与签名一致）
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 按 SigV4 规则编码（仅保留 A-Z a-z 0-9 - _ . ~）
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// expiresSeconds 预签名有效期（秒），限制在 [1, 7 天]
+func expiresSeconds(d time.Duration) string {
+	secs := int64(d / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	if maxSecs := int64(7 * 24 * time.Hour / time.Second); secs > maxSecs {
+		secs = maxSecs
+	}
+	return strconv.FormatInt(secs, 10)
+}
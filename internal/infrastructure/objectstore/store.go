@@ -0,0 +1,147 @@
+// Package objectstore 提供对象存储抽象（S3 / MinIO / R2 / 本地文件系统），
+// 供导出、附件、音频、归档等子系统共享同一实现。
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/config"
+)
+
+// 驱动名称
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverMinIO = "minio"
+	DriverR2    = "r2"
+)
+
+const defaultSignedURLTTL = 15 * time.Minute
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey 对象键非法（为空、绝对路径或包含 ..）
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrSigningDisabled 未配置签名密钥，无法生成签名 URL
+	ErrSigningDisabled = errors.New("signed urls are not configured")
+)
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// PutOptions 写入选项
+type PutOptions struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+}
+
+// Store 对象存储接口
+type Store interface {
+	// Put 写入对象（size < 0 表示长度未知）
+	Put(ctx context.Context, key string, body io.Reader, size int64, opts PutOptions) error
+
+	// Get 读取对象；不存在时返回 ErrNotFound，调用方负责关闭 Reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+
+	// Stat 获取对象元信息；不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// Delete 删除对象（不存在视为成功）
+	Delete(ctx context.Context, key string) error
+
+	// List 列出前缀下的全部对象
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+
+	// SignedURL 生成限时签名 URL（method 为 GET 或 PUT；ttl <= 0 时使用默认有效期）
+	SignedURL(ctx context.Context, key, method string, ttl time.Duration) (string, error)
+}
+
+// NewStore 按配置创建对象存储
+func NewStore(cfg *config.StorageConfig) (Store, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("storage config is nil")
+	}
+	ttl := cfg.SignedURLTTL
+	if ttl <= 0 {
+		ttl = defaultSignedURLTTL
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Driver)) {
+	case "", DriverLocal:
+		return NewLocalStore(cfg.Local.Root, cfg.Local.BaseURL, cfg.Local.SigningKey, ttl)
+	case DriverS3:
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UsePathStyle:    cfg.S3.UsePathStyle,
+			SignedURLTTL:    ttl,
+		})
+	case DriverMinIO:
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UsePathStyle:    true,
+			SignedURLTTL:    ttl,
+		})
+	case DriverR2:
+		return NewS3Store(S3Options{
+			Endpoint:        fmt.Sprintf("https://%s.r2.cloudflarestorage.com", strings.TrimSpace(cfg.R2.AccountID)),
+			Region:          "auto",
+			Bucket:          cfg.R2.Bucket,
+			AccessKeyID:     cfg.R2.AccessKeyID,
+			SecretAccessKey: cfg.R2.SecretAccessKey,
+			UsePathStyle:    true,
+			SignedURLTTL:    ttl,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
+
+// CleanKey 规范化对象键：去除首尾斜杠并拒绝路径穿越
+func CleanKey(key string) (string, error) {
+	key = strings.Trim(strings.TrimSpace(key), "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return path.Clean(key), nil
+}
+
+// normalizeSignMethod 仅允许签发读取与上传 URL
+func normalizeSignMethod(method string) (string, error) {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	switch method {
+	case "":
+		return http.MethodGet, nil
+	case http.MethodGet, http.MethodPut:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unsupported signed url method: %s", method)
+	}
+}
@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/billing"
+)
+
+const (
+	genericTimestampHeader = "X-Webhook-Timestamp"
+	genericSignatureHeader = "X-Webhook-Signature"
+
+	genericEventPaymentSucceeded = "payment.succeeded"
+)
+
+// GenericProvider 通用支付回调适配，供自托管部署接入自建或其他支付处理方。
+// 协议：X-Webhook-Timestamp 为 Unix 秒；X-Webhook-Signature 为 HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制摘要。
+// 请求体：{"id","type":"payment.succeeded","tenant_id","payment_id","amount","currency","tokens","paid_at"}。
+type GenericProvider struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewGenericProvider 创建通用支付回调适配
+func NewGenericProvider(secret string, tolerance time.Duration) *GenericProvider {
+	if tolerance <= 0 {
+		tolerance = defaultSignatureTolerance
+	}
+	return &GenericProvider{secret: secret, tolerance: tolerance, now: time.Now}
+}
+
+// Name 提供商标识
+func (p *GenericProvider) Name() string {
+	return "generic"
+}
+
+type genericEvent struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	TenantID  string     `json:"tenant_id"`
+	PaymentID string     `json:"payment_id"`
+	Amount    int64      `json:"amount"`
+	Currency  string     `json:"currency"`
+	Tokens    int64      `json:"tokens"`
+	PaidAt    *time.Time `json:"paid_at"`
+}
+
+// ParseWebhook 校验签名并解析 payment.succeeded 事件
+func (p *GenericProvider) ParseWebhook(header http.Header, payload []byte) (*billing.PaymentEvent, error) {
+	timestamp := strings.TrimSpace(header.Get(genericTimestampHeader))
+	signature := strings.TrimPrefix(strings.TrimSpace(header.Get(genericSignatureHeader)), "sha256=")
+	if timestamp == "" || signature == "" {
+		return nil, fmt.Errorf("%w: missing signature headers", billing.ErrInvalidSignature)
+	}
+	if err := verifyTimestamp(timestamp, p.tolerance, p.now()); err != nil {
+		return nil, err
+	}
+	if !signatureMatches(computeSignature(p.secret, timestamp, payload), signature) {
+		return nil, billing.ErrInvalidSignature
+	}
+
+	var evt genericEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, fmt.Errorf("%w: %v", billing.ErrInvalidPayload, err)
+	}
+	out := &billing.PaymentEvent{EventID: evt.ID, Type: evt.Type}
+	if evt.Type != genericEventPaymentSucceeded {
+		return out, nil
+	}
+
+	tenantID := strings.TrimSpace(evt.TenantID)
+	paymentID := strings.TrimSpace(evt.PaymentID)
+	if tenantID == "" || paymentID == "" || evt.Tokens < 0 {
+		return nil, fmt.Errorf("%w: missing tenant_id or payment_id", billing.ErrInvalidPayload)
+	}
+
+	out.Succeeded = true
+	out.TenantID = tenantID
+	out.ExternalID = paymentID
+	out.Amount = evt.Amount
+	out.Currency = evt.Currency
+	out.Tokens = evt.Tokens
+	if evt.PaidAt != nil {
+		out.PaidAt = *evt.PaidAt
+	}
+	return out, nil
+}
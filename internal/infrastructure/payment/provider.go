@@ -0,0 +1,29 @@
+package payment
+
+import (
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/application/billing"
+	"z-novel-ai-api/internal/config"
+)
+
+// NewProvider 按配置创建支付提供商适配
+func NewProvider(cfg *config.BillingConfig) (billing.PaymentProvider, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("billing config is nil")
+	}
+	secret := strings.TrimSpace(cfg.WebhookSecret)
+	if secret == "" {
+		return nil, fmt.Errorf("billing webhook secret is required")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", "stripe":
+		return NewStripeProvider(secret, cfg.SignatureTolerance), nil
+	case "generic":
+		return NewGenericProvider(secret, cfg.SignatureTolerance), nil
+	default:
+		return nil, fmt.Errorf("unsupported billing provider: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,46 @@
+// Package payment 提供支付提供商 Webhook 适配实现
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"z-novel-ai-api/internal/application/billing"
+)
+
+// defaultSignatureTolerance 未配置时签名时间戳允许的最大偏差
+const defaultSignatureTolerance = 5 * time.Minute
+
+// computeSignature 计算 HMAC-SHA256(secret, "<timestamp>.<payload>") 的十六进制摘要
+func computeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyTimestamp 校验签名时间戳（Unix 秒）在容忍范围内，防止重放
+func verifyTimestamp(timestamp string, tolerance time.Duration, now time.Time) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", billing.ErrInvalidSignature)
+	}
+	skew := now.Sub(time.Unix(sec, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", billing.ErrInvalidSignature)
+	}
+	return nil
+}
+
+// signatureMatches 常量时间比较十六进制签名
+func signatureMatches(expected, actual string) bool {
+	return hmac.Equal([]byte(expected), []byte(actual))
+}
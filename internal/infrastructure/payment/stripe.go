@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/billing"
+)
+
+const (
+	stripeSignatureHeader = "Stripe-Signature"
+
+	stripeEventCheckoutCompleted      = "checkout.session.completed"
+	stripeEventCheckoutAsyncSucceeded = "checkout.session.async_payment_succeeded"
+)
+
+// StripeProvider Stripe Checkout Webhook 适配。
+// 约定：创建 Checkout Session 时在 metadata 中写入 tenant_id 与 tokens（或使用 client_reference_id 传递租户 ID）。
+type StripeProvider struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewStripeProvider 创建 Stripe 适配
+func NewStripeProvider(secret string, tolerance time.Duration) *StripeProvider {
+	if tolerance <= 0 {
+		tolerance = defaultSignatureTolerance
+	}
+	return &StripeProvider{secret: secret, tolerance: tolerance, now: time.Now}
+}
+
+// Name 提供商标识
+func (p *StripeProvider) Name() string {
+	return "stripe"
+}
+
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object stripeCheckoutSession `json:"object"`
+	} `json:"data"`
+}
+
+type stripeCheckoutSession struct {
+	ID                string            `json:"id"`
+	PaymentStatus     string            `json:"payment_status"`
+	AmountTotal       int64             `json:"amount_total"`
+	Currency          string            `json:"currency"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Metadata          map[string]string `json:"metadata"`
+}
+
+// ParseWebhook 校验 Stripe-Signature（t=时间戳,v1=签名）并解析 Checkout 支付完成事件
+func (p *StripeProvider) ParseWebhook(header http.Header, payload []byte) (*billing.PaymentEvent, error) {
+	if err := p.verify(header.Get(stripeSignatureHeader), payload); err != nil {
+		return nil, err
+	}
+
+	var evt stripeEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return nil, fmt.Errorf("%w: %v", billing.ErrInvalidPayload, err)
+	}
+	out := &billing.PaymentEvent{EventID: evt.ID, Type: evt.Type}
+	if evt.Type != stripeEventCheckoutCompleted && evt.Type != stripeEventCheckoutAsyncSucceeded {
+		return out, nil
+	}
+
+	session := evt.Data.Object
+	// 异步支付方式（如银行转账）在 completed 时仍为 unpaid，到账后另行推送 async_payment_succeeded
+	if session.PaymentStatus != "paid" {
+		return out, nil
+	}
+
+	tenantID := strings.TrimSpace(session.Metadata["tenant_id"])
+	if tenantID == "" {
+		tenantID = strings.TrimSpace(session.ClientReferenceID)
+	}
+	if tenantID == "" || session.ID == "" {
+		return nil, fmt.Errorf("%w: missing tenant_id or session id", billing.ErrInvalidPayload)
+	}
+
+	var tokens int64
+	if raw := strings.TrimSpace(session.Metadata["tokens"]); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%w: invalid tokens metadata", billing.ErrInvalidPayload)
+		}
+		tokens = v
+	}
+
+	out.Succeeded = true
+	out.TenantID = tenantID
+	out.ExternalID = session.ID
+	out.Amount = session.AmountTotal
+	out.Currency = session.Currency
+	out.Tokens = tokens
+	if evt.Created > 0 {
+		out.PaidAt = time.Unix(evt.Created, 0)
+	}
+	return out, nil
+}
+
+// verify 校验签名头；头中可能包含多个 v1（密钥轮换期间），任一匹配即通过
+func (p *StripeProvider) verify(signatureHeader string, payload []byte) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: missing %s header", billing.ErrInvalidSignature, stripeSignatureHeader)
+	}
+	if err := verifyTimestamp(timestamp, p.tolerance, p.now()); err != nil {
+		return err
+	}
+
+	expected := computeSignature(p.secret, timestamp, payload)
+	for _, sig := range signatures {
+		if signatureMatches(expected, sig) {
+			return nil
+		}
+	}
+	return billing.ErrInvalidSignature
+}
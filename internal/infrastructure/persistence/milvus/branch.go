@@ -0,0 +1,116 @@
+package milvus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SupportsBranches 集合是否包含 branch_key 字段（须在 EnsureStorySegmentsCollection 之后调用）
+func (r *Repository) SupportsBranches() bool {
+	return r != nil && r.hasBranchKey()
+}
+
+// DeleteSegmentsByBranch 删除分支片段；chapterID / segmentType 为空表示不按该维度过滤。主线片段不可经此删除。
+func (r *Repository) DeleteSegmentsByBranch(ctx context.Context, tenantID, projectID, chapterID, segmentType, branchKey string) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	branchKey = normalizeBranchKey(branchKey)
+	if branchKey == MainBranchKey {
+		return fmt.Errorf("main branch segments cannot be deleted by branch")
+	}
+	if err := checkBranchKey(branchKey); err != nil {
+		return err
+	}
+	if !r.hasBranchKey() {
+		return nil
+	}
+
+	ctx, span := tracer.Start(ctx, "milvus.DeleteSegmentsByBranch",
+		trace.WithAttributes(
+			attribute.String("project_id", projectID),
+			attribute.String("branch_key", branchKey),
+		))
+	defer span.End()
+
+	collName := r.client.CollectionName(CollectionStorySegments)
+	partitionName := PartitionName(tenantID, projectID)
+
+	if has, err := r.client.milvus.HasPartition(ctx, collName, partitionName); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to check partition: %w", err)
+	} else if !has {
+		return nil
+	}
+
+	filter := fmt.Sprintf(`%s == "%s"`, FieldBranchKey, branchKey)
+	if chapterID = strings.TrimSpace(chapterID); chapterID != "" {
+		filter += fmt.Sprintf(` && chapter_id == "%s"`, chapterID)
+	}
+	if segmentType = strings.TrimSpace(segmentType); segmentType != "" {
+		filter += fmt.Sprintf(` && segment_type == "%s"`, segmentType)
+	}
+	if err := r.client.milvus.Delete(ctx, collName, partitionName, filter); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete segments: %w", err)
+	}
+	return nil
+}
+
+// branchSearchFilter 分支检索的过滤表达式：主线只读主线片段；其他分支读取本分支片段，
+// 并对本分支没有片段的文档回退到主线片段（分支覆盖的文档先经一次遍历得到）。
+func (r *Repository) branchSearchFilter(ctx context.Context, params *SearchParams) (string, error) {
+	branchKey := normalizeBranchKey(params.BranchKey)
+	mainFilter := fmt.Sprintf(`%s == "%s"`, FieldBranchKey, MainBranchKey)
+	if branchKey == MainBranchKey {
+		return mainFilter, nil
+	}
+	if err := checkBranchKey(branchKey); err != nil {
+		return "", err
+	}
+
+	branchFilter := fmt.Sprintf(`%s == "%s"`, FieldBranchKey, branchKey)
+	expr := projectFilter(params.TenantID, params.ProjectID, nil) + " && " + branchFilter
+	seen := make(map[string]struct{})
+	var docs []string
+	if err := r.scan(ctx, params.TenantID, params.ProjectID, expr, []string{"id", "chapter_id"}, MaxScanBatch, func(rs client.ResultSet) error {
+		if col, ok := rs.GetColumn("chapter_id").(*entity.ColumnVarChar); ok {
+			for _, id := range col.Data() {
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+				docs = append(docs, fmt.Sprintf(`"%s"`, id))
+			}
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if len(docs) == 0 {
+		return mainFilter, nil
+	}
+	return fmt.Sprintf(`(%s || (%s && chapter_id not in [%s]))`, branchFilter, mainFilter, strings.Join(docs, ", ")), nil
+}
+
+// normalizeBranchKey 空值为主线
+func normalizeBranchKey(branchKey string) string {
+	if bk := strings.TrimSpace(branchKey); bk != "" {
+		return bk
+	}
+	return MainBranchKey
+}
+
+// checkBranchKey 分支名会拼入过滤表达式，拒绝引号/反斜杠避免表达式注入
+func checkBranchKey(branchKey string) error {
+	if strings.ContainsAny(branchKey, `"\`) {
+		return fmt.Errorf("invalid branch key")
+	}
+	return nil
+}
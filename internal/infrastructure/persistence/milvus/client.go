@@ -4,13 +4,17 @@ package milvus
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 
 	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/pkg/deadline"
 )
 
 var tracer = otel.Tracer("milvus")
@@ -28,15 +32,21 @@ func NewClient(ctx context.Context, cfg *config.MilvusConfig) (*Client, error) {
 	var milvusClient client.Client
 	var err error
 
+	// 在默认连接参数之上追加截止时间上报拦截器（位于 SDK 重试拦截器外层，观察最终结果）
+	dialOptions := append(append([]grpc.DialOption{}, client.DefaultGrpcOpts...),
+		grpc.WithChainUnaryInterceptor(deadlineInterceptor))
+
 	if cfg.User != "" && cfg.Password != "" {
 		milvusClient, err = client.NewClient(ctx, client.Config{
-			Address:  addr,
-			Username: cfg.User,
-			Password: cfg.Password,
+			Address:     addr,
+			Username:    cfg.User,
+			Password:    cfg.Password,
+			DialOptions: dialOptions,
 		})
 	} else {
 		milvusClient, err = client.NewClient(ctx, client.Config{
-			Address: addr,
+			Address:     addr,
+			DialOptions: dialOptions,
 		})
 	}
 
@@ -50,6 +60,13 @@ func NewClient(ctx context.Context, cfg *config.MilvusConfig) (*Client, error) {
 	}, nil
 }
 
+// deadlineInterceptor 调用因请求截止时间超时时上报向量库依赖
+func deadlineInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	deadline.Observe(ctx, deadline.VectorDB, err)
+	return err
+}
+
 // Milvus 获取底层 Milvus 客户端
 func (c *Client) Milvus() client.Client {
 	return c.milvus
@@ -90,6 +107,34 @@ func (c *Client) HasCollection(ctx context.Context, name string) (bool, error) {
 	return c.milvus.HasCollection(ctx, c.CollectionName(name))
 }
 
+// VectorDimension 获取集合向量字段的维度（集合不存在时返回 0）
+func (c *Client) VectorDimension(ctx context.Context, name string) (int, error) {
+	ctx, span := tracer.Start(ctx, "milvus.VectorDimension",
+		trace.WithAttributes(attribute.String("collection", name)))
+	defer span.End()
+
+	exists, err := c.milvus.HasCollection(ctx, c.CollectionName(name))
+	if err != nil || !exists {
+		return 0, err
+	}
+	coll, err := c.milvus.DescribeCollection(ctx, c.CollectionName(name))
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to describe collection: %w", err)
+	}
+	for _, field := range coll.Schema.Fields {
+		if field.DataType != entity.FieldTypeFloatVector {
+			continue
+		}
+		dim, err := strconv.Atoi(field.TypeParams[entity.TypeParamDim])
+		if err != nil {
+			return 0, fmt.Errorf("invalid vector dim on field %s: %w", field.Name, err)
+		}
+		return dim, nil
+	}
+	return 0, fmt.Errorf("collection %s has no float vector field", name)
+}
+
 // LoadCollection 加载集合到内存
 func (c *Client) LoadCollection(ctx context.Context, name string) error {
 	ctx, span := tracer.Start(ctx, "milvus.LoadCollection",
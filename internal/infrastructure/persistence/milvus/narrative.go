@@ -0,0 +1,117 @@
+package milvus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// narrativeUpsertBatch 回写叙事位置时单批 upsert 的片段数（含向量，批次不宜过大）
+const narrativeUpsertBatch = 200
+
+// UpdateNarrativePositions 章节重排/重编号后原地回写片段的叙事位置（positions: chapter_id → narrative_pos）。
+// 读取片段原有向量后 upsert，不重新向量化；位置未变化的片段不写入。集合不含 narrative_pos 字段时不处理。
+func (r *Repository) UpdateNarrativePositions(ctx context.Context, tenantID, projectID string, positions map[string]int64) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	if len(positions) == 0 {
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "milvus.UpdateNarrativePositions",
+		trace.WithAttributes(
+			attribute.String("tenant_id", tenantID),
+			attribute.String("project_id", projectID),
+			attribute.Int("chapter_count", len(positions)),
+		))
+	defer span.End()
+
+	r.ensureSchema(ctx)
+	if !r.hasNarrativePos() {
+		return nil
+	}
+
+	fields := append(r.segmentOutputFields(), "vector")
+	if r.hasSparse() {
+		fields = append(fields, FieldSparseVector)
+	}
+
+	collName := r.client.CollectionName(CollectionStorySegments)
+	partitionName := PartitionName(tenantID, projectID)
+	updated := 0
+	var pending []*StorySegment
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		columns, err := r.segmentColumns(pending)
+		if err != nil {
+			return err
+		}
+		if _, err := r.client.milvus.Upsert(ctx, collName, partitionName, columns...); err != nil {
+			return fmt.Errorf("failed to upsert segments: %w", err)
+		}
+		updated += len(pending)
+		pending = pending[:0]
+		return nil
+	}
+
+	expr := projectFilter(tenantID, projectID, nil)
+	err := r.scan(ctx, tenantID, projectID, expr, fields, DefaultScanBatch, func(rs client.ResultSet) error {
+		for _, seg := range segmentsWithVectorsFromResultSet(rs) {
+			pos, ok := positions[seg.ChapterID]
+			if !ok || seg.NarrativePos == pos {
+				continue
+			}
+			seg.NarrativePos = pos
+			pending = append(pending, seg)
+			if len(pending) >= narrativeUpsertBatch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	span.SetAttributes(attribute.Int("updated_count", updated))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// segmentsWithVectorsFromResultSet 解析含稠密/稀疏向量的片段（用于原地回写）
+func segmentsWithVectorsFromResultSet(rs client.ResultSet) []*StorySegment {
+	segments := segmentsFromResultSet(rs)
+	if col, ok := rs.GetColumn("vector").(*entity.ColumnFloatVector); ok {
+		for i, v := range col.Data() {
+			if i < len(segments) {
+				segments[i].Vector = v
+			}
+		}
+	}
+	if col, ok := rs.GetColumn(FieldSparseVector).(*entity.ColumnSparseFloatVector); ok {
+		for i, emb := range col.Data() {
+			if i >= len(segments) || emb == nil {
+				continue
+			}
+			sparse := Sparse{Indices: make([]uint32, 0, emb.Len()), Values: make([]float32, 0, emb.Len())}
+			for j := 0; j < emb.Len(); j++ {
+				if pos, val, ok := emb.Get(j); ok {
+					sparse.Indices = append(sparse.Indices, pos)
+					sparse.Values = append(sparse.Values, val)
+				}
+			}
+			segments[i].Sparse = sparse
+		}
+	}
+	return segments
+}
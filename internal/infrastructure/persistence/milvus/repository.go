@@ -4,17 +4,44 @@ package milvus
 import (
 	"context"
 	"fmt"
-	"sort"
+	"math"
 	"strings"
+	"sync"
 
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// sparseIndexDropRatio 构建稀疏倒排索引时丢弃的低权重比例
+	sparseIndexDropRatio = 0.2
+	// hybridOverFetchFactor 混合检索各路召回的候选放大倍数
+	hybridOverFetchFactor = 2
+	// defaultRRFK 未配置 rrf_k 时的 RRF 平滑常数
+	defaultRRFK = 60
+	// emptySparsePosition 空文本的占位维度（词项哈希只落在 [0, 2^31)，不会与之命中）
+	emptySparsePosition = math.MaxUint32 - 1
+)
+
+// HybridRankerRRF 配置 vector.milvus.hybrid_ranker 取该值时按 RRF 融合，其余按权重融合
+const HybridRankerRRF = "rrf"
+
 // Repository 向量检索仓储
 type Repository struct {
 	client *Client
+
+	// narrativePosSupported 记录 story_segments 集合是否包含 narrative_pos 字段；
+	// 历史集合缺少该字段时写入/过滤均降级为仅使用 story_time。
+	schemaMu              sync.RWMutex
+	narrativePosSupported bool
+	// sparseSupported 记录集合是否包含 sparse_vector 字段；历史集合缺少该字段时退回单向量检索。
+	sparseSupported bool
+	// branchKeySupported 记录集合是否包含 branch_key 字段；历史集合缺少该字段时仅有主线片段，不支持分支记忆。
+	branchKeySupported bool
+	// schemaDetected 是否已探测过已存在集合的字段（未探测前上述能力均视为不支持）
+	schemaDetected bool
 }
 
 // NewRepository 创建向量检索仓储
@@ -31,15 +58,30 @@ type SearchParams struct {
 	TopK             int
 	SegmentType      string
 	SegmentTypes     []string
+
+	// CurrentNarrativePos 叙事位置过滤上界（不含）；UseStoryTime 为 true 时改用 story_time 过滤。
+	CurrentNarrativePos int64
+	UseStoryTime        bool
+
+	// QuerySparse 查询稀疏向量；非空且集合支持时走原生混合检索
+	QuerySparse Sparse
+	// DenseWeight / SparseWeight weighted 融合的权重覆盖，均为 0 时取配置
+	DenseWeight  float64
+	SparseWeight float64
+
+	// BranchKey 检索的分支（空值为主线），见 branchSearchFilter；集合不支持分支时忽略
+	BranchKey string
 }
 
 // SearchResult 检索结果
 type SearchResult struct {
-	ID          string
-	Score       float32
-	TextContent string
-	ChapterID   string
-	StoryTime   int64
+	ID           string
+	Score        float32
+	TextContent  string
+	ChapterID    string
+	StoryTime    int64
+	NarrativePos int64
+	BranchKey    string
 }
 
 // CreateCollection 创建集合
@@ -93,6 +135,56 @@ func (r *Repository) CreateIndex(ctx context.Context, collection string) error {
 	return nil
 }
 
+// CreateSparseIndex 为 sparse_vector 创建稀疏倒排索引（IP 度量）
+func (r *Repository) CreateSparseIndex(ctx context.Context, collection string) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	ctx, span := tracer.Start(ctx, "milvus.CreateSparseIndex",
+		trace.WithAttributes(attribute.String("collection", collection)))
+	defer span.End()
+
+	collName := r.client.CollectionName(collection)
+
+	idx, err := entity.NewIndexSparseInverted(entity.IP, sparseIndexDropRatio)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create sparse index: %w", err)
+	}
+
+	if err := r.client.milvus.CreateIndex(ctx, collName, FieldSparseVector, idx, false); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create sparse index: %w", err)
+	}
+
+	return nil
+}
+
+// CreateTimeIndexes 为 story_time / narrative_pos 创建标量索引，加速时间过滤
+func (r *Repository) CreateTimeIndexes(ctx context.Context, collection string) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	ctx, span := tracer.Start(ctx, "milvus.CreateTimeIndexes",
+		trace.WithAttributes(attribute.String("collection", collection)))
+	defer span.End()
+
+	collName := r.client.CollectionName(collection)
+
+	fields := []string{"story_time"}
+	if r.hasNarrativePos() {
+		fields = append(fields, FieldNarrativePos)
+	}
+	for _, field := range fields {
+		if err := r.client.milvus.CreateIndex(ctx, collName, field, entity.NewScalarIndexWithType(entity.Sorted), false); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to create scalar index on %s: %w", field, err)
+		}
+	}
+
+	return nil
+}
+
 // CreatePartition 创建分区
 func (r *Repository) CreatePartition(ctx context.Context, collection, tenantID, projectID string) error {
 	if r == nil || r.client == nil || r.client.milvus == nil {
@@ -134,61 +226,109 @@ func (r *Repository) SearchSegments(ctx context.Context, params *SearchParams) (
 	} else if !has {
 		return []*SearchResult{}, nil
 	}
+	r.ensureSchema(ctx)
 
-	// 构建过滤表达式
-	filter := fmt.Sprintf(
-		`tenant_id == "%s" && project_id == "%s"`,
-		params.TenantID, params.ProjectID,
-	)
+	filter := r.searchFilter(params)
 
-	// 时间过滤（排除未来事件）
-	if params.CurrentStoryTime > 0 {
-		filter += fmt.Sprintf(` && story_time <= %d`, params.CurrentStoryTime)
+	// 分支过滤：主线只读主线片段；其他分支读取本分支片段，本分支未写入的文档回退到主线
+	if r.hasBranchKey() {
+		bf, err := r.branchSearchFilter(ctx, params)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		filter += " && " + bf
 	}
 
-	// 类型过滤
-	if params.SegmentType != "" {
-		filter += fmt.Sprintf(` && segment_type == "%s"`, params.SegmentType)
-	} else if len(params.SegmentTypes) > 0 {
-		// segment_type 只存在一个字段，使用 OR 条件构建过滤（避免依赖 IN 语法差异）。
-		var parts []string
-		for _, st := range params.SegmentTypes {
-			st = strings.TrimSpace(st)
-			if st == "" {
-				continue
-			}
-			parts = append(parts, fmt.Sprintf(`segment_type == "%s"`, st))
-		}
-		if len(parts) > 0 {
-			filter += " && (" + strings.Join(parts, " || ") + ")"
-		}
+	outputFields := []string{"id", "text_content", "chapter_id", "story_time"}
+	if r.hasNarrativePos() {
+		outputFields = append(outputFields, FieldNarrativePos)
+	}
+	if r.hasBranchKey() {
+		outputFields = append(outputFields, FieldBranchKey)
 	}
 
-	// 搜索参数
-	sp, err := entity.NewIndexHNSWSearchParam(128)
+	dense, err := entity.NewIndexHNSWSearchParam(128)
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to create search param: %w", err)
 	}
 
-	// 执行搜索
-	results, err := r.client.milvus.Search(ctx,
-		collName,
-		[]string{partitionName},
-		filter,
-		[]string{"id", "text_content", "chapter_id", "story_time"},
-		[]entity.Vector{entity.FloatVector(params.QueryVector)},
-		"vector",
-		entity.COSINE,
-		params.TopK,
-		sp,
-	)
+	// 集合含稀疏字段且查询带稀疏向量时走原生混合检索，否则保持单向量检索
+	var results []client.SearchResult
+	if r.useHybrid(params) {
+		span.SetAttributes(attribute.Bool("hybrid", true), attribute.String("hybrid_ranker", r.client.config.HybridRanker))
+		results, err = r.hybridSearch(ctx, collName, partitionName, filter, outputFields, dense, params)
+	} else {
+		results, err = r.client.milvus.Search(ctx,
+			collName,
+			[]string{partitionName},
+			filter,
+			outputFields,
+			[]entity.Vector{entity.FloatVector(params.QueryVector)},
+			"vector",
+			entity.COSINE,
+			params.TopK,
+			dense,
+		)
+	}
 	if err != nil {
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
 
-	// 解析结果
+	searchResults := parseSearchResults(results)
+	span.SetAttributes(attribute.Int("result_count", len(searchResults)))
+	return searchResults, nil
+}
+
+// useHybrid 是否走原生混合检索：配置开启、集合含稀疏字段且查询稀疏向量非空
+func (r *Repository) useHybrid(params *SearchParams) bool {
+	cfg := r.client.config
+	return cfg != nil && cfg.HybridSearch && len(params.QuerySparse.Indices) > 0 && r.hasSparse()
+}
+
+// hybridSearch 稠密（COSINE）与 BM25 稀疏（IP）两路 ANN 召回，由 Milvus 按 hybridReranker 融合。
+// 两路使用相同过滤表达式；各路多召回一些候选以便融合后仍有 TopK。
+func (r *Repository) hybridSearch(ctx context.Context, collName, partitionName, filter string, outputFields []string, dense entity.SearchParam, params *SearchParams) ([]client.SearchResult, error) {
+	sparseVec, err := entity.NewSliceSparseEmbedding(params.QuerySparse.Indices, params.QuerySparse.Values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query sparse vector: %w", err)
+	}
+	sparseParam, err := entity.NewIndexSparseInvertedSearchParam(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sparse search param: %w", err)
+	}
+
+	candidates := params.TopK * hybridOverFetchFactor
+	requests := []*client.ANNSearchRequest{
+		client.NewANNSearchRequest("vector", entity.COSINE, filter,
+			[]entity.Vector{entity.FloatVector(params.QueryVector)}, dense, candidates),
+		client.NewANNSearchRequest(FieldSparseVector, entity.IP, filter,
+			[]entity.Vector{sparseVec}, sparseParam, candidates),
+	}
+	return r.client.milvus.HybridSearch(ctx, collName, []string{partitionName}, params.TopK, outputFields, r.hybridReranker(params), requests)
+}
+
+// hybridReranker rrf 按两路名次融合（忽略权重）；weighted 按请求权重（未指定时取配置）加权归一化得分
+func (r *Repository) hybridReranker(params *SearchParams) client.Reranker {
+	cfg := r.client.config
+	if cfg.HybridRanker == HybridRankerRRF {
+		k := cfg.RRFK
+		if k <= 0 {
+			k = defaultRRFK
+		}
+		return client.NewRRFReranker().WithK(k)
+	}
+	dense, sparse := cfg.DenseWeight, cfg.SparseWeight
+	if params.DenseWeight+params.SparseWeight > 0 {
+		dense, sparse = params.DenseWeight, params.SparseWeight
+	}
+	return client.NewWeightedReranker([]float64{dense, sparse})
+}
+
+// parseSearchResults 解析检索结果（单向量与混合检索共用）
+func parseSearchResults(results []client.SearchResult) []*SearchResult {
 	var searchResults []*SearchResult
 	for _, result := range results {
 		for i := 0; i < result.ResultCount; i++ {
@@ -209,107 +349,17 @@ func (r *Repository) SearchSegments(ctx context.Context, params *SearchParams) (
 			if timeCol, ok := result.Fields.GetColumn("story_time").(*entity.ColumnInt64); ok {
 				sr.StoryTime = timeCol.Data()[i]
 			}
+			if posCol, ok := result.Fields.GetColumn(FieldNarrativePos).(*entity.ColumnInt64); ok {
+				sr.NarrativePos = posCol.Data()[i]
+			}
+			if branchCol, ok := result.Fields.GetColumn(FieldBranchKey).(*entity.ColumnVarChar); ok {
+				sr.BranchKey = branchCol.Data()[i]
+			}
 
 			searchResults = append(searchResults, sr)
 		}
 	}
-
-	span.SetAttributes(attribute.Int("result_count", len(searchResults)))
-	return searchResults, nil
-}
-
-// HybridSearchParams 混合检索参数
-type HybridSearchParams struct {
-	TenantID         string
-	ProjectID        string
-	QueryVector      []float32
-	QueryText        string
-	CurrentStoryTime int64
-	TopK             int
-	VectorWeight     float32
-	KeywordWeight    float32
-}
-
-// HybridSearch 混合检索（语义 + 关键词）
-func (r *Repository) HybridSearch(ctx context.Context, params *HybridSearchParams) ([]*SearchResult, error) {
-	if r == nil || r.client == nil || r.client.milvus == nil {
-		return nil, fmt.Errorf("milvus client not configured")
-	}
-	ctx, span := tracer.Start(ctx, "milvus.HybridSearch",
-		trace.WithAttributes(
-			attribute.String("tenant_id", params.TenantID),
-			attribute.String("project_id", params.ProjectID),
-			attribute.Int("top_k", params.TopK),
-		))
-	defer span.End()
-
-	// 1. 向量检索
-	vectorResults, err := r.SearchSegments(ctx, &SearchParams{
-		TenantID:         params.TenantID,
-		ProjectID:        params.ProjectID,
-		QueryVector:      params.QueryVector,
-		CurrentStoryTime: params.CurrentStoryTime,
-		TopK:             params.TopK * 2, // 多召回用于重排
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// 2. 如果没有关键词权重，直接返回向量结果
-	if params.KeywordWeight <= 0 || params.QueryText == "" {
-		if len(vectorResults) > params.TopK {
-			vectorResults = vectorResults[:params.TopK]
-		}
-		return vectorResults, nil
-	}
-
-	// 3. 融合重排（RRF - Reciprocal Rank Fusion）
-	merged := r.fusionRank(vectorResults, nil, params.VectorWeight, params.KeywordWeight)
-
-	// 4. 返回 TopK
-	if len(merged) > params.TopK {
-		merged = merged[:params.TopK]
-	}
-
-	span.SetAttributes(attribute.Int("result_count", len(merged)))
-	return merged, nil
-}
-
-// fusionRank RRF 融合重排
-func (r *Repository) fusionRank(vecResults, kwResults []*SearchResult, vecWeight, kwWeight float32) []*SearchResult {
-	scores := make(map[string]float32)
-	results := make(map[string]*SearchResult)
-
-	k := float32(60) // RRF 常数
-
-	// 向量结果评分
-	for i, res := range vecResults {
-		score := vecWeight / (k + float32(i+1))
-		scores[res.ID] += score
-		results[res.ID] = res
-	}
-
-	// 关键词结果评分
-	for i, res := range kwResults {
-		score := kwWeight / (k + float32(i+1))
-		scores[res.ID] += score
-		if _, ok := results[res.ID]; !ok {
-			results[res.ID] = res
-		}
-	}
-
-	// 排序
-	var merged []*SearchResult
-	for id, res := range results {
-		res.Score = scores[id]
-		merged = append(merged, res)
-	}
-
-	sort.Slice(merged, func(i, j int) bool {
-		return merged[i].Score > merged[j].Score
-	})
-
-	return merged
+	return searchResults
 }
 
 // InsertSegments 插入故事片段
@@ -328,6 +378,7 @@ func (r *Repository) InsertSegments(ctx context.Context, tenantID, projectID str
 	if len(segments) == 0 {
 		return nil
 	}
+	r.ensureSchema(ctx)
 
 	collName := r.client.CollectionName(CollectionStorySegments)
 	partitionName := PartitionName(tenantID, projectID)
@@ -340,14 +391,32 @@ func (r *Repository) InsertSegments(ctx context.Context, tenantID, projectID str
 		}
 	}
 
-	// 准备数据
+	columns, err := r.segmentColumns(segments)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// 插入
+	if _, err := r.client.milvus.Insert(ctx, collName, partitionName, columns...); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to insert segments: %w", err)
+	}
+
+	return nil
+}
+
+// segmentColumns 按集合实际字段构建片段列（历史集合缺少的可选字段不写入）
+func (r *Repository) segmentColumns(segments []*StorySegment) ([]entity.Column, error) {
 	ids := make([]string, len(segments))
 	vectors := make([][]float32, len(segments))
 	tenantIDs := make([]string, len(segments))
 	projectIDs := make([]string, len(segments))
 	chapterIDs := make([]string, len(segments))
 	storyTimes := make([]int64, len(segments))
+	narrativePositions := make([]int64, len(segments))
 	segmentTypes := make([]string, len(segments))
+	branchKeys := make([]string, len(segments))
 	textContents := make([]string, len(segments))
 
 	for i, seg := range segments {
@@ -357,8 +426,13 @@ func (r *Repository) InsertSegments(ctx context.Context, tenantID, projectID str
 		projectIDs[i] = seg.ProjectID
 		chapterIDs[i] = seg.ChapterID
 		storyTimes[i] = seg.StoryTime
+		narrativePositions[i] = seg.NarrativePos
 		segmentTypes[i] = seg.SegmentType
+		branchKeys[i] = normalizeBranchKey(seg.BranchKey)
 		textContents[i] = seg.TextContent
+		if branchKeys[i] != MainBranchKey && !r.hasBranchKey() {
+			return nil, fmt.Errorf("collection does not support branch segments (missing %s field)", FieldBranchKey)
+		}
 	}
 
 	// 构建列
@@ -371,15 +445,33 @@ func (r *Repository) InsertSegments(ctx context.Context, tenantID, projectID str
 	typeCol := entity.NewColumnVarChar("segment_type", segmentTypes)
 	textCol := entity.NewColumnVarChar("text_content", textContents)
 
-	// 插入
-	_, err := r.client.milvus.Insert(ctx, collName, partitionName,
-		idCol, vectorCol, tenantCol, projectCol, chapterCol, timeCol, typeCol, textCol)
-	if err != nil {
-		span.RecordError(err)
-		return fmt.Errorf("failed to insert segments: %w", err)
+	columns := []entity.Column{idCol, vectorCol, tenantCol, projectCol, chapterCol, timeCol, typeCol, textCol}
+	if r.hasNarrativePos() {
+		columns = append(columns, entity.NewColumnInt64(FieldNarrativePos, narrativePositions))
 	}
+	if r.hasBranchKey() {
+		columns = append(columns, entity.NewColumnVarChar(FieldBranchKey, branchKeys))
+	}
+	if r.hasSparse() {
+		sparseVectors := make([]entity.SparseEmbedding, len(segments))
+		for i, seg := range segments {
+			emb, err := toSparseEmbedding(seg.Sparse)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sparse vector for segment %s: %w", seg.ID, err)
+			}
+			sparseVectors[i] = emb
+		}
+		columns = append(columns, entity.NewColumnSparseVectors(FieldSparseVector, sparseVectors))
+	}
+	return columns, nil
+}
 
-	return nil
+// toSparseEmbedding 稀疏字段不可为空：空文本写入极小权重的占位维度
+func toSparseEmbedding(v Sparse) (entity.SparseEmbedding, error) {
+	if len(v.Indices) == 0 {
+		return entity.NewSliceSparseEmbedding([]uint32{emptySparsePosition}, []float32{1e-6})
+	}
+	return entity.NewSliceSparseEmbedding(append([]uint32(nil), v.Indices...), append([]float32(nil), v.Values...))
 }
 
 // DeleteSegmentsByChapter 删除章节的所有片段
@@ -414,7 +506,7 @@ func (r *Repository) DeleteSegmentsByChapter(ctx context.Context, tenantID, proj
 	return nil
 }
 
-// DeleteSegmentsByChapterAndType 删除指定 chapter_id + segment_type 的片段（同一 project 分区内）。
+// DeleteSegmentsByChapterAndType 删除指定 chapter_id + segment_type 的主线片段（同一 project 分区内；分支片段见 DeleteSegmentsByBranch）。
 func (r *Repository) DeleteSegmentsByChapterAndType(ctx context.Context, tenantID, projectID, chapterID, segmentType string) error {
 	if r == nil || r.client == nil || r.client.milvus == nil {
 		return fmt.Errorf("milvus client not configured")
@@ -443,6 +535,38 @@ func (r *Repository) DeleteSegmentsByChapterAndType(ctx context.Context, tenantI
 	}
 
 	filter := fmt.Sprintf(`chapter_id == "%s" && segment_type == "%s"`, chapterID, segmentType)
+	if r.hasBranchKey() {
+		filter += fmt.Sprintf(` && %s == "%s"`, FieldBranchKey, MainBranchKey)
+	}
+	if err := r.client.milvus.Delete(ctx, collName, partitionName, filter); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete segments: %w", err)
+	}
+	return nil
+}
+
+// DeleteSegmentsByProject 删除项目分区内的全部片段（分区本身保留，后续写入无需重建）
+func (r *Repository) DeleteSegmentsByProject(ctx context.Context, tenantID, projectID string) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	ctx, span := tracer.Start(ctx, "milvus.DeleteSegmentsByProject",
+		trace.WithAttributes(
+			attribute.String("project_id", projectID),
+		))
+	defer span.End()
+
+	collName := r.client.CollectionName(CollectionStorySegments)
+	partitionName := PartitionName(tenantID, projectID)
+
+	if has, err := r.client.milvus.HasPartition(ctx, collName, partitionName); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to check partition: %w", err)
+	} else if !has {
+		return nil
+	}
+
+	filter := fmt.Sprintf(`project_id == "%s"`, projectID)
 	if err := r.client.milvus.Delete(ctx, collName, partitionName, filter); err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to delete segments: %w", err)
@@ -496,10 +620,120 @@ func (r *Repository) EnsureStorySegmentsCollection(ctx context.Context) error {
 		if err := r.CreateCollection(ctx, StorySegmentsSchema()); err != nil {
 			return err
 		}
+	}
+
+	if err := r.detectSchema(ctx); err != nil {
+		return err
+	}
+
+	if !exists {
 		// 新建集合时创建索引；若失败，允许后续由运维介入。
 		_ = r.CreateIndex(ctx, CollectionStorySegments)
+		_ = r.CreateSparseIndex(ctx, CollectionStorySegments)
+		_ = r.CreateTimeIndexes(ctx, CollectionStorySegments)
 	}
 
 	// 尝试确保集合已加载（若已加载，Milvus 会返回成功）
 	return r.client.LoadCollection(ctx, CollectionStorySegments)
 }
+
+// DetectSchema 启动时探测已存在的 story_segments 集合字段；集合尚不存在时不做处理，
+// 留待 EnsureStorySegmentsCollection 建表后探测。不经过 EnsureStorySegmentsCollection 的读写路径
+// （如 gRPC 检索服务）依赖此处的结果，否则会在历史集合上一直降级为 story_time 过滤。
+func (r *Repository) DetectSchema(ctx context.Context) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	exists, err := r.client.HasCollection(ctx, CollectionStorySegments)
+	if err != nil || !exists {
+		return err
+	}
+	return r.detectSchema(ctx)
+}
+
+// ensureSchema 读写前补做探测（启动时 Milvus 尚无集合或探测失败的情况），失败时保持降级行为
+func (r *Repository) ensureSchema(ctx context.Context) {
+	r.schemaMu.RLock()
+	detected := r.schemaDetected
+	r.schemaMu.RUnlock()
+	if detected {
+		return
+	}
+	_ = r.DetectSchema(ctx)
+}
+
+// detectSchema 检查 story_segments 集合是否包含 narrative_pos / sparse_vector / branch_key 字段
+// （历史集合需重建后才支持叙事位置过滤、混合检索与分支记忆，此前分别降级为 story_time 过滤、单向量检索与仅主线）
+func (r *Repository) detectSchema(ctx context.Context) error {
+	coll, err := r.client.milvus.DescribeCollection(ctx, r.client.CollectionName(CollectionStorySegments))
+	if err != nil {
+		return fmt.Errorf("failed to describe collection: %w", err)
+	}
+
+	narrativePos, sparse, branchKey := false, false, false
+	if coll != nil && coll.Schema != nil {
+		for _, f := range coll.Schema.Fields {
+			if f == nil {
+				continue
+			}
+			switch f.Name {
+			case FieldNarrativePos:
+				narrativePos = true
+			case FieldSparseVector:
+				sparse = f.DataType == entity.FieldTypeSparseVector
+			case FieldBranchKey:
+				branchKey = true
+			}
+		}
+	}
+
+	r.schemaMu.Lock()
+	r.narrativePosSupported = narrativePos
+	r.sparseSupported = sparse
+	r.branchKeySupported = branchKey
+	r.schemaDetected = true
+	r.schemaMu.Unlock()
+	return nil
+}
+
+// searchFilter 构建检索的基础过滤表达式（租户/项目、时间、片段类型；分支过滤见 branchSearchFilter）
+func (r *Repository) searchFilter(params *SearchParams) string {
+	filter := fmt.Sprintf(
+		`tenant_id == "%s" && project_id == "%s"`,
+		params.TenantID, params.ProjectID,
+	)
+
+	// 时间过滤：默认按叙事位置排除“读者尚未读到”的内容；显式指定或集合不支持时按 story_time 排除未来事件
+	useNarrative := !params.UseStoryTime && params.CurrentNarrativePos > 0 && r.hasNarrativePos()
+	if useNarrative {
+		filter += fmt.Sprintf(` && %s < %d`, FieldNarrativePos, params.CurrentNarrativePos)
+	} else if params.CurrentStoryTime > 0 {
+		filter += fmt.Sprintf(` && story_time <= %d`, params.CurrentStoryTime)
+	}
+
+	// 类型过滤
+	if params.SegmentType != "" {
+		filter += fmt.Sprintf(` && segment_type == "%s"`, params.SegmentType)
+	} else if st := segmentTypeFilter(params.SegmentTypes); st != "" {
+		filter += " && " + st
+	}
+	return filter
+}
+
+func (r *Repository) hasNarrativePos() bool {
+	r.schemaMu.RLock()
+	defer r.schemaMu.RUnlock()
+	return r.narrativePosSupported
+}
+
+func (r *Repository) hasSparse() bool {
+	r.schemaMu.RLock()
+	defer r.schemaMu.RUnlock()
+	return r.sparseSupported
+}
+
+func (r *Repository) hasBranchKey() bool {
+	r.schemaMu.RLock()
+	defer r.schemaMu.RUnlock()
+	return r.branchKeySupported
+}
@@ -0,0 +1,59 @@
+package milvus
+
+import "testing"
+
+func TestSearchFilter(t *testing.T) {
+	base := `tenant_id == "t1" && project_id == "p1"`
+	cases := []struct {
+		name      string
+		narrative bool
+		params    SearchParams
+		want      string
+	}{
+		{
+			name:      "narrative position",
+			narrative: true,
+			params:    SearchParams{CurrentNarrativePos: 3000002, CurrentStoryTime: 500},
+			want:      base + ` && narrative_pos < 3000002`,
+		},
+		{
+			// 历史集合缺少 narrative_pos 字段时回退为 story_time
+			name:   "collection without narrative_pos",
+			params: SearchParams{CurrentNarrativePos: 3000002, CurrentStoryTime: 500},
+			want:   base + ` && story_time <= 500`,
+		},
+		{
+			name:      "story time requested",
+			narrative: true,
+			params:    SearchParams{CurrentNarrativePos: 3000002, CurrentStoryTime: 500, UseStoryTime: true},
+			want:      base + ` && story_time <= 500`,
+		},
+		{
+			name:      "no time bound",
+			narrative: true,
+			params:    SearchParams{},
+			want:      base,
+		},
+		{
+			name:   "single segment type",
+			params: SearchParams{CurrentStoryTime: 500, SegmentType: "chapter", SegmentTypes: []string{"summary"}},
+			want:   base + ` && story_time <= 500 && segment_type == "chapter"`,
+		},
+		{
+			name:      "segment type list",
+			narrative: true,
+			params:    SearchParams{CurrentNarrativePos: 7, SegmentTypes: []string{"summary", " ", "entity_card"}},
+			want:      base + ` && narrative_pos < 7 && (segment_type == "summary" || segment_type == "entity_card")`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Repository{narrativePosSupported: tc.narrative, schemaDetected: true}
+			params := tc.params
+			params.TenantID, params.ProjectID = "t1", "p1"
+			if got := r.searchFilter(&params); got != tc.want {
+				t.Fatalf("searchFilter =\n  %s\nwant\n  %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -14,7 +14,19 @@ func NewRetrievalVectorRepository(repo *Repository) *RetrievalVectorRepository {
 	return &RetrievalVectorRepository{repo: repo}
 }
 
-var _ retrieval.VectorRepository = (*RetrievalVectorRepository)(nil)
+var (
+	_ retrieval.VectorRepository  = (*RetrievalVectorRepository)(nil)
+	_ retrieval.PartitionNamer    = (*RetrievalVectorRepository)(nil)
+	_ retrieval.SegmentScanner    = (*RetrievalVectorRepository)(nil)
+	_ retrieval.BranchScopedStore = (*RetrievalVectorRepository)(nil)
+
+	_ retrieval.NarrativePositionUpdater = (*RetrievalVectorRepository)(nil)
+)
+
+// PartitionName 返回项目在 story_segments 集合中的分区名
+func (r *RetrievalVectorRepository) PartitionName(tenantID, projectID string) string {
+	return PartitionName(tenantID, projectID)
+}
 
 func (r *RetrievalVectorRepository) EnsureStorySegmentsCollection(ctx context.Context) error {
 	if r == nil || r.repo == nil {
@@ -32,12 +44,18 @@ func (r *RetrievalVectorRepository) SearchSegments(ctx context.Context, params *
 	}
 
 	out, err := r.repo.SearchSegments(ctx, &SearchParams{
-		TenantID:         params.TenantID,
-		ProjectID:        params.ProjectID,
-		QueryVector:      params.QueryVector,
-		CurrentStoryTime: params.CurrentStoryTime,
-		TopK:             params.TopK,
-		SegmentTypes:     params.SegmentTypes,
+		TenantID:            params.TenantID,
+		ProjectID:           params.ProjectID,
+		QueryVector:         params.QueryVector,
+		CurrentStoryTime:    params.CurrentStoryTime,
+		CurrentNarrativePos: params.CurrentNarrativePos,
+		UseStoryTime:        params.TimeFilter == retrieval.TimeFilterStoryTime,
+		TopK:                params.TopK,
+		SegmentTypes:        params.SegmentTypes,
+		QuerySparse:         Sparse{Indices: params.QuerySparse.Indices, Values: params.QuerySparse.Values},
+		DenseWeight:         params.DenseWeight,
+		SparseWeight:        params.SparseWeight,
+		BranchKey:           params.BranchKey,
 	})
 	if err != nil {
 		return nil, err
@@ -50,11 +68,13 @@ func (r *RetrievalVectorRepository) SearchSegments(ctx context.Context, params *
 			continue
 		}
 		results = append(results, &retrieval.VectorSearchResult{
-			ID:          v.ID,
-			Score:       v.Score,
-			TextContent: v.TextContent,
-			ChapterID:   v.ChapterID,
-			StoryTime:   v.StoryTime,
+			ID:           v.ID,
+			Score:        v.Score,
+			TextContent:  v.TextContent,
+			ChapterID:    v.ChapterID,
+			StoryTime:    v.StoryTime,
+			NarrativePos: v.NarrativePos,
+			BranchKey:    v.BranchKey,
 		})
 	}
 	return results, nil
@@ -67,6 +87,34 @@ func (r *RetrievalVectorRepository) DeleteSegmentsByDocAndType(ctx context.Conte
 	return r.repo.DeleteSegmentsByChapterAndType(ctx, tenantID, projectID, docID, segmentType)
 }
 
+// BranchesSupported 集合是否支持分支片段
+func (r *RetrievalVectorRepository) BranchesSupported() bool {
+	return r != nil && r.repo.SupportsBranches()
+}
+
+// UpdateNarrativePositions 回写章节片段的叙事位置
+func (r *RetrievalVectorRepository) UpdateNarrativePositions(ctx context.Context, tenantID, projectID string, positions map[string]int64) error {
+	if r == nil || r.repo == nil {
+		return retrieval.ErrVectorDisabled
+	}
+	return r.repo.UpdateNarrativePositions(ctx, tenantID, projectID, positions)
+}
+
+// DeleteBranchSegments 删除分支片段
+func (r *RetrievalVectorRepository) DeleteBranchSegments(ctx context.Context, tenantID, projectID, docID, segmentType, branchKey string) error {
+	if r == nil || r.repo == nil {
+		return retrieval.ErrVectorDisabled
+	}
+	return r.repo.DeleteSegmentsByBranch(ctx, tenantID, projectID, docID, segmentType, branchKey)
+}
+
+func (r *RetrievalVectorRepository) DeleteProjectSegments(ctx context.Context, tenantID, projectID string) error {
+	if r == nil || r.repo == nil {
+		return retrieval.ErrVectorDisabled
+	}
+	return r.repo.DeleteSegmentsByProject(ctx, tenantID, projectID)
+}
+
 func (r *RetrievalVectorRepository) InsertSegments(ctx context.Context, tenantID, projectID string, segments []*retrieval.VectorStorySegment) error {
 	if r == nil || r.repo == nil {
 		return retrieval.ErrVectorDisabled
@@ -82,15 +130,65 @@ func (r *RetrievalVectorRepository) InsertSegments(ctx context.Context, tenantID
 			continue
 		}
 		out = append(out, &StorySegment{
-			ID:          s.ID,
-			TenantID:    s.TenantID,
-			ProjectID:   s.ProjectID,
-			ChapterID:   s.DocID,
-			StoryTime:   s.StoryTime,
-			SegmentType: s.SegmentType,
-			TextContent: s.TextContent,
-			Vector:      s.Vector,
+			ID:           s.ID,
+			TenantID:     s.TenantID,
+			ProjectID:    s.ProjectID,
+			ChapterID:    s.DocID,
+			StoryTime:    s.StoryTime,
+			NarrativePos: s.NarrativePos,
+			SegmentType:  s.SegmentType,
+			BranchKey:    s.BranchKey,
+			TextContent:  s.TextContent,
+			Vector:       s.Vector,
+			Sparse:       Sparse{Indices: s.Sparse.Indices, Values: s.Sparse.Values},
 		})
 	}
 	return r.repo.InsertSegments(ctx, tenantID, projectID, out)
 }
+
+func (r *RetrievalVectorRepository) ListProjectSegments(ctx context.Context, params *retrieval.SegmentListParams) (*retrieval.SegmentPage, error) {
+	if r == nil || r.repo == nil {
+		return nil, retrieval.ErrVectorDisabled
+	}
+	if params == nil {
+		return &retrieval.SegmentPage{}, nil
+	}
+
+	page, err := r.repo.ListSegmentsByProject(ctx, &ListSegmentsParams{
+		TenantID:     params.TenantID,
+		ProjectID:    params.ProjectID,
+		SegmentTypes: params.SegmentTypes,
+		Cursor:       params.Cursor,
+		Limit:        params.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &retrieval.SegmentPage{
+		Segments:   make([]*retrieval.StoredSegment, 0, len(page.Segments)),
+		NextCursor: page.NextCursor,
+	}
+	for _, s := range page.Segments {
+		if s == nil {
+			continue
+		}
+		out.Segments = append(out.Segments, &retrieval.StoredSegment{
+			ID:           s.ID,
+			DocID:        s.ChapterID,
+			SegmentType:  s.SegmentType,
+			TextContent:  s.TextContent,
+			StoryTime:    s.StoryTime,
+			NarrativePos: s.NarrativePos,
+			BranchKey:    s.BranchKey,
+		})
+	}
+	return out, nil
+}
+
+func (r *RetrievalVectorRepository) CountProjectSegments(ctx context.Context, tenantID, projectID string) (map[string]int64, error) {
+	if r == nil || r.repo == nil {
+		return nil, retrieval.ErrVectorDisabled
+	}
+	return r.repo.CountSegmentsByType(ctx, tenantID, projectID)
+}
@@ -0,0 +1,262 @@
+// Package milvus 提供 Milvus 向量数据库访问层实现
+package milvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultScanBatch 遍历片段时的默认单批条数
+	DefaultScanBatch = 500
+	// MaxScanBatch 单批条数上限（Milvus query limit 上限为 16384，文本字段较大时留足余量）
+	MaxScanBatch = 2000
+)
+
+// ListSegmentsParams 按项目分页列出片段的参数
+type ListSegmentsParams struct {
+	TenantID     string
+	ProjectID    string
+	SegmentTypes []string
+	// Cursor 上一页最后一条片段的 ID（不含）；为空时从头开始
+	Cursor string
+	Limit  int
+}
+
+// SegmentPage 一页片段（按主键升序，不含向量）
+type SegmentPage struct {
+	Segments []*StorySegment
+	// NextCursor 下一页游标；为空表示已遍历完毕
+	NextCursor string
+}
+
+// ListSegmentsByProject 基于 QueryIterator 按主键顺序分页列出项目分区内的片段（游标为上一页最后一条 ID）。
+// 与 TopK 检索不同，该方法可稳定枚举项目全部片段，用于导出、去重、清理与管理端浏览。
+func (r *Repository) ListSegmentsByProject(ctx context.Context, params *ListSegmentsParams) (*SegmentPage, error) {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return nil, fmt.Errorf("milvus client not configured")
+	}
+	if params == nil {
+		return &SegmentPage{}, nil
+	}
+	// 游标会拼入过滤表达式，拒绝引号/反斜杠避免表达式注入
+	if strings.ContainsAny(params.Cursor, `"\`) {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	limit := clampScanBatch(params.Limit)
+
+	ctx, span := tracer.Start(ctx, "milvus.ListSegmentsByProject",
+		trace.WithAttributes(
+			attribute.String("tenant_id", params.TenantID),
+			attribute.String("project_id", params.ProjectID),
+			attribute.Int("limit", limit),
+		))
+	defer span.End()
+	r.ensureSchema(ctx)
+
+	expr := projectFilter(params.TenantID, params.ProjectID, params.SegmentTypes)
+	if params.Cursor != "" {
+		expr += fmt.Sprintf(` && id > "%s"`, params.Cursor)
+	}
+
+	page := &SegmentPage{Segments: []*StorySegment{}}
+	err := r.scan(ctx, params.TenantID, params.ProjectID, expr, r.segmentOutputFields(), limit, func(rs client.ResultSet) error {
+		page.Segments = segmentsFromResultSet(rs)
+		return errStopScan
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if n := len(page.Segments); n == limit {
+		page.NextCursor = page.Segments[n-1].ID
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(page.Segments)))
+	return page, nil
+}
+
+// ScanSegmentsByProject 按主键顺序分批遍历项目分区内的全部片段（不含向量），fn 返回错误时中止遍历
+func (r *Repository) ScanSegmentsByProject(ctx context.Context, tenantID, projectID string, batchSize int, fn func(segments []*StorySegment) error) error {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return fmt.Errorf("milvus client not configured")
+	}
+	ctx, span := tracer.Start(ctx, "milvus.ScanSegmentsByProject",
+		trace.WithAttributes(
+			attribute.String("tenant_id", tenantID),
+			attribute.String("project_id", projectID),
+		))
+	defer span.End()
+	r.ensureSchema(ctx)
+
+	expr := projectFilter(tenantID, projectID, nil)
+	if err := r.scan(ctx, tenantID, projectID, expr, r.segmentOutputFields(), clampScanBatch(batchSize), func(rs client.ResultSet) error {
+		return fn(segmentsFromResultSet(rs))
+	}); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// CountSegmentsByType 统计项目分区内各 segment_type 的片段数（仅读取主键与类型字段）
+func (r *Repository) CountSegmentsByType(ctx context.Context, tenantID, projectID string) (map[string]int64, error) {
+	if r == nil || r.client == nil || r.client.milvus == nil {
+		return nil, fmt.Errorf("milvus client not configured")
+	}
+	ctx, span := tracer.Start(ctx, "milvus.CountSegmentsByType",
+		trace.WithAttributes(
+			attribute.String("tenant_id", tenantID),
+			attribute.String("project_id", projectID),
+		))
+	defer span.End()
+
+	counts := make(map[string]int64)
+	expr := projectFilter(tenantID, projectID, nil)
+	if err := r.scan(ctx, tenantID, projectID, expr, []string{"id", "segment_type"}, MaxScanBatch, func(rs client.ResultSet) error {
+		if col, ok := rs.GetColumn("segment_type").(*entity.ColumnVarChar); ok {
+			for _, st := range col.Data() {
+				counts[st]++
+			}
+		}
+		return nil
+	}); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+// errStopScan 回调主动结束遍历（不视为错误）
+var errStopScan = errors.New("stop scan")
+
+// scan 在项目分区上以 QueryIterator 分批遍历；分区不存在时视为空。
+// QueryIterator 按主键升序翻页（内部以 id > lastPK 续查），遍历期间的新写入可能被读到也可能被跳过。
+func (r *Repository) scan(ctx context.Context, tenantID, projectID, expr string, outputFields []string, batchSize int, fn func(rs client.ResultSet) error) error {
+	collName := r.client.CollectionName(CollectionStorySegments)
+	partitionName := PartitionName(tenantID, projectID)
+
+	if has, err := r.client.milvus.HasPartition(ctx, collName, partitionName); err != nil {
+		return fmt.Errorf("failed to check partition: %w", err)
+	} else if !has {
+		return nil
+	}
+
+	itr, err := r.client.milvus.QueryIterator(ctx, client.NewQueryIteratorOption(collName).
+		WithPartitions(partitionName).
+		WithExpr(expr).
+		WithOutputFields(outputFields...).
+		WithBatchSize(batchSize))
+	if err != nil {
+		return fmt.Errorf("failed to create query iterator: %w", err)
+	}
+
+	for {
+		rs, err := itr.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query segments: %w", err)
+		}
+		if err := fn(rs); err != nil {
+			if errors.Is(err, errStopScan) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// segmentOutputFields 遍历片段时读取的字段（不含向量）
+func (r *Repository) segmentOutputFields() []string {
+	fields := []string{"id", "tenant_id", "project_id", "chapter_id", "story_time", "segment_type", "text_content"}
+	if r.hasNarrativePos() {
+		fields = append(fields, FieldNarrativePos)
+	}
+	if r.hasBranchKey() {
+		fields = append(fields, FieldBranchKey)
+	}
+	return fields
+}
+
+// projectFilter 项目范围过滤表达式，可附加 segment_type 条件
+func projectFilter(tenantID, projectID string, segmentTypes []string) string {
+	filter := fmt.Sprintf(`tenant_id == "%s" && project_id == "%s"`, tenantID, projectID)
+	if st := segmentTypeFilter(segmentTypes); st != "" {
+		filter += " && " + st
+	}
+	return filter
+}
+
+// segmentTypeFilter segment_type 只存在一个字段，使用 OR 条件构建过滤（避免依赖 IN 语法差异）。
+func segmentTypeFilter(segmentTypes []string) string {
+	var parts []string
+	for _, st := range segmentTypes {
+		st = strings.TrimSpace(st)
+		if st == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf(`segment_type == "%s"`, st))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, " || ") + ")"
+}
+
+func clampScanBatch(n int) int {
+	if n <= 0 {
+		return DefaultScanBatch
+	}
+	if n > MaxScanBatch {
+		return MaxScanBatch
+	}
+	return n
+}
+
+func segmentsFromResultSet(rs client.ResultSet) []*StorySegment {
+	n := rs.Len()
+	segments := make([]*StorySegment, n)
+	for i := range segments {
+		segments[i] = &StorySegment{}
+	}
+
+	varchar := func(name string, set func(s *StorySegment, v string)) {
+		if col, ok := rs.GetColumn(name).(*entity.ColumnVarChar); ok {
+			for i, v := range col.Data() {
+				if i < n {
+					set(segments[i], v)
+				}
+			}
+		}
+	}
+	int64s := func(name string, set func(s *StorySegment, v int64)) {
+		if col, ok := rs.GetColumn(name).(*entity.ColumnInt64); ok {
+			for i, v := range col.Data() {
+				if i < n {
+					set(segments[i], v)
+				}
+			}
+		}
+	}
+
+	varchar("id", func(s *StorySegment, v string) { s.ID = v })
+	varchar("tenant_id", func(s *StorySegment, v string) { s.TenantID = v })
+	varchar("project_id", func(s *StorySegment, v string) { s.ProjectID = v })
+	varchar("chapter_id", func(s *StorySegment, v string) { s.ChapterID = v })
+	varchar("segment_type", func(s *StorySegment, v string) { s.SegmentType = v })
+	varchar("text_content", func(s *StorySegment, v string) { s.TextContent = v })
+	varchar(FieldBranchKey, func(s *StorySegment, v string) { s.BranchKey = v })
+	int64s("story_time", func(s *StorySegment, v int64) { s.StoryTime = v })
+	int64s(FieldNarrativePos, func(s *StorySegment, v int64) { s.NarrativePos = v })
+	return segments
+}
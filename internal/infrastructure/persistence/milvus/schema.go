@@ -13,6 +13,16 @@ const (
 
 	// VectorDimension 向量维度
 	VectorDimension = 1024
+
+	// FieldNarrativePos 叙事位置字段（读者阅读顺序，区别于 story_time 故事内时间）
+	FieldNarrativePos = "narrative_pos"
+	// FieldSparseVector 稀疏向量字段（关键词通道，与 vector 一同用于原生混合检索）
+	FieldSparseVector = "sparse_vector"
+	// FieldBranchKey 片段所属分支（what-if 时间线）；主线为 "main"
+	FieldBranchKey = "branch_key"
+
+	// MainBranchKey 主线分支
+	MainBranchKey = "main"
 )
 
 // StorySegmentsSchema 故事片段 Collection Schema
@@ -37,6 +47,10 @@ func StorySegmentsSchema() *entity.Schema {
 					"dim": "1024",
 				},
 			},
+			{
+				Name:     FieldSparseVector,
+				DataType: entity.FieldTypeSparseVector,
+			},
 			{
 				Name:     "tenant_id",
 				DataType: entity.FieldTypeVarChar,
@@ -62,6 +76,10 @@ func StorySegmentsSchema() *entity.Schema {
 				Name:     "story_time",
 				DataType: entity.FieldTypeInt64,
 			},
+			{
+				Name:     FieldNarrativePos,
+				DataType: entity.FieldTypeInt64,
+			},
 			{
 				Name:     "segment_type",
 				DataType: entity.FieldTypeVarChar,
@@ -69,6 +87,13 @@ func StorySegmentsSchema() *entity.Schema {
 					"max_length": "32",
 				},
 			},
+			{
+				Name:     FieldBranchKey,
+				DataType: entity.FieldTypeVarChar,
+				TypeParams: map[string]string{
+					"max_length": "64",
+				},
+			},
 			{
 				Name:     "text_content",
 				DataType: entity.FieldTypeVarChar,
@@ -150,14 +175,23 @@ func EntityProfilesSchema() *entity.Schema {
 
 // StorySegment 故事片段数据结构
 type StorySegment struct {
-	ID          string    `json:"id"`
-	Vector      []float32 `json:"vector"`
-	TenantID    string    `json:"tenant_id"`
-	ProjectID   string    `json:"project_id"`
-	ChapterID   string    `json:"chapter_id"`
-	StoryTime   int64     `json:"story_time"`
-	SegmentType string    `json:"segment_type"`
-	TextContent string    `json:"text_content"`
+	ID           string    `json:"id"`
+	Vector       []float32 `json:"vector"`
+	Sparse       Sparse    `json:"sparse_vector"`
+	TenantID     string    `json:"tenant_id"`
+	ProjectID    string    `json:"project_id"`
+	ChapterID    string    `json:"chapter_id"`
+	StoryTime    int64     `json:"story_time"`
+	NarrativePos int64     `json:"narrative_pos"`
+	SegmentType  string    `json:"segment_type"`
+	BranchKey    string    `json:"branch_key"`
+	TextContent  string    `json:"text_content"`
+}
+
+// Sparse 稀疏向量（维度下标 → 权重）
+type Sparse struct {
+	Indices []uint32  `json:"indices"`
+	Values  []float32 `json:"values"`
 }
 
 // EntityProfile 实体档案数据结构
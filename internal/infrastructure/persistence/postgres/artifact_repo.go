@@ -128,7 +128,7 @@ func (r *ArtifactRepository) GetVersionByID(ctx context.Context, id string) (*en
 	return &v, nil
 }
 
-func (r *ArtifactRepository) ListVersions(ctx context.Context, artifactID string, branchKey string, pagination repository.Pagination) (*repository.PagedResult[*entity.ArtifactVersion], error) {
+func (r *ArtifactRepository) ListVersions(ctx context.Context, artifactID string, branchKey string, tag string, pagination repository.Pagination) (*repository.PagedResult[*entity.ArtifactVersion], error) {
 	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.ListVersions")
 	defer span.End()
 
@@ -137,6 +137,11 @@ func (r *ArtifactRepository) ListVersions(ctx context.Context, artifactID string
 	if branchKey != "" {
 		query = query.Where("branch_key = ?", branchKey)
 	}
+	if tag != "" {
+		query = query.Where("id IN (?)", db.Model(&entity.ArtifactVersionTag{}).
+			Select("version_id").
+			Where("artifact_id = ? AND tag = ?", artifactID, tag))
+	}
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -191,6 +196,49 @@ ORDER BY branch_key, version_no DESC;
 	return versions, nil
 }
 
+func (r *ArtifactRepository) ListVersionSummaries(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionSummary, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.ListVersionSummaries")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var out []*entity.ArtifactVersionSummary
+	if err := db.Raw(`
+SELECT id, version_no, branch_key, pg_column_size(content) AS content_bytes, created_at
+FROM artifact_versions
+WHERE artifact_id = ?
+ORDER BY branch_key, version_no DESC;
+`, artifactID).Scan(&out).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list artifact version summaries: %w", err)
+	}
+	return out, nil
+}
+
+func (r *ArtifactRepository) DeleteVersions(ctx context.Context, artifactID string, versionIDs []string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.DeleteVersions")
+	defer span.End()
+
+	if len(versionIDs) == 0 {
+		return nil, nil
+	}
+
+	db := getDB(ctx, r.client.db)
+	var deleted []string
+	// 删除时再次排除激活版本与带标签的版本，避免评估后并发回滚/打标签导致误删
+	if err := db.Raw(`
+DELETE FROM artifact_versions v
+WHERE v.artifact_id = ?
+    AND v.id IN ?
+    AND NOT EXISTS (SELECT 1 FROM project_artifacts a WHERE a.active_version_id = v.id)
+    AND NOT EXISTS (SELECT 1 FROM artifact_version_tags t WHERE t.version_id = v.id)
+RETURNING v.id;
+`, artifactID, versionIDs).Scan(&deleted).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to delete artifact versions: %w", err)
+	}
+	return deleted, nil
+}
+
 func (r *ArtifactRepository) SetActiveVersion(ctx context.Context, artifactID, versionID string) error {
 	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.SetActiveVersion")
 	defer span.End()
@@ -204,3 +252,64 @@ func (r *ArtifactRepository) SetActiveVersion(ctx context.Context, artifactID, v
 	}
 	return nil
 }
+
+func (r *ArtifactRepository) CreateTag(ctx context.Context, tag *entity.ArtifactVersionTag) error {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.CreateTag")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Create(tag).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create artifact version tag: %w", err)
+	}
+	return nil
+}
+
+func (r *ArtifactRepository) GetTag(ctx context.Context, artifactID, tag string) (*entity.ArtifactVersionTag, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.GetTag")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var t entity.ArtifactVersionTag
+	if err := tagQuery(db).Where("t.artifact_id = ? AND t.tag = ?", artifactID, tag).First(&t).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get artifact version tag: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *ArtifactRepository) ListTags(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionTag, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.ListTags")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var tags []*entity.ArtifactVersionTag
+	if err := tagQuery(db).Where("t.artifact_id = ?", artifactID).Order("v.version_no DESC, t.created_at ASC").Find(&tags).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list artifact version tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *ArtifactRepository) DeleteTag(ctx context.Context, artifactID, tag string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ArtifactRepository.DeleteTag")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	res := db.Where("artifact_id = ? AND tag = ?", artifactID, tag).Delete(&entity.ArtifactVersionTag{})
+	if res.Error != nil {
+		span.RecordError(res.Error)
+		return false, fmt.Errorf("failed to delete artifact version tag: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// tagQuery 标签查询（联表带出版本号与分支）
+func tagQuery(db *gorm.DB) *gorm.DB {
+	return db.Table("artifact_version_tags AS t").
+		Select("t.*, v.version_no, v.branch_key").
+		Joins("JOIN artifact_versions v ON v.id = t.version_id")
+}
@@ -5,13 +5,18 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 )
 
+// chapterContentColumn 正文列；列表查询默认省略，其余列（含后续新增列）始终返回
+const chapterContentColumn = "content_text"
+
 // ChapterRepository 章节仓储实现
 type ChapterRepository struct {
 	client *Client
@@ -28,7 +33,14 @@ func (r *ChapterRepository) Create(ctx context.Context, chapter *entity.Chapter)
 	defer span.End()
 
 	db := getDB(ctx, r.client.db)
-	if err := db.Create(chapter).Error; err != nil {
+	chapter.ContentHash = entity.ChapterContentHash(chapter.ContentText)
+	chapter.ContentChanged = chapter.ContentHash != ""
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(chapter).Error; err != nil {
+			return err
+		}
+		return saveChapterDerived(tx, chapter)
+	}); err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to create chapter: %w", err)
 	}
@@ -52,13 +64,42 @@ func (r *ChapterRepository) GetByID(ctx context.Context, id string) (*entity.Cha
 	return &chapter, nil
 }
 
-// Update 更新章节
+// GetByIDForUpdate 根据 ID 获取章节并加行锁
+func (r *ChapterRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.GetByIDForUpdate")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db).Clauses(clause.Locking{Strength: "UPDATE"})
+	var chapter entity.Chapter
+	if err := db.First(&chapter, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get chapter for update: %w", err)
+	}
+	return &chapter, nil
+}
+
+// Update 更新章节（按 content_hash 判断正文是否变化，结果写入 chapter.ContentChanged）
 func (r *ChapterRepository) Update(ctx context.Context, chapter *entity.Chapter) error {
 	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.Update")
 	defer span.End()
 
 	db := getDB(ctx, r.client.db)
-	if err := db.Save(chapter).Error; err != nil {
+	hash := entity.ChapterContentHash(chapter.ContentText)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		var stored []string
+		if err := tx.Model(&entity.Chapter{}).Where("id = ?", chapter.ID).Pluck("content_hash", &stored).Error; err != nil {
+			return err
+		}
+		chapter.ContentChanged = len(stored) == 0 || stored[0] != hash
+		chapter.ContentHash = hash
+		if err := tx.Save(chapter).Error; err != nil {
+			return err
+		}
+		return saveChapterDerived(tx, chapter)
+	}); err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to update chapter: %w", err)
 	}
@@ -87,7 +128,9 @@ func (r *ChapterRepository) ListByProject(ctx context.Context, projectID string,
 	query := db.Model(&entity.Chapter{}).Where("project_id = ?", projectID)
 
 	// 应用过滤条件
+	includeContent := false
 	if filter != nil {
+		includeContent = filter.IncludeContent
 		if filter.VolumeID != "" {
 			query = query.Where("volume_id = ?", filter.VolumeID)
 		}
@@ -104,6 +147,10 @@ func (r *ChapterRepository) ListByProject(ctx context.Context, projectID string,
 	}
 
 	// 获取列表
+	if !includeContent {
+		query = query.Omit(chapterContentColumn)
+	}
+
 	var chapters []*entity.Chapter
 	if err := query.Order("seq_num ASC").
 		Offset(pagination.Offset()).
@@ -181,11 +228,23 @@ func (r *ChapterRepository) UpdateContent(ctx context.Context, id, content, summ
 
 	db := getDB(ctx, r.client.db)
 	wordCount := len([]rune(content))
-	if err := db.Model(&entity.Chapter{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"content_text": content,
-		"summary":      summary,
-		"word_count":   wordCount,
-	}).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.Chapter{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"content_text": content,
+			"content_hash": entity.ChapterContentHash(content),
+			"summary":      summary,
+			"word_count":   wordCount,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		var chapter entity.Chapter
+		if err := tx.Select("id", "project_id", "version").Take(&chapter, "id = ?", id).Error; err != nil {
+			return err
+		}
+		chapter.ContentText = content
+		return saveChapterDerived(tx, &chapter)
+	}); err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("failed to update chapter content: %w", err)
 	}
@@ -205,6 +264,90 @@ func (r *ChapterRepository) UpdateStatus(ctx context.Context, id string, status
 	return nil
 }
 
+// UpdateContextPins 更新章节固定上下文
+func (r *ChapterRepository) UpdateContextPins(ctx context.Context, id string, pins []entity.ContextPin) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.UpdateContextPins")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Chapter{ID: id}).Select("context_pins").Updates(&entity.Chapter{ContextPins: pins}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update chapter context pins: %w", err)
+	}
+	return nil
+}
+
+// UpdateGenerationMetadata 更新章节生成元数据
+func (r *ChapterRepository) UpdateGenerationMetadata(ctx context.Context, id string, meta *entity.GenerationMetadata) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.UpdateGenerationMetadata")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Chapter{ID: id}).Select("generation_metadata").Updates(&entity.Chapter{GenerationMetadata: meta}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update chapter generation metadata: %w", err)
+	}
+	return nil
+}
+
+// MarkOutlineStale 标记关联到给定大纲条目的章节为大纲过期
+func (r *ChapterRepository) MarkOutlineStale(ctx context.Context, projectID string, outlineKeys []string, at time.Time) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.MarkOutlineStale")
+	defer span.End()
+
+	if len(outlineKeys) == 0 {
+		return nil, nil
+	}
+	db := getDB(ctx, r.client.db)
+	var ids []string
+	if err := db.Model(&entity.Chapter{}).
+		Where("project_id = ? AND outline_key IN ?", projectID, outlineKeys).
+		Pluck("id", &ids).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to find outline-linked chapters: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := db.Model(&entity.Chapter{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+		"outline_stale":       true,
+		"outline_stale_since": gorm.Expr("COALESCE(outline_stale_since, ?)", at),
+	}).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to mark chapters outline stale: %w", err)
+	}
+	return ids, nil
+}
+
+// ClearOutlineStale 清除章节的大纲过期标记
+func (r *ChapterRepository) ClearOutlineStale(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ClearOutlineStale")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Chapter{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"outline_stale":       false,
+		"outline_stale_since": nil,
+	}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to clear chapter outline stale: %w", err)
+	}
+	return nil
+}
+
+// UpdateWordCount 更新章节字数（卷汇总由 chapters 表触发器同步）
+func (r *ChapterRepository) UpdateWordCount(ctx context.Context, id string, wordCount int) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.UpdateWordCount")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Chapter{}).Where("id = ?", id).UpdateColumn("word_count", wordCount).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update chapter word count: %w", err)
+	}
+	return nil
+}
+
 // ReorderChapters 重新排序某一卷下的章节（按给定 ID 顺序；未包含的章节会追加到末尾）
 func (r *ChapterRepository) ReorderChapters(ctx context.Context, projectID, volumeID string, chapterIDs []string) error {
 	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ReorderChapters")
@@ -295,6 +438,27 @@ func (r *ChapterRepository) GetNextSeqNum(ctx context.Context, projectID, volume
 	return *maxSeq + 1, nil
 }
 
+// RefreshDisplayNumbers 按叙事顺序重新分配项目章节的展示序号（仅更新变化的行）
+func (r *ChapterRepository) RefreshDisplayNumbers(ctx context.Context, projectID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.RefreshDisplayNumbers")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Exec(`
+UPDATE chapters c SET display_no = o.rn
+FROM (
+	SELECT ch.id, ROW_NUMBER() OVER (ORDER BY COALESCE(v.seq_num, 0) ASC, ch.seq_num ASC, ch.created_at ASC) AS rn
+	FROM chapters ch
+	LEFT JOIN volumes v ON v.id = ch.volume_id
+	WHERE ch.project_id = ?
+) o
+WHERE c.id = o.id AND c.display_no <> o.rn`, projectID).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to refresh chapter display numbers: %w", err)
+	}
+	return nil
+}
+
 // GetByStoryTimeRange 根据故事时间范围获取章节
 func (r *ChapterRepository) GetByStoryTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Chapter, error) {
 	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.GetByStoryTimeRange")
@@ -319,6 +483,136 @@ func (r *ChapterRepository) GetByStoryTimeRange(ctx context.Context, projectID s
 	return chapters, nil
 }
 
+// ListTimeline 按叙事顺序获取项目章节的时间轴字段（不含正文）
+func (r *ChapterRepository) ListTimeline(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListTimeline")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var chapters []*entity.Chapter
+
+	if err := db.Model(&entity.Chapter{}).
+		Select("chapters.id, chapters.project_id, chapters.volume_id, chapters.seq_num, chapters.display_no, chapters.title, chapters.story_time_start, chapters.story_time_end, chapters.is_flashback").
+		Joins("LEFT JOIN volumes ON volumes.id = chapters.volume_id").
+		Where("chapters.project_id = ?", projectID).
+		Order("COALESCE(volumes.seq_num, 0) ASC, chapters.seq_num ASC").
+		Find(&chapters).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list chapter timeline: %w", err)
+	}
+
+	return chapters, nil
+}
+
+// ListOutlineLinks 按叙事顺序获取项目全部章节的大纲关联字段
+func (r *ChapterRepository) ListOutlineLinks(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListOutlineLinks")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var chapters []*entity.Chapter
+
+	if err := db.Model(&entity.Chapter{}).
+		Select("chapters.id, chapters.project_id, chapters.ai_key, chapters.outline_key, chapters.volume_id, chapters.seq_num, chapters.title, chapters.word_count, chapters.status, chapters.outline_stale, chapters.outline_stale_since").
+		Joins("LEFT JOIN volumes ON volumes.id = chapters.volume_id").
+		Where("chapters.project_id = ?", projectID).
+		Order("COALESCE(volumes.seq_num, 0) ASC, chapters.seq_num ASC").
+		Find(&chapters).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list chapter outline links: %w", err)
+	}
+
+	return chapters, nil
+}
+
+// ListPublished 按叙事顺序获取项目已完成章节
+func (r *ChapterRepository) ListPublished(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListPublished")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var chapters []*entity.Chapter
+
+	if err := db.Model(&entity.Chapter{}).
+		Select("chapters.id, chapters.project_id, chapters.volume_id, chapters.seq_num, chapters.display_no, chapters.title, chapters.summary, chapters.word_count, chapters.status, chapters.updated_at").
+		Joins("LEFT JOIN volumes ON volumes.id = chapters.volume_id").
+		Where("chapters.project_id = ? AND chapters.status = ?", projectID, entity.ChapterStatusCompleted).
+		Order("COALESCE(volumes.seq_num, 0) ASC, chapters.seq_num ASC").
+		Find(&chapters).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list published chapters: %w", err)
+	}
+
+	return chapters, nil
+}
+
+// ListManuscript 按叙事顺序获取项目已完成章节（含正文）
+func (r *ChapterRepository) ListManuscript(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListManuscript")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var chapters []*entity.Chapter
+
+	if err := db.Model(&entity.Chapter{}).
+		Select("chapters.*").
+		Joins("LEFT JOIN volumes ON volumes.id = chapters.volume_id").
+		Where("chapters.project_id = ? AND chapters.status = ?", projectID, entity.ChapterStatusCompleted).
+		Order("COALESCE(volumes.seq_num, 0) ASC, chapters.seq_num ASC").
+		Find(&chapters).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list manuscript chapters: %w", err)
+	}
+
+	return chapters, nil
+}
+
+// GetNarrativePosition 获取章节的叙事位置
+func (r *ChapterRepository) GetNarrativePosition(ctx context.Context, chapterID string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.GetNarrativePosition")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var row struct {
+		VolumeSeq  int
+		ChapterSeq int
+	}
+
+	if err := db.Model(&entity.Chapter{}).
+		Select("COALESCE(volumes.seq_num, 0) AS volume_seq, chapters.seq_num AS chapter_seq").
+		Joins("LEFT JOIN volumes ON volumes.id = chapters.volume_id").
+		Where("chapters.id = ?", chapterID).
+		Take(&row).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to get chapter narrative position: %w", err)
+	}
+
+	return entity.NarrativePosition(row.VolumeSeq, row.ChapterSeq), nil
+}
+
+// ListByStatus 获取指定状态且 updated_at 早于 updatedBefore 的章节（不含正文）
+func (r *ChapterRepository) ListByStatus(ctx context.Context, status entity.ChapterStatus, updatedBefore time.Time, limit int) ([]*entity.Chapter, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListByStatus")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var chapters []*entity.Chapter
+
+	if err := db.Omit(chapterContentColumn).
+		Where("status = ? AND updated_at < ?", status, updatedBefore).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&chapters).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list chapters by status: %w", err)
+	}
+
+	return chapters, nil
+}
+
 // GetRecent 获取最近章节
 func (r *ChapterRepository) GetRecent(ctx context.Context, projectID string, limit int) ([]*entity.Chapter, error) {
 	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.GetRecent")
@@ -327,7 +621,8 @@ func (r *ChapterRepository) GetRecent(ctx context.Context, projectID string, lim
 	db := getDB(ctx, r.client.db)
 	var chapters []*entity.Chapter
 
-	if err := db.Where("project_id = ?", projectID).
+	if err := db.Omit(chapterContentColumn).
+		Where("project_id = ?", projectID).
 		Order("seq_num DESC").
 		Limit(limit).
 		Find(&chapters).Error; err != nil {
@@ -337,3 +632,105 @@ func (r *ChapterRepository) GetRecent(ctx context.Context, projectID string, lim
 
 	return chapters, nil
 }
+
+// ListStats 获取项目全部章节的文本统计
+func (r *ChapterRepository) ListStats(ctx context.Context, projectID string) ([]*entity.ChapterStats, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListStats")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var stats []*entity.ChapterStats
+	if err := db.Where("project_id = ?", projectID).Find(&stats).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list chapter stats: %w", err)
+	}
+	return stats, nil
+}
+
+// SaveStats 写入（覆盖）章节文本统计
+func (r *ChapterRepository) SaveStats(ctx context.Context, stats *entity.ChapterStats) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.SaveStats")
+	defer span.End()
+
+	if err := upsertByChapterID(getDB(ctx, r.client.db), stats); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to save chapter stats: %w", err)
+	}
+	return nil
+}
+
+// ListFingerprints 获取项目全部章节的内容指纹
+func (r *ChapterRepository) ListFingerprints(ctx context.Context, projectID string) ([]*entity.ChapterFingerprint, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.ListFingerprints")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var fps []*entity.ChapterFingerprint
+	if err := db.Where("project_id = ?", projectID).Find(&fps).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list chapter fingerprints: %w", err)
+	}
+	return fps, nil
+}
+
+// SaveFingerprint 写入（覆盖）章节内容指纹
+func (r *ChapterRepository) SaveFingerprint(ctx context.Context, fp *entity.ChapterFingerprint) error {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.SaveFingerprint")
+	defer span.End()
+
+	if err := upsertByChapterID(getDB(ctx, r.client.db), fp); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to save chapter fingerprint: %w", err)
+	}
+	return nil
+}
+
+// GetVersion 获取章节指定版本的正文快照
+func (r *ChapterRepository) GetVersion(ctx context.Context, chapterID string, version int) (*entity.ChapterVersion, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ChapterRepository.GetVersion")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var v entity.ChapterVersion
+	if err := db.First(&v, "chapter_id = ? AND version = ?", chapterID, version).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get chapter version: %w", err)
+	}
+	return &v, nil
+}
+
+// saveChapterDerived 按正文重新计算并 upsert 章节统计、内容指纹与当前版本快照
+func saveChapterDerived(db *gorm.DB, chapter *entity.Chapter) error {
+	if err := upsertByChapterID(db, entity.NewChapterStats(chapter)); err != nil {
+		return err
+	}
+	if err := upsertByChapterID(db, entity.NewChapterFingerprint(chapter)); err != nil {
+		return err
+	}
+	return saveChapterVersion(db, chapter)
+}
+
+// saveChapterVersion upsert 当前版本号的正文快照（空正文不留快照；正文未变化时不重写）
+func saveChapterVersion(db *gorm.DB, chapter *entity.Chapter) error {
+	if chapter.ContentText == "" || chapter.Version <= 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chapter_id"}, {Name: "version"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content_text", "word_count", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "chapter_versions.content_text IS DISTINCT FROM excluded.content_text"},
+		}},
+	}).Create(entity.NewChapterVersion(chapter)).Error
+}
+
+// upsertByChapterID 按 chapter_id upsert 章节派生数据（统计 / 指纹）
+func upsertByChapterID(db *gorm.DB, row any) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chapter_id"}},
+		UpdateAll: true,
+	}).Create(row).Error
+}
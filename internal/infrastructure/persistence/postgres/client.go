@@ -17,7 +17,7 @@ import (
 	"z-novel-ai-api/internal/config"
 )
 
-var tracer = otel.Tracer("postgres")
+var tracer = labeledTracer{Tracer: otel.Tracer("postgres")}
 
 // Client PostgreSQL 客户端（GORM 版本）
 type Client struct {
@@ -52,6 +52,10 @@ func NewClient(cfg *config.PostgresConfig) (*Client, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	if err := registerQueryMetrics(db, cfg.SlowQueryThreshold); err != nil {
+		return nil, fmt.Errorf("failed to register query metrics: %w", err)
+	}
+
 	// 配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -3,8 +3,12 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 )
@@ -29,6 +33,34 @@ func (r *ConversationTurnRepository) Create(ctx context.Context, turn *entity.Co
 	return nil
 }
 
+func (r *ConversationTurnRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.ConversationTurn, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ConversationTurnRepository.GetByIDForUpdate")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db).Clauses(clause.Locking{Strength: "UPDATE"})
+	var turn entity.ConversationTurn
+	if err := db.First(&turn, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get conversation turn for update: %w", err)
+	}
+	return &turn, nil
+}
+
+func (r *ConversationTurnRepository) UpdateMetadata(ctx context.Context, id string, metadata json.RawMessage) error {
+	ctx, span := tracer.Start(ctx, "postgres.ConversationTurnRepository.UpdateMetadata")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.ConversationTurn{}).Where("id = ?", id).UpdateColumn("metadata", metadata).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update conversation turn metadata: %w", err)
+	}
+	return nil
+}
+
 func (r *ConversationTurnRepository) ListBySession(ctx context.Context, sessionID string, pagination repository.Pagination) (*repository.PagedResult[*entity.ConversationTurn], error) {
 	ctx, span := tracer.Start(ctx, "postgres.ConversationTurnRepository.ListBySession")
 	defer span.End()
@@ -53,3 +85,57 @@ func (r *ConversationTurnRepository) ListBySession(ctx context.Context, sessionI
 
 	return repository.NewPagedResult(turns, total, pagination), nil
 }
+
+func (r *ConversationTurnRepository) SumUsageBySession(ctx context.Context, sessionID string) (*entity.ConversationUsage, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ConversationTurnRepository.SumUsageBySession")
+	defer span.End()
+
+	var rows []struct {
+		CostCurrency     string
+		Turns            int64
+		PromptTokens     int64
+		CompletionTokens int64
+		Cost             float64
+	}
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.ConversationTurn{}).
+		Select("COALESCE(cost_currency, '') AS cost_currency, COUNT(*) AS turns, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, SUM(cost) AS cost").
+		Where("session_id = ? AND role = ?", sessionID, entity.RoleAssistant).
+		Group("COALESCE(cost_currency, '')").
+		Scan(&rows).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to sum conversation usage: %w", err)
+	}
+
+	usage := &entity.ConversationUsage{}
+	for _, row := range rows {
+		usage.Turns += row.Turns
+		usage.PromptTokens += row.PromptTokens
+		usage.CompletionTokens += row.CompletionTokens
+		if row.CostCurrency != "" && row.Cost > 0 {
+			if usage.Costs == nil {
+				usage.Costs = make(map[string]float64)
+			}
+			usage.Costs[row.CostCurrency] += row.Cost
+		}
+	}
+	return usage, nil
+}
+
+func (r *ConversationTurnRepository) ListConflictWarnings(ctx context.Context, versionIDs []string) ([]*entity.ConversationTurn, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ConversationTurnRepository.ListConflictWarnings")
+	defer span.End()
+
+	if len(versionIDs) == 0 {
+		return nil, nil
+	}
+	db := getDB(ctx, r.client.db)
+	var turns []*entity.ConversationTurn
+	if err := db.Where("role = ? AND metadata->'conflict_warnings' IS NOT NULL AND metadata->>'version_id' IN ?", entity.RoleAssistant, versionIDs).
+		Order("created_at ASC").
+		Find(&turns).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list conflict warning turns: %w", err)
+	}
+	return turns, nil
+}
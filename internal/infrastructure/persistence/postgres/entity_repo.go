@@ -208,6 +208,31 @@ func (r *EntityRepository) RecordAppearance(ctx context.Context, id, chapterID s
 	return nil
 }
 
+// SetAppearances 覆盖实体出场统计
+func (r *EntityRepository) SetAppearances(ctx context.Context, id, firstChapterID, lastChapterID string, count int) error {
+	ctx, span := tracer.Start(ctx, "postgres.EntityRepository.SetAppearances")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.StoryEntity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"appear_count":            count,
+		"first_appear_chapter_id": nullIfEmpty(firstChapterID),
+		"last_appear_chapter_id":  nullIfEmpty(lastChapterID),
+	}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to set entity appearances: %w", err)
+	}
+	return nil
+}
+
+// nullIfEmpty 空字符串写为 NULL（uuid 列不接受空串）
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
 // GetByType 根据类型获取实体列表
 func (r *EntityRepository) GetByType(ctx context.Context, projectID string, entityType entity.StoryEntityType) ([]*entity.StoryEntity, error) {
 	ctx, span := tracer.Start(ctx, "postgres.EntityRepository.GetByType")
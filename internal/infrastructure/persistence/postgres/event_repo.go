@@ -4,6 +4,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -86,6 +87,9 @@ func (r *EventRepository) ListByProject(ctx context.Context, projectID string, f
 	query := db.Model(&entity.Event{}).Where("project_id = ?", projectID)
 
 	// 应用过滤条件
+	if filter == nil || !filter.IncludeSuperseded {
+		query = query.Where("superseded_at IS NULL")
+	}
 	if filter != nil {
 		if filter.ChapterID != "" {
 			query = query.Where("chapter_id = ?", filter.ChapterID)
@@ -132,7 +136,7 @@ func (r *EventRepository) ListByChapter(ctx context.Context, chapterID string) (
 	db := getDB(ctx, r.client.db)
 	var events []*entity.Event
 
-	if err := db.Where("chapter_id = ?", chapterID).
+	if err := db.Where("chapter_id = ? AND superseded_at IS NULL", chapterID).
 		Order("story_time_start ASC").
 		Find(&events).Error; err != nil {
 		span.RecordError(err)
@@ -142,6 +146,22 @@ func (r *EventRepository) ListByChapter(ctx context.Context, chapterID string) (
 	return events, nil
 }
 
+// Supersede 将事件标记为已被替代
+func (r *EventRepository) Supersede(ctx context.Context, id string, supersededBy *string, at time.Time) error {
+	ctx, span := tracer.Start(ctx, "postgres.EventRepository.Supersede")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Event{}).Where("id = ?", id).Updates(map[string]any{
+		"superseded_at": at,
+		"superseded_by": supersededBy,
+	}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to supersede event: %w", err)
+	}
+	return nil
+}
+
 // GetByTimeRange 根据时间范围获取事件
 func (r *EventRepository) GetByTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Event, error) {
 	ctx, span := tracer.Start(ctx, "postgres.EventRepository.GetByTimeRange")
@@ -150,7 +170,7 @@ func (r *EventRepository) GetByTimeRange(ctx context.Context, projectID string,
 	db := getDB(ctx, r.client.db)
 	var events []*entity.Event
 
-	query := db.Where("project_id = ?", projectID)
+	query := db.Where("project_id = ? AND superseded_at IS NULL", projectID)
 	if startTime > 0 {
 		query = query.Where("story_time_end >= ? OR story_time_end = 0", startTime)
 	}
@@ -172,7 +192,9 @@ func (r *EventRepository) GetByEntity(ctx context.Context, entityID string, pagi
 	defer span.End()
 
 	db := getDB(ctx, r.client.db)
-	query := db.Model(&entity.Event{}).Where("involved_entities @> ?", fmt.Sprintf(`["%s"]`, entityID))
+	query := db.Model(&entity.Event{}).
+		Where("involved_entities @> ?", fmt.Sprintf(`["%s"]`, entityID)).
+		Where("superseded_at IS NULL")
 
 	// 获取总数
 	var total int64
@@ -215,7 +237,7 @@ func (r *EventRepository) GetTimeline(ctx context.Context, projectID string, lim
 	db := getDB(ctx, r.client.db)
 	var events []*entity.Event
 
-	if err := db.Where("project_id = ?", projectID).
+	if err := db.Where("project_id = ? AND superseded_at IS NULL", projectID).
 		Order("story_time_start ASC").
 		Limit(limit).
 		Find(&events).Error; err != nil {
@@ -234,7 +256,7 @@ func (r *EventRepository) SearchByTags(ctx context.Context, projectID string, ta
 	db := getDB(ctx, r.client.db)
 	var events []*entity.Event
 
-	query := db.Where("project_id = ?", projectID)
+	query := db.Where("project_id = ? AND superseded_at IS NULL", projectID)
 	for _, tag := range tags {
 		query = query.Where("tags @> ?", fmt.Sprintf(`["%s"]`, tag))
 	}
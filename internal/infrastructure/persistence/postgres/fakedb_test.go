@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// fakePG 模拟 PostgreSQL 事务语义的最小驱动：语句失败后事务进入 aborted 状态，
+// 之后除 ROLLBACK [TO SAVEPOINT] 外的语句全部失败；COMMIT 已 aborted 的事务等同回滚。
+type fakePG struct {
+	mu         sync.Mutex
+	failOn     string // 语句包含该片段时失败
+	aborted    bool
+	inTx       bool
+	statements []string
+	committed  bool
+}
+
+var errTxAborted = errors.New("ERROR: current transaction is aborted, commands ignored until end of transaction block (SQLSTATE 25P02)")
+
+func (f *fakePG) exec(query string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, query)
+	q := strings.TrimSpace(query)
+	switch {
+	case strings.HasPrefix(q, "ROLLBACK TO SAVEPOINT"):
+		f.aborted = false
+		return nil
+	case f.aborted:
+		return errTxAborted
+	case f.failOn != "" && strings.Contains(q, f.failOn):
+		if f.inTx {
+			f.aborted = true
+		}
+		return errors.New("ERROR: insert failed (SQLSTATE 23503)")
+	}
+	return nil
+}
+
+func (f *fakePG) executed(fragment string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.statements {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFakeClient 创建基于 fakePG 的客户端（GORM 使用真实的 postgres 方言生成 SQL）
+func newFakeClient(t *testing.T, f *fakePG) *Client {
+	t.Helper()
+	sqlDB := sql.OpenDB(&fakeConnector{db: f})
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: sqlDB}), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	return &Client{db: db}
+}
+
+type fakeConnector struct{ db *fakePG }
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+func (c *fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use connector") }
+
+type fakeConn struct{ db *fakePG }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.inTx, c.db.aborted = true, false
+	return &fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.db.exec(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.db.exec(query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{ db *fakePG }
+
+func (t *fakeTx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.inTx = false
+	if t.db.aborted {
+		t.db.aborted = false
+		return errTxAborted
+	}
+	t.db.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.inTx, t.db.aborted = false, false
+	return nil
+}
+
+// fakeRows 空结果集（RETURNING 不回填，调用方保留原值）
+type fakeRows struct{}
+
+func (*fakeRows) Columns() []string         { return nil }
+func (*fakeRows) Close() error              { return nil }
+func (*fakeRows) Next([]driver.Value) error { return io.EOF }
@@ -0,0 +1,117 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// FeatureFlagRepository 功能开关仓储实现
+type FeatureFlagRepository struct {
+	client *Client
+}
+
+// NewFeatureFlagRepository 创建功能开关仓储
+func NewFeatureFlagRepository(client *Client) *FeatureFlagRepository {
+	return &FeatureFlagRepository{client: client}
+}
+
+// ListFlags 获取全部全局开关定义
+func (r *FeatureFlagRepository) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	ctx, span := tracer.Start(ctx, "postgres.FeatureFlagRepository.ListFlags")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var flags []*entity.FeatureFlag
+	if err := db.Order("key ASC").Find(&flags).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// GetFlag 根据 Key 获取开关定义
+func (r *FeatureFlagRepository) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	ctx, span := tracer.Start(ctx, "postgres.FeatureFlagRepository.GetFlag")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var flag entity.FeatureFlag
+	if err := db.First(&flag, "key = ?", key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+	return &flag, nil
+}
+
+// ListOverrides 获取租户的全部覆盖（含项目级）
+func (r *FeatureFlagRepository) ListOverrides(ctx context.Context, tenantID string) ([]*entity.FeatureFlagOverride, error) {
+	ctx, span := tracer.Start(ctx, "postgres.FeatureFlagRepository.ListOverrides")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var overrides []*entity.FeatureFlagOverride
+	if err := db.Where("tenant_id = ?", tenantID).Order("flag_key ASC, created_at ASC").Find(&overrides).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertOverride 创建或更新覆盖（按租户 + 开关 + 项目唯一）
+func (r *FeatureFlagRepository) UpsertOverride(ctx context.Context, override *entity.FeatureFlagOverride) error {
+	ctx, span := tracer.Start(ctx, "postgres.FeatureFlagRepository.UpsertOverride")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var existing entity.FeatureFlagOverride
+	err := overrideScope(db, override.TenantID, override.FlagKey, override.ProjectID).First(&existing).Error
+	switch {
+	case err == nil:
+		if err := db.Model(&existing).Update("enabled", override.Enabled).Error; err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to update feature flag override: %w", err)
+		}
+		override.ID = existing.ID
+		override.CreatedAt = existing.CreatedAt
+		override.UpdatedAt = existing.UpdatedAt
+		return nil
+	case err != gorm.ErrRecordNotFound:
+		span.RecordError(err)
+		return fmt.Errorf("failed to get feature flag override: %w", err)
+	}
+
+	if err := db.Create(override).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create feature flag override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride 删除覆盖（projectID 为空表示租户级覆盖）
+func (r *FeatureFlagRepository) DeleteOverride(ctx context.Context, tenantID, flagKey string, projectID *string) error {
+	ctx, span := tracer.Start(ctx, "postgres.FeatureFlagRepository.DeleteOverride")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := overrideScope(db, tenantID, flagKey, projectID).Delete(&entity.FeatureFlagOverride{}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+	return nil
+}
+
+func overrideScope(db *gorm.DB, tenantID, flagKey string, projectID *string) *gorm.DB {
+	q := db.Where("tenant_id = ? AND flag_key = ?", tenantID, flagKey)
+	if projectID != nil && *projectID != "" {
+		return q.Where("project_id = ?", *projectID)
+	}
+	return q.Where("project_id IS NULL")
+}
@@ -0,0 +1,112 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// GenerationCandidateRepository 多候选生成结果仓储实现
+type GenerationCandidateRepository struct {
+	client *Client
+}
+
+// NewGenerationCandidateRepository 创建多候选生成结果仓储
+func NewGenerationCandidateRepository(client *Client) *GenerationCandidateRepository {
+	return &GenerationCandidateRepository{client: client}
+}
+
+// CreateBatch 批量保存同一任务的候选结果
+func (r *GenerationCandidateRepository) CreateBatch(ctx context.Context, candidates []*entity.GenerationCandidate) error {
+	ctx, span := tracer.Start(ctx, "postgres.GenerationCandidateRepository.CreateBatch")
+	defer span.End()
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	db := getDB(ctx, r.client.db)
+	if err := db.Create(&candidates).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create generation candidates: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取候选结果
+func (r *GenerationCandidateRepository) GetByID(ctx context.Context, id string) (*entity.GenerationCandidate, error) {
+	ctx, span := tracer.Start(ctx, "postgres.GenerationCandidateRepository.GetByID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var candidate entity.GenerationCandidate
+	if err := db.First(&candidate, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get generation candidate: %w", err)
+	}
+	return &candidate, nil
+}
+
+// ListByJob 按候选序号获取任务的全部候选结果
+func (r *GenerationCandidateRepository) ListByJob(ctx context.Context, jobID string) ([]*entity.GenerationCandidate, error) {
+	ctx, span := tracer.Start(ctx, "postgres.GenerationCandidateRepository.ListByJob")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var candidates []*entity.GenerationCandidate
+	if err := db.Where("job_id = ?", jobID).Order("candidate_no ASC").Find(&candidates).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list generation candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// MarkSelected 将候选标记为已采用，同任务其余候选标记为未采用
+func (r *GenerationCandidateRepository) MarkSelected(ctx context.Context, jobID, candidateID string, versionID *string) error {
+	ctx, span := tracer.Start(ctx, "postgres.GenerationCandidateRepository.MarkSelected")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.GenerationCandidate{}).
+		Where("job_id = ? AND id <> ?", jobID, candidateID).
+		Update("status", entity.CandidateStatusDiscarded).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to discard generation candidates: %w", err)
+	}
+	updates := map[string]any{
+		"status":      entity.CandidateStatusSelected,
+		"selected_at": time.Now(),
+	}
+	if versionID != nil {
+		updates["version_id"] = *versionID
+	}
+	if err := db.Model(&entity.GenerationCandidate{}).
+		Where("id = ? AND job_id = ?", candidateID, jobID).
+		Updates(updates).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to select generation candidate: %w", err)
+	}
+	return nil
+}
+
+// MarkDiscarded 将单个候选标记为未采用
+func (r *GenerationCandidateRepository) MarkDiscarded(ctx context.Context, candidateID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.GenerationCandidateRepository.MarkDiscarded")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.GenerationCandidate{}).
+		Where("id = ?", candidateID).
+		Update("status", entity.CandidateStatusDiscarded).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to discard generation candidate: %w", err)
+	}
+	return nil
+}
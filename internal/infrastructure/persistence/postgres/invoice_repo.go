@@ -0,0 +1,83 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// InvoiceRepository 账单仓储实现
+type InvoiceRepository struct {
+	client *Client
+}
+
+// NewInvoiceRepository 创建账单仓储
+func NewInvoiceRepository(client *Client) *InvoiceRepository {
+	return &InvoiceRepository{client: client}
+}
+
+// Create 创建账单；(provider, external_id) 冲突时不写入并返回 false
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) (bool, error) {
+	ctx, span := tracer.Start(ctx, "postgres.InvoiceRepository.Create")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider"}, {Name: "external_id"}},
+		DoNothing: true,
+	}).Create(invoice)
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		return false, fmt.Errorf("failed to create invoice: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByID 根据 ID 获取账单
+func (r *InvoiceRepository) GetByID(ctx context.Context, id string) (*entity.Invoice, error) {
+	ctx, span := tracer.Start(ctx, "postgres.InvoiceRepository.GetByID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var invoice entity.Invoice
+	if err := db.First(&invoice, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return &invoice, nil
+}
+
+// ListByTenant 获取租户账单（按支付时间倒序）
+func (r *InvoiceRepository) ListByTenant(ctx context.Context, tenantID string, pagination repository.Pagination) (*repository.PagedResult[*entity.Invoice], error) {
+	ctx, span := tracer.Start(ctx, "postgres.InvoiceRepository.ListByTenant")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	query := db.Model(&entity.Invoice{}).Where("tenant_id = ?", tenantID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count invoices: %w", err)
+	}
+
+	var invoices []*entity.Invoice
+	if err := query.Order("paid_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Limit()).
+		Find(&invoices).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	return repository.NewPagedResult(invoices, total, pagination), nil
+}
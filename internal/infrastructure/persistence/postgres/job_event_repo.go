@@ -0,0 +1,52 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// JobEventRepository 任务时间线事件仓储实现
+type JobEventRepository struct {
+	client *Client
+}
+
+// NewJobEventRepository 创建任务时间线事件仓储
+func NewJobEventRepository(client *Client) *JobEventRepository {
+	return &JobEventRepository{client: client}
+}
+
+// Create 追加事件。
+// 在调用方事务内以保存点（SAVEPOINT）执行：插入失败只回滚到保存点，
+// 不会使整个事务进入 aborted 状态，调用方后续的任务状态更新仍可提交。
+func (r *JobEventRepository) Create(ctx context.Context, event *entity.JobEvent) error {
+	ctx, span := tracer.Start(ctx, "postgres.JobEventRepository.Create")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(event).Error
+	}); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create job event: %w", err)
+	}
+	return nil
+}
+
+// ListByJob 按时间顺序获取任务的全部事件
+func (r *JobEventRepository) ListByJob(ctx context.Context, jobID string) ([]*entity.JobEvent, error) {
+	ctx, span := tracer.Start(ctx, "postgres.JobEventRepository.ListByJob")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var events []*entity.JobEvent
+	if err := db.Where("job_id = ?", jobID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list job events: %w", err)
+	}
+	return events, nil
+}
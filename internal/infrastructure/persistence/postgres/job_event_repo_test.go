@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+func TestJobEventInsertFailureDoesNotAbortCallerTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := &fakePG{failOn: `INSERT INTO "job_events"`}
+	client := newFakeClient(t, db)
+	txMgr := NewTxManager(client)
+	jobs := NewJobRepository(client)
+	timeline := story.NewJobTimeline(NewJobEventRepository(client))
+
+	job := &entity.GenerationJob{ID: "job-1", TenantID: "t1", ProjectID: "p1", Status: entity.JobStatusRunning}
+	err := txMgr.WithTransaction(ctx, func(txCtx context.Context) error {
+		timeline.Record(txCtx, job, entity.JobEventLLMStarted, "generation started", nil)
+		job.Complete(nil)
+		return jobs.Update(txCtx, job)
+	})
+	if err != nil {
+		t.Fatalf("job update should survive a failed timeline insert, got %v", err)
+	}
+	if !db.executed(`INSERT INTO "job_events"`) || !db.executed("ROLLBACK TO SAVEPOINT") {
+		t.Fatalf("timeline insert should run inside a savepoint, statements: %v", db.statements)
+	}
+	if !db.executed(`UPDATE "generation_jobs"`) || !db.committed {
+		t.Fatalf("job completion should be committed, statements: %v", db.statements)
+	}
+}
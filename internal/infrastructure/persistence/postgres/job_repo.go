@@ -3,6 +3,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -94,9 +95,18 @@ func (r *JobRepository) ListByProject(ctx context.Context, projectID string, fil
 		if filter.JobType != "" {
 			query = query.Where("job_type = ?", filter.JobType)
 		}
+		if filter.Category != "" {
+			query = query.Where("category = ?", filter.Category)
+		}
 		if filter.Status != "" {
 			query = query.Where("status = ?", filter.Status)
 		}
+		if filter.MinPriority > 0 {
+			query = query.Where("priority >= ?", filter.MinPriority)
+		}
+		if filter.MaxPriority > 0 {
+			query = query.Where("priority <= ?", filter.MaxPriority)
+		}
 	}
 
 	// 获取总数
@@ -162,6 +172,42 @@ func (r *JobRepository) UpdateProgress(ctx context.Context, id string, progress
 	return nil
 }
 
+// UpdateInputSnapshot 记录任务执行时的输入快照
+func (r *JobRepository) UpdateInputSnapshot(ctx context.Context, id string, snapshot json.RawMessage) error {
+	ctx, span := tracer.Start(ctx, "postgres.JobRepository.UpdateInputSnapshot")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.GenerationJob{}).Where("id = ?", id).Update("input_snapshot", snapshot).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update job input snapshot: %w", err)
+	}
+	return nil
+}
+
+// AppendWarnings 追加任务警告（拼接 JSONB 数组，不覆盖并发写入的其他列）
+func (r *JobRepository) AppendWarnings(ctx context.Context, id string, warnings ...entity.JobWarning) error {
+	ctx, span := tracer.Start(ctx, "postgres.JobRepository.AppendWarnings")
+	defer span.End()
+
+	if len(warnings) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(warnings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job warnings: %w", err)
+	}
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.GenerationJob{}).
+		Where("id = ? AND jsonb_array_length(COALESCE(warnings, '[]'::jsonb)) < ?", id, entity.MaxJobWarnings).
+		Update("warnings", gorm.Expr("COALESCE(warnings, '[]'::jsonb) || ?::jsonb", string(payload))).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to append job warnings: %w", err)
+	}
+	return nil
+}
+
 // GetPendingJobs 获取待处理任务
 func (r *JobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*entity.GenerationJob, error) {
 	ctx, span := tracer.Start(ctx, "postgres.JobRepository.GetPendingJobs")
@@ -199,6 +245,67 @@ func (r *JobRepository) GetRunningJobs(ctx context.Context) ([]*entity.Generatio
 	return jobs, nil
 }
 
+// jobStatusColumns 任务状态摘要查询的列（避免加载 input_params / output_result 等大字段）
+var jobStatusColumns = []string{
+	"id", "tenant_id", "project_id", "chapter_id", "job_type", "category", "status",
+	"progress", "error_code", "created_at", "updated_at", "started_at", "completed_at",
+}
+
+// ListStatusByIDs 批量获取任务状态摘要
+func (r *JobRepository) ListStatusByIDs(ctx context.Context, ids []string) ([]*entity.GenerationJob, error) {
+	ctx, span := tracer.Start(ctx, "postgres.JobRepository.ListStatusByIDs")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	db := getDB(ctx, r.client.db)
+	var jobs []*entity.GenerationJob
+	if err := db.Select(jobStatusColumns).Where("id IN ?", ids).Find(&jobs).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list job statuses: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetActiveByChapter 获取章节上正在排队或执行中的生成任务
+func (r *JobRepository) GetActiveByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error) {
+	ctx, span := tracer.Start(ctx, "postgres.JobRepository.GetActiveByChapter")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var job entity.GenerationJob
+	if err := db.Where("chapter_id = ? AND status IN ?", chapterID, []entity.JobStatus{entity.JobStatusPending, entity.JobStatusRunning}).
+		Order("created_at DESC").
+		First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get active job by chapter: %w", err)
+	}
+	return &job, nil
+}
+
+// GetLatestByChapter 获取章节最近创建的章节生成任务（不限状态）
+func (r *JobRepository) GetLatestByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error) {
+	ctx, span := tracer.Start(ctx, "postgres.JobRepository.GetLatestByChapter")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var job entity.GenerationJob
+	if err := db.Where("chapter_id = ? AND job_type = ?", chapterID, entity.JobTypeChapterGen).
+		Order("created_at DESC").
+		First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get latest job by chapter: %w", err)
+	}
+	return &job, nil
+}
+
 // GetFailedJobs 获取失败任务（可重试）
 func (r *JobRepository) GetFailedJobs(ctx context.Context, maxRetries int, limit int) ([]*entity.GenerationJob, error) {
 	ctx, span := tracer.Start(ctx, "postgres.JobRepository.GetFailedJobs")
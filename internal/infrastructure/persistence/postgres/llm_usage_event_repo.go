@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+
+	"gorm.io/gorm"
 )
 
 type LLMUsageEventRepository struct {
@@ -46,3 +49,94 @@ func (r *LLMUsageEventRepository) GetTokenUsage(ctx context.Context, tenantID st
 	return total, nil
 }
 
+// List 按条件分页查询租户用量流水（按时间倒序）
+func (r *LLMUsageEventRepository) List(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.LLMUsageEvent], error) {
+	ctx, span := tracer.Start(ctx, "postgres.LLMUsageEventRepository.List")
+	defer span.End()
+
+	query := r.filtered(ctx, tenantID, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count llm usage events: %w", err)
+	}
+
+	var events []*entity.LLMUsageEvent
+	if err := query.Order("created_at DESC, id DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Limit()).
+		Find(&events).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list llm usage events: %w", err)
+	}
+
+	return repository.NewPagedResult(events, total, pagination), nil
+}
+
+// ListAfter 按 (created_at, id) 升序游标读取用量流水
+func (r *LLMUsageEventRepository) ListAfter(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, afterCreatedAt time.Time, afterID string, limit int) ([]*entity.LLMUsageEvent, error) {
+	ctx, span := tracer.Start(ctx, "postgres.LLMUsageEventRepository.ListAfter")
+	defer span.End()
+
+	query := r.filtered(ctx, tenantID, filter)
+	if afterID != "" {
+		query = query.Where("(created_at, id) > (?, ?)", afterCreatedAt, afterID)
+	}
+
+	var events []*entity.LLMUsageEvent
+	if err := query.Order("created_at ASC, id ASC").Limit(limit).Find(&events).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list llm usage events: %w", err)
+	}
+	return events, nil
+}
+
+// Summarize 汇总符合条件的用量流水
+func (r *LLMUsageEventRepository) Summarize(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter) (*repository.LLMUsageSummary, error) {
+	ctx, span := tracer.Start(ctx, "postgres.LLMUsageEventRepository.Summarize")
+	defer span.End()
+
+	var summary repository.LLMUsageSummary
+	if err := r.filtered(ctx, tenantID, filter).
+		Select("COUNT(*) AS events, " +
+			"COALESCE(SUM(tokens_prompt),0) AS tokens_prompt, " +
+			"COALESCE(SUM(tokens_completion),0) AS tokens_completion, " +
+			"COALESCE(SUM(duration_ms),0) AS duration_ms").
+		Scan(&summary).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to summarize llm usage events: %w", err)
+	}
+	return &summary, nil
+}
+
+func (r *LLMUsageEventRepository) filtered(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter) *gorm.DB {
+	query := getDB(ctx, r.client.db).Model(&entity.LLMUsageEvent{}).Where("tenant_id = ?", tenantID)
+	if filter == nil {
+		return query
+	}
+	if !filter.Start.IsZero() {
+		query = query.Where("created_at >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		query = query.Where("created_at < ?", filter.End)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.Workflow != "" {
+		query = query.Where("workflow = ?", filter.Workflow)
+	}
+	if filter.JobType != "" {
+		query = query.Where("job_type = ?", filter.JobType)
+	}
+	if filter.ProjectID != "" {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	return query
+}
+
+var _ repository.LLMUsageEventRepository = (*LLMUsageEventRepository)(nil)
@@ -0,0 +1,295 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"z-novel-ai-api/internal/config"
+)
+
+// migrationsTable 版本表（与 golang-migrate 的 schema_migrations 结构兼容，可与 migrate CLI 混用）
+const migrationsTable = "schema_migrations"
+
+// migrationLockID 迁移互斥的 advisory lock 键（多服务同时启动自动迁移时串行执行）
+const migrationLockID int64 = 7_461_206_318
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// ErrDirtyMigration 上次迁移中途失败，需人工修复后执行 force
+var ErrDirtyMigration = errors.New("database is in dirty migration state")
+
+// Migration 迁移文件
+type Migration struct {
+	Version uint64
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	// Version 当前版本（0 表示尚未执行任何迁移）
+	Version uint64
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator 基于嵌入 SQL 的迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator 创建迁移执行器；source 根目录为 <version>_<name>.{up,down}.sql 文件
+func NewMigrator(client *Client, source fs.FS) (*Migrator, error) {
+	db, err := client.SqlDB()
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Migrations 返回全部迁移（按版本升序）
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Status 获取当前版本与待执行迁移
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	status := &MigrationStatus{Version: version, Dirty: dirty}
+	for _, mig := range m.migrations {
+		if mig.Version <= version {
+			status.Applied = append(status.Applied, mig)
+		} else {
+			status.Pending = append(status.Pending, mig)
+		}
+	}
+	return status, nil
+}
+
+// Up 执行待执行迁移；limit > 0 时最多执行 limit 个。返回已执行的迁移
+func (m *Migrator) Up(ctx context.Context, limit int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirtyMigration, version)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= version {
+				continue
+			}
+			if limit > 0 && len(applied) >= limit {
+				break
+			}
+			if err := runMigration(ctx, conn, mig.Version, mig.up); err != nil {
+				return fmt.Errorf("migration %06d_%s up failed: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 回滚最近 steps 个迁移（steps <= 0 时按 1 处理）。返回已回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, dirty, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirtyMigration, version)
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if mig.Version > version {
+				continue
+			}
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if mig.down == "" {
+				return fmt.Errorf("migration %06d_%s has no down file", mig.Version, mig.Name)
+			}
+			if err := runMigration(ctx, conn, previous, mig.down); err != nil {
+				return fmt.Errorf("migration %06d_%s down failed: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+			version = previous
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force 强制设置版本并清除 dirty 标记（不执行 SQL，用于人工修复失败的迁移后）
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+// AutoMigrate 使用独立连接执行全部待执行迁移（用于开发环境启动时自动迁移）
+func AutoMigrate(ctx context.Context, cfg *config.PostgresConfig, source fs.FS) ([]Migration, error) {
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	migrator, err := NewMigrator(client, source)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Up(ctx, 0)
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID) //nolint:errcheck
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// runMigration 先标记 dirty，执行 SQL（多语句以简单协议执行，整体隐式事务），成功后写入目标版本
+func runMigration(ctx context.Context, conn *sql.Conn, target uint64, body string) error {
+	if err := setVersion(ctx, conn, target, true); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	return setVersion(ctx, conn, target, false)
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", migrationsTable, err)
+	}
+	return nil
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return uint64(version), dirty, nil
+}
+
+// setVersion 与 golang-migrate 一致：表内至多一行；版本 0 表示未迁移（不写入行）
+func setVersion(ctx context.Context, conn *sql.Conn, version uint64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "TRUNCATE "+migrationsTable); err != nil {
+		return fmt.Errorf("failed to reset migration version: %w", err)
+	}
+	if version > 0 || dirty {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+migrationsTable+" (version, dirty) VALUES ($1, $2)", int64(version), dirty); err != nil {
+			return fmt.Errorf("failed to set migration version: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func loadMigrations(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		body, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s / %s", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.up = string(body)
+		} else {
+			mig.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" {
+			return nil, fmt.Errorf("migration %06d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
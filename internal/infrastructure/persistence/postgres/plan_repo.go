@@ -0,0 +1,73 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// PlanRepository 订阅套餐仓储实现
+type PlanRepository struct {
+	client *Client
+}
+
+// NewPlanRepository 创建订阅套餐仓储
+func NewPlanRepository(client *Client) *PlanRepository {
+	return &PlanRepository{client: client}
+}
+
+// GetByID 根据 ID 获取套餐
+func (r *PlanRepository) GetByID(ctx context.Context, id string) (*entity.Plan, error) {
+	ctx, span := tracer.Start(ctx, "postgres.PlanRepository.GetByID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var plan entity.Plan
+	if err := db.First(&plan, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// GetByCode 根据编码获取套餐
+func (r *PlanRepository) GetByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	ctx, span := tracer.Start(ctx, "postgres.PlanRepository.GetByCode")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var plan entity.Plan
+	if err := db.First(&plan, "code = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get plan by code: %w", err)
+	}
+	return &plan, nil
+}
+
+// List 获取套餐列表
+func (r *PlanRepository) List(ctx context.Context, activeOnly bool) ([]*entity.Plan, error) {
+	ctx, span := tracer.Start(ctx, "postgres.PlanRepository.List")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	query := db.Model(&entity.Plan{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	var plans []*entity.Plan
+	if err := query.Order("monthly_tokens ASC, code ASC").Find(&plans).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	return plans, nil
+}
@@ -0,0 +1,53 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// projectLockNamespace 项目结构锁的 advisory lock 命名空间（两参数形式的第一个 key）
+const projectLockNamespace = 1001
+
+// ProjectLocker 基于 pg_advisory_xact_lock 的项目级结构锁
+type ProjectLocker struct {
+	client *Client
+}
+
+// NewProjectLocker 创建项目级结构锁
+func NewProjectLocker(client *Client) *ProjectLocker {
+	return &ProjectLocker{client: client}
+}
+
+// LockProject 获取项目排他锁（需在事务中调用，事务提交/回滚时释放）
+func (l *ProjectLocker) LockProject(ctx context.Context, projectID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.ProjectLocker.LockProject")
+	defer span.End()
+
+	if err := l.lock(ctx, "pg_advisory_xact_lock", projectID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to lock project: %w", err)
+	}
+	return nil
+}
+
+// LockProjectShared 获取项目共享锁（需在事务中调用，事务提交/回滚时释放）
+func (l *ProjectLocker) LockProjectShared(ctx context.Context, projectID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.ProjectLocker.LockProjectShared")
+	defer span.End()
+
+	if err := l.lock(ctx, "pg_advisory_xact_lock_shared", projectID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to lock project (shared): %w", err)
+	}
+	return nil
+}
+
+func (l *ProjectLocker) lock(ctx context.Context, fn, projectID string) error {
+	// 事务级锁在自动提交模式下会立即释放，必须在事务中获取才有意义
+	if GetTxFromContext(ctx) == nil {
+		return fmt.Errorf("project lock requires a transaction")
+	}
+	db := getDB(ctx, l.client.db)
+	return db.Exec(fmt.Sprintf("SELECT %s(%d, hashtext(?))", fn, projectLockNamespace), projectID).Error
+}
@@ -0,0 +1,46 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// ProjectNoteRepository 项目笔记仓储实现
+type ProjectNoteRepository struct {
+	client *Client
+}
+
+// NewProjectNoteRepository 创建项目笔记仓储
+func NewProjectNoteRepository(client *Client) *ProjectNoteRepository {
+	return &ProjectNoteRepository{client: client}
+}
+
+// Create 创建项目笔记
+func (r *ProjectNoteRepository) Create(ctx context.Context, note *entity.ProjectNote) error {
+	ctx, span := tracer.Start(ctx, "postgres.ProjectNoteRepository.Create")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Create(note).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create project note: %w", err)
+	}
+	return nil
+}
+
+// ListByProject 按导入顺序获取项目的全部笔记
+func (r *ProjectNoteRepository) ListByProject(ctx context.Context, projectID string) ([]*entity.ProjectNote, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ProjectNoteRepository.ListByProject")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var notes []*entity.ProjectNote
+	if err := db.Where("project_id = ?", projectID).Order("created_at ASC, id ASC").Find(&notes).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list project notes: %w", err)
+	}
+	return notes, nil
+}
@@ -0,0 +1,126 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/pkg/deadline"
+	"z-novel-ai-api/pkg/logger"
+	"z-novel-ai-api/pkg/metrics"
+)
+
+const (
+	queryStartKey     = "metrics:query_start"
+	unknownQueryLabel = "other"
+	maxLoggedSQLLen   = 512
+)
+
+type queryLabelKey struct{}
+
+// queryLabel 查询归属的仓储与方法
+type queryLabel struct {
+	repository string
+	method     string
+}
+
+// labeledTracer 在创建 span 的同时把 "postgres.<Repository>.<Method>" 写入 context，
+// 使 getDB 派生的查询无需逐个改造即可按仓储/方法打点。
+type labeledTracer struct {
+	trace.Tracer
+}
+
+// Start 创建 span 并附带查询标签
+func (t labeledTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if label, ok := parseQueryLabel(spanName); ok {
+		ctx = context.WithValue(ctx, queryLabelKey{}, label)
+	}
+	return t.Tracer.Start(ctx, spanName, opts...)
+}
+
+func parseQueryLabel(spanName string) (queryLabel, bool) {
+	parts := strings.Split(spanName, ".")
+	if len(parts) != 3 || parts[0] != "postgres" {
+		return queryLabel{}, false
+	}
+	return queryLabel{repository: parts[1], method: parts[2]}, true
+}
+
+func queryLabelFromContext(ctx context.Context) queryLabel {
+	if ctx != nil {
+		if label, ok := ctx.Value(queryLabelKey{}).(queryLabel); ok {
+			return label
+		}
+	}
+	return queryLabel{repository: unknownQueryLabel, method: unknownQueryLabel}
+}
+
+// registerQueryMetrics 注册 GORM 回调：记录查询耗时直方图，在超过阈值时输出慢查询日志，并向请求截止时间记录器上报超时
+func registerQueryMetrics(db *gorm.DB, slowThreshold time.Duration) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", beforeQuery),
+		cb.Create().After("gorm:create").Register("metrics:after_create", afterQuery("create", slowThreshold)),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", beforeQuery),
+		cb.Query().After("gorm:query").Register("metrics:after_query", afterQuery("query", slowThreshold)),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", beforeQuery),
+		cb.Update().After("gorm:update").Register("metrics:after_update", afterQuery("update", slowThreshold)),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", beforeQuery),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", afterQuery("delete", slowThreshold)),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", beforeQuery),
+		cb.Row().After("gorm:row").Register("metrics:after_row", afterQuery("row", slowThreshold)),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", beforeQuery),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", afterQuery("raw", slowThreshold)),
+	)
+}
+
+func beforeQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func afterQuery(operation string, slowThreshold time.Duration) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(start)
+
+		ctx := db.Statement.Context
+		deadline.Observe(ctx, deadline.Database, db.Error)
+		label := queryLabelFromContext(ctx)
+		metrics.DBQueryDuration.WithLabelValues(label.repository, label.method, operation).Observe(elapsed.Seconds())
+
+		if slowThreshold <= 0 || elapsed < slowThreshold {
+			return
+		}
+		metrics.DBSlowQueriesTotal.WithLabelValues(label.repository, label.method).Inc()
+
+		sql := db.Statement.SQL.String()
+		if len(sql) > maxLoggedSQLLen {
+			sql = sql[:maxLoggedSQLLen] + "..."
+		}
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		logger.Warn(ctx, "slow database query",
+			"repository", label.repository,
+			"method", label.method,
+			"operation", operation,
+			"table", db.Statement.Table,
+			"duration_ms", elapsed.Milliseconds(),
+			"threshold_ms", slowThreshold.Milliseconds(),
+			"rows", db.Statement.RowsAffected,
+			"sql", sql,
+		)
+	}
+}
@@ -0,0 +1,132 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// QuotaReservationRepository Token 配额预留仓储实现
+type QuotaReservationRepository struct {
+	client *Client
+}
+
+// NewQuotaReservationRepository 创建 Token 配额预留仓储
+func NewQuotaReservationRepository(client *Client) *QuotaReservationRepository {
+	return &QuotaReservationRepository{client: client}
+}
+
+// Upsert 创建预留；同一任务已存在记录时重置为新的预留
+func (r *QuotaReservationRepository) Upsert(ctx context.Context, reservation *entity.QuotaReservation) error {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.Upsert")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"amount", "settled_tokens", "status", "expires_at", "updated_at"}),
+	}).Create(reservation).Error
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to upsert quota reservation: %w", err)
+	}
+	return nil
+}
+
+// GetByJobID 获取任务的预留记录
+func (r *QuotaReservationRepository) GetByJobID(ctx context.Context, jobID string) (*entity.QuotaReservation, error) {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.GetByJobID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var reservation entity.QuotaReservation
+	if err := db.First(&reservation, "job_id = ?", jobID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get quota reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+// SumActive 汇总租户有效预留
+func (r *QuotaReservationRepository) SumActive(ctx context.Context, tenantID, excludeJobID string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.SumActive")
+	defer span.End()
+
+	var total int64
+	if err := r.activeQuery(ctx, tenantID, excludeJobID).Select("COALESCE(SUM(amount), 0)").Scan(&total).Error; err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to sum quota reservations: %w", err)
+	}
+	return total, nil
+}
+
+// CountActive 统计租户有效预留数量
+func (r *QuotaReservationRepository) CountActive(ctx context.Context, tenantID, excludeJobID string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.CountActive")
+	defer span.End()
+
+	var count int64
+	if err := r.activeQuery(ctx, tenantID, excludeJobID).Count(&count).Error; err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to count quota reservations: %w", err)
+	}
+	return count, nil
+}
+
+// activeQuery 有效预留：status = reserved 且未过期
+func (r *QuotaReservationRepository) activeQuery(ctx context.Context, tenantID, excludeJobID string) *gorm.DB {
+	query := getDB(ctx, r.client.db).Model(&entity.QuotaReservation{}).
+		Where("tenant_id = ? AND status = ? AND expires_at > ?", tenantID, entity.QuotaReservationReserved, time.Now())
+	if excludeJobID != "" {
+		query = query.Where("job_id <> ?", excludeJobID)
+	}
+	return query
+}
+
+// Consume 按实际用量收缩仍处于 reserved 的预留（不低于 0）
+func (r *QuotaReservationRepository) Consume(ctx context.Context, jobID string, tokens int64) error {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.Consume")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	err := db.Model(&entity.QuotaReservation{}).
+		Where("job_id = ? AND status = ?", jobID, entity.QuotaReservationReserved).
+		Updates(map[string]any{
+			"amount":     gorm.Expr("GREATEST(amount - ?, 0)", tokens),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to consume quota reservation: %w", err)
+	}
+	return nil
+}
+
+// Finish 将仍处于 reserved 的预留置为终态
+func (r *QuotaReservationRepository) Finish(ctx context.Context, jobID string, status entity.QuotaReservationStatus, settledTokens int64) (bool, error) {
+	ctx, span := tracer.Start(ctx, "postgres.QuotaReservationRepository.Finish")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	result := db.Model(&entity.QuotaReservation{}).
+		Where("job_id = ? AND status = ?", jobID, entity.QuotaReservationReserved).
+		Updates(map[string]any{
+			"status":         status,
+			"settled_tokens": settledTokens,
+			"updated_at":     time.Now(),
+		})
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		return false, fmt.Errorf("failed to finish quota reservation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -216,6 +216,21 @@ func (r *RelationRepository) UpdateStrength(ctx context.Context, id string, stre
 	return nil
 }
 
+// UpdateReinforcement 写回章节强化结果（仅强度与出现/强化章节列）
+func (r *RelationRepository) UpdateReinforcement(ctx context.Context, relation *entity.Relation) error {
+	ctx, span := tracer.Start(ctx, "postgres.RelationRepository.UpdateReinforcement")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Relation{ID: relation.ID}).
+		Select("strength", "first_chapter_id", "last_chapter_id", "last_reinforced_chapter_id", "updated_at").
+		Updates(relation).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update relation reinforcement: %w", err)
+	}
+	return nil
+}
+
 // DeleteByEntity 删除实体相关的所有关系
 func (r *RelationRepository) DeleteByEntity(ctx context.Context, entityID string) error {
 	ctx, span := tracer.Start(ctx, "postgres.RelationRepository.DeleteByEntity")
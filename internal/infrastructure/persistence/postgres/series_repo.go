@@ -0,0 +1,157 @@
+// Package postgres 提供 PostgreSQL Repository 实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// SeriesRepository 系列仓储实现
+type SeriesRepository struct {
+	client *Client
+}
+
+// NewSeriesRepository 创建系列仓储
+func NewSeriesRepository(client *Client) *SeriesRepository {
+	return &SeriesRepository{client: client}
+}
+
+// Create 创建系列
+func (r *SeriesRepository) Create(ctx context.Context, series *entity.Series) error {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.Create")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Create(series).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create series: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取系列
+func (r *SeriesRepository) GetByID(ctx context.Context, id string) (*entity.Series, error) {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.GetByID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var series entity.Series
+	if err := db.First(&series, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get series: %w", err)
+	}
+	return &series, nil
+}
+
+// Update 更新系列
+func (r *SeriesRepository) Update(ctx context.Context, series *entity.Series) error {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.Update")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Save(series).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除系列
+func (r *SeriesRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.Delete")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Model(&entity.Project{}).
+		Where("series_id = ?", id).
+		Updates(map[string]any{"series_id": nil, "series_order": 0}).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to detach series projects: %w", err)
+	}
+	if err := db.Delete(&entity.Series{}, "id = ?", id).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete series: %w", err)
+	}
+	return nil
+}
+
+// List 获取系列列表
+func (r *SeriesRepository) List(ctx context.Context, pagination repository.Pagination) (*repository.PagedResult[*entity.Series], error) {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.List")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	query := db.Model(&entity.Series{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to count series: %w", err)
+	}
+
+	var items []*entity.Series
+	if err := query.Order("updated_at DESC").
+		Offset(pagination.Offset()).
+		Limit(pagination.Limit()).
+		Find(&items).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list series: %w", err)
+	}
+
+	return repository.NewPagedResult(items, total, pagination), nil
+}
+
+// ListProjects 获取系列下的项目
+func (r *SeriesRepository) ListProjects(ctx context.Context, seriesID string) ([]*entity.Project, error) {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.ListProjects")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var projects []*entity.Project
+	if err := db.Where("series_id = ?", seriesID).
+		Order("series_order ASC, created_at ASC").
+		Find(&projects).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list series projects: %w", err)
+	}
+	return projects, nil
+}
+
+// GetProjectStats 获取系列下各项目的统计信息
+func (r *SeriesRepository) GetProjectStats(ctx context.Context, seriesID string) ([]*repository.SeriesProjectStats, error) {
+	ctx, span := tracer.Start(ctx, "postgres.SeriesRepository.GetProjectStats")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var stats []*repository.SeriesProjectStats
+
+	err := db.Raw(`
+		SELECT
+			p.id AS project_id,
+			p.title,
+			p.series_order,
+			p.status,
+			COALESCE((SELECT COUNT(*) FROM chapters c WHERE c.project_id = p.id), 0) AS total_chapters,
+			COALESCE((SELECT COUNT(*) FROM entities e WHERE e.project_id = p.id), 0) AS total_entities,
+			COALESCE((SELECT SUM(c.word_count) FROM chapters c WHERE c.project_id = p.id), 0) AS total_word_count
+		FROM projects p
+		WHERE p.series_id = ?
+		ORDER BY p.series_order ASC, p.created_at ASC
+	`, seriesID).Scan(&stats).Error
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get series project stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+var _ repository.SeriesRepository = (*SeriesRepository)(nil)
@@ -0,0 +1,78 @@
+// Package postgres 提供 PostgreSQL 数据库访问层实现
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// SpoilerGuardRepository 剧透保护仓储实现
+type SpoilerGuardRepository struct {
+	client *Client
+}
+
+// NewSpoilerGuardRepository 创建剧透保护仓储
+func NewSpoilerGuardRepository(client *Client) *SpoilerGuardRepository {
+	return &SpoilerGuardRepository{client: client}
+}
+
+// Create 创建剧透保护
+func (r *SpoilerGuardRepository) Create(ctx context.Context, guard *entity.SpoilerGuard) error {
+	ctx, span := tracer.Start(ctx, "postgres.SpoilerGuardRepository.Create")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Create(guard).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to create spoiler guard: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取剧透保护
+func (r *SpoilerGuardRepository) GetByID(ctx context.Context, id string) (*entity.SpoilerGuard, error) {
+	ctx, span := tracer.Start(ctx, "postgres.SpoilerGuardRepository.GetByID")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var guard entity.SpoilerGuard
+	if err := db.First(&guard, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get spoiler guard: %w", err)
+	}
+	return &guard, nil
+}
+
+// Delete 删除剧透保护
+func (r *SpoilerGuardRepository) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "postgres.SpoilerGuardRepository.Delete")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Delete(&entity.SpoilerGuard{}, "id = ?", id).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to delete spoiler guard: %w", err)
+	}
+	return nil
+}
+
+// ListByProject 按创建顺序获取项目的全部剧透保护
+func (r *SpoilerGuardRepository) ListByProject(ctx context.Context, projectID string) ([]*entity.SpoilerGuard, error) {
+	ctx, span := tracer.Start(ctx, "postgres.SpoilerGuardRepository.ListByProject")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var guards []*entity.SpoilerGuard
+	if err := db.Where("project_id = ?", projectID).Order("created_at ASC, id ASC").Find(&guards).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list spoiler guards: %w", err)
+	}
+	return guards, nil
+}
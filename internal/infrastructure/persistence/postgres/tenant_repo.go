@@ -4,8 +4,10 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
@@ -51,6 +53,23 @@ func (r *TenantRepository) GetByID(ctx context.Context, id string) (*entity.Tena
 	return &tenant, nil
 }
 
+// GetByIDForUpdate 根据 ID 获取租户并加行锁
+func (r *TenantRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.Tenant, error) {
+	ctx, span := tracer.Start(ctx, "postgres.TenantRepository.GetByIDForUpdate")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db).Clauses(clause.Locking{Strength: "UPDATE"})
+	var tenant entity.Tenant
+	if err := db.First(&tenant, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get tenant for update: %w", err)
+	}
+	return &tenant, nil
+}
+
 // GetBySlug 根据 Slug 获取租户
 func (r *TenantRepository) GetBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
 	ctx, span := tracer.Start(ctx, "postgres.TenantRepository.GetBySlug")
@@ -148,6 +167,24 @@ func (r *TenantRepository) ExistsBySlug(ctx context.Context, slug string) (bool,
 	return count > 0, nil
 }
 
+// ListQuotaResetDue 获取配额周期已到期的租户 ID
+func (r *TenantRepository) ListQuotaResetDue(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "postgres.TenantRepository.ListQuotaResetDue")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	var ids []string
+	if err := db.Model(&entity.Tenant{}).
+		Where("plan_id IS NOT NULL AND quota_period_end <= ? AND status <> ?", before, entity.TenantStatusDeleted).
+		Order("quota_period_end ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list tenants due for quota reset: %w", err)
+	}
+	return ids, nil
+}
+
 // DeductBalance 原子扣除租户余额
 func (r *TenantRepository) DeductBalance(ctx context.Context, id string, amount int64) error {
 	ctx, span := tracer.Start(ctx, "postgres.TenantRepository.DeductBalance")
@@ -169,3 +206,25 @@ func (r *TenantRepository) DeductBalance(ctx context.Context, id string, amount
 
 	return nil
 }
+
+// CreditBalance 原子增加租户余额
+func (r *TenantRepository) CreditBalance(ctx context.Context, id string, amount int64) error {
+	ctx, span := tracer.Start(ctx, "postgres.TenantRepository.CreditBalance")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	result := db.Model(&entity.Tenant{}).
+		Where("id = ?", id).
+		Update("token_balance", gorm.Expr("token_balance + ?", amount))
+
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		return fmt.Errorf("failed to credit balance: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tenant not found: %s", id)
+	}
+
+	return nil
+}
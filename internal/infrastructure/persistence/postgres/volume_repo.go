@@ -142,6 +142,19 @@ func (r *VolumeRepository) UpdateWordCount(ctx context.Context, id string, wordC
 	return nil
 }
 
+// RefreshRollups 调用 refresh_volume_rollup（迁移 000041）重新汇总项目全部卷
+func (r *VolumeRepository) RefreshRollups(ctx context.Context, projectID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.VolumeRepository.RefreshRollups")
+	defer span.End()
+
+	db := getDB(ctx, r.client.db)
+	if err := db.Exec("SELECT refresh_volume_rollup(id) FROM volumes WHERE project_id = ?", projectID).Error; err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to refresh volume rollups: %w", err)
+	}
+	return nil
+}
+
 // ReorderVolumes 重新排序卷
 func (r *VolumeRepository) ReorderVolumes(ctx context.Context, projectID string, volumeIDs []string) error {
 	ctx, span := tracer.Start(ctx, "postgres.VolumeRepository.ReorderVolumes")
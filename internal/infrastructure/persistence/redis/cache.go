@@ -164,6 +164,27 @@ func (c *Cache) GetOrLoadSafe(ctx context.Context, key string, ttl time.Duration
 	return result.([]byte), nil
 }
 
+// MGet 批量获取缓存值（一次往返；未命中的 Key 对应位置为 nil）
+func (c *Cache) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	ctx, span := cacheTracer.Start(ctx, "cache.MGet",
+		trace.WithAttributes(attribute.Int("cache.key_count", len(keys))))
+	defer span.End()
+
+	vals, err := c.client.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	out := make([][]byte, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			out[i] = []byte(s)
+		}
+	}
+	return out, nil
+}
+
 // SetWithDB Write-Through 缓存模式
 func (c *Cache) SetWithDB(ctx context.Context, key string, value interface{}, ttl time.Duration, dbWriter func() error) error {
 	ctx, span := cacheTracer.Start(ctx, "cache.SetWithDB",
@@ -33,7 +33,10 @@ func NewClient(cfg *config.RedisConfig) (*Client, error) {
 		DialTimeout:  cfg.DialTimeout,
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
+		// 请求 context 的截止时间优先于 Read/WriteTimeout，避免单条慢命令占满整个请求
+		ContextTimeoutEnabled: true,
 	})
+	rdb.AddHook(deadlineHook{})
 
 	// 验证连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
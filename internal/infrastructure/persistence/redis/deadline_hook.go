@@ -0,0 +1,38 @@
+// Package redis 提供 Redis 缓存和消息队列实现
+package redis
+
+import (
+	"context"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+
+	"z-novel-ai-api/pkg/deadline"
+)
+
+// deadlineHook 命令因请求截止时间超时时上报缓存依赖（需配合 Options.ContextTimeoutEnabled 使用）
+type deadlineHook struct{}
+
+func (deadlineHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		deadline.Observe(ctx, deadline.Cache, err)
+		return conn, err
+	}
+}
+
+func (deadlineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		deadline.Observe(ctx, deadline.Cache, err)
+		return err
+	}
+}
+
+func (deadlineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		deadline.Observe(ctx, deadline.Cache, err)
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 任务取消信号：键 job:cancel:{job_id} 记录取消请求（供开始监听较晚的执行方回查），同名频道通知正在监听的执行方
+const jobCancelKeyPrefix = "job:cancel:"
+
+// jobCancelTTL 取消请求的保留时间（覆盖任务排队与重试退避的最长周期）
+const jobCancelTTL = 24 * time.Hour
+
+// JobCancelSignal 基于 Redis 键与 Pub/Sub 的任务取消信号
+type JobCancelSignal struct {
+	client *Client
+}
+
+// NewJobCancelSignal 创建任务取消信号
+func NewJobCancelSignal(client *Client) *JobCancelSignal {
+	return &JobCancelSignal{client: client}
+}
+
+// Request 记录任务的取消请求并通知正在监听的执行方
+func (s *JobCancelSignal) Request(ctx context.Context, jobID string) error {
+	key := jobCancelKey(jobID)
+	if err := s.client.rdb.Set(ctx, key, "1", jobCancelTTL).Err(); err != nil {
+		return fmt.Errorf("failed to record job cancel request: %w", err)
+	}
+	return s.client.rdb.Publish(ctx, key, jobID).Err()
+}
+
+// Watch 监听任务取消：先订阅再回查取消记录，订阅生效前发出的请求不会遗漏
+func (s *JobCancelSignal) Watch(ctx context.Context, jobID string) (<-chan struct{}, func(), error) {
+	key := jobCancelKey(jobID)
+	ps := s.client.rdb.Subscribe(ctx, key)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, nil, fmt.Errorf("failed to watch job cancellation: %w", err)
+	}
+
+	out := make(chan struct{})
+	var once sync.Once
+	fire := func() { once.Do(func() { close(out) }) }
+
+	requested, err := s.client.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		_ = ps.Close()
+		return nil, nil, fmt.Errorf("failed to check job cancel request: %w", err)
+	}
+	if requested > 0 {
+		fire()
+	}
+	go func() {
+		for range ps.Channel() {
+			fire()
+		}
+	}()
+	return out, func() { _ = ps.Close() }, nil
+}
+
+func jobCancelKey(jobID string) string {
+	return jobCancelKeyPrefix + jobID
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/pkg/logger"
+)
+
+// 任务实时进度 Pub/Sub 频道：job:progress:{job_id}；项目频道 project:events:{tenant_id}:{project_id} 汇集项目下全部任务的事件
+const (
+	jobProgressChannelPrefix   = "job:progress:"
+	projectEventsChannelPrefix = "project:events:"
+)
+
+// jobProgressSubscriberBuffer 订阅端缓冲的事件数（SSE 写出慢于发布时暂存）
+const jobProgressSubscriberBuffer = 64
+
+// JobProgressBus 基于 Redis Pub/Sub 的任务实时进度广播
+type JobProgressBus struct {
+	client *Client
+}
+
+// NewJobProgressBus 创建任务进度广播
+func NewJobProgressBus(client *Client) *JobProgressBus {
+	return &JobProgressBus{client: client}
+}
+
+// Publish 发布任务进度事件（事件带 ProjectID 时同时发布到项目频道）
+func (b *JobProgressBus) Publish(ctx context.Context, ev *appstory.JobProgressEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job progress: %w", err)
+	}
+	if ev.JobID != "" {
+		if err := b.client.rdb.Publish(ctx, jobProgressChannel(ev.JobID), data).Err(); err != nil {
+			return err
+		}
+	}
+	if ev.ProjectID == "" {
+		return nil
+	}
+	return b.client.rdb.Publish(ctx, projectEventsChannel(ev.TenantID, ev.ProjectID), data).Err()
+}
+
+// Subscribe 订阅任务进度事件；确认订阅生效后才返回，调用方随后读取的任务状态与后续事件之间不会遗漏
+func (b *JobProgressBus) Subscribe(ctx context.Context, jobID string) (<-chan *appstory.JobProgressEvent, func(), error) {
+	return b.subscribe(ctx, jobProgressChannel(jobID))
+}
+
+// SubscribeProject 订阅项目下全部任务的事件（含冲突提醒）
+func (b *JobProgressBus) SubscribeProject(ctx context.Context, tenantID, projectID string) (<-chan *appstory.JobProgressEvent, func(), error) {
+	return b.subscribe(ctx, projectEventsChannel(tenantID, projectID))
+}
+
+func (b *JobProgressBus) subscribe(ctx context.Context, channel string) (<-chan *appstory.JobProgressEvent, func(), error) {
+	ps := b.client.rdb.Subscribe(ctx, channel)
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, nil, fmt.Errorf("failed to subscribe job progress: %w", err)
+	}
+
+	out := make(chan *appstory.JobProgressEvent, jobProgressSubscriberBuffer)
+	go func() {
+		defer close(out)
+		for msg := range ps.Channel() {
+			var ev appstory.JobProgressEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				logger.Warn(ctx, "invalid job progress payload", "error", err.Error(), "channel", channel)
+				continue
+			}
+			select {
+			case out <- &ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, func() { _ = ps.Close() }, nil
+}
+
+func jobProgressChannel(jobID string) string {
+	return jobProgressChannelPrefix + jobID
+}
+
+func projectEventsChannel(tenantID, projectID string) string {
+	return projectEventsChannelPrefix + tenantID + ":" + projectID
+}
@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	appstory "z-novel-ai-api/internal/application/story"
+)
+
+// 章节重建索引队列 Key：ZSET（成员 tenant/project/chapter，分数为到期时间毫秒）与首次入队时间 Hash；
+// Hash Tag 保证两个 Key 落在同一 slot
+const (
+	reindexDueKey   = "reindex:{chapters}:due"
+	reindexFirstKey = "reindex:{chapters}:first"
+)
+
+// scheduleReindexScript 入队或推迟：到期时间为 now+debounce，但不晚于首次入队 + maxDelay。
+// KEYS[1] ZSET KEYS[2] Hash；ARGV[1] 成员 ARGV[2] now(ms) ARGV[3] debounce(ms) ARGV[4] maxDelay(ms，<=0 不封顶)
+var scheduleReindexScript = redis.NewScript(`
+local first = redis.call('HGET', KEYS[2], ARGV[1])
+if not first then
+	first = ARGV[2]
+	redis.call('HSET', KEYS[2], ARGV[1], first)
+end
+local due = tonumber(ARGV[2]) + tonumber(ARGV[3])
+local maxDelay = tonumber(ARGV[4])
+if maxDelay > 0 and due > tonumber(first) + maxDelay then
+	due = tonumber(first) + maxDelay
+end
+redis.call('ZADD', KEYS[1], due, ARGV[1])
+return 1
+`)
+
+// claimDueReindexScript 领取到期成员并移出队列（多 Worker 并发领取互不重复）。ARGV[1] now(ms) ARGV[2] 数量上限
+var claimDueReindexScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, m in ipairs(due) do
+	redis.call('ZREM', KEYS[1], m)
+	redis.call('HDEL', KEYS[2], m)
+end
+return due
+`)
+
+// ReindexQueue 基于 Redis 的章节重建索引队列
+type ReindexQueue struct {
+	client *Client
+}
+
+// NewReindexQueue 创建章节重建索引队列
+func NewReindexQueue(client *Client) *ReindexQueue {
+	return &ReindexQueue{client: client}
+}
+
+// Schedule 入队（已在队列中时推迟到期时间）
+func (q *ReindexQueue) Schedule(ctx context.Context, req appstory.ReindexRequest, now time.Time, debounce, maxDelay time.Duration) error {
+	ctx, span := tracer.Start(ctx, "reindex.Schedule")
+	defer span.End()
+
+	member := strings.Join([]string{req.TenantID, req.ProjectID, req.ChapterID}, "/")
+	if err := scheduleReindexScript.Run(ctx, q.client.rdb, []string{reindexDueKey, reindexFirstKey},
+		member, now.UnixMilli(), debounce.Milliseconds(), maxDelay.Milliseconds()).Err(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to schedule reindex: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue 领取到期请求
+func (q *ReindexQueue) ClaimDue(ctx context.Context, now time.Time, limit int) ([]appstory.ReindexRequest, error) {
+	ctx, span := tracer.Start(ctx, "reindex.ClaimDue")
+	defer span.End()
+
+	members, err := claimDueReindexScript.Run(ctx, q.client.rdb, []string{reindexDueKey, reindexFirstKey},
+		now.UnixMilli(), limit).StringSlice()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to claim reindex requests: %w", err)
+	}
+
+	out := make([]appstory.ReindexRequest, 0, len(members))
+	for _, m := range members {
+		parts := strings.Split(m, "/")
+		if len(parts) != 3 {
+			continue
+		}
+		out = append(out, appstory.ReindexRequest{TenantID: parts[0], ProjectID: parts[1], ChapterID: parts[2]})
+	}
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"z-novel-ai-api/internal/application/retrieval"
+)
+
+// 向量用量 Key：租户汇总 Hash（字段 segments / text_bytes / {pid}:segments / {pid}:text_bytes）
+// 与项目文档 Hash（字段 {segment_type}/{doc_id} → "片段数:字节数"）；Hash Tag 保证同一租户的 Key 落在同一 slot
+const vectorUsageKeyPrefix = "vector:usage:"
+
+// replaceDocUsageScript 原子地替换文档用量并检查配额（仅在片段数增加时检查，缩减总是放行）。
+// KEYS[1] 租户 Hash KEYS[2] 项目文档 Hash；ARGV[1] 项目 ID ARGV[2] 文档 Key ARGV[3] 片段数 ARGV[4] 字节数
+// ARGV[5] 租户上限 ARGV[6] 项目上限。返回 {状态, 超限时的已用量, 原片段数, 原字节数}，状态 1 成功、-1 租户超限、-2 项目超限
+var replaceDocUsageScript = redis.NewScript(`
+local oldSeg, oldBytes = 0, 0
+local old = redis.call('HGET', KEYS[2], ARGV[2])
+if old then
+	local sep = string.find(old, ':', 1, true)
+	oldSeg = tonumber(string.sub(old, 1, sep - 1))
+	oldBytes = tonumber(string.sub(old, sep + 1))
+end
+local seg, bytes = tonumber(ARGV[3]), tonumber(ARGV[4])
+local delta = seg - oldSeg
+if delta > 0 then
+	local maxTenant, maxProject = tonumber(ARGV[5]), tonumber(ARGV[6])
+	local tenantSeg = tonumber(redis.call('HGET', KEYS[1], 'segments') or '0')
+	if maxTenant > 0 and tenantSeg + delta > maxTenant then
+		return {-1, tenantSeg, oldSeg, oldBytes}
+	end
+	local projectSeg = tonumber(redis.call('HGET', KEYS[1], ARGV[1] .. ':segments') or '0')
+	if maxProject > 0 and projectSeg + delta > maxProject then
+		return {-2, projectSeg, oldSeg, oldBytes}
+	end
+end
+if seg > 0 then
+	redis.call('HSET', KEYS[2], ARGV[2], seg .. ':' .. bytes)
+else
+	redis.call('HDEL', KEYS[2], ARGV[2])
+end
+redis.call('HINCRBY', KEYS[1], 'segments', delta)
+redis.call('HINCRBY', KEYS[1], 'text_bytes', bytes - oldBytes)
+redis.call('HINCRBY', KEYS[1], ARGV[1] .. ':segments', delta)
+redis.call('HINCRBY', KEYS[1], ARGV[1] .. ':text_bytes', bytes - oldBytes)
+return {1, 0, oldSeg, oldBytes}
+`)
+
+// purgeProjectUsageScript 清空项目用量并从租户汇总中扣除。KEYS 同上；ARGV[1] 项目 ID
+var purgeProjectUsageScript = redis.NewScript(`
+local seg = tonumber(redis.call('HGET', KEYS[1], ARGV[1] .. ':segments') or '0')
+local bytes = tonumber(redis.call('HGET', KEYS[1], ARGV[1] .. ':text_bytes') or '0')
+redis.call('HINCRBY', KEYS[1], 'segments', -seg)
+redis.call('HINCRBY', KEYS[1], 'text_bytes', -bytes)
+redis.call('HDEL', KEYS[1], ARGV[1] .. ':segments', ARGV[1] .. ':text_bytes')
+redis.call('DEL', KEYS[2])
+return 1
+`)
+
+// VectorUsageCounter 基于 Redis 的向量用量计数器
+type VectorUsageCounter struct {
+	client *Client
+}
+
+// NewVectorUsageCounter 创建向量用量计数器
+func NewVectorUsageCounter(client *Client) *VectorUsageCounter {
+	return &VectorUsageCounter{client: client}
+}
+
+// ReplaceDoc 替换文档用量并检查配额
+func (c *VectorUsageCounter) ReplaceDoc(ctx context.Context, tenantID, projectID, docKey string, usage retrieval.VectorUsage, limits retrieval.QuotaLimits) (retrieval.VectorUsage, error) {
+	ctx, span := tracer.Start(ctx, "vectorusage.ReplaceDoc")
+	span.SetAttributes(
+		attribute.String("tenant.id", tenantID),
+		attribute.String("project.id", projectID),
+		attribute.Int64("vectorusage.segments", usage.Segments),
+	)
+	defer span.End()
+
+	res, err := replaceDocUsageScript.Run(ctx, c.client.rdb, vectorUsageKeys(tenantID, projectID),
+		projectID, docKey, usage.Segments, usage.TextBytes, limits.MaxSegmentsPerTenant, limits.MaxSegmentsPerProject).Int64Slice()
+	if err != nil {
+		span.RecordError(err)
+		return retrieval.VectorUsage{}, fmt.Errorf("failed to update vector usage: %w", err)
+	}
+	if len(res) != 4 {
+		return retrieval.VectorUsage{}, fmt.Errorf("unexpected vector usage script result: %v", res)
+	}
+
+	prev := retrieval.VectorUsage{Segments: res[2], TextBytes: res[3]}
+	switch res[0] {
+	case -1:
+		return prev, &retrieval.QuotaExceededError{Scope: retrieval.QuotaScopeTenant, Limit: limits.MaxSegmentsPerTenant, Used: res[1], Requested: usage.Segments - prev.Segments}
+	case -2:
+		return prev, &retrieval.QuotaExceededError{Scope: retrieval.QuotaScopeProject, Limit: limits.MaxSegmentsPerProject, Used: res[1], Requested: usage.Segments - prev.Segments}
+	}
+	return prev, nil
+}
+
+// PurgeProject 清空项目用量
+func (c *VectorUsageCounter) PurgeProject(ctx context.Context, tenantID, projectID string) error {
+	ctx, span := tracer.Start(ctx, "vectorusage.PurgeProject")
+	defer span.End()
+
+	if err := purgeProjectUsageScript.Run(ctx, c.client.rdb, vectorUsageKeys(tenantID, projectID), projectID).Err(); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to purge vector usage: %w", err)
+	}
+	return nil
+}
+
+// TenantUsage 租户下各项目的用量
+func (c *VectorUsageCounter) TenantUsage(ctx context.Context, tenantID string) (map[string]retrieval.VectorUsage, error) {
+	ctx, span := tracer.Start(ctx, "vectorusage.TenantUsage")
+	defer span.End()
+
+	fields, err := c.client.rdb.HGetAll(ctx, vectorUsageKeys(tenantID, "")[0]).Result()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to load vector usage: %w", err)
+	}
+
+	out := make(map[string]retrieval.VectorUsage)
+	for field, raw := range fields {
+		projectID, metric, ok := strings.Cut(field, ":")
+		if !ok {
+			continue // 租户汇总字段
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		u := out[projectID]
+		switch metric {
+		case "segments":
+			u.Segments = v
+		case "text_bytes":
+			u.TextBytes = v
+		}
+		out[projectID] = u
+	}
+	return out, nil
+}
+
+// vectorUsageKeys 返回 [租户 Hash, 项目文档 Hash]
+func vectorUsageKeys(tenantID, projectID string) []string {
+	tenantKey := vectorUsageKeyPrefix + "{" + tenantID + "}"
+	return []string{tenantKey, tenantKey + ":" + projectID}
+}
@@ -0,0 +1,210 @@
+// Package preflight 提供启动前的依赖连通性与一致性检查（配置校验命令与严格启动模式共用）
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/config"
+	infraembedding "z-novel-ai-api/internal/infrastructure/embedding"
+	"z-novel-ai-api/internal/infrastructure/persistence/milvus"
+	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
+	"z-novel-ai-api/internal/infrastructure/persistence/redis"
+)
+
+// defaultProbeTimeout 单项检查超时
+const defaultProbeTimeout = 10 * time.Second
+
+// Status 检查结果状态
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Check 单项检查结果
+type Check struct {
+	Name     string
+	Status   Status
+	Detail   string
+	Duration time.Duration
+}
+
+// String 格式化为单行报告
+func (c Check) String() string {
+	line := fmt.Sprintf("[%s] %s (%s)", strings.ToUpper(string(c.Status)), c.Name, c.Duration.Round(time.Millisecond))
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	return line
+}
+
+// Options 检查选项
+type Options struct {
+	// Timeout 单项检查超时（默认 10s）
+	Timeout time.Duration
+	// SkipLLM 跳过 LLM 提供商连通性检查（避免在 CI 中访问外部服务）
+	SkipLLM bool
+}
+
+// Failed 是否存在失败项
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 依次检查 PostgreSQL、Redis、Milvus、Embedding 与各 LLM 提供商，并核对 Embedding 维度与 Milvus 集合维度
+func Run(ctx context.Context, cfg *config.Config, opts Options) []Check {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultProbeTimeout
+	}
+
+	checks := []Check{
+		probe(ctx, "postgres", opts.Timeout, func(ctx context.Context) (Status, string) {
+			return checkPostgres(ctx, cfg)
+		}),
+		probe(ctx, "redis", opts.Timeout, func(ctx context.Context) (Status, string) {
+			return checkRedis(cfg)
+		}),
+	}
+
+	checks = append(checks, checkDimensionConfig(cfg))
+	checks = append(checks, probe(ctx, "milvus", opts.Timeout, func(ctx context.Context) (Status, string) {
+		return checkMilvus(ctx, cfg)
+	}))
+	checks = append(checks, probe(ctx, "embedding", opts.Timeout, func(ctx context.Context) (Status, string) {
+		return checkEmbedding(ctx, cfg)
+	}))
+
+	if !opts.SkipLLM {
+		client := &http.Client{Timeout: opts.Timeout}
+		for _, name := range cfg.ProviderNames() {
+			provider := cfg.LLM.Providers[name]
+			checks = append(checks, probe(ctx, "llm."+name, opts.Timeout, func(ctx context.Context) (Status, string) {
+				return checkLLMProvider(ctx, client, provider)
+			}))
+		}
+	}
+	return checks
+}
+
+func probe(ctx context.Context, name string, timeout time.Duration, fn func(ctx context.Context) (Status, string)) Check {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	status, detail := fn(ctx)
+	return Check{Name: name, Status: status, Detail: detail, Duration: time.Since(start)}
+}
+
+func checkPostgres(ctx context.Context, cfg *config.Config) (Status, string) {
+	client, err := postgres.NewClient(&cfg.Database.Postgres)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer client.Close()
+	if err := client.Ping(ctx); err != nil {
+		return StatusFail, err.Error()
+	}
+	return StatusOK, ""
+}
+
+func checkRedis(cfg *config.Config) (Status, string) {
+	client, err := redis.NewClient(&cfg.Cache.Redis)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer client.Close()
+	return StatusOK, ""
+}
+
+// checkDimensionConfig 核对 embedding.dimension 与 Milvus 集合 Schema 中固定的向量维度
+func checkDimensionConfig(cfg *config.Config) Check {
+	c := Check{Name: "embedding.dimension", Status: StatusOK}
+	if cfg.Embedding.Dimension != milvus.VectorDimension {
+		c.Status = StatusFail
+		c.Detail = fmt.Sprintf("configured %d but milvus schema uses %d", cfg.Embedding.Dimension, milvus.VectorDimension)
+	}
+	return c
+}
+
+func checkMilvus(ctx context.Context, cfg *config.Config) (Status, string) {
+	client, err := milvus.NewClient(ctx, &cfg.Vector.Milvus)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer client.Close()
+
+	dim, err := client.VectorDimension(ctx, milvus.CollectionStorySegments)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if dim == 0 {
+		return StatusWarn, fmt.Sprintf("collection %s not created yet", client.CollectionName(milvus.CollectionStorySegments))
+	}
+	if dim != cfg.Embedding.Dimension {
+		return StatusFail, fmt.Sprintf("collection %s dim %d does not match embedding.dimension %d",
+			client.CollectionName(milvus.CollectionStorySegments), dim, cfg.Embedding.Dimension)
+	}
+	return StatusOK, fmt.Sprintf("dim %d", dim)
+}
+
+// checkEmbedding 实际请求一次 Embedding，核对返回向量维度
+func checkEmbedding(ctx context.Context, cfg *config.Config) (Status, string) {
+	if cfg.Embedding.Endpoint == "" && cfg.Embedding.Provider != infraembedding.ProviderFake {
+		return StatusWarn, "endpoint not set, vector features disabled"
+	}
+	embedder, err := infraembedding.NewEinoEmbedder(ctx, &cfg.Embedding)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	vectors, err := embedder.EmbedStrings(ctx, []string{"preflight"})
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if len(vectors) == 0 {
+		return StatusFail, "empty embedding response"
+	}
+	if got := len(vectors[0]); got != cfg.Embedding.Dimension {
+		return StatusFail, fmt.Sprintf("model returned dim %d but embedding.dimension is %d", got, cfg.Embedding.Dimension)
+	}
+	return StatusOK, fmt.Sprintf("dim %d", len(vectors[0]))
+}
+
+// checkLLMProvider 请求 OpenAI 兼容的 GET /models 校验地址与 API Key（不产生 Token 消耗）
+func checkLLMProvider(ctx context.Context, client *http.Client, provider config.ProviderConfig) (Status, string) {
+	if provider.Type == config.ProviderTypeMock {
+		return StatusOK, "mock provider (no external calls)"
+	}
+	endpoint := strings.TrimRight(provider.BaseURL, "/") + "/models"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	req.Header.Set("Authorization", "Bearer "+provider.APIKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return StatusOK, ""
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return StatusFail, fmt.Sprintf("api key rejected (HTTP %d)", resp.StatusCode)
+	default:
+		// 部分兼容服务未实现 /models，地址可达即视为可用
+		return StatusWarn, fmt.Sprintf("reachable but %s returned HTTP %d", endpoint, resp.StatusCode)
+	}
+}
@@ -0,0 +1,97 @@
+// Package rerank 提供检索精排的外部 rerank 接口调用实现
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBytes 精排响应体读取上限
+const maxResponseBytes = 1 << 20
+
+// APIReranker 调用 Cohere / Jina / BGE 兼容的 rerank 接口：
+// POST {endpoint}/rerank，请求 {model, query, documents, top_n}，响应 {results: [{index, relevance_score}]}
+type APIReranker struct {
+	client   *http.Client
+	endpoint string
+	apiKey   string
+	model    string
+}
+
+// NewAPIReranker 创建 rerank 接口调用器（client 为 nil 时使用默认客户端，超时由每次调用的 ctx 控制）
+func NewAPIReranker(client *http.Client, endpoint, apiKey, model string) *APIReranker {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &APIReranker{
+		client:   client,
+		endpoint: strings.TrimRight(strings.TrimSpace(endpoint), "/"),
+		apiKey:   strings.TrimSpace(apiKey),
+		model:    strings.TrimSpace(model),
+	}
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type rerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank 返回与 docs 一一对应的相关性分数；响应未覆盖的片段记为 0
+func (r *APIReranker) Rerank(ctx context.Context, query string, docs []string) ([]float64, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(rerankRequest{Model: r.model, Query: query, Documents: docs, TopN: len(docs)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rerank request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint+"/rerank", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid rerank endpoint: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rerank response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("rerank returned status %d", resp.StatusCode)
+	}
+	var out rerankResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid rerank response: %w", err)
+	}
+
+	scores := make([]float64, len(docs))
+	for _, res := range out.Results {
+		if res.Index < 0 || res.Index >= len(docs) {
+			return nil, fmt.Errorf("rerank result index %d out of range", res.Index)
+		}
+		scores[res.Index] = res.RelevanceScore
+	}
+	return scores, nil
+}
@@ -0,0 +1,44 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIRerankerMapsScoresByIndex(t *testing.T) {
+	var got rerankRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`))
+	}))
+	defer srv.Close()
+
+	r := NewAPIReranker(srv.Client(), srv.URL+"/v1/", "key", "bge-reranker-v2-m3")
+	scores, err := r.Rerank(context.Background(), "林舟夜探旧宅", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Rerank: %v", err)
+	}
+	if len(scores) != 3 || scores[0] != 0.4 || scores[1] != 0 || scores[2] != 0.9 {
+		t.Fatalf("unexpected scores %v", scores)
+	}
+	if auth != "Bearer key" || got.Model != "bge-reranker-v2-m3" || got.TopN != 3 || len(got.Documents) != 3 {
+		t.Fatalf("unexpected request %+v (auth %q)", got, auth)
+	}
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"index":5,"relevance_score":1}]}`))
+	}))
+	defer bad.Close()
+	if _, err := NewAPIReranker(bad.Client(), bad.URL, "", "").Rerank(context.Background(), "q", []string{"a"}); err == nil {
+		t.Fatal("out-of-range index should fail")
+	}
+}
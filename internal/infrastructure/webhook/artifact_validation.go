@@ -0,0 +1,91 @@
+// Package webhook 提供对租户外部 Webhook 的出站调用实现
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// maxResponseBytes 校验响应体读取上限
+const maxResponseBytes = 1 << 20
+
+// 签名请求头：X-Signature = hex(HMAC-SHA256(secret, "<timestamp>.<body>"))，X-Timestamp 为 Unix 秒
+const (
+	HeaderSignature = "X-Signature"
+	HeaderTimestamp = "X-Timestamp"
+)
+
+// ArtifactValidationCaller 构件激活校验 Webhook 的 HTTP 调用
+type ArtifactValidationCaller struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewArtifactValidationCaller 创建校验 Webhook 调用器（client 为 nil 时使用默认客户端，超时由每次调用的 ctx 控制）
+func NewArtifactValidationCaller(client *http.Client) *ArtifactValidationCaller {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &ArtifactValidationCaller{client: client, now: time.Now}
+}
+
+// Call POST 候选内容并解析校验结果
+func (c *ArtifactValidationCaller) Call(ctx context.Context, hook *entity.ArtifactValidationWebhook, req *storyartifact.ActivationValidationRequest, timeout time.Duration) (*storyartifact.ActivationValidationResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode validation request: %w", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(callCtx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		ts := strconv.FormatInt(c.now().Unix(), 10)
+		httpReq.Header.Set(HeaderTimestamp, ts)
+		httpReq.Header.Set(HeaderSignature, Sign(hook.Secret, ts, body))
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	var out storyartifact.ActivationValidationResult
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	return &out, nil
+}
+
+// Sign 计算 HMAC-SHA256(secret, "<timestamp>.<body>") 的十六进制摘要（供接收方校验）
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+func TestActivationValidatorCallsTenantWebhook(t *testing.T) {
+	ctx := context.Background()
+	var gotSignature bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(HeaderSignature) == Sign("s3cret", r.Header.Get(HeaderTimestamp), body)
+		var req storyartifact.ActivationValidationRequest
+		_ = json.Unmarshal(body, &req)
+		if req.Trigger == storyartifact.ActivationTriggerRollback {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		passed := string(req.Content) == `{"title":"好名字"}`
+		_ = json.NewEncoder(w).Encode(storyartifact.ActivationValidationResult{
+			Passed:   passed,
+			Messages: []storyartifact.ActivationValidationMessage{{Code: "naming", Message: "标题不符合命名规范", Path: "title"}},
+		})
+	}))
+	defer srv.Close()
+
+	store := memrepo.NewStore()
+	tenants := memrepo.NewTenantRepository(store)
+	tenant := entity.NewTenant("t", "t")
+	tenant.Settings.ArtifactValidation = &entity.ArtifactValidationWebhook{
+		URL:           srv.URL,
+		Secret:        "s3cret",
+		ArtifactTypes: []entity.ArtifactType{entity.ArtifactTypeNovelFoundation},
+	}
+	if err := tenants.Create(ctx, tenant); err != nil {
+		t.Fatal(err)
+	}
+	validator := storyartifact.NewActivationValidator(tenants, NewArtifactValidationCaller(srv.Client()))
+
+	req := &storyartifact.ActivationValidationRequest{
+		TenantID:     tenant.ID,
+		ArtifactType: entity.ArtifactTypeNovelFoundation,
+		Trigger:      storyartifact.ActivationTriggerCandidate,
+		Content:      json.RawMessage(`{"title":"好名字"}`),
+	}
+	if err := validator.Check(ctx, req); err != nil {
+		t.Fatalf("expected pass, got %v", err)
+	}
+	if !gotSignature {
+		t.Fatal("request should be signed with the tenant secret")
+	}
+
+	req.Content = json.RawMessage(`{"title":"坏名字"}`)
+	err := validator.Check(ctx, req)
+	var rejected *storyartifact.ActivationRejectedError
+	if !errors.Is(err, storyartifact.ErrActivationRejected) || !errors.As(err, &rejected) || rejected.Messages[0].Path != "title" {
+		t.Fatalf("expected rejection with messages, got %v", err)
+	}
+
+	// 不在校验范围内的构件类型直接放行
+	req.ArtifactType = entity.ArtifactTypeOutline
+	if err := validator.Check(ctx, req); err != nil {
+		t.Fatalf("unscoped type should pass, got %v", err)
+	}
+
+	// 调用失败默认阻止激活，fail_open 时放行
+	req.ArtifactType = entity.ArtifactTypeNovelFoundation
+	req.Trigger = storyartifact.ActivationTriggerRollback
+	if err := validator.Check(ctx, req); !errors.Is(err, storyartifact.ErrActivationValidationUnavailable) {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+	tenant.Settings.ArtifactValidation.FailOpen = true
+	if err := tenants.Update(ctx, tenant); err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.Check(ctx, req); err != nil {
+		t.Fatalf("fail_open should allow activation, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+
+	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "z-novel-ai-api/api/proto/gen/go/common"
+	storyv1 "z-novel-ai-api/api/proto/gen/go/story"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+)
+
+// StoryGenStreamer 经 StoryGen gRPC 服务流式生成章节，供网关 SSE 处理器代理（模型调用不在网关进程内进行）。
+type StoryGenStreamer struct {
+	client storyv1.StoryGenServiceClient
+}
+
+// NewStoryGenStreamer 创建章节流式生成代理
+func NewStoryGenStreamer(client storyv1.StoryGenServiceClient) *StoryGenStreamer {
+	return &StoryGenStreamer{client: client}
+}
+
+// StreamChapter 发起流式生成并转换为 Eino StreamReader（与本地 ChapterGenerator.Stream 约定一致）：
+// 正文片段逐条返回，最后一条消息 Content 为空、携带 Usage；调用方负责 Close()。
+// ctx 的截止时间随请求传递给生成服务，超时或取消时返回 ctx 的错误。
+func (s *StoryGenStreamer) StreamChapter(ctx context.Context, tenantID, userID, chapterID string, in *wfmodel.ChapterGenerateInput) (*schema.StreamReader[*schema.Message], error) {
+	stream, err := s.client.StreamGenerateChapter(ctx, chapterRequest(ctx, tenantID, userID, chapterID, in))
+	if err != nil {
+		return nil, streamError(ctx, err)
+	}
+
+	reader, writer := schema.Pipe[*schema.Message](16)
+	go func() {
+		defer writer.Close()
+		for {
+			chunk, recvErr := stream.Recv()
+			if stderrors.Is(recvErr, io.EOF) {
+				return
+			}
+			if recvErr != nil {
+				writer.Send(nil, streamError(ctx, recvErr))
+				return
+			}
+			var msg *schema.Message
+			switch p := chunk.GetPayload().(type) {
+			case *storyv1.GenerateChapterChunk_ContentChunk:
+				msg = &schema.Message{Role: schema.Assistant, Content: p.ContentChunk}
+			case *storyv1.GenerateChapterChunk_Metadata:
+				msg = &schema.Message{Role: schema.Assistant, ResponseMeta: &schema.ResponseMeta{
+					Usage: &schema.TokenUsage{
+						PromptTokens:     int(p.Metadata.GetPromptTokens()),
+						CompletionTokens: int(p.Metadata.GetCompletionTokens()),
+						TotalTokens:      int(p.Metadata.GetPromptTokens() + p.Metadata.GetCompletionTokens()),
+					},
+				}}
+			case *storyv1.GenerateChapterChunk_Error:
+				writer.Send(nil, stderrors.New(p.Error))
+				return
+			default:
+				continue
+			}
+			if closed := writer.Send(msg, nil); closed {
+				return
+			}
+		}
+	}()
+	return reader, nil
+}
+
+func chapterRequest(ctx context.Context, tenantID, userID, chapterID string, in *wfmodel.ChapterGenerateInput) *storyv1.GenerateChapterRequest {
+	tc := &commonv1.TenantContext{TenantId: tenantID, UserId: userID}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		tc.TraceId = sc.TraceID().String()
+	}
+	opts := &storyv1.GenerationOptions{Provider: in.Provider, Model: in.Model}
+	if in.Temperature != nil {
+		t := float64(*in.Temperature)
+		opts.Temperature = &t
+	}
+	return &storyv1.GenerateChapterRequest{
+		Context:         tc,
+		ChapterId:       chapterID,
+		Outline:         in.ChapterOutline,
+		TargetWordCount: int32(in.TargetWordCount),
+		Options:         opts,
+		Prompt: &storyv1.ChapterPromptContext{
+			ProjectTitle:       in.ProjectTitle,
+			ProjectDescription: in.ProjectDescription,
+			ChapterTitle:       in.ChapterTitle,
+			RetrievedContext:   in.RetrievedContext,
+			ActiveWorldview:    in.ActiveWorldview,
+			ActiveCharacters:   in.ActiveCharacters,
+			WritingStyle:       in.WritingStyle,
+			Pov:                in.POV,
+		},
+	}
+}
+
+// streamError 将 gRPC 取消/超时状态还原为 ctx 错误，便于调用方按本地生成的方式识别超时
+func streamError(ctx context.Context, err error) error {
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded:
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+	return err
+}
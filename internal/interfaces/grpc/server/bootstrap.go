@@ -5,9 +5,6 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"google.golang.org/grpc"
 
@@ -15,7 +12,7 @@ import (
 	"z-novel-ai-api/pkg/logger"
 )
 
-// Run 启动 gRPC Server，并在退出信号时优雅停止。
+// Run 启动 gRPC Server 并阻塞运行，ctx 取消时优雅停止（退出信号由 pkg/runtime 统一处理）。
 func Run(ctx context.Context, cfg *config.Config, register func(s *grpc.Server)) error {
 	addr := fmt.Sprintf("%s:%d", cfg.Server.GRPC.Host, cfg.Server.GRPC.Port)
 
@@ -41,11 +38,8 @@ func Run(ctx context.Context, cfg *config.Config, register func(s *grpc.Server))
 		errCh <- s.Serve(lis)
 	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
 	select {
-	case <-quit:
+	case <-ctx.Done():
 		log.Info("grpc server shutting down")
 		s.GracefulStop()
 		return nil
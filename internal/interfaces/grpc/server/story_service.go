@@ -3,27 +3,153 @@ package server
 
 import (
 	"context"
+	stderrors "errors"
+	"io"
+	"strings"
+	"time"
 
 	storyv1 "z-novel-ai-api/api/proto/gen/go/story"
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+	"z-novel-ai-api/pkg/logger"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// StoryGenService gRPC StoryGenService 服务端（占位实现）
+// StoryGenService gRPC StoryGenService 服务端：在独立进程内调用模型生成章节。
+// 提示词上下文由调用方（网关）组装后随请求传入，本服务不访问数据库、不做扣费与落库。
 type StoryGenService struct {
 	storyv1.UnimplementedStoryGenServiceServer
+
+	generator *storychapter.ChapterGenerator
+}
+
+// NewStoryGenService 创建 StoryGenService 服务端
+func NewStoryGenService(generator *storychapter.ChapterGenerator) *StoryGenService {
+	return &StoryGenService{generator: generator}
 }
 
 func (s *StoryGenService) GenerateChapter(ctx context.Context, req *storyv1.GenerateChapterRequest) (*storyv1.GenerateChapterResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "story generation not implemented")
+	in, err := chapterInputFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.generator.Generate(ctx, in)
+	if err != nil {
+		return nil, generationStatus(ctx, req, err)
+	}
+	return &storyv1.GenerateChapterResponse{
+		ChapterId: req.GetChapterId(),
+		Content:   out.Content,
+		WordCount: int32(len([]rune(out.Content))),
+		Metadata:  generationMetadata(out.Meta),
+	}, nil
 }
 
+// StreamGenerateChapter 按模型输出顺序转发正文片段，流结束后发送一条包含 Token 用量的 metadata。
+// 客户端断开或截止时间到达时 ctx 被取消，模型调用随之中止。
 func (s *StoryGenService) StreamGenerateChapter(req *storyv1.GenerateChapterRequest, stream grpc.ServerStreamingServer[storyv1.GenerateChapterChunk]) error {
-	return status.Error(codes.Unimplemented, "story streaming not implemented")
+	ctx := stream.Context()
+	in, err := chapterInputFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	reader, err := s.generator.Stream(ctx, in)
+	if err != nil {
+		return generationStatus(ctx, req, err)
+	}
+	defer reader.Close()
+
+	meta := wfmodel.LLMUsageMeta{Provider: in.Provider, Model: in.Model}
+	if in.Temperature != nil {
+		meta.Temperature = float64(*in.Temperature)
+	}
+	for {
+		msg, recvErr := reader.Recv()
+		if stderrors.Is(recvErr, io.EOF) {
+			break
+		}
+		if recvErr != nil {
+			return generationStatus(ctx, req, recvErr)
+		}
+		if msg == nil {
+			continue
+		}
+		if msg.Content != "" {
+			if err := stream.Send(&storyv1.GenerateChapterChunk{
+				Payload: &storyv1.GenerateChapterChunk_ContentChunk{ContentChunk: msg.Content},
+			}); err != nil {
+				return err
+			}
+		}
+		if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+			meta.PromptTokens = msg.ResponseMeta.Usage.PromptTokens
+			meta.CompletionTokens = msg.ResponseMeta.Usage.CompletionTokens
+		}
+	}
+
+	meta.GeneratedAt = time.Now().UTC()
+	return stream.Send(&storyv1.GenerateChapterChunk{
+		Payload: &storyv1.GenerateChapterChunk_Metadata{Metadata: generationMetadata(meta)},
+	})
 }
 
 func (s *StoryGenService) GenerateSummary(ctx context.Context, req *storyv1.GenerateSummaryRequest) (*storyv1.GenerateSummaryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "summary generation not implemented")
 }
+
+// chapterInputFromRequest 将 gRPC 请求转换为章节生成输入
+func chapterInputFromRequest(req *storyv1.GenerateChapterRequest) (*wfmodel.ChapterGenerateInput, error) {
+	outline := strings.TrimSpace(req.GetOutline())
+	if outline == "" {
+		return nil, status.Error(codes.InvalidArgument, "outline is required")
+	}
+	prompt := req.GetPrompt()
+	opts := req.GetOptions()
+	in := &wfmodel.ChapterGenerateInput{
+		ProjectTitle:       prompt.GetProjectTitle(),
+		ProjectDescription: prompt.GetProjectDescription(),
+		ChapterTitle:       prompt.GetChapterTitle(),
+		ChapterOutline:     outline,
+		RetrievedContext:   prompt.GetRetrievedContext(),
+		ActiveWorldview:    prompt.GetActiveWorldview(),
+		ActiveCharacters:   prompt.GetActiveCharacters(),
+		TargetWordCount:    int(req.GetTargetWordCount()),
+		WritingStyle:       prompt.GetWritingStyle(),
+		POV:                prompt.GetPov(),
+		Provider:           strings.TrimSpace(opts.GetProvider()),
+		Model:              strings.TrimSpace(opts.GetModel()),
+	}
+	if opts != nil && opts.Temperature != nil {
+		t := float32(opts.GetTemperature())
+		in.Temperature = &t
+	}
+	return in, nil
+}
+
+func generationMetadata(meta wfmodel.LLMUsageMeta) *storyv1.GenerationMetadata {
+	return &storyv1.GenerationMetadata{
+		Model:            meta.Model,
+		Provider:         meta.Provider,
+		PromptTokens:     int32(meta.PromptTokens),
+		CompletionTokens: int32(meta.CompletionTokens),
+		Temperature:      meta.Temperature,
+		GeneratedAt:      meta.GeneratedAt.Unix(),
+	}
+}
+
+// generationStatus 将生成错误转换为 gRPC 状态：取消/超时保留对应状态码，其余记为 Internal
+func generationStatus(ctx context.Context, req *storyv1.GenerateChapterRequest, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	logger.Error(ctx, "chapter generation failed", err,
+		"tenant_id", req.GetContext().GetTenantId(),
+		"trace_id", req.GetContext().GetTraceId(),
+		"chapter_id", req.GetChapterId(),
+	)
+	return status.Error(codes.Internal, err.Error())
+}
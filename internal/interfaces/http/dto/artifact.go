@@ -6,6 +6,7 @@ import (
 	"time"
 
 	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
 	"z-novel-ai-api/internal/domain/entity"
 )
 
@@ -45,7 +46,10 @@ type ArtifactVersionResponse struct {
 	Content         json.RawMessage `json:"content"`
 	CreatedBy       *string         `json:"created_by,omitempty"`
 	SourceJobID     *string         `json:"source_job_id,omitempty"`
+	Tags            []string        `json:"tags,omitempty"`
 	CreatedAt       string          `json:"created_at"`
+	// Metadata 写入时的激活策略与决策原因（早期版本为空）
+	Metadata *entity.ArtifactVersionMetadata `json:"metadata,omitempty"`
 }
 
 func ToArtifactVersionResponse(v *entity.ArtifactVersion) *ArtifactVersionResponse {
@@ -62,6 +66,7 @@ func ToArtifactVersionResponse(v *entity.ArtifactVersion) *ArtifactVersionRespon
 		CreatedBy:       v.CreatedBy,
 		SourceJobID:     v.SourceJobID,
 		CreatedAt:       v.CreatedAt.UTC().Format(time.RFC3339),
+		Metadata:        v.Metadata,
 	}
 }
 
@@ -69,6 +74,48 @@ type ArtifactVersionListResponse struct {
 	Versions []*ArtifactVersionResponse `json:"versions"`
 }
 
+// WithTags 附上版本的标签
+func (r *ArtifactVersionResponse) WithTags(tags []string) *ArtifactVersionResponse {
+	if r != nil && len(tags) > 0 {
+		r.Tags = tags
+	}
+	return r
+}
+
+type ArtifactTagCreateRequest struct {
+	Tag  string `json:"tag" binding:"required"`
+	Note string `json:"note"`
+}
+
+type ArtifactTagResponse struct {
+	Tag       string  `json:"tag"`
+	VersionID string  `json:"version_id"`
+	VersionNo int     `json:"version_no"`
+	BranchKey string  `json:"branch_key"`
+	Note      string  `json:"note,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func ToArtifactTagResponse(t *entity.ArtifactVersionTag) *ArtifactTagResponse {
+	if t == nil {
+		return nil
+	}
+	return &ArtifactTagResponse{
+		Tag:       t.Tag,
+		VersionID: t.VersionID,
+		VersionNo: t.VersionNo,
+		BranchKey: t.BranchKey,
+		Note:      t.Note,
+		CreatedBy: t.CreatedBy,
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type ArtifactTagListResponse struct {
+	Tags []*ArtifactTagResponse `json:"tags"`
+}
+
 type ArtifactRollbackRequest struct {
 	VersionID string `json:"version_id" binding:"required"`
 }
@@ -92,6 +139,14 @@ type ArtifactBranchListResponse struct {
 	Branches []*ArtifactBranchHeadResponse `json:"branches"`
 }
 
+// ArtifactBranchDeleteResponse 删除分支结果
+type ArtifactBranchDeleteResponse struct {
+	BranchKey         string   `json:"branch_key"`
+	DeletedVersionIDs []string `json:"deleted_version_ids"`
+	// MemoryPurged 分支记忆（向量片段）是否已清理；向量检索未启用或不支持分支隔离时为 false
+	MemoryPurged bool `json:"memory_purged"`
+}
+
 type ArtifactCompareResponse struct {
 	ArtifactID string                             `json:"artifact_id"`
 	Type       string                             `json:"type"`
@@ -99,3 +154,26 @@ type ArtifactCompareResponse struct {
 	To         *ArtifactVersionResponse           `json:"to"`
 	Diff       *storyartifact.ArtifactCompareDiff `json:"diff,omitempty"`
 }
+
+// ArtifactApplyRequest 将构件版本增量应用到项目（worldview/characters/outline → FoundationPlan）
+type ArtifactApplyRequest struct {
+	// Types 要应用的构件类型；为空表示全部已有激活版本的构件
+	Types []string `json:"types,omitempty"`
+	// VersionIDs 按构件类型指定版本（type -> version_id），未指定时使用激活版本
+	VersionIDs map[string]string `json:"version_ids,omitempty"`
+}
+
+// ArtifactApplySource 本次应用使用的构件版本
+type ArtifactApplySource struct {
+	Type       string `json:"type"`
+	ArtifactID string `json:"artifact_id"`
+	VersionID  string `json:"version_id"`
+	VersionNo  int    `json:"version_no"`
+}
+
+// ArtifactApplyResponse 构件应用（落库）响应
+type ArtifactApplyResponse struct {
+	ProjectID string                                 `json:"project_id"`
+	Sources   []*ArtifactApplySource                 `json:"sources"`
+	Result    *storyfoundation.FoundationApplyResult `json:"result"`
+}
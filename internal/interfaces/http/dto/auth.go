@@ -37,6 +37,12 @@ type AuthResponse struct {
 	User         *AuthUserDTO `json:"user"`
 }
 
+// RefreshTokenResponse 刷新 Token 响应
+type RefreshTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // 秒
+}
+
 // ToAuthUserDTO 将领域实体转换为 DTO
 func ToAuthUserDTO(u *entity.User) *AuthUserDTO {
 	if u == nil {
@@ -0,0 +1,54 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// InvoiceResponse 账单（购买记录）响应
+type InvoiceResponse struct {
+	ID         string               `json:"id"`
+	Provider   string               `json:"provider"`
+	ExternalID string               `json:"external_id"`
+	Amount     int64                `json:"amount"`
+	Currency   string               `json:"currency"`
+	Tokens     int64                `json:"tokens"`
+	Status     entity.InvoiceStatus `json:"status"`
+	PaidAt     time.Time            `json:"paid_at"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+// InvoiceListResponse 账单列表响应
+type InvoiceListResponse struct {
+	Items []*InvoiceResponse `json:"items"`
+}
+
+// BillingWebhookResponse 支付回调响应
+type BillingWebhookResponse struct {
+	Received bool `json:"received"`
+	// Duplicate 该笔支付此前已入账
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Ignored 与充值无关的事件（未入账）
+	Ignored   bool   `json:"ignored,omitempty"`
+	InvoiceID string `json:"invoice_id,omitempty"`
+}
+
+// ToInvoiceResponse 转换为账单响应
+func ToInvoiceResponse(inv *entity.Invoice) *InvoiceResponse {
+	if inv == nil {
+		return nil
+	}
+	return &InvoiceResponse{
+		ID:         inv.ID,
+		Provider:   inv.Provider,
+		ExternalID: inv.ExternalID,
+		Amount:     inv.Amount,
+		Currency:   inv.Currency,
+		Tokens:     inv.Tokens,
+		Status:     inv.Status,
+		PaidAt:     inv.PaidAt,
+		CreatedAt:  inv.CreatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// CandidateResponse 多候选生成的单个候选
+type CandidateResponse struct {
+	ID               string          `json:"id"`
+	JobID            string          `json:"job_id"`
+	TargetType       string          `json:"target_type"` // chapter / artifact
+	TargetID         string          `json:"target_id"`
+	CandidateNo      int             `json:"candidate_no"`
+	Status           string          `json:"status"`            // pending / selected / discarded
+	Partial          bool            `json:"partial,omitempty"` // 流式生成被中断时保存的部分正文
+	Score            float64         `json:"score"`
+	Content          string          `json:"content,omitempty"`          // 章节正文
+	ArtifactContent  json.RawMessage `json:"artifact_content,omitempty"` // 构件 JSON
+	Provider         string          `json:"provider,omitempty"`
+	Model            string          `json:"model,omitempty"`
+	PromptTokens     int             `json:"prompt_tokens,omitempty"`
+	CompletionTokens int             `json:"completion_tokens,omitempty"`
+	VersionID        *string         `json:"version_id,omitempty"`
+	SelectedAt       *time.Time      `json:"selected_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// CandidateListResponse 任务候选列表响应
+type CandidateListResponse struct {
+	JobID      string               `json:"job_id"`
+	Candidates []*CandidateResponse `json:"candidates"`
+}
+
+// SelectCandidateResponse 选择候选响应
+type SelectCandidateResponse struct {
+	Candidate *CandidateResponse `json:"candidate"`
+	// Chapter 章节候选被采用后的章节
+	Chapter *ChapterResponse `json:"chapter,omitempty"`
+	// ArtifactSnapshot 构件候选被采用后新建的版本
+	ArtifactSnapshot *ArtifactSnapshotResponse `json:"artifact_snapshot,omitempty"`
+}
+
+// ToCandidateResponse 将候选实体转换为响应 DTO；withContent 为 false 时省略正文（用于生成响应中的概览）
+func ToCandidateResponse(c *entity.GenerationCandidate, withContent bool) *CandidateResponse {
+	if c == nil {
+		return nil
+	}
+	resp := &CandidateResponse{
+		ID:               c.ID,
+		JobID:            c.JobID,
+		TargetType:       string(c.TargetType),
+		TargetID:         c.TargetID,
+		CandidateNo:      c.CandidateNo,
+		Status:           string(c.Status),
+		Partial:          c.Partial,
+		Score:            c.Score,
+		Provider:         c.Provider,
+		Model:            c.Model,
+		PromptTokens:     c.PromptTokens,
+		CompletionTokens: c.CompletionTokens,
+		VersionID:        c.VersionID,
+		SelectedAt:       c.SelectedAt,
+		CreatedAt:        c.CreatedAt,
+	}
+	if withContent {
+		if c.TargetType == entity.CandidateTargetArtifact {
+			resp.ArtifactContent = c.ArtifactContent()
+		} else {
+			resp.Content = c.Content
+		}
+	}
+	return resp
+}
+
+// ToCandidateListResponse 将任务候选转换为列表响应
+func ToCandidateListResponse(jobID string, candidates []*entity.GenerationCandidate) *CandidateListResponse {
+	resp := &CandidateListResponse{
+		JobID:      jobID,
+		Candidates: make([]*CandidateResponse, 0, len(candidates)),
+	}
+	for _, c := range candidates {
+		resp.Candidates = append(resp.Candidates, ToCandidateResponse(c, true))
+	}
+	return resp
+}
@@ -2,8 +2,12 @@
 package dto
 
 import (
+	"strings"
 	"time"
 
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/pacing"
+	"z-novel-ai-api/internal/application/story/textdiff"
 	"z-novel-ai-api/internal/domain/entity"
 )
 
@@ -13,6 +17,8 @@ type CreateChapterRequest struct {
 	Outline        string `json:"outline" binding:"max=10000"`
 	VolumeID       string `json:"volume_id,omitempty"`
 	StoryTimeStart int64  `json:"story_time_start,omitempty"`
+	IsFlashback    bool   `json:"is_flashback,omitempty"`
+	POVEntityID    string `json:"pov_entity_id,omitempty" binding:"omitempty,uuid"`
 	Notes          string `json:"notes" binding:"max=2000"`
 }
 
@@ -20,12 +26,44 @@ type CreateChapterRequest struct {
 type UpdateChapterRequest struct {
 	Title          *string `json:"title,omitempty" binding:"omitempty,max=255"`
 	Outline        *string `json:"outline,omitempty" binding:"omitempty,max=10000"`
+	OutlineKey     *string `json:"outline_key,omitempty" binding:"omitempty,max=128"` // 关联的大纲章节条目 key，传空串解除关联
 	ContentText    *string `json:"content_text,omitempty"`
 	Summary        *string `json:"summary,omitempty" binding:"omitempty,max=5000"`
 	Notes          *string `json:"notes,omitempty" binding:"omitempty,max=2000"`
 	StoryTimeStart *int64  `json:"story_time_start,omitempty"`
 	StoryTimeEnd   *int64  `json:"story_time_end,omitempty"`
+	IsFlashback    *bool   `json:"is_flashback,omitempty"`
+	POVEntityID    *string `json:"pov_entity_id,omitempty" binding:"omitempty,uuid"` // 传空串清除 POV
 	Status         *string `json:"status,omitempty"`
+	OutlineStale   *bool   `json:"outline_stale,omitempty"` // 传 false 确认章节已按新大纲处理，清除大纲过期标记（true 忽略，标记仅由大纲激活产生）
+}
+
+// AutosaveChapterRequest 章节正文自动保存请求
+type AutosaveChapterRequest struct {
+	ContentText *string `json:"content_text" binding:"required"`
+	// BaseVersion 客户端编辑所基于的章节版本；提供时与当前版本不一致将返回 409
+	BaseVersion *int `json:"base_version,omitempty" binding:"omitempty,gte=1"`
+}
+
+// AutosaveChapterResponse 章节正文自动保存响应
+type AutosaveChapterResponse struct {
+	ID           string     `json:"id"`
+	Version      int        `json:"version"`
+	NewVersion   bool       `json:"new_version"` // 本次保存是否产生了新版本（debounce 窗口外）
+	WordCount    int        `json:"word_count"`
+	DraftDirty   bool       `json:"draft_dirty"`
+	LastEditedBy string     `json:"last_edited_by,omitempty"`
+	LastEditedAt *time.Time `json:"last_edited_at,omitempty"`
+	// Warnings 非阻断提示（如与其他章节内容高度相似）
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ChapterConflictResponse 章节编辑冲突响应
+type ChapterConflictResponse struct {
+	ErrorResponse
+	CurrentVersion int    `json:"current_version"`
+	ConflictJobID  string `json:"conflict_job_id,omitempty"` // 正在写入同一章节的生成任务
+	LastEditedBy   string `json:"last_edited_by,omitempty"`
 }
 
 // GenerateChapterRequest 生成章节请求
@@ -37,6 +75,8 @@ type GenerateChapterRequest struct {
 	StoryTimeStart  int64              `json:"story_time_start,omitempty"`
 	Notes           string             `json:"notes" binding:"max=2000"`
 	Options         *GenerationOptions `json:"options,omitempty"`
+	// Background 批量起草等无人等待结果的后台生成：以低优先级入队，让位于交互式请求
+	Background bool `json:"background,omitempty"`
 }
 
 // GenerationOptions 生成选项
@@ -45,6 +85,10 @@ type GenerationOptions struct {
 	Temperature    float64 `json:"temperature,omitempty"`
 	SkipValidation bool    `json:"skip_validation,omitempty"`
 	MaxRetries     int     `json:"max_retries,omitempty"`
+	// Candidates 并行生成的候选数（best-of-N）；受 story.best_of_n 上限与预算约束，<= 1 表示单次生成
+	Candidates int `json:"candidates,omitempty" binding:"omitempty,gte=1,lte=8"`
+	// Selection 候选选择方式：auto（默认，按质量评分自动采用）/ manual（通过选择接口采用）
+	Selection string `json:"selection,omitempty" binding:"omitempty,oneof=auto manual"`
 }
 
 // RegenerateChapterRequest 重新生成章节请求
@@ -54,25 +98,139 @@ type RegenerateChapterRequest struct {
 	Options         *GenerationOptions `json:"options,omitempty"`
 }
 
+// EstimateChapterRequest 章节生成成本预估请求（字段与 GenerateChapterRequest 对齐）
+type EstimateChapterRequest struct {
+	Title           string   `json:"title" binding:"max=255"`
+	Outline         string   `json:"outline" binding:"required,max=10000"`
+	TargetWordCount int      `json:"target_word_count,omitempty" binding:"omitempty,gte=500,lte=10000"`
+	StoryTimeStart  int64    `json:"story_time_start,omitempty"`
+	POVEntityID     string   `json:"pov_entity_id,omitempty"`
+	Providers       []string `json:"providers,omitempty" binding:"max=16"`
+}
+
+// ChapterCostEstimateResponse 单个提供商的成本预估
+type ChapterCostEstimateResponse struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	IsDefault        bool    `json:"is_default"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CompletionCapped bool    `json:"completion_capped"`
+	Priced           bool    `json:"priced"`
+	Currency         string  `json:"currency,omitempty"`
+	PromptCost       float64 `json:"prompt_cost"`
+	CompletionCost   float64 `json:"completion_cost"`
+	TotalCost        float64 `json:"total_cost"`
+}
+
+// TokenEstimateBasisShared Token 数由同一字符启发式估算，未按各提供商分词器区分
+const TokenEstimateBasisShared = "shared_heuristic"
+
+// ChapterEstimateResponse 章节生成成本预估响应
+//
+// 各提供商的 Token 数相同（TokenEstimateBasis=shared_heuristic），成本差异仅来自单价；
+// 实际计费以各提供商分词结果为准。
+type ChapterEstimateResponse struct {
+	TargetWordCount    int                            `json:"target_word_count"`
+	PromptTokens       int                            `json:"prompt_tokens"`
+	CompletionTokens   int                            `json:"completion_tokens"`
+	RetrievedSegments  int                            `json:"retrieved_segments"`
+	TokenEstimateBasis string                         `json:"token_estimate_basis"`
+	Estimates          []*ChapterCostEstimateResponse `json:"estimates"`
+}
+
 // ChapterResponse 章节响应
 type ChapterResponse struct {
 	ID                 string                      `json:"id"`
 	ProjectID          string                      `json:"project_id"`
 	VolumeID           string                      `json:"volume_id,omitempty"`
 	SeqNum             int                         `json:"seq_num"`
+	DisplayNo          int                         `json:"display_no"` // 展示序号（第 N 章），与卷内排序键 seq_num 分离
 	Title              string                      `json:"title,omitempty"`
 	Outline            string                      `json:"outline,omitempty"`
-	ContentText        string                      `json:"content_text,omitempty"`
+	OutlineKey         string                      `json:"outline_key,omitempty"`
+	ContentText        *string                     `json:"content_text,omitempty"` // 列表未请求正文时省略
 	Summary            string                      `json:"summary,omitempty"`
 	Notes              string                      `json:"notes,omitempty"`
 	StoryTimeStart     int64                       `json:"story_time_start,omitempty"`
 	StoryTimeEnd       int64                       `json:"story_time_end,omitempty"`
+	IsFlashback        bool                        `json:"is_flashback,omitempty"`
+	POVEntityID        string                      `json:"pov_entity_id,omitempty"`
 	WordCount          int                         `json:"word_count"`
 	Status             string                      `json:"status"`
 	GenerationMetadata *GenerationMetadataResponse `json:"generation_metadata,omitempty"`
+	ContextPins        []*ContextPinDTO            `json:"context_pins,omitempty"`
 	Version            int                         `json:"version"`
+	DraftDirty         bool                        `json:"draft_dirty"`
+	OutlineStale       bool                        `json:"outline_stale,omitempty"`       // 关联的大纲条目在新激活的大纲中已变化
+	OutlineStaleSince  *time.Time                  `json:"outline_stale_since,omitempty"` // 首次标记时间
+	LastEditedBy       string                      `json:"last_edited_by,omitempty"`
+	LastEditedAt       *time.Time                  `json:"last_edited_at,omitempty"`
 	CreatedAt          time.Time                   `json:"created_at"`
 	UpdatedAt          time.Time                   `json:"updated_at"`
+
+	// Warnings 非阻断性校验提示（如故事时间倒退，warn 模式下返回）
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ContextPinDTO 章节固定上下文
+type ContextPinDTO struct {
+	// Type 类型：chapter / entity / segment
+	Type  string `json:"type" binding:"required,oneof=chapter entity segment"`
+	RefID string `json:"ref_id" binding:"required,max=64"`
+	// Text 片段文本（segment 类型必填，可取自检索结果）
+	Text string `json:"text,omitempty"`
+	Note string `json:"note,omitempty"`
+}
+
+// UpdateContextPinsRequest 整体替换章节固定上下文（空列表表示清空）
+type UpdateContextPinsRequest struct {
+	Pins []*ContextPinDTO `json:"pins" binding:"max=20,dive"`
+}
+
+// ContextPinsResponse 章节固定上下文响应
+type ContextPinsResponse struct {
+	ChapterID string           `json:"chapter_id"`
+	Pins      []*ContextPinDTO `json:"pins"`
+}
+
+// ToEntities 转换为领域对象
+func (r *UpdateContextPinsRequest) ToEntities() []entity.ContextPin {
+	out := make([]entity.ContextPin, 0, len(r.Pins))
+	for _, p := range r.Pins {
+		if p == nil {
+			continue
+		}
+		out = append(out, entity.ContextPin{
+			Type:  entity.ContextPinType(p.Type),
+			RefID: p.RefID,
+			Text:  p.Text,
+			Note:  p.Note,
+		})
+	}
+	return out
+}
+
+// ToContextPinDTOs 将领域对象转换为 DTO
+func ToContextPinDTOs(pins []entity.ContextPin) []*ContextPinDTO {
+	out := make([]*ContextPinDTO, 0, len(pins))
+	for _, p := range pins {
+		out = append(out, &ContextPinDTO{
+			Type:  string(p.Type),
+			RefID: p.RefID,
+			Text:  p.Text,
+			Note:  p.Note,
+		})
+	}
+	return out
+}
+
+// ToContextPinsResponse 构建章节固定上下文响应
+func ToContextPinsResponse(c *entity.Chapter) *ContextPinsResponse {
+	return &ContextPinsResponse{
+		ChapterID: c.ID,
+		Pins:      ToContextPinDTOs(c.ContextPins),
+	}
 }
 
 // GenerationMetadataResponse 生成元数据响应
@@ -83,11 +241,15 @@ type GenerationMetadataResponse struct {
 	CompletionTokens int     `json:"completion_tokens,omitempty"`
 	Temperature      float64 `json:"temperature,omitempty"`
 	GeneratedAt      string  `json:"generated_at,omitempty"`
+	// TitleSuggestions 待采用的标题建议（通过 accept-title 一键采用）
+	TitleSuggestions []string `json:"title_suggestions,omitempty"`
 }
 
 // ChapterListResponse 章节列表响应
 type ChapterListResponse struct {
 	Chapters []*ChapterResponse `json:"chapters"`
+	// ContentIncluded 是否包含正文；默认不含，需 ?include=content 或通过详情接口获取
+	ContentIncluded bool `json:"content_included"`
 }
 
 // ToChapterResponse 将领域实体转换为响应 DTO
@@ -97,22 +259,36 @@ func ToChapterResponse(c *entity.Chapter) *ChapterResponse {
 	}
 
 	resp := &ChapterResponse{
-		ID:             c.ID,
-		ProjectID:      c.ProjectID,
-		VolumeID:       c.VolumeID,
-		SeqNum:         c.SeqNum,
-		Title:          c.Title,
-		Outline:        c.Outline,
-		ContentText:    c.ContentText,
-		Summary:        c.Summary,
-		Notes:          c.Notes,
-		StoryTimeStart: c.StoryTimeStart,
-		StoryTimeEnd:   c.StoryTimeEnd,
-		WordCount:      c.WordCount,
-		Status:         string(c.Status),
-		Version:        c.Version,
-		CreatedAt:      c.CreatedAt,
-		UpdatedAt:      c.UpdatedAt,
+		ID:                c.ID,
+		ProjectID:         c.ProjectID,
+		VolumeID:          c.VolumeID,
+		SeqNum:            c.SeqNum,
+		DisplayNo:         c.DisplayNumber(),
+		Title:             c.Title,
+		Outline:           c.Outline,
+		OutlineKey:        c.OutlineKey,
+		ContentText:       &c.ContentText,
+		Summary:           c.Summary,
+		Notes:             c.Notes,
+		StoryTimeStart:    c.StoryTimeStart,
+		StoryTimeEnd:      c.StoryTimeEnd,
+		IsFlashback:       c.IsFlashback,
+		POVEntityID:       c.POVEntity(),
+		WordCount:         c.WordCount,
+		Status:            string(c.Status),
+		Version:           c.Version,
+		DraftDirty:        c.DraftDirty,
+		OutlineStale:      c.OutlineStale,
+		OutlineStaleSince: c.OutlineStaleSince,
+		LastEditedAt:      c.LastEditedAt,
+		CreatedAt:         c.CreatedAt,
+		UpdatedAt:         c.UpdatedAt,
+	}
+	if c.LastEditedBy != nil {
+		resp.LastEditedBy = *c.LastEditedBy
+	}
+	if len(c.ContextPins) > 0 {
+		resp.ContextPins = ToContextPinDTOs(c.ContextPins)
 	}
 
 	if c.GenerationMetadata != nil {
@@ -123,6 +299,7 @@ func ToChapterResponse(c *entity.Chapter) *ChapterResponse {
 			CompletionTokens: c.GenerationMetadata.CompletionTokens,
 			Temperature:      c.GenerationMetadata.Temperature,
 			GeneratedAt:      c.GenerationMetadata.GeneratedAt,
+			TitleSuggestions: c.GenerationMetadata.TitleSuggestions,
 		}
 	}
 
@@ -130,13 +307,18 @@ func ToChapterResponse(c *entity.Chapter) *ChapterResponse {
 }
 
 // ToChapterListResponse 将领域实体列表转换为响应 DTO
-func ToChapterListResponse(chapters []*entity.Chapter) *ChapterListResponse {
+func ToChapterListResponse(chapters []*entity.Chapter, contentIncluded bool) *ChapterListResponse {
 	resp := &ChapterListResponse{
-		Chapters: make([]*ChapterResponse, 0, len(chapters)),
+		Chapters:        make([]*ChapterResponse, 0, len(chapters)),
+		ContentIncluded: contentIncluded,
 	}
 
 	for _, c := range chapters {
-		resp.Chapters = append(resp.Chapters, ToChapterResponse(c))
+		item := ToChapterResponse(c)
+		if !contentIncluded {
+			item.ContentText = nil
+		}
+		resp.Chapters = append(resp.Chapters, item)
 	}
 
 	return resp
@@ -149,6 +331,10 @@ func (r *CreateChapterRequest) ToChapterEntity(projectID string, seqNum int) *en
 	chapter.Outline = r.Outline
 	chapter.Notes = r.Notes
 	chapter.StoryTimeStart = r.StoryTimeStart
+	chapter.IsFlashback = r.IsFlashback
+	if id := strings.TrimSpace(r.POVEntityID); id != "" {
+		chapter.POVEntityID = &id
+	}
 
 	return chapter
 }
@@ -161,8 +347,12 @@ func (r *UpdateChapterRequest) ApplyToChapter(c *entity.Chapter) {
 	if r.Outline != nil {
 		c.Outline = *r.Outline
 	}
+	if r.OutlineKey != nil {
+		c.OutlineKey = strings.TrimSpace(*r.OutlineKey)
+	}
 	if r.ContentText != nil {
-		c.SetContent(*r.ContentText)
+		c.ReplaceContent(*r.ContentText)
+		c.DraftDirty = false
 	}
 	if r.Summary != nil {
 		c.Summary = *r.Summary
@@ -176,9 +366,133 @@ func (r *UpdateChapterRequest) ApplyToChapter(c *entity.Chapter) {
 	if r.StoryTimeEnd != nil {
 		c.StoryTimeEnd = *r.StoryTimeEnd
 	}
+	if r.IsFlashback != nil {
+		c.IsFlashback = *r.IsFlashback
+	}
+	if r.POVEntityID != nil {
+		if id := strings.TrimSpace(*r.POVEntityID); id != "" {
+			c.POVEntityID = &id
+		} else {
+			c.POVEntityID = nil
+		}
+	}
 	if r.Status != nil {
 		c.Status = entity.ChapterStatus(*r.Status)
 	}
+	if r.OutlineStale != nil && !*r.OutlineStale {
+		c.ClearOutlineStale()
+	}
 
 	c.UpdatedAt = time.Now()
 }
+
+// PacingResponse 项目节奏分析响应
+type PacingResponse struct {
+	ProjectID string `json:"project_id"`
+	*pacing.Report
+}
+
+// ToPacingResponse 转换为节奏分析响应
+func ToPacingResponse(projectID string, report *pacing.Report) *PacingResponse {
+	return &PacingResponse{ProjectID: projectID, Report: report}
+}
+
+// OutlineCoverageResponse 大纲覆盖度响应
+type OutlineCoverageResponse struct {
+	ProjectID string `json:"project_id"`
+	*outline.Coverage
+}
+
+// ToOutlineCoverageResponse 转换为大纲覆盖度响应
+func ToOutlineCoverageResponse(projectID string, coverage *outline.Coverage) *OutlineCoverageResponse {
+	return &OutlineCoverageResponse{ProjectID: projectID, Coverage: coverage}
+}
+
+// ChapterDiffResponse 章节版本对比响应
+type ChapterDiffResponse struct {
+	ChapterID   string `json:"chapter_id"`
+	FromVersion int    `json:"from_version"`
+	ToVersion   int    `json:"to_version"`
+	*textdiff.Result
+}
+
+// ToChapterDiffResponse 转换为章节版本对比响应
+func ToChapterDiffResponse(chapterID string, from, to int, result *textdiff.Result) *ChapterDiffResponse {
+	return &ChapterDiffResponse{ChapterID: chapterID, FromVersion: from, ToVersion: to, Result: result}
+}
+
+// SuggestTitlesRequest 章节标题建议请求（请求体可省略）
+type SuggestTitlesRequest struct {
+	// Count 建议数量（3-5，默认 5）
+	Count    int    `json:"count,omitempty" binding:"omitempty,gte=3,lte=5"`
+	Provider string `json:"provider,omitempty" binding:"max=100"`
+	Model    string `json:"model,omitempty" binding:"max=100"`
+}
+
+// AcceptTitleRequest 采用标题建议请求
+type AcceptTitleRequest struct {
+	Title string `json:"title" binding:"required,max=255"`
+}
+
+// TitleSuggestionsResponse 章节标题建议响应
+type TitleSuggestionsResponse struct {
+	ChapterID    string   `json:"chapter_id"`
+	CurrentTitle string   `json:"current_title,omitempty"`
+	Titles       []string `json:"titles"`
+}
+
+// ToTitleSuggestionsResponse 转换为标题建议响应
+func ToTitleSuggestionsResponse(c *entity.Chapter) *TitleSuggestionsResponse {
+	titles := c.TitleSuggestions()
+	if titles == nil {
+		titles = []string{}
+	}
+	return &TitleSuggestionsResponse{ChapterID: c.ID, CurrentTitle: c.Title, Titles: titles}
+}
+
+// MoveChapterRequest 移动章节（跨卷或在卷内调整位置）
+type MoveChapterRequest struct {
+	// VolumeID 目标卷；为空表示章节当前所在卷
+	VolumeID string `json:"volume_id,omitempty"`
+	// Position 目标位置（从 1 开始）；为空或越界时放到卷末尾
+	Position int `json:"position,omitempty" binding:"omitempty,gte=1"`
+}
+
+// RenumberChaptersRequest 章节重编号请求
+type RenumberChaptersRequest struct {
+	// VolumeID 只压缩该卷的章节序号；为空表示全项目（含卷序号）
+	VolumeID string `json:"volume_id,omitempty"`
+}
+
+// ChapterNumberResponse 章节编号
+type ChapterNumberResponse struct {
+	ID        string `json:"id"`
+	VolumeID  string `json:"volume_id,omitempty"`
+	SeqNum    int    `json:"seq_num"`
+	DisplayNo int    `json:"display_no"`
+	Title     string `json:"title,omitempty"`
+}
+
+// ChapterNumberListResponse 重编号后按叙事顺序的章节编号
+type ChapterNumberListResponse struct {
+	ProjectID string                   `json:"project_id"`
+	Chapters  []*ChapterNumberResponse `json:"chapters"`
+}
+
+// ToChapterNumberListResponse 将叙事顺序的章节列表转换为编号响应
+func ToChapterNumberListResponse(projectID string, chapters []*entity.Chapter) *ChapterNumberListResponse {
+	resp := &ChapterNumberListResponse{
+		ProjectID: projectID,
+		Chapters:  make([]*ChapterNumberResponse, 0, len(chapters)),
+	}
+	for _, c := range chapters {
+		resp.Chapters = append(resp.Chapters, &ChapterNumberResponse{
+			ID:        c.ID,
+			VolumeID:  c.VolumeID,
+			SeqNum:    c.SeqNum,
+			DisplayNo: c.DisplayNumber(),
+			Title:     c.Title,
+		})
+	}
+	return resp
+}
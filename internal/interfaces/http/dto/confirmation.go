@@ -0,0 +1,18 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import "z-novel-ai-api/internal/application/confirm"
+
+// CreateConfirmationRequest 申请危险操作确认令牌请求
+type CreateConfirmationRequest struct {
+	Action     string `json:"action" binding:"required,oneof=project.delete user.delete artifact_branch.delete"`
+	ResourceID string `json:"resource_id" binding:"required,uuid"`
+	// BranchKey artifact_branch.delete 时必填：要删除的分支（resource_id 为构件 ID）
+	BranchKey string `json:"branch_key,omitempty" binding:"omitempty,max=64"`
+}
+
+// ConfirmationResponse 确认令牌响应：impact 为操作将删除或影响的对象数量，
+// 执行操作时在 X-Confirmation-Token 请求头中出示 token（一次性，过期后需重新申请）
+type ConfirmationResponse struct {
+	*confirm.Token
+}
@@ -3,6 +3,7 @@ package dto
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"z-novel-ai-api/internal/domain/entity"
@@ -12,25 +13,146 @@ type CreateSessionRequest struct {
 	Task string `json:"task,omitempty"`
 }
 
+// UpdateSessionRequest 更新会话请求：未提供的字段保持不变
+type UpdateSessionRequest struct {
+	Task *string `json:"task,omitempty"`
+	// GenerationDefaults 整体替换会话默认生成参数；传 {} 清空
+	GenerationDefaults *SessionGenerationDefaults `json:"generation_defaults,omitempty"`
+}
+
+// SessionGenerationDefaults 会话默认生成参数（发送消息时请求显式指定的参数优先）
+type SessionGenerationDefaults struct {
+	Provider    string   `json:"provider,omitempty" binding:"omitempty,max=32"`
+	Model       string   `json:"model,omitempty" binding:"omitempty,max=64"`
+	Temperature *float32 `json:"temperature,omitempty" binding:"omitempty,min=0,max=2"`
+	MaxTokens   *int     `json:"max_tokens,omitempty" binding:"omitempty,min=1,max=32000"`
+}
+
+// ToEntity 转换为会话默认参数实体（全部为空时返回 nil）
+func (d *SessionGenerationDefaults) ToEntity() *entity.SessionGenerationDefaults {
+	if d == nil {
+		return nil
+	}
+	out := &entity.SessionGenerationDefaults{
+		Provider:    strings.TrimSpace(d.Provider),
+		Model:       strings.TrimSpace(d.Model),
+		Temperature: d.Temperature,
+		MaxTokens:   d.MaxTokens,
+	}
+	if out.IsEmpty() {
+		return nil
+	}
+	return out
+}
+
+// 生成参数来源
+const (
+	GenerationParamSourceRequest = "request"
+	GenerationParamSourceSession = "session"
+	GenerationParamSourceDefault = "default"
+)
+
+// ApplySessionDefaults 以会话默认参数补全请求中未指定的生成参数（请求显式值优先），返回各参数来源。
+// 会话默认模型仅在生效 provider 与会话默认 provider 一致（或会话未指定 provider）时沿用，
+// 避免请求切换 provider 后套用另一家的模型名。
+func (r *ConversationMessageRequest) ApplySessionDefaults(d *entity.SessionGenerationDefaults) map[string]string {
+	sources := map[string]string{
+		"provider":    GenerationParamSourceDefault,
+		"model":       GenerationParamSourceDefault,
+		"temperature": GenerationParamSourceDefault,
+		"max_tokens":  GenerationParamSourceDefault,
+	}
+	if d == nil {
+		d = &entity.SessionGenerationDefaults{}
+	}
+
+	if strings.TrimSpace(r.Provider) != "" {
+		sources["provider"] = GenerationParamSourceRequest
+	} else if d.Provider != "" {
+		r.Provider = d.Provider
+		sources["provider"] = GenerationParamSourceSession
+	}
+	if strings.TrimSpace(r.Model) != "" {
+		sources["model"] = GenerationParamSourceRequest
+	} else if d.Model != "" && (d.Provider == "" || d.Provider == strings.TrimSpace(r.Provider)) {
+		r.Model = d.Model
+		sources["model"] = GenerationParamSourceSession
+	}
+	if r.Temperature != nil {
+		sources["temperature"] = GenerationParamSourceRequest
+	} else if d.Temperature != nil {
+		t := *d.Temperature
+		r.Temperature = &t
+		sources["temperature"] = GenerationParamSourceSession
+	}
+	if r.MaxTokens != nil {
+		sources["max_tokens"] = GenerationParamSourceRequest
+	} else if d.MaxTokens != nil {
+		n := *d.MaxTokens
+		r.MaxTokens = &n
+		sources["max_tokens"] = GenerationParamSourceSession
+	}
+	return sources
+}
+
 type SessionResponse struct {
 	ID          string `json:"id"`
 	ProjectID   string `json:"project_id"`
 	CurrentTask string `json:"current_task"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
+
+	GenerationDefaults *SessionGenerationDefaults `json:"generation_defaults,omitempty"`
+
+	// Usage 会话累计用量（仅会话详情与发送消息时返回）
+	Usage *SessionUsageResponse `json:"usage,omitempty"`
+}
+
+// SessionUsageResponse 会话累计用量
+type SessionUsageResponse struct {
+	AssistantTurns   int64 `json:"assistant_turns"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	// Costs 按币种汇总的成本（提供商未配置单价的轮次不计入）
+	Costs map[string]float64 `json:"costs,omitempty"`
+}
+
+// WithUsage 附加会话累计用量
+func (r *SessionResponse) WithUsage(u *entity.ConversationUsage) *SessionResponse {
+	if r == nil || u == nil {
+		return r
+	}
+	r.Usage = &SessionUsageResponse{
+		AssistantTurns:   u.Turns,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.PromptTokens + u.CompletionTokens,
+		Costs:            u.Costs,
+	}
+	return r
 }
 
 func ToSessionResponse(s *entity.ConversationSession) *SessionResponse {
 	if s == nil {
 		return nil
 	}
-	return &SessionResponse{
+	resp := &SessionResponse{
 		ID:          s.ID,
 		ProjectID:   s.ProjectID,
 		CurrentTask: string(s.CurrentTask),
 		CreatedAt:   s.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:   s.UpdatedAt.UTC().Format(time.RFC3339),
 	}
+	if d := s.GenerationDefaults; !d.IsEmpty() {
+		resp.GenerationDefaults = &SessionGenerationDefaults{
+			Provider:    d.Provider,
+			Model:       d.Model,
+			Temperature: d.Temperature,
+			MaxTokens:   d.MaxTokens,
+		}
+	}
+	return resp
 }
 
 type SessionListResponse struct {
@@ -38,19 +160,28 @@ type SessionListResponse struct {
 }
 
 type TurnResponse struct {
-	ID        string          `json:"id"`
-	Role      string          `json:"role"`
-	Task      string          `json:"task"`
-	Content   string          `json:"content"`
-	Metadata  json.RawMessage `json:"metadata,omitempty"`
-	CreatedAt string          `json:"created_at"`
+	ID        string             `json:"id"`
+	Role      string             `json:"role"`
+	Task      string             `json:"task"`
+	Content   string             `json:"content"`
+	Metadata  json.RawMessage    `json:"metadata,omitempty"`
+	Usage     *TurnUsageResponse `json:"usage,omitempty"`
+	CreatedAt string             `json:"created_at"`
+}
+
+// TurnUsageResponse 单轮用量（仅 assistant 轮次）
+type TurnUsageResponse struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost,omitempty"`
+	CostCurrency     string  `json:"cost_currency,omitempty"`
 }
 
 func ToTurnResponse(t *entity.ConversationTurn) *TurnResponse {
 	if t == nil {
 		return nil
 	}
-	return &TurnResponse{
+	resp := &TurnResponse{
 		ID:        t.ID,
 		Role:      string(t.Role),
 		Task:      string(t.Task),
@@ -58,6 +189,15 @@ func ToTurnResponse(t *entity.ConversationTurn) *TurnResponse {
 		Metadata:  t.Metadata,
 		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
 	}
+	if t.PromptTokens > 0 || t.CompletionTokens > 0 {
+		resp.Usage = &TurnUsageResponse{
+			PromptTokens:     t.PromptTokens,
+			CompletionTokens: t.CompletionTokens,
+			Cost:             t.Cost,
+			CostCurrency:     t.CostCurrency,
+		}
+	}
+	return resp
 }
 
 type TurnListResponse struct {
@@ -69,10 +209,18 @@ type SendMessageRequest struct {
 
 	// 分支（多分支创作）：为空表示 main 分支。
 	BranchKey string `json:"branch_key,omitempty"`
-	// 是否将本次生成结果设为激活版本；默认：main=true，非 main=false。
+	// 是否将本次生成结果设为激活版本；未指定时按项目设置 artifact_activation 决定
+	// （默认 auto_main：main 分支或构件首个版本激活；manual 不激活；auto_on_clean_scan 还要求冲突检查无冲突）。
 	Activate *bool `json:"activate,omitempty"`
-	// 是否启用“设定冲突扫描”；默认 true。
+	// 是否启用“设定冲突扫描”；默认 true（功能开关 artifact_conflict_scan 关闭时始终跳过）。
 	EnableConflictScan *bool `json:"enable_conflict_scan,omitempty"`
+	// 并行生成的候选数（best-of-N）；受 story.best_of_n 上限与预算约束，<= 1 表示单次生成
+	Candidates int `json:"candidates,omitempty" binding:"omitempty,gte=1,lte=8"`
+	// 候选选择方式：auto（默认，按质量评分自动采用并写入版本）/ manual（不写版本，通过选择接口采用）
+	Selection string `json:"selection,omitempty" binding:"omitempty,oneof=auto manual"`
+	// 草稿模式：只保存对话轮次，不创建构件版本、不做冲突扫描与索引；
+	// 生成结果保存在助手轮次中，之后可通过 POST .../turns/:tid/materialize 转为构件版本（不支持多候选）
+	Draft bool `json:"draft,omitempty"`
 
 	ConversationMessageRequest
 }
@@ -102,4 +250,28 @@ type SendMessageResponse struct {
 	ArtifactSnapshot *ArtifactSnapshotResponse `json:"artifact_snapshot,omitempty"`
 	ConflictWarnings []*SettingConflictWarning `json:"conflict_warnings,omitempty"`
 	Usage            *FoundationUsageResponse  `json:"usage,omitempty"`
+	// 多候选生成时的候选概览（不含内容，通过 GET /v1/jobs/:jid/candidates 查看详情）
+	Candidates []*CandidateResponse `json:"candidates,omitempty"`
+	// ActivationBlocked 请求激活但未通过租户校验 Webhook（或校验不可用）：版本已保存但未激活
+	ActivationBlocked *ErrorDetail `json:"activation_blocked,omitempty"`
+	// Draft 草稿模式生成：未创建构件版本（artifact_snapshot 为空）
+	Draft bool `json:"draft,omitempty"`
+}
+
+// MaterializeTurnRequest 将草稿轮次转为构件版本的请求（请求体可省略）
+type MaterializeTurnRequest struct {
+	// 目标分支：为空表示 main
+	BranchKey string `json:"branch_key,omitempty"`
+	// 是否激活新版本；未指定时按项目设置 artifact_activation 决定（草稿未做冲突扫描，auto_on_clean_scan 策略下不自动激活）
+	Activate *bool `json:"activate,omitempty"`
+}
+
+// MaterializeTurnResponse 草稿轮次转为构件版本的结果
+type MaterializeTurnResponse struct {
+	TurnID           string                    `json:"turn_id"`
+	JobID            string                    `json:"job_id,omitempty"`
+	ArtifactSnapshot *ArtifactSnapshotResponse `json:"artifact_snapshot"`
+	Activated        bool                      `json:"activated"`
+	// ActivationBlocked 请求激活但未通过租户校验 Webhook（或校验不可用）：版本已保存但未激活
+	ActivationBlocked *ErrorDetail `json:"activation_blocked,omitempty"`
 }
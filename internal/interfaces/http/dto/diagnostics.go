@@ -0,0 +1,31 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/pkg/diagnostics"
+)
+
+// CaptureDiagnosticsSnapshotRequest 采集诊断快照请求
+type CaptureDiagnosticsSnapshotRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=heap goroutine allocs"`
+}
+
+// DiagnosticsSnapshotResponse 诊断快照文件信息
+type DiagnosticsSnapshotResponse struct {
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ToDiagnosticsSnapshotResponse 转换诊断快照响应
+func ToDiagnosticsSnapshotResponse(s *diagnostics.Snapshot) *DiagnosticsSnapshotResponse {
+	return &DiagnosticsSnapshotResponse{
+		Name:      s.Name,
+		Kind:      s.Kind,
+		Size:      s.Size,
+		CreatedAt: s.CreatedAt,
+	}
+}
@@ -4,6 +4,7 @@ package dto
 import (
 	"time"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 )
 
@@ -75,14 +76,20 @@ type EntityRelationsResponse struct {
 
 // RelationResponse 关系响应
 type RelationResponse struct {
-	ID             string    `json:"id"`
-	SourceEntityID string    `json:"source_entity_id"`
-	TargetEntityID string    `json:"target_entity_id"`
-	RelationType   string    `json:"relation_type"`
-	Strength       float64   `json:"strength"`
-	Description    string    `json:"description,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string  `json:"id"`
+	SourceEntityID string  `json:"source_entity_id"`
+	TargetEntityID string  `json:"target_entity_id"`
+	RelationType   string  `json:"relation_type"`
+	Strength       float64 `json:"strength"`
+	Description    string  `json:"description,omitempty"`
+	// LastReinforcedChapterID 最近一次强化该关系的章节
+	LastReinforcedChapterID *string `json:"last_reinforced_chapter_id,omitempty"`
+	// EffectiveStrength 按时效衰减后的有效强度（仅列表/图谱查询返回）
+	EffectiveStrength *float64 `json:"effective_strength,omitempty"`
+	// ChaptersSinceReinforced 参照章节距最近强化的章数（仅列表/图谱查询返回）
+	ChaptersSinceReinforced *int      `json:"chapters_since_reinforced,omitempty"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
 }
 
 // ToEntityResponse 将领域实体转换为响应 DTO
@@ -139,17 +146,31 @@ func ToRelationResponse(r *entity.Relation) *RelationResponse {
 	}
 
 	return &RelationResponse{
-		ID:             r.ID,
-		SourceEntityID: r.SourceEntityID,
-		TargetEntityID: r.TargetEntityID,
-		RelationType:   string(r.RelationType),
-		Strength:       r.Strength,
-		Description:    r.Description,
-		CreatedAt:      r.CreatedAt,
-		UpdatedAt:      r.UpdatedAt,
+		ID:                      r.ID,
+		SourceEntityID:          r.SourceEntityID,
+		TargetEntityID:          r.TargetEntityID,
+		RelationType:            string(r.RelationType),
+		Strength:                r.Strength,
+		Description:             r.Description,
+		LastReinforcedChapterID: r.LastReinforcedChapterID,
+		CreatedAt:               r.CreatedAt,
+		UpdatedAt:               r.UpdatedAt,
 	}
 }
 
+// ToWeightedRelationResponse 将时效加权后的关系转换为响应 DTO
+func ToWeightedRelationResponse(w appstory.WeightedRelation) *RelationResponse {
+	resp := ToRelationResponse(w.Relation)
+	if resp == nil {
+		return nil
+	}
+	effective := w.EffectiveStrength
+	since := w.ChaptersSince
+	resp.EffectiveStrength = &effective
+	resp.ChaptersSinceReinforced = &since
+	return resp
+}
+
 // ToEntityRelationsResponse 构建实体关系响应
 func ToEntityRelationsResponse(entityID string, relations []*entity.Relation) *EntityRelationsResponse {
 	resp := &EntityRelationsResponse{
@@ -164,6 +185,20 @@ func ToEntityRelationsResponse(entityID string, relations []*entity.Relation) *E
 	return resp
 }
 
+// ToWeightedEntityRelationsResponse 构建带有效强度的实体关系响应
+func ToWeightedEntityRelationsResponse(entityID string, weighted []appstory.WeightedRelation) *EntityRelationsResponse {
+	resp := &EntityRelationsResponse{
+		EntityID:  entityID,
+		Relations: make([]*RelationResponse, 0, len(weighted)),
+	}
+
+	for _, w := range weighted {
+		resp.Relations = append(resp.Relations, ToWeightedRelationResponse(w))
+	}
+
+	return resp
+}
+
 // ToStoryEntity 将请求 DTO 转换为领域实体
 func (r *CreateEntityRequest) ToStoryEntity(projectID string) *entity.StoryEntity {
 	e := entity.NewStoryEntity(
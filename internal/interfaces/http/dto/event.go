@@ -4,6 +4,7 @@ package dto
 import (
 	"time"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 
 	"github.com/google/uuid"
@@ -23,6 +24,8 @@ type EventResponse struct {
 	LocationID       string                 `json:"location_id,omitempty"`
 	Importance       entity.EventImportance `json:"importance"`
 	Tags             []string               `json:"tags,omitempty"`
+	SupersededAt     *time.Time             `json:"superseded_at,omitempty"`
+	SupersededBy     *string                `json:"superseded_by,omitempty"`
 	CreatedAt        time.Time              `json:"created_at"`
 }
 
@@ -145,6 +148,8 @@ func ToEventResponse(e *entity.Event) *EventResponse {
 		LocationID:       e.LocationID,
 		Importance:       e.Importance,
 		Tags:             e.Tags,
+		SupersededAt:     e.SupersededAt,
+		SupersededBy:     e.SupersededBy,
 		CreatedAt:        e.CreatedAt,
 	}
 }
@@ -157,3 +162,51 @@ func ToEventListResponse(events []*entity.Event) *EventListResponse {
 	}
 	return &EventListResponse{Items: items}
 }
+
+// ReplaceChapterEventsRequest 替换章节事件请求（各事件的 chapter_id 以路径为准）
+type ReplaceChapterEventsRequest struct {
+	Events []CreateEventRequest `json:"events" binding:"omitempty,max=200,dive"`
+}
+
+// SupersededEventResponse 被替代的旧事件
+type SupersededEventResponse struct {
+	EventID      string  `json:"event_id"`
+	SupersededBy *string `json:"superseded_by,omitempty"`
+}
+
+// ReplaceChapterEventsResponse 替换章节事件响应
+type ReplaceChapterEventsResponse struct {
+	ChapterID  string                     `json:"chapter_id"`
+	Events     []*EventResponse           `json:"events"`
+	Superseded []*SupersededEventResponse `json:"superseded"`
+	Duplicates int                        `json:"duplicates"`
+}
+
+// ToEventEntities 转换为事件实体列表
+func (r *ReplaceChapterEventsRequest) ToEventEntities(projectID string) []*entity.Event {
+	events := make([]*entity.Event, 0, len(r.Events))
+	for i := range r.Events {
+		events = append(events, r.Events[i].ToEventEntity(projectID))
+	}
+	return events
+}
+
+// ToReplaceChapterEventsResponse 构建替换章节事件响应
+func ToReplaceChapterEventsResponse(chapterID string, res *appstory.ChapterEventReplacement) *ReplaceChapterEventsResponse {
+	resp := &ReplaceChapterEventsResponse{
+		ChapterID:  chapterID,
+		Events:     make([]*EventResponse, 0),
+		Superseded: make([]*SupersededEventResponse, 0),
+	}
+	if res == nil {
+		return resp
+	}
+	for _, e := range res.Created {
+		resp.Events = append(resp.Events, ToEventResponse(e))
+	}
+	for _, s := range res.Superseded {
+		resp.Superseded = append(resp.Superseded, &SupersededEventResponse{EventID: s.EventID, SupersededBy: s.SupersededBy})
+	}
+	resp.Duplicates = res.Duplicates
+	return resp
+}
@@ -0,0 +1,87 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/internal/application/featureflag"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// FeatureFlagResponse 功能开关判定结果响应
+type FeatureFlagResponse struct {
+	Key            string `json:"key"`
+	Description    string `json:"description,omitempty"`
+	Enabled        bool   `json:"enabled"`
+	Source         string `json:"source"`
+	GlobalEnabled  bool   `json:"global_enabled"`
+	RolloutPercent int    `json:"rollout_percent"`
+}
+
+// FeatureFlagListResponse 功能开关列表响应
+type FeatureFlagListResponse struct {
+	ProjectID string                         `json:"project_id,omitempty"`
+	Flags     []*FeatureFlagResponse         `json:"flags"`
+	Overrides []*FeatureFlagOverrideResponse `json:"overrides"`
+}
+
+// FeatureFlagOverrideResponse 开关覆盖响应
+type FeatureFlagOverrideResponse struct {
+	FlagKey   string    `json:"flag_key"`
+	ProjectID *string   `json:"project_id,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetFeatureFlagOverrideRequest 设置开关覆盖请求（project_id 为空表示租户级覆盖）
+type SetFeatureFlagOverrideRequest struct {
+	Enabled   *bool   `json:"enabled" binding:"required"`
+	ProjectID *string `json:"project_id" binding:"omitempty,uuid"`
+}
+
+// ToFeatureFlagResponse 转换开关判定结果
+func ToFeatureFlagResponse(ev *featureflag.Evaluation) *FeatureFlagResponse {
+	if ev == nil {
+		return nil
+	}
+	resp := &FeatureFlagResponse{
+		Key:     ev.Key,
+		Enabled: ev.Enabled,
+		Source:  ev.Source,
+	}
+	if ev.Flag != nil {
+		resp.Description = ev.Flag.Description
+		resp.GlobalEnabled = ev.Flag.Enabled
+		resp.RolloutPercent = ev.Flag.RolloutPercent
+	}
+	return resp
+}
+
+// ToFeatureFlagOverrideResponse 转换开关覆盖
+func ToFeatureFlagOverrideResponse(o *entity.FeatureFlagOverride) *FeatureFlagOverrideResponse {
+	if o == nil {
+		return nil
+	}
+	return &FeatureFlagOverrideResponse{
+		FlagKey:   o.FlagKey,
+		ProjectID: o.ProjectID,
+		Enabled:   o.Enabled,
+		UpdatedAt: o.UpdatedAt,
+	}
+}
+
+// ToFeatureFlagListResponse 转换开关列表
+func ToFeatureFlagListResponse(projectID string, evals []*featureflag.Evaluation, overrides []*entity.FeatureFlagOverride) *FeatureFlagListResponse {
+	resp := &FeatureFlagListResponse{
+		ProjectID: projectID,
+		Flags:     make([]*FeatureFlagResponse, 0, len(evals)),
+		Overrides: make([]*FeatureFlagOverrideResponse, 0, len(overrides)),
+	}
+	for _, ev := range evals {
+		resp.Flags = append(resp.Flags, ToFeatureFlagResponse(ev))
+	}
+	for _, o := range overrides {
+		resp.Overrides = append(resp.Overrides, ToFeatureFlagOverrideResponse(o))
+	}
+	return resp
+}
@@ -24,8 +24,8 @@ type FoundationGenerateRequest struct {
 	MaxTokens   *int     `json:"max_tokens,omitempty"`
 }
 
-// ToStoryInput 转换为应用层输入结构
-func (r *FoundationGenerateRequest) ToStoryInput(projectTitle, projectDescription string, provider, model string) *wfmodel.FoundationGenerateInput {
+// ToStoryAttachments 转换为应用层附件结构
+func (r *FoundationGenerateRequest) ToStoryAttachments() []wfmodel.TextAttachment {
 	attachments := make([]wfmodel.TextAttachment, 0, len(r.Attachments))
 	for i := range r.Attachments {
 		a := r.Attachments[i]
@@ -34,12 +34,16 @@ func (r *FoundationGenerateRequest) ToStoryInput(projectTitle, projectDescriptio
 			Content: a.Content,
 		})
 	}
+	return attachments
+}
 
+// ToStoryInput 转换为应用层输入结构
+func (r *FoundationGenerateRequest) ToStoryInput(projectTitle, projectDescription string, provider, model string) *wfmodel.FoundationGenerateInput {
 	return &wfmodel.FoundationGenerateInput{
 		ProjectTitle:       projectTitle,
 		ProjectDescription: projectDescription,
 		Prompt:             r.Prompt,
-		Attachments:        attachments,
+		Attachments:        r.ToStoryAttachments(),
 		Provider:           provider,
 		Model:              model,
 		Temperature:        r.Temperature,
@@ -56,6 +60,8 @@ type FoundationUsageResponse struct {
 	Temperature      float64 `json:"temperature,omitempty"`
 	DurationMs       int     `json:"duration_ms,omitempty"`
 	GeneratedAt      string  `json:"generated_at,omitempty"`
+	// Truncation 组装 Prompt 时被截断的输入（无截断时省略）
+	Truncation *TruncationReportResponse `json:"truncation,omitempty"`
 }
 
 // FoundationPreviewResponse 同步预览响应
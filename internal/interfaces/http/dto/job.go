@@ -3,34 +3,117 @@ package dto
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
 	"z-novel-ai-api/internal/domain/entity"
 )
 
 // JobResponse 任务响应
 type JobResponse struct {
-	ID               string                 `json:"id"`
-	ProjectID        string                 `json:"project_id"`
-	ChapterID        *string                `json:"chapter_id,omitempty"`
-	JobType          string                 `json:"job_type"`
-	Status           string                 `json:"status"`
-	Priority         int                    `json:"priority"`
-	LLMProvider      string                 `json:"llm_provider,omitempty"`
-	LLMModel         string                 `json:"llm_model,omitempty"`
-	TokensPrompt     int                    `json:"tokens_prompt,omitempty"`
-	TokensCompletion int                    `json:"tokens_completion,omitempty"`
-	DurationMs       int                    `json:"duration_ms,omitempty"`
-	Payload          map[string]interface{} `json:"payload,omitempty"`
-	Result           map[string]interface{} `json:"result,omitempty"`
-	ErrorMsg         string                 `json:"error_msg,omitempty"`
-	RetryCount       int                    `json:"retry_count"`
-	Progress         int                    `json:"progress"`
-	ScheduledAt      time.Time              `json:"scheduled_at,omitempty"`
-	StartedAt        time.Time              `json:"started_at,omitempty"`
-	CompletedAt      time.Time              `json:"completed_at,omitempty"`
-	CreatedAt        time.Time              `json:"created_at"`
-	UpdatedAt        time.Time              `json:"updated_at"`
+	ID               string                    `json:"id"`
+	ProjectID        string                    `json:"project_id"`
+	ChapterID        *string                   `json:"chapter_id,omitempty"`
+	JobType          string                    `json:"job_type"`
+	Category         string                    `json:"category"`
+	Status           string                    `json:"status"`
+	Priority         int                       `json:"priority"`
+	PriorityClass    string                    `json:"priority_class"` // high / normal / low（对应 Worker 消费的优先级流）
+	LLMProvider      string                    `json:"llm_provider,omitempty"`
+	LLMModel         string                    `json:"llm_model,omitempty"`
+	TokensPrompt     int                       `json:"tokens_prompt,omitempty"`
+	TokensCompletion int                       `json:"tokens_completion,omitempty"`
+	DurationMs       int                       `json:"duration_ms,omitempty"`
+	Payload          map[string]interface{}    `json:"payload,omitempty"`
+	Result           map[string]interface{}    `json:"result,omitempty"`
+	ErrorMsg         string                    `json:"error_msg,omitempty"`
+	ErrorCode        string                    `json:"error_code,omitempty"` // failed / timeout / quota_exceeded / cost_ceiling_exceeded / invalid_payload
+	Warnings         []*JobWarningResponse     `json:"warnings,omitempty"`
+	Truncation       *TruncationReportResponse `json:"truncation,omitempty"` // 组装 Prompt 时的截断报告
+	RetryCount       int                       `json:"retry_count"`
+	Attempts         int                       `json:"attempts"`     // 累计执行尝试次数（含失败尝试）
+	SpentTokens      int64                     `json:"spent_tokens"` // 累计 Token 消耗（含失败尝试、修复轮次与提供商切换）
+	Progress         int                       `json:"progress"`
+	ScheduledAt      time.Time                 `json:"scheduled_at,omitempty"`
+	StartedAt        time.Time                 `json:"started_at,omitempty"`
+	CompletedAt      time.Time                 `json:"completed_at,omitempty"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	UpdatedAt        time.Time                 `json:"updated_at"`
+
+	// 排队估算（仅异步生成接口入队时返回；Worker 未上报队列统计时省略）
+	QueuePosition        *int64     `json:"queue_position,omitempty"`
+	EstimatedWaitSeconds *int64     `json:"estimated_wait_seconds,omitempty"`
+	EstimatedStartAt     *time.Time `json:"estimated_start_at,omitempty"`
+}
+
+// WithQueueEstimate 附加排队估算
+func (r *JobResponse) WithQueueEstimate(position int64, wait time.Duration, startAt time.Time) *JobResponse {
+	if r == nil {
+		return r
+	}
+	waitSeconds := int64(math.Ceil(wait.Seconds()))
+	r.QueuePosition = &position
+	r.EstimatedWaitSeconds = &waitSeconds
+	r.EstimatedStartAt = &startAt
+	return r
+}
+
+// CreateJobRequest 提交运维任务请求
+type CreateJobRequest struct {
+	// JobType 运维任务类型：project_export / index_rebuild / vector_purge / artifact_gc
+	JobType string `json:"job_type" binding:"required,max=32"`
+	// Params 任务参数（project_export 支持 format：markdown / txt；artifact_gc 支持 keep_last、abandoned_branch_days、dry_run）
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// JobWarningResponse 任务警告（任务仍可成功，但部分步骤被跳过或输入被裁剪）
+type JobWarningResponse struct {
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TruncationReportResponse 组装 Prompt 时的截断报告（附件、会话滚动上下文、召回片段被截去的部分）
+type TruncationReportResponse struct {
+	Items     []*TruncationItemResponse `json:"items"`
+	CutChars  int                       `json:"cut_chars"`  // 被截去的总字数
+	CutTokens int                       `json:"cut_tokens"` // 被截去部分的估算 Token 数
+}
+
+// TruncationItemResponse 单项截断
+type TruncationItemResponse struct {
+	Source        string `json:"source"`         // attachment / rolling_context / retrieval
+	Name          string `json:"name,omitempty"` // 附件名、召回片段引用或滚动上下文的部分（prompt / summary）
+	OriginalChars int    `json:"original_chars"`
+	KeptChars     int    `json:"kept_chars"`
+	CutChars      int    `json:"cut_chars"`
+	CutTokens     int    `json:"cut_tokens"`
+	Dropped       bool   `json:"dropped,omitempty"` // 整体丢弃
+}
+
+// ToTruncationReportResponse 转换截断报告（无截断时返回 nil）
+func ToTruncationReportResponse(r *entity.TruncationReport) *TruncationReportResponse {
+	if r == nil || len(r.Items) == 0 {
+		return nil
+	}
+	resp := &TruncationReportResponse{
+		Items:     make([]*TruncationItemResponse, 0, len(r.Items)),
+		CutChars:  r.CutChars,
+		CutTokens: r.CutTokens,
+	}
+	for _, it := range r.Items {
+		resp.Items = append(resp.Items, &TruncationItemResponse{
+			Source:        string(it.Source),
+			Name:          it.Name,
+			OriginalChars: it.OriginalChars,
+			KeptChars:     it.KeptChars,
+			CutChars:      it.CutChars,
+			CutTokens:     it.CutTokens,
+			Dropped:       it.Dropped,
+		})
+	}
+	return resp
 }
 
 // JobListResponse 任务列表响应
@@ -38,6 +121,84 @@ type JobListResponse struct {
 	Jobs []*JobResponse `json:"jobs"`
 }
 
+// JobEventResponse 任务时间线事件响应
+type JobEventResponse struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Message   string                 `json:"message,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// JobEventListResponse 任务时间线响应
+type JobEventListResponse struct {
+	JobID    string              `json:"job_id"`
+	Status   string              `json:"status"`
+	Progress int                 `json:"progress"`
+	Events   []*JobEventResponse `json:"events"`
+}
+
+// MaxBatchJobStatusIDs 批量查询任务状态的单次 ID 上限
+const MaxBatchJobStatusIDs = 100
+
+// BatchJobStatusRequest 批量查询任务状态请求
+type BatchJobStatusRequest struct {
+	JobIDs []string `json:"job_ids" binding:"required,min=1,max=100,dive,uuid"`
+}
+
+// JobStatusResponse 任务状态摘要（轮询用，不含输入参数与结果）
+type JobStatusResponse struct {
+	ID          string     `json:"id"`
+	ProjectID   string     `json:"project_id"`
+	ChapterID   *string    `json:"chapter_id,omitempty"`
+	JobType     string     `json:"job_type"`
+	Category    string     `json:"category"`
+	Status      string     `json:"status"`
+	Progress    int        `json:"progress"`
+	ErrorCode   string     `json:"error_code,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BatchJobStatusResponse 批量任务状态响应：jobs 按请求顺序排列（去重），不存在或无权访问的 ID 列入 not_found
+type BatchJobStatusResponse struct {
+	Jobs     []*JobStatusResponse `json:"jobs"`
+	NotFound []string             `json:"not_found,omitempty"`
+}
+
+// ToJobStatusResponse 将任务实体转换为状态摘要
+func ToJobStatusResponse(j *entity.GenerationJob) *JobStatusResponse {
+	if j == nil {
+		return nil
+	}
+	return &JobStatusResponse{
+		ID:          j.ID,
+		ProjectID:   j.ProjectID,
+		ChapterID:   j.ChapterID,
+		JobType:     string(j.JobType),
+		Category:    string(j.Category),
+		Status:      string(j.Status),
+		Progress:    j.Progress,
+		ErrorCode:   string(j.ErrorCode),
+		StartedAt:   j.StartedAt,
+		CompletedAt: j.CompletedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
+
+// UpdateJobPriorityRequest 调整任务优先级请求
+type UpdateJobPriorityRequest struct {
+	Priority int `json:"priority" binding:"required,gte=1,lte=10"`
+}
+
+// UpdateJobPriorityResponse 调整任务优先级响应
+type UpdateJobPriorityResponse struct {
+	*JobResponse
+	// Requeued 任务消息是否已移入新优先级对应的流（同档位内调整时仅更新记录）
+	Requeued bool `json:"requeued"`
+}
+
 // CancelJobResponse 取消任务响应
 type CancelJobResponse struct {
 	ID        string `json:"id"`
@@ -55,15 +216,20 @@ func ToJobResponse(j *entity.GenerationJob) *JobResponse {
 		ProjectID:        j.ProjectID,
 		ChapterID:        j.ChapterID,
 		JobType:          string(j.JobType),
+		Category:         string(j.Category),
 		Status:           string(j.Status),
 		Priority:         j.Priority,
+		PriorityClass:    entity.PriorityClass(j.Priority),
 		LLMProvider:      j.LLMProvider,
 		LLMModel:         j.LLMModel,
 		TokensPrompt:     j.TokensPrompt,
 		TokensCompletion: j.TokensComplete,
 		DurationMs:       j.DurationMs,
 		ErrorMsg:         j.ErrorMessage,
+		ErrorCode:        string(j.ErrorCode),
 		RetryCount:       j.RetryCount,
+		Attempts:         j.Attempts,
+		SpentTokens:      j.SpentTokens,
 		Progress:         j.Progress,
 		CreatedAt:        j.CreatedAt,
 		UpdatedAt:        j.UpdatedAt,
@@ -72,6 +238,13 @@ func ToJobResponse(j *entity.GenerationJob) *JobResponse {
 	if j.StartedAt != nil {
 		resp.StartedAt = *j.StartedAt
 	}
+	if len(j.Warnings) > 0 {
+		resp.Warnings = make([]*JobWarningResponse, 0, len(j.Warnings))
+		for _, w := range j.Warnings {
+			resp.Warnings = append(resp.Warnings, &JobWarningResponse{Code: string(w.Code), Message: w.Message, CreatedAt: w.CreatedAt})
+		}
+	}
+	resp.Truncation = ToTruncationReportResponse(j.Truncation)
 	if j.CompletedAt != nil {
 		resp.CompletedAt = *j.CompletedAt
 	}
@@ -104,3 +277,116 @@ func ToJobListResponse(jobs []*entity.GenerationJob) *JobListResponse {
 
 	return resp
 }
+
+// ToJobEventListResponse 将任务及其时间线事件转换为响应 DTO
+func ToJobEventListResponse(job *entity.GenerationJob, events []*entity.JobEvent) *JobEventListResponse {
+	resp := &JobEventListResponse{
+		JobID:    job.ID,
+		Status:   string(job.Status),
+		Progress: job.Progress,
+		Events:   make([]*JobEventResponse, 0, len(events)),
+	}
+
+	for _, e := range events {
+		resp.Events = append(resp.Events, &JobEventResponse{
+			ID:        e.ID,
+			Type:      string(e.Type),
+			Message:   e.Message,
+			Data:      e.Data,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+// ReplayJobRequest 生成回放请求：未提供的字段沿用任务记录的输入快照
+type ReplayJobRequest struct {
+	ProjectTitle       *string  `json:"project_title,omitempty" binding:"omitempty,max=255"`
+	ProjectDescription *string  `json:"project_description,omitempty" binding:"omitempty,max=10000"`
+	ChapterTitle       *string  `json:"chapter_title,omitempty" binding:"omitempty,max=255"`
+	ChapterOutline     *string  `json:"chapter_outline,omitempty" binding:"omitempty,max=10000"`
+	RetrievedContext   *string  `json:"retrieved_context,omitempty" binding:"omitempty,max=50000"`
+	ActiveWorldview    *string  `json:"active_worldview,omitempty" binding:"omitempty,max=20000"`
+	ActiveCharacters   *string  `json:"active_characters,omitempty" binding:"omitempty,max=20000"`
+	TargetWordCount    *int     `json:"target_word_count,omitempty" binding:"omitempty,min=100,max=20000"`
+	WritingStyle       *string  `json:"writing_style,omitempty" binding:"omitempty,max=2000"`
+	POV                *string  `json:"pov,omitempty" binding:"omitempty,max=200"`
+	Provider           *string  `json:"provider,omitempty" binding:"omitempty,max=32"`
+	Model              *string  `json:"model,omitempty" binding:"omitempty,max=100"`
+	Temperature        *float32 `json:"temperature,omitempty" binding:"omitempty,min=0,max=2"`
+	MaxTokens          *int     `json:"max_tokens,omitempty" binding:"omitempty,min=1,max=32000"`
+	// PromptSystem / PromptUser 覆盖模板文本（变量语法与内置模板一致，如 {chapter_outline}）
+	PromptSystem *string `json:"prompt_system,omitempty" binding:"omitempty,max=20000"`
+	PromptUser   *string `json:"prompt_user,omitempty" binding:"omitempty,max=20000"`
+	// DryRun 仅渲染 Prompt，不调用模型
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// ApplyTo 将覆盖项写入快照，返回被覆盖的字段名
+func (r *ReplayJobRequest) ApplyTo(s *storychapter.InputSnapshot) []string {
+	var overridden []string
+	setString := func(name string, v *string, dst *string) {
+		if v != nil {
+			*dst = *v
+			overridden = append(overridden, name)
+		}
+	}
+	setString("project_title", r.ProjectTitle, &s.ProjectTitle)
+	setString("project_description", r.ProjectDescription, &s.ProjectDescription)
+	setString("chapter_title", r.ChapterTitle, &s.ChapterTitle)
+	setString("chapter_outline", r.ChapterOutline, &s.ChapterOutline)
+	setString("retrieved_context", r.RetrievedContext, &s.RetrievedContext)
+	setString("active_worldview", r.ActiveWorldview, &s.ActiveWorldview)
+	setString("active_characters", r.ActiveCharacters, &s.ActiveCharacters)
+	setString("writing_style", r.WritingStyle, &s.WritingStyle)
+	setString("pov", r.POV, &s.POV)
+	setString("provider", r.Provider, &s.Provider)
+	setString("model", r.Model, &s.Model)
+	setString("prompt_system", r.PromptSystem, &s.PromptSystem)
+	setString("prompt_user", r.PromptUser, &s.PromptUser)
+	if r.TargetWordCount != nil {
+		s.TargetWordCount = *r.TargetWordCount
+		overridden = append(overridden, "target_word_count")
+	}
+	if r.Temperature != nil {
+		s.Temperature = r.Temperature
+		overridden = append(overridden, "temperature")
+	}
+	if r.MaxTokens != nil {
+		s.MaxTokens = r.MaxTokens
+		overridden = append(overridden, "max_tokens")
+	}
+	return overridden
+}
+
+// ReplayMessage 回放渲染出的 Prompt 消息
+type ReplayMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// JobReplayResponse 生成回放响应（沙箱执行，结果不落库）
+type JobReplayResponse struct {
+	JobID string `json:"job_id"`
+	// RecordedPromptDigest 任务执行时的模板摘要；CurrentPromptDigest 当前内置模板摘要，二者不同说明模板已变更
+	RecordedPromptDigest string `json:"recorded_prompt_digest"`
+	CurrentPromptDigest  string `json:"current_prompt_digest"`
+	// PromptDigest 本次回放实际使用的模板摘要
+	PromptDigest string                      `json:"prompt_digest"`
+	Overridden   []string                    `json:"overridden"`
+	Input        *storychapter.InputSnapshot `json:"input"`
+	Messages     []*ReplayMessage            `json:"messages"`
+	DryRun       bool                        `json:"dry_run"`
+	Content      string                      `json:"content,omitempty"`
+	Usage        *ReplayUsage                `json:"usage,omitempty"`
+}
+
+// ReplayUsage 回放调用的模型用量
+type ReplayUsage struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	DurationMs       int64  `json:"duration_ms"`
+}
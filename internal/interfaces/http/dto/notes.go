@@ -0,0 +1,41 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+// IngestNotesRequest 导入作者笔记请求
+type IngestNotesRequest struct {
+	// Title 笔记标题（用于召回展示）
+	Title string `json:"title" binding:"omitempty,max=255"`
+	// Notes 笔记原文（自由格式，上限 200000 字）
+	Notes string `json:"notes" binding:"required"`
+	// BranchKey 草稿版本写入的分支，默认 notes-import；不允许 main
+	BranchKey string `json:"branch_key,omitempty"`
+
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// IngestedDraftResponse 抽取生成的设定草稿版本（未激活）
+type IngestedDraftResponse struct {
+	ArtifactID      string  `json:"artifact_id"`
+	Type            string  `json:"type"`
+	VersionID       string  `json:"version_id"`
+	VersionNo       int     `json:"version_no"`
+	BranchKey       string  `json:"branch_key"`
+	ParentVersionID *string `json:"parent_version_id,omitempty"`
+	// Passes 抽取轮数（每段笔记一轮）
+	Passes int `json:"passes"`
+}
+
+// IngestNotesResponse 导入作者笔记响应
+type IngestNotesResponse struct {
+	JobID  string `json:"job_id"`
+	NoteID string `json:"note_id"`
+	// Chunks 笔记切分的段数
+	Chunks int `json:"chunks"`
+	// Indexed 笔记是否已写入向量索引（segment_type = notes）
+	Indexed bool                     `json:"indexed"`
+	Drafts  []*IngestedDraftResponse `json:"drafts"`
+	Usage   *FoundationUsageResponse `json:"usage,omitempty"`
+}
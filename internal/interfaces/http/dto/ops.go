@@ -0,0 +1,411 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"z-novel-ai-api/internal/application/ops"
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/application/warmup"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	"z-novel-ai-api/internal/infrastructure/messaging"
+)
+
+// OpsSwitchesResponse 单个作用域的运维开关
+type OpsSwitchesResponse struct {
+	ReadOnly         bool       `json:"read_only"`
+	GenerationPaused bool       `json:"generation_paused"`
+	Message          string     `json:"message,omitempty"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy        string     `json:"updated_by,omitempty"`
+}
+
+// OpsStatusResponse 生效的运维开关（任一作用域开启即生效）
+type OpsStatusResponse struct {
+	ReadOnly         bool                 `json:"read_only"`
+	GenerationPaused bool                 `json:"generation_paused"`
+	Message          string               `json:"message,omitempty"`
+	Global           *OpsSwitchesResponse `json:"global,omitempty"`
+	Tenant           *OpsSwitchesResponse `json:"tenant,omitempty"`
+}
+
+// UpdateOpsSwitchesRequest 设置运维开关请求（全部关闭且无公告时等同于清除）
+type UpdateOpsSwitchesRequest struct {
+	ReadOnly         bool   `json:"read_only"`
+	GenerationPaused bool   `json:"generation_paused"`
+	Message          string `json:"message" binding:"omitempty,max=500"`
+}
+
+// ToEntity 转换为运维开关
+func (r *UpdateOpsSwitchesRequest) ToEntity(userID string) *ops.Switches {
+	return &ops.Switches{
+		ReadOnly:         r.ReadOnly,
+		GenerationPaused: r.GenerationPaused,
+		Message:          r.Message,
+		UpdatedAt:        time.Now(),
+		UpdatedBy:        userID,
+	}
+}
+
+// ToOpsSwitchesResponse 转换运维开关（未设置时返回 nil）
+func ToOpsSwitchesResponse(sw *ops.Switches) *OpsSwitchesResponse {
+	if sw == nil {
+		return nil
+	}
+	resp := &OpsSwitchesResponse{
+		ReadOnly:         sw.ReadOnly,
+		GenerationPaused: sw.GenerationPaused,
+		Message:          sw.Message,
+		UpdatedBy:        sw.UpdatedBy,
+	}
+	if !sw.UpdatedAt.IsZero() {
+		updatedAt := sw.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+// ToOpsStatusResponse 合并全局与租户级开关
+func ToOpsStatusResponse(global, tenant *ops.Switches) *OpsStatusResponse {
+	eff := ops.Merge(global, tenant)
+	return &OpsStatusResponse{
+		ReadOnly:         eff.ReadOnly,
+		GenerationPaused: eff.GenerationPaused,
+		Message:          eff.Message,
+		Global:           ToOpsSwitchesResponse(global),
+		Tenant:           ToOpsSwitchesResponse(tenant),
+	}
+}
+
+// OpsCircuitBreakerResponse 单个 提供商 + 模型 的熔断状态
+type OpsCircuitBreakerResponse struct {
+	Model       string     `json:"model"`
+	State       string     `json:"state"` // closed/half_open/open
+	Requests    int        `json:"requests"`
+	Failures    int        `json:"failures"`
+	FailureRate float64    `json:"failure_rate"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	RetryAt     *time.Time `json:"retry_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// OpsProviderResponse LLM 提供商配置摘要（不含密钥与地址）
+type OpsProviderResponse struct {
+	Name      string                      `json:"name"`
+	Type      string                      `json:"type"`
+	Model     string                      `json:"model"`
+	Default   bool                        `json:"default"`
+	Fallbacks []string                    `json:"fallbacks"`
+	Breakers  []OpsCircuitBreakerResponse `json:"breakers"`
+}
+
+// OpsProvidersResponse LLM 提供商列表
+type OpsProvidersResponse struct {
+	CircuitBreakerEnabled bool                  `json:"circuit_breaker_enabled"`
+	Providers             []OpsProviderResponse `json:"providers"`
+}
+
+// ToOpsProvidersResponse 转换提供商状态
+func ToOpsProvidersResponse(enabled bool, providers []llm.ProviderStatus) *OpsProvidersResponse {
+	resp := &OpsProvidersResponse{
+		CircuitBreakerEnabled: enabled,
+		Providers:             make([]OpsProviderResponse, 0, len(providers)),
+	}
+	for _, p := range providers {
+		item := OpsProviderResponse{
+			Name:      p.Name,
+			Type:      p.Type,
+			Model:     p.Model,
+			Default:   p.Default,
+			Fallbacks: p.Fallbacks,
+			Breakers:  make([]OpsCircuitBreakerResponse, 0, len(p.Breakers)),
+		}
+		for _, b := range p.Breakers {
+			item.Breakers = append(item.Breakers, OpsCircuitBreakerResponse{
+				Model:       b.Model,
+				State:       string(b.State),
+				Requests:    b.Requests,
+				Failures:    b.Failures,
+				FailureRate: b.FailureRate,
+				OpenedAt:    b.OpenedAt,
+				RetryAt:     b.RetryAt,
+				LastError:   b.LastError,
+			})
+		}
+		resp.Providers = append(resp.Providers, item)
+	}
+	return resp
+}
+
+// OpsQueueResponse 队列实时状态
+type OpsQueueResponse struct {
+	Name      string `json:"name"` // 简写名称（如 story-gen），用于死信接口路径
+	Stream    string `json:"stream"`
+	Group     string `json:"group,omitempty"`
+	Length    int64  `json:"length"`
+	Lag       int64  `json:"lag"`
+	Pending   int64  `json:"pending"`
+	Consumers int64  `json:"consumers"`
+	DLQLength int64  `json:"dlq_length"`
+
+	// Worker 上报的统计（未上报或已过期时省略）
+	Workers      *int       `json:"workers,omitempty"`
+	AvgJobMillis *int64     `json:"avg_job_ms,omitempty"`
+	StatsAt      *time.Time `json:"stats_updated_at,omitempty"`
+}
+
+// ToOpsQueueResponse 转换队列状态
+func ToOpsQueueResponse(q *messaging.QueueInfo) *OpsQueueResponse {
+	resp := &OpsQueueResponse{
+		Name:      q.Stream.Name(),
+		Stream:    string(q.Stream),
+		Group:     string(q.Group),
+		Length:    q.Length,
+		Lag:       q.Lag,
+		Pending:   q.Pending,
+		Consumers: q.Consumers,
+		DLQLength: q.DLQLength,
+	}
+	if q.Stats != nil {
+		resp.Workers = &q.Stats.Workers
+		resp.AvgJobMillis = &q.Stats.AvgJobMillis
+		resp.StatsAt = &q.Stats.UpdatedAt
+	}
+	return resp
+}
+
+// OpsDLQMessageResponse 死信消息
+type OpsDLQMessageResponse struct {
+	ID             string            `json:"id"`
+	OriginalStream string            `json:"original_stream"`
+	MessageID      string            `json:"message_id,omitempty"` // 原消息 ID（生成类任务即任务 ID）
+	Type           string            `json:"type,omitempty"`
+	TenantID       string            `json:"tenant_id,omitempty"`
+	ProjectID      string            `json:"project_id,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	FailedAt       *time.Time        `json:"failed_at,omitempty"`
+	RetryCount     int               `json:"retry_count,omitempty"`
+}
+
+// ToOpsDLQMessageResponse 转换死信消息
+func ToOpsDLQMessageResponse(e *messaging.DLQEntry) *OpsDLQMessageResponse {
+	resp := &OpsDLQMessageResponse{
+		ID:             e.ID,
+		OriginalStream: string(e.OriginalStream),
+		Error:          e.Error,
+		RetryCount:     e.RetryCount,
+	}
+	if m := e.Message; m != nil {
+		resp.MessageID = m.ID
+		resp.Type = m.Type
+		resp.TenantID = m.TenantID
+		resp.ProjectID = m.ProjectID
+		resp.Metadata = m.Metadata
+	}
+	if !e.FailedAt.IsZero() {
+		resp.FailedAt = &e.FailedAt
+	}
+	return resp
+}
+
+// RequeueDLQRequest 死信重新入队请求
+type RequeueDLQRequest struct {
+	// IDs 死信消息 ID；为空时重新入队队首的全部（单次最多 1000 条）
+	IDs []string `json:"ids,omitempty" binding:"max=1000"`
+}
+
+// RequeueDLQResponse 死信重新入队结果
+type RequeueDLQResponse struct {
+	Requeued []string `json:"requeued"`
+}
+
+// OpsDLQMessageDetailResponse 死信消息详情（含原消息载荷）
+type OpsDLQMessageDetailResponse struct {
+	OpsDLQMessageResponse
+	Version   int             `json:"version,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+}
+
+// ToOpsDLQMessageDetailResponse 转换死信消息详情
+func ToOpsDLQMessageDetailResponse(e *messaging.DLQEntry) *OpsDLQMessageDetailResponse {
+	resp := &OpsDLQMessageDetailResponse{OpsDLQMessageResponse: *ToOpsDLQMessageResponse(e)}
+	if m := e.Message; m != nil {
+		resp.Version = m.Version
+		resp.Payload = m.Payload
+		if !m.CreatedAt.IsZero() {
+			resp.CreatedAt = &m.CreatedAt
+		}
+	}
+	return resp
+}
+
+// PurgeDLQRequest 死信清除请求
+type PurgeDLQRequest struct {
+	// IDs 死信消息 ID；为空时清空整个死信队列
+	IDs []string `json:"ids,omitempty" binding:"max=1000"`
+}
+
+// PurgeDLQResponse 死信清除结果
+type PurgeDLQResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// OpsPendingMessageResponse 处理中/等待重试的消息
+type OpsPendingMessageResponse struct {
+	ID         string `json:"id"`
+	Consumer   string `json:"consumer"`
+	IdleMs     int64  `json:"idle_ms"`
+	RetryCount int    `json:"retry_count"`
+	// RetryLimit 投递次数达到该值后移入死信队列
+	RetryLimit int    `json:"retry_limit"`
+	MessageID  string `json:"message_id,omitempty"`
+	Type       string `json:"type,omitempty"`
+	TenantID   string `json:"tenant_id,omitempty"`
+	ProjectID  string `json:"project_id,omitempty"`
+}
+
+// ToOpsPendingMessageResponse 转换处理中的消息
+func ToOpsPendingMessageResponse(p *messaging.PendingEntry, retryLimit int) *OpsPendingMessageResponse {
+	resp := &OpsPendingMessageResponse{
+		ID:         p.ID,
+		Consumer:   p.Consumer,
+		IdleMs:     p.Idle.Milliseconds(),
+		RetryCount: p.RetryCount,
+		RetryLimit: retryLimit,
+	}
+	if m := p.Message; m != nil {
+		resp.MessageID = m.ID
+		resp.Type = m.Type
+		resp.TenantID = m.TenantID
+		resp.ProjectID = m.ProjectID
+	}
+	return resp
+}
+
+// AdjustBalanceRequest 调整租户 Token 余额请求
+type AdjustBalanceRequest struct {
+	// Delta 调整量：正数增加，负数扣减（扣减后余额不能为负）
+	Delta int64 `json:"delta" binding:"required"`
+	// Reason 调整原因（记入日志）
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// AdjustBalanceResponse 调整租户 Token 余额结果
+type AdjustBalanceResponse struct {
+	TenantID     string `json:"tenant_id"`
+	Delta        int64  `json:"delta"`
+	TokenBalance int64  `json:"token_balance"`
+}
+
+// GenerationIssueResponse 生成任务与章节状态的一条不一致记录
+type GenerationIssueResponse struct {
+	// Kind stale_generating（章节卡在 generating 且无活跃任务）/ active_job_not_generating（活跃任务的章节不在 generating）
+	Kind           string `json:"kind"`
+	ProjectID      string `json:"project_id"`
+	ChapterID      string `json:"chapter_id"`
+	JobID          string `json:"job_id,omitempty"`
+	JobStatus      string `json:"job_status,omitempty"`
+	ChapterStatus  string `json:"chapter_status,omitempty"`
+	ExpectedStatus string `json:"expected_status"`
+	Repaired       bool   `json:"repaired"`
+}
+
+// GenerationConsistencyResponse 任务/章节状态巡检结果
+type GenerationConsistencyResponse struct {
+	TenantID  string                     `json:"tenant_id"`
+	CheckedAt time.Time                  `json:"checked_at"`
+	Repaired  int                        `json:"repaired"`
+	Issues    []*GenerationIssueResponse `json:"issues"`
+}
+
+// ToGenerationConsistencyResponse 转换巡检结果
+func ToGenerationConsistencyResponse(r *appstory.GenerationConsistencyReport) *GenerationConsistencyResponse {
+	resp := &GenerationConsistencyResponse{
+		TenantID:  r.TenantID,
+		CheckedAt: r.CheckedAt,
+		Repaired:  r.Repaired(),
+		Issues:    make([]*GenerationIssueResponse, 0, len(r.Issues)),
+	}
+	for _, issue := range r.Issues {
+		resp.Issues = append(resp.Issues, &GenerationIssueResponse{
+			Kind:           string(issue.Kind),
+			ProjectID:      issue.ProjectID,
+			ChapterID:      issue.ChapterID,
+			JobID:          issue.JobID,
+			JobStatus:      string(issue.JobStatus),
+			ChapterStatus:  string(issue.ChapterStatus),
+			ExpectedStatus: string(issue.ExpectedStatus),
+			Repaired:       issue.Repaired,
+		})
+	}
+	return resp
+}
+
+// WarmupStepResponse 预热步骤结果
+type WarmupStepResponse struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// WarmupResponse 预热结果（仅反映处理本次请求的网关实例）
+type WarmupResponse struct {
+	Enabled    bool                  `json:"enabled"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	DurationMs int64                 `json:"duration_ms"`
+	OK         bool                  `json:"ok"`
+	Steps      []*WarmupStepResponse `json:"steps"`
+}
+
+// ToWarmupResponse 转换预热结果（report 为 nil 表示本实例尚未执行预热）
+func ToWarmupResponse(enabled bool, report *warmup.Report) *WarmupResponse {
+	resp := &WarmupResponse{Enabled: enabled, Steps: []*WarmupStepResponse{}}
+	if report == nil {
+		return resp
+	}
+	startedAt := report.StartedAt
+	resp.StartedAt = &startedAt
+	resp.DurationMs = report.DurationMs
+	resp.OK = report.OK
+	for _, s := range report.Steps {
+		resp.Steps = append(resp.Steps, &WarmupStepResponse{Name: s.Name, DurationMs: s.DurationMs, Skipped: s.Skipped, Error: s.Error})
+	}
+	return resp
+}
+
+// FoundationPlanLimitsRequest 租户设定集规模上限覆盖（整体替换；0 表示该维度沿用全局配置，全部为 0 等同于清除）
+type FoundationPlanLimitsRequest struct {
+	MaxEntities     int `json:"max_entities" binding:"min=0"`
+	MaxRelations    int `json:"max_relations" binding:"min=0"`
+	MaxVolumes      int `json:"max_volumes" binding:"min=0"`
+	MaxChapters     int `json:"max_chapters" binding:"min=0"`
+	MaxOutlineChars int `json:"max_outline_chars" binding:"min=0"`
+}
+
+// ToEntity 转换为租户覆盖（全部为 0 时返回 nil）
+func (r *FoundationPlanLimitsRequest) ToEntity() *entity.FoundationPlanLimits {
+	limits := entity.FoundationPlanLimits{
+		MaxEntities:     r.MaxEntities,
+		MaxRelations:    r.MaxRelations,
+		MaxVolumes:      r.MaxVolumes,
+		MaxChapters:     r.MaxChapters,
+		MaxOutlineChars: r.MaxOutlineChars,
+	}
+	if limits.IsZero() {
+		return nil
+	}
+	return &limits
+}
+
+// FoundationPlanLimitsResponse 租户设定集规模上限：defaults 为全局配置，override 为租户覆盖，effective 为生效值（0 表示不限制）
+type FoundationPlanLimitsResponse struct {
+	TenantID  string                       `json:"tenant_id"`
+	Defaults  entity.FoundationPlanLimits  `json:"defaults"`
+	Override  *entity.FoundationPlanLimits `json:"override,omitempty"`
+	Effective entity.FoundationPlanLimits  `json:"effective"`
+}
@@ -2,8 +2,10 @@
 package dto
 
 import (
+	"strings"
 	"time"
 
+	"z-novel-ai-api/internal/application/story/health"
 	"z-novel-ai-api/internal/domain/entity"
 )
 
@@ -34,6 +36,33 @@ type ProjectSettingsRequest struct {
 	WritingStyle         string  `json:"writing_style,omitempty"`
 	POV                  string  `json:"pov,omitempty"`
 	Temperature          float64 `json:"temperature,omitempty"`
+
+	// POVStyles 按 POV 角色（实体 ID）覆盖写作风格；更新时按 key 合并，字段全空表示移除该角色设置
+	POVStyles map[string]*POVStyleSettings `json:"pov_styles,omitempty" binding:"omitempty,dive"`
+
+	// SeriesRetrieval 是否在生成时只读召回同系列前作内容
+	SeriesRetrieval *bool `json:"series_retrieval,omitempty"`
+
+	// PublicRead 是否通过公开只读 API 对外发布已完成章节
+	PublicRead *bool `json:"public_read,omitempty"`
+
+	// Provider / Model 项目默认 LLM（章节生成未指定时使用）
+	Provider string `json:"provider,omitempty" binding:"omitempty,max=32"`
+	Model    string `json:"model,omitempty" binding:"omitempty,max=64"`
+	// RetrievalTopK 章节生成前召回的片段数
+	RetrievalTopK int `json:"retrieval_top_k,omitempty" binding:"omitempty,min=1,max=50"`
+	// RetrievalNamespaces 章节召回的命名空间配额（prose / summaries / entity_cards / settings / notes），提供时整体替换，传 {} 清除
+	RetrievalNamespaces map[string]int `json:"retrieval_namespaces,omitempty" binding:"omitempty,max=5,dive,keys,oneof=prose summaries entity_cards settings notes,endkeys,min=1,max=50"`
+	// ArtifactActivation 构件新版本自动激活策略：manual / auto_main / auto_on_clean_scan
+	ArtifactActivation string `json:"artifact_activation,omitempty" binding:"omitempty,oneof=manual auto_main auto_on_clean_scan"`
+	// OutlineRefresh 激活的大纲条目变化后是否自动提交大纲刷新任务（outline_refresh）
+	OutlineRefresh *bool `json:"outline_refresh,omitempty"`
+}
+
+// POVStyleSettings POV 角色专属写作设置
+type POVStyleSettings struct {
+	WritingStyle string `json:"writing_style,omitempty" binding:"max=2000"`
+	POV          string `json:"pov,omitempty" binding:"max=2000"`
 }
 
 // WorldSettingsRequest 世界观设置请求
@@ -54,6 +83,8 @@ type ProjectResponse struct {
 	TargetWordCount  int                      `json:"target_word_count,omitempty"`
 	CurrentWordCount int                      `json:"current_word_count"`
 	Status           string                   `json:"status"`
+	SeriesID         string                   `json:"series_id,omitempty"`
+	SeriesOrder      int                      `json:"series_order,omitempty"`
 	Settings         *ProjectSettingsResponse `json:"settings,omitempty"`
 	WorldSettings    *WorldSettingsResponse   `json:"world_settings,omitempty"`
 	CreatedAt        time.Time                `json:"created_at"`
@@ -62,10 +93,19 @@ type ProjectResponse struct {
 
 // ProjectSettingsResponse 项目设置响应
 type ProjectSettingsResponse struct {
-	DefaultChapterLength int     `json:"default_chapter_length,omitempty"`
-	WritingStyle         string  `json:"writing_style,omitempty"`
-	POV                  string  `json:"pov,omitempty"`
-	Temperature          float64 `json:"temperature,omitempty"`
+	DefaultChapterLength int                          `json:"default_chapter_length,omitempty"`
+	WritingStyle         string                       `json:"writing_style,omitempty"`
+	POV                  string                       `json:"pov,omitempty"`
+	Temperature          float64                      `json:"temperature,omitempty"`
+	POVStyles            map[string]*POVStyleSettings `json:"pov_styles,omitempty"`
+	SeriesRetrieval      bool                         `json:"series_retrieval,omitempty"`
+	PublicRead           bool                         `json:"public_read,omitempty"`
+	Provider             string                       `json:"provider,omitempty"`
+	Model                string                       `json:"model,omitempty"`
+	RetrievalTopK        int                          `json:"retrieval_top_k,omitempty"`
+	RetrievalNamespaces  map[string]int               `json:"retrieval_namespaces,omitempty"`
+	ArtifactActivation   string                       `json:"artifact_activation,omitempty"`
+	OutlineRefresh       bool                         `json:"outline_refresh,omitempty"`
 }
 
 // WorldSettingsResponse 世界观设置响应
@@ -96,17 +136,16 @@ func ToProjectResponse(p *entity.Project) *ProjectResponse {
 		TargetWordCount:  p.TargetWordCount,
 		CurrentWordCount: p.CurrentWordCount,
 		Status:           string(p.Status),
+		SeriesOrder:      p.SeriesOrder,
 		CreatedAt:        p.CreatedAt,
 		UpdatedAt:        p.UpdatedAt,
 	}
+	if p.SeriesID != nil {
+		resp.SeriesID = *p.SeriesID
+	}
 
 	if p.Settings != nil {
-		resp.Settings = &ProjectSettingsResponse{
-			DefaultChapterLength: p.Settings.DefaultChapterLength,
-			WritingStyle:         p.Settings.WritingStyle,
-			POV:                  p.Settings.POV,
-			Temperature:          p.Settings.Temperature,
-		}
+		resp.Settings = ToProjectSettingsResponse(p.Settings)
 	}
 
 	if p.WorldSettings != nil {
@@ -120,6 +159,61 @@ func ToProjectResponse(p *entity.Project) *ProjectResponse {
 	return resp
 }
 
+// ToProjectSettingsResponse 将项目设置转换为响应 DTO
+func ToProjectSettingsResponse(s *entity.ProjectSettings) *ProjectSettingsResponse {
+	if s == nil {
+		return &ProjectSettingsResponse{}
+	}
+	resp := &ProjectSettingsResponse{
+		DefaultChapterLength: s.DefaultChapterLength,
+		WritingStyle:         s.WritingStyle,
+		POV:                  s.POV,
+		Temperature:          s.Temperature,
+		SeriesRetrieval:      s.SeriesRetrieval,
+		PublicRead:           s.PublicRead,
+		Provider:             s.Provider,
+		Model:                s.Model,
+		RetrievalTopK:        s.RetrievalTopK,
+		RetrievalNamespaces:  s.RetrievalNamespaces,
+		ArtifactActivation:   string(s.ArtifactActivation),
+		OutlineRefresh:       s.OutlineRefresh,
+	}
+	if len(s.POVStyles) > 0 {
+		resp.POVStyles = make(map[string]*POVStyleSettings, len(s.POVStyles))
+		for id, ps := range s.POVStyles {
+			if ps == nil {
+				continue
+			}
+			resp.POVStyles[id] = &POVStyleSettings{
+				WritingStyle: ps.WritingStyle,
+				POV:          ps.POV,
+			}
+		}
+	}
+	return resp
+}
+
+// applyPOVStyles 按 key 合并 POV 风格设置；字段全空的条目视为删除
+func applyPOVStyles(s *entity.ProjectSettings, styles map[string]*POVStyleSettings) {
+	for id, ps := range styles {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if ps == nil || (strings.TrimSpace(ps.WritingStyle) == "" && strings.TrimSpace(ps.POV) == "") {
+			delete(s.POVStyles, id)
+			continue
+		}
+		if s.POVStyles == nil {
+			s.POVStyles = make(map[string]*entity.POVStyle)
+		}
+		s.POVStyles[id] = &entity.POVStyle{
+			WritingStyle: strings.TrimSpace(ps.WritingStyle),
+			POV:          strings.TrimSpace(ps.POV),
+		}
+	}
+}
+
 // ToProjectListResponse 将领域实体列表转换为响应 DTO
 func ToProjectListResponse(projects []*entity.Project) *ProjectListResponse {
 	resp := &ProjectListResponse{
@@ -133,20 +227,16 @@ func ToProjectListResponse(projects []*entity.Project) *ProjectListResponse {
 	return resp
 }
 
-// ToProjectEntity 将请求 DTO 转换为领域实体
-func (r *CreateProjectRequest) ToProjectEntity(tenantID, ownerID string) *entity.Project {
+// ToProjectEntity 将请求 DTO 转换为领域实体：设置以租户默认模板为基础，请求中显式提供的字段覆盖模板值
+func (r *CreateProjectRequest) ToProjectEntity(tenantID, ownerID string, defaults *entity.ProjectSettings) *entity.Project {
 	project := entity.NewProject(tenantID, ownerID, r.Title)
 	project.Description = r.Description
 	project.Genre = r.Genre
 	project.TargetWordCount = r.TargetWordCount
+	project.Settings = entity.NewProjectSettingsFromTemplate(defaults)
 
 	if r.Settings != nil {
-		project.Settings = &entity.ProjectSettings{
-			DefaultChapterLength: r.Settings.DefaultChapterLength,
-			WritingStyle:         r.Settings.WritingStyle,
-			POV:                  r.Settings.POV,
-			Temperature:          r.Settings.Temperature,
-		}
+		r.Settings.applyTo(project.Settings)
 	}
 
 	if r.WorldSettings != nil {
@@ -160,6 +250,50 @@ func (r *CreateProjectRequest) ToProjectEntity(tenantID, ownerID string) *entity
 	return project
 }
 
+// applyTo 将请求中提供的设置合并到项目设置（零值字段保持不变）
+func (r *ProjectSettingsRequest) applyTo(s *entity.ProjectSettings) {
+	if r.DefaultChapterLength > 0 {
+		s.DefaultChapterLength = r.DefaultChapterLength
+	}
+	if r.WritingStyle != "" {
+		s.WritingStyle = r.WritingStyle
+	}
+	if r.POV != "" {
+		s.POV = r.POV
+	}
+	if r.Temperature > 0 {
+		s.Temperature = r.Temperature
+	}
+	if r.SeriesRetrieval != nil {
+		s.SeriesRetrieval = *r.SeriesRetrieval
+	}
+	if r.PublicRead != nil {
+		s.PublicRead = *r.PublicRead
+	}
+	if v := strings.TrimSpace(r.Provider); v != "" {
+		s.Provider = v
+	}
+	if v := strings.TrimSpace(r.Model); v != "" {
+		s.Model = v
+	}
+	if r.RetrievalTopK > 0 {
+		s.RetrievalTopK = r.RetrievalTopK
+	}
+	if r.RetrievalNamespaces != nil {
+		s.RetrievalNamespaces = nil
+		if len(r.RetrievalNamespaces) > 0 {
+			s.RetrievalNamespaces = r.RetrievalNamespaces
+		}
+	}
+	if r.ArtifactActivation != "" {
+		s.ArtifactActivation = entity.ArtifactActivationPolicy(r.ArtifactActivation)
+	}
+	if r.OutlineRefresh != nil {
+		s.OutlineRefresh = *r.OutlineRefresh
+	}
+	applyPOVStyles(s, r.POVStyles)
+}
+
 // ApplyToProject 将更新请求应用到项目实体
 func (r *UpdateProjectRequest) ApplyToProject(p *entity.Project) {
 	if r.Title != nil {
@@ -182,18 +316,7 @@ func (r *UpdateProjectRequest) ApplyToProject(p *entity.Project) {
 		if p.Settings == nil {
 			p.Settings = &entity.ProjectSettings{}
 		}
-		if r.Settings.DefaultChapterLength > 0 {
-			p.Settings.DefaultChapterLength = r.Settings.DefaultChapterLength
-		}
-		if r.Settings.WritingStyle != "" {
-			p.Settings.WritingStyle = r.Settings.WritingStyle
-		}
-		if r.Settings.POV != "" {
-			p.Settings.POV = r.Settings.POV
-		}
-		if r.Settings.Temperature > 0 {
-			p.Settings.Temperature = r.Settings.Temperature
-		}
+		r.Settings.applyTo(p.Settings)
 	}
 
 	if r.WorldSettings != nil {
@@ -213,3 +336,13 @@ func (r *UpdateProjectRequest) ApplyToProject(p *entity.Project) {
 
 	p.UpdatedAt = time.Now()
 }
+
+// ProjectHealthResponse 项目健康度响应
+type ProjectHealthResponse struct {
+	*health.Report
+}
+
+// ToProjectHealthResponse 转换为项目健康度响应
+func ToProjectHealthResponse(report *health.Report) *ProjectHealthResponse {
+	return &ProjectHealthResponse{Report: report}
+}
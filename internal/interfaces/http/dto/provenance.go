@@ -0,0 +1,49 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/internal/application/provenance"
+)
+
+// VerifyProvenanceRequest 来源标记校验请求
+type VerifyProvenanceRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// ProvenanceVerificationResponse 来源标记校验响应
+type ProvenanceVerificationResponse struct {
+	// ProducedByThisSystem 存在签名有效的水印，即文本由本系统导出
+	ProducedByThisSystem bool `json:"produced_by_this_system"`
+	// Watermarked 存在本系统格式的水印（签名未必有效，可能被篡改或来自其他部署）
+	Watermarked bool       `json:"watermarked"`
+	Methods     []string   `json:"methods,omitempty"`
+	IssuedAt    *time.Time `json:"issued_at,omitempty"`
+	// ProjectID 仅当水印属于当前租户时返回
+	ProjectID string `json:"project_id,omitempty"`
+	// ContentIntact 正文与导出时一致（仅元数据块可判断）
+	ContentIntact  *bool `json:"content_intact,omitempty"`
+	ZeroWidthMarks int   `json:"zero_width_marks,omitempty"`
+}
+
+// ToProvenanceVerificationResponse 转换为校验响应；来源项目仅对所属租户可见
+func ToProvenanceVerificationResponse(v *provenance.Verification, tenantID string) *ProvenanceVerificationResponse {
+	resp := &ProvenanceVerificationResponse{
+		ProducedByThisSystem: v.Valid,
+		Watermarked:          v.Watermarked,
+		ContentIntact:        v.ContentIntact,
+		ZeroWidthMarks:       v.ZeroWidthMarks,
+	}
+	for _, m := range v.Methods {
+		resp.Methods = append(resp.Methods, string(m))
+	}
+	if v.Marker != nil {
+		issuedAt := v.Marker.IssuedAt
+		resp.IssuedAt = &issuedAt
+		if v.Marker.TenantID == tenantID {
+			resp.ProjectID = v.Marker.ProjectID
+		}
+	}
+	return resp
+}
@@ -0,0 +1,102 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// PublicProjectResponse 公开项目响应（仅包含可对外展示的字段）
+type PublicProjectResponse struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	Genre        string    `json:"genre,omitempty"`
+	Status       string    `json:"status"`
+	WordCount    int       `json:"word_count"`
+	ChapterCount int       `json:"chapter_count"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PublicChapterSummary 公开章节目录项
+type PublicChapterSummary struct {
+	ID        string    `json:"id"`
+	VolumeID  string    `json:"volume_id,omitempty"`
+	SeqNum    int       `json:"seq_num"`
+	DisplayNo int       `json:"display_no"`
+	Title     string    `json:"title,omitempty"`
+	Summary   string    `json:"summary,omitempty"`
+	WordCount int       `json:"word_count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PublicChapterListResponse 公开章节目录响应
+type PublicChapterListResponse struct {
+	Items []*PublicChapterSummary `json:"items"`
+}
+
+// PublicChapterResponse 公开章节正文响应
+type PublicChapterResponse struct {
+	PublicChapterSummary
+	Content string `json:"content"`
+}
+
+// ToPublicProjectResponse 实体转换为公开项目响应
+func ToPublicProjectResponse(p *entity.Project, chapters []*entity.Chapter) *PublicProjectResponse {
+	if p == nil {
+		return nil
+	}
+	resp := &PublicProjectResponse{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		Genre:        p.Genre,
+		Status:       string(p.Status),
+		ChapterCount: len(chapters),
+		UpdatedAt:    p.UpdatedAt,
+	}
+	for _, ch := range chapters {
+		resp.WordCount += ch.WordCount
+	}
+	return resp
+}
+
+// ToPublicChapterSummary 实体转换为公开章节目录项
+func ToPublicChapterSummary(ch *entity.Chapter) *PublicChapterSummary {
+	if ch == nil {
+		return nil
+	}
+	return &PublicChapterSummary{
+		ID:        ch.ID,
+		VolumeID:  ch.VolumeID,
+		SeqNum:    ch.SeqNum,
+		DisplayNo: ch.DisplayNumber(),
+		Title:     ch.Title,
+		Summary:   ch.Summary,
+		WordCount: ch.WordCount,
+		UpdatedAt: ch.UpdatedAt,
+	}
+}
+
+// ToPublicChapterListResponse 实体列表转换为公开章节目录响应
+func ToPublicChapterListResponse(chapters []*entity.Chapter) *PublicChapterListResponse {
+	resp := &PublicChapterListResponse{
+		Items: make([]*PublicChapterSummary, 0, len(chapters)),
+	}
+	for _, ch := range chapters {
+		resp.Items = append(resp.Items, ToPublicChapterSummary(ch))
+	}
+	return resp
+}
+
+// ToPublicChapterResponse 实体转换为公开章节正文响应
+func ToPublicChapterResponse(ch *entity.Chapter) *PublicChapterResponse {
+	if ch == nil {
+		return nil
+	}
+	return &PublicChapterResponse{
+		PublicChapterSummary: *ToPublicChapterSummary(ch),
+		Content:              ch.ContentText,
+	}
+}
@@ -4,6 +4,7 @@ package dto
 import (
 	"time"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 
 	"github.com/google/uuid"
@@ -79,3 +80,12 @@ func ToRelationListResponse(relations []*entity.Relation) *RelationListResponse
 	}
 	return &RelationListResponse{Items: items}
 }
+
+// ToWeightedRelationListResponse 将时效加权后的关系列表转换为响应 DTO
+func ToWeightedRelationListResponse(weighted []appstory.WeightedRelation) *RelationListResponse {
+	items := make([]*RelationResponse, len(weighted))
+	for i, w := range weighted {
+		items[i] = ToWeightedRelationResponse(w)
+	}
+	return &RelationListResponse{Items: items}
+}
@@ -3,6 +3,7 @@ package dto
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -71,6 +72,16 @@ func BindPagedRequest(c *gin.Context) PagedRequest {
 	}
 }
 
+// WantsInclude 判断 ?include= 参数（逗号分隔）是否包含指定字段
+func WantsInclude(c *gin.Context, field string) bool {
+	for _, v := range strings.Split(c.Query("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), field) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseIntWithDefault 解析整数，失败时返回默认值
 func parseIntWithDefault(s string, defaultVal int) int {
 	if s == "" {
@@ -138,6 +149,11 @@ func BindSessionID(c *gin.Context) string {
 	return c.Param("sid")
 }
 
+// BindTurnID 从 URI 绑定会话轮次 ID
+func BindTurnID(c *gin.Context) string {
+	return c.Param("tid")
+}
+
 // BindProjectCreationSessionID 从 URI 绑定对话创建项目会话 ID
 func BindProjectCreationSessionID(c *gin.Context) string {
 	return c.Param("sid")
@@ -163,6 +179,11 @@ func BindJobID(c *gin.Context) string {
 	return c.Param("jid")
 }
 
+// BindCandidateID 从 URI 绑定生成候选 ID
+func BindCandidateID(c *gin.Context) string {
+	return c.Param("cand")
+}
+
 // BindVolumeID 从 URI 绑定卷 ID
 func BindVolumeID(c *gin.Context) string {
 	return c.Param("vid")
@@ -172,3 +193,8 @@ func BindVolumeID(c *gin.Context) string {
 func BindTenantID(c *gin.Context) string {
 	return c.Param("tid")
 }
+
+// BindSeriesID 从 URI 绑定系列 ID
+func BindSeriesID(c *gin.Context) string {
+	return c.Param("srid")
+}
@@ -2,6 +2,17 @@
 package dto
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"z-novel-ai-api/pkg/deadline"
+	"z-novel-ai-api/pkg/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,9 +35,18 @@ type PageMeta struct {
 
 // ErrorDetail 错误详情
 type ErrorDetail struct {
-	ErrorCode   string   `json:"error_code,omitempty"`
-	Details     string   `json:"details,omitempty"`
-	Suggestions []string `json:"suggestions,omitempty"`
+	ErrorCode   string       `json:"error_code,omitempty"`
+	Details     string       `json:"details,omitempty"`
+	Suggestions []string     `json:"suggestions,omitempty"`
+	Issues      []ErrorIssue `json:"issues,omitempty"`
+}
+
+// ErrorIssue 结构化的单条问题（例如外部校验返回的消息）
+type ErrorIssue struct {
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"`
+	Severity string `json:"severity,omitempty"`
 }
 
 // ErrorResponse 错误响应结构
@@ -58,6 +78,46 @@ func SuccessWithPage[T any](c *gin.Context, data T, meta *PageMeta) {
 	})
 }
 
+// SuccessCacheable 返回可被浏览器/CDN 缓存的成功响应：按响应体计算强 ETag，
+// If-None-Match 命中时返回 304。响应体不含 trace_id，保证相同数据的 ETag 稳定。
+func SuccessCacheable[T any](c *gin.Context, data T, cacheControl string) {
+	body, err := json.Marshal(Response[T]{
+		Code:    200,
+		Message: "success",
+		Data:    data,
+	})
+	if err != nil {
+		InternalError(c, "failed to encode response")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Vary", "Accept-Encoding")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches 判断 If-None-Match 是否命中（支持多值与弱校验前缀）
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // Created 返回创建成功响应 (201)
 func Created[T any](c *gin.Context, data T) {
 	c.JSON(201, Response[T]{
@@ -85,21 +145,115 @@ func NoContent(c *gin.Context) {
 
 // Error 返回错误响应
 func Error(c *gin.Context, httpCode int, message string) {
-	c.JSON(httpCode, ErrorResponse{
-		Code:    httpCode,
-		Message: message,
-		TraceID: c.GetString("trace_id"),
-	})
+	ErrorWithDetail(c, httpCode, message, nil)
 }
 
-// ErrorWithDetail 返回带详情的错误响应
+// ErrorWithDetail 返回带详情的错误响应；请求截止时间已到时 5xx 错误改写为 504（见 deadlineError）
 func ErrorWithDetail(c *gin.Context, httpCode int, message string, detail *ErrorDetail) {
-	c.JSON(httpCode, ErrorResponse{
+	httpCode, message, detail = deadlineError(c, httpCode, message, detail)
+	c.JSON(httpCode, NewErrorResponse(c, httpCode, message, detail))
+}
+
+// timeoutMessages 下游依赖超时的消息键（error_code 为 <依赖>_timeout）
+var timeoutMessages = map[deadline.Dependency]string{
+	deadline.Database:  "database timed out",
+	deadline.Cache:     "cache timed out",
+	deadline.VectorDB:  "vector database timed out",
+	deadline.LLM:       "LLM provider timed out",
+	deadline.Embedding: "embedding provider timed out",
+}
+
+// deadlineError 请求截止时间已到时，把 5xx 错误改写为 504，并按首个超时的下游依赖给出稳定错误码
+// （database_timeout / cache_timeout / vector_db_timeout / llm_timeout / embedding_timeout；无法归因时为 request_timeout）
+func deadlineError(c *gin.Context, httpCode int, message string, detail *ErrorDetail) (int, string, *ErrorDetail) {
+	if httpCode < http.StatusInternalServerError || c.Request == nil {
+		return httpCode, message, detail
+	}
+	ctx := c.Request.Context()
+	dep, ok := deadline.TimedOut(ctx)
+	if !ok && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return httpCode, message, detail
+	}
+	if msg, found := timeoutMessages[dep]; ok && found {
+		return http.StatusGatewayTimeout, msg, &ErrorDetail{ErrorCode: string(dep) + "_timeout"}
+	}
+	return http.StatusGatewayTimeout, "request timed out", &ErrorDetail{ErrorCode: "request_timeout"}
+}
+
+// NewErrorResponse 构造本地化的错误响应：message 与 suggestions 按请求语言翻译，
+// error.error_code 未指定时取消息键对应的稳定错误码（未收录的消息按 HTTP 状态取通用错误码），不随语言变化
+func NewErrorResponse(c *gin.Context, httpCode int, message string, detail *ErrorDetail) ErrorResponse {
+	locale := RequestLocale(c)
+	c.Header("Content-Language", string(locale))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+
+	d := ErrorDetail{}
+	if detail != nil {
+		d = *detail
+	}
+	if d.ErrorCode == "" {
+		d.ErrorCode = i18n.Code(message)
+	}
+	if d.ErrorCode == "" {
+		d.ErrorCode = statusErrorCode(httpCode)
+	}
+	if len(d.Suggestions) > 0 {
+		suggestions := make([]string, len(d.Suggestions))
+		for i, s := range d.Suggestions {
+			suggestions[i] = i18n.Translate(locale, s)
+		}
+		d.Suggestions = suggestions
+	}
+	return ErrorResponse{
 		Code:    httpCode,
-		Message: message,
-		Error:   detail,
+		Message: i18n.Translate(locale, message),
+		Error:   &d,
 		TraceID: c.GetString("trace_id"),
-	})
+	}
+}
+
+// RequestLocale 返回请求协商出的语言（Locale 中间件已写入时直接使用，否则按 Accept-Language 协商）
+func RequestLocale(c *gin.Context) i18n.Locale {
+	if l, ok := i18n.Parse(c.GetString(i18n.GinKey)); ok {
+		return l
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// Localize 按请求语言翻译消息（供不使用 ErrorResponse 结构的中间件响应使用）
+func Localize(c *gin.Context, message string) string {
+	return i18n.Translate(RequestLocale(c), message)
+}
+
+// statusErrorCode 未收录消息的通用错误码
+func statusErrorCode(httpCode int) string {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable_entity"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	case http.StatusServiceUnavailable:
+		return "service_unavailable"
+	case http.StatusGatewayTimeout:
+		return "request_timeout"
+	default:
+		if httpCode >= http.StatusInternalServerError {
+			return "internal_error"
+		}
+		return "error"
+	}
 }
 
 // BadRequest 返回 400 错误
@@ -142,6 +296,34 @@ func ServiceUnavailable(c *gin.Context, message string) {
 	Error(c, 503, message)
 }
 
+// RequestTooLarge 返回 413 错误（请求体超过 BodyLimit 中间件的上限）
+func RequestTooLarge(c *gin.Context, maxBytes int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+		"code":      http.StatusRequestEntityTooLarge,
+		"message":   Localize(c, "request body too large"),
+		"max_bytes": maxBytes,
+		"trace_id":  c.GetString("trace_id"),
+	})
+}
+
+// BodyTooLarge 读取请求体时超过上限（未声明长度的请求体由 http.MaxBytesReader 截断）则返回 413 并返回 true
+func BodyTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	RequestTooLarge(c, tooLarge.Limit)
+	return true
+}
+
+// InvalidBody 请求体绑定失败：超过大小上限返回 413，其余返回 400
+func InvalidBody(c *gin.Context, err error) {
+	if BodyTooLarge(c, err) {
+		return
+	}
+	BadRequest(c, "invalid request body: "+err.Error())
+}
+
 // NewPageMeta 创建分页元数据
 func NewPageMeta(page, pageSize, total int) *PageMeta {
 	totalPages := total / pageSize
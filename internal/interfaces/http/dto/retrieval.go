@@ -6,17 +6,24 @@ type SearchRequest struct {
 	ProjectID        string           `json:"project_id" binding:"required"`
 	Query            string           `json:"query" binding:"required,max=5000"`
 	CurrentStoryTime int64            `json:"current_story_time,omitempty"`
+	CurrentChapterID string           `json:"current_chapter_id,omitempty"`                                         // 仅召回叙事顺序早于该章节的内容
+	TimeFilter       string           `json:"time_filter,omitempty" binding:"omitempty,oneof=narrative story_time"` // 默认 narrative
+	POVEntityID      string           `json:"pov_entity_id,omitempty" binding:"omitempty,uuid"`                     // 仅召回该 POV 角色可知的章节内容
+	BranchKey        string           `json:"branch_key,omitempty" binding:"omitempty,max=64"`                      // 检索的分支（what-if 时间线），默认 main；分支未覆盖的文档回退主线
 	TopK             int              `json:"top_k,omitempty"`
 	Options          *RetrievalOption `json:"options,omitempty"`
 }
 
 // RetrievalOption 检索选项
 type RetrievalOption struct {
-	VectorWeight    float64  `json:"vector_weight,omitempty"`  // 默认 0.7
-	KeywordWeight   float64  `json:"keyword_weight,omitempty"` // 默认 0.3
+	VectorWeight    float64  `json:"vector_weight,omitempty" binding:"omitempty,min=0,max=1"`  // 混合检索稠密向量一路的权重，与 keyword_weight 均省略时取配置（默认 0.7）
+	KeywordWeight   float64  `json:"keyword_weight,omitempty" binding:"omitempty,min=0,max=1"` // BM25 关键词一路的权重（默认 0.3）；融合方式为 rrf 时忽略
 	IncludeEntities bool     `json:"include_entities,omitempty"`
 	IncludeEvents   bool     `json:"include_events,omitempty"`
 	EntityTypes     []string `json:"entity_types,omitempty"`
+	IncludeSeries   bool     `json:"include_series,omitempty"` // 同时只读召回同系列前作内容
+	// NamespaceQuotas 按命名空间分配召回配额（prose / summaries / entity_cards / settings / notes），提供时 top_k 取配额总和
+	NamespaceQuotas map[string]int `json:"namespace_quotas,omitempty" binding:"omitempty,max=5,dive,keys,oneof=prose summaries entity_cards settings notes,endkeys,min=1,max=50"`
 }
 
 // DebugRetrievalRequest 调试检索请求
@@ -24,6 +31,10 @@ type DebugRetrievalRequest struct {
 	ProjectID        string           `json:"project_id" binding:"required"`
 	Query            string           `json:"query" binding:"required,max=5000"`
 	CurrentStoryTime int64            `json:"current_story_time,omitempty"`
+	CurrentChapterID string           `json:"current_chapter_id,omitempty"`                                         // 仅召回叙事顺序早于该章节的内容
+	TimeFilter       string           `json:"time_filter,omitempty" binding:"omitempty,oneof=narrative story_time"` // 默认 narrative
+	POVEntityID      string           `json:"pov_entity_id,omitempty" binding:"omitempty,uuid"`                     // 仅召回该 POV 角色可知的章节内容
+	BranchKey        string           `json:"branch_key,omitempty" binding:"omitempty,max=64"`                      // 检索的分支（what-if 时间线），默认 main；分支未覆盖的文档回退主线
 	TopK             int              `json:"top_k,omitempty"`
 	Options          *RetrievalOption `json:"options,omitempty"`
 	IncludeScores    bool             `json:"include_scores,omitempty"`
@@ -39,18 +50,22 @@ type SearchResponse struct {
 
 // ContextSegment 上下文片段
 type ContextSegment struct {
-	ID        string  `json:"id"`
-	Text      string  `json:"text"`
-	ChapterID string  `json:"chapter_id,omitempty"`
-	StoryTime int64   `json:"story_time,omitempty"`
-	Score     float64 `json:"score"`
-	Source    string  `json:"source"` // vector, keyword, time
-
-	DocType      string `json:"doc_type,omitempty"` // chapter | artifact
+	ID           string  `json:"id"`
+	Text         string  `json:"text"`
+	ChapterID    string  `json:"chapter_id,omitempty"`
+	StoryTime    int64   `json:"story_time,omitempty"`
+	NarrativePos int64   `json:"narrative_pos,omitempty"` // 叙事位置：卷序号 * 100000 + 章节序号
+	Score        float64 `json:"score"`
+	Source       string  `json:"source"`               // vector, keyword, time
+	ProjectID    string  `json:"project_id,omitempty"` // 片段所属项目（跨系列召回时为前作）
+
+	DocType      string `json:"doc_type,omitempty"` // chapter | chapter_summary | artifact | notes
 	Title        string `json:"title,omitempty"`    // chapter title（或其他可读标题）
 	ArtifactID   string `json:"artifact_id,omitempty"`
 	ArtifactType string `json:"artifact_type,omitempty"`
 	RefPath      string `json:"ref_path,omitempty"` // JSON Pointer（RFC6901）或近似路径
+	NoteID       string `json:"note_id,omitempty"`
+	BranchKey    string `json:"branch_key,omitempty"` // 片段所属分支
 }
 
 // EntityRef 实体引用
@@ -78,9 +93,96 @@ type DebugRetrievalResponse struct {
 
 // DebugInfo 调试信息
 type DebugInfo struct {
-	VectorSearchTime   int64 `json:"vector_search_time_ms"`
-	KeywordSearchTime  int64 `json:"keyword_search_time_ms"`
-	FusionTime         int64 `json:"fusion_time_ms"`
-	TotalCandidates    int   `json:"total_candidates"`
-	FilteredCandidates int   `json:"filtered_candidates"`
+	VectorSearchTime   int64    `json:"vector_search_time_ms"`
+	KeywordSearchTime  int64    `json:"keyword_search_time_ms"`
+	FusionTime         int64    `json:"fusion_time_ms"`
+	TotalCandidates    int      `json:"total_candidates"`
+	FilteredCandidates int      `json:"filtered_candidates"`
+	TimeFilter         string   `json:"time_filter,omitempty"`      // 实际生效的时间过滤维度
+	VectorTopK         int      `json:"vector_top_k,omitempty"`     // 向量召回候选数（POV 隔离时放大）
+	POVFiltered        int      `json:"pov_filtered"`               // 因 POV 隔离剔除的片段数
+	FocusEntityIDs     []string `json:"focus_entity_ids,omitempty"` // 从大纲识别的聚焦实体
+	FocusBoosted       int      `json:"focus_boosted"`              // 因涉及聚焦实体被提升排序的片段数
+	RelationBoosted    int      `json:"relation_boosted"`           // 因涉及聚焦实体的关联实体（按衰减后的关系强度）被加权的片段数
+	Partitions         []string `json:"partitions,omitempty"`       // 实际检索的向量分区（当前项目在前）
+	BranchKey          string   `json:"branch_key,omitempty"`       // 实际检索的分支（仅作用于当前项目）
+	KeywordTerms       int      `json:"keyword_terms"`              // 查询经 BM25 分词后的词项数（关键词一路的查询维度）
+	Reranked           bool     `json:"reranked"`                   // 是否经精排重新排序
+	RerankTime         int64    `json:"rerank_time_ms,omitempty"`   // 精排耗时
+	RerankError        string   `json:"rerank_error,omitempty"`     // 精排失败原因（失败时保持向量召回顺序）
+
+	NamespaceQuotas map[string]int `json:"namespace_quotas,omitempty"` // 生效的命名空间配额
+	NamespaceHits   map[string]int `json:"namespace_hits,omitempty"`   // 各命名空间最终入选的片段数
+}
+
+// ChapterRetrievalDebugRequest 按章节生成参数调试召回请求
+type ChapterRetrievalDebugRequest struct {
+	ChapterID        string `json:"chapter_id" binding:"required,uuid"`
+	Outline          string `json:"outline,omitempty" binding:"omitempty,max=10000"` // 覆盖章节大纲作为查询（对应生成任务的 outline 参数）
+	IncludeEmbedding bool   `json:"include_embedding,omitempty"`
+}
+
+// ChapterRetrievalDebugResponse 按章节生成参数调试召回响应（不触发生成）
+type ChapterRetrievalDebugResponse struct {
+	ChapterID      string                   `json:"chapter_id"`
+	Query          string                   `json:"query"`
+	Filters        *RetrievalDebugFilters   `json:"filters"`
+	Budget         *RetrievalPromptBudget   `json:"budget"`
+	Segments       []*RetrievalDebugSegment `json:"segments"`
+	PromptContext  string                   `json:"prompt_context"` // 实际注入 Prompt 的召回块
+	DisabledReason string                   `json:"disabled_reason,omitempty"`
+	QueryEmbedding []float32                `json:"query_embedding,omitempty"`
+	DebugInfo      *DebugInfo               `json:"debug_info,omitempty"`
+}
+
+// RetrievalDebugFilters 生成侧召回使用的过滤条件
+type RetrievalDebugFilters struct {
+	TopK                int            `json:"top_k"`
+	CurrentStoryTime    int64          `json:"current_story_time"`
+	CurrentNarrativePos int64          `json:"current_narrative_pos"`
+	POVEntityID         string         `json:"pov_entity_id,omitempty"`
+	SeriesProjectIDs    []string       `json:"series_project_ids,omitempty"`
+	NamespaceQuotas     map[string]int `json:"namespace_quotas,omitempty"` // 项目设置的命名空间配额
+	SpoilerGuards       int            `json:"spoiler_guards"`             // 本章生效的剧透保护数
+	SpoilerExcluded     int            `json:"spoiler_excluded"`           // 因剧透保护剔除的片段数
+}
+
+// RetrievalPromptBudget Prompt 召回预算及使用情况
+type RetrievalPromptBudget struct {
+	MaxSegments        int `json:"max_segments"`
+	MaxRunesPerSegment int `json:"max_runes_per_segment"`
+	UsedSegments       int `json:"used_segments"`
+	Chars              int `json:"chars"`
+}
+
+// RetrievalDebugSegment 调试召回片段
+type RetrievalDebugSegment struct {
+	ContextSegment
+	Rank       int    `json:"rank"`                  // 召回排序（从 1 开始）
+	InPrompt   bool   `json:"in_prompt"`             // 是否落入 Prompt 预算
+	Truncated  bool   `json:"truncated,omitempty"`   // 写入 Prompt 时是否被截断
+	ExcludedBy string `json:"excluded_by,omitempty"` // 被剔除的原因（spoiler_guard）
+}
+
+// VectorSegmentResponse 项目已索引片段（不含向量）
+type VectorSegmentResponse struct {
+	ID           string `json:"id"`
+	DocID        string `json:"doc_id"` // 章节/设定/笔记 ID
+	SegmentType  string `json:"segment_type"`
+	TextContent  string `json:"text_content"`
+	StoryTime    int64  `json:"story_time"`
+	NarrativePos int64  `json:"narrative_pos,omitempty"`
+}
+
+// VectorSegmentListResponse 项目已索引片段列表（按片段 ID 游标分页）
+type VectorSegmentListResponse struct {
+	Segments   []*VectorSegmentResponse `json:"segments"`
+	NextCursor string                   `json:"next_cursor,omitempty"` // 作为下一页 cursor 参数；为空表示已到末尾
+}
+
+// VectorSegmentStatsResponse 项目已索引片段统计
+type VectorSegmentStatsResponse struct {
+	ProjectID string           `json:"project_id"`
+	Total     int64            `json:"total"`
+	ByType    map[string]int64 `json:"by_type"`
 }
@@ -0,0 +1,112 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// CreateSeriesRequest 创建系列请求
+type CreateSeriesRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=5000"`
+}
+
+// UpdateSeriesRequest 更新系列请求
+type UpdateSeriesRequest struct {
+	Title       *string `json:"title,omitempty" binding:"omitempty,max=255"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=5000"`
+}
+
+// AttachSeriesProjectRequest 将项目加入系列请求
+type AttachSeriesProjectRequest struct {
+	ProjectID   string `json:"project_id" binding:"required,uuid"`
+	SeriesOrder int    `json:"series_order" binding:"gte=0"` // 系列内顺序（第几部），越小越早
+}
+
+// SeriesResponse 系列响应
+type SeriesResponse struct {
+	ID          string             `json:"id"`
+	OwnerID     string             `json:"owner_id,omitempty"`
+	Title       string             `json:"title"`
+	Description string             `json:"description,omitempty"`
+	Projects    []*ProjectResponse `json:"projects,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// SeriesListResponse 系列列表响应
+type SeriesListResponse struct {
+	Items []*SeriesResponse `json:"items"`
+}
+
+// SeriesStatsResponse 系列统计响应
+type SeriesStatsResponse struct {
+	SeriesID       string                           `json:"series_id"`
+	TotalProjects  int                              `json:"total_projects"`
+	TotalChapters  int                              `json:"total_chapters"`
+	TotalEntities  int                              `json:"total_entities"`
+	TotalWordCount int64                            `json:"total_word_count"`
+	Projects       []*repository.SeriesProjectStats `json:"projects"`
+}
+
+// CanonArtifactResponse 项目生效设定响应
+type CanonArtifactResponse struct {
+	Type            string          `json:"type"`
+	SourceProjectID string          `json:"source_project_id"`
+	Inherited       bool            `json:"inherited"` // 是否继承自前作
+	VersionID       string          `json:"version_id"`
+	VersionNo       int             `json:"version_no"`
+	Content         json.RawMessage `json:"content"`
+}
+
+// ToEntity 转换为实体
+func (r *CreateSeriesRequest) ToEntity(tenantID, ownerID string) *entity.Series {
+	s := entity.NewSeries(tenantID, ownerID, r.Title)
+	s.Description = r.Description
+	return s
+}
+
+// ApplyToSeries 将更新请求应用到系列实体
+func (r *UpdateSeriesRequest) ApplyToSeries(s *entity.Series) {
+	if r.Title != nil {
+		s.Title = *r.Title
+	}
+	if r.Description != nil {
+		s.Description = *r.Description
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// ToSeriesResponse 实体转换为响应
+func ToSeriesResponse(s *entity.Series, projects []*entity.Project) *SeriesResponse {
+	if s == nil {
+		return nil
+	}
+	resp := &SeriesResponse{
+		ID:          s.ID,
+		OwnerID:     s.OwnerID,
+		Title:       s.Title,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, ToProjectResponse(p))
+	}
+	return resp
+}
+
+// ToSeriesListResponse 实体列表转换为响应
+func ToSeriesListResponse(items []*entity.Series) *SeriesListResponse {
+	resp := &SeriesListResponse{
+		Items: make([]*SeriesResponse, 0, len(items)),
+	}
+	for _, s := range items {
+		resp.Items = append(resp.Items, ToSeriesResponse(s, nil))
+	}
+	return resp
+}
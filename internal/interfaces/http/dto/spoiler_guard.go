@@ -0,0 +1,103 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"time"
+
+	"z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/domain/entity"
+)
+
+// SpoilerGuardResponse 剧透保护响应
+type SpoilerGuardResponse struct {
+	ID              string                  `json:"id"`
+	ProjectID       string                  `json:"project_id"`
+	Kind            entity.SpoilerGuardKind `json:"kind"`
+	RefID           *string                 `json:"ref_id,omitempty"`
+	Label           string                  `json:"label"`
+	Keywords        []string                `json:"keywords,omitempty"`
+	RevealChapterID *string                 `json:"reveal_chapter_id,omitempty"`
+	RevealVolumeID  *string                 `json:"reveal_volume_id,omitempty"`
+	Note            string                  `json:"note,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// SpoilerGuardListResponse 剧透保护列表响应
+type SpoilerGuardListResponse struct {
+	Items []*SpoilerGuardResponse `json:"items"`
+}
+
+// CreateSpoilerGuardRequest 创建剧透保护请求（reveal_chapter_id 与 reveal_volume_id 二选一）
+type CreateSpoilerGuardRequest struct {
+	Kind            entity.SpoilerGuardKind `json:"kind" binding:"required,oneof=entity fact event"`
+	RefID           *string                 `json:"ref_id" binding:"omitempty,uuid"`
+	Label           string                  `json:"label" binding:"omitempty,max=200"`
+	Keywords        []string                `json:"keywords" binding:"omitempty,max=20"`
+	RevealChapterID *string                 `json:"reveal_chapter_id" binding:"omitempty,uuid"`
+	RevealVolumeID  *string                 `json:"reveal_volume_id" binding:"omitempty,uuid"`
+	Note            string                  `json:"note" binding:"omitempty,max=500"`
+}
+
+// SpoilerCheckResponse 章节剧透扫描结果
+type SpoilerCheckResponse struct {
+	ChapterID     string              `json:"chapter_id"`
+	ActiveGuards  int                 `json:"active_guards"`
+	Violations    []spoiler.Violation `json:"violations"`
+	HasViolations bool                `json:"has_violations"`
+}
+
+// ToEntity 转换为剧透保护实体
+func (r *CreateSpoilerGuardRequest) ToEntity(tenantID, projectID string) *entity.SpoilerGuard {
+	return &entity.SpoilerGuard{
+		TenantID:        tenantID,
+		ProjectID:       projectID,
+		Kind:            r.Kind,
+		RefID:           r.RefID,
+		Label:           r.Label,
+		Keywords:        entity.StringSlice(r.Keywords),
+		RevealChapterID: r.RevealChapterID,
+		RevealVolumeID:  r.RevealVolumeID,
+		Note:            r.Note,
+	}
+}
+
+// ToSpoilerGuardResponse 转换为剧透保护响应
+func ToSpoilerGuardResponse(g *entity.SpoilerGuard) *SpoilerGuardResponse {
+	if g == nil {
+		return nil
+	}
+	return &SpoilerGuardResponse{
+		ID:              g.ID,
+		ProjectID:       g.ProjectID,
+		Kind:            g.Kind,
+		RefID:           g.RefID,
+		Label:           g.Label,
+		Keywords:        g.Keywords,
+		RevealChapterID: g.RevealChapterID,
+		RevealVolumeID:  g.RevealVolumeID,
+		Note:            g.Note,
+		CreatedAt:       g.CreatedAt,
+	}
+}
+
+// ToSpoilerGuardListResponse 转换为剧透保护列表响应
+func ToSpoilerGuardListResponse(guards []*entity.SpoilerGuard) *SpoilerGuardListResponse {
+	items := make([]*SpoilerGuardResponse, 0, len(guards))
+	for _, g := range guards {
+		items = append(items, ToSpoilerGuardResponse(g))
+	}
+	return &SpoilerGuardListResponse{Items: items}
+}
+
+// ToSpoilerCheckResponse 转换为章节剧透扫描结果
+func ToSpoilerCheckResponse(chapterID string, activeGuards int, violations []spoiler.Violation) *SpoilerCheckResponse {
+	if violations == nil {
+		violations = []spoiler.Violation{}
+	}
+	return &SpoilerCheckResponse{
+		ChapterID:     chapterID,
+		ActiveGuards:  activeGuards,
+		Violations:    violations,
+		HasViolations: len(violations) > 0,
+	}
+}
@@ -0,0 +1,165 @@
+// Package dto 提供 HTTP 层数据传输对象
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// SSE 事件协议：所有流式接口共享同一组事件类型与数据结构，
+// 每个事件的 data 均为 StreamEvent 信封，客户端按 type 分派、按 v 判断兼容性。
+
+// StreamSchemaVersion 当前 SSE 事件协议版本；字段仅做增量扩展时不升级，破坏性变更时递增
+const StreamSchemaVersion = "1"
+
+// StreamSchemaVersionHeader 响应头：声明本次 SSE 流使用的事件协议版本
+const StreamSchemaVersionHeader = "X-Stream-Schema-Version"
+
+// StreamEventType SSE 事件类型（同时作为 SSE 的 event 字段）
+type StreamEventType string
+
+const (
+	StreamEventContent  StreamEventType = "content"
+	StreamEventProgress StreamEventType = "progress"
+	StreamEventContext  StreamEventType = "context"
+	StreamEventWarning  StreamEventType = "warning"
+	StreamEventDone     StreamEventType = "done"
+	StreamEventError    StreamEventType = "error"
+)
+
+// StreamEvent SSE 事件信封
+type StreamEvent struct {
+	Version string          `json:"v"`
+	Type    StreamEventType `json:"type"`
+	// Seq 单条流内单调递增的事件序号（跨类型）
+	Seq  int `json:"seq"`
+	Data any `json:"data"`
+}
+
+// StreamContentData 内容分片
+type StreamContentData struct {
+	Chunk string `json:"chunk"`
+	// Index 内容分片序号（仅统计 content 事件）
+	Index int `json:"index"`
+	// Candidate 候选序号（异步任务多候选并行生成时区分正文归属）
+	Candidate int `json:"candidate,omitempty"`
+}
+
+// StreamProgressData 阶段进度
+type StreamProgressData struct {
+	Stage    string `json:"stage"`
+	Progress int    `json:"progress"`
+	Message  string `json:"message,omitempty"`
+}
+
+// StreamContextData 检索上下文摘要
+type StreamContextData struct {
+	Segments int `json:"segments"`
+	Chars    int `json:"chars"`
+	// Truncation 召回片段超出 Prompt 预算被截断或丢弃的明细（无截断时省略）
+	Truncation *TruncationReportResponse `json:"truncation,omitempty"`
+}
+
+// StreamWarningData 非致命告警（流继续）
+type StreamWarningData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// StreamDoneData 流正常结束
+type StreamDoneData struct {
+	JobID     string `json:"job_id"`
+	ChapterID string `json:"chapter_id,omitempty"`
+	WordCount int    `json:"word_count,omitempty"`
+	Plan      any    `json:"plan,omitempty"`
+}
+
+// StreamErrorData 流异常结束
+type StreamErrorData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 流式阶段
+const (
+	StreamStageRetrieval  = "retrieval"
+	StreamStageGenerating = "generating"
+	StreamStageValidating = "validating"
+	StreamStageSaving     = "saving"
+)
+
+// 流式错误/告警码
+const (
+	StreamCodeGenerationFailed = "generation_failed"
+	// StreamCodeGenerationTimeout 生成超过 story.generation_timeouts 配置的时限
+	StreamCodeGenerationTimeout = "generation_timeout"
+	StreamCodeInvalidOutput     = "invalid_output"
+	StreamCodePersistFailed     = "persist_failed"
+	StreamCodeRetrievalFailed   = "retrieval_failed"
+	StreamCodeSpoilerViolation  = "spoiler_violation"
+	// StreamCodeJobCancelled 订阅的异步任务已取消
+	StreamCodeJobCancelled = "job_cancelled"
+)
+
+// StreamNotice 生成协程发往 SSE 写入端的非内容事件（progress/context/warning）
+type StreamNotice struct {
+	Type StreamEventType
+	Data any
+}
+
+// StreamWriter 按统一协议写出 SSE 事件
+type StreamWriter struct {
+	c            *gin.Context
+	seq          int
+	contentIndex int
+}
+
+// NewStreamWriter 设置 SSE 响应头（含协议版本）并创建事件写入器
+func NewStreamWriter(c *gin.Context) *StreamWriter {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header(StreamSchemaVersionHeader, StreamSchemaVersion)
+	return &StreamWriter{c: c}
+}
+
+// Content 写出内容分片
+func (w *StreamWriter) Content(chunk string) {
+	w.CandidateContent(0, chunk)
+}
+
+// CandidateContent 写出指定候选的内容分片
+func (w *StreamWriter) CandidateContent(candidate int, chunk string) {
+	w.write(StreamEventContent, StreamContentData{Chunk: chunk, Index: w.contentIndex, Candidate: candidate})
+	w.contentIndex++
+}
+
+// Progress 写出阶段进度
+func (w *StreamWriter) Progress(stage string, progress int, message string) {
+	w.write(StreamEventProgress, StreamProgressData{Stage: stage, Progress: progress, Message: message})
+}
+
+// Notice 写出生成协程产生的非内容事件
+func (w *StreamWriter) Notice(n StreamNotice) {
+	w.write(n.Type, n.Data)
+}
+
+// Done 写出结束事件
+func (w *StreamWriter) Done(data StreamDoneData) {
+	w.write(StreamEventDone, data)
+}
+
+// Error 写出错误事件
+func (w *StreamWriter) Error(code, message string) {
+	w.write(StreamEventError, StreamErrorData{Code: code, Message: message})
+}
+
+func (w *StreamWriter) write(typ StreamEventType, data any) {
+	w.c.SSEvent(string(typ), StreamEvent{
+		Version: StreamSchemaVersion,
+		Type:    typ,
+		Seq:     w.seq,
+		Data:    data,
+	})
+	w.seq++
+}
@@ -2,9 +2,13 @@
 package dto
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
+	"z-novel-ai-api/internal/application/retrieval"
 	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
 )
 
 // TenantResponse 租户响应
@@ -17,6 +21,48 @@ type TenantResponse struct {
 	Status    entity.TenantStatus    `json:"status"`
 	CreatedAt time.Time              `json:"created_at"`
 	UpdatedAt time.Time              `json:"updated_at"`
+
+	TokenBalance     int64      `json:"token_balance"`
+	PlanID           string     `json:"plan_id,omitempty"`
+	QuotaPeriodStart *time.Time `json:"quota_period_start,omitempty"`
+	QuotaPeriodEnd   *time.Time `json:"quota_period_end,omitempty"`
+}
+
+// PlanResponse 套餐响应
+type PlanResponse struct {
+	ID                string          `json:"id"`
+	Code              string          `json:"code"`
+	Name              string          `json:"name"`
+	MonthlyTokens     int64           `json:"monthly_tokens"`
+	MaxConcurrentJobs int             `json:"max_concurrent_jobs"`
+	RequestsPerSecond int             `json:"requests_per_second"`
+	Features          map[string]bool `json:"features"`
+}
+
+// PlanListResponse 套餐列表响应
+type PlanListResponse struct {
+	Items []*PlanResponse `json:"items"`
+}
+
+// TenantPlanResponse 租户当前套餐与配额周期
+type TenantPlanResponse struct {
+	Plan             *PlanResponse `json:"plan,omitempty"`
+	TokenBalance     int64         `json:"token_balance"`
+	QuotaPeriodStart *time.Time    `json:"quota_period_start,omitempty"`
+	QuotaPeriodEnd   *time.Time    `json:"quota_period_end,omitempty"`
+}
+
+// ChangePlanRequest 变更套餐请求
+type ChangePlanRequest struct {
+	PlanCode string `json:"plan_code" binding:"required,max=50"`
+}
+
+// ChangePlanResponse 变更套餐响应（含按周期剩余比例折算的余额调整）
+type ChangePlanResponse struct {
+	PreviousPlan      *PlanResponse       `json:"previous_plan,omitempty"`
+	Current           *TenantPlanResponse `json:"current"`
+	RemainingRatio    float64             `json:"remaining_ratio"`
+	BalanceAdjustment int64               `json:"balance_adjustment"`
 }
 
 // CreateTenantRequest 创建租户请求
@@ -31,6 +77,103 @@ type UpdateTenantRequest struct {
 	Settings *entity.TenantSettings `json:"settings"`
 }
 
+// TenantProjectDefaultsRequest 租户默认项目设置请求（整体替换；全部为空表示清除模板）
+type TenantProjectDefaultsRequest struct {
+	DefaultChapterLength int     `json:"default_chapter_length,omitempty" binding:"omitempty,min=100,max=20000"`
+	WritingStyle         string  `json:"writing_style,omitempty" binding:"omitempty,max=2000"`
+	POV                  string  `json:"pov,omitempty" binding:"omitempty,max=2000"`
+	Temperature          float64 `json:"temperature,omitempty" binding:"omitempty,min=0,max=2"`
+	SeriesRetrieval      bool    `json:"series_retrieval,omitempty"`
+	Provider             string  `json:"provider,omitempty" binding:"omitempty,max=32"`
+	Model                string  `json:"model,omitempty" binding:"omitempty,max=64"`
+	RetrievalTopK        int     `json:"retrieval_top_k,omitempty" binding:"omitempty,min=1,max=50"`
+	// RetrievalNamespaces 章节召回的命名空间配额（prose / summaries / entity_cards / settings / notes）
+	RetrievalNamespaces map[string]int `json:"retrieval_namespaces,omitempty" binding:"omitempty,max=5,dive,keys,oneof=prose summaries entity_cards settings notes,endkeys,min=1,max=50"`
+}
+
+// ToEntity 转换为项目设置模板（全部为空时返回 nil）
+func (r *TenantProjectDefaultsRequest) ToEntity() *entity.ProjectSettings {
+	s := &entity.ProjectSettings{
+		DefaultChapterLength: r.DefaultChapterLength,
+		WritingStyle:         strings.TrimSpace(r.WritingStyle),
+		POV:                  strings.TrimSpace(r.POV),
+		Temperature:          r.Temperature,
+		SeriesRetrieval:      r.SeriesRetrieval,
+		Provider:             strings.TrimSpace(r.Provider),
+		Model:                strings.TrimSpace(r.Model),
+		RetrievalTopK:        r.RetrievalTopK,
+	}
+	if len(r.RetrievalNamespaces) > 0 {
+		s.RetrievalNamespaces = r.RetrievalNamespaces
+	}
+	if s.DefaultChapterLength == 0 && s.WritingStyle == "" && s.POV == "" && s.Temperature == 0 &&
+		!s.SeriesRetrieval && s.Provider == "" && s.Model == "" && s.RetrievalTopK == 0 && len(s.RetrievalNamespaces) == 0 {
+		return nil
+	}
+	return s
+}
+
+// TenantArtifactValidationRequest 构件激活校验 Webhook 设置请求（整体替换；url 为空表示关闭）。
+// secret 省略时保留原值，传空字符串表示清除
+type TenantArtifactValidationRequest struct {
+	URL           string   `json:"url,omitempty" binding:"omitempty,max=2048,url"`
+	Secret        *string  `json:"secret,omitempty" binding:"omitempty,max=256"`
+	ArtifactTypes []string `json:"artifact_types,omitempty" binding:"omitempty,max=10,dive,oneof=novel_foundation worldview characters outline"`
+	TimeoutMs     int      `json:"timeout_ms,omitempty" binding:"omitempty,min=100,max=30000"`
+	FailOpen      bool     `json:"fail_open,omitempty"`
+}
+
+// ToEntity 转换为 Webhook 设置（url 为空时返回 nil）；prev 为原设置，用于保留未提供的密钥
+func (r *TenantArtifactValidationRequest) ToEntity(prev *entity.ArtifactValidationWebhook) *entity.ArtifactValidationWebhook {
+	url := strings.TrimSpace(r.URL)
+	if url == "" {
+		return nil
+	}
+	w := &entity.ArtifactValidationWebhook{
+		URL:       url,
+		TimeoutMs: r.TimeoutMs,
+		FailOpen:  r.FailOpen,
+	}
+	switch {
+	case r.Secret != nil:
+		w.Secret = strings.TrimSpace(*r.Secret)
+	case prev != nil:
+		w.Secret = prev.Secret
+	}
+	for _, t := range r.ArtifactTypes {
+		w.ArtifactTypes = append(w.ArtifactTypes, entity.ArtifactType(t))
+	}
+	return w
+}
+
+// TenantArtifactValidationResponse 构件激活校验 Webhook 设置（不返回密钥，仅返回是否已设置）
+type TenantArtifactValidationResponse struct {
+	Enabled       bool     `json:"enabled"`
+	URL           string   `json:"url,omitempty"`
+	SecretSet     bool     `json:"secret_set"`
+	ArtifactTypes []string `json:"artifact_types,omitempty"`
+	TimeoutMs     int      `json:"timeout_ms,omitempty"`
+	FailOpen      bool     `json:"fail_open"`
+}
+
+// ToTenantArtifactValidationResponse 转换为响应（未设置时 enabled=false）
+func ToTenantArtifactValidationResponse(w *entity.ArtifactValidationWebhook) *TenantArtifactValidationResponse {
+	if w == nil || w.URL == "" {
+		return &TenantArtifactValidationResponse{}
+	}
+	resp := &TenantArtifactValidationResponse{
+		Enabled:   true,
+		URL:       w.URL,
+		SecretSet: w.Secret != "",
+		TimeoutMs: w.TimeoutMs,
+		FailOpen:  w.FailOpen,
+	}
+	for _, t := range w.ArtifactTypes {
+		resp.ArtifactTypes = append(resp.ArtifactTypes, string(t))
+	}
+	return resp
+}
+
 // TenantListResponse 租户列表响应
 type TenantListResponse struct {
 	Items []*TenantResponse `json:"items"`
@@ -41,15 +184,68 @@ func ToTenantResponse(t *entity.Tenant) *TenantResponse {
 	if t == nil {
 		return nil
 	}
-	return &TenantResponse{
+	resp := &TenantResponse{
 		ID:        t.ID,
 		Name:      t.Name,
 		Slug:      t.Slug,
-		Settings:  t.Settings,
+		Settings:  publicTenantSettings(t.Settings),
 		Quota:     t.Quota,
 		Status:    t.Status,
 		CreatedAt: t.CreatedAt,
 		UpdatedAt: t.UpdatedAt,
+
+		TokenBalance:     t.TokenBalance,
+		QuotaPeriodStart: t.QuotaPeriodStart,
+		QuotaPeriodEnd:   t.QuotaPeriodEnd,
+	}
+	if t.PlanID != nil {
+		resp.PlanID = *t.PlanID
+	}
+	return resp
+}
+
+// publicTenantSettings 返回不含校验 Webhook 密钥的设置副本
+func publicTenantSettings(s *entity.TenantSettings) *entity.TenantSettings {
+	if s == nil || s.ArtifactValidation == nil || s.ArtifactValidation.Secret == "" {
+		return s
+	}
+	out := *s
+	hook := *s.ArtifactValidation
+	hook.Secret = ""
+	out.ArtifactValidation = &hook
+	return &out
+}
+
+// ToPlanResponse 套餐实体转换为响应
+func ToPlanResponse(p *entity.Plan) *PlanResponse {
+	if p == nil {
+		return nil
+	}
+	features := p.Features
+	if features == nil {
+		features = map[string]bool{}
+	}
+	return &PlanResponse{
+		ID:                p.ID,
+		Code:              p.Code,
+		Name:              p.Name,
+		MonthlyTokens:     p.MonthlyTokens,
+		MaxConcurrentJobs: p.MaxConcurrentJobs,
+		RequestsPerSecond: p.RequestsPerSecond,
+		Features:          features,
+	}
+}
+
+// ToTenantPlanResponse 租户与套餐转换为响应
+func ToTenantPlanResponse(t *entity.Tenant, p *entity.Plan) *TenantPlanResponse {
+	if t == nil {
+		return nil
+	}
+	return &TenantPlanResponse{
+		Plan:             ToPlanResponse(p),
+		TokenBalance:     t.TokenBalance,
+		QuotaPeriodStart: t.QuotaPeriodStart,
+		QuotaPeriodEnd:   t.QuotaPeriodEnd,
 	}
 }
 
@@ -59,7 +255,118 @@ func (r *UpdateTenantRequest) ApplyToTenant(t *entity.Tenant) {
 		t.Name = *r.Name
 	}
 	if r.Settings != nil {
+		// 默认项目设置模板通过专用接口维护，未提供时保留原值
+		if r.Settings.ProjectDefaults == nil {
+			r.Settings.ProjectDefaults = t.ProjectDefaults()
+		}
+		// 构件激活校验 Webhook 同样只通过专用接口维护（避免经此接口绕过校验或覆盖密钥）
+		r.Settings.ArtifactValidation = t.ArtifactValidation()
+		// 设定集规模上限由运维接口维护
+		r.Settings.FoundationPlanLimits = t.FoundationPlanLimits()
 		t.Settings = r.Settings
 	}
 	t.UpdatedAt = time.Now()
 }
+
+// TenantVectorUsageResponse 租户向量用量响应：tenant 为租户合计，projects 按片段数降序，limits 为配置的上限（0 表示不限）。
+// 用量由写索引时维护的计数得出（近似值），开启计数前已写入的片段需对项目执行一次 index_rebuild 才会计入
+type TenantVectorUsageResponse struct {
+	*retrieval.UsageReport
+}
+
+// LLMUsageEventResponse LLM 用量流水：workflow 为发起调用的工作流，project_id / job_id 为调用归属（非项目作用域的调用为空）
+type LLMUsageEventResponse struct {
+	ID               string    `json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	Workflow         string    `json:"workflow"`
+	JobType          string    `json:"job_type,omitempty"`
+	ProjectID        *string   `json:"project_id,omitempty"`
+	JobID            *string   `json:"job_id,omitempty"`
+	TokensPrompt     int       `json:"tokens_prompt"`
+	TokensCompletion int       `json:"tokens_completion"`
+	TotalTokens      int       `json:"total_tokens"`
+	DurationMs       int       `json:"duration_ms"`
+}
+
+// LLMUsageSummaryResponse 符合筛选条件的全部用量流水汇总（不受分页影响）
+type LLMUsageSummaryResponse struct {
+	Events           int64 `json:"events"`
+	TokensPrompt     int64 `json:"tokens_prompt"`
+	TokensCompletion int64 `json:"tokens_completion"`
+	TotalTokens      int64 `json:"total_tokens"`
+	DurationMs       int64 `json:"duration_ms"`
+}
+
+// LLMUsageListResponse LLM 用量流水列表响应
+type LLMUsageListResponse struct {
+	Items   []*LLMUsageEventResponse `json:"items"`
+	Summary *LLMUsageSummaryResponse `json:"summary"`
+}
+
+// LLMUsageCSVHeader 用量流水 CSV 导出的表头
+var LLMUsageCSVHeader = []string{
+	"id", "created_at", "provider", "model", "workflow", "job_type", "project_id", "job_id",
+	"tokens_prompt", "tokens_completion", "total_tokens", "duration_ms",
+}
+
+// ToLLMUsageEventResponse 实体转换为响应
+func ToLLMUsageEventResponse(e *entity.LLMUsageEvent) *LLMUsageEventResponse {
+	return &LLMUsageEventResponse{
+		ID:               e.ID,
+		CreatedAt:        e.CreatedAt,
+		Provider:         e.Provider,
+		Model:            e.Model,
+		Workflow:         e.Workflow,
+		JobType:          e.JobType,
+		ProjectID:        e.ProjectID,
+		JobID:            e.JobID,
+		TokensPrompt:     e.TokensPrompt,
+		TokensCompletion: e.TokensCompletion,
+		TotalTokens:      e.TokensPrompt + e.TokensCompletion,
+		DurationMs:       e.DurationMs,
+	}
+}
+
+// ToLLMUsageListResponse 转换用量流水列表与汇总
+func ToLLMUsageListResponse(events []*entity.LLMUsageEvent, summary *repository.LLMUsageSummary) *LLMUsageListResponse {
+	items := make([]*LLMUsageEventResponse, len(events))
+	for i, e := range events {
+		items[i] = ToLLMUsageEventResponse(e)
+	}
+	return &LLMUsageListResponse{
+		Items: items,
+		Summary: &LLMUsageSummaryResponse{
+			Events:           summary.Events,
+			TokensPrompt:     summary.TokensPrompt,
+			TokensCompletion: summary.TokensCompletion,
+			TotalTokens:      summary.TokensPrompt + summary.TokensCompletion,
+			DurationMs:       summary.DurationMs,
+		},
+	}
+}
+
+// ToLLMUsageCSVRecord 用量流水转换为 CSV 行（列顺序同 LLMUsageCSVHeader，时间为 UTC RFC 3339）
+func ToLLMUsageCSVRecord(e *entity.LLMUsageEvent) []string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return []string{
+		e.ID,
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		e.Provider,
+		e.Model,
+		e.Workflow,
+		e.JobType,
+		deref(e.ProjectID),
+		deref(e.JobID),
+		strconv.Itoa(e.TokensPrompt),
+		strconv.Itoa(e.TokensCompletion),
+		strconv.Itoa(e.TokensPrompt + e.TokensCompletion),
+		strconv.Itoa(e.DurationMs),
+	}
+}
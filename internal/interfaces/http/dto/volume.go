@@ -32,8 +32,21 @@ type VolumeResponse struct {
 	Summary     string              `json:"summary,omitempty"`
 	WordCount   int                 `json:"word_count"`
 	Status      entity.VolumeStatus `json:"status"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
+	// Chapters 卷内章节状态计数（用于进度条）
+	Chapters VolumeChapterCounts `json:"chapters"`
+	// ReadingMinutes 预计阅读时长（分钟）
+	ReadingMinutes int       `json:"reading_minutes"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// VolumeChapterCounts 卷内章节状态计数
+type VolumeChapterCounts struct {
+	Total      int `json:"total"`
+	Draft      int `json:"draft"`
+	Generating int `json:"generating"`
+	Review     int `json:"review"`
+	Completed  int `json:"completed"`
 }
 
 // VolumeListResponse 卷列表响应
@@ -65,8 +78,16 @@ func ToVolumeResponse(v *entity.Volume) *VolumeResponse {
 		Summary:     v.Summary,
 		WordCount:   v.WordCount,
 		Status:      v.Status,
-		CreatedAt:   v.CreatedAt,
-		UpdatedAt:   v.UpdatedAt,
+		Chapters: VolumeChapterCounts{
+			Total:      v.ChapterCount,
+			Draft:      v.DraftChapters,
+			Generating: v.GeneratingChapters,
+			Review:     v.ReviewChapters,
+			Completed:  v.CompletedChapters,
+		},
+		ReadingMinutes: v.ReadingMinutes(),
+		CreatedAt:      v.CreatedAt,
+		UpdatedAt:      v.UpdatedAt,
 	}
 }
 
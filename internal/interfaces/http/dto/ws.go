@@ -0,0 +1,63 @@
+package dto
+
+import "encoding/json"
+
+// WebSocket 协议（GET /v1/ws）：客户端发送 WSClientMessage 订阅项目或任务，
+// 服务端以 WSServerMessage 推送订阅确认、任务事件与错误；一个连接可同时订阅多个项目/任务。
+
+// WSOp 客户端操作
+type WSOp string
+
+const (
+	WSOpSubscribe   WSOp = "subscribe"
+	WSOpUnsubscribe WSOp = "unsubscribe"
+	WSOpPing        WSOp = "ping"
+)
+
+// WSMessageType 服务端消息类型
+type WSMessageType string
+
+const (
+	WSMessageSubscribed   WSMessageType = "subscribed"
+	WSMessageUnsubscribed WSMessageType = "unsubscribed"
+	// WSMessageEvent 任务事件（content/progress/done/error/conflict_warnings，见 WSJobEvent.Type）
+	WSMessageEvent WSMessageType = "event"
+	WSMessagePong  WSMessageType = "pong"
+	WSMessageError WSMessageType = "error"
+)
+
+// WSClientMessage 客户端消息：project_id 与 job_id 二选一
+type WSClientMessage struct {
+	Op        WSOp   `json:"op"`
+	ProjectID string `json:"project_id,omitempty"`
+	JobID     string `json:"job_id,omitempty"`
+	// RequestID 客户端自定义标识，原样回带在对应的确认/错误消息中
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WSServerMessage 服务端消息
+type WSServerMessage struct {
+	Version string        `json:"v"`
+	Type    WSMessageType `json:"type"`
+	// Topic 订阅主题：project:{project_id} 或 job:{job_id}
+	Topic     string      `json:"topic,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Event     *WSJobEvent `json:"event,omitempty"`
+	Code      string      `json:"code,omitempty"`
+	Message   string      `json:"message,omitempty"`
+}
+
+// WSJobEvent 任务事件（字段含义同 SSE 任务流；conflict_warnings 事件携带 SettingConflictWarning 列表）
+type WSJobEvent struct {
+	Type      string          `json:"type"`
+	JobID     string          `json:"job_id"`
+	ProjectID string          `json:"project_id,omitempty"`
+	Candidate int             `json:"candidate,omitempty"`
+	Chunk     string          `json:"chunk,omitempty"`
+	Progress  int             `json:"progress,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	ChapterID string          `json:"chapter_id,omitempty"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Conflicts json.RawMessage `json:"conflicts,omitempty"`
+}
@@ -2,13 +2,22 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
 	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/timeline"
+	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/messaging"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 	"z-novel-ai-api/pkg/logger"
@@ -19,10 +28,31 @@ import (
 type ArtifactHandler struct {
 	artifactRepo repository.ArtifactRepository
 	indexer      *appretrieval.Indexer
+	validator    *storyartifact.ActivationValidator
+	applier      *storyfoundation.FoundationApplier
+	planLimits   *storyfoundation.PlanLimits
+	outlineStale *storyoutline.StaleTracker
+	producer     *messaging.Producer
 }
 
-func NewArtifactHandler(artifactRepo repository.ArtifactRepository, indexer *appretrieval.Indexer) *ArtifactHandler {
-	return &ArtifactHandler{artifactRepo: artifactRepo, indexer: indexer}
+func NewArtifactHandler(
+	artifactRepo repository.ArtifactRepository,
+	indexer *appretrieval.Indexer,
+	validator *storyartifact.ActivationValidator,
+	applier *storyfoundation.FoundationApplier,
+	planLimits *storyfoundation.PlanLimits,
+	outlineStale *storyoutline.StaleTracker,
+	producer *messaging.Producer,
+) *ArtifactHandler {
+	return &ArtifactHandler{
+		artifactRepo: artifactRepo,
+		indexer:      indexer,
+		validator:    validator,
+		applier:      applier,
+		planLimits:   planLimits,
+		outlineStale: outlineStale,
+		producer:     producer,
+	}
 }
 
 // ListArtifacts 列出项目下构件
@@ -33,6 +63,7 @@ func NewArtifactHandler(artifactRepo repository.ArtifactRepository, indexer *app
 // @Param pid path string true "项目 ID"
 // @Success 200 {object} dto.Response[dto.ArtifactListResponse]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/artifacts [get]
 func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -59,11 +90,14 @@ func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
 // @Produce json
 // @Param pid path string true "项目 ID"
 // @Param aid path string true "构件 ID"
+// @Param branch_key query string false "按分支过滤"
+// @Param tag query string false "按标签过滤"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.ArtifactVersionListResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/artifacts/{aid}/versions [get]
 func (h *ArtifactHandler) ListVersions(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -74,6 +108,11 @@ func (h *ArtifactHandler) ListVersions(c *gin.Context) {
 		dto.BadRequest(c, "invalid branch_key: "+branchKey)
 		return
 	}
+	tag := strings.TrimSpace(c.Query("tag"))
+	if tag != "" && !isValidArtifactTag(tag) {
+		dto.BadRequest(c, "invalid tag: "+tag)
+		return
+	}
 
 	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
 	if err != nil {
@@ -87,16 +126,23 @@ func (h *ArtifactHandler) ListVersions(c *gin.Context) {
 	}
 
 	pageReq := dto.BindPage(c)
-	result, err := h.artifactRepo.ListVersions(ctx, artifactID, branchKey, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	result, err := h.artifactRepo.ListVersions(ctx, artifactID, branchKey, tag, repository.NewPagination(pageReq.Page, pageReq.PageSize))
 	if err != nil {
 		logger.Error(ctx, "failed to list artifact versions", err)
 		dto.InternalError(c, "failed to list versions")
 		return
 	}
+	tags, err := h.versionTags(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact version tags", err)
+		dto.InternalError(c, "failed to list versions")
+		return
+	}
 
 	versions := make([]*dto.ArtifactVersionResponse, 0, len(result.Items))
 	for i := range result.Items {
-		versions = append(versions, dto.ToArtifactVersionResponse(result.Items[i]))
+		v := result.Items[i]
+		versions = append(versions, dto.ToArtifactVersionResponse(v).WithTags(tags[v.ID]))
 	}
 	dto.SuccessWithPage(c, &dto.ArtifactVersionListResponse{Versions: versions}, dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total)))
 }
@@ -111,6 +157,7 @@ func (h *ArtifactHandler) ListVersions(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.ArtifactBranchListResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/artifacts/{aid}/branches [get]
 func (h *ArtifactHandler) ListBranches(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -160,19 +207,133 @@ func (h *ArtifactHandler) ListBranches(c *gin.Context) {
 	dto.Success(c, &dto.ArtifactBranchListResponse{Branches: out})
 }
 
-// CompareVersions 对比两个版本（A/B 并行对比）
+// DeleteBranch 删除构件分支（what-if 时间线）：删除分支的全部版本并清理分支记忆
+// @Summary 删除构件分支
+// @Description 删除非主线分支的全部版本，并清理该分支写入的向量片段（分支记忆），此后检索该分支将回退到主线。分支包含激活版本或带标签的版本时拒绝删除（需先切换激活版本或删除标签）
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param aid path string true "构件 ID"
+// @Param branch path string true "分支名"
+// @Success 200 {object} dto.Response[dto.ArtifactBranchDeleteResponse]
+// @Failure 400 {object} dto.ErrorResponse "主线分支不可删除"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "分支包含激活版本或带标签的版本"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/artifacts/{aid}/branches/{branch} [delete]
+func (h *ArtifactHandler) DeleteBranch(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+	artifactID := dto.BindArtifactID(c)
+
+	branchKey, _, err := normalizeBranchOptions(c.Param("branch"), nil)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	if appretrieval.IsMainBranch(branchKey) {
+		dto.BadRequest(c, "main branch cannot be deleted")
+		return
+	}
+
+	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact", err)
+		dto.InternalError(c, "failed to delete branch")
+		return
+	}
+	if art == nil || art.ProjectID != projectID {
+		dto.NotFound(c, "artifact not found")
+		return
+	}
+
+	summaries, err := h.artifactRepo.ListVersionSummaries(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact versions", err)
+		dto.InternalError(c, "failed to delete branch")
+		return
+	}
+	branchVersions := make(map[string]bool)
+	ids := make([]string, 0, len(summaries))
+	for _, v := range summaries {
+		if v != nil && v.BranchKey == branchKey {
+			branchVersions[v.ID] = true
+			ids = append(ids, v.ID)
+		}
+	}
+	if len(ids) == 0 {
+		dto.NotFound(c, "branch not found")
+		return
+	}
+
+	// 激活版本与带标签的版本受保护（DeleteVersions 会静默跳过），此处显式拒绝，避免分支只删一半
+	if art.ActiveVersionID != nil && branchVersions[strings.TrimSpace(*art.ActiveVersionID)] {
+		dto.Conflict(c, "branch contains the active version")
+		return
+	}
+	tags, err := h.artifactRepo.ListTags(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact version tags", err)
+		dto.InternalError(c, "failed to delete branch")
+		return
+	}
+	for _, t := range tags {
+		if t != nil && branchVersions[t.VersionID] {
+			dto.Conflict(c, "branch contains tagged versions")
+			return
+		}
+	}
+
+	deleted, err := h.artifactRepo.DeleteVersions(ctx, artifactID, ids)
+	if err != nil {
+		logger.Error(ctx, "failed to delete artifact branch versions", err)
+		dto.InternalError(c, "failed to delete branch")
+		return
+	}
+
+	// 清理分支记忆：失败仅记录日志（残留片段只在检索该分支时可见，不影响主线）
+	purged := false
+	if h.indexer != nil {
+		err := h.indexer.PurgeArtifactBranch(ctx, tenantID, projectID, art.Type, art.ID, branchKey)
+		switch {
+		case err == nil:
+			purged = true
+		case errors.Is(err, appretrieval.ErrVectorDisabled), errors.Is(err, appretrieval.ErrBranchIndexUnsupported):
+		default:
+			logger.Warn(ctx, "failed to purge artifact branch memory",
+				"error", err.Error(),
+				"artifact_id", art.ID,
+				"branch_key", branchKey,
+			)
+		}
+	}
+
+	dto.Success(c, &dto.ArtifactBranchDeleteResponse{
+		BranchKey:         branchKey,
+		DeletedVersionIDs: deleted,
+		MemoryPurged:      purged,
+	})
+}
+
+// CompareVersions 对比两个版本（A/B 并行对比）；两端均可用版本 ID 或标签指定
 // @Summary 对比两个构件版本
 // @Tags Artifacts
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
 // @Param aid path string true "构件 ID"
-// @Param from_version_id query string true "起始版本 ID"
-// @Param to_version_id query string true "目标版本 ID"
+// @Param from_version_id query string false "起始版本 ID（与 from_tag 二选一）"
+// @Param to_version_id query string false "目标版本 ID（与 to_tag 二选一）"
+// @Param from_tag query string false "起始版本标签"
+// @Param to_tag query string false "目标版本标签"
 // @Success 200 {object} dto.Response[dto.ArtifactCompareResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/artifacts/{aid}/compare [get]
 func (h *ArtifactHandler) CompareVersions(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -181,8 +342,10 @@ func (h *ArtifactHandler) CompareVersions(c *gin.Context) {
 
 	fromID := strings.TrimSpace(c.Query("from_version_id"))
 	toID := strings.TrimSpace(c.Query("to_version_id"))
-	if fromID == "" || toID == "" {
-		dto.BadRequest(c, "from_version_id and to_version_id are required")
+	fromTag := strings.TrimSpace(c.Query("from_tag"))
+	toTag := strings.TrimSpace(c.Query("to_tag"))
+	if (fromID == "") == (fromTag == "") || (toID == "") == (toTag == "") {
+		dto.BadRequest(c, "exactly one of from_version_id/from_tag and one of to_version_id/to_tag are required")
 		return
 	}
 
@@ -197,23 +360,23 @@ func (h *ArtifactHandler) CompareVersions(c *gin.Context) {
 		return
 	}
 
-	fromV, err := h.artifactRepo.GetVersionByID(ctx, fromID)
+	fromV, err := h.resolveVersion(ctx, artifactID, fromID, fromTag)
 	if err != nil {
 		logger.Error(ctx, "failed to get from version", err)
 		dto.InternalError(c, "failed to compare versions")
 		return
 	}
-	toV, err := h.artifactRepo.GetVersionByID(ctx, toID)
+	toV, err := h.resolveVersion(ctx, artifactID, toID, toTag)
 	if err != nil {
 		logger.Error(ctx, "failed to get to version", err)
 		dto.InternalError(c, "failed to compare versions")
 		return
 	}
-	if fromV == nil || fromV.ArtifactID != artifactID {
+	if fromV == nil {
 		dto.NotFound(c, "from version not found")
 		return
 	}
-	if toV == nil || toV.ArtifactID != artifactID {
+	if toV == nil {
 		dto.NotFound(c, "to version not found")
 		return
 	}
@@ -225,17 +388,199 @@ func (h *ArtifactHandler) CompareVersions(c *gin.Context) {
 		return
 	}
 
+	tags, err := h.versionTags(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact version tags", err)
+		dto.InternalError(c, "failed to compare versions")
+		return
+	}
+
 	dto.Success(c, &dto.ArtifactCompareResponse{
 		ArtifactID: art.ID,
 		Type:       string(art.Type),
-		From:       dto.ToArtifactVersionResponse(fromV),
-		To:         dto.ToArtifactVersionResponse(toV),
+		From:       dto.ToArtifactVersionResponse(fromV).WithTags(tags[fromV.ID]),
+		To:         dto.ToArtifactVersionResponse(toV).WithTags(tags[toV.ID]),
 		Diff:       diff,
 	})
 }
 
+// ListTags 列出构件的版本标签
+// @Summary 列出构件版本标签
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param aid path string true "构件 ID"
+// @Success 200 {object} dto.Response[dto.ArtifactTagListResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/artifacts/{aid}/tags [get]
+func (h *ArtifactHandler) ListTags(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+	artifactID := dto.BindArtifactID(c)
+
+	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact", err)
+		dto.InternalError(c, "failed to list tags")
+		return
+	}
+	if art == nil || art.ProjectID != projectID {
+		dto.NotFound(c, "artifact not found")
+		return
+	}
+
+	tags, err := h.artifactRepo.ListTags(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact version tags", err)
+		dto.InternalError(c, "failed to list tags")
+		return
+	}
+
+	out := make([]*dto.ArtifactTagResponse, 0, len(tags))
+	for i := range tags {
+		out = append(out, dto.ToArtifactTagResponse(tags[i]))
+	}
+	dto.Success(c, &dto.ArtifactTagListResponse{Tags: out})
+}
+
+// CreateTag 为构件版本打标签（命名发布）；被标记的版本不会被版本清理删除
+// @Summary 为构件版本打标签
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param aid path string true "构件 ID"
+// @Param vid path string true "版本 ID"
+// @Param body body dto.ArtifactTagCreateRequest true "标签"
+// @Success 201 {object} dto.Response[dto.ArtifactTagResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/artifacts/{aid}/versions/{vid}/tags [post]
+func (h *ArtifactHandler) CreateTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := strings.TrimSpace(middleware.GetUserIDFromGin(c))
+	projectID := dto.BindProjectID(c)
+	artifactID := dto.BindArtifactID(c)
+	versionID := strings.TrimSpace(c.Param("vid"))
+
+	var req dto.ArtifactTagCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	tagName := strings.TrimSpace(req.Tag)
+	if !isValidArtifactTag(tagName) {
+		dto.BadRequest(c, "invalid tag: must be 1-64 printable characters without '/'")
+		return
+	}
+
+	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact", err)
+		dto.InternalError(c, "failed to create tag")
+		return
+	}
+	if art == nil || art.ProjectID != projectID {
+		dto.NotFound(c, "artifact not found")
+		return
+	}
+
+	version, err := h.artifactRepo.GetVersionByID(ctx, versionID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact version", err)
+		dto.InternalError(c, "failed to create tag")
+		return
+	}
+	if version == nil || version.ArtifactID != artifactID {
+		dto.NotFound(c, "version not found")
+		return
+	}
+
+	existing, err := h.artifactRepo.GetTag(ctx, artifactID, tagName)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact version tag", err)
+		dto.InternalError(c, "failed to create tag")
+		return
+	}
+	if existing != nil {
+		dto.Conflict(c, "tag already exists on version: "+existing.VersionID)
+		return
+	}
+
+	tag := &entity.ArtifactVersionTag{
+		TenantID:   tenantID,
+		ArtifactID: artifactID,
+		VersionID:  version.ID,
+		Tag:        tagName,
+		Note:       strings.TrimSpace(req.Note),
+	}
+	if userID != "" {
+		tag.CreatedBy = &userID
+	}
+	if err := h.artifactRepo.CreateTag(ctx, tag); err != nil {
+		logger.Error(ctx, "failed to create artifact version tag", err)
+		dto.InternalError(c, "failed to create tag")
+		return
+	}
+	tag.VersionNo = version.VersionNo
+	tag.BranchKey = version.BranchKey
+
+	dto.Created(c, dto.ToArtifactTagResponse(tag))
+}
+
+// DeleteTag 删除构件版本标签（版本本身保留，之后可被版本清理删除）
+// @Summary 删除构件版本标签
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param aid path string true "构件 ID"
+// @Param tag path string true "标签"
+// @Success 204
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/artifacts/{aid}/tags/{tag} [delete]
+func (h *ArtifactHandler) DeleteTag(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+	artifactID := dto.BindArtifactID(c)
+	tagName := strings.TrimSpace(c.Param("tag"))
+
+	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact", err)
+		dto.InternalError(c, "failed to delete tag")
+		return
+	}
+	if art == nil || art.ProjectID != projectID {
+		dto.NotFound(c, "artifact not found")
+		return
+	}
+
+	deleted, err := h.artifactRepo.DeleteTag(ctx, artifactID, tagName)
+	if err != nil {
+		logger.Error(ctx, "failed to delete artifact version tag", err)
+		dto.InternalError(c, "failed to delete tag")
+		return
+	}
+	if !deleted {
+		dto.NotFound(c, "tag not found")
+		return
+	}
+	dto.NoContent(c)
+}
+
 // Rollback 回滚构件到指定版本（只切 active_version_id）
 // @Summary 回滚构件到指定版本
+// @Description 切换构件激活版本；outline 构件切换时，关联到内容修改或跨卷移动条目的章节标记为 outline_stale（见项目健康检查），项目开启 outline_refresh 时自动提交大纲刷新任务
 // @Tags Artifacts
 // @Accept json
 // @Produce json
@@ -245,7 +590,10 @@ func (h *ArtifactHandler) CompareVersions(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.ArtifactRollbackResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse "租户校验 Webhook 不通过"
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse "校验 Webhook 不可用"
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/artifacts/{aid}/rollback [post]
 func (h *ArtifactHandler) Rollback(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -255,7 +603,7 @@ func (h *ArtifactHandler) Rollback(c *gin.Context) {
 
 	var req dto.ArtifactRollbackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -281,11 +629,37 @@ func (h *ArtifactHandler) Rollback(c *gin.Context) {
 		return
 	}
 
+	if err := h.validator.Check(ctx, &storyartifact.ActivationValidationRequest{
+		TenantID:     tenantID,
+		ProjectID:    projectID,
+		ArtifactID:   art.ID,
+		ArtifactType: art.Type,
+		VersionID:    version.ID,
+		VersionNo:    version.VersionNo,
+		Trigger:      storyartifact.ActivationTriggerRollback,
+		Content:      version.Content,
+	}); err != nil {
+		if writeActivationValidationError(c, err) {
+			return
+		}
+		logger.Error(ctx, "failed to validate artifact activation", err)
+		dto.InternalError(c, "failed to rollback")
+		return
+	}
+
+	previousVersionID := art.ActiveVersionID
 	if err := h.artifactRepo.SetActiveVersion(ctx, artifactID, version.ID); err != nil {
 		logger.Error(ctx, "failed to set active version", err)
 		dto.InternalError(c, "failed to rollback")
 		return
 	}
+	outlineMark, err := h.outlineStale.MarkActivated(ctx, art, previousVersionID, version)
+	if err != nil {
+		logger.Error(ctx, "failed to mark outline stale chapters", err)
+		dto.InternalError(c, "failed to rollback")
+		return
+	}
+	publishOutlineRefresh(ctx, h.producer, outlineMark)
 
 	// 同步写索引：回滚只切 active_version_id，因此直接用目标版本内容重建索引。
 	if h.indexer != nil {
@@ -303,3 +677,207 @@ func (h *ArtifactHandler) Rollback(c *gin.Context) {
 		Version:  dto.ToArtifactVersionResponse(version),
 	})
 }
+
+// Apply 将构件版本转换为 FoundationPlan 并增量落库
+// @Summary 应用构件版本（落库）
+// @Description 将 worldview/characters/outline 构件的激活版本（或按类型指定的版本）转换为 FoundationPlan，幂等写入 Project/Entity/Relation/Volume/Chapter；未选中的构件对应数据保持不变
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param body body dto.ArtifactApplyRequest false "应用请求"
+// @Success 200 {object} dto.Response[dto.ArtifactApplyResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "没有可应用的构件版本"
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/artifacts/apply [post]
+func (h *ArtifactHandler) Apply(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+
+	var req dto.ArtifactApplyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+	types, err := applyArtifactTypes(&req)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	arts, err := h.artifactRepo.ListArtifactsByProject(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifacts", err)
+		dto.InternalError(c, "failed to apply artifacts")
+		return
+	}
+	byType := make(map[entity.ArtifactType]*entity.ProjectArtifact, len(arts))
+	for _, a := range arts {
+		if a != nil {
+			byType[a.Type] = a
+		}
+	}
+
+	contents := make(map[entity.ArtifactType]json.RawMessage, len(types))
+	sources := make([]*dto.ArtifactApplySource, 0, len(types))
+	for _, t := range types {
+		versionID := strings.TrimSpace(req.VersionIDs[string(t)])
+		// 显式选择的类型必须有可用版本；默认模式下跳过尚无激活版本的构件
+		explicit := len(req.Types) > 0 || versionID != ""
+		art := byType[t]
+		if versionID == "" && art != nil && art.ActiveVersionID != nil {
+			versionID = *art.ActiveVersionID
+		}
+		if art == nil || versionID == "" {
+			if explicit {
+				dto.NotFound(c, "no version to apply for artifact: "+string(t))
+				return
+			}
+			continue
+		}
+
+		version, err := h.resolveVersion(ctx, art.ID, versionID, "")
+		if err != nil {
+			logger.Error(ctx, "failed to get artifact version", err)
+			dto.InternalError(c, "failed to apply artifacts")
+			return
+		}
+		if version == nil {
+			dto.NotFound(c, "version not found for artifact: "+string(t))
+			return
+		}
+		contents[t] = version.Content
+		sources = append(sources, &dto.ArtifactApplySource{
+			Type:       string(t),
+			ArtifactID: art.ID,
+			VersionID:  version.ID,
+			VersionNo:  version.VersionNo,
+		})
+	}
+	if len(contents) == 0 {
+		dto.Conflict(c, "no active artifact versions to apply")
+		return
+	}
+
+	plan, err := storyartifact.BuildFoundationPlan(contents)
+	if err != nil {
+		dto.UnprocessableEntity(c, "invalid artifact content", &dto.ErrorDetail{
+			ErrorCode: "artifact_invalid",
+			Details:   err.Error(),
+		})
+		return
+	}
+	if err := storyfoundation.ValidateFoundationPlan(plan, h.planLimits.ForTenant(ctx, middleware.GetTenantIDFromGin(c))); err != nil {
+		writeFoundationPlanError(c, err)
+		return
+	}
+
+	if h.applier == nil {
+		dto.InternalError(c, "foundation applier not configured")
+		return
+	}
+	result, err := h.applier.Apply(ctx, projectID, plan)
+	if err != nil {
+		var regErr timeline.RegressionError
+		if errors.As(err, &regErr) {
+			dto.UnprocessableEntity(c, "story time regression", &dto.ErrorDetail{
+				ErrorCode: "story_time_regression",
+				Details:   regErr.Error(),
+			})
+			return
+		}
+		logger.Error(ctx, "failed to apply artifacts", err)
+		dto.InternalError(c, "failed to apply artifacts")
+		return
+	}
+
+	dto.Success(c, &dto.ArtifactApplyResponse{
+		ProjectID: projectID,
+		Sources:   sources,
+		Result:    result,
+	})
+}
+
+// applyArtifactTypes 解析要应用的构件类型（去重并按 worldview/characters/outline 顺序）；
+// 未指定 types 时为全部可转换类型
+func applyArtifactTypes(req *dto.ArtifactApplyRequest) ([]entity.ArtifactType, error) {
+	if len(req.Types) == 0 {
+		for t := range req.VersionIDs {
+			if !storyartifact.IsFoundationPlanArtifactType(entity.ArtifactType(t)) {
+				return nil, errors.New("unsupported artifact type in version_ids: " + t)
+			}
+		}
+		return storyartifact.FoundationPlanArtifactTypes, nil
+	}
+
+	selected := make(map[entity.ArtifactType]bool, len(req.Types))
+	for _, t := range req.Types {
+		at := entity.ArtifactType(strings.TrimSpace(t))
+		if !storyartifact.IsFoundationPlanArtifactType(at) {
+			return nil, errors.New("unsupported artifact type: " + t)
+		}
+		selected[at] = true
+	}
+	for t := range req.VersionIDs {
+		if !selected[entity.ArtifactType(t)] {
+			return nil, errors.New("version_ids contains type not listed in types: " + t)
+		}
+	}
+	out := make([]entity.ArtifactType, 0, len(selected))
+	for _, t := range storyartifact.FoundationPlanArtifactTypes {
+		if selected[t] {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// resolveVersion 按版本 ID 或标签定位构件版本；不存在或不属于该构件时返回 nil
+func (h *ArtifactHandler) resolveVersion(ctx context.Context, artifactID, versionID, tag string) (*entity.ArtifactVersion, error) {
+	if tag != "" {
+		t, err := h.artifactRepo.GetTag(ctx, artifactID, tag)
+		if err != nil || t == nil {
+			return nil, err
+		}
+		versionID = t.VersionID
+	}
+	v, err := h.artifactRepo.GetVersionByID(ctx, versionID)
+	if err != nil || v == nil || v.ArtifactID != artifactID {
+		return nil, err
+	}
+	return v, nil
+}
+
+// versionTags 返回构件各版本的标签（version_id -> 标签列表）
+func (h *ArtifactHandler) versionTags(ctx context.Context, artifactID string) (map[string][]string, error) {
+	tags, err := h.artifactRepo.ListTags(ctx, artifactID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]string, len(tags))
+	for _, t := range tags {
+		if t != nil {
+			out[t.VersionID] = append(out[t.VersionID], t.Tag)
+		}
+	}
+	return out, nil
+}
+
+// isValidArtifactTag 标签：1-64 个可打印字符（允许空格与中文），不含 '/'（标签会出现在 URL 路径中）
+func isValidArtifactTag(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > entity.ArtifactTagMaxRunes {
+		return false
+	}
+	for _, r := range s {
+		if r == '/' || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -49,7 +49,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -139,7 +139,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -186,6 +186,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 }
 
 // RefreshToken 刷新 Token (保持原有逻辑但适配 DTO)
+// @Summary 刷新 Access Token
+// @Description 使用 HttpOnly Cookie 中的 refresh_token 换取新的 Access Token
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} dto.Response[dto.RefreshTokenResponse]
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /v1/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	refreshToken, err := c.Cookie("refresh_token")
 	if err != nil {
@@ -205,13 +212,20 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	dto.Success(c, gin.H{
-		"access_token": newAccessToken,
-		"expires_in":   900,
+	dto.Success(c, &dto.RefreshTokenResponse{
+		AccessToken: newAccessToken,
+		ExpiresIn:   900,
 	})
 }
 
 // Logout 登出
+// @Summary 用户登出
+// @Description 清除 refresh_token Cookie
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} dto.Response[map[string]interface{}]
+// @Security BearerAuth
+// @Router /v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	c.SetCookie("refresh_token", "", -1, "/v1/auth/refresh", "", false, true)
 	dto.Success(c, gin.H{"message": "logged out success"})
@@ -2,13 +2,25 @@ package handler
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"z-novel-ai-api/internal/application/quota"
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/messaging"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
 )
 
 // resolveProviderModel 解析 LLM Provider 和 Model
@@ -43,6 +55,20 @@ func resolveProviderModel(cfg *config.Config, provider, model string) (string, s
 	return p, m, nil
 }
 
+// resolveProjectProviderModel 解析章节生成的 Provider/Model：请求指定 provider 时优先；
+// 否则使用项目设置的默认 provider（请求仅指定 model 时沿用该 model），最后回退全局默认。
+func resolveProjectProviderModel(cfg *config.Config, settings *entity.ProjectSettings, provider, model string) (string, string, error) {
+	if strings.TrimSpace(provider) == "" {
+		if p, m := settings.DefaultProviderModel(); p != "" {
+			provider = p
+			if strings.TrimSpace(model) == "" {
+				model = m
+			}
+		}
+	}
+	return resolveProviderModel(cfg, provider, model)
+}
+
 // precheckQuota 检查余额是否足以进行至少一次基础调用
 func precheckQuota(ctx context.Context, quotaChecker *quota.TokenQuotaChecker, tenant *entity.Tenant) error {
 	if quotaChecker == nil {
@@ -53,6 +79,131 @@ func precheckQuota(ctx context.Context, quotaChecker *quota.TokenQuotaChecker, t
 	return err
 }
 
+// precheckFeatureQuota 在基础余额预查之外，校验租户套餐是否开通对应功能
+func precheckFeatureQuota(ctx context.Context, quotaChecker *quota.TokenQuotaChecker, tenant *entity.Tenant, feature entity.PlanFeature) error {
+	if quotaChecker == nil {
+		return nil
+	}
+	if err := quotaChecker.CheckFeature(ctx, tenant.ID, feature); err != nil {
+		return err
+	}
+	return precheckQuota(ctx, quotaChecker, tenant)
+}
+
+// writeQuotaLimitError 将余额/并发/套餐功能限制错误写入响应；非此类错误返回 false 由调用方处理
+func writeQuotaLimitError(c *gin.Context, err error) bool {
+	var exceeded quota.TokenBalanceExceededError
+	if stderrors.As(err, &exceeded) {
+		dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
+		return true
+	}
+	var concurrency quota.ConcurrencyLimitExceededError
+	if stderrors.As(err, &concurrency) {
+		dto.Error(c, http.StatusTooManyRequests, "concurrent job limit reached")
+		return true
+	}
+	var feature quota.PlanFeatureUnavailableError
+	if stderrors.As(err, &feature) {
+		dto.Forbidden(c, "feature not available in current plan: "+string(feature.Feature))
+		return true
+	}
+	return false
+}
+
+// writeActivationValidationError 构件激活被租户校验 Webhook 拒绝时写入 422（附校验消息），
+// Webhook 不可用时写入 503；其他错误返回 false
+func writeActivationValidationError(c *gin.Context, err error) bool {
+	if stderrors.Is(err, storyartifact.ErrActivationRejected) {
+		dto.UnprocessableEntity(c, "artifact validation failed", activationBlockedDetail(err))
+		return true
+	}
+	if stderrors.Is(err, storyartifact.ErrActivationValidationUnavailable) {
+		logger.Warn(c.Request.Context(), "artifact validation webhook unavailable", "error", err.Error())
+		dto.ErrorWithDetail(c, http.StatusServiceUnavailable, "artifact validation unavailable", activationBlockedDetail(err))
+		return true
+	}
+	return false
+}
+
+// activationBlockedDetail 将激活校验失败转换为响应详情（用于保存但不激活的场景）
+func activationBlockedDetail(err error) *dto.ErrorDetail {
+	var rejected *storyartifact.ActivationRejectedError
+	if !stderrors.As(err, &rejected) {
+		code := "artifact_validation_unavailable"
+		if !stderrors.Is(err, storyartifact.ErrActivationValidationUnavailable) {
+			code = "artifact_validation_error"
+		}
+		return &dto.ErrorDetail{ErrorCode: code, Details: err.Error()}
+	}
+	detail := &dto.ErrorDetail{ErrorCode: "artifact_validation_failed", Details: rejected.Error()}
+	for _, m := range rejected.Messages {
+		detail.Issues = append(detail.Issues, dto.ErrorIssue{Code: m.Code, Message: m.Message, Path: m.Path, Severity: m.Severity})
+	}
+	return detail
+}
+
+// admitQueuedJob 生成队列背压：估算新任务的排队位置与开始时间（只计优先级不低于该任务的任务流）；
+// 队列深度达到阈值时写入 503（附 Retry-After）并返回 false。Worker 未上报统计（未运行或统计过期）时既不估算也不拒绝。
+func admitQueuedJob(c *gin.Context, cfg *config.Config, producer *messaging.Producer, priority int) (*messaging.QueueEstimate, bool) {
+	if cfg == nil || producer == nil {
+		return nil, true
+	}
+	ctx := c.Request.Context()
+	bp := cfg.Messaging.Backpressure
+
+	stats, err := producer.QueueStatsAhead(ctx, priority, 3*bp.StatsInterval)
+	if err != nil {
+		logger.Warn(ctx, "failed to load queue stats", "error", err.Error())
+		return nil, true
+	}
+	if stats == nil {
+		return nil, true
+	}
+
+	estimate := stats.Estimate(time.Now(), bp.DefaultJobDuration)
+	if bp.RejectQueueDepth > 0 && stats.Depth() >= int64(bp.RejectQueueDepth) {
+		retryAfter := int(math.Ceil(estimate.Wait.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		dto.ErrorWithDetail(c, http.StatusServiceUnavailable, "generation queue is full", &dto.ErrorDetail{
+			ErrorCode: "queue_full",
+			Details:   fmt.Sprintf("queue depth %d reached limit %d", stats.Depth(), bp.RejectQueueDepth),
+		})
+		return nil, false
+	}
+	return estimate, true
+}
+
+// queuedJobResponse 入队任务响应（附排队估算）
+func queuedJobResponse(job *entity.GenerationJob, estimate *messaging.QueueEstimate) *dto.JobResponse {
+	resp := dto.ToJobResponse(job)
+	if estimate == nil {
+		return resp
+	}
+	return resp.WithQueueEstimate(estimate.Position, estimate.Wait, estimate.StartAt)
+}
+
+// publishOutlineRefresh 发布大纲激活时创建的大纲刷新任务（需在写入任务的事务提交后调用）；
+// 发布失败只记录日志：章节仍保持大纲过期标记，可通过 POST /v1/projects/:pid/jobs 手动提交 outline_refresh
+func publishOutlineRefresh(ctx context.Context, producer *messaging.Producer, mark *storyoutline.StaleMark) {
+	if mark == nil || mark.RefreshJob == nil || producer == nil {
+		return
+	}
+	job := mark.RefreshJob
+	if _, err := producer.PublishMaintenanceJob(ctx, &messaging.GenerationJobMessage{
+		JobID:     job.ID,
+		TenantID:  job.TenantID,
+		ProjectID: job.ProjectID,
+		JobType:   string(job.JobType),
+		Priority:  job.Priority,
+		Params:    mark.RefreshParams,
+	}); err != nil {
+		logger.Warn(ctx, "failed to publish outline refresh job", "error", err.Error(), "job_id", job.ID, "project_id", job.ProjectID)
+	}
+}
+
 // withTenantTx 在租户事务中执行
 func withTenantTx(ctx context.Context, txMgr repository.Transactor, tenantCtx repository.TenantContextManager, tenantID string, fn func(context.Context) error) error {
 	if txMgr == nil || tenantCtx == nil {
@@ -0,0 +1,176 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"z-novel-ai-api/internal/application/billing"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxWebhookPayloadBytes 支付回调请求体上限
+const maxWebhookPayloadBytes = 1 << 20
+
+// BillingHandler 计费处理器（支付回调入账与购买记录）
+type BillingHandler struct {
+	billingService *billing.Service
+	txMgr          repository.Transactor
+	tenantCtx      repository.TenantContextManager
+}
+
+// NewBillingHandler 创建计费处理器
+func NewBillingHandler(billingService *billing.Service, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *BillingHandler {
+	return &BillingHandler{
+		billingService: billingService,
+		txMgr:          txMgr,
+		tenantCtx:      tenantCtx,
+	}
+}
+
+// HandleWebhook 处理支付提供商回调
+// @Summary 支付回调
+// @Description 校验支付提供商签名；支付成功时记录账单并为租户增加 Token 余额（同一笔支付重复回调只入账一次）
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.Response[dto.BillingWebhookResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Router /webhooks/billing [post]
+func (h *BillingHandler) HandleWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	if !h.billingService.Enabled() {
+		dto.ServiceUnavailable(c, "billing not configured")
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayloadBytes))
+	if err != nil {
+		if dto.BodyTooLarge(c, err) {
+			return
+		}
+		dto.BadRequest(c, "failed to read request body")
+		return
+	}
+
+	event, err := h.billingService.ParseWebhook(c.Request.Header, payload)
+	if err != nil {
+		if stderrors.Is(err, billing.ErrInvalidSignature) {
+			logger.Warn(ctx, "billing webhook signature rejected", "error", err.Error())
+			dto.Unauthorized(c, "invalid signature")
+			return
+		}
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	if !event.Succeeded {
+		dto.Success(c, &dto.BillingWebhookResponse{Received: true, Ignored: true})
+		return
+	}
+	if _, err := uuid.Parse(event.TenantID); err != nil {
+		dto.BadRequest(c, "invalid tenant_id")
+		return
+	}
+
+	var result *billing.PaymentResult
+	err = withTenantTx(ctx, h.txMgr, h.tenantCtx, event.TenantID, func(txCtx context.Context) error {
+		var err error
+		result, err = h.billingService.HandlePayment(txCtx, event)
+		return err
+	})
+	if err != nil {
+		if stderrors.Is(err, billing.ErrInvalidPayload) || stderrors.Is(err, billing.ErrTenantNotFound) {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error(ctx, "failed to handle billing webhook", err, "event_id", event.EventID)
+		dto.InternalError(c, "failed to handle payment")
+		return
+	}
+
+	if result.Duplicate {
+		logger.Info(ctx, "billing webhook duplicate payment ignored", "event_id", event.EventID, "external_id", event.ExternalID)
+	} else {
+		logger.Info(ctx, "tenant tokens credited", "tenant_id", event.TenantID, "invoice_id", result.Invoice.ID, "tokens", result.Invoice.Tokens)
+	}
+	dto.Success(c, &dto.BillingWebhookResponse{
+		Received:  true,
+		Duplicate: result.Duplicate,
+		InvoiceID: result.Invoice.ID,
+	})
+}
+
+// ListInvoices 获取当前租户购买记录
+// @Summary 获取购买记录
+// @Description 分页获取当前租户的 Token 充值账单（按支付时间倒序）
+// @Tags Billing
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} dto.Response[dto.InvoiceListResponse]
+// @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/billing/invoices [get]
+func (h *BillingHandler) ListInvoices(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	pageReq := dto.BindPage(c)
+
+	result, err := h.billingService.ListInvoices(ctx, tenantID, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	if err != nil {
+		logger.Error(ctx, "failed to list invoices", err)
+		dto.InternalError(c, "failed to list invoices")
+		return
+	}
+
+	items := make([]*dto.InvoiceResponse, len(result.Items))
+	for i, inv := range result.Items {
+		items[i] = dto.ToInvoiceResponse(inv)
+	}
+
+	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
+	dto.SuccessWithPage(c, &dto.InvoiceListResponse{Items: items}, meta)
+}
+
+// GetInvoice 获取账单详情
+// @Summary 获取账单详情
+// @Tags Billing
+// @Produce json
+// @Param iid path string true "账单 ID"
+// @Success 200 {object} dto.Response[dto.InvoiceResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/billing/invoices/{iid} [get]
+func (h *BillingHandler) GetInvoice(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	invoiceID := c.Param("iid")
+	if _, err := uuid.Parse(invoiceID); err != nil {
+		dto.NotFound(c, "invoice not found")
+		return
+	}
+
+	invoice, err := h.billingService.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		logger.Error(ctx, "failed to get invoice", err)
+		dto.InternalError(c, "failed to get invoice")
+		return
+	}
+	if invoice == nil || invoice.TenantID != tenantID {
+		dto.NotFound(c, "invoice not found")
+		return
+	}
+
+	dto.Success(c, dto.ToInvoiceResponse(invoice))
+}
@@ -0,0 +1,518 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/messaging"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CandidateHandler 多候选生成（best-of-N）的候选查看与选择
+type CandidateHandler struct {
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+
+	jobRepo       repository.JobRepository
+	candidateRepo repository.GenerationCandidateRepository
+	chapterRepo   repository.ChapterRepository
+	artifactRepo  repository.ArtifactRepository
+	projectRepo   repository.ProjectRepository
+	projectLocker repository.ProjectLocker
+
+	finalizer   *appstory.GenerationFinalizer
+	indexer     *appretrieval.Indexer
+	jobTimeline *appstory.JobTimeline
+	validator   *storyartifact.ActivationValidator
+
+	outlineStale *storyoutline.StaleTracker
+	producer     *messaging.Producer
+}
+
+// NewCandidateHandler 创建候选处理器
+func NewCandidateHandler(
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	jobRepo repository.JobRepository,
+	candidateRepo repository.GenerationCandidateRepository,
+	chapterRepo repository.ChapterRepository,
+	artifactRepo repository.ArtifactRepository,
+	projectRepo repository.ProjectRepository,
+	projectLocker repository.ProjectLocker,
+	finalizer *appstory.GenerationFinalizer,
+	indexer *appretrieval.Indexer,
+	jobTimeline *appstory.JobTimeline,
+	validator *storyartifact.ActivationValidator,
+	outlineStale *storyoutline.StaleTracker,
+	producer *messaging.Producer,
+) *CandidateHandler {
+	return &CandidateHandler{
+		txMgr:         txMgr,
+		tenantCtx:     tenantCtx,
+		jobRepo:       jobRepo,
+		candidateRepo: candidateRepo,
+		chapterRepo:   chapterRepo,
+		artifactRepo:  artifactRepo,
+		projectRepo:   projectRepo,
+		projectLocker: projectLocker,
+		finalizer:     finalizer,
+		indexer:       indexer,
+		jobTimeline:   jobTimeline,
+		validator:     validator,
+		outlineStale:  outlineStale,
+		producer:      producer,
+	}
+}
+
+// ListCandidates 获取任务的候选结果
+// @Summary 获取任务的候选结果
+// @Description 多候选生成（best-of-N）任务的全部候选，含质量评分、状态与内容；单次生成的任务返回空列表
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Success 200 {object} dto.Response[dto.CandidateListResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/candidates [get]
+func (h *CandidateHandler) ListCandidates(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobID := dto.BindJobID(c)
+
+	job, err := h.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to get job", err)
+		dto.InternalError(c, "failed to get job")
+		return
+	}
+	if job == nil {
+		dto.NotFound(c, "job not found")
+		return
+	}
+
+	candidates, err := h.candidateRepo.ListByJob(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to list generation candidates", err)
+		dto.InternalError(c, "failed to list candidates")
+		return
+	}
+	dto.Success(c, dto.ToCandidateListResponse(jobID, candidates))
+}
+
+// SelectCandidate 采用候选结果
+// @Summary 采用候选结果
+// @Description 将候选写入章节正文，或以候选内容新建构件版本（沿用生成时的分支与激活设置）；同任务其余候选记为 discarded。
+// @Description 可对已采用过候选的任务再次调用以切换结果。
+// @Description 流式生成被中断（cancelled_partial）时保存的部分正文（partial=true）也可原样采用，章节保持 draft 状态。
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Param cand path string true "候选 ID"
+// @Success 200 {object} dto.Response[dto.SelectCandidateResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse "租户校验 Webhook 不通过"
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse "校验 Webhook 不可用"
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/candidates/{cand}/select [post]
+func (h *CandidateHandler) SelectCandidate(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := middleware.GetUserIDFromGin(c)
+	jobID := dto.BindJobID(c)
+	candidateID := dto.BindCandidateID(c)
+
+	// 构件候选激活前的租户校验（外部调用，不放在事务内）
+	if err := h.checkArtifactActivation(ctx, tenantID, jobID, candidateID); err != nil {
+		if writeActivationValidationError(c, err) {
+			return
+		}
+		logger.Error(ctx, "failed to validate artifact activation", err)
+		dto.InternalError(c, "failed to select candidate")
+		return
+	}
+
+	resp := &dto.SelectCandidateResponse{}
+	var chapterForIndex *appstory.ChapterIndexSnapshot
+	var indexArtifact *entity.ProjectArtifact
+	var indexBranch string
+	var indexActivated bool
+	var outlineMark *storyoutline.StaleMark
+	// 本接口不持有请求级事务（见 middleware.DBTransaction）：落库在短事务内完成，章节/构件索引在提交后写入
+	err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		job, err := h.jobRepo.GetByID(txCtx, jobID)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return errNotFound("job not found")
+		}
+		candidate, err := h.candidateRepo.GetByID(txCtx, candidateID)
+		if err != nil {
+			return err
+		}
+		if candidate == nil || candidate.JobID != job.ID {
+			return errNotFound("candidate not found")
+		}
+		// 被中断的流式任务保存的部分内容可原样采用
+		if job.Status != entity.JobStatusCompleted && !(job.Status == entity.JobStatusCancelledPartial && candidate.Partial) {
+			return candidateStateError{msg: "job is not completed"}
+		}
+
+		switch candidate.TargetType {
+		case entity.CandidateTargetChapter:
+			// 共享锁：与生成收尾一致，写回章节期间阻止项目结构调整
+			if err := h.projectLocker.LockProjectShared(txCtx, job.ProjectID); err != nil {
+				return err
+			}
+			chapter, err := h.chapterRepo.GetByID(txCtx, candidate.TargetID)
+			if err != nil {
+				return err
+			}
+			if chapter == nil {
+				return errNotFound("chapter not found")
+			}
+			if chapter.Status == entity.ChapterStatusGenerating {
+				return candidateStateError{msg: "chapter is being generated"}
+			}
+			if chapterForIndex, err = h.finalizer.SelectChapterCandidate(txCtx, job, chapter, candidate); err != nil {
+				return err
+			}
+			resp.Chapter = dto.ToChapterResponse(chapter)
+
+		case entity.CandidateTargetArtifact:
+			art, err := h.artifactRepo.GetArtifactByID(txCtx, candidate.TargetID)
+			if err != nil {
+				return err
+			}
+			if art == nil {
+				return errNotFound("artifact not found")
+			}
+			project, err := h.projectRepo.GetByID(txCtx, art.ProjectID)
+			if err != nil {
+				return err
+			}
+			if project == nil {
+				return errNotFound("project not found")
+			}
+			apply := candidate.Apply
+			if apply == nil {
+				apply = &entity.CandidateApplyOptions{BranchKey: "main", Activate: true}
+			}
+			version, mark, err := persistArtifactVersion(txCtx, h.artifactRepo, h.projectRepo, h.outlineStale, project, art, artifactVersionInput{
+				Content:         candidate.ArtifactContent(),
+				BranchKey:       apply.BranchKey,
+				ParentVersionID: apply.ParentVersionID,
+				Activate:        apply.Activate,
+				CreatedBy:       userID,
+				SourceJobID:     job.ID,
+				Metadata: &entity.ArtifactVersionMetadata{
+					ActivationPolicy: project.Settings.ArtifactActivationPolicy(),
+					Activated:        apply.Activate,
+					ActivationReason: entity.ActivationReasonCandidateApply,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			outlineMark = mark
+			if err := h.candidateRepo.MarkSelected(txCtx, job.ID, candidate.ID, &version.ID); err != nil {
+				return err
+			}
+			job.OutputResult = version.Content
+			if err := h.jobRepo.Update(txCtx, job); err != nil {
+				return err
+			}
+			h.jobTimeline.Record(txCtx, job, entity.JobEventSelected, "candidate selected", map[string]any{
+				"candidate_id": candidate.ID,
+				"score":        candidate.Score,
+				"version_id":   version.ID,
+				"version_no":   version.VersionNo,
+			})
+			indexArtifact, indexBranch, indexActivated = art, apply.BranchKey, apply.Activate
+			resp.ArtifactSnapshot = &dto.ArtifactSnapshotResponse{
+				ArtifactID: art.ID,
+				Type:       string(art.Type),
+				VersionID:  version.ID,
+				VersionNo:  version.VersionNo,
+				Content:    version.Content,
+			}
+
+		default:
+			return fmt.Errorf("unknown candidate target type: %s", candidate.TargetType)
+		}
+
+		if candidate, err = h.candidateRepo.GetByID(txCtx, candidate.ID); err != nil {
+			return err
+		}
+		resp.Candidate = dto.ToCandidateResponse(candidate, true)
+		return nil
+	})
+	if err != nil {
+		var stateErr candidateStateError
+		switch {
+		case isNotFound(err):
+			dto.NotFound(c, err.Error())
+		case errors.As(err, &stateErr):
+			dto.Conflict(c, stateErr.Error())
+		default:
+			logger.Error(ctx, "failed to select generation candidate", err)
+			dto.InternalError(c, "failed to select candidate")
+		}
+		return
+	}
+
+	publishOutlineRefresh(ctx, h.producer, outlineMark)
+
+	// 同步写索引（事务提交之后，失败仅记录任务警告）
+	var indexErr error
+	if chapterForIndex != nil {
+		indexErr = h.finalizer.IndexChapter(ctx, tenantID, chapterForIndex)
+	}
+	if indexArtifact != nil {
+		if err := indexArtifactVersion(h.indexer, tenantID, indexArtifact.ProjectID, indexArtifact.Type, indexArtifact.ID, indexBranch, indexActivated, resp.ArtifactSnapshot.Content); err != nil {
+			logger.Warn(ctx, "failed to index artifact after candidate selection",
+				"error", err.Error(),
+				"artifact_id", indexArtifact.ID,
+				"branch_key", indexBranch,
+			)
+			indexErr = err
+		}
+	}
+	if indexErr != nil {
+		if werr := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+			return h.jobRepo.AppendWarnings(txCtx, jobID, appstory.IndexFailedWarning())
+		}); werr != nil {
+			logger.Warn(ctx, "failed to record job warning", "error", werr.Error(), "job_id", jobID)
+		}
+	}
+
+	dto.Success(c, resp)
+}
+
+// DiscardCandidate 丢弃中断保存的部分内容
+// @Summary 丢弃中断保存的部分内容
+// @Description 流式生成被中断（任务状态 cancelled_partial）时保存的部分正文可续写（GET /v1/chapters/{cid}/stream?resume_job_id=）、
+// @Description 原样采用（select）或丢弃；丢弃后章节保持原正文不变。
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Param cand path string true "候选 ID"
+// @Success 200 {object} dto.Response[dto.CandidateResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/candidates/{cand}/discard [post]
+func (h *CandidateHandler) DiscardCandidate(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	jobID := dto.BindJobID(c)
+	candidateID := dto.BindCandidateID(c)
+
+	var candidate *entity.GenerationCandidate
+	err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		job, err := h.jobRepo.GetByID(txCtx, jobID)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return errNotFound("job not found")
+		}
+		candidate, err = h.candidateRepo.GetByID(txCtx, candidateID)
+		if err != nil {
+			return err
+		}
+		if candidate == nil || candidate.JobID != job.ID {
+			return errNotFound("candidate not found")
+		}
+		if !candidate.Partial || candidate.Status != entity.CandidateStatusPending {
+			return candidateStateError{msg: "only pending partial content can be discarded"}
+		}
+		if err := h.finalizer.DiscardPartial(txCtx, job, candidate, "partial content discarded by user"); err != nil {
+			return err
+		}
+		candidate, err = h.candidateRepo.GetByID(txCtx, candidate.ID)
+		return err
+	})
+	if err != nil {
+		var stateErr candidateStateError
+		switch {
+		case isNotFound(err):
+			dto.NotFound(c, err.Error())
+		case errors.As(err, &stateErr):
+			dto.Conflict(c, stateErr.Error())
+		default:
+			logger.Error(ctx, "failed to discard generation candidate", err)
+			dto.InternalError(c, "failed to discard candidate")
+		}
+		return
+	}
+	dto.Success(c, dto.ToCandidateResponse(candidate, false))
+}
+
+// checkArtifactActivation 采用的候选为需激活的构件版本时，调用租户校验 Webhook；
+// 候选或任务不存在等情况留给选择事务处理
+func (h *CandidateHandler) checkArtifactActivation(ctx context.Context, tenantID, jobID, candidateID string) error {
+	var req *storyartifact.ActivationValidationRequest
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		candidate, err := h.candidateRepo.GetByID(txCtx, candidateID)
+		if err != nil || candidate == nil || candidate.JobID != jobID || candidate.TargetType != entity.CandidateTargetArtifact {
+			return err
+		}
+		if candidate.Apply != nil && !candidate.Apply.Activate {
+			return nil
+		}
+		art, err := h.artifactRepo.GetArtifactByID(txCtx, candidate.TargetID)
+		if err != nil || art == nil {
+			return err
+		}
+		req = &storyartifact.ActivationValidationRequest{
+			TenantID:     tenantID,
+			ProjectID:    art.ProjectID,
+			ArtifactID:   art.ID,
+			ArtifactType: art.Type,
+			Trigger:      storyartifact.ActivationTriggerCandidate,
+			Content:      candidate.ArtifactContent(),
+		}
+		return nil
+	}); err != nil || req == nil {
+		return err
+	}
+	return h.validator.Check(ctx, req)
+}
+
+// candidateStateError 任务或目标当前状态不允许采用候选
+type candidateStateError struct {
+	msg string
+}
+
+func (e candidateStateError) Error() string {
+	return e.msg
+}
+
+// artifactVersionInput 新建构件版本的参数
+type artifactVersionInput struct {
+	Content         json.RawMessage
+	BranchKey       string
+	ParentVersionID *string
+	Activate        bool
+	CreatedBy       string
+	SourceJobID     string
+	Metadata        *entity.ArtifactVersionMetadata
+}
+
+// persistArtifactVersion 为构件追加新版本（需在事务内调用）；激活时同步切换 active_version，
+// 激活的小说基底同时回写项目标题/简介/类型，激活的大纲标记偏离新大纲的章节（返回的标记结果需在提交后发布刷新任务）。
+// 会话生成与候选选择共用。
+func persistArtifactVersion(ctx context.Context, artifactRepo repository.ArtifactRepository, projectRepo repository.ProjectRepository, outlineStale *storyoutline.StaleTracker, project *entity.Project, art *entity.ProjectArtifact, in artifactVersionInput) (*entity.ArtifactVersion, *storyoutline.StaleMark, error) {
+	latest, err := artifactRepo.GetLatestVersionNo(ctx, art.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	branchKey := in.BranchKey
+	if branchKey == "" {
+		branchKey = "main"
+	}
+	createdBy := strings.TrimSpace(in.CreatedBy)
+	sourceJobID := in.SourceJobID
+	version := &entity.ArtifactVersion{
+		ID:              uuid.NewString(),
+		ArtifactID:      art.ID,
+		VersionNo:       latest + 1,
+		BranchKey:       branchKey,
+		ParentVersionID: in.ParentVersionID,
+		Content:         in.Content,
+		CreatedBy:       &createdBy,
+		SourceJobID:     &sourceJobID,
+		Metadata:        in.Metadata,
+	}
+	if err := artifactRepo.CreateVersion(ctx, version); err != nil {
+		return nil, nil, err
+	}
+
+	if !in.Activate {
+		return version, nil, nil
+	}
+	previousVersionID := art.ActiveVersionID
+	if err := artifactRepo.SetActiveVersion(ctx, art.ID, version.ID); err != nil {
+		return nil, nil, err
+	}
+	mark, err := outlineStale.MarkActivated(ctx, art, previousVersionID, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if art.Type == entity.ArtifactTypeNovelFoundation {
+		var payload struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Genre       string `json:"genre,omitempty"`
+		}
+		if err := json.Unmarshal(in.Content, &payload); err != nil {
+			return nil, nil, fmt.Errorf("invalid novel_foundation content: %w", err)
+		}
+		payload.Title = strings.TrimSpace(payload.Title)
+		payload.Description = strings.TrimSpace(payload.Description)
+		payload.Genre = strings.TrimSpace(payload.Genre)
+		if payload.Title != "" {
+			project.Title = payload.Title
+		}
+		if payload.Description != "" {
+			project.Description = payload.Description
+		}
+		if payload.Genre != "" {
+			project.Genre = payload.Genre
+		}
+		if err := projectRepo.Update(ctx, project); err != nil {
+			return nil, nil, err
+		}
+	}
+	return version, mark, nil
+}
+
+// indexArtifactVersion 写入构件新版本的向量索引：激活版本重建主线索引，非主线分支的版本写入分支记忆
+// （检索该分支时覆盖主线片段，不污染主线）。向量检索未启用或存储不支持分支隔离时跳过对应写入。
+// 使用独立超时：客户端断开不影响索引。
+func indexArtifactVersion(indexer *appretrieval.Indexer, tenantID, projectID string, artifactType entity.ArtifactType, artifactID, branchKey string, activated bool, content json.RawMessage) error {
+	if indexer == nil {
+		return nil
+	}
+	indexCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var errs []error
+	if activated {
+		if err := indexer.IndexArtifactJSON(indexCtx, tenantID, projectID, artifactType, artifactID, content); err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) {
+			errs = append(errs, err)
+		}
+	}
+	if !appretrieval.IsMainBranch(branchKey) {
+		err := indexer.IndexArtifactBranch(indexCtx, tenantID, projectID, artifactType, artifactID, branchKey, content)
+		if err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) && !errors.Is(err, appretrieval.ErrBranchIndexUnsupported) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,18 +2,33 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	stderrors "errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"z-novel-ai-api/internal/application/quota"
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/pacing"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	"z-novel-ai-api/internal/application/story/textdiff"
+	"z-novel-ai-api/internal/application/story/timeline"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/infrastructure/messaging"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
 	"z-novel-ai-api/pkg/errors"
 	"z-novel-ai-api/pkg/logger"
 
@@ -30,220 +45,1162 @@ type ChapterHandler struct {
 	jobRepo     repository.JobRepository
 	producer    *messaging.Producer
 
-	quotaChecker *quota.TokenQuotaChecker
+	quotaChecker     *quota.TokenQuotaChecker
+	storyTimeChecker *timeline.StoryTimeValidator
+	duplicates       *duplicate.Detector
+	jobTimeline      *appstory.JobTimeline
+
+	// 成本预估（渲染 Prompt + RAG 预览，不调用模型）
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+	generator *storychapter.ChapterGenerator
+	retrieval *appretrieval.Engine
+	series    *storyseries.SeriesService
+
+	contextPins *appstory.ContextPinService
+	titles      *appstory.ChapterTitleService
+
+	// 大纲覆盖度（读取激活的 outline 构件）
+	artifactRepo repository.ArtifactRepository
+
+	// 手动编辑改变正文后排队重建向量索引
+	reindexer *appstory.ChapterReindexer
+
+	// 新建、删除、移动后维护卷内序号与展示序号
+	numbering *appstory.ChapterNumbering
+}
+
+// NewChapterHandler 创建章节处理器
+func NewChapterHandler(
+	cfg *config.Config,
+	chapterRepo repository.ChapterRepository,
+	projectRepo repository.ProjectRepository,
+	jobRepo repository.JobRepository,
+	producer *messaging.Producer,
+	quotaChecker *quota.TokenQuotaChecker,
+	storyTimeChecker *timeline.StoryTimeValidator,
+	jobTimeline *appstory.JobTimeline,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	generator *storychapter.ChapterGenerator,
+	retrievalEngine *appretrieval.Engine,
+	seriesService *storyseries.SeriesService,
+	contextPins *appstory.ContextPinService,
+	titles *appstory.ChapterTitleService,
+	duplicates *duplicate.Detector,
+	artifactRepo repository.ArtifactRepository,
+	reindexer *appstory.ChapterReindexer,
+	numbering *appstory.ChapterNumbering,
+) *ChapterHandler {
+	return &ChapterHandler{
+		cfg:              cfg,
+		chapterRepo:      chapterRepo,
+		projectRepo:      projectRepo,
+		jobRepo:          jobRepo,
+		producer:         producer,
+		quotaChecker:     quotaChecker,
+		storyTimeChecker: storyTimeChecker,
+		duplicates:       duplicates,
+		jobTimeline:      jobTimeline,
+		txMgr:            txMgr,
+		tenantCtx:        tenantCtx,
+		generator:        generator,
+		retrieval:        retrievalEngine,
+		series:           seriesService,
+		contextPins:      contextPins,
+		titles:           titles,
+		artifactRepo:     artifactRepo,
+		reindexer:        reindexer,
+		numbering:        numbering,
+	}
+}
+
+// ListChapters 获取章节列表
+// @Summary 获取章节列表
+// @Description 获取指定项目的章节列表（默认不含正文，?include=content 时返回正文）
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Param include query string false "附加字段（content）"
+// @Success 200 {object} dto.Response[dto.ChapterListResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/chapters [get]
+func (h *ChapterHandler) ListChapters(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+	pageReq := dto.BindPage(c)
+
+	filter := &repository.ChapterFilter{IncludeContent: dto.WantsInclude(c, "content")}
+
+	result, err := h.chapterRepo.ListByProject(ctx, projectID, filter, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	if err != nil {
+		logger.Error(ctx, "failed to list chapters", err)
+		dto.InternalError(c, "failed to list chapters")
+		return
+	}
+
+	resp := dto.ToChapterListResponse(result.Items, filter.IncludeContent)
+	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
+	dto.SuccessWithPage(c, resp, meta)
+}
+
+// CreateChapter 创建章节
+// @Summary 创建章节
+// @Description 创建新的章节
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param body body dto.CreateChapterRequest true "章节信息"
+// @Success 201 {object} dto.Response[dto.ChapterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/chapters [post]
+func (h *ChapterHandler) CreateChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+
+	var req dto.CreateChapterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	// 获取当前最大序号
+	maxSeq, err := h.chapterRepo.GetNextSeqNum(ctx, projectID, req.VolumeID)
+	if err != nil {
+		logger.Error(ctx, "failed to get next seq num", err)
+		dto.InternalError(c, "failed to create chapter")
+		return
+	}
+
+	chapter := req.ToChapterEntity(projectID, maxSeq)
+
+	if err := h.chapterRepo.Create(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to create chapter", err)
+		dto.InternalError(c, "failed to create chapter")
+		return
+	}
+	if err := h.numbering.AssignDisplayNumber(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to assign chapter display number", err)
+		dto.InternalError(c, "failed to create chapter")
+		return
+	}
+
+	warnings, ok := h.checkStoryTime(c, chapter)
+	if !ok {
+		return
+	}
+	warnings = append(warnings, h.checkDuplicates(ctx, chapter)...)
+	h.reindexer.RequestIfChanged(ctx, middleware.GetTenantIDFromGin(c), chapter)
+
+	resp := dto.ToChapterResponse(chapter)
+	resp.Warnings = warnings
+	dto.Created(c, resp)
+}
+
+// GetChapter 获取章节详情
+// @Summary 获取章节详情
+// @Description 获取指定章节的详细信息
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid} [get]
+func (h *ChapterHandler) GetChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		if errors.IsAppError(err) {
+			appErr := errors.AsAppError(err)
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
+			return
+		}
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	resp := dto.ToChapterResponse(chapter)
+	dto.Success(c, resp)
+}
+
+// DiffChapterVersions 对比章节两个版本的正文
+// @Summary 对比章节版本
+// @Description 按段落对齐、段内按词（中文按字）对比两个版本的正文；format=html 返回带 <ins>/<del> 标记的 HTML 片段。版本快照在章节保存时按版本号维护，重新生成与全文保存会产生新版本
+// @Tags Chapters
+// @Produce json
+// @Produce html
+// @Param cid path string true "章节 ID"
+// @Param a path int true "旧版本号"
+// @Param b path int true "新版本号"
+// @Param format query string false "输出格式：json（默认）/ html"
+// @Success 200 {object} dto.Response[dto.ChapterDiffResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/versions/{a}/diff/{b} [get]
+func (h *ChapterHandler) DiffChapterVersions(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	from, errA := strconv.Atoi(c.Param("a"))
+	to, errB := strconv.Atoi(c.Param("b"))
+	if errA != nil || errB != nil || from < 1 || to < 1 {
+		dto.BadRequest(c, "invalid version")
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "html" {
+		dto.BadRequest(c, "invalid format: must be json or html")
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	texts := make([]string, 2)
+	for i, v := range []int{from, to} {
+		text, ok, err := h.versionContent(ctx, chapter, v)
+		if err != nil {
+			logger.Error(ctx, "failed to get chapter version", err)
+			dto.InternalError(c, "failed to get chapter version")
+			return
+		}
+		if !ok {
+			dto.NotFound(c, fmt.Sprintf("chapter version %d not found", v))
+			return
+		}
+		texts[i] = text
+	}
+
+	result := textdiff.Compare(texts[0], texts[1])
+	if format == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(textdiff.RenderHTML(result)))
+		return
+	}
+	dto.Success(c, dto.ToChapterDiffResponse(chapter.ID, from, to, result))
+}
+
+// versionContent 读取章节指定版本的正文：当前版本直接取章节正文（含尚无快照的历史章节），其余取版本快照
+func (h *ChapterHandler) versionContent(ctx context.Context, chapter *entity.Chapter, version int) (string, bool, error) {
+	if version == chapter.Version {
+		return chapter.ContentText, true, nil
+	}
+	if version > chapter.Version {
+		return "", false, nil
+	}
+	snapshot, err := h.chapterRepo.GetVersion(ctx, chapter.ID, version)
+	if err != nil || snapshot == nil {
+		return "", false, err
+	}
+	return snapshot.ContentText, true, nil
+}
+
+// UpdateChapter 更新章节
+// @Summary 更新章节
+// @Description 更新指定章节的信息
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.UpdateChapterRequest true "更新内容"
+// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid} [put]
+func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.UpdateChapterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	// 获取现有章节
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	// 应用更新
+	req.ApplyToChapter(chapter)
+
+	// 保存更新
+	if err := h.chapterRepo.Update(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to update chapter", err)
+		dto.InternalError(c, "failed to update chapter")
+		return
+	}
+
+	warnings, ok := h.checkStoryTime(c, chapter)
+	if !ok {
+		return
+	}
+	warnings = append(warnings, h.checkDuplicates(ctx, chapter)...)
+	h.reindexer.RequestIfChanged(ctx, middleware.GetTenantIDFromGin(c), chapter)
+
+	resp := dto.ToChapterResponse(chapter)
+	resp.Warnings = warnings
+	dto.Success(c, resp)
 }
 
-// NewChapterHandler 创建章节处理器
-func NewChapterHandler(
-	cfg *config.Config,
-	chapterRepo repository.ChapterRepository,
-	projectRepo repository.ProjectRepository,
-	jobRepo repository.JobRepository,
-	producer *messaging.Producer,
-	quotaChecker *quota.TokenQuotaChecker,
-) *ChapterHandler {
-	return &ChapterHandler{
-		cfg:          cfg,
-		chapterRepo:  chapterRepo,
-		projectRepo:  projectRepo,
-		jobRepo:      jobRepo,
-		producer:     producer,
-		quotaChecker: quotaChecker,
+// GetContextPins 获取章节固定上下文
+// @Summary 获取章节固定上下文
+// @Description 返回作者为章节固定的章节/实体/片段，生成时无论相似度高低都会注入
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Success 200 {object} dto.Response[dto.ContextPinsResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/pins [get]
+func (h *ChapterHandler) GetContextPins(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	dto.Success(c, dto.ToContextPinsResponse(chapter))
+}
+
+// UpdateContextPins 设置章节固定上下文
+// @Summary 设置章节固定上下文
+// @Description 整体替换章节的固定上下文（空列表表示清空）。chapter / entity 引用须属于同一项目；segment 需附带片段文本。重生成沿用已固定的上下文
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.UpdateContextPinsRequest true "固定上下文"
+// @Success 200 {object} dto.Response[dto.ContextPinsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/pins [put]
+func (h *ChapterHandler) UpdateContextPins(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.UpdateContextPinsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	pins, err := h.contextPins.Normalize(ctx, chapter, req.ToEntities())
+	if err != nil {
+		if stderrors.Is(err, appstory.ErrInvalidContextPin) {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error(ctx, "failed to validate context pins", err)
+		dto.InternalError(c, "failed to update context pins")
+		return
+	}
+
+	if err := h.chapterRepo.UpdateContextPins(ctx, chapter.ID, pins); err != nil {
+		logger.Error(ctx, "failed to update context pins", err)
+		dto.InternalError(c, "failed to update context pins")
+		return
+	}
+	chapter.ContextPins = pins
+
+	dto.Success(c, dto.ToContextPinsResponse(chapter))
+}
+
+// SuggestTitles 为章节提出标题建议
+// @Summary 章节标题建议
+// @Description 根据章节正文（开头与结尾节选）提出 3-5 个候选标题，写入生成元数据 title_suggestions，可通过 accept-title 一键采用；不修改当前标题
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.SuggestTitlesRequest false "建议数量与模型（可省略）"
+// @Success 200 {object} dto.Response[dto.TitleSuggestionsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 504 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/suggest-titles [post]
+func (h *ChapterHandler) SuggestTitles(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.SuggestTitlesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+	if strings.TrimSpace(chapter.ContentText) == "" {
+		dto.BadRequest(c, "chapter has no content")
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, chapter.ProjectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	provider, model, err := resolveProjectProviderModel(h.cfg, project.Settings, req.Provider, req.Model)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	titles, err := h.titles.Suggest(ctx, project, chapter, provider, model, req.Count)
+	if err != nil {
+		logger.Error(ctx, "failed to suggest chapter titles", err)
+		if appstory.IsGenerationTimeout(err) {
+			dto.Error(c, http.StatusGatewayTimeout, "title suggestion timed out")
+			return
+		}
+		dto.InternalError(c, "failed to suggest chapter titles")
+		return
+	}
+
+	updated, err := h.titles.SaveSuggestions(ctx, chapter.ID, titles)
+	if err != nil {
+		logger.Error(ctx, "failed to save title suggestions", err)
+		dto.InternalError(c, "failed to save title suggestions")
+		return
+	}
+	if updated == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	dto.Success(c, dto.ToTitleSuggestionsResponse(updated))
+}
+
+// AcceptTitle 采用标题建议
+// @Summary 采用标题建议
+// @Description 将章节标题设为 title_suggestions 中的一项并清空建议
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.AcceptTitleRequest true "采用的标题"
+// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/accept-title [post]
+func (h *ChapterHandler) AcceptTitle(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.AcceptTitleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+	if !chapter.AcceptTitleSuggestion(req.Title) {
+		dto.BadRequest(c, "title is not one of the current suggestions")
+		return
+	}
+
+	if err := h.chapterRepo.Update(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to update chapter", err)
+		dto.InternalError(c, "failed to update chapter")
+		return
+	}
+
+	dto.Success(c, dto.ToChapterResponse(chapter))
+}
+
+// AutosaveChapter 自动保存章节正文
+// @Summary 自动保存章节正文
+// @Description 增量保存章节正文（同一编辑者在合并窗口内的连续保存不递增版本），并检测与生成任务/其他编辑者的冲突；仅 draft / review 状态的章节可自动保存
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.AutosaveChapterRequest true "正文内容"
+// @Success 200 {object} dto.Response[dto.AutosaveChapterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ChapterConflictResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/content [patch]
+func (h *ChapterHandler) AutosaveChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+	userID := middleware.GetUserIDFromGin(c)
+
+	var req dto.AutosaveChapterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	// 加行锁，避免并发自动保存同时通过版本校验
+	chapter, err := h.chapterRepo.GetByIDForUpdate(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	// 生成任务正在写入同一章节时拒绝保存，返回冲突任务 ID
+	job, err := h.jobRepo.GetActiveByChapter(ctx, chapter.ID)
+	if err != nil {
+		logger.Error(ctx, "failed to check active chapter job", err)
+		dto.InternalError(c, "failed to save chapter")
+		return
+	}
+	if job != nil || chapter.Status == entity.ChapterStatusGenerating {
+		resp := newChapterConflict(c, chapter, "chapter is being generated", "chapter_generation_in_progress")
+		if job != nil {
+			resp.ConflictJobID = job.ID
+		}
+		c.JSON(http.StatusConflict, resp)
+		return
+	}
+
+	if req.BaseVersion != nil && *req.BaseVersion != chapter.Version {
+		c.JSON(http.StatusConflict, newChapterConflict(c, chapter, "chapter has been modified", "chapter_version_conflict"))
+		return
+	}
+
+	if !chapter.IsEditable() {
+		dto.Conflict(c, fmt.Sprintf("chapter in status %s cannot be autosaved; use PUT /v1/chapters/{cid} to revise it", chapter.Status))
+		return
+	}
+
+	newVersion := chapter.ApplyAutosave(*req.ContentText, userID, time.Now(), h.cfg.Story.AutosaveVersionInterval)
+	if err := h.chapterRepo.Update(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to autosave chapter", err)
+		dto.InternalError(c, "failed to save chapter")
+		return
+	}
+	h.reindexer.RequestIfChanged(ctx, middleware.GetTenantIDFromGin(c), chapter)
+
+	resp := &dto.AutosaveChapterResponse{
+		ID:           chapter.ID,
+		Version:      chapter.Version,
+		NewVersion:   newVersion,
+		WordCount:    chapter.WordCount,
+		DraftDirty:   chapter.DraftDirty,
+		LastEditedAt: chapter.LastEditedAt,
+	}
+	if chapter.LastEditedBy != nil {
+		resp.LastEditedBy = *chapter.LastEditedBy
+	}
+	resp.Warnings = h.checkDuplicates(ctx, chapter)
+	dto.Success(c, resp)
+}
+
+// newChapterConflict 构建章节编辑冲突响应
+func newChapterConflict(c *gin.Context, chapter *entity.Chapter, message, code string) *dto.ChapterConflictResponse {
+	resp := &dto.ChapterConflictResponse{
+		ErrorResponse: dto.NewErrorResponse(c, http.StatusConflict, message, &dto.ErrorDetail{
+			ErrorCode:   code,
+			Suggestions: []string{"reload the chapter and merge local changes before saving again"},
+		}),
+		CurrentVersion: chapter.Version,
+	}
+	if chapter.LastEditedBy != nil {
+		resp.LastEditedBy = *chapter.LastEditedBy
+	}
+	return resp
+}
+
+// checkStoryTime 校验章节故事时间是否倒退（章节已在请求事务内落库）。
+// reject 模式下直接写入 422 响应（事务随之回滚）并返回 ok=false；warn 模式返回提示信息。
+func (h *ChapterHandler) checkStoryTime(c *gin.Context, chapter *entity.Chapter) (warnings []string, ok bool) {
+	if h.storyTimeChecker == nil {
+		return nil, true
+	}
+	ctx := c.Request.Context()
+
+	regressions, err := h.storyTimeChecker.CheckChapter(ctx, chapter)
+	if err != nil {
+		var regErr timeline.RegressionError
+		if stderrors.As(err, &regErr) {
+			dto.UnprocessableEntity(c, "story time regression", &dto.ErrorDetail{
+				ErrorCode:   "story_time_regression",
+				Details:     regErr.Error(),
+				Suggestions: []string{"adjust story_time_start/story_time_end", "set is_flashback=true for intentional flashbacks"},
+			})
+			return nil, false
+		}
+		// 校验本身失败不阻断写入
+		logger.Warn(ctx, "failed to check chapter story time", "error", err.Error(), "chapter_id", chapter.ID)
+		return nil, true
+	}
+
+	for i := range regressions {
+		warnings = append(warnings, regressions[i].String())
+	}
+	return warnings, true
+}
+
+// checkDuplicates 检测章节正文是否与同项目其他章节高度相似（仅提示，检测失败不阻断写入）
+func (h *ChapterHandler) checkDuplicates(ctx context.Context, chapter *entity.Chapter) []string {
+	matches, err := h.duplicates.CheckChapter(ctx, chapter)
+	if err != nil {
+		logger.Warn(ctx, "failed to check duplicate chapter content", "error", err.Error(), "chapter_id", chapter.ID)
+		return nil
+	}
+	warnings := make([]string, 0, len(matches))
+	for i := range matches {
+		warnings = append(warnings, matches[i].String())
+	}
+	return warnings
+}
+
+// DeleteChapter 删除章节
+// @Summary 删除章节
+// @Description 删除指定章节，并压缩所在卷的章节序号、刷新项目展示序号
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid} [delete]
+func (h *ChapterHandler) DeleteChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to delete chapter")
+		return
+	}
+
+	if err := h.chapterRepo.Delete(ctx, chapterID); err != nil {
+		if errors.IsAppError(err) {
+			appErr := errors.AsAppError(err)
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
+			return
+		}
+		logger.Error(ctx, "failed to delete chapter", err)
+		dto.InternalError(c, "failed to delete chapter")
+		return
+	}
+	if chapter != nil {
+		if err := h.numbering.RenumberVolume(ctx, chapter.ProjectID, chapter.VolumeID); err != nil {
+			logger.Error(ctx, "failed to renumber chapters after delete", err)
+			dto.InternalError(c, "failed to delete chapter")
+			return
+		}
+		h.numbering.SyncNarrativePositions(ctx, middleware.GetTenantIDFromGin(c), chapter.ProjectID)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MoveChapter 移动章节
+// @Summary 移动章节
+// @Description 将章节移动到目标卷的指定位置（跨卷或卷内调整），压缩来源卷与目标卷的章节序号并刷新项目展示序号
+// @Tags Chapters
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.MoveChapterRequest true "移动请求"
+// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/move [post]
+func (h *ChapterHandler) MoveChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.MoveChapterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to move chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+	volumeID := strings.TrimSpace(req.VolumeID)
+	if volumeID == "" {
+		volumeID = chapter.VolumeID
+	}
+	if volumeID == "" {
+		dto.BadRequest(c, "volume_id is required for chapters without a volume")
+		return
+	}
+
+	if err := h.numbering.MoveChapter(ctx, chapter, volumeID, req.Position); err != nil {
+		if stderrors.Is(err, appstory.ErrNumberingVolumeNotFound) {
+			dto.NotFound(c, "volume not found")
+			return
+		}
+		logger.Error(ctx, "failed to move chapter", err)
+		dto.InternalError(c, "failed to move chapter")
+		return
 	}
+	h.numbering.SyncNarrativePositions(ctx, middleware.GetTenantIDFromGin(c), chapter.ProjectID)
+
+	dto.Success(c, dto.ToChapterResponse(chapter))
 }
 
-// ListChapters 获取章节列表
-// @Summary 获取章节列表
-// @Description 获取指定项目的章节列表
+// RenumberChapters 章节重编号
+// @Summary 章节重编号
+// @Description 压缩卷内章节序号（指定 volume_id 时只处理该卷，否则全项目并压缩卷序号），并按叙事顺序刷新展示序号
 // @Tags Chapters
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
-// @Param page query int false "页码" default(1)
-// @Param page_size query int false "每页条数" default(20)
-// @Success 200 {object} dto.Response[dto.ChapterListResponse]
+// @Param body body dto.RenumberChaptersRequest false "重编号请求"
+// @Success 200 {object} dto.Response[dto.ChapterNumberListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /v1/projects/{pid}/chapters [get]
-func (h *ChapterHandler) ListChapters(c *gin.Context) {
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/chapters/renumber [post]
+func (h *ChapterHandler) RenumberChapters(c *gin.Context) {
 	ctx := c.Request.Context()
 	projectID := dto.BindProjectID(c)
-	pageReq := dto.BindPage(c)
 
-	result, err := h.chapterRepo.ListByProject(ctx, projectID, nil, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	var req dto.RenumberChaptersRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+
+	var err error
+	if volumeID := strings.TrimSpace(req.VolumeID); volumeID != "" {
+		err = h.numbering.RenumberVolume(ctx, projectID, volumeID)
+	} else {
+		err = h.numbering.RenumberProject(ctx, projectID)
+	}
 	if err != nil {
-		logger.Error(ctx, "failed to list chapters", err)
-		dto.InternalError(c, "failed to list chapters")
+		if stderrors.Is(err, appstory.ErrNumberingVolumeNotFound) {
+			dto.NotFound(c, "volume not found")
+			return
+		}
+		logger.Error(ctx, "failed to renumber chapters", err)
+		dto.InternalError(c, "failed to renumber chapters")
 		return
 	}
+	h.numbering.SyncNarrativePositions(ctx, middleware.GetTenantIDFromGin(c), projectID)
 
-	resp := dto.ToChapterListResponse(result.Items)
-	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
-	dto.SuccessWithPage(c, resp, meta)
+	chapters, err := h.chapterRepo.ListTimeline(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list chapters", err)
+		dto.InternalError(c, "failed to renumber chapters")
+		return
+	}
+	dto.Success(c, dto.ToChapterNumberListResponse(projectID, chapters))
 }
 
-// CreateChapter 创建章节
-// @Summary 创建章节
-// @Description 创建新的章节
+// GetPacing 获取项目节奏分析
+// @Summary 获取项目节奏分析
+// @Description 按叙事顺序返回各章字数、对白占比、场景数与节奏强度，并以滚动平均标记低于均值一个标准差的拖沓区间
 // @Tags Chapters
-// @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
-// @Param body body dto.CreateChapterRequest true "章节信息"
-// @Success 201 {object} dto.Response[dto.ChapterResponse]
+// @Param window query int false "滚动平均窗口（章节数，1-10，默认 3）"
+// @Success 200 {object} dto.Response[dto.PacingResponse]
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /v1/projects/{pid}/chapters [post]
-func (h *ChapterHandler) CreateChapter(c *gin.Context) {
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/pacing [get]
+func (h *ChapterHandler) GetPacing(c *gin.Context) {
 	ctx := c.Request.Context()
 	projectID := dto.BindProjectID(c)
 
-	var req dto.CreateChapterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+	window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(pacing.DefaultWindow)))
+	if err != nil || window < 1 || window > pacing.MaxWindow {
+		dto.BadRequest(c, "invalid window")
 		return
 	}
 
-	// 获取当前最大序号
-	maxSeq, err := h.chapterRepo.GetNextSeqNum(ctx, projectID, req.VolumeID)
+	project, err := h.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
-		logger.Error(ctx, "failed to get next seq num", err)
-		dto.InternalError(c, "failed to create chapter")
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
 		return
 	}
 
-	chapter := req.ToChapterEntity(projectID, maxSeq)
-
-	if err := h.chapterRepo.Create(ctx, chapter); err != nil {
-		logger.Error(ctx, "failed to create chapter", err)
-		dto.InternalError(c, "failed to create chapter")
+	chapters, err := h.chapterRepo.ListTimeline(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list chapters", err)
+		dto.InternalError(c, "failed to analyze pacing")
 		return
 	}
+	rows, err := h.chapterRepo.ListStats(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list chapter stats", err)
+		dto.InternalError(c, "failed to analyze pacing")
+		return
+	}
+	stats := make(map[string]*entity.ChapterStats, len(rows))
+	for _, s := range rows {
+		stats[s.ChapterID] = s
+	}
 
-	resp := dto.ToChapterResponse(chapter)
-	dto.Created(c, resp)
+	// 统计表上线前保存的章节没有统计行：按正文补算并回写，之后由章节保存维护
+	for _, ch := range chapters {
+		if stats[ch.ID] != nil {
+			continue
+		}
+		full, err := h.chapterRepo.GetByID(ctx, ch.ID)
+		if err != nil {
+			logger.Error(ctx, "failed to get chapter", err)
+			dto.InternalError(c, "failed to analyze pacing")
+			return
+		}
+		if full == nil {
+			continue
+		}
+		s := entity.NewChapterStats(full)
+		if err := h.chapterRepo.SaveStats(ctx, s); err != nil {
+			logger.Warn(ctx, "failed to backfill chapter stats", "error", err, "chapter_id", ch.ID)
+		}
+		stats[ch.ID] = s
+	}
+
+	dto.Success(c, dto.ToPacingResponse(projectID, pacing.Analyze(chapters, stats, window)))
 }
 
-// GetChapter 获取章节详情
-// @Summary 获取章节详情
-// @Description 获取指定章节的详细信息
+// GetOutlineCoverage 获取大纲覆盖度
+// @Summary 获取大纲覆盖度
+// @Description 按激活的大纲构件（outline）逐条列出章节条目及其关联章节（按章节 outline_key 匹配）的落地状态：
+// @Description missing（无章节）/ draft（无正文）/ generated（有正文待审阅）/ approved（已完成）；
+// @Description 同一条目关联多章时列出重复章节，未关联或关联到不存在条目的章节列入 orphans
 // @Tags Chapters
-// @Accept json
 // @Produce json
-// @Param cid path string true "章节 ID"
-// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.OutlineCoverageResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /v1/chapters/{cid} [get]
-func (h *ChapterHandler) GetChapter(c *gin.Context) {
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/outline/coverage [get]
+func (h *ChapterHandler) GetOutlineCoverage(c *gin.Context) {
 	ctx := c.Request.Context()
-	chapterID := dto.BindChapterID(c)
+	projectID := dto.BindProjectID(c)
 
-	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	project, err := h.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
-		if errors.IsAppError(err) {
-			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
-			return
-		}
-		logger.Error(ctx, "failed to get chapter", err)
-		dto.InternalError(c, "failed to get chapter")
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
 		return
 	}
 
-	if chapter == nil {
-		dto.NotFound(c, "chapter not found")
+	versionID, volumes, err := outline.Active(ctx, h.artifactRepo, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to load active outline", err)
+		dto.InternalError(c, "failed to load outline")
+		return
+	}
+	chapters, err := h.chapterRepo.ListOutlineLinks(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list chapters", err)
+		dto.InternalError(c, "failed to compute outline coverage")
 		return
 	}
 
-	resp := dto.ToChapterResponse(chapter)
-	dto.Success(c, resp)
+	coverage := outline.Build(volumes, chapters)
+	coverage.OutlineVersionID = versionID
+	dto.Success(c, dto.ToOutlineCoverageResponse(projectID, coverage))
 }
 
-// UpdateChapter 更新章节
-// @Summary 更新章节
-// @Description 更新指定章节的信息
+// EstimateChapter 预估章节生成成本
+// @Summary 预估章节生成成本
+// @Description 按与实际生成一致的方式组装 Prompt（大纲 + 风格 + RAG 预览），估算各提供商的 Token 与成本（各提供商共用同一 Token 估算，token_estimate_basis=shared_heuristic，成本差异仅来自单价）；不调用模型、不创建任务
 // @Tags Chapters
 // @Accept json
 // @Produce json
-// @Param cid path string true "章节 ID"
-// @Param body body dto.UpdateChapterRequest true "更新内容"
-// @Success 200 {object} dto.Response[dto.ChapterResponse]
+// @Param pid path string true "项目 ID"
+// @Param body body dto.EstimateChapterRequest true "预估请求"
+// @Success 200 {object} dto.Response[dto.ChapterEstimateResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
-// @Router /v1/chapters/{cid} [put]
-func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/chapters/estimate [post]
+func (h *ChapterHandler) EstimateChapter(c *gin.Context) {
 	ctx := c.Request.Context()
-	chapterID := dto.BindChapterID(c)
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
 
-	var req dto.UpdateChapterRequest
+	var req dto.EstimateChapterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
+		return
+	}
+	outline := strings.TrimSpace(req.Outline)
+	if outline == "" {
+		dto.BadRequest(c, "outline is required")
 		return
 	}
 
-	// 获取现有章节
-	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	pricing, err := h.providerPricing(req.Providers)
 	if err != nil {
-		logger.Error(ctx, "failed to get chapter", err)
-		dto.InternalError(c, "failed to get chapter")
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	if h.generator == nil {
+		dto.InternalError(c, "chapter generator not configured")
 		return
 	}
 
-	if chapter == nil {
-		dto.NotFound(c, "chapter not found")
+	// 该接口在 DBTransaction 中间件豁免列表内：仅用短事务读取项目，RAG 检索（Embedding）在事务外执行
+	var project *entity.Project
+	var seriesProjectIDs []string
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		p, err := h.projectRepo.GetByID(txCtx, projectID)
+		if err != nil || p == nil {
+			project = p
+			return err
+		}
+		project = p
+		if h.series != nil {
+			ids, seriesErr := h.series.RetrievalProjectIDs(txCtx, p)
+			if seriesErr != nil {
+				logger.Warn(txCtx, "failed to resolve series retrieval scope", "error", seriesErr.Error(), "project_id", p.ID)
+			}
+			seriesProjectIDs = ids
+		}
+		return nil
+	}); err != nil {
+		logger.Error(ctx, "failed to load project for chapter estimate", err)
+		dto.InternalError(c, "failed to load project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
 		return
 	}
 
-	// 应用更新
-	req.ApplyToChapter(chapter)
+	povEntityID := strings.TrimSpace(req.POVEntityID)
+	writingStyle, pov := project.Settings.ResolveStyle(povEntityID)
+	targetWordCount := req.TargetWordCount
+	if targetWordCount <= 0 && project.Settings != nil && project.Settings.DefaultChapterLength > 0 {
+		targetWordCount = project.Settings.DefaultChapterLength
+	}
+	if targetWordCount <= 0 {
+		targetWordCount = 2000
+	}
 
-	// 保存更新
-	if err := h.chapterRepo.Update(ctx, chapter); err != nil {
-		logger.Error(ctx, "failed to update chapter", err)
-		dto.InternalError(c, "failed to update chapter")
+	retrievedContext := ""
+	retrievedSegments := 0
+	if h.retrieval != nil {
+		retrievalCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		ro, rerr := h.retrieval.Search(retrievalCtx, appretrieval.SearchInput{
+			TenantID:         tenantID,
+			ProjectID:        projectID,
+			Query:            outline,
+			CurrentStoryTime: req.StoryTimeStart,
+			POVEntityID:      povEntityID,
+			SeriesProjectIDs: seriesProjectIDs,
+			TopK:             appretrieval.ChapterTopK(project.Settings),
+			IncludeEntities:  false,
+		})
+		cancel()
+		if rerr != nil {
+			// RAG 预览失败仅降低预估精度，不阻断
+			logger.Warn(ctx, "failed to retrieve context for chapter estimate", "error", rerr.Error(), "project_id", projectID)
+		} else if ro != nil && len(ro.Segments) > 0 {
+			retrievedContext = appretrieval.BuildPromptContext(ro.Segments, appretrieval.PromptMaxSegments, appretrieval.PromptMaxRunesPerSegment)
+			retrievedSegments = len(ro.Segments)
+		}
+	}
+
+	promptTokens, err := h.generator.EstimatePromptTokens(ctx, &wfmodel.ChapterGenerateInput{
+		ProjectTitle:       project.Title,
+		ProjectDescription: project.Description,
+		ChapterTitle:       strings.TrimSpace(req.Title),
+		ChapterOutline:     outline,
+		RetrievedContext:   retrievedContext,
+		TargetWordCount:    targetWordCount,
+		WritingStyle:       writingStyle,
+		POV:                pov,
+	})
+	if err != nil {
+		logger.Error(ctx, "failed to render chapter prompt for estimate", err)
+		dto.InternalError(c, "failed to estimate chapter cost")
 		return
 	}
+	completionTokens := storychapter.EstimateCompletionTokens(targetWordCount)
 
-	resp := dto.ToChapterResponse(chapter)
+	estimates := storychapter.EstimateCosts(promptTokens, completionTokens, pricing)
+	resp := &dto.ChapterEstimateResponse{
+		TargetWordCount:    targetWordCount,
+		PromptTokens:       promptTokens,
+		CompletionTokens:   completionTokens,
+		RetrievedSegments:  retrievedSegments,
+		TokenEstimateBasis: dto.TokenEstimateBasisShared,
+		Estimates:          make([]*dto.ChapterCostEstimateResponse, 0, len(estimates)),
+	}
+	defaultProvider := strings.TrimSpace(h.cfg.LLM.DefaultProvider)
+	for _, est := range estimates {
+		resp.Estimates = append(resp.Estimates, &dto.ChapterCostEstimateResponse{
+			Provider:         est.Provider,
+			Model:            est.Model,
+			IsDefault:        est.Provider == defaultProvider,
+			PromptTokens:     est.PromptTokens,
+			CompletionTokens: est.CompletionTokens,
+			CompletionCapped: est.CompletionCapped,
+			Priced:           est.Priced,
+			Currency:         est.Currency,
+			PromptCost:       est.PromptCost,
+			CompletionCost:   est.CompletionCost,
+			TotalCost:        est.TotalCost,
+		})
+	}
 	dto.Success(c, resp)
 }
 
-// DeleteChapter 删除章节
-// @Summary 删除章节
-// @Description 删除指定章节
-// @Tags Chapters
-// @Accept json
-// @Produce json
-// @Param cid path string true "章节 ID"
-// @Success 204 "No Content"
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
-// @Router /v1/chapters/{cid} [delete]
-func (h *ChapterHandler) DeleteChapter(c *gin.Context) {
-	ctx := c.Request.Context()
-	chapterID := dto.BindChapterID(c)
-
-	if err := h.chapterRepo.Delete(ctx, chapterID); err != nil {
-		if errors.IsAppError(err) {
-			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
-			return
+// providerPricing 从配置中提取提供商计费参数；names 为空时返回全部提供商（按名称排序）。
+func (h *ChapterHandler) providerPricing(names []string) ([]storychapter.ProviderPricing, error) {
+	if h.cfg == nil {
+		return nil, stderrors.New("server config not configured")
+	}
+	if len(names) == 0 {
+		for name := range h.cfg.LLM.Providers {
+			names = append(names, name)
 		}
-		logger.Error(ctx, "failed to delete chapter", err)
-		dto.InternalError(c, "failed to delete chapter")
-		return
+		sort.Strings(names)
 	}
 
-	c.Status(http.StatusNoContent)
+	out := make([]storychapter.ProviderPricing, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		pc, ok := h.cfg.LLM.Providers[name]
+		if !ok {
+			return nil, stderrors.New("llm provider not found: " + name)
+		}
+		out = append(out, storychapter.ProviderPricing{
+			Provider:    name,
+			Model:       strings.TrimSpace(pc.Model),
+			MaxTokens:   pc.MaxTokens,
+			InputPer1K:  pc.Pricing.InputPer1K,
+			OutputPer1K: pc.Pricing.OutputPer1K,
+			Currency:    pc.Pricing.Currency,
+		})
+	}
+	return out, nil
 }
 
 // GenerateChapter 生成章节（异步）
@@ -256,7 +1213,10 @@ func (h *ChapterHandler) DeleteChapter(c *gin.Context) {
 // @Param body body dto.GenerateChapterRequest true "生成请求"
 // @Success 202 {object} dto.Response[dto.JobResponse]
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/chapters/generate [post]
 func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -265,11 +1225,22 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 
 	var req dto.GenerateChapterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to load project", err)
+		dto.InternalError(c, "failed to load project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
 		return
 	}
 
-	provider, model, err := resolveProviderModel(h.cfg, "", pickOptionModel(req.Options))
+	provider, model, err := resolveProjectProviderModel(h.cfg, project.Settings, "", pickOptionModel(req.Options))
 	if err != nil {
 		dto.BadRequest(c, err.Error())
 		return
@@ -297,31 +1268,18 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		}
 	}
 
-	if h.quotaChecker != nil {
-		if _, err := h.quotaChecker.CheckBalance(ctx, tenantID, 1000); err != nil {
-			var exceeded quota.TokenBalanceExceededError
-			if stderrors.As(err, &exceeded) {
-				dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
-				return
-			}
-			logger.Error(ctx, "quota check failed", err)
-			dto.InternalError(c, "quota check failed")
-			return
-		}
+	trigger := entity.JobTriggerStandard
+	if req.Background {
+		trigger = entity.JobTriggerBulk
+	}
+	priority := entity.DefaultPriority(entity.JobTypeChapterGen, trigger)
+	estimate, ok := admitQueuedJob(c, h.cfg, h.producer, priority)
+	if !ok {
+		return
 	}
 
 	targetWordCount := req.TargetWordCount
 	if targetWordCount <= 0 {
-		project, err := h.projectRepo.GetByID(ctx, projectID)
-		if err != nil {
-			logger.Error(ctx, "failed to load project", err)
-			dto.InternalError(c, "failed to load project")
-			return
-		}
-		if project == nil {
-			dto.NotFound(c, "project not found")
-			return
-		}
 		if project.Settings != nil && project.Settings.DefaultChapterLength > 0 {
 			targetWordCount = project.Settings.DefaultChapterLength
 		} else {
@@ -329,6 +1287,8 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		}
 	}
 
+	candidates, selection := h.chapterCandidates(req.Options, targetWordCount)
+
 	jobID := uuid.NewString()
 	inputParams := map[string]any{
 		"mode":              "async_generate",
@@ -353,10 +1313,15 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 			inputParams["skip_validation"] = true
 		}
 	}
+	if candidates > 1 {
+		inputParams["candidates"] = candidates
+		inputParams["selection"] = selection
+	}
 	inputBytes, _ := json.Marshal(inputParams)
 
 	job := entity.NewGenerationJob(tenantID, projectID, entity.JobTypeChapterGen, inputBytes)
 	job.ID = jobID
+	job.Priority = priority
 	if idempotencyKey != "" {
 		job.IdempotencyKey = &idempotencyKey
 	}
@@ -372,6 +1337,10 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		dto.InternalError(c, "failed to create job")
 		return
 	}
+	// 入队前原子预留配额（与任务创建同一请求事务，余额不足时整体回滚）
+	if !h.reserveQuota(c, tenantID, jobID, targetWordCount, candidates) {
+		return
+	}
 
 	seqNum, err := h.chapterRepo.GetNextSeqNum(ctx, projectID, strings.TrimSpace(req.VolumeID))
 	if err != nil {
@@ -385,13 +1354,18 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 	chapter.Outline = strings.TrimSpace(req.Outline)
 	chapter.Notes = strings.TrimSpace(req.Notes)
 	chapter.StoryTimeStart = req.StoryTimeStart
-	chapter.Status = entity.ChapterStatusGenerating
+	chapter.Status = appstory.ChapterStatusForJob(job.Status, "")
 
 	if err := h.chapterRepo.Create(ctx, chapter); err != nil {
 		logger.Error(ctx, "failed to create chapter", err)
 		dto.InternalError(c, "failed to create chapter")
 		return
 	}
+	if err := h.numbering.AssignDisplayNumber(ctx, chapter); err != nil {
+		logger.Error(ctx, "failed to assign chapter display number", err)
+		dto.InternalError(c, "failed to create chapter")
+		return
+	}
 
 	job.ChapterID = &chapter.ID
 	inputParams["chapter_id"] = chapter.ID
@@ -404,7 +1378,20 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		return
 	}
 
-	temp := pickOptionTemperature(req.Options)
+	params := messaging.ChapterGenParams{
+		Outline:         chapter.Outline,
+		TargetWordCount: targetWordCount,
+		Provider:        provider,
+		Model:           model,
+	}
+	if temp := pickOptionTemperature(req.Options); temp != nil {
+		t := float64(*temp)
+		params.Temperature = &t
+	}
+	if candidates > 1 {
+		params.Candidates = candidates
+		params.Selection = string(selection)
+	}
 	msg := &messaging.GenerationJobMessage{
 		JobID:          jobID,
 		TenantID:       tenantID,
@@ -413,15 +1400,7 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		JobType:        string(entity.JobTypeChapterGen),
 		Priority:       job.Priority,
 		IdempotencyKey: job.IdempotencyKey,
-		Params: map[string]interface{}{
-			"outline":           chapter.Outline,
-			"target_word_count": targetWordCount,
-			"provider":          provider,
-			"model":             model,
-		},
-	}
-	if temp != nil {
-		msg.Params["temperature"] = float64(*temp)
+		Params:         messaging.EncodeJobParams(params),
 	}
 
 	if _, err := h.producer.PublishGenJob(ctx, msg); err != nil {
@@ -429,8 +1408,9 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 		dto.InternalError(c, "failed to enqueue job")
 		return
 	}
+	h.jobTimeline.Record(ctx, job, entity.JobEventQueued, "chapter generation queued", map[string]any{"chapter_id": chapter.ID})
 
-	dto.Accepted(c, dto.ToJobResponse(job))
+	dto.Accepted(c, queuedJobResponse(job, estimate))
 }
 
 // RegenerateChapter 重新生成章节
@@ -445,6 +1425,8 @@ func (h *ChapterHandler) GenerateChapter(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/chapters/{cid}/regenerate [post]
 func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -453,7 +1435,7 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 
 	var req dto.RegenerateChapterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -479,19 +1461,6 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		return
 	}
 
-	if h.quotaChecker != nil {
-		if _, err := h.quotaChecker.CheckBalance(ctx, tenantID, 1000); err != nil {
-			var exceeded quota.TokenBalanceExceededError
-			if stderrors.As(err, &exceeded) {
-				dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
-				return
-			}
-			logger.Error(ctx, "quota check failed", err)
-			dto.InternalError(c, "quota check failed")
-			return
-		}
-	}
-
 	outline := strings.TrimSpace(req.Outline)
 	if outline == "" {
 		outline = strings.TrimSpace(chapter.Outline)
@@ -510,7 +1479,7 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		}
 	}
 
-	provider, model, err := resolveProviderModel(h.cfg, "", pickOptionModel(req.Options))
+	provider, model, err := resolveProjectProviderModel(h.cfg, project.Settings, "", pickOptionModel(req.Options))
 	if err != nil {
 		dto.BadRequest(c, err.Error())
 		return
@@ -538,11 +1507,21 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		}
 	}
 
+	// 交互式请求：用户在编辑界面等待结果，优先于批量起草与运维任务
+	priority := entity.DefaultPriority(entity.JobTypeChapterGen, entity.JobTriggerInteractive)
+	estimate, ok := admitQueuedJob(c, h.cfg, h.producer, priority)
+	if !ok {
+		return
+	}
+
+	candidates, selection := h.chapterCandidates(req.Options, targetWordCount)
+
 	jobID := uuid.NewString()
 	inputParams := map[string]any{
 		"mode":              "async_regenerate",
 		"project_id":        chapter.ProjectID,
 		"chapter_id":        chapter.ID,
+		"base_version":      chapter.Version, // 收尾时据此判断生成期间章节是否被修改
 		"outline":           outline,
 		"target_word_count": targetWordCount,
 		"provider":          provider,
@@ -559,11 +1538,16 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 			inputParams["skip_validation"] = true
 		}
 	}
+	if candidates > 1 {
+		inputParams["candidates"] = candidates
+		inputParams["selection"] = selection
+	}
 	inputBytes, _ := json.Marshal(inputParams)
 
 	job := entity.NewGenerationJob(tenantID, chapter.ProjectID, entity.JobTypeChapterGen, inputBytes)
 	job.ID = jobID
 	job.ChapterID = &chapterID
+	job.Priority = priority
 	if idempotencyKey != "" {
 		job.IdempotencyKey = &idempotencyKey
 	}
@@ -579,16 +1563,32 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		dto.InternalError(c, "failed to create job")
 		return
 	}
+	if !h.reserveQuota(c, tenantID, jobID, targetWordCount, candidates) {
+		return
+	}
 
 	chapter.Outline = outline
-	chapter.Status = entity.ChapterStatusGenerating
+	chapter.Status = appstory.ChapterStatusForJob(job.Status, "")
 	if err := h.chapterRepo.Update(ctx, chapter); err != nil {
 		logger.Error(ctx, "failed to update chapter status", err)
 		dto.InternalError(c, "failed to regenerate chapter")
 		return
 	}
 
-	temp := pickOptionTemperature(req.Options)
+	params := messaging.ChapterGenParams{
+		Outline:         outline,
+		TargetWordCount: targetWordCount,
+		Provider:        provider,
+		Model:           model,
+	}
+	if temp := pickOptionTemperature(req.Options); temp != nil {
+		t := float64(*temp)
+		params.Temperature = &t
+	}
+	if candidates > 1 {
+		params.Candidates = candidates
+		params.Selection = string(selection)
+	}
 	msg := &messaging.GenerationJobMessage{
 		JobID:          jobID,
 		TenantID:       tenantID,
@@ -597,15 +1597,7 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		JobType:        string(entity.JobTypeChapterGen),
 		Priority:       job.Priority,
 		IdempotencyKey: job.IdempotencyKey,
-		Params: map[string]interface{}{
-			"outline":           outline,
-			"target_word_count": targetWordCount,
-			"provider":          provider,
-			"model":             model,
-		},
-	}
-	if temp != nil {
-		msg.Params["temperature"] = float64(*temp)
+		Params:         messaging.EncodeJobParams(params),
 	}
 
 	if _, err := h.producer.PublishGenJob(ctx, msg); err != nil {
@@ -613,8 +1605,37 @@ func (h *ChapterHandler) RegenerateChapter(c *gin.Context) {
 		dto.InternalError(c, "failed to enqueue job")
 		return
 	}
+	h.jobTimeline.Record(ctx, job, entity.JobEventQueued, "chapter regeneration queued", map[string]any{"chapter_id": chapter.ID})
+
+	dto.Accepted(c, queuedJobResponse(job, estimate))
+}
+
+// reserveQuota 按目标字数与候选数为任务预留配额；失败时写入响应并返回 false
+func (h *ChapterHandler) reserveQuota(c *gin.Context, tenantID, jobID string, targetWordCount, candidates int) bool {
+	ctx := c.Request.Context()
+	if err := h.quotaChecker.Reserve(ctx, tenantID, jobID, quota.ChapterReserveTokens(targetWordCount)*int64(candidates)); err != nil {
+		if writeQuotaLimitError(c, err) {
+			return false
+		}
+		logger.Error(ctx, "failed to reserve token quota", err)
+		dto.InternalError(c, "quota check failed")
+		return false
+	}
+	return true
+}
 
-	dto.Accepted(c, dto.ToJobResponse(job))
+// chapterCandidates 按 story.best_of_n 上限与预算折算请求的候选数，并返回选择方式（缺省 auto）
+func (h *ChapterHandler) chapterCandidates(opt *dto.GenerationOptions, targetWordCount int) (int, entity.CandidateSelection) {
+	if opt == nil || opt.Candidates <= 1 {
+		return 1, entity.CandidateSelectionAuto
+	}
+	bon := h.cfg.Story.BestOfN
+	n := appstory.CandidateCount(opt.Candidates, bon.MaxCandidates, bon.MaxTotalTokens, quota.ChapterReserveTokens(targetWordCount))
+	selection, err := appstory.NormalizeCandidateSelection(opt.Selection)
+	if err != nil {
+		selection = entity.CandidateSelectionAuto
+	}
+	return n, selection
 }
 
 func pickOptionModel(opt *dto.GenerationOptions) string {
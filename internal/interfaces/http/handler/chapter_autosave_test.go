@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/testing/memrepo"
+)
+
+type autosaveFixture struct {
+	router   *gin.Engine
+	chapters *memrepo.ChapterRepository
+	jobs     *memrepo.JobRepository
+	chapter  *entity.Chapter
+}
+
+// newAutosaveFixture 准备一个 draft 章节；userID 为空时模拟未携带用户身份的请求
+func newAutosaveFixture(t *testing.T, userID string) *autosaveFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := memrepo.NewStore()
+	f := &autosaveFixture{
+		chapters: memrepo.NewChapterRepository(store),
+		jobs:     memrepo.NewJobRepository(store),
+	}
+	cfg := &config.Config{}
+	cfg.Story.AutosaveVersionInterval = time.Hour
+	h := NewChapterHandler(cfg, f.chapters, nil, f.jobs, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		duplicate.NewDetector(f.chapters, 0), nil, nil, nil)
+
+	f.chapter = entity.NewChapter("project-1", "volume-1", 1)
+	f.chapter.Status = entity.ChapterStatusDraft
+	if err := f.chapters.Create(context.Background(), f.chapter); err != nil {
+		t.Fatal(err)
+	}
+
+	f.router = gin.New()
+	f.router.PATCH("/v1/chapters/:cid/content", func(c *gin.Context) {
+		c.Set("tenant_id", "tenant-1")
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		h.AutosaveChapter(c)
+	})
+	return f
+}
+
+func (f *autosaveFixture) save(t *testing.T, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPatch, "/v1/chapters/"+f.chapter.ID+"/content", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response body %q: %v", w.Body.String(), err)
+	}
+	if data, ok := resp["data"].(map[string]any); ok {
+		return w.Code, data
+	}
+	return w.Code, resp
+}
+
+func TestAutosaveChapterDebouncesVersions(t *testing.T) {
+	for _, userID := range []string{"user-1", ""} {
+		f := newAutosaveFixture(t, userID)
+		start := f.chapter.Version
+
+		code, resp := f.save(t, `{"content_text":"第一稿"}`)
+		if code != http.StatusOK || resp["new_version"] != true {
+			t.Fatalf("user %q: first autosave = %d %v, want a new version", userID, code, resp)
+		}
+		// 同一编辑者在合并窗口内的连续保存合并到当前版本（未携带用户身份的请求同样合并）
+		for _, body := range []string{`{"content_text":"第一稿，改"}`, `{"content_text":"第一稿，再改"}`} {
+			code, resp = f.save(t, body)
+			if code != http.StatusOK || resp["new_version"] != false {
+				t.Fatalf("user %q: autosave within window = %d %v, want merged", userID, code, resp)
+			}
+		}
+		chapter, _ := f.chapters.GetByID(context.Background(), f.chapter.ID)
+		if chapter.Version != start+1 || chapter.ContentText != "第一稿，再改" || !chapter.DraftDirty {
+			t.Fatalf("user %q: chapter = v%d %q dirty=%v, want v%d with latest content", userID, chapter.Version, chapter.ContentText, chapter.DraftDirty, start+1)
+		}
+	}
+
+	// 编辑者变化时产生新版本
+	f := newAutosaveFixture(t, "user-2")
+	other := "user-1"
+	now := time.Now()
+	f.chapter.LastEditedBy, f.chapter.LastEditedAt = &other, &now
+	_ = f.chapters.Update(context.Background(), f.chapter)
+	if _, resp := f.save(t, `{"content_text":"接手修改"}`); resp["new_version"] != true {
+		t.Fatalf("another editor's save should create a new version: %v", resp)
+	}
+}
+
+func TestAutosaveChapterConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	// 生成任务正在写入同一章节：拒绝保存并返回冲突任务 ID
+	f := newAutosaveFixture(t, "user-1")
+	job := entity.NewGenerationJob("tenant-1", f.chapter.ProjectID, entity.JobTypeChapterGen, nil)
+	job.ChapterID = &f.chapter.ID
+	job.Start()
+	if err := f.jobs.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	code, resp := f.save(t, `{"content_text":"生成中修改"}`)
+	if code != http.StatusConflict || resp["conflict_job_id"] != job.ID {
+		t.Fatalf("autosave during generation = %d %v, want 409 with job id", code, resp)
+	}
+
+	// 已完成的章节不可自动保存
+	f = newAutosaveFixture(t, "user-1")
+	f.chapter.Status = entity.ChapterStatusCompleted
+	_ = f.chapters.Update(ctx, f.chapter)
+	if code, resp = f.save(t, `{"content_text":"覆盖已完成章节"}`); code != http.StatusConflict {
+		t.Fatalf("autosave of a completed chapter = %d %v, want 409", code, resp)
+	}
+
+	// 基于过期版本的保存
+	f = newAutosaveFixture(t, "user-1")
+	code, resp = f.save(t, `{"content_text":"过期修改","base_version":99}`)
+	if code != http.StatusConflict || resp["current_version"] != float64(f.chapter.Version) {
+		t.Fatalf("stale base_version = %d %v, want 409 with current version", code, resp)
+	}
+	chapter, _ := f.chapters.GetByID(ctx, f.chapter.ID)
+	if chapter.ContentText != "" {
+		t.Fatalf("rejected autosave should not change content, got %q", chapter.ContentText)
+	}
+}
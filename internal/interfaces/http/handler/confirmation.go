@@ -0,0 +1,223 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	stderrors "errors"
+
+	"z-novel-ai-api/internal/application/confirm"
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmationHandler 危险操作二次确认处理器
+type ConfirmationHandler struct {
+	confirmations *confirm.Service
+	projectRepo   repository.ProjectRepository
+	userRepo      repository.UserRepository
+	artifactRepo  repository.ArtifactRepository
+	indexer       *retrieval.Indexer
+}
+
+// NewConfirmationHandler 创建危险操作二次确认处理器
+func NewConfirmationHandler(
+	confirmations *confirm.Service,
+	projectRepo repository.ProjectRepository,
+	userRepo repository.UserRepository,
+	artifactRepo repository.ArtifactRepository,
+	indexer *retrieval.Indexer,
+) *ConfirmationHandler {
+	return &ConfirmationHandler{
+		confirmations: confirmations,
+		projectRepo:   projectRepo,
+		userRepo:      userRepo,
+		artifactRepo:  artifactRepo,
+		indexer:       indexer,
+	}
+}
+
+// CreateConfirmation 申请危险操作确认令牌
+// @Summary 申请危险操作确认令牌
+// @Description 危险操作（project.delete 删除项目、user.delete 删除用户、artifact_branch.delete 删除构件分支（resource_id 为构件 ID，branch_key 为分支名））需两步确认：先申请令牌并查看影响范围（impact：将删除的章节/卷/实体/向量片段数等），再在执行请求的 X-Confirmation-Token 头中出示令牌。令牌与当前用户、操作和资源绑定，5 分钟内有效且只能使用一次；申请令牌需要与执行操作相同的权限
+// @Tags Confirmations
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateConfirmationRequest true "操作与目标资源"
+// @Success 201 {object} dto.Response[dto.ConfirmationResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/confirmations [post]
+func (h *ConfirmationHandler) CreateConfirmation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.CreateConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	var (
+		impact confirm.Impact
+		ok     bool
+	)
+	resourceID := req.ResourceID
+	switch req.Action {
+	case confirm.ActionProjectDelete:
+		impact, ok = h.projectDeleteImpact(c, req.ResourceID)
+	case confirm.ActionUserDelete:
+		impact, ok = h.userDeleteImpact(c, req.ResourceID)
+	case confirm.ActionArtifactBranchDelete:
+		resourceID = confirm.BranchResourceID(req.ResourceID, req.BranchKey)
+		impact, ok = h.branchDeleteImpact(c, req.ResourceID, req.BranchKey)
+	default:
+		dto.BadRequest(c, "unsupported action")
+		return
+	}
+	if !ok {
+		return
+	}
+
+	token, err := h.confirmations.Issue(ctx, middleware.GetTenantIDFromGin(c), middleware.GetUserIDFromGin(c), req.Action, resourceID, impact)
+	if err != nil {
+		logger.Error(ctx, "failed to issue confirmation token", err)
+		dto.InternalError(c, "failed to issue confirmation token")
+		return
+	}
+
+	dto.Created(c, &dto.ConfirmationResponse{Token: token})
+}
+
+// projectDeleteImpact 删除项目的影响范围；向量库不可用时不统计片段数（impact 中不含 segments）
+func (h *ConfirmationHandler) projectDeleteImpact(c *gin.Context, projectID string) (confirm.Impact, bool) {
+	ctx := c.Request.Context()
+	if !h.requirePermission(c, middleware.PermProjectWrite) {
+		return nil, false
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return nil, false
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return nil, false
+	}
+
+	stats, err := h.projectRepo.GetStats(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project stats", err)
+		dto.InternalError(c, "failed to get project stats")
+		return nil, false
+	}
+	impact := confirm.Impact{
+		"chapters": int64(stats.TotalChapters),
+		"volumes":  int64(stats.TotalVolumes),
+		"entities": int64(stats.TotalEntities),
+		"words":    stats.TotalWordCount,
+	}
+
+	counts, err := h.indexer.CountSegments(ctx, middleware.GetTenantIDFromGin(c), projectID)
+	switch {
+	case err == nil:
+		var total int64
+		for _, n := range counts {
+			total += n
+		}
+		impact["segments"] = total
+	case stderrors.Is(err, retrieval.ErrVectorDisabled), stderrors.Is(err, retrieval.ErrSegmentScanUnsupported):
+	default:
+		logger.Warn(ctx, "failed to count project segments for confirmation", "error", err.Error(), "project_id", projectID)
+	}
+	return impact, true
+}
+
+// userDeleteImpact 删除用户的影响范围（其名下项目需先转移或删除，此处一并给出数量）
+func (h *ConfirmationHandler) userDeleteImpact(c *gin.Context, userID string) (confirm.Impact, bool) {
+	ctx := c.Request.Context()
+	if !h.requirePermission(c, middleware.PermAdminAccess) {
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		logger.Error(ctx, "failed to get user", err)
+		dto.InternalError(c, "failed to get user")
+		return nil, false
+	}
+	if user == nil || user.TenantID != middleware.GetTenantIDFromGin(c) {
+		dto.NotFound(c, "user not found")
+		return nil, false
+	}
+
+	owned, err := h.projectRepo.ListByOwner(ctx, userID, repository.NewPagination(1, 1))
+	if err != nil {
+		logger.Error(ctx, "failed to list owned projects", err)
+		dto.InternalError(c, "failed to list owned projects")
+		return nil, false
+	}
+	return confirm.Impact{"users": 1, "owned_projects": owned.Total}, true
+}
+
+// branchDeleteImpact 删除构件分支的影响范围：将删除的版本数（分支记忆随之清理，不单独统计）
+func (h *ConfirmationHandler) branchDeleteImpact(c *gin.Context, artifactID, branchKey string) (confirm.Impact, bool) {
+	ctx := c.Request.Context()
+	if !h.requirePermission(c, middleware.PermProjectWrite) {
+		return nil, false
+	}
+	if branchKey == "" {
+		dto.BadRequest(c, "branch_key is required")
+		return nil, false
+	}
+	if retrieval.IsMainBranch(branchKey) {
+		dto.BadRequest(c, "main branch cannot be deleted")
+		return nil, false
+	}
+
+	art, err := h.artifactRepo.GetArtifactByID(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to get artifact", err)
+		dto.InternalError(c, "failed to get artifact")
+		return nil, false
+	}
+	if art == nil {
+		dto.NotFound(c, "artifact not found")
+		return nil, false
+	}
+
+	summaries, err := h.artifactRepo.ListVersionSummaries(ctx, artifactID)
+	if err != nil {
+		logger.Error(ctx, "failed to list artifact versions", err)
+		dto.InternalError(c, "failed to list artifact versions")
+		return nil, false
+	}
+	var versions int64
+	for _, v := range summaries {
+		if v != nil && v.BranchKey == branchKey {
+			versions++
+		}
+	}
+	if versions == 0 {
+		dto.NotFound(c, "branch not found")
+		return nil, false
+	}
+	return confirm.Impact{"versions": versions}, true
+}
+
+// requirePermission 申请令牌需要与执行操作相同的权限；失败时已写响应
+func (h *ConfirmationHandler) requirePermission(c *gin.Context, perm middleware.Permission) bool {
+	if !middleware.HasPermission(entity.UserRole(c.GetString("role")), perm) {
+		dto.Forbidden(c, "permission denied")
+		return false
+	}
+	return true
+}
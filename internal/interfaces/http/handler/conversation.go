@@ -9,16 +9,23 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
+	"z-novel-ai-api/internal/application/featureflag"
 	"z-novel-ai-api/internal/application/quota"
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
 	storyartifact "z-novel-ai-api/internal/application/story/artifact"
 	storyctx "z-novel-ai-api/internal/application/story/context"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	storytranscript "z-novel-ai-api/internal/application/story/transcript"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/messaging"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 	wfmodel "z-novel-ai-api/internal/workflow/model"
@@ -38,14 +45,24 @@ type ConversationHandler struct {
 	projectRepo repository.ProjectRepository
 	jobRepo     repository.JobRepository
 
-	sessionRepo  repository.ConversationSessionRepository
-	turnRepo     repository.ConversationTurnRepository
-	artifactRepo repository.ArtifactRepository
+	sessionRepo   repository.ConversationSessionRepository
+	turnRepo      repository.ConversationTurnRepository
+	artifactRepo  repository.ArtifactRepository
+	candidateRepo repository.GenerationCandidateRepository
 
 	rollingCtx   *storyctx.RollingContextManager
 	quotaChecker *quota.TokenQuotaChecker
 	generator    *storyartifact.ArtifactGenerator
 	indexer      *appretrieval.Indexer
+	series       *storyseries.SeriesService
+	jobTimeline  *appstory.JobTimeline
+	flags        *featureflag.Service
+	exporter     *storytranscript.Exporter
+	validator    *storyartifact.ActivationValidator
+	outlineStale *storyoutline.StaleTracker
+	producer     *messaging.Producer
+	cancelSignal appstory.JobCancelSignal
+	progress     appstory.JobProgressBus
 }
 
 func NewConversationHandler(
@@ -62,21 +79,41 @@ func NewConversationHandler(
 	quotaChecker *quota.TokenQuotaChecker,
 	generator *storyartifact.ArtifactGenerator,
 	indexer *appretrieval.Indexer,
+	seriesService *storyseries.SeriesService,
+	jobTimeline *appstory.JobTimeline,
+	flags *featureflag.Service,
+	exporter *storytranscript.Exporter,
+	candidateRepo repository.GenerationCandidateRepository,
+	validator *storyartifact.ActivationValidator,
+	outlineStale *storyoutline.StaleTracker,
+	producer *messaging.Producer,
+	cancelSignal appstory.JobCancelSignal,
+	progress appstory.JobProgressBus,
 ) *ConversationHandler {
 	return &ConversationHandler{
-		cfg:          cfg,
-		txMgr:        txMgr,
-		tenantCtx:    tenantCtx,
-		tenantRepo:   tenantRepo,
-		projectRepo:  projectRepo,
-		jobRepo:      jobRepo,
-		sessionRepo:  sessionRepo,
-		turnRepo:     turnRepo,
-		artifactRepo: artifactRepo,
-		rollingCtx:   rollingCtx,
-		quotaChecker: quotaChecker,
-		generator:    generator,
-		indexer:      indexer,
+		cfg:           cfg,
+		txMgr:         txMgr,
+		tenantCtx:     tenantCtx,
+		tenantRepo:    tenantRepo,
+		projectRepo:   projectRepo,
+		jobRepo:       jobRepo,
+		sessionRepo:   sessionRepo,
+		turnRepo:      turnRepo,
+		artifactRepo:  artifactRepo,
+		rollingCtx:    rollingCtx,
+		quotaChecker:  quotaChecker,
+		generator:     generator,
+		indexer:       indexer,
+		series:        seriesService,
+		jobTimeline:   jobTimeline,
+		flags:         flags,
+		exporter:      exporter,
+		candidateRepo: candidateRepo,
+		validator:     validator,
+		outlineStale:  outlineStale,
+		producer:      producer,
+		cancelSignal:  cancelSignal,
+		progress:      progress,
 	}
 }
 
@@ -92,6 +129,7 @@ func NewConversationHandler(
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/sessions [post]
 func (h *ConversationHandler) CreateSession(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -100,7 +138,7 @@ func (h *ConversationHandler) CreateSession(c *gin.Context) {
 
 	var req dto.CreateSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -145,6 +183,7 @@ func (h *ConversationHandler) CreateSession(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.SessionResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/sessions/{sid} [get]
 func (h *ConversationHandler) GetSession(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -162,9 +201,148 @@ func (h *ConversationHandler) GetSession(c *gin.Context) {
 		return
 	}
 
+	usage, err := h.turnRepo.SumUsageBySession(ctx, sessionID)
+	if err != nil {
+		logger.Error(ctx, "failed to sum session usage", err)
+		dto.InternalError(c, "failed to get session")
+		return
+	}
+
+	dto.Success(c, dto.ToSessionResponse(session).WithUsage(usage))
+}
+
+// UpdateSession 更新会话
+// @Summary 更新会话
+// @Description 切换会话任务或设置会话默认生成参数（provider/model/temperature/max_tokens），发送消息时未显式指定的参数以此补全
+// @Tags Conversations
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param sid path string true "会话 ID"
+// @Param body body dto.UpdateSessionRequest true "更新会话请求"
+// @Success 200 {object} dto.Response[dto.SessionResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/sessions/{sid} [patch]
+func (h *ConversationHandler) UpdateSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+	sessionID := dto.BindSessionID(c)
+
+	var req dto.UpdateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	var task entity.ConversationTask
+	if req.Task != nil {
+		normalized, err := normalizeConversationTask(*req.Task)
+		if err != nil {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		task = normalized
+	}
+	defaults := req.GenerationDefaults.ToEntity()
+	if defaults != nil && defaults.Provider != "" {
+		if _, _, err := resolveProviderModel(h.cfg, defaults.Provider, defaults.Model); err != nil {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	var session *entity.ConversationSession
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		var loadErr error
+		session, loadErr = h.sessionRepo.GetByIDForUpdate(txCtx, sessionID)
+		if loadErr != nil {
+			return loadErr
+		}
+		if session == nil || session.ProjectID != projectID {
+			return errNotFound("session not found")
+		}
+
+		if task != "" {
+			session.CurrentTask = task
+		}
+		if req.GenerationDefaults != nil {
+			session.GenerationDefaults = defaults
+		}
+		return h.sessionRepo.Update(txCtx, session)
+	}); err != nil {
+		if isNotFound(err) {
+			dto.NotFound(c, err.Error())
+			return
+		}
+		logger.Error(ctx, "failed to update session", err)
+		dto.InternalError(c, "failed to update session")
+		return
+	}
+
 	dto.Success(c, dto.ToSessionResponse(session))
 }
 
+// ExportSession 导出会话记录
+// @Summary 导出会话记录
+// @Description 按时间顺序导出会话全部轮次（用户提示、助手说明、每一步的构件快照与激活标记），长会话分批流式写出
+// @Tags Conversations
+// @Produce plain
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param sid path string true "会话 ID"
+// @Param format query string false "导出格式：markdown（默认）/ json"
+// @Success 200 {string} string "会话记录"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/sessions/{sid}/export [get]
+func (h *ConversationHandler) ExportSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+	sessionID := dto.BindSessionID(c)
+
+	format, ok := storytranscript.ParseFormat(c.Query("format"))
+	if !ok {
+		dto.BadRequest(c, "unsupported format")
+		return
+	}
+
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Error(ctx, "failed to get session", err)
+		dto.InternalError(c, "failed to get session")
+		return
+	}
+	if session == nil || session.ProjectID != projectID {
+		dto.NotFound(c, "session not found")
+		return
+	}
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	filename := fmt.Sprintf("%s-session-%s.%s", strings.TrimSpace(project.Title), session.ID, format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	c.Status(http.StatusOK)
+	// 响应头已发出，导出中途失败只能截断输出并记录日志
+	if err := h.exporter.Export(ctx, c.Writer, project, session, format); err != nil {
+		logger.Error(ctx, "failed to export session transcript", err)
+	}
+}
+
 // ListTurns 获取会话轮次列表
 // @Summary 获取会话轮次列表
 // @Tags Conversations
@@ -177,6 +355,7 @@ func (h *ConversationHandler) GetSession(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.TurnListResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/sessions/{sid}/turns [get]
 func (h *ConversationHandler) ListTurns(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -211,6 +390,7 @@ func (h *ConversationHandler) ListTurns(c *gin.Context) {
 
 // SendMessage 发送消息并生成构件新版本
 // @Summary 发送消息并生成构件新版本
+// @Description draft=true 时为草稿模式：只保存对话轮次，不创建构件版本、不做冲突扫描与索引，之后可通过 POST /v1/projects/{pid}/sessions/{sid}/turns/{tid}/materialize 将助手轮次转为构件版本
 // @Tags Conversations
 // @Accept json
 // @Produce json
@@ -220,8 +400,11 @@ func (h *ConversationHandler) ListTurns(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.SendMessageResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "生成期间任务被取消"
 // @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 504 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/sessions/{sid}/messages [post]
 func (h *ConversationHandler) SendMessage(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -232,9 +415,26 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 
 	var req dto.SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
+	if req.Draft && req.Candidates > 1 {
+		dto.BadRequest(c, "draft mode does not support multiple candidates")
+		return
+	}
+
+	// 会话默认生成参数补全请求未指定的参数（请求显式值优先）；会话归属在事务内加锁后再次校验
+	defaultsSession, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		logger.Error(ctx, "failed to get session", err)
+		dto.InternalError(c, "failed to send message")
+		return
+	}
+	if defaultsSession == nil || defaultsSession.ProjectID != projectID {
+		dto.NotFound(c, "session not found")
+		return
+	}
+	paramSources := req.ApplySessionDefaults(defaultsSession.GenerationDefaults)
 
 	provider, model, err := resolveProviderModel(h.cfg, req.Provider, req.Model)
 	if err != nil {
@@ -259,15 +459,36 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 	var currentOutline json.RawMessage
 	var currentArtifact json.RawMessage
 	var baseVersionID *string
+	var existingArtifactID string
 
 	requestID := c.GetString("request_id")
 	traceID := c.GetString("trace_id")
 
-	branchKey, activate, enableConflictScan, err := normalizeBranchOptions(req.BranchKey, req.Activate, req.EnableConflictScan)
+	branchKey, enableConflictScan, err := normalizeBranchOptions(req.BranchKey, req.EnableConflictScan)
 	if err != nil {
 		dto.BadRequest(c, err.Error())
 		return
 	}
+	// 冲突扫描按功能开关灰度：开关关闭时即使请求显式开启也跳过
+	if enableConflictScan && !h.flags.Enabled(ctx, entity.FeatureFlagArtifactConflictScan, tenantID, projectID) {
+		enableConflictScan = false
+	}
+	// 草稿不与已有设定比对（转为版本时也不补做扫描）
+	if req.Draft {
+		enableConflictScan = false
+	}
+
+	// 多候选生成：候选数按 story.best_of_n 上限与预算折算
+	candidates, selection := 1, entity.CandidateSelectionAuto
+	if req.Candidates > 1 {
+		bon := h.cfg.Story.BestOfN
+		candidates = appstory.CandidateCount(req.Candidates, bon.MaxCandidates, bon.MaxTotalTokens, quota.ArtifactReserveTokens(req.MaxTokens))
+		if selection, err = appstory.NormalizeCandidateSelection(req.Selection); err != nil {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+	}
+	manualSelection := candidates > 1 && selection == entity.CandidateSelectionManual
 
 	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		var loadErr error
@@ -281,6 +502,11 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 		if quotaErr := precheckQuota(txCtx, h.quotaChecker, tenant); quotaErr != nil {
 			return quotaErr
 		}
+		if candidates > 1 && h.quotaChecker != nil {
+			if _, quotaErr := h.quotaChecker.CheckBalance(txCtx, tenant.ID, int64(candidates)*quota.ArtifactReserveTokens(req.MaxTokens)); quotaErr != nil {
+				return quotaErr
+			}
+		}
 
 		project, loadErr = h.projectRepo.GetByID(txCtx, projectID)
 		if loadErr != nil {
@@ -341,14 +567,29 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 			"max_tokens":  req.MaxTokens,
 			"request_id":  requestID,
 			"trace_id":    traceID,
+			"candidates":  candidates,
+			"selection":   selection,
+			"draft":       req.Draft,
+			"generation_params": map[string]any{
+				"provider":    provider,
+				"model":       model,
+				"temperature": req.Temperature,
+				"max_tokens":  req.MaxTokens,
+				"sources":     paramSources,
+			},
 		})
 		job := entity.NewGenerationJob(tenantID, projectID, entity.JobTypeArtifactGen, inputParams)
 		job.ID = jobID
 		job.Status = entity.JobStatusRunning
 		job.StartedAt = &now
+		// 超长附件在 Prompt 中会被截断，提前告知用户
+		_, truncated := wfmodel.LimitAttachments(req.ToStoryAttachments())
+		job.AddWarnings(appstory.AttachmentWarnings(truncated)...)
+		job.RecordTruncation(appstory.AttachmentTruncations(req.ToStoryAttachments())...)
 		if err := h.jobRepo.Create(txCtx, job); err != nil {
 			return err
 		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "artifact generation started", map[string]any{"task": task, "candidates": candidates})
 
 		arts, loadErr := h.artifactRepo.ListArtifactsByProject(txCtx, projectID)
 		if loadErr != nil {
@@ -425,10 +666,8 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 		if loadErr != nil {
 			return loadErr
 		}
-
-		// 分支默认激活策略：如果是首次生成（无基线版本），默认激活，避免构件长期无 active_version。
-		if req.Activate == nil && baseVersionID == nil {
-			activate = true
+		if a := typeKeyByArtifactType[artifactType]; a != nil {
+			existingArtifactID = a.ID
 		}
 
 		// 目标类型本身也要作为上下文工具可读的“当前版本”。
@@ -441,6 +680,18 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 			currentOutline = currentArtifact
 		}
 
+		// 系列续作：本书尚无世界观/角色设定时，沿用前作的生效版本作为上下文
+		if currentWorldview == nil {
+			if currentWorldview, loadErr = h.inheritedCanon(txCtx, project, entity.ArtifactTypeWorldview); loadErr != nil {
+				return loadErr
+			}
+		}
+		if currentCharacters == nil {
+			if currentCharacters, loadErr = h.inheritedCanon(txCtx, project, entity.ArtifactTypeCharacters); loadErr != nil {
+				return loadErr
+			}
+		}
+
 		return nil
 	}); err != nil {
 		var exceeded quota.TokenBalanceExceededError
@@ -457,11 +708,16 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	// 任务已落库：之后的生成与落库按 story.sync_generation 策略决定是否随客户端断开而中止
+	ctx, cancelGen := detachGeneration(ctx, h.cfg, config.SyncEndpointSendMessage)
+	defer cancelGen()
+
 	conversationSummary := ""
 	recentUserTurns := ""
+	var rollingTruncated []entity.PromptTruncation
 	if h.rollingCtx != nil && task != "" {
 		var err error
-		conversationSummary, recentUserTurns, err = h.rollingCtx.SnapshotAndAppendUserPrompt(ctx, tenantID, projectID, sessionID, task, req.Prompt)
+		conversationSummary, recentUserTurns, rollingTruncated, err = h.rollingCtx.SnapshotAndAppendUserPrompt(ctx, tenantID, projectID, sessionID, task, req.Prompt)
 		if err != nil {
 			logger.Warn(ctx, "failed to update rolling conversation context",
 				"error", err.Error(),
@@ -470,37 +726,81 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 	}
 
 	start := time.Now()
-	out, genErr := h.generator.Generate(ctx, &wfmodel.ArtifactGenerateInput{
-		TenantID:            tenantID,
-		ProjectID:           projectID,
-		ProjectTitle:        project.Title,
-		ProjectDescription:  project.Description,
-		Type:                artifactType,
-		Prompt:              strings.TrimSpace(req.Prompt),
-		Attachments:         req.ToStoryAttachments(),
-		ConversationSummary: conversationSummary,
-		RecentUserTurns:     recentUserTurns,
-		CurrentWorldview:    currentWorldview,
-		CurrentCharacters:   currentCharacters,
-		CurrentOutline:      currentOutline,
-		CurrentArtifactRaw:  currentArtifact,
-		Provider:            provider,
-		Model:               model,
-		Temperature:         req.Temperature,
-		MaxTokens:           req.MaxTokens,
+	genCtx, finishGen := appstory.WithGenerationTimeout(ctx, appstory.StageArtifactGen, h.cfg.Story.GenerationTimeouts.ArtifactGen)
+	// 生成期间可经 POST /v1/jobs/:jid/cancel 取消任务并中止 LLM 调用
+	genCtx, finishCancel := appstory.WithJobCancellation(genCtx, h.cancelSignal, jobID)
+	outs, failedCandidates, genErr := appstory.RunCandidates(genCtx, candidates, func(candCtx context.Context, _ int) (*wfmodel.ArtifactGenerateOutput, error) {
+		return h.generator.Generate(candCtx, &wfmodel.ArtifactGenerateInput{
+			TenantID:            tenantID,
+			ProjectID:           projectID,
+			ProjectTitle:        project.Title,
+			ProjectDescription:  project.Description,
+			Type:                artifactType,
+			BranchKey:           branchKey,
+			Prompt:              strings.TrimSpace(req.Prompt),
+			Attachments:         req.ToStoryAttachments(),
+			ConversationSummary: conversationSummary,
+			RecentUserTurns:     recentUserTurns,
+			CurrentWorldview:    currentWorldview,
+			CurrentCharacters:   currentCharacters,
+			CurrentOutline:      currentOutline,
+			CurrentArtifactRaw:  currentArtifact,
+			Provider:            provider,
+			Model:               model,
+			Temperature:         req.Temperature,
+			MaxTokens:           req.MaxTokens,
+		})
 	})
+	genErr = finishGen(finishCancel(genErr))
 	durationMs := int(time.Since(start).Milliseconds())
 
+	// 任务已由取消接口记为 cancelled 并释放预留，结果不再落库
+	if appstory.IsJobCancelled(genErr) {
+		dto.Conflict(c, "job cancelled")
+		return
+	}
 	if genErr != nil {
+		genErr = disconnectError(c, genErr)
 		_ = h.markJobFailed(ctx, tenantID, jobID, genErr, durationMs)
 		logger.Error(ctx, "artifact generation failed", genErr)
+		if appstory.IsGenerationTimeout(genErr) {
+			dto.Error(c, http.StatusGatewayTimeout, "artifact generation timed out")
+			return
+		}
 		dto.InternalError(c, "artifact generation failed")
 		return
 	}
 
+	// 多候选时按质量评分排序：auto 采用最高分候选，manual 仅保存候选待用户选择
+	scores := make([]float64, len(outs))
+	for i := range outs {
+		scores[i] = appstory.ScoreArtifact(outs[i].Content)
+	}
+	best := appstory.BestCandidate(scores)
+	out := outs[best]
+	usage := out.Meta
+	for i := range outs {
+		if i != best {
+			usage.PromptTokens += outs[i].Meta.PromptTokens
+			usage.CompletionTokens += outs[i].Meta.CompletionTokens
+		}
+	}
+
 	var conflictWarnings []*dto.SettingConflictWarning
-	if enableConflictScan && hasAnyArtifactContext(project, currentWorldview, currentCharacters, currentOutline, currentArtifact) {
-		scanOut, scanErr := h.generator.ScanConflicts(ctx, &wfmodel.ArtifactConflictScanInput{
+	var jobWarnings []entity.JobWarning
+	var truncation *entity.TruncationReport
+	if failedCandidates > 0 {
+		jobWarnings = append(jobWarnings, appstory.CandidatesFailedWarning(failedCandidates, candidates))
+	}
+	// 冲突检查结果用于 auto_on_clean_scan 策略：没有可比对的已有设定时视为无冲突
+	scanOutcome := entity.ConflictScanSkipped
+	hasScanContext := hasAnyArtifactContext(project, currentWorldview, currentCharacters, currentOutline, currentArtifact)
+	if enableConflictScan && !manualSelection && !hasScanContext {
+		scanOutcome = entity.ConflictScanClean
+	}
+	if enableConflictScan && !manualSelection && hasScanContext {
+		scanCtx, finishScan := appstory.WithGenerationTimeout(ctx, appstory.StageConflictScan, h.cfg.Story.GenerationTimeouts.ConflictScan)
+		scanOut, scanErr := h.generator.ScanConflicts(scanCtx, &wfmodel.ArtifactConflictScanInput{
 			ProjectTitle:       project.Title,
 			ProjectDescription: project.Description,
 			ProjectGenre:       project.Genre,
@@ -515,12 +815,21 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 			Temperature:        req.Temperature,
 			MaxTokens:          req.MaxTokens,
 		})
+		scanErr = finishScan(scanErr)
 		if scanErr != nil {
 			logger.Warn(ctx, "artifact conflict scan failed",
 				"error", scanErr.Error(),
 				"artifact_type", string(out.Type),
 			)
-		} else if scanOut != nil && len(scanOut.Conflicts) > 0 {
+			if appstory.IsGenerationTimeout(scanErr) {
+				jobWarnings = append(jobWarnings, appstory.ConflictScanTimeoutWarning())
+			} else {
+				jobWarnings = append(jobWarnings, appstory.ConflictScanWarning())
+			}
+		} else if scanOut == nil || len(scanOut.Conflicts) == 0 {
+			scanOutcome = entity.ConflictScanClean
+		} else {
+			scanOutcome = entity.ConflictScanConflicts
 			conflictWarnings = make([]*dto.SettingConflictWarning, 0, len(scanOut.Conflicts))
 			for i := range scanOut.Conflicts {
 				cf := scanOut.Conflicts[i]
@@ -535,7 +844,42 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 		}
 	}
 
+	// 按项目激活策略决定新版本是否激活（请求显式指定 activate 时以请求为准）
+	activationPolicy := project.Settings.ArtifactActivationPolicy()
+	activate, activationReason := entity.DecideArtifactActivation(entity.ArtifactActivationInput{
+		Policy:       activationPolicy,
+		BranchKey:    branchKey,
+		Requested:    req.Activate,
+		FirstVersion: baseVersionID == nil,
+		Scan:         scanOutcome,
+	})
+	if req.Draft {
+		// 草稿不创建版本，是否激活在转为版本时决定
+		activate, activationReason = false, ""
+	}
+
+	// 激活前的租户校验：不通过（或校验不可用）时仍保存新版本，但不激活
+	var activationBlocked *dto.ErrorDetail
+	if activate && !manualSelection {
+		if err := h.validator.Check(ctx, &storyartifact.ActivationValidationRequest{
+			TenantID:     tenantID,
+			ProjectID:    projectID,
+			ArtifactID:   existingArtifactID,
+			ArtifactType: out.Type,
+			Trigger:      storyartifact.ActivationTriggerConversation,
+			Content:      out.Content,
+		}); err != nil {
+			activationBlocked = activationBlockedDetail(err)
+			activate, activationReason = false, entity.ActivationReasonValidationBlocked
+			jobWarnings = append(jobWarnings, appstory.ActivationBlockedWarning(activationBlocked.Details))
+		}
+	}
+
 	var snapshot *dto.ArtifactSnapshotResponse
+	var sessionUsage *entity.ConversationUsage
+	var candidateSummaries []*dto.CandidateResponse
+	var assistantMessage string
+	var outlineMark *storyoutline.StaleMark
 	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		session, err := h.sessionRepo.GetByIDForUpdate(txCtx, sessionID)
 		if err != nil {
@@ -545,119 +889,200 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 			return errNotFound("session not found")
 		}
 
-		art, err := h.artifactRepo.EnsureArtifact(txCtx, tenantID, projectID, out.Type)
-		if err != nil {
-			return err
-		}
-
-		latest, err := h.artifactRepo.GetLatestVersionNo(txCtx, art.ID)
-		if err != nil {
-			return err
-		}
-		nextNo := latest + 1
-
-		versionID := uuid.NewString()
-		createdBy := strings.TrimSpace(userID)
-		sourceJobID := jobID
-
-		version := &entity.ArtifactVersion{
-			ID:              versionID,
-			ArtifactID:      art.ID,
-			VersionNo:       nextNo,
-			BranchKey:       branchKey,
-			ParentVersionID: baseVersionID,
-			Content:         out.Content,
-			CreatedBy:       &createdBy,
-			SourceJobID:     &sourceJobID,
-		}
-		if err := h.artifactRepo.CreateVersion(txCtx, version); err != nil {
-			return err
+		// 草稿不创建构件与版本，生成结果保存在助手轮次 metadata.draft_content 中
+		var art *entity.ProjectArtifact
+		if !req.Draft {
+			if art, err = h.artifactRepo.EnsureArtifact(txCtx, tenantID, projectID, out.Type); err != nil {
+				return err
+			}
 		}
 
-		if activate {
-			if err := h.artifactRepo.SetActiveVersion(txCtx, art.ID, version.ID); err != nil {
+		// manual 选择时不写版本，由 POST /v1/jobs/:jid/candidates/:cand/select 采用候选后再写入
+		var version *entity.ArtifactVersion
+		if art != nil && !manualSelection {
+			version, outlineMark, err = persistArtifactVersion(txCtx, h.artifactRepo, h.projectRepo, h.outlineStale, project, art, artifactVersionInput{
+				Content:         out.Content,
+				BranchKey:       branchKey,
+				ParentVersionID: baseVersionID,
+				Activate:        activate,
+				CreatedBy:       userID,
+				SourceJobID:     jobID,
+				Metadata: &entity.ArtifactVersionMetadata{
+					ActivationPolicy: activationPolicy,
+					Activated:        activate,
+					ActivationReason: activationReason,
+				},
+			})
+			if err != nil {
 				return err
 			}
 		}
 
-		if activate && out.Type == entity.ArtifactTypeNovelFoundation {
-			var payload struct {
-				Title       string `json:"title"`
-				Description string `json:"description"`
-				Genre       string `json:"genre,omitempty"`
-			}
-			if err := json.Unmarshal(out.Content, &payload); err != nil {
-				return fmt.Errorf("invalid novel_foundation content: %w", err)
-			}
-			payload.Title = strings.TrimSpace(payload.Title)
-			payload.Description = strings.TrimSpace(payload.Description)
-			payload.Genre = strings.TrimSpace(payload.Genre)
-			if payload.Title != "" {
-				project.Title = payload.Title
-			}
-			if payload.Description != "" {
-				project.Description = payload.Description
-			}
-			if payload.Genre != "" {
-				project.Genre = payload.Genre
+		var saved []*entity.GenerationCandidate
+		if candidates > 1 {
+			apply := &entity.CandidateApplyOptions{BranchKey: branchKey, ParentVersionID: baseVersionID, Activate: activate}
+			saved = make([]*entity.GenerationCandidate, 0, len(outs))
+			for i := range outs {
+				cand := &entity.GenerationCandidate{
+					TenantID:         tenantID,
+					JobID:            jobID,
+					ProjectID:        projectID,
+					TargetType:       entity.CandidateTargetArtifact,
+					TargetID:         art.ID,
+					CandidateNo:      i + 1,
+					Status:           entity.CandidateStatusPending,
+					Score:            scores[i],
+					Content:          string(outs[i].Content),
+					Raw:              outs[i].Raw,
+					Apply:            apply,
+					Provider:         outs[i].Meta.Provider,
+					Model:            outs[i].Meta.Model,
+					PromptTokens:     outs[i].Meta.PromptTokens,
+					CompletionTokens: outs[i].Meta.CompletionTokens,
+					Temperature:      outs[i].Meta.Temperature,
+				}
+				if version != nil {
+					cand.Status = entity.CandidateStatusDiscarded
+					if i == best {
+						now := time.Now()
+						cand.Status = entity.CandidateStatusSelected
+						cand.VersionID = &version.ID
+						cand.SelectedAt = &now
+					}
+				}
+				saved = append(saved, cand)
 			}
-			if err := h.projectRepo.Update(txCtx, project); err != nil {
+			if err := h.candidateRepo.CreateBatch(txCtx, saved); err != nil {
 				return err
 			}
+			for _, cand := range saved {
+				candidateSummaries = append(candidateSummaries, dto.ToCandidateResponse(cand, false))
+			}
 		}
 
+		assistantMessage = out.Raw
 		metaObj := map[string]any{
 			"job_id":            jobID,
-			"artifact_id":       art.ID,
-			"version_id":        version.ID,
-			"version_no":        version.VersionNo,
 			"branch_key":        branchKey,
 			"parent_version_id": baseVersionID,
-			"activated":         activate,
+			"activated":         activate && version != nil,
+			"activation_policy": activationPolicy,
+			"activation_reason": activationReason,
 			"provider":          out.Meta.Provider,
 			"model":             out.Meta.Model,
 			"generation_mode":   out.Mode,
-			"prompt_tokens":     out.Meta.PromptTokens,
-			"completion_tokens": out.Meta.CompletionTokens,
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
 			"temperature":       out.Meta.Temperature,
 			"duration_ms":       durationMs,
 			"generated_at":      out.Meta.GeneratedAt.Format(time.RFC3339),
 			"request_id":        requestID,
 			"trace_id":          traceID,
 		}
+		if art != nil {
+			metaObj["artifact_id"] = art.ID
+		}
+		if version != nil {
+			metaObj["version_id"] = version.ID
+			metaObj["version_no"] = version.VersionNo
+		}
+		if req.Draft {
+			metaObj["draft"] = true
+			metaObj["artifact_type"] = out.Type
+			metaObj["draft_content"] = out.Content
+		}
 		if strings.TrimSpace(out.Mode) == "json_patch" && strings.TrimSpace(out.ModelRaw) != "" {
 			metaObj["model_raw"] = out.ModelRaw
 		}
 		if len(conflictWarnings) > 0 {
 			metaObj["conflict_warnings"] = conflictWarnings
 		}
+		if candidates > 1 {
+			metaObj["selection"] = selection
+			metaObj["candidates"] = candidateSummaries
+			if manualSelection {
+				assistantMessage = fmt.Sprintf("Generated %d candidates; select one to create a new version.", len(saved))
+			}
+		}
 		assistantMeta, _ := json.Marshal(metaObj)
-		assistantTurn := entity.NewConversationTurn(sessionID, entity.RoleAssistant, task, out.Raw, assistantMeta)
+		assistantTurn := entity.NewConversationTurn(sessionID, entity.RoleAssistant, task, assistantMessage, assistantMeta)
 		assistantTurn.ID = assistantTurnID
+		cost, currency := h.turnCost(usage)
+		assistantTurn.SetUsage(usage.PromptTokens, usage.CompletionTokens, cost, currency)
 		if err := h.turnRepo.Create(txCtx, assistantTurn); err != nil {
 			return err
 		}
+		if sessionUsage, err = h.turnRepo.SumUsageBySession(txCtx, sessionID); err != nil {
+			return err
+		}
 
 		job, err := h.jobRepo.GetByID(txCtx, jobID)
 		if err != nil || job == nil {
 			return err
 		}
 		job.OutputResult = out.Content
+		if manualSelection {
+			job.OutputResult, _ = json.Marshal(map[string]any{
+				"artifact_id": art.ID,
+				"selection":   selection,
+				"candidates":  candidateSummaries,
+			})
+		}
 		job.Status = entity.JobStatusCompleted
 		done := time.Now()
 		job.CompletedAt = &done
 		job.DurationMs = durationMs
-		job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
+		job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, usage.PromptTokens, usage.CompletionTokens)
+		job.AddWarnings(jobWarnings...)
+		job.RecordTruncation(rollingTruncated...)
+		truncation = job.Truncation
 		if err := h.jobRepo.Update(txCtx, job); err != nil {
 			return err
 		}
+		if out.RepairRounds > 0 {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventRepaired, "artifact repaired after validation failure", map[string]any{
+				"repair_rounds": out.RepairRounds,
+				"fallback_used": out.FallbackUsed,
+				"graph_path":    out.GraphPath,
+			})
+		}
+		if req.Draft {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "artifact draft generated", map[string]any{
+				"prompt_tokens":     usage.PromptTokens,
+				"completion_tokens": usage.CompletionTokens,
+			})
+		} else if version == nil {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "artifact candidates generated", map[string]any{
+				"candidates":        len(saved),
+				"prompt_tokens":     usage.PromptTokens,
+				"completion_tokens": usage.CompletionTokens,
+			})
+		} else {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "artifact version created", map[string]any{
+				"version_id":        version.ID,
+				"version_no":        version.VersionNo,
+				"prompt_tokens":     usage.PromptTokens,
+				"completion_tokens": usage.CompletionTokens,
+			})
+			if len(saved) > 0 {
+				h.jobTimeline.Record(txCtx, job, entity.JobEventSelected, "highest scoring candidate applied", map[string]any{
+					"candidate_id": saved[best].ID,
+					"score":        saved[best].Score,
+				})
+			}
+		}
+		if clientGone(c) {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventDetached, "client disconnected; result persisted in background", nil)
+		}
 
-		snapshot = &dto.ArtifactSnapshotResponse{
-			ArtifactID: art.ID,
-			Type:       string(art.Type),
-			VersionID:  version.ID,
-			VersionNo:  version.VersionNo,
-			Content:    version.Content,
+		if version != nil {
+			snapshot = &dto.ArtifactSnapshotResponse{
+				ArtifactID: art.ID,
+				Type:       string(art.Type),
+				VersionID:  version.ID,
+				VersionNo:  version.VersionNo,
+				Content:    version.Content,
+			}
 		}
 
 		return nil
@@ -671,55 +1096,72 @@ func (h *ConversationHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	// 同步写索引（仅对激活版本）
-	if activate && snapshot != nil && h.indexer != nil {
-		indexCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-		if err := h.indexer.IndexArtifactJSON(indexCtx, tenantID, projectID, out.Type, snapshot.ArtifactID, snapshot.Content); err != nil && !errors.Is(err, appretrieval.ErrVectorDisabled) {
-			logger.Warn(ctx, "failed to index artifact",
-				"error", err.Error(),
-				"artifact_id", snapshot.ArtifactID,
-				"artifact_type", string(out.Type),
-			)
-		}
-	}
+	publishOutlineRefresh(ctx, h.producer, outlineMark)
+
+	// 同步写索引（激活版本写主线，分支版本写分支记忆）
+	h.indexSnapshot(ctx, tenantID, projectID, jobID, out.Type, branchKey, activate, snapshot)
+
+	// 冲突提醒推送到项目频道（WebSocket 订阅方可在其他标签页/协作者处收到）
+	appstory.PublishConflictWarnings(ctx, h.progress, tenantID, projectID, jobID, conflictWarnings)
 
 	dto.Success(c, &dto.SendMessageResponse{
-		Session:          dto.ToSessionResponse(session),
-		UserTurnID:       userTurnID,
-		AssistantTurnID:  assistantTurnID,
-		AssistantMessage: out.Raw,
-		JobID:            jobID,
-		ArtifactSnapshot: snapshot,
-		ConflictWarnings: conflictWarnings,
+		Session:           dto.ToSessionResponse(session).WithUsage(sessionUsage),
+		UserTurnID:        userTurnID,
+		AssistantTurnID:   assistantTurnID,
+		AssistantMessage:  assistantMessage,
+		JobID:             jobID,
+		ArtifactSnapshot:  snapshot,
+		ConflictWarnings:  conflictWarnings,
+		Candidates:        candidateSummaries,
+		ActivationBlocked: activationBlocked,
+		Draft:             req.Draft,
 		Usage: &dto.FoundationUsageResponse{
 			Provider:         out.Meta.Provider,
 			Model:            out.Meta.Model,
-			PromptTokens:     out.Meta.PromptTokens,
-			CompletionTokens: out.Meta.CompletionTokens,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
 			Temperature:      out.Meta.Temperature,
 			DurationMs:       durationMs,
 			GeneratedAt:      out.Meta.GeneratedAt.Format(time.RFC3339),
+			Truncation:       dto.ToTruncationReportResponse(truncation),
 		},
 	})
 }
 
-func normalizeBranchOptions(branchKey string, activate *bool, enableConflictScan *bool) (normalizedBranch string, normalizedActivate bool, normalizedScan bool, err error) {
+// indexSnapshot 同步写入新版本的向量索引（见 indexArtifactVersion）；失败时记录任务警告（不影响接口结果）
+func (h *ConversationHandler) indexSnapshot(ctx context.Context, tenantID, projectID, jobID string, artifactType entity.ArtifactType, branchKey string, activated bool, snapshot *dto.ArtifactSnapshotResponse) {
+	if snapshot == nil {
+		return
+	}
+	if err := indexArtifactVersion(h.indexer, tenantID, projectID, artifactType, snapshot.ArtifactID, branchKey, activated, snapshot.Content); err != nil {
+		logger.Warn(ctx, "failed to index artifact",
+			"error", err.Error(),
+			"artifact_id", snapshot.ArtifactID,
+			"artifact_type", string(artifactType),
+			"branch_key", branchKey,
+		)
+		if jobID == "" {
+			return
+		}
+		if werr := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+			return h.jobRepo.AppendWarnings(txCtx, jobID, appstory.IndexFailedWarning())
+		}); werr != nil {
+			logger.Warn(ctx, "failed to record job warning", "error", werr.Error(), "job_id", jobID)
+		}
+	}
+}
+
+// normalizeBranchOptions 规范化分支与冲突检查选项；是否激活由项目激活策略决定（entity.DecideArtifactActivation）
+func normalizeBranchOptions(branchKey string, enableConflictScan *bool) (normalizedBranch string, normalizedScan bool, err error) {
 	bk := strings.TrimSpace(branchKey)
 	if bk == "" {
 		bk = "main"
 	}
 	if len(bk) > 64 {
-		return "", false, false, fmt.Errorf("branch_key too long")
+		return "", false, fmt.Errorf("branch_key too long")
 	}
 	if !isValidBranchKey(bk) {
-		return "", false, false, fmt.Errorf("invalid branch_key: %s", bk)
-	}
-
-	if activate != nil {
-		normalizedActivate = *activate
-	} else {
-		normalizedActivate = bk == "main"
+		return "", false, fmt.Errorf("invalid branch_key: %s", bk)
 	}
 
 	if enableConflictScan != nil {
@@ -728,7 +1170,7 @@ func normalizeBranchOptions(branchKey string, activate *bool, enableConflictScan
 		normalizedScan = true
 	}
 
-	return bk, normalizedActivate, normalizedScan, nil
+	return bk, normalizedScan, nil
 }
 
 func isValidBranchKey(s string) bool {
@@ -771,6 +1213,38 @@ func hasAnyArtifactContext(project *entity.Project, worldview, characters, outli
 	return false
 }
 
+// inheritedCanon 返回系列前作的生效设定内容；未加入系列或前作均无该设定时返回 nil。
+func (h *ConversationHandler) inheritedCanon(ctx context.Context, project *entity.Project, t entity.ArtifactType) (json.RawMessage, error) {
+	if h.series == nil {
+		return nil, nil
+	}
+	canon, err := h.series.ResolveCanon(ctx, project, t)
+	if err != nil || canon == nil || canon.Version == nil {
+		return nil, err
+	}
+	return canon.Version.Content, nil
+}
+
+// turnCost 按提供商配置单价折算本轮成本；未配置单价时返回 0 与空币种
+func (h *ConversationHandler) turnCost(meta wfmodel.LLMUsageMeta) (float64, string) {
+	if h.cfg == nil {
+		return 0, ""
+	}
+	pc, ok := h.cfg.LLM.Providers[strings.TrimSpace(meta.Provider)]
+	if !ok {
+		return 0, ""
+	}
+	cost, priced := pc.Pricing.Cost(meta.PromptTokens, meta.CompletionTokens)
+	if !priced {
+		return 0, ""
+	}
+	currency := strings.TrimSpace(pc.Pricing.Currency)
+	if currency == "" {
+		currency = config.UnknownCurrency
+	}
+	return cost, currency
+}
+
 func (h *ConversationHandler) writeQuotaError(c *gin.Context, err error) {
 	var exceeded quota.TokenBalanceExceededError
 	if errors.As(err, &exceeded) {
@@ -780,18 +1254,28 @@ func (h *ConversationHandler) writeQuotaError(c *gin.Context, err error) {
 	dto.InternalError(c, "quota check failed")
 }
 
+// markJobFailed 标记任务失败；请求已取消时仍需落库，因此不随请求上下文取消
 func (h *ConversationHandler) markJobFailed(ctx context.Context, tenantID, jobID string, err error, durationMs int) error {
-	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+	return withTenantTx(context.WithoutCancel(ctx), h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		job, getErr := h.jobRepo.GetByID(txCtx, jobID)
 		if getErr != nil || job == nil {
 			return getErr
 		}
 		job.Status = entity.JobStatusFailed
 		job.ErrorMessage = err.Error()
+		job.ErrorCode = appstory.JobErrorCode(err)
 		now := time.Now()
 		job.CompletedAt = &now
 		job.DurationMs = durationMs
-		return h.jobRepo.Update(txCtx, job)
+		if updateErr := h.jobRepo.Update(txCtx, job); updateErr != nil {
+			return updateErr
+		}
+		var ve storyartifact.ArtifactValidationError
+		if errors.As(err, &ve) {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventValidateFailed, "artifact validation failed", map[string]any{"issues": ve.Issues})
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventFailed, err.Error(), nil)
+		return nil
 	})
 }
 
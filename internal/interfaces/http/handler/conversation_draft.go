@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// draftTurnMeta 草稿助手轮次 metadata 中转为构件版本所需的字段
+type draftTurnMeta struct {
+	Draft                 bool                `json:"draft"`
+	JobID                 string              `json:"job_id"`
+	ArtifactType          entity.ArtifactType `json:"artifact_type"`
+	DraftContent          json.RawMessage     `json:"draft_content"`
+	MaterializedVersionID string              `json:"materialized_version_id"`
+}
+
+// draftStateError 轮次当前状态不允许转为构件版本（非草稿或已转换）
+type draftStateError struct {
+	msg string
+}
+
+func (e draftStateError) Error() string {
+	return e.msg
+}
+
+// MaterializeTurn 将草稿轮次转为构件版本
+// @Summary 将草稿轮次转为构件版本
+// @Description 将草稿模式（draft=true）生成的助手轮次保存为构件新版本；每个草稿轮次只能转换一次。父版本为目标分支的最新版本（分支无版本时为激活版本），是否激活按请求或项目激活策略决定（草稿未做冲突扫描）
+// @Tags Conversations
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param sid path string true "会话 ID"
+// @Param tid path string true "助手轮次 ID"
+// @Param body body dto.MaterializeTurnRequest false "转换选项"
+// @Success 200 {object} dto.Response[dto.MaterializeTurnResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "非草稿轮次或已转为版本"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/sessions/{sid}/turns/{tid}/materialize [post]
+func (h *ConversationHandler) MaterializeTurn(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := middleware.GetUserIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+	sessionID := dto.BindSessionID(c)
+	turnID := dto.BindTurnID(c)
+
+	var req dto.MaterializeTurnRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		dto.InvalidBody(c, err)
+		return
+	}
+	branchKey, _, err := normalizeBranchOptions(req.BranchKey, nil)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	// 1. 读取草稿并决定是否激活（租户校验 Webhook 在事务外调用）
+	var project *entity.Project
+	var draft *draftTurnMeta
+	var existingArtifactID string
+	firstVersion := true
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		var err error
+		if project, _, draft, err = h.loadDraftTurn(txCtx, projectID, sessionID, turnID); err != nil {
+			return err
+		}
+		arts, err := h.artifactRepo.ListArtifactsByProject(txCtx, projectID)
+		if err != nil {
+			return err
+		}
+		for _, a := range arts {
+			if a.Type != draft.ArtifactType {
+				continue
+			}
+			existingArtifactID = a.ID
+			base, err := artifactBaseVersion(txCtx, h.artifactRepo, a, branchKey)
+			if err != nil {
+				return err
+			}
+			firstVersion = base == nil
+		}
+		return nil
+	}); err != nil {
+		h.writeDraftError(c, err)
+		return
+	}
+
+	activationPolicy := project.Settings.ArtifactActivationPolicy()
+	activate, activationReason := entity.DecideArtifactActivation(entity.ArtifactActivationInput{
+		Policy:       activationPolicy,
+		BranchKey:    branchKey,
+		Requested:    req.Activate,
+		FirstVersion: firstVersion,
+		Scan:         entity.ConflictScanSkipped,
+	})
+	var activationBlocked *dto.ErrorDetail
+	if activate {
+		if err := h.validator.Check(ctx, &storyartifact.ActivationValidationRequest{
+			TenantID:     tenantID,
+			ProjectID:    projectID,
+			ArtifactID:   existingArtifactID,
+			ArtifactType: draft.ArtifactType,
+			Trigger:      storyartifact.ActivationTriggerDraft,
+			Content:      draft.DraftContent,
+		}); err != nil {
+			activationBlocked = activationBlockedDetail(err)
+			activate, activationReason = false, entity.ActivationReasonValidationBlocked
+		}
+	}
+
+	// 2. 锁定轮次后再次校验并写入版本
+	var snapshot *dto.ArtifactSnapshotResponse
+	var outlineMark *storyoutline.StaleMark
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		project, turn, draft, err := h.loadDraftTurn(txCtx, projectID, sessionID, turnID)
+		if err != nil {
+			return err
+		}
+		art, err := h.artifactRepo.EnsureArtifact(txCtx, tenantID, projectID, draft.ArtifactType)
+		if err != nil {
+			return err
+		}
+		base, err := artifactBaseVersion(txCtx, h.artifactRepo, art, branchKey)
+		if err != nil {
+			return err
+		}
+		var parentVersionID *string
+		if base != nil {
+			parentVersionID = &base.ID
+		}
+
+		var version *entity.ArtifactVersion
+		version, outlineMark, err = persistArtifactVersion(txCtx, h.artifactRepo, h.projectRepo, h.outlineStale, project, art, artifactVersionInput{
+			Content:         draft.DraftContent,
+			BranchKey:       branchKey,
+			ParentVersionID: parentVersionID,
+			Activate:        activate,
+			CreatedBy:       userID,
+			SourceJobID:     draft.JobID,
+			Metadata: &entity.ArtifactVersionMetadata{
+				ActivationPolicy: activationPolicy,
+				Activated:        activate,
+				ActivationReason: activationReason,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		metadata, err := markDraftMaterialized(turn.Metadata, art.ID, version)
+		if err != nil {
+			return err
+		}
+		if err := h.turnRepo.UpdateMetadata(txCtx, turn.ID, metadata); err != nil {
+			return err
+		}
+
+		snapshot = &dto.ArtifactSnapshotResponse{
+			ArtifactID: art.ID,
+			Type:       string(art.Type),
+			VersionID:  version.ID,
+			VersionNo:  version.VersionNo,
+			Content:    version.Content,
+		}
+		return nil
+	}); err != nil {
+		h.writeDraftError(c, err)
+		return
+	}
+
+	publishOutlineRefresh(ctx, h.producer, outlineMark)
+	h.indexSnapshot(ctx, tenantID, projectID, draft.JobID, draft.ArtifactType, branchKey, activate, snapshot)
+
+	dto.Success(c, &dto.MaterializeTurnResponse{
+		TurnID:            turnID,
+		JobID:             draft.JobID,
+		ArtifactSnapshot:  snapshot,
+		Activated:         activate,
+		ActivationBlocked: activationBlocked,
+	})
+}
+
+// loadDraftTurn 校验项目、会话与轮次归属，锁定轮次并解析草稿；非草稿或已转换时返回 draftStateError
+func (h *ConversationHandler) loadDraftTurn(ctx context.Context, projectID, sessionID, turnID string) (*entity.Project, *entity.ConversationTurn, *draftTurnMeta, error) {
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if project == nil {
+		return nil, nil, nil, errNotFound("project not found")
+	}
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if session == nil || session.ProjectID != projectID {
+		return nil, nil, nil, errNotFound("session not found")
+	}
+
+	turn, err := h.turnRepo.GetByIDForUpdate(ctx, turnID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if turn == nil || turn.SessionID != sessionID {
+		return nil, nil, nil, errNotFound("turn not found")
+	}
+
+	var meta draftTurnMeta
+	if turn.Role != entity.RoleAssistant || len(turn.Metadata) == 0 || json.Unmarshal(turn.Metadata, &meta) != nil ||
+		!meta.Draft || len(meta.DraftContent) == 0 || strings.TrimSpace(meta.JobID) == "" {
+		return nil, nil, nil, draftStateError{msg: "turn is not a draft"}
+	}
+	if meta.MaterializedVersionID != "" {
+		return nil, nil, nil, draftStateError{msg: "draft turn already materialized"}
+	}
+	return project, turn, &meta, nil
+}
+
+// writeDraftError 草稿转换失败的错误响应
+func (h *ConversationHandler) writeDraftError(c *gin.Context, err error) {
+	var stateErr draftStateError
+	switch {
+	case isNotFound(err):
+		dto.NotFound(c, err.Error())
+	case errors.As(err, &stateErr):
+		dto.Conflict(c, stateErr.Error())
+	default:
+		logger.Error(c.Request.Context(), "failed to materialize draft turn", err)
+		dto.InternalError(c, "failed to materialize draft")
+	}
+}
+
+// artifactBaseVersion 新版本的父版本：目标分支的最新版本，分支尚无版本时为激活版本
+func artifactBaseVersion(ctx context.Context, artifactRepo repository.ArtifactRepository, art *entity.ProjectArtifact, branchKey string) (*entity.ArtifactVersion, error) {
+	if branchKey != "" && branchKey != "main" {
+		v, err := artifactRepo.GetLatestVersionByBranch(ctx, art.ID, branchKey)
+		if err != nil || v != nil {
+			return v, err
+		}
+	}
+	if art.ActiveVersionID == nil || strings.TrimSpace(*art.ActiveVersionID) == "" {
+		return nil, nil
+	}
+	return artifactRepo.GetVersionByID(ctx, *art.ActiveVersionID)
+}
+
+// markDraftMaterialized 在轮次 metadata 中记录草稿转成的版本（保留其余字段）
+func markDraftMaterialized(raw json.RawMessage, artifactID string, version *entity.ArtifactVersion) (json.RawMessage, error) {
+	meta := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, err
+	}
+	for key, value := range map[string]any{
+		"artifact_id":             artifactID,
+		"materialized_version_id": version.ID,
+		"materialized_version_no": version.VersionNo,
+		"materialized_at":         time.Now().UTC().Format(time.RFC3339),
+	} {
+		meta[key], _ = json.Marshal(value)
+	}
+	return json.Marshal(meta)
+}
@@ -0,0 +1,157 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/diagnostics"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosticsHandler 运行时诊断处理器（pprof/expvar/快照，仅 admin）
+type DiagnosticsHandler struct {
+	enabled   bool
+	snapshots *diagnostics.Snapshotter
+	debug     http.Handler
+}
+
+// NewDiagnosticsHandler 创建运行时诊断处理器；observability.diagnostics.enabled 关闭时不注册路由
+func NewDiagnosticsHandler(cfg *config.Config) *DiagnosticsHandler {
+	diag := cfg.Observability.Diagnostics
+	snapshots := diagnostics.NewSnapshotter("api-gateway", diag.SnapshotDir, diag.MaxSnapshots)
+	return &DiagnosticsHandler{
+		enabled:   diag.Enabled,
+		snapshots: snapshots,
+		debug:     diagnostics.Handler(snapshots),
+	}
+}
+
+// Enabled 是否启用诊断路由
+func (h *DiagnosticsHandler) Enabled() bool {
+	return h != nil && h.enabled
+}
+
+// Pprof 代理标准 pprof 端点
+// @Summary 性能剖析（pprof）
+// @Description 代理 net/http/pprof：/pprof/ 为索引，/pprof/profile?seconds=30 采集 CPU，/pprof/heap、/pprof/goroutine?debug=2 等查看各 profile（仅 admin，需开启 observability.diagnostics.enabled）
+// @Tags Ops
+// @Produce octet-stream
+// @Param profile path string true "profile 名称（如 heap、goroutine、profile、trace）"
+// @Success 200 {file} binary
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/diagnostics/pprof/{profile} [get]
+func (h *DiagnosticsHandler) Pprof(c *gin.Context) {
+	h.serveDebug(c, "/debug/pprof/"+strings.TrimLeft(c.Param("profile"), "/"))
+}
+
+// Vars 代理 expvar 端点
+// @Summary 运行时变量（expvar）
+// @Description 返回 expvar 导出的运行时变量（memstats、cmdline 等，仅 admin）
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} map[string]any
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/diagnostics/vars [get]
+func (h *DiagnosticsHandler) Vars(c *gin.Context) {
+	h.serveDebug(c, "/debug/vars")
+}
+
+// CaptureSnapshot 采集 goroutine/heap 快照
+// @Summary 采集诊断快照
+// @Description 将当前进程的 heap（采集前触发 GC）、goroutine 或 allocs profile 写入快照目录（超过 max_snapshots 时删除最旧的），可用 go tool pprof 离线分析（仅 admin）
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param body body dto.CaptureDiagnosticsSnapshotRequest true "快照类型"
+// @Success 201 {object} dto.Response[dto.DiagnosticsSnapshotResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/diagnostics/snapshots [post]
+func (h *DiagnosticsHandler) CaptureSnapshot(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req dto.CaptureDiagnosticsSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	snap, err := h.snapshots.Capture(req.Kind)
+	if err != nil {
+		if stderrors.Is(err, diagnostics.ErrUnknownKind) {
+			dto.BadRequest(c, "unknown snapshot kind")
+			return
+		}
+		logger.Error(ctx, "failed to capture diagnostics snapshot", err, "kind", req.Kind)
+		dto.InternalError(c, "failed to capture diagnostics snapshot")
+		return
+	}
+	logger.Info(ctx, "diagnostics snapshot captured", "name", snap.Name, "size", snap.Size)
+
+	dto.Created(c, dto.ToDiagnosticsSnapshotResponse(snap))
+}
+
+// ListSnapshots 列出诊断快照
+// @Summary 列出诊断快照
+// @Description 按采集时间倒序列出快照目录中的文件（仅 admin）
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[[]dto.DiagnosticsSnapshotResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/diagnostics/snapshots [get]
+func (h *DiagnosticsHandler) ListSnapshots(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	list, err := h.snapshots.List()
+	if err != nil {
+		logger.Error(ctx, "failed to list diagnostics snapshots", err)
+		dto.InternalError(c, "failed to list diagnostics snapshots")
+		return
+	}
+
+	resp := make([]*dto.DiagnosticsSnapshotResponse, 0, len(list))
+	for i := range list {
+		resp = append(resp, dto.ToDiagnosticsSnapshotResponse(&list[i]))
+	}
+	dto.Success(c, resp)
+}
+
+// DownloadSnapshot 下载诊断快照
+// @Summary 下载诊断快照
+// @Description 以附件形式下载快照文件（gzip 压缩的 pprof 格式，仅 admin）
+// @Tags Ops
+// @Produce octet-stream
+// @Param name path string true "快照文件名"
+// @Success 200 {file} binary
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/diagnostics/snapshots/{name} [get]
+func (h *DiagnosticsHandler) DownloadSnapshot(c *gin.Context) {
+	name := c.Param("name")
+	if _, err := h.snapshots.Path(name); err != nil {
+		dto.NotFound(c, "snapshot not found")
+		return
+	}
+	diagnostics.ServeSnapshot(c.Writer, c.Request, h.snapshots, name)
+}
+
+// serveDebug 将请求改写到 diagnostics.Handler 的 /debug/ 路径下处理
+func (h *DiagnosticsHandler) serveDebug(c *gin.Context, path string) {
+	req := c.Request.Clone(c.Request.Context())
+	req.URL.Path = path
+	req.URL.RawPath = ""
+	h.debug.ServeHTTP(c.Writer, req)
+}
@@ -2,8 +2,11 @@
 package handler
 
 import (
+	stderrors "errors"
 	"net/http"
+	"strconv"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
@@ -18,16 +21,19 @@ import (
 type EntityHandler struct {
 	entityRepo   repository.EntityRepository
 	relationRepo repository.RelationRepository
+	weigher      *appstory.RelationWeigher
 }
 
 // NewEntityHandler 创建实体处理器
 func NewEntityHandler(
 	entityRepo repository.EntityRepository,
 	relationRepo repository.RelationRepository,
+	weigher *appstory.RelationWeigher,
 ) *EntityHandler {
 	return &EntityHandler{
 		entityRepo:   entityRepo,
 		relationRepo: relationRepo,
+		weigher:      weigher,
 	}
 }
 
@@ -44,6 +50,7 @@ func NewEntityHandler(
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.EntityListResponse]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/entities [get]
 func (h *EntityHandler) ListEntities(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -89,6 +96,7 @@ func (h *EntityHandler) ListEntities(c *gin.Context) {
 // @Success 201 {object} dto.Response[dto.EntityResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/entities [post]
 func (h *EntityHandler) CreateEntity(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -96,7 +104,7 @@ func (h *EntityHandler) CreateEntity(c *gin.Context) {
 
 	var req dto.CreateEntityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -122,6 +130,7 @@ func (h *EntityHandler) CreateEntity(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.EntityResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/entities/{eid} [get]
 func (h *EntityHandler) GetEntity(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -131,11 +140,7 @@ func (h *EntityHandler) GetEntity(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get entity", err)
@@ -164,6 +169,7 @@ func (h *EntityHandler) GetEntity(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/entities/{eid} [put]
 func (h *EntityHandler) UpdateEntity(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -171,7 +177,7 @@ func (h *EntityHandler) UpdateEntity(c *gin.Context) {
 
 	var req dto.UpdateEntityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -212,6 +218,7 @@ func (h *EntityHandler) UpdateEntity(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/entities/{eid} [delete]
 func (h *EntityHandler) DeleteEntity(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -220,11 +227,7 @@ func (h *EntityHandler) DeleteEntity(c *gin.Context) {
 	if err := h.entityRepo.Delete(ctx, entityID); err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to delete entity", err)
@@ -247,6 +250,7 @@ func (h *EntityHandler) DeleteEntity(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/entities/{eid}/state [put]
 func (h *EntityHandler) UpdateEntityState(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -254,7 +258,7 @@ func (h *EntityHandler) UpdateEntityState(c *gin.Context) {
 
 	var req dto.UpdateEntityStateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -292,27 +296,66 @@ func (h *EntityHandler) UpdateEntityState(c *gin.Context) {
 
 // GetEntityRelations 获取实体关系
 // @Summary 获取实体关系
-// @Description 获取指定实体的所有关系
+// @Description 获取指定实体的所有关系，按参照章节的时效衰减有效强度降序排列
 // @Tags Entities
 // @Accept json
 // @Produce json
 // @Param eid path string true "实体 ID"
+// @Param at_chapter_id query string false "参照章节 ID（默认项目最后一章）"
+// @Param min_effective_strength query number false "最低有效强度（0-1），低于该值的关系不返回"
 // @Success 200 {object} dto.Response[dto.EntityRelationsResponse]
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/entities/{eid}/relations [get]
 func (h *EntityHandler) GetEntityRelations(c *gin.Context) {
 	ctx := c.Request.Context()
 	entityID := dto.BindEntityID(c)
 	_ = middleware.GetTenantIDFromGin(c)
 
+	minStrength := 0.0
+	if s := c.Query("min_effective_strength"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 || v > 1 {
+			dto.BadRequest(c, "min_effective_strength must be between 0 and 1")
+			return
+		}
+		minStrength = v
+	}
+
 	relations, err := h.relationRepo.ListByEntity(ctx, entityID)
 	if err != nil {
 		logger.Error(ctx, "failed to get entity relations", err)
 		dto.InternalError(c, "failed to get entity relations")
 		return
 	}
+	if len(relations) == 0 {
+		dto.Success(c, dto.ToEntityRelationsResponse(entityID, relations))
+		return
+	}
+
+	weighted, err := h.weigher.Weigh(ctx, relations[0].ProjectID, c.Query("at_chapter_id"), relations)
+	if err != nil {
+		if stderrors.Is(err, appstory.ErrChapterNotInProject) {
+			dto.BadRequest(c, "at_chapter_id does not belong to project")
+			return
+		}
+		logger.Error(ctx, "failed to weigh entity relations", err)
+		dto.InternalError(c, "failed to get entity relations")
+		return
+	}
+	appstory.SortByEffectiveStrength(weighted)
+	if minStrength > 0 {
+		kept := weighted[:0]
+		for _, w := range weighted {
+			if w.EffectiveStrength >= minStrength {
+				kept = append(kept, w)
+			}
+		}
+		weighted = kept
+	}
 
-	resp := dto.ToEntityRelationsResponse(entityID, relations)
+	resp := dto.ToWeightedEntityRelationsResponse(entityID, weighted)
 	dto.Success(c, resp)
 }
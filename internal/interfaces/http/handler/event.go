@@ -2,11 +2,15 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
 	"z-novel-ai-api/pkg/errors"
 	"z-novel-ai-api/pkg/logger"
 
@@ -15,38 +19,58 @@ import (
 
 // EventHandler 事件处理器
 type EventHandler struct {
-	eventRepo repository.EventRepository
+	eventRepo   repository.EventRepository
+	chapterRepo repository.ChapterRepository
+	txMgr       repository.Transactor
+	tenantCtx   repository.TenantContextManager
+	replacer    *appstory.ChapterEventReplacer
 }
 
 // NewEventHandler 创建事件处理器
-func NewEventHandler(eventRepo repository.EventRepository) *EventHandler {
+func NewEventHandler(
+	eventRepo repository.EventRepository,
+	chapterRepo repository.ChapterRepository,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	replacer *appstory.ChapterEventReplacer,
+) *EventHandler {
 	return &EventHandler{
-		eventRepo: eventRepo,
+		eventRepo:   eventRepo,
+		chapterRepo: chapterRepo,
+		txMgr:       txMgr,
+		tenantCtx:   tenantCtx,
+		replacer:    replacer,
 	}
 }
 
 // ListEvents 获取项目事件列表
 // @Summary 获取项目事件列表
-// @Description 根据项目 ID 获取事件，支持按类型、重要性及时间范围过滤
+// @Description 根据项目 ID 获取事件，支持按类型、重要性、章节过滤；默认不含已被替代的事件
 // @Tags Events
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
 // @Param type query string false "事件类型 (plot, dialogue, action, description)"
 // @Param importance query string false "重要性 (critical, major, normal, minor)"
+// @Param chapter_id query string false "章节 ID"
+// @Param include_superseded query bool false "是否包含已被替代的事件（审计用）"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.EventListResponse]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/events [get]
 func (h *EventHandler) ListEvents(c *gin.Context) {
 	ctx := c.Request.Context()
 	projectID := dto.BindProjectID(c)
 	pageReq := dto.BindPage(c)
 
+	includeSuperseded, _ := strconv.ParseBool(c.Query("include_superseded"))
 	filter := &repository.EventFilter{
-		EventType:  entity.EventType(c.Query("type")),
-		Importance: entity.EventImportance(c.Query("importance")),
+		EventType:         entity.EventType(c.Query("type")),
+		Importance:        entity.EventImportance(c.Query("importance")),
+		ChapterID:         c.Query("chapter_id"),
+		IncludeSuperseded: includeSuperseded,
 	}
 
 	result, err := h.eventRepo.ListByProject(ctx, projectID, filter, repository.NewPagination(pageReq.Page, pageReq.PageSize))
@@ -72,6 +96,7 @@ func (h *EventHandler) ListEvents(c *gin.Context) {
 // @Success 201 {object} dto.Response[dto.EventResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/events [post]
 func (h *EventHandler) CreateEvent(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -79,7 +104,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 
 	var req dto.CreateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -105,6 +130,7 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.EventResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/events/{evid} [get]
 func (h *EventHandler) GetEvent(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -114,11 +140,7 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get event", err)
@@ -147,6 +169,7 @@ func (h *EventHandler) GetEvent(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/events/{evid} [put]
 func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -154,7 +177,7 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 
 	var req dto.UpdateEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -195,6 +218,7 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/events/{evid} [delete]
 func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -203,11 +227,7 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	if err := h.eventRepo.Delete(ctx, eventID); err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to delete event", err)
@@ -217,3 +237,55 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// ReplaceChapterEvents 替换章节事件
+// @Summary 替换章节事件
+// @Description 章节重新生成后用新抽取的事件替换该章节的旧事件：批次内按摘要去重，旧事件标记为已替代（不删除），与新事件摘要相同的旧事件记录 superseded_by 链接；提交后按新事件参与者重建章节索引的涉及实体
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Param body body dto.ReplaceChapterEventsRequest true "新事件列表"
+// @Success 200 {object} dto.Response[dto.ReplaceChapterEventsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/events [put]
+func (h *EventHandler) ReplaceChapterEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	chapterID := dto.BindChapterID(c)
+
+	var req dto.ReplaceChapterEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	var (
+		chapter *entity.Chapter
+		result  *appstory.ChapterEventReplacement
+	)
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		var err error
+		chapter, err = h.chapterRepo.GetByID(txCtx, chapterID)
+		if err != nil || chapter == nil {
+			return err
+		}
+		result, err = h.replacer.Replace(txCtx, chapter, req.ToEventEntities(chapter.ProjectID))
+		return err
+	}); err != nil {
+		logger.Error(ctx, "failed to replace chapter events", err)
+		dto.InternalError(c, "failed to replace chapter events")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+	// 按新事件的参与者重建索引的 involved_entities（失败已记录日志，不影响已提交的替换）
+	_ = h.replacer.IndexChapter(ctx, tenantID, result.Index)
+
+	dto.Success(c, dto.ToReplaceChapterEventsResponse(chapter.ID, result))
+}
@@ -0,0 +1,169 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	stderrors "errors"
+	"strings"
+
+	"z-novel-ai-api/internal/application/featureflag"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FeatureFlagHandler 功能开关处理器
+type FeatureFlagHandler struct {
+	projectRepo repository.ProjectRepository
+	flags       *featureflag.Service
+}
+
+// NewFeatureFlagHandler 创建功能开关处理器
+func NewFeatureFlagHandler(projectRepo repository.ProjectRepository, flags *featureflag.Service) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		projectRepo: projectRepo,
+		flags:       flags,
+	}
+}
+
+// ListFeatureFlags 获取当前租户的功能开关判定结果
+// @Summary 获取当前租户功能开关
+// @Description 返回全部已定义开关对当前租户（可选指定项目）的判定结果及来源（project_override / tenant_override / rollout / default），以及租户下的全部覆盖
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param project_id query string false "项目 ID（按项目级覆盖判定）"
+// @Success 200 {object} dto.Response[dto.FeatureFlagListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	projectID := strings.TrimSpace(c.Query("project_id"))
+	if projectID != "" {
+		if _, err := uuid.Parse(projectID); err != nil {
+			dto.BadRequest(c, "invalid project_id")
+			return
+		}
+	}
+
+	evals, err := h.flags.EvaluateAll(ctx, tenantID, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to evaluate feature flags", err)
+		dto.InternalError(c, "failed to list feature flags")
+		return
+	}
+	overrides, err := h.flags.ListOverrides(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to list feature flag overrides", err)
+		dto.InternalError(c, "failed to list feature flags")
+		return
+	}
+
+	dto.Success(c, dto.ToFeatureFlagListResponse(projectID, evals, overrides))
+}
+
+// SetFeatureFlagOverride 设置功能开关覆盖
+// @Summary 设置功能开关覆盖
+// @Description 为当前租户（或指定项目）覆盖开关取值，优先级高于全局开关与灰度比例；缓存即时失效
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param key path string true "开关 Key"
+// @Param body body dto.SetFeatureFlagOverrideRequest true "覆盖取值"
+// @Success 200 {object} dto.Response[dto.FeatureFlagOverrideResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/feature-flags/{key} [put]
+func (h *FeatureFlagHandler) SetFeatureFlagOverride(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	key := strings.TrimSpace(c.Param("key"))
+
+	var req dto.SetFeatureFlagOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	if !h.ensureProject(c, req.ProjectID) {
+		return
+	}
+
+	override, err := h.flags.SetOverride(ctx, tenantID, key, req.ProjectID, *req.Enabled)
+	if err != nil {
+		if stderrors.Is(err, featureflag.ErrFlagNotFound) {
+			dto.NotFound(c, "feature flag not found")
+			return
+		}
+		logger.Error(ctx, "failed to set feature flag override", err)
+		dto.InternalError(c, "failed to set feature flag override")
+		return
+	}
+
+	dto.Success(c, dto.ToFeatureFlagOverrideResponse(override))
+}
+
+// DeleteFeatureFlagOverride 删除功能开关覆盖
+// @Summary 删除功能开关覆盖
+// @Description 删除当前租户（或指定项目）的覆盖，恢复按全局开关与灰度比例判定
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param key path string true "开关 Key"
+// @Param project_id query string false "项目 ID（为空表示租户级覆盖）"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/feature-flags/{key} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlagOverride(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	key := strings.TrimSpace(c.Param("key"))
+
+	var projectID *string
+	if v := strings.TrimSpace(c.Query("project_id")); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			dto.BadRequest(c, "invalid project_id")
+			return
+		}
+		projectID = &v
+	}
+
+	if err := h.flags.DeleteOverride(ctx, tenantID, key, projectID); err != nil {
+		logger.Error(ctx, "failed to delete feature flag override", err)
+		dto.InternalError(c, "failed to delete feature flag override")
+		return
+	}
+
+	dto.NoContent(c)
+}
+
+// ensureProject 项目级覆盖需校验项目归属当前租户（RLS 下其他租户的项目不可见）
+func (h *FeatureFlagHandler) ensureProject(c *gin.Context, projectID *string) bool {
+	if projectID == nil || strings.TrimSpace(*projectID) == "" {
+		return true
+	}
+	ctx := c.Request.Context()
+	project, err := h.projectRepo.GetByID(ctx, strings.TrimSpace(*projectID))
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return false
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return false
+	}
+	return true
+}
@@ -12,8 +12,10 @@ import (
 	"time"
 
 	"z-novel-ai-api/internal/application/quota"
+	appstory "z-novel-ai-api/internal/application/story"
 	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
 	storymodel "z-novel-ai-api/internal/application/story/model"
+	"z-novel-ai-api/internal/application/story/timeline"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
@@ -43,6 +45,8 @@ type FoundationHandler struct {
 	quotaChecker *quota.TokenQuotaChecker
 	generator    *storyfoundation.FoundationGenerator
 	applier      *storyfoundation.FoundationApplier
+	planLimits   *storyfoundation.PlanLimits
+	jobTimeline  *appstory.JobTimeline
 }
 
 type applyPlanResolveErrorCode string
@@ -74,6 +78,8 @@ func NewFoundationHandler(
 	quotaChecker *quota.TokenQuotaChecker,
 	generator *storyfoundation.FoundationGenerator,
 	applier *storyfoundation.FoundationApplier,
+	planLimits *storyfoundation.PlanLimits,
+	jobTimeline *appstory.JobTimeline,
 ) *FoundationHandler {
 	return &FoundationHandler{
 		cfg:          cfg,
@@ -86,6 +92,8 @@ func NewFoundationHandler(
 		quotaChecker: quotaChecker,
 		generator:    generator,
 		applier:      applier,
+		planLimits:   planLimits,
+		jobTimeline:  jobTimeline,
 	}
 }
 
@@ -102,6 +110,8 @@ func NewFoundationHandler(
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 504 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/foundation/preview [post]
 func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -110,7 +120,7 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 
 	var req dto.FoundationGenerateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -139,6 +149,10 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 		"max_tokens":  req.MaxTokens,
 	})
 	job.InputParams = inputParams
+	// 超长附件在 Prompt 中会被截断，提前告知用户
+	_, truncated := wfmodel.LimitAttachments(req.ToStoryAttachments())
+	job.AddWarnings(appstory.AttachmentWarnings(truncated)...)
+	job.RecordTruncation(appstory.AttachmentTruncations(req.ToStoryAttachments())...)
 
 	var tenant *entity.Tenant
 	var project *entity.Project
@@ -149,7 +163,7 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 			return loadErr
 		}
 
-		if quotaErr := precheckQuota(txCtx, h.quotaChecker, tenant); quotaErr != nil {
+		if quotaErr := precheckFeatureQuota(txCtx, h.quotaChecker, tenant, entity.PlanFeatureFoundationGenerate); quotaErr != nil {
 			return quotaErr
 		}
 
@@ -158,11 +172,13 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 			return loadErr
 		}
 
-		return h.jobRepo.Create(txCtx, job)
+		if err := h.jobRepo.Create(txCtx, job); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "foundation preview started", nil)
+		return nil
 	}); err != nil {
-		var exceeded quota.TokenBalanceExceededError
-		if errors.As(err, &exceeded) {
-			dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
+		if writeQuotaLimitError(c, err) {
 			return
 		}
 		logger.Error(ctx, "failed to prepare foundation preview", err)
@@ -178,24 +194,35 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 		return
 	}
 
+	// 任务已落库：之后的生成与落库按 story.sync_generation 策略决定是否随客户端断开而中止
+	ctx, cancelGen := detachGeneration(ctx, h.cfg, config.SyncEndpointPreviewFoundation)
+	defer cancelGen()
+
 	start := time.Now()
-	out, genErr := h.generator.Generate(ctx, req.ToStoryInput(project.Title, project.Description, provider, model))
+	genCtx, finishGen := appstory.WithGenerationTimeout(ctx, appstory.StageFoundationGen, h.cfg.Story.GenerationTimeouts.FoundationGen)
+	out, genErr := h.generator.Generate(genCtx, req.ToStoryInput(project.Title, project.Description, provider, model))
+	genErr = finishGen(genErr)
 	durationMs := int(time.Since(start).Milliseconds())
 
 	if genErr != nil {
+		genErr = disconnectError(c, genErr)
 		_ = h.markJobFailed(ctx, tenantID, jobID, genErr, durationMs)
 		logger.Error(ctx, "foundation generation failed", genErr)
+		if appstory.IsGenerationTimeout(genErr) {
+			dto.Error(c, http.StatusGatewayTimeout, "foundation generation timed out")
+			return
+		}
 		dto.InternalError(c, "foundation generation failed")
 		return
 	}
 
-	if err := storyfoundation.ValidateFoundationPlan(out.Plan); err != nil {
+	if err := storyfoundation.ValidateFoundationPlan(out.Plan, h.planLimits.For(tenant)); err != nil {
 		_ = h.markJobFailed(ctx, tenantID, jobID, err, durationMs)
-		h.writePlanValidationError(c, err)
+		writeFoundationPlanError(c, err)
 		return
 	}
 
-	if err := h.markJobCompleted(ctx, tenantID, jobID, out, durationMs); err != nil {
+	if err := h.markJobCompleted(ctx, tenantID, jobID, out, durationMs, clientGone(c)); err != nil {
 		logger.Error(ctx, "failed to persist job result", err)
 		dto.InternalError(c, "failed to persist job result")
 		return
@@ -212,24 +239,47 @@ func (h *FoundationHandler) PreviewFoundation(c *gin.Context) {
 			Temperature:      out.Meta.Temperature,
 			DurationMs:       durationMs,
 			GeneratedAt:      out.Meta.GeneratedAt.Format(time.RFC3339),
+			Truncation:       dto.ToTruncationReportResponse(job.Truncation),
 		},
 	}
 	dto.Success(c, resp)
 }
 
 // StreamFoundation SSE 流式生成设定集 Plan（不落库）
-// @Summary SSE 流式生成设定集 Plan
-// @Description 通过 SSE 事件流输出增量 content，结束时输出 done（包含 plan 与 job_id）
+// @Summary SSE 流式生成设定集 Plan（GET）
+// @Description 兼容 EventSource 的 GET 形式（仅支持 query 参数，不支持附件）。通过 SSE 事件流输出增量 content 与 progress，结束时输出 done（包含 plan 与 job_id）；每个事件的 data 为 dto.StreamEvent 信封
 // @Tags Foundation
-// @Accept json
 // @Produce text/event-stream
 // @Param pid path string true "项目 ID"
-// @Success 200 "SSE stream"
+// @Param prompt query string true "生成提示"
+// @Param provider query string false "LLM 提供商"
+// @Param model query string false "模型"
+// @Success 200 {object} dto.StreamEvent "SSE stream"
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/foundation/stream [get]
+// StreamFoundationPost SSE 流式生成设定集 Plan（POST，请求体与异步生成一致）
+// @Summary SSE 流式生成设定集 Plan（POST）
+// @Description 与 GET 形式输出相同的 SSE 事件流，支持附件与完整生成参数；每个事件的 data 为 dto.StreamEvent 信封
+// @Tags Foundation
+// @Accept json
+// @Produce text/event-stream
+// @Param pid path string true "项目 ID"
+// @Param body body dto.FoundationGenerateRequest true "生成请求"
+// @Success 200 {object} dto.StreamEvent "SSE stream"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/foundation/stream [post]
+func (h *FoundationHandler) StreamFoundationPost(c *gin.Context) {
+	h.StreamFoundation(c)
+}
+
 func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 	ctx := c.Request.Context()
 	tenantID := middleware.GetTenantIDFromGin(c)
@@ -237,6 +287,9 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 
 	req, err := h.bindStreamRequest(c)
 	if err != nil {
+		if dto.BodyTooLarge(c, err) {
+			return
+		}
 		dto.BadRequest(c, err.Error())
 		return
 	}
@@ -262,7 +315,7 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 		return
 	}
 
-	if err := precheckQuota(ctx, h.quotaChecker, tenant); err != nil {
+	if err := precheckFeatureQuota(ctx, h.quotaChecker, tenant, entity.PlanFeatureFoundationGenerate); err != nil {
 		h.writeQuotaError(c, err)
 		return
 	}
@@ -300,33 +353,45 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 		"max_tokens":  req.MaxTokens,
 	})
 	job.InputParams = inputParams
+	// 超长附件在 Prompt 中会被截断，提前告知用户
+	_, truncated := wfmodel.LimitAttachments(req.ToStoryAttachments())
+	job.AddWarnings(appstory.AttachmentWarnings(truncated)...)
+	job.RecordTruncation(appstory.AttachmentTruncations(req.ToStoryAttachments())...)
 
 	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
-		return h.jobRepo.Create(txCtx, job)
+		if err := h.jobRepo.Create(txCtx, job); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "foundation stream started", nil)
+		return nil
 	}); err != nil {
 		logger.Error(ctx, "failed to create generation job", err)
 		dto.InternalError(c, "failed to create job")
 		return
 	}
 
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no")
+	planLimits := h.planLimits.For(tenant)
+	sse := dto.NewStreamWriter(c)
 
 	contentCh := make(chan string, 16)
+	noticeCh := make(chan dto.StreamNotice, 4)
 	doneCh := make(chan *storyfoundation.FoundationGenerateOutput, 1)
-	errCh := make(chan error, 1)
+	errCh := make(chan dto.StreamErrorData, 1)
 
 	go func() {
 		defer close(contentCh)
+		defer close(noticeCh)
 		defer close(doneCh)
 		defer close(errCh)
 
 		start := time.Now()
-		reader, streamErr := h.generator.Stream(ctx, req.ToStoryInput(project.Title, project.Description, provider, model))
+		noticeCh <- dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageGenerating, Progress: 5}}
+		genCtx, finishGen := appstory.WithGenerationTimeout(ctx, appstory.StageFoundationGen, h.cfg.Story.GenerationTimeouts.FoundationGen)
+		defer finishGen(nil)
+		reader, streamErr := h.generator.Stream(genCtx, req.ToStoryInput(project.Title, project.Description, provider, model))
 		if streamErr != nil {
-			errCh <- streamErr
+			streamErr = finishGen(streamErr)
+			errCh <- streamGenerationError(streamErr)
 			_ = h.markJobFailed(ctx, tenantID, jobID, streamErr, int(time.Since(start).Milliseconds()))
 			return
 		}
@@ -341,7 +406,8 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 				break
 			}
 			if recvErr != nil {
-				errCh <- recvErr
+				recvErr = finishGen(recvErr)
+				errCh <- streamGenerationError(recvErr)
 				_ = h.markJobFailed(ctx, tenantID, jobID, recvErr, int(time.Since(start).Milliseconds()))
 				return
 			}
@@ -367,15 +433,16 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 			}
 		}
 
+		noticeCh <- dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageValidating, Progress: 90}}
 		plan, jsonText, parseErr := storyfoundation.ParseFoundationPlan(raw.String())
 		if parseErr != nil {
-			errCh <- parseErr
+			errCh <- dto.StreamErrorData{Code: dto.StreamCodeInvalidOutput, Message: parseErr.Error()}
 			_ = h.markJobFailed(ctx, tenantID, jobID, parseErr, int(time.Since(start).Milliseconds()))
 			return
 		}
 
-		if err := storyfoundation.ValidateFoundationPlan(plan); err != nil {
-			errCh <- err
+		if err := storyfoundation.ValidateFoundationPlan(plan, planLimits); err != nil {
+			errCh <- dto.StreamErrorData{Code: dto.StreamCodeInvalidOutput, Message: err.Error()}
 			_ = h.markJobFailed(ctx, tenantID, jobID, err, int(time.Since(start).Milliseconds()))
 			return
 		}
@@ -396,38 +463,45 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 			out.Meta = *usage
 		}
 
-		if err := h.markJobCompleted(ctx, tenantID, jobID, out, int(time.Since(start).Milliseconds())); err != nil {
-			errCh <- err
+		noticeCh <- dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageSaving, Progress: 95}}
+		if err := h.markJobCompleted(ctx, tenantID, jobID, out, int(time.Since(start).Milliseconds()), false); err != nil {
+			errCh <- dto.StreamErrorData{Code: dto.StreamCodePersistFailed, Message: err.Error()}
 			return
 		}
 
 		doneCh <- out
 	}()
 
-	index := 0
 	c.Stream(func(w io.Writer) bool {
 		select {
 		case chunk, ok := <-contentCh:
 			if !ok {
 				return false
 			}
-			c.SSEvent("content", gin.H{"chunk": chunk, "index": index})
-			index++
+			sse.Content(chunk)
+			return true
+
+		case notice, ok := <-noticeCh:
+			if !ok {
+				noticeCh = nil
+				return true
+			}
+			sse.Notice(notice)
 			return true
 
 		case out, ok := <-doneCh:
 			if !ok {
 				return false
 			}
-			c.SSEvent("done", gin.H{
-				"job_id": jobID,
-				"plan":   out.Plan,
+			sse.Done(dto.StreamDoneData{
+				JobID: jobID,
+				Plan:  out.Plan,
 			})
 			return false
 
 		case streamErr, ok := <-errCh:
-			if ok && streamErr != nil {
-				c.SSEvent("error", gin.H{"message": streamErr.Error()})
+			if ok {
+				sse.Error(streamErr.Code, streamErr.Message)
 			}
 			return false
 
@@ -451,6 +525,8 @@ func (h *FoundationHandler) StreamFoundation(c *gin.Context) {
 // @Failure 409 {object} dto.ErrorResponse
 // @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/foundation/generate [post]
 func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -459,7 +535,7 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 
 	var req dto.FoundationGenerateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -480,7 +556,7 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 		return
 	}
 
-	if err := precheckQuota(ctx, h.quotaChecker, tenant); err != nil {
+	if err := precheckFeatureQuota(ctx, h.quotaChecker, tenant, entity.PlanFeatureFoundationGenerate); err != nil {
 		h.writeQuotaError(c, err)
 		return
 	}
@@ -518,6 +594,11 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 		}
 	}
 
+	estimate, ok := admitQueuedJob(c, h.cfg, h.producer, entity.DefaultPriority(entity.JobTypeFoundationGen, entity.JobTriggerStandard))
+	if !ok {
+		return
+	}
+
 	jobID := uuid.NewString()
 	inputParams, _ := json.Marshal(map[string]any{
 		"mode":        "async",
@@ -550,6 +631,12 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 		dto.InternalError(c, "failed to create job")
 		return
 	}
+	// 入队前原子预留配额（与任务创建同一请求事务，余额不足时整体回滚）
+	if err := h.quotaChecker.Reserve(ctx, tenantID, jobID, quota.FoundationReserveTokens(req.MaxTokens)); err != nil {
+		logger.Warn(ctx, "failed to reserve token quota", "error", err.Error())
+		h.writeQuotaError(c, err)
+		return
+	}
 
 	msg := &messaging.GenerationJobMessage{
 		JobID:          jobID,
@@ -558,14 +645,7 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 		JobType:        string(entity.JobTypeFoundationGen),
 		Priority:       job.Priority,
 		IdempotencyKey: job.IdempotencyKey,
-		Params: map[string]interface{}{
-			"prompt":      req.Prompt,
-			"attachments": req.Attachments,
-			"provider":    provider,
-			"model":       model,
-			"temperature": req.Temperature,
-			"max_tokens":  req.MaxTokens,
-		},
+		Params:         messaging.EncodeJobParams(foundationJobParams(&req, provider, model)),
 	}
 
 	if _, err := h.producer.PublishFoundationJob(ctx, msg); err != nil {
@@ -573,8 +653,9 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 		dto.InternalError(c, "failed to enqueue job")
 		return
 	}
+	h.jobTimeline.Record(ctx, job, entity.JobEventQueued, "foundation generation queued", nil)
 
-	dto.Accepted(c, dto.ToJobResponse(job))
+	dto.Accepted(c, queuedJobResponse(job, estimate))
 }
 
 // ApplyFoundation 应用 Plan 落库（单事务，幂等）
@@ -591,6 +672,7 @@ func (h *FoundationHandler) GenerateFoundation(c *gin.Context) {
 // @Failure 409 {object} dto.ErrorResponse
 // @Failure 422 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/foundation/apply [post]
 func (h *FoundationHandler) ApplyFoundation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -598,7 +680,7 @@ func (h *FoundationHandler) ApplyFoundation(c *gin.Context) {
 
 	var req dto.FoundationApplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -608,8 +690,8 @@ func (h *FoundationHandler) ApplyFoundation(c *gin.Context) {
 		return
 	}
 
-	if err := storyfoundation.ValidateFoundationPlan(plan); err != nil {
-		h.writePlanValidationError(c, err)
+	if err := storyfoundation.ValidateFoundationPlan(plan, h.planLimits.ForTenant(ctx, middleware.GetTenantIDFromGin(c))); err != nil {
+		writeFoundationPlanError(c, err)
 		return
 	}
 
@@ -620,6 +702,14 @@ func (h *FoundationHandler) ApplyFoundation(c *gin.Context) {
 
 	result, err := h.applier.Apply(ctx, projectID, plan)
 	if err != nil {
+		var regErr timeline.RegressionError
+		if errors.As(err, &regErr) {
+			dto.UnprocessableEntity(c, "story time regression", &dto.ErrorDetail{
+				ErrorCode: "story_time_regression",
+				Details:   regErr.Error(),
+			})
+			return
+		}
 		logger.Error(ctx, "failed to apply foundation plan", err)
 		dto.InternalError(c, "failed to apply foundation plan")
 		return
@@ -632,15 +722,23 @@ func (h *FoundationHandler) ApplyFoundation(c *gin.Context) {
 }
 
 func (h *FoundationHandler) writeQuotaError(c *gin.Context, err error) {
-	var exceeded quota.TokenBalanceExceededError
-	if errors.As(err, &exceeded) {
-		dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
+	if writeQuotaLimitError(c, err) {
 		return
 	}
 	dto.InternalError(c, "quota check failed")
 }
 
-func (h *FoundationHandler) writePlanValidationError(c *gin.Context, err error) {
+// writeFoundationPlanError 设定集校验失败统一返回 422：规模超限为 foundation_plan_too_large，其余为 foundation_plan_invalid
+func writeFoundationPlanError(c *gin.Context, err error) {
+	var tooLarge storyfoundation.FoundationPlanTooLargeError
+	if errors.As(err, &tooLarge) {
+		dto.UnprocessableEntity(c, "foundation plan too large", &dto.ErrorDetail{
+			ErrorCode:   "foundation_plan_too_large",
+			Details:     strings.Join(tooLarge.Issues, "; "),
+			Suggestions: []string{"reduce the number of volumes, chapters or entities in the plan", "ask an administrator to raise the foundation plan limits for this tenant"},
+		})
+		return
+	}
 	var ve storyfoundation.FoundationPlanValidationError
 	if errors.As(err, &ve) {
 		dto.UnprocessableEntity(c, "invalid foundation plan", &dto.ErrorDetail{
@@ -678,22 +776,51 @@ func (h *FoundationHandler) bindStreamRequest(c *gin.Context) (*dto.FoundationGe
 	return req, nil
 }
 
+// foundationJobParams 组装 foundation_gen 异步任务参数（结构由 Worker 按 messaging.FoundationGenParams 校验）
+func foundationJobParams(req *dto.FoundationGenerateRequest, provider, model string) messaging.FoundationGenParams {
+	params := messaging.FoundationGenParams{
+		Prompt:    req.Prompt,
+		Provider:  provider,
+		Model:     model,
+		MaxTokens: req.MaxTokens,
+	}
+	for _, a := range req.Attachments {
+		params.Attachments = append(params.Attachments, messaging.FoundationAttachment{Name: a.Name, Content: a.Content})
+	}
+	if req.Temperature != nil {
+		t := float64(*req.Temperature)
+		params.Temperature = &t
+	}
+	return params
+}
+
+// markJobFailed 标记任务失败；请求已取消时仍需落库，因此不随请求上下文取消
 func (h *FoundationHandler) markJobFailed(ctx context.Context, tenantID, jobID string, err error, durationMs int) error {
-	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+	return withTenantTx(context.WithoutCancel(ctx), h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		job, getErr := h.jobRepo.GetByID(txCtx, jobID)
 		if getErr != nil || job == nil {
 			return getErr
 		}
 		job.Status = entity.JobStatusFailed
 		job.ErrorMessage = err.Error()
+		job.ErrorCode = appstory.JobErrorCode(err)
 		now := time.Now()
 		job.CompletedAt = &now
 		job.DurationMs = durationMs
-		return h.jobRepo.Update(txCtx, job)
+		if updateErr := h.jobRepo.Update(txCtx, job); updateErr != nil {
+			return updateErr
+		}
+		var ve storyfoundation.FoundationPlanValidationError
+		if errors.As(err, &ve) {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventValidateFailed, "foundation plan validation failed", map[string]any{"issues": ve.Issues})
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventFailed, err.Error(), nil)
+		return nil
 	})
 }
 
-func (h *FoundationHandler) markJobCompleted(ctx context.Context, tenantID, jobID string, out *storyfoundation.FoundationGenerateOutput, durationMs int) error {
+// markJobCompleted 落库生成结果；detached 表示客户端已断开、结果在后台完成
+func (h *FoundationHandler) markJobCompleted(ctx context.Context, tenantID, jobID string, out *storyfoundation.FoundationGenerateOutput, durationMs int, detached bool) error {
 	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		job, err := h.jobRepo.GetByID(txCtx, jobID)
 		if err != nil || job == nil {
@@ -706,7 +833,17 @@ func (h *FoundationHandler) markJobCompleted(ctx context.Context, tenantID, jobI
 		job.CompletedAt = &now
 		job.DurationMs = durationMs
 		job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
-		return h.jobRepo.Update(txCtx, job)
+		if err := h.jobRepo.Update(txCtx, job); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "foundation plan generated", map[string]any{
+			"prompt_tokens":     out.Meta.PromptTokens,
+			"completion_tokens": out.Meta.CompletionTokens,
+		})
+		if detached {
+			h.jobTimeline.Record(txCtx, job, entity.JobEventDetached, "client disconnected; result persisted in background", nil)
+		}
+		return nil
 	})
 }
 
@@ -2,24 +2,65 @@
 package handler
 
 import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/maintenance"
+	"z-novel-ai-api/internal/application/quota"
+	appstory "z-novel-ai-api/internal/application/story"
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/messaging"
 	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
 	"z-novel-ai-api/pkg/errors"
 	"z-novel-ai-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // JobHandler 任务处理器
 type JobHandler struct {
-	jobRepo repository.JobRepository
+	cfg          *config.Config
+	jobRepo      repository.JobRepository
+	jobEventRepo repository.JobEventRepository
+	projectRepo  repository.ProjectRepository
+	producer     *messaging.Producer
+	quotaChecker *quota.TokenQuotaChecker
+	jobTimeline  *appstory.JobTimeline
+	generator    *storychapter.ChapterGenerator
+	cancelSignal appstory.JobCancelSignal
 }
 
 // NewJobHandler 创建任务处理器
-func NewJobHandler(jobRepo repository.JobRepository) *JobHandler {
+func NewJobHandler(
+	cfg *config.Config,
+	jobRepo repository.JobRepository,
+	jobEventRepo repository.JobEventRepository,
+	projectRepo repository.ProjectRepository,
+	producer *messaging.Producer,
+	quotaChecker *quota.TokenQuotaChecker,
+	jobTimeline *appstory.JobTimeline,
+	generator *storychapter.ChapterGenerator,
+	cancelSignal appstory.JobCancelSignal,
+) *JobHandler {
 	return &JobHandler{
-		jobRepo: jobRepo,
+		cfg:          cfg,
+		jobRepo:      jobRepo,
+		jobEventRepo: jobEventRepo,
+		projectRepo:  projectRepo,
+		producer:     producer,
+		quotaChecker: quotaChecker,
+		jobTimeline:  jobTimeline,
+		generator:    generator,
+		cancelSignal: cancelSignal,
 	}
 }
 
@@ -33,6 +74,7 @@ func NewJobHandler(jobRepo repository.JobRepository) *JobHandler {
 // @Success 200 {object} dto.Response[dto.JobResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/jobs/{jid} [get]
 func (h *JobHandler) GetJob(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -42,11 +84,7 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get job", err)
@@ -63,9 +101,102 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 	dto.Success(c, resp)
 }
 
+// BatchJobStatus 批量查询任务状态
+// @Summary 批量查询任务状态
+// @Description 一次查询最多 100 个任务的状态与进度（轻量记录，不含输入参数与结果），供仪表盘轮询；不存在或无权访问的 ID 列入 not_found
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param body body dto.BatchJobStatusRequest true "任务 ID 列表"
+// @Success 200 {object} dto.Response[dto.BatchJobStatusResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/batch-status [post]
+func (h *JobHandler) BatchJobStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	var req dto.BatchJobStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	ids := make([]string, 0, len(req.JobIDs))
+	seen := make(map[string]bool, len(req.JobIDs))
+	for _, id := range req.JobIDs {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	jobs, err := h.jobRepo.ListStatusByIDs(ctx, ids)
+	if err != nil {
+		logger.Error(ctx, "failed to list job statuses", err)
+		dto.InternalError(c, "failed to get job statuses")
+		return
+	}
+
+	byID := make(map[string]*entity.GenerationJob, len(jobs))
+	for _, j := range jobs {
+		if j.TenantID == tenantID {
+			byID[strings.ToLower(j.ID)] = j
+		}
+	}
+	resp := &dto.BatchJobStatusResponse{Jobs: make([]*dto.JobStatusResponse, 0, len(ids))}
+	for _, id := range ids {
+		if j, ok := byID[id]; ok {
+			resp.Jobs = append(resp.Jobs, dto.ToJobStatusResponse(j))
+		} else {
+			resp.NotFound = append(resp.NotFound, id)
+		}
+	}
+	dto.Success(c, resp)
+}
+
+// ListJobEvents 获取任务时间线
+// @Summary 获取任务时间线
+// @Description 按时间顺序返回任务的关键事件（排队、领取、召回、调用模型、校验、修复、完成等）
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Success 200 {object} dto.Response[dto.JobEventListResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/events [get]
+func (h *JobHandler) ListJobEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobID := dto.BindJobID(c)
+
+	job, err := h.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to get job", err)
+		dto.InternalError(c, "failed to get job")
+		return
+	}
+	if job == nil {
+		dto.NotFound(c, "job not found")
+		return
+	}
+
+	events, err := h.jobEventRepo.ListByJob(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to list job events", err)
+		dto.InternalError(c, "failed to list job events")
+		return
+	}
+
+	dto.Success(c, dto.ToJobEventListResponse(job, events))
+}
+
 // CancelJob 取消任务
 // @Summary 取消任务
-// @Description 取消指定的任务
+// @Description 取消指定的任务；任务正在执行时同时发出取消信号，执行方中止进行中的 LLM 调用（同 POST /v1/jobs/{jid}/cancel）
 // @Tags Jobs
 // @Accept json
 // @Produce json
@@ -74,8 +205,31 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 409 {object} dto.ErrorResponse "任务无法取消"
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/jobs/{jid} [delete]
 func (h *JobHandler) CancelJob(c *gin.Context) {
+	h.cancelJob(c)
+}
+
+// InterruptJob 取消并中止运行中的任务
+// @Summary 取消并中止运行中的任务
+// @Description 取消指定的任务。任务正在生成时通过取消信号通知 Worker（或同步生成接口），在图节点之间与工具轮次前后检查并中止 LLM 调用，不再继续消耗 Token；已生成的结果不落库，任务记为 cancelled 并释放配额预留。已取消的任务重复调用返回成功
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Success 200 {object} dto.Response[dto.CancelJobResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "任务已结束"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/cancel [post]
+func (h *JobHandler) InterruptJob(c *gin.Context) {
+	h.cancelJob(c)
+}
+
+// cancelJob 取消任务：先发出取消信号再更新状态（Worker 生成期间可能持有任务行锁，中止后才释放）
+func (h *JobHandler) cancelJob(c *gin.Context) {
 	ctx := c.Request.Context()
 	jobID := dto.BindJobID(c)
 
@@ -93,7 +247,7 @@ func (h *JobHandler) CancelJob(c *gin.Context) {
 	}
 
 	// 检查任务状态
-	if job.Status == entity.JobStatusCompleted || job.Status == entity.JobStatusFailed {
+	if job.Status == entity.JobStatusCompleted || job.Status == entity.JobStatusFailed || job.Status == entity.JobStatusCancelledPartial {
 		dto.Conflict(c, "job already finished")
 		return
 	}
@@ -106,6 +260,13 @@ func (h *JobHandler) CancelJob(c *gin.Context) {
 		return
 	}
 
+	// 排队中的任务也发出信号：读取状态后可能刚被 Worker 领取
+	if h.cancelSignal != nil {
+		if err := h.cancelSignal.Request(ctx, job.ID); err != nil {
+			logger.Warn(ctx, "failed to signal job cancellation", "error", err.Error(), "job_id", job.ID)
+		}
+	}
+
 	// 取消任务
 	job.Status = entity.JobStatusCancelled
 	if err := h.jobRepo.Update(ctx, job); err != nil {
@@ -113,6 +274,14 @@ func (h *JobHandler) CancelJob(c *gin.Context) {
 		dto.InternalError(c, "failed to cancel job")
 		return
 	}
+	if err := h.quotaChecker.Release(ctx, job.ID); err != nil {
+		logger.Error(ctx, "failed to release token quota", err)
+		dto.InternalError(c, "failed to cancel job")
+		return
+	}
+	if err := h.jobEventRepo.Create(ctx, entity.NewJobEvent(job, entity.JobEventCancelled, "cancelled by user", nil)); err != nil {
+		logger.Warn(ctx, "failed to record job event", "error", err.Error(), "job_id", job.ID)
+	}
 
 	dto.Success(c, &dto.CancelJobResponse{
 		ID:        jobID,
@@ -120,21 +289,123 @@ func (h *JobHandler) CancelJob(c *gin.Context) {
 	})
 }
 
-// ListProjectJobs 获取项目任务列表（内部方法，可选暴露为 API）
+// UpdateJobPriority 调整任务优先级
+// @Summary 调整任务优先级（管理员）
+// @Description 调整排队中任务的优先级；跨档位（high / normal / low）时将任务消息移入对应优先级流，使其被 Worker 提前或延后领取。任务已被领取时返回 409
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Param body body dto.UpdateJobPriorityRequest true "优先级（1-10）"
+// @Success 200 {object} dto.Response[dto.UpdateJobPriorityResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "任务不在排队中"
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/priority [put]
+func (h *JobHandler) UpdateJobPriority(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobID := dto.BindJobID(c)
+
+	var req dto.UpdateJobPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	job, err := h.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to get job", err)
+		dto.InternalError(c, "failed to get job")
+		return
+	}
+	if job == nil {
+		dto.NotFound(c, "job not found")
+		return
+	}
+	if job.Status != entity.JobStatusPending {
+		dto.Conflict(c, "only pending jobs can be reprioritized")
+		return
+	}
+
+	// 先移动队列消息（消息可能已被 Worker 领取），成功后再更新记录
+	from, to := messaging.StoryGenStream(job.Priority), messaging.StoryGenStream(req.Priority)
+	requeued := false
+	if from != to {
+		if err := h.producer.Reprioritize(ctx, job.ID, req.Priority, from, to); err != nil {
+			if stderrors.Is(err, messaging.ErrMessageNotQueued) {
+				dto.Conflict(c, "job is no longer waiting in queue")
+				return
+			}
+			logger.Error(ctx, "failed to requeue job", err)
+			dto.InternalError(c, "failed to update job priority")
+			return
+		}
+		requeued = true
+	}
+
+	oldPriority := job.Priority
+	job.Priority = req.Priority
+	if err := h.jobRepo.Update(ctx, job); err != nil {
+		logger.Error(ctx, "failed to update job priority", err)
+		dto.InternalError(c, "failed to update job priority")
+		return
+	}
+	h.jobTimeline.Record(ctx, job, entity.JobEventReprioritized, "priority changed by admin", map[string]any{
+		"from":     oldPriority,
+		"to":       req.Priority,
+		"requeued": requeued,
+	})
+
+	dto.Success(c, &dto.UpdateJobPriorityResponse{JobResponse: dto.ToJobResponse(job), Requeued: requeued})
+}
+
+// ListProjectJobs 获取项目任务列表
+// @Summary 获取项目任务列表
+// @Description 分页获取项目下的全部任务（生成任务与导出/重建索引等运维任务），可按类别、类型、状态过滤
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param category query string false "任务类别：generation / maintenance"
+// @Param job_type query string false "任务类型"
+// @Param status query string false "任务状态"
+// @Param priority query string false "优先级档位：high / normal / low"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Success 200 {object} dto.Response[dto.JobListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/jobs [get]
 func (h *JobHandler) ListProjectJobs(c *gin.Context) {
 	ctx := c.Request.Context()
 	projectID := dto.BindProjectID(c)
 	pageReq := dto.BindPage(c)
 
-	// 获取状态过滤
-	status := c.Query("status")
-
 	// 构建过滤条件
-	var filter *repository.JobFilter
-	if status != "" {
-		filter = &repository.JobFilter{
-			Status: entity.JobStatus(status),
-		}
+	filter := &repository.JobFilter{
+		JobType:  entity.JobType(strings.TrimSpace(c.Query("job_type"))),
+		Category: entity.JobCategory(strings.TrimSpace(c.Query("category"))),
+		Status:   entity.JobStatus(strings.TrimSpace(c.Query("status"))),
+	}
+	if filter.Category != "" && !filter.Category.IsValid() {
+		dto.BadRequest(c, "invalid category")
+		return
+	}
+	switch strings.TrimSpace(c.Query("priority")) {
+	case "":
+	case "high":
+		filter.MinPriority = entity.JobPriorityHigh
+	case "normal":
+		filter.MinPriority, filter.MaxPriority = entity.JobPriorityLow+1, entity.JobPriorityHigh-1
+	case "low":
+		filter.MaxPriority = entity.JobPriorityLow
+	default:
+		dto.BadRequest(c, "invalid priority")
+		return
 	}
 
 	result, err := h.jobRepo.ListByProject(ctx, projectID, filter, repository.NewPagination(pageReq.Page, pageReq.PageSize))
@@ -148,3 +419,256 @@ func (h *JobHandler) ListProjectJobs(c *gin.Context) {
 	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
 	dto.SuccessWithPage(c, resp, meta)
 }
+
+// CreateProjectJob 提交运维任务
+// @Summary 提交运维任务
+// @Description 异步执行项目级运维任务：project_export（导出成稿，结果见任务 result.content）、index_rebuild（重建向量索引）、vector_purge（清空向量）、artifact_gc（按保留策略清理构件历史版本，默认 dry-run 只出报告）、outline_refresh（将大纲过期章节的大纲文本同步为激活大纲，无正文的章节同时清除过期标记；可选 chapter_ids）、project_recount（重新统计项目字数/卷汇总/实体出场/向量用量）。支持 Idempotency-Key 去重
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param Idempotency-Key header string false "幂等键"
+// @Param body body dto.CreateJobRequest true "任务请求"
+// @Success 202 {object} dto.Response[dto.JobResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/jobs [post]
+func (h *JobHandler) CreateProjectJob(c *gin.Context) {
+	projectID := dto.BindProjectID(c)
+
+	var req dto.CreateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	jobType := entity.JobType(strings.TrimSpace(req.JobType))
+	params, err := maintenance.NormalizeParams(jobType, req.Params)
+	if err != nil {
+		if stderrors.Is(err, maintenance.ErrInvalidParams) {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		dto.InternalError(c, "failed to create job")
+		return
+	}
+	h.submitMaintenanceJob(c, projectID, jobType, params)
+}
+
+// RecountProject 重新统计项目数据
+// @Summary 重新统计项目数据
+// @Description 提交 project_recount 运维任务：按源数据重算章节字数与文本统计、卷汇总、项目字数、实体出场统计及向量用量计数。可重复执行，任务结果 result 含重算前后的统计（before/after）与各类修正数（fixed）。支持 Idempotency-Key 去重（仅 admin）
+// @Tags Jobs
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param Idempotency-Key header string false "幂等键"
+// @Success 202 {object} dto.Response[dto.JobResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/admin/projects/{pid}/recount [post]
+func (h *JobHandler) RecountProject(c *gin.Context) {
+	params, err := maintenance.NormalizeParams(entity.JobTypeProjectRecount, nil)
+	if err != nil {
+		dto.InternalError(c, "failed to create job")
+		return
+	}
+	h.submitMaintenanceJob(c, dto.BindProjectID(c), entity.JobTypeProjectRecount, params)
+}
+
+// submitMaintenanceJob 校验项目与幂等键后创建运维任务并投递到队列
+func (h *JobHandler) submitMaintenanceJob(c *gin.Context, projectID string, jobType entity.JobType, params map[string]any) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idempotencyKey) > 128 {
+		dto.BadRequest(c, "Idempotency-Key too long")
+		return
+	}
+	if idempotencyKey != "" {
+		existing, err := h.jobRepo.GetByIdempotencyKey(ctx, idempotencyKey)
+		if err != nil {
+			logger.Error(ctx, "failed to check idempotency key", err)
+			dto.InternalError(c, "failed to create job")
+			return
+		}
+		if existing != nil {
+			if existing.ProjectID != projectID || existing.JobType != jobType {
+				dto.Conflict(c, "idempotency key already used")
+				return
+			}
+			dto.Accepted(c, dto.ToJobResponse(existing))
+			return
+		}
+	}
+
+	inputParams, _ := json.Marshal(params)
+	job := entity.NewGenerationJob(tenantID, projectID, jobType, inputParams)
+	job.ID = uuid.NewString()
+	if idempotencyKey != "" {
+		job.IdempotencyKey = &idempotencyKey
+	}
+
+	if err := h.jobRepo.Create(ctx, job); err != nil {
+		if idempotencyKey != "" {
+			existing, getErr := h.jobRepo.GetByIdempotencyKey(ctx, idempotencyKey)
+			if getErr == nil && existing != nil {
+				dto.Accepted(c, dto.ToJobResponse(existing))
+				return
+			}
+		}
+		logger.Error(ctx, "failed to create maintenance job", err)
+		dto.InternalError(c, "failed to create job")
+		return
+	}
+
+	msg := &messaging.GenerationJobMessage{
+		JobID:          job.ID,
+		TenantID:       tenantID,
+		ProjectID:      projectID,
+		JobType:        string(jobType),
+		Priority:       job.Priority,
+		IdempotencyKey: job.IdempotencyKey,
+		Params:         params,
+	}
+	if _, err := h.producer.PublishMaintenanceJob(ctx, msg); err != nil {
+		logger.Error(ctx, "failed to publish maintenance job", err)
+		dto.InternalError(c, "failed to enqueue job")
+		return
+	}
+	h.jobTimeline.Record(ctx, job, entity.JobEventQueued, string(jobType)+" queued", nil)
+
+	dto.Accepted(c, dto.ToJobResponse(job))
+}
+
+// ReplayJob 回放生成任务（沙箱）
+// @Summary 回放生成任务
+// @Description 按任务执行时记录的输入快照（模板版本、召回上下文、参数）重新调用模型，可覆盖任意输入或模板文本；结果仅返回，不写入章节/构件，也不计入任务与配额。仅支持已记录快照的章节生成任务；dry_run 时仅渲染 Prompt。同步执行，受服务端写超时限制
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param jid path string true "任务 ID"
+// @Param body body dto.ReplayJobRequest false "覆盖项"
+// @Success 200 {object} dto.Response[dto.JobReplayResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "任务未记录输入快照"
+// @Failure 502 {object} dto.ErrorResponse "模型调用失败"
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/replay [post]
+func (h *JobHandler) ReplayJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	jobID := dto.BindJobID(c)
+
+	var req dto.ReplayJobRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+
+	job, err := h.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to get job", err)
+		dto.InternalError(c, "failed to get job")
+		return
+	}
+	if job == nil {
+		dto.NotFound(c, "job not found")
+		return
+	}
+	if job.JobType != entity.JobTypeChapterGen {
+		dto.BadRequest(c, "only chapter_gen jobs can be replayed")
+		return
+	}
+	snapshot, err := storychapter.ParseInputSnapshot(job.InputSnapshot)
+	if err != nil {
+		logger.Error(ctx, "failed to parse job input snapshot", err)
+		dto.InternalError(c, "failed to load job input snapshot")
+		return
+	}
+	if snapshot == nil {
+		dto.Conflict(c, "job has no recorded input snapshot")
+		return
+	}
+
+	resp := &dto.JobReplayResponse{
+		JobID:                job.ID,
+		RecordedPromptDigest: snapshot.PromptDigest,
+		DryRun:               req.DryRun,
+	}
+	if digest, err := workflowprompt.TemplateDigest(workflowprompt.PromptID(snapshot.PromptID)); err == nil {
+		resp.CurrentPromptDigest = digest
+	}
+
+	resp.Overridden = req.ApplyTo(snapshot)
+	provider, model, err := resolveProviderModel(h.cfg, snapshot.Provider, snapshot.Model)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	snapshot.Provider, snapshot.Model = provider, model
+
+	in := snapshot.Input()
+	effective, err := storychapter.NewInputSnapshot(in)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	resp.Input = effective
+	resp.PromptDigest = effective.PromptDigest
+
+	msgs, err := h.generator.RenderPrompt(ctx, in)
+	if err != nil {
+		dto.BadRequest(c, "failed to render prompt: "+err.Error())
+		return
+	}
+	resp.Messages = make([]*dto.ReplayMessage, 0, len(msgs))
+	for _, m := range msgs {
+		if m != nil {
+			resp.Messages = append(resp.Messages, &dto.ReplayMessage{Role: string(m.Role), Content: m.Content})
+		}
+	}
+	if req.DryRun {
+		dto.Success(c, resp)
+		return
+	}
+
+	start := time.Now()
+	out, err := h.generator.Generate(ctx, in)
+	if err != nil {
+		logger.Warn(ctx, "job replay generation failed", "error", err.Error(), "job_id", job.ID)
+		dto.Error(c, http.StatusBadGateway, "replay generation failed: "+err.Error())
+		return
+	}
+	logger.Info(ctx, "job replayed in sandbox",
+		"job_id", job.ID, "provider", provider, "model", model, "overridden", strings.Join(resp.Overridden, ","))
+
+	resp.Content = out.Content
+	resp.Usage = &dto.ReplayUsage{
+		Provider:         provider,
+		Model:            model,
+		PromptTokens:     out.Meta.PromptTokens,
+		CompletionTokens: out.Meta.CompletionTokens,
+		DurationMs:       time.Since(start).Milliseconds(),
+	}
+	dto.Success(c, resp)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"time"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobStreamPollInterval 订阅期间回查任务状态的间隔：兼作心跳，并兜底 Worker 异常退出未发布结束事件的情况
+const jobStreamPollInterval = 15 * time.Second
+
+// StreamJob 订阅异步任务实时进度
+// @Summary 订阅异步任务实时进度
+// @Description 通过 SSE 推送异步生成任务（如 chapter_gen）的实时正文与进度（content/progress/done/error，事件协议见 dto.StreamEvent）。订阅前已生成的正文不会补发；任务已结束时直接返回 done/error（失败后由消费者重试时需重新订阅）。多候选任务的 content 事件以 candidate 区分候选
+// @Tags Jobs
+// @Produce text/event-stream
+// @Param jid path string true "任务 ID"
+// @Success 200 {object} dto.StreamEvent "SSE stream"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/jobs/{jid}/stream [get]
+func (h *StreamHandler) StreamJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	jobID := dto.BindJobID(c)
+
+	if h.progress == nil {
+		dto.ServiceUnavailable(c, "job progress streaming not configured")
+		return
+	}
+
+	// 先订阅再读取任务状态，保证读取之后发布的事件都能收到
+	events, unsubscribe, err := h.progress.Subscribe(ctx, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to subscribe job progress", err)
+		dto.InternalError(c, "failed to subscribe job progress")
+		return
+	}
+	defer unsubscribe()
+
+	job, err := h.loadJob(ctx, tenantID, jobID)
+	if err != nil {
+		logger.Error(ctx, "failed to get job", err)
+		dto.InternalError(c, "failed to get job")
+		return
+	}
+	if job == nil {
+		dto.NotFound(c, "job not found")
+		return
+	}
+
+	sse := dto.NewStreamWriter(c)
+	if job.IsFinished() {
+		writeJobFinished(sse, job)
+		return
+	}
+	sse.Progress(dto.StreamStageGenerating, job.Progress, string(job.Status))
+
+	ticker := time.NewTicker(jobStreamPollInterval)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			switch ev.Type {
+			case appstory.JobProgressContent:
+				sse.CandidateContent(ev.Candidate, ev.Chunk)
+			case appstory.JobProgressProgress:
+				sse.Progress(dto.StreamStageGenerating, ev.Progress, "")
+			case appstory.JobProgressDone:
+				sse.Done(dto.StreamDoneData{JobID: jobID, ChapterID: ev.ChapterID})
+				return false
+			case appstory.JobProgressError:
+				sse.Error(jobStreamErrorCode(entity.JobStatus(ev.Status), ev.ErrorCode), ev.Error)
+				return false
+			}
+			return true
+
+		case <-ticker.C:
+			latest, err := h.loadJob(ctx, tenantID, jobID)
+			if err != nil || latest == nil {
+				return true
+			}
+			if latest.IsFinished() {
+				writeJobFinished(sse, latest)
+				return false
+			}
+			sse.Progress(dto.StreamStageGenerating, latest.Progress, string(latest.Status))
+			return true
+
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// loadJob 在独立短事务中读取任务（SSE 路径不持有请求级事务）
+func (h *StreamHandler) loadJob(ctx context.Context, tenantID, jobID string) (*entity.GenerationJob, error) {
+	var job *entity.GenerationJob
+	err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		var err error
+		job, err = h.jobRepo.GetByID(txCtx, jobID)
+		return err
+	})
+	return job, err
+}
+
+// writeJobFinished 按任务最终状态写出 done 或 error 事件
+func writeJobFinished(sse *dto.StreamWriter, job *entity.GenerationJob) {
+	if job.Status == entity.JobStatusCompleted {
+		done := dto.StreamDoneData{JobID: job.ID}
+		if job.ChapterID != nil {
+			done.ChapterID = *job.ChapterID
+		}
+		sse.Done(done)
+		return
+	}
+	sse.Error(jobStreamErrorCode(job.Status, string(job.ErrorCode)), job.ErrorMessage)
+}
+
+// jobStreamErrorCode 任务非正常结束时的流式错误码：取消单独标识，失败优先使用任务记录的错误类型
+func jobStreamErrorCode(status entity.JobStatus, errorCode string) string {
+	switch {
+	case status == entity.JobStatusCancelled || status == entity.JobStatusCancelledPartial:
+		return dto.StreamCodeJobCancelled
+	case errorCode != "":
+		return errorCode
+	default:
+		return dto.StreamCodeGenerationFailed
+	}
+}
@@ -0,0 +1,192 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListLLMUsage 查询租户 LLM 用量流水
+// @Summary 查询 LLM 用量流水
+// @Description 按时间范围、提供商、模型、工作流、任务类型、项目筛选租户的 LLM 调用流水（仅 admin），用于财务对账与容量规划。
+// @Description 时间范围为 [from, to)，支持 RFC 3339 或 YYYY-MM-DD（UTC）。format=csv 时按时间升序导出全部符合条件的记录（上限 100000 行）
+// @Tags Tenants
+// @Produce json
+// @Produce text/csv
+// @Param tid path string true "租户 ID"
+// @Param from query string false "起始时间（含）"
+// @Param to query string false "结束时间（不含）"
+// @Param provider query string false "提供商"
+// @Param model query string false "模型"
+// @Param workflow query string false "工作流"
+// @Param job_type query string false "任务类型"
+// @Param project_id query string false "项目 ID"
+// @Param format query string false "返回格式：json（默认）/ csv"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Success 200 {object} dto.Response[dto.LLMUsageListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/{tid}/llm-usage [get]
+func (h *TenantHandler) ListLLMUsage(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := strings.TrimSpace(c.Param("tid"))
+	if _, err := uuid.Parse(tenantID); err != nil {
+		dto.BadRequest(c, "invalid tenant id")
+		return
+	}
+	filter, ok := bindLLMUsageFilter(c)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != "csv" {
+		dto.BadRequest(c, "unsupported format")
+		return
+	}
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant")
+		return
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return
+	}
+
+	if format == "csv" {
+		h.exportLLMUsage(c, tenant, filter)
+		return
+	}
+
+	pageReq := dto.BindPage(c)
+	result, summary, err := h.usage.List(ctx, tenantID, filter, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	if err != nil {
+		logger.Error(ctx, "failed to list llm usage", err)
+		dto.InternalError(c, "failed to list llm usage")
+		return
+	}
+
+	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
+	dto.SuccessWithPage(c, dto.ToLLMUsageListResponse(result.Items, summary), meta)
+}
+
+// exportLLMUsage 以 CSV 流式导出用量流水。未指定结束时间时截止到请求时刻，避免导出过程中新写入的记录打乱游标
+func (h *TenantHandler) exportLLMUsage(c *gin.Context, tenant *entity.Tenant, filter *repository.LLMUsageEventFilter) {
+	ctx := c.Request.Context()
+	now := time.Now()
+	if filter.End.IsZero() {
+		filter.End = now
+	}
+
+	w := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("llm-usage-%s-%s.csv", tenant.Slug, now.UTC().Format("20060102"))
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+		c.Status(http.StatusOK)
+		return w.Write(dto.LLMUsageCSVHeader)
+	}
+
+	rows, err := h.usage.Export(ctx, tenant.ID, filter, func(e *entity.LLMUsageEvent) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return w.Write(dto.ToLLMUsageCSVRecord(e))
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err != nil {
+		if started {
+			// 已开始输出，无法再返回错误响应：记录日志，客户端收到截断的文件
+			logger.Error(ctx, "llm usage export interrupted", err)
+			return
+		}
+		if stderrors.Is(err, quota.ErrUsageExportTooLarge) {
+			dto.UnprocessableEntity(c, "usage export too large: narrow the time range or filters", nil)
+			return
+		}
+		logger.Error(ctx, "failed to export llm usage", err)
+		dto.InternalError(c, "failed to export llm usage")
+		return
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logger.Error(ctx, "llm usage export interrupted", err)
+		return
+	}
+	logger.Info(ctx, "llm usage exported", "tenant_id", tenant.ID, "rows", rows)
+}
+
+// bindLLMUsageFilter 解析用量流水筛选条件；参数无效时写入 400 响应并返回 false
+func bindLLMUsageFilter(c *gin.Context) (*repository.LLMUsageEventFilter, bool) {
+	filter := &repository.LLMUsageEventFilter{
+		Provider:  strings.TrimSpace(c.Query("provider")),
+		Model:     strings.TrimSpace(c.Query("model")),
+		Workflow:  strings.TrimSpace(c.Query("workflow")),
+		JobType:   strings.TrimSpace(c.Query("job_type")),
+		ProjectID: strings.TrimSpace(c.Query("project_id")),
+	}
+	if filter.ProjectID != "" {
+		if _, err := uuid.Parse(filter.ProjectID); err != nil {
+			dto.BadRequest(c, "invalid project_id")
+			return nil, false
+		}
+	}
+
+	var ok bool
+	if filter.Start, ok = parseUsageTime(c.Query("from")); !ok {
+		dto.BadRequest(c, "invalid from")
+		return nil, false
+	}
+	if filter.End, ok = parseUsageTime(c.Query("to")); !ok {
+		dto.BadRequest(c, "invalid to")
+		return nil, false
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && !filter.Start.Before(filter.End) {
+		dto.BadRequest(c, "invalid time range: from must be before to")
+		return nil, false
+	}
+	return filter, true
+}
+
+// parseUsageTime 解析 RFC 3339 时间或 YYYY-MM-DD 日期（UTC 零点）；空字符串返回零值
+func parseUsageTime(raw string) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,114 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"z-novel-ai-api/internal/application/provenance"
+	"z-novel-ai-api/internal/application/story/manuscript"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ManuscriptHandler 成稿导出与 AI 来源标记校验处理器
+type ManuscriptHandler struct {
+	projectRepo repository.ProjectRepository
+	chapterRepo repository.ChapterRepository
+	watermarker *provenance.Watermarker
+}
+
+// NewManuscriptHandler 创建成稿处理器；watermarker 为 nil 时导出不嵌入水印、校验接口不可用
+func NewManuscriptHandler(projectRepo repository.ProjectRepository, chapterRepo repository.ChapterRepository, watermarker *provenance.Watermarker) *ManuscriptHandler {
+	return &ManuscriptHandler{
+		projectRepo: projectRepo,
+		chapterRepo: chapterRepo,
+		watermarker: watermarker,
+	}
+}
+
+// ExportManuscript 导出项目成稿
+// @Summary 导出成稿
+// @Description 按叙事顺序导出已完成章节；启用来源标记时嵌入 AI 来源水印
+// @Tags Projects
+// @Produce plain
+// @Param pid path string true "项目 ID"
+// @Param format query string false "导出格式：markdown（默认）/ txt"
+// @Success 200 {string} string "成稿文本"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/export [get]
+func (h *ManuscriptHandler) ExportManuscript(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	format, ok := manuscript.ParseFormat(c.Query("format"))
+	if !ok {
+		dto.BadRequest(c, "unsupported format")
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	chapters, err := h.chapterRepo.ListManuscript(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list manuscript chapters", err)
+		dto.InternalError(c, "failed to export manuscript")
+		return
+	}
+
+	body := manuscript.Render(project, chapters, format)
+	if h.watermarker.Enabled() {
+		body = h.watermarker.Embed(body, provenance.Marker{TenantID: tenantID, ProjectID: project.ID})
+		c.Header("X-AI-Provenance", provenance.Generator)
+	}
+
+	filename := fmt.Sprintf("%s.%s", strings.TrimSpace(project.Title), format.Extension())
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	c.Data(http.StatusOK, format.ContentType(), []byte(body))
+}
+
+// VerifyProvenance 校验文本是否由本系统导出
+// @Summary 校验 AI 来源标记
+// @Description 检测文本中的来源水印并校验签名；元数据块可额外判断正文是否被修改
+// @Tags Provenance
+// @Accept json
+// @Produce json
+// @Param body body dto.VerifyProvenanceRequest true "待校验文本"
+// @Success 200 {object} dto.Response[dto.ProvenanceVerificationResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/provenance/verify [post]
+func (h *ManuscriptHandler) VerifyProvenance(c *gin.Context) {
+	if !h.watermarker.Enabled() {
+		dto.ServiceUnavailable(c, "provenance not configured")
+		return
+	}
+
+	var req dto.VerifyProvenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	tenantID := middleware.GetTenantIDFromGin(c)
+	dto.Success(c, dto.ToProvenanceVerificationResponse(h.watermarker.Verify(req.Text), tenantID))
+}
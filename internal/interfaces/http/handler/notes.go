@@ -0,0 +1,370 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
+	storynotes "z-novel-ai-api/internal/application/story/notes"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// notesIndexTimeout 导入笔记后同步写索引的超时时间（笔记可能较长）
+const notesIndexTimeout = 60 * time.Second
+
+// NotesHandler 作者笔记导入处理器
+type NotesHandler struct {
+	cfg *config.Config
+
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+
+	tenantRepo   repository.TenantRepository
+	projectRepo  repository.ProjectRepository
+	jobRepo      repository.JobRepository
+	artifactRepo repository.ArtifactRepository
+	noteRepo     repository.ProjectNoteRepository
+
+	quotaChecker *quota.TokenQuotaChecker
+	ingestor     *storynotes.Ingestor
+	indexer      *appretrieval.Indexer
+	jobTimeline  *appstory.JobTimeline
+}
+
+// NewNotesHandler 创建作者笔记导入处理器
+func NewNotesHandler(
+	cfg *config.Config,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	tenantRepo repository.TenantRepository,
+	projectRepo repository.ProjectRepository,
+	jobRepo repository.JobRepository,
+	artifactRepo repository.ArtifactRepository,
+	noteRepo repository.ProjectNoteRepository,
+	quotaChecker *quota.TokenQuotaChecker,
+	ingestor *storynotes.Ingestor,
+	indexer *appretrieval.Indexer,
+	jobTimeline *appstory.JobTimeline,
+) *NotesHandler {
+	return &NotesHandler{
+		cfg:          cfg,
+		txMgr:        txMgr,
+		tenantCtx:    tenantCtx,
+		tenantRepo:   tenantRepo,
+		projectRepo:  projectRepo,
+		jobRepo:      jobRepo,
+		artifactRepo: artifactRepo,
+		noteRepo:     noteRepo,
+		quotaChecker: quotaChecker,
+		ingestor:     ingestor,
+		indexer:      indexer,
+		jobTimeline:  jobTimeline,
+	}
+}
+
+// IngestNotes 导入作者笔记
+// @Summary 导入作者笔记（冷启动）
+// @Description 保存自由格式的作者笔记并写入向量索引（segment_type = notes），按段多轮抽取世界观、角色、大纲草稿，作为指定分支的新版本落库（不激活）
+// @Tags Artifacts
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param body body dto.IngestNotesRequest true "笔记内容"
+// @Success 200 {object} dto.Response[dto.IngestNotesResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 429 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/ingest-notes [post]
+func (h *NotesHandler) IngestNotes(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := middleware.GetUserIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	var req dto.IngestNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	content, err := storynotes.Validate(req.Notes)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	branchKey := strings.TrimSpace(req.BranchKey)
+	if branchKey == "" {
+		branchKey = storynotes.DefaultBranchKey
+	}
+	if len(branchKey) > 64 || !isValidBranchKey(branchKey) {
+		dto.BadRequest(c, "invalid branch_key: "+branchKey)
+		return
+	}
+	if branchKey == "main" {
+		dto.BadRequest(c, "branch_key must not be main: imported drafts are never activated")
+		return
+	}
+
+	provider, model, err := resolveProviderModel(h.cfg, req.Provider, req.Model)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	jobID := uuid.NewString()
+	title := strings.TrimSpace(req.Title)
+	now := time.Now()
+
+	var project *entity.Project
+	var note *entity.ProjectNote
+	bases := make(map[entity.ArtifactType]json.RawMessage, len(storynotes.PassOrder))
+	baseVersionIDs := make(map[entity.ArtifactType]*string, len(storynotes.PassOrder))
+
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		tenant, err := h.tenantRepo.GetByID(txCtx, tenantID)
+		if err != nil {
+			return err
+		}
+		if tenant == nil {
+			return errNotFound("tenant not found")
+		}
+		if err := precheckFeatureQuota(txCtx, h.quotaChecker, tenant, entity.PlanFeatureFoundationGenerate); err != nil {
+			return err
+		}
+
+		project, err = h.projectRepo.GetByID(txCtx, projectID)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return errNotFound("project not found")
+		}
+
+		note = entity.NewProjectNote(tenantID, projectID, title, content)
+		inputParams, _ := json.Marshal(map[string]any{
+			"mode":       "notes_ingest",
+			"project_id": projectID,
+			"title":      title,
+			"chars":      note.CharCount,
+			"branch_key": branchKey,
+			"provider":   provider,
+			"model":      model,
+		})
+		job := entity.NewGenerationJob(tenantID, projectID, entity.JobTypeNotesIngest, inputParams)
+		job.ID = jobID
+		job.Status = entity.JobStatusRunning
+		job.StartedAt = &now
+		if err := h.jobRepo.Create(txCtx, job); err != nil {
+			return err
+		}
+
+		note.SourceJobID = &jobID
+		if err := h.noteRepo.Create(txCtx, note); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventLLMStarted, "notes extraction started", map[string]any{
+			"note_id": note.ID,
+			"chars":   note.CharCount,
+		})
+
+		// 草稿以各设定当前激活版本为基线增补
+		arts, err := h.artifactRepo.ListArtifactsByProject(txCtx, projectID)
+		if err != nil {
+			return err
+		}
+		for _, art := range arts {
+			if art == nil || art.ActiveVersionID == nil {
+				continue
+			}
+			v, err := h.artifactRepo.GetVersionByID(txCtx, *art.ActiveVersionID)
+			if err != nil {
+				return err
+			}
+			if v == nil {
+				continue
+			}
+			id := v.ID
+			bases[art.Type] = v.Content
+			baseVersionIDs[art.Type] = &id
+		}
+		return nil
+	}); err != nil {
+		if writeQuotaLimitError(c, err) {
+			return
+		}
+		if isNotFound(err) {
+			dto.NotFound(c, err.Error())
+			return
+		}
+		logger.Error(ctx, "failed to prepare notes ingestion", err)
+		dto.InternalError(c, "failed to ingest notes")
+		return
+	}
+
+	// 笔记先行入索引：即使后续抽取失败，原文也可被召回
+	indexed := h.indexNotes(ctx, tenantID, projectID, note)
+
+	start := time.Now()
+	out, genErr := h.ingestor.Extract(ctx, &storynotes.ExtractInput{
+		TenantID:           tenantID,
+		ProjectID:          projectID,
+		ProjectTitle:       project.Title,
+		ProjectDescription: project.Description,
+		Notes:              content,
+		Base:               bases,
+		Provider:           provider,
+		Model:              model,
+		Temperature:        req.Temperature,
+		MaxTokens:          req.MaxTokens,
+	})
+	durationMs := int(time.Since(start).Milliseconds())
+	if genErr != nil {
+		if err := h.markJobFailed(ctx, tenantID, jobID, genErr, durationMs); err != nil {
+			logger.Warn(ctx, "failed to mark notes ingestion job failed", "error", err.Error(), "job_id", jobID)
+		}
+		logger.Error(ctx, "notes extraction failed", genErr)
+		dto.InternalError(c, "notes extraction failed")
+		return
+	}
+
+	resp := &dto.IngestNotesResponse{
+		JobID:   jobID,
+		NoteID:  note.ID,
+		Chunks:  out.Chunks,
+		Indexed: indexed,
+		Drafts:  make([]*dto.IngestedDraftResponse, 0, len(out.Drafts)),
+		Usage: &dto.FoundationUsageResponse{
+			Provider:         out.Usage.Provider,
+			Model:            out.Usage.Model,
+			PromptTokens:     out.Usage.PromptTokens,
+			CompletionTokens: out.Usage.CompletionTokens,
+			Temperature:      out.Usage.Temperature,
+			DurationMs:       durationMs,
+			GeneratedAt:      out.Usage.GeneratedAt.Format(time.RFC3339),
+		},
+	}
+
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		createdBy := strings.TrimSpace(userID)
+		sourceJobID := jobID
+		for _, draft := range out.Drafts {
+			art, err := h.artifactRepo.EnsureArtifact(txCtx, tenantID, projectID, draft.Type)
+			if err != nil {
+				return err
+			}
+			latest, err := h.artifactRepo.GetLatestVersionNo(txCtx, art.ID)
+			if err != nil {
+				return err
+			}
+			version := &entity.ArtifactVersion{
+				ID:              uuid.NewString(),
+				ArtifactID:      art.ID,
+				VersionNo:       latest + 1,
+				BranchKey:       branchKey,
+				ParentVersionID: baseVersionIDs[draft.Type],
+				Content:         draft.Content,
+				CreatedBy:       &createdBy,
+				SourceJobID:     &sourceJobID,
+			}
+			if err := h.artifactRepo.CreateVersion(txCtx, version); err != nil {
+				return err
+			}
+			resp.Drafts = append(resp.Drafts, &dto.IngestedDraftResponse{
+				ArtifactID:      art.ID,
+				Type:            string(draft.Type),
+				VersionID:       version.ID,
+				VersionNo:       version.VersionNo,
+				BranchKey:       branchKey,
+				ParentVersionID: version.ParentVersionID,
+				Passes:          draft.Passes,
+			})
+		}
+
+		job, err := h.jobRepo.GetByID(txCtx, jobID)
+		if err != nil || job == nil {
+			return err
+		}
+		result, _ := json.Marshal(map[string]any{
+			"note_id":    note.ID,
+			"chunks":     out.Chunks,
+			"indexed":    indexed,
+			"branch_key": branchKey,
+			"drafts":     resp.Drafts,
+		})
+		job.OutputResult = result
+		job.Status = entity.JobStatusCompleted
+		done := time.Now()
+		job.CompletedAt = &done
+		job.DurationMs = durationMs
+		job.SetLLMMetrics(out.Usage.Provider, out.Usage.Model, out.Usage.PromptTokens, out.Usage.CompletionTokens)
+		if err := h.jobRepo.Update(txCtx, job); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventCompleted, "notes drafts created", map[string]any{
+			"chunks":            out.Chunks,
+			"drafts":            len(resp.Drafts),
+			"prompt_tokens":     out.Usage.PromptTokens,
+			"completion_tokens": out.Usage.CompletionTokens,
+		})
+		return nil
+	}); err != nil {
+		logger.Error(ctx, "failed to persist notes drafts", err)
+		dto.InternalError(c, "failed to persist result")
+		return
+	}
+
+	dto.Success(c, resp)
+}
+
+// indexNotes 同步写入笔记索引（失败降级，仅记录日志）
+func (h *NotesHandler) indexNotes(ctx context.Context, tenantID, projectID string, note *entity.ProjectNote) bool {
+	if h.indexer == nil || note == nil {
+		return false
+	}
+	indexCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notesIndexTimeout)
+	defer cancel()
+	if err := h.indexer.IndexNotes(indexCtx, tenantID, projectID, note); err != nil {
+		if !stderrors.Is(err, appretrieval.ErrVectorDisabled) {
+			logger.Warn(ctx, "failed to index notes", "error", err.Error(), "note_id", note.ID)
+		}
+		return false
+	}
+	return true
+}
+
+func (h *NotesHandler) markJobFailed(ctx context.Context, tenantID, jobID string, cause error, durationMs int) error {
+	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		job, err := h.jobRepo.GetByID(txCtx, jobID)
+		if err != nil || job == nil {
+			return err
+		}
+		job.Status = entity.JobStatusFailed
+		job.ErrorMessage = cause.Error()
+		job.ErrorCode = appstory.JobErrorCode(cause)
+		now := time.Now()
+		job.CompletedAt = &now
+		job.DurationMs = durationMs
+		if err := h.jobRepo.Update(txCtx, job); err != nil {
+			return err
+		}
+		h.jobTimeline.Record(txCtx, job, entity.JobEventFailed, cause.Error(), nil)
+		return nil
+	})
+}
@@ -0,0 +1,656 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/ops"
+	appstory "z-novel-ai-api/internal/application/story"
+	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	"z-novel-ai-api/internal/application/warmup"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	"z-novel-ai-api/internal/infrastructure/messaging"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OpsHandler 运维处理器：运维开关、提供商状态、队列与死信、租户余额调整、任务/章节状态巡检、设定集规模上限、预热
+type OpsHandler struct {
+	cfg         *config.Config
+	tenantRepo  repository.TenantRepository
+	switches    *ops.Service
+	llmFactory  *llm.EinoFactory
+	producer    *messaging.Producer
+	consistency *appstory.GenerationConsistency
+	planLimits  *storyfoundation.PlanLimits
+	warmer      *warmup.Warmer
+}
+
+// NewOpsHandler 创建运维处理器
+func NewOpsHandler(cfg *config.Config, tenantRepo repository.TenantRepository, switches *ops.Service, llmFactory *llm.EinoFactory, producer *messaging.Producer, consistency *appstory.GenerationConsistency, planLimits *storyfoundation.PlanLimits, warmer *warmup.Warmer) *OpsHandler {
+	return &OpsHandler{
+		cfg:         cfg,
+		tenantRepo:  tenantRepo,
+		switches:    switches,
+		llmFactory:  llmFactory,
+		producer:    producer,
+		consistency: consistency,
+		planLimits:  planLimits,
+		warmer:      warmer,
+	}
+}
+
+// GetStatus 获取当前租户生效的运维开关
+// @Summary 获取运维状态
+// @Description 返回当前租户生效的只读模式、暂停生成与维护公告（全局与租户级任一开启即生效），供客户端展示维护横幅
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[dto.OpsStatusResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/status [get]
+func (h *OpsHandler) GetStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	global, tenant, err := h.switches.Load(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to load ops switches", err)
+		dto.InternalError(c, "failed to get ops status")
+		return
+	}
+
+	dto.Success(c, dto.ToOpsStatusResponse(global, tenant))
+}
+
+// ListProviders 列出 LLM 提供商及熔断状态
+// @Summary 列出 LLM 提供商
+// @Description 返回已配置的 LLM 提供商（类型、默认模型、备用链，不含密钥）以及各 提供商+模型 的熔断器状态（仅 admin）；熔断器在首次调用时创建
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[dto.OpsProvidersResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/providers [get]
+func (h *OpsHandler) ListProviders(c *gin.Context) {
+	dto.Success(c, dto.ToOpsProvidersResponse(h.llmFactory.CircuitBreakerEnabled(), h.llmFactory.Providers()))
+}
+
+// UpdateGlobalSwitches 设置全局运维开关
+// @Summary 设置全局运维开关
+// @Description 设置全局只读模式/暂停生成/维护公告（仅 admin）；暂停生成时 Worker 停止认领任务。全部关闭且无公告时清除
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param body body dto.UpdateOpsSwitchesRequest true "运维开关"
+// @Success 200 {object} dto.Response[dto.OpsStatusResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/switches/global [put]
+func (h *OpsHandler) UpdateGlobalSwitches(c *gin.Context) {
+	h.updateSwitches(c, "")
+}
+
+// UpdateTenantSwitches 设置租户级运维开关
+// @Summary 设置租户级运维开关
+// @Description 设置指定租户的只读模式/暂停生成/维护公告（仅 admin）；暂停生成时该租户的任务在 Worker 侧延后处理。全部关闭且无公告时清除
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Param body body dto.UpdateOpsSwitchesRequest true "运维开关"
+// @Success 200 {object} dto.Response[dto.OpsStatusResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/switches/tenants/{tid} [put]
+func (h *OpsHandler) UpdateTenantSwitches(c *gin.Context) {
+	tenantID, ok := h.bindTenant(c)
+	if !ok {
+		return
+	}
+	h.updateSwitches(c, tenantID)
+}
+
+// ClearTenantSwitches 清除租户级运维开关
+// @Summary 清除租户级运维开关
+// @Description 清除指定租户的运维开关（仅 admin），恢复仅受全局开关约束
+// @Tags Ops
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Success 204
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/switches/tenants/{tid} [delete]
+func (h *OpsHandler) ClearTenantSwitches(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID, ok := h.bindTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := h.switches.Clear(ctx, tenantID); err != nil {
+		logger.Error(ctx, "failed to clear ops switches", err)
+		dto.InternalError(c, "failed to clear ops switches")
+		return
+	}
+
+	dto.NoContent(c)
+}
+
+// ListQueues 查看消息队列状态
+// @Summary 查看消息队列
+// @Description 返回各业务流的长度、未认领数（lag）、处理中数（pending）、消费者数与死信队列长度，以及 Worker 上报的心跳统计（仅 admin）
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[[]dto.OpsQueueResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues [get]
+func (h *OpsHandler) ListQueues(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resp := make([]*dto.OpsQueueResponse, 0, len(messaging.KnownStreams))
+	for _, stream := range messaging.KnownStreams {
+		info, err := h.producer.InspectQueue(ctx, stream, 3*h.cfg.Messaging.Backpressure.StatsInterval)
+		if err != nil {
+			logger.Error(ctx, "failed to inspect queue", err, "stream", string(stream))
+			dto.InternalError(c, "failed to inspect queues")
+			return
+		}
+		resp = append(resp, dto.ToOpsQueueResponse(info))
+	}
+
+	dto.Success(c, resp)
+}
+
+// ListDLQ 查看死信消息
+// @Summary 查看死信消息
+// @Description 按进入顺序列出指定队列的死信消息（原消息类型、租户、项目与失败原因，不含载荷；仅 admin）
+// @Tags Ops
+// @Produce json
+// @Param queue path string true "队列名称（story-gen / memory-update / audit-log）"
+// @Param limit query int false "最多返回条数（默认 100，最大 1000）"
+// @Success 200 {object} dto.Response[[]dto.OpsDLQMessageResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues/{queue}/dlq [get]
+func (h *OpsHandler) ListDLQ(c *gin.Context) {
+	ctx := c.Request.Context()
+	stream, ok := bindStream(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 64)
+	if err != nil || limit < 1 || limit > messaging.MaxDLQBatch {
+		dto.BadRequest(c, "invalid limit")
+		return
+	}
+
+	entries, err := h.producer.ListDLQ(ctx, stream, limit)
+	if err != nil {
+		logger.Error(ctx, "failed to list DLQ", err, "stream", string(stream))
+		dto.InternalError(c, "failed to list DLQ")
+		return
+	}
+
+	resp := make([]*dto.OpsDLQMessageResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, dto.ToOpsDLQMessageResponse(e))
+	}
+	dto.Success(c, resp)
+}
+
+// RequeueDLQ 死信消息重新入队
+// @Summary 死信消息重新入队
+// @Description 将死信消息重新发布到原始流并从死信队列删除（仅 admin）；未指定 ids 时处理队首全部（单次最多 1000 条）。失败的生成任务被重新认领时按重试处理
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param queue path string true "队列名称（story-gen / memory-update / audit-log）"
+// @Param body body dto.RequeueDLQRequest false "死信消息 ID"
+// @Success 200 {object} dto.Response[dto.RequeueDLQResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues/{queue}/dlq/requeue [post]
+func (h *OpsHandler) RequeueDLQ(c *gin.Context) {
+	ctx := c.Request.Context()
+	stream, ok := bindStream(c)
+	if !ok {
+		return
+	}
+	var req dto.RequeueDLQRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+
+	requeued, err := h.producer.RequeueDLQ(ctx, stream, req.IDs)
+	logger.Info(ctx, "DLQ messages requeued",
+		"stream", string(stream),
+		"count", len(requeued),
+		"user_id", middleware.GetUserIDFromGin(c),
+	)
+	if err != nil {
+		logger.Error(ctx, "failed to requeue DLQ messages", err, "stream", string(stream))
+		dto.InternalError(c, "failed to requeue DLQ messages")
+		return
+	}
+
+	dto.Success(c, &dto.RequeueDLQResponse{Requeued: requeued})
+}
+
+// GetDLQMessage 查看单条死信消息
+// @Summary 查看死信消息详情
+// @Description 返回单条死信消息的失败原因、移入时的投递次数与原消息载荷（仅 admin），用于判断重新入队还是清除
+// @Tags Ops
+// @Produce json
+// @Param queue path string true "队列名称（story-gen / memory-update / audit-log）"
+// @Param id path string true "死信消息 ID"
+// @Success 200 {object} dto.Response[dto.OpsDLQMessageDetailResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues/{queue}/dlq/{id} [get]
+func (h *OpsHandler) GetDLQMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+	stream, ok := bindStream(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.producer.GetDLQ(ctx, stream, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, messaging.ErrDLQMessageNotFound) {
+			dto.NotFound(c, "DLQ message not found")
+			return
+		}
+		logger.Error(ctx, "failed to get DLQ message", err, "stream", string(stream))
+		dto.InternalError(c, "failed to get DLQ message")
+		return
+	}
+
+	dto.Success(c, dto.ToOpsDLQMessageDetailResponse(entry))
+}
+
+// PurgeDLQ 清除死信消息
+// @Summary 清除死信消息
+// @Description 从死信队列删除指定消息（仅 admin）；未指定 ids 时清空该队列的全部死信，删除后不可恢复
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param queue path string true "队列名称（story-gen / memory-update / audit-log）"
+// @Param body body dto.PurgeDLQRequest false "死信消息 ID"
+// @Success 200 {object} dto.Response[dto.PurgeDLQResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues/{queue}/dlq/purge [post]
+func (h *OpsHandler) PurgeDLQ(c *gin.Context) {
+	ctx := c.Request.Context()
+	stream, ok := bindStream(c)
+	if !ok {
+		return
+	}
+	var req dto.PurgeDLQRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+	}
+
+	purged, err := h.producer.PurgeDLQ(ctx, stream, req.IDs)
+	if err != nil {
+		logger.Error(ctx, "failed to purge DLQ messages", err, "stream", string(stream))
+		dto.InternalError(c, "failed to purge DLQ messages")
+		return
+	}
+	logger.Info(ctx, "DLQ messages purged",
+		"stream", string(stream),
+		"count", purged,
+		"all", len(req.IDs) == 0,
+		"user_id", middleware.GetUserIDFromGin(c),
+	)
+
+	dto.Success(c, &dto.PurgeDLQResponse{Purged: purged})
+}
+
+// ListPending 查看重试中的消息
+// @Summary 查看处理中/待重试消息
+// @Description 列出消费者组中已认领未确认的消息、所属 Worker、空闲时长与投递次数（仅 admin）；投递次数达到 retry_limit 后移入死信队列
+// @Tags Ops
+// @Produce json
+// @Param queue path string true "队列名称（story-gen / memory-update / audit-log）"
+// @Param limit query int false "最多返回条数（默认 100，最大 1000）"
+// @Success 200 {object} dto.Response[[]dto.OpsPendingMessageResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/queues/{queue}/pending [get]
+func (h *OpsHandler) ListPending(c *gin.Context) {
+	ctx := c.Request.Context()
+	stream, ok := bindStream(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 64)
+	if err != nil || limit < 1 || limit > messaging.MaxDLQBatch {
+		dto.BadRequest(c, "invalid limit")
+		return
+	}
+
+	entries, err := h.producer.ListPending(ctx, stream, limit)
+	if err != nil {
+		logger.Error(ctx, "failed to list pending messages", err, "stream", string(stream))
+		dto.InternalError(c, "failed to list pending messages")
+		return
+	}
+
+	resp := make([]*dto.OpsPendingMessageResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, dto.ToOpsPendingMessageResponse(e, h.cfg.Messaging.RedisStream.RetryLimit))
+	}
+	dto.Success(c, resp)
+}
+
+// AdjustTenantBalance 调整租户 Token 余额
+// @Summary 调整租户 Token 余额
+// @Description 人工增加或扣减指定租户的 Token 余额（仅 admin），用于补偿或纠错；扣减后余额不能为负。调整记录写入日志
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Param body body dto.AdjustBalanceRequest true "调整量与原因"
+// @Success 200 {object} dto.Response[dto.AdjustBalanceResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/tenants/{tid}/balance [post]
+func (h *OpsHandler) AdjustTenantBalance(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID, ok := h.bindTenant(c)
+	if !ok {
+		return
+	}
+	var req dto.AdjustBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		dto.BadRequest(c, "reason is required")
+		return
+	}
+
+	if req.Delta > 0 {
+		if err := h.tenantRepo.CreditBalance(ctx, tenantID, req.Delta); err != nil {
+			logger.Error(ctx, "failed to credit tenant balance", err)
+			dto.InternalError(c, "failed to adjust balance")
+			return
+		}
+	} else if err := h.tenantRepo.DeductBalance(ctx, tenantID, -req.Delta); err != nil {
+		// 余额不足时条件更新不命中（租户存在性已校验）
+		logger.Warn(ctx, "failed to deduct tenant balance", "error", err.Error(), "tenant_id", tenantID)
+		dto.Conflict(c, "insufficient balance")
+		return
+	}
+	logger.Info(ctx, "tenant balance adjusted",
+		"tenant_id", tenantID,
+		"delta", req.Delta,
+		"reason", req.Reason,
+		"user_id", middleware.GetUserIDFromGin(c),
+	)
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil || tenant == nil {
+		logger.Error(ctx, "failed to reload tenant", err)
+		dto.InternalError(c, "failed to get tenant")
+		return
+	}
+	dto.Success(c, &dto.AdjustBalanceResponse{
+		TenantID:     tenantID,
+		Delta:        req.Delta,
+		TokenBalance: tenant.TokenBalance,
+	})
+}
+
+// CheckGenerationConsistency 检查租户的任务与章节状态一致性
+// @Summary 检查任务/章节状态一致性
+// @Description 按任务→章节状态机检查指定租户（仅 admin）：章节处于 generating 但无排队/执行中任务（超过宽限期）、活跃任务的章节不在 generating。repair=true 时将卡住的章节修复为最近一次任务对应的状态
+// @Tags Ops
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Param repair query bool false "是否修复（默认仅报告）"
+// @Success 200 {object} dto.Response[dto.GenerationConsistencyResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/tenants/{tid}/generation-consistency [post]
+func (h *OpsHandler) CheckGenerationConsistency(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID, ok := h.bindTenant(c)
+	if !ok {
+		return
+	}
+	repair := false
+	if raw := c.Query("repair"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			dto.BadRequest(c, "invalid repair")
+			return
+		}
+		repair = v
+	}
+
+	report, err := h.consistency.Reconcile(ctx, tenantID, repair)
+	if err != nil {
+		logger.Error(ctx, "failed to check generation consistency", err)
+		dto.InternalError(c, "failed to check generation consistency")
+		return
+	}
+	if repaired := report.Repaired(); repaired > 0 {
+		logger.Info(ctx, "generation state repaired",
+			"tenant_id", tenantID,
+			"repaired", repaired,
+			"user_id", middleware.GetUserIDFromGin(c),
+		)
+	}
+	dto.Success(c, dto.ToGenerationConsistencyResponse(report))
+}
+
+// UpdateTenantFoundationPlanLimits 设置租户设定集规模上限
+// @Summary 设置租户设定集规模上限
+// @Description 为超长篇等合理的大规划单独放宽（或收紧）指定租户的设定集规模上限（仅 admin）；0 表示该维度沿用全局配置，全部为 0 时清除覆盖
+// @Tags Ops
+// @Accept json
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Param body body dto.FoundationPlanLimitsRequest true "规模上限"
+// @Success 200 {object} dto.Response[dto.FoundationPlanLimitsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/tenants/{tid}/foundation-plan-limits [put]
+func (h *OpsHandler) UpdateTenantFoundationPlanLimits(c *gin.Context) {
+	var req dto.FoundationPlanLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	h.setFoundationPlanLimits(c, req.ToEntity())
+}
+
+// ClearTenantFoundationPlanLimits 清除租户设定集规模上限覆盖
+// @Summary 清除租户设定集规模上限
+// @Description 清除指定租户的设定集规模上限覆盖（仅 admin），恢复使用全局配置
+// @Tags Ops
+// @Produce json
+// @Param tid path string true "租户 ID"
+// @Success 200 {object} dto.Response[dto.FoundationPlanLimitsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/tenants/{tid}/foundation-plan-limits [delete]
+func (h *OpsHandler) ClearTenantFoundationPlanLimits(c *gin.Context) {
+	h.setFoundationPlanLimits(c, nil)
+}
+
+func (h *OpsHandler) setFoundationPlanLimits(c *gin.Context, override *entity.FoundationPlanLimits) {
+	ctx := c.Request.Context()
+	tenantID, ok := h.bindTenant(c)
+	if !ok {
+		return
+	}
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil || tenant == nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant")
+		return
+	}
+
+	if tenant.Settings == nil {
+		tenant.Settings = &entity.TenantSettings{}
+	}
+	tenant.Settings.FoundationPlanLimits = override
+	tenant.UpdatedAt = time.Now()
+	if err := h.tenantRepo.Update(ctx, tenant); err != nil {
+		logger.Error(ctx, "failed to update tenant", err)
+		dto.InternalError(c, "failed to update tenant info")
+		return
+	}
+	logger.Info(ctx, "tenant foundation plan limits updated",
+		"tenant_id", tenantID,
+		"cleared", override == nil,
+		"user_id", middleware.GetUserIDFromGin(c),
+	)
+
+	dto.Success(c, &dto.FoundationPlanLimitsResponse{
+		TenantID:  tenantID,
+		Defaults:  h.planLimits.Defaults(),
+		Override:  tenant.FoundationPlanLimits(),
+		Effective: h.planLimits.For(tenant),
+	})
+}
+
+func (h *OpsHandler) updateSwitches(c *gin.Context, tenantID string) {
+	ctx := c.Request.Context()
+
+	var req dto.UpdateOpsSwitchesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	if err := h.switches.Set(ctx, tenantID, req.ToEntity(middleware.GetUserIDFromGin(c))); err != nil {
+		logger.Error(ctx, "failed to update ops switches", err)
+		dto.InternalError(c, "failed to update ops switches")
+		return
+	}
+
+	global, tenant, err := h.switches.Load(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to load ops switches", err)
+		dto.InternalError(c, "failed to update ops switches")
+		return
+	}
+
+	dto.Success(c, dto.ToOpsStatusResponse(global, tenant))
+}
+
+func (h *OpsHandler) bindTenant(c *gin.Context) (string, bool) {
+	ctx := c.Request.Context()
+	tenantID := strings.TrimSpace(c.Param("tid"))
+	if _, err := uuid.Parse(tenantID); err != nil {
+		dto.BadRequest(c, "invalid tenant id")
+		return "", false
+	}
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant")
+		return "", false
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return "", false
+	}
+	return tenantID, true
+}
+
+func bindStream(c *gin.Context) (messaging.Stream, bool) {
+	stream, ok := messaging.ParseStream(c.Param("queue"))
+	if !ok {
+		dto.BadRequest(c, "unknown queue: "+c.Param("queue"))
+		return "", false
+	}
+	return stream, true
+}
+
+// GetWarmup 查看最近一次预热结果
+// @Summary 查看预热结果
+// @Description 返回处理本次请求的网关实例最近一次预热（启动预热或手动触发）的各步骤耗时与错误（仅 admin）；尚未执行时 steps 为空
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[dto.WarmupResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/warmup [get]
+func (h *OpsHandler) GetWarmup(c *gin.Context) {
+	dto.Success(c, dto.ToWarmupResponse(h.cfg.Warmup.Enabled, h.warmer.Last()))
+}
+
+// TriggerWarmup 手动触发预热
+// @Summary 触发预热
+// @Description 在处理本次请求的网关实例上执行一轮预热（编译工作流图、加载 story_segments 集合、创建模型客户端、调用一次嵌入服务），不受 warmup.enabled 限制（仅 admin）；已有一轮在执行时等待并返回该轮结果。job-worker 仅在启动时预热
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} dto.Response[dto.WarmupResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ops/warmup [post]
+func (h *OpsHandler) TriggerWarmup(c *gin.Context) {
+	ctx := c.Request.Context()
+	report := h.warmer.Run(ctx)
+	report.Log(ctx)
+	logger.Info(ctx, "warmup triggered", "user_id", middleware.GetUserIDFromGin(c), "ok", report.OK)
+	dto.Success(c, dto.ToWarmupResponse(h.cfg.Warmup.Enabled, report))
+}
@@ -3,7 +3,12 @@ package handler
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
+	"z-novel-ai-api/internal/application/story/health"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
@@ -15,13 +20,19 @@ import (
 
 // ProjectHandler 项目处理器
 type ProjectHandler struct {
+	cfg         *config.Config
 	projectRepo repository.ProjectRepository
+	tenantRepo  repository.TenantRepository
+	health      *health.Service
 }
 
 // NewProjectHandler 创建项目处理器
-func NewProjectHandler(projectRepo repository.ProjectRepository) *ProjectHandler {
+func NewProjectHandler(cfg *config.Config, projectRepo repository.ProjectRepository, tenantRepo repository.TenantRepository, healthService *health.Service) *ProjectHandler {
 	return &ProjectHandler{
+		cfg:         cfg,
 		projectRepo: projectRepo,
+		tenantRepo:  tenantRepo,
+		health:      healthService,
 	}
 }
 
@@ -35,6 +46,7 @@ func NewProjectHandler(projectRepo repository.ProjectRepository) *ProjectHandler
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.ProjectListResponse]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects [get]
 func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -64,6 +76,7 @@ func (h *ProjectHandler) ListProjects(c *gin.Context) {
 // @Success 201 {object} dto.Response[dto.ProjectResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects [post]
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -72,11 +85,24 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 
 	var req dto.CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
-	project := req.ToProjectEntity(tenantID, userID)
+	if err := validateDefaultProvider(h.cfg, req.Settings); err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	// 新项目设置以租户默认模板为基础
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to create project")
+		return
+	}
+
+	project := req.ToProjectEntity(tenantID, userID, tenant.ProjectDefaults())
 
 	if err := h.projectRepo.Create(ctx, project); err != nil {
 		logger.Error(ctx, "failed to create project", err)
@@ -98,6 +124,7 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.ProjectResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid} [get]
 func (h *ProjectHandler) GetProject(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -107,11 +134,7 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get project", err)
@@ -128,6 +151,37 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	dto.Success(c, resp)
 }
 
+// GetProjectHealth 获取项目健康度
+// @Summary 获取项目健康度
+// @Description 汇总大纲偏离、未落地的大纲条目、故事时间倒退、未处理的设定冲突、过期构件、近期失败任务与 Token 余额，按 ok/info/warning/critical 分级；单项数据源失败时该项标记为 unavailable
+// @Tags Projects
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.ProjectHealthResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/health [get]
+func (h *ProjectHandler) GetProjectHealth(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	report := h.health.Check(ctx, tenantID, projectID, time.Now())
+	dto.Success(c, dto.ToProjectHealthResponse(report))
+}
+
 // UpdateProject 更新项目
 // @Summary 更新项目
 // @Description 更新指定项目的信息
@@ -140,6 +194,7 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid} [put]
 func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -147,7 +202,11 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 
 	var req dto.UpdateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
+		return
+	}
+	if err := validateDefaultProvider(h.cfg, req.Settings); err != nil {
+		dto.BadRequest(c, err.Error())
 		return
 	}
 
@@ -180,14 +239,17 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 
 // DeleteProject 删除项目
 // @Summary 删除项目
-// @Description 删除指定项目
+// @Description 删除指定项目；需先通过 POST /v1/confirmations（action=project.delete）申请确认令牌
 // @Tags Projects
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
+// @Param X-Confirmation-Token header string true "二次确认令牌"
 // @Success 204 "No Content"
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 428 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid} [delete]
 func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -196,11 +258,7 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	if err := h.projectRepo.Delete(ctx, projectID); err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to delete project", err)
@@ -220,6 +278,7 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 // @Param pid path string true "项目 ID"
 // @Success 200 {object} dto.Response[dto.ProjectSettingsResponse]
 // @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/settings [get]
 func (h *ProjectHandler) GetProjectSettings(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -237,17 +296,7 @@ func (h *ProjectHandler) GetProjectSettings(c *gin.Context) {
 		return
 	}
 
-	settings := &dto.ProjectSettingsResponse{}
-	if project.Settings != nil {
-		settings = &dto.ProjectSettingsResponse{
-			DefaultChapterLength: project.Settings.DefaultChapterLength,
-			WritingStyle:         project.Settings.WritingStyle,
-			POV:                  project.Settings.POV,
-			Temperature:          project.Settings.Temperature,
-		}
-	}
-
-	dto.Success(c, settings)
+	dto.Success(c, dto.ToProjectSettingsResponse(project.Settings))
 }
 
 // UpdateProjectSettings 更新项目设置
@@ -261,6 +310,7 @@ func (h *ProjectHandler) GetProjectSettings(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.ProjectSettingsResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/settings [put]
 func (h *ProjectHandler) UpdateProjectSettings(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -268,7 +318,11 @@ func (h *ProjectHandler) UpdateProjectSettings(c *gin.Context) {
 
 	var req dto.ProjectSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
+		return
+	}
+	if err := validateDefaultProvider(h.cfg, &req); err != nil {
+		dto.BadRequest(c, err.Error())
 		return
 	}
 
@@ -297,12 +351,63 @@ func (h *ProjectHandler) UpdateProjectSettings(c *gin.Context) {
 		return
 	}
 
-	settings := &dto.ProjectSettingsResponse{
-		DefaultChapterLength: project.Settings.DefaultChapterLength,
-		WritingStyle:         project.Settings.WritingStyle,
-		POV:                  project.Settings.POV,
-		Temperature:          project.Settings.Temperature,
+	dto.Success(c, dto.ToProjectSettingsResponse(project.Settings))
+}
+
+// ResetProjectSettings 将项目设置重置为租户默认
+// @Summary 重置项目设置为租户默认
+// @Description 按租户默认项目设置模板重置章节长度、风格、视角、召回与 LLM 默认值；POV 角色风格与公开发布状态保持不变
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.ProjectSettingsResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/settings/reset [post]
+func (h *ProjectHandler) ResetProjectSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
 	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to reset project settings")
+		return
+	}
+
+	if project.Settings == nil {
+		project.Settings = &entity.ProjectSettings{}
+	}
+	project.Settings.ResetTo(tenant.ProjectDefaults())
 
-	dto.Success(c, settings)
+	if err := h.projectRepo.Update(ctx, project); err != nil {
+		logger.Error(ctx, "failed to update project", err)
+		dto.InternalError(c, "failed to update project")
+		return
+	}
+
+	dto.Success(c, dto.ToProjectSettingsResponse(project.Settings))
+}
+
+// validateDefaultProvider 校验项目设置中的默认 provider 已配置
+func validateDefaultProvider(cfg *config.Config, req *dto.ProjectSettingsRequest) error {
+	if req == nil || strings.TrimSpace(req.Provider) == "" {
+		return nil
+	}
+	_, _, err := resolveProviderModel(cfg, req.Provider, req.Model)
+	return err
 }
@@ -80,6 +80,7 @@ func NewProjectCreationHandler(
 // @Produce json
 // @Param body body dto.CreateProjectCreationSessionRequest false "创建请求"
 // @Success 201 {object} dto.Response[dto.ProjectCreationSessionResponse]
+// @Security BearerAuth
 // @Router /v1/project-creation-sessions [post]
 func (h *ProjectCreationHandler) CreateSession(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -102,6 +103,16 @@ func (h *ProjectCreationHandler) CreateSession(c *gin.Context) {
 }
 
 // GetSession 获取会话详情
+// @Summary 获取孵化会话详情
+// @Description 获取对话创建项目会话的阶段、草稿与关联项目
+// @Tags ProjectCreation
+// @Produce json
+// @Param sid path string true "会话 ID"
+// @Success 200 {object} dto.Response[dto.ProjectCreationSessionResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/project-creation-sessions/{sid} [get]
 func (h *ProjectCreationHandler) GetSession(c *gin.Context) {
 	ctx := c.Request.Context()
 	sessionID := c.Param("sid")
@@ -121,6 +132,17 @@ func (h *ProjectCreationHandler) GetSession(c *gin.Context) {
 }
 
 // ListTurns 获取会话轮次
+// @Summary 获取孵化会话轮次
+// @Description 分页获取会话的对话轮次
+// @Tags ProjectCreation
+// @Produce json
+// @Param sid path string true "会话 ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Success 200 {object} dto.Response[dto.ProjectCreationTurnListResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/project-creation-sessions/{sid}/turns [get]
 func (h *ProjectCreationHandler) ListTurns(c *gin.Context) {
 	ctx := c.Request.Context()
 	sessionID := c.Param("sid")
@@ -168,6 +190,7 @@ func (h *ProjectCreationHandler) ListTurns(c *gin.Context) {
 // @Param sid path string true "会话ID"
 // @Param body body dto.SendProjectCreationMessageRequest true "消息内容"
 // @Success 200 {object} dto.Response[dto.SendProjectCreationMessageResponse]
+// @Security BearerAuth
 // @Router /v1/project-creation-sessions/{sid}/messages [post]
 func (h *ProjectCreationHandler) SendMessage(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -178,7 +201,7 @@ func (h *ProjectCreationHandler) SendMessage(c *gin.Context) {
 	// 1. 参数绑定与模型解析
 	var req dto.SendProjectCreationMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -287,6 +310,7 @@ func (h *ProjectCreationHandler) SendMessage(c *gin.Context) {
 					Description: out.ProposedProject.Description,
 					Genre:       out.ProposedProject.Genre,
 					Status:      entity.ProjectStatusActive,
+					Settings:    entity.NewProjectSettingsFromTemplate(tenant.ProjectDefaults()),
 				}
 				if err := h.projectRepo.Create(txCtx, newProject); err != nil {
 					return err
@@ -0,0 +1,210 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PublicHandler 公开只读 API 处理器（免认证；仅暴露开启 public_read 的项目中已完成的章节）
+type PublicHandler struct {
+	txMgr     repository.Transactor
+	tenantCtx repository.TenantContextManager
+
+	tenantRepo  repository.TenantRepository
+	projectRepo repository.ProjectRepository
+	chapterRepo repository.ChapterRepository
+
+	cacheControl string
+}
+
+// NewPublicHandler 创建公开只读 API 处理器
+func NewPublicHandler(
+	cfg *config.Config,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	tenantRepo repository.TenantRepository,
+	projectRepo repository.ProjectRepository,
+	chapterRepo repository.ChapterRepository,
+) *PublicHandler {
+	return &PublicHandler{
+		txMgr:        txMgr,
+		tenantCtx:    tenantCtx,
+		tenantRepo:   tenantRepo,
+		projectRepo:  projectRepo,
+		chapterRepo:  chapterRepo,
+		cacheControl: publicCacheControl(cfg.PublicAPI),
+	}
+}
+
+// publicCacheControl 构建公开响应的 Cache-Control 头
+func publicCacheControl(cfg config.PublicAPIConfig) string {
+	parts := []string{"public", fmt.Sprintf("max-age=%d", int(cfg.MaxAge.Seconds()))}
+	if cfg.SharedMaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("s-maxage=%d", int(cfg.SharedMaxAge.Seconds())))
+	}
+	if cfg.StaleWhileRevalidate > 0 {
+		parts = append(parts, fmt.Sprintf("stale-while-revalidate=%d", int(cfg.StaleWhileRevalidate.Seconds())))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetProject 获取公开项目信息
+// @Summary 获取公开项目信息
+// @Description 免认证获取已开启公开访问的项目信息（响应可被 CDN 缓存）
+// @Tags Public
+// @Produce json
+// @Param tenant path string true "租户 Slug"
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.PublicProjectResponse]
+// @Success 304 "Not Modified"
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /public/v1/{tenant}/projects/{pid} [get]
+func (h *PublicHandler) GetProject(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var resp *dto.PublicProjectResponse
+	found, err := h.withPublicProject(c, func(txCtx context.Context, project *entity.Project) error {
+		chapters, err := h.chapterRepo.ListPublished(txCtx, project.ID)
+		if err != nil {
+			return err
+		}
+		resp = dto.ToPublicProjectResponse(project, chapters)
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "failed to get public project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if !found {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	dto.SuccessCacheable(c, resp, h.cacheControl)
+}
+
+// ListChapters 获取公开章节目录
+// @Summary 获取公开章节目录
+// @Description 免认证按叙事顺序获取已完成章节目录（不含正文，响应可被 CDN 缓存）
+// @Tags Public
+// @Produce json
+// @Param tenant path string true "租户 Slug"
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.PublicChapterListResponse]
+// @Success 304 "Not Modified"
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /public/v1/{tenant}/projects/{pid}/chapters [get]
+func (h *PublicHandler) ListChapters(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var chapters []*entity.Chapter
+	found, err := h.withPublicProject(c, func(txCtx context.Context, project *entity.Project) error {
+		var err error
+		chapters, err = h.chapterRepo.ListPublished(txCtx, project.ID)
+		return err
+	})
+	if err != nil {
+		logger.Error(ctx, "failed to list public chapters", err)
+		dto.InternalError(c, "failed to list chapters")
+		return
+	}
+	if !found {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	dto.SuccessCacheable(c, dto.ToPublicChapterListResponse(chapters), h.cacheControl)
+}
+
+// GetChapter 获取公开章节正文
+// @Summary 获取公开章节正文
+// @Description 免认证获取已完成章节正文（响应可被 CDN 缓存）
+// @Tags Public
+// @Produce json
+// @Param tenant path string true "租户 Slug"
+// @Param pid path string true "项目 ID"
+// @Param cid path string true "章节 ID"
+// @Success 200 {object} dto.Response[dto.PublicChapterResponse]
+// @Success 304 "Not Modified"
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /public/v1/{tenant}/projects/{pid}/chapters/{cid} [get]
+func (h *PublicHandler) GetChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+	if _, err := uuid.Parse(chapterID); err != nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	var chapter *entity.Chapter
+	found, err := h.withPublicProject(c, func(txCtx context.Context, project *entity.Project) error {
+		ch, err := h.chapterRepo.GetByID(txCtx, chapterID)
+		if err != nil {
+			return err
+		}
+		// 仅暴露已完成章节，草稿/审阅中的章节对外视为不存在
+		if ch != nil && ch.ProjectID == project.ID && ch.Status == entity.ChapterStatusCompleted {
+			chapter = ch
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "failed to get public chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if !found || chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	dto.SuccessCacheable(c, dto.ToPublicChapterResponse(chapter), h.cacheControl)
+}
+
+// withPublicProject 按租户 Slug 解析租户并在其 RLS 上下文中加载项目；
+// 租户不可用、项目不存在或未开启公开访问时 found 为 false（统一返回 404，避免泄露项目是否存在）。
+func (h *PublicHandler) withPublicProject(c *gin.Context, fn func(txCtx context.Context, project *entity.Project) error) (bool, error) {
+	ctx := c.Request.Context()
+	slug := strings.TrimSpace(c.Param("tenant"))
+	projectID := dto.BindProjectID(c)
+	if slug == "" {
+		return false, nil
+	}
+	if _, err := uuid.Parse(projectID); err != nil {
+		return false, nil
+	}
+
+	tenant, err := h.tenantRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return false, err
+	}
+	if tenant == nil || tenant.Status != entity.TenantStatusActive {
+		return false, nil
+	}
+
+	found := false
+	err = withTenantTx(ctx, h.txMgr, h.tenantCtx, tenant.ID, func(txCtx context.Context) error {
+		project, err := h.projectRepo.GetByID(txCtx, projectID)
+		if err != nil {
+			return err
+		}
+		if !project.IsPublicReadable() {
+			return nil
+		}
+		found = true
+		return fn(txCtx, project)
+	})
+	return found, err
+}
@@ -2,8 +2,10 @@
 package handler
 
 import (
+	stderrors "errors"
 	"net/http"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
@@ -16,27 +18,32 @@ import (
 // RelationHandler 关系处理器
 type RelationHandler struct {
 	relationRepo repository.RelationRepository
+	weigher      *appstory.RelationWeigher
 }
 
 // NewRelationHandler 创建关系处理器
-func NewRelationHandler(relationRepo repository.RelationRepository) *RelationHandler {
+func NewRelationHandler(relationRepo repository.RelationRepository, weigher *appstory.RelationWeigher) *RelationHandler {
 	return &RelationHandler{
 		relationRepo: relationRepo,
+		weigher:      weigher,
 	}
 }
 
 // ListRelations 获取项目关系列表
 // @Summary 获取项目关系列表
-// @Description 获取指定项目的实体关系列表
+// @Description 获取指定项目的实体关系列表，附带以参照章节计算的时效衰减有效强度
 // @Tags Relations
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
 // @Param type query string false "关系类型 (friend, enemy, family, lover, subordinate, mentor, rival, ally)"
+// @Param at_chapter_id query string false "参照章节 ID（默认项目最后一章）"
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.RelationListResponse]
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/relations [get]
 func (h *RelationHandler) ListRelations(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -54,7 +61,18 @@ func (h *RelationHandler) ListRelations(c *gin.Context) {
 		return
 	}
 
-	resp := dto.ToRelationListResponse(result.Items)
+	weighted, err := h.weigher.Weigh(ctx, projectID, c.Query("at_chapter_id"), result.Items)
+	if err != nil {
+		if stderrors.Is(err, appstory.ErrChapterNotInProject) {
+			dto.BadRequest(c, "at_chapter_id does not belong to project")
+			return
+		}
+		logger.Error(ctx, "failed to weigh relations", err)
+		dto.InternalError(c, "failed to list relations")
+		return
+	}
+
+	resp := dto.ToWeightedRelationListResponse(weighted)
 	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
 	dto.SuccessWithPage(c, resp, meta)
 }
@@ -70,6 +88,7 @@ func (h *RelationHandler) ListRelations(c *gin.Context) {
 // @Success 201 {object} dto.Response[dto.RelationResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/relations [post]
 func (h *RelationHandler) CreateRelation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -77,7 +96,7 @@ func (h *RelationHandler) CreateRelation(c *gin.Context) {
 
 	var req dto.CreateRelationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -103,6 +122,7 @@ func (h *RelationHandler) CreateRelation(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.RelationResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/relations/{rid} [get]
 func (h *RelationHandler) GetRelation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -112,11 +132,7 @@ func (h *RelationHandler) GetRelation(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get relation", err)
@@ -145,6 +161,7 @@ func (h *RelationHandler) GetRelation(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/relations/{rid} [put]
 func (h *RelationHandler) UpdateRelation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -152,7 +169,7 @@ func (h *RelationHandler) UpdateRelation(c *gin.Context) {
 
 	var req dto.UpdateRelationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -193,6 +210,7 @@ func (h *RelationHandler) UpdateRelation(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/relations/{rid} [delete]
 func (h *RelationHandler) DeleteRelation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -201,11 +219,7 @@ func (h *RelationHandler) DeleteRelation(c *gin.Context) {
 	if err := h.relationRepo.Delete(ctx, relationID); err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to delete relation", err)
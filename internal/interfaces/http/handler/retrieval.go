@@ -2,25 +2,49 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"z-novel-ai-api/internal/application/retrieval"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	storyspoiler "z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RetrievalHandler 检索处理器
 type RetrievalHandler struct {
-	engine *retrieval.Engine
+	engine      *retrieval.Engine
+	chapterRepo repository.ChapterRepository
+	projectRepo repository.ProjectRepository
+	series      *storyseries.SeriesService
+	spoilers    *storyspoiler.Service
+	indexer     *retrieval.Indexer
 }
 
 // NewRetrievalHandler 创建检索处理器
-func NewRetrievalHandler(engine *retrieval.Engine) *RetrievalHandler {
+func NewRetrievalHandler(
+	engine *retrieval.Engine,
+	chapterRepo repository.ChapterRepository,
+	projectRepo repository.ProjectRepository,
+	seriesService *storyseries.SeriesService,
+	spoilers *storyspoiler.Service,
+	indexer *retrieval.Indexer,
+) *RetrievalHandler {
 	return &RetrievalHandler{
-		engine: engine,
+		engine:      engine,
+		chapterRepo: chapterRepo,
+		projectRepo: projectRepo,
+		series:      seriesService,
+		spoilers:    spoilers,
+		indexer:     indexer,
 	}
 }
 
@@ -34,6 +58,7 @@ func NewRetrievalHandler(engine *retrieval.Engine) *RetrievalHandler {
 // @Success 200 {object} dto.Response[dto.SearchResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/retrieval/search [post]
 func (h *RetrievalHandler) Search(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -41,7 +66,7 @@ func (h *RetrievalHandler) Search(c *gin.Context) {
 
 	var req dto.SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 	projectID := strings.TrimSpace(req.ProjectID)
@@ -61,14 +86,33 @@ func (h *RetrievalHandler) Search(c *gin.Context) {
 		return
 	}
 
+	narrativePos, err := h.resolveNarrativePos(ctx, req.CurrentChapterID)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	seriesProjectIDs, err := h.resolveSeriesProjects(ctx, projectID, req.Options)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
 	start := time.Now()
 	out, err := h.engine.Search(ctx, retrieval.SearchInput{
-		TenantID:         tenantID,
-		ProjectID:        projectID,
-		Query:            query,
-		CurrentStoryTime: req.CurrentStoryTime,
-		TopK:             topK,
-		IncludeEntities:  true,
+		TenantID:            tenantID,
+		ProjectID:           projectID,
+		Query:               query,
+		CurrentStoryTime:    req.CurrentStoryTime,
+		CurrentNarrativePos: narrativePos,
+		TimeFilter:          retrieval.ParseTimeFilter(req.TimeFilter),
+		POVEntityID:         req.POVEntityID,
+		SeriesProjectIDs:    seriesProjectIDs,
+		BranchKey:           req.BranchKey,
+		TopK:                topK,
+		NamespaceQuotas:     namespaceQuotas(req.Options),
+		VectorWeight:        vectorWeight(req.Options),
+		KeywordWeight:       keywordWeight(req.Options),
+		IncludeEntities:     true,
 	})
 	if err != nil {
 		dto.BadRequest(c, err.Error())
@@ -89,6 +133,7 @@ func (h *RetrievalHandler) Search(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.DebugRetrievalResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/retrieval/debug [post]
 func (h *RetrievalHandler) DebugRetrieval(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -96,7 +141,7 @@ func (h *RetrievalHandler) DebugRetrieval(c *gin.Context) {
 
 	var req dto.DebugRetrievalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 	projectID := strings.TrimSpace(req.ProjectID)
@@ -116,15 +161,34 @@ func (h *RetrievalHandler) DebugRetrieval(c *gin.Context) {
 		return
 	}
 
+	narrativePos, err := h.resolveNarrativePos(ctx, req.CurrentChapterID)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+	seriesProjectIDs, err := h.resolveSeriesProjects(ctx, projectID, req.Options)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
 	start := time.Now()
 	out, err := h.engine.DebugSearch(ctx, retrieval.SearchInput{
-		TenantID:         tenantID,
-		ProjectID:        projectID,
-		Query:            query,
-		CurrentStoryTime: req.CurrentStoryTime,
-		TopK:             topK,
-		IncludeEntities:  true,
-		IncludeEmbedding: req.IncludeEmbedding,
+		TenantID:            tenantID,
+		ProjectID:           projectID,
+		Query:               query,
+		CurrentStoryTime:    req.CurrentStoryTime,
+		CurrentNarrativePos: narrativePos,
+		TimeFilter:          retrieval.ParseTimeFilter(req.TimeFilter),
+		POVEntityID:         req.POVEntityID,
+		SeriesProjectIDs:    seriesProjectIDs,
+		BranchKey:           req.BranchKey,
+		TopK:                topK,
+		NamespaceQuotas:     namespaceQuotas(req.Options),
+		VectorWeight:        vectorWeight(req.Options),
+		KeywordWeight:       keywordWeight(req.Options),
+		IncludeEntities:     true,
+		IncludeEmbedding:    req.IncludeEmbedding,
 	})
 	if err != nil {
 		dto.BadRequest(c, err.Error())
@@ -137,17 +201,301 @@ func (h *RetrievalHandler) DebugRetrieval(c *gin.Context) {
 	if req.IncludeEmbedding {
 		debugResp.QueryEmbedding = out.QueryEmbedding
 	}
-	if out.Debug != nil {
-		debugResp.DebugInfo = &dto.DebugInfo{
-			VectorSearchTime:   out.Debug.VectorSearchTimeMs,
-			KeywordSearchTime:  0,
-			FusionTime:         0,
-			TotalCandidates:    out.Debug.TotalCandidates,
-			FilteredCandidates: out.Debug.FilteredCandidates,
+	debugResp.DebugInfo = toDebugInfo(out.Debug)
+
+	dto.Success(c, debugResp)
+}
+
+// DebugChapterRetrieval 按章节生成参数调试召回
+// @Summary 按章节生成参数调试召回
+// @Description 使用与章节生成完全相同的参数（大纲查询、叙事位置/故事时间、POV、同系列前作、剧透保护）执行召回，返回片段得分、生效的过滤条件、检索分区及哪些片段落入 Prompt 预算；不触发生成
+// @Tags Retrieval
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param body body dto.ChapterRetrievalDebugRequest true "调试请求"
+// @Success 200 {object} dto.Response[dto.ChapterRetrievalDebugResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/retrieval/debug [post]
+func (h *RetrievalHandler) DebugChapterRetrieval(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	var req dto.ChapterRetrievalDebugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	if h.engine == nil {
+		dto.InternalError(c, "retrieval engine not configured")
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(ctx, req.ChapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil || chapter.ProjectID != projectID {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	// 与生成任务一致：outline 参数优先，其次为章节大纲
+	outline := strings.TrimSpace(req.Outline)
+	if outline == "" {
+		outline = strings.TrimSpace(chapter.Outline)
+	}
+	if outline == "" {
+		dto.BadRequest(c, "chapter outline is empty")
+		return
+	}
+
+	narrativePos, err := h.chapterRepo.GetNarrativePosition(ctx, chapter.ID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter narrative position", err)
+		dto.InternalError(c, "failed to get chapter narrative position")
+		return
+	}
+	seriesProjectIDs, err := h.series.RetrievalProjectIDs(ctx, project)
+	if err != nil {
+		logger.Warn(ctx, "failed to resolve series retrieval scope", "error", err.Error(), "project_id", project.ID)
+	}
+	spoilers, err := h.spoilers.ForChapter(ctx, chapter)
+	if err != nil {
+		logger.Warn(ctx, "failed to load spoiler guards", "error", err.Error(), "chapter_id", chapter.ID)
+	}
+
+	focusEntityIDs, err := h.engine.DetectFocusEntities(ctx, project.ID, chapter.Title+"\n"+outline)
+	if err != nil {
+		logger.Warn(ctx, "failed to detect outline entities", "error", err.Error(), "chapter_id", chapter.ID)
+	}
+
+	in := retrieval.ChapterSearchInput(tenantID, project.Settings, chapter, outline, narrativePos, seriesProjectIDs)
+	in.FocusEntityIDs = focusEntityIDs
+	in.IncludeEmbedding = req.IncludeEmbedding
+	out, err := h.engine.DebugSearch(ctx, in)
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
+	}
+
+	// 按生成侧顺序：剧透保护剔除后再计算 Prompt 预算
+	kept := make([]retrieval.Segment, 0, len(out.Segments))
+	keptIdx := make([]int, 0, len(out.Segments))
+	segments := make([]*dto.RetrievalDebugSegment, 0, len(out.Segments))
+	for i := range out.Segments {
+		seg := &dto.RetrievalDebugSegment{ContextSegment: *toContextSegment(out.Segments[i]), Rank: i + 1}
+		if spoilers.Excludes(out.Segments[i]) {
+			seg.ExcludedBy = "spoiler_guard"
+		} else {
+			kept = append(kept, out.Segments[i])
+			keptIdx = append(keptIdx, i)
 		}
+		segments = append(segments, seg)
 	}
+	usedSegments := 0
+	for j, slot := range retrieval.PromptPlacement(kept, retrieval.PromptMaxSegments, retrieval.PromptMaxRunesPerSegment) {
+		segments[keptIdx[j]].InPrompt = slot.Included
+		segments[keptIdx[j]].Truncated = slot.Truncated
+		if slot.Included {
+			usedSegments++
+		}
+	}
+	promptContext := retrieval.BuildPromptContext(kept, retrieval.PromptMaxSegments, retrieval.PromptMaxRunesPerSegment)
 
-	dto.Success(c, debugResp)
+	spoilerGuards := 0
+	if !spoilers.Empty() {
+		spoilerGuards = len(spoilers.Guards)
+	}
+	resp := &dto.ChapterRetrievalDebugResponse{
+		ChapterID: chapter.ID,
+		Query:     outline,
+		Filters: &dto.RetrievalDebugFilters{
+			TopK:                in.TopK,
+			CurrentStoryTime:    in.CurrentStoryTime,
+			CurrentNarrativePos: in.CurrentNarrativePos,
+			POVEntityID:         in.POVEntityID,
+			SeriesProjectIDs:    in.SeriesProjectIDs,
+			NamespaceQuotas:     in.NamespaceQuotas,
+			SpoilerGuards:       spoilerGuards,
+			SpoilerExcluded:     len(out.Segments) - len(kept),
+		},
+		Budget: &dto.RetrievalPromptBudget{
+			MaxSegments:        retrieval.PromptMaxSegments,
+			MaxRunesPerSegment: retrieval.PromptMaxRunesPerSegment,
+			UsedSegments:       usedSegments,
+			Chars:              len([]rune(promptContext)),
+		},
+		Segments:       segments,
+		PromptContext:  promptContext,
+		DisabledReason: strings.TrimSpace(out.DisabledReason),
+		DebugInfo:      toDebugInfo(out.Debug),
+	}
+	if req.IncludeEmbedding {
+		resp.QueryEmbedding = out.QueryEmbedding
+	}
+
+	dto.Success(c, resp)
+}
+
+// ListProjectSegments 列出项目已索引的向量片段
+// @Summary 列出项目向量片段
+// @Description 按片段 ID 游标分页枚举项目已写入向量库的全部片段（不含向量），用于排查索引内容
+// @Tags Retrieval
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页条数（默认 100，最大 1000）"
+// @Param segment_type query string false "片段类型过滤，多个以逗号分隔"
+// @Success 200 {object} dto.Response[dto.VectorSegmentListResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/vectors/segments [get]
+func (h *RetrievalHandler) ListProjectSegments(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(retrieval.DefaultSegmentPageSize)))
+	if err != nil || limit < 1 || limit > retrieval.MaxSegmentPageSize {
+		dto.BadRequest(c, "invalid limit")
+		return
+	}
+	if !h.ensureProject(c, projectID) {
+		return
+	}
+
+	var segmentTypes []string
+	for _, st := range strings.Split(c.Query("segment_type"), ",") {
+		if st = strings.TrimSpace(st); st != "" {
+			segmentTypes = append(segmentTypes, st)
+		}
+	}
+
+	page, err := h.indexer.ListSegments(ctx, retrieval.SegmentListParams{
+		TenantID:     tenantID,
+		ProjectID:    projectID,
+		SegmentTypes: segmentTypes,
+		Cursor:       strings.TrimSpace(c.Query("cursor")),
+		Limit:        limit,
+	})
+	if err != nil {
+		h.writeSegmentScanError(c, err)
+		return
+	}
+
+	resp := &dto.VectorSegmentListResponse{
+		Segments:   make([]*dto.VectorSegmentResponse, 0, len(page.Segments)),
+		NextCursor: page.NextCursor,
+	}
+	for _, s := range page.Segments {
+		resp.Segments = append(resp.Segments, &dto.VectorSegmentResponse{
+			ID:           s.ID,
+			DocID:        s.DocID,
+			SegmentType:  s.SegmentType,
+			TextContent:  s.TextContent,
+			StoryTime:    s.StoryTime,
+			NarrativePos: s.NarrativePos,
+		})
+	}
+	dto.Success(c, resp)
+}
+
+// GetProjectSegmentStats 统计项目已索引的向量片段
+// @Summary 项目向量片段统计
+// @Description 遍历项目向量分区，按 segment_type 统计片段数
+// @Tags Retrieval
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.VectorSegmentStatsResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/vectors/stats [get]
+func (h *RetrievalHandler) GetProjectSegmentStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	if !h.ensureProject(c, projectID) {
+		return
+	}
+
+	counts, err := h.indexer.CountSegments(ctx, tenantID, projectID)
+	if err != nil {
+		h.writeSegmentScanError(c, err)
+		return
+	}
+
+	resp := &dto.VectorSegmentStatsResponse{ProjectID: projectID, ByType: counts}
+	for _, n := range counts {
+		resp.Total += n
+	}
+	dto.Success(c, resp)
+}
+
+// ensureProject 校验项目存在（租户隔离由 RLS 保证）；失败时已写响应
+func (h *RetrievalHandler) ensureProject(c *gin.Context, projectID string) bool {
+	ctx := c.Request.Context()
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return false
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return false
+	}
+	return true
+}
+
+func (h *RetrievalHandler) writeSegmentScanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, retrieval.ErrInvalidSegmentCursor):
+		dto.BadRequest(c, err.Error())
+	case errors.Is(err, retrieval.ErrVectorDisabled), errors.Is(err, retrieval.ErrSegmentScanUnsupported):
+		dto.ServiceUnavailable(c, err.Error())
+	default:
+		logger.Error(c.Request.Context(), "failed to scan project segments", err)
+		dto.InternalError(c, "failed to scan project segments")
+	}
+}
+
+// resolveNarrativePos 将 current_chapter_id 解析为叙事位置；未指定时返回 0（不按叙事位置过滤）。
+func (h *RetrievalHandler) resolveNarrativePos(ctx context.Context, chapterID string) (int64, error) {
+	chapterID = strings.TrimSpace(chapterID)
+	if chapterID == "" || h.chapterRepo == nil {
+		return 0, nil
+	}
+	return h.chapterRepo.GetNarrativePosition(ctx, chapterID)
+}
+
+// resolveSeriesProjects 显式请求 include_series 时返回同系列前作项目 ID（只读召回）。
+func (h *RetrievalHandler) resolveSeriesProjects(ctx context.Context, projectID string, opts *dto.RetrievalOption) ([]string, error) {
+	if opts == nil || !opts.IncludeSeries || h.series == nil {
+		return nil, nil
+	}
+	return h.series.EarlierProjectIDs(ctx, projectID)
 }
 
 func mapSearchOutput(out *retrieval.SearchOutput, elapsed time.Duration) *dto.SearchResponse {
@@ -168,23 +516,7 @@ func mapSearchOutput(out *retrieval.SearchOutput, elapsed time.Duration) *dto.Se
 	}
 
 	for i := range out.Segments {
-		s := out.Segments[i]
-		cs := &dto.ContextSegment{
-			ID:           s.ID,
-			Text:         s.Text,
-			StoryTime:    s.StoryTime,
-			Score:        s.Score,
-			Source:       s.Source,
-			DocType:      s.DocType,
-			Title:        s.ChapterTitle,
-			ArtifactID:   s.ArtifactID,
-			ArtifactType: s.ArtifactType,
-			RefPath:      s.RefPath,
-		}
-		if strings.TrimSpace(s.DocType) == "chapter" {
-			cs.ChapterID = s.ChapterID
-		}
-		resp.Segments = append(resp.Segments, cs)
+		resp.Segments = append(resp.Segments, toContextSegment(out.Segments[i]))
 	}
 	for i := range out.Entities {
 		e := out.Entities[i]
@@ -199,3 +531,79 @@ func mapSearchOutput(out *retrieval.SearchOutput, elapsed time.Duration) *dto.Se
 	resp.Metadata.TotalEntities = len(resp.Entities)
 	return resp
 }
+
+// vectorWeight / keywordWeight 请求指定的混合检索融合权重（未指定返回 0，使用配置默认值）
+func vectorWeight(opts *dto.RetrievalOption) float64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.VectorWeight
+}
+
+func keywordWeight(opts *dto.RetrievalOption) float64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.KeywordWeight
+}
+
+// namespaceQuotas 请求指定的命名空间配额（未指定返回 nil）
+func namespaceQuotas(opts *dto.RetrievalOption) map[string]int {
+	if opts == nil {
+		return nil
+	}
+	return opts.NamespaceQuotas
+}
+
+func toContextSegment(s retrieval.Segment) *dto.ContextSegment {
+	cs := &dto.ContextSegment{
+		ID:           s.ID,
+		Text:         s.Text,
+		StoryTime:    s.StoryTime,
+		NarrativePos: s.NarrativePos,
+		Score:        s.Score,
+		Source:       s.Source,
+		ProjectID:    s.ProjectID,
+		DocType:      s.DocType,
+		Title:        s.ChapterTitle,
+		ArtifactID:   s.ArtifactID,
+		ArtifactType: s.ArtifactType,
+		RefPath:      s.RefPath,
+		BranchKey:    s.BranchKey,
+	}
+	switch strings.TrimSpace(s.DocType) {
+	case "chapter", retrieval.SummarySegmentType:
+		cs.ChapterID = s.ChapterID
+	case retrieval.NotesSegmentType:
+		cs.NoteID = s.NoteID
+		cs.Title = s.NoteTitle
+	}
+	return cs
+}
+
+func toDebugInfo(d *retrieval.DebugInfo) *dto.DebugInfo {
+	if d == nil {
+		return nil
+	}
+	return &dto.DebugInfo{
+		VectorSearchTime:   d.VectorSearchTimeMs,
+		KeywordSearchTime:  0,
+		FusionTime:         0,
+		TotalCandidates:    d.TotalCandidates,
+		FilteredCandidates: d.FilteredCandidates,
+		TimeFilter:         string(d.TimeFilter),
+		VectorTopK:         d.VectorTopK,
+		POVFiltered:        d.POVFiltered,
+		FocusEntityIDs:     d.FocusEntityIDs,
+		FocusBoosted:       d.FocusBoosted,
+		RelationBoosted:    d.RelationBoosted,
+		Partitions:         d.Partitions,
+		BranchKey:          d.BranchKey,
+		KeywordTerms:       d.KeywordTerms,
+		Reranked:           d.Reranked,
+		RerankTime:         d.RerankTimeMs,
+		RerankError:        d.RerankError,
+		NamespaceQuotas:    d.NamespaceQuotas,
+		NamespaceHits:      d.NamespaceHits,
+	}
+}
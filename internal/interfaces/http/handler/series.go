@@ -0,0 +1,410 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	"strings"
+
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesHandler 系列处理器
+type SeriesHandler struct {
+	seriesRepo  repository.SeriesRepository
+	projectRepo repository.ProjectRepository
+	series      *storyseries.SeriesService
+}
+
+// NewSeriesHandler 创建系列处理器
+func NewSeriesHandler(
+	seriesRepo repository.SeriesRepository,
+	projectRepo repository.ProjectRepository,
+	seriesService *storyseries.SeriesService,
+) *SeriesHandler {
+	return &SeriesHandler{
+		seriesRepo:  seriesRepo,
+		projectRepo: projectRepo,
+		series:      seriesService,
+	}
+}
+
+// ListSeries 获取系列列表
+// @Summary 获取系列列表
+// @Description 获取当前租户的系列（多部曲）列表
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Success 200 {object} dto.Response[dto.SeriesListResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series [get]
+func (h *SeriesHandler) ListSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+	pageReq := dto.BindPage(c)
+
+	result, err := h.seriesRepo.List(ctx, repository.NewPagination(pageReq.Page, pageReq.PageSize))
+	if err != nil {
+		logger.Error(ctx, "failed to list series", err)
+		dto.InternalError(c, "failed to list series")
+		return
+	}
+
+	meta := dto.NewPageMeta(pageReq.Page, pageReq.PageSize, int(result.Total))
+	dto.SuccessWithPage(c, dto.ToSeriesListResponse(result.Items), meta)
+}
+
+// CreateSeries 创建系列
+// @Summary 创建系列
+// @Description 创建系列，用于组织多部曲项目
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateSeriesRequest true "系列信息"
+// @Success 201 {object} dto.Response[dto.SeriesResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series [post]
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := middleware.GetUserIDFromGin(c)
+
+	var req dto.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	series := req.ToEntity(tenantID, userID)
+	if err := h.seriesRepo.Create(ctx, series); err != nil {
+		logger.Error(ctx, "failed to create series", err)
+		dto.InternalError(c, "failed to create series")
+		return
+	}
+
+	dto.Created(c, dto.ToSeriesResponse(series, nil))
+}
+
+// GetSeries 获取系列详情
+// @Summary 获取系列详情
+// @Description 获取系列信息及其项目（按系列顺序）
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Success 200 {object} dto.Response[dto.SeriesResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid} [get]
+func (h *SeriesHandler) GetSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+
+	series, err := h.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series", err)
+		dto.InternalError(c, "failed to get series")
+		return
+	}
+	if series == nil {
+		dto.NotFound(c, "series not found")
+		return
+	}
+
+	projects, err := h.seriesRepo.ListProjects(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to list series projects", err)
+		dto.InternalError(c, "failed to get series")
+		return
+	}
+
+	dto.Success(c, dto.ToSeriesResponse(series, projects))
+}
+
+// UpdateSeries 更新系列
+// @Summary 更新系列
+// @Description 更新系列标题或简介
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Param body body dto.UpdateSeriesRequest true "更新内容"
+// @Success 200 {object} dto.Response[dto.SeriesResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid} [put]
+func (h *SeriesHandler) UpdateSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+
+	var req dto.UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	series, err := h.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series", err)
+		dto.InternalError(c, "failed to get series")
+		return
+	}
+	if series == nil {
+		dto.NotFound(c, "series not found")
+		return
+	}
+
+	req.ApplyToSeries(series)
+	if err := h.seriesRepo.Update(ctx, series); err != nil {
+		logger.Error(ctx, "failed to update series", err)
+		dto.InternalError(c, "failed to update series")
+		return
+	}
+
+	dto.Success(c, dto.ToSeriesResponse(series, nil))
+}
+
+// DeleteSeries 删除系列
+// @Summary 删除系列
+// @Description 删除系列；其下项目保留，仅解除系列归属
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid} [delete]
+func (h *SeriesHandler) DeleteSeries(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+
+	series, err := h.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series", err)
+		dto.InternalError(c, "failed to delete series")
+		return
+	}
+	if series == nil {
+		dto.NotFound(c, "series not found")
+		return
+	}
+
+	if err := h.seriesRepo.Delete(ctx, seriesID); err != nil {
+		logger.Error(ctx, "failed to delete series", err)
+		dto.InternalError(c, "failed to delete series")
+		return
+	}
+
+	dto.NoContent(c)
+}
+
+// AttachProject 将项目加入系列
+// @Summary 将项目加入系列
+// @Description 将项目加入系列并设置其在系列中的顺序；项目已属于其他系列时将被移入当前系列
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Param body body dto.AttachSeriesProjectRequest true "项目与顺序"
+// @Success 200 {object} dto.Response[dto.ProjectResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid}/projects [post]
+func (h *SeriesHandler) AttachProject(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+
+	var req dto.AttachSeriesProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	series, err := h.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series", err)
+		dto.InternalError(c, "failed to get series")
+		return
+	}
+	if series == nil {
+		dto.NotFound(c, "series not found")
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, req.ProjectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	project.SeriesID = &series.ID
+	project.SeriesOrder = req.SeriesOrder
+	if err := h.projectRepo.Update(ctx, project); err != nil {
+		logger.Error(ctx, "failed to attach project to series", err)
+		dto.InternalError(c, "failed to attach project")
+		return
+	}
+
+	dto.Success(c, dto.ToProjectResponse(project))
+}
+
+// DetachProject 将项目移出系列
+// @Summary 将项目移出系列
+// @Description 解除项目的系列归属，项目本身不受影响
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Param pid path string true "项目 ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid}/projects/{pid} [delete]
+func (h *SeriesHandler) DetachProject(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+	projectID := dto.BindProjectID(c)
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil || project.SeriesID == nil || *project.SeriesID != seriesID {
+		dto.NotFound(c, "project not found in series")
+		return
+	}
+
+	project.SeriesID = nil
+	project.SeriesOrder = 0
+	if err := h.projectRepo.Update(ctx, project); err != nil {
+		logger.Error(ctx, "failed to detach project from series", err)
+		dto.InternalError(c, "failed to detach project")
+		return
+	}
+
+	dto.NoContent(c)
+}
+
+// GetSeriesStats 获取系列统计
+// @Summary 获取系列统计
+// @Description 汇总系列内各部作品的章节、实体与字数统计
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param srid path string true "系列 ID"
+// @Success 200 {object} dto.Response[dto.SeriesStatsResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/series/{srid}/stats [get]
+func (h *SeriesHandler) GetSeriesStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	seriesID := dto.BindSeriesID(c)
+
+	series, err := h.seriesRepo.GetByID(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series", err)
+		dto.InternalError(c, "failed to get series")
+		return
+	}
+	if series == nil {
+		dto.NotFound(c, "series not found")
+		return
+	}
+
+	stats, err := h.series.GetStats(ctx, seriesID)
+	if err != nil {
+		logger.Error(ctx, "failed to get series stats", err)
+		dto.InternalError(c, "failed to get series stats")
+		return
+	}
+
+	dto.Success(c, &dto.SeriesStatsResponse{
+		SeriesID:       stats.SeriesID,
+		TotalProjects:  stats.TotalProjects,
+		TotalChapters:  stats.TotalChapters,
+		TotalEntities:  stats.TotalEntities,
+		TotalWordCount: stats.TotalWordCount,
+		Projects:       stats.Projects,
+	})
+}
+
+// GetProjectCanon 获取项目生效设定
+// @Summary 获取项目生效设定
+// @Description 获取项目生效的世界观/角色设定：本书有激活版本时使用本书版本，否则继承系列中最近一部前作的版本
+// @Tags Series
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param type path string true "构件类型（worldview | characters）"
+// @Success 200 {object} dto.Response[dto.CanonArtifactResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/canon/{type} [get]
+func (h *SeriesHandler) GetProjectCanon(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+
+	artifactType := entity.ArtifactType(strings.TrimSpace(c.Param("type")))
+	if !entity.IsSeriesInheritable(artifactType) {
+		dto.BadRequest(c, "type must be worldview or characters")
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	canon, err := h.series.ResolveCanon(ctx, project, artifactType)
+	if err != nil {
+		logger.Error(ctx, "failed to resolve canon artifact", err)
+		dto.InternalError(c, "failed to resolve canon artifact")
+		return
+	}
+	if canon == nil || canon.Version == nil {
+		dto.NotFound(c, "canon artifact not found")
+		return
+	}
+
+	dto.Success(c, &dto.CanonArtifactResponse{
+		Type:            string(canon.Type),
+		SourceProjectID: canon.SourceProjectID,
+		Inherited:       canon.Inherited,
+		VersionID:       canon.Version.ID,
+		VersionNo:       canon.Version.VersionNo,
+		Content:         canon.Version.Content,
+	})
+}
@@ -0,0 +1,193 @@
+// Package handler 提供 HTTP 请求处理器
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpoilerGuardHandler 剧透保护处理器
+type SpoilerGuardHandler struct {
+	guardRepo   repository.SpoilerGuardRepository
+	projectRepo repository.ProjectRepository
+	chapterRepo repository.ChapterRepository
+	spoilers    *spoiler.Service
+}
+
+// NewSpoilerGuardHandler 创建剧透保护处理器
+func NewSpoilerGuardHandler(
+	guardRepo repository.SpoilerGuardRepository,
+	projectRepo repository.ProjectRepository,
+	chapterRepo repository.ChapterRepository,
+	spoilers *spoiler.Service,
+) *SpoilerGuardHandler {
+	return &SpoilerGuardHandler{
+		guardRepo:   guardRepo,
+		projectRepo: projectRepo,
+		chapterRepo: chapterRepo,
+		spoilers:    spoilers,
+	}
+}
+
+// ListSpoilerGuards 获取项目剧透保护列表
+// @Summary 获取项目剧透保护列表
+// @Description 获取项目下全部剧透保护（揭晓点之前的章节不得出现的实体/事实/事件）
+// @Tags SpoilerGuards
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Success 200 {object} dto.Response[dto.SpoilerGuardListResponse]
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/spoiler-guards [get]
+func (h *SpoilerGuardHandler) ListSpoilerGuards(c *gin.Context) {
+	ctx := c.Request.Context()
+	projectID := dto.BindProjectID(c)
+
+	guards, err := h.guardRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to list spoiler guards", err)
+		dto.InternalError(c, "failed to list spoiler guards")
+		return
+	}
+
+	dto.Success(c, dto.ToSpoilerGuardListResponse(guards))
+}
+
+// CreateSpoilerGuard 创建剧透保护
+// @Summary 创建剧透保护
+// @Description 指定揭晓点（章节或卷，二选一）之前不得出现的实体/事实/事件。生成时剔除相关召回片段并注入否定约束，生成后扫描并标记违规。
+// @Description entity 需 ref_id（名称与别名自动参与扫描）；fact / event 需提供 keywords；event 可选关联 ref_id
+// @Tags SpoilerGuards
+// @Accept json
+// @Produce json
+// @Param pid path string true "项目 ID"
+// @Param body body dto.CreateSpoilerGuardRequest true "剧透保护"
+// @Success 201 {object} dto.Response[dto.SpoilerGuardResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/projects/{pid}/spoiler-guards [post]
+func (h *SpoilerGuardHandler) CreateSpoilerGuard(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+	projectID := dto.BindProjectID(c)
+
+	var req dto.CreateSpoilerGuardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		logger.Error(ctx, "failed to get project", err)
+		dto.InternalError(c, "failed to get project")
+		return
+	}
+	if project == nil {
+		dto.NotFound(c, "project not found")
+		return
+	}
+
+	guard := req.ToEntity(tenantID, projectID)
+	if err := h.spoilers.Normalize(ctx, guard); err != nil {
+		if stderrors.Is(err, spoiler.ErrInvalidGuard) {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		logger.Error(ctx, "failed to validate spoiler guard", err)
+		dto.InternalError(c, "failed to create spoiler guard")
+		return
+	}
+
+	if err := h.guardRepo.Create(ctx, guard); err != nil {
+		logger.Error(ctx, "failed to create spoiler guard", err)
+		dto.InternalError(c, "failed to create spoiler guard")
+		return
+	}
+
+	dto.Created(c, dto.ToSpoilerGuardResponse(guard))
+}
+
+// DeleteSpoilerGuard 删除剧透保护
+// @Summary 删除剧透保护
+// @Description 删除指定剧透保护
+// @Tags SpoilerGuards
+// @Accept json
+// @Produce json
+// @Param gid path string true "剧透保护 ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/spoiler-guards/{gid} [delete]
+func (h *SpoilerGuardHandler) DeleteSpoilerGuard(c *gin.Context) {
+	ctx := c.Request.Context()
+	guardID := c.Param("gid")
+
+	guard, err := h.guardRepo.GetByID(ctx, guardID)
+	if err != nil {
+		logger.Error(ctx, "failed to get spoiler guard", err)
+		dto.InternalError(c, "failed to get spoiler guard")
+		return
+	}
+	if guard == nil {
+		dto.NotFound(c, "spoiler guard not found")
+		return
+	}
+
+	if err := h.guardRepo.Delete(ctx, guard.ID); err != nil {
+		logger.Error(ctx, "failed to delete spoiler guard", err)
+		dto.InternalError(c, "failed to delete spoiler guard")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CheckChapter 扫描章节正文中的剧透
+// @Summary 扫描章节剧透
+// @Description 按章节叙事位置计算生效的剧透保护，扫描当前正文并返回命中项（适用于手动编辑后的复查）
+// @Tags SpoilerGuards
+// @Accept json
+// @Produce json
+// @Param cid path string true "章节 ID"
+// @Success 200 {object} dto.Response[dto.SpoilerCheckResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/chapters/{cid}/spoiler-check [get]
+func (h *SpoilerGuardHandler) CheckChapter(c *gin.Context) {
+	ctx := c.Request.Context()
+	chapterID := dto.BindChapterID(c)
+
+	chapter, err := h.chapterRepo.GetByID(ctx, chapterID)
+	if err != nil {
+		logger.Error(ctx, "failed to get chapter", err)
+		dto.InternalError(c, "failed to get chapter")
+		return
+	}
+	if chapter == nil {
+		dto.NotFound(c, "chapter not found")
+		return
+	}
+
+	constraints, err := h.spoilers.ForChapter(ctx, chapter)
+	if err != nil {
+		logger.Error(ctx, "failed to load spoiler guards", err)
+		dto.InternalError(c, "failed to check chapter")
+		return
+	}
+
+	dto.Success(c, dto.ToSpoilerCheckResponse(chapter.ID, len(constraints.Guards), constraints.Scan(chapter.ContentText)))
+}
@@ -7,22 +7,27 @@ import (
 	stderrors "errors"
 	"fmt"
 	"io"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"z-novel-ai-api/internal/application/quota"
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
 	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	storyspoiler "z-novel-ai-api/internal/application/story/spoiler"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/domain/service"
+	grpcclient "z-novel-ai-api/internal/interfaces/grpc/client"
 	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 	wfmodel "z-novel-ai-api/internal/workflow/model"
 	"z-novel-ai-api/pkg/logger"
 
+	"github.com/cloudwego/eino/schema"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -37,11 +42,23 @@ type StreamHandler struct {
 
 	txMgr     repository.Transactor
 	tenantCtx repository.TenantContextManager
+	locker    repository.ProjectLocker
 
 	quotaChecker *quota.TokenQuotaChecker
 	generator    *storychapter.ChapterGenerator
-	indexer      *appretrieval.Indexer
+	finalizer    *appstory.GenerationFinalizer
 	retrieval    *appretrieval.Engine
+	series       *storyseries.SeriesService
+	jobTimeline  *appstory.JobTimeline
+	contextPins  *appstory.ContextPinService
+	canon        *appstory.CanonContextService
+	spoilers     *storyspoiler.Service
+	titles       *appstory.ChapterTitleService
+
+	// remote 非 nil 时章节流经 StoryGen gRPC 服务生成
+	remote *grpcclient.StoryGenStreamer
+	// progress 异步任务实时进度订阅
+	progress appstory.JobProgressBus
 }
 
 // NewStreamHandler 创建流式响应处理器
@@ -54,8 +71,17 @@ func NewStreamHandler(
 	tenantCtx repository.TenantContextManager,
 	quotaChecker *quota.TokenQuotaChecker,
 	generator *storychapter.ChapterGenerator,
-	indexer *appretrieval.Indexer,
+	finalizer *appstory.GenerationFinalizer,
 	retrievalEngine *appretrieval.Engine,
+	seriesService *storyseries.SeriesService,
+	locker repository.ProjectLocker,
+	jobTimeline *appstory.JobTimeline,
+	contextPins *appstory.ContextPinService,
+	canon *appstory.CanonContextService,
+	spoilers *storyspoiler.Service,
+	remote *grpcclient.StoryGenStreamer,
+	titles *appstory.ChapterTitleService,
+	progress appstory.JobProgressBus,
 ) *StreamHandler {
 	return &StreamHandler{
 		cfg:          cfg,
@@ -66,33 +92,46 @@ func NewStreamHandler(
 		tenantCtx:    tenantCtx,
 		quotaChecker: quotaChecker,
 		generator:    generator,
-		indexer:      indexer,
+		finalizer:    finalizer,
 		retrieval:    retrievalEngine,
+		series:       seriesService,
+		locker:       locker,
+		jobTimeline:  jobTimeline,
+		contextPins:  contextPins,
+		canon:        canon,
+		spoilers:     spoilers,
+		remote:       remote,
+		titles:       titles,
+		progress:     progress,
 	}
 }
 
 // StreamChapter 流式获取章节内容
 // @Summary 流式获取章节内容
-// @Description 通过 SSE 流式获取章节生成内容
+// @Description 通过 SSE 流式获取章节生成内容（content/progress/context/warning/done/error，事件协议见 dto.StreamEvent）
 // @Tags Chapters
 // @Accept json
 // @Produce text/event-stream
 // @Param cid path string true "章节 ID"
-// @Success 200 "SSE stream"
+// @Param provider query string false "LLM 提供商"
+// @Param model query string false "模型"
+// @Param temperature query number false "采样温度"
+// @Param target_word_count query int false "目标字数"
+// @Param resume_job_id query string false "续写被中断的流式任务（状态 cancelled_partial）保存的部分内容"
+// @Success 200 {object} dto.StreamEvent "SSE stream"
+// @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse "任务没有可续写的部分内容"
+// @Failure 429 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/chapters/{cid}/stream [get]
 func (h *StreamHandler) StreamChapter(c *gin.Context) {
 	ctx := c.Request.Context()
 	tenantID := middleware.GetTenantIDFromGin(c)
+	userID := middleware.GetUserIDFromGin(c)
 	chapterID := dto.BindChapterID(c)
 
-	provider, model, err := resolveProviderModel(h.cfg, strings.TrimSpace(c.Query("provider")), strings.TrimSpace(c.Query("model")))
-	if err != nil {
-		dto.BadRequest(c, err.Error())
-		return
-	}
-
 	var temperature *float32
 	if s := strings.TrimSpace(c.Query("temperature")); s != "" {
 		f, err := strconv.ParseFloat(s, 32)
@@ -113,9 +152,11 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 		}
 		targetWordCount = i
 	}
+	resumeJobID := strings.TrimSpace(c.Query("resume_job_id"))
 
 	var chapter *entity.Chapter
 	var project *entity.Project
+	var resume *streamResume
 	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		var loadErr error
 		chapter, loadErr = h.chapterRepo.GetByID(txCtx, chapterID)
@@ -123,10 +164,22 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 			return loadErr
 		}
 		project, loadErr = h.projectRepo.GetByID(txCtx, chapter.ProjectID)
+		if loadErr != nil || resumeJobID == "" {
+			return loadErr
+		}
+		resume, loadErr = h.loadResume(txCtx, resumeJobID, chapter.ID)
 		return loadErr
 	}); err != nil {
-		logger.Error(ctx, "failed to load chapter for stream", err)
-		dto.InternalError(c, "failed to stream chapter")
+		var stateErr candidateStateError
+		switch {
+		case isNotFound(err):
+			dto.NotFound(c, err.Error())
+		case stderrors.As(err, &stateErr):
+			dto.Conflict(c, stateErr.Error())
+		default:
+			logger.Error(ctx, "failed to load chapter for stream", err)
+			dto.InternalError(c, "failed to stream chapter")
+		}
 		return
 	}
 	if chapter == nil {
@@ -138,17 +191,10 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 		return
 	}
 
-	if h.quotaChecker != nil {
-		if _, err := h.quotaChecker.CheckBalance(ctx, tenantID, 1000); err != nil {
-			var exceeded quota.TokenBalanceExceededError
-			if stderrors.As(err, &exceeded) {
-				dto.Error(c, http.StatusTooManyRequests, "token balance insufficient")
-				return
-			}
-			logger.Error(ctx, "quota check failed", err)
-			dto.InternalError(c, "quota check failed")
-			return
-		}
+	provider, model, err := resolveProjectProviderModel(h.cfg, project.Settings, strings.TrimSpace(c.Query("provider")), strings.TrimSpace(c.Query("model")))
+	if err != nil {
+		dto.BadRequest(c, err.Error())
+		return
 	}
 
 	outline := strings.TrimSpace(chapter.Outline)
@@ -157,11 +203,9 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 		return
 	}
 
-	writingStyle := ""
-	pov := ""
+	// 多 POV：章节指定 POV 角色时优先使用该角色的专属风格
+	writingStyle, pov := project.Settings.ResolveStyle(chapter.POVEntity())
 	if project.Settings != nil {
-		writingStyle = strings.TrimSpace(project.Settings.WritingStyle)
-		pov = strings.TrimSpace(project.Settings.POV)
 		if temperature == nil && project.Settings.Temperature != 0 {
 			t := float32(project.Settings.Temperature)
 			temperature = &t
@@ -174,10 +218,15 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 		targetWordCount = 2000
 	}
 
-	if h.generator == nil {
+	if h.generator == nil && h.remote == nil {
 		dto.InternalError(c, "chapter generator not configured")
 		return
 	}
+	// 续写需要在 Prompt 中附带已有正文，远端生成服务的协议不含该字段，仅支持网关进程内生成
+	if resume != nil && h.generator == nil {
+		dto.Conflict(c, "resuming partial content requires the in-process chapter generator")
+		return
+	}
 
 	jobID := uuid.NewString()
 	now := time.Now()
@@ -185,86 +234,216 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 		"mode":              "stream",
 		"project_id":        chapter.ProjectID,
 		"chapter_id":        chapter.ID,
+		"base_version":      chapter.Version,
 		"outline":           outline,
 		"target_word_count": targetWordCount,
 		"provider":          provider,
 		"model":             model,
 		"temperature":       temperature,
+		"resume_job_id":     resumeJobID,
 	})
 	job := entity.NewGenerationJob(tenantID, chapter.ProjectID, entity.JobTypeChapterGen, inputParams)
 	job.ID = jobID
 	job.JobType = entity.JobTypeChapterGen
+	job.Priority = entity.DefaultPriority(entity.JobTypeChapterGen, entity.JobTriggerInteractive)
 	job.ChapterID = &chapter.ID
 	job.Status = entity.JobStatusRunning
 	job.StartedAt = &now
 	job.Progress = 1
-
+	ctx = service.WithUsageAttribution(ctx, service.UsageAttribution{ProjectID: job.ProjectID, JobID: job.ID, JobType: string(job.JobType)})
+
+	var narrativePos int64
+	var seriesProjectIDs []string
+	var focusEntityIDs []string
+	var pinnedContext string
+	var canon appstory.CanonExcerpts
+	var spoilers *storyspoiler.Constraints
 	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		// 共享锁：与 Foundation 落库/重排串行；仅更新状态列，避免以过期的卷/序号覆盖重排结果
+		if err := h.locker.LockProjectShared(txCtx, chapter.ProjectID); err != nil {
+			return err
+		}
 		if err := h.jobRepo.Create(txCtx, job); err != nil {
 			return err
 		}
-		chapter.Status = entity.ChapterStatusGenerating
-		return h.chapterRepo.Update(txCtx, chapter)
+		if err := h.quotaChecker.CheckFeature(txCtx, tenantID, entity.PlanFeatureChapterStream); err != nil {
+			return err
+		}
+		if err := h.quotaChecker.Reserve(txCtx, tenantID, job.ID, quota.ChapterReserveTokens(targetWordCount)); err != nil {
+			return err
+		}
+		chapter.Status = appstory.ChapterStatusForJob(job.Status, "")
+		if err := h.chapterRepo.UpdateStatus(txCtx, chapter.ID, chapter.Status); err != nil {
+			return err
+		}
+		pos, posErr := h.chapterRepo.GetNarrativePosition(txCtx, chapter.ID)
+		if posErr != nil {
+			logger.Warn(txCtx, "failed to get chapter narrative position", "error", posErr.Error(), "chapter_id", chapter.ID)
+		}
+		narrativePos = pos
+		ids, seriesErr := h.series.RetrievalProjectIDs(txCtx, project)
+		if seriesErr != nil {
+			logger.Warn(txCtx, "failed to resolve series retrieval scope", "error", seriesErr.Error(), "project_id", project.ID)
+		}
+		seriesProjectIDs = ids
+		if h.retrieval != nil {
+			focusIDs, focusErr := h.retrieval.DetectFocusEntities(txCtx, project.ID, chapter.Title+"\n"+outline)
+			if focusErr != nil {
+				logger.Warn(txCtx, "failed to detect outline entities", "error", focusErr.Error(), "chapter_id", chapter.ID)
+			}
+			focusEntityIDs = focusIDs
+		}
+		pinnedContext = h.contextPins.PromptContext(txCtx, chapter)
+		canon = h.canon.ForChapter(txCtx, project, chapter.Title, outline)
+		constraints, spoilerErr := h.spoilers.ForChapter(txCtx, chapter)
+		if spoilerErr != nil {
+			logger.Warn(txCtx, "failed to load spoiler guards", "error", spoilerErr.Error(), "chapter_id", chapter.ID)
+			if err := h.jobRepo.AppendWarnings(txCtx, job.ID, appstory.SpoilerGuardWarning()); err != nil {
+				return err
+			}
+		}
+		spoilers = constraints
+		return nil
 	}); err != nil {
+		if writeQuotaLimitError(c, err) {
+			return
+		}
 		logger.Error(ctx, "failed to prepare chapter stream job", err)
 		dto.InternalError(c, "failed to create job")
 		return
 	}
 
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no")
+	sse := dto.NewStreamWriter(c)
 
 	contentCh := make(chan string, 16)
+	noticeCh := make(chan dto.StreamNotice, 4)
 	doneCh := make(chan *wfmodel.ChapterGenerateOutput, 1)
-	errCh := make(chan error, 1)
+	errCh := make(chan dto.StreamErrorData, 1)
 
 	go func() {
 		defer close(contentCh)
+		defer close(noticeCh)
 		defer close(doneCh)
 		defer close(errCh)
 
-		start := time.Now()
+		// 客户端断开后 c.Stream 不再读取，发送方需同时监听 ctx，避免阻塞生成协程
+		notify := func(n dto.StreamNotice) {
+			select {
+			case noticeCh <- n:
+			case <-ctx.Done():
+			}
+		}
+		// 客户端断开后收尾仍需落库，改用不随请求取消的上下文
+		persistCtx := context.WithoutCancel(ctx)
+
 		retrievedContext := ""
+		var retrievalTruncated []entity.PromptTruncation
 		if h.retrieval != nil {
+			notify(dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageRetrieval, Progress: 5}})
 			retrievalCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			ro, rerr := h.retrieval.Search(retrievalCtx, appretrieval.SearchInput{
-				TenantID:         tenantID,
-				ProjectID:        chapter.ProjectID,
-				Query:            outline,
-				CurrentStoryTime: chapter.StoryTimeStart,
-				TopK:             12,
-				IncludeEntities:  false,
-			})
+			searchIn := appretrieval.ChapterSearchInput(tenantID, project.Settings, chapter, outline, narrativePos, seriesProjectIDs)
+			searchIn.FocusEntityIDs = focusEntityIDs
+			ro, rerr := h.retrieval.Search(retrievalCtx, searchIn)
 			cancel()
-			if rerr == nil && ro != nil && len(ro.Segments) > 0 {
-				retrievedContext = appretrieval.BuildPromptContext(ro.Segments, 10, 360)
+			if rerr == nil && ro != nil {
+				segments := spoilers.FilterSegments(ro.Segments)
+				if len(segments) > 0 {
+					retrievedContext = appretrieval.BuildPromptContext(segments, appretrieval.PromptMaxSegments, appretrieval.PromptMaxRunesPerSegment)
+					retrievalTruncated = appstory.RetrievalTruncations(segments)
+				}
+				h.recordJobEvent(ctx, tenantID, job, entity.JobEventRAGRetrieved, "context retrieved", map[string]any{"segments": len(segments), "spoiler_excluded": len(ro.Segments) - len(segments)})
+				notify(dto.StreamNotice{Type: dto.StreamEventContext, Data: dto.StreamContextData{
+					Segments:   len(segments),
+					Chars:      len([]rune(retrievedContext)),
+					Truncation: dto.ToTruncationReportResponse(entity.NewTruncationReport(retrievalTruncated...)),
+				}})
+			}
+			if rerr != nil {
+				// 检索失败不阻断生成，仅提示上下文缺失
+				notify(dto.StreamNotice{Type: dto.StreamEventWarning, Data: dto.StreamWarningData{Code: dto.StreamCodeRetrievalFailed, Message: "context retrieval failed, generating without retrieved context"}})
+				h.appendJobWarning(ctx, tenantID, jobID, appstory.RetrievalFailedWarning())
 			}
 		}
 
-		reader, streamErr := h.generator.Stream(ctx, &wfmodel.ChapterGenerateInput{
+		// 作者固定的上下文不受召回结果影响，始终注入（置于召回上下文之前）
+		retrievedContext = appstory.JoinPromptContext(pinnedContext, retrievedContext)
+		retrievedContext = appstory.JoinPromptContext(retrievedContext, spoilers.PromptBlock())
+
+		notify(dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageGenerating, Progress: 10}})
+		h.recordJobEvent(ctx, tenantID, job, entity.JobEventLLMStarted, "chapter stream started", map[string]any{"provider": provider, "model": model, "remote": h.remote != nil, "resume_job_id": resumeJobID})
+
+		in := &wfmodel.ChapterGenerateInput{
 			ProjectTitle:       project.Title,
 			ProjectDescription: project.Description,
 			ChapterTitle:       chapter.Title,
 			ChapterOutline:     outline,
 			RetrievedContext:   retrievedContext,
+			ActiveWorldview:    canon.Worldview,
+			ActiveCharacters:   canon.Characters,
 			TargetWordCount:    targetWordCount,
 			WritingStyle:       writingStyle,
 			POV:                pov,
 			Provider:           provider,
 			Model:              model,
 			Temperature:        temperature,
-		})
-		if streamErr != nil {
-			errCh <- streamErr
-			_ = h.markJobFailed(ctx, tenantID, jobID, chapter.ID, streamErr, int(time.Since(start).Milliseconds()))
-			return
 		}
-		defer reader.Close()
+		if resume != nil {
+			in.ContinueFrom = resume.candidate.Content
+		}
+		h.saveInputSnapshot(ctx, tenantID, jobID, in)
 
 		var raw strings.Builder
 		var usage *wfmodel.LLMUsageMeta
+		chunks := 0
+		output := func() *wfmodel.ChapterGenerateOutput {
+			out := &wfmodel.ChapterGenerateOutput{
+				Content: strings.TrimSpace(raw.String()),
+				Meta: wfmodel.LLMUsageMeta{
+					Provider:    provider,
+					Model:       model,
+					GeneratedAt: time.Now().UTC(),
+				},
+			}
+			if temperature != nil {
+				out.Meta.Temperature = float64(*temperature)
+			}
+			if usage != nil {
+				out.Meta = *usage
+			}
+			return out
+		}
+		// 客户端断开（非生成超时）：保存已生成的部分内容，任务记为 cancelled_partial
+		cancelled := func(genErr error) bool {
+			if ctx.Err() == nil || appstory.IsGenerationTimeout(genErr) {
+				return false
+			}
+			if err := h.markJobCancelled(persistCtx, tenantID, jobID, chapter.ID, output(), resume); err != nil {
+				logger.Error(persistCtx, "failed to save partial chapter stream", err)
+			}
+			return true
+		}
+		// 续写时先回放已有正文，客户端看到的是完整章节
+		if resume != nil {
+			raw.WriteString(resume.candidate.Content)
+			select {
+			case contentCh <- resume.candidate.Content:
+			case <-ctx.Done():
+			}
+		}
+
+		genCtx, finishGen := appstory.WithGenerationTimeout(ctx, appstory.StageChapterGen, h.cfg.Story.GenerationTimeouts.ChapterGen)
+		defer finishGen(nil)
+		reader, streamErr := h.openChapterStream(genCtx, tenantID, userID, chapter.ID, in)
+		if streamErr != nil {
+			streamErr = finishGen(streamErr)
+			if cancelled(streamErr) {
+				return
+			}
+			errCh <- streamGenerationError(streamErr)
+			_ = h.markJobFailed(persistCtx, tenantID, jobID, chapter.ID, streamErr)
+			return
+		}
+		defer reader.Close()
 
 		for {
 			msg, recvErr := reader.Recv()
@@ -272,14 +451,22 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 				break
 			}
 			if recvErr != nil {
-				errCh <- recvErr
-				_ = h.markJobFailed(ctx, tenantID, jobID, chapter.ID, recvErr, int(time.Since(start).Milliseconds()))
+				recvErr = finishGen(recvErr)
+				if cancelled(recvErr) {
+					return
+				}
+				errCh <- streamGenerationError(recvErr)
+				_ = h.markJobFailed(persistCtx, tenantID, jobID, chapter.ID, recvErr)
 				return
 			}
 
 			if msg.Content != "" {
 				raw.WriteString(msg.Content)
-				contentCh <- msg.Content
+				chunks++
+				select {
+				case contentCh <- msg.Content:
+				case <-ctx.Done():
+				}
 			}
 
 			if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
@@ -298,74 +485,67 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 			}
 		}
 
-		out := &wfmodel.ChapterGenerateOutput{
-			Content: strings.TrimSpace(raw.String()),
-			Meta: wfmodel.LLMUsageMeta{
-				Provider:    provider,
-				Model:       model,
-				GeneratedAt: time.Now().UTC(),
-			},
-		}
-		if temperature != nil {
-			out.Meta.Temperature = float64(*temperature)
-		}
-		if usage != nil {
-			out.Meta = *usage
+		out := output()
+
+		// 剧透扫描仅告警，不阻断落库（由作者决定是否重生成）
+		if violations := spoilers.Scan(out.Content); len(violations) > 0 {
+			summary := storyspoiler.Summary(violations)
+			h.recordJobEvent(ctx, tenantID, job, entity.JobEventSpoilerFlagged, summary, map[string]any{"violations": violations})
+			notify(dto.StreamNotice{Type: dto.StreamEventWarning, Data: dto.StreamWarningData{Code: dto.StreamCodeSpoilerViolation, Message: summary}})
 		}
 
-		if err := h.markJobCompleted(ctx, tenantID, jobID, chapter.ID, out, int(time.Since(start).Milliseconds())); err != nil {
-			errCh <- err
+		notify(dto.StreamNotice{Type: dto.StreamEventProgress, Data: dto.StreamProgressData{Stage: dto.StreamStageSaving, Progress: 95}})
+		chForIndex, err := h.markJobCompleted(persistCtx, tenantID, jobID, chapter.ID, out, chunks, resume, retrievalTruncated)
+		if err != nil {
+			errCh <- dto.StreamErrorData{Code: dto.StreamCodePersistFailed, Message: err.Error()}
 			return
 		}
 
-		// 同步写索引：章节落库完成后写入向量索引（失败不影响主流程）
-		if h.indexer != nil {
-			indexCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-			chForIndex := &entity.Chapter{
-				ID:             chapter.ID,
-				ProjectID:      chapter.ProjectID,
-				Title:          chapter.Title,
-				ContentText:    out.Content,
-				StoryTimeStart: chapter.StoryTimeStart,
-				StoryTimeEnd:   chapter.StoryTimeEnd,
-			}
-			if err := h.indexer.IndexChapter(indexCtx, tenantID, chapter.ProjectID, chForIndex); err != nil && !stderrors.Is(err, appretrieval.ErrVectorDisabled) {
-				logger.Warn(ctx, "failed to index chapter after stream",
-					"error", err.Error(),
-					"chapter_id", chapter.ID,
-				)
-			}
+		// 同步写索引：章节落库完成后写入向量索引（失败不影响主流程，仅记录任务警告）
+		if err := h.finalizer.IndexChapter(persistCtx, tenantID, chForIndex); err != nil {
+			h.appendJobWarning(persistCtx, tenantID, jobID, appstory.IndexFailedWarning())
 		}
 
 		doneCh <- out
+
+		// 占位标题的章节自动提出标题建议：在 done 事件之后执行，客户端断开不影响（失败仅记录日志）
+		titleCtx := context.WithoutCancel(ctx)
+		if err := h.titles.SuggestAfterGeneration(titleCtx, tenantID, chForIndex, out.Meta.Provider, out.Meta.Model); err != nil {
+			logger.Warn(titleCtx, "failed to suggest chapter titles", "error", err.Error(), "chapter_id", chapter.ID)
+		}
 	}()
 
-	index := 0
 	c.Stream(func(w io.Writer) bool {
 		select {
 		case chunk, ok := <-contentCh:
 			if !ok {
 				return false
 			}
-			c.SSEvent("content", gin.H{"chunk": chunk, "index": index})
-			index++
+			sse.Content(chunk)
+			return true
+
+		case notice, ok := <-noticeCh:
+			if !ok {
+				noticeCh = nil
+				return true
+			}
+			sse.Notice(notice)
 			return true
 
 		case out, ok := <-doneCh:
 			if !ok || out == nil {
 				return false
 			}
-			c.SSEvent("done", gin.H{
-				"job_id":     jobID,
-				"chapter_id": chapter.ID,
-				"word_count": len([]rune(out.Content)),
+			sse.Done(dto.StreamDoneData{
+				JobID:     jobID,
+				ChapterID: chapter.ID,
+				WordCount: len([]rune(out.Content)),
 			})
 			return false
 
 		case streamErr, ok := <-errCh:
-			if ok && streamErr != nil {
-				c.SSEvent("error", gin.H{"message": streamErr.Error()})
+			if ok {
+				sse.Error(streamErr.Code, streamErr.Message)
 			}
 			return false
 
@@ -375,116 +555,80 @@ func (h *StreamHandler) StreamChapter(c *gin.Context) {
 	})
 }
 
-// StreamGenerate 流式生成内容（内部方法）
-// 用于实际的生成流程，由 GenerationService 调用
-func (h *StreamHandler) StreamGenerate(c *gin.Context, contentChan <-chan string, metaChan <-chan map[string]interface{}, errChan <-chan error) {
-	// 设置 SSE 响应头
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no")
-
-	index := 0
-
-	c.Stream(func(w io.Writer) bool {
-		select {
-		case chunk, ok := <-contentChan:
-			if !ok {
-				// 内容通道关闭
-				return false
-			}
-			c.SSEvent("content", gin.H{
-				"chunk": chunk,
-				"index": index,
-			})
-			index++
-			return true
-
-		case meta, ok := <-metaChan:
-			if !ok {
-				return true // 元数据通道关闭，继续等待内容
-			}
-			c.SSEvent("metadata", meta)
-			return true
-
-		case err, ok := <-errChan:
-			if !ok {
-				return true // 错误通道关闭
-			}
-			c.SSEvent("error", gin.H{
-				"message": err.Error(),
-			})
-			return false
-
-		case <-c.Request.Context().Done():
-			// 客户端断开
-			return false
-		}
-	})
+// openChapterStream 启用远端生成服务时经 gRPC 代理流式生成，否则在网关进程内调用模型
+func (h *StreamHandler) openChapterStream(ctx context.Context, tenantID, userID, chapterID string, in *wfmodel.ChapterGenerateInput) (*schema.StreamReader[*schema.Message], error) {
+	if h.remote != nil {
+		return h.remote.StreamChapter(ctx, tenantID, userID, chapterID, in)
+	}
+	return h.generator.Stream(ctx, in)
 }
 
-func (h *StreamHandler) markJobFailed(ctx context.Context, tenantID, jobID, chapterID string, err error, durationMs int) error {
+func (h *StreamHandler) markJobFailed(ctx context.Context, tenantID, jobID, chapterID string, err error) error {
 	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		job, getErr := h.jobRepo.GetByID(txCtx, jobID)
 		if getErr != nil || job == nil {
 			return getErr
 		}
-		job.Status = entity.JobStatusFailed
-		job.ErrorMessage = err.Error()
-		now := time.Now()
-		job.CompletedAt = &now
-		job.DurationMs = durationMs
-		job.Progress = 100
-		if updateErr := h.jobRepo.Update(txCtx, job); updateErr != nil {
-			return updateErr
+		if lockErr := h.locker.LockProjectShared(txCtx, job.ProjectID); lockErr != nil {
+			return lockErr
 		}
+		return h.finalizer.FailChapter(txCtx, job, chapterID, err)
+	})
+}
 
-		if strings.TrimSpace(chapterID) == "" {
-			return nil
-		}
-		ch, getErr := h.chapterRepo.GetByID(txCtx, chapterID)
-		if getErr != nil {
-			logger.Warn(txCtx, "failed to load chapter for stream failure", "error", getErr.Error())
-			return nil
-		}
-		if ch == nil {
-			return nil
-		}
-		if ch.Status == entity.ChapterStatusGenerating {
-			ch.Status = entity.ChapterStatusDraft
-			if err := h.chapterRepo.Update(txCtx, ch); err != nil {
-				logger.Warn(txCtx, "failed to update chapter status for stream failure", "error", err.Error())
-			}
-		}
+// streamGenerationError 生成失败的流式错误事件（超时单独标识，便于客户端提示缩短篇幅或稍后重试）
+func streamGenerationError(err error) dto.StreamErrorData {
+	code := dto.StreamCodeGenerationFailed
+	if appstory.IsGenerationTimeout(err) {
+		code = dto.StreamCodeGenerationTimeout
+	}
+	return dto.StreamErrorData{Code: code, Message: err.Error()}
+}
+
+// saveInputSnapshot 在独立短事务中记录实际送入模型的输入（失败仅记录日志）
+func (h *StreamHandler) saveInputSnapshot(ctx context.Context, tenantID, jobID string, in *wfmodel.ChapterGenerateInput) {
+	snapshot := storychapter.MarshalInputSnapshot(in)
+	if snapshot == nil {
+		return
+	}
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		return h.jobRepo.UpdateInputSnapshot(txCtx, jobID, snapshot)
+	}); err != nil {
+		logger.Warn(ctx, "failed to save job input snapshot", "error", err.Error(), "job_id", jobID)
+	}
+}
+
+// appendJobWarning 在独立短事务中追加任务警告（失败仅记录日志）
+func (h *StreamHandler) appendJobWarning(ctx context.Context, tenantID, jobID string, warnings ...entity.JobWarning) {
+	if err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		return h.jobRepo.AppendWarnings(txCtx, jobID, warnings...)
+	}); err != nil {
+		logger.Warn(ctx, "failed to record job warning", "error", err.Error(), "job_id", jobID)
+	}
+}
+
+// recordJobEvent 在独立短事务中追加任务事件（SSE 路径生成过程不持有事务）
+func (h *StreamHandler) recordJobEvent(ctx context.Context, tenantID string, job *entity.GenerationJob, eventType entity.JobEventType, message string, data map[string]any) {
+	_ = withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		h.jobTimeline.Record(txCtx, job, eventType, message, data)
 		return nil
 	})
 }
 
-func (h *StreamHandler) markJobCompleted(ctx context.Context, tenantID, jobID, chapterID string, out *wfmodel.ChapterGenerateOutput, durationMs int) error {
-	if out == nil {
-		return fmt.Errorf("chapter output is nil")
-	}
-	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+func (h *StreamHandler) markJobCompleted(ctx context.Context, tenantID, jobID, chapterID string, out *wfmodel.ChapterGenerateOutput, chunks int, resume *streamResume, truncated []entity.PromptTruncation) (*appstory.ChapterIndexSnapshot, error) {
+	var chForIndex *appstory.ChapterIndexSnapshot
+	err := withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
 		job, err := h.jobRepo.GetByID(txCtx, jobID)
-		if err != nil || job == nil {
+		if err != nil {
 			return err
 		}
-
-		resultBytes, _ := json.Marshal(map[string]any{
-			"chapter_id": chapterID,
-			"word_count": len([]rune(out.Content)),
-		})
-		job.OutputResult = resultBytes
-		job.Status = entity.JobStatusCompleted
-		now := time.Now()
-		job.CompletedAt = &now
-		job.DurationMs = durationMs
-		job.Progress = 100
-		job.SetLLMMetrics(out.Meta.Provider, out.Meta.Model, out.Meta.PromptTokens, out.Meta.CompletionTokens)
-		if err := h.jobRepo.Update(txCtx, job); err != nil {
+		if job == nil {
+			return fmt.Errorf("job not found: %s", jobID)
+		}
+		// 共享锁在读取章节前获取，确保写回基于重排后的最新结构
+		if err := h.locker.LockProjectShared(txCtx, job.ProjectID); err != nil {
 			return err
 		}
-
 		ch, err := h.chapterRepo.GetByID(txCtx, chapterID)
 		if err != nil {
 			return err
@@ -492,30 +636,67 @@ func (h *StreamHandler) markJobCompleted(ctx context.Context, tenantID, jobID, c
 		if ch == nil {
 			return fmt.Errorf("chapter not found: %s", chapterID)
 		}
-
-		ch.SetContent(out.Content)
-		ch.Status = entity.ChapterStatusCompleted
-		ch.GenerationMetadata = &entity.GenerationMetadata{
-			Model:            out.Meta.Model,
-			Provider:         out.Meta.Provider,
-			PromptTokens:     out.Meta.PromptTokens,
-			CompletionTokens: out.Meta.CompletionTokens,
-			Temperature:      out.Meta.Temperature,
-			GeneratedAt:      out.Meta.GeneratedAt.Format(time.RFC3339),
+		h.jobTimeline.Record(txCtx, job, entity.JobEventTokensStreamed, "stream finished", map[string]any{
+			"chunks":            chunks,
+			"completion_tokens": out.Meta.CompletionTokens,
+		})
+		job.RecordTruncation(truncated...)
+		chForIndex, err = h.finalizer.CompleteChapter(txCtx, job, ch, out)
+		if err != nil {
+			return err
 		}
+		return h.discardResumed(txCtx, resume, "partial content continued by job "+jobID)
+	})
+	return chForIndex, err
+}
+
+// streamResume 续写的来源：被中断任务及其保存的部分内容
+type streamResume struct {
+	job       *entity.GenerationJob
+	candidate *entity.GenerationCandidate
+}
+
+// loadResume 加载可续写的部分内容（任务须为本章节的 cancelled_partial 任务，且部分内容尚未采用或丢弃）
+func (h *StreamHandler) loadResume(ctx context.Context, jobID, chapterID string) (*streamResume, error) {
+	job, err := h.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.ChapterID == nil || *job.ChapterID != chapterID {
+		return nil, errNotFound("resume job not found")
+	}
+	candidate, err := h.finalizer.PartialCandidate(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if candidate == nil {
+		return nil, candidateStateError{msg: "job has no partial content to resume"}
+	}
+	return &streamResume{job: job, candidate: candidate}, nil
+}
+
+// discardResumed 续写结束（完成或再次中断）后，原部分内容由新结果替代
+func (h *StreamHandler) discardResumed(ctx context.Context, resume *streamResume, reason string) error {
+	if resume == nil {
+		return nil
+	}
+	return h.finalizer.DiscardPartial(ctx, resume.job, resume.candidate, reason)
+}
 
-		if err := h.chapterRepo.Update(txCtx, ch); err != nil {
+// markJobCancelled 客户端中断流式生成后保存部分内容并结束任务（再次中断的续写同样替代原部分内容）
+func (h *StreamHandler) markJobCancelled(ctx context.Context, tenantID, jobID, chapterID string, partial *wfmodel.ChapterGenerateOutput, resume *streamResume) error {
+	return withTenantTx(ctx, h.txMgr, h.tenantCtx, tenantID, func(txCtx context.Context) error {
+		job, err := h.jobRepo.GetByID(txCtx, jobID)
+		if err != nil || job == nil {
 			return err
 		}
-
-		stats, err := h.projectRepo.GetStats(txCtx, ch.ProjectID)
-		if err != nil || stats == nil {
-			logger.Warn(txCtx, "failed to refresh project word count after chapter generation", "error", err)
-			return nil
+		if err := h.locker.LockProjectShared(txCtx, job.ProjectID); err != nil {
+			return err
 		}
-		if err := h.projectRepo.UpdateWordCount(txCtx, ch.ProjectID, int(stats.TotalWordCount)); err != nil {
-			logger.Warn(txCtx, "failed to update project word count after chapter generation", "error", err.Error())
+		candidate, err := h.finalizer.CancelChapter(txCtx, job, chapterID, partial)
+		if err != nil || candidate == nil {
+			return err
 		}
-		return nil
+		return h.discardResumed(txCtx, resume, "partial content superseded by job "+jobID)
 	})
 }
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDetachTimeout 未配置 story.sync_generation.detach_timeout 时的后台完成时限
+const defaultDetachTimeout = 10 * time.Minute
+
+// detachGeneration 返回同步生成（额度预检与任务落库之后）使用的上下文。
+// detach 策略下脱离请求取消（保留日志与追踪信息）并施加超时：客户端断开后生成继续完成、结果照常落库，
+// 避免已消耗的模型调用被浪费；cancel 策略下沿用请求上下文，客户端断开即中止生成。
+func detachGeneration(ctx context.Context, cfg *config.Config, endpoint string) (context.Context, context.CancelFunc) {
+	if cfg == nil {
+		return context.WithCancel(ctx)
+	}
+	sg := cfg.Story.SyncGeneration
+	if sg.Policy(endpoint) != config.DisconnectDetach {
+		return context.WithCancel(ctx)
+	}
+	timeout := sg.DetachTimeout
+	if timeout <= 0 {
+		timeout = defaultDetachTimeout
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), timeout)
+}
+
+// clientGone 客户端是否已断开（请求上下文已取消）
+func clientGone(c *gin.Context) bool {
+	return c.Request.Context().Err() != nil
+}
+
+// disconnectError 客户端断开导致生成中止时补充原因，便于在任务错误信息中区分
+func disconnectError(c *gin.Context, err error) error {
+	if err == nil || !clientGone(c) {
+		return err
+	}
+	return fmt.Errorf("client disconnected: %w", err)
+}
@@ -2,6 +2,14 @@
 package handler
 
 import (
+	stderrors "errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
@@ -13,13 +21,21 @@ import (
 
 // TenantHandler 租户处理器
 type TenantHandler struct {
-	tenantRepo repository.TenantRepository
+	cfg         *config.Config
+	tenantRepo  repository.TenantRepository
+	planService *quota.PlanService
+	indexer     *retrieval.Indexer
+	usage       *quota.UsageQuery
 }
 
 // NewTenantHandler 创建租户处理器
-func NewTenantHandler(tenantRepo repository.TenantRepository) *TenantHandler {
+func NewTenantHandler(cfg *config.Config, tenantRepo repository.TenantRepository, planService *quota.PlanService, indexer *retrieval.Indexer, usage *quota.UsageQuery) *TenantHandler {
 	return &TenantHandler{
-		tenantRepo: tenantRepo,
+		cfg:         cfg,
+		tenantRepo:  tenantRepo,
+		planService: planService,
+		indexer:     indexer,
+		usage:       usage,
 	}
 }
 
@@ -31,6 +47,7 @@ func NewTenantHandler(tenantRepo repository.TenantRepository) *TenantHandler {
 // @Produce json
 // @Success 200 {object} dto.Response[dto.TenantResponse]
 // @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/tenants/current [get]
 func (h *TenantHandler) GetCurrentTenant(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -62,6 +79,7 @@ func (h *TenantHandler) GetCurrentTenant(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.TenantResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/tenants/current [put]
 func (h *TenantHandler) UpdateCurrentTenant(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -69,7 +87,7 @@ func (h *TenantHandler) UpdateCurrentTenant(c *gin.Context) {
 
 	var req dto.UpdateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -97,7 +115,218 @@ func (h *TenantHandler) UpdateCurrentTenant(c *gin.Context) {
 	dto.Success(c, resp)
 }
 
+// GetProjectDefaults 获取租户默认项目设置
+// @Summary 获取租户默认项目设置
+// @Description 新建项目时复制到项目设置的默认模板（章节长度、风格、视角、召回与 LLM 默认值）
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} dto.Response[dto.ProjectSettingsResponse]
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/project-defaults [get]
+func (h *TenantHandler) GetProjectDefaults(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant info")
+		return
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return
+	}
+
+	dto.Success(c, dto.ToProjectSettingsResponse(tenant.ProjectDefaults()))
+}
+
+// UpdateProjectDefaults 设置租户默认项目设置
+// @Summary 设置租户默认项目设置
+// @Description 整体替换默认模板（仅 admin）；只影响之后新建或重置设置的项目
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param body body dto.TenantProjectDefaultsRequest true "默认项目设置"
+// @Success 200 {object} dto.Response[dto.ProjectSettingsResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/project-defaults [put]
+func (h *TenantHandler) UpdateProjectDefaults(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	var req dto.TenantProjectDefaultsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	defaults := req.ToEntity()
+	if defaults != nil && defaults.Provider != "" {
+		if _, _, err := resolveProviderModel(h.cfg, defaults.Provider, defaults.Model); err != nil {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant info")
+		return
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return
+	}
+
+	if tenant.Settings == nil {
+		tenant.Settings = &entity.TenantSettings{}
+	}
+	tenant.Settings.ProjectDefaults = defaults
+	tenant.UpdatedAt = time.Now()
+
+	if err := h.tenantRepo.Update(ctx, tenant); err != nil {
+		logger.Error(ctx, "failed to update tenant", err)
+		dto.InternalError(c, "failed to update tenant info")
+		return
+	}
+
+	dto.Success(c, dto.ToProjectSettingsResponse(tenant.ProjectDefaults()))
+}
+
+// GetArtifactValidation 获取构件激活校验 Webhook 设置
+// @Summary 获取构件激活校验 Webhook
+// @Description 构件版本激活（回滚、采用候选、会话生成后激活）前调用的租户自定义校验设置（仅 admin）；不返回密钥
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} dto.Response[dto.TenantArtifactValidationResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/artifact-validation [get]
+func (h *TenantHandler) GetArtifactValidation(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant info")
+		return
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return
+	}
+
+	dto.Success(c, dto.ToTenantArtifactValidationResponse(tenant.ArtifactValidation()))
+}
+
+// UpdateArtifactValidation 设置构件激活校验 Webhook
+// @Summary 设置构件激活校验 Webhook
+// @Description 整体替换设置（仅 admin），url 为空表示关闭。激活前 POST {tenant_id, project_id, artifact_id, artifact_type, version_id, trigger, content}，
+// @Description 响应 {passed, messages:[{code, message, path, severity}]}；passed=false 时激活返回 422。
+// @Description 设置 secret 时请求带 X-Timestamp 与 X-Signature（hex(HMAC-SHA256(secret, "<timestamp>.<body>"))）。调用失败默认阻止激活（503），fail_open=true 时放行
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param body body dto.TenantArtifactValidationRequest true "Webhook 设置"
+// @Success 200 {object} dto.Response[dto.TenantArtifactValidationResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/artifact-validation [put]
+func (h *TenantHandler) UpdateArtifactValidation(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	var req dto.TenantArtifactValidationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+	if u := strings.TrimSpace(req.URL); u != "" {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			dto.BadRequest(c, "url must be an absolute http(s) url")
+			return
+		}
+	}
+
+	tenant, err := h.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant", err)
+		dto.InternalError(c, "failed to get tenant info")
+		return
+	}
+	if tenant == nil {
+		dto.NotFound(c, "tenant not found")
+		return
+	}
+
+	if tenant.Settings == nil {
+		tenant.Settings = &entity.TenantSettings{}
+	}
+	tenant.Settings.ArtifactValidation = req.ToEntity(tenant.ArtifactValidation())
+	tenant.UpdatedAt = time.Now()
+
+	if err := h.tenantRepo.Update(ctx, tenant); err != nil {
+		logger.Error(ctx, "failed to update tenant", err)
+		dto.InternalError(c, "failed to update tenant info")
+		return
+	}
+
+	dto.Success(c, dto.ToTenantArtifactValidationResponse(tenant.ArtifactValidation()))
+}
+
+// GetVectorUsage 获取当前租户向量用量
+// @Summary 获取租户向量用量
+// @Description 当前租户已索引的向量片段数与近似存储量（按项目拆分）及配额上限（仅 admin）。写索引超出上限时相应写入失败
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} dto.Response[dto.TenantVectorUsageResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse "未开启向量用量计数"
+// @Security BearerAuth
+// @Router /v1/tenants/current/vector-usage [get]
+func (h *TenantHandler) GetVectorUsage(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	report, err := h.indexer.Usage(ctx, tenantID)
+	if err != nil {
+		if stderrors.Is(err, retrieval.ErrVectorDisabled) {
+			dto.ServiceUnavailable(c, "vector usage tracking is disabled")
+			return
+		}
+		logger.Error(ctx, "failed to get vector usage", err)
+		dto.InternalError(c, "failed to get vector usage")
+		return
+	}
+
+	dto.Success(c, &dto.TenantVectorUsageResponse{UsageReport: report})
+}
+
 // ListTenants 获取租户列表
+// @Summary 获取租户列表
+// @Description 分页获取全部租户（仅 admin）
+// @Tags Tenants
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页条数" default(20)
+// @Success 200 {object} dto.Response[dto.TenantListResponse]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants [get]
 func (h *TenantHandler) ListTenants(c *gin.Context) {
 	ctx := c.Request.Context()
 	pageReq := dto.BindPage(c)
@@ -119,12 +348,24 @@ func (h *TenantHandler) ListTenants(c *gin.Context) {
 }
 
 // CreateTenant 创建新租户
+// @Summary 创建租户
+// @Description 创建新租户（仅 admin），slug 需唯一
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateTenantRequest true "租户信息"
+// @Success 201 {object} dto.Response[dto.TenantResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants [post]
 func (h *TenantHandler) CreateTenant(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	var req dto.CreateTenantRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -141,6 +382,11 @@ func (h *TenantHandler) CreateTenant(c *gin.Context) {
 	}
 
 	tenant := entity.NewTenant(req.Name, req.Slug)
+	if err := h.planService.AssignDefaultPlan(ctx, tenant, time.Now()); err != nil {
+		logger.Error(ctx, "failed to assign default plan", err)
+		dto.InternalError(c, "failed to create tenant")
+		return
+	}
 	if err := h.tenantRepo.Create(ctx, tenant); err != nil {
 		logger.Error(ctx, "failed to create tenant", err)
 		dto.InternalError(c, "failed to create tenant")
@@ -149,3 +395,90 @@ func (h *TenantHandler) CreateTenant(c *gin.Context) {
 
 	dto.Created(c, dto.ToTenantResponse(tenant))
 }
+
+// ListPlans 获取可订阅套餐列表
+// @Summary 获取套餐列表
+// @Description 获取可订阅的套餐（月度 Token 额度、并发上限、限流与功能开关）
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} dto.Response[dto.PlanListResponse]
+// @Security BearerAuth
+// @Router /v1/plans [get]
+func (h *TenantHandler) ListPlans(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	plans, err := h.planService.ListPlans(ctx)
+	if err != nil {
+		logger.Error(ctx, "failed to list plans", err)
+		dto.InternalError(c, "failed to list plans")
+		return
+	}
+
+	items := make([]*dto.PlanResponse, 0, len(plans))
+	for _, p := range plans {
+		items = append(items, dto.ToPlanResponse(p))
+	}
+	dto.Success(c, &dto.PlanListResponse{Items: items})
+}
+
+// GetCurrentPlan 获取当前租户套餐
+// @Summary 获取当前租户套餐
+// @Description 获取当前租户的套餐、余额与配额周期
+// @Tags Tenants
+// @Produce json
+// @Success 200 {object} dto.Response[dto.TenantPlanResponse]
+// @Security BearerAuth
+// @Router /v1/tenants/current/plan [get]
+func (h *TenantHandler) GetCurrentPlan(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	tenant, plan, err := h.planService.TenantPlan(ctx, tenantID)
+	if err != nil {
+		logger.Error(ctx, "failed to get tenant plan", err)
+		dto.InternalError(c, "failed to get tenant plan")
+		return
+	}
+	dto.Success(c, dto.ToTenantPlanResponse(tenant, plan))
+}
+
+// ChangeCurrentPlan 变更当前租户套餐
+// @Summary 变更当前租户套餐
+// @Description 切换套餐；配额周期不变，余额按当前周期剩余比例折算两档套餐的月度额度差
+// @Tags Tenants
+// @Accept json
+// @Produce json
+// @Param body body dto.ChangePlanRequest true "目标套餐"
+// @Success 200 {object} dto.Response[dto.ChangePlanResponse]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/tenants/current/plan [put]
+func (h *TenantHandler) ChangeCurrentPlan(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantIDFromGin(c)
+
+	var req dto.ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	change, err := h.planService.ChangePlan(ctx, tenantID, req.PlanCode, time.Now())
+	if err != nil {
+		if stderrors.Is(err, quota.ErrPlanNotFound) {
+			dto.NotFound(c, "plan not found")
+			return
+		}
+		logger.Error(ctx, "failed to change tenant plan", err)
+		dto.InternalError(c, "failed to change plan")
+		return
+	}
+
+	dto.Success(c, &dto.ChangePlanResponse{
+		PreviousPlan:      dto.ToPlanResponse(change.PreviousPlan),
+		Current:           dto.ToTenantPlanResponse(change.Tenant, change.Plan),
+		RemainingRatio:    change.RemainingRatio,
+		BalanceAdjustment: change.BalanceAdjustment,
+	})
+}
@@ -30,6 +30,7 @@ func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
 // @Produce json
 // @Success 200 {object} dto.Response[dto.UserResponse]
 // @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/users/me [get]
 func (h *UserHandler) GetMe(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -61,6 +62,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.UserResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 401 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/users/me [put]
 func (h *UserHandler) UpdateMe(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -68,7 +70,7 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 
 	var req dto.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -106,6 +108,7 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 // @Param page_size query int false "每页条数" default(20)
 // @Success 200 {object} dto.Response[dto.UserListResponse]
 // @Failure 403 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/users [get]
 func (h *UserHandler) ListTenantUsers(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -125,13 +128,26 @@ func (h *UserHandler) ListTenantUsers(c *gin.Context) {
 }
 
 // UpdateUserRole 更新用户角色
+// @Summary 更新用户角色
+// @Description 修改租户内指定用户的角色（仅 admin）
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "用户 ID"
+// @Param body body dto.UpdateUserRoleRequest true "目标角色"
+// @Success 200 {object} dto.Response[map[string]interface{}]
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/users/{id}/role [put]
 func (h *UserHandler) UpdateUserRole(c *gin.Context) {
 	ctx := c.Request.Context()
 	targetUserID := c.Param("id")
 
 	var req dto.UpdateUserRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -145,6 +161,18 @@ func (h *UserHandler) UpdateUserRole(c *gin.Context) {
 }
 
 // DeleteUser 删除用户
+// @Summary 删除用户
+// @Description 删除租户内指定用户（仅 admin）；需先通过 POST /v1/confirmations（action=user.delete）申请确认令牌
+// @Tags Users
+// @Produce json
+// @Param id path string true "用户 ID"
+// @Param X-Confirmation-Token header string true "二次确认令牌"
+// @Success 200 {object} dto.Response[map[string]interface{}]
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 428 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	ctx := c.Request.Context()
 	targetUserID := c.Param("id")
@@ -4,8 +4,10 @@ package handler
 import (
 	"net/http"
 
+	appstory "z-novel-ai-api/internal/application/story"
 	"z-novel-ai-api/internal/domain/repository"
 	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
 	"z-novel-ai-api/pkg/errors"
 	"z-novel-ai-api/pkg/logger"
 
@@ -15,24 +17,29 @@ import (
 // VolumeHandler 卷处理器
 type VolumeHandler struct {
 	volumeRepo repository.VolumeRepository
+	locker     repository.ProjectLocker
+	numbering  *appstory.ChapterNumbering
 }
 
 // NewVolumeHandler 创建卷处理器
-func NewVolumeHandler(volumeRepo repository.VolumeRepository) *VolumeHandler {
+func NewVolumeHandler(volumeRepo repository.VolumeRepository, locker repository.ProjectLocker, numbering *appstory.ChapterNumbering) *VolumeHandler {
 	return &VolumeHandler{
 		volumeRepo: volumeRepo,
+		locker:     locker,
+		numbering:  numbering,
 	}
 }
 
 // ListVolumes 获取卷列表
 // @Summary 获取项目卷列表
-// @Description 获取指定项目下的所有卷信息
+// @Description 获取指定项目下的所有卷信息（含字数、章节状态计数与预计阅读时长汇总）
 // @Tags Volumes
 // @Accept json
 // @Produce json
 // @Param pid path string true "项目 ID"
 // @Success 200 {object} dto.Response[dto.VolumeListResponse]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/volumes [get]
 func (h *VolumeHandler) ListVolumes(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -60,6 +67,7 @@ func (h *VolumeHandler) ListVolumes(c *gin.Context) {
 // @Success 201 {object} dto.Response[dto.VolumeResponse]
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/volumes [post]
 func (h *VolumeHandler) CreateVolume(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -67,7 +75,7 @@ func (h *VolumeHandler) CreateVolume(c *gin.Context) {
 
 	var req dto.CreateVolumeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -101,6 +109,7 @@ func (h *VolumeHandler) CreateVolume(c *gin.Context) {
 // @Success 200 {object} dto.Response[dto.VolumeResponse]
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/volumes/{vid} [get]
 func (h *VolumeHandler) GetVolume(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -110,11 +119,7 @@ func (h *VolumeHandler) GetVolume(c *gin.Context) {
 	if err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to get volume", err)
@@ -143,6 +148,7 @@ func (h *VolumeHandler) GetVolume(c *gin.Context) {
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/volumes/{vid} [put]
 func (h *VolumeHandler) UpdateVolume(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -150,7 +156,7 @@ func (h *VolumeHandler) UpdateVolume(c *gin.Context) {
 
 	var req dto.UpdateVolumeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
 		return
 	}
 
@@ -183,7 +189,7 @@ func (h *VolumeHandler) UpdateVolume(c *gin.Context) {
 
 // DeleteVolume 删除卷
 // @Summary 删除卷
-// @Description 删除指定卷记录
+// @Description 删除指定卷记录（卷内章节保留为无卷章节），并压缩卷序号、刷新章节展示序号
 // @Tags Volumes
 // @Accept json
 // @Produce json
@@ -191,25 +197,37 @@ func (h *VolumeHandler) UpdateVolume(c *gin.Context) {
 // @Success 204 "No Content"
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/volumes/{vid} [delete]
 func (h *VolumeHandler) DeleteVolume(c *gin.Context) {
 	ctx := c.Request.Context()
 	volumeID := dto.BindVolumeID(c)
 
+	volume, err := h.volumeRepo.GetByID(ctx, volumeID)
+	if err != nil {
+		logger.Error(ctx, "failed to get volume", err)
+		dto.InternalError(c, "failed to delete volume")
+		return
+	}
+
 	if err := h.volumeRepo.Delete(ctx, volumeID); err != nil {
 		if errors.IsAppError(err) {
 			appErr := errors.AsAppError(err)
-			c.JSON(appErr.HTTPStatus, dto.ErrorResponse{
-				Code:    appErr.HTTPStatus,
-				Message: appErr.Message,
-				TraceID: c.GetString("trace_id"),
-			})
+			dto.Error(c, appErr.HTTPStatus, appErr.Message)
 			return
 		}
 		logger.Error(ctx, "failed to delete volume", err)
 		dto.InternalError(c, "failed to delete volume")
 		return
 	}
+	if volume != nil {
+		if err := h.numbering.RenumberProject(ctx, volume.ProjectID); err != nil {
+			logger.Error(ctx, "failed to renumber after volume delete", err)
+			dto.InternalError(c, "failed to delete volume")
+			return
+		}
+		h.numbering.SyncNarrativePositions(ctx, middleware.GetTenantIDFromGin(c), volume.ProjectID)
+	}
 
 	c.Status(http.StatusNoContent)
 }
@@ -224,6 +242,7 @@ func (h *VolumeHandler) DeleteVolume(c *gin.Context) {
 // @Param body body dto.ReorderVolumesRequest true "排序列表"
 // @Success 200 {object} dto.Response[map[string]interface{}]
 // @Failure 500 {object} dto.ErrorResponse
+// @Security BearerAuth
 // @Router /v1/projects/{pid}/volumes/reorder [post]
 func (h *VolumeHandler) ReorderVolumes(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -231,7 +250,14 @@ func (h *VolumeHandler) ReorderVolumes(c *gin.Context) {
 
 	var req dto.ReorderVolumesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		dto.BadRequest(c, "invalid request body: "+err.Error())
+		dto.InvalidBody(c, err)
+		return
+	}
+
+	// 重排属于结构性变更：与 Foundation 落库、章节生成写回串行
+	if err := h.locker.LockProject(ctx, projectID); err != nil {
+		logger.Error(ctx, "failed to lock project", err)
+		dto.InternalError(c, "failed to reorder volumes")
 		return
 	}
 
@@ -240,6 +266,12 @@ func (h *VolumeHandler) ReorderVolumes(c *gin.Context) {
 		dto.InternalError(c, "failed to reorder volumes")
 		return
 	}
+	if err := h.numbering.RefreshDisplayNumbers(ctx, projectID); err != nil {
+		logger.Error(ctx, "failed to refresh chapter display numbers", err)
+		dto.InternalError(c, "failed to reorder volumes")
+		return
+	}
+	h.numbering.SyncNarrativePositions(ctx, middleware.GetTenantIDFromGin(c), projectID)
 
 	dto.Success(c, gin.H{"message": "volumes reordered"})
 }
@@ -0,0 +1,447 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	appstory "z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/i18n"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsPingInterval 服务端心跳间隔：兼作已订阅任务的状态回查（兜底 Worker 异常退出未发布结束事件）
+	wsPingInterval = 30 * time.Second
+	// wsPongWait 未收到任何客户端消息（含 pong）的最长等待时间
+	wsPongWait = 2 * wsPingInterval
+	// wsWriteWait 单条消息的写出时限
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessageSize 客户端消息大小上限
+	wsMaxMessageSize = 4 << 10
+	// wsMaxSubscriptions 单个连接的订阅上限
+	wsMaxSubscriptions = 32
+	// wsEventBuffer 连接内汇集各订阅事件的缓冲
+	wsEventBuffer = 256
+)
+
+// WebSocketHandler 实时事件网关：单个连接按项目/任务多路订阅 Redis 广播的任务进度、正文分片与冲突提醒
+type WebSocketHandler struct {
+	txMgr       repository.Transactor
+	tenantCtx   repository.TenantContextManager
+	projectRepo repository.ProjectRepository
+	jobRepo     repository.JobRepository
+	progress    appstory.JobProgressBus
+	upgrader    websocket.Upgrader
+}
+
+// NewWebSocketHandler 创建实时事件网关
+func NewWebSocketHandler(
+	cfg *config.Config,
+	txMgr repository.Transactor,
+	tenantCtx repository.TenantContextManager,
+	projectRepo repository.ProjectRepository,
+	jobRepo repository.JobRepository,
+	progress appstory.JobProgressBus,
+) *WebSocketHandler {
+	origins := cfg.Security.CORS.AllowedOrigins
+	return &WebSocketHandler{
+		txMgr:       txMgr,
+		tenantCtx:   tenantCtx,
+		projectRepo: projectRepo,
+		jobRepo:     jobRepo,
+		progress:    progress,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				return wsOriginAllowed(origins, r)
+			},
+		},
+	}
+}
+
+// Connect 建立实时事件 WebSocket 连接
+// @Summary 建立实时事件 WebSocket 连接
+// @Description 升级为 WebSocket 后按项目或任务订阅实时事件（替代逐任务的 SSE 订阅）。客户端发送 {"op":"subscribe","project_id":"..."} 订阅项目下全部任务的进度、正文分片（content）、结束事件与设定冲突提醒（conflict_warnings），或 {"op":"subscribe","job_id":"..."} 只订阅单个任务；服务端以 dto.WSServerMessage 推送。订阅前已发布的事件不会补发，订阅已结束的任务时直接返回 done/error。浏览器无法设置请求头时可用 access_token 查询参数携带访问令牌
+// @Tags Realtime
+// @Param access_token query string false "访问令牌（仅 WebSocket 握手）"
+// @Success 101 {object} dto.WSServerMessage "WebSocket 消息"
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 503 {object} dto.ErrorResponse
+// @Security BearerAuth
+// @Router /v1/ws [get]
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	if h.progress == nil {
+		dto.ServiceUnavailable(c, "job progress streaming not configured")
+		return
+	}
+	if !middleware.IsWebSocketUpgrade(c.Request) {
+		dto.BadRequest(c, "websocket upgrade required")
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader 已写出握手失败响应
+		logger.Warn(c.Request.Context(), "websocket upgrade failed", "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	s := &wsSession{
+		h:        h,
+		conn:     conn,
+		tenantID: middleware.GetTenantIDFromGin(c),
+		locale:   dto.RequestLocale(c),
+		subs:     make(map[string]*wsSubscription),
+		events:   make(chan wsDelivery, wsEventBuffer),
+	}
+	defer func() {
+		cancel()
+		s.closeAll()
+		_ = conn.Close()
+	}()
+	s.run(ctx)
+}
+
+// wsSession 单个 WebSocket 连接：读协程只解析客户端消息，订阅管理与写出都在 run 所在的协程完成
+type wsSession struct {
+	h        *WebSocketHandler
+	conn     *websocket.Conn
+	tenantID string
+	locale   i18n.Locale
+
+	subs   map[string]*wsSubscription
+	events chan wsDelivery
+}
+
+// wsSubscription 一个订阅主题
+type wsSubscription struct {
+	jobID       string
+	unsubscribe func()
+}
+
+// wsDelivery 转发到连接的订阅事件
+type wsDelivery struct {
+	topic string
+	ev    *appstory.JobProgressEvent
+}
+
+func (s *wsSession) run(ctx context.Context) {
+	incoming := make(chan dto.WSClientMessage)
+	readErr := make(chan error, 1)
+	go s.readLoop(ctx, incoming, readErr)
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-incoming:
+			if err := s.handle(ctx, msg); err != nil {
+				return
+			}
+
+		case d := <-s.events:
+			if _, ok := s.subs[d.topic]; !ok {
+				continue
+			}
+			if err := s.write(&dto.WSServerMessage{Type: dto.WSMessageEvent, Topic: d.topic, Event: toWSJobEvent(d.ev)}); err != nil {
+				return
+			}
+			// 任务结束后自动退订任务主题
+			if sub := s.subs[d.topic]; sub.jobID != "" && (d.ev.Type == appstory.JobProgressDone || d.ev.Type == appstory.JobProgressError) {
+				s.drop(d.topic)
+			}
+
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+			if err := s.pollJobs(ctx); err != nil {
+				return
+			}
+
+		case err := <-readErr:
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				logger.Debug(ctx, "websocket connection closed", "error", err.Error())
+			}
+			return
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readLoop 读取客户端消息；任何读错误（含心跳超时）结束连接
+func (s *wsSession) readLoop(ctx context.Context, incoming chan<- dto.WSClientMessage, readErr chan<- error) {
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			readErr <- err
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		var msg dto.WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			msg = dto.WSClientMessage{}
+		}
+		select {
+		case incoming <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handle 处理客户端消息；仅写出失败时返回错误
+func (s *wsSession) handle(ctx context.Context, msg dto.WSClientMessage) error {
+	switch msg.Op {
+	case dto.WSOpPing:
+		return s.write(&dto.WSServerMessage{Type: dto.WSMessagePong, RequestID: msg.RequestID})
+	case dto.WSOpSubscribe:
+		return s.subscribe(ctx, msg)
+	case dto.WSOpUnsubscribe:
+		topic, ok := wsTopic(msg)
+		if !ok {
+			return s.writeError(msg.RequestID, "", "exactly one of project_id or job_id is required")
+		}
+		s.drop(topic)
+		return s.write(&dto.WSServerMessage{Type: dto.WSMessageUnsubscribed, Topic: topic, RequestID: msg.RequestID})
+	default:
+		return s.writeError(msg.RequestID, "", "invalid websocket message")
+	}
+}
+
+// subscribe 校验项目/任务归属后订阅；先订阅再读取任务状态，保证读取之后发布的事件都能收到
+func (s *wsSession) subscribe(ctx context.Context, msg dto.WSClientMessage) error {
+	topic, ok := wsTopic(msg)
+	if !ok {
+		return s.writeError(msg.RequestID, "", "exactly one of project_id or job_id is required")
+	}
+	if _, ok := s.subs[topic]; ok {
+		return s.write(&dto.WSServerMessage{Type: dto.WSMessageSubscribed, Topic: topic, RequestID: msg.RequestID})
+	}
+	if len(s.subs) >= wsMaxSubscriptions {
+		return s.writeError(msg.RequestID, topic, "too many websocket subscriptions")
+	}
+
+	if msg.ProjectID != "" {
+		project, err := s.loadProject(ctx, msg.ProjectID)
+		if err != nil {
+			logger.Error(ctx, "failed to get project", err)
+			return s.writeError(msg.RequestID, topic, "failed to get project")
+		}
+		if project == nil {
+			return s.writeError(msg.RequestID, topic, "project not found")
+		}
+		events, unsubscribe, err := s.h.progress.SubscribeProject(ctx, s.tenantID, msg.ProjectID)
+		if err != nil {
+			logger.Error(ctx, "failed to subscribe project events", err)
+			return s.writeError(msg.RequestID, topic, "failed to subscribe job progress")
+		}
+		s.add(ctx, topic, &wsSubscription{unsubscribe: unsubscribe}, events)
+		return s.write(&dto.WSServerMessage{Type: dto.WSMessageSubscribed, Topic: topic, RequestID: msg.RequestID})
+	}
+
+	events, unsubscribe, err := s.h.progress.Subscribe(ctx, msg.JobID)
+	if err != nil {
+		logger.Error(ctx, "failed to subscribe job progress", err)
+		return s.writeError(msg.RequestID, topic, "failed to subscribe job progress")
+	}
+	job, err := s.loadJob(ctx, msg.JobID)
+	if err != nil || job == nil {
+		unsubscribe()
+		if err != nil {
+			logger.Error(ctx, "failed to get job", err)
+			return s.writeError(msg.RequestID, topic, "failed to get job")
+		}
+		return s.writeError(msg.RequestID, topic, "job not found")
+	}
+	if err := s.write(&dto.WSServerMessage{Type: dto.WSMessageSubscribed, Topic: topic, RequestID: msg.RequestID}); err != nil {
+		unsubscribe()
+		return err
+	}
+	if job.IsFinished() {
+		unsubscribe()
+		return s.write(&dto.WSServerMessage{Type: dto.WSMessageEvent, Topic: topic, Event: wsJobFinishedEvent(job)})
+	}
+	s.add(ctx, topic, &wsSubscription{jobID: job.ID, unsubscribe: unsubscribe}, events)
+	return s.write(&dto.WSServerMessage{Type: dto.WSMessageEvent, Topic: topic, Event: &dto.WSJobEvent{
+		Type:      appstory.JobProgressProgress,
+		JobID:     job.ID,
+		ProjectID: job.ProjectID,
+		Progress:  job.Progress,
+		Status:    string(job.Status),
+	}})
+}
+
+// add 登记订阅并把其事件转发到连接
+func (s *wsSession) add(ctx context.Context, topic string, sub *wsSubscription, events <-chan *appstory.JobProgressEvent) {
+	s.subs[topic] = sub
+	go func() {
+		for ev := range events {
+			select {
+			case s.events <- wsDelivery{topic: topic, ev: ev}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// drop 退订主题（未订阅时为空操作）
+func (s *wsSession) drop(topic string) {
+	if sub, ok := s.subs[topic]; ok {
+		sub.unsubscribe()
+		delete(s.subs, topic)
+	}
+}
+
+func (s *wsSession) closeAll() {
+	for topic := range s.subs {
+		s.drop(topic)
+	}
+}
+
+// pollJobs 回查已订阅任务的状态：已结束但未收到结束事件的任务补发 done/error 并退订
+func (s *wsSession) pollJobs(ctx context.Context) error {
+	for topic, sub := range s.subs {
+		if sub.jobID == "" {
+			continue
+		}
+		job, err := s.loadJob(ctx, sub.jobID)
+		if err != nil || job == nil || !job.IsFinished() {
+			continue
+		}
+		s.drop(topic)
+		if err := s.write(&dto.WSServerMessage{Type: dto.WSMessageEvent, Topic: topic, Event: wsJobFinishedEvent(job)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadProject 在独立短事务中读取项目（WebSocket 路径不持有请求级事务）
+func (s *wsSession) loadProject(ctx context.Context, projectID string) (*entity.Project, error) {
+	var project *entity.Project
+	err := withTenantTx(ctx, s.h.txMgr, s.h.tenantCtx, s.tenantID, func(txCtx context.Context) error {
+		var err error
+		project, err = s.h.projectRepo.GetByID(txCtx, projectID)
+		return err
+	})
+	return project, err
+}
+
+// loadJob 在独立短事务中读取任务
+func (s *wsSession) loadJob(ctx context.Context, jobID string) (*entity.GenerationJob, error) {
+	var job *entity.GenerationJob
+	err := withTenantTx(ctx, s.h.txMgr, s.h.tenantCtx, s.tenantID, func(txCtx context.Context) error {
+		var err error
+		job, err = s.h.jobRepo.GetByID(txCtx, jobID)
+		return err
+	})
+	return job, err
+}
+
+func (s *wsSession) write(msg *dto.WSServerMessage) error {
+	msg.Version = dto.StreamSchemaVersion
+	_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return s.conn.WriteJSON(msg)
+}
+
+// writeError 写出错误消息（按握手请求协商的语言翻译，错误码与 HTTP 错误响应一致）
+func (s *wsSession) writeError(requestID, topic, message string) error {
+	return s.write(&dto.WSServerMessage{
+		Type:      dto.WSMessageError,
+		Topic:     topic,
+		RequestID: requestID,
+		Code:      i18n.Code(message),
+		Message:   i18n.Translate(s.locale, message),
+	})
+}
+
+// wsTopic 客户端消息对应的订阅主题：project_id 与 job_id 须二选一且为合法 UUID
+func wsTopic(msg dto.WSClientMessage) (string, bool) {
+	projectID, jobID := strings.TrimSpace(msg.ProjectID), strings.TrimSpace(msg.JobID)
+	switch {
+	case projectID != "" && jobID == "":
+		if _, err := uuid.Parse(projectID); err != nil {
+			return "", false
+		}
+		return "project:" + projectID, true
+	case jobID != "" && projectID == "":
+		if _, err := uuid.Parse(jobID); err != nil {
+			return "", false
+		}
+		return "job:" + jobID, true
+	}
+	return "", false
+}
+
+// toWSJobEvent 转换广播事件（不外泄租户 ID）
+func toWSJobEvent(ev *appstory.JobProgressEvent) *dto.WSJobEvent {
+	return &dto.WSJobEvent{
+		Type:      ev.Type,
+		JobID:     ev.JobID,
+		ProjectID: ev.ProjectID,
+		Candidate: ev.Candidate,
+		Chunk:     ev.Chunk,
+		Progress:  ev.Progress,
+		Status:    ev.Status,
+		ChapterID: ev.ChapterID,
+		ErrorCode: ev.ErrorCode,
+		Error:     ev.Error,
+		Conflicts: ev.Conflicts,
+	}
+}
+
+// wsJobFinishedEvent 按任务最终状态构造 done 或 error 事件
+func wsJobFinishedEvent(job *entity.GenerationJob) *dto.WSJobEvent {
+	ev := &dto.WSJobEvent{Type: appstory.JobProgressDone, JobID: job.ID, ProjectID: job.ProjectID, Progress: job.Progress, Status: string(job.Status)}
+	if job.ChapterID != nil {
+		ev.ChapterID = *job.ChapterID
+	}
+	if job.Status != entity.JobStatusCompleted {
+		ev.Type = appstory.JobProgressError
+		ev.ErrorCode = jobStreamErrorCode(job.Status, string(job.ErrorCode))
+		ev.Error = job.ErrorMessage
+	}
+	return ev
+}
+
+// wsOriginAllowed 按 CORS 允许的来源校验握手 Origin（未配置或含 * 时放行；非浏览器客户端不带 Origin）
+func wsOriginAllowed(allowed []string, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(allowed) == 0 {
+		return true
+	}
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
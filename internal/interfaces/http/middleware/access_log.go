@@ -0,0 +1,139 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLogRoute 单个路由的采样率覆盖（Method 为空匹配全部方法）
+type AccessLogRoute struct {
+	Method     string
+	Route      string
+	SampleRate float64
+}
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	// SampleRate 成功请求的默认采样率 [0, 1]
+	SampleRate float64
+	// SlowThreshold 耗时超过该值的请求始终记录；0 表示不按耗时记录
+	SlowThreshold time.Duration
+	// SkipPaths 不记录的请求路径
+	SkipPaths []string
+	// Routes 按路由模板覆盖采样率
+	Routes []AccessLogRoute
+}
+
+// AccessLog 结构化访问日志中间件：每个请求一条日志，携带租户/用户/路由模板/耗时/状态码与 trace_id。
+// 错误响应（>= 400）与慢请求始终记录；成功请求按路由采样率记录，采样按 request_id 取哈希，
+// 同一请求 ID 的重放结果一致。日志中的 sample_rate 可用于按采样率还原请求量。
+func AccessLog(cfg AccessLogConfig) gin.HandlerFunc {
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, p := range cfg.SkipPaths {
+		skip[p] = true
+	}
+	rates := make(map[string]float64, len(cfg.Routes))
+	for _, r := range cfg.Routes {
+		rates[accessLogRouteKey(r.Method, r.Route)] = r.SampleRate
+	}
+	sampleRate := func(method, route string) float64 {
+		if rate, ok := rates[accessLogRouteKey(method, route)]; ok {
+			return rate
+		}
+		if rate, ok := rates[accessLogRouteKey("", route)]; ok {
+			return rate
+		}
+		return cfg.SampleRate
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		requestID := c.GetString("request_id")
+		slow := cfg.SlowThreshold > 0 && latency >= cfg.SlowThreshold
+		rate := sampleRate(method, route)
+
+		reason := "sampled"
+		switch {
+		case status >= 400:
+			reason = "error"
+		case slow:
+			reason = "slow"
+		case !accessLogSampled(requestID, rate):
+			return
+		}
+
+		ctx := c.Request.Context()
+		traceID, spanID := c.GetString("trace_id"), c.GetString("span_id")
+		if traceID == "" {
+			if sc := trace.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+				traceID, spanID = sc.TraceID().String(), sc.SpanID().String()
+			}
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelWarn
+		}
+		logger.Default().Log(ctx, level, "http access",
+			"method", method,
+			"route", route,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", latency.Milliseconds(),
+			"tenant_id", c.GetString("tenant_id"),
+			"user_id", c.GetString("user_id"),
+			"request_id", requestID,
+			"trace_id", traceID,
+			"span_id", spanID,
+			"ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
+			"bytes_in", c.Request.ContentLength,
+			"bytes_out", c.Writer.Size(),
+			"log_reason", reason,
+			"sample_rate", rate,
+		)
+	}
+}
+
+func accessLogRouteKey(method, route string) string {
+	return strings.ToUpper(strings.TrimSpace(method)) + " " + strings.TrimSpace(route)
+}
+
+// accessLogSampled 按请求 ID 哈希决定是否采样；无请求 ID 时随机采样
+func accessLogSampled(requestID string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	if requestID == "" {
+		return rand.Float64() < rate
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(requestID))
+	return float64(h.Sum32()%10000) < rate*10000
+}
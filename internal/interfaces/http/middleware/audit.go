@@ -9,37 +9,6 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Audit 审计日志中间件
-// 记录请求的详细信息，用于审计和监控
-func Audit() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 记录请求开始时间
-		start := time.Now()
-
-		// 处理请求
-		c.Next()
-
-		// 计算请求耗时
-		duration := time.Since(start)
-
-		// 记录审计日志
-		logger.Info(c.Request.Context(), "api request",
-			"method", c.Request.Method,
-			"path", c.Request.URL.Path,
-			"query", c.Request.URL.RawQuery,
-			"status", c.Writer.Status(),
-			"duration_ms", duration.Milliseconds(),
-			"ip", c.ClientIP(),
-			"user_agent", c.Request.UserAgent(),
-			"tenant_id", c.GetString("tenant_id"),
-			"user_id", c.GetString("user_id"),
-			"request_id", c.GetString("request_id"),
-			"trace_id", c.GetString("trace_id"),
-			"body_size", c.Writer.Size(),
-		)
-	}
-}
-
 // AuditConfig 审计配置
 type AuditConfig struct {
 	// Enabled 是否启用审计
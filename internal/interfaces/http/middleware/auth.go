@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -54,8 +55,13 @@ func Auth(cfg AuthConfig) gin.HandlerFunc {
 			}
 		}
 
-		// 获取 Authorization Header
+		// 获取 Authorization Header（浏览器 WebSocket 握手无法设置请求头，允许以 access_token 查询参数携带令牌）
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && IsWebSocketUpgrade(c.Request) {
+			if token := c.Query("access_token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			abortUnauthorized(c, "missing authorization header")
 			return
@@ -96,13 +102,15 @@ func Auth(cfg AuthConfig) gin.HandlerFunc {
 	}
 }
 
+// IsWebSocketUpgrade 是否为 WebSocket 握手请求
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
 // abortUnauthorized 终止请求并返回 401
 func abortUnauthorized(c *gin.Context, msg string) {
-	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-		"code":     401,
-		"message":  msg,
-		"trace_id": c.GetString("trace_id"),
-	})
+	c.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewErrorResponse(c, http.StatusUnauthorized, msg, nil))
 }
 
 // 错误定义
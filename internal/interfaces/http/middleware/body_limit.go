@@ -0,0 +1,74 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitRule 路由级请求体上限（按路由模板前缀匹配，如 "/v1/auth/"、"/v1/projects/:pid/ingest-notes"）
+type BodyLimitRule struct {
+	Prefix   string
+	MaxBytes int64
+}
+
+// BodyLimitConfig 请求体大小限制配置
+type BodyLimitConfig struct {
+	// DefaultMaxBytes 未命中规则时的上限（<=0 表示不限制）
+	DefaultMaxBytes int64
+	// Rules 路由规则，最长前缀优先
+	Rules []BodyLimitRule
+}
+
+// BodyLimit 请求体大小限制中间件：声明的 Content-Length 超限直接返回 413，
+// 未声明长度（chunked）的请求体读取时超限报错，由处理器经 dto.InvalidBody / dto.BodyTooLarge 返回同样的 413。
+func BodyLimit(cfg BodyLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := cfg.limitFor(routePath(c))
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			dto.RequestTooLarge(c, limit)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// limitFor 返回路径对应的上限（最长前缀匹配）
+func (cfg BodyLimitConfig) limitFor(path string) int64 {
+	limit := cfg.DefaultMaxBytes
+	matched := -1
+	for _, rule := range cfg.Rules {
+		if rule.Prefix == "" || !strings.HasPrefix(path, rule.Prefix) {
+			continue
+		}
+		if len(rule.Prefix) > matched {
+			matched = len(rule.Prefix)
+			limit = rule.MaxBytes
+		}
+	}
+	return limit
+}
+
+// routePath 优先使用路由模板（含 :pid 等占位符），未匹配路由时退回实际路径
+func routePath(c *gin.Context) string {
+	if p := c.FullPath(); p != "" {
+		return p
+	}
+	return c.Request.URL.Path
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+)
+
+func TestBodyLimitRejectsOversizedBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimit(BodyLimitConfig{DefaultMaxBytes: 32}))
+	r.POST("/v1/notes", func(c *gin.Context) {
+		var req struct {
+			Text string `json:"text"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			dto.InvalidBody(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	body := `{"text":"` + strings.Repeat("长", 40) + `"}`
+	cases := []struct {
+		name          string
+		contentLength int64
+	}{
+		{name: "declared content length", contentLength: int64(len(body))},
+		// 未声明长度（chunked）：读取时由 http.MaxBytesReader 截断，经 dto.InvalidBody 返回同样的 413
+		{name: "chunked", contentLength: -1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/notes", strings.NewReader(body))
+			req.ContentLength = tc.contentLength
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want 413 (body %s)", w.Code, w.Body.String())
+			}
+			var resp map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp["max_bytes"] != float64(32) || resp["message"] != "request body too large" {
+				t.Fatalf("response = %v", resp)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/notes", strings.NewReader(`{"text":"短"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("small body status = %d, want 204", w.Code)
+	}
+}
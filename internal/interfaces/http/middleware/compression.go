@@ -0,0 +1,173 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressibleTypes 默认压缩的响应类型（前缀匹配；SSE 始终不压缩）
+var DefaultCompressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/javascript",
+	"text/",
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	// Level 压缩级别（1-9，0/越界时取默认级别）
+	Level int
+	// MinSize 小于该字节数的响应不压缩
+	MinSize int
+	// ContentTypes 需要压缩的 Content-Type 前缀
+	ContentTypes []string
+}
+
+// Compression gzip/deflate 响应压缩中间件（按 Accept-Encoding 协商，gzip 优先）
+func Compression(cfg CompressionConfig) gin.HandlerFunc {
+	if cfg.Level < flate.BestSpeed || cfg.Level > flate.BestCompression {
+		cfg.Level = flate.DefaultCompression
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressibleTypes
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead || IsWebSocketUpgrade(c.Request) {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, cfg: &cfg, encoding: encoding}
+		c.Writer = w
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		defer w.close()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding 选择客户端接受的编码（忽略 q=0）
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	switch {
+	case accepted["gzip"], accepted["*"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter 在首次写入时决定是否压缩（依据状态码、Content-Type 与长度）
+type compressWriter struct {
+	gin.ResponseWriter
+	cfg      *CompressionConfig
+	encoding string
+
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decide(len(p))
+	}
+	if w.compressor == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.compressor.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 先刷出压缩缓冲，保证流式响应及时送达
+func (w *compressWriter) Flush() {
+	if f, ok := w.compressor.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) decide(firstChunk int) {
+	w.decided = true
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || !w.compressibleStatus() || !w.compressibleType(h.Get("Content-Type")) {
+		return
+	}
+
+	size := firstChunk
+	if cl, err := strconv.Atoi(h.Get("Content-Length")); err == nil {
+		size = cl
+	}
+	if size < w.cfg.MinSize {
+		return
+	}
+
+	var err error
+	switch w.encoding {
+	case "gzip":
+		var gw *gzip.Writer
+		gw, err = gzip.NewWriterLevel(w.ResponseWriter, w.cfg.Level)
+		w.compressor = gw
+	case "deflate":
+		// HTTP 的 deflate 编码为 zlib 封装格式（RFC 9110 §8.4.1.2），而非裸 DEFLATE 流
+		var zw *zlib.Writer
+		zw, err = zlib.NewWriterLevel(w.ResponseWriter, w.cfg.Level)
+		w.compressor = zw
+	}
+	if err != nil || w.compressor == nil {
+		w.compressor = nil
+		return
+	}
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+}
+
+func (w *compressWriter) compressibleStatus() bool {
+	status := w.Status()
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+func (w *compressWriter) compressibleType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	for _, prefix := range w.cfg.ContentTypes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) close() {
+	if w.compressor != nil {
+		_ = w.compressor.Close()
+	}
+}
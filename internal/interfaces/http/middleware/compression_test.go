@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompressionDeflateIsZlibWrapped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Compression(CompressionConfig{}))
+	payload := strings.Repeat("雾港纪事", 100)
+	r.GET("/v1/text", func(c *gin.Context) {
+		c.String(http.StatusOK, payload)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/text", nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "deflate" {
+		t.Fatalf("Content-Encoding = %q, want deflate", got)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("deflate response is not zlib-wrapped: %v", err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != payload {
+		t.Fatalf("decoded payload mismatch")
+	}
+}
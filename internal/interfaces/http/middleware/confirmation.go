@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfirmationTokenHeader 二次确认令牌请求头
+const ConfirmationTokenHeader = "X-Confirmation-Token"
+
+// ConfirmationVerifier 校验并核销二次确认令牌（令牌无效时返回的错误由 RequireConfirmation 的 invalidErr 识别）
+type ConfirmationVerifier interface {
+	Consume(ctx context.Context, token, tenantID, userID, action, resourceID string) error
+}
+
+// RequireConfirmation 危险操作二次确认中间件：请求须在 X-Confirmation-Token 中携带针对同一操作与资源签发的令牌
+// （POST /v1/confirmations），核销后放行；缺失或无效时返回 428。resourceParam 为目标资源 ID 的路径参数名，
+// invalidErr 为令牌无效时 verifier 返回的哨兵错误。
+func RequireConfirmation(verifier ConfirmationVerifier, invalidErr error, action, resourceParam string) gin.HandlerFunc {
+	return RequireConfirmationFor(verifier, invalidErr, action, func(c *gin.Context) string { return c.Param(resourceParam) })
+}
+
+// RequireConfirmationFor 同 RequireConfirmation，目标资源 ID 由 resource 从请求中得出（用于由多个路径参数组成的资源）
+func RequireConfirmationFor(verifier ConfirmationVerifier, invalidErr error, action string, resource func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(ConfirmationTokenHeader)
+		if token == "" {
+			abortConfirmation(c, "confirmation_required", "this operation requires a confirmation token", action)
+			return
+		}
+
+		err := verifier.Consume(c.Request.Context(), token, GetTenantIDFromGin(c), GetUserIDFromGin(c), action, resource(c))
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, invalidErr):
+			abortConfirmation(c, "confirmation_invalid", err.Error(), action)
+		default:
+			logger.Error(c.Request.Context(), "failed to verify confirmation token", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.NewErrorResponse(c, http.StatusInternalServerError, "failed to verify confirmation token", nil))
+		}
+	}
+}
+
+// abortConfirmation 终止请求并返回 428（附带需确认的操作，便于客户端发起确认流程）
+func abortConfirmation(c *gin.Context, reason, msg, action string) {
+	c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{
+		"code":     http.StatusPreconditionRequired,
+		"message":  dto.Localize(c, msg),
+		"reason":   reason,
+		"action":   action,
+		"trace_id": c.GetString("trace_id"),
+	})
+}
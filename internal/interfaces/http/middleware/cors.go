@@ -22,7 +22,7 @@ func CORS(cfg CORSConfig) gin.HandlerFunc {
 		cfg.AllowedOrigins = []string{"*"}
 	}
 	if len(cfg.AllowedMethods) == 0 {
-		cfg.AllowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+		cfg.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	}
 	if len(cfg.AllowedHeaders) == 0 {
 		cfg.AllowedHeaders = []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"}
@@ -32,7 +32,7 @@ func CORS(cfg CORSConfig) gin.HandlerFunc {
 		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     cfg.AllowedMethods,
 		AllowHeaders:     cfg.AllowedHeaders,
-		ExposeHeaders:    []string{"X-Request-ID", "X-Trace-ID"},
+		ExposeHeaders:    []string{"X-Request-ID", "X-Trace-ID", "X-Stream-Schema-Version"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/pkg/logger"
 )
 
@@ -46,8 +47,7 @@ func DBTransaction(tx repository.Transactor, tenantCtx repository.TenantContextM
 		// 不应持有全局的数据库事务连接。
 		// 原因：这些请求持续时间长，如果一直占用事务，会迅速耗尽数据库连接池。
 		// 方案：此类请求应在 Handler 内部按需创建短事务 (txMgr.WithTransaction)。
-		path := c.Request.URL.Path
-		if strings.HasSuffix(path, "/stream") || strings.HasSuffix(path, "/foundation/preview") || strings.HasSuffix(path, "/messages") {
+		if isLongRunningPath(c.Request.URL.Path) {
 			c.Next()
 			return
 		}
@@ -104,11 +104,20 @@ func DBTransaction(tx repository.Transactor, tenantCtx repository.TenantContextM
 		// 如果是数据库层面的系统错误（如提交失败、死锁等），记录日志并返回 500。
 		logger.Error(ctx, "db transaction failed", err)
 		if !c.Writer.Written() && c.Writer.Status() < http.StatusBadRequest {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"code":     http.StatusInternalServerError,
-				"message":  "internal server error",
-				"trace_id": c.GetString("trace_id"),
-			})
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.NewErrorResponse(c, http.StatusInternalServerError, "internal server error", nil))
 		}
 	}
 }
+
+// isLongRunningPath 长连接/流式或长时间运行的接口：不持有请求级事务，也不施加请求级截止时间
+func isLongRunningPath(path string) bool {
+	return strings.HasSuffix(path, "/stream") ||
+		strings.HasSuffix(path, "/foundation/preview") ||
+		strings.HasSuffix(path, "/chapters/estimate") ||
+		strings.HasSuffix(path, "/messages") ||
+		strings.HasSuffix(path, "/ingest-notes") ||
+		strings.HasSuffix(path, "/select") ||
+		strings.HasSuffix(path, "/materialize") ||
+		strings.HasPrefix(path, "/v1/ops/diagnostics/") ||
+		path == "/v1/ws"
+}
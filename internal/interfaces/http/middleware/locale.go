@@ -0,0 +1,18 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"z-novel-ai-api/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 按 Accept-Language 协商响应语言，写入 Gin Context（错误消息本地化）与请求 context（应用层按需读取）
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(i18n.GinKey, string(locale))
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Next()
+	}
+}
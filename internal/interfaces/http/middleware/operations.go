@@ -0,0 +1,109 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceMessageHeader 维护公告响应头（值经 URL 编码）
+const MaintenanceMessageHeader = "X-Maintenance-Message"
+
+// OpsSwitchResolver 解析租户生效的运维开关
+type OpsSwitchResolver interface {
+	Resolve(ctx context.Context, tenantID string) (readOnly, generationPaused bool, message string)
+}
+
+// OperationsConfig 运维开关中间件配置
+type OperationsConfig struct {
+	// ExemptPrefixes 不受只读/暂停限制的路径前缀（运维接口自身、认证等）
+	ExemptPrefixes []string
+}
+
+// generationSuffixes 触发模型生成的接口（与 DBTransaction 的长请求豁免一致按路径后缀识别）
+var generationSuffixes = []string{
+	"/chapters/generate",
+	"/regenerate",
+	"/stream",
+	"/foundation/preview",
+	"/foundation/generate",
+	"/messages",
+	"/ingest-notes",
+}
+
+// Operations 运维开关中间件：下发维护公告；只读模式拒绝写请求，暂停生成时拒绝生成类接口（503）。
+func Operations(cfg OperationsConfig, resolver OpsSwitchResolver) gin.HandlerFunc {
+	if resolver == nil {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		readOnly, paused, message := resolver.Resolve(c.Request.Context(), c.GetString("tenant_id"))
+		if message != "" {
+			c.Header(MaintenanceMessageHeader, url.QueryEscape(message))
+		}
+
+		path := c.Request.URL.Path
+		for _, prefix := range cfg.ExemptPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		generation := isGenerationPath(path)
+		switch {
+		case readOnly && (generation || !isReadMethod(c.Request.Method)):
+			abortUnavailable(c, "read_only", "service is in read-only mode", message)
+			return
+		case paused && generation:
+			abortUnavailable(c, "generation_paused", "generation is temporarily paused", message)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isGenerationPath(path string) bool {
+	// 订阅异步任务进度（/v1/jobs/:jid/stream）不触发生成
+	if strings.HasPrefix(path, "/v1/jobs/") {
+		return false
+	}
+	for _, suffix := range generationSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isReadMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// abortUnavailable 终止请求并返回 503
+func abortUnavailable(c *gin.Context, reason, msg, notice string) {
+	body := gin.H{
+		"code":     http.StatusServiceUnavailable,
+		"message":  dto.Localize(c, msg),
+		"reason":   reason,
+		"trace_id": c.GetString("trace_id"),
+	}
+	if notice != "" {
+		body["notice"] = notice
+	}
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, body)
+}
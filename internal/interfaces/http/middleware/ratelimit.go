@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"time"
 
+	"z-novel-ai-api/internal/interfaces/http/dto"
+
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
@@ -21,6 +23,30 @@ type RateLimitConfig struct {
 	Burst int
 	// KeyPrefix Redis Key 前缀
 	KeyPrefix string
+	// KeyFunc 自定义限流维度（默认按租户 + 路径）
+	KeyFunc func(c *gin.Context) string
+	// LimitFunc 按请求解析限流阈值（如租户套餐）；返回 <= 0 时使用 RequestsPerSecond
+	LimitFunc func(c *gin.Context) int
+}
+
+// PlanRateLimitResolver 按租户套餐解析限流阈值（0 表示使用全局配置）
+type PlanRateLimitResolver interface {
+	RequestsPerSecond(ctx context.Context, tenantID string) int
+}
+
+// PlanLimit 基于租户套餐的 LimitFunc
+func PlanLimit(resolver PlanRateLimitResolver) func(c *gin.Context) int {
+	if resolver == nil {
+		return nil
+	}
+	return func(c *gin.Context) int {
+		return resolver.RequestsPerSecond(c.Request.Context(), c.GetString("tenant_id"))
+	}
+}
+
+// ClientIPKey 按客户端 IP + 路径限流（用于免认证接口）
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP() + ":" + c.Request.URL.Path
 }
 
 // RateLimiter 限流器接口
@@ -46,16 +72,27 @@ func RateLimit(cfg RateLimitConfig, limiter RateLimiter) gin.HandlerFunc {
 	}
 
 	return func(c *gin.Context) {
-		// 构建限流 Key：prefix:tenant_id:path
-		tenantID := c.GetString("tenant_id")
-		if tenantID == "" {
-			tenantID = "anonymous"
+		var key string
+		if cfg.KeyFunc != nil {
+			key = cfg.KeyPrefix + ":" + cfg.KeyFunc(c)
+		} else {
+			// 构建限流 Key：prefix:tenant_id:path
+			tenantID := c.GetString("tenant_id")
+			if tenantID == "" {
+				tenantID = "anonymous"
+			}
+			key = cfg.KeyPrefix + ":" + tenantID + ":" + c.Request.URL.Path
 		}
 
-		key := cfg.KeyPrefix + ":" + tenantID + ":" + c.Request.URL.Path
+		limit := cfg.RequestsPerSecond
+		if cfg.LimitFunc != nil {
+			if l := cfg.LimitFunc(c); l > 0 {
+				limit = l
+			}
+		}
 
 		// 检查限流
-		allowed, err := limiter.Allow(c.Request.Context(), key, cfg.RequestsPerSecond, time.Second)
+		allowed, err := limiter.Allow(c.Request.Context(), key, limit, time.Second)
 		if err != nil {
 			// 限流器故障时放行，避免影响业务
 			c.Next()
@@ -63,11 +100,7 @@ func RateLimit(cfg RateLimitConfig, limiter RateLimiter) gin.HandlerFunc {
 		}
 
 		if !allowed {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"code":     429,
-				"message":  "rate limit exceeded",
-				"trace_id": c.GetString("trace_id"),
-			})
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, dto.NewErrorResponse(c, http.StatusTooManyRequests, "rate limit exceeded", nil))
 			return
 		}
 
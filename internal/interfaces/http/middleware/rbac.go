@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/interfaces/http/dto"
 
 	"github.com/gin-gonic/gin"
 )
@@ -93,9 +94,5 @@ func RequireAdmin() gin.HandlerFunc {
 
 // abortForbidden 终止请求并返回 403
 func abortForbidden(c *gin.Context, msg string) {
-	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-		"code":     403,
-		"message":  msg,
-		"trace_id": c.GetString("trace_id"),
-	})
+	c.AbortWithStatusJSON(http.StatusForbidden, dto.NewErrorResponse(c, http.StatusForbidden, msg, nil))
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"runtime/debug"
 
+	"z-novel-ai-api/internal/interfaces/http/dto"
 	"z-novel-ai-api/pkg/errors"
 	"z-novel-ai-api/pkg/logger"
 
@@ -31,7 +32,7 @@ func Recovery() gin.HandlerFunc {
 				// 返回 500 错误
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"code":    errors.CodeInternalError,
-					"message": "internal server error",
+					"message": dto.Localize(c, "internal server error"),
 				})
 			}
 		}()
@@ -0,0 +1,75 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"z-novel-ai-api/internal/interfaces/http/dto"
+	"z-novel-ai-api/pkg/deadline"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeoutRule 路由级截止时间（按路由模板前缀匹配）
+type RequestTimeoutRule struct {
+	Prefix  string
+	Timeout time.Duration
+}
+
+// RequestTimeoutConfig 请求级截止时间配置
+type RequestTimeoutConfig struct {
+	// Default 默认截止时间（0 表示不限制）
+	Default time.Duration
+	// Rules 路由级截止时间（最长前缀优先；0 表示不限制）
+	Rules []RequestTimeoutRule
+}
+
+// RequestTimeout 请求级截止时间中间件：为请求 context 设置截止时间并注入下游超时记录器。
+// 数据库、缓存、向量库与模型客户端均按 context 截止时间返回，并上报首个超时的依赖；
+// 超时后的 5xx 错误响应改写为 504 与按依赖区分的 error_code（见 dto.ErrorWithDetail）。
+// 长连接/流式接口不设截止时间（由各自的生成超时控制）。
+func RequestTimeout(cfg RequestTimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := deadline.WithTracker(c.Request.Context())
+
+		var timeout time.Duration
+		if !isLongRunningPath(c.Request.URL.Path) {
+			timeout = cfg.timeoutFor(routePath(c))
+		}
+		if timeout <= 0 {
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		// Handler 因超时返回却未写响应
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			dto.Error(c, http.StatusGatewayTimeout, "request timed out")
+		}
+	}
+}
+
+// timeoutFor 返回路径对应的截止时间（最长前缀匹配）
+func (cfg RequestTimeoutConfig) timeoutFor(path string) time.Duration {
+	timeout := cfg.Default
+	matched := -1
+	for _, rule := range cfg.Rules {
+		if rule.Prefix == "" || !strings.HasPrefix(path, rule.Prefix) {
+			continue
+		}
+		if len(rule.Prefix) > matched {
+			matched = len(rule.Prefix)
+			timeout = rule.Timeout
+		}
+	}
+	return timeout
+}
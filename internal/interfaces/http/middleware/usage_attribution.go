@@ -0,0 +1,19 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"z-novel-ai-api/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageAttribution 项目作用域路由（/projects/:pid/...）内的 LLM 调用归属到该项目，写入用量流水
+func UsageAttribution() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if projectID := c.Param("pid"); projectID != "" {
+			ctx := service.WithUsageAttribution(c.Request.Context(), service.UsageAttribution{ProjectID: projectID})
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
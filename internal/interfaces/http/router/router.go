@@ -2,10 +2,13 @@
 package router
 
 import (
+	"z-novel-ai-api/internal/application/warmup"
 	"z-novel-ai-api/internal/config"
 	"z-novel-ai-api/internal/domain/repository"
+	"z-novel-ai-api/internal/infrastructure/objectstore"
 	"z-novel-ai-api/internal/interfaces/http/handler"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
+	"z-novel-ai-api/pkg/i18n"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -59,15 +62,38 @@ type RouterHandlers struct {
 	Tenant          *handler.TenantHandler
 	Event           *handler.EventHandler
 	Relation        *handler.RelationHandler
+	Series          *handler.SeriesHandler
+	Public          *handler.PublicHandler
+	Billing         *handler.BillingHandler
+	Manuscript      *handler.ManuscriptHandler
+	SpoilerGuard    *handler.SpoilerGuardHandler
+	Notes           *handler.NotesHandler
+	FeatureFlag     *handler.FeatureFlagHandler
+	Ops             *handler.OpsHandler
+	Candidate       *handler.CandidateHandler
+	Confirmation    *handler.ConfirmationHandler
+	Diagnostics     *handler.DiagnosticsHandler
+	WebSocket       *handler.WebSocketHandler
 
 	// Repositories (needed for eino initialization)
 	TenantRepo    repository.TenantRepository
 	LLMUsageRepo  repository.LLMUsageEventRepository
 	TenantContext repository.TenantContextManager
+	// QuotaReservationRepo 用量记账时同步收缩任务配额预留
+	QuotaReservationRepo repository.QuotaReservationRepository
 
 	// Middleware deps
 	RateLimiter middleware.RateLimiter
 	Transactor  repository.Transactor
+	PlanLimits  middleware.PlanRateLimitResolver
+	OpsSwitches middleware.OpsSwitchResolver
+	// Confirmations 危险操作二次确认令牌核销
+	Confirmations middleware.ConfirmationVerifier
+
+	// Infrastructure
+	ObjectStore objectstore.Store
+	// Warmer 启动预热（网关启动时按 warmup.enabled 执行）
+	Warmer *warmup.Warmer
 }
 
 // NewWithDeps 创建带依赖的路由器（推荐）
@@ -91,6 +117,9 @@ func NewWithDeps(cfg *config.Config, handlers *RouterHandlers) *Router {
 	r.setupMiddleware()
 	r.setupSystemRoutes()
 	r.setupBusinessRoutes()
+	r.setupPublicRoutes()
+	r.setupWebhookRoutes()
+	r.setupObjectRoutes()
 
 	return r
 }
@@ -114,6 +143,10 @@ func (r *Router) setupMiddleware() {
 	// 3. 请求 ID
 	r.engine.Use(middleware.RequestID())
 
+	// 3.1 错误消息语言（Accept-Language）
+	i18n.SetDefault(r.cfg.Server.HTTP.DefaultLocale)
+	r.engine.Use(middleware.Locale())
+
 	// 4. CORS
 	r.engine.Use(middleware.CORS(middleware.CORSConfig{
 		AllowedOrigins: r.cfg.Security.CORS.AllowedOrigins,
@@ -126,8 +159,31 @@ func (r *Router) setupMiddleware() {
 		r.engine.Use(middleware.Metrics())
 	}
 
-	// 6. 审计日志
-	r.engine.Use(middleware.Audit())
+	// 6. 请求体大小限制
+	r.engine.Use(middleware.BodyLimit(bodyLimitConfig(r.cfg.Server.HTTP.BodyLimit)))
+
+	// 7. 响应压缩
+	if comp := r.cfg.Server.HTTP.Compression; comp.Enabled {
+		r.engine.Use(middleware.Compression(middleware.CompressionConfig{
+			Level:        comp.Level,
+			MinSize:      comp.MinSize,
+			ContentTypes: comp.ContentTypes,
+		}))
+	}
+
+	// 8. 访问日志（错误与慢请求全量，成功请求按路由采样）
+	if al := r.cfg.Observability.AccessLog; al.Enabled {
+		routes := make([]middleware.AccessLogRoute, 0, len(al.Routes))
+		for _, rt := range al.Routes {
+			routes = append(routes, middleware.AccessLogRoute{Method: rt.Method, Route: rt.Route, SampleRate: rt.SampleRate})
+		}
+		r.engine.Use(middleware.AccessLog(middleware.AccessLogConfig{
+			SampleRate:    al.SampleRate,
+			SlowThreshold: al.SlowThreshold,
+			SkipPaths:     al.SkipPaths,
+			Routes:        routes,
+		}))
+	}
 }
 
 // setupSystemRoutes 配置系统路由
@@ -148,6 +204,9 @@ func (r *Router) setupBusinessRoutes() {
 	// API v1 路由组
 	v1 := r.engine.Group("/v1")
 
+	// 请求级截止时间（下游数据库/缓存/向量库/模型调用按其超时，超时响应按依赖给出错误码）
+	v1.Use(middleware.RequestTimeout(requestTimeoutConfig(r.cfg.Server.HTTP.RequestTimeout)))
+
 	// 添加认证中间件（全功能模式）
 	v1.Use(middleware.Auth(middleware.AuthConfig{
 		Secret:    r.cfg.Security.JWT.Secret,
@@ -162,11 +221,20 @@ func (r *Router) setupBusinessRoutes() {
 		DefaultTenantID: "default-tenant", // 开发环境默认值
 	}))
 
-	// 添加限流中间件 (全功能方案)
+	// LLM 用量归属（项目作用域路由）
+	v1.Use(middleware.UsageAttribution())
+
+	// 运维开关（只读模式/暂停生成/维护公告）；运维接口与认证接口不受限制
+	v1.Use(middleware.Operations(middleware.OperationsConfig{
+		ExemptPrefixes: []string{"/v1/ops/", "/v1/auth/"},
+	}, r.Handlers.OpsSwitches))
+
+	// 添加限流中间件 (全功能方案；租户套餐配置了限流时按套餐阈值)
 	v1.Use(middleware.RateLimit(middleware.RateLimitConfig{
 		Enabled:           r.cfg.Security.RateLimit.Enabled,
 		RequestsPerSecond: r.cfg.Security.RateLimit.RequestsPerSecond,
 		Burst:             r.cfg.Security.RateLimit.Burst,
+		LimitFunc:         middleware.PlanLimit(r.Handlers.PlanLimits),
 	}, r.rateLimiter))
 
 	// 通过事务绑定租户上下文（确保 RLS 生效）
@@ -191,5 +259,77 @@ func (r *Router) setupBusinessRoutes() {
 		r.Handlers.Tenant,
 		r.Handlers.Event,
 		r.Handlers.Relation,
+		r.Handlers.Series,
+		r.Handlers.Billing,
+		r.Handlers.Manuscript,
+		r.Handlers.SpoilerGuard,
+		r.Handlers.Notes,
+		r.Handlers.FeatureFlag,
+		r.Handlers.Ops,
+		r.Handlers.Candidate,
+		r.Handlers.Confirmation,
+		r.Handlers.Confirmations,
+		r.Handlers.Diagnostics,
+		r.Handlers.WebSocket,
 	)
 }
+
+// setupPublicRoutes 配置公开只读路由（免认证、不走请求级事务，按客户端 IP 限流）
+func (r *Router) setupPublicRoutes() {
+	if !r.cfg.PublicAPI.Enabled || r.Handlers.Public == nil {
+		return
+	}
+
+	public := r.engine.Group("/public/v1")
+	public.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Enabled:           r.cfg.PublicAPI.RateLimit.Enabled,
+		RequestsPerSecond: r.cfg.PublicAPI.RateLimit.RequestsPerSecond,
+		Burst:             r.cfg.PublicAPI.RateLimit.Burst,
+		KeyPrefix:         "ratelimit:public",
+		KeyFunc:           middleware.ClientIPKey,
+	}, r.rateLimiter))
+
+	RegisterPublicRoutes(public, r.Handlers.Public)
+}
+
+// setupWebhookRoutes 配置第三方回调路由（免认证，由各处理器自行验签；不走请求级事务）
+func (r *Router) setupWebhookRoutes() {
+	if !r.cfg.Billing.Enabled || r.Handlers.Billing == nil {
+		return
+	}
+
+	webhooks := r.engine.Group("/webhooks")
+	RegisterWebhookRoutes(webhooks, r.Handlers.Billing)
+}
+
+// setupObjectRoutes 本地对象存储的签名 URL 读写路由（免认证，由签名校验访问权限；S3 类驱动直连存储服务）
+func (r *Router) setupObjectRoutes() {
+	local, ok := r.Handlers.ObjectStore.(*objectstore.LocalStore)
+	if !ok || local.MountPath() == "" {
+		return
+	}
+
+	h := gin.WrapH(local.Handler())
+	route := local.MountPath() + "/*key"
+	r.engine.GET(route, h)
+	r.engine.HEAD(route, h)
+	r.engine.PUT(route, h)
+}
+
+// bodyLimitConfig 将配置转换为请求体限制中间件参数
+func bodyLimitConfig(cfg config.BodyLimitConfig) middleware.BodyLimitConfig {
+	rules := make([]middleware.BodyLimitRule, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		rules = append(rules, middleware.BodyLimitRule{Prefix: route.Prefix, MaxBytes: route.MaxBytes})
+	}
+	return middleware.BodyLimitConfig{DefaultMaxBytes: cfg.DefaultMaxBytes, Rules: rules}
+}
+
+// requestTimeoutConfig 将配置转换为请求级截止时间中间件参数
+func requestTimeoutConfig(cfg config.RequestTimeoutConfig) middleware.RequestTimeoutConfig {
+	rules := make([]middleware.RequestTimeoutRule, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		rules = append(rules, middleware.RequestTimeoutRule{Prefix: route.Prefix, Timeout: route.Timeout})
+	}
+	return middleware.RequestTimeoutConfig{Default: cfg.Default, Rules: rules}
+}
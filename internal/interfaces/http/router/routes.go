@@ -2,6 +2,7 @@
 package router
 
 import (
+	"z-novel-ai-api/internal/application/confirm"
 	"z-novel-ai-api/internal/interfaces/http/handler"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 
@@ -27,7 +28,28 @@ func RegisterV1Routes(
 	tenantHandler *handler.TenantHandler,
 	eventHandler *handler.EventHandler,
 	relationHandler *handler.RelationHandler,
+	seriesHandler *handler.SeriesHandler,
+	billingHandler *handler.BillingHandler,
+	manuscriptHandler *handler.ManuscriptHandler,
+	spoilerGuardHandler *handler.SpoilerGuardHandler,
+	notesHandler *handler.NotesHandler,
+	featureFlagHandler *handler.FeatureFlagHandler,
+	opsHandler *handler.OpsHandler,
+	candidateHandler *handler.CandidateHandler,
+	confirmationHandler *handler.ConfirmationHandler,
+	confirmations middleware.ConfirmationVerifier,
+	diagnosticsHandler *handler.DiagnosticsHandler,
+	wsHandler *handler.WebSocketHandler,
 ) {
+	// 危险操作二次确认：先 POST /v1/confirmations 申请令牌，再在执行请求中出示
+	requireConfirmation := func(action, resourceParam string) gin.HandlerFunc {
+		return middleware.RequireConfirmation(confirmations, confirm.ErrInvalidToken, action, resourceParam)
+	}
+	requireBranchConfirmation := middleware.RequireConfirmationFor(confirmations, confirm.ErrInvalidToken, confirm.ActionArtifactBranchDelete, func(c *gin.Context) string {
+		return confirm.BranchResourceID(c.Param("aid"), c.Param("branch"))
+	})
+	v1.POST("/confirmations", confirmationHandler.CreateConfirmation)
+
 	// 认证管理
 	auth := v1.Group("/auth")
 	{
@@ -49,13 +71,23 @@ func RegisterV1Routes(
 		projects.GET("/:pid/entities", middleware.RequirePermission(middleware.PermProjectRead), entityHandler.ListEntities)
 		projects.GET("/:pid/events", middleware.RequirePermission(middleware.PermProjectRead), eventHandler.ListEvents)
 		projects.GET("/:pid/relations", middleware.RequirePermission(middleware.PermProjectRead), relationHandler.ListRelations)
+		projects.GET("/:pid/pacing", middleware.RequirePermission(middleware.PermProjectRead), chapterHandler.GetPacing)
+		projects.GET("/:pid/health", middleware.RequirePermission(middleware.PermProjectRead), projectHandler.GetProjectHealth)
+		projects.GET("/:pid/outline/coverage", middleware.RequirePermission(middleware.PermProjectRead), chapterHandler.GetOutlineCoverage)
+		projects.GET("/:pid/export", middleware.RequirePermission(middleware.PermProjectRead), manuscriptHandler.ExportManuscript)
 		projects.GET("/:pid/jobs", middleware.RequirePermission(middleware.PermProjectRead), jobHandler.ListProjectJobs)
+		projects.GET("/:pid/canon/:type", middleware.RequirePermission(middleware.PermProjectRead), seriesHandler.GetProjectCanon)
+		projects.GET("/:pid/spoiler-guards", middleware.RequirePermission(middleware.PermProjectRead), spoilerGuardHandler.ListSpoilerGuards)
+		projects.POST("/:pid/retrieval/debug", middleware.RequirePermission(middleware.PermProjectRead), retrievalHandler.DebugChapterRetrieval) // 只读：按生成参数复现召回
+		projects.GET("/:pid/vectors/segments", middleware.RequirePermission(middleware.PermProjectRead), retrievalHandler.ListProjectSegments)   // 只读：游标分页枚举已索引片段
+		projects.GET("/:pid/vectors/stats", middleware.RequirePermission(middleware.PermProjectRead), retrievalHandler.GetProjectSegmentStats)   // 只读：按片段类型统计
 
 		// 写操作（需要 project:write 权限）
 		projects.POST("", middleware.RequirePermission(middleware.PermProjectWrite), projectHandler.CreateProject)
 		projects.PUT("/:pid", middleware.RequirePermission(middleware.PermProjectWrite), projectHandler.UpdateProject)
-		projects.DELETE("/:pid", middleware.RequirePermission(middleware.PermProjectWrite), projectHandler.DeleteProject)
+		projects.DELETE("/:pid", middleware.RequirePermission(middleware.PermProjectWrite), requireConfirmation(confirm.ActionProjectDelete, "pid"), projectHandler.DeleteProject)
 		projects.PUT("/:pid/settings", middleware.RequirePermission(middleware.PermProjectWrite), projectHandler.UpdateProjectSettings)
+		projects.POST("/:pid/settings/reset", middleware.RequirePermission(middleware.PermProjectWrite), projectHandler.ResetProjectSettings)
 
 		// 卷写操作
 		projects.POST("/:pid/volumes", middleware.RequirePermission(middleware.PermProjectWrite), volumeHandler.CreateVolume)
@@ -64,28 +96,42 @@ func RegisterV1Routes(
 		// 章节写操作
 		projects.POST("/:pid/chapters", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.CreateChapter)
 
+		// 运维任务（导出/重建索引/清理向量，进度见任务列表）
+		projects.POST("/:pid/jobs", middleware.RequirePermission(middleware.PermProjectWrite), jobHandler.CreateProjectJob)
+
 		// 章节生成（需要 chapter:generate 权限）
+		projects.POST("/:pid/chapters/renumber", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.RenumberChapters)
 		projects.POST("/:pid/chapters/generate", middleware.RequirePermission(middleware.PermChapterGenerate), chapterHandler.GenerateChapter)
+		projects.POST("/:pid/chapters/estimate", middleware.RequirePermission(middleware.PermChapterGenerate), chapterHandler.EstimateChapter)
 
 		// 设定集生成（一期：复用 chapter:generate；落库 apply 需要 project:write）
 		projects.POST("/:pid/foundation/preview", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.PreviewFoundation)
-		projects.GET("/:pid/foundation/stream", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.StreamFoundation)  // SSE (GET)
-		projects.POST("/:pid/foundation/stream", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.StreamFoundation) // SSE (POST)
+		projects.GET("/:pid/foundation/stream", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.StreamFoundation)      // SSE (GET)
+		projects.POST("/:pid/foundation/stream", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.StreamFoundationPost) // SSE (POST)
 		projects.POST("/:pid/foundation/generate", middleware.RequirePermission(middleware.PermChapterGenerate), foundationHandler.GenerateFoundation)
 		projects.POST("/:pid/foundation/apply", middleware.RequirePermission(middleware.PermProjectWrite), foundationHandler.ApplyFoundation)
 
 		// 长期会话（按任务切换生成构件版本；写操作需要 project:write）
 		projects.POST("/:pid/sessions", middleware.RequirePermission(middleware.PermProjectWrite), conversationHandler.CreateSession)
 		projects.GET("/:pid/sessions/:sid", middleware.RequirePermission(middleware.PermProjectRead), conversationHandler.GetSession)
+		projects.PATCH("/:pid/sessions/:sid", middleware.RequirePermission(middleware.PermProjectWrite), conversationHandler.UpdateSession)
 		projects.GET("/:pid/sessions/:sid/turns", middleware.RequirePermission(middleware.PermProjectRead), conversationHandler.ListTurns)
+		projects.GET("/:pid/sessions/:sid/export", middleware.RequirePermission(middleware.PermProjectRead), conversationHandler.ExportSession)
 		projects.POST("/:pid/sessions/:sid/messages", middleware.RequirePermission(middleware.PermProjectWrite), conversationHandler.SendMessage)
+		projects.POST("/:pid/sessions/:sid/turns/:tid/materialize", middleware.RequirePermission(middleware.PermProjectWrite), conversationHandler.MaterializeTurn) // 草稿轮次转为构件版本
 
-		// 构件版本（读：project:read；回滚：project:write）
+		// 构件版本（读：project:read；回滚、标签：project:write）
 		projects.GET("/:pid/artifacts", middleware.RequirePermission(middleware.PermProjectRead), artifactHandler.ListArtifacts)
+		projects.POST("/:pid/artifacts/apply", middleware.RequirePermission(middleware.PermProjectWrite), artifactHandler.Apply)
 		projects.GET("/:pid/artifacts/:aid/versions", middleware.RequirePermission(middleware.PermProjectRead), artifactHandler.ListVersions)
 		projects.GET("/:pid/artifacts/:aid/branches", middleware.RequirePermission(middleware.PermProjectRead), artifactHandler.ListBranches)
+		projects.DELETE("/:pid/artifacts/:aid/branches/:branch", middleware.RequirePermission(middleware.PermProjectWrite), requireBranchConfirmation, artifactHandler.DeleteBranch)
 		projects.GET("/:pid/artifacts/:aid/compare", middleware.RequirePermission(middleware.PermProjectRead), artifactHandler.CompareVersions)
 		projects.POST("/:pid/artifacts/:aid/rollback", middleware.RequirePermission(middleware.PermProjectWrite), artifactHandler.Rollback)
+		projects.GET("/:pid/artifacts/:aid/tags", middleware.RequirePermission(middleware.PermProjectRead), artifactHandler.ListTags)
+		projects.POST("/:pid/artifacts/:aid/versions/:vid/tags", middleware.RequirePermission(middleware.PermProjectWrite), artifactHandler.CreateTag)
+		projects.DELETE("/:pid/artifacts/:aid/tags/:tag", middleware.RequirePermission(middleware.PermProjectWrite), artifactHandler.DeleteTag)
+		projects.POST("/:pid/ingest-notes", middleware.RequirePermission(middleware.PermProjectWrite), notesHandler.IngestNotes)
 
 		// 实体写操作
 		projects.POST("/:pid/entities", middleware.RequirePermission(middleware.PermProjectWrite), entityHandler.CreateEntity)
@@ -95,6 +141,22 @@ func RegisterV1Routes(
 
 		// 关系写操作
 		projects.POST("/:pid/relations", middleware.RequirePermission(middleware.PermProjectWrite), relationHandler.CreateRelation)
+
+		// 剧透保护写操作
+		projects.POST("/:pid/spoiler-guards", middleware.RequirePermission(middleware.PermProjectWrite), spoilerGuardHandler.CreateSpoilerGuard)
+	}
+
+	// 系列管理（多部曲：跨书检索与设定继承）
+	series := v1.Group("/series")
+	{
+		series.GET("", middleware.RequirePermission(middleware.PermProjectRead), seriesHandler.ListSeries)
+		series.GET("/:srid", middleware.RequirePermission(middleware.PermProjectRead), seriesHandler.GetSeries)
+		series.GET("/:srid/stats", middleware.RequirePermission(middleware.PermProjectRead), seriesHandler.GetSeriesStats)
+		series.POST("", middleware.RequirePermission(middleware.PermProjectWrite), seriesHandler.CreateSeries)
+		series.PUT("/:srid", middleware.RequirePermission(middleware.PermProjectWrite), seriesHandler.UpdateSeries)
+		series.DELETE("/:srid", middleware.RequirePermission(middleware.PermProjectWrite), seriesHandler.DeleteSeries)
+		series.POST("/:srid/projects", middleware.RequirePermission(middleware.PermProjectWrite), seriesHandler.AttachProject)
+		series.DELETE("/:srid/projects/:pid", middleware.RequirePermission(middleware.PermProjectWrite), seriesHandler.DetachProject)
 	}
 
 	// 对话创建项目（Project 未存在时的会话）
@@ -114,6 +176,12 @@ func RegisterV1Routes(
 		events.DELETE("/:evid", middleware.RequirePermission(middleware.PermProjectWrite), eventHandler.DeleteEvent)
 	}
 
+	// 剧透保护管理
+	spoilerGuards := v1.Group("/spoiler-guards")
+	{
+		spoilerGuards.DELETE("/:gid", middleware.RequirePermission(middleware.PermProjectWrite), spoilerGuardHandler.DeleteSpoilerGuard)
+	}
+
 	// 关系管理
 	relations := v1.Group("/relations")
 	{
@@ -136,7 +204,16 @@ func RegisterV1Routes(
 		chapters.GET("/:cid", middleware.RequirePermission(middleware.PermProjectRead), chapterHandler.GetChapter)
 		chapters.GET("/:cid/stream", middleware.RequirePermission(middleware.PermChapterGenerate), streamHandler.StreamChapter) // SSE
 		chapters.PUT("/:cid", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.UpdateChapter)
+		chapters.PATCH("/:cid/content", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.AutosaveChapter) // 自动保存
+		chapters.GET("/:cid/versions/:a/diff/:b", middleware.RequirePermission(middleware.PermProjectRead), chapterHandler.DiffChapterVersions)
+		chapters.GET("/:cid/pins", middleware.RequirePermission(middleware.PermProjectRead), chapterHandler.GetContextPins)
+		chapters.PUT("/:cid/pins", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.UpdateContextPins)
+		chapters.POST("/:cid/suggest-titles", middleware.RequirePermission(middleware.PermChapterGenerate), chapterHandler.SuggestTitles)
+		chapters.POST("/:cid/accept-title", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.AcceptTitle)
+		chapters.GET("/:cid/spoiler-check", middleware.RequirePermission(middleware.PermProjectRead), spoilerGuardHandler.CheckChapter)
+		chapters.PUT("/:cid/events", middleware.RequirePermission(middleware.PermProjectWrite), eventHandler.ReplaceChapterEvents)
 		chapters.DELETE("/:cid", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.DeleteChapter)
+		chapters.POST("/:cid/move", middleware.RequirePermission(middleware.PermProjectWrite), chapterHandler.MoveChapter)
 		chapters.POST("/:cid/regenerate", middleware.RequirePermission(middleware.PermChapterGenerate), chapterHandler.RegenerateChapter)
 	}
 
@@ -160,10 +237,22 @@ func RegisterV1Routes(
 	// 任务管理
 	jobs := v1.Group("/jobs")
 	{
+		jobs.POST("/batch-status", middleware.RequirePermission(middleware.PermProjectRead), jobHandler.BatchJobStatus)
 		jobs.GET("/:jid", middleware.RequirePermission(middleware.PermProjectRead), jobHandler.GetJob)
+		jobs.GET("/:jid/events", middleware.RequirePermission(middleware.PermProjectRead), jobHandler.ListJobEvents)
+		jobs.GET("/:jid/stream", middleware.RequirePermission(middleware.PermProjectRead), streamHandler.StreamJob) // SSE：订阅异步任务实时进度
+		jobs.GET("/:jid/candidates", middleware.RequirePermission(middleware.PermProjectRead), candidateHandler.ListCandidates)
+		jobs.POST("/:jid/candidates/:cand/select", middleware.RequirePermission(middleware.PermProjectWrite), candidateHandler.SelectCandidate)
+		jobs.POST("/:jid/candidates/:cand/discard", middleware.RequirePermission(middleware.PermProjectWrite), candidateHandler.DiscardCandidate)
 		jobs.DELETE("/:jid", middleware.RequirePermission(middleware.PermProjectWrite), jobHandler.CancelJob)
+		jobs.POST("/:jid/cancel", middleware.RequirePermission(middleware.PermProjectWrite), jobHandler.InterruptJob) // 取消并中止运行中的 LLM 调用
+		jobs.PUT("/:jid/priority", middleware.RequireAdmin(), jobHandler.UpdateJobPriority)
+		jobs.POST("/:jid/replay", middleware.RequireAdmin(), jobHandler.ReplayJob) // 沙箱回放：结果不落库
 	}
 
+	// 实时事件 WebSocket：按项目/任务多路订阅任务进度、正文分片与冲突提醒
+	v1.GET("/ws", middleware.RequirePermission(middleware.PermProjectRead), wsHandler.Connect)
+
 	// 用户管理
 	users := v1.Group("/users")
 	{
@@ -176,18 +265,97 @@ func RegisterV1Routes(
 
 		// 管理操作（仅 admin 可访问）
 		users.PUT("/:id/role", middleware.RequireAdmin(), userHandler.UpdateUserRole)
-		users.DELETE("/:id", middleware.RequireAdmin(), userHandler.DeleteUser)
+		users.DELETE("/:id", middleware.RequireAdmin(), requireConfirmation(confirm.ActionUserDelete, "id"), userHandler.DeleteUser)
 	}
 
+	// AI 来源标记校验
+	v1.POST("/provenance/verify", manuscriptHandler.VerifyProvenance)
+
+	// 订阅套餐
+	v1.GET("/plans", tenantHandler.ListPlans)
+
 	// 租户管理
 	tenants := v1.Group("/tenants")
 	{
 		// 当前租户操作（所有已认证用户可访问当前租户信息）
 		tenants.GET("/current", tenantHandler.GetCurrentTenant)
+		tenants.GET("/current/plan", tenantHandler.GetCurrentPlan)
+		tenants.GET("/current/feature-flags", featureFlagHandler.ListFeatureFlags)
+		tenants.GET("/current/project-defaults", tenantHandler.GetProjectDefaults)
+		tenants.GET("/current/vector-usage", middleware.RequireAdmin(), tenantHandler.GetVectorUsage)
+		tenants.GET("/current/artifact-validation", middleware.RequireAdmin(), tenantHandler.GetArtifactValidation)
 
 		// 管理操作（仅 admin 可访问）
 		tenants.PUT("/current", middleware.RequireAdmin(), tenantHandler.UpdateCurrentTenant)
+		tenants.PUT("/current/plan", middleware.RequireAdmin(), tenantHandler.ChangeCurrentPlan)
+		tenants.PUT("/current/project-defaults", middleware.RequireAdmin(), tenantHandler.UpdateProjectDefaults)
+		tenants.PUT("/current/artifact-validation", middleware.RequireAdmin(), tenantHandler.UpdateArtifactValidation)
+		tenants.PUT("/current/feature-flags/:key", middleware.RequireAdmin(), featureFlagHandler.SetFeatureFlagOverride)
+		tenants.DELETE("/current/feature-flags/:key", middleware.RequireAdmin(), featureFlagHandler.DeleteFeatureFlagOverride)
 		tenants.GET("", middleware.RequireAdmin(), tenantHandler.ListTenants)
 		tenants.POST("", middleware.RequireAdmin(), tenantHandler.CreateTenant)
+		tenants.GET("/:tid/llm-usage", middleware.RequireAdmin(), tenantHandler.ListLLMUsage) // 用量流水查询 / CSV 导出（财务对账）
+	}
+
+	// 管理员运维操作（仅 admin）
+	admin := v1.Group("/admin", middleware.RequireAdmin())
+	{
+		admin.POST("/projects/:pid/recount", jobHandler.RecountProject) // 重新统计项目字数/卷汇总/实体出场/向量用量
+	}
+
+	// 运维开关：只读模式/暂停生成/维护公告（状态所有已认证用户可查看，设置仅 admin）
+	opsGroup := v1.Group("/ops")
+	{
+		opsGroup.GET("/status", opsHandler.GetStatus)
+		opsGroup.GET("/providers", middleware.RequireAdmin(), opsHandler.ListProviders)
+		opsGroup.GET("/warmup", middleware.RequireAdmin(), opsHandler.GetWarmup)
+		opsGroup.POST("/warmup", middleware.RequireAdmin(), opsHandler.TriggerWarmup)
+		opsGroup.PUT("/switches/global", middleware.RequireAdmin(), opsHandler.UpdateGlobalSwitches)
+		opsGroup.PUT("/switches/tenants/:tid", middleware.RequireAdmin(), opsHandler.UpdateTenantSwitches)
+		opsGroup.DELETE("/switches/tenants/:tid", middleware.RequireAdmin(), opsHandler.ClearTenantSwitches)
+		opsGroup.GET("/queues", middleware.RequireAdmin(), opsHandler.ListQueues)
+		opsGroup.GET("/queues/:queue/dlq", middleware.RequireAdmin(), opsHandler.ListDLQ)
+		opsGroup.POST("/queues/:queue/dlq/requeue", middleware.RequireAdmin(), opsHandler.RequeueDLQ)
+		opsGroup.POST("/queues/:queue/dlq/purge", middleware.RequireAdmin(), opsHandler.PurgeDLQ)
+		opsGroup.GET("/queues/:queue/dlq/:id", middleware.RequireAdmin(), opsHandler.GetDLQMessage)
+		opsGroup.GET("/queues/:queue/pending", middleware.RequireAdmin(), opsHandler.ListPending)
+		opsGroup.POST("/tenants/:tid/balance", middleware.RequireAdmin(), opsHandler.AdjustTenantBalance)
+		opsGroup.POST("/tenants/:tid/generation-consistency", middleware.RequireAdmin(), opsHandler.CheckGenerationConsistency)
+		opsGroup.PUT("/tenants/:tid/foundation-plan-limits", middleware.RequireAdmin(), opsHandler.UpdateTenantFoundationPlanLimits)
+		opsGroup.DELETE("/tenants/:tid/foundation-plan-limits", middleware.RequireAdmin(), opsHandler.ClearTenantFoundationPlanLimits)
+	}
+
+	// 运行时诊断：pprof/expvar/快照（需开启 observability.diagnostics.enabled，仅 admin）
+	if diagnosticsHandler.Enabled() {
+		diagnosticsGroup := opsGroup.Group("/diagnostics", middleware.RequireAdmin())
+		{
+			diagnosticsGroup.GET("/pprof/*profile", diagnosticsHandler.Pprof)
+			diagnosticsGroup.GET("/vars", diagnosticsHandler.Vars)
+			diagnosticsGroup.GET("/snapshots", diagnosticsHandler.ListSnapshots)
+			diagnosticsGroup.POST("/snapshots", diagnosticsHandler.CaptureSnapshot)
+			diagnosticsGroup.GET("/snapshots/:name", diagnosticsHandler.DownloadSnapshot)
+		}
+	}
+
+	// 计费：购买记录（仅 admin 可访问）
+	billingGroup := v1.Group("/billing")
+	{
+		billingGroup.GET("/invoices", middleware.RequireAdmin(), billingHandler.ListInvoices)
+		billingGroup.GET("/invoices/:iid", middleware.RequireAdmin(), billingHandler.GetInvoice)
+	}
+}
+
+// RegisterWebhookRoutes 注册第三方回调路由
+func RegisterWebhookRoutes(webhooks *gin.RouterGroup, billingHandler *handler.BillingHandler) {
+	webhooks.POST("/billing", billingHandler.HandleWebhook)
+}
+
+// RegisterPublicRoutes 注册公开只读路由
+func RegisterPublicRoutes(public *gin.RouterGroup, publicHandler *handler.PublicHandler) {
+	projects := public.Group("/:tenant/projects")
+	{
+		projects.GET("/:pid", publicHandler.GetProject)
+		projects.GET("/:pid/chapters", publicHandler.ListChapters)
+		projects.GET("/:pid/chapters/:cid", publicHandler.GetChapter)
 	}
 }
@@ -0,0 +1,229 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ArtifactRepository 项目构件仓储内存实现
+type ArtifactRepository struct {
+	store *Store
+}
+
+// NewArtifactRepository 创建项目构件仓储
+func NewArtifactRepository(store *Store) *ArtifactRepository {
+	return &ArtifactRepository{store: store}
+}
+
+// branchThenVersionDesc 等价于 ORDER BY branch_key, version_no DESC
+func branchThenVersionDesc(a, b *entity.ArtifactVersion) bool {
+	if a.BranchKey != b.BranchKey {
+		return a.BranchKey < b.BranchKey
+	}
+	return a.VersionNo > b.VersionNo
+}
+
+// EnsureArtifact 获取或创建项目构件（project_id + type 唯一）
+func (r *ArtifactRepository) EnsureArtifact(ctx context.Context, tenantID, projectID string, artifactType entity.ArtifactType) (*entity.ProjectArtifact, error) {
+	match := func(a *entity.ProjectArtifact) bool { return a.ProjectID == projectID && a.Type == artifactType }
+	if art := r.store.artifacts.first(ctx, match, nil); art != nil {
+		return art, nil
+	}
+	created := &entity.ProjectArtifact{
+		TenantID:  tenantID,
+		ProjectID: projectID,
+		Type:      artifactType,
+	}
+	if err := r.store.artifacts.insert(ctx, created); err != nil {
+		return nil, fmt.Errorf("failed to create artifact: %w", err)
+	}
+	return created, nil
+}
+
+// GetArtifactByID 根据 ID 获取构件
+func (r *ArtifactRepository) GetArtifactByID(ctx context.Context, id string) (*entity.ProjectArtifact, error) {
+	return r.store.artifacts.get(ctx, id), nil
+}
+
+// ListArtifactsByProject 获取项目构件列表（按创建时间升序）
+func (r *ArtifactRepository) ListArtifactsByProject(ctx context.Context, projectID string) ([]*entity.ProjectArtifact, error) {
+	return r.store.artifacts.find(ctx, func(a *entity.ProjectArtifact) bool { return a.ProjectID == projectID },
+		func(a, b *entity.ProjectArtifact) bool { return a.CreatedAt.Before(b.CreatedAt) }), nil
+}
+
+// CreateVersion 创建构件版本（artifact_id + version_no 唯一）
+func (r *ArtifactRepository) CreateVersion(ctx context.Context, version *entity.ArtifactVersion) error {
+	if r.store.artifactVersions.count(ctx, func(v *entity.ArtifactVersion) bool {
+		return v.ArtifactID == version.ArtifactID && v.VersionNo == version.VersionNo
+	}) > 0 {
+		return fmt.Errorf("failed to create artifact version: %w", ErrDuplicateKey)
+	}
+	if err := r.store.artifactVersions.insert(ctx, version); err != nil {
+		return fmt.Errorf("failed to create artifact version: %w", err)
+	}
+	return nil
+}
+
+// GetLatestVersionNo 获取最新版本号（无版本时返回 0）
+func (r *ArtifactRepository) GetLatestVersionNo(ctx context.Context, artifactID string) (int, error) {
+	maxNo := 0
+	for _, v := range r.store.artifactVersions.find(ctx, func(v *entity.ArtifactVersion) bool { return v.ArtifactID == artifactID }, nil) {
+		maxNo = max(maxNo, v.VersionNo)
+	}
+	return maxNo, nil
+}
+
+// GetVersionByID 根据 ID 获取版本
+func (r *ArtifactRepository) GetVersionByID(ctx context.Context, id string) (*entity.ArtifactVersion, error) {
+	return r.store.artifactVersions.get(ctx, id), nil
+}
+
+// ListVersions 获取版本列表（可按分支、标签过滤；按版本号倒序）
+func (r *ArtifactRepository) ListVersions(ctx context.Context, artifactID string, branchKey string, tag string, pagination repository.Pagination) (*repository.PagedResult[*entity.ArtifactVersion], error) {
+	var tagged []string
+	if tag != "" {
+		for _, t := range r.store.artifactTags.find(ctx, func(t *entity.ArtifactVersionTag) bool {
+			return t.ArtifactID == artifactID && t.Tag == tag
+		}, nil) {
+			tagged = append(tagged, t.VersionID)
+		}
+	}
+	rows := r.store.artifactVersions.find(ctx, func(v *entity.ArtifactVersion) bool {
+		if v.ArtifactID != artifactID {
+			return false
+		}
+		if branchKey != "" && v.BranchKey != branchKey {
+			return false
+		}
+		return tag == "" || slices.Contains(tagged, v.ID)
+	}, func(a, b *entity.ArtifactVersion) bool { return a.VersionNo > b.VersionNo })
+	return paginate(rows, pagination), nil
+}
+
+// GetLatestVersionByBranch 获取分支最新版本
+func (r *ArtifactRepository) GetLatestVersionByBranch(ctx context.Context, artifactID string, branchKey string) (*entity.ArtifactVersion, error) {
+	return r.store.artifactVersions.first(ctx, func(v *entity.ArtifactVersion) bool {
+		return v.ArtifactID == artifactID && v.BranchKey == branchKey
+	}, func(a, b *entity.ArtifactVersion) bool { return a.VersionNo > b.VersionNo }), nil
+}
+
+// ListBranchHeads 获取各分支最新版本（不含内容，按分支名排序）
+func (r *ArtifactRepository) ListBranchHeads(ctx context.Context, artifactID string) ([]*entity.ArtifactVersion, error) {
+	rows := r.store.artifactVersions.find(ctx, func(v *entity.ArtifactVersion) bool { return v.ArtifactID == artifactID }, branchThenVersionDesc)
+	heads := make([]*entity.ArtifactVersion, 0)
+	for _, v := range rows {
+		if n := len(heads); n > 0 && heads[n-1].BranchKey == v.BranchKey {
+			continue
+		}
+		v.Content = nil
+		heads = append(heads, v)
+	}
+	return heads, nil
+}
+
+// ListVersionSummaries 获取版本摘要（ContentBytes 为内容字节数，未模拟 TOAST 压缩）
+func (r *ArtifactRepository) ListVersionSummaries(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionSummary, error) {
+	rows := r.store.artifactVersions.find(ctx, func(v *entity.ArtifactVersion) bool { return v.ArtifactID == artifactID }, branchThenVersionDesc)
+	out := make([]*entity.ArtifactVersionSummary, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, &entity.ArtifactVersionSummary{
+			ID:           v.ID,
+			VersionNo:    v.VersionNo,
+			BranchKey:    v.BranchKey,
+			ContentBytes: int64(len(v.Content)),
+			CreatedAt:    v.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// DeleteVersions 删除版本（跳过当前激活版本与带标签的版本），返回实际删除的 ID
+func (r *ArtifactRepository) DeleteVersions(ctx context.Context, artifactID string, versionIDs []string) ([]string, error) {
+	if len(versionIDs) == 0 {
+		return nil, nil
+	}
+	protected := make(map[string]bool)
+	for _, a := range r.store.artifacts.find(ctx, func(a *entity.ProjectArtifact) bool { return a.ActiveVersionID != nil }, nil) {
+		protected[*a.ActiveVersionID] = true
+	}
+	for _, t := range r.store.artifactTags.find(ctx, nil, nil) {
+		protected[t.VersionID] = true
+	}
+	var deleted []string
+	r.store.artifactVersions.delete(ctx, func(v *entity.ArtifactVersion) bool {
+		if v.ArtifactID != artifactID || !slices.Contains(versionIDs, v.ID) || protected[v.ID] {
+			return false
+		}
+		deleted = append(deleted, v.ID)
+		return true
+	})
+	return deleted, nil
+}
+
+// SetActiveVersion 设置当前激活版本
+func (r *ArtifactRepository) SetActiveVersion(ctx context.Context, artifactID, versionID string) error {
+	r.store.artifacts.updateByID(ctx, artifactID, true, func(a *entity.ProjectArtifact) { a.ActiveVersionID = &versionID })
+	return nil
+}
+
+// CreateTag 创建版本标签（artifact_id + tag 唯一）
+func (r *ArtifactRepository) CreateTag(ctx context.Context, tag *entity.ArtifactVersionTag) error {
+	if existing, _ := r.GetTag(ctx, tag.ArtifactID, tag.Tag); existing != nil {
+		return fmt.Errorf("failed to create artifact version tag: %w", ErrDuplicateKey)
+	}
+	if err := r.store.artifactTags.insert(ctx, tag); err != nil {
+		return fmt.Errorf("failed to create artifact version tag: %w", err)
+	}
+	return nil
+}
+
+// GetTag 获取标签（附带所指版本的版本号与分支）
+func (r *ArtifactRepository) GetTag(ctx context.Context, artifactID, tag string) (*entity.ArtifactVersionTag, error) {
+	tags := r.tagsWithVersion(ctx, func(t *entity.ArtifactVersionTag) bool { return t.ArtifactID == artifactID && t.Tag == tag })
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags[0], nil
+}
+
+// ListTags 获取构件全部标签（按版本号倒序）
+func (r *ArtifactRepository) ListTags(ctx context.Context, artifactID string) ([]*entity.ArtifactVersionTag, error) {
+	tags := r.tagsWithVersion(ctx, func(t *entity.ArtifactVersionTag) bool { return t.ArtifactID == artifactID })
+	slices.SortStableFunc(tags, func(a, b *entity.ArtifactVersionTag) int {
+		if a.VersionNo != b.VersionNo {
+			return b.VersionNo - a.VersionNo
+		}
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return tags, nil
+}
+
+// tagsWithVersion 模拟标签与版本的 JOIN（版本不存在的标签不返回）
+func (r *ArtifactRepository) tagsWithVersion(ctx context.Context, match func(*entity.ArtifactVersionTag) bool) []*entity.ArtifactVersionTag {
+	tags := r.store.artifactTags.find(ctx, match, nil)
+	out := make([]*entity.ArtifactVersionTag, 0, len(tags))
+	for _, t := range tags {
+		v := r.store.artifactVersions.get(ctx, t.VersionID)
+		if v == nil {
+			continue
+		}
+		t.VersionNo = v.VersionNo
+		t.BranchKey = v.BranchKey
+		out = append(out, t)
+	}
+	return out
+}
+
+// DeleteTag 删除标签，返回是否存在
+func (r *ArtifactRepository) DeleteTag(ctx context.Context, artifactID, tag string) (bool, error) {
+	n := r.store.artifactTags.delete(ctx, func(t *entity.ArtifactVersionTag) bool {
+		return t.ArtifactID == artifactID && t.Tag == tag
+	})
+	return n > 0, nil
+}
+
+var _ repository.ArtifactRepository = (*ArtifactRepository)(nil)
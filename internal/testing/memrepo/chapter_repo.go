@@ -0,0 +1,384 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ChapterRepository 章节仓储内存实现
+type ChapterRepository struct {
+	store *Store
+}
+
+// NewChapterRepository 创建章节仓储
+func NewChapterRepository(store *Store) *ChapterRepository {
+	return &ChapterRepository{store: store}
+}
+
+func chapterSeqLess(a, b *entity.Chapter) bool { return a.SeqNum < b.SeqNum }
+
+// withoutContent 模拟只查询摘要列（不加载正文）
+func withoutContent(chapters []*entity.Chapter) []*entity.Chapter {
+	for _, c := range chapters {
+		c.ContentText = ""
+	}
+	return chapters
+}
+
+// Create 创建章节
+func (r *ChapterRepository) Create(ctx context.Context, chapter *entity.Chapter) error {
+	chapter.ContentHash = entity.ChapterContentHash(chapter.ContentText)
+	chapter.ContentChanged = chapter.ContentHash != ""
+	if err := r.store.chapters.insert(ctx, chapter); err != nil {
+		return fmt.Errorf("failed to create chapter: %w", err)
+	}
+	r.refreshVolumeRollup(ctx, chapter.VolumeID)
+	return r.saveDerived(ctx, chapter)
+}
+
+// GetByID 根据 ID 获取章节
+func (r *ChapterRepository) GetByID(ctx context.Context, id string) (*entity.Chapter, error) {
+	return r.store.chapters.get(ctx, id), nil
+}
+
+// GetByIDForUpdate 根据 ID 获取章节（内存实现无行锁）
+func (r *ChapterRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.Chapter, error) {
+	return r.store.chapters.get(ctx, id), nil
+}
+
+// Update 更新章节
+func (r *ChapterRepository) Update(ctx context.Context, chapter *entity.Chapter) error {
+	oldVolumeID := r.volumeOf(ctx, chapter.ID)
+	hash := entity.ChapterContentHash(chapter.ContentText)
+	stored := r.store.chapters.get(ctx, chapter.ID)
+	chapter.ContentChanged = stored == nil || stored.ContentHash != hash
+	chapter.ContentHash = hash
+	if err := r.store.chapters.save(ctx, chapter); err != nil {
+		return fmt.Errorf("failed to update chapter: %w", err)
+	}
+	r.refreshVolumeRollup(ctx, oldVolumeID, chapter.VolumeID)
+	return r.saveDerived(ctx, chapter)
+}
+
+// Delete 删除章节
+func (r *ChapterRepository) Delete(ctx context.Context, id string) error {
+	volumeID := r.volumeOf(ctx, id)
+	r.store.chapters.deleteByID(ctx, id)
+	r.refreshVolumeRollup(ctx, volumeID)
+	r.store.chapterStats.deleteByID(ctx, id)
+	r.store.chapterFingerprints.deleteByID(ctx, id)
+	r.store.chapterVersions.delete(ctx, func(v *entity.ChapterVersion) bool { return v.ChapterID == id })
+	return nil
+}
+
+// ListByProject 获取项目的章节列表（按序号排序）
+func (r *ChapterRepository) ListByProject(ctx context.Context, projectID string, filter *repository.ChapterFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.Chapter], error) {
+	rows := r.store.chapters.find(ctx, func(c *entity.Chapter) bool {
+		if c.ProjectID != projectID {
+			return false
+		}
+		if filter == nil {
+			return true
+		}
+		if filter.VolumeID != "" && c.VolumeID != filter.VolumeID {
+			return false
+		}
+		return filter.Status == "" || c.Status == filter.Status
+	}, chapterSeqLess)
+	result := paginate(rows, pagination)
+	if filter == nil || !filter.IncludeContent {
+		withoutContent(result.Items)
+	}
+	return result, nil
+}
+
+// ListByVolume 获取卷章节列表（按序号排序）
+func (r *ChapterRepository) ListByVolume(ctx context.Context, volumeID string) ([]*entity.Chapter, error) {
+	return r.store.chapters.find(ctx, func(c *entity.Chapter) bool { return c.VolumeID == volumeID }, chapterSeqLess), nil
+}
+
+// GetByProjectAndSeq 根据项目和序号获取章节（volumeID 为空时不限定卷）
+func (r *ChapterRepository) GetByProjectAndSeq(ctx context.Context, projectID string, volumeID string, seqNum int) (*entity.Chapter, error) {
+	return r.store.chapters.first(ctx, func(c *entity.Chapter) bool {
+		return c.ProjectID == projectID && c.SeqNum == seqNum && (volumeID == "" || c.VolumeID == volumeID)
+	}, nil), nil
+}
+
+// GetByAIKey 根据 AIKey 获取章节
+func (r *ChapterRepository) GetByAIKey(ctx context.Context, projectID, aiKey string) (*entity.Chapter, error) {
+	return r.store.chapters.first(ctx, func(c *entity.Chapter) bool {
+		return c.ProjectID == projectID && c.AIKey == aiKey
+	}, nil), nil
+}
+
+// UpdateContent 更新章节内容（字数按字符数计算）
+func (r *ChapterRepository) UpdateContent(ctx context.Context, id, content, summary string) error {
+	var updated *entity.Chapter
+	r.store.chapters.updateByID(ctx, id, true, func(c *entity.Chapter) {
+		c.ContentText = content
+		c.ContentHash = entity.ChapterContentHash(content)
+		c.Summary = summary
+		c.WordCount = len([]rune(content))
+		cp := *c
+		updated = &cp
+	})
+	if updated == nil {
+		return nil
+	}
+	r.refreshVolumeRollup(ctx, updated.VolumeID)
+	return r.saveDerived(ctx, updated)
+}
+
+// UpdateStatus 更新章节状态
+func (r *ChapterRepository) UpdateStatus(ctx context.Context, id string, status entity.ChapterStatus) error {
+	r.store.chapters.updateByID(ctx, id, true, func(c *entity.Chapter) { c.Status = status })
+	r.refreshVolumeRollup(ctx, r.volumeOf(ctx, id))
+	return nil
+}
+
+// UpdateContextPins 更新章节固定上下文（仅写该列）
+func (r *ChapterRepository) UpdateContextPins(ctx context.Context, id string, pins []entity.ContextPin) error {
+	r.store.chapters.updateByID(ctx, id, false, func(c *entity.Chapter) { c.ContextPins = pins })
+	return nil
+}
+
+// UpdateGenerationMetadata 更新章节生成元数据（不刷新 updated_at，同 Select 单列更新）
+func (r *ChapterRepository) UpdateGenerationMetadata(ctx context.Context, id string, meta *entity.GenerationMetadata) error {
+	r.store.chapters.updateByID(ctx, id, false, func(c *entity.Chapter) { c.GenerationMetadata = meta })
+	return nil
+}
+
+// MarkOutlineStale 标记关联到给定大纲条目的章节为大纲过期（不刷新 updated_at，同 UpdateColumns）
+func (r *ChapterRepository) MarkOutlineStale(ctx context.Context, projectID string, outlineKeys []string, at time.Time) ([]string, error) {
+	keys := make(map[string]bool, len(outlineKeys))
+	for _, k := range outlineKeys {
+		keys[k] = true
+	}
+	match := func(c *entity.Chapter) bool {
+		return c.ProjectID == projectID && c.OutlineKey != "" && keys[c.OutlineKey]
+	}
+	var ids []string
+	for _, c := range r.store.chapters.find(ctx, match, chapterSeqLess) {
+		ids = append(ids, c.ID)
+	}
+	r.store.chapters.update(ctx, match, false, func(c *entity.Chapter) {
+		c.OutlineStale = true
+		if c.OutlineStaleSince == nil {
+			since := at
+			c.OutlineStaleSince = &since
+		}
+	})
+	return ids, nil
+}
+
+// ClearOutlineStale 清除章节的大纲过期标记（仅写该列）
+func (r *ChapterRepository) ClearOutlineStale(ctx context.Context, id string) error {
+	r.store.chapters.updateByID(ctx, id, false, func(c *entity.Chapter) { c.ClearOutlineStale() })
+	return nil
+}
+
+// UpdateWordCount 更新章节字数（同步卷汇总）
+func (r *ChapterRepository) UpdateWordCount(ctx context.Context, id string, wordCount int) error {
+	r.store.chapters.updateByID(ctx, id, false, func(c *entity.Chapter) { c.WordCount = wordCount })
+	r.refreshVolumeRollup(ctx, r.volumeOf(ctx, id))
+	return nil
+}
+
+// ReorderChapters 重新排序某一卷下的章节（未包含的章节按原顺序追加到末尾）
+func (r *ChapterRepository) ReorderChapters(ctx context.Context, projectID, volumeID string, chapterIDs []string) error {
+	inVolume := func(c *entity.Chapter) bool { return c.ProjectID == projectID && c.VolumeID == volumeID }
+	existing := r.store.chapters.find(ctx, inVolume, chapterSeqLess)
+	ids := make([]string, 0, len(existing))
+	for _, c := range existing {
+		ids = append(ids, c.ID)
+	}
+	for i, id := range reorderIDs(chapterIDs, ids) {
+		seq := i + 1
+		r.store.chapters.update(ctx, func(c *entity.Chapter) bool {
+			return c.ID == id && inVolume(c)
+		}, true, func(c *entity.Chapter) { c.SeqNum = seq })
+	}
+	return nil
+}
+
+// GetNextSeqNum 获取下一个序号（volumeID 为空时按整个项目计算）
+func (r *ChapterRepository) GetNextSeqNum(ctx context.Context, projectID, volumeID string) (int, error) {
+	maxSeq := 0
+	for _, c := range r.store.chapters.find(ctx, func(c *entity.Chapter) bool {
+		return c.ProjectID == projectID && (volumeID == "" || c.VolumeID == volumeID)
+	}, nil) {
+		maxSeq = max(maxSeq, c.SeqNum)
+	}
+	return maxSeq + 1, nil
+}
+
+// RefreshDisplayNumbers 按叙事顺序重新分配项目章节的展示序号
+func (r *ChapterRepository) RefreshDisplayNumbers(ctx context.Context, projectID string) error {
+	for i, c := range r.narrativeOrder(ctx, func(c *entity.Chapter) bool { return c.ProjectID == projectID }) {
+		no := i + 1
+		if c.DisplayNo != no {
+			r.store.chapters.updateByID(ctx, c.ID, false, func(c *entity.Chapter) { c.DisplayNo = no })
+		}
+	}
+	return nil
+}
+
+// GetByStoryTimeRange 根据故事时间范围获取章节（起止为 0 表示不限）
+func (r *ChapterRepository) GetByStoryTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Chapter, error) {
+	return r.store.chapters.find(ctx, func(c *entity.Chapter) bool {
+		if c.ProjectID != projectID {
+			return false
+		}
+		if startTime > 0 && c.StoryTimeEnd < startTime {
+			return false
+		}
+		return endTime <= 0 || c.StoryTimeStart <= endTime
+	}, chapterSeqLess), nil
+}
+
+// narrativeOrder 按叙事顺序（卷序号 -> 章节序号）返回满足条件的章节；无卷的章节卷序号视为 0
+func (r *ChapterRepository) narrativeOrder(ctx context.Context, match func(*entity.Chapter) bool) []*entity.Chapter {
+	volumeSeq := make(map[string]int)
+	for _, v := range r.store.volumes.find(ctx, nil, nil) {
+		volumeSeq[v.ID] = v.SeqNum
+	}
+	return r.store.chapters.find(ctx, match, func(a, b *entity.Chapter) bool {
+		if va, vb := volumeSeq[a.VolumeID], volumeSeq[b.VolumeID]; va != vb {
+			return va < vb
+		}
+		return a.SeqNum < b.SeqNum
+	})
+}
+
+// ListTimeline 按叙事顺序获取项目章节的时间轴字段（不含正文）
+func (r *ChapterRepository) ListTimeline(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	return withoutContent(r.narrativeOrder(ctx, func(c *entity.Chapter) bool { return c.ProjectID == projectID })), nil
+}
+
+// ListOutlineLinks 按叙事顺序获取项目全部章节（不含正文）
+func (r *ChapterRepository) ListOutlineLinks(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	return withoutContent(r.narrativeOrder(ctx, func(c *entity.Chapter) bool { return c.ProjectID == projectID })), nil
+}
+
+// ListPublished 按叙事顺序获取项目已完成章节（不含正文）
+func (r *ChapterRepository) ListPublished(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	rows, _ := r.ListManuscript(ctx, projectID)
+	return withoutContent(rows), nil
+}
+
+// ListManuscript 按叙事顺序获取项目已完成章节（含正文）
+func (r *ChapterRepository) ListManuscript(ctx context.Context, projectID string) ([]*entity.Chapter, error) {
+	return r.narrativeOrder(ctx, func(c *entity.Chapter) bool {
+		return c.ProjectID == projectID && c.Status == entity.ChapterStatusCompleted
+	}), nil
+}
+
+// GetNarrativePosition 获取章节的叙事位置（章节不存在时返回 0）
+func (r *ChapterRepository) GetNarrativePosition(ctx context.Context, chapterID string) (int64, error) {
+	c := r.store.chapters.get(ctx, chapterID)
+	if c == nil {
+		return 0, nil
+	}
+	volumeSeq := 0
+	if v := r.store.volumes.get(ctx, c.VolumeID); v != nil {
+		volumeSeq = v.SeqNum
+	}
+	return entity.NarrativePosition(volumeSeq, c.SeqNum), nil
+}
+
+// GetRecent 获取最近章节（按序号倒序，不含正文）
+func (r *ChapterRepository) GetRecent(ctx context.Context, projectID string, limit int) ([]*entity.Chapter, error) {
+	rows := r.store.chapters.find(ctx, func(c *entity.Chapter) bool { return c.ProjectID == projectID },
+		func(a, b *entity.Chapter) bool { return a.SeqNum > b.SeqNum })
+	return withoutContent(limitOffset(rows, 0, limit)), nil
+}
+
+// ListByStatus 获取指定状态且 updated_at 早于 updatedBefore 的章节（不含正文，按 updated_at 升序）
+func (r *ChapterRepository) ListByStatus(ctx context.Context, status entity.ChapterStatus, updatedBefore time.Time, limit int) ([]*entity.Chapter, error) {
+	rows := r.store.chapters.find(ctx, func(c *entity.Chapter) bool {
+		return c.Status == status && c.UpdatedAt.Before(updatedBefore)
+	}, func(a, b *entity.Chapter) bool { return a.UpdatedAt.Before(b.UpdatedAt) })
+	return withoutContent(limitOffset(rows, 0, limit)), nil
+}
+
+var _ repository.ChapterRepository = (*ChapterRepository)(nil)
+
+// ListStats 获取项目全部章节的文本统计
+func (r *ChapterRepository) ListStats(ctx context.Context, projectID string) ([]*entity.ChapterStats, error) {
+	return r.store.chapterStats.find(ctx, func(s *entity.ChapterStats) bool { return s.ProjectID == projectID }, nil), nil
+}
+
+// SaveStats 写入（覆盖）章节文本统计
+func (r *ChapterRepository) SaveStats(ctx context.Context, stats *entity.ChapterStats) error {
+	if err := r.store.chapterStats.save(ctx, stats); err != nil {
+		return fmt.Errorf("failed to save chapter stats: %w", err)
+	}
+	return nil
+}
+
+// ListFingerprints 获取项目全部章节的内容指纹
+func (r *ChapterRepository) ListFingerprints(ctx context.Context, projectID string) ([]*entity.ChapterFingerprint, error) {
+	return r.store.chapterFingerprints.find(ctx, func(f *entity.ChapterFingerprint) bool { return f.ProjectID == projectID }, nil), nil
+}
+
+// SaveFingerprint 写入（覆盖）章节内容指纹
+func (r *ChapterRepository) SaveFingerprint(ctx context.Context, fp *entity.ChapterFingerprint) error {
+	if err := r.store.chapterFingerprints.save(ctx, fp); err != nil {
+		return fmt.Errorf("failed to save chapter fingerprint: %w", err)
+	}
+	return nil
+}
+
+// volumeOf 章节当前所属卷（章节不存在时为空）
+func (r *ChapterRepository) volumeOf(ctx context.Context, id string) string {
+	if c := r.store.chapters.get(ctx, id); c != nil {
+		return c.VolumeID
+	}
+	return ""
+}
+
+// refreshVolumeRollup 重新汇总卷的字数与章节状态计数，模拟 chapters 表触发器
+func (r *ChapterRepository) refreshVolumeRollup(ctx context.Context, volumeIDs ...string) {
+	seen := make(map[string]struct{}, len(volumeIDs))
+	for _, volumeID := range volumeIDs {
+		if volumeID == "" {
+			continue
+		}
+		if _, ok := seen[volumeID]; ok {
+			continue
+		}
+		seen[volumeID] = struct{}{}
+		chapters := r.store.chapters.find(ctx, func(c *entity.Chapter) bool { return c.VolumeID == volumeID }, nil)
+		r.store.volumes.updateByID(ctx, volumeID, true, func(v *entity.Volume) { v.ApplyChapterRollup(chapters) })
+	}
+}
+
+// GetVersion 获取章节指定版本的正文快照
+func (r *ChapterRepository) GetVersion(ctx context.Context, chapterID string, version int) (*entity.ChapterVersion, error) {
+	return r.store.chapterVersions.get(ctx, (&entity.ChapterVersion{ChapterID: chapterID, Version: version}).Key()), nil
+}
+
+// saveDerived 按正文重新计算章节统计、内容指纹与当前版本快照
+func (r *ChapterRepository) saveDerived(ctx context.Context, chapter *entity.Chapter) error {
+	if err := r.store.chapterStats.save(ctx, entity.NewChapterStats(chapter)); err != nil {
+		return err
+	}
+	if err := r.store.chapterFingerprints.save(ctx, entity.NewChapterFingerprint(chapter)); err != nil {
+		return err
+	}
+	if chapter.ContentText == "" || chapter.Version <= 0 {
+		return nil
+	}
+	v := entity.NewChapterVersion(chapter)
+	if old := r.store.chapterVersions.get(ctx, v.Key()); old != nil {
+		if old.ContentText == v.ContentText {
+			return nil
+		}
+		v.CreatedAt = old.CreatedAt
+	}
+	return r.store.chapterVersions.save(ctx, v)
+}
@@ -0,0 +1,54 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ConversationSessionRepository 会话仓储内存实现
+type ConversationSessionRepository struct {
+	store *Store
+}
+
+// NewConversationSessionRepository 创建会话仓储
+func NewConversationSessionRepository(store *Store) *ConversationSessionRepository {
+	return &ConversationSessionRepository{store: store}
+}
+
+// Create 创建会话
+func (r *ConversationSessionRepository) Create(ctx context.Context, session *entity.ConversationSession) error {
+	if err := r.store.conversationSessions.insert(ctx, session); err != nil {
+		return fmt.Errorf("failed to create conversation session: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取会话
+func (r *ConversationSessionRepository) GetByID(ctx context.Context, id string) (*entity.ConversationSession, error) {
+	return r.store.conversationSessions.get(ctx, id), nil
+}
+
+// GetByIDForUpdate 根据 ID 获取会话（内存实现无行锁）
+func (r *ConversationSessionRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.ConversationSession, error) {
+	return r.store.conversationSessions.get(ctx, id), nil
+}
+
+// Update 更新会话
+func (r *ConversationSessionRepository) Update(ctx context.Context, session *entity.ConversationSession) error {
+	if err := r.store.conversationSessions.save(ctx, session); err != nil {
+		return fmt.Errorf("failed to update conversation session: %w", err)
+	}
+	return nil
+}
+
+// ListByProject 获取项目会话列表（按创建时间倒序）
+func (r *ConversationSessionRepository) ListByProject(ctx context.Context, projectID string, pagination repository.Pagination) (*repository.PagedResult[*entity.ConversationSession], error) {
+	rows := r.store.conversationSessions.find(ctx, func(s *entity.ConversationSession) bool { return s.ProjectID == projectID },
+		func(a, b *entity.ConversationSession) bool { return a.CreatedAt.After(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+var _ repository.ConversationSessionRepository = (*ConversationSessionRepository)(nil)
@@ -0,0 +1,91 @@
+package memrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ConversationTurnRepository 会话轮次仓储内存实现
+type ConversationTurnRepository struct {
+	store *Store
+}
+
+// NewConversationTurnRepository 创建会话轮次仓储
+func NewConversationTurnRepository(store *Store) *ConversationTurnRepository {
+	return &ConversationTurnRepository{store: store}
+}
+
+// Create 创建轮次
+func (r *ConversationTurnRepository) Create(ctx context.Context, turn *entity.ConversationTurn) error {
+	if err := r.store.conversationTurns.insert(ctx, turn); err != nil {
+		return fmt.Errorf("failed to create conversation turn: %w", err)
+	}
+	return nil
+}
+
+// GetByIDForUpdate 根据 ID 获取轮次（内存实现无行锁）
+func (r *ConversationTurnRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.ConversationTurn, error) {
+	return r.store.conversationTurns.get(ctx, id), nil
+}
+
+// UpdateMetadata 覆盖写入轮次 metadata
+func (r *ConversationTurnRepository) UpdateMetadata(ctx context.Context, id string, metadata json.RawMessage) error {
+	r.store.conversationTurns.updateByID(ctx, id, false, func(t *entity.ConversationTurn) {
+		t.Metadata = metadata
+	})
+	return nil
+}
+
+// ListBySession 获取会话轮次（按时间升序）
+func (r *ConversationTurnRepository) ListBySession(ctx context.Context, sessionID string, pagination repository.Pagination) (*repository.PagedResult[*entity.ConversationTurn], error) {
+	rows := r.store.conversationTurns.find(ctx, func(t *entity.ConversationTurn) bool { return t.SessionID == sessionID },
+		func(a, b *entity.ConversationTurn) bool { return a.CreatedAt.Before(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// SumUsageBySession 汇总会话内助手轮次的 Token 与成本
+func (r *ConversationTurnRepository) SumUsageBySession(ctx context.Context, sessionID string) (*entity.ConversationUsage, error) {
+	usage := &entity.ConversationUsage{}
+	for _, t := range r.store.conversationTurns.find(ctx, func(t *entity.ConversationTurn) bool {
+		return t.SessionID == sessionID && t.Role == entity.RoleAssistant
+	}, nil) {
+		usage.Turns++
+		usage.PromptTokens += int64(t.PromptTokens)
+		usage.CompletionTokens += int64(t.CompletionTokens)
+		if t.CostCurrency != "" && t.Cost > 0 {
+			if usage.Costs == nil {
+				usage.Costs = make(map[string]float64)
+			}
+			usage.Costs[t.CostCurrency] += t.Cost
+		}
+	}
+	return usage, nil
+}
+
+// ListConflictWarnings 获取为指定构件版本记录了设定冲突警告的助手轮次（按时间升序）
+func (r *ConversationTurnRepository) ListConflictWarnings(ctx context.Context, versionIDs []string) ([]*entity.ConversationTurn, error) {
+	wanted := make(map[string]struct{}, len(versionIDs))
+	for _, id := range versionIDs {
+		wanted[id] = struct{}{}
+	}
+	return r.store.conversationTurns.find(ctx, func(t *entity.ConversationTurn) bool {
+		if t.Role != entity.RoleAssistant || len(t.Metadata) == 0 {
+			return false
+		}
+		var meta struct {
+			VersionID        string          `json:"version_id"`
+			ConflictWarnings json.RawMessage `json:"conflict_warnings"`
+		}
+		if json.Unmarshal(t.Metadata, &meta) != nil || len(meta.ConflictWarnings) == 0 || string(meta.ConflictWarnings) == "null" {
+			return false
+		}
+		_, ok := wanted[meta.VersionID]
+		return ok
+	}, func(a, b *entity.ConversationTurn) bool { return a.CreatedAt.Before(b.CreatedAt) }), nil
+}
+
+var _ repository.ConversationTurnRepository = (*ConversationTurnRepository)(nil)
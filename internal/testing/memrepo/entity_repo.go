@@ -0,0 +1,156 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// EntityRepository 实体仓储内存实现
+type EntityRepository struct {
+	store *Store
+}
+
+// NewEntityRepository 创建实体仓储
+func NewEntityRepository(store *Store) *EntityRepository {
+	return &EntityRepository{store: store}
+}
+
+// entityImportanceLess 等价于 ORDER BY importance ASC, created_at DESC
+func entityImportanceLess(a, b *entity.StoryEntity) bool {
+	if a.Importance != b.Importance {
+		return a.Importance < b.Importance
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// containsFold 等价于 ILIKE '%sub%'
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+// Create 创建实体
+func (r *EntityRepository) Create(ctx context.Context, storyEntity *entity.StoryEntity) error {
+	if err := r.store.entities.insert(ctx, storyEntity); err != nil {
+		return fmt.Errorf("failed to create entity: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取实体
+func (r *EntityRepository) GetByID(ctx context.Context, id string) (*entity.StoryEntity, error) {
+	return r.store.entities.get(ctx, id), nil
+}
+
+// Update 更新实体
+func (r *EntityRepository) Update(ctx context.Context, storyEntity *entity.StoryEntity) error {
+	if err := r.store.entities.save(ctx, storyEntity); err != nil {
+		return fmt.Errorf("failed to update entity: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除实体
+func (r *EntityRepository) Delete(ctx context.Context, id string) error {
+	r.store.entities.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 获取项目实体列表
+func (r *EntityRepository) ListByProject(ctx context.Context, projectID string, filter *repository.EntityFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.StoryEntity], error) {
+	rows := r.store.entities.find(ctx, func(e *entity.StoryEntity) bool {
+		if e.ProjectID != projectID {
+			return false
+		}
+		if filter == nil {
+			return true
+		}
+		if filter.Type != "" && e.Type != filter.Type {
+			return false
+		}
+		if filter.Importance != "" && e.Importance != filter.Importance {
+			return false
+		}
+		return filter.Name == "" || containsFold(e.Name, filter.Name)
+	}, entityImportanceLess)
+	return paginate(rows, pagination), nil
+}
+
+// GetByAIKey 根据 AIKey 获取实体
+func (r *EntityRepository) GetByAIKey(ctx context.Context, projectID, aiKey string) (*entity.StoryEntity, error) {
+	return r.store.entities.first(ctx, func(e *entity.StoryEntity) bool {
+		return e.ProjectID == projectID && e.AIKey == aiKey
+	}, nil), nil
+}
+
+// SearchByName 按名称或别名模糊搜索实体
+func (r *EntityRepository) SearchByName(ctx context.Context, projectID, query string, limit int) ([]*entity.StoryEntity, error) {
+	rows := r.store.entities.find(ctx, func(e *entity.StoryEntity) bool {
+		if e.ProjectID != projectID {
+			return false
+		}
+		if containsFold(e.Name, query) {
+			return true
+		}
+		for _, alias := range e.Aliases {
+			if containsFold(alias, query) {
+				return true
+			}
+		}
+		return false
+	}, nil)
+	return limitOffset(rows, 0, limit), nil
+}
+
+// UpdateState 更新实体当前状态
+func (r *EntityRepository) UpdateState(ctx context.Context, id, state string) error {
+	r.store.entities.updateByID(ctx, id, true, func(e *entity.StoryEntity) { e.CurrentState = state })
+	return nil
+}
+
+// UpdateVectorID 更新向量 ID
+func (r *EntityRepository) UpdateVectorID(ctx context.Context, id, vectorID string) error {
+	r.store.entities.updateByID(ctx, id, true, func(e *entity.StoryEntity) { e.VectorID = vectorID })
+	return nil
+}
+
+// RecordAppearance 记录实体出场（首次出场同时记录首次出场章节）
+func (r *EntityRepository) RecordAppearance(ctx context.Context, id, chapterID string) error {
+	r.store.entities.updateByID(ctx, id, true, func(e *entity.StoryEntity) {
+		e.AppearCount++
+		e.LastAppearChapterID = chapterID
+		if e.FirstAppearChapterID == "" {
+			e.FirstAppearChapterID = chapterID
+		}
+	})
+	return nil
+}
+
+// SetAppearances 覆盖实体出场统计
+func (r *EntityRepository) SetAppearances(ctx context.Context, id, firstChapterID, lastChapterID string, count int) error {
+	r.store.entities.updateByID(ctx, id, true, func(e *entity.StoryEntity) {
+		e.AppearCount = count
+		e.FirstAppearChapterID = firstChapterID
+		e.LastAppearChapterID = lastChapterID
+	})
+	return nil
+}
+
+// GetByType 根据类型获取实体
+func (r *EntityRepository) GetByType(ctx context.Context, projectID string, entityType entity.StoryEntityType) ([]*entity.StoryEntity, error) {
+	return r.store.entities.find(ctx, func(e *entity.StoryEntity) bool {
+		return e.ProjectID == projectID && e.Type == entityType
+	}, entityImportanceLess), nil
+}
+
+// GetProtagonists 获取主角
+func (r *EntityRepository) GetProtagonists(ctx context.Context, projectID string) ([]*entity.StoryEntity, error) {
+	return r.store.entities.find(ctx, func(e *entity.StoryEntity) bool {
+		return e.ProjectID == projectID && e.Importance == entity.ImportanceProtagonist
+	}, nil), nil
+}
+
+var _ repository.EntityRepository = (*EntityRepository)(nil)
@@ -0,0 +1,77 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// EntityStateRepository 实体状态仓储内存实现
+type EntityStateRepository struct {
+	store *Store
+}
+
+// NewEntityStateRepository 创建实体状态仓储
+func NewEntityStateRepository(store *Store) *EntityStateRepository {
+	return &EntityStateRepository{store: store}
+}
+
+// stateLatestFirst 等价于 ORDER BY story_time DESC, created_at DESC
+func stateLatestFirst(a, b *entity.EntityState) bool {
+	if a.StoryTime != b.StoryTime {
+		return a.StoryTime > b.StoryTime
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// Create 创建实体状态
+func (r *EntityStateRepository) Create(ctx context.Context, state *entity.EntityState) error {
+	if err := r.store.entityStates.insert(ctx, state); err != nil {
+		return fmt.Errorf("failed to create entity state: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取实体状态
+func (r *EntityStateRepository) GetByID(ctx context.Context, id string) (*entity.EntityState, error) {
+	return r.store.entityStates.get(ctx, id), nil
+}
+
+// ListByEntity 获取实体的状态历史（按故事时间倒序）
+func (r *EntityStateRepository) ListByEntity(ctx context.Context, entityID string, pagination repository.Pagination) (*repository.PagedResult[*entity.EntityState], error) {
+	rows := r.store.entityStates.find(ctx, func(s *entity.EntityState) bool { return s.EntityID == entityID }, stateLatestFirst)
+	return paginate(rows, pagination), nil
+}
+
+// GetByChapter 获取章节内的状态变化（按故事时间升序）
+func (r *EntityStateRepository) GetByChapter(ctx context.Context, chapterID string) ([]*entity.EntityState, error) {
+	return r.store.entityStates.find(ctx, func(s *entity.EntityState) bool { return s.ChapterID == chapterID },
+		func(a, b *entity.EntityState) bool {
+			if a.StoryTime != b.StoryTime {
+				return a.StoryTime < b.StoryTime
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		}), nil
+}
+
+// GetStateAtTime 获取指定时间点的状态
+func (r *EntityStateRepository) GetStateAtTime(ctx context.Context, entityID string, storyTime int64) (*entity.EntityState, error) {
+	return r.store.entityStates.first(ctx, func(s *entity.EntityState) bool {
+		return s.EntityID == entityID && s.StoryTime <= storyTime
+	}, func(a, b *entity.EntityState) bool { return a.StoryTime > b.StoryTime }), nil
+}
+
+// GetLatestState 获取实体最新状态
+func (r *EntityStateRepository) GetLatestState(ctx context.Context, entityID string) (*entity.EntityState, error) {
+	return r.store.entityStates.first(ctx, func(s *entity.EntityState) bool { return s.EntityID == entityID }, stateLatestFirst), nil
+}
+
+// DeleteByEntity 删除实体的全部状态
+func (r *EntityStateRepository) DeleteByEntity(ctx context.Context, entityID string) error {
+	r.store.entityStates.delete(ctx, func(s *entity.EntityState) bool { return s.EntityID == entityID })
+	return nil
+}
+
+var _ repository.EntityStateRepository = (*EntityStateRepository)(nil)
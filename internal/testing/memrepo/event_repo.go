@@ -0,0 +1,152 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// EventRepository 事件仓储内存实现
+type EventRepository struct {
+	store *Store
+}
+
+// NewEventRepository 创建事件仓储
+func NewEventRepository(store *Store) *EventRepository {
+	return &EventRepository{store: store}
+}
+
+func eventStoryTimeLess(a, b *entity.Event) bool { return a.StoryTimeStart < b.StoryTimeStart }
+
+// activeInProject 项目内未被取代的事件
+func activeInProject(projectID string) func(*entity.Event) bool {
+	return func(e *entity.Event) bool { return e.ProjectID == projectID && e.SupersededAt == nil }
+}
+
+// Create 创建事件
+func (r *EventRepository) Create(ctx context.Context, event *entity.Event) error {
+	if err := r.store.events.insert(ctx, event); err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取事件
+func (r *EventRepository) GetByID(ctx context.Context, id string) (*entity.Event, error) {
+	return r.store.events.get(ctx, id), nil
+}
+
+// Update 更新事件
+func (r *EventRepository) Update(ctx context.Context, event *entity.Event) error {
+	if err := r.store.events.save(ctx, event); err != nil {
+		return fmt.Errorf("failed to update event: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除事件
+func (r *EventRepository) Delete(ctx context.Context, id string) error {
+	r.store.events.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 获取项目事件列表（按故事时间升序；Tags / LocationID 过滤与 PostgreSQL 实现一致不生效）
+func (r *EventRepository) ListByProject(ctx context.Context, projectID string, filter *repository.EventFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.Event], error) {
+	rows := r.store.events.find(ctx, func(e *entity.Event) bool {
+		if e.ProjectID != projectID {
+			return false
+		}
+		if filter == nil {
+			return e.SupersededAt == nil
+		}
+		if !filter.IncludeSuperseded && e.SupersededAt != nil {
+			return false
+		}
+		if filter.ChapterID != "" && e.ChapterID != filter.ChapterID {
+			return false
+		}
+		if filter.EventType != "" && e.EventType != filter.EventType {
+			return false
+		}
+		if filter.Importance != "" && e.Importance != filter.Importance {
+			return false
+		}
+		if filter.TimeStart > 0 && e.StoryTimeStart < filter.TimeStart {
+			return false
+		}
+		return filter.TimeEnd <= 0 || e.StoryTimeEnd <= filter.TimeEnd
+	}, eventStoryTimeLess)
+	return paginate(rows, pagination), nil
+}
+
+// ListByChapter 获取章节内未被取代的事件
+func (r *EventRepository) ListByChapter(ctx context.Context, chapterID string) ([]*entity.Event, error) {
+	return r.store.events.find(ctx, func(e *entity.Event) bool {
+		return e.ChapterID == chapterID && e.SupersededAt == nil
+	}, eventStoryTimeLess), nil
+}
+
+// Supersede 将事件标记为已被取代
+func (r *EventRepository) Supersede(ctx context.Context, id string, supersededBy *string, at time.Time) error {
+	r.store.events.updateByID(ctx, id, false, func(e *entity.Event) {
+		e.SupersededAt = &at
+		e.SupersededBy = supersededBy
+	})
+	return nil
+}
+
+// GetByTimeRange 根据故事时间范围获取事件（结束时间为 0 的事件视为未结束）
+func (r *EventRepository) GetByTimeRange(ctx context.Context, projectID string, startTime, endTime int64) ([]*entity.Event, error) {
+	active := activeInProject(projectID)
+	return r.store.events.find(ctx, func(e *entity.Event) bool {
+		if !active(e) {
+			return false
+		}
+		if startTime > 0 && e.StoryTimeEnd < startTime && e.StoryTimeEnd != 0 {
+			return false
+		}
+		return endTime <= 0 || e.StoryTimeStart <= endTime
+	}, eventStoryTimeLess), nil
+}
+
+// GetByEntity 获取涉及实体的事件
+func (r *EventRepository) GetByEntity(ctx context.Context, entityID string, pagination repository.Pagination) (*repository.PagedResult[*entity.Event], error) {
+	rows := r.store.events.find(ctx, func(e *entity.Event) bool {
+		return e.SupersededAt == nil && slices.Contains(e.InvolvedEntities, entityID)
+	}, eventStoryTimeLess)
+	return paginate(rows, pagination), nil
+}
+
+// UpdateVectorID 更新向量 ID
+func (r *EventRepository) UpdateVectorID(ctx context.Context, id, vectorID string) error {
+	r.store.events.updateByID(ctx, id, false, func(e *entity.Event) { e.VectorID = vectorID })
+	return nil
+}
+
+// GetTimeline 获取项目时间线
+func (r *EventRepository) GetTimeline(ctx context.Context, projectID string, limit int) ([]*entity.Event, error) {
+	return limitOffset(r.store.events.find(ctx, activeInProject(projectID), eventStoryTimeLess), 0, limit), nil
+}
+
+// SearchByTags 获取包含全部标签的事件
+func (r *EventRepository) SearchByTags(ctx context.Context, projectID string, tags []string, limit int) ([]*entity.Event, error) {
+	active := activeInProject(projectID)
+	rows := r.store.events.find(ctx, func(e *entity.Event) bool {
+		if !active(e) {
+			return false
+		}
+		for _, tag := range tags {
+			if !slices.Contains(e.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	}, eventStoryTimeLess)
+	return limitOffset(rows, 0, limit), nil
+}
+
+var _ repository.EventRepository = (*EventRepository)(nil)
@@ -0,0 +1,88 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// FeatureFlagRepository 功能开关仓储内存实现
+type FeatureFlagRepository struct {
+	store *Store
+}
+
+// NewFeatureFlagRepository 创建功能开关仓储
+func NewFeatureFlagRepository(store *Store) *FeatureFlagRepository {
+	return &FeatureFlagRepository{store: store}
+}
+
+// CreateFlag 写入全局开关定义（PostgreSQL 中由迁移预置，内存实现用于准备测试数据）
+func (r *FeatureFlagRepository) CreateFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	if err := r.store.featureFlags.insert(ctx, flag); err != nil {
+		return fmt.Errorf("failed to create feature flag: %w", err)
+	}
+	return nil
+}
+
+// ListFlags 获取全部全局开关定义
+func (r *FeatureFlagRepository) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	return r.store.featureFlags.find(ctx, nil, func(a, b *entity.FeatureFlag) bool { return a.Key < b.Key }), nil
+}
+
+// GetFlag 根据 Key 获取开关定义
+func (r *FeatureFlagRepository) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	return r.store.featureFlags.get(ctx, key), nil
+}
+
+// ListOverrides 获取租户的全部覆盖（含项目级）
+func (r *FeatureFlagRepository) ListOverrides(ctx context.Context, tenantID string) ([]*entity.FeatureFlagOverride, error) {
+	return r.store.featureFlagOverrides.find(ctx, func(o *entity.FeatureFlagOverride) bool { return o.TenantID == tenantID },
+		func(a, b *entity.FeatureFlagOverride) bool {
+			if a.FlagKey != b.FlagKey {
+				return a.FlagKey < b.FlagKey
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		}), nil
+}
+
+// UpsertOverride 创建或更新覆盖（按租户 + 开关 + 项目唯一）
+func (r *FeatureFlagRepository) UpsertOverride(ctx context.Context, override *entity.FeatureFlagOverride) error {
+	now := r.store.now()
+	updated := r.store.featureFlagOverrides.update(ctx, overrideScope(override.TenantID, override.FlagKey, override.ProjectID), false,
+		func(o *entity.FeatureFlagOverride) {
+			o.Enabled = override.Enabled
+			o.UpdatedAt = now
+			override.ID = o.ID
+			override.CreatedAt = o.CreatedAt
+			override.UpdatedAt = now
+		})
+	if updated > 0 {
+		return nil
+	}
+	if err := r.store.featureFlagOverrides.insert(ctx, override); err != nil {
+		return fmt.Errorf("failed to create feature flag override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride 删除覆盖（projectID 为空表示租户级覆盖）
+func (r *FeatureFlagRepository) DeleteOverride(ctx context.Context, tenantID, flagKey string, projectID *string) error {
+	r.store.featureFlagOverrides.delete(ctx, overrideScope(tenantID, flagKey, projectID))
+	return nil
+}
+
+func overrideScope(tenantID, flagKey string, projectID *string) func(*entity.FeatureFlagOverride) bool {
+	return func(o *entity.FeatureFlagOverride) bool {
+		if o.TenantID != tenantID || o.FlagKey != flagKey {
+			return false
+		}
+		if projectID != nil && *projectID != "" {
+			return o.ProjectID != nil && *o.ProjectID == *projectID
+		}
+		return o.ProjectID == nil
+	}
+}
+
+var _ repository.FeatureFlagRepository = (*FeatureFlagRepository)(nil)
@@ -0,0 +1,76 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// GenerationCandidateRepository 多候选生成结果仓储内存实现
+type GenerationCandidateRepository struct {
+	store *Store
+}
+
+// NewGenerationCandidateRepository 创建候选结果仓储
+func NewGenerationCandidateRepository(store *Store) *GenerationCandidateRepository {
+	return &GenerationCandidateRepository{store: store}
+}
+
+// CreateBatch 批量写入候选结果（job_id + candidate_no 唯一）
+func (r *GenerationCandidateRepository) CreateBatch(ctx context.Context, candidates []*entity.GenerationCandidate) error {
+	for _, c := range candidates {
+		if r.store.generationCandidates.count(ctx, func(existing *entity.GenerationCandidate) bool {
+			return existing.JobID == c.JobID && existing.CandidateNo == c.CandidateNo
+		}) > 0 {
+			return fmt.Errorf("failed to create generation candidates: %w", ErrDuplicateKey)
+		}
+		if c.Status == "" {
+			c.Status = entity.CandidateStatusPending
+		}
+		if err := r.store.generationCandidates.insert(ctx, c); err != nil {
+			return fmt.Errorf("failed to create generation candidates: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取候选结果
+func (r *GenerationCandidateRepository) GetByID(ctx context.Context, id string) (*entity.GenerationCandidate, error) {
+	return r.store.generationCandidates.get(ctx, id), nil
+}
+
+// ListByJob 获取任务的全部候选（按候选序号）
+func (r *GenerationCandidateRepository) ListByJob(ctx context.Context, jobID string) ([]*entity.GenerationCandidate, error) {
+	return r.store.generationCandidates.find(ctx, func(c *entity.GenerationCandidate) bool { return c.JobID == jobID },
+		func(a, b *entity.GenerationCandidate) bool { return a.CandidateNo < b.CandidateNo }), nil
+}
+
+// MarkSelected 标记采用的候选，同任务其余候选标记为未采用
+func (r *GenerationCandidateRepository) MarkSelected(ctx context.Context, jobID, candidateID string, versionID *string) error {
+	now := r.store.now()
+	r.store.generationCandidates.update(ctx, func(c *entity.GenerationCandidate) bool {
+		return c.JobID == jobID && c.ID != candidateID
+	}, false, func(c *entity.GenerationCandidate) { c.Status = entity.CandidateStatusDiscarded })
+	r.store.generationCandidates.update(ctx, func(c *entity.GenerationCandidate) bool {
+		return c.JobID == jobID && c.ID == candidateID
+	}, false, func(c *entity.GenerationCandidate) {
+		c.Status = entity.CandidateStatusSelected
+		c.SelectedAt = &now
+		if versionID != nil {
+			c.VersionID = versionID
+		}
+	})
+	return nil
+}
+
+// MarkDiscarded 将单个候选标记为未采用
+func (r *GenerationCandidateRepository) MarkDiscarded(ctx context.Context, candidateID string) error {
+	r.store.generationCandidates.update(ctx, func(c *entity.GenerationCandidate) bool {
+		return c.ID == candidateID
+	}, false, func(c *entity.GenerationCandidate) { c.Status = entity.CandidateStatusDiscarded })
+	return nil
+}
+
+var _ repository.GenerationCandidateRepository = (*GenerationCandidateRepository)(nil)
@@ -0,0 +1,53 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// InvoiceRepository 账单仓储内存实现
+type InvoiceRepository struct {
+	store *Store
+}
+
+// NewInvoiceRepository 创建账单仓储
+func NewInvoiceRepository(store *Store) *InvoiceRepository {
+	return &InvoiceRepository{store: store}
+}
+
+// Create 创建账单；(provider, external_id) 已存在时不写入并返回 false
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) (bool, error) {
+	r.store.mu.Lock()
+	exists := false
+	for _, inv := range r.store.invoices.rows {
+		if inv.Provider == invoice.Provider && inv.ExternalID == invoice.ExternalID {
+			exists = true
+			break
+		}
+	}
+	r.store.mu.Unlock()
+	if exists {
+		return false, nil
+	}
+	if err := r.store.invoices.insert(ctx, invoice); err != nil {
+		return false, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return true, nil
+}
+
+// GetByID 根据 ID 获取账单
+func (r *InvoiceRepository) GetByID(ctx context.Context, id string) (*entity.Invoice, error) {
+	return r.store.invoices.get(ctx, id), nil
+}
+
+// ListByTenant 获取租户账单（按支付时间倒序）
+func (r *InvoiceRepository) ListByTenant(ctx context.Context, tenantID string, pagination repository.Pagination) (*repository.PagedResult[*entity.Invoice], error) {
+	rows := r.store.invoices.find(ctx, func(inv *entity.Invoice) bool { return inv.TenantID == tenantID },
+		func(a, b *entity.Invoice) bool { return a.PaidAt.After(b.PaidAt) })
+	return paginate(rows, pagination), nil
+}
+
+var _ repository.InvoiceRepository = (*InvoiceRepository)(nil)
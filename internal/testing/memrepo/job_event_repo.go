@@ -0,0 +1,40 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// JobEventRepository 任务事件仓储内存实现
+type JobEventRepository struct {
+	store *Store
+}
+
+// NewJobEventRepository 创建任务事件仓储
+func NewJobEventRepository(store *Store) *JobEventRepository {
+	return &JobEventRepository{store: store}
+}
+
+// Create 写入任务事件
+func (r *JobEventRepository) Create(ctx context.Context, event *entity.JobEvent) error {
+	if err := r.store.jobEvents.insert(ctx, event); err != nil {
+		return fmt.Errorf("failed to create job event: %w", err)
+	}
+	return nil
+}
+
+// ListByJob 获取任务事件（按时间升序）
+func (r *JobEventRepository) ListByJob(ctx context.Context, jobID string) ([]*entity.JobEvent, error) {
+	return r.store.jobEvents.find(ctx, func(e *entity.JobEvent) bool { return e.JobID == jobID },
+		func(a, b *entity.JobEvent) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		}), nil
+}
+
+var _ repository.JobEventRepository = (*JobEventRepository)(nil)
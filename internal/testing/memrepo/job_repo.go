@@ -0,0 +1,215 @@
+package memrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// JobRepository 生成任务仓储内存实现
+type JobRepository struct {
+	store *Store
+}
+
+// NewJobRepository 创建生成任务仓储
+func NewJobRepository(store *Store) *JobRepository {
+	return &JobRepository{store: store}
+}
+
+func jobNewestFirst(a, b *entity.GenerationJob) bool { return a.CreatedAt.After(b.CreatedAt) }
+
+func jobOldestFirst(a, b *entity.GenerationJob) bool { return a.CreatedAt.Before(b.CreatedAt) }
+
+// Create 创建任务（幂等键唯一）
+func (r *JobRepository) Create(ctx context.Context, job *entity.GenerationJob) error {
+	if job.IdempotencyKey != nil {
+		if existing, _ := r.GetByIdempotencyKey(ctx, *job.IdempotencyKey); existing != nil {
+			return fmt.Errorf("failed to create job: %w", ErrDuplicateKey)
+		}
+	}
+	if err := r.store.jobs.insert(ctx, job); err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取任务
+func (r *JobRepository) GetByID(ctx context.Context, id string) (*entity.GenerationJob, error) {
+	return r.store.jobs.get(ctx, id), nil
+}
+
+// Update 更新任务
+func (r *JobRepository) Update(ctx context.Context, job *entity.GenerationJob) error {
+	if err := r.store.jobs.save(ctx, job); err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除任务
+func (r *JobRepository) Delete(ctx context.Context, id string) error {
+	r.store.jobs.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 获取项目任务列表（按创建时间倒序）
+func (r *JobRepository) ListByProject(ctx context.Context, projectID string, filter *repository.JobFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.GenerationJob], error) {
+	rows := r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool {
+		if j.ProjectID != projectID {
+			return false
+		}
+		if filter == nil {
+			return true
+		}
+		if filter.ChapterID != nil && (j.ChapterID == nil || *j.ChapterID != *filter.ChapterID) {
+			return false
+		}
+		if filter.JobType != "" && j.JobType != filter.JobType {
+			return false
+		}
+		if filter.Category != "" && j.Category != filter.Category {
+			return false
+		}
+		if filter.MinPriority > 0 && j.Priority < filter.MinPriority {
+			return false
+		}
+		if filter.MaxPriority > 0 && j.Priority > filter.MaxPriority {
+			return false
+		}
+		return filter.Status == "" || j.Status == filter.Status
+	}, jobNewestFirst)
+	return paginate(rows, pagination), nil
+}
+
+// GetByIdempotencyKey 根据幂等键获取任务
+func (r *JobRepository) GetByIdempotencyKey(ctx context.Context, key string) (*entity.GenerationJob, error) {
+	return r.store.jobs.first(ctx, func(j *entity.GenerationJob) bool {
+		return j.IdempotencyKey != nil && *j.IdempotencyKey == key
+	}, nil), nil
+}
+
+// UpdateStatus 更新任务状态
+func (r *JobRepository) UpdateStatus(ctx context.Context, id string, status entity.JobStatus) error {
+	r.store.jobs.updateByID(ctx, id, true, func(j *entity.GenerationJob) { j.Status = status })
+	return nil
+}
+
+// UpdateProgress 更新任务进度
+func (r *JobRepository) UpdateProgress(ctx context.Context, id string, progress int) error {
+	r.store.jobs.updateByID(ctx, id, true, func(j *entity.GenerationJob) { j.Progress = progress })
+	return nil
+}
+
+// UpdateInputSnapshot 记录任务执行时的输入快照
+func (r *JobRepository) UpdateInputSnapshot(ctx context.Context, id string, snapshot json.RawMessage) error {
+	r.store.jobs.updateByID(ctx, id, true, func(j *entity.GenerationJob) { j.InputSnapshot = snapshot })
+	return nil
+}
+
+// AppendWarnings 追加任务警告（已达上限时丢弃）
+func (r *JobRepository) AppendWarnings(ctx context.Context, id string, warnings ...entity.JobWarning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	r.store.jobs.update(ctx, func(j *entity.GenerationJob) bool {
+		return j.ID == id && len(j.Warnings) < entity.MaxJobWarnings
+	}, true, func(j *entity.GenerationJob) {
+		j.Warnings = append(append(entity.JobWarnings{}, j.Warnings...), warnings...)
+	})
+	return nil
+}
+
+// GetPendingJobs 获取待处理任务（优先级高者优先，同优先级先进先出）
+func (r *JobRepository) GetPendingJobs(ctx context.Context, limit int) ([]*entity.GenerationJob, error) {
+	rows := r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool { return j.Status == entity.JobStatusPending },
+		func(a, b *entity.GenerationJob) bool {
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			return jobOldestFirst(a, b)
+		})
+	return limitOffset(rows, 0, limit), nil
+}
+
+// ListStatusByIDs 批量获取任务（内存实现返回完整记录）
+func (r *JobRepository) ListStatusByIDs(ctx context.Context, ids []string) ([]*entity.GenerationJob, error) {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	return r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool { return want[j.ID] }, jobNewestFirst), nil
+}
+
+// GetActiveByChapter 获取章节上正在排队或执行中的生成任务
+func (r *JobRepository) GetActiveByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error) {
+	return r.store.jobs.first(ctx, func(j *entity.GenerationJob) bool {
+		return j.ChapterID != nil && *j.ChapterID == chapterID &&
+			(j.Status == entity.JobStatusPending || j.Status == entity.JobStatusRunning)
+	}, jobNewestFirst), nil
+}
+
+// GetLatestByChapter 获取章节最近创建的章节生成任务（不限状态）
+func (r *JobRepository) GetLatestByChapter(ctx context.Context, chapterID string) (*entity.GenerationJob, error) {
+	return r.store.jobs.first(ctx, func(j *entity.GenerationJob) bool {
+		return j.ChapterID != nil && *j.ChapterID == chapterID && j.JobType == entity.JobTypeChapterGen
+	}, jobNewestFirst), nil
+}
+
+// GetRunningJobs 获取运行中任务（按开始时间升序，未记录开始时间的排在最后）
+func (r *JobRepository) GetRunningJobs(ctx context.Context) ([]*entity.GenerationJob, error) {
+	return r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool { return j.Status == entity.JobStatusRunning },
+		func(a, b *entity.GenerationJob) bool {
+			switch {
+			case a.StartedAt == nil:
+				return false
+			case b.StartedAt == nil:
+				return true
+			}
+			return a.StartedAt.Before(*b.StartedAt)
+		}), nil
+}
+
+// GetFailedJobs 获取失败任务（可重试）
+func (r *JobRepository) GetFailedJobs(ctx context.Context, maxRetries int, limit int) ([]*entity.GenerationJob, error) {
+	rows := r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool {
+		return j.Status == entity.JobStatusFailed && j.RetryCount < maxRetries
+	}, jobOldestFirst)
+	return limitOffset(rows, 0, limit), nil
+}
+
+// GetJobStats 获取任务统计信息
+func (r *JobRepository) GetJobStats(ctx context.Context, projectID string) (*repository.JobStats, error) {
+	stats := &repository.JobStats{}
+	for _, j := range r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool { return j.ProjectID == projectID }, nil) {
+		stats.TotalJobs++
+		switch j.Status {
+		case entity.JobStatusPending:
+			stats.PendingJobs++
+		case entity.JobStatusRunning:
+			stats.RunningJobs++
+		case entity.JobStatusCompleted:
+			stats.CompletedJobs++
+		case entity.JobStatusFailed:
+			stats.FailedJobs++
+		}
+		stats.TotalTokensUsed += int64(j.TokensPrompt + j.TokensComplete)
+	}
+	return stats, nil
+}
+
+// GetTokenUsage 获取租户在指定时间范围内的 Token 使用量（prompt + completion）
+func (r *JobRepository) GetTokenUsage(ctx context.Context, tenantID string, startInclusive, endExclusive time.Time) (int64, error) {
+	var total int64
+	for _, j := range r.store.jobs.find(ctx, func(j *entity.GenerationJob) bool {
+		return j.TenantID == tenantID && !j.CreatedAt.Before(startInclusive) && j.CreatedAt.Before(endExclusive)
+	}, nil) {
+		total += int64(j.TokensPrompt + j.TokensComplete)
+	}
+	return total, nil
+}
+
+var _ repository.JobRepository = (*JobRepository)(nil)
@@ -0,0 +1,97 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// LLMUsageEventRepository LLM 用量事件仓储内存实现
+type LLMUsageEventRepository struct {
+	store *Store
+}
+
+// NewLLMUsageEventRepository 创建 LLM 用量事件仓储
+func NewLLMUsageEventRepository(store *Store) *LLMUsageEventRepository {
+	return &LLMUsageEventRepository{store: store}
+}
+
+// Create 写入用量事件
+func (r *LLMUsageEventRepository) Create(ctx context.Context, event *entity.LLMUsageEvent) error {
+	if err := r.store.llmUsageEvents.insert(ctx, event); err != nil {
+		return fmt.Errorf("failed to create llm usage event: %w", err)
+	}
+	return nil
+}
+
+// GetTokenUsage 获取租户在指定时间范围内的 Token 使用量（prompt + completion）
+func (r *LLMUsageEventRepository) GetTokenUsage(ctx context.Context, tenantID string, startInclusive, endExclusive time.Time) (int64, error) {
+	var total int64
+	for _, e := range r.store.llmUsageEvents.find(ctx, func(e *entity.LLMUsageEvent) bool {
+		return e.TenantID == tenantID && !e.CreatedAt.Before(startInclusive) && e.CreatedAt.Before(endExclusive)
+	}, nil) {
+		total += int64(e.TokensPrompt + e.TokensCompletion)
+	}
+	return total, nil
+}
+
+// List 按条件分页查询租户用量流水（按时间倒序）
+func (r *LLMUsageEventRepository) List(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.LLMUsageEvent], error) {
+	rows := r.store.llmUsageEvents.find(ctx, usageEventMatch(tenantID, filter), func(a, b *entity.LLMUsageEvent) bool {
+		return usageEventBefore(b, a)
+	})
+	return repository.NewPagedResult(limitOffset(rows, pagination.Offset(), pagination.Limit()), int64(len(rows)), pagination), nil
+}
+
+// ListAfter 按 (created_at, id) 升序游标读取用量流水
+func (r *LLMUsageEventRepository) ListAfter(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter, afterCreatedAt time.Time, afterID string, limit int) ([]*entity.LLMUsageEvent, error) {
+	match := usageEventMatch(tenantID, filter)
+	cursor := &entity.LLMUsageEvent{ID: afterID, CreatedAt: afterCreatedAt}
+	rows := r.store.llmUsageEvents.find(ctx, func(e *entity.LLMUsageEvent) bool {
+		return match(e) && (afterID == "" || usageEventBefore(cursor, e))
+	}, usageEventBefore)
+	return limitOffset(rows, 0, limit), nil
+}
+
+// Summarize 汇总符合条件的用量流水
+func (r *LLMUsageEventRepository) Summarize(ctx context.Context, tenantID string, filter *repository.LLMUsageEventFilter) (*repository.LLMUsageSummary, error) {
+	summary := &repository.LLMUsageSummary{}
+	for _, e := range r.store.llmUsageEvents.find(ctx, usageEventMatch(tenantID, filter), nil) {
+		summary.Events++
+		summary.TokensPrompt += int64(e.TokensPrompt)
+		summary.TokensCompletion += int64(e.TokensCompletion)
+		summary.DurationMs += int64(e.DurationMs)
+	}
+	return summary, nil
+}
+
+func usageEventMatch(tenantID string, filter *repository.LLMUsageEventFilter) func(*entity.LLMUsageEvent) bool {
+	return func(e *entity.LLMUsageEvent) bool {
+		if e.TenantID != tenantID {
+			return false
+		}
+		if filter == nil {
+			return true
+		}
+		return (filter.Start.IsZero() || !e.CreatedAt.Before(filter.Start)) &&
+			(filter.End.IsZero() || e.CreatedAt.Before(filter.End)) &&
+			(filter.Provider == "" || e.Provider == filter.Provider) &&
+			(filter.Model == "" || e.Model == filter.Model) &&
+			(filter.Workflow == "" || e.Workflow == filter.Workflow) &&
+			(filter.JobType == "" || e.JobType == filter.JobType) &&
+			(filter.ProjectID == "" || (e.ProjectID != nil && *e.ProjectID == filter.ProjectID))
+	}
+}
+
+// usageEventBefore (created_at, id) 升序
+func usageEventBefore(a, b *entity.LLMUsageEvent) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+var _ repository.LLMUsageEventRepository = (*LLMUsageEventRepository)(nil)
@@ -0,0 +1,53 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// PlanRepository 订阅套餐仓储内存实现
+type PlanRepository struct {
+	store *Store
+}
+
+// NewPlanRepository 创建订阅套餐仓储
+func NewPlanRepository(store *Store) *PlanRepository {
+	return &PlanRepository{store: store}
+}
+
+// Create 写入套餐（PostgreSQL 中套餐由迁移预置，内存实现用于准备测试数据）
+func (r *PlanRepository) Create(ctx context.Context, plan *entity.Plan) error {
+	if existing, _ := r.GetByCode(ctx, plan.Code); existing != nil {
+		return fmt.Errorf("failed to create plan: %w", ErrDuplicateKey)
+	}
+	if err := r.store.plans.insert(ctx, plan); err != nil {
+		return fmt.Errorf("failed to create plan: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取套餐
+func (r *PlanRepository) GetByID(ctx context.Context, id string) (*entity.Plan, error) {
+	return r.store.plans.get(ctx, id), nil
+}
+
+// GetByCode 根据编码获取套餐
+func (r *PlanRepository) GetByCode(ctx context.Context, code string) (*entity.Plan, error) {
+	return r.store.plans.first(ctx, func(p *entity.Plan) bool { return p.Code == code }, nil), nil
+}
+
+// List 获取套餐列表（按月度额度、编码升序）
+func (r *PlanRepository) List(ctx context.Context, activeOnly bool) ([]*entity.Plan, error) {
+	return r.store.plans.find(ctx, func(p *entity.Plan) bool { return !activeOnly || p.IsActive },
+		func(a, b *entity.Plan) bool {
+			if a.MonthlyTokens != b.MonthlyTokens {
+				return a.MonthlyTokens < b.MonthlyTokens
+			}
+			return a.Code < b.Code
+		}), nil
+}
+
+var _ repository.PlanRepository = (*PlanRepository)(nil)
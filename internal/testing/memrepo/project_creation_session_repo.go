@@ -0,0 +1,47 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ProjectCreationSessionRepository 项目创建会话仓储内存实现
+type ProjectCreationSessionRepository struct {
+	store *Store
+}
+
+// NewProjectCreationSessionRepository 创建项目创建会话仓储
+func NewProjectCreationSessionRepository(store *Store) *ProjectCreationSessionRepository {
+	return &ProjectCreationSessionRepository{store: store}
+}
+
+// Create 创建会话
+func (r *ProjectCreationSessionRepository) Create(ctx context.Context, session *entity.ProjectCreationSession) error {
+	if err := r.store.creationSessions.insert(ctx, session); err != nil {
+		return fmt.Errorf("failed to create project creation session: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取会话
+func (r *ProjectCreationSessionRepository) GetByID(ctx context.Context, id string) (*entity.ProjectCreationSession, error) {
+	return r.store.creationSessions.get(ctx, id), nil
+}
+
+// GetByIDForUpdate 根据 ID 获取会话（内存实现无行锁）
+func (r *ProjectCreationSessionRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.ProjectCreationSession, error) {
+	return r.store.creationSessions.get(ctx, id), nil
+}
+
+// Update 更新会话
+func (r *ProjectCreationSessionRepository) Update(ctx context.Context, session *entity.ProjectCreationSession) error {
+	if err := r.store.creationSessions.save(ctx, session); err != nil {
+		return fmt.Errorf("failed to update project creation session: %w", err)
+	}
+	return nil
+}
+
+var _ repository.ProjectCreationSessionRepository = (*ProjectCreationSessionRepository)(nil)
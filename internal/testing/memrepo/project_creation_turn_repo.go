@@ -0,0 +1,36 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ProjectCreationTurnRepository 项目创建会话轮次仓储内存实现
+type ProjectCreationTurnRepository struct {
+	store *Store
+}
+
+// NewProjectCreationTurnRepository 创建项目创建会话轮次仓储
+func NewProjectCreationTurnRepository(store *Store) *ProjectCreationTurnRepository {
+	return &ProjectCreationTurnRepository{store: store}
+}
+
+// Create 创建轮次
+func (r *ProjectCreationTurnRepository) Create(ctx context.Context, turn *entity.ProjectCreationTurn) error {
+	if err := r.store.creationTurns.insert(ctx, turn); err != nil {
+		return fmt.Errorf("failed to create project creation turn: %w", err)
+	}
+	return nil
+}
+
+// ListBySession 获取会话轮次（按时间升序）
+func (r *ProjectCreationTurnRepository) ListBySession(ctx context.Context, sessionID string, pagination repository.Pagination) (*repository.PagedResult[*entity.ProjectCreationTurn], error) {
+	rows := r.store.creationTurns.find(ctx, func(t *entity.ProjectCreationTurn) bool { return t.SessionID == sessionID },
+		func(a, b *entity.ProjectCreationTurn) bool { return a.CreatedAt.Before(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+var _ repository.ProjectCreationTurnRepository = (*ProjectCreationTurnRepository)(nil)
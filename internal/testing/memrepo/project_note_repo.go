@@ -0,0 +1,40 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ProjectNoteRepository 项目笔记仓储内存实现
+type ProjectNoteRepository struct {
+	store *Store
+}
+
+// NewProjectNoteRepository 创建项目笔记仓储
+func NewProjectNoteRepository(store *Store) *ProjectNoteRepository {
+	return &ProjectNoteRepository{store: store}
+}
+
+// Create 创建项目笔记
+func (r *ProjectNoteRepository) Create(ctx context.Context, note *entity.ProjectNote) error {
+	if err := r.store.projectNotes.insert(ctx, note); err != nil {
+		return fmt.Errorf("failed to create project note: %w", err)
+	}
+	return nil
+}
+
+// ListByProject 按导入顺序获取项目的全部笔记
+func (r *ProjectNoteRepository) ListByProject(ctx context.Context, projectID string) ([]*entity.ProjectNote, error) {
+	return r.store.projectNotes.find(ctx, func(n *entity.ProjectNote) bool { return n.ProjectID == projectID },
+		func(a, b *entity.ProjectNote) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		}), nil
+}
+
+var _ repository.ProjectNoteRepository = (*ProjectNoteRepository)(nil)
@@ -0,0 +1,94 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ProjectRepository 项目仓储内存实现
+type ProjectRepository struct {
+	store *Store
+}
+
+// NewProjectRepository 创建项目仓储
+func NewProjectRepository(store *Store) *ProjectRepository {
+	return &ProjectRepository{store: store}
+}
+
+// Create 创建项目
+func (r *ProjectRepository) Create(ctx context.Context, project *entity.Project) error {
+	if err := r.store.projects.insert(ctx, project); err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取项目
+func (r *ProjectRepository) GetByID(ctx context.Context, id string) (*entity.Project, error) {
+	return r.store.projects.get(ctx, id), nil
+}
+
+// Update 更新项目
+func (r *ProjectRepository) Update(ctx context.Context, project *entity.Project) error {
+	if err := r.store.projects.save(ctx, project); err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除项目
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+	r.store.projects.deleteByID(ctx, id)
+	return nil
+}
+
+// List 获取项目列表（按更新时间倒序）
+func (r *ProjectRepository) List(ctx context.Context, filter *repository.ProjectFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.Project], error) {
+	rows := r.store.projects.find(ctx, func(p *entity.Project) bool {
+		if filter == nil {
+			return true
+		}
+		if filter.OwnerID != "" && p.OwnerID != filter.OwnerID {
+			return false
+		}
+		if filter.Genre != "" && p.Genre != filter.Genre {
+			return false
+		}
+		return filter.Status == "" || p.Status == filter.Status
+	}, func(a, b *entity.Project) bool { return a.UpdatedAt.After(b.UpdatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// ListByOwner 获取用户项目列表
+func (r *ProjectRepository) ListByOwner(ctx context.Context, ownerID string, pagination repository.Pagination) (*repository.PagedResult[*entity.Project], error) {
+	return r.List(ctx, &repository.ProjectFilter{OwnerID: ownerID}, pagination)
+}
+
+// UpdateStatus 更新项目状态
+func (r *ProjectRepository) UpdateStatus(ctx context.Context, id string, status entity.ProjectStatus) error {
+	r.store.projects.updateByID(ctx, id, true, func(p *entity.Project) { p.Status = status })
+	return nil
+}
+
+// UpdateWordCount 更新字数统计
+func (r *ProjectRepository) UpdateWordCount(ctx context.Context, id string, wordCount int) error {
+	r.store.projects.updateByID(ctx, id, true, func(p *entity.Project) { p.CurrentWordCount = wordCount })
+	return nil
+}
+
+// GetStats 获取项目统计信息
+func (r *ProjectRepository) GetStats(ctx context.Context, id string) (*repository.ProjectStats, error) {
+	stats := &repository.ProjectStats{}
+	for _, c := range r.store.chapters.find(ctx, func(c *entity.Chapter) bool { return c.ProjectID == id }, nil) {
+		stats.TotalChapters++
+		stats.TotalWordCount += int64(c.WordCount)
+	}
+	stats.TotalVolumes = r.store.volumes.count(ctx, func(v *entity.Volume) bool { return v.ProjectID == id })
+	stats.TotalEntities = r.store.entities.count(ctx, func(e *entity.StoryEntity) bool { return e.ProjectID == id })
+	return stats, nil
+}
+
+var _ repository.ProjectRepository = (*ProjectRepository)(nil)
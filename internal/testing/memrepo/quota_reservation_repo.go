@@ -0,0 +1,96 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// QuotaReservationRepository 配额预留仓储内存实现
+type QuotaReservationRepository struct {
+	store *Store
+}
+
+// NewQuotaReservationRepository 创建配额预留仓储
+func NewQuotaReservationRepository(store *Store) *QuotaReservationRepository {
+	return &QuotaReservationRepository{store: store}
+}
+
+// Upsert 按任务创建或覆盖预留（job_id 唯一）
+func (r *QuotaReservationRepository) Upsert(ctx context.Context, reservation *entity.QuotaReservation) error {
+	now := r.store.now()
+	updated := r.store.quotaReservations.update(ctx, func(q *entity.QuotaReservation) bool {
+		return q.JobID == reservation.JobID
+	}, false, func(q *entity.QuotaReservation) {
+		q.Amount = reservation.Amount
+		q.SettledTokens = reservation.SettledTokens
+		q.Status = reservation.Status
+		q.ExpiresAt = reservation.ExpiresAt
+		q.UpdatedAt = now
+		reservation.ID = q.ID
+		reservation.CreatedAt = q.CreatedAt
+		reservation.UpdatedAt = now
+	})
+	if updated > 0 {
+		return nil
+	}
+	if err := r.store.quotaReservations.insert(ctx, reservation); err != nil {
+		return fmt.Errorf("failed to upsert quota reservation: %w", err)
+	}
+	return nil
+}
+
+// GetByJobID 根据任务 ID 获取预留
+func (r *QuotaReservationRepository) GetByJobID(ctx context.Context, jobID string) (*entity.QuotaReservation, error) {
+	return r.store.quotaReservations.first(ctx, func(q *entity.QuotaReservation) bool { return q.JobID == jobID }, nil), nil
+}
+
+// SumActive 汇总租户未过期的预留额度（可排除指定任务）
+func (r *QuotaReservationRepository) SumActive(ctx context.Context, tenantID, excludeJobID string) (int64, error) {
+	var total int64
+	for _, q := range r.active(ctx, tenantID, excludeJobID) {
+		total += q.Amount
+	}
+	return total, nil
+}
+
+// CountActive 统计租户未过期的预留数（可排除指定任务）
+func (r *QuotaReservationRepository) CountActive(ctx context.Context, tenantID, excludeJobID string) (int64, error) {
+	return int64(len(r.active(ctx, tenantID, excludeJobID))), nil
+}
+
+func (r *QuotaReservationRepository) active(ctx context.Context, tenantID, excludeJobID string) []*entity.QuotaReservation {
+	now := r.store.now()
+	return r.store.quotaReservations.find(ctx, func(q *entity.QuotaReservation) bool {
+		return q.TenantID == tenantID && q.Status == entity.QuotaReservationReserved && q.ExpiresAt.After(now) &&
+			(excludeJobID == "" || q.JobID != excludeJobID)
+	}, nil)
+}
+
+// Consume 按实际用量收缩仍为 reserved 的预留（不低于 0）
+func (r *QuotaReservationRepository) Consume(ctx context.Context, jobID string, tokens int64) error {
+	r.store.quotaReservations.update(ctx, func(q *entity.QuotaReservation) bool {
+		return q.JobID == jobID && q.Status == entity.QuotaReservationReserved
+	}, true, func(q *entity.QuotaReservation) {
+		q.Amount -= tokens
+		if q.Amount < 0 {
+			q.Amount = 0
+		}
+	})
+	return nil
+}
+
+// Finish 结算预留（仅处理仍为 reserved 的记录，返回是否有记录被更新）
+func (r *QuotaReservationRepository) Finish(ctx context.Context, jobID string, status entity.QuotaReservationStatus, settledTokens int64) (bool, error) {
+	n := r.store.quotaReservations.update(ctx, func(q *entity.QuotaReservation) bool {
+		return q.JobID == jobID && q.Status == entity.QuotaReservationReserved
+	}, true, func(q *entity.QuotaReservation) {
+		q.Status = status
+		q.SettledTokens = settledTokens
+	})
+	return n > 0, nil
+}
+
+var _ repository.QuotaReservationRepository = (*QuotaReservationRepository)(nil)
@@ -0,0 +1,133 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// RelationRepository 关系仓储内存实现
+type RelationRepository struct {
+	store *Store
+}
+
+// NewRelationRepository 创建关系仓储
+func NewRelationRepository(store *Store) *RelationRepository {
+	return &RelationRepository{store: store}
+}
+
+func relationStrongerFirst(a, b *entity.Relation) bool { return a.Strength > b.Strength }
+
+// Create 创建关系
+func (r *RelationRepository) Create(ctx context.Context, relation *entity.Relation) error {
+	if err := r.store.relations.insert(ctx, relation); err != nil {
+		return fmt.Errorf("failed to create relation: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取关系
+func (r *RelationRepository) GetByID(ctx context.Context, id string) (*entity.Relation, error) {
+	return r.store.relations.get(ctx, id), nil
+}
+
+// Update 更新关系
+func (r *RelationRepository) Update(ctx context.Context, relation *entity.Relation) error {
+	if err := r.store.relations.save(ctx, relation); err != nil {
+		return fmt.Errorf("failed to update relation: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除关系
+func (r *RelationRepository) Delete(ctx context.Context, id string) error {
+	r.store.relations.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 获取项目关系列表（按创建时间倒序）
+func (r *RelationRepository) ListByProject(ctx context.Context, projectID string, filter *repository.RelationFilter, pagination repository.Pagination) (*repository.PagedResult[*entity.Relation], error) {
+	rows := r.store.relations.find(ctx, func(rel *entity.Relation) bool {
+		if rel.ProjectID != projectID {
+			return false
+		}
+		if filter == nil {
+			return true
+		}
+		if filter.RelationType != "" && rel.RelationType != filter.RelationType {
+			return false
+		}
+		return filter.MinStrength <= 0 || rel.Strength >= filter.MinStrength
+	}, func(a, b *entity.Relation) bool { return a.CreatedAt.After(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// GetByEntities 根据两个实体获取关系
+func (r *RelationRepository) GetByEntities(ctx context.Context, projectID, sourceID, targetID string) (*entity.Relation, error) {
+	return r.store.relations.first(ctx, func(rel *entity.Relation) bool {
+		return rel.ProjectID == projectID && rel.SourceEntityID == sourceID && rel.TargetEntityID == targetID
+	}, nil), nil
+}
+
+// GetByEntitiesAndType 根据两个实体与关系类型获取关系
+func (r *RelationRepository) GetByEntitiesAndType(ctx context.Context, projectID, sourceID, targetID string, relationType entity.RelationType) (*entity.Relation, error) {
+	return r.store.relations.first(ctx, func(rel *entity.Relation) bool {
+		return rel.ProjectID == projectID && rel.SourceEntityID == sourceID && rel.TargetEntityID == targetID &&
+			rel.RelationType == relationType
+	}, nil), nil
+}
+
+// ListBySourceEntity 获取源实体的关系列表
+func (r *RelationRepository) ListBySourceEntity(ctx context.Context, entityID string) ([]*entity.Relation, error) {
+	return r.store.relations.find(ctx, func(rel *entity.Relation) bool { return rel.SourceEntityID == entityID }, relationStrongerFirst), nil
+}
+
+// ListByTargetEntity 获取目标实体的关系列表
+func (r *RelationRepository) ListByTargetEntity(ctx context.Context, entityID string) ([]*entity.Relation, error) {
+	return r.store.relations.find(ctx, func(rel *entity.Relation) bool { return rel.TargetEntityID == entityID }, relationStrongerFirst), nil
+}
+
+// ListByEntity 获取实体的全部关系
+func (r *RelationRepository) ListByEntity(ctx context.Context, entityID string) ([]*entity.Relation, error) {
+	return r.store.relations.find(ctx, func(rel *entity.Relation) bool {
+		return rel.SourceEntityID == entityID || rel.TargetEntityID == entityID
+	}, relationStrongerFirst), nil
+}
+
+// UpdateStrength 更新关系强度
+func (r *RelationRepository) UpdateStrength(ctx context.Context, id string, strength float64) error {
+	r.store.relations.updateByID(ctx, id, true, func(rel *entity.Relation) { rel.Strength = strength })
+	return nil
+}
+
+// UpdateReinforcement 写回章节强化结果（仅强度与出现/强化章节列）
+func (r *RelationRepository) UpdateReinforcement(ctx context.Context, relation *entity.Relation) error {
+	r.store.relations.updateByID(ctx, relation.ID, true, func(rel *entity.Relation) {
+		rel.Strength = relation.Strength
+		rel.FirstChapterID = relation.FirstChapterID
+		rel.LastChapterID = relation.LastChapterID
+		rel.LastReinforcedChapterID = relation.LastReinforcedChapterID
+	})
+	return nil
+}
+
+// DeleteByEntity 删除实体相关的所有关系
+func (r *RelationRepository) DeleteByEntity(ctx context.Context, entityID string) error {
+	r.store.relations.delete(ctx, func(rel *entity.Relation) bool {
+		return rel.SourceEntityID == entityID || rel.TargetEntityID == entityID
+	})
+	return nil
+}
+
+// GetRelationGraph 获取关系图谱（任一端在给定实体集合内）
+func (r *RelationRepository) GetRelationGraph(ctx context.Context, projectID string, entityIDs []string) ([]*entity.Relation, error) {
+	return r.store.relations.find(ctx, func(rel *entity.Relation) bool {
+		return rel.ProjectID == projectID &&
+			(slices.Contains(entityIDs, rel.SourceEntityID) || slices.Contains(entityIDs, rel.TargetEntityID))
+	}, relationStrongerFirst), nil
+}
+
+var _ repository.RelationRepository = (*RelationRepository)(nil)
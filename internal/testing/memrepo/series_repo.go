@@ -0,0 +1,92 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// SeriesRepository 系列仓储内存实现
+type SeriesRepository struct {
+	store *Store
+}
+
+// NewSeriesRepository 创建系列仓储
+func NewSeriesRepository(store *Store) *SeriesRepository {
+	return &SeriesRepository{store: store}
+}
+
+// Create 创建系列
+func (r *SeriesRepository) Create(ctx context.Context, series *entity.Series) error {
+	if err := r.store.series.insert(ctx, series); err != nil {
+		return fmt.Errorf("failed to create series: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取系列
+func (r *SeriesRepository) GetByID(ctx context.Context, id string) (*entity.Series, error) {
+	return r.store.series.get(ctx, id), nil
+}
+
+// Update 更新系列
+func (r *SeriesRepository) Update(ctx context.Context, series *entity.Series) error {
+	if err := r.store.series.save(ctx, series); err != nil {
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除系列（先解除项目归属）
+func (r *SeriesRepository) Delete(ctx context.Context, id string) error {
+	r.store.projects.update(ctx, func(p *entity.Project) bool {
+		return p.SeriesID != nil && *p.SeriesID == id
+	}, true, func(p *entity.Project) {
+		p.SeriesID = nil
+		p.SeriesOrder = 0
+	})
+	r.store.series.deleteByID(ctx, id)
+	return nil
+}
+
+// List 获取系列列表（按更新时间倒序）
+func (r *SeriesRepository) List(ctx context.Context, pagination repository.Pagination) (*repository.PagedResult[*entity.Series], error) {
+	rows := r.store.series.find(ctx, nil, func(a, b *entity.Series) bool { return a.UpdatedAt.After(b.UpdatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// ListProjects 获取系列下的项目（按系列内顺序）
+func (r *SeriesRepository) ListProjects(ctx context.Context, seriesID string) ([]*entity.Project, error) {
+	return r.store.projects.find(ctx, func(p *entity.Project) bool {
+		return p.SeriesID != nil && *p.SeriesID == seriesID
+	}, func(a, b *entity.Project) bool {
+		if a.SeriesOrder != b.SeriesOrder {
+			return a.SeriesOrder < b.SeriesOrder
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+// GetProjectStats 获取系列下各项目的统计信息
+func (r *SeriesRepository) GetProjectStats(ctx context.Context, seriesID string) ([]*repository.SeriesProjectStats, error) {
+	projects, _ := r.ListProjects(ctx, seriesID)
+	projectRepo := NewProjectRepository(r.store)
+	stats := make([]*repository.SeriesProjectStats, 0, len(projects))
+	for _, p := range projects {
+		ps, _ := projectRepo.GetStats(ctx, p.ID)
+		stats = append(stats, &repository.SeriesProjectStats{
+			ProjectID:      p.ID,
+			Title:          p.Title,
+			SeriesOrder:    p.SeriesOrder,
+			Status:         string(p.Status),
+			TotalChapters:  ps.TotalChapters,
+			TotalEntities:  ps.TotalEntities,
+			TotalWordCount: ps.TotalWordCount,
+		})
+	}
+	return stats, nil
+}
+
+var _ repository.SeriesRepository = (*SeriesRepository)(nil)
@@ -0,0 +1,51 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// SpoilerGuardRepository 剧透保护仓储内存实现
+type SpoilerGuardRepository struct {
+	store *Store
+}
+
+// NewSpoilerGuardRepository 创建剧透保护仓储
+func NewSpoilerGuardRepository(store *Store) *SpoilerGuardRepository {
+	return &SpoilerGuardRepository{store: store}
+}
+
+// Create 创建剧透保护
+func (r *SpoilerGuardRepository) Create(ctx context.Context, guard *entity.SpoilerGuard) error {
+	if err := r.store.spoilerGuards.insert(ctx, guard); err != nil {
+		return fmt.Errorf("failed to create spoiler guard: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取剧透保护
+func (r *SpoilerGuardRepository) GetByID(ctx context.Context, id string) (*entity.SpoilerGuard, error) {
+	return r.store.spoilerGuards.get(ctx, id), nil
+}
+
+// Delete 删除剧透保护
+func (r *SpoilerGuardRepository) Delete(ctx context.Context, id string) error {
+	r.store.spoilerGuards.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 按创建顺序获取项目的全部剧透保护
+func (r *SpoilerGuardRepository) ListByProject(ctx context.Context, projectID string) ([]*entity.SpoilerGuard, error) {
+	return r.store.spoilerGuards.find(ctx, func(g *entity.SpoilerGuard) bool { return g.ProjectID == projectID },
+		func(a, b *entity.SpoilerGuard) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		}), nil
+}
+
+var _ repository.SpoilerGuardRepository = (*SpoilerGuardRepository)(nil)
@@ -0,0 +1,597 @@
+// Package memrepo 提供 repository 接口的内存实现，供应用层单元测试使用（无需数据库）。
+//
+// 行为约定（与 PostgreSQL 实现对齐）：
+//   - 查询不到记录时 Get* 返回 (nil, nil)；列表按与 SQL 相同的字段排序、分页（repository.PagedResult）。
+//   - 租户隔离模拟 RLS：在 Transactor 事务内通过 TenantContext.SetTenant 设置租户后，
+//     带租户范围的表（含通过项目归属的表）只可见当前租户的数据，写入其他租户的数据返回错误；
+//     未设置租户时不做过滤（等同于表所有者连接）。
+//   - 事务返回错误时回滚该事务开始后的全部写入；嵌套调用复用外层事务。
+//   - 读写均复制结构体（浅拷贝），调用方修改返回值不会影响已存储数据。
+//   - 不模拟外键级联删除与数据库触发器。
+package memrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// ErrDuplicateKey 违反唯一约束
+var ErrDuplicateKey = errors.New("duplicate key value violates unique constraint")
+
+// ErrRowLevelSecurity 写入的行不属于当前租户
+var ErrRowLevelSecurity = errors.New("new row violates row-level security policy")
+
+// Store 内存数据集：同一 Store 上创建的仓储共享数据与事务
+type Store struct {
+	mu     sync.Mutex
+	tables []snapshotter
+	seq    int64
+
+	// rowLocks 行锁（模拟 SELECT ... FOR UPDATE），按表名 + 主键区分
+	lockMu   sync.Mutex
+	rowLocks map[string]*sync.Mutex
+
+	// Now 时间源（默认 time.Now），用于 created_at / updated_at 等自动时间戳
+	Now func() time.Time
+
+	tenants              *table[entity.Tenant]
+	users                *table[entity.User]
+	plans                *table[entity.Plan]
+	invoices             *table[entity.Invoice]
+	series               *table[entity.Series]
+	projects             *table[entity.Project]
+	volumes              *table[entity.Volume]
+	chapters             *table[entity.Chapter]
+	chapterStats         *table[entity.ChapterStats]
+	chapterVersions      *table[entity.ChapterVersion]
+	chapterFingerprints  *table[entity.ChapterFingerprint]
+	entities             *table[entity.StoryEntity]
+	entityStates         *table[entity.EntityState]
+	relations            *table[entity.Relation]
+	events               *table[entity.Event]
+	jobs                 *table[entity.GenerationJob]
+	jobEvents            *table[entity.JobEvent]
+	llmUsageEvents       *table[entity.LLMUsageEvent]
+	quotaReservations    *table[entity.QuotaReservation]
+	artifacts            *table[entity.ProjectArtifact]
+	artifactVersions     *table[entity.ArtifactVersion]
+	artifactTags         *table[entity.ArtifactVersionTag]
+	conversationSessions *table[entity.ConversationSession]
+	conversationTurns    *table[entity.ConversationTurn]
+	creationSessions     *table[entity.ProjectCreationSession]
+	creationTurns        *table[entity.ProjectCreationTurn]
+	spoilerGuards        *table[entity.SpoilerGuard]
+	projectNotes         *table[entity.ProjectNote]
+	featureFlags         *table[entity.FeatureFlag]
+	featureFlagOverrides *table[entity.FeatureFlagOverride]
+	generationCandidates *table[entity.GenerationCandidate]
+}
+
+// NewStore 创建空的内存数据集
+func NewStore() *Store {
+	s := &Store{Now: time.Now}
+
+	// 无 RLS 的全局表
+	s.tenants = newTable(s, func(v *entity.Tenant) string { return v.ID }, nil)
+	s.users = newTable(s, func(v *entity.User) string { return v.ID }, nil)
+	s.plans = newTable(s, func(v *entity.Plan) string { return v.ID }, nil)
+	s.featureFlags = newTable(s, func(v *entity.FeatureFlag) string { return v.Key }, nil)
+
+	// 自带 tenant_id 的表
+	s.invoices = newTable(s, func(v *entity.Invoice) string { return v.ID }, func(v *entity.Invoice) string { return v.TenantID })
+	s.series = newTable(s, func(v *entity.Series) string { return v.ID }, func(v *entity.Series) string { return v.TenantID })
+	s.projects = newTable(s, func(v *entity.Project) string { return v.ID }, func(v *entity.Project) string { return v.TenantID })
+	s.jobs = newTable(s, func(v *entity.GenerationJob) string { return v.ID }, func(v *entity.GenerationJob) string { return v.TenantID })
+	s.jobEvents = newTable(s, func(v *entity.JobEvent) string { return v.ID }, func(v *entity.JobEvent) string { return v.TenantID })
+	s.llmUsageEvents = newTable(s, func(v *entity.LLMUsageEvent) string { return v.ID }, func(v *entity.LLMUsageEvent) string { return v.TenantID })
+	s.quotaReservations = newTable(s, func(v *entity.QuotaReservation) string { return v.ID }, func(v *entity.QuotaReservation) string { return v.TenantID })
+	s.artifacts = newTable(s, func(v *entity.ProjectArtifact) string { return v.ID }, func(v *entity.ProjectArtifact) string { return v.TenantID })
+	s.artifactTags = newTable(s, func(v *entity.ArtifactVersionTag) string { return v.ID }, func(v *entity.ArtifactVersionTag) string { return v.TenantID })
+	s.conversationSessions = newTable(s, func(v *entity.ConversationSession) string { return v.ID }, func(v *entity.ConversationSession) string { return v.TenantID })
+	s.creationSessions = newTable(s, func(v *entity.ProjectCreationSession) string { return v.ID }, func(v *entity.ProjectCreationSession) string { return v.TenantID })
+	s.spoilerGuards = newTable(s, func(v *entity.SpoilerGuard) string { return v.ID }, func(v *entity.SpoilerGuard) string { return v.TenantID })
+	s.projectNotes = newTable(s, func(v *entity.ProjectNote) string { return v.ID }, func(v *entity.ProjectNote) string { return v.TenantID })
+	s.featureFlagOverrides = newTable(s, func(v *entity.FeatureFlagOverride) string { return v.ID }, func(v *entity.FeatureFlagOverride) string { return v.TenantID })
+	s.generationCandidates = newTable(s, func(v *entity.GenerationCandidate) string { return v.ID }, func(v *entity.GenerationCandidate) string { return v.TenantID })
+
+	// 通过项目 / 父记录归属租户的表（与迁移中 RLS 策略的 EXISTS 子查询一致）
+	s.volumes = newTable(s, func(v *entity.Volume) string { return v.ID }, func(v *entity.Volume) string { return s.projectTenant(v.ProjectID) })
+	s.chapters = newTable(s, func(v *entity.Chapter) string { return v.ID }, func(v *entity.Chapter) string { return s.projectTenant(v.ProjectID) })
+	s.chapterStats = newTable(s, func(v *entity.ChapterStats) string { return v.ChapterID }, func(v *entity.ChapterStats) string { return s.projectTenant(v.ProjectID) })
+	s.chapterVersions = newTable(s, func(v *entity.ChapterVersion) string { return v.Key() }, func(v *entity.ChapterVersion) string { return s.projectTenant(v.ProjectID) })
+	s.chapterFingerprints = newTable(s, func(v *entity.ChapterFingerprint) string { return v.ChapterID }, func(v *entity.ChapterFingerprint) string { return s.projectTenant(v.ProjectID) })
+	s.entities = newTable(s, func(v *entity.StoryEntity) string { return v.ID }, func(v *entity.StoryEntity) string { return s.projectTenant(v.ProjectID) })
+	s.relations = newTable(s, func(v *entity.Relation) string { return v.ID }, func(v *entity.Relation) string { return s.projectTenant(v.ProjectID) })
+	s.events = newTable(s, func(v *entity.Event) string { return v.ID }, func(v *entity.Event) string { return s.projectTenant(v.ProjectID) })
+	s.entityStates = newTable(s, func(v *entity.EntityState) string { return v.ID }, func(v *entity.EntityState) string {
+		if e, ok := s.entities.rows[v.EntityID]; ok {
+			return s.projectTenant(e.ProjectID)
+		}
+		return ""
+	})
+	s.artifactVersions = newTable(s, func(v *entity.ArtifactVersion) string { return v.ID }, func(v *entity.ArtifactVersion) string {
+		if a, ok := s.artifacts.rows[v.ArtifactID]; ok {
+			return a.TenantID
+		}
+		return ""
+	})
+	s.conversationTurns = newTable(s, func(v *entity.ConversationTurn) string { return v.ID }, func(v *entity.ConversationTurn) string {
+		if cs, ok := s.conversationSessions.rows[v.SessionID]; ok {
+			return cs.TenantID
+		}
+		return ""
+	})
+	s.creationTurns = newTable(s, func(v *entity.ProjectCreationTurn) string { return v.ID }, func(v *entity.ProjectCreationTurn) string {
+		if cs, ok := s.creationSessions.rows[v.SessionID]; ok {
+			return cs.TenantID
+		}
+		return ""
+	})
+	return s
+}
+
+// projectTenant 项目所属租户（调用方持有 mu；项目不存在时返回空，设置租户后不可见）
+func (s *Store) projectTenant(projectID string) string {
+	if p, ok := s.projects.rows[projectID]; ok {
+		return p.TenantID
+	}
+	return ""
+}
+
+func (s *Store) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+type snapshotter interface {
+	snapshot() (restore func())
+}
+
+// txState 事务状态（通过 ctx 传递）
+type txState struct {
+	tenantID string
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// releaseLocks 事务结束时释放持有的行锁
+func (tx *txState) releaseLocks() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for key, l := range tx.locks {
+		l.Unlock()
+		delete(tx.locks, key)
+	}
+}
+
+// lockRow 在事务内获取行锁并持有到事务结束（同一事务重复获取直接通过；事务外调用不加锁，与 PostgreSQL 自动提交语义一致）
+func (s *Store) lockRow(ctx context.Context, key string) {
+	tx := txFromContext(ctx)
+	if tx == nil {
+		return
+	}
+	tx.mu.Lock()
+	_, held := tx.locks[key]
+	tx.mu.Unlock()
+	if held {
+		return
+	}
+
+	s.lockMu.Lock()
+	if s.rowLocks == nil {
+		s.rowLocks = make(map[string]*sync.Mutex)
+	}
+	l, ok := s.rowLocks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		s.rowLocks[key] = l
+	}
+	s.lockMu.Unlock()
+
+	l.Lock()
+	tx.mu.Lock()
+	if tx.locks == nil {
+		tx.locks = make(map[string]*sync.Mutex)
+	}
+	tx.locks[key] = l
+	tx.mu.Unlock()
+}
+
+type txKey struct{}
+
+func txFromContext(ctx context.Context) *txState {
+	if tx, ok := ctx.Value(txKey{}).(*txState); ok {
+		return tx
+	}
+	return nil
+}
+
+// currentTenant 当前事务设置的租户（未设置时为空，不做过滤）
+func currentTenant(ctx context.Context) string {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.tenantID
+	}
+	return ""
+}
+
+// TxManager 内存事务管理器
+type TxManager struct {
+	store *Store
+}
+
+// NewTxManager 创建内存事务管理器
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// WithTransaction 在事务中执行操作；fn 返回错误时回滚本事务内的全部写入
+func (m *TxManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	restore := m.store.snapshot()
+	tx := &txState{}
+	txCtx := context.WithValue(ctx, txKey{}, tx)
+	defer tx.releaseLocks()
+	if err := fn(txCtx); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+func (s *Store) snapshot() func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	restores := make([]func(), 0, len(s.tables))
+	for _, t := range s.tables {
+		restores = append(restores, t.snapshot())
+	}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, r := range restores {
+			r()
+		}
+	}
+}
+
+// TenantContext 内存租户上下文（模拟 set_config('app.current_tenant_id', ..., TRUE)）
+type TenantContext struct{}
+
+// NewTenantContext 创建内存租户上下文
+func NewTenantContext(_ *Store) *TenantContext {
+	return &TenantContext{}
+}
+
+// SetTenant 设置当前事务的租户（事务外调用返回错误，与事务级 set_config 一致）
+func (tc *TenantContext) SetTenant(ctx context.Context, tenantID string) error {
+	tx := txFromContext(ctx)
+	if tx == nil {
+		return fmt.Errorf("failed to set tenant context: not in transaction")
+	}
+	tx.tenantID = tenantID
+	return nil
+}
+
+// ClearTenant 清除当前事务的租户
+func (tc *TenantContext) ClearTenant(ctx context.Context) error {
+	if tx := txFromContext(ctx); tx != nil {
+		tx.tenantID = ""
+	}
+	return nil
+}
+
+// GetCurrentTenant 获取当前事务的租户
+func (tc *TenantContext) GetCurrentTenant(ctx context.Context) (string, error) {
+	return currentTenant(ctx), nil
+}
+
+// ProjectLocker 内存项目锁（单进程测试下无需互斥，仅校验在事务内调用）
+type ProjectLocker struct{}
+
+// NewProjectLocker 创建内存项目锁
+func NewProjectLocker(_ *Store) *ProjectLocker {
+	return &ProjectLocker{}
+}
+
+// LockProject 获取项目排他锁
+func (l *ProjectLocker) LockProject(ctx context.Context, projectID string) error {
+	if txFromContext(ctx) == nil {
+		return fmt.Errorf("failed to lock project %s: not in transaction", projectID)
+	}
+	return nil
+}
+
+// LockProjectShared 获取项目共享锁
+func (l *ProjectLocker) LockProjectShared(ctx context.Context, projectID string) error {
+	return l.LockProject(ctx, projectID)
+}
+
+// table 单表存储：按主键保存值拷贝，并记录插入顺序作为排序的最终依据
+type table[T any] struct {
+	store  *Store
+	rows   map[string]T
+	seqs   map[string]int64
+	id     func(*T) string
+	tenant func(*T) string // nil 表示该表不受租户隔离
+}
+
+func newTable[T any](store *Store, id func(*T) string, tenant func(*T) string) *table[T] {
+	t := &table[T]{
+		store:  store,
+		rows:   make(map[string]T),
+		seqs:   make(map[string]int64),
+		id:     id,
+		tenant: tenant,
+	}
+	store.tables = append(store.tables, t)
+	return t
+}
+
+func (t *table[T]) snapshot() func() {
+	rows := make(map[string]T, len(t.rows))
+	for k, v := range t.rows {
+		rows[k] = v
+	}
+	seqs := make(map[string]int64, len(t.seqs))
+	for k, v := range t.seqs {
+		seqs[k] = v
+	}
+	return func() {
+		t.rows = rows
+		t.seqs = seqs
+	}
+}
+
+// visible 判断行对当前租户是否可见（调用方持有 store.mu）
+func (t *table[T]) visible(ctx context.Context, v *T) bool {
+	tenantID := currentTenant(ctx)
+	if tenantID == "" || t.tenant == nil {
+		return true
+	}
+	return t.tenant(v) == tenantID
+}
+
+// insert 插入新行：自动填充空 ID 与 created_at / updated_at；主键冲突返回 ErrDuplicateKey
+func (t *table[T]) insert(ctx context.Context, v *T) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	fillDefaults(v, t.store.now())
+	if !t.visible(ctx, v) {
+		return ErrRowLevelSecurity
+	}
+	id := t.id(v)
+	if _, ok := t.rows[id]; ok {
+		return ErrDuplicateKey
+	}
+	t.store.seq++
+	t.rows[id] = *v
+	t.seqs[id] = t.store.seq
+	return nil
+}
+
+// save 按主键整行写入（不存在则插入），等价于 GORM Save
+func (t *table[T]) save(ctx context.Context, v *T) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	id := t.id(v)
+	if old, ok := t.rows[id]; ok && !t.visible(ctx, &old) {
+		return ErrRowLevelSecurity
+	}
+	fillDefaults(v, t.store.now())
+	touchUpdatedAt(v, t.store.now())
+	if !t.visible(ctx, v) {
+		return ErrRowLevelSecurity
+	}
+	if _, ok := t.rows[id]; !ok {
+		t.store.seq++
+		t.seqs[id] = t.store.seq
+	}
+	t.rows[id] = *v
+	return nil
+}
+
+// get 按主键读取（不可见或不存在时返回 nil）
+func (t *table[T]) get(ctx context.Context, id string) *T {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	return t.getLocked(ctx, id)
+}
+
+func (t *table[T]) getLocked(ctx context.Context, id string) *T {
+	v, ok := t.rows[id]
+	if !ok || !t.visible(ctx, &v) {
+		return nil
+	}
+	return &v
+}
+
+// first 返回按 less 排序后第一条满足条件的行
+func (t *table[T]) first(ctx context.Context, match func(*T) bool, less func(a, b *T) bool) *T {
+	rows := t.find(ctx, match, less)
+	if len(rows) == 0 {
+		return nil
+	}
+	return rows[0]
+}
+
+// find 返回满足条件的可见行拷贝；less 为 nil 时按插入顺序
+func (t *table[T]) find(ctx context.Context, match func(*T) bool, less func(a, b *T) bool) []*T {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	return t.findLocked(ctx, match, less)
+}
+
+func (t *table[T]) findLocked(ctx context.Context, match func(*T) bool, less func(a, b *T) bool) []*T {
+	type item struct {
+		v   *T
+		seq int64
+	}
+	items := make([]item, 0)
+	for id, v := range t.rows {
+		v := v
+		if !t.visible(ctx, &v) {
+			continue
+		}
+		if match != nil && !match(&v) {
+			continue
+		}
+		items = append(items, item{v: &v, seq: t.seqs[id]})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if less != nil {
+			if less(items[i].v, items[j].v) {
+				return true
+			}
+			if less(items[j].v, items[i].v) {
+				return false
+			}
+		}
+		return items[i].seq < items[j].seq
+	})
+	out := make([]*T, len(items))
+	for i := range items {
+		out[i] = items[i].v
+	}
+	return out
+}
+
+// count 统计满足条件的可见行数
+func (t *table[T]) count(ctx context.Context, match func(*T) bool) int {
+	return len(t.find(ctx, match, nil))
+}
+
+// update 按条件原地修改可见行并返回影响行数；touch 为 true 时同时刷新 updated_at（同 GORM Model().Update）
+func (t *table[T]) update(ctx context.Context, match func(*T) bool, touch bool, fn func(*T)) int {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	n := 0
+	for id, v := range t.rows {
+		v := v
+		if !t.visible(ctx, &v) || (match != nil && !match(&v)) {
+			continue
+		}
+		fn(&v)
+		if touch {
+			touchUpdatedAt(&v, t.store.now())
+		}
+		t.rows[id] = v
+		n++
+	}
+	return n
+}
+
+// updateByID 修改指定主键的行，返回是否命中
+func (t *table[T]) updateByID(ctx context.Context, id string, touch bool, fn func(*T)) bool {
+	return t.update(ctx, func(v *T) bool { return t.id(v) == id }, touch, fn) > 0
+}
+
+// delete 删除满足条件的可见行并返回影响行数
+func (t *table[T]) delete(ctx context.Context, match func(*T) bool) int {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	n := 0
+	for id, v := range t.rows {
+		v := v
+		if !t.visible(ctx, &v) || (match != nil && !match(&v)) {
+			continue
+		}
+		delete(t.rows, id)
+		delete(t.seqs, id)
+		n++
+	}
+	return n
+}
+
+// deleteByID 删除指定主键的行
+func (t *table[T]) deleteByID(ctx context.Context, id string) int {
+	return t.delete(ctx, func(v *T) bool { return t.id(v) == id })
+}
+
+// paginate 对已排序结果做 OFFSET/LIMIT 并包装为分页结果（Total 为过滤后的总数）
+func paginate[T any](rows []*T, pagination repository.Pagination) *repository.PagedResult[*T] {
+	total := int64(len(rows))
+	return repository.NewPagedResult(limitOffset(rows, pagination.Offset(), pagination.Limit()), total, pagination)
+}
+
+// limitOffset 等价于 SQL OFFSET/LIMIT（limit <= 0 表示不限制）
+func limitOffset[T any](rows []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return []T{}
+	}
+	rows = rows[offset:]
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// fillDefaults 模拟数据库默认值：空字符串主键生成 UUID，零值 CreatedAt / UpdatedAt 填充当前时间
+func fillDefaults(v any, now time.Time) {
+	rv := reflect.ValueOf(v).Elem()
+	if f := rv.FieldByName("ID"); f.IsValid() && f.Kind() == reflect.String && f.String() == "" {
+		f.SetString(uuid.NewString())
+	}
+	for _, name := range []string{"CreatedAt", "UpdatedAt"} {
+		if f := rv.FieldByName(name); f.IsValid() && f.Type() == reflect.TypeOf(time.Time{}) && f.Interface().(time.Time).IsZero() {
+			f.Set(reflect.ValueOf(now))
+		}
+	}
+}
+
+// touchUpdatedAt 模拟 GORM autoUpdateTime
+func touchUpdatedAt(v any, now time.Time) {
+	rv := reflect.ValueOf(v).Elem()
+	if f := rv.FieldByName("UpdatedAt"); f.IsValid() && f.Type() == reflect.TypeOf(time.Time{}) {
+		f.Set(reflect.ValueOf(now))
+	}
+}
+
+// reorderIDs 计算重排后的顺序：先按给定 ID（去空白、去重），再追加未包含的现有 ID（保持原顺序）
+func reorderIDs(requested, existing []string) []string {
+	seen := make(map[string]struct{}, len(existing))
+	order := make([]string, 0, len(existing))
+	for _, id := range requested {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		order = append(order, id)
+	}
+	for _, id := range existing {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		order = append(order, id)
+	}
+	return order
+}
+
+var (
+	_ repository.Transactor           = (*TxManager)(nil)
+	_ repository.TenantContextManager = (*TenantContext)(nil)
+	_ repository.ProjectLocker        = (*ProjectLocker)(nil)
+)
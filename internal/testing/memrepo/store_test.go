@@ -0,0 +1,221 @@
+package memrepo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	store := NewStore()
+	projects := NewProjectRepository(store)
+	txMgr := NewTxManager(store)
+	ctx := context.Background()
+
+	kept := entity.NewProject("tenant-1", "owner-1", "保留")
+	if err := projects.Create(ctx, kept); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	boom := errors.New("boom")
+	err := txMgr.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := projects.Create(ctx, entity.NewProject("tenant-1", "owner-1", "回滚")); err != nil {
+			return err
+		}
+		return txMgr.WithTransaction(ctx, func(ctx context.Context) error {
+			return projects.UpdateWordCount(ctx, kept.ID, 999)
+		})
+	})
+	if err != nil {
+		t.Fatalf("nested transaction: %v", err)
+	}
+
+	err = txMgr.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := projects.Create(ctx, entity.NewProject("tenant-1", "owner-1", "回滚")); err != nil {
+			return err
+		}
+		if err := projects.UpdateWordCount(ctx, kept.ID, 1); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+
+	result, _ := projects.List(ctx, nil, repository.NewPagination(1, 20))
+	if result.Total != 2 {
+		t.Fatalf("total = %d, want 2 (committed rows only)", result.Total)
+	}
+	got, _ := projects.GetByID(ctx, kept.ID)
+	if got.CurrentWordCount != 999 {
+		t.Fatalf("word count = %d, want 999 from the committed transaction", got.CurrentWordCount)
+	}
+}
+
+func TestTenantScopingInsideTransaction(t *testing.T) {
+	store := NewStore()
+	projects := NewProjectRepository(store)
+	volumes := NewVolumeRepository(store)
+	txMgr := NewTxManager(store)
+	tenantCtx := NewTenantContext(store)
+	ctx := context.Background()
+
+	own := entity.NewProject("tenant-1", "owner-1", "本租户")
+	other := entity.NewProject("tenant-2", "owner-2", "其他租户")
+	for _, p := range []*entity.Project{own, other} {
+		if err := projects.Create(ctx, p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	otherVolume := entity.NewVolume(other.ID, 1, "第一卷")
+	if err := volumes.Create(ctx, otherVolume); err != nil {
+		t.Fatalf("create volume: %v", err)
+	}
+
+	if err := tenantCtx.SetTenant(ctx, "tenant-1"); err == nil {
+		t.Fatalf("SetTenant outside transaction should fail")
+	}
+
+	err := txMgr.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tenantCtx.SetTenant(ctx, "tenant-1"); err != nil {
+			return err
+		}
+		if p, _ := projects.GetByID(ctx, other.ID); p != nil {
+			t.Errorf("project of another tenant is visible")
+		}
+		if v, _ := volumes.GetByID(ctx, otherVolume.ID); v != nil {
+			t.Errorf("volume scoped through another tenant's project is visible")
+		}
+		result, _ := projects.List(ctx, nil, repository.NewPagination(1, 20))
+		if result.Total != 1 || result.Items[0].ID != own.ID {
+			t.Errorf("list = %d items, want only the current tenant's project", result.Total)
+		}
+		if err := projects.Create(ctx, entity.NewProject("tenant-2", "owner-2", "越权")); !errors.Is(err, ErrRowLevelSecurity) {
+			t.Errorf("cross-tenant insert err = %v, want ErrRowLevelSecurity", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	// 未设置租户时不过滤
+	if p, _ := projects.GetByID(ctx, other.ID); p == nil {
+		t.Fatalf("project should be visible without tenant context")
+	}
+}
+
+func TestPaginationMatchesPostgres(t *testing.T) {
+	store := NewStore()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tick := 0
+	store.Now = func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Minute)
+	}
+	projects := NewProjectRepository(store)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		p := entity.NewProject("tenant-1", "owner-1", "项目")
+		p.CreatedAt, p.UpdatedAt = time.Time{}, time.Time{}
+		if err := projects.Create(ctx, p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		ids = append(ids, p.ID)
+	}
+
+	page, _ := projects.List(ctx, nil, repository.NewPagination(2, 2))
+	if page.Total != 5 || page.TotalPages != 3 || page.Page != 2 || page.PageSize != 2 {
+		t.Fatalf("unexpected page meta: %+v", page)
+	}
+	// 按更新时间倒序：第 2 页为第 3、2 个创建的项目
+	if len(page.Items) != 2 || page.Items[0].ID != ids[2] || page.Items[1].ID != ids[1] {
+		t.Fatalf("unexpected page items")
+	}
+
+	last, _ := projects.List(ctx, nil, repository.NewPagination(4, 2))
+	if len(last.Items) != 0 || last.Total != 5 {
+		t.Fatalf("page past the end = %d items, total %d", len(last.Items), last.Total)
+	}
+}
+
+func TestReturnedRowsAreCopies(t *testing.T) {
+	store := NewStore()
+	projects := NewProjectRepository(store)
+	ctx := context.Background()
+
+	p := entity.NewProject("tenant-1", "owner-1", "原标题")
+	if err := projects.Create(ctx, p); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	p.Title = "调用方修改"
+	got, _ := projects.GetByID(ctx, p.ID)
+	if got.Title != "原标题" {
+		t.Fatalf("stored row changed through caller's pointer: %q", got.Title)
+	}
+	got.Title = "再次修改"
+	again, _ := projects.GetByID(ctx, p.ID)
+	if again.Title != "原标题" {
+		t.Fatalf("stored row changed through returned pointer: %q", again.Title)
+	}
+}
+
+func TestChapterWritesRefreshVolumeRollup(t *testing.T) {
+	store := NewStore()
+	volumes := NewVolumeRepository(store)
+	chapters := NewChapterRepository(store)
+	ctx := context.Background()
+
+	v1 := entity.NewVolume("project-1", 1, "卷一")
+	v2 := entity.NewVolume("project-1", 2, "卷二")
+	for _, v := range []*entity.Volume{v1, v2} {
+		if err := volumes.Create(ctx, v); err != nil {
+			t.Fatalf("create volume: %v", err)
+		}
+	}
+
+	a := entity.NewChapter("project-1", v1.ID, 1)
+	b := entity.NewChapter("project-1", v1.ID, 2)
+	for _, c := range []*entity.Chapter{a, b} {
+		if err := chapters.Create(ctx, c); err != nil {
+			t.Fatalf("create chapter: %v", err)
+		}
+	}
+	if err := chapters.UpdateContent(ctx, a.ID, strings.Repeat("字", 900), ""); err != nil {
+		t.Fatalf("update content: %v", err)
+	}
+	if err := chapters.UpdateStatus(ctx, a.ID, entity.ChapterStatusCompleted); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	got, _ := volumes.GetByID(ctx, v1.ID)
+	if got.WordCount != 900 || got.ChapterCount != 2 || got.CompletedChapters != 1 || got.DraftChapters != 1 || got.ReadingMinutes() != 3 {
+		t.Fatalf("unexpected rollup: %+v (reading %d min)", got, got.ReadingMinutes())
+	}
+
+	// 章节移到另一卷时新旧两卷都重新汇总
+	moved, _ := chapters.GetByID(ctx, a.ID)
+	moved.VolumeID = v2.ID
+	if err := chapters.Update(ctx, moved); err != nil {
+		t.Fatalf("move chapter: %v", err)
+	}
+	if err := chapters.Delete(ctx, b.ID); err != nil {
+		t.Fatalf("delete chapter: %v", err)
+	}
+	got, _ = volumes.GetByID(ctx, v1.ID)
+	if got.WordCount != 0 || got.ChapterCount != 0 {
+		t.Fatalf("source volume should be empty, got %+v", got)
+	}
+	got, _ = volumes.GetByID(ctx, v2.ID)
+	if got.WordCount != 900 || got.ChapterCount != 1 || got.CompletedChapters != 1 {
+		t.Fatalf("target volume should carry the moved chapter, got %+v", got)
+	}
+}
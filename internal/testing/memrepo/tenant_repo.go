@@ -0,0 +1,112 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// TenantRepository 租户仓储内存实现
+type TenantRepository struct {
+	store *Store
+}
+
+// NewTenantRepository 创建租户仓储
+func NewTenantRepository(store *Store) *TenantRepository {
+	return &TenantRepository{store: store}
+}
+
+// Create 创建租户
+func (r *TenantRepository) Create(ctx context.Context, tenant *entity.Tenant) error {
+	if existing := r.store.tenants.first(ctx, func(t *entity.Tenant) bool { return t.Slug == tenant.Slug }, nil); existing != nil {
+		return fmt.Errorf("failed to create tenant: %w", ErrDuplicateKey)
+	}
+	if err := r.store.tenants.insert(ctx, tenant); err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取租户
+func (r *TenantRepository) GetByID(ctx context.Context, id string) (*entity.Tenant, error) {
+	return r.store.tenants.get(ctx, id), nil
+}
+
+// GetByIDForUpdate 根据 ID 获取租户并持有行锁直到事务结束
+func (r *TenantRepository) GetByIDForUpdate(ctx context.Context, id string) (*entity.Tenant, error) {
+	r.store.lockRow(ctx, "tenants:"+id)
+	return r.store.tenants.get(ctx, id), nil
+}
+
+// GetBySlug 根据 Slug 获取租户
+func (r *TenantRepository) GetBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
+	return r.store.tenants.first(ctx, func(t *entity.Tenant) bool { return t.Slug == slug }, nil), nil
+}
+
+// Update 更新租户
+func (r *TenantRepository) Update(ctx context.Context, tenant *entity.Tenant) error {
+	if err := r.store.tenants.save(ctx, tenant); err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除租户
+func (r *TenantRepository) Delete(ctx context.Context, id string) error {
+	r.store.tenants.deleteByID(ctx, id)
+	return nil
+}
+
+// List 获取租户列表（按创建时间倒序）
+func (r *TenantRepository) List(ctx context.Context, pagination repository.Pagination) (*repository.PagedResult[*entity.Tenant], error) {
+	rows := r.store.tenants.find(ctx, nil, func(a, b *entity.Tenant) bool { return a.CreatedAt.After(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// UpdateStatus 更新租户状态
+func (r *TenantRepository) UpdateStatus(ctx context.Context, id string, status entity.TenantStatus) error {
+	r.store.tenants.updateByID(ctx, id, true, func(t *entity.Tenant) { t.Status = status })
+	return nil
+}
+
+// ExistsBySlug 检查 Slug 是否存在
+func (r *TenantRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	return r.store.tenants.count(ctx, func(t *entity.Tenant) bool { return t.Slug == slug }) > 0, nil
+}
+
+// ListQuotaResetDue 获取配额周期已到期且已关联套餐的租户 ID（按周期结束时间升序）
+func (r *TenantRepository) ListQuotaResetDue(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	rows := r.store.tenants.find(ctx, func(t *entity.Tenant) bool {
+		return t.PlanID != nil && t.QuotaPeriodEnd != nil && !t.QuotaPeriodEnd.After(before) && t.Status != entity.TenantStatusDeleted
+	}, func(a, b *entity.Tenant) bool { return a.QuotaPeriodEnd.Before(*b.QuotaPeriodEnd) })
+	rows = limitOffset(rows, 0, limit)
+	ids := make([]string, 0, len(rows))
+	for _, t := range rows {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// DeductBalance 原子扣除租户余额（余额不足时不扣除并返回错误）
+func (r *TenantRepository) DeductBalance(ctx context.Context, id string, amount int64) error {
+	n := r.store.tenants.update(ctx, func(t *entity.Tenant) bool {
+		return t.ID == id && t.TokenBalance >= amount
+	}, true, func(t *entity.Tenant) { t.TokenBalance -= amount })
+	if n == 0 {
+		return fmt.Errorf("insufficient balance or tenant not found: %s", id)
+	}
+	return nil
+}
+
+// CreditBalance 原子增加租户余额
+func (r *TenantRepository) CreditBalance(ctx context.Context, id string, amount int64) error {
+	if !r.store.tenants.updateByID(ctx, id, true, func(t *entity.Tenant) { t.TokenBalance += amount }) {
+		return fmt.Errorf("tenant not found: %s", id)
+	}
+	return nil
+}
+
+var _ repository.TenantRepository = (*TenantRepository)(nil)
@@ -0,0 +1,90 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// UserRepository 用户仓储内存实现
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// Create 创建用户（租户内邮箱唯一）
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	if exists, _ := r.ExistsByEmail(ctx, user.TenantID, user.Email); exists {
+		return fmt.Errorf("failed to create user: %w", ErrDuplicateKey)
+	}
+	if err := r.store.users.insert(ctx, user); err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取用户
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	return r.store.users.get(ctx, id), nil
+}
+
+// GetByEmail 根据邮箱获取用户
+func (r *UserRepository) GetByEmail(ctx context.Context, tenantID, email string) (*entity.User, error) {
+	return r.store.users.first(ctx, func(u *entity.User) bool {
+		return u.TenantID == tenantID && u.Email == email
+	}, nil), nil
+}
+
+// GetByExternalID 根据外部 ID 获取用户
+func (r *UserRepository) GetByExternalID(ctx context.Context, externalID string) (*entity.User, error) {
+	return r.store.users.first(ctx, func(u *entity.User) bool { return u.ExternalID == externalID }, nil), nil
+}
+
+// Update 更新用户
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := r.store.users.save(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除用户
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.store.users.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByTenant 获取租户用户列表（按创建时间倒序）
+func (r *UserRepository) ListByTenant(ctx context.Context, tenantID string, pagination repository.Pagination) (*repository.PagedResult[*entity.User], error) {
+	rows := r.store.users.find(ctx, func(u *entity.User) bool { return u.TenantID == tenantID },
+		func(a, b *entity.User) bool { return a.CreatedAt.After(b.CreatedAt) })
+	return paginate(rows, pagination), nil
+}
+
+// UpdateRole 更新用户角色
+func (r *UserRepository) UpdateRole(ctx context.Context, id string, role entity.UserRole) error {
+	r.store.users.updateByID(ctx, id, true, func(u *entity.User) { u.Role = role })
+	return nil
+}
+
+// UpdateLastLogin 更新最后登录时间
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id string) error {
+	now := r.store.now()
+	r.store.users.updateByID(ctx, id, true, func(u *entity.User) { u.LastLoginAt = &now })
+	return nil
+}
+
+// ExistsByEmail 检查邮箱是否存在
+func (r *UserRepository) ExistsByEmail(ctx context.Context, tenantID, email string) (bool, error) {
+	return r.store.users.count(ctx, func(u *entity.User) bool {
+		return u.TenantID == tenantID && u.Email == email
+	}) > 0, nil
+}
+
+var _ repository.UserRepository = (*UserRepository)(nil)
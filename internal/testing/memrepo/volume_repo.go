@@ -0,0 +1,110 @@
+package memrepo
+
+import (
+	"context"
+	"fmt"
+
+	"z-novel-ai-api/internal/domain/entity"
+	"z-novel-ai-api/internal/domain/repository"
+)
+
+// VolumeRepository 卷仓储内存实现
+type VolumeRepository struct {
+	store *Store
+}
+
+// NewVolumeRepository 创建卷仓储
+func NewVolumeRepository(store *Store) *VolumeRepository {
+	return &VolumeRepository{store: store}
+}
+
+func volumeSeqLess(a, b *entity.Volume) bool { return a.SeqNum < b.SeqNum }
+
+// Create 创建卷
+func (r *VolumeRepository) Create(ctx context.Context, volume *entity.Volume) error {
+	if err := r.store.volumes.insert(ctx, volume); err != nil {
+		return fmt.Errorf("failed to create volume: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取卷
+func (r *VolumeRepository) GetByID(ctx context.Context, id string) (*entity.Volume, error) {
+	return r.store.volumes.get(ctx, id), nil
+}
+
+// Update 更新卷
+func (r *VolumeRepository) Update(ctx context.Context, volume *entity.Volume) error {
+	if err := r.store.volumes.save(ctx, volume); err != nil {
+		return fmt.Errorf("failed to update volume: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除卷
+func (r *VolumeRepository) Delete(ctx context.Context, id string) error {
+	r.store.volumes.deleteByID(ctx, id)
+	return nil
+}
+
+// ListByProject 获取项目卷列表（按序号排序）
+func (r *VolumeRepository) ListByProject(ctx context.Context, projectID string) ([]*entity.Volume, error) {
+	return r.store.volumes.find(ctx, func(v *entity.Volume) bool { return v.ProjectID == projectID }, volumeSeqLess), nil
+}
+
+// GetByProjectAndSeq 根据项目和序号获取卷
+func (r *VolumeRepository) GetByProjectAndSeq(ctx context.Context, projectID string, seqNum int) (*entity.Volume, error) {
+	return r.store.volumes.first(ctx, func(v *entity.Volume) bool {
+		return v.ProjectID == projectID && v.SeqNum == seqNum
+	}, nil), nil
+}
+
+// GetByAIKey 根据 AIKey 获取卷
+func (r *VolumeRepository) GetByAIKey(ctx context.Context, projectID, aiKey string) (*entity.Volume, error) {
+	return r.store.volumes.first(ctx, func(v *entity.Volume) bool {
+		return v.ProjectID == projectID && v.AIKey == aiKey
+	}, nil), nil
+}
+
+// UpdateWordCount 更新字数统计
+func (r *VolumeRepository) UpdateWordCount(ctx context.Context, id string, wordCount int) error {
+	r.store.volumes.updateByID(ctx, id, true, func(v *entity.Volume) { v.WordCount = wordCount })
+	return nil
+}
+
+// RefreshRollups 按章节重新汇总项目全部卷
+func (r *VolumeRepository) RefreshRollups(ctx context.Context, projectID string) error {
+	volumes, _ := r.ListByProject(ctx, projectID)
+	for _, v := range volumes {
+		chapters := r.store.chapters.find(ctx, func(c *entity.Chapter) bool { return c.VolumeID == v.ID }, nil)
+		r.store.volumes.updateByID(ctx, v.ID, true, func(v *entity.Volume) { v.ApplyChapterRollup(chapters) })
+	}
+	return nil
+}
+
+// ReorderVolumes 重新排序卷（未包含的卷按原顺序追加到末尾）
+func (r *VolumeRepository) ReorderVolumes(ctx context.Context, projectID string, volumeIDs []string) error {
+	existing, _ := r.ListByProject(ctx, projectID)
+	ids := make([]string, 0, len(existing))
+	for _, v := range existing {
+		ids = append(ids, v.ID)
+	}
+	for i, id := range reorderIDs(volumeIDs, ids) {
+		seq := i + 1
+		r.store.volumes.update(ctx, func(v *entity.Volume) bool {
+			return v.ID == id && v.ProjectID == projectID
+		}, true, func(v *entity.Volume) { v.SeqNum = seq })
+	}
+	return nil
+}
+
+// GetNextSeqNum 获取下一个序号
+func (r *VolumeRepository) GetNextSeqNum(ctx context.Context, projectID string) (int, error) {
+	maxSeq := 0
+	for _, v := range r.store.volumes.find(ctx, func(v *entity.Volume) bool { return v.ProjectID == projectID }, nil) {
+		maxSeq = max(maxSeq, v.SeqNum)
+	}
+	return maxSeq + 1, nil
+}
+
+var _ repository.VolumeRepository = (*VolumeRepository)(nil)
@@ -0,0 +1,32 @@
+// Package wire 提供依赖注入配置
+package wire
+
+import (
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/repository"
+	einocallback "z-novel-ai-api/internal/infrastructure/eino/callback"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
+)
+
+// LLMCallbacks 标记 Eino 全局回调（指标/追踪/日志/用量记账）已注册。
+// LLM 工厂依赖它，保证回调先于任何模型调用注册，各二进制不再各自调用 einocallback.Init
+type LLMCallbacks struct{}
+
+// ProvideLLMCallbacks 注册带用量记账的 Eino 全局回调（需访问数据库的进程使用）
+func ProvideLLMCallbacks(tenantRepo repository.TenantRepository, usageRepo repository.LLMUsageEventRepository, reservationRepo repository.QuotaReservationRepository, tenantCtx *postgres.TenantContext) LLMCallbacks {
+	einocallback.Init(quota.NewLLMUsageRecorder(tenantRepo, usageRepo, reservationRepo), tenantCtx)
+	return LLMCallbacks{}
+}
+
+// ProvideMetricsOnlyLLMCallbacks 注册仅含指标/追踪/日志的 Eino 全局回调（不访问数据库的进程使用，Token 扣费由调用方结算）
+func ProvideMetricsOnlyLLMCallbacks() LLMCallbacks {
+	einocallback.Init(nil, nil)
+	return LLMCallbacks{}
+}
+
+// ProvideEinoFactory 提供 LLM 工厂（依赖 LLMCallbacks，确保回调已注册）
+func ProvideEinoFactory(cfg *config.Config, _ LLMCallbacks) *llm.EinoFactory {
+	return llm.NewEinoFactory(cfg)
+}
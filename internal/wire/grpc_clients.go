@@ -81,3 +81,15 @@ func ProvideValidatorGRPCClient(conn ValidatorGRPCConn) validatorv1.ValidatorSer
 	}
 	return validatorv1.NewValidatorServiceClient((*grpc.ClientConn)(conn))
 }
+
+// ProvideStoryGenStreamerOptional 提供章节流式生成代理（未启用 clients.grpc.proxy_chapter_stream 时返回 nil，SSE 在网关进程内生成）
+func ProvideStoryGenStreamerOptional(ctx context.Context, cfg *config.Config) (*grpcclient.StoryGenStreamer, func(), error) {
+	if !cfg.Clients.GRPC.ProxyChapterStream {
+		return nil, func() {}, nil
+	}
+	conn, cleanup, err := ProvideStoryGenGRPCConn(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grpcclient.NewStoryGenStreamer(ProvideStoryGenGRPCClient(conn)), cleanup, nil
+}
@@ -0,0 +1,46 @@
+// Package wire 提供依赖注入配置
+package wire
+
+import (
+	"context"
+
+	einoembedding "github.com/cloudwego/eino/components/embedding"
+	"github.com/google/wire"
+
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	"z-novel-ai-api/internal/config"
+	infraembedding "z-novel-ai-api/internal/infrastructure/embedding"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	grpcserver "z-novel-ai-api/internal/interfaces/grpc/server"
+	workflowport "z-novel-ai-api/internal/workflow/port"
+)
+
+// MemoryServiceSet Memory gRPC 服务提供者集合（与 RepoSet 组合使用）
+var MemoryServiceSet = wire.NewSet(
+	grpcserver.NewMemoryService,
+)
+
+// RetrievalServiceSet Retrieval gRPC 服务提供者集合（与 MilvusSet 组合使用；Milvus/Embedder 均为必需）
+var RetrievalServiceSet = wire.NewSet(
+	ProvideEmbedder,
+	grpcserver.NewRetrievalService,
+)
+
+// StoryGenServiceSet StoryGen gRPC 服务提供者集合（不访问数据库，Token 扣费由网关按返回的用量结算）
+var StoryGenServiceSet = wire.NewSet(
+	ProvideMetricsOnlyLLMCallbacks,
+	ProvideEinoFactory,
+	wire.Bind(new(workflowport.ChatModelFactory), new(*llm.EinoFactory)),
+	storychapter.NewChapterGenerator,
+	grpcserver.NewStoryGenService,
+)
+
+// ValidatorServiceSet Validator gRPC 服务提供者集合
+var ValidatorServiceSet = wire.NewSet(
+	wire.Struct(new(grpcserver.ValidatorService)),
+)
+
+// ProvideEmbedder 提供 Embedder（不可用时返回错误，用于以向量检索为核心职责的服务）
+func ProvideEmbedder(ctx context.Context, cfg *config.Config) (einoembedding.Embedder, error) {
+	return infraembedding.NewEinoEmbedder(ctx, &cfg.Embedding)
+}
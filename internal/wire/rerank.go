@@ -0,0 +1,29 @@
+package wire
+
+import (
+	"net/http"
+
+	"z-novel-ai-api/internal/application/retrieval"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	"z-novel-ai-api/internal/infrastructure/rerank"
+	"z-novel-ai-api/internal/workflow/chain"
+)
+
+// ProvideRetrievalReranker 检索精排：api 调用外部 rerank 接口，llm 由模型打分；未启用时返回 nil
+func ProvideRetrievalReranker(cfg *config.Config, factory *llm.EinoFactory) retrieval.Reranker {
+	if cfg == nil || !cfg.Vector.Rerank.Enabled {
+		return nil
+	}
+	rr := cfg.Vector.Rerank
+	switch rr.Provider {
+	case "llm":
+		provider := rr.LLMProvider
+		if provider == "" {
+			provider = cfg.LLM.DefaultProvider
+		}
+		return chain.NewRerankChain(factory, provider, rr.Model)
+	default:
+		return rerank.NewAPIReranker(&http.Client{}, rr.Endpoint, rr.APIKey, rr.Model)
+	}
+}
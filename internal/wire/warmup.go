@@ -0,0 +1,67 @@
+// Package wire 提供依赖注入配置
+package wire
+
+import (
+	"context"
+	"errors"
+
+	"z-novel-ai-api/internal/application/retrieval"
+	storyartifact "z-novel-ai-api/internal/application/story/artifact"
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	storyprojectcreation "z-novel-ai-api/internal/application/story/projectcreation"
+	"z-novel-ai-api/internal/application/warmup"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/llm"
+)
+
+// ProvideGatewayWarmer 网关预热：对话构件、设定集、项目创建与章节工作流
+func ProvideGatewayWarmer(
+	cfg *config.Config,
+	factory *llm.EinoFactory,
+	chapterGen *storychapter.ChapterGenerator,
+	foundationGen *storyfoundation.FoundationGenerator,
+	artifactGen *storyartifact.ArtifactGenerator,
+	creationGen *storyprojectcreation.ProjectCreationGenerator,
+	engine *retrieval.Engine,
+) *warmup.Warmer {
+	return newWarmer(cfg, factory, engine, chapterGen.Warmup, foundationGen.Warmup, artifactGen.Warmup, creationGen.Warmup)
+}
+
+// ProvideWorkerWarmer job-worker 预热：章节与设定集工作流
+func ProvideWorkerWarmer(
+	cfg *config.Config,
+	factory *llm.EinoFactory,
+	chapterGen *storychapter.ChapterGenerator,
+	foundationGen *storyfoundation.FoundationGenerator,
+	engine *retrieval.Engine,
+) *warmup.Warmer {
+	return newWarmer(cfg, factory, engine, chapterGen.Warmup, foundationGen.Warmup)
+}
+
+// newWarmer 组装预热步骤：工作流编译、模型客户端、向量集合加载、嵌入服务（向量检索未启用时后两步跳过）
+func newWarmer(cfg *config.Config, factory *llm.EinoFactory, engine *retrieval.Engine, graphs ...func(context.Context) error) *warmup.Warmer {
+	skipDisabled := func(run func(context.Context) error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			err := run(ctx)
+			if errors.Is(err, retrieval.ErrVectorDisabled) {
+				return warmup.ErrSkipped
+			}
+			return err
+		}
+	}
+	return warmup.NewWarmer(cfg.Warmup.Timeout,
+		warmup.Step{Name: "graphs", Run: func(ctx context.Context) error {
+			errs := make([]error, 0, len(graphs))
+			for _, compile := range graphs {
+				errs = append(errs, compile(ctx))
+			}
+			return errors.Join(errs...)
+		}},
+		warmup.Step{Name: "providers", Run: func(ctx context.Context) error {
+			return factory.Warmup(ctx, cfg.Warmup.PingProviders)
+		}},
+		warmup.Step{Name: "vector_collection", Run: skipDisabled(engine.WarmupCollection)},
+		warmup.Step{Name: "embedder", Run: skipDisabled(engine.WarmupEmbedder)},
+	)
+}
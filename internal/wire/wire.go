@@ -6,28 +6,49 @@ package wire
 
 import (
 	"context"
+	"time"
+	"z-novel-ai-api/internal/application/featureflag"
+	"z-novel-ai-api/internal/application/ops"
 
 	einoembedding "github.com/cloudwego/eino/components/embedding"
 	"github.com/google/wire"
 
+	"z-novel-ai-api/internal/application/billing"
+	"z-novel-ai-api/internal/application/confirm"
+	"z-novel-ai-api/internal/application/provenance"
 	"z-novel-ai-api/internal/application/quota"
 	"z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
 	storyartifact "z-novel-ai-api/internal/application/story/artifact"
 	storychapter "z-novel-ai-api/internal/application/story/chapter"
 	storyctx "z-novel-ai-api/internal/application/story/context"
+	"z-novel-ai-api/internal/application/story/duplicate"
 	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	storyhealth "z-novel-ai-api/internal/application/story/health"
+	storynotes "z-novel-ai-api/internal/application/story/notes"
+	storyoutline "z-novel-ai-api/internal/application/story/outline"
 	storyprojectcreation "z-novel-ai-api/internal/application/story/projectcreation"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	storyspoiler "z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/application/story/timeline"
+	storytranscript "z-novel-ai-api/internal/application/story/transcript"
 	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	infraembedding "z-novel-ai-api/internal/infrastructure/embedding"
 	"z-novel-ai-api/internal/infrastructure/llm"
 	"z-novel-ai-api/internal/infrastructure/messaging"
+	"z-novel-ai-api/internal/infrastructure/objectstore"
+	"z-novel-ai-api/internal/infrastructure/payment"
 	"z-novel-ai-api/internal/infrastructure/persistence/milvus"
 	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
 	"z-novel-ai-api/internal/infrastructure/persistence/redis"
+	"z-novel-ai-api/internal/infrastructure/webhook"
+	grpcserver "z-novel-ai-api/internal/interfaces/grpc/server"
 	"z-novel-ai-api/internal/interfaces/http/handler"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 	"z-novel-ai-api/internal/interfaces/http/router"
+	workflowport "z-novel-ai-api/internal/workflow/port"
 	"z-novel-ai-api/pkg/logger"
 )
 
@@ -52,6 +73,7 @@ type DataLayer struct {
 	ArtifactRepo  *postgres.ArtifactRepository
 	PCSessionRepo *postgres.ProjectCreationSessionRepository
 	PCTurnRepo    *postgres.ProjectCreationTurnRepository
+	SeriesRepo    *postgres.SeriesRepository
 
 	// Redis
 	RedisClient *redis.Client
@@ -86,6 +108,7 @@ type PostgresOnlyDataLayer struct {
 	ArtifactRepo  *postgres.ArtifactRepository
 	PCSessionRepo *postgres.ProjectCreationSessionRepository
 	PCTurnRepo    *postgres.ProjectCreationTurnRepository
+	SeriesRepo    *postgres.SeriesRepository
 }
 
 // InitializeDataLayer 初始化数据层
@@ -123,6 +146,53 @@ func InitializeApp(ctx context.Context, cfg *config.Config) (*router.Router, fun
 	return nil, nil, nil
 }
 
+// InitializeWorker 初始化 job-worker 依赖（Milvus/Embedding 不可达时降级，向量索引与 RAG 召回不可用）
+func InitializeWorker(ctx context.Context, cfg *config.Config) (*Worker, func(), error) {
+	wire.Build(
+		RepoSet,
+		RedisSet,
+		EmbeddingSet,
+		MilvusAppSet,
+		RetrievalSet,
+		WorkerSet,
+	)
+	return nil, nil, nil
+}
+
+// InitializeMemoryService 初始化 Memory gRPC 服务
+func InitializeMemoryService(ctx context.Context, cfg *config.Config) (*grpcserver.MemoryService, func(), error) {
+	wire.Build(
+		RepoSet,
+		MemoryServiceSet,
+	)
+	return nil, nil, nil
+}
+
+// InitializeRetrievalService 初始化 Retrieval gRPC 服务
+func InitializeRetrievalService(ctx context.Context, cfg *config.Config) (*grpcserver.RetrievalService, func(), error) {
+	wire.Build(
+		MilvusSet,
+		RetrievalServiceSet,
+	)
+	return nil, nil, nil
+}
+
+// InitializeStoryGenService 初始化 StoryGen gRPC 服务
+func InitializeStoryGenService(ctx context.Context, cfg *config.Config) (*grpcserver.StoryGenService, func(), error) {
+	wire.Build(
+		StoryGenServiceSet,
+	)
+	return nil, nil, nil
+}
+
+// InitializeValidatorService 初始化 Validator gRPC 服务
+func InitializeValidatorService(ctx context.Context, cfg *config.Config) (*grpcserver.ValidatorService, func(), error) {
+	wire.Build(
+		ValidatorServiceSet,
+	)
+	return nil, nil, nil
+}
+
 // PostgresSet PostgreSQL 提供者集合
 var PostgresSet = wire.NewSet(
 	ProvidePostgresClient,
@@ -143,6 +213,16 @@ var PostgresSet = wire.NewSet(
 	postgres.NewArtifactRepository,
 	postgres.NewProjectCreationSessionRepository,
 	postgres.NewProjectCreationTurnRepository,
+	postgres.NewSeriesRepository,
+	postgres.NewProjectLocker,
+	postgres.NewJobEventRepository,
+	postgres.NewQuotaReservationRepository,
+	postgres.NewPlanRepository,
+	postgres.NewInvoiceRepository,
+	postgres.NewSpoilerGuardRepository,
+	postgres.NewProjectNoteRepository,
+	postgres.NewFeatureFlagRepository,
+	postgres.NewGenerationCandidateRepository,
 )
 
 // RedisSet Redis 提供者集合
@@ -151,6 +231,15 @@ var RedisSet = wire.NewSet(
 	redis.NewCache,
 	redis.NewRateLimiter,
 	wire.Bind(new(storyctx.KVCache), new(*redis.Cache)),
+	wire.Bind(new(featureflag.Cache), new(*redis.Cache)),
+	wire.Bind(new(ops.Store), new(*redis.Cache)),
+	wire.Bind(new(confirm.Store), new(*redis.Cache)),
+	redis.NewVectorUsageCounter,
+	wire.Bind(new(retrieval.UsageCounter), new(*redis.VectorUsageCounter)),
+	redis.NewJobProgressBus,
+	wire.Bind(new(appstory.JobProgressBus), new(*redis.JobProgressBus)),
+	redis.NewJobCancelSignal,
+	wire.Bind(new(appstory.JobCancelSignal), new(*redis.JobCancelSignal)),
 	wire.Bind(new(middleware.RateLimiter), new(*redis.RateLimiter)),
 )
 
@@ -162,7 +251,7 @@ var MessagingSet = wire.NewSet(
 // MilvusSet Milvus 提供者集合
 var MilvusSet = wire.NewSet(
 	ProvideMilvusClient,
-	milvus.NewRepository,
+	ProvideMilvusRepository,
 )
 
 // MilvusAppSet API 网关可选 Milvus（不可达时不阻塞启动）
@@ -180,6 +269,7 @@ var EmbeddingSet = wire.NewSet(
 // RetrievalSet 本地检索引擎（HTTP + 生成侧共用）
 var RetrievalSet = wire.NewSet(
 	ProvideRetrievalEngine,
+	ProvideRetrievalReranker,
 	ProvideRetrievalIndexer,
 )
 
@@ -199,13 +289,49 @@ var GRPCClientsSet = wire.NewSet(
 var RouterSet = wire.NewSet(
 	ProvideAuthConfig,
 	llm.NewEinoFactory,
+	wire.Bind(new(workflowport.ChatModelFactory), new(*llm.EinoFactory)),
 	storychapter.NewChapterGenerator,
 	storyfoundation.NewFoundationGenerator,
 	storyartifact.NewArtifactGenerator,
 	quota.NewTokenQuotaChecker,
+	quota.NewPlanService,
+	quota.NewUsageQuery,
+	wire.Bind(new(middleware.PlanRateLimitResolver), new(*quota.PlanService)),
 	storyfoundation.NewFoundationApplier,
+	wire.Bind(new(storyfoundation.NarrativePositionSyncer), new(*appstory.ChapterNumbering)),
+	ProvideStoryTimeValidator,
+	ProvideRelationWeigher,
+	ProvideDuplicateDetector,
+	ProvideChapterTitleService,
 	storyprojectcreation.NewProjectCreationGenerator,
 	storyctx.NewRollingContextManager,
+	appstory.NewJobTimeline,
+	appstory.NewGenerationFinalizer,
+	appstory.NewChapterNumbering,
+	ProvideChapterReindexer,
+	ProvideGenerationConsistency,
+	ProvideFoundationPlanLimits,
+	ProvideArtifactActivationValidator,
+	appstory.NewContextPinService,
+	appstory.NewCanonContextService,
+	appstory.NewChapterEventReplacer,
+	storyspoiler.NewService,
+	storyhealth.NewService,
+	storyoutline.NewStaleTracker,
+	storynotes.NewIngestor,
+	storytranscript.NewExporter,
+	featureflag.NewService,
+	ops.NewService,
+	wire.Bind(new(middleware.OpsSwitchResolver), new(*ops.Service)),
+	confirm.NewService,
+	wire.Bind(new(middleware.ConfirmationVerifier), new(*confirm.Service)),
+	wire.Bind(new(featureflag.Client), new(*featureflag.Service)),
+	storyseries.NewSeriesService,
+	ProvidePaymentProviderOptional,
+	ProvideBillingService,
+	ProvideWatermarker,
+	ProvideObjectStoreOptional,
+	ProvideStoryGenStreamerOptional,
 	handler.NewAuthHandler,
 	handler.NewHealthHandler,
 	handler.NewProjectHandler,
@@ -223,6 +349,19 @@ var RouterSet = wire.NewSet(
 	handler.NewTenantHandler,
 	handler.NewEventHandler,
 	handler.NewRelationHandler,
+	handler.NewSeriesHandler,
+	handler.NewPublicHandler,
+	handler.NewBillingHandler,
+	handler.NewManuscriptHandler,
+	handler.NewSpoilerGuardHandler,
+	handler.NewNotesHandler,
+	handler.NewFeatureFlagHandler,
+	handler.NewOpsHandler,
+	handler.NewCandidateHandler,
+	handler.NewConfirmationHandler,
+	handler.NewDiagnosticsHandler,
+	handler.NewWebSocketHandler,
+	ProvideGatewayWarmer,
 	wire.Struct(new(router.RouterHandlers), "*"),
 	router.NewWithDeps,
 )
@@ -248,6 +387,16 @@ var RepoSet = wire.NewSet(
 	wire.Bind(new(repository.ArtifactRepository), new(*postgres.ArtifactRepository)),
 	wire.Bind(new(repository.ProjectCreationSessionRepository), new(*postgres.ProjectCreationSessionRepository)),
 	wire.Bind(new(repository.ProjectCreationTurnRepository), new(*postgres.ProjectCreationTurnRepository)),
+	wire.Bind(new(repository.SeriesRepository), new(*postgres.SeriesRepository)),
+	wire.Bind(new(repository.ProjectLocker), new(*postgres.ProjectLocker)),
+	wire.Bind(new(repository.JobEventRepository), new(*postgres.JobEventRepository)),
+	wire.Bind(new(repository.QuotaReservationRepository), new(*postgres.QuotaReservationRepository)),
+	wire.Bind(new(repository.PlanRepository), new(*postgres.PlanRepository)),
+	wire.Bind(new(repository.InvoiceRepository), new(*postgres.InvoiceRepository)),
+	wire.Bind(new(repository.SpoilerGuardRepository), new(*postgres.SpoilerGuardRepository)),
+	wire.Bind(new(repository.ProjectNoteRepository), new(*postgres.ProjectNoteRepository)),
+	wire.Bind(new(repository.FeatureFlagRepository), new(*postgres.FeatureFlagRepository)),
+	wire.Bind(new(repository.GenerationCandidateRepository), new(*postgres.GenerationCandidateRepository)),
 )
 
 // ProvidePostgresClient 提供 PostgreSQL 客户端
@@ -307,11 +456,20 @@ func ProvideMilvusClientOptional(ctx context.Context, cfg *config.Config) (*milv
 	return client, cleanup, nil
 }
 
-func ProvideMilvusRepositoryOptional(client *milvus.Client) *milvus.Repository {
+// ProvideMilvusRepository 提供 Milvus 仓储，并探测已存在集合的可选字段（narrative_pos 等）
+func ProvideMilvusRepository(ctx context.Context, client *milvus.Client) *milvus.Repository {
+	repo := milvus.NewRepository(client)
+	if err := repo.DetectSchema(ctx); err != nil {
+		logger.Warn(ctx, "failed to detect milvus collection schema", "error", err.Error())
+	}
+	return repo
+}
+
+func ProvideMilvusRepositoryOptional(ctx context.Context, client *milvus.Client) *milvus.Repository {
 	if client == nil {
 		return nil
 	}
-	return milvus.NewRepository(client)
+	return ProvideMilvusRepository(ctx, client)
 }
 
 func ProvideRetrievalVectorRepositoryOptional(repo *milvus.Repository) retrieval.VectorRepository {
@@ -330,20 +488,107 @@ func ProvideEmbedderOptional(ctx context.Context, cfg *config.Config) (einoembed
 	return embedder, nil
 }
 
-func ProvideRetrievalEngine(cfg *config.Config, embedder einoembedding.Embedder, vectorRepo retrieval.VectorRepository, entityRepo repository.EntityRepository) *retrieval.Engine {
+func ProvideRetrievalEngine(cfg *config.Config, embedder einoembedding.Embedder, vectorRepo retrieval.VectorRepository, entityRepo repository.EntityRepository, reranker retrieval.Reranker, relations *appstory.RelationWeigher) *retrieval.Engine {
 	bs := 0
 	if cfg != nil {
 		bs = cfg.Embedding.BatchSize
 	}
-	return retrieval.NewEngine(embedder, vectorRepo, entityRepo, bs)
+	engine := retrieval.NewEngine(embedder, vectorRepo, entityRepo, bs)
+	if reranker != nil {
+		engine.EnableRerank(reranker, cfg.Vector.Rerank.Timeout)
+	}
+	if relations != nil {
+		engine.EnableRelationWeighting(relations)
+	}
+	return engine
 }
 
-func ProvideRetrievalIndexer(cfg *config.Config, embedder einoembedding.Embedder, vectorRepo retrieval.VectorRepository) *retrieval.Indexer {
+func ProvideRetrievalIndexer(cfg *config.Config, embedder einoembedding.Embedder, vectorRepo retrieval.VectorRepository, usage retrieval.UsageCounter) *retrieval.Indexer {
 	bs := 0
 	if cfg != nil {
 		bs = cfg.Embedding.BatchSize
 	}
-	return retrieval.NewIndexer(embedder, vectorRepo, bs)
+	indexer := retrieval.NewIndexer(embedder, vectorRepo, bs)
+	if cfg != nil && usage != nil {
+		indexer.EnableUsageQuota(usage, retrieval.QuotaLimits{
+			MaxSegmentsPerTenant:  cfg.Vector.Quota.MaxSegmentsPerTenant,
+			MaxSegmentsPerProject: cfg.Vector.Quota.MaxSegmentsPerProject,
+		}, cfg.Embedding.Dimension)
+	}
+	return indexer
+}
+
+// ProvideStoryTimeValidator 提供章节故事时间单调性校验器
+func ProvideStoryTimeValidator(cfg *config.Config, chapterRepo repository.ChapterRepository) *timeline.StoryTimeValidator {
+	mode := timeline.CheckModeWarn
+	if cfg != nil {
+		mode = timeline.ParseCheckMode(cfg.Story.StoryTimeCheck)
+	}
+	return timeline.NewStoryTimeValidator(chapterRepo, mode)
+}
+
+// ProvideRelationWeigher 提供关系时效加权器
+func ProvideRelationWeigher(cfg *config.Config, chapterRepo repository.ChapterRepository, relationRepo repository.RelationRepository) *appstory.RelationWeigher {
+	halfLife := 0
+	if cfg != nil {
+		halfLife = cfg.Story.RelationHalfLifeChapters
+	}
+	return appstory.NewRelationWeigher(chapterRepo, relationRepo, halfLife)
+}
+
+// ProvideDuplicateDetector 提供章节重复内容检测器
+func ProvideDuplicateDetector(cfg *config.Config, chapterRepo repository.ChapterRepository) *duplicate.Detector {
+	threshold := 0.0
+	if cfg != nil {
+		threshold = cfg.Story.DuplicateSimilarityThreshold
+	}
+	return duplicate.NewDetector(chapterRepo, threshold)
+}
+
+// ProvideChapterTitleService 提供章节标题建议服务
+func ProvideChapterTitleService(cfg *config.Config, generator *storychapter.ChapterGenerator, chapterRepo repository.ChapterRepository, projectRepo repository.ProjectRepository, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *appstory.ChapterTitleService {
+	auto, timeout := false, time.Duration(0)
+	if cfg != nil {
+		auto = cfg.Story.AutoTitleSuggestions
+		timeout = cfg.Story.GenerationTimeouts.TitleSuggest
+	}
+	return appstory.NewChapterTitleService(generator, chapterRepo, projectRepo, txMgr, tenantCtx, auto, timeout)
+}
+
+// ProvideChapterReindexer 提供编辑后自动重建索引服务（未开启时不排队）
+func ProvideChapterReindexer(cfg *config.Config, redisClient *redis.Client, chapterRepo repository.ChapterRepository, finalizer *appstory.GenerationFinalizer, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *appstory.ChapterReindexer {
+	var rc config.ReindexConfig
+	if cfg != nil {
+		rc = cfg.Story.Reindex
+	}
+	var queue appstory.ReindexQueue
+	if rc.Enabled && redisClient != nil {
+		queue = redis.NewReindexQueue(redisClient)
+	}
+	return appstory.NewChapterReindexer(queue, chapterRepo, finalizer, txMgr, tenantCtx, rc.Debounce, rc.MaxDelay)
+}
+
+// ProvideGenerationConsistency 提供任务/章节状态巡检服务（运维接口按需触发，Worker 周期执行）
+func ProvideGenerationConsistency(cfg *config.Config, chapterRepo repository.ChapterRepository, jobRepo repository.JobRepository, tenantRepo repository.TenantRepository, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *appstory.GenerationConsistency {
+	var grace time.Duration
+	if cfg != nil {
+		grace = cfg.Story.GenerationConsistency.Grace
+	}
+	return appstory.NewGenerationConsistency(chapterRepo, jobRepo, tenantRepo, txMgr, tenantCtx, grace)
+}
+
+// ProvideFoundationPlanLimits 提供设定集规模上限（全局配置叠加租户覆盖）
+func ProvideFoundationPlanLimits(cfg *config.Config, tenantRepo repository.TenantRepository) *storyfoundation.PlanLimits {
+	var defaults entity.FoundationPlanLimits
+	if cfg != nil {
+		defaults = entity.FoundationPlanLimits(cfg.Story.FoundationPlanLimits)
+	}
+	return storyfoundation.NewPlanLimits(defaults, tenantRepo)
+}
+
+// ProvideArtifactActivationValidator 提供构件激活前的租户校验 Webhook（按租户设置调用，未设置时放行）
+func ProvideArtifactActivationValidator(tenantRepo repository.TenantRepository) *storyartifact.ActivationValidator {
+	return storyartifact.NewActivationValidator(tenantRepo, webhook.NewArtifactValidationCaller(nil))
 }
 
 // ProvideAuthConfig 提供认证配置
@@ -355,3 +600,43 @@ func ProvideAuthConfig(cfg *config.Config) middleware.AuthConfig {
 		Enabled:   true,
 	}
 }
+
+// ProvidePaymentProviderOptional 提供支付提供商适配（未启用计费或配置无效时返回 nil，Webhook 不可用）
+func ProvidePaymentProviderOptional(ctx context.Context, cfg *config.Config) billing.PaymentProvider {
+	if !cfg.Billing.Enabled {
+		return nil
+	}
+	provider, err := payment.NewProvider(&cfg.Billing)
+	if err != nil {
+		logger.Warn(ctx, "payment provider not available, billing webhooks disabled", "error", err.Error())
+		return nil
+	}
+	return provider
+}
+
+// ProvideBillingService 提供计费服务
+func ProvideBillingService(cfg *config.Config, provider billing.PaymentProvider, tenantRepo repository.TenantRepository, invoiceRepo repository.InvoiceRepository) *billing.Service {
+	return billing.NewService(provider, tenantRepo, invoiceRepo, cfg.Billing.TokensPerMinorUnit)
+}
+
+// ProvideWatermarker 提供成稿来源标记水印器（未启用或未配置密钥时返回 nil）
+func ProvideWatermarker(ctx context.Context, cfg *config.Config) *provenance.Watermarker {
+	if !cfg.Provenance.Enabled {
+		return nil
+	}
+	w := provenance.NewWatermarker(cfg.Provenance.Secret, provenance.ParseMode(cfg.Provenance.Mode))
+	if w == nil {
+		logger.Warn(ctx, "provenance secret not configured, manuscript watermarking disabled")
+	}
+	return w
+}
+
+// ProvideObjectStoreOptional 提供对象存储（配置无效时返回 nil，依赖存储的功能不可用）
+func ProvideObjectStoreOptional(ctx context.Context, cfg *config.Config) objectstore.Store {
+	store, err := objectstore.NewStore(&cfg.Storage)
+	if err != nil {
+		logger.Warn(ctx, "object store not available, blob storage disabled", "error", err.Error())
+		return nil
+	}
+	return store
+}
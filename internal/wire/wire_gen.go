@@ -8,28 +8,49 @@ package wire
 
 import (
 	"context"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/google/wire"
+	"time"
+	"z-novel-ai-api/internal/application/billing"
+	"z-novel-ai-api/internal/application/confirm"
+	"z-novel-ai-api/internal/application/featureflag"
+	"z-novel-ai-api/internal/application/maintenance"
+	"z-novel-ai-api/internal/application/ops"
+	"z-novel-ai-api/internal/application/provenance"
 	"z-novel-ai-api/internal/application/quota"
 	"z-novel-ai-api/internal/application/retrieval"
-	storyartifact "z-novel-ai-api/internal/application/story/artifact"
-	storychapter "z-novel-ai-api/internal/application/story/chapter"
-	storyctx "z-novel-ai-api/internal/application/story/context"
-	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
-	storyprojectcreation "z-novel-ai-api/internal/application/story/projectcreation"
+	"z-novel-ai-api/internal/application/story"
+	"z-novel-ai-api/internal/application/story/artifact"
+	"z-novel-ai-api/internal/application/story/chapter"
+	context2 "z-novel-ai-api/internal/application/story/context"
+	"z-novel-ai-api/internal/application/story/duplicate"
+	"z-novel-ai-api/internal/application/story/foundation"
+	"z-novel-ai-api/internal/application/story/health"
+	"z-novel-ai-api/internal/application/story/notes"
+	"z-novel-ai-api/internal/application/story/outline"
+	"z-novel-ai-api/internal/application/story/projectcreation"
+	"z-novel-ai-api/internal/application/story/series"
+	"z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/application/story/timeline"
+	"z-novel-ai-api/internal/application/story/transcript"
 	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/domain/entity"
 	"z-novel-ai-api/internal/domain/repository"
 	embedding2 "z-novel-ai-api/internal/infrastructure/embedding"
 	"z-novel-ai-api/internal/infrastructure/llm"
 	"z-novel-ai-api/internal/infrastructure/messaging"
+	"z-novel-ai-api/internal/infrastructure/objectstore"
+	"z-novel-ai-api/internal/infrastructure/payment"
 	"z-novel-ai-api/internal/infrastructure/persistence/milvus"
 	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
 	"z-novel-ai-api/internal/infrastructure/persistence/redis"
+	"z-novel-ai-api/internal/infrastructure/webhook"
+	"z-novel-ai-api/internal/interfaces/grpc/server"
 	"z-novel-ai-api/internal/interfaces/http/handler"
 	"z-novel-ai-api/internal/interfaces/http/middleware"
 	"z-novel-ai-api/internal/interfaces/http/router"
+	"z-novel-ai-api/internal/workflow/port"
 	"z-novel-ai-api/pkg/logger"
-
-	"github.com/cloudwego/eino/components/embedding"
-	"github.com/google/wire"
 )
 
 // Injectors from wire.go:
@@ -57,6 +78,7 @@ func InitializeDataLayer(ctx context.Context, cfg *config.Config) (*DataLayer, f
 	artifactRepository := postgres.NewArtifactRepository(client)
 	projectCreationSessionRepository := postgres.NewProjectCreationSessionRepository(client)
 	projectCreationTurnRepository := postgres.NewProjectCreationTurnRepository(client)
+	seriesRepository := postgres.NewSeriesRepository(client)
 	redisClient, cleanup2, err := ProvideRedisClient(cfg)
 	if err != nil {
 		cleanup()
@@ -71,7 +93,7 @@ func InitializeDataLayer(ctx context.Context, cfg *config.Config) (*DataLayer, f
 		cleanup()
 		return nil, nil, err
 	}
-	repository := milvus.NewRepository(milvusClient)
+	repository := ProvideMilvusRepository(ctx, milvusClient)
 	dataLayer := &DataLayer{
 		PgClient:      client,
 		TxManager:     txManager,
@@ -91,6 +113,7 @@ func InitializeDataLayer(ctx context.Context, cfg *config.Config) (*DataLayer, f
 		ArtifactRepo:  artifactRepository,
 		PCSessionRepo: projectCreationSessionRepository,
 		PCTurnRepo:    projectCreationTurnRepository,
+		SeriesRepo:    seriesRepository,
 		RedisClient:   redisClient,
 		Cache:         cache,
 		RateLimiter:   rateLimiter,
@@ -128,6 +151,7 @@ func InitializePostgresOnly(ctx context.Context, cfg *config.Config) (*PostgresO
 	artifactRepository := postgres.NewArtifactRepository(client)
 	projectCreationSessionRepository := postgres.NewProjectCreationSessionRepository(client)
 	projectCreationTurnRepository := postgres.NewProjectCreationTurnRepository(client)
+	seriesRepository := postgres.NewSeriesRepository(client)
 	postgresOnlyDataLayer := &PostgresOnlyDataLayer{
 		PgClient:      client,
 		TxManager:     txManager,
@@ -147,6 +171,7 @@ func InitializePostgresOnly(ctx context.Context, cfg *config.Config) (*PostgresO
 		ArtifactRepo:  artifactRepository,
 		PCSessionRepo: projectCreationSessionRepository,
 		PCTurnRepo:    projectCreationTurnRepository,
+		SeriesRepo:    seriesRepository,
 	}
 	return postgresOnlyDataLayer, func() {
 		cleanup()
@@ -163,34 +188,227 @@ func InitializeApp(ctx context.Context, cfg *config.Config) (*router.Router, fun
 	userRepository := postgres.NewUserRepository(client)
 	tenantRepository := postgres.NewTenantRepository(client)
 	authHandler := handler.NewAuthHandler(authConfig, userRepository, tenantRepository)
+	redisClient, cleanup2, err := ProvideRedisClient(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	milvusClient, cleanup3, err := ProvideMilvusClientOptional(ctx, cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	healthHandler := handler.NewHealthHandler(client, redisClient, milvusClient)
 	projectRepository := postgres.NewProjectRepository(client)
-	projectHandler := handler.NewProjectHandler(projectRepository)
-	volumeRepository := postgres.NewVolumeRepository(client)
-	volumeHandler := handler.NewVolumeHandler(volumeRepository)
 	chapterRepository := postgres.NewChapterRepository(client)
+	artifactRepository := postgres.NewArtifactRepository(client)
+	conversationTurnRepository := postgres.NewConversationTurnRepository(client)
 	jobRepository := postgres.NewJobRepository(client)
-	redisClient, cleanup2, err := ProvideRedisClient(cfg)
+	planRepository := postgres.NewPlanRepository(client)
+	planService := quota.NewPlanService(tenantRepository, planRepository)
+	service := health.NewService(chapterRepository, artifactRepository, conversationTurnRepository, jobRepository, planService)
+	projectHandler := handler.NewProjectHandler(cfg, projectRepository, tenantRepository, service)
+	volumeRepository := postgres.NewVolumeRepository(client)
+	projectLocker := postgres.NewProjectLocker(client)
+	embedder, err := ProvideEmbedderOptional(ctx, cfg)
 	if err != nil {
+		cleanup3()
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
+	repository := ProvideMilvusRepositoryOptional(ctx, milvusClient)
+	vectorRepository := ProvideRetrievalVectorRepositoryOptional(repository)
+	vectorUsageCounter := redis.NewVectorUsageCounter(redisClient)
+	indexer := ProvideRetrievalIndexer(cfg, embedder, vectorRepository, vectorUsageCounter)
+	chapterNumbering := story.NewChapterNumbering(chapterRepository, volumeRepository, projectLocker, indexer)
+	volumeHandler := handler.NewVolumeHandler(volumeRepository, projectLocker, chapterNumbering)
 	producer := ProvideMessagingProducer(redisClient, cfg)
-	tokenQuotaChecker := quota.NewTokenQuotaChecker(tenantRepository)
-	chapterHandler := handler.NewChapterHandler(cfg, chapterRepository, projectRepository, jobRepository, producer, tokenQuotaChecker)
-	entityRepository := postgres.NewEntityRepository(client)
-	relationRepository := postgres.NewRelationRepository(client)
-	entityHandler := handler.NewEntityHandler(entityRepository, relationRepository)
+	quotaReservationRepository := postgres.NewQuotaReservationRepository(client)
+	tokenQuotaChecker := quota.NewTokenQuotaChecker(tenantRepository, quotaReservationRepository, planRepository)
+	storyTimeValidator := ProvideStoryTimeValidator(cfg, chapterRepository)
+	jobEventRepository := postgres.NewJobEventRepository(client)
+	jobTimeline := story.NewJobTimeline(jobEventRepository)
 	txManager := postgres.NewTxManager(client)
 	tenantContext := postgres.NewTenantContext(client)
 	einoFactory := llm.NewEinoFactory(cfg)
-	foundationGenerator := storyfoundation.NewFoundationGenerator(einoFactory)
-	foundationApplier := storyfoundation.NewFoundationApplier(projectRepository, entityRepository, relationRepository, volumeRepository, chapterRepository)
-	foundationHandler := handler.NewFoundationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, jobRepository, producer, tokenQuotaChecker, foundationGenerator, foundationApplier)
+	chapterGenerator := chapter.NewChapterGenerator(einoFactory)
+	entityRepository := postgres.NewEntityRepository(client)
+	reranker := ProvideRetrievalReranker(cfg, einoFactory)
+	relationRepository := postgres.NewRelationRepository(client)
+	relationWeigher := ProvideRelationWeigher(cfg, chapterRepository, relationRepository)
+	engine := ProvideRetrievalEngine(cfg, embedder, vectorRepository, entityRepository, reranker, relationWeigher)
+	seriesRepository := postgres.NewSeriesRepository(client)
+	seriesService := series.NewSeriesService(seriesRepository, projectRepository, artifactRepository)
+	contextPinService := story.NewContextPinService(chapterRepository, entityRepository)
+	chapterTitleService := ProvideChapterTitleService(cfg, chapterGenerator, chapterRepository, projectRepository, txManager, tenantContext)
+	detector := ProvideDuplicateDetector(cfg, chapterRepository)
+	eventRepository := postgres.NewEventRepository(client)
+	generationCandidateRepository := postgres.NewGenerationCandidateRepository(client)
+	chapterEventReplacer := story.NewChapterEventReplacer(eventRepository, chapterRepository, indexer, relationWeigher)
+	generationFinalizer := story.NewGenerationFinalizer(chapterRepository, projectRepository, jobRepository, eventRepository, indexer, jobTimeline, tokenQuotaChecker, generationCandidateRepository, detector, chapterEventReplacer)
+	chapterReindexer := ProvideChapterReindexer(cfg, redisClient, chapterRepository, generationFinalizer, txManager, tenantContext)
+	chapterHandler := handler.NewChapterHandler(cfg, chapterRepository, projectRepository, jobRepository, producer, tokenQuotaChecker, storyTimeValidator, jobTimeline, txManager, tenantContext, chapterGenerator, engine, seriesService, contextPinService, chapterTitleService, detector, artifactRepository, chapterReindexer, chapterNumbering)
+	entityHandler := handler.NewEntityHandler(entityRepository, relationRepository, relationWeigher)
+	foundationGenerator := foundation.NewFoundationGenerator(einoFactory)
+	foundationApplier := foundation.NewFoundationApplier(projectRepository, entityRepository, relationRepository, volumeRepository, chapterRepository, storyTimeValidator, projectLocker, chapterNumbering)
+	planLimits := ProvideFoundationPlanLimits(cfg, tenantRepository)
+	foundationHandler := handler.NewFoundationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, jobRepository, producer, tokenQuotaChecker, foundationGenerator, foundationApplier, planLimits, jobTimeline)
 	conversationSessionRepository := postgres.NewConversationSessionRepository(client)
-	conversationTurnRepository := postgres.NewConversationTurnRepository(client)
-	artifactRepository := postgres.NewArtifactRepository(client)
 	cache := redis.NewCache(redisClient)
-	rollingContextManager := storyctx.NewRollingContextManager(cache)
+	rollingContextManager := context2.NewRollingContextManager(cache)
+	featureFlagRepository := postgres.NewFeatureFlagRepository(client)
+	featureflagService := featureflag.NewService(featureFlagRepository, cache, txManager, tenantContext)
+	artifactGenerator := artifact.NewArtifactGenerator(einoFactory, engine, featureflagService)
+	exporter := transcript.NewExporter(conversationTurnRepository, artifactRepository)
+	activationValidator := ProvideArtifactActivationValidator(tenantRepository)
+	staleTracker := outline.NewStaleTracker(artifactRepository, chapterRepository, projectRepository, jobRepository, jobTimeline)
+	jobCancelSignal := redis.NewJobCancelSignal(redisClient)
+	jobProgressBus := redis.NewJobProgressBus(redisClient)
+	conversationHandler := handler.NewConversationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, jobRepository, conversationSessionRepository, conversationTurnRepository, artifactRepository, rollingContextManager, tokenQuotaChecker, artifactGenerator, indexer, seriesService, jobTimeline, featureflagService, exporter, generationCandidateRepository, activationValidator, staleTracker, producer, jobCancelSignal, jobProgressBus)
+	projectCreationSessionRepository := postgres.NewProjectCreationSessionRepository(client)
+	projectCreationTurnRepository := postgres.NewProjectCreationTurnRepository(client)
+	llmUsageEventRepository := postgres.NewLLMUsageEventRepository(client)
+	projectCreationGenerator := projectcreation.NewProjectCreationGenerator(einoFactory)
+	projectCreationHandler := handler.NewProjectCreationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, conversationSessionRepository, projectCreationSessionRepository, projectCreationTurnRepository, jobRepository, llmUsageEventRepository, tokenQuotaChecker, projectCreationGenerator)
+	artifactHandler := handler.NewArtifactHandler(artifactRepository, indexer, activationValidator, foundationApplier, planLimits, staleTracker, producer)
+	jobHandler := handler.NewJobHandler(cfg, jobRepository, jobEventRepository, projectRepository, producer, tokenQuotaChecker, jobTimeline, chapterGenerator, jobCancelSignal)
+	spoilerGuardRepository := postgres.NewSpoilerGuardRepository(client)
+	spoilerService := spoiler.NewService(spoilerGuardRepository, chapterRepository, volumeRepository, entityRepository, eventRepository)
+	retrievalHandler := handler.NewRetrievalHandler(engine, chapterRepository, projectRepository, seriesService, spoilerService, indexer)
+	canonContextService := story.NewCanonContextService(artifactRepository, seriesService)
+	storyGenStreamer, cleanup4, err := ProvideStoryGenStreamerOptional(ctx, cfg)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	streamHandler := handler.NewStreamHandler(cfg, chapterRepository, projectRepository, jobRepository, txManager, tenantContext, tokenQuotaChecker, chapterGenerator, generationFinalizer, engine, seriesService, projectLocker, jobTimeline, contextPinService, canonContextService, spoilerService, storyGenStreamer, chapterTitleService, jobProgressBus)
+	userHandler := handler.NewUserHandler(userRepository)
+	usageQuery := quota.NewUsageQuery(llmUsageEventRepository, txManager, tenantContext)
+	tenantHandler := handler.NewTenantHandler(cfg, tenantRepository, planService, indexer, usageQuery)
+	eventHandler := handler.NewEventHandler(eventRepository, chapterRepository, txManager, tenantContext, chapterEventReplacer)
+	relationHandler := handler.NewRelationHandler(relationRepository, relationWeigher)
+	seriesHandler := handler.NewSeriesHandler(seriesRepository, projectRepository, seriesService)
+	publicHandler := handler.NewPublicHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, chapterRepository)
+	paymentProvider := ProvidePaymentProviderOptional(ctx, cfg)
+	invoiceRepository := postgres.NewInvoiceRepository(client)
+	billingService := ProvideBillingService(cfg, paymentProvider, tenantRepository, invoiceRepository)
+	billingHandler := handler.NewBillingHandler(billingService, txManager, tenantContext)
+	watermarker := ProvideWatermarker(ctx, cfg)
+	manuscriptHandler := handler.NewManuscriptHandler(projectRepository, chapterRepository, watermarker)
+	spoilerGuardHandler := handler.NewSpoilerGuardHandler(spoilerGuardRepository, projectRepository, chapterRepository, spoilerService)
+	projectNoteRepository := postgres.NewProjectNoteRepository(client)
+	ingestor := notes.NewIngestor(artifactGenerator)
+	notesHandler := handler.NewNotesHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, jobRepository, artifactRepository, projectNoteRepository, tokenQuotaChecker, ingestor, indexer, jobTimeline)
+	featureFlagHandler := handler.NewFeatureFlagHandler(projectRepository, featureflagService)
+	opsService := ops.NewService(cache)
+	generationConsistency := ProvideGenerationConsistency(cfg, chapterRepository, jobRepository, tenantRepository, txManager, tenantContext)
+	warmer := ProvideGatewayWarmer(cfg, einoFactory, chapterGenerator, foundationGenerator, artifactGenerator, projectCreationGenerator, engine)
+	opsHandler := handler.NewOpsHandler(cfg, tenantRepository, opsService, einoFactory, producer, generationConsistency, planLimits, warmer)
+	candidateHandler := handler.NewCandidateHandler(txManager, tenantContext, jobRepository, generationCandidateRepository, chapterRepository, artifactRepository, projectRepository, projectLocker, generationFinalizer, indexer, jobTimeline, activationValidator, staleTracker, producer)
+	confirmService := confirm.NewService(cache)
+	confirmationHandler := handler.NewConfirmationHandler(confirmService, projectRepository, userRepository, artifactRepository, indexer)
+	diagnosticsHandler := handler.NewDiagnosticsHandler(cfg)
+	webSocketHandler := handler.NewWebSocketHandler(cfg, txManager, tenantContext, projectRepository, jobRepository, jobProgressBus)
+	rateLimiter := redis.NewRateLimiter(redisClient)
+	store := ProvideObjectStoreOptional(ctx, cfg)
+	routerHandlers := &router.RouterHandlers{
+		Auth:                 authHandler,
+		Health:               healthHandler,
+		Project:              projectHandler,
+		Volume:               volumeHandler,
+		Chapter:              chapterHandler,
+		Entity:               entityHandler,
+		Foundation:           foundationHandler,
+		Conversation:         conversationHandler,
+		ProjectCreation:      projectCreationHandler,
+		Artifact:             artifactHandler,
+		Job:                  jobHandler,
+		Retrieval:            retrievalHandler,
+		Stream:               streamHandler,
+		User:                 userHandler,
+		Tenant:               tenantHandler,
+		Event:                eventHandler,
+		Relation:             relationHandler,
+		Series:               seriesHandler,
+		Public:               publicHandler,
+		Billing:              billingHandler,
+		Manuscript:           manuscriptHandler,
+		SpoilerGuard:         spoilerGuardHandler,
+		Notes:                notesHandler,
+		FeatureFlag:          featureFlagHandler,
+		Ops:                  opsHandler,
+		Candidate:            candidateHandler,
+		Confirmation:         confirmationHandler,
+		Diagnostics:          diagnosticsHandler,
+		WebSocket:            webSocketHandler,
+		TenantRepo:           tenantRepository,
+		LLMUsageRepo:         llmUsageEventRepository,
+		TenantContext:        tenantContext,
+		QuotaReservationRepo: quotaReservationRepository,
+		RateLimiter:          rateLimiter,
+		Transactor:           txManager,
+		PlanLimits:           planService,
+		OpsSwitches:          opsService,
+		Confirmations:        confirmService,
+		ObjectStore:          store,
+		Warmer:               warmer,
+	}
+	routerRouter := router.NewWithDeps(cfg, routerHandlers)
+	return routerRouter, func() {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+// InitializeWorker 初始化 job-worker 依赖（Milvus/Embedding 不可达时降级，向量索引与 RAG 召回不可用）
+func InitializeWorker(ctx context.Context, cfg *config.Config) (*Worker, func(), error) {
+	client, cleanup, err := ProvidePostgresClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	redisClient, cleanup2, err := ProvideRedisClient(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	store := ProvideObjectStoreOptional(ctx, cfg)
+	txManager := postgres.NewTxManager(client)
+	tenantContext := postgres.NewTenantContext(client)
+	tenantRepository := postgres.NewTenantRepository(client)
+	projectRepository := postgres.NewProjectRepository(client)
+	chapterRepository := postgres.NewChapterRepository(client)
+	jobRepository := postgres.NewJobRepository(client)
+	projectLocker := postgres.NewProjectLocker(client)
+	llmUsageEventRepository := postgres.NewLLMUsageEventRepository(client)
+	quotaReservationRepository := postgres.NewQuotaReservationRepository(client)
+	llmCallbacks := ProvideLLMCallbacks(tenantRepository, llmUsageEventRepository, quotaReservationRepository, tenantContext)
+	einoFactory := ProvideEinoFactory(cfg, llmCallbacks)
+	foundationGenerator := foundation.NewFoundationGenerator(einoFactory)
+	planLimits := ProvideFoundationPlanLimits(cfg, tenantRepository)
+	chapterGenerator := chapter.NewChapterGenerator(einoFactory)
+	planRepository := postgres.NewPlanRepository(client)
+	tokenQuotaChecker := quota.NewTokenQuotaChecker(tenantRepository, quotaReservationRepository, planRepository)
+	planService := quota.NewPlanService(tenantRepository, planRepository)
+	seriesRepository := postgres.NewSeriesRepository(client)
+	artifactRepository := postgres.NewArtifactRepository(client)
+	seriesService := series.NewSeriesService(seriesRepository, projectRepository, artifactRepository)
+	jobEventRepository := postgres.NewJobEventRepository(client)
+	jobTimeline := story.NewJobTimeline(jobEventRepository)
+	jobBudget := ProvideJobBudget(cfg)
+	entityRepository := postgres.NewEntityRepository(client)
+	contextPinService := story.NewContextPinService(chapterRepository, entityRepository)
+	canonContextService := story.NewCanonContextService(artifactRepository, seriesService)
+	spoilerGuardRepository := postgres.NewSpoilerGuardRepository(client)
+	volumeRepository := postgres.NewVolumeRepository(client)
+	eventRepository := postgres.NewEventRepository(client)
+	service := spoiler.NewService(spoilerGuardRepository, chapterRepository, volumeRepository, entityRepository, eventRepository)
+	chapterTitleService := ProvideChapterTitleService(cfg, chapterGenerator, chapterRepository, projectRepository, txManager, tenantContext)
 	embedder, err := ProvideEmbedderOptional(ctx, cfg)
 	if err != nil {
 		cleanup2()
@@ -203,61 +421,118 @@ func InitializeApp(ctx context.Context, cfg *config.Config) (*router.Router, fun
 		cleanup()
 		return nil, nil, err
 	}
-	healthHandler := handler.NewHealthHandler(client, redisClient, milvusClient)
-	repository := ProvideMilvusRepositoryOptional(milvusClient)
+	repository := ProvideMilvusRepositoryOptional(ctx, milvusClient)
 	vectorRepository := ProvideRetrievalVectorRepositoryOptional(repository)
-	engine := ProvideRetrievalEngine(cfg, embedder, vectorRepository, entityRepository)
-	artifactGenerator := storyartifact.NewArtifactGenerator(einoFactory, engine)
-	indexer := ProvideRetrievalIndexer(cfg, embedder, vectorRepository)
-	conversationHandler := handler.NewConversationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, jobRepository, conversationSessionRepository, conversationTurnRepository, artifactRepository, rollingContextManager, tokenQuotaChecker, artifactGenerator, indexer)
-	projectCreationSessionRepository := postgres.NewProjectCreationSessionRepository(client)
-	projectCreationTurnRepository := postgres.NewProjectCreationTurnRepository(client)
-	llmUsageEventRepository := postgres.NewLLMUsageEventRepository(client)
-	projectCreationGenerator := storyprojectcreation.NewProjectCreationGenerator(einoFactory)
-	projectCreationHandler := handler.NewProjectCreationHandler(cfg, txManager, tenantContext, tenantRepository, projectRepository, conversationSessionRepository, projectCreationSessionRepository, projectCreationTurnRepository, jobRepository, llmUsageEventRepository, tokenQuotaChecker, projectCreationGenerator)
-	artifactHandler := handler.NewArtifactHandler(artifactRepository, indexer)
-	jobHandler := handler.NewJobHandler(jobRepository)
-	retrievalHandler := handler.NewRetrievalHandler(engine)
-	chapterGenerator := storychapter.NewChapterGenerator(einoFactory)
-	streamHandler := handler.NewStreamHandler(cfg, chapterRepository, projectRepository, jobRepository, txManager, tenantContext, tokenQuotaChecker, chapterGenerator, indexer, engine)
-	userHandler := handler.NewUserHandler(userRepository)
-	tenantHandler := handler.NewTenantHandler(tenantRepository)
-	eventRepository := postgres.NewEventRepository(client)
-	eventHandler := handler.NewEventHandler(eventRepository)
-	relationHandler := handler.NewRelationHandler(relationRepository)
-	rateLimiter := redis.NewRateLimiter(redisClient)
-	routerHandlers := &router.RouterHandlers{
-		Auth:            authHandler,
-		Health:          healthHandler,
-		Project:         projectHandler,
-		Volume:          volumeHandler,
-		Chapter:         chapterHandler,
-		Entity:          entityHandler,
-		Foundation:      foundationHandler,
-		Conversation:    conversationHandler,
-		ProjectCreation: projectCreationHandler,
-		Artifact:        artifactHandler,
-		Job:             jobHandler,
-		Retrieval:       retrievalHandler,
-		Stream:          streamHandler,
-		User:            userHandler,
-		Tenant:          tenantHandler,
-		Event:           eventHandler,
-		Relation:        relationHandler,
-		TenantRepo:      tenantRepository,
-		LLMUsageRepo:    llmUsageEventRepository,
-		TenantContext:   tenantContext,
-		RateLimiter:     rateLimiter,
-		Transactor:      txManager,
+	vectorUsageCounter := redis.NewVectorUsageCounter(redisClient)
+	indexer := ProvideRetrievalIndexer(cfg, embedder, vectorRepository, vectorUsageCounter)
+	generationCandidateRepository := postgres.NewGenerationCandidateRepository(client)
+	detector := ProvideDuplicateDetector(cfg, chapterRepository)
+	relationRepository := postgres.NewRelationRepository(client)
+	relationWeigher := ProvideRelationWeigher(cfg, chapterRepository, relationRepository)
+	chapterEventReplacer := story.NewChapterEventReplacer(eventRepository, chapterRepository, indexer, relationWeigher)
+	generationFinalizer := story.NewGenerationFinalizer(chapterRepository, projectRepository, jobRepository, eventRepository, indexer, jobTimeline, tokenQuotaChecker, generationCandidateRepository, detector, chapterEventReplacer)
+	reranker := ProvideRetrievalReranker(cfg, einoFactory)
+	engine := ProvideRetrievalEngine(cfg, embedder, vectorRepository, entityRepository, reranker, relationWeigher)
+	projectNoteRepository := postgres.NewProjectNoteRepository(client)
+	watermarker := ProvideWatermarker(ctx, cfg)
+	runner := maintenance.NewRunner(txManager, tenantContext, jobRepository, projectRepository, chapterRepository, volumeRepository, entityRepository, eventRepository, artifactRepository, projectNoteRepository, generationFinalizer, indexer, watermarker, jobTimeline)
+	cache := redis.NewCache(redisClient)
+	opsService := ops.NewService(cache)
+	chapterReindexer := ProvideChapterReindexer(cfg, redisClient, chapterRepository, generationFinalizer, txManager, tenantContext)
+	generationConsistency := ProvideGenerationConsistency(cfg, chapterRepository, jobRepository, tenantRepository, txManager, tenantContext)
+	jobProgressBus := redis.NewJobProgressBus(redisClient)
+	jobCancelSignal := redis.NewJobCancelSignal(redisClient)
+	warmer := ProvideWorkerWarmer(cfg, einoFactory, chapterGenerator, foundationGenerator, engine)
+	worker := &Worker{
+		PgClient:             client,
+		RedisClient:          redisClient,
+		ObjectStore:          store,
+		TxManager:            txManager,
+		TenantContext:        tenantContext,
+		TenantRepo:           tenantRepository,
+		ProjectRepo:          projectRepository,
+		ChapterRepo:          chapterRepository,
+		JobRepo:              jobRepository,
+		ProjectLocker:        projectLocker,
+		LLMCallbacks:         llmCallbacks,
+		FoundationGenerator:  foundationGenerator,
+		FoundationPlanLimits: planLimits,
+		ChapterGenerator:     chapterGenerator,
+		TokenQuotaChecker:    tokenQuotaChecker,
+		PlanService:          planService,
+		SeriesService:        seriesService,
+		JobTimeline:          jobTimeline,
+		JobBudget:            jobBudget,
+		ContextPins:          contextPinService,
+		CanonContext:         canonContextService,
+		SpoilerGuards:        service,
+		TitleSuggestions:     chapterTitleService,
+		Finalizer:            generationFinalizer,
+		RetrievalEngine:      engine,
+		MaintenanceRunner:    runner,
+		OpsSwitches:          opsService,
+		Reindexer:            chapterReindexer,
+		Consistency:          generationConsistency,
+		JobProgress:          jobProgressBus,
+		JobCancel:            jobCancelSignal,
+		Warmer:               warmer,
 	}
-	routerRouter := router.NewWithDeps(cfg, routerHandlers)
-	return routerRouter, func() {
+	return worker, func() {
 		cleanup3()
 		cleanup2()
 		cleanup()
 	}, nil
 }
 
+// InitializeMemoryService 初始化 Memory gRPC 服务
+func InitializeMemoryService(ctx context.Context, cfg *config.Config) (*server.MemoryService, func(), error) {
+	client, cleanup, err := ProvidePostgresClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	txManager := postgres.NewTxManager(client)
+	tenantContext := postgres.NewTenantContext(client)
+	entityRepository := postgres.NewEntityRepository(client)
+	memoryService := server.NewMemoryService(txManager, tenantContext, entityRepository)
+	return memoryService, func() {
+		cleanup()
+	}, nil
+}
+
+// InitializeRetrievalService 初始化 Retrieval gRPC 服务
+func InitializeRetrievalService(ctx context.Context, cfg *config.Config) (*server.RetrievalService, func(), error) {
+	embedder, err := ProvideEmbedder(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, cleanup, err := ProvideMilvusClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	repository := ProvideMilvusRepository(ctx, client)
+	retrievalService := server.NewRetrievalService(embedder, repository)
+	return retrievalService, func() {
+		cleanup()
+	}, nil
+}
+
+// InitializeStoryGenService 初始化 StoryGen gRPC 服务
+func InitializeStoryGenService(ctx context.Context, cfg *config.Config) (*server.StoryGenService, func(), error) {
+	llmCallbacks := ProvideMetricsOnlyLLMCallbacks()
+	einoFactory := ProvideEinoFactory(cfg, llmCallbacks)
+	chapterGenerator := chapter.NewChapterGenerator(einoFactory)
+	storyGenService := server.NewStoryGenService(chapterGenerator)
+	return storyGenService, func() {
+	}, nil
+}
+
+// InitializeValidatorService 初始化 Validator gRPC 服务
+func InitializeValidatorService(ctx context.Context, cfg *config.Config) (*server.ValidatorService, func(), error) {
+	validatorService := &server.ValidatorService{}
+	return validatorService, func() {
+	}, nil
+}
+
 // wire.go:
 
 // DataLayer 数据层依赖容器
@@ -281,6 +556,7 @@ type DataLayer struct {
 	ArtifactRepo  *postgres.ArtifactRepository
 	PCSessionRepo *postgres.ProjectCreationSessionRepository
 	PCTurnRepo    *postgres.ProjectCreationTurnRepository
+	SeriesRepo    *postgres.SeriesRepository
 
 	// Redis
 	RedisClient *redis.Client
@@ -315,16 +591,17 @@ type PostgresOnlyDataLayer struct {
 	ArtifactRepo  *postgres.ArtifactRepository
 	PCSessionRepo *postgres.ProjectCreationSessionRepository
 	PCTurnRepo    *postgres.ProjectCreationTurnRepository
+	SeriesRepo    *postgres.SeriesRepository
 }
 
 // PostgresSet PostgreSQL 提供者集合
 var PostgresSet = wire.NewSet(
-	ProvidePostgresClient, postgres.NewTxManager, postgres.NewTenantContext, postgres.NewTenantRepository, postgres.NewUserRepository, postgres.NewProjectRepository, postgres.NewVolumeRepository, postgres.NewChapterRepository, postgres.NewEntityRepository, postgres.NewRelationRepository, postgres.NewEventRepository, postgres.NewJobRepository, postgres.NewLLMUsageEventRepository, postgres.NewConversationSessionRepository, postgres.NewConversationTurnRepository, postgres.NewArtifactRepository, postgres.NewProjectCreationSessionRepository, postgres.NewProjectCreationTurnRepository,
+	ProvidePostgresClient, postgres.NewTxManager, postgres.NewTenantContext, postgres.NewTenantRepository, postgres.NewUserRepository, postgres.NewProjectRepository, postgres.NewVolumeRepository, postgres.NewChapterRepository, postgres.NewEntityRepository, postgres.NewRelationRepository, postgres.NewEventRepository, postgres.NewJobRepository, postgres.NewLLMUsageEventRepository, postgres.NewConversationSessionRepository, postgres.NewConversationTurnRepository, postgres.NewArtifactRepository, postgres.NewProjectCreationSessionRepository, postgres.NewProjectCreationTurnRepository, postgres.NewSeriesRepository, postgres.NewProjectLocker, postgres.NewJobEventRepository, postgres.NewQuotaReservationRepository, postgres.NewPlanRepository, postgres.NewInvoiceRepository, postgres.NewSpoilerGuardRepository, postgres.NewProjectNoteRepository, postgres.NewFeatureFlagRepository, postgres.NewGenerationCandidateRepository,
 )
 
 // RedisSet Redis 提供者集合
 var RedisSet = wire.NewSet(
-	ProvideRedisClient, redis.NewCache, redis.NewRateLimiter, wire.Bind(new(storyctx.KVCache), new(*redis.Cache)), wire.Bind(new(middleware.RateLimiter), new(*redis.RateLimiter)),
+	ProvideRedisClient, redis.NewCache, redis.NewRateLimiter, wire.Bind(new(context2.KVCache), new(*redis.Cache)), wire.Bind(new(featureflag.Cache), new(*redis.Cache)), wire.Bind(new(ops.Store), new(*redis.Cache)), wire.Bind(new(confirm.Store), new(*redis.Cache)), redis.NewVectorUsageCounter, wire.Bind(new(retrieval.UsageCounter), new(*redis.VectorUsageCounter)), redis.NewJobProgressBus, wire.Bind(new(story.JobProgressBus), new(*redis.JobProgressBus)), redis.NewJobCancelSignal, wire.Bind(new(story.JobCancelSignal), new(*redis.JobCancelSignal)), wire.Bind(new(middleware.RateLimiter), new(*redis.RateLimiter)),
 )
 
 // MessagingSet 消息队列提供者集合
@@ -334,7 +611,8 @@ var MessagingSet = wire.NewSet(
 
 // MilvusSet Milvus 提供者集合
 var MilvusSet = wire.NewSet(
-	ProvideMilvusClient, milvus.NewRepository,
+	ProvideMilvusClient,
+	ProvideMilvusRepository,
 )
 
 // MilvusAppSet API 网关可选 Milvus（不可达时不阻塞启动）
@@ -352,6 +630,7 @@ var EmbeddingSet = wire.NewSet(
 // RetrievalSet 本地检索引擎（HTTP + 生成侧共用）
 var RetrievalSet = wire.NewSet(
 	ProvideRetrievalEngine,
+	ProvideRetrievalReranker,
 	ProvideRetrievalIndexer,
 )
 
@@ -369,12 +648,22 @@ var GRPCClientsSet = wire.NewSet(
 
 // RouterSet 路由器提供者集合
 var RouterSet = wire.NewSet(
-	ProvideAuthConfig, llm.NewEinoFactory, storychapter.NewChapterGenerator, storyfoundation.NewFoundationGenerator, storyartifact.NewArtifactGenerator, quota.NewTokenQuotaChecker, storyfoundation.NewFoundationApplier, storyprojectcreation.NewProjectCreationGenerator, storyctx.NewRollingContextManager, handler.NewAuthHandler, handler.NewHealthHandler, handler.NewProjectHandler, handler.NewVolumeHandler, handler.NewChapterHandler, handler.NewEntityHandler, handler.NewFoundationHandler, handler.NewConversationHandler, handler.NewProjectCreationHandler, handler.NewArtifactHandler, handler.NewJobHandler, handler.NewRetrievalHandler, handler.NewStreamHandler, handler.NewUserHandler, handler.NewTenantHandler, handler.NewEventHandler, handler.NewRelationHandler, wire.Struct(new(router.RouterHandlers), "*"), router.NewWithDeps,
+	ProvideAuthConfig, llm.NewEinoFactory, wire.Bind(new(port.ChatModelFactory), new(*llm.EinoFactory)), chapter.NewChapterGenerator, foundation.NewFoundationGenerator, artifact.NewArtifactGenerator, quota.NewTokenQuotaChecker, quota.NewPlanService, quota.NewUsageQuery, wire.Bind(new(middleware.PlanRateLimitResolver), new(*quota.PlanService)), foundation.NewFoundationApplier, wire.Bind(new(foundation.NarrativePositionSyncer), new(*story.ChapterNumbering)), ProvideStoryTimeValidator,
+	ProvideRelationWeigher,
+	ProvideDuplicateDetector,
+	ProvideChapterTitleService, projectcreation.NewProjectCreationGenerator, context2.NewRollingContextManager, story.NewJobTimeline, story.NewGenerationFinalizer, story.NewChapterNumbering, ProvideChapterReindexer,
+	ProvideGenerationConsistency,
+	ProvideFoundationPlanLimits,
+	ProvideArtifactActivationValidator, story.NewContextPinService, story.NewCanonContextService, story.NewChapterEventReplacer, spoiler.NewService, health.NewService, outline.NewStaleTracker, notes.NewIngestor, transcript.NewExporter, featureflag.NewService, ops.NewService, wire.Bind(new(middleware.OpsSwitchResolver), new(*ops.Service)), confirm.NewService, wire.Bind(new(middleware.ConfirmationVerifier), new(*confirm.Service)), wire.Bind(new(featureflag.Client), new(*featureflag.Service)), series.NewSeriesService, ProvidePaymentProviderOptional,
+	ProvideBillingService,
+	ProvideWatermarker,
+	ProvideObjectStoreOptional,
+	ProvideStoryGenStreamerOptional, handler.NewAuthHandler, handler.NewHealthHandler, handler.NewProjectHandler, handler.NewVolumeHandler, handler.NewChapterHandler, handler.NewEntityHandler, handler.NewFoundationHandler, handler.NewConversationHandler, handler.NewProjectCreationHandler, handler.NewArtifactHandler, handler.NewJobHandler, handler.NewRetrievalHandler, handler.NewStreamHandler, handler.NewUserHandler, handler.NewTenantHandler, handler.NewEventHandler, handler.NewRelationHandler, handler.NewSeriesHandler, handler.NewPublicHandler, handler.NewBillingHandler, handler.NewManuscriptHandler, handler.NewSpoilerGuardHandler, handler.NewNotesHandler, handler.NewFeatureFlagHandler, handler.NewOpsHandler, handler.NewCandidateHandler, handler.NewConfirmationHandler, handler.NewDiagnosticsHandler, handler.NewWebSocketHandler, ProvideGatewayWarmer, wire.Struct(new(router.RouterHandlers), "*"), router.NewWithDeps,
 )
 
 // RepoSet 整合了具体实现与接口绑定的集合
 var RepoSet = wire.NewSet(
-	PostgresSet, wire.Bind(new(repository.Transactor), new(*postgres.TxManager)), wire.Bind(new(repository.TenantContextManager), new(*postgres.TenantContext)), wire.Bind(new(repository.TenantRepository), new(*postgres.TenantRepository)), wire.Bind(new(repository.UserRepository), new(*postgres.UserRepository)), wire.Bind(new(repository.ProjectRepository), new(*postgres.ProjectRepository)), wire.Bind(new(repository.VolumeRepository), new(*postgres.VolumeRepository)), wire.Bind(new(repository.ChapterRepository), new(*postgres.ChapterRepository)), wire.Bind(new(repository.EntityRepository), new(*postgres.EntityRepository)), wire.Bind(new(repository.RelationRepository), new(*postgres.RelationRepository)), wire.Bind(new(repository.JobRepository), new(*postgres.JobRepository)), wire.Bind(new(repository.LLMUsageEventRepository), new(*postgres.LLMUsageEventRepository)), wire.Bind(new(repository.EventRepository), new(*postgres.EventRepository)), wire.Bind(new(repository.ConversationSessionRepository), new(*postgres.ConversationSessionRepository)), wire.Bind(new(repository.ConversationTurnRepository), new(*postgres.ConversationTurnRepository)), wire.Bind(new(repository.ArtifactRepository), new(*postgres.ArtifactRepository)), wire.Bind(new(repository.ProjectCreationSessionRepository), new(*postgres.ProjectCreationSessionRepository)), wire.Bind(new(repository.ProjectCreationTurnRepository), new(*postgres.ProjectCreationTurnRepository)),
+	PostgresSet, wire.Bind(new(repository.Transactor), new(*postgres.TxManager)), wire.Bind(new(repository.TenantContextManager), new(*postgres.TenantContext)), wire.Bind(new(repository.TenantRepository), new(*postgres.TenantRepository)), wire.Bind(new(repository.UserRepository), new(*postgres.UserRepository)), wire.Bind(new(repository.ProjectRepository), new(*postgres.ProjectRepository)), wire.Bind(new(repository.VolumeRepository), new(*postgres.VolumeRepository)), wire.Bind(new(repository.ChapterRepository), new(*postgres.ChapterRepository)), wire.Bind(new(repository.EntityRepository), new(*postgres.EntityRepository)), wire.Bind(new(repository.RelationRepository), new(*postgres.RelationRepository)), wire.Bind(new(repository.JobRepository), new(*postgres.JobRepository)), wire.Bind(new(repository.LLMUsageEventRepository), new(*postgres.LLMUsageEventRepository)), wire.Bind(new(repository.EventRepository), new(*postgres.EventRepository)), wire.Bind(new(repository.ConversationSessionRepository), new(*postgres.ConversationSessionRepository)), wire.Bind(new(repository.ConversationTurnRepository), new(*postgres.ConversationTurnRepository)), wire.Bind(new(repository.ArtifactRepository), new(*postgres.ArtifactRepository)), wire.Bind(new(repository.ProjectCreationSessionRepository), new(*postgres.ProjectCreationSessionRepository)), wire.Bind(new(repository.ProjectCreationTurnRepository), new(*postgres.ProjectCreationTurnRepository)), wire.Bind(new(repository.SeriesRepository), new(*postgres.SeriesRepository)), wire.Bind(new(repository.ProjectLocker), new(*postgres.ProjectLocker)), wire.Bind(new(repository.JobEventRepository), new(*postgres.JobEventRepository)), wire.Bind(new(repository.QuotaReservationRepository), new(*postgres.QuotaReservationRepository)), wire.Bind(new(repository.PlanRepository), new(*postgres.PlanRepository)), wire.Bind(new(repository.InvoiceRepository), new(*postgres.InvoiceRepository)), wire.Bind(new(repository.SpoilerGuardRepository), new(*postgres.SpoilerGuardRepository)), wire.Bind(new(repository.ProjectNoteRepository), new(*postgres.ProjectNoteRepository)), wire.Bind(new(repository.FeatureFlagRepository), new(*postgres.FeatureFlagRepository)), wire.Bind(new(repository.GenerationCandidateRepository), new(*postgres.GenerationCandidateRepository)),
 )
 
 // ProvidePostgresClient 提供 PostgreSQL 客户端
@@ -434,11 +723,20 @@ func ProvideMilvusClientOptional(ctx context.Context, cfg *config.Config) (*milv
 	return client, cleanup, nil
 }
 
-func ProvideMilvusRepositoryOptional(client *milvus.Client) *milvus.Repository {
+// ProvideMilvusRepository 提供 Milvus 仓储，并探测已存在集合的可选字段（narrative_pos 等）
+func ProvideMilvusRepository(ctx context.Context, client *milvus.Client) *milvus.Repository {
+	repo := milvus.NewRepository(client)
+	if err := repo.DetectSchema(ctx); err != nil {
+		logger.Warn(ctx, "failed to detect milvus collection schema", "error", err.Error())
+	}
+	return repo
+}
+
+func ProvideMilvusRepositoryOptional(ctx context.Context, client *milvus.Client) *milvus.Repository {
 	if client == nil {
 		return nil
 	}
-	return milvus.NewRepository(client)
+	return ProvideMilvusRepository(ctx, client)
 }
 
 func ProvideRetrievalVectorRepositoryOptional(repo *milvus.Repository) retrieval.VectorRepository {
@@ -457,20 +755,107 @@ func ProvideEmbedderOptional(ctx context.Context, cfg *config.Config) (embedding
 	return embedder, nil
 }
 
-func ProvideRetrievalEngine(cfg *config.Config, embedder embedding.Embedder, vectorRepo retrieval.VectorRepository, entityRepo repository.EntityRepository) *retrieval.Engine {
+func ProvideRetrievalEngine(cfg *config.Config, embedder embedding.Embedder, vectorRepo retrieval.VectorRepository, entityRepo repository.EntityRepository, reranker retrieval.Reranker, relations *story.RelationWeigher) *retrieval.Engine {
 	bs := 0
 	if cfg != nil {
 		bs = cfg.Embedding.BatchSize
 	}
-	return retrieval.NewEngine(embedder, vectorRepo, entityRepo, bs)
+	engine := retrieval.NewEngine(embedder, vectorRepo, entityRepo, bs)
+	if reranker != nil {
+		engine.EnableRerank(reranker, cfg.Vector.Rerank.Timeout)
+	}
+	if relations != nil {
+		engine.EnableRelationWeighting(relations)
+	}
+	return engine
 }
 
-func ProvideRetrievalIndexer(cfg *config.Config, embedder embedding.Embedder, vectorRepo retrieval.VectorRepository) *retrieval.Indexer {
+func ProvideRetrievalIndexer(cfg *config.Config, embedder embedding.Embedder, vectorRepo retrieval.VectorRepository, usage retrieval.UsageCounter) *retrieval.Indexer {
 	bs := 0
 	if cfg != nil {
 		bs = cfg.Embedding.BatchSize
 	}
-	return retrieval.NewIndexer(embedder, vectorRepo, bs)
+	indexer := retrieval.NewIndexer(embedder, vectorRepo, bs)
+	if cfg != nil && usage != nil {
+		indexer.EnableUsageQuota(usage, retrieval.QuotaLimits{
+			MaxSegmentsPerTenant:  cfg.Vector.Quota.MaxSegmentsPerTenant,
+			MaxSegmentsPerProject: cfg.Vector.Quota.MaxSegmentsPerProject,
+		}, cfg.Embedding.Dimension)
+	}
+	return indexer
+}
+
+// ProvideStoryTimeValidator 提供章节故事时间单调性校验器
+func ProvideStoryTimeValidator(cfg *config.Config, chapterRepo repository.ChapterRepository) *timeline.StoryTimeValidator {
+	mode := timeline.CheckModeWarn
+	if cfg != nil {
+		mode = timeline.ParseCheckMode(cfg.Story.StoryTimeCheck)
+	}
+	return timeline.NewStoryTimeValidator(chapterRepo, mode)
+}
+
+// ProvideRelationWeigher 提供关系时效加权器
+func ProvideRelationWeigher(cfg *config.Config, chapterRepo repository.ChapterRepository, relationRepo repository.RelationRepository) *story.RelationWeigher {
+	halfLife := 0
+	if cfg != nil {
+		halfLife = cfg.Story.RelationHalfLifeChapters
+	}
+	return story.NewRelationWeigher(chapterRepo, relationRepo, halfLife)
+}
+
+// ProvideDuplicateDetector 提供章节重复内容检测器
+func ProvideDuplicateDetector(cfg *config.Config, chapterRepo repository.ChapterRepository) *duplicate.Detector {
+	threshold := 0.0
+	if cfg != nil {
+		threshold = cfg.Story.DuplicateSimilarityThreshold
+	}
+	return duplicate.NewDetector(chapterRepo, threshold)
+}
+
+// ProvideChapterTitleService 提供章节标题建议服务
+func ProvideChapterTitleService(cfg *config.Config, generator *chapter.ChapterGenerator, chapterRepo repository.ChapterRepository, projectRepo repository.ProjectRepository, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *story.ChapterTitleService {
+	auto, timeout := false, time.Duration(0)
+	if cfg != nil {
+		auto = cfg.Story.AutoTitleSuggestions
+		timeout = cfg.Story.GenerationTimeouts.TitleSuggest
+	}
+	return story.NewChapterTitleService(generator, chapterRepo, projectRepo, txMgr, tenantCtx, auto, timeout)
+}
+
+// ProvideChapterReindexer 提供编辑后自动重建索引服务（未开启时不排队）
+func ProvideChapterReindexer(cfg *config.Config, redisClient *redis.Client, chapterRepo repository.ChapterRepository, finalizer *story.GenerationFinalizer, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *story.ChapterReindexer {
+	var rc config.ReindexConfig
+	if cfg != nil {
+		rc = cfg.Story.Reindex
+	}
+	var queue story.ReindexQueue
+	if rc.Enabled && redisClient != nil {
+		queue = redis.NewReindexQueue(redisClient)
+	}
+	return story.NewChapterReindexer(queue, chapterRepo, finalizer, txMgr, tenantCtx, rc.Debounce, rc.MaxDelay)
+}
+
+// ProvideGenerationConsistency 提供任务/章节状态巡检服务（运维接口按需触发，Worker 周期执行）
+func ProvideGenerationConsistency(cfg *config.Config, chapterRepo repository.ChapterRepository, jobRepo repository.JobRepository, tenantRepo repository.TenantRepository, txMgr repository.Transactor, tenantCtx repository.TenantContextManager) *story.GenerationConsistency {
+	var grace time.Duration
+	if cfg != nil {
+		grace = cfg.Story.GenerationConsistency.Grace
+	}
+	return story.NewGenerationConsistency(chapterRepo, jobRepo, tenantRepo, txMgr, tenantCtx, grace)
+}
+
+// ProvideFoundationPlanLimits 提供设定集规模上限（全局配置叠加租户覆盖）
+func ProvideFoundationPlanLimits(cfg *config.Config, tenantRepo repository.TenantRepository) *foundation.PlanLimits {
+	var defaults entity.FoundationPlanLimits
+	if cfg != nil {
+		defaults = entity.FoundationPlanLimits(cfg.Story.FoundationPlanLimits)
+	}
+	return foundation.NewPlanLimits(defaults, tenantRepo)
+}
+
+// ProvideArtifactActivationValidator 提供构件激活前的租户校验 Webhook（按租户设置调用，未设置时放行）
+func ProvideArtifactActivationValidator(tenantRepo repository.TenantRepository) *artifact.ActivationValidator {
+	return artifact.NewActivationValidator(tenantRepo, webhook.NewArtifactValidationCaller(nil))
 }
 
 // ProvideAuthConfig 提供认证配置
@@ -482,3 +867,43 @@ func ProvideAuthConfig(cfg *config.Config) middleware.AuthConfig {
 		Enabled:   true,
 	}
 }
+
+// ProvidePaymentProviderOptional 提供支付提供商适配（未启用计费或配置无效时返回 nil，Webhook 不可用）
+func ProvidePaymentProviderOptional(ctx context.Context, cfg *config.Config) billing.PaymentProvider {
+	if !cfg.Billing.Enabled {
+		return nil
+	}
+	provider, err := payment.NewProvider(&cfg.Billing)
+	if err != nil {
+		logger.Warn(ctx, "payment provider not available, billing webhooks disabled", "error", err.Error())
+		return nil
+	}
+	return provider
+}
+
+// ProvideBillingService 提供计费服务
+func ProvideBillingService(cfg *config.Config, provider billing.PaymentProvider, tenantRepo repository.TenantRepository, invoiceRepo repository.InvoiceRepository) *billing.Service {
+	return billing.NewService(provider, tenantRepo, invoiceRepo, cfg.Billing.TokensPerMinorUnit)
+}
+
+// ProvideWatermarker 提供成稿来源标记水印器（未启用或未配置密钥时返回 nil）
+func ProvideWatermarker(ctx context.Context, cfg *config.Config) *provenance.Watermarker {
+	if !cfg.Provenance.Enabled {
+		return nil
+	}
+	w := provenance.NewWatermarker(cfg.Provenance.Secret, provenance.ParseMode(cfg.Provenance.Mode))
+	if w == nil {
+		logger.Warn(ctx, "provenance secret not configured, manuscript watermarking disabled")
+	}
+	return w
+}
+
+// ProvideObjectStoreOptional 提供对象存储（配置无效时返回 nil，依赖存储的功能不可用）
+func ProvideObjectStoreOptional(ctx context.Context, cfg *config.Config) objectstore.Store {
+	store, err := objectstore.NewStore(&cfg.Storage)
+	if err != nil {
+		logger.Warn(ctx, "object store not available, blob storage disabled", "error", err.Error())
+		return nil
+	}
+	return store
+}
@@ -0,0 +1,104 @@
+// Package wire 提供依赖注入配置
+package wire
+
+import (
+	"github.com/google/wire"
+
+	"z-novel-ai-api/internal/application/maintenance"
+	"z-novel-ai-api/internal/application/ops"
+	"z-novel-ai-api/internal/application/quota"
+	"z-novel-ai-api/internal/application/retrieval"
+	appstory "z-novel-ai-api/internal/application/story"
+	storychapter "z-novel-ai-api/internal/application/story/chapter"
+	storyfoundation "z-novel-ai-api/internal/application/story/foundation"
+	storyseries "z-novel-ai-api/internal/application/story/series"
+	storyspoiler "z-novel-ai-api/internal/application/story/spoiler"
+	"z-novel-ai-api/internal/application/warmup"
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/internal/infrastructure/llm"
+	"z-novel-ai-api/internal/infrastructure/objectstore"
+	"z-novel-ai-api/internal/infrastructure/persistence/postgres"
+	"z-novel-ai-api/internal/infrastructure/persistence/redis"
+	workflowport "z-novel-ai-api/internal/workflow/port"
+)
+
+// Worker job-worker 依赖容器（消费者与后台循环在 main 中按配置启动）
+type Worker struct {
+	// 基础设施
+	PgClient    *postgres.Client
+	RedisClient *redis.Client
+	ObjectStore objectstore.Store
+
+	// Repositories
+	TxManager     *postgres.TxManager
+	TenantContext *postgres.TenantContext
+	TenantRepo    *postgres.TenantRepository
+	ProjectRepo   *postgres.ProjectRepository
+	ChapterRepo   *postgres.ChapterRepository
+	JobRepo       *postgres.JobRepository
+	ProjectLocker *postgres.ProjectLocker
+
+	// 应用服务
+	LLMCallbacks         LLMCallbacks
+	FoundationGenerator  *storyfoundation.FoundationGenerator
+	FoundationPlanLimits *storyfoundation.PlanLimits
+	ChapterGenerator     *storychapter.ChapterGenerator
+	TokenQuotaChecker    *quota.TokenQuotaChecker
+	PlanService          *quota.PlanService
+	SeriesService        *storyseries.SeriesService
+	JobTimeline          *appstory.JobTimeline
+	JobBudget            *appstory.JobBudget
+	ContextPins          *appstory.ContextPinService
+	CanonContext         *appstory.CanonContextService
+	SpoilerGuards        *storyspoiler.Service
+	TitleSuggestions     *appstory.ChapterTitleService
+	Finalizer            *appstory.GenerationFinalizer
+	RetrievalEngine      *retrieval.Engine
+	MaintenanceRunner    *maintenance.Runner
+	OpsSwitches          *ops.Service
+	Reindexer            *appstory.ChapterReindexer
+	Consistency          *appstory.GenerationConsistency
+	JobProgress          appstory.JobProgressBus
+	JobCancel            appstory.JobCancelSignal
+	Warmer               *warmup.Warmer
+}
+
+// WorkerSet job-worker 应用服务提供者集合（与 RepoSet/RedisSet/MilvusAppSet/EmbeddingSet/RetrievalSet 组合使用）
+var WorkerSet = wire.NewSet(
+	ProvideLLMCallbacks,
+	ProvideEinoFactory,
+	wire.Bind(new(workflowport.ChatModelFactory), new(*llm.EinoFactory)),
+	storyfoundation.NewFoundationGenerator,
+	ProvideFoundationPlanLimits,
+	storychapter.NewChapterGenerator,
+	quota.NewTokenQuotaChecker,
+	quota.NewPlanService,
+	storyseries.NewSeriesService,
+	appstory.NewJobTimeline,
+	ProvideJobBudget,
+	appstory.NewContextPinService,
+	appstory.NewCanonContextService,
+	storyspoiler.NewService,
+	ProvideRelationWeigher,
+	ProvideDuplicateDetector,
+	ProvideChapterTitleService,
+	appstory.NewChapterEventReplacer,
+	appstory.NewGenerationFinalizer,
+	ProvideWatermarker,
+	maintenance.NewRunner,
+	ops.NewService,
+	ProvideChapterReindexer,
+	ProvideGenerationConsistency,
+	ProvideObjectStoreOptional,
+	ProvideWorkerWarmer,
+	wire.Struct(new(Worker), "*"),
+)
+
+// ProvideJobBudget 提供单任务 Token 成本上限
+func ProvideJobBudget(cfg *config.Config) *appstory.JobBudget {
+	var ceiling int64
+	if cfg != nil {
+		ceiling = cfg.Story.JobCostCeilingTokens
+	}
+	return appstory.NewJobBudget(ceiling)
+}
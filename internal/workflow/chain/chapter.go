@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/components/model"
+	einoprompt "github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/schema"
 
 	llmctx "z-novel-ai-api/internal/domain/service"
@@ -92,10 +93,18 @@ func (c *ChapterChain) Stream(ctx context.Context, in *wfmodel.ChapterGenerateIn
 	return chatModel.Stream(ctx, msgs, buildChapterModelOptions(in)...)
 }
 
+// Messages 渲染章节生成的完整 Prompt（不调用模型），用于 Token/成本预估。
+func (c *ChapterChain) Messages(ctx context.Context, in *wfmodel.ChapterGenerateInput) ([]*schema.Message, error) {
+	if in == nil {
+		return nil, fmt.Errorf("input is nil")
+	}
+	return formatChapterMessages(ctx, in)
+}
+
 var chapterPromptRegistry = workflowprompt.NewRegistry()
 
 func formatChapterMessages(ctx context.Context, in *wfmodel.ChapterGenerateInput) ([]*schema.Message, error) {
-	tpl, err := chapterPromptRegistry.ChatTemplate(workflowprompt.PromptChapterGenV1)
+	tpl, err := chapterPromptTemplate(in.PromptOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -108,8 +117,48 @@ func formatChapterMessages(ctx context.Context, in *wfmodel.ChapterGenerateInput
 		"chapter_title":       strings.TrimSpace(in.ChapterTitle),
 		"chapter_outline":     strings.TrimSpace(in.ChapterOutline),
 		"retrieved_context":   strings.TrimSpace(in.RetrievedContext),
+		"active_worldview":    strings.TrimSpace(in.ActiveWorldview),
+		"active_characters":   strings.TrimSpace(in.ActiveCharacters),
+	}
+	msgs, err := tpl.Format(ctx, vars)
+	if err != nil {
+		return nil, err
+	}
+	if partial := strings.TrimSpace(in.ContinueFrom); partial != "" {
+		msgs = append(msgs, schema.AssistantMessage(partial, nil), schema.UserMessage(chapterContinueInstruction))
+	}
+	return msgs, nil
+}
+
+// chapterContinueInstruction 续写被中断的章节时追加的用户指令
+const chapterContinueInstruction = "上面的章节正文在生成途中被中断。请从中断处直接接着写完本章：不要重复已有内容，不要添加说明或标题，保持文风与叙述视角一致，使全文总字数接近目标字数。"
+
+// ChapterPromptText 返回章节生成实际使用的模板文本（应用覆盖后）
+func ChapterPromptText(override *wfmodel.PromptTemplateOverride) (system string, user string, err error) {
+	system, user, err = workflowprompt.TemplateTexts(workflowprompt.PromptChapterGenV1)
+	if err != nil {
+		return "", "", err
+	}
+	if override != nil {
+		if v := strings.TrimSpace(override.System); v != "" {
+			system = v
+		}
+		if v := strings.TrimSpace(override.User); v != "" {
+			user = v
+		}
+	}
+	return system, user, nil
+}
+
+func chapterPromptTemplate(override *wfmodel.PromptTemplateOverride) (einoprompt.ChatTemplate, error) {
+	if override == nil {
+		return chapterPromptRegistry.ChatTemplate(workflowprompt.PromptChapterGenV1)
+	}
+	system, user, err := ChapterPromptText(override)
+	if err != nil {
+		return nil, err
 	}
-	return tpl.Format(ctx, vars)
+	return workflowprompt.NewChatTemplate(system, user), nil
 }
 
 func buildChapterModelOptions(in *wfmodel.ChapterGenerateInput) []model.Option {
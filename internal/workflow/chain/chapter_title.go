@@ -0,0 +1,161 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	openaiopts "github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+
+	llmctx "z-novel-ai-api/internal/domain/service"
+	wfmodel "z-novel-ai-api/internal/workflow/model"
+	wfnode "z-novel-ai-api/internal/workflow/node"
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
+)
+
+// maxChapterTitleRunes 单个建议标题的最大字符数（超出视为无效输出）
+const maxChapterTitleRunes = 30
+
+// SuggestTitles 根据章节正文提出候选标题（单次小请求，输出 {"titles": [...]}）
+func (c *ChapterChain) SuggestTitles(ctx context.Context, in *wfmodel.ChapterTitleSuggestInput) (*wfmodel.ChapterTitleSuggestOutput, error) {
+	if c == nil || c.factory == nil {
+		return nil, fmt.Errorf("llm factory not configured")
+	}
+	if in == nil {
+		return nil, fmt.Errorf("input is nil")
+	}
+	if strings.TrimSpace(in.Provider) == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if strings.TrimSpace(in.ChapterContent) == "" {
+		return nil, fmt.Errorf("chapter content is required")
+	}
+
+	tpl, err := chapterPromptRegistry.ChatTemplate(workflowprompt.PromptChapterTitleV1)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := tpl.Format(ctx, map[string]any{
+		"project_title":   strings.TrimSpace(in.ProjectTitle),
+		"project_genre":   strings.TrimSpace(in.ProjectGenre),
+		"seq_num":         in.SeqNum,
+		"current_title":   strings.TrimSpace(in.CurrentTitle),
+		"chapter_outline": strings.TrimSpace(in.ChapterOutline),
+		"chapter_content": strings.TrimSpace(in.ChapterContent),
+		"count":           in.Count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = llmctx.WithWorkflowProvider(ctx, "chapter_title_suggest", strings.TrimSpace(in.Provider))
+	chatModel, err := c.factory.Get(ctx, strings.TrimSpace(in.Provider))
+	if err != nil {
+		return nil, err
+	}
+
+	outMsg, err := chatModel.Generate(ctx, msgs, buildChapterTitleModelOptions(in, true)...)
+	if err != nil && wfnode.IsResponseFormatUnsupportedError(err) {
+		outMsg, err = chatModel.Generate(ctx, msgs, buildChapterTitleModelOptions(in, false)...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if outMsg == nil {
+		return nil, fmt.Errorf("empty llm response")
+	}
+
+	raw := wfnode.ExtractJSONObject(outMsg.Content)
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("empty title suggestion output")
+	}
+	var parsed struct {
+		Titles []string `json:"titles"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse title suggestion json: %w", err)
+	}
+	titles := normalizeChapterTitles(parsed.Titles, in.Count)
+	if len(titles) == 0 {
+		return nil, fmt.Errorf("no valid title suggestions")
+	}
+
+	meta := wfmodel.LLMUsageMeta{
+		Provider:    strings.TrimSpace(in.Provider),
+		Model:       strings.TrimSpace(in.Model),
+		GeneratedAt: time.Now().UTC(),
+	}
+	if in.Temperature != nil {
+		meta.Temperature = float64(*in.Temperature)
+	}
+	if outMsg.ResponseMeta != nil && outMsg.ResponseMeta.Usage != nil {
+		meta.PromptTokens = outMsg.ResponseMeta.Usage.PromptTokens
+		meta.CompletionTokens = outMsg.ResponseMeta.Usage.CompletionTokens
+	}
+	return &wfmodel.ChapterTitleSuggestOutput{Titles: titles, Meta: meta}, nil
+}
+
+// normalizeChapterTitles 去除引号/书名号与“第X章”前缀，丢弃空、过长与重复标题，最多保留 limit 个
+func normalizeChapterTitles(in []string, limit int) []string {
+	out := make([]string, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for _, t := range in {
+		t = strings.Trim(strings.TrimSpace(t), "\"'“”‘’《》「」『』")
+		if rest, ok := strings.CutPrefix(t, "第"); ok {
+			if i := strings.Index(rest, "章"); i >= 0 && i <= 12 {
+				t = strings.TrimLeft(strings.TrimSpace(rest[i+len("章"):]), ":：、 ")
+			}
+		}
+		t = strings.TrimSpace(t)
+		if t == "" || utf8.RuneCountInString(t) > maxChapterTitleRunes || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+func buildChapterTitleModelOptions(in *wfmodel.ChapterTitleSuggestInput, enableSchema bool) []model.Option {
+	opts := make([]model.Option, 0, 4)
+	if in.Temperature != nil {
+		opts = append(opts, model.WithTemperature(*in.Temperature))
+	}
+	if in.MaxTokens != nil {
+		opts = append(opts, model.WithMaxTokens(*in.MaxTokens))
+	}
+	if strings.TrimSpace(in.Model) != "" {
+		opts = append(opts, model.WithModel(strings.TrimSpace(in.Model)))
+	}
+	if enableSchema {
+		opts = append(opts, openaiopts.WithExtraFields(map[string]any{
+			"response_format": map[string]any{
+				"type": "json_schema",
+				"json_schema": map[string]any{
+					"name":   "chapter_title_suggest",
+					"strict": false,
+					"schema": map[string]any{
+						"type":                 "object",
+						"additionalProperties": false,
+						"required":             []any{"titles"},
+						"properties": map[string]any{
+							"titles": map[string]any{
+								"type":     "array",
+								"maxItems": in.Count,
+								"items":    map[string]any{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		}))
+	}
+	return opts
+}
@@ -0,0 +1,88 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+
+	llmctx "z-novel-ai-api/internal/domain/service"
+	wfnode "z-novel-ai-api/internal/workflow/node"
+	workflowport "z-novel-ai-api/internal/workflow/port"
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
+)
+
+// RerankChain 由模型为检索候选片段打分（检索精排的 llm 实现，满足 retrieval.Reranker）
+type RerankChain struct {
+	factory  workflowport.ChatModelFactory
+	provider string
+	model    string
+}
+
+// NewRerankChain 创建精排链；model 为空时使用提供商的默认模型
+func NewRerankChain(factory workflowport.ChatModelFactory, provider, model string) *RerankChain {
+	return &RerankChain{factory: factory, provider: strings.TrimSpace(provider), model: strings.TrimSpace(model)}
+}
+
+// Rerank 一次请求为全部片段打分（输出 {"scores": [...]}）；缺失的分数记为 0，超出片段数的忽略
+func (c *RerankChain) Rerank(ctx context.Context, query string, docs []string) ([]float64, error) {
+	if c == nil || c.factory == nil {
+		return nil, fmt.Errorf("llm factory not configured")
+	}
+	if c.provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	tpl, err := defaultPromptRegistry.ChatTemplate(workflowprompt.PromptRetrievalRerankV1)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for i, doc := range docs {
+		fmt.Fprintf(&sb, "[%d] %s\n\n", i, strings.TrimSpace(doc))
+	}
+	msgs, err := tpl.Format(ctx, map[string]any{
+		"query":     strings.TrimSpace(query),
+		"count":     len(docs),
+		"documents": strings.TrimSpace(sb.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = llmctx.WithWorkflowProvider(ctx, "retrieval_rerank", c.provider)
+	chatModel, err := c.factory.Get(ctx, c.provider)
+	if err != nil {
+		return nil, err
+	}
+	opts := []model.Option{model.WithTemperature(0)}
+	if c.model != "" {
+		opts = append(opts, model.WithModel(c.model))
+	}
+	outMsg, err := chatModel.Generate(ctx, msgs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if outMsg == nil {
+		return nil, fmt.Errorf("empty llm response")
+	}
+
+	raw := wfnode.ExtractJSONObject(outMsg.Content)
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("empty rerank output")
+	}
+	var parsed struct {
+		Scores []float64 `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank json: %w", err)
+	}
+	scores := make([]float64, len(docs))
+	copy(scores, parsed.Scores)
+	return scores, nil
+}
@@ -0,0 +1,45 @@
+package chain
+
+import (
+	"context"
+	"errors"
+
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
+)
+
+// Warmup 预先解析章节生成与标题建议的提示词模板
+func (c *ChapterChain) Warmup(_ context.Context) error {
+	if c == nil {
+		return errors.New("chapter chain not configured")
+	}
+	for _, id := range []workflowprompt.PromptID{workflowprompt.PromptChapterGenV1, workflowprompt.PromptChapterTitleV1} {
+		if _, err := chapterPromptRegistry.ChatTemplate(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Warmup 预先编译设定集生成链
+func (c *FoundationChain) Warmup(_ context.Context) error {
+	if c == nil {
+		return errors.New("foundation chain not configured")
+	}
+	if _, err := c.getChain(); err != nil {
+		return err
+	}
+	_, err := defaultPromptRegistry.ChatTemplate(workflowprompt.PromptFoundationPlanV1)
+	return err
+}
+
+// Warmup 预先编译项目创建对话链
+func (c *ProjectCreationChain) Warmup(_ context.Context) error {
+	if c == nil {
+		return errors.New("project creation chain not configured")
+	}
+	if _, err := c.getChain(); err != nil {
+		return err
+	}
+	_, err := defaultPromptRegistry.ChatTemplate(workflowprompt.PromptProjectCreationV1)
+	return err
+}
@@ -17,6 +17,8 @@ type ArtifactGenerateInput struct {
 	ProjectDescription string
 
 	Type entity.ArtifactType
+	// BranchKey 生成目标分支（what-if 时间线）；工具检索读取该分支记忆，未覆盖的内容回退主线
+	BranchKey string
 
 	Prompt      string
 	Attachments []TextAttachment
@@ -43,4 +45,11 @@ type ArtifactGenerateOutput struct {
 	ModelRaw string
 	Mode     string
 	Meta     LLMUsageMeta
+
+	// RepairRounds 校验失败后经修复节点重试的轮数（0 表示一次通过）
+	RepairRounds int
+	// FallbackUsed json_patch 模式修复耗尽后回退为全量输出
+	FallbackUsed bool
+	// GraphPath 图节点访问路径（如 init>model>validate>finalize），与追踪属性 artifact.graph_path 一致
+	GraphPath string
 }
@@ -9,6 +9,10 @@ type ChapterGenerateInput struct {
 
 	RetrievedContext string
 
+	// ActiveWorldview / ActiveCharacters 激活构件版本的设定摘录（已按预算裁剪，可能为空）
+	ActiveWorldview  string
+	ActiveCharacters string
+
 	TargetWordCount int
 	WritingStyle    string
 	POV             string
@@ -18,6 +22,18 @@ type ChapterGenerateInput struct {
 
 	Temperature *float32
 	MaxTokens   *int
+
+	// ContinueFrom 非空时为续写：已生成的部分正文作为助手消息，模型从其末尾接着写（不重复已有内容）
+	ContinueFrom string
+
+	// PromptOverride 非空时替换内置模板文本（仅用于回放沙箱）
+	PromptOverride *PromptTemplateOverride
+}
+
+// PromptTemplateOverride Prompt 模板文本覆盖；为空的部分沿用内置模板
+type PromptTemplateOverride struct {
+	System string
+	User   string
 }
 
 type ChapterGenerateOutput struct {
@@ -0,0 +1,26 @@
+package model
+
+// ChapterTitleSuggestInput 章节标题建议输入（正文已由调用方按预算截取）
+type ChapterTitleSuggestInput struct {
+	ProjectTitle string
+	ProjectGenre string
+
+	SeqNum         int
+	CurrentTitle   string
+	ChapterOutline string
+	ChapterContent string
+
+	// Count 期望的建议数量（3-5）
+	Count int
+
+	Provider string
+	Model    string
+
+	Temperature *float32
+	MaxTokens   *int
+}
+
+type ChapterTitleSuggestOutput struct {
+	Titles []string
+	Meta   LLMUsageMeta
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type TextAttachment struct {
 	Name    string `json:"name"`
@@ -15,3 +18,41 @@ type LLMUsageMeta struct {
 	Temperature      float64
 	GeneratedAt      time.Time
 }
+
+const (
+	// AttachmentMaxRunes 单个附件注入 Prompt 的字数上限
+	AttachmentMaxRunes = 12000
+	// AttachmentsMaxRunes 全部附件注入 Prompt 的总字数上限（按顺序分配，靠后的附件可能被截断或整体丢弃）
+	AttachmentsMaxRunes = 24000
+)
+
+// AttachmentTruncation 附件截断信息
+type AttachmentTruncation struct {
+	Name      string
+	Runes     int // 原始字数
+	KeptRunes int // 实际注入 Prompt 的字数（0 表示整体丢弃）
+}
+
+// LimitAttachments 按单个/总字数上限裁剪附件（跳过空附件），返回裁剪后的附件及被截断的附件列表
+func LimitAttachments(attachments []TextAttachment) ([]TextAttachment, []AttachmentTruncation) {
+	out := make([]TextAttachment, 0, len(attachments))
+	var truncated []AttachmentTruncation
+	remaining := AttachmentsMaxRunes
+	for _, a := range attachments {
+		content := strings.TrimSpace(a.Content)
+		if content == "" {
+			continue
+		}
+		runes := []rune(content)
+		keep := min(len(runes), AttachmentMaxRunes, remaining)
+		if keep < len(runes) {
+			truncated = append(truncated, AttachmentTruncation{Name: a.Name, Runes: len(runes), KeptRunes: keep})
+		}
+		if keep == 0 {
+			continue
+		}
+		remaining -= keep
+		out = append(out, TextAttachment{Name: a.Name, Content: string(runes[:keep])})
+	}
+	return out, truncated
+}
@@ -13,7 +13,7 @@ type ArtifactValidator interface {
 }
 
 type ArtifactJSONPatcher interface {
-	IsEnabled(in *wfmodel.ArtifactGenerateInput) bool
+	IsEnabled(ctx context.Context, in *wfmodel.ArtifactGenerateInput) bool
 	AllowedOps() []string
 	AllowedPaths(t entity.ArtifactType) []string
 	Apply(ctx context.Context, t entity.ArtifactType, base json.RawMessage, patchText string) (json.RawMessage, error)
@@ -7,6 +7,8 @@ import (
 )
 
 func BuildAttachmentsBlock(attachments []wfmodel.TextAttachment) string {
+	// 超出字数上限的附件在此统一截断；需要告知用户时由调用方通过 LimitAttachments 获取截断信息
+	attachments, _ = wfmodel.LimitAttachments(attachments)
 	if len(attachments) == 0 {
 		return ""
 	}
@@ -13,6 +13,9 @@ import (
 	einotool "github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	appretrieval "z-novel-ai-api/internal/application/retrieval"
 	"z-novel-ai-api/internal/domain/entity"
@@ -67,7 +70,28 @@ func (g *ArtifactPipeline) Generate(ctx context.Context, in *wfmodel.ArtifactGen
 	if err != nil {
 		return nil, err
 	}
-	return graph.Invoke(ctx, in, compose.WithRuntimeMaxSteps(20))
+
+	// 各节点在 artifact.generate 下创建子 Span；执行路径汇总写入本 Span 与调用方（任务/请求）Span
+	parent := trace.SpanFromContext(ctx)
+	ctx, graphTrace := withArtifactGraphTrace(ctx)
+	ctx, span := artifactTracer.Start(ctx, "artifact.generate", trace.WithAttributes(
+		attribute.String("artifact.type", string(in.Type)),
+		attribute.String("llm.provider", in.Provider),
+		attribute.String("llm.model", pickArtifactModel(in)),
+	))
+	defer span.End()
+
+	out, err := graph.Invoke(ctx, in, compose.WithRuntimeMaxSteps(20))
+	attrs := graphTrace.attributes()
+	span.SetAttributes(attrs...)
+	parent.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "artifact generation failed")
+		return nil, err
+	}
+	out.GraphPath = graphTrace.graphPath()
+	return out, nil
 }
 
 func formatArtifactMessages(ctx context.Context, in *wfmodel.ArtifactGenerateInput) ([]*schema.Message, error) {
@@ -220,7 +244,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 	//    2. 初始化可用的工具列表 (Tool Set)，如搜索、查询项目简报等。
 	//    3. 绑定工具到 ChatModel：如果模型支持工具调用 (Function Calling)，将工具信息注入模型配置。
 	//    4. 创建 artifactReActState 状态对象，作为图在节点间传递的上下文。
-	if err := graph.AddLambdaNode("init", compose.InvokableLambda(func(ctx context.Context, in *wfmodel.ArtifactGenerateInput) (*artifactReActState, error) {
+	if err := graph.AddLambdaNode("init", compose.InvokableLambda(tracedArtifactNode("init", func(ctx context.Context, in *wfmodel.ArtifactGenerateInput) (*artifactReActState, error) {
 		if in == nil {
 			return nil, fmt.Errorf("input is nil")
 		}
@@ -235,7 +259,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 
 		mode := artifactOutputModeFull
 		var patchMsgs []*schema.Message
-		if g != nil && g.patcher != nil && g.patcher.IsEnabled(in) {
+		if g != nil && g.patcher != nil && g.patcher.IsEnabled(ctx, in) {
 			patchMsgs, err = g.formatArtifactPatchMessages(ctx, in)
 			if err != nil {
 				return nil, err
@@ -293,7 +317,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 			MaxRepairRounds: wfmodel.DefaultMaxRepairRounds,
 			Mode:            mode,
 		}, nil
-	}), compose.WithNodeName("artifact.init")); err != nil {
+	})), compose.WithNodeName("artifact.init")); err != nil {
 		return nil, err
 	}
 
@@ -306,10 +330,14 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 	//    2. 降级策略 A (工具不支持)：如果 Provider 报错不支持工具，回退到基础模型 (BaseModel) 重试。
 	//    3. 降级策略 B (Schema 不支持)：如果 Provider 报错不支持 JSON Schema，回退到普通 Prompt 模式重试。
 	// 输出：更新状态中的 Messages 列表（追加 Assistant 的回复）。
-	if err := graph.AddLambdaNode("model", compose.InvokableLambda(func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
+	if err := graph.AddLambdaNode("model", compose.InvokableLambda(tracedArtifactNode("model", func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
 		if st == nil || st.In == nil || st.ChatModel == nil {
 			return nil, fmt.Errorf("state is nil")
 		}
+		// 任务已取消或超时：不再发起新一轮模型调用（工具轮次与修复轮次都会回到此节点）
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		ctx = llmctx.WithWorkflowProvider(ctx, "artifact_generate", st.In.Provider)
 
 		// 尝试生成
@@ -324,6 +352,8 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 				"error", err.Error(),
 			)
 			st.ChatModel = st.BaseModel
+			artifactGraphTraceFrom(ctx).update(func(t *artifactGraphTrace) { t.toolsFallback = true })
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("artifact.tools_fallback", true))
 			outMsg, err = st.ChatModel.Generate(ctx, st.Messages, g.buildArtifactModelOptions(st.In, true, st.Mode)...)
 		}
 
@@ -335,6 +365,8 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 				"artifact_type", string(st.In.Type),
 				"error", err.Error(),
 			)
+			artifactGraphTraceFrom(ctx).update(func(t *artifactGraphTrace) { t.schemaFallback = true })
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("artifact.schema_fallback", true))
 			outMsg, err = st.ChatModel.Generate(ctx, st.Messages, g.buildArtifactModelOptions(st.In, false, st.Mode)...)
 		}
 		if err != nil {
@@ -347,7 +379,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 		st.LastAssistant = outMsg
 		st.Messages = append(st.Messages, outMsg)
 		return st, nil
-	}), compose.WithNodeName("artifact.model")); err != nil {
+	})), compose.WithNodeName("artifact.model")); err != nil {
 		return nil, err
 	}
 
@@ -359,7 +391,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 	//    1. 使用 Eino 标准的 ToolsNode 来解析并执行工具调用。
 	//    2. 将工具执行结果 (ToolMessage) 追加到 Messages 列表中。
 	//    3. 增加轮数计数器 (ToolRounds) 以防止无限循环。
-	if err := graph.AddLambdaNode("tools", compose.InvokableLambda(func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
+	if err := graph.AddLambdaNode("tools", compose.InvokableLambda(tracedArtifactNode("tools", func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
 		if st == nil || st.LastAssistant == nil {
 			return nil, fmt.Errorf("state is nil")
 		}
@@ -369,16 +401,23 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 		if st.ToolRounds >= st.MaxToolRounds {
 			return nil, fmt.Errorf("too many tool rounds")
 		}
+		// 工具轮次前后检查取消：检索类工具可能耗时较长，结束后不再把结果送回模型
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
 		ctx = llmctx.WithWorkflowProvider(ctx, "artifact_generate", st.In.Provider)
 		outMsgs, err := toolsNode.Invoke(ctx, st.LastAssistant, compose.WithToolList(st.Tools...))
 		if err != nil {
 			return nil, err
 		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		st.Messages = append(st.Messages, outMsgs...)
 		st.ToolRounds++
 		return st, nil
-	}), compose.WithNodeName("artifact.tools")); err != nil {
+	})), compose.WithNodeName("artifact.tools")); err != nil {
 		return nil, err
 	}
 
@@ -390,7 +429,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 	//    1. 提取 JSON 内容。
 	//    2. 校验并规范化生成的 Artifact 内容 (normalizeAndValidateArtifact)。
 	//    3. 将结果写入状态，供 Repair / Finalize 使用。
-	if err := graph.AddLambdaNode("validate", compose.InvokableLambda(func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
+	if err := graph.AddLambdaNode("validate", compose.InvokableLambda(tracedArtifactNode("validate", func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
 		if st == nil || st.In == nil || st.LastAssistant == nil {
 			return nil, fmt.Errorf("state is nil")
 		}
@@ -437,7 +476,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 			st.ValidatedContent = content
 		}
 		return st, nil
-	}), compose.WithNodeName("artifact.validate")); err != nil {
+	})), compose.WithNodeName("artifact.validate")); err != nil {
 		return nil, err
 	}
 
@@ -446,7 +485,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 	// ---------------------------------------------------------------------
 	// 作用：当解析/校验失败时，向 Messages 追加修复指令并回到 model 重试。
 	// 约束：最多修复 MaxRepairRounds 次，避免死循环与成本失控。
-	if err := graph.AddLambdaNode("repair", compose.InvokableLambda(func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
+	if err := graph.AddLambdaNode("repair", compose.InvokableLambda(tracedArtifactNode("repair", func(ctx context.Context, st *artifactReActState) (*artifactReActState, error) {
 		if st == nil || st.In == nil || st.LastAssistant == nil {
 			return nil, fmt.Errorf("state is nil")
 		}
@@ -461,14 +500,14 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 		st.Messages = append(st.Messages, schema.UserMessage(repairMsg))
 		st.RepairRounds++
 		return st, nil
-	}), compose.WithNodeName("artifact.repair")); err != nil {
+	})), compose.WithNodeName("artifact.repair")); err != nil {
 		return nil, err
 	}
 
 	// ---------------------------------------------------------------------
 	// 6. Finalize: 结果封装节点
 	// ---------------------------------------------------------------------
-	if err := graph.AddLambdaNode("finalize", compose.InvokableLambda(func(ctx context.Context, st *artifactReActState) (*wfmodel.ArtifactGenerateOutput, error) {
+	if err := graph.AddLambdaNode("finalize", compose.InvokableLambda(tracedArtifactNode("finalize", func(ctx context.Context, st *artifactReActState) (*wfmodel.ArtifactGenerateOutput, error) {
 		if st == nil || st.In == nil || st.LastAssistant == nil {
 			return nil, fmt.Errorf("state is nil")
 		}
@@ -511,8 +550,11 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 			ModelRaw: modelRaw,
 			Mode:     string(st.Mode),
 			Meta:     meta,
+
+			RepairRounds: st.RepairRounds,
+			FallbackUsed: st.FallbackUsed,
 		}, nil
-	}), compose.WithNodeName("artifact.finalize")); err != nil {
+	})), compose.WithNodeName("artifact.finalize")); err != nil {
 		return nil, err
 	}
 
@@ -567,6 +609,7 @@ func (g *ArtifactPipeline) buildGraph(ctx context.Context) (compose.Runnable[*wf
 		}
 		// Patch 模式修复耗尽后，自动回退到“全量 JSON 输出”再尝试一次，避免增量模式放大失败率。
 		if st.Mode == artifactOutputModeJSONPatch && !st.FallbackUsed && st.RepairRounds >= st.MaxRepairRounds && len(st.FullMessages) > 0 {
+			markArtifactFallback(ctx, st)
 			st.FallbackUsed = true
 			st.Mode = artifactOutputModeFull
 			st.Messages = cloneMessages(st.FullMessages)
@@ -163,6 +163,7 @@ func (t *artifactSearchTool) InvokableRun(ctx context.Context, argumentsInJSON s
 			Query:           q,
 			TopK:            topK,
 			SegmentTypes:    segmentTypes,
+			BranchKey:       strings.TrimSpace(t.in.BranchKey),
 			IncludeEntities: false,
 		})
 		if err != nil {
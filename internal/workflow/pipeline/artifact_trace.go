@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	wfnode "z-novel-ai-api/internal/workflow/node"
+)
+
+var artifactTracer = otel.Tracer("workflow.artifact")
+
+// artifactValidationErrorRunes 校验错误摘要写入 Span 的最大长度
+const artifactValidationErrorRunes = 200
+
+// artifactGraphTrace 单次图执行的路径记录（经 context 传给各节点与分支），
+// 汇总为 artifact.graph_path 等属性，用于统计修复与回退分支的触发频率
+type artifactGraphTrace struct {
+	mu                 sync.Mutex
+	path               []string
+	visits             map[string]int
+	initialMode        artifactOutputMode
+	fallbackUsed       bool
+	toolsFallback      bool
+	schemaFallback     bool
+	validationFailures int
+}
+
+type artifactGraphTraceKey struct{}
+
+func withArtifactGraphTrace(ctx context.Context) (context.Context, *artifactGraphTrace) {
+	t := &artifactGraphTrace{visits: make(map[string]int)}
+	return context.WithValue(ctx, artifactGraphTraceKey{}, t), t
+}
+
+// artifactGraphTraceFrom 取当前图执行的路径记录；未经 Generate 调用时返回 nil（方法均可安全调用）
+func artifactGraphTraceFrom(ctx context.Context) *artifactGraphTrace {
+	t, _ := ctx.Value(artifactGraphTraceKey{}).(*artifactGraphTrace)
+	return t
+}
+
+// enter 记录进入节点，返回该节点在本次执行中的第几次访问（从 1 开始）
+func (t *artifactGraphTrace) enter(node string) int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.path = append(t.path, node)
+	t.visits[node]++
+	return t.visits[node]
+}
+
+func (t *artifactGraphTrace) update(fn func(t *artifactGraphTrace)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(t)
+}
+
+// graphPath 节点访问路径，如 init>model>tools>model>validate>repair>model>validate>finalize；
+// patch 模式回退为全量输出时插入 fallback
+func (t *artifactGraphTrace) graphPath() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.Join(t.path, ">")
+}
+
+func (t *artifactGraphTrace) attributes() []attribute.KeyValue {
+	if t == nil {
+		return nil
+	}
+	path := t.graphPath()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return []attribute.KeyValue{
+		attribute.String("artifact.graph_path", path),
+		attribute.String("artifact.initial_mode", string(t.initialMode)),
+		attribute.Int("artifact.model_calls", t.visits["model"]),
+		attribute.Int("artifact.tool_rounds", t.visits["tools"]),
+		attribute.Int("artifact.repair_rounds", t.visits["repair"]),
+		attribute.Int("artifact.validation_failures", t.validationFailures),
+		attribute.Bool("artifact.fallback_used", t.fallbackUsed),
+		attribute.Bool("artifact.tools_fallback", t.toolsFallback),
+		attribute.Bool("artifact.schema_fallback", t.schemaFallback),
+	}
+}
+
+// markArtifactFallback patch 模式修复耗尽、回退为全量输出（在 validate 分支中调用，事件记在 artifact.generate Span 上）
+func markArtifactFallback(ctx context.Context, st *artifactReActState) {
+	t := artifactGraphTraceFrom(ctx)
+	t.update(func(t *artifactGraphTrace) {
+		t.fallbackUsed = true
+		t.path = append(t.path, "fallback")
+	})
+	trace.SpanFromContext(ctx).AddEvent("artifact.fallback_full", trace.WithAttributes(
+		attribute.String("artifact.validation_error", summarizeArtifactValidationError(st.ValidateErr)),
+	))
+}
+
+// tracedArtifactNode 为图节点包一层子 Span（artifact.<node>），记录访问轮次、输出模式与各轮计数；
+// 节点内可经 trace.SpanFromContext(ctx) 追加节点特有的属性
+func tracedArtifactNode[I, O any](node string, fn func(context.Context, I) (O, error)) func(context.Context, I) (O, error) {
+	return func(ctx context.Context, in I) (O, error) {
+		t := artifactGraphTraceFrom(ctx)
+		ctx, span := artifactTracer.Start(ctx, "artifact."+node, trace.WithAttributes(
+			attribute.String("artifact.node", node),
+			attribute.Int("artifact.node_round", t.enter(node)),
+		))
+		defer span.End()
+
+		out, err := fn(ctx, in)
+		if st, ok := any(out).(*artifactReActState); ok && st != nil {
+			annotateArtifactState(span, t, node, st)
+		} else if st, ok := any(in).(*artifactReActState); ok && st != nil {
+			annotateArtifactState(span, t, node, st)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "artifact."+node+" failed")
+		}
+		return out, err
+	}
+}
+
+func annotateArtifactState(span trace.Span, t *artifactGraphTrace, node string, st *artifactReActState) {
+	span.SetAttributes(
+		attribute.String("artifact.mode", string(st.Mode)),
+		attribute.Int("artifact.tool_round", st.ToolRounds),
+		attribute.Int("artifact.repair_round", st.RepairRounds),
+		attribute.Bool("artifact.fallback_used", st.FallbackUsed),
+	)
+	switch node {
+	case "init":
+		t.update(func(t *artifactGraphTrace) { t.initialMode = st.Mode })
+		span.SetAttributes(attribute.Int("artifact.tools", len(st.Tools)))
+	case "model":
+		if st.LastAssistant != nil {
+			span.SetAttributes(attribute.Int("artifact.tool_calls", len(st.LastAssistant.ToolCalls)))
+		}
+	case "validate":
+		span.SetAttributes(attribute.Bool("artifact.validation_ok", st.ValidateErr == nil))
+		if st.ValidateErr != nil {
+			t.update(func(t *artifactGraphTrace) { t.validationFailures++ })
+			span.SetAttributes(attribute.String("artifact.validation_error", summarizeArtifactValidationError(st.ValidateErr)))
+		}
+	}
+}
+
+// summarizeArtifactValidationError 截断校验错误（错误可能回显模型输出片段，导出前另经统一脱敏）
+func summarizeArtifactValidationError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return wfnode.TruncateByRunes(strings.TrimSpace(err.Error()), artifactValidationErrorRunes)
+}
@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	workflowprompt "z-novel-ai-api/internal/workflow/prompt"
+)
+
+// Warmup 预先编译构件生成图、创建工具节点并解析提示词模板，避免首个请求承担编译开销
+func (g *ArtifactPipeline) Warmup(_ context.Context) error {
+	if g == nil {
+		return errors.New("artifact pipeline not configured")
+	}
+	if _, err := g.getGraph(); err != nil {
+		return err
+	}
+	if _, err := g.getToolsNode(); err != nil {
+		return err
+	}
+	for _, id := range []workflowprompt.PromptID{
+		workflowprompt.PromptArtifactV2,
+		workflowprompt.PromptArtifactPatchV1,
+		workflowprompt.PromptArtifactConflictScanV1,
+	} {
+		if _, err := defaultPromptRegistry.ChatTemplate(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package prompt
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"sync"
@@ -23,6 +25,8 @@ const (
 	PromptArtifactPatchV1        PromptID = "artifact_patch_v1"
 	PromptArtifactConflictScanV1 PromptID = "artifact_conflict_scan_v1"
 	PromptProjectCreationV1      PromptID = "project_creation_v1"
+	PromptChapterTitleV1         PromptID = "chapter_title_v1"
+	PromptRetrievalRerankV1      PromptID = "retrieval_rerank_v1"
 )
 
 type Registry struct {
@@ -54,26 +58,53 @@ func (r *Registry) ChatTemplate(id PromptID) (einoprompt.ChatTemplate, error) {
 		return tpl, nil
 	}
 
-	systemPath, userPath, err := resolvePromptFiles(id)
-	if err != nil {
-		return nil, err
-	}
-	system, err := readEmbeddedText(systemPath)
-	if err != nil {
-		return nil, err
-	}
-	user, err := readEmbeddedText(userPath)
+	system, user, err := TemplateTexts(id)
 	if err != nil {
 		return nil, err
 	}
 
-	tpl := einoprompt.FromMessages(
+	tpl := NewChatTemplate(system, user)
+	r.cache[id] = tpl
+	return tpl, nil
+}
+
+// NewChatTemplate 以给定的系统/用户提示文本创建模板（FString 变量语法，与内置模板一致）
+func NewChatTemplate(system, user string) einoprompt.ChatTemplate {
+	return einoprompt.FromMessages(
 		schema.FString,
 		schema.SystemMessage(system),
 		schema.UserMessage(user),
 	)
-	r.cache[id] = tpl
-	return tpl, nil
+}
+
+// TemplateTexts 返回内置模板的系统/用户提示文本
+func TemplateTexts(id PromptID) (system string, user string, err error) {
+	systemPath, userPath, err := resolvePromptFiles(id)
+	if err != nil {
+		return "", "", err
+	}
+	if system, err = readEmbeddedText(systemPath); err != nil {
+		return "", "", err
+	}
+	if user, err = readEmbeddedText(userPath); err != nil {
+		return "", "", err
+	}
+	return system, user, nil
+}
+
+// TemplateDigest 内置模板的内容摘要，用于识别模板版本变化
+func TemplateDigest(id PromptID) (string, error) {
+	system, user, err := TemplateTexts(id)
+	if err != nil {
+		return "", err
+	}
+	return Digest(system, user), nil
+}
+
+// Digest 计算系统/用户提示文本的摘要（sha256 前 12 位十六进制）
+func Digest(system, user string) string {
+	sum := sha256.Sum256([]byte(system + "\x00" + user))
+	return hex.EncodeToString(sum[:])[:12]
 }
 
 func resolvePromptFiles(id PromptID) (systemFile string, userFile string, err error) {
@@ -92,6 +123,10 @@ func resolvePromptFiles(id PromptID) (systemFile string, userFile string, err er
 		return "templates/artifact_conflict_scan_v1.system.txt", "templates/artifact_conflict_scan_v1.user.txt", nil
 	case PromptProjectCreationV1:
 		return "templates/project_creation_v1.system.txt", "templates/project_creation_v1.user.txt", nil
+	case PromptChapterTitleV1:
+		return "templates/chapter_title_v1.system.txt", "templates/chapter_title_v1.user.txt", nil
+	case PromptRetrievalRerankV1:
+		return "templates/retrieval_rerank_v1.system.txt", "templates/retrieval_rerank_v1.user.txt", nil
 	default:
 		return "", "", fmt.Errorf("unknown prompt id: %s", id)
 	}
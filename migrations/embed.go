@@ -0,0 +1,19 @@
+// Package migrations 嵌入数据库迁移 SQL，供 cmd/migrate 与启动时自动迁移使用
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql
+var postgresFS embed.FS
+
+// Postgres 返回 PostgreSQL 迁移文件（根目录即 migrations/postgres）
+func Postgres() fs.FS {
+	sub, err := fs.Sub(postgresFS, "postgres")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
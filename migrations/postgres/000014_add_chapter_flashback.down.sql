@@ -0,0 +1,5 @@
+-- 000014_add_chapter_flashback.down.sql
+-- 回滚章节“倒叙/闪回”标记
+
+ALTER TABLE chapters
+    DROP COLUMN IF EXISTS is_flashback;
@@ -0,0 +1,5 @@
+-- 000014_add_chapter_flashback.up.sql
+-- 为章节增加“倒叙/闪回”标记（用于豁免故事时间单调性校验）
+
+ALTER TABLE chapters
+    ADD COLUMN IF NOT EXISTS is_flashback BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- 000015_add_chapter_pov.down.sql
+-- 回滚章节 POV 角色
+
+DROP INDEX IF EXISTS idx_chapters_pov_entity;
+
+ALTER TABLE chapters
+    DROP COLUMN IF EXISTS pov_entity_id;
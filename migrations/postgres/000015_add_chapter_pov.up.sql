@@ -0,0 +1,7 @@
+-- 000015_add_chapter_pov.up.sql
+-- 为章节增加 POV 角色（多视角小说：按 POV 隔离检索上下文与写作风格）
+
+ALTER TABLE chapters
+    ADD COLUMN IF NOT EXISTS pov_entity_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_chapters_pov_entity ON chapters (pov_entity_id);
@@ -0,0 +1,10 @@
+-- 000016_create_series.down.sql
+-- 回滚系列表
+
+DROP INDEX IF EXISTS idx_projects_series;
+
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS series_order,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS series CASCADE;
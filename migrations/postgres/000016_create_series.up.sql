@@ -0,0 +1,51 @@
+-- 000016_create_series.up.sql
+-- 创建系列表（多部曲），项目可归属系列并按 series_order 排序
+
+CREATE TABLE IF NOT EXISTS series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    owner_id UUID REFERENCES users (id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_series_tenant ON series (tenant_id);
+
+CREATE INDEX IF NOT EXISTS idx_series_owner ON series (owner_id);
+
+CREATE TRIGGER update_series_updated_at
+    BEFORE UPDATE ON series
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES series (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS series_order INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_projects_series ON projects (series_id, series_order);
+
+-- 启用 RLS（直接按 tenant_id 隔离）
+ALTER TABLE series ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON series FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON series FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON series FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON series FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
@@ -0,0 +1,7 @@
+-- 000017_add_chapter_autosave.down.sql
+-- 回滚章节自动保存字段
+
+ALTER TABLE chapters
+    DROP COLUMN IF EXISTS last_edited_at,
+    DROP COLUMN IF EXISTS last_edited_by,
+    DROP COLUMN IF EXISTS draft_dirty;
@@ -0,0 +1,7 @@
+-- 000017_add_chapter_autosave.up.sql
+-- 章节自动保存：草稿脏标记与最后编辑者信息
+
+ALTER TABLE chapters
+    ADD COLUMN IF NOT EXISTS draft_dirty BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_edited_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS last_edited_at TIMESTAMPTZ;
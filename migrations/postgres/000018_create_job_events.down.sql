@@ -0,0 +1,4 @@
+-- 000018_create_job_events.down.sql
+-- 回滚任务时间线事件表
+
+DROP TABLE IF EXISTS job_events CASCADE;
@@ -0,0 +1,34 @@
+-- 000018_create_job_events.up.sql
+-- 创建任务时间线事件表（排队/领取/召回/调用模型/校验/修复/完成等），供 UI 展示生成进度
+
+CREATE TABLE IF NOT EXISTS job_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES generation_jobs (id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    message TEXT,
+    data JSONB DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_tenant ON job_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_job_events_job_created ON job_events (job_id, created_at);
+
+-- 启用 RLS
+ALTER TABLE job_events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON job_events FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON job_events FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_delete ON job_events FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
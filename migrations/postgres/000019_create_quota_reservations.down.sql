@@ -0,0 +1,4 @@
+-- 000019_create_quota_reservations.down.sql
+-- 回滚 Token 配额预留表
+
+DROP TABLE IF EXISTS quota_reservations CASCADE;
@@ -0,0 +1,44 @@
+-- 000019_create_quota_reservations.up.sql
+-- 创建 Token 配额预留表：入队时按预估用量预留，完成时按实际用量结算，失败/取消时释放
+
+CREATE TABLE IF NOT EXISTS quota_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES generation_jobs (id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL,
+    settled_tokens BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'reserved',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT uq_quota_reservations_job UNIQUE (job_id)
+);
+
+-- 汇总有效预留（status = reserved 且未过期）
+CREATE INDEX IF NOT EXISTS idx_quota_reservations_tenant_active ON quota_reservations (tenant_id, expires_at)
+WHERE
+    status = 'reserved';
+
+-- 启用 RLS
+ALTER TABLE quota_reservations ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON quota_reservations FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON quota_reservations FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON quota_reservations FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON quota_reservations FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
@@ -0,0 +1,11 @@
+-- 000020_create_plans.down.sql
+-- 回滚订阅套餐表及租户套餐字段
+
+DROP INDEX IF EXISTS idx_tenants_quota_period_end;
+
+ALTER TABLE tenants
+DROP COLUMN IF EXISTS quota_period_end,
+DROP COLUMN IF EXISTS quota_period_start,
+DROP COLUMN IF EXISTS plan_id;
+
+DROP TABLE IF EXISTS plans CASCADE;
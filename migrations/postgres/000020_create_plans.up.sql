@@ -0,0 +1,75 @@
+-- 000020_create_plans.up.sql
+-- 创建订阅套餐表（月度 Token 额度/并发上限/限流/功能开关），租户关联套餐并记录当前配额周期
+
+CREATE TABLE IF NOT EXISTS plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    monthly_tokens BIGINT NOT NULL DEFAULT 0,
+    max_concurrent_jobs INT NOT NULL DEFAULT 0,
+    requests_per_second INT NOT NULL DEFAULT 0,
+    features JSONB DEFAULT '{}'::jsonb,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 内置套餐（0 表示不限制 / 使用全局限流配置）
+INSERT INTO
+    plans (
+        code,
+        name,
+        monthly_tokens,
+        max_concurrent_jobs,
+        requests_per_second,
+        features
+    )
+VALUES (
+        'free',
+        'Free',
+        1000000,
+        2,
+        20,
+        '{"chapter_stream": true, "foundation_generate": true}'::jsonb
+    ),
+    (
+        'pro',
+        'Pro',
+        10000000,
+        5,
+        100,
+        '{"chapter_stream": true, "foundation_generate": true}'::jsonb
+    ),
+    (
+        'enterprise',
+        'Enterprise',
+        100000000,
+        0,
+        0,
+        '{"chapter_stream": true, "foundation_generate": true}'::jsonb
+    )
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE tenants
+ADD COLUMN IF NOT EXISTS plan_id UUID REFERENCES plans (id),
+ADD COLUMN IF NOT EXISTS quota_period_start TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS quota_period_end TIMESTAMPTZ;
+
+-- 到期扫描：按周期结束时间查找需要重置的租户
+CREATE INDEX IF NOT EXISTS idx_tenants_quota_period_end ON tenants (quota_period_end)
+WHERE
+    plan_id IS NOT NULL;
+
+-- 存量租户归入 free 套餐，配额周期按自然月对齐（保留现有余额）
+UPDATE tenants
+SET
+    plan_id = (
+        SELECT id
+        FROM plans
+        WHERE
+            code = 'free'
+    ),
+    quota_period_start = date_trunc('month', NOW()),
+    quota_period_end = date_trunc('month', NOW()) + INTERVAL '1 month'
+WHERE
+    plan_id IS NULL;
@@ -0,0 +1,4 @@
+-- 000021_create_invoices.down.sql
+-- 回滚账单表
+
+DROP TABLE IF EXISTS invoices CASCADE;
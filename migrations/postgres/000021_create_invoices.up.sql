@@ -0,0 +1,35 @@
+-- 000021_create_invoices.up.sql
+-- 创建账单表：记录支付提供商回调确认的 Token 充值，(provider, external_id) 唯一保证重复回调幂等
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    provider VARCHAR(32) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255),
+    amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(8) NOT NULL DEFAULT '',
+    tokens BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'paid',
+    paid_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT uq_invoices_provider_external UNIQUE (provider, external_id)
+);
+
+-- 购买记录按时间倒序分页
+CREATE INDEX IF NOT EXISTS idx_invoices_tenant_paid_at ON invoices (tenant_id, paid_at DESC);
+
+-- 启用 RLS
+ALTER TABLE invoices ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON invoices FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON invoices FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
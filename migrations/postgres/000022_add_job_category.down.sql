@@ -0,0 +1,6 @@
+-- 000022_add_job_category.down.sql
+-- 回滚任务类别
+
+DROP INDEX IF EXISTS idx_jobs_project_category_created;
+
+ALTER TABLE generation_jobs DROP COLUMN IF EXISTS category;
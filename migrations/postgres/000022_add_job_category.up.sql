@@ -0,0 +1,12 @@
+-- 000022_add_job_category.up.sql
+-- 任务表增加类别：导出、重建索引、清理等运维任务与生成任务共用 generation_jobs，按 category 区分
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT 'generation';
+
+UPDATE generation_jobs
+SET category = 'maintenance'
+WHERE job_type IN ('embedding_gen', 'index_rebuild');
+
+-- 项目任务列表按类别过滤、时间倒序
+CREATE INDEX IF NOT EXISTS idx_jobs_project_category_created ON generation_jobs (project_id, category, created_at DESC);
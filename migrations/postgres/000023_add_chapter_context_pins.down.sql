@@ -0,0 +1,4 @@
+-- 000023_add_chapter_context_pins.down.sql
+-- 回滚章节固定上下文
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS context_pins;
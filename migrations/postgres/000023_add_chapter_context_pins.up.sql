@@ -0,0 +1,5 @@
+-- 000023_add_chapter_context_pins.up.sql
+-- 章节固定上下文：作者指定的章节/实体/片段，生成时无论相似度高低都会注入 Prompt
+
+ALTER TABLE chapters
+ADD COLUMN IF NOT EXISTS context_pins JSONB;
@@ -0,0 +1,4 @@
+-- 000024_create_spoiler_guards.down.sql
+-- 回滚剧透保护表
+
+DROP TABLE IF EXISTS spoiler_guards CASCADE;
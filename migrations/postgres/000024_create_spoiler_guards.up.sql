@@ -0,0 +1,52 @@
+-- 000024_create_spoiler_guards.up.sql
+-- 创建剧透保护表：揭晓点（章节或卷）之前的章节不得出现受保护的实体/事实/事件
+
+CREATE TABLE IF NOT EXISTS spoiler_guards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    ref_id UUID,
+    label TEXT NOT NULL,
+    keywords JSONB DEFAULT '[]'::jsonb,
+    reveal_chapter_id UUID REFERENCES chapters (id) ON DELETE CASCADE,
+    reveal_volume_id UUID REFERENCES volumes (id) ON DELETE CASCADE,
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_spoiler_guards_reveal CHECK (
+        (reveal_chapter_id IS NULL) <> (reveal_volume_id IS NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_spoiler_guards_tenant ON spoiler_guards (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_spoiler_guards_project ON spoiler_guards (project_id, created_at);
+
+CREATE TRIGGER update_spoiler_guards_updated_at
+    BEFORE UPDATE ON spoiler_guards
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 启用 RLS
+ALTER TABLE spoiler_guards ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON spoiler_guards FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON spoiler_guards FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON spoiler_guards FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON spoiler_guards FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
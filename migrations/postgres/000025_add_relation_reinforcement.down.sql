@@ -0,0 +1,4 @@
+-- 000025_add_relation_reinforcement.down.sql
+-- 回滚关系强化记录
+
+ALTER TABLE relations DROP COLUMN IF EXISTS last_reinforced_chapter_id;
@@ -0,0 +1,13 @@
+-- 000025_add_relation_reinforcement.up.sql
+-- 关系强化记录：章节中双方同时出场时更新，用于按章节距离计算关系强度衰减
+
+ALTER TABLE relations
+ADD COLUMN IF NOT EXISTS last_reinforced_chapter_id UUID REFERENCES chapters (id) ON DELETE SET NULL;
+
+-- 已有关系以最近出现章节作为最近强化章节
+UPDATE relations
+SET
+    last_reinforced_chapter_id = last_chapter_id
+WHERE
+    last_reinforced_chapter_id IS NULL
+    AND last_chapter_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- 000026_add_event_supersession.down.sql
+-- 回滚事件替代记录
+
+DROP INDEX IF EXISTS idx_events_chapter_active;
+
+ALTER TABLE events
+DROP COLUMN IF EXISTS superseded_by,
+DROP COLUMN IF EXISTS superseded_at;
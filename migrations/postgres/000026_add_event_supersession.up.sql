@@ -0,0 +1,11 @@
+-- 000026_add_event_supersession.up.sql
+-- 事件替代记录：章节重新生成后按章节替换抽取事件，旧事件标记为已替代并保留指向新事件的链接以便审计
+
+ALTER TABLE events
+ADD COLUMN IF NOT EXISTS superseded_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS superseded_by UUID REFERENCES events (id) ON DELETE SET NULL;
+
+-- 按章节查询当前有效事件
+CREATE INDEX IF NOT EXISTS idx_events_chapter_active ON events (chapter_id)
+WHERE
+    superseded_at IS NULL;
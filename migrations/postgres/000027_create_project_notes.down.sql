@@ -0,0 +1,4 @@
+-- 000027_create_project_notes.down.sql
+-- 回滚项目笔记表
+
+DROP TABLE IF EXISTS project_notes CASCADE;
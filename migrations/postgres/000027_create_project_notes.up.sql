@@ -0,0 +1,40 @@
+-- 000027_create_project_notes.up.sql
+-- 创建项目笔记表：作者导入的原始笔记，用于冷启动抽取设定草稿并写入向量索引（segment_type = notes）
+
+CREATE TABLE IF NOT EXISTS project_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    title VARCHAR(255),
+    content TEXT NOT NULL,
+    char_count INT NOT NULL DEFAULT 0,
+    source_job_id UUID REFERENCES generation_jobs (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_notes_tenant ON project_notes (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_project_notes_project ON project_notes (project_id, created_at);
+
+-- 启用 RLS
+ALTER TABLE project_notes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON project_notes FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON project_notes FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON project_notes FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON project_notes FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
@@ -0,0 +1,6 @@
+-- 000028_create_feature_flags.down.sql
+-- 回滚功能开关表
+
+DROP TABLE IF EXISTS feature_flag_overrides CASCADE;
+
+DROP TABLE IF EXISTS feature_flags CASCADE;
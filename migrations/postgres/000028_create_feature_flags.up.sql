@@ -0,0 +1,85 @@
+-- 000028_create_feature_flags.up.sql
+-- 创建功能开关表（全局定义 + 灰度比例）与租户/项目级覆盖表，用于高风险能力的逐步放量
+
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout_percent INT NOT NULL DEFAULT 0 CHECK (
+        rollout_percent BETWEEN 0 AND 100
+    ),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 内置开关（保持现有行为：JSON Patch 与冲突扫描全量开启，新版章节流水线默认关闭）
+INSERT INTO
+    feature_flags (
+        key,
+        description,
+        enabled,
+        rollout_percent
+    )
+VALUES (
+        'artifact_json_patch',
+        '构件生成优先输出 JSON Patch 增量',
+        TRUE,
+        100
+    ),
+    (
+        'artifact_conflict_scan',
+        '构件生成后的跨构件冲突扫描',
+        TRUE,
+        100
+    ),
+    (
+        'chapter_pipeline_v2',
+        '新版章节生成流水线（预留）',
+        FALSE,
+        0
+    )
+ON CONFLICT (key) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects (id) ON DELETE CASCADE,
+    flag_key VARCHAR(100) NOT NULL REFERENCES feature_flags (key) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- 每个租户/项目在同一开关上至多一条覆盖（租户级覆盖 project_id 为空）
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_scope ON feature_flag_overrides (
+    tenant_id,
+    flag_key,
+    COALESCE(
+        project_id,
+        '00000000-0000-0000-0000-000000000000'::uuid
+    )
+);
+
+-- 启用 RLS
+ALTER TABLE feature_flag_overrides ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON feature_flag_overrides FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON feature_flag_overrides FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON feature_flag_overrides FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON feature_flag_overrides FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
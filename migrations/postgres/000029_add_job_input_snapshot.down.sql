@@ -0,0 +1,5 @@
+-- 000029_add_job_input_snapshot.down.sql
+-- 回滚生成任务输入快照
+
+ALTER TABLE generation_jobs
+DROP COLUMN IF EXISTS input_snapshot;
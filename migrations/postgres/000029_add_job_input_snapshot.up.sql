@@ -0,0 +1,5 @@
+-- 000029_add_job_input_snapshot.up.sql
+-- 记录生成任务执行时实际送入模型的输入快照（模板版本、召回上下文、参数），供管理员回放调试
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS input_snapshot JSONB;
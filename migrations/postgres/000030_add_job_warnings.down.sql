@@ -0,0 +1,5 @@
+-- 000030_add_job_warnings.down.sql
+-- 回滚任务警告
+
+ALTER TABLE generation_jobs
+DROP COLUMN IF EXISTS warnings;
@@ -0,0 +1,5 @@
+-- 000030_add_job_warnings.up.sql
+-- 记录任务执行过程中的非致命问题（附件截断、索引失败、冲突检查跳过等），随任务详情返回给用户
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS warnings JSONB;
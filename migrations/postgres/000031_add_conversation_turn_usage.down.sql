@@ -0,0 +1,8 @@
+-- 000031_add_conversation_turn_usage.down.sql
+-- 回滚会话轮次用量归因
+
+ALTER TABLE conversation_turns
+DROP COLUMN IF EXISTS cost_currency,
+DROP COLUMN IF EXISTS cost,
+DROP COLUMN IF EXISTS completion_tokens,
+DROP COLUMN IF EXISTS prompt_tokens;
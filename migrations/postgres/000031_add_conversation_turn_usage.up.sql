@@ -0,0 +1,16 @@
+-- 000031_add_conversation_turn_usage.up.sql
+-- 会话轮次用量归因：assistant 轮次记录 Token 与成本，支持按会话汇总
+
+ALTER TABLE conversation_turns
+ADD COLUMN IF NOT EXISTS prompt_tokens INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS completion_tokens INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS cost NUMERIC(18, 6) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS cost_currency VARCHAR(8);
+
+-- 历史轮次：从 metadata 回填 Token（成本无法追溯，保持为 0）
+UPDATE conversation_turns
+SET prompt_tokens = COALESCE((metadata->>'prompt_tokens')::INTEGER, 0),
+    completion_tokens = COALESCE((metadata->>'completion_tokens')::INTEGER, 0)
+WHERE role = 'assistant'
+  AND metadata IS NOT NULL
+  AND (metadata ? 'prompt_tokens' OR metadata ? 'completion_tokens');
@@ -0,0 +1,4 @@
+-- 000032_create_artifact_version_tags.down.sql
+-- 回滚构件版本标签表
+
+DROP TABLE IF EXISTS artifact_version_tags CASCADE;
@@ -0,0 +1,42 @@
+-- 000032_create_artifact_version_tags.up.sql
+-- 创建构件版本标签表（命名发布）：同一构件内标签唯一；被标记的版本不可直接删除（NO ACTION 外键），
+-- 删除构件/项目时标签随 artifact_id 级联删除
+
+CREATE TABLE IF NOT EXISTS artifact_version_tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    artifact_id UUID NOT NULL REFERENCES project_artifacts (id) ON DELETE CASCADE,
+    version_id UUID NOT NULL REFERENCES artifact_versions (id) ON DELETE NO ACTION,
+    tag VARCHAR(64) NOT NULL,
+    note TEXT,
+    created_by UUID REFERENCES users (id),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (artifact_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_version_tags_tenant ON artifact_version_tags (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_artifact_version_tags_version ON artifact_version_tags (version_id);
+
+-- 启用 RLS
+ALTER TABLE artifact_version_tags ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON artifact_version_tags FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON artifact_version_tags FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON artifact_version_tags FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON artifact_version_tags FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
@@ -0,0 +1,4 @@
+-- 回滚任务失败类型
+
+ALTER TABLE generation_jobs
+DROP COLUMN IF EXISTS error_code;
@@ -0,0 +1,10 @@
+-- 任务失败类型：区分生成超时与其他失败
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS error_code VARCHAR(32);
+
+-- 历史失败任务：无法区分原因，统一记为 failed
+UPDATE generation_jobs
+SET error_code = 'failed'
+WHERE status = 'failed'
+  AND error_code IS NULL;
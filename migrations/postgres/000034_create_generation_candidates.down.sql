@@ -0,0 +1,4 @@
+-- 000034_create_generation_candidates.down.sql
+-- 回滚多候选生成结果表
+
+DROP TABLE IF EXISTS generation_candidates CASCADE;
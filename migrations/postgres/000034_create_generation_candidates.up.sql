@@ -0,0 +1,53 @@
+-- 000034_create_generation_candidates.up.sql
+-- 创建多候选生成（best-of-N）结果表：每个候选一行，未采用的候选保留为 discarded 供参考与再次选择
+
+CREATE TABLE IF NOT EXISTS generation_candidates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    tenant_id UUID NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES generation_jobs (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    target_type VARCHAR(32) NOT NULL,
+    target_id UUID NOT NULL,
+    candidate_no INT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    content TEXT NOT NULL,
+    raw TEXT,
+    apply JSONB,
+    provider VARCHAR(100),
+    model VARCHAR(100),
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    completion_tokens INT NOT NULL DEFAULT 0,
+    temperature DOUBLE PRECISION NOT NULL DEFAULT 0,
+    version_id UUID REFERENCES artifact_versions (id) ON DELETE SET NULL,
+    selected_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (job_id, candidate_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_generation_candidates_tenant ON generation_candidates (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_generation_candidates_target ON generation_candidates (target_type, target_id);
+
+-- 启用 RLS
+ALTER TABLE generation_candidates ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON generation_candidates FOR
+SELECT USING (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_insert ON generation_candidates FOR
+INSERT
+WITH
+    CHECK (
+        tenant_id = current_tenant_id ()
+    );
+
+CREATE POLICY tenant_isolation_update ON generation_candidates FOR
+UPDATE USING (
+    tenant_id = current_tenant_id ()
+);
+
+CREATE POLICY tenant_isolation_delete ON generation_candidates FOR DELETE USING (
+    tenant_id = current_tenant_id ()
+);
@@ -0,0 +1,4 @@
+-- 回滚会话级默认生成参数
+
+ALTER TABLE conversation_sessions
+DROP COLUMN IF EXISTS generation_defaults;
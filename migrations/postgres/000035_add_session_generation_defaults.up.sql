@@ -0,0 +1,4 @@
+-- 会话级默认生成参数：发送消息时未显式指定的 provider/model/temperature/max_tokens 以此补全
+
+ALTER TABLE conversation_sessions
+ADD COLUMN IF NOT EXISTS generation_defaults JSONB;
@@ -0,0 +1,4 @@
+-- 000036_create_chapter_stats.down.sql
+-- 回滚章节文本统计表
+
+DROP TABLE IF EXISTS chapter_stats;
@@ -0,0 +1,61 @@
+-- 000036_create_chapter_stats.up.sql
+-- 创建章节文本统计表：章节正文保存时由文本分析器重新计算（字数、对白/叙述比例、段落与场景数），供节奏分析使用
+-- 历史章节无统计行，首次查询节奏分析时按正文补算
+
+CREATE TABLE IF NOT EXISTS chapter_stats (
+    chapter_id UUID PRIMARY KEY REFERENCES chapters (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    word_count INT NOT NULL DEFAULT 0,
+    dialogue_chars INT NOT NULL DEFAULT 0,
+    narration_chars INT NOT NULL DEFAULT 0,
+    dialogue_ratio DOUBLE PRECISION NOT NULL DEFAULT 0,
+    paragraph_count INT NOT NULL DEFAULT 0,
+    scene_count INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chapter_stats_project ON chapter_stats (project_id);
+
+-- 启用 RLS（与 chapters 一致，通过项目归属租户）
+ALTER TABLE chapter_stats ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON chapter_stats FOR
+SELECT USING (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_insert ON chapter_stats FOR
+INSERT
+WITH
+    CHECK (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_update ON chapter_stats FOR
+UPDATE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
+
+CREATE POLICY tenant_isolation_delete ON chapter_stats FOR DELETE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
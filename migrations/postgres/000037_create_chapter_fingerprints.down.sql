@@ -0,0 +1,4 @@
+-- 000037_create_chapter_fingerprints.down.sql
+-- 回滚章节内容指纹表
+
+DROP TABLE IF EXISTS chapter_fingerprints;
@@ -0,0 +1,57 @@
+-- 000037_create_chapter_fingerprints.up.sql
+-- 创建章节内容指纹表：章节正文保存时计算 64 位 SimHash（按字符 4-gram），用于发现同项目内近乎重复的章节
+-- 历史章节无指纹行，首次参与重复检测时按正文补算
+
+CREATE TABLE IF NOT EXISTS chapter_fingerprints (
+    chapter_id UUID PRIMARY KEY REFERENCES chapters (id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    sim_hash BIGINT NOT NULL DEFAULT 0,
+    shingle_count INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_chapter_fingerprints_project ON chapter_fingerprints (project_id);
+
+-- 启用 RLS（与 chapters 一致，通过项目归属租户）
+ALTER TABLE chapter_fingerprints ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON chapter_fingerprints FOR
+SELECT USING (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_insert ON chapter_fingerprints FOR
+INSERT
+WITH
+    CHECK (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_update ON chapter_fingerprints FOR
+UPDATE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
+
+CREATE POLICY tenant_isolation_delete ON chapter_fingerprints FOR DELETE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
@@ -0,0 +1,5 @@
+-- 回滚任务累计尝试次数与 Token 消耗
+
+ALTER TABLE generation_jobs
+DROP COLUMN IF EXISTS spent_tokens,
+DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,11 @@
+-- 任务累计执行尝试次数与 Token 消耗（含失败尝试、修复轮次与提供商切换），用于单任务成本上限
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS spent_tokens BIGINT NOT NULL DEFAULT 0;
+
+-- 历史任务：以最后一次成功的 Token 计量作为已知消耗
+UPDATE generation_jobs
+SET attempts = retry_count + 1,
+    spent_tokens = COALESCE(tokens_prompt, 0) + COALESCE(tokens_completion, 0)
+WHERE status IN ('completed', 'failed');
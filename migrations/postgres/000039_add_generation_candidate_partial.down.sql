@@ -0,0 +1,4 @@
+-- 回滚 partial 候选标记
+
+ALTER TABLE generation_candidates
+DROP COLUMN IF EXISTS partial;
@@ -0,0 +1,4 @@
+-- 流式生成被客户端中断时，已生成的部分正文保存为 partial 候选（任务状态 cancelled_partial），供作者续写或丢弃
+
+ALTER TABLE generation_candidates
+ADD COLUMN IF NOT EXISTS partial BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+-- 回滚章节大纲关联
+
+DROP INDEX IF EXISTS idx_chapters_project_outline_key;
+
+ALTER TABLE chapters
+DROP COLUMN IF EXISTS outline_key;
@@ -0,0 +1,13 @@
+-- 章节显式关联大纲章节条目（outline 构件 volumes[].chapters[].key），用于大纲覆盖度查询
+
+ALTER TABLE chapters
+ADD COLUMN IF NOT EXISTS outline_key VARCHAR(128);
+
+-- 历史章节：设定集落库时 ai_key 即大纲条目 key
+UPDATE chapters
+SET outline_key = ai_key
+WHERE outline_key IS NULL
+    AND ai_key IS NOT NULL
+    AND ai_key <> '';
+
+CREATE INDEX IF NOT EXISTS idx_chapters_project_outline_key ON chapters (project_id, outline_key);
@@ -0,0 +1,12 @@
+-- 回滚卷级章节汇总
+
+DROP TRIGGER IF EXISTS refresh_volume_rollup_on_chapters ON chapters;
+DROP FUNCTION IF EXISTS chapters_refresh_volume_rollup();
+DROP FUNCTION IF EXISTS refresh_volume_rollup(UUID);
+
+ALTER TABLE volumes
+DROP COLUMN IF EXISTS chapter_count,
+DROP COLUMN IF EXISTS draft_chapters,
+DROP COLUMN IF EXISTS generating_chapters,
+DROP COLUMN IF EXISTS review_chapters,
+DROP COLUMN IF EXISTS completed_chapters;
@@ -0,0 +1,82 @@
+-- 卷级章节汇总：字数与各状态章节数，由 chapters 表触发器维护，卷列表无需逐卷查询章节
+
+ALTER TABLE volumes
+ADD COLUMN IF NOT EXISTS chapter_count INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS draft_chapters INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS generating_chapters INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS review_chapters INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS completed_chapters INT NOT NULL DEFAULT 0;
+
+-- 按卷重新汇总（口径与 entity.Volume.ApplyChapterRollup 一致）
+CREATE OR REPLACE FUNCTION refresh_volume_rollup(vid UUID)
+RETURNS VOID AS $$
+BEGIN
+    IF vid IS NULL THEN
+        RETURN;
+    END IF;
+    UPDATE volumes v
+    SET word_count = s.word_count,
+        chapter_count = s.chapter_count,
+        draft_chapters = s.draft_chapters,
+        generating_chapters = s.generating_chapters,
+        review_chapters = s.review_chapters,
+        completed_chapters = s.completed_chapters
+    FROM (
+        SELECT
+            COALESCE(SUM(word_count), 0) AS word_count,
+            COUNT(*) AS chapter_count,
+            COUNT(*) FILTER (WHERE status = 'draft') AS draft_chapters,
+            COUNT(*) FILTER (WHERE status = 'generating') AS generating_chapters,
+            COUNT(*) FILTER (WHERE status = 'review') AS review_chapters,
+            COUNT(*) FILTER (WHERE status = 'completed') AS completed_chapters
+        FROM chapters
+        WHERE volume_id = vid
+    ) s
+    WHERE v.id = vid;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION chapters_refresh_volume_rollup()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        PERFORM refresh_volume_rollup(NEW.volume_id);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM refresh_volume_rollup(OLD.volume_id);
+    ELSE
+        PERFORM refresh_volume_rollup(OLD.volume_id);
+        IF NEW.volume_id IS DISTINCT FROM OLD.volume_id THEN
+            PERFORM refresh_volume_rollup(NEW.volume_id);
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER refresh_volume_rollup_on_chapters
+    AFTER INSERT OR DELETE OR UPDATE OF volume_id, status, word_count ON chapters
+    FOR EACH ROW
+    EXECUTE FUNCTION chapters_refresh_volume_rollup();
+
+-- 回填历史数据
+UPDATE volumes v
+SET word_count = s.word_count,
+    chapter_count = s.chapter_count,
+    draft_chapters = s.draft_chapters,
+    generating_chapters = s.generating_chapters,
+    review_chapters = s.review_chapters,
+    completed_chapters = s.completed_chapters
+FROM (
+    SELECT
+        volume_id,
+        COALESCE(SUM(word_count), 0) AS word_count,
+        COUNT(*) AS chapter_count,
+        COUNT(*) FILTER (WHERE status = 'draft') AS draft_chapters,
+        COUNT(*) FILTER (WHERE status = 'generating') AS generating_chapters,
+        COUNT(*) FILTER (WHERE status = 'review') AS review_chapters,
+        COUNT(*) FILTER (WHERE status = 'completed') AS completed_chapters
+    FROM chapters
+    WHERE volume_id IS NOT NULL
+    GROUP BY volume_id
+) s
+WHERE v.id = s.volume_id;
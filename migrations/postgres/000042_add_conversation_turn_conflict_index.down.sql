@@ -0,0 +1,3 @@
+-- 回滚设定冲突警告版本索引
+
+DROP INDEX IF EXISTS idx_conversation_turns_conflict_version;
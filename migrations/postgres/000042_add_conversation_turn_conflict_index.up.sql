@@ -0,0 +1,5 @@
+-- 项目健康度按构件激活版本查询未处理的设定冲突警告（助手轮次 metadata.version_id）
+
+CREATE INDEX IF NOT EXISTS idx_conversation_turns_conflict_version ON conversation_turns ((metadata ->> 'version_id'))
+WHERE
+    metadata -> 'conflict_warnings' IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 000043_create_chapter_versions.down.sql
+-- 回滚章节正文版本快照表
+
+DROP TABLE IF EXISTS chapter_versions;
@@ -0,0 +1,75 @@
+-- 000043_create_chapter_versions.up.sql
+-- 章节正文版本快照：章节保存时按当前版本号 upsert（自动保存 debounce 窗口内的多次保存合并到同一版本），供版本 diff 使用
+-- 历史章节仅回填当前版本
+
+CREATE TABLE IF NOT EXISTS chapter_versions (
+    chapter_id UUID NOT NULL REFERENCES chapters (id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    content_text TEXT NOT NULL,
+    word_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (chapter_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chapter_versions_project ON chapter_versions (project_id);
+
+INSERT INTO
+    chapter_versions (
+        chapter_id,
+        version,
+        project_id,
+        content_text,
+        word_count
+    )
+SELECT id, COALESCE(version, 1), project_id, content_text, COALESCE(word_count, 0)
+FROM chapters
+WHERE
+    content_text IS NOT NULL
+    AND content_text <> ''
+ON CONFLICT DO NOTHING;
+
+-- 启用 RLS（与 chapters 一致，通过项目归属租户）
+ALTER TABLE chapter_versions ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_select ON chapter_versions FOR
+SELECT USING (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_insert ON chapter_versions FOR
+INSERT
+WITH
+    CHECK (
+        project_id IN (
+            SELECT id
+            FROM projects
+            WHERE
+                tenant_id = current_tenant_id ()
+        )
+    );
+
+CREATE POLICY tenant_isolation_update ON chapter_versions FOR
+UPDATE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
+
+CREATE POLICY tenant_isolation_delete ON chapter_versions FOR DELETE USING (
+    project_id IN (
+        SELECT id
+        FROM projects
+        WHERE
+            tenant_id = current_tenant_id ()
+    )
+);
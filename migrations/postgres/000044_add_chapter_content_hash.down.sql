@@ -0,0 +1,4 @@
+-- 000044_add_chapter_content_hash.down.sql
+-- 回滚章节正文哈希列
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS content_hash;
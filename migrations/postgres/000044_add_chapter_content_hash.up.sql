@@ -0,0 +1,16 @@
+-- 000044_add_chapter_content_hash.up.sql
+-- 章节正文哈希：保存时由仓储计算，用于识别正文是否变化（变化时自动排队重建该章向量索引）
+-- 历史章节按现有正文回填；空正文为空串
+
+ALTER TABLE chapters
+ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE chapters
+SET
+    content_hash = encode(
+        sha256(convert_to(content_text, 'UTF8')),
+        'hex'
+    )
+WHERE
+    content_text IS NOT NULL
+    AND content_text <> '';
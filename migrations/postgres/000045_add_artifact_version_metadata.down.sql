@@ -0,0 +1,4 @@
+-- 000045_add_artifact_version_metadata.down.sql
+-- 回滚构件版本附加信息列
+
+ALTER TABLE artifact_versions DROP COLUMN IF EXISTS metadata;
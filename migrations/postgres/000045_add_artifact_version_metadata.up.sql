@@ -0,0 +1,5 @@
+-- 000045_add_artifact_version_metadata.up.sql
+-- 构件版本附加信息：记录写入时生效的项目自动激活策略与激活决策原因（历史版本为空）
+
+ALTER TABLE artifact_versions
+ADD COLUMN IF NOT EXISTS metadata JSONB;
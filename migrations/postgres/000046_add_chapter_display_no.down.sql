@@ -0,0 +1,4 @@
+-- 000046_add_chapter_display_no.down.sql
+-- 回滚章节展示序号列
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS display_no;
@@ -0,0 +1,21 @@
+-- 000046_add_chapter_display_no.up.sql
+-- 章节展示序号：项目内按叙事顺序（卷序号 -> 章节序号）连续编号（第 N 章），与卷内排序键 seq_num 分离；
+-- 删除、移动、重排后由章节编号服务刷新。历史章节按当前叙事顺序回填
+
+ALTER TABLE chapters
+ADD COLUMN IF NOT EXISTS display_no INT NOT NULL DEFAULT 0;
+
+UPDATE chapters c
+SET
+    display_no = o.rn
+FROM (
+        SELECT ch.id, ROW_NUMBER() OVER (
+                PARTITION BY
+                    ch.project_id
+                ORDER BY COALESCE(v.seq_num, 0) ASC, ch.seq_num ASC, ch.created_at ASC
+            ) AS rn
+        FROM chapters ch
+            LEFT JOIN volumes v ON v.id = ch.volume_id
+    ) o
+WHERE
+    c.id = o.id;
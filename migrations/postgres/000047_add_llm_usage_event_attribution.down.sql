@@ -0,0 +1,9 @@
+-- 000047_add_llm_usage_event_attribution.down.sql
+-- 回滚 LLM 用量事件归属列
+
+DROP INDEX IF EXISTS idx_llm_usage_events_tenant_project_created;
+
+ALTER TABLE llm_usage_events
+DROP COLUMN IF EXISTS job_type,
+DROP COLUMN IF EXISTS job_id,
+DROP COLUMN IF EXISTS project_id;
@@ -0,0 +1,12 @@
+-- 000047_add_llm_usage_event_attribution.up.sql
+-- LLM 用量事件记录归属（项目 / 任务），供用量查询按项目、任务类型筛选与导出。
+-- 不加外键：项目或任务删除后用量流水仍需保留用于对账；历史事件归属为空
+
+ALTER TABLE llm_usage_events
+ADD COLUMN IF NOT EXISTS project_id UUID,
+ADD COLUMN IF NOT EXISTS job_id UUID,
+ADD COLUMN IF NOT EXISTS job_type VARCHAR(32) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_events_tenant_project_created ON llm_usage_events (tenant_id, project_id, created_at)
+WHERE
+    project_id IS NOT NULL;
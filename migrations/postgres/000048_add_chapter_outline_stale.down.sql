@@ -0,0 +1,8 @@
+-- 000048_add_chapter_outline_stale.down.sql
+-- 回滚章节大纲过期标记
+
+DROP INDEX IF EXISTS idx_chapters_project_outline_stale;
+
+ALTER TABLE chapters
+DROP COLUMN IF EXISTS outline_stale_since,
+DROP COLUMN IF EXISTS outline_stale;
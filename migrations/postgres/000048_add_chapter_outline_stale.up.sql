@@ -0,0 +1,11 @@
+-- 000048_add_chapter_outline_stale.up.sql
+-- 章节大纲过期标记：激活的大纲版本中关联条目发生实质变化（修改 / 删除 / 移动）时置位，
+-- 由重新生成、大纲刷新任务或作者手动确认清除；项目健康检查据此列出偏离新大纲的章节
+
+ALTER TABLE chapters
+ADD COLUMN IF NOT EXISTS outline_stale BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS outline_stale_since TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_chapters_project_outline_stale ON chapters (project_id)
+WHERE
+    outline_stale;
@@ -0,0 +1,5 @@
+-- 000049_add_job_truncation.down.sql
+-- 回滚任务截断报告
+
+ALTER TABLE generation_jobs
+DROP COLUMN IF EXISTS truncation;
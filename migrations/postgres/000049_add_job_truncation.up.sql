@@ -0,0 +1,5 @@
+-- 000049_add_job_truncation.up.sql
+-- 记录组装 Prompt 时被截断的输入（附件、滚动上下文、召回片段）及截去的字数与估算 Token 数，随任务详情返回给用户
+
+ALTER TABLE generation_jobs
+ADD COLUMN IF NOT EXISTS truncation JSONB;
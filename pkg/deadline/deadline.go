@@ -0,0 +1,70 @@
+// Package deadline 记录请求截止时间内首个超时的下游依赖。
+// 网关的截止时间中间件在请求入口注入记录器，各基础设施客户端（数据库、缓存、向量库、模型）在调用返回后上报，
+// 错误响应据此给出按依赖区分的超时错误码。
+package deadline
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Dependency 下游依赖
+type Dependency string
+
+// 下游依赖
+const (
+	Database  Dependency = "database"
+	Cache     Dependency = "cache"
+	VectorDB  Dependency = "vector_db"
+	LLM       Dependency = "llm"
+	Embedding Dependency = "embedding"
+)
+
+type trackerKey struct{}
+
+type tracker struct {
+	mu  sync.Mutex
+	dep Dependency
+}
+
+// WithTracker 返回可记录下游超时的 context；已存在记录器时原样返回
+func WithTracker(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(trackerKey{}).(*tracker); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, trackerKey{}, &tracker{})
+}
+
+// Observe 在下游调用返回后调用：err 为截止时间超时（或 ctx 已超时）时记录依赖，仅保留首个
+func Observe(ctx context.Context, dep Dependency, err error) {
+	if ctx == nil || err == nil {
+		return
+	}
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	t, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dep == "" {
+		t.dep = dep
+	}
+}
+
+// TimedOut 返回首个超时的下游依赖
+func TimedOut(ctx context.Context) (Dependency, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	t, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dep, t.dep != ""
+}
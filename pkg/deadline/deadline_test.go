@@ -0,0 +1,43 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestObserveRecordsFirstTimedOutDependency(t *testing.T) {
+	ctx := WithTracker(context.Background())
+
+	Observe(ctx, Cache, errors.New("connection refused"))
+	if _, ok := TimedOut(ctx); ok {
+		t.Fatal("non-timeout error must not be recorded")
+	}
+
+	Observe(ctx, VectorDB, fmt.Errorf("failed to search: %w", context.DeadlineExceeded))
+	Observe(ctx, Database, context.DeadlineExceeded)
+	if dep, ok := TimedOut(ctx); !ok || dep != VectorDB {
+		t.Fatalf("expected vector_db, got %q (ok=%v)", dep, ok)
+	}
+}
+
+func TestObserveUsesExpiredContext(t *testing.T) {
+	ctx, cancel := context.WithDeadline(WithTracker(context.Background()), time.Now().Add(-time.Second))
+	defer cancel()
+
+	// 部分客户端把超时包装成自定义错误（如 gRPC status），以 ctx 状态为准
+	Observe(ctx, LLM, errors.New("rpc error: code = DeadlineExceeded"))
+	if dep, ok := TimedOut(ctx); !ok || dep != LLM {
+		t.Fatalf("expected llm, got %q (ok=%v)", dep, ok)
+	}
+}
+
+func TestObserveWithoutTracker(t *testing.T) {
+	ctx := context.Background()
+	Observe(ctx, Database, context.DeadlineExceeded)
+	if _, ok := TimedOut(ctx); ok {
+		t.Fatal("expected no tracker")
+	}
+}
@@ -0,0 +1,258 @@
+// Package diagnostics 提供运行时诊断：pprof、expvar 与 goroutine/heap 快照。
+// 快照写入本地目录并按数量轮转，用于在生产环境定位大规模生成期间的内存增长。
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	runtimepprof "runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 快照类型（runtime/pprof 内置 profile 名）
+const (
+	KindHeap      = "heap"
+	KindGoroutine = "goroutine"
+	KindAllocs    = "allocs"
+)
+
+// DefaultMaxSnapshots 默认保留的快照数量
+const DefaultMaxSnapshots = 20
+
+var (
+	// ErrUnknownKind 不支持的快照类型
+	ErrUnknownKind = errors.New("unknown snapshot kind")
+	// ErrSnapshotNotFound 快照不存在（或名称非法）
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// snapshotTimeLayout 快照文件名中的采集时间（UTC，精确到毫秒；写入文件名时去掉小数点）
+const snapshotTimeLayout = "20060102T150405.000Z"
+
+var snapshotNamePattern = regexp.MustCompile(`^[a-z0-9-]+-(heap|goroutine|allocs)-(\d{8}T\d{9}Z)\.pb\.gz$`)
+
+// Kinds 返回支持的快照类型
+func Kinds() []string {
+	return []string{KindHeap, KindGoroutine, KindAllocs}
+}
+
+// Snapshot 快照文件信息
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Snapshotter 采集 profile 快照并写入本地目录（超过 max 时删除最旧的）
+type Snapshotter struct {
+	service string
+	dir     string
+	max     int
+
+	mu sync.Mutex
+}
+
+// NewSnapshotter 创建快照采集器；dir 为空时使用系统临时目录下的 z-novel-diagnostics
+func NewSnapshotter(service, dir string, max int) *Snapshotter {
+	if strings.TrimSpace(dir) == "" {
+		dir = filepath.Join(os.TempDir(), "z-novel-diagnostics")
+	}
+	if max <= 0 {
+		max = DefaultMaxSnapshots
+	}
+	return &Snapshotter{service: sanitizeService(service), dir: dir, max: max}
+}
+
+// Dir 返回快照目录
+func (s *Snapshotter) Dir() string {
+	return s.dir
+}
+
+// Capture 采集指定类型的快照；heap 快照采集前先触发 GC，使结果反映存活对象
+func (s *Snapshotter) Capture(kind string) (*Snapshot, error) {
+	profile := runtimepprof.Lookup(kind)
+	if profile == nil || !validKind(kind) {
+		return nil, ErrUnknownKind
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s-%s.pb.gz", s.service, kind, strings.Replace(now.Format(snapshotTimeLayout), ".", "", 1))
+	path := filepath.Join(s.dir, name)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot file: %w", err)
+	}
+	if kind == KindHeap {
+		goruntime.GC()
+	}
+	if err := profile.WriteTo(f, 0); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("write snapshot: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rotate()
+	return &Snapshot{Name: name, Kind: kind, Size: info.Size(), CreatedAt: now}, nil
+}
+
+// List 按时间倒序列出快照
+func (s *Snapshotter) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Snapshot, 0, len(entries))
+	for _, e := range entries {
+		m := snapshotNamePattern.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		createdAt, err := time.Parse(snapshotTimeLayout, m[2][:15]+"."+m[2][15:])
+		if err != nil {
+			createdAt = info.ModTime().UTC()
+		}
+		out = append(out, Snapshot{Name: e.Name(), Kind: m[1], Size: info.Size(), CreatedAt: createdAt})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Path 返回快照文件路径；名称非法或文件不存在时返回 ErrSnapshotNotFound
+func (s *Snapshotter) Path(name string) (string, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return "", ErrSnapshotNotFound
+	}
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrSnapshotNotFound
+	}
+	return path, nil
+}
+
+// rotate 删除超出保留数量的最旧快照（调用方持有锁）
+func (s *Snapshotter) rotate() {
+	list, err := s.List()
+	if err != nil || len(list) <= s.max {
+		return
+	}
+	for _, snap := range list[s.max:] {
+		_ = os.Remove(filepath.Join(s.dir, snap.Name))
+	}
+}
+
+// Handler 返回挂载在 /debug/ 下的诊断端点：
+// /debug/pprof/*（标准 pprof）、/debug/vars（expvar）、/debug/snapshots（GET 列表，POST ?kind= 采集）、/debug/snapshots/{name}（下载）
+func Handler(snapshots *Snapshotter) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list, err := snapshots.List()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, list)
+		case http.MethodPost:
+			snap, err := snapshots.Capture(r.URL.Query().Get("kind"))
+			if errors.Is(err, ErrUnknownKind) {
+				http.Error(w, "kind must be one of "+strings.Join(Kinds(), ", "), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusCreated, snap)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/debug/snapshots/", func(w http.ResponseWriter, r *http.Request) {
+		ServeSnapshot(w, r, snapshots, strings.TrimPrefix(r.URL.Path, "/debug/snapshots/"))
+	})
+	return mux
+}
+
+// ServeSnapshot 以附件形式下载快照文件
+func ServeSnapshot(w http.ResponseWriter, r *http.Request, snapshots *Snapshotter, name string) {
+	path, err := snapshots.Path(name)
+	if err != nil {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeFile(w, r, path)
+}
+
+func validKind(kind string) bool {
+	for _, k := range Kinds() {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeService(service string) string {
+	service = strings.ToLower(strings.TrimSpace(service))
+	var b strings.Builder
+	for _, r := range service {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return "service"
+	}
+	return b.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
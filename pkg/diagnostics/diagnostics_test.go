@@ -0,0 +1,72 @@
+package diagnostics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCaptureRotatesOldestSnapshots(t *testing.T) {
+	s := NewSnapshotter("job worker", t.TempDir(), 2)
+	for _, kind := range []string{KindGoroutine, KindHeap, KindAllocs} {
+		if _, err := s.Capture(kind); err != nil {
+			t.Fatalf("capture %s: %v", kind, err)
+		}
+		// 文件名精确到毫秒，避免同名
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 snapshots after rotation, got %d", len(list))
+	}
+	if list[0].Kind != KindAllocs || list[1].Kind != KindHeap {
+		t.Fatalf("expected newest first without goroutine snapshot, got %+v", list)
+	}
+	if _, err := s.Path(list[0].Name); err != nil {
+		t.Fatalf("path of captured snapshot: %v", err)
+	}
+}
+
+func TestCaptureRejectsUnknownKind(t *testing.T) {
+	s := NewSnapshotter("svc", t.TempDir(), 0)
+	if _, err := s.Capture("threadcreate"); !errors.Is(err, ErrUnknownKind) {
+		t.Fatalf("expected ErrUnknownKind, got %v", err)
+	}
+}
+
+func TestPathRejectsTraversal(t *testing.T) {
+	s := NewSnapshotter("svc", t.TempDir(), 0)
+	for _, name := range []string{"../etc/passwd", "svc-heap-20260101T000000000Z.pb.gz"} {
+		if _, err := s.Path(name); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Fatalf("expected ErrSnapshotNotFound for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestHandlerCapturesAndServesSnapshot(t *testing.T) {
+	h := Handler(NewSnapshotter("svc", t.TempDir(), 0))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/snapshots?kind=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown kind, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/snapshots?kind=goroutine", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected expvar 200, got %d", rec.Code)
+	}
+}
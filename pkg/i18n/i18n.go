@@ -0,0 +1,278 @@
+// Package i18n 提供 API 错误消息与校验文案的多语言支持：
+// 代码中的英文消息即消息键（稳定，不随语言变化），按 Accept-Language 协商出的语言从消息目录翻译。
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Locale 语言标签（BCP 47）
+type Locale string
+
+const (
+	// LocaleEN 英文（消息键的原文）
+	LocaleEN Locale = "en"
+	// LocaleZhCN 简体中文
+	LocaleZhCN Locale = "zh-CN"
+)
+
+// GinKey Gin Context 中保存协商语言的键
+const GinKey = "locale"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalog 单个语言的消息目录
+type catalog struct {
+	// Separator 拼接“消息键: 详情”时使用的分隔符
+	Separator string `json:"separator"`
+	// ListSeparator 多条校验文案之间的分隔符
+	ListSeparator string `json:"list_separator"`
+	// Messages 消息键 -> 译文
+	Messages map[string]string `json:"messages"`
+	// Validation 校验规则（binding tag）-> 文案模板，{field} 替换为字段名；default 为未知规则的兜底
+	Validation map[string]string `json:"validation"`
+}
+
+var (
+	catalogs      = mustLoadCatalogs()
+	defaultLocale atomic.Value
+)
+
+func init() {
+	defaultLocale.Store(LocaleEN)
+}
+
+func mustLoadCatalogs() map[Locale]*catalog {
+	out := make(map[Locale]*catalog)
+	for _, l := range []Locale{LocaleEN, LocaleZhCN} {
+		raw, err := localeFS.ReadFile("locales/" + string(l) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", l, err))
+		}
+		var c catalog
+		if err := json.Unmarshal(raw, &c); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", l, err))
+		}
+		out[l] = &c
+	}
+	return out
+}
+
+// Supported 返回支持的语言
+func Supported() []Locale {
+	return []Locale{LocaleEN, LocaleZhCN}
+}
+
+// Parse 解析语言标签（大小写不敏感，只比较主语言时 zh-TW 等也归入 zh-CN）；不支持时返回 false
+func Parse(tag string) (Locale, bool) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", false
+	}
+	for _, l := range Supported() {
+		if strings.EqualFold(tag, string(l)) {
+			return l, true
+		}
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	for _, l := range Supported() {
+		lb, _, _ := strings.Cut(string(l), "-")
+		if strings.EqualFold(base, lb) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// SetDefault 设置未携带或无法匹配 Accept-Language 时使用的语言（不支持的标签忽略）
+func SetDefault(tag string) {
+	if l, ok := Parse(tag); ok {
+		defaultLocale.Store(l)
+	}
+}
+
+// Default 返回默认语言
+func Default() Locale {
+	return defaultLocale.Load().(Locale)
+}
+
+// Negotiate 按 Accept-Language（含 q 权重）选择支持的语言，无匹配时返回默认语言
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.tag == "*" {
+			break
+		}
+		if l, ok := Parse(c.tag); ok {
+			return l
+		}
+	}
+	return Default()
+}
+
+type ctxKey struct{}
+
+// WithLocale 将语言写入 context
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext 读取 context 中的语言（未设置时返回默认语言）
+func FromContext(ctx context.Context) Locale {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(Locale); ok {
+			return l
+		}
+	}
+	return Default()
+}
+
+func catalogOf(l Locale) *catalog {
+	if c, ok := catalogs[l]; ok {
+		return c
+	}
+	return catalogs[LocaleEN]
+}
+
+// Translate 将消息翻译为指定语言：整句命中消息键时直接翻译；
+// 形如“消息键: 详情”的拼接消息翻译前半部分，详情中的请求校验错误按校验文案翻译，其余原样保留。
+// 未收录的消息原样返回。
+func Translate(l Locale, message string) string {
+	c := catalogOf(l)
+	if t, ok := c.Messages[message]; ok {
+		return t
+	}
+	head, detail, ok := strings.Cut(message, ": ")
+	if !ok {
+		return message
+	}
+	t, ok := c.Messages[head]
+	if !ok {
+		return message
+	}
+	return t + c.Separator + translateDetail(c, detail)
+}
+
+// Code 返回消息的稳定错误码（由消息键转换为 snake_case，与语言无关）；未收录的消息返回空字符串
+func Code(message string) string {
+	key := message
+	if _, ok := catalogs[LocaleEN].Messages[key]; !ok {
+		head, _, found := strings.Cut(message, ": ")
+		if !found {
+			return ""
+		}
+		if _, ok := catalogs[LocaleEN].Messages[head]; !ok {
+			return ""
+		}
+		key = head
+	}
+	return toSnake(key)
+}
+
+func toSnake(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// validatorLine 匹配 go-playground/validator 的默认错误文本
+var validatorLine = regexp.MustCompile(`^Key: '([^']*)' Error:Field validation for '([^']*)' failed on the '([^']*)' tag$`)
+
+// translateDetail 翻译请求绑定错误：校验错误逐行替换为校验文案，空请求体（EOF）使用消息目录
+func translateDetail(c *catalog, detail string) string {
+	if t, ok := c.Messages[detail]; ok {
+		return t
+	}
+	lines := strings.Split(detail, "\n")
+	for i, line := range lines {
+		m := validatorLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return detail
+		}
+		tmpl, ok := c.Validation[m[3]]
+		if !ok {
+			tmpl = c.Validation["default"]
+		}
+		lines[i] = strings.NewReplacer("{field}", fieldName(m[1], m[2]), "{tag}", m[3]).Replace(tmpl)
+	}
+	return strings.Join(lines, c.ListSeparator)
+}
+
+// fieldName 将校验错误中的字段转换为请求 JSON 字段名（嵌套字段保留路径，如 volumes[0].title）
+func fieldName(namespace, field string) string {
+	path := namespace
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		path = rest
+	}
+	if path == "" {
+		path = field
+	}
+	parts := strings.Split(path, ".")
+	for i, p := range parts {
+		name, index, _ := strings.Cut(p, "[")
+		parts[i] = camelToSnake(name)
+		if index != "" {
+			parts[i] += "[" + index
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// camelToSnake Go 字段名转 snake_case（连续大写视为缩写：VolumeID -> volume_id，ChapterIDs -> chapter_ids）
+func camelToSnake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			prev := runes[i-1]
+			prevLower := (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9')
+			nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z' && runes[i+1] != 's'
+			if prevLower || (prev >= 'A' && prev <= 'Z' && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteString(strings.ToLower(string(r)))
+	}
+	return b.String()
+}
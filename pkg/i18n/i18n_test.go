@@ -0,0 +1,73 @@
+package i18n
+
+import "testing"
+
+func TestCatalogsCoverSameKeys(t *testing.T) {
+	en := catalogs[LocaleEN]
+	for _, l := range Supported() {
+		c := catalogs[l]
+		if len(c.Messages) != len(en.Messages) {
+			t.Fatalf("%s: %d messages, en has %d", l, len(c.Messages), len(en.Messages))
+		}
+		for key := range en.Messages {
+			if c.Messages[key] == "" {
+				t.Fatalf("%s: missing translation for %q", l, key)
+			}
+		}
+		for tag := range en.Validation {
+			if c.Validation[tag] == "" {
+				t.Fatalf("%s: missing validation text for %q", l, tag)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]Locale{
+		"":                           LocaleEN,
+		"zh-CN,zh;q=0.9,en;q=0.8":    LocaleZhCN,
+		"zh-TW":                      LocaleZhCN,
+		"fr-FR,en-US;q=0.7,zh;q=0.5": LocaleEN,
+		"en;q=0.3, zh-Hans;q=0.8":    LocaleZhCN,
+		"fr, *;q=0.1":                LocaleEN,
+		"zh;q=0, en-GB":              LocaleEN,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestTranslateAndCode(t *testing.T) {
+	if got := Translate(LocaleZhCN, "project not found"); got != "项目不存在" {
+		t.Fatalf("unexpected translation %q", got)
+	}
+	if got := Translate(LocaleEN, "project not found"); got != "project not found" {
+		t.Fatalf("unexpected translation %q", got)
+	}
+	// 未收录的消息原样返回，且没有稳定错误码
+	if got := Translate(LocaleZhCN, "something else"); got != "something else" || Code("something else") != "" {
+		t.Fatalf("unknown message should pass through, got %q", got)
+	}
+
+	// 拼接消息：翻译消息键，校验错误按字段翻译，错误码取消息键
+	msg := "invalid request body: Key: 'CreateChapterRequest.VolumeID' Error:Field validation for 'VolumeID' failed on the 'required' tag\n" +
+		"Key: 'CreateChapterRequest.Title' Error:Field validation for 'Title' failed on the 'max' tag"
+	if got := Translate(LocaleZhCN, msg); got != "请求体无效：volume_id 为必填项；title 超过最大长度或取值" {
+		t.Fatalf("unexpected validation translation %q", got)
+	}
+	if got := Translate(LocaleEN, msg); got != "invalid request body: volume_id is required; title exceeds the maximum length or value" {
+		t.Fatalf("unexpected validation translation %q", got)
+	}
+	if got := Translate(LocaleZhCN, "invalid request body: EOF"); got != "请求体无效：请求体为空" {
+		t.Fatalf("unexpected EOF translation %q", got)
+	}
+	if Code(msg) != "invalid_request_body" || Code("Idempotency-Key too long") != "idempotency_key_too_long" {
+		t.Fatalf("unexpected codes %q %q", Code(msg), Code("Idempotency-Key too long"))
+	}
+
+	if got := fieldName("ApplyFoundationRequest.Plan.Volumes[0].ChapterIDs", "ChapterIDs"); got != "plan.volumes[0].chapter_ids" {
+		t.Fatalf("unexpected field name %q", got)
+	}
+}
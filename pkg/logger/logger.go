@@ -30,6 +30,10 @@ func Init(level string, format string) {
 	opts := &slog.HandlerOptions{
 		Level:     parseLevel(level),
 		AddSource: true,
+		// 统一脱敏：每次输出时读取当前配置（见 SetRedaction）
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return Redaction().ReplaceAttr(groups, a)
+		},
 	}
 
 	if strings.ToLower(format) == "json" {
@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// RedactionConfig 日志与链路脱敏配置（observability.redaction）
+type RedactionConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// AllowFields 原样输出的字段（优先于内置规则，仍受长度限制）
+	AllowFields []string `yaml:"allow_fields" mapstructure:"allow_fields"`
+	// RedactFields 追加整体替换为 [REDACTED] 的字段
+	RedactFields []string `yaml:"redact_fields" mapstructure:"redact_fields"`
+	// HashFields 追加以哈希输出的标识字段
+	HashFields []string `yaml:"hash_fields" mapstructure:"hash_fields"`
+	// MaxValueLength 字符串值最大字符数，超出截断；0 表示不截断
+	MaxValueLength int `yaml:"max_value_length" mapstructure:"max_value_length"`
+	// HashSalt 标识哈希盐值（跨服务需一致才能关联同一标识）
+	HashSalt string `yaml:"hash_salt" mapstructure:"hash_salt"`
+}
+
+const (
+	// RedactedValue 敏感值替换文本
+	RedactedValue = "[REDACTED]"
+	// DefaultMaxValueLength 默认字符串值长度上限
+	DefaultMaxValueLength = 512
+
+	hashPrefix = "sha256:"
+	hashLength = 12
+)
+
+var (
+	// defaultRedactFields 正文类字段：Prompt、章节/构件正文、凭据等，整体替换
+	defaultRedactFields = []string{
+		"prompt", "prompts", "messages", "completion", "content", "content_text", "text", "text_content",
+		"outline", "query", "raw", "body", "request_body", "response_body",
+		"password", "secret", "token", "access_token", "refresh_token", "api_key", "authorization", "cookie",
+	}
+	// defaultHashFields 个人标识字段，以带盐哈希输出（可关联、不可还原）
+	defaultHashFields = []string{"email", "phone", "ip", "client_ip", "remote_addr", "username"}
+
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// DefaultRedactionConfig 默认脱敏配置
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{Enabled: true, MaxValueLength: DefaultMaxValueLength}
+}
+
+// Redactor 按字段名脱敏：allow → 原样；redact → [REDACTED]；hash → sha256 前缀；
+// 其他字符串值截断并将其中的邮箱替换为哈希。字段名不区分大小写，
+// 带点号的链路属性取最后一段（llm.prompt → prompt），也匹配 "_" 后缀（system_prompt → prompt）。
+type Redactor struct {
+	enabled bool
+	allow   map[string]bool
+	redact  map[string]bool
+	hash    map[string]bool
+	maxLen  int
+	salt    string
+}
+
+// NewRedactor 创建脱敏器（内置规则 + 配置追加）
+func NewRedactor(cfg RedactionConfig) *Redactor {
+	r := &Redactor{
+		enabled: cfg.Enabled,
+		allow:   fieldSet(cfg.AllowFields),
+		redact:  fieldSet(append(append([]string{}, defaultRedactFields...), cfg.RedactFields...)),
+		hash:    fieldSet(append(append([]string{}, defaultHashFields...), cfg.HashFields...)),
+		maxLen:  cfg.MaxValueLength,
+		salt:    cfg.HashSalt,
+	}
+	if r.maxLen < 0 {
+		r.maxLen = 0
+	}
+	return r
+}
+
+var redactor atomic.Pointer[Redactor]
+
+func init() {
+	redactor.Store(NewRedactor(DefaultRedactionConfig()))
+}
+
+// SetRedaction 设置全局脱敏配置（日志与链路导出共用）
+func SetRedaction(cfg RedactionConfig) {
+	redactor.Store(NewRedactor(cfg))
+}
+
+// Redaction 返回全局脱敏器
+func Redaction() *Redactor {
+	return redactor.Load()
+}
+
+// String 按字段名脱敏字符串值
+func (r *Redactor) String(key, value string) string {
+	if r == nil || !r.enabled {
+		return value
+	}
+	switch r.classify(key) {
+	case fieldAllow:
+		return r.truncate(value)
+	case fieldRedact:
+		if value == "" {
+			return value
+		}
+		return RedactedValue
+	case fieldHash:
+		if value == "" {
+			return value
+		}
+		return r.Hash(value)
+	default:
+		return r.Text(value)
+	}
+}
+
+// Text 脱敏自由文本（错误信息等）：邮箱替换为哈希并截断
+func (r *Redactor) Text(s string) string {
+	if r == nil || !r.enabled || s == "" {
+		return s
+	}
+	if strings.Contains(s, "@") {
+		s = emailPattern.ReplaceAllStringFunc(s, r.Hash)
+	}
+	return r.truncate(s)
+}
+
+// Hash 标识哈希：sha256(salt + value) 前 12 位
+func (r *Redactor) Hash(value string) string {
+	sum := sha256.Sum256([]byte(r.salt + value))
+	return hashPrefix + hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// Sensitive 字段是否整体脱敏（redact 或 hash）
+func (r *Redactor) Sensitive(key string) bool {
+	if r == nil || !r.enabled {
+		return false
+	}
+	c := r.classify(key)
+	return c == fieldRedact || c == fieldHash
+}
+
+// ReplaceAttr 作为 slog.HandlerOptions.ReplaceAttr 使用
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if r == nil || !r.enabled {
+		return a
+	}
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.TimeKey, slog.LevelKey, slog.SourceKey:
+			return a
+		case slog.MessageKey:
+			return slog.String(a.Key, r.Text(a.Value.String()))
+		}
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.String(a.Key, v.String()))
+	case slog.KindAny:
+		if r.Sensitive(a.Key) {
+			return slog.String(a.Key, r.String(a.Key, fmt.Sprint(v.Any())))
+		}
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, r.Text(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, r.Text(x.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+type fieldClass int
+
+const (
+	fieldDefault fieldClass = iota
+	fieldAllow
+	fieldRedact
+	fieldHash
+)
+
+func (r *Redactor) classify(key string) fieldClass {
+	key = normalizeField(key)
+	switch {
+	case matchField(r.allow, key):
+		return fieldAllow
+	case matchField(r.redact, key):
+		return fieldRedact
+	case matchField(r.hash, key):
+		return fieldHash
+	default:
+		return fieldDefault
+	}
+}
+
+func (r *Redactor) truncate(s string) string {
+	if r.maxLen <= 0 || utf8.RuneCountInString(s) <= r.maxLen {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:r.maxLen]) + fmt.Sprintf("…(+%d chars)", len(runes)-r.maxLen)
+}
+
+// matchField 精确匹配或 "_" 分隔的后缀匹配
+func matchField(set map[string]bool, key string) bool {
+	if set[key] {
+		return true
+	}
+	for i := strings.IndexByte(key, '_'); i >= 0; i = strings.IndexByte(key, '_') {
+		key = key[i+1:]
+		if set[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeField(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	return strings.ReplaceAll(key, "-", "_")
+}
+
+func fieldSet(fields []string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f = normalizeField(f); f != "" {
+			set[f] = true
+		}
+	}
+	return set
+}
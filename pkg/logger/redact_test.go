@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactorFieldRules(t *testing.T) {
+	r := NewRedactor(RedactionConfig{Enabled: true, MaxValueLength: 16, HashSalt: "salt", AllowFields: []string{"ip"}})
+
+	cases := []struct {
+		key, value, want string
+	}{
+		{"prompt", "写一段林默拔剑的场景", RedactedValue},
+		{"system_prompt", "你是小说家", RedactedValue},
+		{"llm.prompt", "secret prompt", RedactedValue},
+		{"text_content", "章节正文", RedactedValue},
+		{"api_key", "sk-xxx", RedactedValue},
+		{"prompt_tokens", "128", "128"},
+		{"email", "a@b.com", r.Hash("a@b.com")},
+		{"user_email", "a@b.com", r.Hash("a@b.com")},
+		{"ip", "10.0.0.1", "10.0.0.1"}, // allowlist 优先于内置 hash 规则
+		{"job_id", "0123456789abcdefXYZ", "0123456789abcdef…(+3 chars)"},
+		{"error", "user a@b.com not found", "user " + r.Hash("a@b.com")[:11] + "…(+18 chars)"},
+		{"prompt", "", ""},
+	}
+	for _, tc := range cases {
+		if got := r.String(tc.key, tc.value); got != tc.want {
+			t.Errorf("String(%q, %q) = %q, want %q", tc.key, tc.value, got, tc.want)
+		}
+	}
+
+	if h := r.Hash("a@b.com"); !strings.HasPrefix(h, "sha256:") || h == NewRedactor(RedactionConfig{Enabled: true}).Hash("a@b.com") {
+		t.Errorf("hash should be prefixed and salted, got %q", h)
+	}
+	if got := NewRedactor(RedactionConfig{Enabled: false}).String("prompt", "raw"); got != "raw" {
+		t.Errorf("disabled redactor should pass through, got %q", got)
+	}
+}
+
+func TestRedactionAppliedToLogger(t *testing.T) {
+	prev := Redaction()
+	defer redactor.Store(prev)
+	SetRedaction(RedactionConfig{Enabled: true, MaxValueLength: 64})
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr { return Redaction().ReplaceAttr(groups, a) },
+	}))
+
+	log.With("email", "writer@example.com").Info("login failed for writer@example.com",
+		"prompt", "林默的秘密身份",
+		"raw", []byte(`{"content":"正文"}`),
+		"error", errors.New("provider echoed: 林默 writer@example.com"),
+		"chapter_id", "ch-1",
+	)
+
+	out := buf.String()
+	for _, leak := range []string{"writer@example.com", "林默的秘密身份", "正文"} {
+		if strings.Contains(out, leak) {
+			t.Fatalf("log output leaks %q: %s", leak, out)
+		}
+	}
+	for _, keep := range []string{`"chapter_id":"ch-1"`, RedactedValue, "sha256:"} {
+		if !strings.Contains(out, keep) {
+			t.Fatalf("log output missing %q: %s", keep, out)
+		}
+	}
+}
@@ -120,6 +120,27 @@ var (
 		[]string{"workflow", "provider", "model", "status"},
 	)
 
+	// LLMCircuitState 熔断器状态：0=closed，1=half_open，2=open
+	LLMCircuitState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "llm",
+			Name:      "circuit_state",
+			Help:      "LLM circuit breaker state per provider and model (0=closed, 1=half_open, 2=open)",
+		},
+		[]string{"provider", "model"},
+	)
+
+	LLMCircuitShortCircuits = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "llm",
+			Name:      "circuit_short_circuits_total",
+			Help:      "Total number of LLM calls rejected by an open circuit breaker",
+		},
+		[]string{"provider", "model", "outcome"}, // outcome: fallback/rejected
+	)
+
 	// Tool 指标（ToolCalling / ToolsNode）
 	ToolCallDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -185,6 +206,80 @@ var (
 		[]string{"stream", "status"},
 	)
 
+	RedisStreamPending = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "stream_pending",
+			Help:      "Redis stream pending entry list size (delivered but not acknowledged)",
+		},
+		[]string{"stream", "consumer_group"},
+	)
+
+	RedisStreamClaimAttempts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "stream_claim_attempts_total",
+			Help:      "Total number of XCLAIM attempts on pending Redis stream messages",
+		},
+		[]string{"stream", "reason", "status"}, // reason: retry/reclaim/dlq
+	)
+
+	RedisStreamMessageAge = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "stream_message_age_seconds",
+			Help:      "Age of Redis stream messages when a handler starts processing them",
+			Buckets:   []float64{.1, .5, 1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"stream", "type"},
+	)
+
+	RedisStreamHandlerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "stream_handler_duration_seconds",
+			Help:      "Redis stream message handler duration in seconds per message type",
+			Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{"stream", "type", "status"},
+	)
+
+	RedisStreamStalledMessages = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "stream_stalled_messages_total",
+			Help:      "Total number of times a pending Redis stream message was found claimed more often than the stall threshold",
+		},
+		[]string{"stream", "consumer_group"},
+	)
+
+	// 数据库查询指标（按仓储/方法聚合）
+	DBQueryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Database query duration in seconds",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"repository", "method", "operation"},
+	)
+
+	DBSlowQueriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "slow_queries_total",
+			Help:      "Total number of database queries exceeding the slow query threshold",
+		},
+		[]string{"repository", "method"},
+	)
+
 	// 校验指标
 	ValidationTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -196,6 +291,25 @@ var (
 		[]string{"type", "status"},
 	)
 
+	// 构件版本清理指标（仅统计实际删除，dry-run 不计入）
+	ArtifactGCVersionsDeleted = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "artifact_gc",
+			Name:      "versions_deleted_total",
+			Help:      "Total number of artifact versions deleted by retention cleanup",
+		},
+	)
+
+	ArtifactGCReclaimedBytes = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "artifact_gc",
+			Name:      "reclaimed_bytes_total",
+			Help:      "Total bytes of artifact version content reclaimed by retention cleanup",
+		},
+	)
+
 	// 活跃用户/写作者指标
 	ActiveWriters = promauto.NewGauge(
 		prometheus.GaugeOpts{
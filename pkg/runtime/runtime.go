@@ -0,0 +1,270 @@
+// Package runtime 提供服务入口的统一启动/关闭流程：
+// 加载 .env 与配置、初始化日志与脱敏、启动期配置校验、初始化追踪、可选指标端口与诊断端口，
+// 运行服务组件并在退出信号到达时按序优雅关闭。新增的运维能力（指标、健康检查、性能剖析）在此接入即可覆盖所有二进制。
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"z-novel-ai-api/internal/config"
+	"z-novel-ai-api/pkg/diagnostics"
+	"z-novel-ai-api/pkg/logger"
+	"z-novel-ai-api/pkg/tracer"
+)
+
+// DefaultShutdownTimeout 默认优雅关闭超时
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Options 服务入口选项
+type Options struct {
+	// ServiceName 服务名（日志与追踪的 service.name）
+	ServiceName string
+	// Version / BuildTime 构建信息（可选，仅用于启动日志）
+	Version   string
+	BuildTime string
+
+	// CheckConfig 启动前执行 config.CheckStartup（严格模式下存在错误则拒绝启动）
+	CheckConfig bool
+	// MetricsServer 在 observability.metrics.port 独立暴露 Prometheus 指标（无 HTTP 路由的进程使用）
+	MetricsServer bool
+	// ShutdownTimeout 优雅关闭超时；0 使用 DefaultShutdownTimeout
+	ShutdownTimeout time.Duration
+
+	// LoadConfig 加载配置；为空时使用 config.Load
+	LoadConfig func() (*config.Config, error)
+	// Init 初始化依赖并通过 App.Go 启动服务组件；未启动任何组件时视为一次性任务，Init 返回后即退出
+	Init func(ctx context.Context, app *App) error
+	// Shutdown 收到退出信号（或组件异常退出）后调用，先于组件等待与资源清理
+	Shutdown func(ctx context.Context) error
+
+	// signals 测试注入的退出信号通道；为空时监听 SIGINT/SIGTERM
+	signals <-chan os.Signal
+}
+
+// App 运行期上下文：持有配置，登记服务组件与清理函数
+type App struct {
+	Config *config.Config
+
+	ctx      context.Context
+	wg       sync.WaitGroup
+	errCh    chan error
+	started  int
+	mu       sync.Mutex
+	cleanups []func()
+}
+
+// Go 启动服务组件：fn 应阻塞运行直至 ctx 取消。组件提前返回（含 nil）会触发整个进程关闭，返回错误时进程以非零状态退出
+func (a *App) Go(fn func(ctx context.Context) error) {
+	a.started++
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		err := fn(a.ctx)
+		select {
+		case a.errCh <- err:
+		default:
+		}
+	}()
+}
+
+// Defer 登记清理函数，在组件全部退出后按登记的逆序执行
+func (a *App) Defer(fn func()) {
+	if fn == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cleanups = append(a.cleanups, fn)
+}
+
+func (a *App) cleanup() {
+	a.mu.Lock()
+	cleanups := a.cleanups
+	a.cleanups = nil
+	a.mu.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// Run 执行服务入口的完整生命周期；启动失败或组件异常退出时以状态码 1 退出进程
+func Run(ctx context.Context, opts Options) {
+	if err := run(ctx, opts); err != nil {
+		logger.Error(ctx, opts.ServiceName+" exited with error", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts Options) error {
+	// 加载 .env 文件（如果存在）
+	_ = godotenv.Load()
+
+	load := opts.LoadConfig
+	if load == nil {
+		load = config.Load
+	}
+	cfg, err := load()
+	if err != nil {
+		// 日志尚未初始化：直接输出到标准输出
+		fmt.Printf("Failed to load config: %v\n", err)
+		return err
+	}
+
+	logger.Init(cfg.Observability.Logging.Level, cfg.Observability.Logging.Format)
+	logger.SetRedaction(cfg.Observability.Redaction)
+	logger.Info(ctx, "starting "+opts.ServiceName, "version", opts.Version, "build_time", opts.BuildTime, "env", cfg.App.Env)
+
+	if opts.CheckConfig {
+		report, err := config.CheckStartup(cfg)
+		for _, issue := range report.Issues {
+			logger.Warn(ctx, "config issue", "severity", issue.Severity, "field", issue.Field, "message", issue.Message)
+		}
+		if err != nil {
+			return fmt.Errorf("config validation failed: %w", err)
+		}
+	}
+
+	shutdownTracer, err := tracer.Init(ctx, tracer.Config{
+		ServiceName: opts.ServiceName,
+		Endpoint:    cfg.Observability.Tracing.Endpoint,
+		SampleRate:  cfg.Observability.Tracing.SampleRate,
+		Enabled:     cfg.Observability.Tracing.Enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to init tracer: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracer(ctx); err != nil {
+			logger.Warn(ctx, "failed to shutdown tracer", "error", err.Error())
+		}
+	}()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	app := &App{Config: cfg, ctx: runCtx, errCh: make(chan error, 1)}
+	defer app.cleanup()
+
+	if opts.Init != nil {
+		if err := opts.Init(runCtx, app); err != nil {
+			return err
+		}
+	}
+	// 一次性任务：Init 完成即退出
+	if app.started == 0 {
+		return nil
+	}
+
+	var metricsSrv *http.Server
+	if opts.MetricsServer {
+		metricsSrv = startMetricsServer(ctx, cfg.Observability.Metrics)
+	}
+	diagnosticsSrv := startDiagnosticsServer(ctx, opts.ServiceName, cfg.Observability.Diagnostics)
+	logger.Info(ctx, opts.ServiceName+" started")
+
+	quit := opts.signals
+	if quit == nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(ch)
+		quit = ch
+	}
+
+	var runErr error
+	select {
+	case <-quit:
+	case <-ctx.Done():
+	case runErr = <-app.errCh:
+	}
+	logger.Info(ctx, opts.ServiceName+" shutting down")
+
+	timeout := opts.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), timeout)
+	defer cancelShutdown()
+
+	cancel()
+	if opts.Shutdown != nil {
+		if err := opts.Shutdown(shutdownCtx); err != nil {
+			logger.Warn(ctx, "shutdown hook failed", "error", err.Error())
+		}
+	}
+	for _, srv := range []*http.Server{metricsSrv, diagnosticsSrv} {
+		if srv != nil {
+			_ = srv.Shutdown(shutdownCtx)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		app.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		logger.Warn(ctx, "shutdown timed out, exiting with components still running", "timeout", timeout.String())
+	}
+
+	logger.Info(ctx, opts.ServiceName+" exited")
+	if runErr != nil && !errors.Is(runErr, context.Canceled) {
+		return runErr
+	}
+	return nil
+}
+
+// startMetricsServer 在独立端口暴露 Prometheus 指标；未启用时返回 nil
+func startMetricsServer(ctx context.Context, cfg config.MetricsConfig) *http.Server {
+	if !cfg.Enabled || cfg.Port <= 0 {
+		return nil
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.Handler())
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Warn(ctx, "metrics server stopped", "error", err.Error())
+		}
+	}()
+	return srv
+}
+
+// startDiagnosticsServer 在独立端口暴露 pprof/expvar/快照端点（无认证，应仅监听本机或内网）；未启用或未配置端口时返回 nil
+func startDiagnosticsServer(ctx context.Context, service string, cfg config.DiagnosticsConfig) *http.Server {
+	if !cfg.Enabled || cfg.Port <= 0 {
+		return nil
+	}
+	snapshots := diagnostics.NewSnapshotter(service, cfg.SnapshotDir, cfg.MaxSnapshots)
+	srv := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:           diagnostics.Handler(snapshots),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info(ctx, "diagnostics server starting", "addr", srv.Addr, "snapshot_dir", snapshots.Dir())
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Warn(ctx, "diagnostics server stopped", "error", err.Error())
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"z-novel-ai-api/internal/config"
+)
+
+func testConfig() (*config.Config, error) {
+	return &config.Config{}, nil
+}
+
+func TestRunOneShotRunsCleanups(t *testing.T) {
+	var order []string
+	err := run(context.Background(), Options{
+		ServiceName: "oneshot",
+		LoadConfig:  testConfig,
+		Init: func(ctx context.Context, app *App) error {
+			app.Defer(func() { order = append(order, "first") })
+			app.Defer(func() { order = append(order, "second") })
+			order = append(order, "init")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 未启动组件时 Init 返回即退出，清理按登记逆序执行
+	if strings.Join(order, ",") != "init,second,first" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestRunShutsDownOnSignal(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, step)
+	}
+	err := run(context.Background(), Options{
+		ServiceName: "svc",
+		LoadConfig:  testConfig,
+		signals:     signals,
+		Init: func(ctx context.Context, app *App) error {
+			app.Defer(func() { record("cleanup") })
+			app.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				record("component")
+				return nil
+			})
+			signals <- syscall.SIGTERM
+			return nil
+		},
+		Shutdown: func(ctx context.Context) error {
+			record("shutdown")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 资源清理在 Shutdown 与组件全部退出之后执行
+	if order[len(order)-1] != "cleanup" || len(order) != 3 {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestRunReturnsComponentError(t *testing.T) {
+	boom := errors.New("listen failed")
+	shutdownCalled := false
+	err := run(context.Background(), Options{
+		ServiceName: "svc",
+		LoadConfig:  testConfig,
+		signals:     make(chan os.Signal),
+		Init: func(ctx context.Context, app *App) error {
+			app.Go(func(ctx context.Context) error { return boom })
+			return nil
+		},
+		Shutdown: func(ctx context.Context) error {
+			shutdownCalled = true
+			return nil
+		},
+	})
+	if !errors.Is(err, boom) || !shutdownCalled {
+		t.Fatalf("expected component error after shutdown, got %v (shutdown=%v)", err, shutdownCalled)
+	}
+}
+
+func TestRunInitErrorSkipsComponents(t *testing.T) {
+	boom := errors.New("init failed")
+	cleaned := false
+	err := run(context.Background(), Options{
+		ServiceName: "svc",
+		LoadConfig:  testConfig,
+		Init: func(ctx context.Context, app *App) error {
+			app.Defer(func() { cleaned = true })
+			return boom
+		},
+	})
+	if !errors.Is(err, boom) || !cleaned {
+		t.Fatalf("expected init error with cleanup, got %v (cleaned=%v)", err, cleaned)
+	}
+}
@@ -0,0 +1,80 @@
+package tracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+// redactingExporter 导出前按全局脱敏规则（logger.Redaction）处理 Span 属性、事件属性与状态描述，
+// 覆盖各处 SetAttributes / RecordError 写入的 Prompt、正文、邮箱等敏感值。
+type redactingExporter struct {
+	sdktrace.SpanExporter
+}
+
+// NewRedactingExporter 包装 exporter，导出前统一脱敏
+func NewRedactingExporter(exporter sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &redactingExporter{SpanExporter: exporter}
+}
+
+func (e *redactingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	r := logger.Redaction()
+	out := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		out[i] = &redactedSpan{ReadOnlySpan: s, r: r}
+	}
+	return e.SpanExporter.ExportSpans(ctx, out)
+}
+
+// redactedSpan 只读 Span 视图：读取时脱敏，不修改原 Span
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	r *logger.Redactor
+}
+
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return RedactAttributes(s.r, s.ReadOnlySpan.Attributes())
+}
+
+func (s *redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	out := make([]sdktrace.Event, len(events))
+	for i, ev := range events {
+		ev.Attributes = RedactAttributes(s.r, ev.Attributes)
+		out[i] = ev
+	}
+	return out
+}
+
+func (s *redactedSpan) Status() sdktrace.Status {
+	st := s.ReadOnlySpan.Status()
+	st.Description = s.r.Text(st.Description)
+	return st
+}
+
+// RedactAttributes 按字段名脱敏字符串（及字符串切片）属性，其他类型原样保留
+func RedactAttributes(r *logger.Redactor, attrs []attribute.KeyValue) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return attrs
+	}
+	out := make([]attribute.KeyValue, len(attrs))
+	for i, kv := range attrs {
+		key := string(kv.Key)
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			out[i] = attribute.String(key, r.String(key, kv.Value.AsString()))
+		case attribute.STRINGSLICE:
+			values := kv.Value.AsStringSlice()
+			for j := range values {
+				values[j] = r.String(key, values[j])
+			}
+			out[i] = attribute.StringSlice(key, values)
+		default:
+			out[i] = kv
+		}
+	}
+	return out
+}
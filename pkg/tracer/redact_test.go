@@ -0,0 +1,73 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"z-novel-ai-api/pkg/logger"
+)
+
+func TestRedactingExporter(t *testing.T) {
+	mem := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewRedactingExporter(mem)))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	_, span := tp.Tracer("test").Start(context.Background(), "llm.generate")
+	span.SetAttributes(
+		attribute.String("llm.prompt", "林默的秘密身份"),
+		attribute.String("user.email", "writer@example.com"),
+		attribute.StringSlice("llm.messages", []string{"第一条", "第二条"}),
+		attribute.Int("llm.prompt_tokens", 42),
+		attribute.String("llm.model", "gpt-4o"),
+	)
+	err := errors.New("rate limited for writer@example.com")
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
+
+	spans := mem.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["llm.prompt"].AsString(); got != logger.RedactedValue {
+		t.Errorf("llm.prompt = %q, want redacted", got)
+	}
+	if got := attrs["user.email"].AsString(); !strings.HasPrefix(got, "sha256:") {
+		t.Errorf("user.email = %q, want hashed", got)
+	}
+	for _, v := range attrs["llm.messages"].AsStringSlice() {
+		if v != logger.RedactedValue {
+			t.Errorf("llm.messages element = %q, want redacted", v)
+		}
+	}
+	if got := attrs["llm.prompt_tokens"].AsInt64(); got != 42 {
+		t.Errorf("llm.prompt_tokens = %d, want 42", got)
+	}
+	if got := attrs["llm.model"].AsString(); got != "gpt-4o" {
+		t.Errorf("llm.model = %q, want unchanged", got)
+	}
+
+	if strings.Contains(s.Status.Description, "writer@example.com") {
+		t.Errorf("status description leaks email: %q", s.Status.Description)
+	}
+	for _, ev := range s.Events {
+		for _, kv := range ev.Attributes {
+			if strings.Contains(kv.Value.Emit(), "writer@example.com") {
+				t.Errorf("event attribute %s leaks email: %q", kv.Key, kv.Value.Emit())
+			}
+		}
+	}
+}
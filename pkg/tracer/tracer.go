@@ -64,8 +64,9 @@ func Init(ctx context.Context, cfg Config) (func(context.Context) error, error)
 	}
 
 	// 创建 TracerProvider
+	// 导出前统一脱敏（Prompt/正文/邮箱等，见 logger.Redaction）
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithBatcher(NewRedactingExporter(exporter)),
 		sdktrace.WithResource(res),
 		sdktrace.WithSampler(sampler),
 	)
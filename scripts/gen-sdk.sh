@@ -0,0 +1,53 @@
+#!/bin/bash
+set -e
+
+# 从 Swagger 注解生成 OpenAPI 规范，并生成 Go / TypeScript 客户端 SDK。
+# 依赖：go（swag 通过 go run 拉取）、docker（openapi-generator），或通过 OPENAPI_GENERATOR 指定本地命令。
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
+PROJECT_ROOT="$( dirname "$SCRIPT_DIR" )"
+
+SWAG_VERSION="${SWAG_VERSION:-v1.16.4}"
+OPENAPI_GENERATOR_IMAGE="${OPENAPI_GENERATOR_IMAGE:-openapitools/openapi-generator-cli:v7.10.0}"
+SPEC_DIR="api/openapi"
+
+cd "$PROJECT_ROOT"
+
+echo "Generating OpenAPI spec into $SPEC_DIR..."
+go run "github.com/swaggo/swag/cmd/swag@${SWAG_VERSION}" init \
+	--generalInfo cmd/api-gateway/main.go \
+	--dir ./ \
+	--parseInternal \
+	--outputTypes json,yaml \
+	--output "$SPEC_DIR"
+
+openapi_generator() {
+	if [ -n "$OPENAPI_GENERATOR" ]; then
+		$OPENAPI_GENERATOR "$@"
+	else
+		docker run --rm -u "$(id -u):$(id -g)" -v "$PROJECT_ROOT:/local" -w /local "$OPENAPI_GENERATOR_IMAGE" "$@"
+	fi
+}
+
+echo "Generating Go client into sdk/go/client..."
+rm -rf sdk/go/client
+openapi_generator generate \
+	-i "$SPEC_DIR/swagger.yaml" \
+	-g go \
+	-o sdk/go/client \
+	--global-property apiTests=false,modelTests=false,apiDocs=false,modelDocs=false \
+	--additional-properties packageName=client,isGoSubmodule=true,withGoMod=false,enumClassPrefix=true
+rm -f sdk/go/client/git_push.sh sdk/go/client/.travis.yml
+
+echo "Generating TypeScript client into sdk/typescript/src/generated..."
+rm -rf sdk/typescript/src/generated
+openapi_generator generate \
+	-i "$SPEC_DIR/swagger.yaml" \
+	-g typescript-fetch \
+	-o sdk/typescript/src/generated \
+	--additional-properties supportsES6=true,typescriptThreePlus=true
+
+(cd sdk/go && go vet ./...)
+(cd sdk/typescript && npm install --no-audit --no-fund && npm run build)
+
+echo "SDK generation completed successfully."
@@ -0,0 +1,26 @@
+#!/bin/bash
+set -e
+
+# 发布客户端 SDK：npm 发布 TypeScript 包，Go 子模块打 sdk/go/vX.Y.Z 标签并推送。
+# 用法：scripts/publish-sdk.sh <version>（需先执行 make sdk）
+
+VERSION="${1:?usage: publish-sdk.sh <version>}"
+VERSION="${VERSION#v}"
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
+PROJECT_ROOT="$( dirname "$SCRIPT_DIR" )"
+cd "$PROJECT_ROOT"
+
+if [ ! -d sdk/go/client ] || [ ! -d sdk/typescript/src/generated ]; then
+	echo "generated SDK not found, run 'make sdk' first" >&2
+	exit 1
+fi
+
+echo "Publishing TypeScript SDK $VERSION..."
+(cd sdk/typescript && npm version "$VERSION" --no-git-tag-version --allow-same-version && npm publish --access restricted)
+
+echo "Tagging Go SDK sdk/go/v$VERSION..."
+git tag "sdk/go/v$VERSION"
+git push origin "sdk/go/v$VERSION"
+
+echo "SDK $VERSION published."
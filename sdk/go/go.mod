@@ -0,0 +1,3 @@
+module github.com/nsxzhou/z-novel-ai-api/sdk/go
+
+go 1.24.0
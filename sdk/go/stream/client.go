@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client SSE 流式接口客户端
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	tenantID   string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 指定 HTTP 客户端（流式响应不应设置整体超时，请使用 ctx 控制）
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken 设置 Access Token
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithTenantID 设置租户 ID（X-Tenant-ID）
+func WithTenantID(tenantID string) Option {
+	return func(c *Client) { c.tenantID = tenantID }
+}
+
+// NewClient 创建客户端，baseURL 形如 https://api.example.com
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ChapterStreamParams 章节流式生成参数（均可选）
+type ChapterStreamParams struct {
+	Provider        string
+	Model           string
+	Temperature     *float32
+	TargetWordCount int
+}
+
+// TextAttachment 设定集生成附件
+type TextAttachment struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// FoundationStreamRequest 设定集流式生成请求
+type FoundationStreamRequest struct {
+	Prompt      string           `json:"prompt"`
+	Attachments []TextAttachment `json:"attachments,omitempty"`
+	Provider    string           `json:"provider,omitempty"`
+	Model       string           `json:"model,omitempty"`
+	Temperature *float32         `json:"temperature,omitempty"`
+	MaxTokens   *int             `json:"max_tokens,omitempty"`
+}
+
+// StreamChapter 流式生成章节正文：GET /v1/chapters/{cid}/stream
+func (c *Client) StreamChapter(ctx context.Context, chapterID string, p ChapterStreamParams) (*Reader, error) {
+	q := url.Values{}
+	if p.Provider != "" {
+		q.Set("provider", p.Provider)
+	}
+	if p.Model != "" {
+		q.Set("model", p.Model)
+	}
+	if p.Temperature != nil {
+		q.Set("temperature", strconv.FormatFloat(float64(*p.Temperature), 'f', -1, 32))
+	}
+	if p.TargetWordCount > 0 {
+		q.Set("target_word_count", strconv.Itoa(p.TargetWordCount))
+	}
+	u := c.baseURL + "/v1/chapters/" + url.PathEscape(chapterID) + "/stream"
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.open(req)
+}
+
+// StreamFoundation 流式生成设定集 Plan：POST /v1/projects/{pid}/foundation/stream
+func (c *Client) StreamFoundation(ctx context.Context, projectID string, body FoundationStreamRequest) (*Reader, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := c.baseURL + "/v1/projects/" + url.PathEscape(projectID) + "/foundation/stream"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.open(req)
+}
+
+func (c *Client) open(req *http.Request) (*Reader, error) {
+	req.Header.Set("Accept", "text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenantID != "" {
+		req.Header.Set("X-Tenant-ID", c.tenantID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: body.Message}
+	}
+	if v := resp.Header.Get(SchemaVersionHeader); v != SchemaVersion {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unsupported stream schema version %q (client supports %s)", v, SchemaVersion)
+	}
+	return &Reader{body: resp.Body, r: bufio.NewReader(resp.Body)}, nil
+}
+
+// Reader 逐条读取 SSE 事件
+type Reader struct {
+	body io.ReadCloser
+	r    *bufio.Reader
+	done bool
+}
+
+// Next 返回下一条事件；done 事件之后返回 io.EOF，error 事件返回 *StreamError
+func (r *Reader) Next() (*Event, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	for {
+		name, data, err := r.readFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// 未收到 done/error 即断开：视为异常结束
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if data == "" {
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return nil, fmt.Errorf("failed to decode %s event: %w", name, err)
+		}
+		switch ev.Type {
+		case EventDone:
+			r.done = true
+		case EventError:
+			r.done = true
+			var ed ErrorData
+			if err := ev.Decode(&ed); err != nil {
+				return nil, err
+			}
+			return nil, &StreamError{Code: ed.Code, Message: ed.Message}
+		}
+		return &ev, nil
+	}
+}
+
+// Close 关闭底层连接
+func (r *Reader) Close() error {
+	return r.body.Close()
+}
+
+// readFrame 读取一个以空行结束的 SSE 帧
+func (r *Reader) readFrame() (event, data string, err error) {
+	var lines []string
+	for {
+		line, readErr := r.r.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if readErr != nil {
+				return "", "", readErr
+			}
+			if event != "" || len(lines) > 0 {
+				return event, strings.Join(lines, "\n"), nil
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, ":"):
+			// 注释/心跳
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			lines = append(lines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		if readErr != nil {
+			return "", "", readErr
+		}
+	}
+}
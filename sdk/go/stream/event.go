@@ -0,0 +1,101 @@
+// Package stream 为 SSE 流式接口提供手写客户端（OpenAPI 生成器无法描述事件流）。
+// 事件结构与服务端 dto/stream.go 保持一致，协议版本见 SchemaVersion。
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion 本客户端支持的 SSE 事件协议版本
+const SchemaVersion = "1"
+
+// SchemaVersionHeader 服务端声明事件协议版本的响应头
+const SchemaVersionHeader = "X-Stream-Schema-Version"
+
+// EventType SSE 事件类型
+type EventType string
+
+const (
+	EventContent  EventType = "content"
+	EventProgress EventType = "progress"
+	EventContext  EventType = "context"
+	EventWarning  EventType = "warning"
+	EventDone     EventType = "done"
+	EventError    EventType = "error"
+)
+
+// Event SSE 事件信封；Data 按 Type 使用对应的 *Data 结构解码
+type Event struct {
+	Version string          `json:"v"`
+	Type    EventType       `json:"type"`
+	Seq     int             `json:"seq"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ContentData 内容分片
+type ContentData struct {
+	Chunk string `json:"chunk"`
+	Index int    `json:"index"`
+}
+
+// ProgressData 阶段进度
+type ProgressData struct {
+	Stage    string `json:"stage"`
+	Progress int    `json:"progress"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ContextData 检索上下文摘要
+type ContextData struct {
+	Segments int `json:"segments"`
+	Chars    int `json:"chars"`
+}
+
+// WarningData 非致命告警
+type WarningData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// DoneData 流正常结束；Plan 仅设定集流返回
+type DoneData struct {
+	JobID     string          `json:"job_id"`
+	ChapterID string          `json:"chapter_id,omitempty"`
+	WordCount int             `json:"word_count,omitempty"`
+	Plan      json.RawMessage `json:"plan,omitempty"`
+}
+
+// ErrorData 流异常结束
+type ErrorData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Decode 将 Data 解码到 v
+func (e *Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// StreamError 服务端以 error 事件结束流
+type StreamError struct {
+	Code    string
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("stream error (%s): %s", e.Code, e.Message)
+}
+
+// APIError 建立流之前服务端返回的非 2xx 响应
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (%d): %s", e.StatusCode, e.Message)
+}
@@ -0,0 +1,3 @@
+// 生成代码位于 src/generated（make sdk 生成，不纳入版本库）；流式接口由 stream.ts 手写实现。
+export * from "./generated";
+export * from "./stream";
@@ -0,0 +1,194 @@
+// SSE 流式接口的手写客户端（OpenAPI 生成器无法描述事件流）。
+// 事件结构与服务端 dto/stream.go 保持一致，协议版本见 STREAM_SCHEMA_VERSION。
+
+export const STREAM_SCHEMA_VERSION = "1";
+export const STREAM_SCHEMA_VERSION_HEADER = "X-Stream-Schema-Version";
+
+export interface ContentData {
+  chunk: string;
+  index: number;
+}
+
+export interface ProgressData {
+  stage: string;
+  progress: number;
+  message?: string;
+}
+
+export interface ContextData {
+  segments: number;
+  chars: number;
+}
+
+export interface WarningData {
+  code: string;
+  message: string;
+}
+
+export interface DoneData {
+  job_id: string;
+  chapter_id?: string;
+  word_count?: number;
+  plan?: unknown;
+}
+
+export interface ErrorData {
+  code: string;
+  message: string;
+}
+
+interface Envelope<T extends string, D> {
+  v: string;
+  type: T;
+  seq: number;
+  data: D;
+}
+
+export type StreamEvent =
+  | Envelope<"content", ContentData>
+  | Envelope<"progress", ProgressData>
+  | Envelope<"context", ContextData>
+  | Envelope<"warning", WarningData>
+  | Envelope<"done", DoneData>
+  | Envelope<"error", ErrorData>;
+
+/** 服务端以 error 事件结束流 */
+export class StreamError extends Error {
+  constructor(
+    public readonly code: string,
+    message: string,
+  ) {
+    super(`stream error (${code}): ${message}`);
+  }
+}
+
+/** 建立流之前服务端返回的非 2xx 响应 */
+export class APIError extends Error {
+  constructor(
+    public readonly status: number,
+    message: string,
+  ) {
+    super(`api error (${status}): ${message}`);
+  }
+}
+
+export interface StreamClientOptions {
+  baseURL: string;
+  token?: string;
+  tenantId?: string;
+  fetch?: typeof fetch;
+}
+
+export interface ChapterStreamParams {
+  provider?: string;
+  model?: string;
+  temperature?: number;
+  targetWordCount?: number;
+  signal?: AbortSignal;
+}
+
+export interface FoundationStreamRequest {
+  prompt: string;
+  attachments?: { name: string; content: string }[];
+  provider?: string;
+  model?: string;
+  temperature?: number;
+  max_tokens?: number;
+}
+
+export class StreamClient {
+  private readonly baseURL: string;
+  private readonly fetchImpl: typeof fetch;
+
+  constructor(private readonly opts: StreamClientOptions) {
+    this.baseURL = opts.baseURL.replace(/\/+$/, "");
+    this.fetchImpl = opts.fetch ?? fetch;
+  }
+
+  /** GET /v1/chapters/{cid}/stream */
+  streamChapter(chapterId: string, params: ChapterStreamParams = {}): AsyncGenerator<StreamEvent> {
+    const q = new URLSearchParams();
+    if (params.provider) q.set("provider", params.provider);
+    if (params.model) q.set("model", params.model);
+    if (params.temperature !== undefined) q.set("temperature", String(params.temperature));
+    if (params.targetWordCount) q.set("target_word_count", String(params.targetWordCount));
+    const qs = q.toString();
+    const url = `${this.baseURL}/v1/chapters/${encodeURIComponent(chapterId)}/stream${qs ? `?${qs}` : ""}`;
+    return this.open(url, { method: "GET", signal: params.signal });
+  }
+
+  /** POST /v1/projects/{pid}/foundation/stream */
+  streamFoundation(projectId: string, body: FoundationStreamRequest, signal?: AbortSignal): AsyncGenerator<StreamEvent> {
+    const url = `${this.baseURL}/v1/projects/${encodeURIComponent(projectId)}/foundation/stream`;
+    return this.open(url, {
+      method: "POST",
+      body: JSON.stringify(body),
+      headers: { "Content-Type": "application/json" },
+      signal,
+    });
+  }
+
+  private async *open(url: string, init: RequestInit): AsyncGenerator<StreamEvent> {
+    const headers = new Headers(init.headers);
+    headers.set("Accept", "text/event-stream");
+    if (this.opts.token) headers.set("Authorization", `Bearer ${this.opts.token}`);
+    if (this.opts.tenantId) headers.set("X-Tenant-ID", this.opts.tenantId);
+
+    const resp = await this.fetchImpl(url, { ...init, headers });
+    if (!resp.ok) {
+      let message = resp.statusText;
+      try {
+        message = (await resp.json()).message ?? message;
+      } catch {
+        // 非 JSON 错误体
+      }
+      throw new APIError(resp.status, message);
+    }
+    const version = resp.headers.get(STREAM_SCHEMA_VERSION_HEADER);
+    if (version !== STREAM_SCHEMA_VERSION) {
+      await resp.body?.cancel();
+      throw new Error(`unsupported stream schema version "${version}" (client supports ${STREAM_SCHEMA_VERSION})`);
+    }
+    if (!resp.body) {
+      throw new Error("empty stream body");
+    }
+
+    const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = "";
+    try {
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) {
+          // 未收到 done/error 即断开：视为异常结束
+          throw new Error("stream closed before done event");
+        }
+        buffer += value;
+        let sep: number;
+        while ((sep = buffer.search(/\r?\n\r?\n/)) >= 0) {
+          const frame = buffer.slice(0, sep);
+          buffer = buffer.slice(sep).replace(/^\r?\n\r?\n/, "");
+          const ev = parseFrame(frame);
+          if (!ev) continue;
+          if (ev.type === "error") {
+            throw new StreamError(ev.data.code, ev.data.message);
+          }
+          yield ev;
+          if (ev.type === "done") return;
+        }
+      }
+    } finally {
+      await reader.cancel().catch(() => undefined);
+    }
+  }
+}
+
+function parseFrame(frame: string): StreamEvent | undefined {
+  const data: string[] = [];
+  for (const line of frame.split(/\r?\n/)) {
+    if (line.startsWith("data:")) {
+      data.push(line.slice(5).replace(/^ /, ""));
+    }
+  }
+  if (data.length === 0) return undefined;
+  return JSON.parse(data.join("\n")) as StreamEvent;
+}